@@ -0,0 +1,1894 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde_json::Value;
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum ClientLang {
+    Ts,
+    Go,
+    Python,
+}
+
+impl ClientLang {
+    fn label(self) -> &'static str {
+        match self {
+            ClientLang::Ts => "TypeScript",
+            ClientLang::Go => "Go",
+            ClientLang::Python => "Python",
+        }
+    }
+}
+
+/// Directories never searched for specs or routes.
+const SKIP_DIRS: &[&str] = &["node_modules", "vendor", "target", "dist", "build", "venv", "__pycache__", "testdata", "third_party"];
+/// HTTP methods of an OpenAPI path item, in the order operations are emitted.
+const METHODS: &[&str] = &["get", "put", "post", "delete", "patch", "head", "options"];
+
+#[derive(Debug, Clone, PartialEq)]
+enum Type {
+    String,
+    Integer,
+    Number,
+    Boolean,
+    Array(Box<Type>),
+    Map(Box<Type>),
+    Named(String),
+    Any,
+}
+
+#[derive(Debug)]
+struct Field {
+    name: String,
+    ty: Type,
+    required: bool,
+}
+
+#[derive(Debug)]
+enum Shape {
+    Object(Vec<Field>),
+    Enum(Vec<String>),
+    Alias(Type),
+}
+
+#[derive(Debug)]
+struct Schema {
+    name: String,
+    shape: Shape,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq)]
+enum ParamIn {
+    Path,
+    Query,
+    Header,
+}
+
+#[derive(Debug)]
+struct Param {
+    name: String,
+    location: ParamIn,
+    ty: Type,
+    required: bool,
+}
+
+#[derive(Debug)]
+struct Operation {
+    /// Lowercase words of the operation name, rendered per language (`getUser`, `GetUser`, `get_user`).
+    words: Vec<String>,
+    method: String,
+    path: String,
+    summary: Option<String>,
+    params: Vec<Param>,
+    body: Option<Type>,
+    response: Option<Type>,
+}
+
+/// The API a client is generated for, whatever it was read from.
+#[derive(Debug, Default)]
+struct Api {
+    /// Spec or description of where the routes came from, shown in the generated header.
+    source: String,
+    schemas: Vec<Schema>,
+    operations: Vec<Operation>,
+}
+
+// ---------------------------------------------------------------------------
+// Names
+
+/// Split an identifier in any case style into lowercase words (`getUserByID` -> get, user, by, id).
+fn words(s: &str) -> Vec<String> {
+    let chars: Vec<char> = s.chars().collect();
+    let mut out = Vec::new();
+    let mut current = String::new();
+    for (i, &c) in chars.iter().enumerate() {
+        if !c.is_ascii_alphanumeric() {
+            if !current.is_empty() {
+                out.push(std::mem::take(&mut current));
+            }
+            continue;
+        }
+        if c.is_ascii_uppercase() && !current.is_empty() {
+            let prev = chars[i - 1];
+            let next_lower = chars.get(i + 1).is_some_and(|n| n.is_ascii_lowercase());
+            if prev.is_ascii_lowercase() || prev.is_ascii_digit() || (prev.is_ascii_uppercase() && next_lower) {
+                out.push(std::mem::take(&mut current));
+            }
+        }
+        current.push(c.to_ascii_lowercase());
+    }
+    if !current.is_empty() {
+        out.push(current);
+    }
+    out
+}
+
+fn capitalize(word: &str) -> String {
+    let mut chars = word.chars();
+    chars.next().map(|c| c.to_ascii_uppercase().to_string() + chars.as_str()).unwrap_or_default()
+}
+
+/// Keep identifiers valid when a name starts with a digit.
+fn identifier(name: String, prefix: &str) -> String {
+    if name.is_empty() || name.starts_with(|c: char| c.is_ascii_digit()) {
+        format!("{}{}", prefix, name)
+    } else {
+        name
+    }
+}
+
+fn pascal(words: &[String]) -> String {
+    identifier(words.iter().map(|w| capitalize(w)).collect(), "T")
+}
+
+fn camel(words: &[String]) -> String {
+    let name: String = words.iter().enumerate().map(|(i, w)| if i == 0 { w.clone() } else { capitalize(w) }).collect();
+    identifier(name, "v")
+}
+
+fn snake(words: &[String]) -> String {
+    identifier(words.join("_"), "v")
+}
+
+/// Initialisms Go style keeps in capitals (`UserID`, `BaseURL`).
+const GO_INITIALISMS: &[&str] = &["api", "html", "http", "id", "ip", "json", "sql", "ui", "uri", "url", "uuid", "xml"];
+
+fn go_pascal(words: &[String]) -> String {
+    let name: String =
+        words.iter().map(|w| if GO_INITIALISMS.contains(&w.as_str()) { w.to_ascii_uppercase() } else { capitalize(w) }).collect();
+    identifier(name, "X")
+}
+
+fn go_camel(words: &[String]) -> String {
+    match words.split_first() {
+        Some((first, [])) => identifier(first.clone(), "v"),
+        Some((first, rest)) => identifier(format!("{}{}", first, go_pascal(rest)), "v"),
+        None => "v".to_string(),
+    }
+}
+
+const TS_RESERVED: &[&str] = &[
+    "break", "case", "catch", "class", "const", "continue", "debugger", "default", "delete", "do", "else", "enum", "export", "extends",
+    "false", "finally", "for", "function", "if", "import", "in", "instanceof", "new", "null", "return", "super", "switch", "this",
+    "throw", "true", "try", "typeof", "var", "void", "while", "with", "body", "query", "headers",
+];
+const GO_RESERVED: &[&str] = &[
+    "break", "case", "chan", "const", "continue", "default", "defer", "else", "fallthrough", "for", "func", "go", "goto", "if",
+    "import", "interface", "map", "package", "range", "return", "select", "struct", "switch", "type", "var", "ctx", "body", "params",
+];
+const PY_KEYWORDS: &[&str] = &[
+    "False", "None", "True", "and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif", "else",
+    "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or", "pass", "raise",
+    "return", "try", "while", "with", "yield",
+];
+const PY_RESERVED: &[&str] = &["self", "body", "query", "headers", "json", "urllib"];
+
+/// Argument name for a parameter, suffixed with `_` when it clashes with a keyword or a generated name.
+fn argument(name: String, reserved: &[&str]) -> String {
+    if reserved.contains(&name.as_str()) {
+        format!("{}_", name)
+    } else {
+        name
+    }
+}
+
+fn py_argument(name: String) -> String {
+    if PY_KEYWORDS.contains(&name.as_str()) {
+        format!("{}_", name)
+    } else {
+        argument(name, PY_RESERVED)
+    }
+}
+
+/// Name of an operation without an operationId: `GET /users/{id}` -> getUsersById.
+fn operation_words(method: &str, path: &str) -> Vec<String> {
+    let mut out = vec![method.to_lowercase()];
+    let mut params = Vec::new();
+    for segment in path.split('/').filter(|s| !s.is_empty()) {
+        match segment.strip_prefix('{').and_then(|s| s.strip_suffix('}')) {
+            Some(param) => params.push(param.to_string()),
+            None => out.extend(words(segment)),
+        }
+    }
+    for (i, param) in params.iter().enumerate() {
+        out.push(if i == 0 { "by" } else { "and" }.to_string());
+        out.extend(words(param));
+    }
+    out
+}
+
+/// Operation name of a route without a better one: `getApiUsersById` (also used by `dx dev-routes openapi`).
+pub(crate) fn operation_name(method: &str, path: &str) -> String {
+    camel(&operation_words(method, path))
+}
+
+/// Give operations that ended up with the same name a numeric suffix.
+fn dedupe_operations(operations: &mut [Operation]) {
+    let mut seen: BTreeMap<String, usize> = BTreeMap::new();
+    for op in operations {
+        let count = seen.entry(op.words.join("_")).or_default();
+        *count += 1;
+        if *count > 1 {
+            op.words.push(count.to_string());
+        }
+    }
+}
+
+/// `{id}` placeholders of a path, in order.
+fn path_params(path: &str) -> Vec<String> {
+    path.split('{').skip(1).filter_map(|s| s.split_once('}')).map(|(name, _)| name.to_string()).collect()
+}
+
+// ---------------------------------------------------------------------------
+// OpenAPI / Swagger
+
+struct OpenApi<'a> {
+    doc: &'a Value,
+    /// Raw component schemas by their name in the spec.
+    components: BTreeMap<String, &'a Value>,
+    schemas: Vec<Schema>,
+    taken: BTreeSet<String>,
+}
+
+fn schema_name(raw: &str) -> String {
+    pascal(&words(raw))
+}
+
+impl<'a> OpenApi<'a> {
+    fn new(doc: &'a Value) -> Self {
+        let section = doc.pointer("/components/schemas").or_else(|| doc.get("definitions"));
+        let components: BTreeMap<String, &Value> =
+            section.and_then(Value::as_object).map(|m| m.iter().map(|(k, v)| (k.clone(), v)).collect()).unwrap_or_default();
+        let taken = components.keys().map(|k| schema_name(k)).collect();
+        OpenApi { doc, components, schemas: Vec::new(), taken }
+    }
+
+    /// Follow a local `$ref` (parameters, request bodies, responses) to what it points at.
+    fn resolve(&self, value: &'a Value) -> &'a Value {
+        let mut value = value;
+        for _ in 0..8 {
+            match value.get("$ref").and_then(Value::as_str).and_then(|r| r.strip_prefix('#')).and_then(|p| self.doc.pointer(p)) {
+                Some(target) => value = target,
+                None => break,
+            }
+        }
+        value
+    }
+
+    fn component(reference: &str) -> Option<&str> {
+        reference.strip_prefix("#/components/schemas/").or_else(|| reference.strip_prefix("#/definitions/"))
+    }
+
+    fn unique(&mut self, hint: &str) -> String {
+        let base = schema_name(hint);
+        let mut name = base.clone();
+        let mut n = 2;
+        while self.taken.contains(&name) {
+            name = format!("{}{}", base, n);
+            n += 1;
+        }
+        self.taken.insert(name.clone());
+        name
+    }
+
+    /// Properties and required names of an object schema, merging `allOf` parts.
+    fn properties(&self, schema: &'a Value, props: &mut Vec<(String, &'a Value)>, required: &mut BTreeSet<String>, depth: usize) {
+        if depth > 8 {
+            return;
+        }
+        let schema = match schema.get("$ref").and_then(Value::as_str).and_then(Self::component) {
+            Some(name) => match self.components.get(name) {
+                Some(target) => *target,
+                None => return,
+            },
+            None => schema,
+        };
+        for part in schema.get("allOf").and_then(Value::as_array).into_iter().flatten() {
+            self.properties(part, props, required, depth + 1);
+        }
+        for (name, prop) in schema.get("properties").and_then(Value::as_object).into_iter().flatten() {
+            match props.iter_mut().find(|(n, _)| n == name) {
+                Some(existing) => existing.1 = prop,
+                None => props.push((name.clone(), prop)),
+            }
+        }
+        required.extend(schema.get("required").and_then(Value::as_array).into_iter().flatten().filter_map(Value::as_str).map(String::from));
+    }
+
+    fn is_struct(schema: &Value) -> bool {
+        schema.get("properties").is_some() || schema.get("allOf").is_some()
+    }
+
+    fn object(&mut self, name: &str, schema: &'a Value) -> Shape {
+        let mut props = Vec::new();
+        let mut required = BTreeSet::new();
+        self.properties(schema, &mut props, &mut required, 0);
+        let fields = props
+            .into_iter()
+            .map(|(prop, value)| {
+                let ty = self.schema_type(value, &format!("{}{}", name, schema_name(&prop)));
+                Field { required: required.contains(&prop), name: prop, ty }
+            })
+            .collect();
+        Shape::Object(fields)
+    }
+
+    fn enum_values(schema: &Value) -> Option<Vec<String>> {
+        let values = schema.get("enum")?.as_array()?;
+        let strings: Vec<String> = values.iter().filter_map(Value::as_str).map(String::from).collect();
+        (!strings.is_empty() && strings.len() == values.len()).then_some(strings)
+    }
+
+    /// Type of `schema`; inline objects and string enums become named schemas called `hint`.
+    fn schema_type(&mut self, schema: &'a Value, hint: &str) -> Type {
+        if let Some(reference) = schema.get("$ref").and_then(Value::as_str) {
+            return Self::component(reference).map(|name| Type::Named(schema_name(name))).unwrap_or(Type::Any);
+        }
+        if let Some([single]) = schema.get("allOf").and_then(Value::as_array).map(Vec::as_slice) {
+            if schema.get("properties").is_none() {
+                return self.schema_type(single, hint);
+            }
+        }
+        if schema.get("oneOf").is_some() || schema.get("anyOf").is_some() {
+            return Type::Any;
+        }
+        let kind = match schema.get("type") {
+            Some(Value::String(kind)) => kind.as_str(),
+            Some(Value::Array(kinds)) => kinds.iter().filter_map(Value::as_str).find(|k| *k != "null").unwrap_or(""),
+            _ => "",
+        };
+        match kind {
+            "string" => match Self::enum_values(schema) {
+                Some(values) if !hint.is_empty() => {
+                    let name = self.unique(hint);
+                    self.schemas.push(Schema { name: name.clone(), shape: Shape::Enum(values) });
+                    Type::Named(name)
+                }
+                _ => Type::String,
+            },
+            "integer" => Type::Integer,
+            "number" => Type::Number,
+            "boolean" => Type::Boolean,
+            "array" => {
+                let item = if hint.is_empty() { String::new() } else { format!("{}Item", hint) };
+                Type::Array(Box::new(schema.get("items").map(|i| self.schema_type(i, &item)).unwrap_or(Type::Any)))
+            }
+            _ if Self::is_struct(schema) && !hint.is_empty() => {
+                let name = self.unique(hint);
+                let shape = self.object(&name, schema);
+                self.schemas.push(Schema { name: name.clone(), shape });
+                Type::Named(name)
+            }
+            "object" | "" => match schema.get("additionalProperties") {
+                Some(extra) if extra.is_object() => {
+                    let value = if hint.is_empty() { String::new() } else { format!("{}Value", hint) };
+                    Type::Map(Box::new(self.schema_type(extra, &value)))
+                }
+                _ if kind == "object" => Type::Map(Box::new(Type::Any)),
+                _ => Type::Any,
+            },
+            _ => Type::Any,
+        }
+    }
+
+    /// JSON schema of a request body or response, picking the JSON media type.
+    fn content_schema(&self, value: &'a Value) -> Option<&'a Value> {
+        if let Some(schema) = value.get("schema") {
+            return Some(schema);
+        }
+        let content = value.get("content")?.as_object()?;
+        let media = content.get("application/json").or_else(|| content.iter().find(|(k, _)| k.contains("json")).map(|(_, v)| v))?;
+        media.get("schema")
+    }
+
+    fn parse(mut self, source: String) -> Api {
+        let components: Vec<(String, &Value)> = self.components.iter().map(|(k, v)| (k.clone(), *v)).collect();
+        for (raw, schema) in components {
+            let name = schema_name(&raw);
+            let shape = match Self::enum_values(schema) {
+                Some(values) => Shape::Enum(values),
+                None if Self::is_struct(schema) => self.object(&name, schema),
+                None => Shape::Alias(self.schema_type(schema, &format!("{}Item", name))),
+            };
+            self.schemas.push(Schema { name, shape });
+        }
+
+        // Swagger's basePath or an OpenAPI server given as a path prefixes every route
+        let base = match self.doc.get("basePath").and_then(Value::as_str) {
+            Some(base) => base.to_string(),
+            None => self.doc.pointer("/servers/0/url").and_then(Value::as_str).filter(|u| u.starts_with('/')).unwrap_or("").to_string(),
+        };
+        let base = base.trim_end_matches('/').to_string();
+        let mut operations = Vec::new();
+        let doc = self.doc;
+        for (path, item) in doc.get("paths").and_then(Value::as_object).into_iter().flatten() {
+            let item = self.resolve(item);
+            for method in METHODS {
+                let Some(op) = item.get(*method) else { continue };
+                operations.push(self.operation(method, path, &base, item, op));
+            }
+        }
+        dedupe_operations(&mut operations);
+        Api { source, schemas: self.schemas, operations }
+    }
+
+    fn operation(&mut self, method: &str, route: &str, base: &str, item: &'a Value, op: &'a Value) -> Operation {
+        let words = match op.get("operationId").and_then(Value::as_str) {
+            Some(id) if !words(id).is_empty() => words(id),
+            _ => operation_words(method, route),
+        };
+        let path = &format!("{}{}", base, route);
+        let hint = pascal(&words);
+        let mut params: Vec<Param> = Vec::new();
+        let mut body = None;
+        let raw_params = item.get("parameters").and_then(Value::as_array).into_iter().flatten();
+        for raw in raw_params.chain(op.get("parameters").and_then(Value::as_array).into_iter().flatten()) {
+            let raw = self.resolve(raw);
+            let Some(name) = raw.get("name").and_then(Value::as_str) else { continue };
+            let location = match raw.get("in").and_then(Value::as_str).unwrap_or("") {
+                "path" => ParamIn::Path,
+                "query" => ParamIn::Query,
+                "header" => ParamIn::Header,
+                "body" => {
+                    body = raw.get("schema").map(|s| self.schema_type(s, &format!("{}Request", hint)));
+                    continue;
+                }
+                _ => continue,
+            };
+            // Swagger 2 puts the type on the parameter itself
+            let ty = self.schema_type(raw.get("schema").unwrap_or(raw), "");
+            let required = location == ParamIn::Path || raw.get("required").and_then(Value::as_bool).unwrap_or(false);
+            let param = Param { name: name.to_string(), location, ty, required };
+            // Operation parameters override the path item's ones
+            match params.iter_mut().find(|p| p.name == param.name && p.location == param.location) {
+                Some(existing) => *existing = param,
+                None => params.push(param),
+            }
+        }
+        for name in path_params(path) {
+            if !params.iter().any(|p| p.location == ParamIn::Path && p.name == name) {
+                params.push(Param { name, location: ParamIn::Path, ty: Type::String, required: true });
+            }
+        }
+        if let Some(schema) = op.get("requestBody").map(|b| self.resolve(b)).and_then(|b| self.content_schema(b)) {
+            body = Some(self.schema_type(schema, &format!("{}Request", hint)));
+        }
+        let responses = op.get("responses").and_then(Value::as_object);
+        let success = responses.into_iter().flatten().filter(|(code, _)| code.starts_with('2')).min_by_key(|(code, _)| code.as_str());
+        let response = success
+            .map(|(_, r)| self.resolve(r))
+            .and_then(|r| self.content_schema(r))
+            .map(|schema| self.schema_type(schema, &format!("{}Response", hint)));
+        let summary = op.get("summary").or_else(|| op.get("description")).and_then(Value::as_str).map(|s| s.lines().next().unwrap_or("").trim().to_string());
+        Operation {
+            words,
+            method: method.to_uppercase(),
+            path: path.to_string(),
+            summary: summary.filter(|s| !s.is_empty()),
+            params,
+            body,
+            response,
+        }
+    }
+}
+
+// ---------------------------------------------------------------------------
+// Protocol Buffers
+
+#[derive(Debug, Clone, PartialEq)]
+enum Token {
+    Word(String),
+    Str(String),
+    Symbol(char),
+}
+
+fn tokenize(source: &str) -> Vec<Token> {
+    let chars: Vec<char> = source.chars().collect();
+    let mut tokens = Vec::new();
+    let mut i = 0;
+    while i < chars.len() {
+        let c = chars[i];
+        if c.is_whitespace() {
+            i += 1;
+        } else if c == '/' && chars.get(i + 1) == Some(&'/') {
+            while i < chars.len() && chars[i] != '\n' {
+                i += 1;
+            }
+        } else if c == '/' && chars.get(i + 1) == Some(&'*') {
+            i += 2;
+            while i + 1 < chars.len() && !(chars[i] == '*' && chars[i + 1] == '/') {
+                i += 1;
+            }
+            i += 2;
+        } else if c == '"' || c == '\'' {
+            let mut s = String::new();
+            i += 1;
+            while i < chars.len() && chars[i] != c {
+                if chars[i] == '\\' {
+                    i += 1;
+                }
+                if let Some(&ch) = chars.get(i) {
+                    s.push(ch);
+                }
+                i += 1;
+            }
+            i += 1;
+            tokens.push(Token::Str(s));
+        } else if c.is_ascii_alphanumeric() || c == '_' || c == '.' || c == '-' || c == '+' {
+            let start = i;
+            while i < chars.len() && (chars[i].is_ascii_alphanumeric() || matches!(chars[i], '_' | '.' | '-' | '+')) {
+                i += 1;
+            }
+            tokens.push(Token::Word(chars[start..i].iter().collect()));
+        } else {
+            tokens.push(Token::Symbol(c));
+            i += 1;
+        }
+    }
+    tokens
+}
+
+#[derive(Debug, Default)]
+struct ProtoFile {
+    package: String,
+    /// Messages by flattened name (`Outer.Inner` -> `OuterInner`): fields as (type, name, repeated, map key).
+    messages: Vec<(String, Vec<ProtoField>)>,
+    enums: Vec<(String, Vec<String>)>,
+    services: Vec<(String, Vec<Rpc>)>,
+}
+
+#[derive(Debug)]
+struct ProtoField {
+    name: String,
+    /// Name in the JSON mapping: the `json_name` option or the lowerCamelCase name.
+    json: String,
+    ty: String,
+    repeated: bool,
+    /// Value type of a `map<K, V>` field (`ty` then holds the key).
+    map_value: Option<String>,
+}
+
+#[derive(Debug)]
+struct Rpc {
+    name: String,
+    request: String,
+    response: String,
+    streaming: bool,
+    /// google.api.http binding: (method, path, body).
+    http: Option<(String, String, Option<String>)>,
+}
+
+struct ProtoParser {
+    tokens: Vec<Token>,
+    pos: usize,
+}
+
+impl ProtoParser {
+    fn peek(&self) -> Option<&Token> {
+        self.tokens.get(self.pos)
+    }
+
+    fn next(&mut self) -> Option<Token> {
+        let token = self.tokens.get(self.pos).cloned();
+        self.pos += 1;
+        token
+    }
+
+    fn word(&mut self) -> String {
+        match self.next() {
+            Some(Token::Word(w)) => w,
+            _ => String::new(),
+        }
+    }
+
+    fn is(&self, symbol: char) -> bool {
+        self.peek() == Some(&Token::Symbol(symbol))
+    }
+
+    /// Skip to the end of the current statement, including a nested `{ ... }` block.
+    fn skip_statement(&mut self) {
+        let mut depth = 0;
+        while let Some(token) = self.next() {
+            match token {
+                Token::Symbol('{') => depth += 1,
+                Token::Symbol('}') => {
+                    depth -= 1;
+                    if depth <= 0 {
+                        return;
+                    }
+                }
+                Token::Symbol(';') if depth == 0 => return,
+                _ => {}
+            }
+        }
+    }
+
+    fn file(&mut self) -> ProtoFile {
+        let mut file = ProtoFile::default();
+        while let Some(token) = self.next() {
+            match token {
+                Token::Word(w) if w == "package" => {
+                    file.package = self.word();
+                    self.skip_statement();
+                }
+                Token::Word(w) if w == "message" => {
+                    let name = self.word();
+                    self.message(&name, &mut file);
+                }
+                Token::Word(w) if w == "enum" => {
+                    let name = self.word();
+                    let values = self.enumeration();
+                    file.enums.push((name, values));
+                }
+                Token::Word(w) if w == "service" => {
+                    let name = self.word();
+                    let rpcs = self.service();
+                    file.services.push((name, rpcs));
+                }
+                Token::Symbol(';') => {}
+                _ => {
+                    self.pos -= 1;
+                    self.skip_statement();
+                }
+            }
+        }
+        file
+    }
+
+    fn message(&mut self, name: &str, file: &mut ProtoFile) {
+        let mut fields = Vec::new();
+        if !self.is('{') {
+            return;
+        }
+        self.next();
+        let mut oneof_depth = 0;
+        while let Some(token) = self.next() {
+            match token {
+                Token::Symbol('}') if oneof_depth > 0 => oneof_depth -= 1,
+                Token::Symbol('}') => break,
+                Token::Symbol(';') => {}
+                Token::Word(w) if w == "message" => {
+                    let inner = self.word();
+                    self.message(&format!("{}.{}", name, inner), file);
+                }
+                Token::Word(w) if w == "enum" => {
+                    let inner = self.word();
+                    let values = self.enumeration();
+                    file.enums.push((format!("{}.{}", name, inner), values));
+                }
+                Token::Word(w) if w == "oneof" => {
+                    self.word();
+                    if self.is('{') {
+                        self.next();
+                        oneof_depth += 1;
+                    }
+                }
+                Token::Word(w) if matches!(w.as_str(), "option" | "reserved" | "extensions" | "extend") => {
+                    self.pos -= 1;
+                    self.skip_statement();
+                }
+                Token::Word(w) => {
+                    let mut ty = w;
+                    let mut repeated = false;
+                    if ty == "repeated" || ty == "optional" || ty == "required" {
+                        repeated = ty == "repeated";
+                        ty = self.word();
+                    }
+                    let mut map_value = None;
+                    if ty == "map" && self.is('<') {
+                        self.next();
+                        ty = self.word();
+                        self.next();
+                        map_value = Some(self.word());
+                        self.next();
+                    }
+                    let field = self.word();
+                    let start = self.pos;
+                    self.skip_statement();
+                    let options = &self.tokens[start..self.pos];
+                    let json = options
+                        .windows(3)
+                        .find_map(|w| match w {
+                            [Token::Word(key), Token::Symbol('='), Token::Str(value)] if key == "json_name" => Some(value.clone()),
+                            _ => None,
+                        })
+                        .unwrap_or_else(|| json_name(&field));
+                    fields.push(ProtoField { name: field, json, ty, repeated, map_value });
+                }
+                _ => {}
+            }
+        }
+        file.messages.push((name.to_string(), fields));
+    }
+
+    fn enumeration(&mut self) -> Vec<String> {
+        let mut values = Vec::new();
+        if !self.is('{') {
+            return values;
+        }
+        self.next();
+        while let Some(token) = self.next() {
+            match token {
+                Token::Symbol('}') => break,
+                Token::Word(w) if w == "option" || w == "reserved" => {
+                    self.pos -= 1;
+                    self.skip_statement();
+                }
+                Token::Word(w) => {
+                    values.push(w);
+                    self.skip_statement();
+                }
+                _ => {}
+            }
+        }
+        values
+    }
+
+    /// `(stream? Type)` of an rpc; returns the type and whether it streams.
+    fn rpc_type(&mut self) -> (String, bool) {
+        self.next();
+        let mut ty = self.word();
+        let streaming = ty == "stream";
+        if streaming {
+            ty = self.word();
+        }
+        self.next();
+        (ty, streaming)
+    }
+
+    fn service(&mut self) -> Vec<Rpc> {
+        let mut rpcs = Vec::new();
+        if !self.is('{') {
+            return rpcs;
+        }
+        self.next();
+        while let Some(token) = self.next() {
+            match token {
+                Token::Symbol('}') => break,
+                Token::Word(w) if w == "rpc" => {
+                    let name = self.word();
+                    let (request, stream_in) = self.rpc_type();
+                    self.word(); // returns
+                    let (response, stream_out) = self.rpc_type();
+                    let http = self.rpc_options();
+                    rpcs.push(Rpc { name, request, response, streaming: stream_in || stream_out, http });
+                }
+                Token::Word(_) => {
+                    self.pos -= 1;
+                    self.skip_statement();
+                }
+                _ => {}
+            }
+        }
+        rpcs
+    }
+
+    /// Body of an rpc: `;` or `{ option (google.api.http) = { get: "/v1/..." body: "*" }; }`.
+    fn rpc_options(&mut self) -> Option<(String, String, Option<String>)> {
+        if !self.is('{') {
+            self.skip_statement();
+            return None;
+        }
+        let start = self.pos;
+        self.skip_statement();
+        let block = &self.tokens[start..self.pos];
+        let http = block.iter().position(|t| matches!(t, Token::Word(w) if w == "google.api.http"))?;
+        let mut binding = None;
+        let mut body = None;
+        let mut i = http;
+        while i + 2 < block.len() {
+            if let (Token::Word(key), Token::Symbol(':'), Token::Str(value)) = (&block[i], &block[i + 1], &block[i + 2]) {
+                match key.as_str() {
+                    "get" | "put" | "post" | "delete" | "patch" if binding.is_none() => binding = Some((key.to_uppercase(), value.clone())),
+                    "body" if body.is_none() => body = Some(value.clone()),
+                    "additional_bindings" => break,
+                    _ => {}
+                }
+            }
+            i += 1;
+        }
+        binding.map(|(method, path)| (method, path, body))
+    }
+}
+
+/// Proto3 JSON name of a field (`user_id` -> `userId`).
+fn json_name(field: &str) -> String {
+    let mut out = String::new();
+    let mut upper = false;
+    for c in field.chars() {
+        if c == '_' {
+            upper = true;
+        } else if upper {
+            out.push(c.to_ascii_uppercase());
+            upper = false;
+        } else {
+            out.push(c);
+        }
+    }
+    out
+}
+
+struct ProtoTypes {
+    /// Flattened message and enum names as written (`Outer.Inner`) -> schema name.
+    known: BTreeMap<String, String>,
+}
+
+impl ProtoTypes {
+    fn schema(name: &str) -> String {
+        pascal(&name.split('.').flat_map(words).collect::<Vec<_>>())
+    }
+
+    /// Type `ty` as referenced from message `scope` in the proto JSON mapping. 64-bit integers are strings there.
+    fn resolve(&self, scope: &str, ty: &str) -> Type {
+        match ty {
+            "double" | "float" => Type::Number,
+            "int32" | "uint32" | "sint32" | "fixed32" | "sfixed32" => Type::Integer,
+            "int64" | "uint64" | "sint64" | "fixed64" | "sfixed64" | "string" | "bytes" => Type::String,
+            "bool" => Type::Boolean,
+            _ => {
+                let ty = ty.trim_start_matches('.');
+                if let Some(well_known) = ty.strip_prefix("google.protobuf.") {
+                    return match well_known {
+                        "Timestamp" | "Duration" | "FieldMask" | "StringValue" | "BytesValue" | "Int64Value" | "UInt64Value" => Type::String,
+                        "Int32Value" | "UInt32Value" => Type::Integer,
+                        "DoubleValue" | "FloatValue" => Type::Number,
+                        "BoolValue" => Type::Boolean,
+                        "Struct" => Type::Map(Box::new(Type::Any)),
+                        "ListValue" => Type::Array(Box::new(Type::Any)),
+                        "Empty" => Type::Named("Empty".to_string()),
+                        _ => Type::Any,
+                    };
+                }
+                // Names are looked up from the innermost enclosing message outwards,
+                // and a reference may be qualified by the package
+                let scopes: Vec<&str> = scope.split('.').filter(|s| !s.is_empty()).collect();
+                let parts: Vec<&str> = ty.split('.').collect();
+                (0..=scopes.len())
+                    .rev()
+                    .find_map(|n| self.known.get(&scopes[..n].iter().chain(&parts).copied().collect::<Vec<_>>().join(".")))
+                    .or_else(|| (0..parts.len()).find_map(|i| self.known.get(&parts[i..].join("."))))
+                    .map(|name| Type::Named(name.clone()))
+                    .unwrap_or(Type::Any)
+            }
+        }
+    }
+}
+
+fn parse_proto(files: &[(String, String)]) -> Api {
+    let parsed: Vec<ProtoFile> = files.iter().map(|(_, src)| ProtoParser { tokens: tokenize(src), pos: 0 }.file()).collect();
+    let mut known = BTreeMap::new();
+    for file in &parsed {
+        for name in file.messages.iter().map(|(n, _)| n).chain(file.enums.iter().map(|(n, _)| n)) {
+            known.insert(name.clone(), ProtoTypes::schema(name));
+        }
+    }
+    let types = ProtoTypes { known };
+    let mut schemas = Vec::new();
+    let mut operations = Vec::new();
+    let mut uses_empty = false;
+    for file in &parsed {
+        for (name, values) in &file.enums {
+            schemas.push(Schema { name: ProtoTypes::schema(name), shape: Shape::Enum(values.clone()) });
+        }
+        for (name, fields) in &file.messages {
+            let fields = fields
+                .iter()
+                .map(|f| {
+                    let ty = match &f.map_value {
+                        Some(value) => Type::Map(Box::new(types.resolve(name, value))),
+                        None if f.repeated => Type::Array(Box::new(types.resolve(name, &f.ty))),
+                        None => types.resolve(name, &f.ty),
+                    };
+                    Field { name: f.json.clone(), ty, required: false }
+                })
+                .collect();
+            schemas.push(Schema { name: ProtoTypes::schema(name), shape: Shape::Object(fields) });
+        }
+        for (service, rpcs) in &file.services {
+            for rpc in rpcs.iter().filter(|r| !r.streaming) {
+                let request = types.resolve("", &rpc.request);
+                let response = types.resolve("", &rpc.response);
+                uses_empty |= [&request, &response].contains(&&Type::Named("Empty".to_string()));
+                let message = parsed
+                    .iter()
+                    .flat_map(|f| &f.messages)
+                    .find(|(n, _)| Type::Named(ProtoTypes::schema(n)) == request)
+                    .map(|(n, f)| (n.as_str(), f.as_slice()));
+                operations.push(proto_operation(&file.package, service, rpc, request, response, message, &types));
+            }
+        }
+    }
+    if uses_empty && !schemas.iter().any(|s| s.name == "Empty") {
+        schemas.push(Schema { name: "Empty".to_string(), shape: Shape::Object(Vec::new()) });
+    }
+    dedupe_operations(&mut operations);
+    let source = files.iter().map(|(p, _)| p.as_str()).collect::<Vec<_>>().join(", ");
+    Api { source, schemas, operations }
+}
+
+/// HTTP call for an rpc: its google.api.http binding, or Connect's unary JSON convention
+/// (`POST /<package>.<Service>/<Method>` with the request message as body).
+fn proto_operation(
+    package: &str,
+    service: &str,
+    rpc: &Rpc,
+    request: Type,
+    response: Type,
+    message: Option<(&str, &[ProtoField])>,
+    types: &ProtoTypes,
+) -> Operation {
+    let words = words(&rpc.name);
+    let Some((method, template, body)) = &rpc.http else {
+        let qualified = if package.is_empty() { service.to_string() } else { format!("{}.{}", package, service) };
+        return Operation {
+            words,
+            method: "POST".to_string(),
+            path: format!("/{}/{}", qualified, rpc.name),
+            summary: None,
+            params: Vec::new(),
+            body: Some(request),
+            response: Some(response),
+        };
+    };
+    // `{name=shelves/*}` binds a field to the segment; only the field name matters to a client
+    let mut path = String::new();
+    let mut rest = template.as_str();
+    while let Some(open) = rest.find('{') {
+        let Some(close) = rest[open..].find('}') else { break };
+        let inner = &rest[open + 1..open + close];
+        path.push_str(&rest[..open]);
+        path.push_str(&format!("{{{}}}", json_name(inner.split('=').next().unwrap_or(inner).trim())));
+        rest = &rest[open + close + 1..];
+    }
+    path.push_str(rest);
+    let bound = path_params(&path);
+    let (scope, fields) = message.unwrap_or_default();
+    let mut params: Vec<Param> = bound
+        .iter()
+        .map(|name| {
+            let ty = match fields.iter().find(|f| f.json == *name).map(|f| types.resolve(scope, &f.ty)) {
+                Some(ty @ (Type::Integer | Type::Number | Type::Boolean)) => ty,
+                _ => Type::String,
+            };
+            Param { name: name.clone(), location: ParamIn::Path, ty, required: true }
+        })
+        .collect();
+    let body = match body.as_deref() {
+        Some("*") => Some(request),
+        Some(field) => fields.iter().find(|f| f.name == field).map(|f| types.resolve(scope, &f.ty)).or(Some(Type::Any)),
+        None => {
+            // Without a body, the remaining scalar fields go in the query string
+            for field in fields.iter().filter(|f| f.map_value.is_none() && !bound.contains(&f.json)) {
+                let ty = types.resolve(scope, &field.ty);
+                if matches!(ty, Type::String | Type::Integer | Type::Number | Type::Boolean) {
+                    let ty = if field.repeated { Type::Array(Box::new(ty)) } else { ty };
+                    params.push(Param { name: field.json.clone(), location: ParamIn::Query, ty, required: false });
+                }
+            }
+            None
+        }
+    };
+    Operation { words, method: method.clone(), path, summary: None, params, body, response: Some(response) }
+}
+
+// ---------------------------------------------------------------------------
+// Routes detected in code
+
+/// Identifier ending right before byte `end` of `line`.
+pub(crate) fn receiver(line: &str, end: usize) -> &str {
+    let before = &line[..end];
+    let start = before.rfind(|c: char| !(c.is_ascii_alphanumeric() || c == '_')).map(|i| i + 1).unwrap_or(0);
+    &before[start..]
+}
+
+/// First string literal at the start of `s` (after whitespace).
+fn leading_literal(s: &str) -> Option<&str> {
+    let s = s.trim_start();
+    let quote = s.chars().next().filter(|c| matches!(c, '"' | '\'' | '`'))?;
+    let rest = &s[1..];
+    rest.find(quote).map(|end| &rest[..end])
+}
+
+/// `:id` (gin, echo, express) and `<id>`/`<int:id>` (Flask) become `{id}`.
+pub(crate) fn normalize_route(path: &str) -> String {
+    let segments: Vec<String> = path
+        .split('/')
+        .map(|segment| {
+            if let Some(name) = segment.strip_prefix(':') {
+                format!("{{{}}}", name.trim_end_matches('?'))
+            } else if let Some(inner) = segment.strip_prefix('<').and_then(|s| s.strip_suffix('>')) {
+                format!("{{{}}}", inner.rsplit(':').next().unwrap_or(inner))
+            } else {
+                segment.to_string()
+            }
+        })
+        .collect();
+    let path = segments.join("/");
+    if path.starts_with('/') {
+        path
+    } else {
+        format!("/{}", path)
+    }
+}
+
+/// Routes registered in Go (gin, echo, chi, fiber, net/http patterns), Express and FastAPI/Flask code,
+/// as (method, path, "file:line").
+pub(crate) fn detect_routes(root: &Path) -> Vec<(String, String, String)> {
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(root, &mut files);
+    files.sort();
+    let mut routes = Vec::new();
+    for file in files {
+        let Ok(content) = fs::read_to_string(&file) else { continue };
+        let rel = file.strip_prefix(root).unwrap_or(&file).display().to_string();
+        // Go has its own analyzer; a route for any method (`http.HandleFunc`) is at least a GET
+        if file.extension().is_some_and(|e| e == "go") {
+            for route in crate::dev_routes::parse_go(&rel, &content) {
+                let method = if route.method == "ANY" { "GET".to_string() } else { route.method };
+                routes.push((method, route.path, route.location));
+            }
+            continue;
+        }
+        // Router groups: `api := r.Group("/api")`, `v1 := api.Group("/v1")`
+        let mut groups: BTreeMap<String, String> = BTreeMap::new();
+        for (i, line) in content.lines().enumerate() {
+            let code = line.trim();
+            if code.starts_with("//") || code.starts_with('#') {
+                continue;
+            }
+            if let Some(at) = code.find(".Group(") {
+                if let (Some((target, _)), Some(prefix)) = (code[..at].split_once(":="), leading_literal(&code[at + 7..])) {
+                    let parent = groups.get(receiver(code, at)).cloned().unwrap_or_default();
+                    groups.insert(target.trim().to_string(), format!("{}{}", parent, prefix));
+                }
+                continue;
+            }
+            let python = code.starts_with('@');
+            // Flask: `@app.route("/users", methods=["GET", "POST"])`, GET when no methods are given
+            if let Some(path) = code.find(".route(").filter(|_| python).and_then(|at| leading_literal(&code[at + 7..])).filter(|p| p.starts_with('/')) {
+                let methods: Vec<String> = code
+                    .split_once("methods=")
+                    .map(|(_, list)| list.split(['"', '\'']).skip(1).step_by(2).map(str::to_uppercase).collect())
+                    .unwrap_or_default();
+                for method in if methods.is_empty() { vec!["GET".to_string()] } else { methods } {
+                    routes.push((method, normalize_route(path), format!("{}:{}", rel, i + 1)));
+                }
+                continue;
+            }
+            for method in ["GET", "POST", "PUT", "PATCH", "DELETE", "Get", "Post", "Put", "Patch", "Delete", "get", "post", "put", "patch", "delete"] {
+                let needle = format!(".{}(", method);
+                let Some(at) = code.find(&needle) else { continue };
+                let Some(path) = leading_literal(&code[at + needle.len()..]) else { continue };
+                if !path.starts_with('/') {
+                    continue;
+                }
+                let prefix = if python { "" } else { groups.get(receiver(code, at)).map(String::as_str).unwrap_or("") };
+                routes.push((method.to_uppercase(), normalize_route(&format!("{}{}", prefix, path)), format!("{}:{}", rel, i + 1)));
+                break;
+            }
+        }
+    }
+    routes.sort_by(|a, b| (&a.1, &a.0).cmp(&(&b.1, &b.0)));
+    routes.dedup_by(|a, b| a.0 == b.0 && a.1 == b.1);
+    routes
+}
+
+fn routes_api(routes: Vec<(String, String, String)>) -> Api {
+    let mut operations: Vec<Operation> = routes
+        .into_iter()
+        .map(|(method, path, location)| {
+            let params = path_params(&path).into_iter().map(|name| Param { name, location: ParamIn::Path, ty: Type::String, required: true }).collect();
+            let body = matches!(method.as_str(), "POST" | "PUT" | "PATCH").then_some(Type::Any);
+            Operation { words: operation_words(&method, &path), method, path, summary: Some(location), params, body, response: Some(Type::Any) }
+        })
+        .collect();
+    dedupe_operations(&mut operations);
+    Api { source: "rotas detectadas no código".to_string(), schemas: Vec::new(), operations }
+}
+
+// ---------------------------------------------------------------------------
+// Discovery
+
+fn walk(dir: &Path, depth: usize, out: &mut Vec<PathBuf>) {
+    let Ok(entries) = fs::read_dir(dir) else { return };
+    for entry in entries.flatten() {
+        let path = entry.path();
+        let name = entry.file_name().to_string_lossy().to_string();
+        if path.is_dir() {
+            if depth < 6 && !name.starts_with('.') && !SKIP_DIRS.contains(&name.as_str()) {
+                walk(&path, depth + 1, out);
+            }
+        } else {
+            out.push(path);
+        }
+    }
+}
+
+/// Parse a JSON or YAML file into a document when it is an OpenAPI or Swagger spec.
+fn read_openapi(path: &Path) -> Option<Value> {
+    let content = fs::read_to_string(path).ok()?;
+    let doc: Value = match path.extension().and_then(|e| e.to_str()) {
+        Some("json") => serde_json::from_str(&content).ok()?,
+        // Through serde_yaml's own value: status codes are often unquoted integer keys
+        _ => serde_json::to_value(serde_yaml::from_str::<serde_yaml::Value>(&content).ok()?).ok()?,
+    };
+    (doc.get("openapi").is_some() || doc.get("swagger").is_some()).then_some(doc)
+}
+
+enum Spec {
+    OpenApi(PathBuf),
+    Proto(Vec<PathBuf>),
+}
+
+/// OpenAPI specs or `.proto` files of the project; `Err` lists ambiguous specs.
+fn discover(root: &Path) -> Result<Option<Spec>, Vec<PathBuf>> {
+    let mut files = Vec::new();
+    walk(root, 0, &mut files);
+    files.sort();
+    let specs: Vec<PathBuf> = files
+        .iter()
+        .filter(|p| matches!(p.extension().and_then(|e| e.to_str()), Some("json" | "yaml" | "yml")))
+        .filter(|p| fs::metadata(p).is_ok_and(|m| m.len() < 5_000_000))
+        .filter(|p| {
+            let name = p.file_name().and_then(|n| n.to_str()).unwrap_or("");
+            // package.json, tsconfig.json and friends are never specs; skip parsing them
+            !name.starts_with("package") && !name.starts_with("tsconfig") && read_openapi(p).is_some()
+        })
+        .cloned()
+        .collect();
+    match specs.len() {
+        1 => return Ok(specs.into_iter().next().map(Spec::OpenApi)),
+        0 => {}
+        _ => return Err(specs),
+    }
+    let protos: Vec<PathBuf> = files.into_iter().filter(|p| p.extension().is_some_and(|e| e == "proto")).collect();
+    Ok((!protos.is_empty()).then_some(Spec::Proto(protos)))
+}
+
+// ---------------------------------------------------------------------------
+// TypeScript
+
+fn ts_type(ty: &Type) -> String {
+    match ty {
+        Type::String => "string".to_string(),
+        Type::Integer | Type::Number => "number".to_string(),
+        Type::Boolean => "boolean".to_string(),
+        Type::Array(item) => match **item {
+            Type::Array(_) | Type::Map(_) => format!("Array<{}>", ts_type(item)),
+            _ => format!("{}[]", ts_type(item)),
+        },
+        Type::Map(value) => format!("Record<string, {}>", ts_type(value)),
+        Type::Named(name) => name.clone(),
+        Type::Any => "unknown".to_string(),
+    }
+}
+
+fn ts_key(name: &str) -> String {
+    let valid = name.starts_with(|c: char| c.is_ascii_alphabetic() || c == '_' || c == '$')
+        && name.chars().all(|c| c.is_ascii_alphanumeric() || c == '_' || c == '$');
+    if valid {
+        name.to_string()
+    } else {
+        format!("'{}'", name.replace('\\', "\\\\").replace('\'', "\\'"))
+    }
+}
+
+fn quote_single(s: &str) -> String {
+    format!("'{}'", s.replace('\\', "\\\\").replace('\'', "\\'"))
+}
+
+fn doc_line(op: &Operation) -> String {
+    match &op.summary {
+        Some(summary) => format!("{} {} — {}", op.method, op.path, summary),
+        None => format!("{} {}", op.method, op.path),
+    }
+}
+
+const TS_RUNTIME: &str = r#"export class ApiError extends Error {
+  readonly status: number;
+  readonly body: string;
+
+  constructor(status: number, body: string) {
+    super(`HTTP ${status}: ${body}`);
+    this.status = status;
+    this.body = body;
+  }
+}
+
+type Query = Record<string, string | number | boolean | Array<string | number | boolean> | undefined>;
+
+export class ApiClient {
+  private readonly baseUrl: string;
+  private readonly init: RequestInit;
+
+  constructor(baseUrl: string, init: RequestInit = {}) {
+    this.baseUrl = baseUrl.replace(/\/+$/, '');
+    this.init = init;
+  }
+
+  private async request<T>(method: string, path: string, query?: Query, body?: unknown, headers?: Record<string, string | undefined>): Promise<T> {
+    const params = new URLSearchParams();
+    for (const [key, value] of Object.entries(query ?? {})) {
+      if (value === undefined) continue;
+      for (const item of Array.isArray(value) ? value : [value]) params.append(key, String(item));
+    }
+    const search = params.toString();
+    const sent = new Headers(this.init.headers);
+    sent.set('Accept', 'application/json');
+    if (body !== undefined) sent.set('Content-Type', 'application/json');
+    for (const [key, value] of Object.entries(headers ?? {})) {
+      if (value !== undefined) sent.set(key, value);
+    }
+    const res = await fetch(this.baseUrl + path + (search ? `?${search}` : ''), {
+      ...this.init,
+      method,
+      headers: sent,
+      body: body !== undefined ? JSON.stringify(body) : undefined,
+    });
+    const text = await res.text();
+    if (!res.ok) throw new ApiError(res.status, text);
+    return (text ? JSON.parse(text) : undefined) as T;
+  }
+"#;
+
+fn render_ts(api: &Api) -> String {
+    let mut out = format!("// Code generated by dx generate client from {}. DO NOT EDIT.\n\n", api.source);
+    for schema in &api.schemas {
+        match &schema.shape {
+            Shape::Object(fields) if fields.is_empty() => out.push_str(&format!("export type {} = Record<string, never>;\n\n", schema.name)),
+            Shape::Object(fields) => {
+                out.push_str(&format!("export interface {} {{\n", schema.name));
+                for field in fields {
+                    out.push_str(&format!("  {}{}: {};\n", ts_key(&field.name), if field.required { "" } else { "?" }, ts_type(&field.ty)));
+                }
+                out.push_str("}\n\n");
+            }
+            Shape::Enum(values) => {
+                let values: Vec<String> = values.iter().map(|v| quote_single(v)).collect();
+                out.push_str(&format!("export type {} = {};\n\n", schema.name, values.join(" | ")));
+            }
+            Shape::Alias(ty) => out.push_str(&format!("export type {} = {};\n\n", schema.name, ts_type(ty))),
+        }
+    }
+    out.push_str(TS_RUNTIME);
+    for op in &api.operations {
+        let mut args = Vec::new();
+        let mut path = String::new();
+        let mut rest = op.path.as_str();
+        while let Some(open) = rest.find('{') {
+            let Some(close) = rest[open..].find('}') else { break };
+            let name = &rest[open + 1..open + close];
+            let arg = argument(camel(&words(name)), TS_RESERVED);
+            let ty = op.params.iter().find(|p| p.location == ParamIn::Path && p.name == name).map(|p| ts_type(&p.ty)).unwrap_or("string".into());
+            args.push(format!("{}: {}", arg, ty));
+            path.push_str(&rest[..open].replace('`', "\\`"));
+            path.push_str(&format!("${{encodeURIComponent(String({}))}}", arg));
+            rest = &rest[open + close + 1..];
+        }
+        path.push_str(&rest.replace('`', "\\`"));
+        let body = op.body.as_ref().map(ts_type);
+        if let Some(ty) = &body {
+            args.push(format!("body: {}", ty));
+        }
+        let members = |location: ParamIn| -> Vec<String> {
+            op.params
+                .iter()
+                .filter(|p| p.location == location)
+                .map(|p| format!("{}{}: {}", ts_key(&p.name), if p.required { "" } else { "?" }, if location == ParamIn::Header { "string".into() } else { ts_type(&p.ty) }))
+                .collect()
+        };
+        let (query, headers) = (members(ParamIn::Query), members(ParamIn::Header));
+        let optional = |group: &[String], location: ParamIn| {
+            if op.params.iter().any(|p| p.location == location && p.required) {
+                format!("{{ {} }}", group.join("; "))
+            } else {
+                format!("{{ {} }} = {{}}", group.join("; "))
+            }
+        };
+        if !query.is_empty() {
+            args.push(format!("query: {}", optional(&query, ParamIn::Query)));
+        }
+        if !headers.is_empty() {
+            args.push(format!("headers: {}", optional(&headers, ParamIn::Header)));
+        }
+        let result = op.response.as_ref().map(ts_type).unwrap_or("void".into());
+        let mut call = vec![quote_single(&op.method), format!("`{}`", path)];
+        let trailing = [
+            (!query.is_empty()).then(|| "query".to_string()),
+            body.as_ref().map(|_| "body".to_string()),
+            (!headers.is_empty()).then(|| "headers".to_string()),
+        ];
+        if let Some(last) = trailing.iter().rposition(Option::is_some) {
+            call.extend(trailing[..=last].iter().map(|a| a.clone().unwrap_or("undefined".into())));
+        }
+        out.push_str(&format!(
+            "\n  /** {} */\n  {}({}): Promise<{}> {{\n    return this.request<{}>({});\n  }}\n",
+            doc_line(op).replace("*/", "* /"),
+            camel(&op.words),
+            args.join(", "),
+            result,
+            result,
+            call.join(", ")
+        ));
+    }
+    out.push_str("}\n");
+    out
+}
+
+// ---------------------------------------------------------------------------
+// Go
+
+fn go_type(ty: &Type) -> String {
+    match ty {
+        Type::String => "string".to_string(),
+        Type::Integer => "int64".to_string(),
+        Type::Number => "float64".to_string(),
+        Type::Boolean => "bool".to_string(),
+        Type::Array(item) => format!("[]{}", go_type(item)),
+        Type::Map(value) => format!("map[string]{}", go_type(value)),
+        Type::Named(name) => go_pascal(&words(name)),
+        Type::Any => "any".to_string(),
+    }
+}
+
+/// Struct types are referenced through pointers, which also allows recursive types.
+fn go_ref_type(ty: &Type, api: &Api) -> String {
+    match ty {
+        Type::Named(name) if api.schemas.iter().any(|s| s.name == *name && matches!(s.shape, Shape::Object(_))) => format!("*{}", go_type(ty)),
+        _ => go_type(ty),
+    }
+}
+
+/// Lines of a struct or const block with columns aligned the way gofmt does.
+fn go_align(rows: &[Vec<String>]) -> String {
+    let columns = rows.iter().map(Vec::len).max().unwrap_or(0);
+    let widths: Vec<usize> = (0..columns).map(|c| rows.iter().filter_map(|r| r.get(c)).map(String::len).max().unwrap_or(0)).collect();
+    rows.iter()
+        .map(|row| {
+            let cells: Vec<String> =
+                row.iter().enumerate().map(|(c, cell)| if c + 1 == row.len() { cell.clone() } else { format!("{:w$}", cell, w = widths[c]) }).collect();
+            format!("\t{}\n", cells.join(" "))
+        })
+        .collect()
+}
+
+fn go_quote(s: &str) -> String {
+    serde_json::to_string(s).unwrap_or_default()
+}
+
+const GO_RUNTIME: &str = r#"// APIError is returned for responses outside the 2xx range.
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body)
+}
+
+// Client calls the API at BaseURL.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+	// Header is sent with every request (e.g. Authorization).
+	Header http.Header
+}
+
+// New returns a Client for the API at baseURL.
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), HTTPClient: http.DefaultClient, Header: http.Header{}}
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, header http.Header, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	target := c.BaseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	for key, values := range c.Header {
+		req.Header[key] = values
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		data, _ := io.ReadAll(resp.Body)
+		return &APIError{StatusCode: resp.StatusCode, Body: string(data)}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+"#;
+
+/// Go package name for the output directory (`clients/go-api` -> `goapi`).
+fn go_package(out_dir: &Path) -> String {
+    let base = out_dir.file_name().and_then(|n| n.to_str()).unwrap_or("client");
+    let name: String = base.chars().filter(|c| c.is_ascii_alphanumeric()).collect::<String>().to_lowercase();
+    match name.as_str() {
+        "" | "go" => "client".to_string(),
+        _ => identifier(name, "client"),
+    }
+}
+
+fn render_go(api: &Api, package: &str) -> String {
+    let mut out = format!(
+        "// Code generated by dx generate client from {}. DO NOT EDIT.\n\npackage {}\n\nimport (\n\t\"bytes\"\n\t\"context\"\n\t\"encoding/json\"\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n\t\"net/url\"\n\t\"strings\"\n)\n\n",
+        api.source, package
+    );
+    for schema in &api.schemas {
+        let name = go_pascal(&words(&schema.name));
+        match &schema.shape {
+            Shape::Object(fields) => {
+                let mut taken = BTreeSet::new();
+                let mut rows = Vec::new();
+                for field in fields {
+                    let mut ident = go_pascal(&words(&field.name));
+                    while !taken.insert(ident.clone()) {
+                        ident.push('_');
+                    }
+                    let tag = if field.required { field.name.clone() } else { format!("{},omitempty", field.name) };
+                    rows.push(vec![ident, go_ref_type(&field.ty, api), format!("`json:{}`", go_quote(&tag))]);
+                }
+                match rows.is_empty() {
+                    true => out.push_str(&format!("type {} struct{{}}\n\n", name)),
+                    false => out.push_str(&format!("type {} struct {{\n{}}}\n\n", name, go_align(&rows))),
+                }
+            }
+            Shape::Enum(values) => {
+                let mut taken = BTreeSet::new();
+                let mut rows = Vec::new();
+                for value in values {
+                    let mut ident = go_pascal(&words(&format!("{} {}", name, value)));
+                    while !taken.insert(ident.clone()) {
+                        ident.push('_');
+                    }
+                    rows.push(vec![ident, name.clone(), format!("= {}", go_quote(value))]);
+                }
+                out.push_str(&format!("type {} string\n\nconst (\n{})\n\n", name, go_align(&rows)));
+            }
+            Shape::Alias(ty) => out.push_str(&format!("type {} = {}\n\n", name, go_type(ty))),
+        }
+    }
+    // Query and header parameters of an operation go in a <Operation>Params struct
+    let mut methods = String::new();
+    for op in &api.operations {
+        let op_name = go_pascal(&op.words);
+        let mut args = vec!["ctx context.Context".to_string()];
+        let mut path = Vec::new();
+        let mut rest = op.path.as_str();
+        while let Some(open) = rest.find('{') {
+            let Some(close) = rest[open..].find('}') else { break };
+            let name = &rest[open + 1..open + close];
+            let arg = argument(go_camel(&words(name)), GO_RESERVED);
+            let param = op.params.iter().find(|p| p.location == ParamIn::Path && p.name == name);
+            let ty = param.map(|p| go_type(&p.ty)).filter(|t| matches!(t.as_str(), "int64" | "float64" | "bool")).unwrap_or("string".into());
+            if !rest[..open].is_empty() {
+                path.push(go_quote(&rest[..open]));
+            }
+            if ty == "string" {
+                path.push(format!("url.PathEscape({})", arg));
+            } else {
+                path.push(format!("url.PathEscape(fmt.Sprint({}))", arg));
+            }
+            args.push(format!("{} {}", arg, ty));
+            rest = &rest[open + close + 1..];
+        }
+        if !rest.is_empty() || path.is_empty() {
+            path.push(go_quote(rest));
+        }
+        if let Some(body) = &op.body {
+            args.push(format!("body {}", go_ref_type(body, api)));
+        }
+        let extra: Vec<&Param> = op.params.iter().filter(|p| p.location != ParamIn::Path).collect();
+        let mut setup = String::new();
+        let (mut query, mut header) = ("nil", "nil");
+        if !extra.is_empty() {
+            let params_type = format!("{}Params", op_name);
+            let required = extra.iter().any(|p| p.required);
+            let mut rows = Vec::new();
+            let mut fill = String::new();
+            let mut taken = BTreeSet::new();
+            for param in &extra {
+                let mut ident = go_pascal(&words(&param.name));
+                while !taken.insert(ident.clone()) {
+                    ident.push('_');
+                }
+                let target = if param.location == ParamIn::Query { "q" } else { "h" };
+                let key = go_quote(&param.name);
+                match &param.ty {
+                    Type::Array(item) => {
+                        let value = if matches!(**item, Type::String) { "v".to_string() } else { "fmt.Sprint(v)".to_string() };
+                        rows.push(vec![ident.clone(), go_type(&param.ty)]);
+                        fill.push_str(&format!("\t\tfor _, v := range params.{} {{\n\t\t\t{}.Add({}, {})\n\t\t}}\n", ident, target, key, value));
+                    }
+                    ty if param.required => {
+                        let value = if matches!(ty, Type::String) { format!("params.{}", ident) } else { format!("fmt.Sprint(params.{})", ident) };
+                        rows.push(vec![ident.clone(), go_type(ty)]);
+                        fill.push_str(&format!("\t\t{}.Set({}, {})\n", target, key, value));
+                    }
+                    ty => {
+                        let value = if matches!(ty, Type::String) { format!("*params.{}", ident) } else { format!("fmt.Sprint(*params.{})", ident) };
+                        rows.push(vec![ident.clone(), format!("*{}", go_type(ty))]);
+                        fill.push_str(&format!("\t\tif params.{} != nil {{\n\t\t\t{}.Set({}, {})\n\t\t}}\n", ident, target, key, value));
+                    }
+                }
+            }
+            out.push_str(&format!(
+                "// {} holds the query and header parameters of {}.\ntype {} struct {{\n{}}}\n\n",
+                params_type,
+                op_name,
+                params_type,
+                go_align(&rows)
+            ));
+            if extra.iter().any(|p| p.location == ParamIn::Query) {
+                setup.push_str("\tq := url.Values{}\n");
+                query = "q";
+            }
+            if extra.iter().any(|p| p.location == ParamIn::Header) {
+                setup.push_str("\th := http.Header{}\n");
+                header = "h";
+            }
+            if required {
+                args.push(format!("params {}", params_type));
+                setup.push_str(&fill.lines().map(|l| format!("{}\n", &l[1..])).collect::<String>());
+            } else {
+                args.push(format!("params *{}", params_type));
+                setup.push_str(&format!("\tif params != nil {{\n{}\t}}\n", fill));
+            }
+        }
+        let body_arg = if op.body.is_some() { "body" } else { "nil" };
+        let call = format!("c.do(ctx, {}, {}, {}, {}, {}", go_quote(&op.method), path.join("+"), query, header, body_arg);
+        let (result, tail) = match &op.response {
+            None => ("error".to_string(), format!("\treturn {}, nil)\n", call)),
+            Some(ty) => {
+                let ty = go_ref_type(ty, api);
+                match ty.strip_prefix('*') {
+                    Some(inner) => (
+                        format!("({}, error)", ty),
+                        format!("\tvar out {}\n\tif err := {}, &out); err != nil {{\n\t\treturn nil, err\n\t}}\n\treturn &out, nil\n", inner, call),
+                    ),
+                    None => (format!("({}, error)", ty), format!("\tvar out {}\n\terr := {}, &out)\n\treturn out, err\n", ty, call)),
+                }
+            }
+        };
+        methods.push_str(&format!("// {} calls {}.\nfunc (c *Client) {}({}) {} {{\n{}{}}}\n\n", op_name, doc_line(op), op_name, args.join(", "), result, setup, tail));
+    }
+    out.push_str(GO_RUNTIME);
+    if !methods.is_empty() {
+        out.push('\n');
+        out.push_str(methods.trim_end());
+        out.push('\n');
+    }
+    out
+}
+
+// ---------------------------------------------------------------------------
+// Python
+
+fn py_type(ty: &Type, quoted: bool) -> String {
+    match ty {
+        Type::String => "str".to_string(),
+        Type::Integer => "int".to_string(),
+        Type::Number => "float".to_string(),
+        Type::Boolean => "bool".to_string(),
+        Type::Array(item) => format!("List[{}]", py_type(item, quoted)),
+        Type::Map(value) => format!("Dict[str, {}]", py_type(value, quoted)),
+        Type::Named(name) if quoted => format!("\"{}\"", name),
+        Type::Named(name) => name.clone(),
+        Type::Any => "Any".to_string(),
+    }
+}
+
+fn py_identifier(name: &str) -> bool {
+    name.starts_with(|c: char| c.is_ascii_alphabetic() || c == '_')
+        && name.chars().all(|c| c.is_ascii_alphanumeric() || c == '_')
+        && !PY_KEYWORDS.contains(&name)
+}
+
+const PY_RUNTIME: &str = r#"class ApiError(Exception):
+    def __init__(self, status: int, body: str):
+        super().__init__(f"HTTP {status}: {body}")
+        self.status = status
+        self.body = body
+
+
+class ApiClient:
+    def __init__(self, base_url: str, headers: Optional[Dict[str, str]] = None, timeout: float = 30):
+        self.base_url = base_url.rstrip("/")
+        self.headers = dict(headers or {})
+        self.timeout = timeout
+
+    def _request(self, method: str, path: str, query: Optional[Dict[str, Any]] = None, body: Any = None, headers: Optional[Dict[str, Any]] = None) -> Any:
+        params = []
+        for key, value in (query or {}).items():
+            for item in value if isinstance(value, list) else [value]:
+                if item is not None:
+                    params.append((key, str(item).lower() if isinstance(item, bool) else str(item)))
+        url = self.base_url + path + ("?" + urllib.parse.urlencode(params) if params else "")
+        data = None if body is None else json.dumps(body).encode()
+        request = urllib.request.Request(url, data=data, method=method)
+        request.add_header("Accept", "application/json")
+        if data is not None:
+            request.add_header("Content-Type", "application/json")
+        for key, value in {**self.headers, **(headers or {})}.items():
+            if value is not None:
+                request.add_header(key, str(value))
+        try:
+            with urllib.request.urlopen(request, timeout=self.timeout) as response:
+                text = response.read().decode()
+        except urllib.error.HTTPError as error:
+            raise ApiError(error.code, error.read().decode()) from None
+        return json.loads(text) if text else None
+"#;
+
+fn render_python(api: &Api) -> String {
+    let mut out = format!(
+        "# Code generated by dx generate client from {}. DO NOT EDIT.\n\nfrom __future__ import annotations\n\nimport json\nimport urllib.error\nimport urllib.parse\nimport urllib.request\nfrom typing import Any, Dict, List, Literal, Optional, TypedDict\n\n",
+        api.source
+    );
+    // Classes first: aliases may point at them and are evaluated at import time
+    for schema in &api.schemas {
+        let Shape::Object(fields) = &schema.shape else { continue };
+        let typed: Vec<(String, String)> = fields
+            .iter()
+            .map(|f| (f.name.clone(), if f.required { py_type(&f.ty, false) } else { format!("Optional[{}]", py_type(&f.ty, false)) }))
+            .collect();
+        if fields.iter().all(|f| py_identifier(&f.name)) {
+            out.push_str(&format!("\nclass {}(TypedDict, total=False):\n", schema.name));
+            if typed.is_empty() {
+                out.push_str("    pass\n");
+            }
+            for (name, ty) in typed {
+                out.push_str(&format!("    {}: {}\n", name, ty));
+            }
+            out.push('\n');
+        } else {
+            // Keys that are not identifiers need the functional syntax
+            let typed: Vec<(String, String)> = fields
+                .iter()
+                .map(|f| (f.name.clone(), if f.required { py_type(&f.ty, true) } else { format!("Optional[{}]", py_type(&f.ty, true)) }))
+                .collect();
+            let entries: Vec<String> = typed.iter().map(|(n, t)| format!("{}: {}", serde_json::to_string(n).unwrap_or_default(), t)).collect();
+            out.push_str(&format!("\n{} = TypedDict(\"{}\", {{{}}}, total=False)\n\n", schema.name, schema.name, entries.join(", ")));
+        }
+    }
+    for schema in &api.schemas {
+        match &schema.shape {
+            Shape::Enum(values) => {
+                let values: Vec<String> = values.iter().map(|v| serde_json::to_string(v).unwrap_or_default()).collect();
+                out.push_str(&format!("{} = Literal[{}]\n", schema.name, values.join(", ")));
+            }
+            Shape::Alias(ty) => out.push_str(&format!("{} = {}\n", schema.name, py_type(ty, true))),
+            Shape::Object(_) => {}
+        }
+    }
+    out.push_str("\n\n");
+    out.push_str(PY_RUNTIME);
+    for op in &api.operations {
+        let mut args = vec!["self".to_string()];
+        let mut optional_args = Vec::new();
+        let mut path = String::new();
+        let mut rest = op.path.as_str();
+        while let Some(open) = rest.find('{') {
+            let Some(close) = rest[open..].find('}') else { break };
+            let name = &rest[open + 1..open + close];
+            let arg = py_argument(snake(&words(name)));
+            let ty = op.params.iter().find(|p| p.location == ParamIn::Path && p.name == name).map(|p| py_type(&p.ty, false)).unwrap_or("str".into());
+            args.push(format!("{}: {}", arg, ty));
+            path.push_str(&rest[..open].replace('{', "{{").replace('}', "}}"));
+            path.push_str(&format!("{{urllib.parse.quote(str({}), safe='')}}", arg));
+            rest = &rest[open + close + 1..];
+        }
+        path.push_str(&rest.replace('{', "{{").replace('}', "}}"));
+        if let Some(body) = &op.body {
+            args.push(format!("body: {}", py_type(body, false)));
+        }
+        let mut groups = Vec::new();
+        for location in [ParamIn::Query, ParamIn::Header] {
+            let params: Vec<&Param> = op.params.iter().filter(|p| p.location == location).collect();
+            if params.is_empty() {
+                groups.push(None);
+                continue;
+            }
+            let mut entries = Vec::new();
+            for param in &params {
+                let arg = py_argument(snake(&words(&param.name)));
+                let ty = if location == ParamIn::Header { "str".to_string() } else { py_type(&param.ty, false) };
+                if param.required {
+                    args.push(format!("{}: {}", arg, ty));
+                } else {
+                    optional_args.push(format!("{}: Optional[{}] = None", arg, ty));
+                }
+                entries.push(format!("{}: {}", serde_json::to_string(&param.name).unwrap_or_default(), arg));
+            }
+            groups.push(Some(format!("{{{}}}", entries.join(", "))));
+        }
+        args.extend(optional_args);
+        let result = op.response.as_ref().map(|t| py_type(t, false)).unwrap_or("None".into());
+        let mut call = vec![format!("\"{}\"", op.method), format!("{}\"{}\"", if op.params.iter().any(|p| p.location == ParamIn::Path) { "f" } else { "" }, path.replace('"', "\\\""))];
+        let trailing = [groups[0].clone(), op.body.as_ref().map(|_| "body".to_string()), groups[1].clone()];
+        if let Some(last) = trailing.iter().rposition(Option::is_some) {
+            call.extend(trailing[..=last].iter().map(|a| a.clone().unwrap_or("None".into())));
+        }
+        let ret = if op.response.is_some() { "return " } else { "" };
+        out.push_str(&format!(
+            "\n    def {}({}) -> {}:\n        \"\"\"{}\"\"\"\n        {}self._request({})\n",
+            py_argument(snake(&op.words)),
+            args.join(", "),
+            result,
+            doc_line(op).replace('\\', "\\\\").replace("\"\"\"", "\\\"\\\"\\\""),
+            ret,
+            call.join(", ")
+        ));
+    }
+    out
+}
+
+// ---------------------------------------------------------------------------
+// Command
+
+/// Files of the client in `out_dir`, relative to it.
+fn render(api: &Api, lang: ClientLang, out_dir: &Path) -> Vec<(&'static str, String)> {
+    match lang {
+        ClientLang::Ts => vec![("client.ts", render_ts(api))],
+        ClientLang::Go => vec![("client.go", render_go(api, &go_package(out_dir)))],
+        ClientLang::Python => vec![
+            ("__init__.py", "# Code generated by dx generate client. DO NOT EDIT.\n\nfrom .client import *  # noqa: F401,F403\n".to_string()),
+            ("client.py", render_python(api)),
+        ],
+    }
+}
+
+fn load(root: &Path, spec: Option<PathBuf>) -> Result<Api, String> {
+    let rel = |p: &Path| p.strip_prefix(root).unwrap_or(p).display().to_string();
+    let spec = match spec {
+        Some(path) => {
+            let path = if path.is_absolute() { path } else { root.join(path) };
+            if path.extension().is_some_and(|e| e == "proto") {
+                Some(Spec::Proto(vec![path]))
+            } else if path.is_file() {
+                Some(Spec::OpenApi(path))
+            } else {
+                return Err(format!("{} não existe.", path.display()));
+            }
+        }
+        None => discover(root).map_err(|specs| {
+            let names: Vec<String> = specs.iter().map(|p| rel(p)).collect();
+            format!("Há mais de uma especificação OpenAPI no projeto ({}); escolha uma com --spec.", names.join(", "))
+        })?,
+    };
+    match spec {
+        Some(Spec::OpenApi(path)) => {
+            let doc = read_openapi(&path).ok_or_else(|| format!("{} não é uma especificação OpenAPI/Swagger válida.", rel(&path)))?;
+            Ok(OpenApi::new(&doc).parse(rel(&path)))
+        }
+        Some(Spec::Proto(paths)) => {
+            let files: Vec<(String, String)> = paths
+                .iter()
+                .map(|p| fs::read_to_string(p).map(|c| (rel(p), c)).map_err(|e| format!("Erro ao ler {}: {}", rel(p), e)))
+                .collect::<Result<_, _>>()?;
+            Ok(parse_proto(&files))
+        }
+        None => {
+            let routes = detect_routes(root);
+            if routes.is_empty() {
+                return Err("Nenhuma especificação OpenAPI, arquivo .proto ou rota HTTP encontrada no projeto; indique uma com --spec.".to_string());
+            }
+            Ok(routes_api(routes))
+        }
+    }
+}
+
+/// `dx generate client`: write a typed client for the API of the project at `dir` into `out`.
+/// With `check`, only compares it with the files there and fails when they are out of date.
+pub fn cmd_client(lang: ClientLang, spec: Option<PathBuf>, out: Option<PathBuf>, check: bool, dir: Option<PathBuf>) -> i32 {
+    let root = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let api = match load(&root, spec) {
+        Ok(api) => api,
+        Err(e) => {
+            eprintln!("{}", e);
+            return 2;
+        }
+    };
+    if api.operations.is_empty() {
+        eprintln!("{} não tem operações para gerar o cliente.", api.source);
+        return 2;
+    }
+    let default_out = match lang {
+        ClientLang::Ts => "clients/ts",
+        ClientLang::Go => "clients/go",
+        ClientLang::Python => "clients/python",
+    };
+    let out_dir = out.unwrap_or_else(|| PathBuf::from(default_out));
+    let out_dir = if out_dir.is_absolute() { out_dir } else { root.join(out_dir) };
+    let shown = out_dir.strip_prefix(&root).unwrap_or(&out_dir).display().to_string();
+    let files = render(&api, lang, &out_dir);
+
+    let mut stale = Vec::new();
+    for (file, content) in &files {
+        let path = out_dir.join(file);
+        if fs::read_to_string(&path).ok().as_deref() != Some(content.as_str()) {
+            stale.push((path, content));
+        }
+    }
+    if check {
+        if stale.is_empty() {
+            println!("✓ Cliente {} em {} em dia com {}.", lang.label(), shown, api.source);
+            return 0;
+        }
+        println!("✗ Cliente {} em {} desatualizado em relação a {}:", lang.label(), shown, api.source);
+        for (path, _) in &stale {
+            println!("  ~ {}", path.strip_prefix(&root).unwrap_or(path).display());
+        }
+        println!("Rode `dx generate client --lang {}` para atualizá-lo.", format!("{:?}", lang).to_lowercase());
+        return 1;
+    }
+    println!(
+        "Cliente {} para {} ({} operações, {} tipos) em {}:",
+        lang.label(),
+        api.source,
+        api.operations.len(),
+        api.schemas.len(),
+        shown
+    );
+    for (file, _) in &files {
+        let path = out_dir.join(file);
+        let Some((_, content)) = stale.iter().find(|(p, _)| *p == path) else {
+            println!("  = {}", path.strip_prefix(&root).unwrap_or(&path).display());
+            continue;
+        };
+        let written = fs::create_dir_all(&out_dir).and_then(|_| crate::audit::write(&path, content));
+        if let Err(e) = written {
+            eprintln!("Erro ao escrever {}: {}", path.display(), e);
+            return 1;
+        }
+        println!("  + {}", path.strip_prefix(&root).unwrap_or(&path).display());
+    }
+    0
+}
+
@@ -0,0 +1,533 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `dx generate asyncapi`: an AsyncAPI 3.0 document of the project's message-driven interfaces —
+//! the Kafka topics and RabbitMQ (AMQP) queues and exchanges it sends to and receives from, with
+//! message payloads taken from the event models of `dx dev-kafka events`.
+
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use serde::Serialize;
+use serde_json::{json, Value};
+
+use crate::kafka_events::Catalog;
+
+/// Spring, kafkajs, kafka-python, kafka-go and confluent-kafka subscriptions followed by topic
+/// name literals.
+const KAFKA_CONSUMER_PATTERNS: &[&str] = &[
+    "@KafkaListener(topics =",
+    "@KafkaListener(topics=",
+    "subscribe({ topic:",
+    "subscribe({ topics:",
+    "subscribe({topic:",
+    "subscribe({topics:",
+    "KafkaConsumer(",
+    "SubscribeTopics([]string{",
+];
+
+/// Files talking to RabbitMQ: amqp091-go, Spring AMQP, amqplib and pika.
+const AMQP_MARKERS: &[&str] = &["amqp", "rabbit", "pika"];
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord)]
+enum Action {
+    Send,
+    Receive,
+}
+
+impl Action {
+    fn as_str(self) -> &'static str {
+        match self {
+            Action::Send => "send",
+            Action::Receive => "receive",
+        }
+    }
+}
+
+/// A place messages go through, as the code uses it.
+#[derive(Debug, Clone, PartialEq, Eq, PartialOrd, Ord)]
+enum Address {
+    Topic(String),
+    /// A queue, reached through the default exchange
+    Queue(String),
+    /// A routing key of an exchange
+    RoutingKey { exchange: String, key: String },
+}
+
+impl Address {
+    fn address(&self) -> &str {
+        match self {
+            Address::Topic(name) | Address::Queue(name) => name,
+            Address::RoutingKey { exchange, key } if key.is_empty() => exchange,
+            Address::RoutingKey { key, .. } => key,
+        }
+    }
+
+    /// Channel key: the address, with what AsyncAPI ids do not allow replaced.
+    fn id(&self) -> String {
+        let id: String = self.address().chars().map(|c| if c.is_ascii_alphanumeric() || matches!(c, '.' | '-' | '_') { c } else { '-' }).collect();
+        match self {
+            Address::RoutingKey { exchange, key } if !key.is_empty() => format!("{}.{}", exchange, id),
+            _ => id,
+        }
+    }
+}
+
+/// A send or receive found in the code, with the payload types it carries.
+#[derive(Debug, Clone)]
+struct Usage {
+    action: Action,
+    address: Address,
+    messages: BTreeSet<String>,
+}
+
+#[derive(Serialize)]
+struct Document {
+    asyncapi: &'static str,
+    info: Info,
+    #[serde(skip_serializing_if = "BTreeMap::is_empty")]
+    servers: BTreeMap<String, Server>,
+    channels: BTreeMap<String, Channel>,
+    operations: BTreeMap<String, Operation>,
+    components: Components,
+}
+
+#[derive(Serialize)]
+struct Info {
+    title: String,
+    version: String,
+    description: String,
+}
+
+#[derive(Serialize)]
+struct Server {
+    host: String,
+    protocol: &'static str,
+    description: &'static str,
+}
+
+#[derive(Serialize)]
+struct Channel {
+    address: String,
+    #[serde(skip_serializing_if = "BTreeMap::is_empty")]
+    messages: BTreeMap<String, Value>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    bindings: Option<Value>,
+}
+
+#[derive(Serialize)]
+struct Operation {
+    action: &'static str,
+    channel: Value,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    messages: Vec<Value>,
+}
+
+#[derive(Serialize)]
+struct Components {
+    messages: BTreeMap<String, Message>,
+    schemas: BTreeMap<String, Value>,
+}
+
+#[derive(Serialize)]
+#[serde(rename_all = "camelCase")]
+struct Message {
+    name: String,
+    content_type: &'static str,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    summary: Option<String>,
+    payload: Value,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    headers: Option<Value>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    bindings: Option<Value>,
+}
+
+/// Arguments of the call whose `(` was just consumed: the text up to the matching `)`, split on
+/// top-level commas (also used by `dx dev-routes openapi`).
+pub(crate) fn call_args(rest: &str) -> Vec<&str> {
+    let (mut args, mut depth, mut start) = (Vec::new(), 0, 0);
+    let mut quote = None;
+    for (i, c) in rest.char_indices() {
+        match (quote, c) {
+            (Some(q), _) if c == q => quote = None,
+            (Some(_), _) => {}
+            (None, '"' | '\'' | '`') => quote = Some(c),
+            (None, '(' | '[' | '{') => depth += 1,
+            (None, ')' | ']' | '}') if depth == 0 => {
+                args.push(rest[start..i].trim());
+                return args;
+            }
+            (None, ')' | ']' | '}') => depth -= 1,
+            (None, ',') if depth == 0 => {
+                args.push(rest[start..i].trim());
+                start = i + 1;
+            }
+            _ => {}
+        }
+    }
+    args
+}
+
+/// The string literal an argument is (`"orders"`, `'orders'`, `queue='orders'`), if it is one.
+fn literal_arg(arg: &str) -> Option<String> {
+    let value = match arg.split_once('=') {
+        Some((name, value)) if name.trim().chars().all(|c| c.is_ascii_alphanumeric() || c == '_') => value,
+        _ => arg,
+    };
+    crate::dev_kafka::literals_after(value).into_iter().next().filter(|_| {
+        let value = value.trim();
+        value.len() >= 2 && value.ends_with(&value[..1])
+    })
+}
+
+/// A Python keyword argument (`routing_key='orders'`) or, failing that, the positional one.
+fn python_arg(args: &[&str], name: &str, position: usize) -> Option<String> {
+    args.iter()
+        .find(|a| a.split_once('=').is_some_and(|(n, _)| n.trim() == name))
+        .or_else(|| args.iter().filter(|a| !a.contains('=')).nth(position))
+        .and_then(|a| literal_arg(a))
+}
+
+/// Types of the first parameter of the method following `at` (a listener annotation).
+fn listener_payload(content: &str, at: usize) -> Option<String> {
+    let rest = &content[at..];
+    let annotation_end = rest.find(')')?;
+    let method = &rest[annotation_end + 1..];
+    let params = &method[method.find('(')? + 1..];
+    let first = call_args(params).into_iter().next()?;
+    let words: Vec<&str> = first.split_whitespace().filter(|w| !w.starts_with('@')).collect();
+    let ty = words.len().checked_sub(2).map(|i| words[i])?;
+    let ty = ty.split('<').next().unwrap_or(ty);
+    ty.starts_with(|c: char| c.is_ascii_uppercase()).then(|| ty.to_string())
+}
+
+/// RabbitMQ sends and receives of a file, and the exchanges it declares with their types.
+fn amqp_usages(content: &str, payloads: &BTreeSet<String>, exchanges: &mut BTreeMap<String, String>, usages: &mut Vec<Usage>) {
+    let mut add = |action: Action, address: Address, messages: BTreeSet<String>| {
+        if !address.address().is_empty() {
+            usages.push(Usage { action, address, messages });
+        }
+    };
+    let calls = |pattern: &'static str| content.match_indices(pattern).map(move |(at, _)| (at, call_args(&content[at + pattern.len()..])));
+
+    // Sends. amqp091-go: `Publish(exchange, key, ...)` and `PublishWithContext(ctx, exchange, key, ...)`
+    for (pattern, first) in [(".Publish(", 0), (".PublishWithContext(", 1)] {
+        for (_, args) in calls(pattern) {
+            if let (Some(exchange), Some(key)) = (args.get(first).and_then(|a| literal_arg(a)), args.get(first + 1).and_then(|a| literal_arg(a))) {
+                let address = if exchange.is_empty() { Address::Queue(key) } else { Address::RoutingKey { exchange, key } };
+                add(Action::Send, address, payloads.clone());
+            }
+        }
+    }
+    // Spring's RabbitTemplate: `convertAndSend([exchange,] key, payload)`
+    for (_, args) in calls(".convertAndSend(") {
+        let literals: Vec<Option<String>> = args.iter().map(|a| literal_arg(a)).collect();
+        let address = match (args.len(), literals.as_slice()) {
+            (3.., [Some(exchange), Some(key), ..]) => Address::RoutingKey { exchange: exchange.clone(), key: key.clone() },
+            (2, [Some(queue), ..]) => Address::Queue(queue.clone()),
+            _ => continue,
+        };
+        add(Action::Send, address, payloads.clone());
+    }
+    // amqplib: `sendToQueue(queue, content)` and `publish(exchange, key, content)`
+    for (_, args) in calls(".sendToQueue(") {
+        if let Some(queue) = args.first().and_then(|a| literal_arg(a)) {
+            add(Action::Send, Address::Queue(queue), payloads.clone());
+        }
+    }
+    for (_, args) in calls(".publish(") {
+        if let (Some(exchange), Some(key)) = (args.first().and_then(|a| literal_arg(a)), args.get(1).and_then(|a| literal_arg(a))) {
+            add(Action::Send, Address::RoutingKey { exchange, key }, payloads.clone());
+        }
+    }
+    // pika
+    for (_, args) in calls(".basic_publish(") {
+        let (exchange, key) = (python_arg(&args, "exchange", 0).unwrap_or_default(), python_arg(&args, "routing_key", 1));
+        if let Some(key) = key {
+            let address = if exchange.is_empty() { Address::Queue(key) } else { Address::RoutingKey { exchange, key } };
+            add(Action::Send, address, payloads.clone());
+        }
+    }
+
+    // Receives
+    for pattern in [".Consume(", ".consume("] {
+        for (_, args) in calls(pattern) {
+            if let Some(queue) = args.first().and_then(|a| literal_arg(a)) {
+                add(Action::Receive, Address::Queue(queue), BTreeSet::new());
+            }
+        }
+    }
+    for (_, args) in calls(".basic_consume(") {
+        if let Some(queue) = python_arg(&args, "queue", 0) {
+            add(Action::Receive, Address::Queue(queue), BTreeSet::new());
+        }
+    }
+    for (at, _) in content.match_indices("@RabbitListener(") {
+        let rest = &content[at..];
+        let Some(queues) = rest.find("queues").map(|i| &rest[i + 6..]) else { continue };
+        let messages: BTreeSet<String> = listener_payload(content, at).into_iter().collect();
+        for queue in crate::dev_kafka::literals_after(queues.trim_start().trim_start_matches('=')) {
+            add(Action::Receive, Address::Queue(queue), messages.clone());
+        }
+    }
+
+    // Exchange declarations, for the type of the exchange bindings
+    for (_, args) in calls(".ExchangeDeclare(").chain(calls(".assertExchange(")) {
+        if let (Some(name), Some(kind)) = (args.first().and_then(|a| literal_arg(a)), args.get(1).and_then(|a| literal_arg(a))) {
+            exchanges.insert(name, kind);
+        }
+    }
+    for (_, args) in calls(".exchange_declare(") {
+        if let (Some(name), Some(kind)) = (python_arg(&args, "exchange", 0), python_arg(&args, "exchange_type", 1)) {
+            exchanges.insert(name, kind);
+        }
+    }
+    for (kind, pattern) in [("topic", "new TopicExchange("), ("direct", "new DirectExchange("), ("fanout", "new FanoutExchange("), ("headers", "new HeadersExchange(")] {
+        for (_, args) in calls(pattern) {
+            if let Some(name) = args.first().and_then(|a| literal_arg(a)) {
+                exchanges.insert(name, kind.to_string());
+            }
+        }
+    }
+}
+
+/// Topics the project consumes, with the payload types of Spring listeners. A kafka-go reader whose
+/// topic is not a literal reads `single`, the only topic of the project (dead-letter topics aside),
+/// when there is one.
+fn kafka_receives(content: &str, single: Option<&String>, usages: &mut Vec<Usage>) {
+    for pattern in KAFKA_CONSUMER_PATTERNS {
+        for (at, _) in content.match_indices(pattern) {
+            let messages: BTreeSet<String> = match pattern.starts_with('@') {
+                true => listener_payload(content, at).into_iter().collect(),
+                false => BTreeSet::new(),
+            };
+            for topic in crate::dev_kafka::literals_after(&content[at + pattern.len()..]) {
+                usages.push(Usage { action: Action::Receive, address: Address::Topic(topic), messages: messages.clone() });
+            }
+        }
+    }
+    // kafka-go readers: the literal topic of a `kafka.ReaderConfig{...}`
+    for (at, pattern) in content.match_indices("ReaderConfig{") {
+        let body = &content[at + pattern.len()..];
+        let body = &body[..body.find("\n}").or_else(|| body.find('}')).unwrap_or(body.len())];
+        let topic = body.find("Topic:").and_then(|i| match crate::dev_kafka::literals_after(&body[i + 6..]).into_iter().next() {
+            Some(literal) => Some(literal),
+            None => single.cloned(),
+        });
+        if let Some(topic) = topic {
+            usages.push(Usage { action: Action::Receive, address: Address::Topic(topic), messages: BTreeSet::new() });
+        }
+    }
+}
+
+/// Host of the RabbitMQ server: the URL in an `*AMQP*`/`*RABBIT*` variable (value or code default).
+fn amqp_host(root: &Path) -> String {
+    let url = crate::dev_env::scan(root)
+        .into_iter()
+        .filter(|v| v.name.contains("AMQP") || v.name.contains("RABBIT"))
+        .find_map(|v| std::env::var(&v.name).ok().or(v.default).filter(|d| d.contains("://")));
+    url.and_then(|url| {
+        let rest = url.split_once("://")?.1;
+        let host = rest.rsplit_once('@').map_or(rest, |(_, h)| h);
+        Some(host.split('/').next().unwrap_or(host).to_string())
+    })
+    .filter(|h| !h.is_empty())
+    .unwrap_or_else(|| "localhost:5672".to_string())
+}
+
+fn camel(address: &str) -> String {
+    address
+        .split(|c: char| !c.is_ascii_alphanumeric())
+        .filter(|w| !w.is_empty())
+        .map(|w| w[..1].to_uppercase() + &w[1..])
+        .collect()
+}
+
+/// The AsyncAPI document of the project at `root`.
+fn document(root: &Path) -> Document {
+    let catalog = Catalog::load(root);
+    let mut usages = Vec::new();
+
+    // Kafka: cataloged events on their topics, detected topics and consumers
+    for event in &catalog.events {
+        for topic in &event.topics {
+            usages.push(Usage { action: Action::Send, address: Address::Topic(topic.clone()), messages: BTreeSet::from([event.name.clone()]) });
+        }
+    }
+    let mut topics: BTreeSet<String> = crate::dev_kafka::detect_topics(root).into_iter().map(|t| t.name).collect();
+    let mut primary = topics.iter().filter(|t| !crate::dev_kafka::is_dead_letter(t));
+    let single = match (primary.next(), primary.next()) {
+        (Some(only), None) => Some(only.clone()),
+        _ => None,
+    };
+
+    // RabbitMQ: sends carry the payload types the same file serializes
+    let mut by_file: BTreeMap<String, BTreeSet<String>> = BTreeMap::new();
+    for (file, ty) in catalog.payloads(AMQP_MARKERS) {
+        by_file.entry(file).or_default().insert(ty);
+    }
+    let mut exchanges = BTreeMap::new();
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(root, &mut files);
+    files.sort();
+    for file in files {
+        let Ok(content) = fs::read_to_string(&file) else { continue };
+        let rel = file.strip_prefix(root).unwrap_or(&file).to_string_lossy().replace('\\', "/");
+        let lower = content.to_lowercase();
+        if lower.contains("kafka") {
+            kafka_receives(&content, single.as_ref(), &mut usages);
+        }
+        if AMQP_MARKERS.iter().any(|m| lower.contains(m)) {
+            let payloads = by_file.get(&rel).cloned().unwrap_or_default();
+            amqp_usages(&content, &payloads, &mut exchanges, &mut usages);
+        }
+    }
+    for usage in &usages {
+        if let Address::Topic(topic) = &usage.address {
+            topics.remove(topic);
+        }
+    }
+
+    // One channel per address and one operation per (action, channel), merging their messages
+    let mut grouped: BTreeMap<(Address, Action), BTreeSet<String>> = BTreeMap::new();
+    for usage in usages {
+        grouped.entry((usage.address, usage.action)).or_default().extend(usage.messages);
+    }
+    let known: BTreeSet<String> = grouped.values().flatten().cloned().collect();
+    let known: BTreeSet<String> = known.into_iter().filter(|m| catalog.has(m)).collect();
+
+    let mut channels: BTreeMap<String, Channel> = BTreeMap::new();
+    let mut operations = BTreeMap::new();
+    for topic in topics {
+        channels.insert(topic.clone(), Channel { address: topic, messages: BTreeMap::new(), bindings: None });
+    }
+    for ((address, action), messages) in &grouped {
+        let id = address.id();
+        let messages: Vec<&String> = messages.iter().filter(|m| known.contains(*m)).collect();
+        let channel = channels.entry(id.clone()).or_insert_with(|| Channel {
+            address: address.address().to_string(),
+            messages: BTreeMap::new(),
+            bindings: match address {
+                Address::Topic(_) => None,
+                Address::Queue(queue) => Some(json!({"amqp": {"is": "queue", "queue": {"name": queue}, "bindingVersion": "0.3.0"}})),
+                Address::RoutingKey { exchange, .. } => {
+                    let mut exchange_binding = json!({"name": exchange});
+                    if let Some(kind) = exchanges.get(exchange) {
+                        exchange_binding["type"] = json!(kind);
+                    }
+                    Some(json!({"amqp": {"is": "routingKey", "exchange": exchange_binding, "bindingVersion": "0.3.0"}}))
+                }
+            },
+        });
+        for message in &messages {
+            channel.messages.insert((*message).clone(), json!({"$ref": format!("#/components/messages/{}", message)}));
+        }
+        let channel_ref = format!("#/channels/{}", id);
+        operations.insert(
+            format!("{}{}", action.as_str(), camel(&id)),
+            Operation {
+                action: action.as_str(),
+                channel: json!({"$ref": channel_ref}),
+                messages: messages.iter().map(|m| json!({"$ref": format!("{}/messages/{}", channel_ref, m)})).collect(),
+            },
+        );
+    }
+
+    let mut messages = BTreeMap::new();
+    for name in &known {
+        let event = catalog.events.iter().find(|e| &e.name == name);
+        let summary = event.map(|e| match (&e.type_field, e.types.is_empty()) {
+            (Some(field), false) => format!("Definido em {}; tipos ({}): {}", e.source, field, e.types.join(", ")),
+            _ => format!("Definido em {}", e.source),
+        });
+        let headers = event.filter(|e| !e.headers.is_empty()).map(|e| {
+            let properties: serde_json::Map<String, Value> = e.headers.iter().map(|h| (h.clone(), json!({"type": "string"}))).collect();
+            json!({"type": "object", "properties": properties})
+        });
+        let bindings = event.and_then(|e| e.key.as_ref()).map(|key| json!({"kafka": {"key": {"type": "string", "description": format!("Campo {} do payload", key)}, "bindingVersion": "0.5.0"}}));
+        messages.insert(
+            name.clone(),
+            Message {
+                name: name.clone(),
+                content_type: "application/json",
+                summary,
+                payload: json!({"$ref": format!("#/components/schemas/{}", name)}),
+                headers,
+                bindings,
+            },
+        );
+    }
+    let schemas = catalog.schemas(&known, "#/components/schemas/");
+
+    let mut servers = BTreeMap::new();
+    if channels.values().any(|c| c.bindings.is_none()) {
+        let (brokers, _) = crate::dev_kafka::resolve_brokers(root, None);
+        let host = brokers.split(',').next().unwrap_or(&brokers).trim().to_string();
+        servers.insert("kafka".to_string(), Server { host, protocol: "kafka", description: "Broker Kafka de desenvolvimento" });
+    }
+    if channels.values().any(|c| c.bindings.is_some()) {
+        servers.insert("rabbitmq".to_string(), Server { host: amqp_host(root), protocol: "amqp", description: "RabbitMQ de desenvolvimento" });
+    }
+    let title = root.canonicalize().ok().and_then(|p| p.file_name().map(|n| n.to_string_lossy().into_owned())).unwrap_or_else(|| "api".to_string());
+    Document {
+        asyncapi: "3.0.0",
+        info: Info { title, version: "1.0.0".to_string(), description: "Gerado por dx generate asyncapi a partir do código.".to_string() },
+        servers,
+        channels,
+        operations,
+        components: Components { messages, schemas },
+    }
+}
+
+/// `dx generate asyncapi`: write the AsyncAPI document of the project at `dir` to `out` (YAML, or
+/// JSON for a `.json` file). With `check`, only compares it with the file and fails when it is out
+/// of date.
+pub fn cmd_asyncapi(out: Option<PathBuf>, check: bool, dir: Option<PathBuf>) -> i32 {
+    let root = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let doc = document(&root);
+    if doc.channels.is_empty() {
+        eprintln!("Nenhum tópico Kafka nem fila RabbitMQ encontrado em {}.", root.display());
+        return 2;
+    }
+    let path = out.unwrap_or_else(|| PathBuf::from("asyncapi.yaml"));
+    let path = if path.is_absolute() { path } else { root.join(path) };
+    let shown = path.strip_prefix(&root).unwrap_or(&path).display().to_string();
+    let content = match path.extension().and_then(|e| e.to_str()) {
+        Some("json") => serde_json::to_string_pretty(&doc).map(|s| s + "\n").map_err(|e| e.to_string()),
+        _ => serde_yaml::to_string(&doc).map_err(|e| e.to_string()),
+    };
+    let content = match content {
+        Ok(content) => content,
+        Err(e) => {
+            eprintln!("Erro ao gerar o documento AsyncAPI: {}", e);
+            return 1;
+        }
+    };
+    let current = fs::read_to_string(&path).ok();
+    if check {
+        if current.as_deref() == Some(content.as_str()) {
+            println!("✓ {} em dia com o código.", shown);
+            return 0;
+        }
+        println!("✗ {} desatualizado em relação ao código; rode `dx generate asyncapi` para atualizá-lo.", shown);
+        return 1;
+    }
+    println!(
+        "AsyncAPI 3.0 com {} canal(is), {} operação(ões) e {} mensagem(ns) em {}",
+        doc.channels.len(),
+        doc.operations.len(),
+        doc.components.messages.len(),
+        shown
+    );
+    if current.as_deref() == Some(content.as_str()) {
+        return 0;
+    }
+    let written = path.parent().map_or(Ok(()), fs::create_dir_all).and_then(|_| crate::audit::write(&path, &content));
+    if let Err(e) = written {
+        eprintln!("Erro ao escrever {}: {}", path.display(), e);
+        return 1;
+    }
+    0
+}
@@ -0,0 +1,529 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::BTreeSet;
+use std::fs;
+use std::io::{self, Write};
+use std::path::{Path, PathBuf};
+use std::sync::OnceLock;
+use std::time::{SystemTime, UNIX_EPOCH};
+
+/// Run id inherited by nested dx processes, so their changes belong to the outer command.
+pub const RUN_ENV: &str = "DX_AUDIT_RUN";
+/// `0` disables the audit log.
+pub const AUDIT_ENV: &str = "DX_AUDIT";
+const LOG_FILE: &str = "audit.jsonl";
+/// Copies of the files each run replaced or removed, in `backups/<run>/`.
+const BACKUP_DIR: &str = "backups";
+/// The log is trimmed to its newest half beyond this size.
+const MAX_LOG_BYTES: u64 = 8 * 1024 * 1024;
+/// Larger (or binary) files are logged without a diff (their backup still allows undo).
+const MAX_CONTENT_BYTES: usize = 1024 * 1024;
+const CONTEXT: usize = 3;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "kebab-case")]
+enum Op {
+    Write,
+    Remove,
+    RemoveDir,
+    Container,
+    /// Marks `target` (a run id) as undone
+    Undo,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+struct Entry {
+    /// Milliseconds since the epoch
+    ts: u64,
+    run: String,
+    command: String,
+    op: Op,
+    /// Absolute path, compose file or undone run id
+    target: String,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    detail: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    diff: Option<String>,
+    /// Content before the operation, kept inline by older versions (now in `backup`)
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    before: Option<String>,
+    /// Copy of the file before the operation, under the backups directory
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    backup: Option<String>,
+    /// The operation created the file
+    #[serde(default)]
+    created: bool,
+    /// SHA-256 of what dx wrote, to detect later edits before undoing
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    after_hash: Option<String>,
+}
+
+struct Run {
+    id: String,
+    command: String,
+}
+
+static RUN: OnceLock<Run> = OnceLock::new();
+
+/// Identify the current invocation; every change it makes is logged under the same run.
+pub fn init(args: &[String]) {
+    let id = std::env::var(RUN_ENV)
+        .ok()
+        .filter(|v| !v.is_empty())
+        .unwrap_or_else(|| format!("{}-{}", now_ms(), std::process::id()));
+    let _ = RUN.set(Run { id, command: format!("dx {}", args.join(" ")) });
+}
+
+/// Id of the current run, passed on to nested dx processes through `RUN_ENV`.
+pub fn run_id() -> String {
+    RUN.get().map(|r| r.id.clone()).unwrap_or_default()
+}
+
+fn enabled() -> bool {
+    RUN.get().is_some() && !std::env::var(AUDIT_ENV).is_ok_and(|v| v == "0")
+}
+
+fn now_ms() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).map(|d| d.as_millis() as u64).unwrap_or(0)
+}
+
+fn log_path() -> PathBuf {
+    crate::paths::state_dir().join(LOG_FILE)
+}
+
+fn hash(content: &[u8]) -> String {
+    Sha256::digest(content).iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+fn absolute(path: &Path) -> PathBuf {
+    std::path::absolute(path).unwrap_or_else(|_| path.to_path_buf())
+}
+
+fn text(content: &[u8]) -> Option<&str> {
+    (content.len() <= MAX_CONTENT_BYTES).then(|| std::str::from_utf8(content).ok()).flatten()
+}
+
+fn entry(op: Op, target: String) -> Entry {
+    let run = RUN.get();
+    Entry {
+        ts: now_ms(),
+        run: run.map(|r| r.id.clone()).unwrap_or_default(),
+        command: run.map(|r| r.command.clone()).unwrap_or_default(),
+        op,
+        target,
+        detail: None,
+        diff: None,
+        before: None,
+        backup: None,
+        created: false,
+        after_hash: None,
+    }
+}
+
+/// Append to the log, first keeping a backup of `previous` (the content `entry.target` had)
+/// when given. Concurrent dx processes take turns through the log's lock. Failures are
+/// ignored: auditing must never break the command itself.
+fn append(mut entry: Entry, previous: Option<&[u8]>) {
+    let path = log_path();
+    let result = (|| -> io::Result<()> {
+        let _lock = crate::lock::for_file(&path)?;
+        if fs::metadata(&path).is_ok_and(|m| m.len() > MAX_LOG_BYTES) {
+            let content = fs::read_to_string(&path)?;
+            let lines: Vec<&str> = content.lines().collect();
+            let kept = &lines[lines.len() / 2..];
+            crate::lock::write_atomic(&path, kept.join("\n") + "\n")?;
+            prune_backups(kept);
+        }
+        if let Some(previous) = previous {
+            entry.backup = backup(Path::new(&entry.target), previous);
+        }
+        let mut file = fs::OpenOptions::new().create(true).append(true).open(&path)?;
+        writeln!(file, "{}", serde_json::to_string(&entry).map_err(io::Error::other)?)
+    })();
+    if let Err(e) = result {
+        eprintln!("Aviso: não foi possível registrar no log de auditoria {}: {}", path.display(), e);
+    }
+}
+
+fn backup_root() -> PathBuf {
+    crate::paths::state_dir().join(BACKUP_DIR)
+}
+
+/// Remove the backups of runs that are no longer in the log.
+fn prune_backups(kept_lines: &[&str]) {
+    let mut runs: BTreeSet<String> =
+        kept_lines.iter().filter_map(|l| serde_json::from_str::<Entry>(l).ok()).map(|e| e.run).collect();
+    runs.insert(run_id());
+    let Ok(dirs) = fs::read_dir(backup_root()) else { return };
+    for dir in dirs.flatten() {
+        if !runs.contains(&*dir.file_name().to_string_lossy()) {
+            let _ = fs::remove_dir_all(dir.path());
+        }
+    }
+}
+
+/// Keep a copy of `content` (what `path` held before this run touched it) and return where it is.
+fn backup(path: &Path, content: &[u8]) -> Option<String> {
+    let dir = backup_root().join(run_id());
+    fs::create_dir_all(&dir).ok()?;
+    let index = fs::read_dir(&dir).map(|d| d.count()).unwrap_or(0);
+    let name = path.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_default();
+    let file = dir.join(format!("{}-{}", index, name));
+    fs::write(&file, content).ok()?;
+    Some(file.to_string_lossy().into_owned())
+}
+
+/// Content of the file before the entry's operation, from its backup (or the inline copy of older logs).
+fn previous_content(e: &Entry) -> Option<Vec<u8>> {
+    match &e.backup {
+        Some(file) => fs::read(file).ok(),
+        None => e.before.as_ref().map(|b| b.clone().into_bytes()),
+    }
+}
+
+fn load() -> Vec<Entry> {
+    let Ok(content) = fs::read_to_string(log_path()) else { return Vec::new() };
+    content.lines().filter_map(|l| serde_json::from_str(l).ok()).collect()
+}
+
+fn record_write(path: &Path, before: Option<Vec<u8>>, after: &[u8]) {
+    if !enabled() || before.as_deref() == Some(after) {
+        return;
+    }
+    let target = absolute(path).to_string_lossy().into_owned();
+    let mut e = entry(Op::Write, target.clone());
+    e.created = before.is_none();
+    e.after_hash = Some(hash(after));
+    let old = before.as_deref().map(text).unwrap_or(Some(""));
+    if let (Some(old), Some(new)) = (old, text(after)) {
+        e.diff = Some(unified_diff(&target, old, new));
+    } else {
+        e.detail = Some("conteúdo binário ou grande demais: sem diff".to_string());
+    }
+    append(e, before.as_deref());
+}
+
+/// `fs::write` that records the change in the audit log, with a diff and a backup of the previous content.
+pub fn write(path: impl AsRef<Path>, contents: impl AsRef<[u8]>) -> io::Result<()> {
+    let path = path.as_ref();
+    let before = fs::read(path).ok();
+    fs::write(path, contents.as_ref())?;
+    record_write(path, before, contents.as_ref());
+    Ok(())
+}
+
+/// `fs::remove_file` that keeps a backup of the removed file.
+pub fn remove_file(path: impl AsRef<Path>) -> io::Result<()> {
+    let path = path.as_ref();
+    let before = fs::read(path).ok();
+    fs::remove_file(path)?;
+    if enabled() {
+        append(entry(Op::Remove, absolute(path).to_string_lossy().into_owned()), before.as_deref());
+    }
+    Ok(())
+}
+
+/// `fs::remove_dir_all` with an audit entry (directories are not restored by `dx undo`).
+pub fn remove_dir_all(path: impl AsRef<Path>) -> io::Result<()> {
+    let path = path.as_ref();
+    fs::remove_dir_all(path)?;
+    if enabled() {
+        append(entry(Op::RemoveDir, absolute(path).to_string_lossy().into_owned()), None);
+    }
+    Ok(())
+}
+
+/// Record a container operation (`docker compose -f <compose> <action>`) before it runs.
+pub fn container(compose: &Path, action: &str) {
+    if enabled() {
+        let mut e = entry(Op::Container, absolute(compose).to_string_lossy().into_owned());
+        e.detail = Some(action.to_string());
+        append(e, None);
+    }
+}
+
+/// Line diff of `old` and `new` as a unified diff with `CONTEXT` lines around each change.
+pub fn unified_diff(path: &str, old: &str, new: &str) -> String {
+    let a: Vec<&str> = old.lines().collect();
+    let b: Vec<&str> = new.lines().collect();
+    let ops = diff_lines(&a, &b);
+    let changes: Vec<usize> = ops.iter().enumerate().filter(|(_, (k, _))| *k != ' ').map(|(i, _)| i).collect();
+    if changes.is_empty() {
+        return String::new();
+    }
+    // Old/new line numbers before each op
+    let mut pos = Vec::with_capacity(ops.len() + 1);
+    let (mut o, mut n) = (0, 0);
+    for (kind, _) in &ops {
+        pos.push((o, n));
+        if *kind != '+' {
+            o += 1;
+        }
+        if *kind != '-' {
+            n += 1;
+        }
+    }
+    pos.push((o, n));
+
+    let mut out = format!("--- a{p}\n+++ b{p}\n", p = if path.starts_with('/') { path.to_string() } else { format!("/{}", path) });
+    let mut i = 0;
+    while i < changes.len() {
+        let start = changes[i].saturating_sub(CONTEXT);
+        let mut last = changes[i];
+        while i + 1 < changes.len() && changes[i + 1] <= last + 2 * CONTEXT {
+            i += 1;
+            last = changes[i];
+        }
+        i += 1;
+        let end = (last + CONTEXT + 1).min(ops.len());
+        let ((o0, n0), (o1, n1)) = (pos[start], pos[end]);
+        // An empty side starts at the line before it, as in `diff -u`
+        let start_of = |first: usize, count: usize| if count == 0 { first } else { first + 1 };
+        out.push_str(&format!(
+            "@@ -{},{} +{},{} @@\n",
+            start_of(o0, o1 - o0),
+            o1 - o0,
+            start_of(n0, n1 - n0),
+            n1 - n0
+        ));
+        for (kind, line) in &ops[start..end] {
+            out.push_str(&format!("{}{}\n", kind, line));
+        }
+    }
+    out
+}
+
+/// Edit script between two line lists (' ' keep, '-' remove, '+' add), via LCS on the
+/// part that differs; very large changes fall back to remove-all/add-all.
+pub fn diff_lines<'a>(a: &[&'a str], b: &[&'a str]) -> Vec<(char, &'a str)> {
+    let prefix = a.iter().zip(b).take_while(|(x, y)| x == y).count();
+    let suffix = a[prefix..].iter().rev().zip(b[prefix..].iter().rev()).take_while(|(x, y)| x == y).count();
+    let (am, bm) = (&a[prefix..a.len() - suffix], &b[prefix..b.len() - suffix]);
+
+    let mut ops: Vec<(char, &str)> = a[..prefix].iter().map(|l| (' ', *l)).collect();
+    if am.len() * bm.len() <= 4_000_000 {
+        let (n, m) = (am.len(), bm.len());
+        let mut lcs = vec![vec![0u32; m + 1]; n + 1];
+        for i in (0..n).rev() {
+            for j in (0..m).rev() {
+                lcs[i][j] = if am[i] == bm[j] { lcs[i + 1][j + 1] + 1 } else { lcs[i + 1][j].max(lcs[i][j + 1]) };
+            }
+        }
+        let (mut i, mut j) = (0, 0);
+        while i < n && j < m {
+            if am[i] == bm[j] {
+                ops.push((' ', am[i]));
+                i += 1;
+                j += 1;
+            } else if lcs[i + 1][j] >= lcs[i][j + 1] {
+                ops.push(('-', am[i]));
+                i += 1;
+            } else {
+                ops.push(('+', bm[j]));
+                j += 1;
+            }
+        }
+        ops.extend(am[i..].iter().map(|l| ('-', *l)));
+        ops.extend(bm[j..].iter().map(|l| ('+', *l)));
+    } else {
+        ops.extend(am.iter().map(|l| ('-', *l)));
+        ops.extend(bm.iter().map(|l| ('+', *l)));
+    }
+    ops.extend(a[a.len() - suffix..].iter().map(|l| (' ', *l)));
+    ops
+}
+
+fn diff_stat(diff: &str) -> (usize, usize) {
+    let lines = diff.lines().filter(|l| !l.starts_with("+++") && !l.starts_with("---"));
+    lines.fold((0, 0), |(add, del), l| match l.chars().next() {
+        Some('+') => (add + 1, del),
+        Some('-') => (add, del + 1),
+        _ => (add, del),
+    })
+}
+
+fn describe(e: &Entry) -> String {
+    match e.op {
+        Op::Write => {
+            let (add, del) = e.diff.as_deref().map(diff_stat).unwrap_or((0, 0));
+            let verb = if e.created { "criado" } else { "alterado" };
+            format!("{:<10} {} (+{} -{})", verb, e.target, add, del)
+        }
+        Op::Remove => format!("{:<10} {}", "removido", e.target),
+        Op::RemoveDir => format!("{:<10} {}", "removido", e.target),
+        Op::Container => format!("{:<10} docker compose -f {} {}", "container", e.target, e.detail.as_deref().unwrap_or("")),
+        Op::Undo if e.detail.is_some() => format!("{:<10} run {} (parcial)", "desfeito", e.target),
+        Op::Undo => format!("{:<10} run {}", "desfeito", e.target),
+    }
+}
+
+/// Runs in log order, each with its entries.
+fn runs(entries: &[Entry]) -> Vec<(String, Vec<&Entry>)> {
+    let mut runs: Vec<(String, Vec<&Entry>)> = Vec::new();
+    for e in entries {
+        match runs.iter_mut().find(|(id, _)| *id == e.run) {
+            Some((_, list)) => list.push(e),
+            None => runs.push((e.run.clone(), vec![e])),
+        }
+    }
+    runs
+}
+
+/// `dx audit [--limit N] [--diff]`: latest commands that changed files or containers.
+pub fn cmd_audit(limit: usize, show_diff: bool) {
+    let entries = load();
+    let runs = runs(&entries);
+    if runs.is_empty() {
+        println!("Nenhuma alteração registrada ({}).", log_path().display());
+        return;
+    }
+    for (id, list) in runs.iter().skip(runs.len().saturating_sub(limit)) {
+        let first = list[0];
+        println!("{}  {}  [{}]", crate::history::ago(first.ts / 1000), first.command, id);
+        for e in list {
+            println!("  {}", describe(e));
+            if show_diff {
+                if let Some(diff) = e.diff.as_deref().filter(|d| !d.is_empty()) {
+                    for line in diff.lines() {
+                        println!("    {}", line);
+                    }
+                }
+            }
+        }
+    }
+    println!("\nLog: {}", log_path().display());
+}
+
+/// Runs that changed files and were not (completely) undone, oldest first.
+fn undoable<'a>(entries: &'a [Entry]) -> Vec<(String, Vec<&'a Entry>)> {
+    // A partial undo (files kept) leaves the run available for `dx undo --force`
+    let undone: BTreeSet<&str> =
+        entries.iter().filter(|e| e.op == Op::Undo && e.detail.is_none()).map(|e| e.target.as_str()).collect();
+    runs(entries)
+        .into_iter()
+        .filter(|(id, list)| {
+            !undone.contains(id.as_str())
+                && !list.iter().any(|e| e.op == Op::Undo)
+                && list.iter().any(|e| matches!(e.op, Op::Write | Op::Remove))
+        })
+        .collect()
+}
+
+/// `dx undo [<run>] [--dry-run] [--force]`: revert the file changes of a run (id or unique prefix,
+/// as listed by `dx audit`), by default the latest one that changed files under the current
+/// directory. Files edited since then are kept unless `--force`.
+pub fn cmd_undo(run: Option<String>, dry_run: bool, force: bool) -> i32 {
+    let cwd = absolute(&std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let entries = load();
+    let candidates = undoable(&entries);
+    let chosen = match &run {
+        Some(prefix) => {
+            let matching: Vec<&(String, Vec<&Entry>)> = candidates.iter().filter(|(id, _)| id.starts_with(prefix.as_str())).collect();
+            match matching.as_slice() {
+                [one] => Some(*one),
+                [] => {
+                    let known = runs(&entries).iter().any(|(id, _)| id.starts_with(prefix.as_str()));
+                    if known {
+                        eprintln!("A execução {} não alterou arquivos ou já foi desfeita.", prefix);
+                    } else {
+                        eprintln!("Execução não encontrada: {}. Use 'dx audit' para ver as execuções.", prefix);
+                    }
+                    return 1;
+                }
+                _ => {
+                    eprintln!("'{}' corresponde a {} execuções; use mais caracteres do id.", prefix, matching.len());
+                    return 2;
+                }
+            }
+        }
+        None => candidates.iter().rev().find(|(_, list)| {
+            list.iter().any(|e| matches!(e.op, Op::Write | Op::Remove) && Path::new(&e.target).starts_with(&cwd))
+        }),
+    };
+    let Some((run_id, list)) = chosen else {
+        println!("Nada para desfazer em {}.", cwd.display());
+        return 0;
+    };
+
+    println!("Desfazendo: {}  [{}]", list[0].command, run_id);
+    let mut failed = false;
+    // Files touched more than once in the run go back to the state before its first change
+    let mut restore: Vec<(&Entry, Option<Vec<u8>>)> = Vec::new();
+    let mut kept: BTreeSet<&str> = BTreeSet::new();
+    for e in list.iter().rev() {
+        if kept.contains(e.target.as_str()) {
+            continue;
+        }
+        match e.op {
+            Op::Write => {
+                let current = fs::read(&e.target).ok();
+                let latest = restore.iter().any(|(r, _)| r.target == e.target);
+                if !force && !latest && current.as_deref().map(hash) != e.after_hash {
+                    println!("  mantido    {} (alterado depois; use --force para desfazer mesmo assim)", e.target);
+                    kept.insert(&e.target);
+                    failed = true;
+                } else if e.created {
+                    restore.retain(|(r, _)| r.target != e.target);
+                    restore.push((e, None));
+                } else if let Some(previous) = previous_content(e) {
+                    restore.retain(|(r, _)| r.target != e.target);
+                    restore.push((e, Some(previous)));
+                } else {
+                    println!("  mantido    {} (cópia anterior não encontrada)", e.target);
+                    failed = true;
+                }
+            }
+            Op::Remove => match previous_content(e) {
+                Some(previous) if !Path::new(&e.target).exists() || force => {
+                    restore.retain(|(r, _)| r.target != e.target);
+                    restore.push((e, Some(previous)));
+                }
+                Some(_) => {
+                    println!("  mantido    {} (recriado depois; use --force para sobrescrever)", e.target);
+                    failed = true;
+                }
+                None => println!("  mantido    {} (cópia do arquivo removido não encontrada)", e.target),
+            },
+            Op::RemoveDir | Op::Container => println!("  ignorado   {} (não reversível)", describe(e).trim_start()),
+            Op::Undo => {}
+        }
+    }
+
+    if dry_run {
+        for (e, content) in &restore {
+            match content {
+                Some(_) => println!("  restauraria {}", e.target),
+                None => println!("  removeria   {}", e.target),
+            }
+        }
+        return 0;
+    }
+    for (e, content) in restore {
+        let result = match &content {
+            Some(c) => {
+                if let Some(parent) = Path::new(&e.target).parent() {
+                    let _ = fs::create_dir_all(parent);
+                }
+                write(&e.target, c)
+            }
+            None => remove_file(&e.target).or_else(|err| if err.kind() == io::ErrorKind::NotFound { Ok(()) } else { Err(err) }),
+        };
+        match (result, content) {
+            (Ok(()), Some(_)) => println!("  restaurado {}", e.target),
+            (Ok(()), None) => println!("  removido   {} (criado pelo comando)", e.target),
+            (Err(err), _) => {
+                eprintln!("  erro       {}: {}", e.target, err);
+                failed = true;
+            }
+        }
+    }
+    if enabled() {
+        let mut e = entry(Op::Undo, run_id.clone());
+        e.detail = failed.then(|| "parcial".to_string());
+        append(e, None);
+    }
+    if failed { 1 } else { 0 }
+}
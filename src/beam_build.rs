@@ -0,0 +1,437 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! BEAM builds: the Hex packages an Elixir project declares in the `deps` of mix.exs, with the
+//! versions mix.lock holds, and an Erlang project in the `deps` of rebar.config (and of its
+//! profiles), with the versions of rebar.lock. Packages restricted to `only: [:dev, :test]` in mix
+//! or declared in the `dev` and `test` profiles of rebar3 are development-only.
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+pub const MIX_FILE: &str = "mix.exs";
+const MIX_LOCK: &str = "mix.lock";
+pub const REBAR_FILE: &str = "rebar.config";
+const REBAR_LOCK: &str = "rebar.lock";
+/// Where mix and rebar3 fetch the packages, each in a directory of its name.
+const FETCHED_DIRS: &[&str] = &["deps", "_build/default/lib"];
+/// Environments (mix) and profiles (rebar3) whose dependencies are development-only.
+const DEV_ENVS: &[&str] = &["dev", "test"];
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct HexPackage {
+    /// Name of the package in Hex (the `hex:` of a renamed mix dependency)
+    pub name: String,
+    /// Requirement as written (`~> 3.3`, `1.2.0`); None for git and path dependencies
+    pub requirement: Option<String>,
+    /// Version the lock file holds for it
+    pub version: Option<String>,
+    /// Development only: `only: [:dev, :test]` in mix, the dev and test profiles in rebar3
+    pub dev: bool,
+    /// `git` or `path` for a dependency outside Hex
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub local: Option<String>,
+    /// Manifest that declares it
+    pub source: String,
+}
+
+impl HexPackage {
+    /// A version a registry or advisory database can match: the locked one, else an exact requirement
+    /// (`1.2.0` or `== 1.2.0`).
+    pub fn exact_version(&self) -> Option<&str> {
+        self.version.as_deref().or_else(|| {
+            let exact = self.requirement.as_deref()?.trim().trim_start_matches("==").trim();
+            (!exact.is_empty() && exact.chars().all(|c| c.is_ascii_digit() || c == '.')).then_some(exact)
+        })
+    }
+}
+
+/// Whether `dir` is an Elixir (mix.exs) or Erlang (rebar.config) project.
+pub fn is_project(dir: &Path) -> bool {
+    manifest(dir).is_some()
+}
+
+/// The manifest of the project in `dir`: mix.exs, else rebar.config.
+pub fn manifest(dir: &Path) -> Option<&'static str> {
+    [MIX_FILE, REBAR_FILE].into_iter().find(|f| dir.join(f).is_file())
+}
+
+/// The packages the manifest of `dir` declares, with the version each is locked to.
+pub fn dependencies(dir: &Path) -> Vec<HexPackage> {
+    match manifest(dir) {
+        Some(MIX_FILE) => mix_dependencies(dir),
+        Some(_) => rebar_dependencies(dir),
+        None => Vec::new(),
+    }
+}
+
+/// Every Hex package with a version pinned for `dir`, as (name, version, source): all the lock file
+/// holds (transitive ones included), else the exact requirements of the manifest.
+pub fn pinned(dir: &Path) -> Vec<(String, String, String)> {
+    let (lock_file, locked) = match manifest(dir) {
+        Some(MIX_FILE) => (MIX_LOCK, mix_lock(dir)),
+        Some(_) => (REBAR_LOCK, rebar_lock(dir)),
+        None => return Vec::new(),
+    };
+    if !locked.is_empty() {
+        return locked.into_iter().map(|(name, version)| (name, version, lock_file.to_string())).collect();
+    }
+    dependencies(dir)
+        .iter()
+        .filter(|p| p.local.is_none())
+        .filter_map(|p| Some((p.name.clone(), p.exact_version()?.to_string(), p.source.clone())))
+        .collect()
+}
+
+/// Licenses in the hex_metadata.config of the package `name` fetched into the project (`deps/` in mix,
+/// `_build/default/lib/` in rebar3), when it is the `version` asked for, with the file read.
+pub fn fetched_licenses(project_dir: &Path, name: &str, version: &str) -> Option<(Vec<String>, PathBuf)> {
+    FETCHED_DIRS.iter().find_map(|dir| {
+        let file = project_dir.join(dir).join(name).join("hex_metadata.config");
+        let terms = parse_terms(&fs::read_to_string(&file).ok()?);
+        // `{<<"version">>, <<"1.4.1">>}.`, `{<<"licenses">>, [<<"MIT">>]}.`
+        let field = |key: &str| {
+            terms.iter().find_map(|t| match t.items() {
+                [k, v] if k.text() == Some(key) => Some(v),
+                _ => None,
+            })
+        };
+        if field("version")?.text() != Some(version) {
+            return None;
+        }
+        let licenses: Vec<String> = field("licenses")?.items().iter().filter_map(Term::text).map(str::to_string).collect();
+        (!licenses.is_empty()).then_some((licenses, file))
+    })
+}
+
+// --- Elixir (mix) -----------------------------------------------------------------------------
+
+/// `text` without `#` comments (outside strings).
+fn strip_comments(text: &str) -> String {
+    let mut out = String::new();
+    for line in text.lines() {
+        let mut in_string = false;
+        let mut escaped = false;
+        for c in line.chars() {
+            match c {
+                '#' if !in_string => break,
+                '"' if !escaped => in_string = !in_string,
+                _ => {}
+            }
+            escaped = c == '\\' && !escaped;
+            out.push(c);
+        }
+        out.push('\n');
+    }
+    out
+}
+
+/// The contents of the bracket that opens at byte `open` of `text` (`[`, `{` or `%{`), up to its match.
+fn enclosed(text: &str, open: usize) -> Option<&str> {
+    let mut depth = 0;
+    let mut in_string = false;
+    let mut escaped = false;
+    for (i, c) in text[open..].char_indices() {
+        match c {
+            '"' if !escaped => in_string = !in_string,
+            '[' | '{' | '(' if !in_string => depth += 1,
+            ']' | '}' | ')' if !in_string => {
+                depth -= 1;
+                if depth == 0 {
+                    return Some(&text[open + 1..open + i]);
+                }
+            }
+            _ => {}
+        }
+        escaped = c == '\\' && !escaped;
+    }
+    None
+}
+
+/// `text` split on the commas outside brackets and strings.
+fn split_top(text: &str) -> Vec<&str> {
+    let mut parts = Vec::new();
+    let (mut depth, mut start) = (0i32, 0);
+    let mut in_string = false;
+    let mut escaped = false;
+    for (i, c) in text.char_indices() {
+        match c {
+            '"' if !escaped => in_string = !in_string,
+            '[' | '{' | '(' if !in_string => depth += 1,
+            ']' | '}' | ')' if !in_string => depth -= 1,
+            ',' if !in_string && depth == 0 => {
+                parts.push(text[start..i].trim());
+                start = i + 1;
+            }
+            _ => {}
+        }
+        escaped = c == '\\' && !escaped;
+    }
+    parts.push(text[start..].trim());
+    parts.retain(|p| !p.is_empty());
+    parts
+}
+
+fn unquote(value: &str) -> Option<String> {
+    value.trim().strip_prefix('"')?.strip_suffix('"').map(str::to_string)
+}
+
+fn atom(value: &str) -> Option<String> {
+    let name = value.trim().strip_prefix(':')?;
+    Some(name.trim_matches('"').to_string()).filter(|n| !n.is_empty())
+}
+
+/// The `deps` list of mix.exs: the list that follows `defp deps` (or `def deps`).
+fn mix_deps_list(content: &str) -> Option<&str> {
+    let start = content.find("defp deps").or_else(|| content.find("def deps"))?;
+    let open = start + content[start..].find('[')?;
+    enclosed(content, open)
+}
+
+fn mix_dependencies(dir: &Path) -> Vec<HexPackage> {
+    let Ok(content) = fs::read_to_string(dir.join(MIX_FILE)) else { return Vec::new() };
+    let content = strip_comments(&content);
+    let Some(list) = mix_deps_list(&content) else { return Vec::new() };
+    let locked = mix_lock(dir);
+    let mut packages = Vec::new();
+    for entry in split_top(list) {
+        let Some(tuple) = entry.strip_prefix('{').and_then(|e| e.strip_suffix('}')) else { continue };
+        let parts = split_top(tuple);
+        let Some(key) = parts.first().and_then(|p| atom(p)) else { continue };
+        let requirement = parts.get(1).and_then(|p| unquote(p));
+        let option = |name: &str| parts.iter().find_map(|p| p.strip_prefix(&format!("{}:", name)).map(str::trim));
+        let local = ["git", "github", "path"].into_iter().find(|k| option(k).is_some()).map(|k| if k == "path" { "path" } else { "git" }.to_string());
+        let name = option("hex").and_then(atom).unwrap_or(key);
+        let only: Vec<String> = option("only")
+            .map(|o| o.trim_matches(['[', ']']).split(',').filter_map(atom).collect())
+            .unwrap_or_default();
+        let dev = !only.is_empty() && only.iter().all(|env| DEV_ENVS.contains(&env.as_str()));
+        let version = locked.iter().find(|(n, _)| *n == name).map(|(_, v)| v.clone()).filter(|_| local.is_none());
+        packages.push(HexPackage { name, requirement: requirement.filter(|_| local.is_none()), version, dev, local, source: MIX_FILE.to_string() });
+    }
+    packages
+}
+
+/// Hex packages of mix.lock as (name, version): `"name": {:hex, :package, "version", ...}`.
+fn mix_lock(dir: &Path) -> Vec<(String, String)> {
+    let Ok(content) = fs::read_to_string(dir.join(MIX_LOCK)) else { return Vec::new() };
+    let Some(open) = content.find("%{").map(|i| i + 1) else { return Vec::new() };
+    let Some(body) = enclosed(&content, open) else { return Vec::new() };
+    let mut locked = Vec::new();
+    for entry in split_top(body) {
+        let Some(open) = entry.find('{') else { continue };
+        let Some(tuple) = enclosed(entry, open) else { continue };
+        let parts = split_top(tuple);
+        if parts.first().map(|p| p.trim()) != Some(":hex") {
+            continue;
+        }
+        if let (Some(name), Some(version)) = (parts.get(1).and_then(|p| atom(p)), parts.get(2).and_then(|p| unquote(p))) {
+            locked.push((name, version));
+        }
+    }
+    locked
+}
+
+// --- Erlang (rebar3) --------------------------------------------------------------------------
+
+/// An Erlang term of rebar.config or rebar.lock.
+#[derive(Debug, Clone, PartialEq)]
+enum Term {
+    Atom(String),
+    /// A string or a binary (`<<"text">>`)
+    Str(String),
+    Tuple(Vec<Term>),
+    List(Vec<Term>),
+    Other,
+}
+
+impl Term {
+    fn atom(&self) -> Option<&str> {
+        match self {
+            Term::Atom(a) => Some(a),
+            _ => None,
+        }
+    }
+
+    fn text(&self) -> Option<&str> {
+        match self {
+            Term::Str(s) => Some(s),
+            _ => None,
+        }
+    }
+
+    fn items(&self) -> &[Term] {
+        match self {
+            Term::Tuple(items) | Term::List(items) => items,
+            _ => &[],
+        }
+    }
+
+    /// The value of `{key, Value}` in a proplist.
+    fn get(&self, key: &str) -> Option<&Term> {
+        self.items().iter().find_map(|t| match t.items() {
+            [k, v] if k.atom() == Some(key) => Some(v),
+            _ => None,
+        })
+    }
+}
+
+/// The terms of an Erlang consult file, each ending with a `.`. Unreadable parts become `Other`.
+fn parse_terms(content: &str) -> Vec<Term> {
+    struct Parser<'a> {
+        chars: std::iter::Peekable<std::str::Chars<'a>>,
+    }
+    impl Parser<'_> {
+        fn skip(&mut self) {
+            while let Some(&c) = self.chars.peek() {
+                if c == '%' {
+                    while self.chars.next_if(|c| *c != '\n').is_some() {}
+                } else if c.is_whitespace() {
+                    self.chars.next();
+                } else {
+                    break;
+                }
+            }
+        }
+
+        fn quoted(&mut self, end: char) -> String {
+            let mut text = String::new();
+            while let Some(c) = self.chars.next() {
+                match c {
+                    '\\' => text.extend(self.chars.next()),
+                    c if c == end => break,
+                    c => text.push(c),
+                }
+            }
+            text
+        }
+
+        fn sequence(&mut self, end: char) -> Vec<Term> {
+            let mut items = Vec::new();
+            loop {
+                self.skip();
+                match self.chars.peek() {
+                    None => break,
+                    Some(&c) if c == end => {
+                        self.chars.next();
+                        break;
+                    }
+                    Some(',') | Some('|') => {
+                        self.chars.next();
+                    }
+                    Some(_) => items.push(self.term()),
+                }
+            }
+            items
+        }
+
+        fn term(&mut self) -> Term {
+            self.skip();
+            match self.chars.next() {
+                Some('{') => Term::Tuple(self.sequence('}')),
+                Some('[') => Term::List(self.sequence(']')),
+                Some('"') => Term::Str(self.quoted('"')),
+                Some('\'') => Term::Atom(self.quoted('\'')),
+                Some('<') if self.chars.next_if_eq(&'<').is_some() => {
+                    self.skip();
+                    let text = if self.chars.next_if_eq(&'"').is_some() { self.quoted('"') } else { String::new() };
+                    while self.chars.next().is_some_and(|c| c != '>') {}
+                    self.chars.next_if_eq(&'>');
+                    Term::Str(text)
+                }
+                Some(c) if c.is_alphanumeric() || c == '_' || c == '-' => {
+                    let mut word = c.to_string();
+                    while let Some(c) = self.chars.next_if(|c| c.is_alphanumeric() || *c == '_' || *c == '@') {
+                        word.push(c);
+                    }
+                    // Floats: a dot followed by a digit is not the end of the term
+                    while let Some(c) = self.chars.clone().next().filter(|c| *c == '.') {
+                        let mut ahead = self.chars.clone();
+                        ahead.next();
+                        if !ahead.peek().is_some_and(|c| c.is_ascii_digit()) {
+                            break;
+                        }
+                        self.chars.next();
+                        word.push(c);
+                        while let Some(d) = self.chars.next_if(|c| c.is_ascii_digit()) {
+                            word.push(d);
+                        }
+                    }
+                    if c.is_lowercase() { Term::Atom(word) } else { Term::Other }
+                }
+                _ => Term::Other,
+            }
+        }
+    }
+
+    let mut parser = Parser { chars: content.chars().peekable() };
+    let mut terms = Vec::new();
+    loop {
+        parser.skip();
+        if parser.chars.peek().is_none() {
+            break;
+        }
+        terms.push(parser.term());
+        // Up to the `.` that ends the form
+        parser.skip();
+        while parser.chars.next().is_some_and(|c| c != '.') {}
+    }
+    terms
+}
+
+/// A rebar3 dependency: `name`, `{name, "requirement"}`, `{name, {pkg, package}}`,
+/// `{name, "requirement", {pkg, package}}` or `{name, {git, ...}}`.
+fn rebar_dep(term: &Term, dev: bool, locked: &BTreeMap<String, String>) -> Option<HexPackage> {
+    let (key, rest) = match term {
+        Term::Atom(name) => (name.clone(), &[][..]),
+        Term::Tuple(items) => (items.first()?.atom()?.to_string(), &items[1..]),
+        _ => return None,
+    };
+    let requirement = rest.iter().find_map(|t| t.text()).map(str::to_string);
+    let source = rest.iter().find_map(|t| t.items().first().and_then(Term::atom).map(|kind| (kind, t)));
+    let (name, local) = match source {
+        Some(("pkg", t)) => (t.items().get(1).and_then(Term::atom).unwrap_or(&key).to_string(), None),
+        Some(("git" | "git_subdir" | "hg", _)) => (key.clone(), Some("git".to_string())),
+        _ => (key.clone(), None),
+    };
+    let version = locked.get(&name).cloned().filter(|_| local.is_none());
+    Some(HexPackage { name, requirement: requirement.filter(|_| local.is_none()), version, dev, local, source: REBAR_FILE.to_string() })
+}
+
+fn rebar_dependencies(dir: &Path) -> Vec<HexPackage> {
+    let Ok(content) = fs::read_to_string(dir.join(REBAR_FILE)) else { return Vec::new() };
+    let config = Term::List(parse_terms(&content));
+    let locked: BTreeMap<String, String> = rebar_lock(dir).into_iter().collect();
+    let mut packages: Vec<HexPackage> =
+        config.get("deps").map(|deps| deps.items().iter().filter_map(|d| rebar_dep(d, false, &locked)).collect()).unwrap_or_default();
+    if let Some(profiles) = config.get("profiles") {
+        for env in DEV_ENVS {
+            let Some(deps) = profiles.get(env).and_then(|p| p.get("deps")) else { continue };
+            packages.extend(deps.items().iter().filter_map(|d| rebar_dep(d, true, &locked)));
+        }
+    }
+    packages
+}
+
+/// Hex packages of rebar.lock as (name, version): `{<<"name">>, {pkg, <<"package">>, <<"version">>}, level}`.
+fn rebar_lock(dir: &Path) -> Vec<(String, String)> {
+    let Ok(content) = fs::read_to_string(dir.join(REBAR_LOCK)) else { return Vec::new() };
+    // `{"1.2.0", [...]}` since rebar3 3.5, a bare list before
+    let Some(first) = parse_terms(&content).into_iter().next() else { return Vec::new() };
+    let entries = match &first {
+        Term::Tuple(items) => items.get(1).map(Term::items).unwrap_or_default(),
+        list => list.items(),
+    };
+    entries
+        .iter()
+        .filter_map(|entry| {
+            let source = entry.items().get(1)?;
+            match source.items() {
+                [kind, name, version, ..] if kind.atom() == Some("pkg") => Some((name.text()?.to_string(), version.text()?.to_string())),
+                _ => None,
+            }
+        })
+        .collect()
+}
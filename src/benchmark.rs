@@ -0,0 +1,450 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `dx benchmark archetype`: score a project against a golden-path archetype the organization
+//! publishes in its team profile (`archetypes:`), or in a local file: the stack, required files,
+//! configuration files and what they must contain, and the service patterns (HTTP routes, Dev
+//! Services, environment variables). Each gap becomes a remediation task, with the dx command that
+//! fixes it when there is one.
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Gap report, next to the other reports in `.dx/`.
+const REPORT_FILE: &str = ".dx/archetype-report.md";
+/// Score a project must reach when the archetype sets no `min_score`.
+const DEFAULT_MIN_SCORE: u64 = 100;
+
+/// A golden path: what every project of this kind is expected to have.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct Archetype {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub description: Option<String>,
+    /// Stack key, as in the detection rules: go, node, python, rust, java-maven, java-gradle, dotnet
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub stack: Option<String>,
+    /// Files that must exist (`*` matches within a path segment)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub files: Vec<Requirement>,
+    /// Files that must exist and contain every `contains` string
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub configs: Vec<Requirement>,
+    /// HTTP routes the code registers (`GET /healthz`, or just the path)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub routes: Vec<Requirement>,
+    /// Dev Services detected in the project (postgres, redis, kafka, ...)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub services: Vec<Requirement>,
+    /// Environment variables the code reads
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub env: Vec<Requirement>,
+    /// Score (0-100) below which the benchmark fails; 100 when absent
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub min_score: Option<u64>,
+}
+
+fn one() -> u64 {
+    1
+}
+
+/// One item of an archetype: just its name, or with a weight, the expected content and how to fix it.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(untagged)]
+pub enum Requirement {
+    Name(String),
+    Detailed {
+        #[serde(alias = "path", alias = "route", alias = "service")]
+        name: String,
+        #[serde(default = "one")]
+        weight: u64,
+        #[serde(default, skip_serializing_if = "Vec::is_empty")]
+        contains: Vec<String>,
+        /// Remediation to suggest instead of the default one
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        fix: Option<String>,
+    },
+}
+
+impl Requirement {
+    fn name(&self) -> &str {
+        match self {
+            Requirement::Name(name) | Requirement::Detailed { name, .. } => name,
+        }
+    }
+
+    fn weight(&self) -> u64 {
+        match self {
+            Requirement::Name(_) => 1,
+            Requirement::Detailed { weight, .. } => *weight,
+        }
+    }
+
+    fn contains(&self) -> &[String] {
+        match self {
+            Requirement::Name(_) => &[],
+            Requirement::Detailed { contains, .. } => contains,
+        }
+    }
+
+    fn fix(&self) -> Option<&str> {
+        match self {
+            Requirement::Name(_) => None,
+            Requirement::Detailed { fix, .. } => fix.as_deref(),
+        }
+    }
+}
+
+/// Outcome of one requirement.
+#[derive(Debug, Serialize)]
+struct Check {
+    category: &'static str,
+    requirement: String,
+    weight: u64,
+    ok: bool,
+    /// What was found instead, or what is missing
+    #[serde(skip_serializing_if = "Option::is_none")]
+    detail: Option<String>,
+}
+
+/// What to do about a gap.
+#[derive(Debug, Serialize)]
+struct Task {
+    title: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    fix: Option<String>,
+}
+
+#[derive(Debug, Serialize)]
+struct Benchmark {
+    archetype: String,
+    source: String,
+    project: String,
+    score: u64,
+    min_score: u64,
+    passed: bool,
+    checks: Vec<Check>,
+    tasks: Vec<Task>,
+}
+
+/// Categories in report order, with their label.
+const CATEGORIES: &[(&str, &str)] = &[
+    ("stack", "Stack"),
+    ("files", "Arquivos"),
+    ("configs", "Configurações"),
+    ("routes", "Rotas"),
+    ("services", "Dev Services"),
+    ("env", "Variáveis de ambiente"),
+];
+
+/// `*` matches any run of characters except `/`.
+fn wildcard(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern.first() {
+        None => text.is_empty(),
+        Some(b'*') => wildcard(&pattern[1..], text) || (!text.is_empty() && text[0] != b'/' && wildcard(pattern, &text[1..])),
+        Some(c) => text.first() == Some(c) && wildcard(&pattern[1..], &text[1..]),
+    }
+}
+
+/// Files of `project_dir` matching `pattern`, segment by segment.
+fn matching(project_dir: &Path, pattern: &str) -> Vec<PathBuf> {
+    let mut found = vec![project_dir.to_path_buf()];
+    for segment in pattern.trim_matches('/').split('/').filter(|s| !s.is_empty() && *s != ".") {
+        found = found
+            .into_iter()
+            .flat_map(|dir| match segment.contains('*') {
+                false => vec![dir.join(segment)].into_iter().filter(|p| p.exists()).collect::<Vec<_>>(),
+                true => fs::read_dir(&dir)
+                    .map(|entries| {
+                        let mut names: Vec<PathBuf> = entries
+                            .flatten()
+                            .filter(|e| wildcard(segment.as_bytes(), e.file_name().to_string_lossy().as_bytes()))
+                            .map(|e| e.path())
+                            .collect();
+                        names.sort();
+                        names
+                    })
+                    .unwrap_or_default(),
+            })
+            .collect();
+    }
+    found
+}
+
+/// The dx command that generates a required file, when one does.
+fn generator(path: &str) -> Option<&'static str> {
+    let path = path.trim_start_matches("./");
+    match path {
+        "Dockerfile" | ".dockerignore" => Some("dx dev-config dockerfile"),
+        "Makefile" => Some("dx dev-config tasks"),
+        "Taskfile.yml" => Some("dx dev-config tasks --format taskfile"),
+        "justfile" => Some("dx dev-config tasks --format justfile"),
+        ".env.example" => Some("dx dev-env init"),
+        "README.md" => Some("dx dev-readme generate"),
+        "asyncapi.yaml" => Some("dx generate asyncapi"),
+        _ if path.starts_with(".devcontainer/") || path == ".devcontainer" => Some("dx dev-config devcontainer"),
+        _ if path.starts_with("k8s/") || path == "k8s" => Some("dx dev-config k8s"),
+        _ if path.starts_with("charts/") || path == "charts" => Some("dx dev-config helm"),
+        _ if path.starts_with(".git/hooks/") => Some("dx dev-config hooks"),
+        _ => None,
+    }
+}
+
+/// Split `GET /healthz` into method and path.
+fn route(requirement: &str) -> (Option<String>, String) {
+    match requirement.trim().split_once(char::is_whitespace) {
+        Some((method, path)) => (Some(method.to_uppercase()), path.trim().to_string()),
+        None => (None, requirement.trim().to_string()),
+    }
+}
+
+/// Check `project_dir` against `archetype`, with the remediation task of each gap.
+fn check(project_dir: &Path, archetype: &Archetype) -> (Vec<Check>, Vec<Task>) {
+    let mut checks = Vec::new();
+    let mut tasks = Vec::new();
+    let mut add = |category: &'static str, requirement: &Requirement, detail: Option<String>, task: Option<(String, Option<String>)>| {
+        if let Some((title, fix)) = &task {
+            tasks.push(Task { title: title.clone(), fix: requirement.fix().map(str::to_string).or_else(|| fix.clone()) });
+        }
+        checks.push(Check { category, requirement: requirement.name().to_string(), weight: requirement.weight(), ok: task.is_none(), detail });
+    };
+
+    if let Some(stack) = &archetype.stack {
+        let detected = crate::dev_config::Stack::detect(project_dir);
+        let requirement = Requirement::Name(stack.clone());
+        match detected.key() == Some(stack.as_str()) {
+            true => add("stack", &requirement, None, None),
+            false => add(
+                "stack",
+                &requirement,
+                Some(format!("detectada: {}", detected)),
+                Some((format!("O arquétipo é para projetos {}; a stack detectada é {}", stack, detected), None)),
+            ),
+        }
+    }
+    for requirement in &archetype.files {
+        let path = requirement.name();
+        match matching(project_dir, path).is_empty() {
+            false => add("files", requirement, None, None),
+            true => add("files", requirement, Some("ausente".to_string()), Some((format!("Crie {}", path), generator(path).map(str::to_string)))),
+        }
+    }
+    for requirement in &archetype.configs {
+        let path = requirement.name();
+        let files = matching(project_dir, path);
+        let contents: Vec<String> = files.iter().filter_map(|f| fs::read_to_string(f).ok()).collect();
+        let missing: Vec<&String> = requirement.contains().iter().filter(|s| !contents.iter().any(|c| c.contains(s.as_str()))).collect();
+        let list = missing.iter().map(|s| s.as_str()).collect::<Vec<_>>().join(", ");
+        if files.is_empty() {
+            let title = match requirement.contains().is_empty() {
+                true => format!("Crie {}", path),
+                false => format!("Crie {} com {}", path, requirement.contains().join(", ")),
+            };
+            add("configs", requirement, Some("ausente".to_string()), Some((title, generator(path).map(str::to_string))));
+        } else if !missing.is_empty() {
+            add("configs", requirement, Some(format!("sem {}", list)), Some((format!("Adicione {} a {}", list, path), None)));
+        } else {
+            add("configs", requirement, None, None);
+        }
+    }
+    if !archetype.routes.is_empty() {
+        let routes = crate::api_client::detect_routes(project_dir);
+        for requirement in &archetype.routes {
+            let (method, path) = route(requirement.name());
+            let found = routes.iter().any(|(m, p, _)| *p == path && method.as_deref().map_or(true, |want| m.eq_ignore_ascii_case(want)));
+            match found {
+                true => add("routes", requirement, None, None),
+                false => add(
+                    "routes",
+                    requirement,
+                    Some("não encontrada no código".to_string()),
+                    Some((format!("Exponha {} na aplicação", requirement.name()), Some("dx dev-routes list".to_string()))),
+                ),
+            }
+        }
+    }
+    if !archetype.services.is_empty() {
+        let detected = crate::dev_services::detect_dependencies(project_dir).services;
+        for requirement in &archetype.services {
+            match detected.contains_key(requirement.name()) {
+                true => add("services", requirement, None, None),
+                false => add(
+                    "services",
+                    requirement,
+                    Some("não detectado".to_string()),
+                    Some((format!("Use {} no projeto (o cliente no código ou a dependência no manifesto)", requirement.name()), Some("dx dev-services".to_string()))),
+                ),
+            }
+        }
+    }
+    if !archetype.env.is_empty() {
+        let vars = crate::dev_env::scan(project_dir);
+        for requirement in &archetype.env {
+            match vars.iter().any(|v| v.name == requirement.name()) {
+                true => add("env", requirement, None, None),
+                false => add(
+                    "env",
+                    requirement,
+                    Some("não lida pelo código".to_string()),
+                    Some((format!("Leia a variável {} na configuração da aplicação", requirement.name()), Some("dx dev-env init".to_string()))),
+                ),
+            }
+        }
+    }
+    (checks, tasks)
+}
+
+/// Share of the weight of the requirements met, 0-100 (100 for an empty archetype).
+fn score(checks: &[Check]) -> u64 {
+    let total: u64 = checks.iter().map(|c| c.weight).sum();
+    let met: u64 = checks.iter().filter(|c| c.ok).map(|c| c.weight).sum();
+    if total == 0 { 100 } else { met * 100 / total }
+}
+
+fn render_markdown(report: &Benchmark, description: Option<&str>) -> String {
+    let mut md = format!("# Arquétipo {}: {}/100\n\n", report.archetype, report.score);
+    if let Some(description) = description {
+        md.push_str(&format!("{}\n\n", description));
+    }
+    md.push_str(&format!(
+        "Projeto: `{}` · origem: {} · mínimo: {} · {}\n\n",
+        report.project,
+        report.source,
+        report.min_score,
+        if report.passed { "aprovado" } else { "reprovado" }
+    ));
+    md.push_str("| Categoria | Requisito | Peso | Situação |\n|---|---|---|---|\n");
+    for (key, label) in CATEGORIES {
+        for c in report.checks.iter().filter(|c| c.category == *key) {
+            let status = match (&c.ok, &c.detail) {
+                (true, _) => "✓".to_string(),
+                (false, Some(detail)) => format!("✗ {}", detail),
+                (false, None) => "✗".to_string(),
+            };
+            md.push_str(&format!("| {} | `{}` | {} | {} |\n", label, c.requirement, c.weight, status));
+        }
+    }
+    md.push_str("\n## Tarefas\n\n");
+    if report.tasks.is_empty() {
+        md.push_str("Nenhuma: o projeto segue o arquétipo.\n");
+    }
+    for task in &report.tasks {
+        match &task.fix {
+            Some(fix) => md.push_str(&format!("- [ ] {} (`{}`)\n", task.title, fix)),
+            None => md.push_str(&format!("- [ ] {}\n", task.title)),
+        }
+    }
+    md
+}
+
+/// The archetypes offered to `project_dir` and where they come from: `file` (a YAML file with
+/// `archetypes:`, like a team profile) or the project's team profile.
+fn available(project_dir: &Path, file: Option<&Path>) -> Result<(BTreeMap<String, Archetype>, String), String> {
+    if let Some(file) = file {
+        let content = fs::read_to_string(file).map_err(|e| format!("{}: {}", file.display(), e))?;
+        let profile: crate::team_profile::Profile = serde_yaml::from_str(&content).map_err(|e| format!("{}: {}", file.display(), e))?;
+        return Ok((profile.archetypes, file.display().to_string()));
+    }
+    let team = crate::team_profile::load(project_dir, false)?.unwrap_or_default();
+    let source = team.source.clone().unwrap_or_else(|| crate::team_profile::LOCAL_FILE.to_string());
+    Ok((team.effective().archetypes, source))
+}
+
+/// `dx benchmark archetype [--name <arquétipo>] [--file <arquivo>]`: score the project against the
+/// archetype and write the gap report, with its remediation tasks, to .dx/archetype-report.md.
+/// Returns the exit code: 0 at or above the archetype's `min_score`, 1 below it, 2 when the
+/// archetype cannot be found.
+pub fn cmd_archetype(dir: Option<PathBuf>, name: Option<String>, file: Option<PathBuf>, save_file: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let (archetypes, source) = match available(&project_dir, file.as_deref()) {
+        Ok(found) => found,
+        Err(e) => {
+            eprintln!("Erro: {}", e);
+            return 2;
+        }
+    };
+    let names: Vec<&String> = archetypes.keys().collect();
+    let chosen = match (&name, names.as_slice()) {
+        (Some(name), _) => archetypes.get_key_value(name),
+        (None, [only]) => archetypes.get_key_value(*only),
+        (None, _) => None,
+    };
+    let Some((archetype_name, archetype)) = chosen else {
+        match (&name, names.is_empty()) {
+            (_, true) => eprintln!(
+                "Nenhum arquétipo definido em {}. Declare-os em `archetypes:` no perfil da equipe (ou em {}) ou use --file.",
+                source,
+                crate::team_profile::LOCAL_FILE
+            ),
+            (Some(name), false) => eprintln!("Arquétipo {} não encontrado em {}; disponíveis: {}.", name, source, names.iter().map(|n| n.as_str()).collect::<Vec<_>>().join(", ")),
+            (None, false) => eprintln!("{} define vários arquétipos; escolha um com --name: {}.", source, names.iter().map(|n| n.as_str()).collect::<Vec<_>>().join(", ")),
+        }
+        return 2;
+    };
+
+    let (checks, tasks) = check(&project_dir, archetype);
+    let score = score(&checks);
+    let min_score = archetype.min_score.unwrap_or(DEFAULT_MIN_SCORE);
+    let report = Benchmark {
+        archetype: archetype_name.clone(),
+        source,
+        project: project_dir.display().to_string(),
+        score,
+        min_score,
+        passed: score >= min_score,
+        checks,
+        tasks,
+    };
+    let markdown = render_markdown(&report, archetype.description.as_deref());
+    let mut saved = None;
+    if save_file {
+        let path = project_dir.join(REPORT_FILE);
+        if let Some(parent) = path.parent() {
+            let _ = fs::create_dir_all(parent);
+        }
+        match crate::audit::write(&path, &markdown) {
+            Ok(()) => saved = Some(path),
+            Err(e) => eprintln!("Erro ao salvar {}: {}", path.display(), e),
+        }
+    }
+    let doc = serde_json::to_value(&report).unwrap_or_default();
+    crate::sinks::publish(&crate::sinks::Report { kind: "archetype", project_dir: &project_dir, content: crate::sinks::Content::Json(doc) });
+
+    if crate::output::json() {
+        crate::output::print(&report);
+    } else {
+        println!("Arquétipo {} ({}) em {}\n", report.archetype, report.source, report.project);
+        for (key, label) in CATEGORIES {
+            let in_category: Vec<&Check> = report.checks.iter().filter(|c| c.category == *key).collect();
+            if in_category.is_empty() {
+                continue;
+            }
+            let met: u64 = in_category.iter().filter(|c| c.ok).map(|c| c.weight).sum();
+            let total: u64 = in_category.iter().map(|c| c.weight).sum();
+            println!("{} ({}/{})", label, met, total);
+            for c in in_category {
+                match (&c.ok, &c.detail) {
+                    (true, _) => println!("  ✓ {}", c.requirement),
+                    (false, Some(detail)) => println!("  ✗ {} ({})", c.requirement, detail),
+                    (false, None) => println!("  ✗ {}", c.requirement),
+                }
+            }
+        }
+        if !report.tasks.is_empty() {
+            println!("\nTarefas:");
+            for task in &report.tasks {
+                match &task.fix {
+                    Some(fix) => println!("  - {} ({})", task.title, fix),
+                    None => println!("  - {}", task.title),
+                }
+            }
+        }
+        if let Some(path) = &saved {
+            println!("\nRelatório: {}", path.display());
+        }
+        let verdict = if report.passed { "✓" } else { "✗" };
+        println!("\n{} Pontuação: {}/100 (mínimo: {}).", verdict, report.score, report.min_score);
+    }
+    if report.passed { 0 } else { 1 }
+}
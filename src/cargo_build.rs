@@ -0,0 +1,337 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Cargo builds: the dependencies declared by the packages of a workspace — the root package and the
+//! members `[workspace]` lists (globs included, `exclude` left out) — with `workspace = true` resolved
+//! against `[workspace.dependencies]`, the features each turns on and the version Cargo.lock holds
+//! for it. A member directory reads the lock file of its workspace root.
+
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::path::{Path, PathBuf};
+use toml_edit::{DocumentMut, Item, TableLike};
+
+const MANIFEST: &str = "Cargo.toml";
+const LOCK_FILE: &str = "Cargo.lock";
+/// Dependency tables of a manifest (also under `[target.'cfg(...)']`) and the kind of their dependencies.
+const TABLES: &[(&str, &str)] = &[("dependencies", "normal"), ("dev-dependencies", "dev"), ("build-dependencies", "build")];
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct CargoDependency {
+    /// Name of the crate in the registry (the `package` of a renamed dependency)
+    pub name: String,
+    /// Version requirement as written (`1`, `~0.4`, `=1.2.3`); None for path and git dependencies
+    pub requirement: Option<String>,
+    /// Version Cargo.lock holds for it (the highest locked one the requirement accepts)
+    pub version: Option<String>,
+    /// normal, dev or build
+    pub kind: String,
+    /// Features turned on in it, from its declaration and the workspace's
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub features: Vec<String>,
+    /// `default-features = false`
+    #[serde(default, skip_serializing_if = "std::ops::Not::not")]
+    pub no_default_features: bool,
+    /// `path` or `git` for a dependency outside the registry
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub local: Option<String>,
+    /// Package that declares it
+    pub package: String,
+    /// Manifest that declares it, relative to the directory read
+    pub source: String,
+}
+
+impl CargoDependency {
+    /// A version a registry or advisory database can match: the locked one, else an exact requirement (`=1.2.3`).
+    pub fn exact_version(&self) -> Option<&str> {
+        self.version.as_deref().or_else(|| {
+            let exact = self.requirement.as_deref()?.trim().strip_prefix('=')?.trim();
+            (numbers(exact).len() == 3 && !exact.contains(',')).then_some(exact)
+        })
+    }
+}
+
+/// A package of Cargo.lock.
+struct Locked {
+    name: String,
+    version: String,
+    /// From a registry (path and git packages have no advisories nor newer releases)
+    registry: bool,
+}
+
+/// The packages below the workspace root that `dir` belongs to.
+struct Workspace {
+    dir: PathBuf,
+    root: PathBuf,
+    root_manifest: DocumentMut,
+    /// Package directories read: every member at the root, only `dir` in a member
+    packages: Vec<PathBuf>,
+}
+
+fn load(path: &Path) -> Option<DocumentMut> {
+    fs::read_to_string(path).ok()?.parse::<DocumentMut>().ok()
+}
+
+fn relative(dir: &Path, path: &Path) -> String {
+    let mut up = String::new();
+    for base in dir.ancestors() {
+        if let Ok(rest) = path.strip_prefix(base) {
+            return format!("{}{}", up, rest.to_string_lossy().replace('\\', "/"));
+        }
+        up.push_str("../");
+    }
+    path.to_string_lossy().into_owned()
+}
+
+fn wildcard(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern.first() {
+        None => text.is_empty(),
+        Some(b'*') => wildcard(&pattern[1..], text) || (!text.is_empty() && wildcard(pattern, &text[1..])),
+        Some(c) => text.first() == Some(c) && wildcard(&pattern[1..], &text[1..]),
+    }
+}
+
+/// Directories below `root` matching a `members` entry (`crates/*`), segment by segment.
+fn expand(root: &Path, pattern: &str) -> Vec<PathBuf> {
+    let mut found = vec![root.to_path_buf()];
+    for segment in pattern.trim_matches('/').split('/').filter(|s| !s.is_empty() && *s != ".") {
+        found = found
+            .into_iter()
+            .flat_map(|dir| {
+                if !segment.contains('*') {
+                    return vec![dir.join(segment)];
+                }
+                let mut dirs: Vec<PathBuf> = fs::read_dir(&dir)
+                    .map(|entries| {
+                        entries
+                            .flatten()
+                            .filter(|e| e.path().is_dir() && wildcard(segment.as_bytes(), e.file_name().to_string_lossy().as_bytes()))
+                            .map(|e| e.path())
+                            .collect()
+                    })
+                    .unwrap_or_default();
+                dirs.sort();
+                dirs
+            })
+            .collect();
+    }
+    found
+}
+
+fn strings(table: Option<&dyn TableLike>, key: &str) -> Vec<String> {
+    table
+        .and_then(|t| t.get(key))
+        .and_then(Item::as_array)
+        .map(|a| a.iter().filter_map(|v| v.as_str().map(str::to_string)).collect())
+        .unwrap_or_default()
+}
+
+/// The package directories of the workspace whose manifest is `manifest` at `root`: the root
+/// package, then the members that have a manifest.
+fn members(root: &Path, manifest: &DocumentMut) -> Vec<PathBuf> {
+    let mut dirs = Vec::new();
+    if manifest.contains_key("package") {
+        dirs.push(root.to_path_buf());
+    }
+    let workspace = manifest.get("workspace").and_then(Item::as_table_like);
+    let excluded: Vec<PathBuf> = strings(workspace, "exclude").iter().flat_map(|e| expand(root, e)).collect();
+    for pattern in strings(workspace, "members") {
+        for dir in expand(root, &pattern) {
+            if dir.join(MANIFEST).is_file() && !excluded.contains(&dir) && !dirs.contains(&dir) {
+                dirs.push(dir);
+            }
+        }
+    }
+    dirs
+}
+
+impl Workspace {
+    /// The workspace of `dir`: the nearest ancestor with a `[workspace]` that lists it, else `dir` alone.
+    fn of(dir: &Path) -> Option<Workspace> {
+        let dir = dir.canonicalize().unwrap_or_else(|_| dir.to_path_buf());
+        let own = load(&dir.join(MANIFEST))?;
+        for root in dir.ancestors() {
+            let Some(manifest) = (if root == dir { Some(own.clone()) } else { load(&root.join(MANIFEST)) }) else { continue };
+            if !manifest.contains_key("workspace") {
+                continue;
+            }
+            let members = members(root, &manifest);
+            if root == dir || members.contains(&dir) {
+                let packages = if root == dir { members } else { vec![dir.clone()] };
+                return Some(Workspace { root: root.to_path_buf(), root_manifest: manifest, packages, dir });
+            }
+        }
+        Some(Workspace { root: dir.clone(), packages: vec![dir.clone()], root_manifest: own, dir })
+    }
+
+    fn lock(&self) -> Vec<Locked> {
+        let Some(doc) = load(&self.root.join(LOCK_FILE)) else { return Vec::new() };
+        let Some(packages) = doc.get("package").and_then(Item::as_array_of_tables) else { return Vec::new() };
+        packages
+            .iter()
+            .filter_map(|p| {
+                Some(Locked {
+                    name: p.get("name")?.as_str()?.to_string(),
+                    version: p.get("version")?.as_str()?.to_string(),
+                    registry: p.get("source").and_then(Item::as_str).is_some_and(|s| s.starts_with("registry+") || s.starts_with("sparse+")),
+                })
+            })
+            .collect()
+    }
+}
+
+/// What a dependency entry says, the workspace's declaration merged in for `workspace = true`.
+struct Spec {
+    package: Option<String>,
+    requirement: Option<String>,
+    features: Vec<String>,
+    default_features: bool,
+    local: Option<String>,
+}
+
+fn spec(item: &Item) -> Spec {
+    if let Some(version) = item.as_str() {
+        return Spec { package: None, requirement: Some(version.to_string()), features: Vec::new(), default_features: true, local: None };
+    }
+    let table = item.as_table_like();
+    let get = |key: &str| table.and_then(|t| t.get(key));
+    Spec {
+        package: get("package").and_then(Item::as_str).map(str::to_string),
+        requirement: get("version").and_then(Item::as_str).map(str::to_string),
+        features: strings(table, "features"),
+        default_features: get("default-features").or_else(|| get("default_features")).and_then(Item::as_bool).unwrap_or(true),
+        local: ["path", "git"].into_iter().find(|k| get(k).is_some()).map(str::to_string),
+    }
+}
+
+/// Dependency tables of `manifest` with their kind, the platform-specific ones included.
+fn tables<'a>(manifest: &'a DocumentMut) -> Vec<(&'a dyn TableLike, &'static str)> {
+    let mut found = Vec::new();
+    let targets: Vec<&dyn TableLike> = manifest
+        .get("target")
+        .and_then(Item::as_table_like)
+        .map(|t| t.iter().filter_map(|(_, item)| item.as_table_like()).collect())
+        .unwrap_or_default();
+    for (name, kind) in TABLES {
+        let own = manifest.get(name).and_then(Item::as_table_like);
+        for table in own.into_iter().chain(targets.iter().filter_map(|t| t.get(name).and_then(Item::as_table_like))) {
+            found.push((table, *kind));
+        }
+    }
+    found
+}
+
+/// The numeric parts of a version (`1.2.3-beta` is [1, 2, 3]; `1.*` is [1]).
+fn numbers(version: &str) -> Vec<u64> {
+    version.split(['-', '+']).next().unwrap_or("").split('.').map_while(|p| p.trim().parse().ok()).take(3).collect()
+}
+
+/// Whether `version` meets a Cargo requirement: `=` exact, `~` the same minor, `^` (the default)
+/// the same leftmost non-zero part; comparisons (`>=`, `<`) are taken as met.
+pub fn matches(requirement: &str, version: &str) -> bool {
+    let clause = requirement.split(',').next().unwrap_or("").trim();
+    let (op, rest) = match clause.chars().next() {
+        Some(c @ ('=' | '~' | '^')) => (c, clause[1..].trim()),
+        Some('>' | '<') => return true,
+        _ => ('^', clause),
+    };
+    let wanted = numbers(rest);
+    if wanted.is_empty() {
+        return true;
+    }
+    let mut got = numbers(version);
+    got.resize(3, 0);
+    let mut floor = wanted.clone();
+    floor.resize(3, 0);
+    let same = |n: usize| wanted[..n] == got[..n];
+    match op {
+        '=' => same(wanted.len()),
+        '~' => same(wanted.len().min(2)) && got >= floor,
+        _ => same(wanted.iter().position(|&n| n != 0).map_or(wanted.len(), |i| i + 1)) && got >= floor,
+    }
+}
+
+/// The dependencies declared by the packages of `dir` — every member of a workspace root — with
+/// the version each is locked to.
+pub fn dependencies(dir: &Path) -> Vec<CargoDependency> {
+    let Some(workspace) = Workspace::of(dir) else { return Vec::new() };
+    let lock = workspace.lock();
+    let shared = workspace.root_manifest.get("workspace").and_then(Item::as_table_like).and_then(|w| w.get("dependencies")).and_then(Item::as_table_like);
+    let mut found = Vec::new();
+    for package_dir in &workspace.packages {
+        let manifest_path = package_dir.join(MANIFEST);
+        let Some(manifest) = load(&manifest_path) else { continue };
+        let package = manifest.get("package").and_then(|p| p.get("name")).and_then(Item::as_str).unwrap_or_default().to_string();
+        let source = relative(&workspace.dir, &manifest_path);
+        for (table, kind) in tables(&manifest) {
+            for (key, item) in table.iter() {
+                let own = spec(item);
+                let inherited = item.as_table_like().and_then(|t| t.get("workspace")).and_then(Item::as_bool) == Some(true);
+                let spec = match shared.and_then(|s| s.get(key)).filter(|_| inherited).map(spec) {
+                    Some(base) => Spec { features: [base.features.clone(), own.features].concat(), ..base },
+                    None => own,
+                };
+                let name = spec.package.unwrap_or_else(|| key.to_string());
+                let version = match (&spec.local, &spec.requirement) {
+                    (None, Some(requirement)) => lock
+                        .iter()
+                        .filter(|l| l.name == name && l.registry && matches(requirement, &l.version))
+                        .max_by_key(|l| numbers(&l.version))
+                        .map(|l| l.version.clone()),
+                    _ => None,
+                };
+                let mut features = spec.features;
+                features.sort();
+                features.dedup();
+                found.push(CargoDependency {
+                    name,
+                    requirement: spec.requirement.filter(|_| spec.local.is_none()),
+                    version,
+                    kind: kind.to_string(),
+                    features,
+                    no_default_features: !spec.default_features,
+                    local: spec.local,
+                    package: package.clone(),
+                    source: source.clone(),
+                });
+            }
+        }
+    }
+    found
+}
+
+/// Every registry crate with a version pinned for `dir`, as (name, version, source): all Cargo.lock
+/// holds (transitive ones and optional ones of any feature included) — the manifest that declares a
+/// direct dependency is its source — else the exact requirements (`=1.2.3`) of the manifests.
+pub fn pinned(dir: &Path) -> Vec<(String, String, String)> {
+    let Some(workspace) = Workspace::of(dir) else { return Vec::new() };
+    let declared = dependencies(dir);
+    let lock = workspace.lock();
+    if lock.is_empty() {
+        return declared
+            .iter()
+            .filter(|d| d.local.is_none())
+            .filter_map(|d| Some((d.name.clone(), d.exact_version()?.to_string(), d.source.clone())))
+            .collect();
+    }
+    let lock_source = relative(&workspace.dir, &workspace.root.join(LOCK_FILE));
+    lock.into_iter()
+        .filter(|l| l.registry)
+        .map(|l| {
+            let source = declared.iter().find(|d| d.name == l.name && d.version.as_deref() == Some(&l.version)).map(|d| d.source.clone());
+            (l.name, l.version, source.unwrap_or_else(|| lock_source.clone()))
+        })
+        .collect()
+}
+
+/// Files that change what `dependencies` finds besides the Cargo.toml and Cargo.lock of `dir`: the
+/// member manifests, or the manifest and lock file of the workspace root of a member.
+pub fn manifests(dir: &Path) -> Vec<PathBuf> {
+    let Some(workspace) = Workspace::of(dir) else { return Vec::new() };
+    let mut files: Vec<PathBuf> = workspace.packages.iter().map(|p| p.join(MANIFEST)).collect();
+    files.extend([workspace.root.join(MANIFEST), workspace.root.join(LOCK_FILE)]);
+    files.retain(|f| f.exists() && f.parent() != Some(workspace.dir.as_path()));
+    files.sort();
+    files.dedup();
+    files
+}
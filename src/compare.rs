@@ -0,0 +1,183 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::collections::BTreeMap;
+use std::path::{Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum CompareFormat {
+    /// Diferenças por categoria
+    Text,
+    /// Objeto JSON (para CI)
+    Json,
+}
+
+/// What dx detects in one project, reduced to comparable key → value maps.
+#[derive(Debug, Clone, Default)]
+pub struct Snapshot {
+    pub stack: String,
+    /// `<ecosystem> <name>` → pinned version(s)
+    pub dependencies: BTreeMap<String, String>,
+    /// Variable → "obrigatória" or its default
+    pub env: BTreeMap<String, String>,
+    /// Dev Service → image
+    pub services: BTreeMap<String, String>,
+}
+
+pub fn snapshot(project_dir: &Path) -> Snapshot {
+    let mut dependencies: BTreeMap<String, Vec<String>> = BTreeMap::new();
+    for p in crate::dependency_audit::collect(project_dir) {
+        dependencies.entry(format!("{} {}", p.ecosystem, p.name)).or_default().push(p.version);
+    }
+    let env = crate::dev_env::scan(project_dir)
+        .into_iter()
+        .map(|v| {
+            let value = match (v.required, v.default) {
+                (true, _) => "obrigatória".to_string(),
+                (false, Some(d)) => format!("padrão: {}", d),
+                (false, None) => "opcional".to_string(),
+            };
+            (v.name, value)
+        })
+        .collect();
+    let services = crate::dev_services::detect_dependencies(project_dir)
+        .services
+        .into_iter()
+        .map(|(name, s)| (name, s.image))
+        .collect();
+    Snapshot {
+        stack: crate::dev_config::Stack::detect(project_dir).to_string(),
+        dependencies: dependencies.into_iter().map(|(k, v)| (k, v.join(", "))).collect(),
+        env,
+        services,
+    }
+}
+
+/// Differences between two maps of one category.
+#[derive(Debug, Clone, Default)]
+pub struct MapDiff {
+    pub only_a: BTreeMap<String, String>,
+    pub only_b: BTreeMap<String, String>,
+    /// Key → (value in A, value in B)
+    pub changed: BTreeMap<String, (String, String)>,
+    pub same: usize,
+}
+
+impl MapDiff {
+    fn new(a: &BTreeMap<String, String>, b: &BTreeMap<String, String>) -> MapDiff {
+        let mut diff = MapDiff::default();
+        for (k, va) in a {
+            match b.get(k) {
+                None => {
+                    diff.only_a.insert(k.clone(), va.clone());
+                }
+                Some(vb) if vb != va => {
+                    diff.changed.insert(k.clone(), (va.clone(), vb.clone()));
+                }
+                Some(_) => diff.same += 1,
+            }
+        }
+        for (k, vb) in b.iter().filter(|(k, _)| !a.contains_key(*k)) {
+            diff.only_b.insert(k.clone(), vb.clone());
+        }
+        diff
+    }
+
+    fn is_empty(&self) -> bool {
+        self.only_a.is_empty() && self.only_b.is_empty() && self.changed.is_empty()
+    }
+
+    fn to_json(&self) -> serde_json::Value {
+        let changed: BTreeMap<&String, serde_json::Value> =
+            self.changed.iter().map(|(k, (a, b))| (k, serde_json::json!({ "a": a, "b": b }))).collect();
+        serde_json::json!({ "only_a": self.only_a, "only_b": self.only_b, "changed": changed, "same": self.same })
+    }
+}
+
+/// Comparison of two projects, category by category.
+#[derive(Debug, Clone, Default)]
+pub struct Comparison {
+    pub stack: (String, String),
+    pub categories: Vec<(&'static str, &'static str, MapDiff)>,
+}
+
+impl Comparison {
+    pub fn new(a: &Snapshot, b: &Snapshot) -> Comparison {
+        Comparison {
+            stack: (a.stack.clone(), b.stack.clone()),
+            categories: vec![
+                ("dependencies", "Dependências", MapDiff::new(&a.dependencies, &b.dependencies)),
+                ("env", "Variáveis de ambiente", MapDiff::new(&a.env, &b.env)),
+                ("services", "Dev Services", MapDiff::new(&a.services, &b.services)),
+            ],
+        }
+    }
+
+    pub fn is_equal(&self) -> bool {
+        self.stack.0 == self.stack.1 && self.categories.iter().all(|(_, _, d)| d.is_empty())
+    }
+}
+
+fn render_text(a: &Path, b: &Path, cmp: &Comparison) -> String {
+    let mut out = format!("Comparando A ({}) com B ({})\n", a.display(), b.display());
+    out.push_str("(- só em A, + só em B, ~ diferente)\n\n");
+    if cmp.stack.0 == cmp.stack.1 {
+        out.push_str(&format!("Stack: {} (igual)\n", cmp.stack.0));
+    } else {
+        out.push_str(&format!("Stack: ~ {} → {}\n", cmp.stack.0, cmp.stack.1));
+    }
+    for (_, title, diff) in &cmp.categories {
+        out.push_str(&format!(
+            "\n{}: {} só em A, {} só em B, {} diferente(s), {} igual(is)\n",
+            title,
+            diff.only_a.len(),
+            diff.only_b.len(),
+            diff.changed.len(),
+            diff.same
+        ));
+        for (k, v) in &diff.only_a {
+            out.push_str(&format!("  - {} ({})\n", k, v));
+        }
+        for (k, v) in &diff.only_b {
+            out.push_str(&format!("  + {} ({})\n", k, v));
+        }
+        for (k, (va, vb)) in &diff.changed {
+            out.push_str(&format!("  ~ {}: {} → {}\n", k, va, vb));
+        }
+    }
+    if cmp.is_equal() {
+        out.push_str("\nOs projetos são equivalentes para o dx.\n");
+    }
+    out
+}
+
+fn render_json(a: &Path, b: &Path, cmp: &Comparison) -> String {
+    let mut doc = serde_json::json!({
+        "a": a.display().to_string(),
+        "b": b.display().to_string(),
+        "equal": cmp.is_equal(),
+        "stack": { "a": cmp.stack.0, "b": cmp.stack.1 },
+    });
+    for (key, _, diff) in &cmp.categories {
+        doc[*key] = diff.to_json();
+    }
+    serde_json::to_string_pretty(&doc).unwrap_or_default()
+}
+
+/// `dx compare <A> <B>`: diff what dx detects in two projects (stack, dependencies, environment
+/// variables, Dev Services), e.g. a service against a golden template. Returns the exit code
+/// like `diff`: 0 when equivalent, 1 when they differ, 2 when a directory does not exist.
+pub fn cmd_compare(a: PathBuf, b: PathBuf, format: CompareFormat) -> i32 {
+    for dir in [&a, &b] {
+        if !dir.is_dir() {
+            eprintln!("Diretório não encontrado: {}", dir.display());
+            return 2;
+        }
+    }
+    let cmp = Comparison::new(&snapshot(&a), &snapshot(&b));
+    match format {
+        CompareFormat::Text => print!("{}", render_text(&a, &b, &cmp)),
+        CompareFormat::Json => println!("{}", render_json(&a, &b, &cmp)),
+    }
+    if cmp.is_equal() { 0 } else { 1 }
+}
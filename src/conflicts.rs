@@ -0,0 +1,259 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Conflicts between what a generator (`dx dev-config ...`, `dx dev-env init`, `dx dev-infra compose`)
+//! produced and a file already in the project that dx did not write. On a terminal the user goes
+//! through the differences hunk by hunk (keep the current lines, take the generated ones or edit
+//! them); elsewhere the `conflict_strategy` setting (`--strategy`) decides for the whole file.
+
+use std::fs;
+use std::io::{self, BufRead, IsTerminal, Write};
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+/// Lines shown around each hunk.
+const CONTEXT: usize = 3;
+const OURS_MARKER: &str = "<<<<<<< atual";
+const SEPARATOR: &str = "=======";
+const THEIRS_MARKER: &str = ">>>>>>> dx";
+
+/// How a conflict is settled.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum Strategy {
+    /// Pergunta trecho a trecho no terminal (sem terminal, age como fail)
+    Ask,
+    /// Mantém o arquivo atual
+    Ours,
+    /// Substitui pelo arquivo gerado
+    Theirs,
+    /// Não grava nada e sai com erro
+    Fail,
+}
+
+impl Strategy {
+    pub fn key(self) -> &'static str {
+        match self {
+            Strategy::Ask => "ask",
+            Strategy::Ours => "ours",
+            Strategy::Theirs => "theirs",
+            Strategy::Fail => "fail",
+        }
+    }
+}
+
+/// Validator of the `conflict_strategy` setting.
+pub fn validate(v: &str) -> Result<String, String> {
+    match v.trim().to_lowercase().as_str() {
+        s @ ("ask" | "ours" | "theirs" | "fail") => Ok(s.to_string()),
+        _ => Err("espera ask, ours, theirs ou fail".to_string()),
+    }
+}
+
+fn configured() -> Strategy {
+    match crate::settings::get("conflict_strategy").as_str() {
+        "ours" => Strategy::Ours,
+        "theirs" => Strategy::Theirs,
+        "fail" => Strategy::Fail,
+        _ => Strategy::Ask,
+    }
+}
+
+/// The `replaceable` check of `settle` for a generator that marks its files with `header`: a file is
+/// replaceable when it is missing or its first line (the second, after a `#!` line) contains
+/// `header`, whatever comment syntax precedes it.
+pub fn generated_by(header: &str) -> impl Fn(&Path) -> bool + '_ {
+    move |path| {
+        fs::read_to_string(path).map_or(true, |content| {
+            let mut lines = content.lines();
+            let first = lines.next().unwrap_or_default();
+            let marked = if first.starts_with("#!") { lines.next().unwrap_or_default() } else { first };
+            marked.contains(header)
+        })
+    }
+}
+
+/// Settle the generated `files` that would replace an existing file dx did not write (`replaceable`
+/// is false): `force` takes the generated content, otherwise the configured strategy decides. Files
+/// kept as they are leave the list; merged ones get the merged content. Returns false, after saying
+/// why, when the command must stop without writing anything.
+pub fn settle(files: &mut Vec<(PathBuf, String)>, replaceable: impl Fn(&Path) -> bool, force: bool) -> bool {
+    let conflicts: Vec<usize> = (0..files.len()).filter(|&i| files[i].0.exists() && !replaceable(&files[i].0)).collect();
+    if conflicts.is_empty() || force {
+        return true;
+    }
+    let strategy = match configured() {
+        Strategy::Ask if !(io::stdin().is_terminal() && io::stderr().is_terminal()) => Strategy::Fail,
+        s => s,
+    };
+    match strategy {
+        Strategy::Theirs => true,
+        Strategy::Ours => {
+            for &i in conflicts.iter().rev() {
+                eprintln!("{} já existe e não foi gerado pelo dx; mantido.", files[i].0.display());
+                files.remove(i);
+            }
+            true
+        }
+        Strategy::Fail => {
+            for &i in &conflicts {
+                eprintln!("{} já existe e não foi gerado pelo dx.", files[i].0.display());
+            }
+            eprintln!("Use --strategy theirs (ou --force) para substituir, --strategy ours para manter ou --no-save para apenas imprimir.");
+            false
+        }
+        Strategy::Ask => {
+            let mut kept = Vec::new();
+            for &i in &conflicts {
+                let current = fs::read_to_string(&files[i].0).unwrap_or_default();
+                match merge(&files[i].0, &current, &files[i].1) {
+                    Some(merged) if merged == current => kept.push(i),
+                    Some(merged) => files[i].1 = merged,
+                    None => {
+                        eprintln!("Nada foi alterado.");
+                        return false;
+                    }
+                }
+            }
+            for i in kept.into_iter().rev() {
+                files.remove(i);
+            }
+            true
+        }
+    }
+}
+
+/// Choice for one hunk.
+enum Choice {
+    Ours,
+    Theirs,
+    Edited(Vec<String>),
+}
+
+/// Go through the hunks that differ between `current` and `generated`, asking which side to keep.
+/// None when the user cancels.
+fn merge(path: &Path, current: &str, generated: &str) -> Option<String> {
+    let a: Vec<&str> = current.lines().collect();
+    let b: Vec<&str> = generated.lines().collect();
+    let ops = crate::audit::diff_lines(&a, &b);
+    // Each hunk is a run of changed lines: (first op, end op)
+    let mut hunks = Vec::new();
+    let mut i = 0;
+    while i < ops.len() {
+        if ops[i].0 == ' ' {
+            i += 1;
+            continue;
+        }
+        let start = i;
+        while i < ops.len() && ops[i].0 != ' ' {
+            i += 1;
+        }
+        hunks.push((start, i));
+    }
+    if hunks.is_empty() {
+        return Some(current.to_string());
+    }
+
+    eprintln!("{} já existe e não foi gerado pelo dx: {} trecho(s) diferem do que seria gerado.", path.display(), hunks.len());
+    eprintln!("(- arquivo atual, + gerado pelo dx)");
+    let mut rest: Option<bool> = None;
+    let mut choices = Vec::new();
+    for (n, &(start, end)) in hunks.iter().enumerate() {
+        if let Some(theirs) = rest {
+            choices.push(if theirs { Choice::Theirs } else { Choice::Ours });
+            continue;
+        }
+        eprintln!("\n@@ trecho {}/{} @@", n + 1, hunks.len());
+        let after_previous = if n > 0 { hunks[n - 1].1 } else { 0 };
+        for (kind, line) in &ops[start.saturating_sub(CONTEXT).max(after_previous)..start] {
+            eprintln!("{}{}", kind, line);
+        }
+        for (kind, line) in &ops[start..end] {
+            eprintln!("{}{}", kind, line);
+        }
+        for (kind, line) in ops[end..].iter().take(CONTEXT).take_while(|(k, _)| *k == ' ') {
+            eprintln!("{}{}", kind, line);
+        }
+        let side = |k: char| ops[start..end].iter().filter(|(kind, _)| *kind == k).map(|(_, l)| l.to_string()).collect::<Vec<_>>();
+        loop {
+            eprint!("[o] manter o atual, [t] usar o gerado, [e] editar, [O]/[T] o mesmo nos demais trechos, [q] cancelar: ");
+            let _ = io::stderr().flush();
+            let mut answer = String::new();
+            if io::stdin().lock().read_line(&mut answer).unwrap_or(0) == 0 {
+                return None;
+            }
+            match answer.trim() {
+                "o" => choices.push(Choice::Ours),
+                "t" => choices.push(Choice::Theirs),
+                "O" => {
+                    rest = Some(false);
+                    choices.push(Choice::Ours);
+                }
+                "T" => {
+                    rest = Some(true);
+                    choices.push(Choice::Theirs);
+                }
+                "e" => match edit(path, &side('-'), &side('+')) {
+                    Some(lines) => choices.push(Choice::Edited(lines)),
+                    None => continue,
+                },
+                "q" => return None,
+                _ => continue,
+            }
+            break;
+        }
+    }
+
+    let mut out: Vec<String> = Vec::new();
+    let mut previous = 0;
+    for (&(start, end), choice) in hunks.iter().zip(choices) {
+        out.extend(ops[previous..start].iter().map(|(_, l)| l.to_string()));
+        match choice {
+            Choice::Ours => out.extend(ops[start..end].iter().filter(|(k, _)| *k == '-').map(|(_, l)| l.to_string())),
+            Choice::Theirs => out.extend(ops[start..end].iter().filter(|(k, _)| *k == '+').map(|(_, l)| l.to_string())),
+            Choice::Edited(lines) => out.extend(lines),
+        }
+        previous = end;
+    }
+    out.extend(ops[previous..].iter().map(|(_, l)| l.to_string()));
+    let newline = if generated.ends_with('\n') || current.ends_with('\n') { "\n" } else { "" };
+    Some(out.join("\n") + newline)
+}
+
+/// Open the hunk, both sides between conflict markers, in `$VISUAL`/`$EDITOR` (vi by default) and
+/// return what the user left. None (ask again) when the editor fails or markers remain.
+fn edit(path: &Path, ours: &[String], theirs: &[String]) -> Option<Vec<String>> {
+    let name = path.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_default();
+    let file = std::env::temp_dir().join(format!("dx-conflito-{}-{}", std::process::id(), name));
+    let content = [vec![OURS_MARKER.to_string()], ours.to_vec(), vec![SEPARATOR.to_string()], theirs.to_vec(), vec![THEIRS_MARKER.to_string()]].concat();
+    if let Err(e) = fs::write(&file, content.join("\n") + "\n") {
+        eprintln!("Erro ao criar {}: {}", file.display(), e);
+        return None;
+    }
+    let editor = std::env::var("VISUAL").or_else(|_| std::env::var("EDITOR")).unwrap_or_else(|_| "vi".to_string());
+    let mut words = editor.split_whitespace();
+    let status = Command::new(words.next().unwrap_or("vi")).args(words).arg(&file).status();
+    let edited = fs::read_to_string(&file);
+    let _ = fs::remove_file(&file);
+    match (status, edited) {
+        (Err(e), _) => {
+            eprintln!("Erro ao abrir o editor {}: {}", editor, e);
+            None
+        }
+        (Ok(s), _) if !s.success() => {
+            eprintln!("{} saiu com {}; escolha de novo.", editor, s);
+            None
+        }
+        (Ok(_), Err(e)) => {
+            eprintln!("Erro ao ler {}: {}", file.display(), e);
+            None
+        }
+        (Ok(_), Ok(edited)) => {
+            let lines: Vec<String> = edited.lines().map(str::to_string).collect();
+            if lines.iter().any(|l| l == OURS_MARKER || l == SEPARATOR || l == THEIRS_MARKER) {
+                eprintln!("O trecho ainda tem marcadores de conflito; remova-os ao editar.");
+                return None;
+            }
+            Some(lines)
+        }
+    }
+}
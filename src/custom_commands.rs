@@ -0,0 +1,186 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::tasks::{self, CustomCommand, OnFailure, Step};
+use std::collections::BTreeMap;
+use std::path::Path;
+use std::process::Command;
+
+/// Aliases and custom commands being expanded by the outer dx processes, separated by commas, so a
+/// name that comes back (`a: b` and `b: a`) stops instead of spawning dx forever.
+const CHAIN_ENV: &str = "DX_COMMAND_CHAIN";
+
+/// The names expanded so far plus `name`; exits with the cycle when `name` is already among them.
+fn enter(name: &str) -> Vec<String> {
+    let mut chain: Vec<String> = std::env::var(CHAIN_ENV).unwrap_or_default().split(',').filter(|n| !n.is_empty()).map(str::to_string).collect();
+    let repeated = chain.iter().any(|n| n == name);
+    chain.push(name.to_string());
+    if repeated {
+        eprintln!("Ciclo de aliases/comandos em {}: {}", tasks::DX_FILE, chain.join(" → "));
+        crate::exit(2);
+    }
+    chain
+}
+
+/// Handle `dx <nome>` for names that are not built-in subcommands: aliases and
+/// custom commands declared in dx.yaml (current directory), then plugins.
+pub fn dispatch(args: Vec<String>) {
+    let Some((name, extra)) = args.split_first() else { return };
+    let project_dir = std::env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf());
+    let dx_file = match tasks::load(&project_dir) {
+        Ok(f) => f.unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", tasks::DX_FILE, e);
+            crate::exit(2);
+        }
+    };
+
+    if let Some(target) = dx_file.aliases.get(name) {
+        let chain = enter(name);
+        let mut alias_args = split_args(target);
+        alias_args.extend(extra.iter().cloned());
+        let code = run_dx(&project_dir, &alias_args, &BTreeMap::new(), &chain);
+        crate::exit(code);
+    }
+
+    if let Some(command) = dx_file.commands.get(name) {
+        let chain = enter(name);
+        crate::trust::ensure_trusted(&project_dir);
+        if !extra.is_empty() {
+            eprintln!("Aviso: argumentos extras ignorados para '{}': {}", name, extra.join(" "));
+        }
+        let code = run_command(&project_dir, name, command, &chain);
+        crate::exit(code);
+    }
+
+    if let Some(plugin) = crate::plugins::find(&project_dir, name) {
+        let code = crate::plugins::run(&plugin, &project_dir, extra);
+        crate::exit(code);
+    }
+
+    eprintln!("Comando desconhecido: '{}'. Veja 'dx --help', os comandos do dx.yaml com 'dx run' ou os plugins com 'dx plugins'.", name);
+    crate::exit(2);
+}
+
+/// Run the steps in order, applying each step's retries and failure policy.
+/// Returns the exit code for the whole command.
+fn run_command(project_dir: &Path, name: &str, command: &CustomCommand, chain: &[String]) -> i32 {
+    let total = command.steps.len();
+    let mut failures = 0;
+    for (i, step) in command.steps.iter().enumerate() {
+        let label = step_label(step);
+        println!("[{}/{}] {}", i + 1, total, label);
+
+        let mut env = command.env.clone();
+        env.extend(step.env.clone());
+        env.insert("DX_COMMAND".to_string(), name.to_string());
+
+        let mut code = run_step(project_dir, step, &env, chain);
+        let mut attempt = 0;
+        while code != 0 && attempt < step.retries {
+            attempt += 1;
+            eprintln!("  falhou (código {}); tentativa {}/{}...", code, attempt, step.retries);
+            code = run_step(project_dir, step, &env, chain);
+        }
+        if code == 0 {
+            continue;
+        }
+        match step.on_failure {
+            OnFailure::Abort => {
+                eprintln!("Passo '{}' falhou (código {}). Abortando '{}'.", label, code, name);
+                return code;
+            }
+            OnFailure::Continue => {
+                eprintln!("Passo '{}' falhou (código {}); continuando (on_failure: continue).", label, code);
+                failures += 1;
+            }
+        }
+    }
+    if failures > 0 {
+        println!("'{}' concluído com {} passo(s) com falha ignorada.", name, failures);
+    } else {
+        println!("'{}' concluído.", name);
+    }
+    0
+}
+
+fn step_label(step: &Step) -> String {
+    if let Some(n) = &step.name {
+        return n.clone();
+    }
+    match (&step.dx, &step.run) {
+        (Some(dx), _) => format!("dx {}", dx),
+        (None, Some(run)) => run.clone(),
+        (None, None) => "(passo vazio)".to_string(),
+    }
+}
+
+fn run_step(project_dir: &Path, step: &Step, env: &BTreeMap<String, String>, chain: &[String]) -> i32 {
+    match (&step.dx, &step.run) {
+        (Some(dx), _) => run_dx(project_dir, &split_args(dx), env, chain),
+        (None, Some(run)) => match tasks::shell_command(project_dir, run, env).and_then(|mut c| c.status()) {
+            Ok(status) => status.code().unwrap_or(1),
+            Err(e) => {
+                eprintln!("Erro ao executar '{}': {}", run, e);
+                1
+            }
+        },
+        (None, None) => {
+            eprintln!("Passo sem `dx` nem `run` em {}.", tasks::DX_FILE);
+            1
+        }
+    }
+}
+
+/// Run the current dx binary with `args` so nested steps use the same version; `chain` (the aliases
+/// and commands being expanded) goes along for the cycle check.
+fn run_dx(project_dir: &Path, args: &[String], env: &BTreeMap<String, String>, chain: &[String]) -> i32 {
+    let exe = std::env::current_exe().unwrap_or_else(|_| "dx".into());
+    // The outer dx already notifies and records history for the whole command
+    let status = Command::new(exe)
+        .args(args)
+        .current_dir(project_dir)
+        .envs(env)
+        .env(crate::notifications::NOTIFY_AFTER_ENV, "0")
+        .env(crate::history::HISTORY_ENV, "0")
+        .env(crate::audit::RUN_ENV, crate::audit::run_id())
+        .env(crate::sandbox::SANDBOX_ENV, if crate::sandbox::active() { "1" } else { "0" })
+        .env(CHAIN_ENV, chain.join(","))
+        .status();
+    match status {
+        Ok(status) => status.code().unwrap_or(1),
+        Err(e) => {
+            eprintln!("Erro ao executar 'dx {}': {}", args.join(" "), e);
+            1
+        }
+    }
+}
+
+/// Split a command line on whitespace, honoring single and double quotes.
+fn split_args(s: &str) -> Vec<String> {
+    let mut args = Vec::new();
+    let mut current = String::new();
+    let mut quote: Option<char> = None;
+    let mut has_token = false;
+    for c in s.chars() {
+        match (quote, c) {
+            (Some(q), c) if c == q => quote = None,
+            (Some(_), c) => current.push(c),
+            (None, '"' | '\'') => {
+                quote = Some(c);
+                has_token = true;
+            }
+            (None, c) if c.is_whitespace() => {
+                if has_token || !current.is_empty() {
+                    args.push(std::mem::take(&mut current));
+                    has_token = false;
+                }
+            }
+            (None, c) => current.push(c),
+        }
+    }
+    if has_token || !current.is_empty() {
+        args.push(current);
+    }
+    args
+}
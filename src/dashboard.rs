@@ -0,0 +1,442 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::{self, IsTerminal, Read, Write};
+use std::net::{SocketAddr, TcpStream};
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+use std::time::{Duration, Instant};
+
+/// How often the services and the log tail are read again.
+const REFRESH: Duration = Duration::from_secs(2);
+/// Log lines fetched for the selected service.
+const LOG_TAIL: usize = 200;
+/// A published port that does not accept a connection this fast counts as down.
+const PORT_TIMEOUT: Duration = Duration::from_millis(300);
+
+/// One service of the dashboard: its container state and whether its published ports answer.
+#[derive(Debug, Clone, Serialize)]
+pub struct ServiceRow {
+    pub name: String,
+    pub image: String,
+    /// Container state ("running", "exited (1)", ...), "não criado" without a container, "?" without Docker
+    pub state: String,
+    /// Healthcheck status; empty when the service has none
+    pub health: String,
+    /// Published ports as (host, container)
+    pub ports: Vec<(u16, u16)>,
+    /// Host ports that accept a TCP connection (only checked for running containers)
+    pub reachable: Vec<u16>,
+}
+
+impl ServiceRow {
+    /// Running, healthy when it has a healthcheck, with every published port answering.
+    fn ok(&self) -> bool {
+        self.state == "running"
+            && (self.health.is_empty() || self.health == "healthy")
+            && self.ports.iter().all(|(host, _)| self.reachable.contains(host))
+    }
+}
+
+/// What the dashboard shows at one refresh.
+#[derive(Debug, Clone, Serialize)]
+pub struct Snapshot {
+    pub project: String,
+    /// Compose file the services come from; `None` when dx has not generated one yet
+    pub compose_file: Option<PathBuf>,
+    /// False when `docker compose ps` could not be run
+    pub docker: bool,
+    pub services: Vec<ServiceRow>,
+}
+
+#[derive(Deserialize)]
+struct ComposeFile {
+    #[serde(default)]
+    services: BTreeMap<String, ComposeService>,
+}
+
+#[derive(Deserialize)]
+struct ComposeService {
+    #[serde(default)]
+    image: Option<String>,
+    #[serde(default)]
+    ports: Vec<PortSpec>,
+}
+
+/// A `ports:` entry: `"8080:80"`, `8080` or the long form with `published` and `target`.
+#[derive(Deserialize)]
+#[serde(untagged)]
+enum PortSpec {
+    Number(u16),
+    Short(String),
+    Long {
+        target: u16,
+        #[serde(default)]
+        published: Option<PortValue>,
+    },
+}
+
+#[derive(Deserialize)]
+#[serde(untagged)]
+enum PortValue {
+    Number(u16),
+    Text(String),
+}
+
+/// Host port of a short-form entry (`[ip:]host:container[/proto]`); an interpolated host port
+/// (`${APP_PORT:-8080}`) counts as its default.
+fn short_port(spec: &str) -> Option<(u16, u16)> {
+    let spec = spec.split('/').next().unwrap_or(spec);
+    let mut depth = 0;
+    let mut split = None;
+    for (i, c) in spec.char_indices() {
+        match c {
+            '{' => depth += 1,
+            '}' => depth -= 1,
+            ':' if depth == 0 => split = Some(i),
+            _ => {}
+        }
+    }
+    let Some(i) = split else {
+        let port = spec.parse().ok()?;
+        return Some((port, port));
+    };
+    let target = spec[i + 1..].parse().ok()?;
+    let host = spec[..i].rsplit(|c| c == ':' || c == '-').next().unwrap_or("").trim_end_matches('}');
+    Some((host.parse().ok()?, target))
+}
+
+impl PortSpec {
+    fn ports(&self) -> Option<(u16, u16)> {
+        match self {
+            PortSpec::Number(port) => Some((*port, *port)),
+            PortSpec::Short(spec) => short_port(spec),
+            PortSpec::Long { target, published } => match published {
+                Some(PortValue::Number(host)) => Some((*host, *target)),
+                Some(PortValue::Text(host)) => Some((host.parse().ok()?, *target)),
+                None => None,
+            },
+        }
+    }
+}
+
+/// Services of the compose file, or those `dx dev-services` would generate when there is none.
+fn declared_services(project_dir: &Path, compose_path: Option<&Path>) -> Vec<ServiceRow> {
+    let row = |name: String, image: String, ports: Vec<(u16, u16)>| ServiceRow {
+        name,
+        image,
+        state: "?".to_string(),
+        health: String::new(),
+        ports,
+        reachable: Vec::new(),
+    };
+    let compose = compose_path
+        .and_then(|p| fs::read_to_string(p).ok())
+        .and_then(|c| serde_yaml::from_str::<ComposeFile>(&c).ok());
+    match compose {
+        Some(compose) => compose
+            .services
+            .into_iter()
+            .map(|(name, s)| {
+                let image = s.image.unwrap_or_else(|| "(build)".to_string());
+                row(name, image, s.ports.iter().filter_map(PortSpec::ports).collect())
+            })
+            .collect(),
+        None => {
+            let detected = crate::dev_services::detect_dependencies(project_dir);
+            let mut rows: Vec<ServiceRow> = detected
+                .services
+                .into_iter()
+                .map(|(name, s)| row(name, s.image, s.ports.iter().map(|p| (*p, *p)).collect()))
+                .collect();
+            rows.sort_by(|a, b| a.name.cmp(&b.name));
+            rows
+        }
+    }
+}
+
+fn port_open(port: u16) -> bool {
+    TcpStream::connect_timeout(&SocketAddr::from(([127, 0, 0, 1], port)), PORT_TIMEOUT).is_ok()
+}
+
+/// Read the services of the project and the state of their containers.
+pub fn snapshot(project_dir: &Path) -> Snapshot {
+    let compose_path = crate::dev_services_compose_path(project_dir);
+    let compose_file = compose_path.exists().then_some(compose_path);
+    let mut services = declared_services(project_dir, compose_file.as_deref());
+    let states = compose_file.as_deref().and_then(|p| crate::startup_profile::query_states(&["docker", "compose"], p));
+    if let Some(states) = &states {
+        for row in &mut services {
+            let Some(c) = states.iter().find(|c| c.service == row.name) else {
+                row.state = "não criado".to_string();
+                continue;
+            };
+            row.state = if c.state == "exited" { format!("exited ({})", c.exit_code) } else { c.state.clone() };
+            row.health = c.health.clone();
+            if !c.ports.is_empty() {
+                row.ports = c.ports.clone();
+            }
+            if c.state == "running" {
+                row.reachable = row.ports.iter().map(|(host, _)| *host).filter(|p| port_open(*p)).collect();
+            }
+        }
+    }
+    let project = fs::canonicalize(project_dir)
+        .ok()
+        .and_then(|d| d.file_name().map(|n| n.to_string_lossy().into_owned()))
+        .unwrap_or_default();
+    Snapshot { project, compose_file, docker: states.is_some(), services }
+}
+
+/// Last `lines` log lines of a service.
+fn tail_logs(compose_path: &Path, service: &str, lines: usize) -> Vec<String> {
+    let output = Command::new("docker")
+        .arg("compose")
+        .arg("-f")
+        .arg(compose_path)
+        .args(["logs", "--no-color", "--no-log-prefix", "--tail", &lines.to_string(), service])
+        .stdin(Stdio::null())
+        .output();
+    match output {
+        Ok(out) => {
+            let text = [out.stdout, out.stderr].concat();
+            String::from_utf8_lossy(&text).lines().map(|l| l.replace('\t', "    ")).collect()
+        }
+        Err(e) => vec![format!("(não foi possível ler os logs: {})", e)],
+    }
+}
+
+/// `text` cut or padded to exactly `width` characters.
+fn fit(text: &str, width: usize) -> String {
+    let mut out: String = text.chars().take(width).collect();
+    let len = out.chars().count();
+    out.extend(std::iter::repeat_n(' ', width - len));
+    out
+}
+
+/// A pane title spanning the width: `── Serviços ─────`.
+fn rule(title: &str, width: usize) -> String {
+    let head = format!("── {} ", title);
+    let len = head.chars().count();
+    format!("{}{}", head, "─".repeat(width.saturating_sub(len)))
+}
+
+/// The screen as `height` lines of `width` characters: the services pane, the log tail of the
+/// selected service, a status line and the keybindings.
+pub fn render(snapshot: &Snapshot, selected: usize, logs: &[String], status: &str, width: usize, height: usize) -> Vec<String> {
+    let mut lines = Vec::with_capacity(height);
+    let source = match &snapshot.compose_file {
+        Some(path) => path.display().to_string(),
+        None => "sem compose; serviços detectados (gere com: dx dev-services)".to_string(),
+    };
+    lines.push(format!("dx dashboard — {} ({})", snapshot.project, source));
+    lines.push(rule(&format!("Serviços ({})", snapshot.services.len()), width));
+    lines.push(format!("  {:<16} {:<12} {:<10} {:<22} {}", "SERVIÇO", "ESTADO", "SAÚDE", "PORTAS", "DEPENDÊNCIA"));
+    if snapshot.services.is_empty() {
+        lines.push("  (nenhum serviço)".to_string());
+    }
+    for (i, s) in snapshot.services.iter().enumerate() {
+        let ports: Vec<String> = s.ports.iter().map(|(host, container)| format!("{}→{}", host, container)).collect();
+        let check = if s.state != "running" {
+            "-".to_string()
+        } else if s.ok() {
+            "✓".to_string()
+        } else {
+            let down: Vec<String> =
+                s.ports.iter().filter(|(host, _)| !s.reachable.contains(host)).map(|(host, _)| host.to_string()).collect();
+            if down.is_empty() { format!("✗ {}", s.health) } else { format!("✗ porta {}", down.join(", ")) }
+        };
+        let marker = if i == selected { '>' } else { ' ' };
+        let health = if s.health.is_empty() { "-" } else { &s.health };
+        lines.push(format!("{} {:<16} {:<12} {:<10} {:<22} {}", marker, s.name, s.state, health, ports.join(" "), check));
+    }
+    if !snapshot.docker {
+        lines.push("  Docker indisponível: estados e logs não puderam ser lidos.".to_string());
+    }
+
+    let service = snapshot.services.get(selected).map(|s| s.name.as_str()).unwrap_or("-");
+    lines.push(rule(&format!("Logs: {}", service), width));
+    // The log pane takes what is left above the status line and the keybindings
+    let room = height.saturating_sub(lines.len() + 2);
+    let start = logs.len().saturating_sub(room);
+    lines.extend(logs[start..].iter().cloned());
+    while lines.len() < height.saturating_sub(2) {
+        lines.push(String::new());
+    }
+    lines.push(status.to_string());
+    lines.push("↑/↓ seleciona · r reinicia · s abre um shell · l atualiza · q sai".to_string());
+    lines.into_iter().map(|l| fit(&l, width)).collect()
+}
+
+/// Raw mode: no echo, no line buffering, reads that wait at most 0.2 s, and Ctrl-C read as a key
+/// (no SIGINT) so the terminal is always restored on the way out.
+const RAW_MODE: &[&str] = &["-icanon", "-echo", "-isig", "min", "0", "time", "2"];
+
+/// Terminal in raw mode on the alternate screen; restored when dropped.
+struct Terminal {
+    saved: String,
+}
+
+fn stty(args: &[&str]) -> Option<String> {
+    let out = Command::new("stty").args(args).stdin(Stdio::inherit()).stderr(Stdio::null()).output().ok()?;
+    out.status.success().then(|| String::from_utf8_lossy(&out.stdout).trim().to_string())
+}
+
+impl Terminal {
+    fn enter() -> Option<Terminal> {
+        let saved = stty(&["-g"])?;
+        stty(RAW_MODE)?;
+        print!("\x1b[?1049h\x1b[?25l");
+        let _ = io::stdout().flush();
+        Some(Terminal { saved })
+    }
+
+    /// Rows and columns.
+    fn size() -> (usize, usize) {
+        stty(&["size"])
+            .and_then(|s| {
+                let (rows, cols) = s.split_once(' ')?;
+                Some((rows.parse().ok()?, cols.parse().ok()?))
+            })
+            .unwrap_or((24, 80))
+    }
+
+    fn draw(lines: &[String]) {
+        let mut out = String::from("\x1b[H");
+        out.push_str(&lines.join("\r\n"));
+        print!("{}", out);
+        let _ = io::stdout().flush();
+    }
+
+    /// Run `command` on the normal screen with the terminal as the user had it, then come back.
+    fn suspend(&self, command: &mut Command) -> io::Result<std::process::ExitStatus> {
+        print!("\x1b[?25h\x1b[?1049l");
+        let _ = io::stdout().flush();
+        stty(&[&self.saved]);
+        let status = command.stdin(Stdio::inherit()).stdout(Stdio::inherit()).stderr(Stdio::inherit()).status();
+        stty(RAW_MODE);
+        print!("\x1b[?1049h\x1b[?25l\x1b[2J");
+        let _ = io::stdout().flush();
+        status
+    }
+}
+
+impl Drop for Terminal {
+    fn drop(&mut self) {
+        print!("\x1b[?25h\x1b[?1049l");
+        let _ = io::stdout().flush();
+        stty(&[&self.saved]);
+    }
+}
+
+enum Key {
+    Up,
+    Down,
+    Restart,
+    Shell,
+    Refresh,
+    Quit,
+}
+
+/// The key pressed within the read timeout of raw mode, if any.
+fn read_key() -> Option<Key> {
+    let mut buf = [0u8; 8];
+    let n = io::stdin().read(&mut buf).ok()?;
+    match &buf[..n] {
+        [b'q'] | [b'Q'] | [0x1b] | [3] => Some(Key::Quit),
+        [0x1b, b'[', b'A'] | [b'k'] => Some(Key::Up),
+        [0x1b, b'[', b'B'] | [b'j'] => Some(Key::Down),
+        [b'r'] => Some(Key::Restart),
+        [b's'] => Some(Key::Shell),
+        [b'l'] => Some(Key::Refresh),
+        _ => None,
+    }
+}
+
+fn compose(compose_path: &Path, args: &[&str]) -> Command {
+    let mut command = Command::new("docker");
+    command.arg("compose").arg("-f").arg(compose_path).args(args);
+    command
+}
+
+/// `dx dashboard`: services, container states, published ports, port checks and the log tail of
+/// the selected service, refreshed every 2 seconds, with keys to restart a service or open a
+/// shell in it. Without a terminal (or with `once`), prints one screen (JSON with `--output
+/// json`) and exits. Returns the exit code.
+pub fn cmd_dashboard(dir: Option<PathBuf>, once: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let interactive = !once && !cfg!(windows) && io::stdin().is_terminal() && io::stdout().is_terminal();
+    if !interactive {
+        let snap = snapshot(&project_dir);
+        if crate::output::json() {
+            crate::output::print(&snap);
+            return 0;
+        }
+        let logs = match (&snap.compose_file, snap.services.first()) {
+            (Some(path), Some(service)) if snap.docker => tail_logs(path, &service.name, 10),
+            _ => Vec::new(),
+        };
+        let height = snap.services.len() + logs.len() + 7;
+        for line in render(&snap, 0, &logs, "", 100, height) {
+            println!("{}", line.trim_end());
+        }
+        return 0;
+    }
+
+    let Some(terminal) = Terminal::enter() else {
+        eprintln!("Erro: não foi possível controlar o terminal (stty); use dx dashboard --once.");
+        return 1;
+    };
+    let mut selected = 0;
+    let mut status = String::new();
+    let mut snap = snapshot(&project_dir);
+    let mut logs = Vec::new();
+    let mut refreshed: Option<Instant> = None;
+    loop {
+        if refreshed.is_none_or(|t| t.elapsed() >= REFRESH) {
+            snap = snapshot(&project_dir);
+            selected = selected.min(snap.services.len().saturating_sub(1));
+            logs = match (&snap.compose_file, snap.services.get(selected)) {
+                (Some(path), Some(service)) if snap.docker => tail_logs(path, &service.name, LOG_TAIL),
+                _ => Vec::new(),
+            };
+            let (rows, cols) = Terminal::size();
+            Terminal::draw(&render(&snap, selected, &logs, &status, cols, rows));
+            refreshed = Some(Instant::now());
+        }
+        let Some(key) = read_key() else { continue };
+        let service = snap.services.get(selected).map(|s| s.name.clone());
+        match (key, &snap.compose_file, service) {
+            (Key::Quit, _, _) => break,
+            (Key::Up, _, _) => selected = selected.saturating_sub(1),
+            (Key::Down, _, _) => selected = (selected + 1).min(snap.services.len().saturating_sub(1)),
+            (Key::Refresh, _, _) => {}
+            (Key::Restart, Some(path), Some(service)) => {
+                let (rows, cols) = Terminal::size();
+                Terminal::draw(&render(&snap, selected, &logs, &format!("Reiniciando {}...", service), cols, rows));
+                let out = compose(path, &["restart", &service]).stdin(Stdio::null()).output();
+                status = match out {
+                    Ok(o) if o.status.success() => format!("✓ {} reiniciado.", service),
+                    Ok(o) => format!("✗ Falha ao reiniciar {}: {}", service, String::from_utf8_lossy(&o.stderr).trim()),
+                    Err(e) => format!("✗ Falha ao reiniciar {}: {}", service, e),
+                };
+            }
+            (Key::Shell, Some(path), Some(service)) => {
+                // sh exists in almost every image; bash is not in Alpine-based ones
+                status = match terminal.suspend(&mut compose(path, &["exec", &service, "sh"])) {
+                    Ok(s) if s.success() => format!("Shell de {} encerrado.", service),
+                    Ok(_) => format!("✗ Não foi possível abrir um shell em {} (o container está rodando?).", service),
+                    Err(e) => format!("✗ Falha ao executar docker: {}", e),
+                };
+            }
+            _ => status = "Sem compose: gere o manifesto com dx dev-services e suba com dx dev-services run.".to_string(),
+        }
+        // Redraw at once after a key instead of waiting for the next refresh
+        refreshed = None;
+    }
+    drop(terminal);
+    0
+}
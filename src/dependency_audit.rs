@@ -0,0 +1,595 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, BTreeSet};
+use std::fmt;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Base URL of the OSV API (a mirror or a test server can replace it).
+const OSV_URL_ENV: &str = "DX_OSV_URL";
+const OSV_URL: &str = "https://api.osv.dev";
+/// Queries per `querybatch` request (the API accepts up to 1000).
+const BATCH_SIZE: usize = 1000;
+
+/// A package at an exact version, as OSV identifies it.
+#[derive(Debug, Clone, PartialEq, Eq, PartialOrd, Ord)]
+pub struct Package {
+    /// OSV ecosystem: "Go", "npm", "PyPI", "crates.io", "Maven", "NuGet" or "Hex"
+    pub ecosystem: &'static str,
+    pub name: String,
+    pub version: String,
+    /// Manifest or lockfile the version came from
+    pub source: String,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, PartialOrd, Ord)]
+pub enum Severity {
+    Unknown,
+    Low,
+    Medium,
+    High,
+    Critical,
+}
+
+impl Severity {
+    fn parse(s: &str) -> Severity {
+        match s.to_uppercase().as_str() {
+            "CRITICAL" => Severity::Critical,
+            "HIGH" => Severity::High,
+            "MODERATE" | "MEDIUM" => Severity::Medium,
+            "LOW" => Severity::Low,
+            _ => Severity::Unknown,
+        }
+    }
+
+    fn from_score(score: f64) -> Severity {
+        match score {
+            s if s >= 9.0 => Severity::Critical,
+            s if s >= 7.0 => Severity::High,
+            s if s >= 4.0 => Severity::Medium,
+            s if s > 0.0 => Severity::Low,
+            _ => Severity::Unknown,
+        }
+    }
+
+    fn key(self) -> &'static str {
+        match self {
+            Severity::Critical => "critical",
+            Severity::High => "high",
+            Severity::Medium => "medium",
+            Severity::Low => "low",
+            Severity::Unknown => "unknown",
+        }
+    }
+}
+
+impl fmt::Display for Severity {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        let label = match self {
+            Severity::Critical => "crítica",
+            Severity::High => "alta",
+            Severity::Medium => "média",
+            Severity::Low => "baixa",
+            Severity::Unknown => "desconhecida",
+        };
+        write!(f, "{}", label)
+    }
+}
+
+/// Lowest severity that makes `dx dev-dependencies audit` exit with an error.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum FailOn {
+    /// Qualquer vulnerabilidade, inclusive sem severidade informada
+    Any,
+    /// Baixa ou acima
+    Low,
+    /// Média ou acima
+    Medium,
+    /// Alta ou crítica
+    High,
+    /// Só crítica
+    Critical,
+}
+
+impl FailOn {
+    fn fails(self, severity: Severity) -> bool {
+        let min = match self {
+            FailOn::Any => Severity::Unknown,
+            FailOn::Low => Severity::Low,
+            FailOn::Medium => Severity::Medium,
+            FailOn::High => Severity::High,
+            FailOn::Critical => Severity::Critical,
+        };
+        severity >= min
+    }
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum AuditFormat {
+    /// Vulnerabilidades agrupadas por pacote
+    Text,
+    /// Objeto JSON (para CI)
+    Json,
+    /// SARIF 2.1.0 (GitHub code scanning, anotações no pull request)
+    Sarif,
+}
+
+/// One advisory affecting one package of the project.
+#[derive(Debug, Clone)]
+pub struct Finding {
+    pub package: Package,
+    pub id: String,
+    pub aliases: Vec<String>,
+    pub summary: String,
+    pub severity: Severity,
+    /// Versions that fix the advisory, the nearest above the current one first
+    pub fixed: Vec<String>,
+}
+
+// OSV API documents (only the fields dx reads)
+
+#[derive(Serialize)]
+struct Query<'a> {
+    package: QueryPackage<'a>,
+    version: &'a str,
+}
+
+#[derive(Serialize)]
+struct QueryPackage<'a> {
+    name: &'a str,
+    ecosystem: &'a str,
+}
+
+#[derive(Deserialize)]
+struct BatchResponse {
+    #[serde(default)]
+    results: Vec<BatchResult>,
+}
+
+#[derive(Deserialize)]
+struct BatchResult {
+    #[serde(default)]
+    vulns: Vec<VulnRef>,
+}
+
+#[derive(Deserialize)]
+struct VulnRef {
+    id: String,
+}
+
+#[derive(Debug, Default, Deserialize)]
+struct Vuln {
+    id: String,
+    #[serde(default)]
+    summary: Option<String>,
+    #[serde(default)]
+    details: Option<String>,
+    #[serde(default)]
+    aliases: Vec<String>,
+    #[serde(default)]
+    severity: Vec<SeverityScore>,
+    #[serde(default)]
+    affected: Vec<Affected>,
+    #[serde(default)]
+    database_specific: Option<DatabaseSpecific>,
+}
+
+#[derive(Debug, Deserialize)]
+struct SeverityScore {
+    #[serde(rename = "type")]
+    kind: String,
+    score: String,
+}
+
+#[derive(Debug, Default, Deserialize)]
+struct DatabaseSpecific {
+    #[serde(default)]
+    severity: Option<String>,
+}
+
+#[derive(Debug, Deserialize)]
+struct Affected {
+    #[serde(default)]
+    package: Option<AffectedPackage>,
+    #[serde(default)]
+    ranges: Vec<Range>,
+    #[serde(default)]
+    database_specific: Option<DatabaseSpecific>,
+}
+
+#[derive(Debug, Deserialize)]
+struct AffectedPackage {
+    name: String,
+    ecosystem: String,
+}
+
+#[derive(Debug, Deserialize)]
+struct Range {
+    #[serde(default)]
+    events: Vec<BTreeMap<String, String>>,
+}
+
+/// Every dependency with an exact version the project pins: go.mod, package-lock.json,
+/// requirements files (`==` only), the Cargo.lock of the workspace (else the `=` requirements), the
+/// effective versions of a Maven or Gradle build, the NuGet packages of a .NET build
+/// (packages.lock.json, else the exact versions referenced) and the Hex packages of mix.lock or
+/// rebar.lock.
+pub fn collect(project_dir: &Path) -> Vec<Package> {
+    // The first file that pins a package is reported as its source
+    let mut packages: BTreeMap<(&'static str, String, String), String> = BTreeMap::new();
+    let mut add = |ecosystem: &'static str, name: String, version: String, source: &str| {
+        packages.entry((ecosystem, name, version)).or_insert_with(|| source.to_string());
+    };
+
+    if let Ok(data) = fs::read_to_string(project_dir.join("go.mod")) {
+        for (name, version) in crate::dev_dependencies::parse_go_mod(&data) {
+            add("Go", name, version.trim_start_matches('v').to_string(), "go.mod");
+        }
+    }
+
+    let npm_lock = fs::read_to_string(project_dir.join("package-lock.json")).ok();
+    if let Some(lock) = npm_lock.and_then(|d| serde_json::from_str::<serde_json::Value>(&d).ok()) {
+        for (name, version) in npm_lock_packages(&lock) {
+            add("npm", name, version, "package-lock.json");
+        }
+    }
+
+    for file in ["requirements.txt", "requirements-dev.txt"] {
+        let Ok(data) = fs::read_to_string(project_dir.join(file)) else { continue };
+        for (name, version) in crate::dev_dependencies::parse_requirements(&data) {
+            // `pkg[extra]==1.0 ; python_version < "3.12"`
+            let name = name.split('[').next().unwrap_or(&name).trim().to_lowercase();
+            let version = version.split([';', ' ', '#']).next().unwrap_or("").trim().to_string();
+            if version != "*" && !version.is_empty() {
+                add("PyPI", name, version, file);
+            }
+        }
+    }
+
+    for (name, version, source) in crate::cargo_build::pinned(project_dir) {
+        add("crates.io", name, version, &source);
+    }
+
+    for dep in crate::java_build::dependencies(project_dir) {
+        if let Some(version) = dep.exact_version() {
+            add("Maven", dep.name(), version.to_string(), &dep.source);
+        }
+    }
+
+    for (name, version, source) in crate::dotnet_build::pinned(project_dir) {
+        add("NuGet", name, version, &source);
+    }
+
+    for (name, version, source) in crate::beam_build::pinned(project_dir) {
+        add("Hex", name, version, &source);
+    }
+    packages
+        .into_iter()
+        .map(|((ecosystem, name, version), source)| Package { ecosystem, name, version, source })
+        .collect()
+}
+
+/// Installed packages of a package-lock.json (`packages` of lockfile v2/v3, `dependencies` of v1).
+fn npm_lock_packages(lock: &serde_json::Value) -> Vec<(String, String)> {
+    let mut out = Vec::new();
+    if let Some(packages) = lock.get("packages").and_then(|p| p.as_object()) {
+        for (path, info) in packages {
+            let Some((_, name)) = path.rsplit_once("node_modules/") else { continue };
+            if info.get("link").and_then(|l| l.as_bool()) == Some(true) {
+                continue;
+            }
+            if let Some(version) = info.get("version").and_then(|v| v.as_str()) {
+                out.push((name.to_string(), version.to_string()));
+            }
+        }
+    } else if let Some(deps) = lock.get("dependencies").and_then(|d| d.as_object()) {
+        fn walk(deps: &serde_json::Map<String, serde_json::Value>, out: &mut Vec<(String, String)>) {
+            for (name, info) in deps {
+                if let Some(version) = info.get("version").and_then(|v| v.as_str()) {
+                    out.push((name.clone(), version.to_string()));
+                }
+                if let Some(nested) = info.get("dependencies").and_then(|d| d.as_object()) {
+                    walk(nested, out);
+                }
+            }
+        }
+        walk(deps, &mut out);
+    }
+    out
+}
+
+fn osv_url() -> String {
+    std::env::var(OSV_URL_ENV).ok().filter(|v| !v.is_empty()).unwrap_or_else(|| OSV_URL.to_string())
+}
+
+/// Ask OSV which advisories affect each package, then fetch the details of each advisory.
+pub fn query(packages: &[Package]) -> Result<Vec<Finding>, String> {
+    let base = osv_url();
+
+    let mut ids_per_package: Vec<Vec<String>> = Vec::with_capacity(packages.len());
+    for chunk in packages.chunks(BATCH_SIZE) {
+        let queries: Vec<Query> = chunk
+            .iter()
+            .map(|p| Query { package: QueryPackage { name: &p.name, ecosystem: p.ecosystem }, version: &p.version })
+            .collect();
+        let url = format!("{}/v1/querybatch", base);
+        let response: BatchResponse = crate::registry::post_json(&url, &serde_json::json!({ "queries": queries }))
+            .and_then(|body| serde_json::from_str(&body).map_err(|e| e.to_string()))
+            .map_err(|e| format!("{}: {}", url, e))?;
+        let mut results = response.results.into_iter();
+        for _ in chunk {
+            let ids = results.next().map(|r| r.vulns.into_iter().map(|v| v.id).collect()).unwrap_or_default();
+            ids_per_package.push(ids);
+        }
+    }
+
+    let unique: Vec<&String> = ids_per_package.iter().flatten().collect::<BTreeSet<_>>().into_iter().collect();
+    let urls: Vec<String> = unique.iter().map(|id| format!("{}/v1/vulns/{}", base, id)).collect();
+    let phase = crate::progress::Phase::start("dev-dependencies.audit", "Consultando as vulnerabilidades no OSV");
+    let responses = crate::registry::get_all(&urls, Some(&phase));
+    phase.finish(responses.iter().all(|r| r.is_ok()));
+    let mut details: BTreeMap<String, Vuln> = BTreeMap::new();
+    let mut failed = Vec::new();
+    for ((id, url), response) in unique.iter().zip(&urls).zip(responses) {
+        match response.and_then(|body| serde_json::from_str::<Vuln>(&body).map_err(|e| e.to_string())) {
+            Ok(vuln) => {
+                details.insert((*id).clone(), vuln);
+            }
+            Err(e) => failed.push(format!("{}: {}", url, e)),
+        }
+    }
+    if let Some(first) = failed.first() {
+        return Err(match failed.len() {
+            1 => first.clone(),
+            n => format!("{} (e mais {} alerta(s) sem resposta)", first, n - 1),
+        });
+    }
+
+    let mut findings = Vec::new();
+    for (package, ids) in packages.iter().zip(&ids_per_package) {
+        for id in ids {
+            let Some(vuln) = details.get(id) else { continue };
+            findings.push(Finding {
+                package: package.clone(),
+                id: vuln.id.clone(),
+                aliases: vuln.aliases.clone(),
+                summary: vuln
+                    .summary
+                    .clone()
+                    .or_else(|| vuln.details.as_ref().and_then(|d| d.lines().next().map(str::to_string)))
+                    .unwrap_or_default(),
+                severity: severity_of(vuln, package),
+                fixed: fixed_versions(vuln, package),
+            });
+        }
+    }
+    findings.sort_by(|a, b| b.severity.cmp(&a.severity).then_with(|| a.package.cmp(&b.package)).then_with(|| a.id.cmp(&b.id)));
+    Ok(findings)
+}
+
+fn affects<'a>(vuln: &'a Vuln, package: &'a Package) -> impl Iterator<Item = &'a Affected> {
+    vuln.affected.iter().filter(move |a| {
+        a.package.as_ref().is_some_and(|p| p.ecosystem == package.ecosystem && p.name.eq_ignore_ascii_case(&package.name))
+    })
+}
+
+/// The advisory's own rating (GitHub advisories), else its CVSS v3 base score.
+fn severity_of(vuln: &Vuln, package: &Package) -> Severity {
+    let rated = vuln
+        .database_specific
+        .iter()
+        .chain(affects(vuln, package).filter_map(|a| a.database_specific.as_ref()))
+        .filter_map(|d| d.severity.as_deref())
+        .map(Severity::parse)
+        .find(|s| *s != Severity::Unknown);
+    if let Some(severity) = rated {
+        return severity;
+    }
+    vuln.severity
+        .iter()
+        .filter(|s| s.kind.starts_with("CVSS_V3"))
+        .filter_map(|s| cvss3_base_score(&s.score))
+        .map(Severity::from_score)
+        .max()
+        .unwrap_or(Severity::Unknown)
+}
+
+/// Base score of a CVSS v3.x vector ("CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H"), per the specification.
+fn cvss3_base_score(vector: &str) -> Option<f64> {
+    let metrics: BTreeMap<&str, &str> = vector.split('/').skip(1).filter_map(|m| m.split_once(':')).collect();
+    let changed = *metrics.get("S")? == "C";
+    let av = match *metrics.get("AV")? { "N" => 0.85, "A" => 0.62, "L" => 0.55, "P" => 0.2, _ => return None };
+    let ac = match *metrics.get("AC")? { "L" => 0.77, "H" => 0.44, _ => return None };
+    let pr = match (*metrics.get("PR")?, changed) {
+        ("N", _) => 0.85,
+        ("L", false) => 0.62,
+        ("L", true) => 0.68,
+        ("H", false) => 0.27,
+        ("H", true) => 0.5,
+        _ => return None,
+    };
+    let ui = match *metrics.get("UI")? { "N" => 0.85, "R" => 0.62, _ => return None };
+    let cia = |key: &str| -> Option<f64> {
+        match *metrics.get(key)? { "H" => Some(0.56), "L" => Some(0.22), "N" => Some(0.0), _ => None }
+    };
+    let iss = 1.0 - (1.0 - cia("C")?) * (1.0 - cia("I")?) * (1.0 - cia("A")?);
+    let impact = if changed { 7.52 * (iss - 0.029) - 3.25 * (iss - 0.02).powi(15) } else { 6.42 * iss };
+    if impact <= 0.0 {
+        return Some(0.0);
+    }
+    let exploitability = 8.22 * av * ac * pr * ui;
+    let raw = if changed { (1.08 * (impact + exploitability)).min(10.0) } else { (impact + exploitability).min(10.0) };
+    // Round up to one decimal, avoiding floating point artifacts
+    let scaled = (raw * 100_000.0).round() as u64;
+    Some(if scaled % 10_000 == 0 { scaled as f64 / 100_000.0 } else { (scaled / 10_000 + 1) as f64 / 10.0 })
+}
+
+/// Numeric parts of a version, enough to order the fixed versions of one package.
+pub(crate) fn version_key(version: &str) -> Vec<u64> {
+    version.trim_start_matches('v').split(|c: char| !c.is_ascii_digit()).filter_map(|p| p.parse().ok()).collect()
+}
+
+fn fixed_versions(vuln: &Vuln, package: &Package) -> Vec<String> {
+    let mut fixed: Vec<String> = affects(vuln, package)
+        .flat_map(|a| &a.ranges)
+        .flat_map(|r| &r.events)
+        .filter_map(|e| e.get("fixed").cloned())
+        .collect();
+    fixed.sort_by_key(|v| version_key(v));
+    fixed.dedup();
+    // The nearest fix above the current version first
+    let current = version_key(&package.version);
+    if let Some(pos) = fixed.iter().position(|v| version_key(v) > current) {
+        fixed.rotate_left(pos);
+    }
+    fixed
+}
+
+fn render_text(packages: &[Package], findings: &[Finding], fail_on: FailOn) -> String {
+    let mut out = String::new();
+    if findings.is_empty() {
+        out.push_str(&format!("Nenhuma vulnerabilidade conhecida nos {} pacotes verificados (OSV).\n", packages.len()));
+        return out;
+    }
+    let affected: BTreeSet<&Package> = findings.iter().map(|f| &f.package).collect();
+    out.push_str(&format!(
+        "{} vulnerabilidade(s) em {} de {} pacotes (OSV):\n",
+        findings.len(),
+        affected.len(),
+        packages.len()
+    ));
+    for package in &affected {
+        out.push_str(&format!("\n  {} {} ({}, {})\n", package.name, package.version, package.ecosystem, package.source));
+        for f in findings.iter().filter(|f| &f.package == *package) {
+            let fixed = match f.fixed.first() {
+                Some(v) => format!("corrigida em {}", v),
+                None => "sem correção".to_string(),
+            };
+            let mark = if fail_on.fails(f.severity) { "✗" } else { " " };
+            out.push_str(&format!("  {} {:<20} {:<13} {:<22} {}\n", mark, f.id, f.severity.to_string(), fixed, f.summary));
+        }
+    }
+    let mut counts: BTreeMap<Severity, usize> = BTreeMap::new();
+    for f in findings {
+        *counts.entry(f.severity).or_default() += 1;
+    }
+    let summary: Vec<String> = counts.iter().rev().map(|(s, n)| format!("{} {}", n, s)).collect();
+    out.push_str(&format!("\nPor severidade: {}\n", summary.join(", ")));
+    out
+}
+
+/// The JSON report, as `--format json` prints it and report sinks receive it.
+fn report(packages: &[Package], findings: &[Finding]) -> serde_json::Value {
+    let vulns: Vec<serde_json::Value> = findings
+        .iter()
+        .map(|f| {
+            serde_json::json!({
+                "id": f.id,
+                "aliases": f.aliases,
+                "package": f.package.name,
+                "version": f.package.version,
+                "ecosystem": f.package.ecosystem,
+                "source": f.package.source,
+                "severity": f.severity.key(),
+                "summary": f.summary,
+                "fixed": f.fixed,
+            })
+        })
+        .collect();
+    serde_json::json!({ "packages": packages.len(), "vulnerabilities": vulns })
+}
+
+/// The SARIF log: one rule per advisory and one result per affected package, declared where the
+/// manifest or lockfile pins it. Findings that reach `fail_on` are errors, the others warnings.
+fn sarif(project_dir: &Path, findings: &[Finding], fail_on: FailOn) -> crate::sarif::Log {
+    use crate::sarif::{Level, Message, Rule, RuleProperties, SarifResult};
+    let mut rules: Vec<Rule> = Vec::new();
+    for f in findings {
+        if rules.iter().any(|r| r.id == f.id) {
+            continue;
+        }
+        let score = match f.severity {
+            Severity::Critical => Some("9.0"),
+            Severity::High => Some("7.0"),
+            Severity::Medium => Some("5.0"),
+            Severity::Low => Some("2.0"),
+            Severity::Unknown => None,
+        };
+        let summary = if f.summary.is_empty() { f.id.clone() } else { f.summary.clone() };
+        rules.push(Rule {
+            id: f.id.clone(),
+            short_description: Message::new(summary),
+            help_uri: Some(format!("https://osv.dev/vulnerability/{}", f.id)),
+            properties: RuleProperties {
+                security_severity: score.map(str::to_string),
+                tags: vec!["security".to_string(), "dependency".to_string(), f.package.ecosystem.to_string()],
+            },
+        });
+    }
+    let results = findings
+        .iter()
+        .map(|f| {
+            let fixed = match f.fixed.first() {
+                Some(v) => format!("corrigida em {}", v),
+                None => "sem correção".to_string(),
+            };
+            SarifResult {
+                rule_id: f.id.clone(),
+                level: if fail_on.fails(f.severity) { Level::Error } else { Level::Warning },
+                message: Message::new(format!(
+                    "{} {} ({}): {} ({}, severidade {}, {})",
+                    f.package.name, f.package.version, f.package.ecosystem, f.id, f.summary, f.severity, fixed
+                )),
+                locations: vec![crate::sarif::location(project_dir, &f.package.source, &f.package.name)],
+                partial_fingerprints: crate::sarif::fingerprint(&[&f.id, f.package.ecosystem, &f.package.name, &f.package.version]),
+            }
+        })
+        .collect();
+    crate::sarif::Log::new(rules, results)
+}
+
+/// `dx dev-dependencies audit`: check the pinned dependencies against OSV (GoVulnDB, GitHub
+/// advisories, PyPA, RustSec). Returns the exit code: 1 when a finding reaches `fail_on`,
+/// 2 when OSV cannot be queried.
+pub fn cmd_audit(dir: Option<PathBuf>, format: AuditFormat, fail_on: FailOn) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let packages = collect(&project_dir);
+    if packages.is_empty() {
+        eprintln!(
+            "Nenhuma dependência com versão exata em {} (go.mod, package-lock.json, requirements*.txt com ==, Cargo.lock, pom.xml, build.gradle, .csproj ou packages.lock.json).",
+            project_dir.display()
+        );
+        return 0;
+    }
+    if project_dir.join("package.json").exists() && !project_dir.join("package-lock.json").exists() {
+        eprintln!("Aviso: sem package-lock.json; as dependências npm não foram verificadas (execute npm install).");
+    }
+
+    let findings = match query(&packages) {
+        Ok(f) => f,
+        Err(e) => {
+            eprintln!("Erro ao consultar o OSV: {}", e);
+            if let Some(ttl) = crate::registry::cache_ttl_label() {
+                eprintln!("As respostas já recebidas ficam guardadas por {}; execute de novo para continuar de onde parou.", ttl);
+            }
+            return 2;
+        }
+    };
+    let doc = report(&packages, &findings);
+    match format {
+        AuditFormat::Text => print!("{}", render_text(&packages, &findings, fail_on)),
+        AuditFormat::Json => println!("{}", serde_json::to_string_pretty(&doc).unwrap_or_default()),
+        AuditFormat::Sarif => crate::output::print(&sarif(&project_dir, &findings, fail_on)),
+    }
+    crate::sinks::publish(&crate::sinks::Report { kind: "audit", project_dir: &project_dir, content: crate::sinks::Content::Json(doc) });
+    let failing = findings.iter().filter(|f| fail_on.fails(f.severity)).count();
+    if failing > 0 {
+        if format == AuditFormat::Text {
+            println!("{} vulnerabilidade(s) no limite de --fail-on ou acima.", failing);
+        }
+        return 1;
+    }
+    0
+}
@@ -0,0 +1,380 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::collections::{BTreeMap, BTreeSet, VecDeque};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum GraphFormat {
+    /// Graphviz (`dx dev-dependencies graph | dot -Tsvg > deps.svg`)
+    Dot,
+    /// Diagrama Mermaid, para colar em Markdown
+    Mermaid,
+    /// Nós e arestas em JSON
+    Json,
+}
+
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct Node {
+    pub name: String,
+    /// Empty for the project itself
+    pub version: String,
+    /// "Go", "npm" or "crates.io", as in `dependency_audit`
+    pub ecosystem: &'static str,
+}
+
+/// Dependency graph of a project; nodes are keyed by `name@version`.
+#[derive(Debug, Clone, Default)]
+pub struct Graph {
+    pub roots: Vec<String>,
+    pub nodes: BTreeMap<String, Node>,
+    pub edges: BTreeMap<String, BTreeSet<String>>,
+}
+
+impl Graph {
+    fn add_node(&mut self, ecosystem: &'static str, name: &str, version: &str) -> String {
+        let id = if version.is_empty() { name.to_string() } else { format!("{}@{}", name, version) };
+        self.nodes.entry(id.clone()).or_insert_with(|| Node {
+            name: name.to_string(),
+            version: version.to_string(),
+            ecosystem,
+        });
+        id
+    }
+
+    fn add_root(&mut self, ecosystem: &'static str, name: &str, version: &str) -> String {
+        let id = self.add_node(ecosystem, name, version);
+        if !self.roots.contains(&id) {
+            self.roots.push(id.clone());
+        }
+        id
+    }
+
+    fn add_edge(&mut self, from: &str, to: &str) {
+        if from != to {
+            self.edges.entry(from.to_string()).or_default().insert(to.to_string());
+        }
+    }
+
+    /// Shortest distance of each node from a root, stopping at `max_depth`.
+    fn depths(&self, max_depth: Option<usize>) -> BTreeMap<String, usize> {
+        let mut depth: BTreeMap<String, usize> = self.roots.iter().map(|r| (r.clone(), 0)).collect();
+        let mut queue: VecDeque<String> = self.roots.iter().cloned().collect();
+        while let Some(id) = queue.pop_front() {
+            let d = depth[&id];
+            if max_depth.is_some_and(|max| d >= max) {
+                continue;
+            }
+            for child in self.edges.get(&id).into_iter().flatten() {
+                if !depth.contains_key(child) {
+                    depth.insert(child.clone(), d + 1);
+                    queue.push_back(child.clone());
+                }
+            }
+        }
+        depth
+    }
+
+    /// Keep the nodes within `max_depth` of a root and, with `prefixes`, only the nodes whose
+    /// name starts with one of them plus the nodes on the way from a root to them.
+    pub fn prune(&self, max_depth: Option<usize>, prefixes: &[String]) -> Graph {
+        let reachable = self.depths(max_depth);
+        let mut keep: BTreeSet<String> = reachable.keys().cloned().collect();
+        if !prefixes.is_empty() {
+            let mut parents: BTreeMap<&str, Vec<&str>> = BTreeMap::new();
+            for (from, tos) in &self.edges {
+                for to in tos.iter().filter(|to| keep.contains(*to) && keep.contains(from)) {
+                    parents.entry(to.as_str()).or_default().push(from.as_str());
+                }
+            }
+            let mut queue: VecDeque<&str> = keep
+                .iter()
+                .filter(|id| prefixes.iter().any(|p| self.nodes[*id].name.starts_with(p.as_str())))
+                .map(String::as_str)
+                .collect();
+            let mut wanted: BTreeSet<String> = BTreeSet::new();
+            while let Some(id) = queue.pop_front() {
+                if wanted.insert(id.to_string()) {
+                    queue.extend(parents.get(id).into_iter().flatten());
+                }
+            }
+            keep = wanted;
+        }
+
+        let mut out = Graph {
+            roots: self.roots.iter().filter(|r| keep.contains(*r)).cloned().collect(),
+            ..Default::default()
+        };
+        for id in &keep {
+            out.nodes.insert(id.clone(), self.nodes[id].clone());
+        }
+        for (from, tos) in self.edges.iter().filter(|(from, _)| keep.contains(*from)) {
+            for to in tos.iter().filter(|to| keep.contains(*to)) {
+                out.add_edge(from, to);
+            }
+        }
+        out
+    }
+}
+
+/// `require` lines of a go.mod as (module, version, indirect).
+fn go_requires(content: &str) -> Vec<(String, String, bool)> {
+    let mut out = Vec::new();
+    let mut in_block = false;
+    for raw in content.lines() {
+        let indirect = raw.contains("// indirect");
+        let line = raw.split("//").next().unwrap_or("").trim();
+        let spec = if in_block {
+            if line.starts_with(')') {
+                in_block = false;
+                continue;
+            }
+            line
+        } else if line == "require (" || line == "require(" {
+            in_block = true;
+            continue;
+        } else if let Some(rest) = line.strip_prefix("require ") {
+            rest
+        } else {
+            continue;
+        };
+        let mut parts = spec.split_whitespace();
+        if let (Some(module), Some(version)) = (parts.next(), parts.next()) {
+            out.push((module.to_string(), version.to_string(), indirect));
+        }
+    }
+    out
+}
+
+/// Go modules: the main module requires its direct dependencies; each dependency's own go.mod
+/// comes from the module cache (`cache/download/<module>/@v/<version>.mod`, present after
+/// `go mod download` or a build). Versions are those the main go.mod selects.
+fn go_graph(project_dir: &Path, graph: &mut Graph) -> bool {
+    let Ok(content) = fs::read_to_string(project_dir.join("go.mod")) else { return false };
+    let main = content
+        .lines()
+        .find_map(|l| l.trim().strip_prefix("module ").map(|m| m.trim().trim_matches('"').to_string()))
+        .unwrap_or_else(|| "(go.mod)".to_string());
+    let root = graph.add_root("Go", &main, "");
+    let requires = go_requires(&content);
+    let selected: BTreeMap<&str, &str> = requires.iter().map(|(m, v, _)| (m.as_str(), v.as_str())).collect();
+    let download = crate::dependency_licenses::go_mod_cache().join("cache").join("download");
+
+    let mut queue: VecDeque<(String, String)> = VecDeque::new();
+    for (module, version, indirect) in &requires {
+        if !indirect {
+            let id = graph.add_node("Go", module, version);
+            graph.add_edge(&root, &id);
+            queue.push_back((module.clone(), version.clone()));
+        }
+    }
+    let mut seen: BTreeSet<(String, String)> = queue.iter().cloned().collect();
+    while let Some((module, version)) = queue.pop_front() {
+        let from = graph.add_node("Go", &module, &version);
+        let mod_file: PathBuf =
+            download.join(crate::dependency_licenses::escape_module(&module)).join("@v").join(format!("{}.mod", version));
+        let Ok(content) = fs::read_to_string(&mod_file) else { continue };
+        for (dep, dep_version, _) in go_requires(&content) {
+            let dep_version = selected.get(dep.as_str()).map(|v| v.to_string()).unwrap_or(dep_version);
+            let to = graph.add_node("Go", &dep, &dep_version);
+            graph.add_edge(&from, &to);
+            if seen.insert((dep.clone(), dep_version.clone())) {
+                queue.push_back((dep, dep_version));
+            }
+        }
+    }
+    // Requirements not reached through the cache stay visible under the main module
+    for (module, version, _) in &requires {
+        let id = format!("{}@{}", module, version);
+        if !graph.edges.values().any(|tos| tos.contains(&id)) {
+            graph.add_node("Go", module, version);
+            graph.add_edge(&root, &id);
+        }
+    }
+    true
+}
+
+/// npm: `packages` of package-lock.json v2/v3, resolving each dependency the way Node does
+/// (the nearest `node_modules` up the tree).
+fn npm_graph(project_dir: &Path, graph: &mut Graph) -> bool {
+    let Ok(data) = fs::read_to_string(project_dir.join("package-lock.json")) else { return false };
+    let Ok(lock) = serde_json::from_str::<serde_json::Value>(&data) else { return false };
+    let Some(packages) = lock.get("packages").and_then(|p| p.as_object()) else { return false };
+
+    let name_of = |path: &str, info: &serde_json::Value| -> String {
+        match path.rsplit_once("node_modules/") {
+            Some((_, name)) => name.to_string(),
+            None => info.get("name").and_then(|n| n.as_str()).unwrap_or("(package.json)").to_string(),
+        }
+    };
+    let id_of = |graph: &mut Graph, path: &str| -> Option<String> {
+        let info = packages.get(path)?;
+        let version = info.get("version").and_then(|v| v.as_str()).unwrap_or("");
+        Some(graph.add_node("npm", &name_of(path, info), if path.is_empty() { "" } else { version }))
+    };
+    let resolve = |from: &str, dep: &str| -> Option<String> {
+        let mut base = from.to_string();
+        loop {
+            let candidate = if base.is_empty() { format!("node_modules/{}", dep) } else { format!("{}/node_modules/{}", base, dep) };
+            if packages.contains_key(&candidate) {
+                return Some(candidate);
+            }
+            if base.is_empty() {
+                return None;
+            }
+            base = match base.rfind("/node_modules/") {
+                Some(i) => base[..i].to_string(),
+                None => String::new(),
+            };
+        }
+    };
+
+    if let Some(root) = id_of(graph, "") {
+        graph.roots.push(root);
+    }
+    for (path, info) in packages {
+        if info.get("link").and_then(|l| l.as_bool()) == Some(true) {
+            continue;
+        }
+        let Some(from) = id_of(graph, path) else { continue };
+        let mut kinds = vec!["dependencies", "optionalDependencies", "peerDependencies"];
+        if path.is_empty() {
+            kinds.push("devDependencies");
+        }
+        for kind in kinds {
+            for dep in info.get(kind).and_then(|d| d.as_object()).into_iter().flat_map(|d| d.keys()) {
+                if let Some(to) = resolve(path, dep).and_then(|p| id_of(graph, &p)) {
+                    graph.add_edge(&from, &to);
+                }
+            }
+        }
+    }
+    true
+}
+
+/// Rust: Cargo.lock; the packages without `source` are the workspace members.
+fn cargo_graph(project_dir: &Path, graph: &mut Graph) -> bool {
+    let Ok(data) = fs::read_to_string(project_dir.join("Cargo.lock")) else { return false };
+    let Ok(doc) = data.parse::<toml_edit::DocumentMut>() else { return false };
+    let Some(packages) = doc.get("package").and_then(|p| p.as_array_of_tables()) else { return false };
+
+    let entries: Vec<(String, String, bool, Vec<String>)> = packages
+        .iter()
+        .filter_map(|p| {
+            let name = p.get("name")?.as_str()?.to_string();
+            let version = p.get("version")?.as_str()?.to_string();
+            let deps = p
+                .get("dependencies")
+                .and_then(|d| d.as_array())
+                .map(|a| a.iter().filter_map(|d| d.as_str().map(str::to_string)).collect())
+                .unwrap_or_default();
+            Some((name, version, p.get("source").is_none(), deps))
+        })
+        .collect();
+    for (name, version, local, _) in &entries {
+        if *local {
+            graph.add_root("crates.io", name, version);
+        }
+    }
+    for (name, version, _, deps) in &entries {
+        let from = graph.add_node("crates.io", name, version);
+        for dep in deps {
+            // "name", "name version" or "name version (source)"; the version only when ambiguous
+            let mut parts = dep.split_whitespace();
+            let dep_name = parts.next().unwrap_or("");
+            let dep_version = parts.next();
+            let target = entries.iter().find(|(n, v, _, _)| n == dep_name && dep_version.is_none_or(|dv| dv == v));
+            if let Some((n, v, _, _)) = target {
+                let to = graph.add_node("crates.io", n, v);
+                graph.add_edge(&from, &to);
+            }
+        }
+    }
+    true
+}
+
+/// Graph of every ecosystem the project pins: go.mod, package-lock.json and Cargo.lock.
+pub fn build(project_dir: &Path) -> Option<Graph> {
+    let mut graph = Graph::default();
+    let go = go_graph(project_dir, &mut graph);
+    let npm = npm_graph(project_dir, &mut graph);
+    let cargo = cargo_graph(project_dir, &mut graph);
+    (go || npm || cargo).then_some(graph)
+}
+
+fn label(node: &Node) -> String {
+    if node.version.is_empty() { node.name.clone() } else { format!("{} {}", node.name, node.version) }
+}
+
+pub fn render_dot(graph: &Graph) -> String {
+    let quote = |s: &str| format!("\"{}\"", s.replace('\\', "\\\\").replace('"', "\\\""));
+    let mut out = String::from("digraph dependencies {\n  rankdir=LR;\n  node [shape=box, fontname=\"Helvetica\"];\n");
+    for (id, node) in &graph.nodes {
+        let style = if graph.roots.contains(id) { ", style=bold" } else { "" };
+        out.push_str(&format!("  {} [label={}{}];\n", quote(id), quote(&label(node)), style));
+    }
+    for (from, tos) in &graph.edges {
+        for to in tos {
+            out.push_str(&format!("  {} -> {};\n", quote(from), quote(to)));
+        }
+    }
+    out.push_str("}\n");
+    out
+}
+
+pub fn render_mermaid(graph: &Graph) -> String {
+    // Mermaid ids cannot hold `/` or `@`; number the nodes instead
+    let index: BTreeMap<&String, usize> = graph.nodes.keys().enumerate().map(|(i, id)| (id, i)).collect();
+    let mut out = String::from("graph LR\n");
+    for (id, node) in &graph.nodes {
+        let text = label(node).replace('"', "#quot;");
+        if graph.roots.contains(id) {
+            out.push_str(&format!("  n{}[[\"{}\"]]\n", index[id], text));
+        } else {
+            out.push_str(&format!("  n{}[\"{}\"]\n", index[id], text));
+        }
+    }
+    for (from, tos) in &graph.edges {
+        for to in tos {
+            out.push_str(&format!("  n{} --> n{}\n", index[from], index[to]));
+        }
+    }
+    out
+}
+
+pub fn render_json(graph: &Graph) -> String {
+    let nodes: Vec<serde_json::Value> = graph
+        .nodes
+        .iter()
+        .map(|(id, n)| serde_json::json!({ "id": id, "name": n.name, "version": n.version, "ecosystem": n.ecosystem }))
+        .collect();
+    let edges: Vec<serde_json::Value> = graph
+        .edges
+        .iter()
+        .flat_map(|(from, tos)| tos.iter().map(move |to| serde_json::json!({ "from": from, "to": to })))
+        .collect();
+    let doc = serde_json::json!({ "roots": graph.roots, "nodes": nodes, "edges": edges });
+    serde_json::to_string_pretty(&doc).unwrap_or_default()
+}
+
+/// `dx dev-dependencies graph`: print the dependency tree for Graphviz, Mermaid or other tools.
+/// Returns the exit code: 1 when there is nothing to draw.
+pub fn cmd_graph(dir: Option<PathBuf>, format: GraphFormat, depth: Option<usize>, filter: Vec<String>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(graph) = build(&project_dir) else {
+        eprintln!("Nenhum go.mod, package-lock.json ou Cargo.lock em {}.", project_dir.display());
+        return 1;
+    };
+    let graph = graph.prune(depth, &filter);
+    if !filter.is_empty() && graph.nodes.is_empty() {
+        eprintln!("Nenhuma dependência começa com: {}.", filter.join(", "));
+        return 1;
+    }
+    match format {
+        GraphFormat::Dot => print!("{}", render_dot(&graph)),
+        GraphFormat::Mermaid => print!("{}", render_mermaid(&graph)),
+        GraphFormat::Json => println!("{}", render_json(&graph)),
+    }
+    0
+}
@@ -0,0 +1,610 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dependency_audit::Package;
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Python virtualenvs looked up in the project, in order.
+const VENV_DIRS: &[&str] = &[".venv", "venv", "env"];
+
+/// `licenses:` of dx.yaml: SPDX ids (or prefixes ending in `*`, such as `GPL-*`) the project accepts or forbids.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct LicensePolicy {
+    /// When not empty, every other license is forbidden
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub allow: Vec<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub deny: Vec<String>,
+}
+
+impl LicensePolicy {
+    pub fn is_empty(&self) -> bool {
+        self.allow.is_empty() && self.deny.is_empty()
+    }
+
+    fn accepts(&self, id: &str) -> bool {
+        let matches = |pattern: &String| match pattern.strip_suffix('*') {
+            Some(prefix) => id.to_lowercase().starts_with(&prefix.to_lowercase()),
+            None => pattern.eq_ignore_ascii_case(id),
+        };
+        !self.deny.iter().any(matches) && (self.allow.is_empty() || self.allow.iter().any(matches))
+    }
+
+    /// A license expression passes when one of its `OR` alternatives has every `AND` part accepted.
+    pub fn verdict(&self, license: Option<&str>) -> Verdict {
+        let Some(license) = license else { return Verdict::Unknown };
+        if alternatives(license).iter().any(|all| all.iter().all(|id| self.accepts(id))) {
+            Verdict::Allowed
+        } else {
+            Verdict::Forbidden
+        }
+    }
+}
+
+/// `MIT OR (Apache-2.0 AND BSD-3-Clause)` as `[[MIT], [Apache-2.0, BSD-3-Clause]]`. Parentheses
+/// are flattened, `/` is the old `OR` of Cargo and npm, and `WITH <exception>` is dropped.
+fn alternatives(expr: &str) -> Vec<Vec<String>> {
+    let flat = expr.replace(['(', ')'], " ").replace('/', " OR ");
+    let words: Vec<&str> = flat.split_whitespace().collect();
+    let mut out = vec![Vec::new()];
+    let mut skip_next = false;
+    for word in words {
+        if skip_next {
+            skip_next = false;
+            continue;
+        }
+        match word.to_uppercase().as_str() {
+            "OR" => out.push(Vec::new()),
+            "AND" => {}
+            "WITH" => skip_next = true,
+            _ => out.last_mut().unwrap().push(word.to_string()),
+        }
+    }
+    out.retain(|all| !all.is_empty());
+    out
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Verdict {
+    Allowed,
+    Forbidden,
+    Unknown,
+}
+
+impl Verdict {
+    fn key(self) -> &'static str {
+        match self {
+            Verdict::Allowed => "allowed",
+            Verdict::Forbidden => "forbidden",
+            Verdict::Unknown => "unknown",
+        }
+    }
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum LicenseFormat {
+    /// Resumo por licença, proibidas e desconhecidas
+    Text,
+    /// Objeto JSON com todas as dependências (para CI)
+    Json,
+    /// SARIF 2.1.0 com as licenças proibidas e desconhecidas (GitHub code scanning)
+    Sarif,
+}
+
+/// The license found for one dependency.
+#[derive(Debug, Clone)]
+pub struct Resolved {
+    pub package: Package,
+    /// SPDX expression, or the license name as the package declares it
+    pub license: Option<String>,
+    /// Where the license was read (file or manifest)
+    pub evidence: Option<String>,
+}
+
+/// Identify a license text by its distinctive sentences.
+pub fn identify(text: &str) -> Option<&'static str> {
+    let t = text.split_whitespace().collect::<Vec<_>>().join(" ");
+    let lower = t.to_lowercase();
+    let has = |s: &str| lower.contains(s);
+    let id = if has("gnu affero general public license") {
+        "AGPL-3.0"
+    } else if has("gnu lesser general public license") {
+        if has("version 2.1") { "LGPL-2.1" } else { "LGPL-3.0" }
+    } else if has("gnu general public license") {
+        if has("version 3") { "GPL-3.0" } else { "GPL-2.0" }
+    } else if has("mozilla public license") && has("2.0") {
+        "MPL-2.0"
+    } else if has("apache license") && has("version 2.0") {
+        "Apache-2.0"
+    } else if has("permission is hereby granted, free of charge") {
+        "MIT"
+    } else if has("permission to use, copy, modify, and/or distribute this software for any purpose") {
+        "ISC"
+    } else if has("redistribution and use in source and binary forms") {
+        if has("neither the name") || has("names of its contributors") { "BSD-3-Clause" } else { "BSD-2-Clause" }
+    } else if has("free and unencumbered software released into the public domain") {
+        "Unlicense"
+    } else if has("eclipse public license") && has("2.0") {
+        "EPL-2.0"
+    } else {
+        return None;
+    };
+    Some(id)
+}
+
+/// License of a package directory from its LICENSE/LICENCE/COPYING files; several licenses
+/// (LICENSE-MIT and LICENSE-APACHE, say) mean the user may pick one.
+fn license_from_dir(dir: &Path) -> Option<(String, PathBuf)> {
+    let mut files: Vec<PathBuf> = fs::read_dir(dir)
+        .ok()?
+        .flatten()
+        .map(|e| e.path())
+        .filter(|p| p.is_file())
+        .filter(|p| {
+            let name = p.file_name().map(|n| n.to_string_lossy().to_uppercase()).unwrap_or_default();
+            ["LICENSE", "LICENCE", "COPYING"].iter().any(|prefix| name.starts_with(prefix))
+        })
+        .collect();
+    files.sort();
+    let mut ids: Vec<&str> = Vec::new();
+    for file in &files {
+        if let Some(id) = fs::read_to_string(file).ok().as_deref().and_then(identify) {
+            if !ids.contains(&id) {
+                ids.push(id);
+            }
+        }
+    }
+    let first = files.first()?.clone();
+    (!ids.is_empty()).then(|| (ids.join(" OR "), first))
+}
+
+/// `$GOMODCACHE`, `$GOPATH/pkg/mod` or `~/go/pkg/mod`.
+pub(crate) fn go_mod_cache() -> PathBuf {
+    if let Some(d) = std::env::var_os("GOMODCACHE").filter(|d| !d.is_empty()) {
+        return PathBuf::from(d);
+    }
+    let gopath = std::env::var_os("GOPATH")
+        .and_then(|p| std::env::split_paths(&p).next())
+        .unwrap_or_else(|| crate::paths::home().join("go"));
+    gopath.join("pkg").join("mod")
+}
+
+/// Module path as stored in the module cache: capitals become `!` + lowercase.
+pub(crate) fn escape_module(path: &str) -> String {
+    let mut out = String::with_capacity(path.len());
+    for c in path.chars() {
+        if c.is_ascii_uppercase() {
+            out.push('!');
+            out.push(c.to_ascii_lowercase());
+        } else {
+            out.push(c);
+        }
+    }
+    out
+}
+
+fn resolve_go(project_dir: &Path, package: &Package) -> Option<(String, PathBuf)> {
+    let vendored = project_dir.join("vendor").join(&package.name);
+    let cached = go_mod_cache().join(format!("{}@v{}", escape_module(&package.name), package.version));
+    license_from_dir(&vendored).or_else(|| license_from_dir(&cached))
+}
+
+/// `license` of a package.json or lockfile entry: `"MIT"`, `{ "type": "MIT" }` or the old `licenses` list.
+fn npm_license(info: &serde_json::Value) -> Option<String> {
+    let name = |v: &serde_json::Value| v.as_str().or_else(|| v.get("type").and_then(|t| t.as_str())).map(str::to_string);
+    if let Some(license) = info.get("license").and_then(name) {
+        return Some(license);
+    }
+    let list: Vec<String> = info.get("licenses")?.as_array()?.iter().filter_map(name).collect();
+    (!list.is_empty()).then(|| list.join(" OR "))
+}
+
+/// Licenses recorded by package-lock.json v2/v3, by (name, version).
+fn npm_lock_licenses(project_dir: &Path) -> BTreeMap<(String, String), String> {
+    let mut out = BTreeMap::new();
+    let Ok(data) = fs::read_to_string(project_dir.join("package-lock.json")) else { return out };
+    let Ok(lock) = serde_json::from_str::<serde_json::Value>(&data) else { return out };
+    for (path, info) in lock.get("packages").and_then(|p| p.as_object()).into_iter().flatten() {
+        let Some((_, name)) = path.rsplit_once("node_modules/") else { continue };
+        let (Some(version), Some(license)) = (info.get("version").and_then(|v| v.as_str()), npm_license(info)) else {
+            continue;
+        };
+        out.insert((name.to_string(), version.to_string()), license);
+    }
+    out
+}
+
+fn resolve_npm(project_dir: &Path, package: &Package, lock: &BTreeMap<(String, String), String>) -> Option<(String, PathBuf)> {
+    if let Some(license) = lock.get(&(package.name.clone(), package.version.clone())) {
+        return Some((license.clone(), PathBuf::from("package-lock.json")));
+    }
+    let dir = project_dir.join("node_modules").join(&package.name);
+    let manifest = dir.join("package.json");
+    let declared = fs::read_to_string(&manifest).ok().and_then(|d| serde_json::from_str::<serde_json::Value>(&d).ok());
+    match declared.as_ref().and_then(npm_license) {
+        Some(license) => Some((license, manifest)),
+        None => license_from_dir(&dir),
+    }
+}
+
+/// `site-packages` directories of the project's virtualenvs.
+fn site_packages(project_dir: &Path) -> Vec<PathBuf> {
+    let mut out = Vec::new();
+    for venv in VENV_DIRS {
+        let venv = project_dir.join(venv);
+        let windows = venv.join("Lib").join("site-packages");
+        if windows.is_dir() {
+            out.push(windows);
+        }
+        for entry in fs::read_dir(venv.join("lib")).into_iter().flatten().flatten() {
+            let site = entry.path().join("site-packages");
+            if entry.file_name().to_string_lossy().starts_with("python") && site.is_dir() {
+                out.push(site);
+            }
+        }
+    }
+    out
+}
+
+/// PyPI names compare case-insensitively, with `-`, `_` and `.` equivalent.
+fn normalize_pypi(name: &str) -> String {
+    name.to_lowercase().replace(['_', '.'], "-")
+}
+
+/// Installed distributions as (normalized name, version, dist-info directory).
+fn installed_dists(project_dir: &Path) -> Vec<(String, String, PathBuf)> {
+    let mut out = Vec::new();
+    for site in site_packages(project_dir) {
+        for entry in fs::read_dir(&site).into_iter().flatten().flatten() {
+            let file_name = entry.file_name().to_string_lossy().into_owned();
+            let Some(stem) = file_name.strip_suffix(".dist-info") else { continue };
+            let Some((name, version)) = stem.rsplit_once('-') else { continue };
+            out.push((normalize_pypi(name), version.to_string(), entry.path()));
+        }
+    }
+    out
+}
+
+/// Trove classifiers (`License :: OSI Approved :: MIT License`) as SPDX ids.
+const CLASSIFIERS: &[(&str, &str)] = &[
+    ("MIT License", "MIT"),
+    ("Apache Software License", "Apache-2.0"),
+    ("BSD License", "BSD"),
+    ("ISC License (ISCL)", "ISC"),
+    ("Mozilla Public License 2.0 (MPL 2.0)", "MPL-2.0"),
+    ("GNU General Public License v2 (GPLv2)", "GPL-2.0"),
+    ("GNU General Public License v3 (GPLv3)", "GPL-3.0"),
+    ("GNU Lesser General Public License v2 or later (LGPLv2+)", "LGPL-2.1"),
+    ("GNU Lesser General Public License v3 (LGPLv3)", "LGPL-3.0"),
+    ("GNU Affero General Public License v3", "AGPL-3.0"),
+    ("Python Software Foundation License", "PSF-2.0"),
+    ("The Unlicense (Unlicense)", "Unlicense"),
+];
+
+/// License of a METADATA file: `License-Expression`, then license classifiers, then a short `License` field.
+fn license_from_metadata(metadata: &str) -> Option<String> {
+    let headers = metadata.split("\n\n").next().unwrap_or("");
+    let field = |key: &'static str| {
+        headers
+            .lines()
+            .filter_map(move |l| l.strip_prefix(key).and_then(|rest| rest.strip_prefix(':')))
+            .map(|v| v.trim().to_string())
+    };
+    if let Some(expr) = field("License-Expression").next() {
+        return Some(expr);
+    }
+    let classified: Vec<String> = field("Classifier")
+        .filter_map(|c| c.strip_prefix("License :: ").map(|c| c.rsplit(" :: ").next().unwrap_or("").to_string()))
+        .map(|name| CLASSIFIERS.iter().find(|(n, _)| *n == name).map(|(_, id)| id.to_string()).unwrap_or(name))
+        .filter(|name| !name.is_empty() && name != "OSI Approved")
+        .collect();
+    if !classified.is_empty() {
+        return Some(classified.join(" OR "));
+    }
+    // Some packages paste the whole license text here
+    field("License").next().filter(|l| !l.is_empty() && l.len() <= 60 && !l.eq_ignore_ascii_case("UNKNOWN"))
+}
+
+fn resolve_pypi(package: &Package, dists: &[(String, String, PathBuf)]) -> Option<(String, PathBuf)> {
+    let name = normalize_pypi(&package.name);
+    let (_, _, dir) = dists.iter().find(|(n, v, _)| *n == name && *v == package.version)?;
+    let metadata = dir.join("METADATA");
+    match fs::read_to_string(&metadata).ok().as_deref().and_then(license_from_metadata) {
+        Some(license) => Some((license, metadata)),
+        None => license_from_dir(dir).or_else(|| license_from_dir(&dir.join("licenses"))),
+    }
+}
+
+/// `$CARGO_HOME` or `~/.cargo`.
+fn cargo_home() -> PathBuf {
+    std::env::var_os("CARGO_HOME")
+        .filter(|d| !d.is_empty())
+        .map(PathBuf::from)
+        .unwrap_or_else(|| crate::paths::home().join(".cargo"))
+}
+
+fn resolve_crate(package: &Package) -> Option<(String, PathBuf)> {
+    let registries = fs::read_dir(cargo_home().join("registry").join("src")).ok()?;
+    for registry in registries.flatten() {
+        let dir = registry.path().join(format!("{}-{}", package.name, package.version));
+        let manifest = dir.join("Cargo.toml");
+        let Ok(data) = fs::read_to_string(&manifest) else { continue };
+        let doc = data.parse::<toml_edit::DocumentMut>().ok();
+        let declared = doc.as_ref().and_then(|d| d.get("package")?.get("license")?.as_str().map(str::to_string));
+        return match declared {
+            Some(license) => Some((license, manifest)),
+            None => license_from_dir(&dir),
+        };
+    }
+    None
+}
+
+/// `$NUGET_PACKAGES` or `~/.nuget/packages`.
+fn nuget_packages() -> PathBuf {
+    std::env::var_os("NUGET_PACKAGES")
+        .filter(|d| !d.is_empty())
+        .map(PathBuf::from)
+        .unwrap_or_else(|| crate::paths::home().join(".nuget").join("packages"))
+}
+
+/// License of a restored NuGet package: the nuspec's `<license type="expression">`, the license
+/// file it names, else the LICENSE files of the package.
+fn resolve_nuget(package: &Package) -> Option<(String, PathBuf)> {
+    let id = package.name.to_lowercase();
+    let dir = nuget_packages().join(&id).join(package.version.to_lowercase());
+    let nuspec = dir.join(format!("{}.nuspec", id));
+    let spec = fs::read_to_string(&nuspec).ok().and_then(|d| crate::xml::parse(&d));
+    if let Some(license) = spec.as_ref().and_then(|s| s.child("metadata")?.child("license")) {
+        let text = license.text.trim();
+        match license.attribute("type").as_deref() {
+            Some("expression") if !text.is_empty() => return Some((text.to_string(), nuspec)),
+            Some("file") if !text.is_empty() => {
+                let file = dir.join(text);
+                if let Some(id) = fs::read_to_string(&file).ok().as_deref().and_then(identify) {
+                    return Some((id.to_string(), file));
+                }
+            }
+            _ => {}
+        }
+    }
+    license_from_dir(&dir)
+}
+
+/// License of a Hex package fetched by `mix deps.get` or `rebar3 get-deps`: the `licenses` of its
+/// hex_metadata.config, else its LICENSE files.
+fn resolve_hex(project_dir: &Path, package: &Package) -> Option<(String, PathBuf)> {
+    match crate::beam_build::fetched_licenses(project_dir, &package.name, &package.version) {
+        Some((licenses, file)) => Some((licenses.join(" OR "), file)),
+        None => license_from_dir(&project_dir.join("deps").join(&package.name)),
+    }
+}
+
+/// Every dependency to report: the pinned ones (`dependency_audit::collect`), go.sum modules
+/// missing from an old go.mod, and the distributions installed in the project's virtualenv.
+pub fn packages(project_dir: &Path) -> Vec<Package> {
+    let mut packages = crate::dependency_audit::collect(project_dir);
+
+    if let Ok(data) = fs::read_to_string(project_dir.join("go.sum")) {
+        let known: BTreeSet<String> = packages.iter().filter(|p| p.ecosystem == "Go").map(|p| p.name.clone()).collect();
+        // Several versions of one module may be listed; the build uses the highest
+        let mut extra: BTreeMap<String, String> = BTreeMap::new();
+        for line in data.lines() {
+            let mut parts = line.split_whitespace();
+            let (Some(name), Some(version)) = (parts.next(), parts.next()) else { continue };
+            if version.ends_with("/go.mod") || known.contains(name) {
+                continue;
+            }
+            let version = version.trim_start_matches('v').to_string();
+            let current = extra.entry(name.to_string()).or_default();
+            if crate::dependency_audit::version_key(&version) > crate::dependency_audit::version_key(current) {
+                *current = version;
+            }
+        }
+        for (name, version) in extra {
+            packages.push(Package { ecosystem: "Go", name, version, source: "go.sum".to_string() });
+        }
+    }
+
+    let pinned: BTreeSet<(String, String)> =
+        packages.iter().filter(|p| p.ecosystem == "PyPI").map(|p| (normalize_pypi(&p.name), p.version.clone())).collect();
+    for (name, version, dir) in installed_dists(project_dir) {
+        if !pinned.contains(&(name.clone(), version.clone())) {
+            let source = dir.parent().and_then(|d| d.strip_prefix(project_dir).ok()).map(|d| d.display().to_string());
+            packages.push(Package { ecosystem: "PyPI", name, version, source: source.unwrap_or_default() });
+        }
+    }
+    packages.sort();
+    packages.dedup();
+    packages
+}
+
+/// Find the license of each package in the places its package manager leaves it on disk.
+pub fn resolve(project_dir: &Path, packages: &[Package]) -> Vec<Resolved> {
+    let npm_lock = npm_lock_licenses(project_dir);
+    let dists = installed_dists(project_dir);
+    let phase = crate::progress::Phase::start("dev-dependencies.licenses", "Resolvendo as licenças das dependências");
+    let resolved = packages
+        .iter()
+        .enumerate()
+        .map(|(i, package)| {
+            phase.step(i + 1, packages.len(), &package.name);
+            let found = match package.ecosystem {
+                "Go" => resolve_go(project_dir, package),
+                "npm" => resolve_npm(project_dir, package, &npm_lock),
+                "PyPI" => resolve_pypi(package, &dists),
+                "crates.io" => resolve_crate(package),
+                "NuGet" => resolve_nuget(package),
+                "Hex" => resolve_hex(project_dir, package),
+                _ => None,
+            };
+            let (license, evidence) = match found {
+                Some((license, path)) => {
+                    let shown = path.strip_prefix(project_dir).unwrap_or(&path).display().to_string();
+                    (Some(license), Some(shown))
+                }
+                None => (None, None),
+            };
+            Resolved { package: package.clone(), license, evidence }
+        })
+        .collect();
+    phase.finish(true);
+    resolved
+}
+
+fn render_text(resolved: &[Resolved], policy: &LicensePolicy) -> String {
+    let mut out = String::new();
+    let mut counts: BTreeMap<&str, usize> = BTreeMap::new();
+    for r in resolved {
+        *counts.entry(r.license.as_deref().unwrap_or("(desconhecida)")).or_default() += 1;
+    }
+    out.push_str(&format!("Licenças de {} dependências:\n\n", resolved.len()));
+    let mut by_count: Vec<(&str, usize)> = counts.into_iter().collect();
+    by_count.sort_by(|a, b| b.1.cmp(&a.1).then(a.0.cmp(b.0)));
+    for (license, n) in by_count {
+        out.push_str(&format!("  {:<32} {}\n", license, n));
+    }
+
+    for (verdict, title, mark) in [(Verdict::Forbidden, "Licenças proibidas", "✗"), (Verdict::Unknown, "Sem licença identificada", "?")] {
+        let hits: Vec<&Resolved> = resolved.iter().filter(|r| policy.verdict(r.license.as_deref()) == verdict).collect();
+        if hits.is_empty() {
+            continue;
+        }
+        out.push_str(&format!("\n{} ({}):\n", title, hits.len()));
+        for r in hits {
+            let p = &r.package;
+            let detail = match (&r.license, &r.evidence) {
+                (Some(license), Some(evidence)) => format!("{} ({})", license, evidence),
+                _ => p.source.clone(),
+            };
+            out.push_str(&format!("  {} {} {} ({}) — {}\n", mark, p.name, p.version, p.ecosystem, detail));
+        }
+    }
+    out
+}
+
+fn render_json(resolved: &[Resolved], policy: &LicensePolicy) -> String {
+    let items: Vec<serde_json::Value> = resolved
+        .iter()
+        .map(|r| {
+            serde_json::json!({
+                "name": r.package.name,
+                "version": r.package.version,
+                "ecosystem": r.package.ecosystem,
+                "source": r.package.source,
+                "license": r.license,
+                "evidence": r.evidence,
+                "verdict": policy.verdict(r.license.as_deref()).key(),
+            })
+        })
+        .collect();
+    let doc = serde_json::json!({ "policy": policy, "dependencies": items });
+    serde_json::to_string_pretty(&doc).unwrap_or_default()
+}
+
+/// The SARIF log: a result for each forbidden license (an error) and each unknown one (an error
+/// with `fail_on_unknown`, a note otherwise), declared where the package is pinned.
+fn render_sarif(project_dir: &Path, resolved: &[Resolved], policy: &LicensePolicy, fail_on_unknown: bool) -> crate::sarif::Log {
+    use crate::sarif::{Level, Message, Rule, RuleProperties, SarifResult};
+    const FORBIDDEN: &str = "dx/license-forbidden";
+    const UNKNOWN: &str = "dx/license-unknown";
+    let rule = |id: &str, description: &str| Rule {
+        id: id.to_string(),
+        short_description: Message::new(description),
+        help_uri: None,
+        properties: RuleProperties { security_severity: None, tags: vec!["license".to_string(), "dependency".to_string()] },
+    };
+    let rules = vec![
+        rule(FORBIDDEN, "Dependência com licença proibida pela política (licenses: do dx.yaml, --allow, --deny)"),
+        rule(UNKNOWN, "Dependência sem licença identificada"),
+    ];
+    let results = resolved
+        .iter()
+        .filter_map(|r| {
+            let p = &r.package;
+            let (rule_id, level, text) = match (policy.verdict(r.license.as_deref()), &r.license) {
+                (Verdict::Forbidden, Some(license)) => {
+                    (FORBIDDEN, Level::Error, format!("{} {} ({}) usa a licença proibida {}", p.name, p.version, p.ecosystem, license))
+                }
+                (Verdict::Unknown, _) => {
+                    let level = if fail_on_unknown { Level::Error } else { Level::Note };
+                    (UNKNOWN, level, format!("{} {} ({}) não tem licença identificada", p.name, p.version, p.ecosystem))
+                }
+                _ => return None,
+            };
+            Some(SarifResult {
+                rule_id: rule_id.to_string(),
+                level,
+                message: Message::new(text),
+                locations: vec![crate::sarif::location(project_dir, &p.source, &p.name)],
+                partial_fingerprints: crate::sarif::fingerprint(&[rule_id, p.ecosystem, &p.name, &p.version]),
+            })
+        })
+        .collect();
+    crate::sarif::Log::new(rules, results)
+}
+
+/// `dx dev-dependencies licenses`: report the license of every dependency, checked against the
+/// `licenses:` policy of the team profile plus those of dx.yaml and `allow`/`deny`. Returns the exit code: 1 when a license is
+/// forbidden (or unknown, with `fail_on_unknown`), 2 when dx.yaml cannot be read.
+pub fn cmd_licenses(
+    dir: Option<PathBuf>,
+    format: LicenseFormat,
+    allow: Vec<String>,
+    deny: Vec<String>,
+    fail_on_unknown: bool,
+) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let project = match crate::tasks::load(&project_dir) {
+        Ok(f) => f.map(|f| f.licenses).unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", crate::tasks::DX_FILE, e);
+            return 2;
+        }
+    };
+    let mut policy = crate::team_profile::effective(&project_dir).licenses;
+    policy.allow.extend(project.allow);
+    policy.deny.extend(project.deny);
+    policy.allow.extend(allow);
+    policy.deny.extend(deny);
+
+    let packages = packages(&project_dir);
+    if packages.is_empty() {
+        eprintln!(
+            "Nenhuma dependência com versão exata em {} (go.mod, package-lock.json, requirements*.txt com ==, Cargo.lock, virtualenv).",
+            project_dir.display()
+        );
+        return 0;
+    }
+    let resolved = resolve(&project_dir, &packages);
+
+    let missing_go = resolved.iter().any(|r| r.package.ecosystem == "Go" && r.license.is_none());
+    if missing_go && !project_dir.join("vendor").is_dir() {
+        eprintln!("Aviso: módulos Go fora do cache ({}); execute go mod download.", go_mod_cache().display());
+    }
+    let missing_npm = resolved.iter().any(|r| r.package.ecosystem == "npm" && r.license.is_none());
+    if missing_npm && !project_dir.join("node_modules").is_dir() {
+        eprintln!("Aviso: sem node_modules; algumas licenças npm não foram encontradas (execute npm install).");
+    }
+
+    match format {
+        LicenseFormat::Text => print!("{}", render_text(&resolved, &policy)),
+        LicenseFormat::Json => println!("{}", render_json(&resolved, &policy)),
+        LicenseFormat::Sarif => crate::output::print(&render_sarif(&project_dir, &resolved, &policy, fail_on_unknown)),
+    }
+    let count = |v: Verdict| resolved.iter().filter(|r| policy.verdict(r.license.as_deref()) == v).count();
+    let (forbidden, unknown) = (count(Verdict::Forbidden), count(Verdict::Unknown));
+    if forbidden > 0 || (fail_on_unknown && unknown > 0) {
+        if format == LicenseFormat::Text {
+            match (forbidden, fail_on_unknown && unknown > 0) {
+                (0, _) => println!("\n{} dependência(s) sem licença identificada (--fail-on-unknown).", unknown),
+                (n, false) => println!("\n{} dependência(s) com licença proibida.", n),
+                (n, true) => println!("\n{} dependência(s) com licença proibida e {} sem licença identificada.", n, unknown),
+            }
+        }
+        return 1;
+    }
+    0
+}
@@ -0,0 +1,346 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dependency_audit::Package;
+use crate::dependency_graph::Graph;
+use crate::dependency_licenses::Resolved;
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum SbomFormat {
+    /// CycloneDX 1.5 (JSON)
+    Cyclonedx,
+    /// SPDX 2.3 (JSON)
+    Spdx,
+}
+
+/// A checksum of a package as its lockfile records it.
+#[derive(Debug, Clone, PartialEq, Eq, PartialOrd, Ord)]
+pub struct Hash {
+    /// "SHA-1", "SHA-256", "SHA-384" or "SHA-512", as CycloneDX names them
+    pub alg: &'static str,
+    /// Lowercase hex
+    pub content: String,
+}
+
+impl Hash {
+    /// The algorithm as SPDX names it (`SHA256`).
+    fn spdx_alg(&self) -> String {
+        self.alg.replace('-', "")
+    }
+}
+
+fn hex(bytes: &[u8]) -> String {
+    bytes.iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+/// An SRI digest (`sha512-<base64>`) of package-lock.json.
+fn sri(integrity: &str) -> Option<Hash> {
+    let (alg, digest) = integrity.split_once('-')?;
+    let alg = match alg {
+        "sha1" => "SHA-1",
+        "sha256" => "SHA-256",
+        "sha384" => "SHA-384",
+        "sha512" => "SHA-512",
+        _ => return None,
+    };
+    Some(Hash { alg, content: hex(&crate::dev_db::base64_decode(digest)?) })
+}
+
+/// Checksums by (ecosystem, name, version): go.sum `h1:` hashes (SHA-256 of the module's file
+/// tree, the value the Go toolchain verifies), `integrity` of package-lock.json, `checksum` of
+/// Cargo.lock and the `--hash=` options of pip's requirements files.
+pub fn lockfile_hashes(project_dir: &Path) -> BTreeMap<(&'static str, String, String), BTreeSet<Hash>> {
+    let mut hashes: BTreeMap<(&'static str, String, String), BTreeSet<Hash>> = BTreeMap::new();
+
+    if let Ok(data) = fs::read_to_string(project_dir.join("go.sum")) {
+        for line in data.lines() {
+            let mut parts = line.split_whitespace();
+            let (Some(name), Some(version), Some(sum)) = (parts.next(), parts.next(), parts.next()) else { continue };
+            let Some(digest) = sum.strip_prefix("h1:").and_then(crate::dev_db::base64_decode) else { continue };
+            if version.ends_with("/go.mod") {
+                continue;
+            }
+            let key = ("Go", name.to_string(), version.trim_start_matches('v').to_string());
+            hashes.entry(key).or_default().insert(Hash { alg: "SHA-256", content: hex(&digest) });
+        }
+    }
+
+    let lock = fs::read_to_string(project_dir.join("package-lock.json")).ok();
+    if let Some(lock) = lock.and_then(|d| serde_json::from_str::<serde_json::Value>(&d).ok()) {
+        for (path, info) in lock.get("packages").and_then(|p| p.as_object()).into_iter().flatten() {
+            let Some((_, name)) = path.rsplit_once("node_modules/") else { continue };
+            let (Some(version), Some(integrity)) =
+                (info.get("version").and_then(|v| v.as_str()), info.get("integrity").and_then(|i| i.as_str()))
+            else {
+                continue;
+            };
+            let key = ("npm", name.to_string(), version.to_string());
+            hashes.entry(key).or_default().extend(integrity.split_whitespace().filter_map(sri));
+        }
+    }
+
+    if let Ok(doc) = fs::read_to_string(project_dir.join("Cargo.lock")).unwrap_or_default().parse::<toml_edit::DocumentMut>() {
+        for p in doc.get("package").and_then(|p| p.as_array_of_tables()).into_iter().flatten() {
+            let field = |k: &str| p.get(k).and_then(|v| v.as_str()).map(str::to_string);
+            if let (Some(name), Some(version), Some(checksum)) = (field("name"), field("version"), field("checksum")) {
+                let key = ("crates.io", name, version);
+                hashes.entry(key).or_default().insert(Hash { alg: "SHA-256", content: checksum.to_lowercase() });
+            }
+        }
+    }
+
+    for file in ["requirements.txt", "requirements-dev.txt"] {
+        let Ok(data) = fs::read_to_string(project_dir.join(file)) else { continue };
+        // `pkg==1.0 \` continues on the next lines with `--hash=sha256:...`
+        for requirement in data.replace("\\\r\n", " ").replace("\\\n", " ").lines() {
+            let Some((name, rest)) = requirement.split_once("==") else { continue };
+            let name = name.split('[').next().unwrap_or(name).trim().to_lowercase();
+            let version = rest.split([';', ' ', '#']).next().unwrap_or("").trim().to_string();
+            let found = rest.split_whitespace().filter_map(|w| w.strip_prefix("--hash=")).filter_map(|h| {
+                let (alg, digest) = h.split_once(':')?;
+                let alg = match alg {
+                    "sha256" => "SHA-256",
+                    "sha384" => "SHA-384",
+                    "sha512" => "SHA-512",
+                    _ => return None,
+                };
+                Some(Hash { alg, content: digest.to_lowercase() })
+            });
+            hashes.entry(("PyPI", name, version)).or_default().extend(found);
+        }
+    }
+    hashes.retain(|_, h| !h.is_empty());
+    hashes
+}
+
+/// Package URL (https://github.com/package-url/purl-spec) of a package.
+pub fn purl(package: &Package) -> String {
+    match package.ecosystem {
+        "Go" => format!("pkg:golang/{}@v{}", package.name, package.version),
+        "npm" => format!("pkg:npm/{}@{}", package.name.replace('@', "%40"), package.version),
+        "PyPI" => format!("pkg:pypi/{}@{}", package.name.to_lowercase().replace('_', "-"), package.version),
+        "crates.io" => format!("pkg:cargo/{}@{}", package.name, package.version),
+        "Maven" => format!("pkg:maven/{}@{}", package.name.replacen(':', "/", 1), package.version),
+        "NuGet" => format!("pkg:nuget/{}@{}", package.name, package.version),
+        "Hex" => format!("pkg:hex/{}@{}", package.name.to_lowercase(), package.version),
+        other => format!("pkg:generic/{}@{}?ecosystem={}", package.name, package.version, other),
+    }
+}
+
+/// A license that reads as an SPDX expression (ids joined by AND/OR/WITH), rather than a free-form name.
+fn spdx_expression(license: &str) -> bool {
+    license
+        .replace(['(', ')'], " ")
+        .split_whitespace()
+        .all(|w| w.chars().all(|c| c.is_ascii_alphanumeric() || matches!(c, '.' | '-' | '+')))
+}
+
+/// One dependency of the SBOM.
+struct Component<'a> {
+    resolved: &'a Resolved,
+    purl: String,
+    hashes: Vec<Hash>,
+}
+
+/// Edges of `dependency_graph` between the SBOM's components, by purl; the project's own
+/// modules (the graph roots) are mapped to `root`.
+fn dependencies(graph: &Graph, components: &[Component], root: &str) -> BTreeMap<String, BTreeSet<String>> {
+    let by_key: BTreeMap<(&str, &str, &str), &str> = components
+        .iter()
+        .map(|c| {
+            let p = &c.resolved.package;
+            ((p.ecosystem, p.name.as_str(), p.version.as_str()), c.purl.as_str())
+        })
+        .collect();
+    let reference = |id: &str| -> Option<String> {
+        if graph.roots.iter().any(|r| r == id) {
+            return Some(root.to_string());
+        }
+        let node = graph.nodes.get(id)?;
+        by_key.get(&(node.ecosystem, node.name.as_str(), node.version.trim_start_matches('v'))).map(|r| r.to_string())
+    };
+    let mut out: BTreeMap<String, BTreeSet<String>> = BTreeMap::new();
+    for (from, tos) in &graph.edges {
+        let Some(from) = reference(from) else { continue };
+        out.entry(from.clone()).or_default().extend(tos.iter().filter_map(|to| reference(to)).filter(|to| *to != from));
+    }
+    out
+}
+
+/// Creation time: `SOURCE_DATE_EPOCH` when set (reproducible builds), else now.
+fn timestamp() -> String {
+    let secs = std::env::var("SOURCE_DATE_EPOCH").ok().and_then(|v| v.trim().parse::<i64>().ok()).unwrap_or_else(|| {
+        std::time::SystemTime::now().duration_since(std::time::UNIX_EPOCH).map_or(0, |d| d.as_secs() as i64)
+    });
+    // The SBOM formats want whole seconds
+    crate::dev_kafka::format_timestamp(secs * 1000).replace(".000Z", "Z")
+}
+
+fn render_cyclonedx(project: &str, components: &[Component], deps: &BTreeMap<String, BTreeSet<String>>, root: &str) -> serde_json::Value {
+    let items: Vec<serde_json::Value> = components
+        .iter()
+        .map(|c| {
+            let p = &c.resolved.package;
+            let mut item = serde_json::json!({
+                "type": "library",
+                "bom-ref": c.purl,
+                "name": p.name,
+                "version": p.version,
+                "purl": c.purl,
+            });
+            if !c.hashes.is_empty() {
+                item["hashes"] = c.hashes.iter().map(|h| serde_json::json!({"alg": h.alg, "content": h.content})).collect();
+            }
+            match c.resolved.license.as_deref() {
+                Some(l) if l.contains(' ') && spdx_expression(l) => item["licenses"] = serde_json::json!([{"expression": l}]),
+                Some(l) if spdx_expression(l) => item["licenses"] = serde_json::json!([{"license": {"id": l}}]),
+                Some(l) => item["licenses"] = serde_json::json!([{"license": {"name": l}}]),
+                None => {}
+            }
+            item["properties"] = serde_json::json!([{"name": "dx:source", "value": p.source}]);
+            item
+        })
+        .collect();
+    let mut refs: BTreeSet<&str> = components.iter().map(|c| c.purl.as_str()).collect();
+    refs.insert(root);
+    let dependencies: Vec<serde_json::Value> = refs
+        .into_iter()
+        .map(|r| serde_json::json!({"ref": r, "dependsOn": deps.get(r).map(|d| d.iter().collect::<Vec<_>>()).unwrap_or_default()}))
+        .collect();
+    serde_json::json!({
+        "bomFormat": "CycloneDX",
+        "specVersion": "1.5",
+        "serialNumber": format!("urn:uuid:{}", crate::dev_kafka::uuid_v4()),
+        "version": 1,
+        "metadata": {
+            "timestamp": timestamp(),
+            "tools": {"components": [{"type": "application", "name": "dx", "version": env!("CARGO_PKG_VERSION")}]},
+            "component": {"type": "application", "bom-ref": root, "name": project},
+        },
+        "components": items,
+        "dependencies": dependencies,
+    })
+}
+
+/// SPDXID of a package: letters, digits, `.` and `-` only.
+fn spdx_id(purl: &str) -> String {
+    let id: String = purl
+        .trim_start_matches("pkg:")
+        .chars()
+        .map(|c| if c.is_ascii_alphanumeric() || c == '.' || c == '-' { c } else { '-' })
+        .collect();
+    format!("SPDXRef-Package-{}", id)
+}
+
+fn render_spdx(project: &str, components: &[Component], deps: &BTreeMap<String, BTreeSet<String>>, root: &str) -> serde_json::Value {
+    const ROOT_ID: &str = "SPDXRef-Package-project";
+    let noassertion = "NOASSERTION";
+    let mut packages = vec![serde_json::json!({
+        "name": project,
+        "SPDXID": ROOT_ID,
+        "downloadLocation": noassertion,
+        "filesAnalyzed": false,
+        "licenseConcluded": noassertion,
+        "licenseDeclared": noassertion,
+        "copyrightText": noassertion,
+        "primaryPackagePurpose": "APPLICATION",
+    })];
+    for c in components {
+        let p = &c.resolved.package;
+        let declared = c.resolved.license.as_deref().filter(|l| spdx_expression(l)).unwrap_or(noassertion);
+        let mut package = serde_json::json!({
+            "name": p.name,
+            "SPDXID": spdx_id(&c.purl),
+            "versionInfo": p.version,
+            "downloadLocation": noassertion,
+            "filesAnalyzed": false,
+            "licenseConcluded": noassertion,
+            "licenseDeclared": declared,
+            "copyrightText": noassertion,
+            "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": c.purl}],
+            "primaryPackagePurpose": "LIBRARY",
+        });
+        if !c.hashes.is_empty() {
+            package["checksums"] =
+                c.hashes.iter().map(|h| serde_json::json!({"algorithm": h.spdx_alg(), "checksumValue": h.content})).collect();
+        }
+        packages.push(package);
+    }
+
+    let id_of = |r: &str| if r == root { ROOT_ID.to_string() } else { spdx_id(r) };
+    let relationship = |from: String, kind: &str, to: String| {
+        serde_json::json!({"spdxElementId": from, "relationshipType": kind, "relatedSpdxElement": to})
+    };
+    let mut relationships = vec![relationship("SPDXRef-DOCUMENT".to_string(), "DESCRIBES", ROOT_ID.to_string())];
+    let in_graph: BTreeSet<&String> = deps.values().flatten().collect();
+    for (from, tos) in deps {
+        for to in tos {
+            relationships.push(relationship(id_of(from), "DEPENDS_ON", id_of(to)));
+        }
+    }
+    // Packages the graph does not reach (no lockfile edges, virtualenv) hang from the project
+    for c in components.iter().filter(|c| !in_graph.contains(&c.purl)) {
+        relationships.push(relationship(ROOT_ID.to_string(), "DEPENDS_ON", spdx_id(&c.purl)));
+    }
+    serde_json::json!({
+        "spdxVersion": "SPDX-2.3",
+        "dataLicense": "CC0-1.0",
+        "SPDXID": "SPDXRef-DOCUMENT",
+        "name": project,
+        "documentNamespace": format!("https://spdx.org/spdxdocs/{}-{}", project, crate::dev_kafka::uuid_v4()),
+        "creationInfo": {
+            "created": timestamp(),
+            "creators": [format!("Tool: dx-{}", env!("CARGO_PKG_VERSION"))],
+        },
+        "packages": packages,
+        "relationships": relationships,
+    })
+}
+
+/// `dx dev-dependencies sbom`: print the software bill of materials of the project, with every
+/// pinned dependency (as in `licenses`), its purl, the hashes of the lockfiles, the license found
+/// on disk and the dependency graph. Returns the exit code.
+pub fn cmd_sbom(dir: Option<PathBuf>, format: SbomFormat) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let graph = crate::dependency_graph::build(&project_dir).unwrap_or_default();
+    // The main module or package names the project; the directory otherwise
+    let project = graph
+        .roots
+        .first()
+        .map(|r| graph.nodes[r].name.clone())
+        .filter(|name| !name.starts_with('('))
+        .or_else(|| fs::canonicalize(&project_dir).ok()?.file_name().map(|n| n.to_string_lossy().to_string()))
+        .unwrap_or_else(|| "project".to_string());
+    let packages = crate::dependency_licenses::packages(&project_dir);
+    if packages.is_empty() {
+        eprintln!(
+            "Aviso: nenhuma dependência com versão exata em {} (go.mod, package-lock.json, requirements*.txt com ==, Cargo.lock, virtualenv); o SBOM sai vazio.",
+            project_dir.display()
+        );
+    }
+    let resolved = crate::dependency_licenses::resolve(&project_dir, &packages);
+    let mut hashes = lockfile_hashes(&project_dir);
+    let components: Vec<Component> = resolved
+        .iter()
+        .map(|r| {
+            let p = &r.package;
+            let hashes = hashes.remove(&(p.ecosystem, p.name.clone(), p.version.clone())).unwrap_or_default();
+            Component { resolved: r, purl: purl(p), hashes: hashes.into_iter().collect() }
+        })
+        .collect();
+    let root = format!("pkg:generic/{}", project);
+    let deps = dependencies(&graph, &components, &root);
+
+    let (doc, name) = match format {
+        SbomFormat::Cyclonedx => (render_cyclonedx(&project, &components, &deps, &root), "CycloneDX 1.5"),
+        SbomFormat::Spdx => (render_spdx(&project, &components, &deps, &root), "SPDX 2.3"),
+    };
+    println!("{}", serde_json::to_string_pretty(&doc).unwrap_or_default());
+    let hashed = components.iter().filter(|c| !c.hashes.is_empty()).count();
+    let licensed = components.iter().filter(|c| c.resolved.license.is_some()).count();
+    eprintln!("SBOM {}: {} componente(s), {} com hash, {} com licença.", name, components.len(), hashed, licensed);
+    0
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Detection results kept between runs, in the cache directory: one file per project and kind of
+//! result, reused while the manifests and lockfiles of the project hash the same. Re-running
+//! `dx dev-dependencies` or `dx analyzer` over many projects then skips the unchanged ones.
+
+use serde::de::DeserializeOwned;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+use std::time::{SystemTime, UNIX_EPOCH};
+
+/// Subdirectory of the cache directory holding the entries.
+const DIR: &str = "detection";
+
+/// Files whose contents key the entries: what a detection reads to know the dependencies.
+const KEY_FILES: &[&str] = &[
+    "package.json",
+    "package-lock.json",
+    "yarn.lock",
+    "pnpm-lock.yaml",
+    "Cargo.toml",
+    "Cargo.lock",
+    "requirements.txt",
+    "requirements-dev.txt",
+    "pyproject.toml",
+    "poetry.lock",
+    "setup.py",
+    "go.mod",
+    "go.sum",
+    "pom.xml",
+    "build.gradle",
+    "build.gradle.kts",
+    "settings.gradle",
+    "settings.gradle.kts",
+    "gradle.properties",
+    "composer.json",
+    "composer.lock",
+    "Gemfile",
+    "Gemfile.lock",
+    "global.json",
+    "Directory.Packages.props",
+    "Directory.Build.props",
+    "packages.lock.json",
+    "mix.exs",
+    "mix.lock",
+    "rebar.config",
+    "rebar.lock",
+];
+
+#[derive(Serialize, Deserialize)]
+struct Entry {
+    /// Hash of the key files when the value was detected
+    hash: String,
+    /// Unix seconds of the detection
+    at: u64,
+    value: serde_json::Value,
+}
+
+fn now() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).unwrap_or_default().as_secs()
+}
+
+fn hex(digest: impl AsRef<[u8]>) -> String {
+    digest.as_ref().iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+fn dir() -> PathBuf {
+    crate::paths::cache_dir().join(DIR)
+}
+
+/// Hash of the key files of `project` (names and contents), of the dx version and of the detection
+/// rules in use, whose detections may differ; None when the project has none of them.
+fn fingerprint(project: &Path) -> Option<String> {
+    let mut hasher = Sha256::new();
+    hasher.update(env!("CARGO_PKG_VERSION"));
+    hasher.update(&crate::rules::active(project).sha256);
+    let mut found = false;
+    for name in KEY_FILES {
+        let Ok(content) = fs::read(project.join(name)) else { continue };
+        found = true;
+        hasher.update([0]);
+        hasher.update(name);
+        hasher.update([0]);
+        hasher.update(&content);
+    }
+    // Modules of a multi-module Java build and its version catalog
+    for path in crate::java_build::manifests(project) {
+        let Ok(content) = fs::read(&path) else { continue };
+        hasher.update([0]);
+        hasher.update(path.strip_prefix(project).unwrap_or(&path).to_string_lossy().as_bytes());
+        hasher.update([0]);
+        hasher.update(&content);
+    }
+    // Members of a Cargo workspace, or the workspace root of a member
+    for path in crate::cargo_build::manifests(project) {
+        let Ok(content) = fs::read(&path) else { continue };
+        hasher.update([0]);
+        hasher.update(path.strip_prefix(project).unwrap_or(&path).to_string_lossy().as_bytes());
+        hasher.update([0]);
+        hasher.update(&content);
+    }
+    // Project files of a .NET build, with the props and lock files next to them
+    for path in crate::dotnet_build::manifests(project) {
+        let Ok(content) = fs::read(&path) else { continue };
+        found = true;
+        hasher.update([0]);
+        hasher.update(path.strip_prefix(project).unwrap_or(&path).to_string_lossy().as_bytes());
+        hasher.update([0]);
+        hasher.update(&content);
+    }
+    found.then(|| hex(hasher.finalize()))
+}
+
+/// File of the `kind` entry of `project`, named after its absolute path.
+fn entry_path(kind: &str, project: &Path) -> PathBuf {
+    let absolute = fs::canonicalize(project).unwrap_or_else(|_| project.to_path_buf());
+    let id = hex(Sha256::digest(absolute.to_string_lossy().as_bytes()));
+    dir().join(format!("{}-{}.json", kind, &id[..16]))
+}
+
+/// The `kind` result for `project`: from the cache while its key files are unchanged (and, with
+/// `max_age`, the entry is younger than that many seconds), else from `detect`, then stored unless
+/// `detect` tells it came out incomplete (a registry could not be reached, say), so the next run
+/// tries again. The `detection_cache` setting (`--no-cache`) turns the cache off; `max_age` 0 too.
+pub fn cached_if<T: Serialize + DeserializeOwned>(
+    kind: &str,
+    project: &Path,
+    max_age: Option<u64>,
+    detect: impl FnOnce() -> (T, bool),
+) -> T {
+    if !crate::settings::get_bool("detection_cache") || max_age == Some(0) {
+        return detect().0;
+    }
+    let Some(hash) = fingerprint(project) else { return detect().0 };
+    let path = entry_path(kind, project);
+    let fresh = |e: &Entry| e.hash == hash && max_age.is_none_or(|age| now().saturating_sub(e.at) < age);
+    if let Some(value) = fs::read_to_string(&path)
+        .ok()
+        .and_then(|c| serde_json::from_str::<Entry>(&c).ok())
+        .filter(fresh)
+        .and_then(|e| serde_json::from_value(e.value).ok())
+    {
+        return value;
+    }
+
+    let (value, complete) = detect();
+    if !complete {
+        return value;
+    }
+    if let Ok(json) = serde_json::to_value(&value) {
+        let entry = Entry { hash, at: now(), value: json };
+        // A cache that cannot be written only costs the next run a new detection
+        if let Ok(content) = serde_json::to_string(&entry) {
+            let _ = crate::lock::write_atomic(&path, content);
+        }
+    }
+    value
+}
+
+/// Remove every entry; returns how many there were.
+pub fn clear() -> io::Result<usize> {
+    let entries = match fs::read_dir(dir()) {
+        Ok(entries) => entries,
+        Err(e) if e.kind() == io::ErrorKind::NotFound => return Ok(0),
+        Err(e) => return Err(e),
+    };
+    let mut removed = 0;
+    for entry in entries.flatten() {
+        if entry.path().extension().is_some_and(|e| e == "json") {
+            fs::remove_file(entry.path())?;
+            removed += 1;
+        }
+    }
+    Ok(removed)
+}
+
+#[derive(Serialize)]
+struct Cleared {
+    detection: usize,
+    registry: bool,
+}
+
+/// `dx cache clear`: drop the detection cache and the cached registry responses, so the next run
+/// detects every project and asks the registries again.
+pub fn cmd_clear() {
+    let detection = clear().unwrap_or_else(|e| {
+        eprintln!("Erro ao limpar {}: {}", dir().display(), e);
+        crate::exit(1);
+    });
+    let registry = crate::registry::clear_cache().unwrap_or_else(|e| {
+        eprintln!("Erro ao limpar as respostas dos registries: {}", e);
+        crate::exit(1);
+    });
+    if crate::output::json() {
+        crate::output::print(&Cleared { detection, registry });
+        return;
+    }
+    println!("Cache de detecção: {} entrada(s) removida(s) de {}", detection, dir().display());
+    if registry {
+        println!("Respostas dos registries removidas.");
+    }
+}
@@ -3,63 +3,238 @@
 use std::fs;
 use std::path::{Path, PathBuf};
 
+use crate::dev_config::Stack;
 use crate::dev_services;
 
 const START_MARKER: &str = "<!-- dx-cli:badges:start -->";
 const END_MARKER: &str = "<!-- dx-cli:badges:end -->";
 
-/// Generate a Markdown line with badges for the given services
-pub fn generate_badges_markdown(services: &[String]) -> String {
-    use std::collections::HashSet;
+/// Directories never searched for monorepo packages.
+const SKIP_DIRS: &[&str] = &["node_modules", "vendor", "target", "dist", "build", "venv", "__pycache__", "testdata"];
+/// How deep below the root packages are looked for (`apps/web`, `services/billing/api`).
+const MAX_DEPTH: usize = 4;
 
-    // Build the same badges as the Analyzer report
-    let mut badges: HashSet<&str> = HashSet::new();
+/// Coverage reports looked up in a package, relative to it.
+const COVERAGE_FILES: &[&str] = &[
+    "coverage/lcov.info",
+    "lcov.info",
+    "coverage.out",
+    "cover.out",
+    "coverage.xml",
+    "target/site/jacoco/jacoco.xml",
+    "build/reports/jacoco/test/jacocoTestReport.xml",
+];
 
-    for s in services {
-        let kl = s.to_lowercase();
-        match kl.as_str() {
-            // Databases
-            "postgres" | "postgresql" => {
-                badges.insert("[![PostgreSQL](https://img.shields.io/badge/PostgreSQL-Dev_Service-blue?logo=postgresql)](#)");
-            }
-            "mysql" | "mariadb" => {
-                badges.insert(
-                    "[![MySQL](https://img.shields.io/badge/MySQL-Dev_Service-blue?logo=mysql)](#)",
-                );
-            }
-            "mongodb" => {
-                badges.insert("[![MongoDB](https://img.shields.io/badge/MongoDB-Dev_Service-green?logo=mongodb)](#)");
-            }
-            // Cache
-            "redis" => {
-                badges.insert(
-                    "[![Redis](https://img.shields.io/badge/Redis-Dev_Service-red?logo=redis)](#)",
-                );
-            }
-            // Streaming (Kafka API)
-            "kafka" => {
-                badges.insert("[![Kafka](https://img.shields.io/badge/Kafka-Dev_Service-black?logo=apachekafka)](#)");
-            }
-            // Data processing (Flink detected by jobmanager/taskmanager too)
-            "flink" | "jobmanager" | "taskmanager" => {
-                badges.insert("[![Apache Flink](https://img.shields.io/badge/Flink-Dev_Service-orange?logo=apacheflink)](#)");
-            }
-            // Skip tools like kafka-ui
-            "kafka-ui" => {}
-            _ => {}
+/// Badges of the infrastructure kinds reported by `dx dev-infra detect`. The ones `dx dev-services`
+/// provisions say so; the others only show that the project talks to them.
+const INFRA_BADGES: &[(&str, &str)] = &[
+    ("PostgreSQL", "[![PostgreSQL](https://img.shields.io/badge/PostgreSQL-Dev_Service-blue?logo=postgresql)](#)"),
+    ("MySQL/MariaDB", "[![MySQL](https://img.shields.io/badge/MySQL-Dev_Service-blue?logo=mysql)](#)"),
+    ("MongoDB", "[![MongoDB](https://img.shields.io/badge/MongoDB-Dev_Service-green?logo=mongodb)](#)"),
+    ("Redis", "[![Redis](https://img.shields.io/badge/Redis-Dev_Service-red?logo=redis)](#)"),
+    ("Kafka", "[![Kafka](https://img.shields.io/badge/Kafka-Dev_Service-black?logo=apachekafka)](#)"),
+    ("Flink", "[![Apache Flink](https://img.shields.io/badge/Flink-Dev_Service-orange?logo=apacheflink)](#)"),
+    ("RabbitMQ", "[![RabbitMQ](https://img.shields.io/badge/RabbitMQ-Dev_Service-FF6600?logo=rabbitmq)](#)"),
+    ("NATS", "[![NATS](https://img.shields.io/badge/NATS-Infra-27AAE1?logo=natsdotio)](#)"),
+    ("Elasticsearch", "[![Elasticsearch](https://img.shields.io/badge/Elasticsearch-Infra-005571?logo=elasticsearch)](#)"),
+    ("Memcached", "[![Memcached](https://img.shields.io/badge/Memcached-Infra-lightgrey)](#)"),
+];
+
+const DX_BADGE: &str = "[![dx-anywhere](https://img.shields.io/badge/DX--Anywhere-CLI-1ED6FF?logo=https://raw.githubusercontent.com/dx-anywhere/dx-cli/HEAD/images/dx-logo.svg)](#)";
+
+/// Infrastructure kind (as named by `dx dev-infra`) of a Dev Services name.
+fn service_kind(service: &str) -> Option<&'static str> {
+    match service.to_lowercase().as_str() {
+        "postgres" | "postgresql" => Some("PostgreSQL"),
+        "mysql" | "mariadb" => Some("MySQL/MariaDB"),
+        "mongodb" => Some("MongoDB"),
+        "redis" => Some("Redis"),
+        "kafka" => Some("Kafka"),
+        "rabbitmq" => Some("RabbitMQ"),
+        // Flink is detected by jobmanager/taskmanager too
+        "flink" | "jobmanager" | "taskmanager" => Some("Flink"),
+        // Tools like kafka-ui have no badge
+        _ => None,
+    }
+}
+
+/// Infrastructure the project talks to. Go, Elixir and Erlang projects use the `dx dev-infra`
+/// detection (clients used in the code); the other stacks the dependencies found by `dx dev-services`.
+pub(crate) fn detect_infra(project_dir: &Path) -> Vec<String> {
+    let mut kinds: Vec<String> = if crate::dev_infra::supported(project_dir) {
+        crate::dev_infra::detect(project_dir).into_iter().filter(|i| !i.files.is_empty()).map(|i| i.kind).collect()
+    } else {
+        let config = dev_services::detect_dependencies(project_dir);
+        config.services.keys().filter_map(|s| service_kind(s)).map(str::to_string).collect()
+    };
+    kinds.sort();
+    kinds.dedup();
+    kinds
+}
+
+/// Badges of the given infrastructure kinds, sorted.
+fn infra_badges(kinds: &[String]) -> Vec<&'static str> {
+    let mut badges: Vec<&str> = INFRA_BADGES.iter().filter(|(kind, _)| kinds.iter().any(|k| k == kind)).map(|(_, badge)| *badge).collect();
+    badges.sort();
+    badges
+}
+
+/// Badges of the web frameworks declared in the package's manifest.
+fn framework_badges(project_dir: &Path, stack: Stack) -> Vec<String> {
+    let read = |file: &str| fs::read_to_string(project_dir.join(file)).unwrap_or_default();
+    // Declared dependency names (Go, Node) or the manifest text searched for the dependency
+    let (names, text): (Vec<String>, String) = match stack {
+        Stack::Go => (crate::dev_infra::parse_go_mod(&read("go.mod")).into_iter().map(|(m, _)| m).collect(), String::new()),
+        Stack::Node => {
+            let manifest: serde_json::Value = serde_json::from_str(&read("package.json")).unwrap_or_default();
+            (manifest["dependencies"].as_object().map(|deps| deps.keys().cloned().collect()).unwrap_or_default(), String::new())
         }
+        Stack::JavaMaven => (Vec::new(), read("pom.xml")),
+        Stack::JavaGradle => (Vec::new(), read("build.gradle") + &read("build.gradle.kts")),
+        Stack::Python => (Vec::new(), (read("requirements.txt") + &read("pyproject.toml")).to_lowercase()),
+        Stack::DotNet => (crate::dotnet_build::dependencies(project_dir).into_iter().map(|p| p.name).collect(), String::new()),
+        Stack::Rust | Stack::Unknown => return Vec::new(),
+    };
+    let declared = |dep: &str| names.iter().any(|n| n == dep || n.starts_with(&format!("{}/", dep))) || text.contains(dep);
+    // Framework signatures come from the detection rules in use (dx rules)
+    let rules = crate::rules::active(project_dir);
+    rules.pack.frameworks.iter().filter(|f| f.applies_to(stack) && declared(&f.dependency)).map(|f| f.badge.clone()).collect()
+}
+
+/// Docker badge when the package has a Dockerfile or a Compose file.
+fn docker_badge(project_dir: &Path) -> Option<&'static str> {
+    let dockerfile = project_dir.join("Dockerfile").exists();
+    let compose = dev_services::find_project_compose_file(project_dir).is_some();
+    match (dockerfile, compose) {
+        (true, true) => Some("[![Docker](https://img.shields.io/badge/Docker-Dockerfile_%2B_Compose-2496ED?logo=docker)](#)"),
+        (true, false) => Some("[![Docker](https://img.shields.io/badge/Docker-Dockerfile-2496ED?logo=docker)](#)"),
+        (false, true) => Some("[![Docker](https://img.shields.io/badge/Docker-Compose-2496ED?logo=docker)](#)"),
+        (false, false) => None,
     }
+}
 
-    let mut badge_lines: Vec<&str> = badges.into_iter().collect();
-    badge_lines.sort();
+/// Badge of the package's stack, detected from its manifest.
+fn stack_badge(stack: Stack) -> Option<&'static str> {
+    let badge = match stack {
+        Stack::Rust => "[![Rust](https://img.shields.io/badge/Stack-Rust-orange?logo=rust)](#)",
+        Stack::Node => "[![Node.js](https://img.shields.io/badge/Stack-Node.js-339933?logo=nodedotjs)](#)",
+        Stack::Python => "[![Python](https://img.shields.io/badge/Stack-Python-3776AB?logo=python)](#)",
+        Stack::Go => "[![Go](https://img.shields.io/badge/Stack-Go-00ADD8?logo=go)](#)",
+        Stack::JavaMaven => "[![Java (Maven)](https://img.shields.io/badge/Stack-Java_(Maven)-C71A36?logo=apachemaven)](#)",
+        Stack::JavaGradle => "[![Java (Gradle)](https://img.shields.io/badge/Stack-Java_(Gradle)-02303A?logo=gradle)](#)",
+        Stack::DotNet => "[![.NET](https://img.shields.io/badge/Stack-.NET-512BD4?logo=dotnet)](#)",
+        Stack::Unknown => return None,
+    };
+    Some(badge)
+}
 
-    // Always append the dx-anywhere badge at the end (using repo logo)
-    let dx_anywhere_badge = "[![dx-anywhere](https://img.shields.io/badge/DX--Anywhere-CLI-1ED6FF?logo=https://raw.githubusercontent.com/dx-anywhere/dx-cli/HEAD/images/dx-logo.svg)](#)";
-    if badge_lines.is_empty() {
-        dx_anywhere_badge.to_string()
-    } else {
-        format!("{} {}", badge_lines.join(" "), dx_anywhere_badge)
+/// Line coverage (percent) of the first coverage report found in the package: lcov, Go
+/// cover profile, Cobertura or JaCoCo.
+pub fn coverage_percent(project_dir: &Path) -> Option<f64> {
+    COVERAGE_FILES.iter().find_map(|file| {
+        let content = fs::read_to_string(project_dir.join(file)).ok()?;
+        if file.ends_with(".info") {
+            lcov_coverage(&content)
+        } else if file.ends_with(".out") {
+            go_coverage(&content)
+        } else if content.contains("<report") {
+            jacoco_coverage(&content)
+        } else {
+            cobertura_coverage(&content)
+        }
+    })
+}
+
+/// `LH`/`LF` totals of an lcov tracefile.
+fn lcov_coverage(content: &str) -> Option<f64> {
+    let (mut hit, mut found) = (0u64, 0u64);
+    for line in content.lines() {
+        if let Some(n) = line.strip_prefix("LH:") {
+            hit += n.trim().parse::<u64>().unwrap_or(0);
+        } else if let Some(n) = line.strip_prefix("LF:") {
+            found += n.trim().parse::<u64>().unwrap_or(0);
+        }
     }
+    (found > 0).then(|| hit as f64 * 100.0 / found as f64)
+}
+
+/// Covered statements of a `go test -coverprofile` file (`file:1.2,3.4 <statements> <count>`).
+fn go_coverage(content: &str) -> Option<f64> {
+    let mut blocks: std::collections::BTreeMap<&str, (u64, bool)> = std::collections::BTreeMap::new();
+    for line in content.lines().filter(|l| !l.starts_with("mode:")) {
+        let mut parts = line.rsplitn(3, ' ');
+        let (Some(count), Some(statements), Some(block)) = (parts.next(), parts.next(), parts.next()) else { continue };
+        let (Ok(count), Ok(statements)) = (count.parse::<u64>(), statements.parse::<u64>()) else { continue };
+        // A block appears once per test binary with -coverpkg; covered if any run hit it
+        let entry = blocks.entry(block).or_insert((statements, false));
+        entry.1 |= count > 0;
+    }
+    let total: u64 = blocks.values().map(|(n, _)| n).sum();
+    let covered: u64 = blocks.values().filter(|(_, hit)| *hit).map(|(n, _)| n).sum();
+    (total > 0).then(|| covered as f64 * 100.0 / total as f64)
+}
+
+/// Value of `name="..."` in an XML tag.
+fn xml_attr<'a>(tag: &'a str, name: &str) -> Option<&'a str> {
+    let start = tag.find(&format!(" {}=\"", name))? + name.len() + 3;
+    let len = tag[start..].find('"')?;
+    Some(&tag[start..start + len])
+}
+
+/// `line-rate` of the root `<coverage>` element of a Cobertura report.
+fn cobertura_coverage(content: &str) -> Option<f64> {
+    let tag = &content[content.find("<coverage ")?..];
+    let rate: f64 = xml_attr(&tag[..tag.find('>')?], "line-rate")?.parse().ok()?;
+    Some(rate * 100.0)
+}
+
+/// Report-level LINE counter of a JaCoCo report (the last one in the file).
+fn jacoco_coverage(content: &str) -> Option<f64> {
+    let tag = &content[content.rfind("<counter type=\"LINE\"")?..];
+    let tag = &tag[..tag.find('>')?];
+    let missed: f64 = xml_attr(tag, "missed")?.parse().ok()?;
+    let covered: f64 = xml_attr(tag, "covered")?.parse().ok()?;
+    (missed + covered > 0.0).then(|| covered * 100.0 / (missed + covered))
+}
+
+fn coverage_badge(percent: f64) -> String {
+    let color = match percent {
+        p if p >= 80.0 => "brightgreen",
+        p if p >= 60.0 => "yellow",
+        _ => "red",
+    };
+    format!("[![Coverage](https://img.shields.io/badge/Coverage-{:.0}%25-{})](#)", percent, color)
+}
+
+/// Badges of one project: stack, frameworks, coverage (when a report exists), infrastructure,
+/// Docker, dx itself and the ones the team profile requires.
+pub fn badges_for(project_dir: &Path) -> String {
+    let stack = Stack::detect(project_dir);
+
+    let mut parts: Vec<String> = Vec::new();
+    if let Some(badge) = stack_badge(stack) {
+        parts.push(badge.to_string());
+    }
+    parts.extend(framework_badges(project_dir, stack));
+    if let Some(percent) = coverage_percent(project_dir) {
+        parts.push(coverage_badge(percent));
+    }
+    parts.extend(infra_badges(&detect_infra(project_dir)).into_iter().map(str::to_string));
+    parts.extend(docker_badge(project_dir).map(str::to_string));
+    parts.push(DX_BADGE.to_string());
+    for badge in crate::team_profile::effective(project_dir).badges {
+        if !parts.contains(&badge) {
+            parts.push(badge);
+        }
+    }
+    parts.join(" ")
+}
+
+/// Badges line currently between the markers of a README, if any.
+fn current_badges(readme: &str) -> Option<&str> {
+    let start = readme.find(START_MARKER)? + START_MARKER.len();
+    let end = readme[start..].find(END_MARKER)? + start;
+    Some(readme[start..end].trim())
 }
 
 /// Upsert badges block in README.md within markers.
@@ -108,13 +283,13 @@ pub fn upsert_badges_in_readme(project_dir: &Path, badges_line: &str) -> std::io
                 content = format!("{}\n\n{}\n{}", content, replacement_block, "");
             }
         }
-        fs::write(&readme_path, content)?;
+        crate::audit::write(&readme_path, content)?;
     } else {
         // Create a minimal README with badges
         let mut content = String::new();
         content.push_str("# Projeto\n\n");
         content.push_str(&replacement_block);
-        fs::write(&readme_path, content)?;
+        crate::audit::write(&readme_path, content)?;
     }
 
     Ok(readme_path)
@@ -122,11 +297,7 @@ pub fn upsert_badges_in_readme(project_dir: &Path, badges_line: &str) -> std::io
 
 /// Process one directory: detect services and apply badges (print or save)
 pub fn process_directory(save_file: bool, project_dir: &Path) {
-    let config = dev_services::detect_dependencies(project_dir);
-    let mut services: Vec<String> = config.services.keys().cloned().collect();
-    services.sort();
-
-    let badges = generate_badges_markdown(&services);
+    let badges = badges_for(project_dir);
 
     println!(
         "Badges detectados para {}:\n{}\n",
@@ -184,7 +355,7 @@ pub fn remove_badges_in_readme(project_dir: &Path) -> std::io::Result<(PathBuf,
     // Collapse 3+ newlines to at most 2 for cleanliness
     let cleaned = collapse_blank_lines(&new_content);
 
-    fs::write(&readme_path, cleaned)?;
+    crate::audit::write(&readme_path, cleaned)?;
     println!("Badges removidos de {}", readme_path.display());
     Ok((readme_path, true))
 }
@@ -216,3 +387,84 @@ pub fn process_clean_directory(project_dir: &Path) {
         Err(e) => eprintln!("Erro ao limpar badges em {}: {}", project_dir.display(), e),
     }
 }
+
+/// Packages of a monorepo below `root`: directories with a manifest dx recognizes (go.mod,
+/// package.json, Cargo.toml, pom.xml...), skipping hidden and dependency/build directories.
+pub fn find_packages(root: &Path) -> Vec<PathBuf> {
+    fn walk(dir: &Path, depth: usize, out: &mut Vec<PathBuf>) {
+        let Ok(entries) = fs::read_dir(dir) else { return };
+        let mut dirs: Vec<PathBuf> = entries
+            .flatten()
+            .filter(|e| e.file_type().map(|t| t.is_dir()).unwrap_or(false))
+            .filter(|e| {
+                let name = e.file_name().to_string_lossy().into_owned();
+                !name.starts_with('.') && !SKIP_DIRS.contains(&name.as_str())
+            })
+            .map(|e| e.path())
+            .collect();
+        dirs.sort();
+        for d in dirs {
+            if Stack::detect(&d) != Stack::Unknown {
+                out.push(d.clone());
+            }
+            if depth < MAX_DEPTH {
+                walk(&d, depth + 1, out);
+            }
+        }
+    }
+    let mut out = Vec::new();
+    walk(root, 1, &mut out);
+    out
+}
+
+/// `dx dev-badges --recursive`: sync the badges of the root README and of every package README
+/// of a monorepo, each with its own stack, coverage and services. Packages without a README are
+/// skipped rather than given one.
+pub fn process_monorepo(save_file: bool, root: &Path) {
+    let packages = find_packages(root);
+    println!("Sincronizando badges de {} e de {} pacote(s):", root.display(), packages.len());
+    let (mut updated, mut unchanged, mut skipped) = (0, 0, 0);
+    for dir in std::iter::once(root.to_path_buf()).chain(packages) {
+        let shown = match dir.strip_prefix(root) {
+            Ok(rel) if rel.as_os_str().is_empty() => ".".to_string(),
+            Ok(rel) => rel.display().to_string(),
+            Err(_) => dir.display().to_string(),
+        };
+        let badges = badges_for(&dir);
+        let readme_path = dir.join("README.md");
+        let readme = fs::read_to_string(&readme_path).ok();
+        if readme.is_none() && dir != root {
+            println!("  - {}: sem README.md, ignorado", shown);
+            skipped += 1;
+            continue;
+        }
+        if readme.as_deref().and_then(current_badges) == Some(badges.as_str()) {
+            println!("  = {}: em dia", shown);
+            unchanged += 1;
+            continue;
+        }
+        if !save_file {
+            println!("  ~ {}: {}", shown, badges);
+            updated += 1;
+            continue;
+        }
+        match upsert_badges_in_readme(&dir, &badges) {
+            Ok(path) => {
+                println!("  ✓ {}: {} atualizado", shown, path.strip_prefix(root).unwrap_or(&path).display());
+                updated += 1;
+            }
+            Err(e) => eprintln!("  ✗ {}: erro ao atualizar o README: {}", shown, e),
+        }
+    }
+    let verb = if save_file { "atualizado(s)" } else { "a atualizar (--no-save)" };
+    println!("\n{} README(s) {}, {} em dia, {} pacote(s) sem README.", updated, verb, unchanged, skipped);
+}
+
+/// `dx dev-badges clean --recursive`: remove the badge blocks of the root and package READMEs.
+pub fn process_clean_monorepo(root: &Path) {
+    for dir in std::iter::once(root.to_path_buf()).chain(find_packages(root)) {
+        if dir.join("README.md").exists() {
+            process_clean_directory(&dir);
+        }
+    }
+}
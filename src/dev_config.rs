@@ -10,18 +10,19 @@ use std::{
 };
 
 #[derive(Debug, Clone, Copy, PartialEq, Eq)]
-enum Stack {
+pub(crate) enum Stack {
     Rust,
     Node,
     Python,
     Go,
     JavaMaven,
     JavaGradle,
+    DotNet,
     Unknown,
 }
 
 impl Stack {
-    fn detect(dir: &Path) -> Self {
+    pub(crate) fn detect(dir: &Path) -> Self {
         if dir.join("Cargo.toml").exists() {
             Stack::Rust
         } else if dir.join("package.json").exists() {
@@ -34,10 +35,26 @@ impl Stack {
             Stack::JavaMaven
         } else if dir.join("build.gradle").exists() || dir.join("build.gradle.kts").exists() {
             Stack::JavaGradle
+        } else if crate::dotnet_build::is_project(dir) {
+            Stack::DotNet
         } else {
             Stack::Unknown
         }
     }
+
+    /// Short name used by the detection rules and the archetypes (`go`, `java-maven`, ...).
+    pub(crate) fn key(self) -> Option<&'static str> {
+        match self {
+            Stack::Go => Some("go"),
+            Stack::Node => Some("node"),
+            Stack::Python => Some("python"),
+            Stack::JavaMaven => Some("java-maven"),
+            Stack::JavaGradle => Some("java-gradle"),
+            Stack::Rust => Some("rust"),
+            Stack::DotNet => Some("dotnet"),
+            Stack::Unknown => None,
+        }
+    }
 }
 
 impl fmt::Display for Stack {
@@ -49,6 +66,7 @@ impl fmt::Display for Stack {
             Stack::Go => "Go",
             Stack::JavaMaven => "Java (Maven)",
             Stack::JavaGradle => "Java (Gradle)",
+            Stack::DotNet => ".NET",
             Stack::Unknown => "Desconhecida",
         };
         write!(f, "{name}")
@@ -72,7 +90,7 @@ impl Config {
             fs::create_dir_all(parent)?;
         }
         let data = serde_json::to_string_pretty(self).unwrap();
-        fs::write(path, data)
+        crate::audit::write(path, data)
     }
 }
 
@@ -80,6 +98,11 @@ fn config_path(project_dir: &Path) -> PathBuf {
     project_dir.join(".dx").join("config.json")
 }
 
+/// Saved configuration of the project (`.dx/config.json`).
+pub fn values(project_dir: &Path) -> BTreeMap<String, String> {
+    Config::load(&config_path(project_dir)).0
+}
+
 fn project_dir(dir: Option<PathBuf>) -> PathBuf {
     dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")))
 }
@@ -106,6 +129,7 @@ pub fn add(dir: Option<PathBuf>, key: String, value: String) {
     println!("Stack detectada: {}", stack);
 
     let path = config_path(&project_dir);
+    let _lock = crate::lock::for_file_or_exit(&path);
     let mut cfg = Config::load(&path);
     if cfg.0.contains_key(&key) {
         println!("Configuração '{key}' já existe.");
@@ -125,6 +149,7 @@ pub fn update(dir: Option<PathBuf>, key: String, value: String) {
     println!("Stack detectada: {}", stack);
 
     let path = config_path(&project_dir);
+    let _lock = crate::lock::for_file_or_exit(&path);
     let mut cfg = Config::load(&path);
     if !cfg.0.contains_key(&key) {
         println!("Configuração '{key}' não existe.");
@@ -144,6 +169,7 @@ pub fn delete(dir: Option<PathBuf>, key: String) {
     println!("Stack detectada: {}", stack);
 
     let path = config_path(&project_dir);
+    let _lock = crate::lock::for_file_or_exit(&path);
     let mut cfg = Config::load(&path);
     if cfg.0.remove(&key).is_some() {
         if let Err(e) = cfg.save(&path) {
@@ -0,0 +1,1061 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde_json::Value;
+use sha2::{Digest, Sha256};
+use std::collections::BTreeMap;
+use std::io::{self, Read, Write};
+use std::net::{TcpStream, ToSocketAddrs};
+use std::path::{Path, PathBuf};
+use std::time::Duration;
+
+/// Database whose shell `dx dev-db shell` opens.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum DbService {
+    Mongodb,
+    Postgres,
+    Mysql,
+    Redis,
+}
+
+/// Connection string used when neither the environment nor the code names one.
+const DEFAULT_URI: &str = "mongodb://localhost:27017";
+const TIMEOUT: Duration = Duration::from_secs(10);
+/// Directory, relative to the project root, holding one fixture file per collection.
+const SEEDS_DIR: &str = "seeds";
+
+/// OP_MSG, the only opcode of MongoDB's wire protocol since 3.6.
+const OP_MSG: i32 = 2013;
+/// Documents sent per `insert` command; keeps each message well under the 48 MB limit.
+const INSERT_BATCH: usize = 1000;
+/// MongoDB error codes dx tolerates: dropping a missing collection and re-inserting an `_id`.
+const NAMESPACE_NOT_FOUND: i64 = 26;
+const DUPLICATE_KEY: i64 = 11000;
+
+/// Variables holding the connection string and the database name, in order of preference.
+const URI_VARS: &[&str] = &["MONGODB_URI", "MONGO_URI", "MONGODB_URL", "MONGO_URL"];
+const DATABASE_VARS: &[&str] = &["MONGODB_DATABASE", "MONGO_DATABASE", "MONGODB_DB", "MONGO_DB", "MONGO_INITDB_DATABASE"];
+const SQL_VARS: &[&str] = &["DATABASE_URL", "POSTGRES_URL", "POSTGRESQL_URL", "MYSQL_URL"];
+const REDIS_VARS: &[&str] = &["REDIS_URL", "REDIS_URI", "REDIS_ADDR"];
+
+/// A BSON value; only the types seeds and server replies use.
+#[derive(Debug, Clone, PartialEq)]
+enum Bson {
+    Double(f64),
+    String(String),
+    Document(Vec<(String, Bson)>),
+    Array(Vec<Bson>),
+    Binary(Vec<u8>),
+    ObjectId([u8; 12]),
+    Bool(bool),
+    DateTime(i64),
+    Null,
+    Int32(i32),
+    Int64(i64),
+}
+
+impl Bson {
+    /// Field `name` of a document.
+    fn get(&self, name: &str) -> Option<&Bson> {
+        match self {
+            Bson::Document(fields) => fields.iter().find(|(n, _)| n == name).map(|(_, v)| v),
+            _ => None,
+        }
+    }
+
+    fn as_i64(&self) -> Option<i64> {
+        match self {
+            Bson::Int32(n) => Some(*n as i64),
+            Bson::Int64(n) => Some(*n),
+            Bson::Double(n) => Some(*n as i64),
+            _ => None,
+        }
+    }
+
+    fn as_str(&self) -> Option<&str> {
+        match self {
+            Bson::String(s) => Some(s),
+            _ => None,
+        }
+    }
+
+    fn encode(&self, out: &mut Vec<u8>) {
+        match self {
+            Bson::Double(n) => out.extend_from_slice(&n.to_le_bytes()),
+            Bson::String(s) => {
+                out.extend_from_slice(&(s.len() as i32 + 1).to_le_bytes());
+                out.extend_from_slice(s.as_bytes());
+                out.push(0);
+            }
+            Bson::Document(fields) => encode_document(fields.iter().map(|(n, v)| (n.as_str(), v)), out),
+            Bson::Array(items) => {
+                let names: Vec<String> = (0..items.len()).map(|i| i.to_string()).collect();
+                encode_document(names.iter().map(String::as_str).zip(items), out)
+            }
+            Bson::Binary(bytes) => {
+                out.extend_from_slice(&(bytes.len() as i32).to_le_bytes());
+                out.push(0); // generic subtype
+                out.extend_from_slice(bytes);
+            }
+            Bson::ObjectId(id) => out.extend_from_slice(id),
+            Bson::Bool(b) => out.push(*b as u8),
+            Bson::DateTime(ms) => out.extend_from_slice(&ms.to_le_bytes()),
+            Bson::Null => {}
+            Bson::Int32(n) => out.extend_from_slice(&n.to_le_bytes()),
+            Bson::Int64(n) => out.extend_from_slice(&n.to_le_bytes()),
+        }
+    }
+
+    fn type_byte(&self) -> u8 {
+        match self {
+            Bson::Double(_) => 0x01,
+            Bson::String(_) => 0x02,
+            Bson::Document(_) => 0x03,
+            Bson::Array(_) => 0x04,
+            Bson::Binary(_) => 0x05,
+            Bson::ObjectId(_) => 0x07,
+            Bson::Bool(_) => 0x08,
+            Bson::DateTime(_) => 0x09,
+            Bson::Null => 0x0a,
+            Bson::Int32(_) => 0x10,
+            Bson::Int64(_) => 0x12,
+        }
+    }
+}
+
+fn encode_document<'a>(fields: impl Iterator<Item = (&'a str, &'a Bson)>, out: &mut Vec<u8>) {
+    let start = out.len();
+    out.extend_from_slice(&[0; 4]);
+    for (name, value) in fields {
+        out.push(value.type_byte());
+        out.extend_from_slice(name.as_bytes());
+        out.push(0);
+        value.encode(out);
+    }
+    out.push(0);
+    let len = (out.len() - start) as i32;
+    out[start..start + 4].copy_from_slice(&len.to_le_bytes());
+}
+
+fn doc(fields: Vec<(&str, Bson)>) -> Bson {
+    Bson::Document(fields.into_iter().map(|(n, v)| (n.to_string(), v)).collect())
+}
+
+fn invalid(message: &str) -> io::Error {
+    io::Error::new(io::ErrorKind::InvalidData, message.to_string())
+}
+
+/// Reply decoder; every read fails with `InvalidData` past the end.
+struct Decoder<'a>(&'a [u8]);
+
+impl Decoder<'_> {
+    fn take(&mut self, n: usize) -> io::Result<&[u8]> {
+        if self.0.len() < n {
+            return Err(invalid("resposta truncada do MongoDB"));
+        }
+        let (head, tail) = self.0.split_at(n);
+        self.0 = tail;
+        Ok(head)
+    }
+
+    fn i32(&mut self) -> io::Result<i32> {
+        Ok(i32::from_le_bytes(self.take(4)?.try_into().unwrap()))
+    }
+
+    fn i64(&mut self) -> io::Result<i64> {
+        Ok(i64::from_le_bytes(self.take(8)?.try_into().unwrap()))
+    }
+
+    fn cstring(&mut self) -> io::Result<String> {
+        let end = self.0.iter().position(|b| *b == 0).ok_or_else(|| invalid("nome de campo sem terminador"))?;
+        let name = String::from_utf8_lossy(&self.0[..end]).into_owned();
+        self.0 = &self.0[end + 1..];
+        Ok(name)
+    }
+
+    fn document(&mut self) -> io::Result<Vec<(String, Bson)>> {
+        let len = self.i32()?;
+        let mut body = Decoder(self.take((len.max(5) - 4) as usize)?);
+        let mut fields = Vec::new();
+        loop {
+            let kind = body.take(1)?[0];
+            if kind == 0 {
+                return Ok(fields);
+            }
+            let name = body.cstring()?;
+            let value = match kind {
+                0x01 => Bson::Double(f64::from_le_bytes(body.take(8)?.try_into().unwrap())),
+                0x02 | 0x0d | 0x0e => {
+                    let len = body.i32()?.max(1) as usize;
+                    Bson::String(String::from_utf8_lossy(&body.take(len)?[..len - 1]).into_owned())
+                }
+                0x03 => Bson::Document(body.document()?),
+                0x04 => Bson::Array(body.document()?.into_iter().map(|(_, v)| v).collect()),
+                0x05 => {
+                    let len = body.i32()?.max(0) as usize;
+                    body.take(1)?;
+                    Bson::Binary(body.take(len)?.to_vec())
+                }
+                0x07 => Bson::ObjectId(body.take(12)?.try_into().unwrap()),
+                0x08 => Bson::Bool(body.take(1)?[0] != 0),
+                0x09 => Bson::DateTime(body.i64()?),
+                0x06 | 0x0a | 0x7f | 0xff => Bson::Null,
+                0x0b => {
+                    body.cstring()?;
+                    body.cstring()?;
+                    Bson::Null
+                }
+                0x10 => Bson::Int32(body.i32()?),
+                0x11 | 0x12 => Bson::Int64(body.i64()?),
+                0x13 => {
+                    body.take(16)?;
+                    Bson::Null
+                }
+                other => return Err(invalid(&format!("tipo BSON não suportado: 0x{:02x}", other))),
+            };
+            fields.push((name, value));
+        }
+    }
+}
+
+/// A seed document as BSON, reading MongoDB Extended JSON (`{"$oid": ...}`, `{"$date": ...}`,
+/// `{"$numberLong": ...}`) so exports from `mongoexport` and Compass load with their types.
+fn from_json(value: &Value) -> Result<Bson, String> {
+    Ok(match value {
+        Value::Null => Bson::Null,
+        Value::Bool(b) => Bson::Bool(*b),
+        Value::Number(n) => match (n.as_i64(), n.as_f64()) {
+            (Some(i), _) => i32::try_from(i).map(Bson::Int32).unwrap_or(Bson::Int64(i)),
+            (None, Some(f)) => Bson::Double(f),
+            _ => return Err(format!("número fora do intervalo: {}", n)),
+        },
+        Value::String(s) => Bson::String(s.clone()),
+        Value::Array(items) => Bson::Array(items.iter().map(from_json).collect::<Result<_, _>>()?),
+        Value::Object(map) => {
+            if map.len() == 1 {
+                let (key, inner) = map.iter().next().unwrap();
+                if let Some(typed) = extended_json(key, inner)? {
+                    return Ok(typed);
+                }
+            }
+            Bson::Document(map.iter().map(|(k, v)| Ok((k.clone(), from_json(v)?))).collect::<Result<_, String>>()?)
+        }
+    })
+}
+
+/// The typed value of a single-key Extended JSON wrapper, or None for ordinary documents.
+fn extended_json(key: &str, inner: &Value) -> Result<Option<Bson>, String> {
+    let text = || inner.as_str().ok_or_else(|| format!("{} espera uma string", key));
+    Ok(Some(match key {
+        "$oid" => {
+            let hex = text()?;
+            let bytes: Vec<u8> = (0..hex.len())
+                .step_by(2)
+                .filter_map(|i| hex.get(i..i + 2).and_then(|b| u8::from_str_radix(b, 16).ok()))
+                .collect();
+            Bson::ObjectId(bytes.try_into().map_err(|_| format!("ObjectId inválido: {}", hex))?)
+        }
+        "$date" => Bson::DateTime(match inner {
+            Value::Number(n) => n.as_i64().ok_or_else(|| format!("data inválida: {}", n))?,
+            Value::String(s) => parse_date(s).ok_or_else(|| format!("data inválida: {} (use ISO 8601, ex.: 2024-01-31T12:00:00Z)", s))?,
+            Value::Object(_) => match from_json(inner)? {
+                Bson::Int64(ms) => ms,
+                _ => return Err("$date espera {\"$numberLong\": \"...\"}".to_string()),
+            },
+            _ => return Err("$date espera uma string ISO 8601 ou milissegundos".to_string()),
+        }),
+        "$numberLong" => Bson::Int64(text()?.parse().map_err(|_| format!("$numberLong inválido: {}", inner))?),
+        "$numberInt" => Bson::Int32(text()?.parse().map_err(|_| format!("$numberInt inválido: {}", inner))?),
+        "$numberDouble" => Bson::Double(text()?.parse().map_err(|_| format!("$numberDouble inválido: {}", inner))?),
+        _ => return Ok(None),
+    }))
+}
+
+/// Milliseconds since the epoch of an ISO 8601 date or date-time (`2024-01-31`,
+/// `2024-01-31T12:00:00.250Z`, `2024-01-31T09:00:00-03:00`).
+fn parse_date(s: &str) -> Option<i64> {
+    let num = |part: &str| part.parse::<i64>().ok();
+    let (date, time) = s.split_once(['T', ' ']).unwrap_or((s, "00:00:00Z"));
+    let mut ymd = date.splitn(3, '-');
+    let (year, month, day) = (num(ymd.next()?)?, num(ymd.next()?)?, num(ymd.next()?)?);
+    if !(1..=12).contains(&month) || !(1..=31).contains(&day) {
+        return None;
+    }
+    let (clock, offset) = match time.find(['Z', 'z', '+', '-']) {
+        Some(i) => (&time[..i], &time[i..]),
+        None => (time, "Z"),
+    };
+    let offset_minutes = match offset {
+        "Z" | "z" => 0,
+        _ => {
+            let sign = if offset.starts_with('-') { -1 } else { 1 };
+            let (h, m) = offset[1..].split_once(':').unwrap_or((&offset[1..offset.len().min(3)], offset.get(3..).unwrap_or("0")));
+            sign * (num(h)? * 60 + num(if m.is_empty() { "0" } else { m })?)
+        }
+    };
+    let (hms, fraction) = clock.split_once('.').unwrap_or((clock, ""));
+    let mut parts = hms.splitn(3, ':');
+    let (hour, minute, second) = (num(parts.next()?)?, num(parts.next().unwrap_or("0"))?, num(parts.next().unwrap_or("0"))?);
+    let millis = if fraction.is_empty() { 0 } else { num(&format!("{:0<3}", &fraction[..fraction.len().min(3)]))? };
+    // Days since 1970-01-01 of a civil date (Howard Hinnant's algorithm)
+    let y = if month <= 2 { year - 1 } else { year };
+    let era = y.div_euclid(400);
+    let yoe = y.rem_euclid(400);
+    let mp = if month > 2 { month - 3 } else { month + 9 };
+    let doy = (153 * mp + 2) / 5 + day - 1;
+    let doe = yoe * 365 + yoe / 4 - yoe / 100 + doy;
+    let days = era * 146_097 + doe - 719_468;
+    Some(((days * 86_400 + hour * 3600 + minute * 60 + second) - offset_minutes * 60) * 1000 + millis)
+}
+
+/// What a `mongodb://` connection string names.
+#[derive(Debug, Clone, PartialEq)]
+struct MongoUri {
+    hosts: Vec<String>,
+    user: Option<String>,
+    password: Option<String>,
+    database: Option<String>,
+    auth_source: Option<String>,
+}
+
+fn percent_decode(s: &str) -> String {
+    let bytes = s.as_bytes();
+    let mut out = Vec::with_capacity(bytes.len());
+    let mut i = 0;
+    while i < bytes.len() {
+        match (bytes[i], s.get(i + 1..i + 3).and_then(|h| u8::from_str_radix(h, 16).ok())) {
+            (b'%', Some(b)) => {
+                out.push(b);
+                i += 3;
+            }
+            (b, _) => {
+                out.push(b);
+                i += 1;
+            }
+        }
+    }
+    String::from_utf8_lossy(&out).into_owned()
+}
+
+fn parse_uri(uri: &str) -> Result<MongoUri, String> {
+    if uri.starts_with("mongodb+srv://") {
+        return Err("conexões mongodb+srv:// (DNS SRV) não são suportadas; use mongodb://host:porta".to_string());
+    }
+    let rest = uri.strip_prefix("mongodb://").ok_or_else(|| format!("URI do MongoDB inválida: {} (esperado mongodb://...)", uri))?;
+    let (rest, query) = rest.split_once('?').unwrap_or((rest, ""));
+    let (authority, path) = rest.split_once('/').unwrap_or((rest, ""));
+    let (userinfo, hosts) = match authority.rsplit_once('@') {
+        Some((u, h)) => (Some(u), h),
+        None => (None, authority),
+    };
+    let hosts: Vec<String> = hosts
+        .split(',')
+        .map(str::trim)
+        .filter(|h| !h.is_empty())
+        .map(|h| if h.contains(':') { h.to_string() } else { format!("{}:27017", h) })
+        .collect();
+    if hosts.is_empty() {
+        return Err(format!("URI do MongoDB sem host: {}", uri));
+    }
+    let (user, password) = match userinfo.map(|u| u.split_once(':').unwrap_or((u, ""))) {
+        Some((u, p)) => (Some(percent_decode(u)).filter(|u| !u.is_empty()), Some(percent_decode(p))),
+        None => (None, None),
+    };
+    let auth_source = query
+        .split('&')
+        .filter_map(|pair| pair.split_once('='))
+        .find(|(k, _)| k.eq_ignore_ascii_case("authSource"))
+        .map(|(_, v)| percent_decode(v));
+    let database = Some(percent_decode(path.trim_matches('/'))).filter(|d| !d.is_empty());
+    Ok(MongoUri { hosts, user, password, database, auth_source })
+}
+
+/// Value of the first of `vars` and where it came from: the environment, `.env`, `dx dev-env
+/// export` and the code's default, in that order.
+pub(crate) fn resolve_var(project_dir: &Path, vars: &[&str]) -> Option<(String, String)> {
+    for var in vars {
+        if let Ok(v) = std::env::var(var).map(|v| v.trim().to_string()) {
+            if !v.is_empty() {
+                return Some((v, format!("{} do ambiente", var)));
+            }
+        }
+    }
+    let dotenv = crate::dev_kafka::dotenv_values(project_dir);
+    for var in vars {
+        if let Some(v) = dotenv.get(*var) {
+            return Some((v.clone(), format!("{} do .env", var)));
+        }
+    }
+    let composed = crate::env_export::compose(project_dir);
+    for var in vars {
+        if let Some(v) = composed.get(*var) {
+            return Some((v.value.clone(), format!("{} ({})", var, v.source)));
+        }
+    }
+    let scanned = crate::dev_env::scan(project_dir);
+    for var in vars {
+        if let Some(default) = scanned.iter().find(|v| v.name == *var).and_then(|v| v.default.clone()) {
+            return Some((default, format!("padrão de {} no código", var)));
+        }
+    }
+    None
+}
+
+/// One connection to a MongoDB server.
+struct Connection {
+    stream: TcpStream,
+    request_id: i32,
+}
+
+impl Connection {
+    fn open(address: &str) -> io::Result<Connection> {
+        let addr = address
+            .to_socket_addrs()?
+            .next()
+            .ok_or_else(|| io::Error::new(io::ErrorKind::NotFound, format!("endereço inválido: {}", address)))?;
+        let stream = TcpStream::connect_timeout(&addr, TIMEOUT)?;
+        stream.set_read_timeout(Some(TIMEOUT))?;
+        stream.set_write_timeout(Some(TIMEOUT))?;
+        Ok(Connection { stream, request_id: 0 })
+    }
+
+    /// Run `command` against database `db` (one OP_MSG round trip) and return the reply document.
+    fn call(&mut self, db: &str, command: Bson) -> io::Result<Bson> {
+        let Bson::Document(mut fields) = command else { unreachable!("commands are documents") };
+        fields.push(("$db".to_string(), Bson::String(db.to_string())));
+        self.request_id += 1;
+        let mut body = Vec::new();
+        body.extend_from_slice(&0u32.to_le_bytes()); // flagBits
+        body.push(0); // section kind 0: the command document
+        Bson::Document(fields).encode(&mut body);
+
+        let mut message = Vec::with_capacity(16 + body.len());
+        message.extend_from_slice(&(16 + body.len() as i32).to_le_bytes());
+        message.extend_from_slice(&self.request_id.to_le_bytes());
+        message.extend_from_slice(&0i32.to_le_bytes());
+        message.extend_from_slice(&OP_MSG.to_le_bytes());
+        message.extend_from_slice(&body);
+        self.stream.write_all(&message)?;
+
+        let mut header = [0u8; 16];
+        self.stream.read_exact(&mut header)?;
+        let mut decoder = Decoder(&header);
+        let len = decoder.i32()?;
+        let (_, response_to, opcode) = (decoder.i32()?, decoder.i32()?, decoder.i32()?);
+        let mut reply = vec![0u8; (len.max(16) - 16) as usize];
+        self.stream.read_exact(&mut reply)?;
+        if response_to != self.request_id || opcode != OP_MSG {
+            return Err(invalid("resposta inesperada do MongoDB"));
+        }
+        let mut decoder = Decoder(&reply);
+        decoder.take(4)?; // flagBits
+        if decoder.take(1)?[0] != 0 {
+            return Err(invalid("resposta do MongoDB sem documento"));
+        }
+        Ok(Bson::Document(decoder.document()?))
+    }
+
+    /// Like `call`, but a reply with `ok: 0` becomes an error carrying its code and message.
+    fn command(&mut self, db: &str, command: Bson) -> Result<Bson, CommandError> {
+        let reply = self.call(db, command).map_err(|e| CommandError { code: 0, message: e.to_string() })?;
+        if reply.get("ok").and_then(Bson::as_i64) == Some(1) {
+            return Ok(reply);
+        }
+        Err(CommandError {
+            code: reply.get("code").and_then(Bson::as_i64).unwrap_or(0),
+            message: reply.get("errmsg").and_then(Bson::as_str).unwrap_or("erro desconhecido").to_string(),
+        })
+    }
+}
+
+/// A failed command: the server's error code (0 for I/O errors) and message.
+#[derive(Debug)]
+struct CommandError {
+    code: i64,
+    message: String,
+}
+
+impl std::fmt::Display for CommandError {
+    fn fmt(&self, f: &mut std::fmt::Formatter<'_>) -> std::fmt::Result {
+        match self.code {
+            0 => write!(f, "{}", self.message),
+            code => write!(f, "{} (código {})", self.message, code),
+        }
+    }
+}
+
+pub(crate) fn hmac(key: &[u8], data: &[u8]) -> [u8; 32] {
+    let mut block = [0u8; 64];
+    if key.len() > 64 {
+        block[..32].copy_from_slice(&Sha256::digest(key));
+    } else {
+        block[..key.len()].copy_from_slice(key);
+    }
+    let mut inner = Sha256::new();
+    inner.update(block.map(|b| b ^ 0x36));
+    inner.update(data);
+    let mut outer = Sha256::new();
+    outer.update(block.map(|b| b ^ 0x5c));
+    outer.update(inner.finalize());
+    outer.finalize().into()
+}
+
+/// PBKDF2-HMAC-SHA-256 with a single output block, SCRAM's `Hi()`.
+fn pbkdf2(password: &[u8], salt: &[u8], iterations: u32) -> [u8; 32] {
+    let mut u = hmac(password, &[salt, &1u32.to_be_bytes()].concat());
+    let mut out = u;
+    for _ in 1..iterations {
+        u = hmac(password, &u);
+        out.iter_mut().zip(u).for_each(|(o, b)| *o ^= b);
+    }
+    out
+}
+
+const BASE64: &[u8; 64] = b"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789+/";
+
+fn base64_encode(data: &[u8]) -> String {
+    let mut out = String::new();
+    for chunk in data.chunks(3) {
+        let n = chunk.iter().enumerate().fold(0u32, |n, (i, b)| n | (*b as u32) << (16 - 8 * i));
+        for i in 0..4 {
+            out.push(if i <= chunk.len() { BASE64[(n >> (18 - 6 * i) & 63) as usize] as char } else { '=' });
+        }
+    }
+    out
+}
+
+pub(crate) fn base64_decode(text: &str) -> Option<Vec<u8>> {
+    let mut out = Vec::new();
+    let (mut acc, mut bits) = (0u32, 0);
+    for c in text.bytes().filter(|c| *c != b'=') {
+        acc = acc << 6 | BASE64.iter().position(|b| *b == c)? as u32;
+        bits += 6;
+        if bits >= 8 {
+            bits -= 8;
+            out.push((acc >> bits) as u8);
+        }
+    }
+    Some(out)
+}
+
+/// Authenticate with SCRAM-SHA-256 (RFC 7677), MongoDB's default mechanism since 4.0.
+fn authenticate(conn: &mut Connection, source: &str, user: &str, password: &str) -> Result<(), String> {
+    let failed = |e: CommandError| format!("Falha na autenticação de '{}' em {}: {}", user, source, e);
+    let payload = |reply: &Bson| match reply.get("payload") {
+        Some(Bson::Binary(bytes)) => String::from_utf8_lossy(bytes).into_owned(),
+        _ => String::new(),
+    };
+    let nonce = base64_encode(&[crate::dev_kafka::random_u64().to_le_bytes(), crate::dev_kafka::random_u64().to_le_bytes()].concat());
+    let first_bare = format!("n={},r={}", user.replace('=', "=3D").replace(',', "=2C"), nonce);
+    let reply = conn
+        .command(
+            source,
+            doc(vec![
+                ("saslStart", Bson::Int32(1)),
+                ("mechanism", Bson::String("SCRAM-SHA-256".to_string())),
+                ("payload", Bson::Binary(format!("n,,{}", first_bare).into_bytes())),
+                ("autoAuthorize", Bson::Int32(1)),
+                ("options", doc(vec![("skipEmptyExchange", Bson::Bool(true))])),
+            ]),
+        )
+        .map_err(failed)?;
+    let server_first = payload(&reply);
+    let attr = |name: &str| server_first.split(',').find_map(|a| a.strip_prefix(name)).map(str::to_string);
+    let (Some(server_nonce), Some(salt), Some(iterations)) =
+        (attr("r="), attr("s=").and_then(|s| base64_decode(&s)), attr("i=").and_then(|i| i.parse::<u32>().ok()))
+    else {
+        return Err(format!("resposta SCRAM inválida do MongoDB: {}", server_first));
+    };
+    if !server_nonce.starts_with(&nonce) {
+        return Err("o MongoDB respondeu com um nonce SCRAM inválido".to_string());
+    }
+
+    let salted = pbkdf2(password.as_bytes(), &salt, iterations);
+    let client_key = hmac(&salted, b"Client Key");
+    let stored_key: [u8; 32] = Sha256::digest(client_key).into();
+    let final_without_proof = format!("c=biws,r={}", server_nonce);
+    let auth_message = format!("{},{},{}", first_bare, server_first, final_without_proof);
+    let signature = hmac(&stored_key, auth_message.as_bytes());
+    let proof: Vec<u8> = client_key.iter().zip(signature).map(|(k, s)| k ^ s).collect();
+    let conversation = reply.get("conversationId").cloned().unwrap_or(Bson::Int32(1));
+    let mut reply = conn
+        .command(
+            source,
+            doc(vec![
+                ("saslContinue", Bson::Int32(1)),
+                ("conversationId", conversation.clone()),
+                ("payload", Bson::Binary(format!("{},p={}", final_without_proof, base64_encode(&proof)).into_bytes())),
+            ]),
+        )
+        .map_err(failed)?;
+    let server_signature = hmac(&hmac(&salted, b"Server Key"), auth_message.as_bytes());
+    if payload(&reply) != format!("v={}", base64_encode(&server_signature)) {
+        return Err("a assinatura SCRAM do MongoDB não confere".to_string());
+    }
+    // Servers that ignore skipEmptyExchange expect one more, empty, round trip
+    if reply.get("done") != Some(&Bson::Bool(true)) {
+        reply = conn
+            .command(
+                source,
+                doc(vec![("saslContinue", Bson::Int32(1)), ("conversationId", conversation), ("payload", Bson::Binary(Vec::new()))]),
+            )
+            .map_err(failed)?;
+        if reply.get("done") != Some(&Bson::Bool(true)) {
+            return Err("a autenticação SCRAM não terminou".to_string());
+        }
+    }
+    Ok(())
+}
+
+/// Connect to the first reachable host of the URI.
+fn connect(uri: &MongoUri) -> Result<(Connection, String), String> {
+    let mut errors = Vec::new();
+    for address in &uri.hosts {
+        match Connection::open(address) {
+            Ok(conn) => return Ok((conn, address.clone())),
+            Err(e) => errors.push(format!("{}: {}", address, e)),
+        }
+    }
+    Err(errors.join("; "))
+}
+
+/// Authenticate when the URI has credentials, against `authSource`, the URI's database or `admin`.
+fn login(conn: &mut Connection, uri: &MongoUri) -> Result<(), String> {
+    let Some(user) = &uri.user else { return Ok(()) };
+    let source = uri.auth_source.as_deref().or(uri.database.as_deref()).unwrap_or("admin");
+    authenticate(conn, source, user, uri.password.as_deref().unwrap_or_default())
+}
+
+/// A fixture file: the collection it fills and its documents.
+struct Seed {
+    collection: String,
+    path: PathBuf,
+    documents: Vec<Bson>,
+}
+
+/// Documents of a fixture: a JSON array (or a single object) for `.json`, one object per line
+/// for `.ndjson`/`.jsonl`.
+fn read_seed(path: &Path) -> Result<Vec<Bson>, String> {
+    let content = std::fs::read_to_string(path).map_err(|e| e.to_string())?;
+    let values: Vec<Value> = match path.extension().and_then(|e| e.to_str()) {
+        Some("json") => match serde_json::from_str(&content).map_err(|e| format!("JSON inválido: {}", e))? {
+            Value::Array(items) => items,
+            value => vec![value],
+        },
+        _ => content
+            .lines()
+            .enumerate()
+            .filter(|(_, l)| !l.trim().is_empty())
+            .map(|(i, l)| serde_json::from_str(l).map_err(|e| format!("linha {}: JSON inválido: {}", i + 1, e)))
+            .collect::<Result<_, _>>()?,
+    };
+    values
+        .iter()
+        .enumerate()
+        .map(|(i, v)| match v {
+            Value::Object(_) => from_json(v).map_err(|e| format!("documento {}: {}", i + 1, e)),
+            _ => Err(format!("documento {}: esperado um objeto JSON", i + 1)),
+        })
+        .collect()
+}
+
+/// Fixtures under `seeds/`, one collection per file (`seeds/users.json` fills `users`), by name.
+fn read_seeds(project_dir: &Path) -> Result<Vec<Seed>, String> {
+    let mut paths: Vec<PathBuf> = std::fs::read_dir(project_dir.join(SEEDS_DIR))
+        .map_err(|_| format!("Nenhum diretório {}/ em {}.", SEEDS_DIR, project_dir.display()))?
+        .flatten()
+        .map(|e| e.path())
+        .filter(|p| p.is_file() && matches!(p.extension().and_then(|e| e.to_str()), Some("json" | "ndjson" | "jsonl")))
+        .collect();
+    paths.sort();
+    paths
+        .into_iter()
+        .map(|path| {
+            let rel = path.strip_prefix(project_dir).unwrap_or(&path).to_path_buf();
+            let documents = read_seed(&path).map_err(|e| format!("{}: {}", rel.display(), e))?;
+            let collection = path.file_stem().and_then(|s| s.to_str()).unwrap_or_default().to_string();
+            Ok(Seed { collection, path: rel, documents })
+        })
+        .collect()
+}
+
+/// Source expressions followed by a collection name: the Go and Node drivers' `Collection(...)`,
+/// pymongo's `get_collection(...)`/`db[...]`, the Java driver's `getCollection(...)` and Spring
+/// Data's `@Document(...)`.
+const COLLECTION_PATTERNS: &[&str] =
+    &["Collection(", "collection(", "getCollection(", "get_collection(", "db[", "@Document(collection =", "@Document(collection=", "@Document("];
+
+/// Collections the project's code reads or writes, with where each was first seen.
+fn detect_collections(project_dir: &Path) -> BTreeMap<String, String> {
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(project_dir, &mut files);
+    files.sort();
+    let mut found = BTreeMap::new();
+    for file in files {
+        let Ok(content) = std::fs::read_to_string(&file) else { continue };
+        let rel = file.strip_prefix(project_dir).unwrap_or(&file).display().to_string();
+        for (n, line) in content.lines().enumerate() {
+            for pattern in COLLECTION_PATTERNS {
+                for (pos, _) in line.match_indices(pattern) {
+                    for name in crate::dev_kafka::literals_after(&line[pos + pattern.len()..]).into_iter().take(1) {
+                        found.entry(name).or_insert_with(|| format!("{}:{}", rel, n + 1));
+                    }
+                }
+            }
+        }
+    }
+    found
+}
+
+/// Outcome of loading one fixture.
+struct Loaded {
+    inserted: usize,
+    existing: usize,
+}
+
+/// Insert `documents` into `collection` in batches. Documents whose `_id` is already there are
+/// counted, not failed, so seeding twice without `--drop` is harmless.
+fn insert(conn: &mut Connection, db: &str, collection: &str, documents: &[Bson]) -> Result<Loaded, String> {
+    let mut loaded = Loaded { inserted: 0, existing: 0 };
+    for batch in documents.chunks(INSERT_BATCH) {
+        let reply = conn
+            .command(
+                db,
+                doc(vec![
+                    ("insert", Bson::String(collection.to_string())),
+                    ("documents", Bson::Array(batch.to_vec())),
+                    ("ordered", Bson::Bool(false)),
+                ]),
+            )
+            .map_err(|e| e.to_string())?;
+        loaded.inserted += reply.get("n").and_then(Bson::as_i64).unwrap_or(0) as usize;
+        let errors = match reply.get("writeErrors") {
+            Some(Bson::Array(errors)) => errors.as_slice(),
+            _ => &[],
+        };
+        for error in errors {
+            if error.get("code").and_then(Bson::as_i64) == Some(DUPLICATE_KEY) {
+                loaded.existing += 1;
+                continue;
+            }
+            let index = error.get("index").and_then(Bson::as_i64).unwrap_or(0) as usize;
+            let message = error.get("errmsg").and_then(Bson::as_str).unwrap_or("erro desconhecido");
+            return Err(format!("documento {}: {}", loaded.inserted + loaded.existing + index + 1, message));
+        }
+    }
+    Ok(loaded)
+}
+
+/// Insert one JSON document into `collection` of the database at `uri` (the URI's database, else
+/// `default_db`); returns the `database.collection` written to.
+pub(crate) fn insert_json(uri: &str, default_db: &str, collection: &str, document: &Value) -> Result<String, String> {
+    let parsed = parse_uri(uri)?;
+    let db = parsed.database.clone().unwrap_or_else(|| default_db.to_string());
+    let (mut conn, _) = connect(&parsed)?;
+    login(&mut conn, &parsed)?;
+    insert(&mut conn, &db, collection, &[from_json(document)?])?;
+    Ok(format!("{}.{}", db, collection))
+}
+
+/// Load the fixtures under `seeds/` into the project's MongoDB database.
+pub fn cmd_seed(dir: Option<PathBuf>, uri: Option<String>, database: Option<String>, drop: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| PathBuf::from("."));
+    let seeds = match read_seeds(&project_dir) {
+        Ok(seeds) if !seeds.is_empty() => seeds,
+        Ok(_) => {
+            eprintln!("Nenhum arquivo .json, .ndjson ou .jsonl em {}/.", SEEDS_DIR);
+            return 2;
+        }
+        Err(e) => {
+            eprintln!("{}", e);
+            let collections = detect_collections(&project_dir);
+            match collections.keys().next() {
+                Some(first) => eprintln!("Crie um arquivo por coleção, ex.: {}/{}.json (coleções do projeto: {}).", SEEDS_DIR, first, collections.keys().cloned().collect::<Vec<_>>().join(", ")),
+                None => eprintln!("Crie um arquivo por coleção, ex.: {}/users.json com um array de documentos.", SEEDS_DIR),
+            }
+            return 2;
+        }
+    };
+
+    let (uri_text, uri_origin) = match uri {
+        Some(u) => (u, "--uri".to_string()),
+        None => resolve_var(&project_dir, URI_VARS).unwrap_or_else(|| (DEFAULT_URI.to_string(), "padrão".to_string())),
+    };
+    let parsed = match parse_uri(&uri_text) {
+        Ok(p) => p,
+        Err(e) => {
+            eprintln!("{}", e);
+            return 2;
+        }
+    };
+    let Some((db, db_origin)) = database
+        .map(|d| (d, "--db".to_string()))
+        .or_else(|| parsed.database.clone().map(|d| (d, "URI".to_string())))
+        .or_else(|| resolve_var(&project_dir, DATABASE_VARS))
+    else {
+        eprintln!("Não foi possível descobrir o banco do projeto: informe-o com --db <banco> (ou MONGODB_DATABASE no .env).");
+        return 2;
+    };
+
+    let (mut conn, address) = match connect(&parsed) {
+        Ok(c) => c,
+        Err(e) => {
+            eprintln!("Não foi possível conectar ao MongoDB {} ({}): {}", parsed.hosts.join(","), uri_origin, e);
+            eprintln!("Suba o banco local com: dx dev-services run (ou informe outro com --uri mongodb://host:porta)");
+            return 2;
+        }
+    };
+    if let Err(e) = login(&mut conn, &parsed) {
+        eprintln!("{}", e);
+        eprintln!("Confira o usuário e a senha da URI ({}).", uri_origin);
+        return 2;
+    }
+    eprintln!("MongoDB: {} ({}), banco {} ({})", address, uri_origin, db, db_origin);
+
+    let collections = detect_collections(&project_dir);
+    let width = seeds.iter().map(|s| s.collection.len()).max().unwrap_or(0);
+    let (mut total, mut failed) = (0, false);
+    for seed in &seeds {
+        if drop {
+            if let Err(e) = conn.command(&db, doc(vec![("drop", Bson::String(seed.collection.clone()))])) {
+                if e.code != NAMESPACE_NOT_FOUND {
+                    println!("  ✗ {:width$}  erro ao remover: {}", seed.collection, e, width = width);
+                    failed = true;
+                    continue;
+                }
+            }
+        }
+        match insert(&mut conn, &db, &seed.collection, &seed.documents) {
+            Ok(loaded) => {
+                total += loaded.inserted;
+                let existing = match loaded.existing {
+                    0 => String::new(),
+                    n => format!(", {} já existia(m)", n),
+                };
+                println!(
+                    "  ✓ {:width$}  {} documento(s) inserido(s){} ({})",
+                    seed.collection,
+                    loaded.inserted,
+                    existing,
+                    seed.path.display(),
+                    width = width
+                );
+            }
+            Err(e) => {
+                println!("  ✗ {:width$}  {} ({})", seed.collection, e, seed.path.display(), width = width);
+                failed = true;
+            }
+        }
+        if !collections.is_empty() && !collections.contains_key(&seed.collection) {
+            println!("    aviso: a coleção '{}' não aparece no código do projeto", seed.collection);
+        }
+    }
+    println!("{} documento(s) inserido(s) em {} coleção(ões){}.", total, seeds.len(), if drop { " (recriadas com --drop)" } else { "" });
+    if failed {
+        1
+    } else {
+        0
+    }
+}
+
+impl DbService {
+    fn name(self) -> &'static str {
+        match self {
+            DbService::Mongodb => "mongodb",
+            DbService::Postgres => "postgres",
+            DbService::Mysql => "mysql",
+            DbService::Redis => "redis",
+        }
+    }
+
+    /// Command-line client of the database, also present in its Dev Services image.
+    fn client(self) -> &'static str {
+        match self {
+            DbService::Mongodb => "mongosh",
+            DbService::Postgres => "psql",
+            DbService::Mysql => "mysql",
+            DbService::Redis => "redis-cli",
+        }
+    }
+
+    /// Connection string of the Dev Services container, for a database named with `--service`.
+    fn default_url(self) -> &'static str {
+        match self {
+            DbService::Mongodb => DEFAULT_URI,
+            DbService::Postgres => "postgres://postgres@localhost:5432/postgres",
+            DbService::Mysql => "mysql://root@localhost:3306",
+            DbService::Redis => "redis://localhost:6379",
+        }
+    }
+}
+
+/// A database the project connects to, with its connection string and where it came from.
+struct Target {
+    service: DbService,
+    url: String,
+    origin: String,
+}
+
+/// Databases named by the project's variables: MongoDB (`MONGODB_URI`), PostgreSQL or MySQL
+/// (`DATABASE_URL`, by its scheme) and Redis (`REDIS_URL`).
+fn detect_targets(project_dir: &Path) -> Vec<Target> {
+    let mut targets = Vec::new();
+    if let Some((url, origin)) = resolve_var(project_dir, URI_VARS) {
+        targets.push(Target { service: DbService::Mongodb, url, origin });
+    }
+    if let Some((url, origin)) = resolve_var(project_dir, SQL_VARS) {
+        let scheme = url.split("://").next().unwrap_or_default().to_lowercase();
+        let service = match scheme.split('+').next().unwrap_or_default() {
+            "postgres" | "postgresql" => Some(DbService::Postgres),
+            "mysql" | "mariadb" => Some(DbService::Mysql),
+            _ => None,
+        };
+        if let Some(service) = service {
+            targets.push(Target { service, url, origin });
+        }
+    }
+    if let Some((url, origin)) = resolve_var(project_dir, REDIS_VARS) {
+        let url = if url.contains("://") { url } else { format!("redis://{}", url) };
+        targets.push(Target { service: DbService::Redis, url, origin });
+    }
+    targets
+}
+
+/// `uri` with `database` as its path when it names none, so mongosh opens the project's database.
+fn with_database(uri: &str, database: &str) -> String {
+    let (base, query) = match uri.split_once('?') {
+        Some((b, q)) => (b, format!("?{}", q)),
+        None => (uri, String::new()),
+    };
+    let base = base.trim_end_matches('/');
+    match base.split_once("://") {
+        Some((_, rest)) if !rest.contains('/') => format!("{}/{}{}", base, database, query),
+        _ => uri.to_string(),
+    }
+}
+
+/// Client arguments and environment to open `url`. Every client but mysql takes the URL as is;
+/// mysql gets the parts, with the password in `MYSQL_PWD` instead of the command line.
+fn client_command(service: DbService, url: &str) -> (Vec<String>, Vec<(String, String)>) {
+    let client = service.client().to_string();
+    match service {
+        DbService::Mongodb | DbService::Postgres => (vec![client, url.to_string()], Vec::new()),
+        DbService::Redis => (vec![client, "-u".to_string(), url.to_string()], Vec::new()),
+        DbService::Mysql => {
+            let rest = url.split_once("://").map_or(url, |(_, r)| r);
+            let rest = rest.split(['?', '#']).next().unwrap_or_default();
+            let (authority, database) = rest.split_once('/').unwrap_or((rest, ""));
+            let (userinfo, host) = authority.rsplit_once('@').map_or((None, authority), |(u, h)| (Some(u), h));
+            let (host, port) = host.rsplit_once(':').unwrap_or((host, "3306"));
+            let (user, password) = userinfo.map_or(("root", None), |u| u.split_once(':').map_or((u, None), |(u, p)| (u, Some(p))));
+            let mut args = vec![client, "--protocol=TCP".to_string(), "-h".to_string(), host.to_string(), "-P".to_string(), port.to_string()];
+            args.extend(["-u".to_string(), percent_decode(user)]);
+            if !database.is_empty() {
+                args.push(percent_decode(database));
+            }
+            let env = password.map(|p| vec![("MYSQL_PWD".to_string(), percent_decode(p))]).unwrap_or_default();
+            (args, env)
+        }
+    }
+}
+
+fn shell_words(words: &[String]) -> String {
+    words
+        .iter()
+        .map(|w| {
+            if !w.is_empty() && w.chars().all(|c| c.is_alphanumeric() || "-_./=:@,+%".contains(c)) {
+                w.clone()
+            } else {
+                format!("'{}'", w.replace('\'', "'\\''"))
+            }
+        })
+        .collect::<Vec<_>>()
+        .join(" ")
+}
+
+/// `url` with its password replaced by `***`, for messages.
+pub(crate) fn masked(url: &str) -> String {
+    let Some((scheme, rest)) = url.split_once("://") else { return url.to_string() };
+    match rest.split_once('@') {
+        Some((userinfo, host)) if userinfo.contains(':') => {
+            format!("{}://{}:***@{}", scheme, userinfo.split(':').next().unwrap_or_default(), host)
+        }
+        _ => url.to_string(),
+    }
+}
+
+/// Whether the Dev Services compose file of the project defines `service`.
+fn compose_defines(compose: &Path, service: &str) -> bool {
+    std::fs::read_to_string(compose)
+        .map(|c| c.lines().any(|l| l.trim_end() == format!("  {}:", service)))
+        .unwrap_or(false)
+}
+
+/// Open the shell of the project's database, connected with the project's own settings.
+pub fn cmd_shell(dir: Option<PathBuf>, service: Option<DbService>, print: bool, args: Vec<String>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| PathBuf::from("."));
+    let targets = detect_targets(&project_dir);
+    let target = match service {
+        Some(service) => targets.into_iter().find(|t| t.service == service).unwrap_or_else(|| Target {
+            service,
+            url: service.default_url().to_string(),
+            origin: "padrão do Dev Services".to_string(),
+        }),
+        None if targets.len() == 1 => targets.into_iter().next().unwrap(),
+        None if targets.is_empty() => {
+            eprintln!("Nenhum banco encontrado nas variáveis do projeto (MONGODB_URI, DATABASE_URL, REDIS_URL).");
+            eprintln!("Informe qual abrir com --service mongodb|postgres|mysql|redis.");
+            return 2;
+        }
+        None => {
+            eprintln!("O projeto usa mais de um banco; escolha um com --service:");
+            for t in &targets {
+                eprintln!("  {:8}  {} ({})", t.service.name(), masked(&t.url), t.origin);
+            }
+            return 2;
+        }
+    };
+
+    let mut url = target.url.clone();
+    if target.service == DbService::Mongodb {
+        if let Some((database, _)) = resolve_var(&project_dir, DATABASE_VARS) {
+            url = with_database(&url, &database);
+        }
+    }
+    let (mut command, env) = client_command(target.service, &url);
+    command.extend(args);
+    if print {
+        let assignments: Vec<String> = env.iter().map(|(k, v)| format!("{}={} ", k, shell_words(std::slice::from_ref(v)))).collect();
+        println!("{}{}", assignments.concat(), shell_words(&command));
+        return 0;
+    }
+
+    // Without the client installed, run the one inside the Dev Services container
+    let compose = crate::dev_services_compose_path(&project_dir);
+    let mut process = if crate::sandbox::find_in_path(&command[0]).is_some() {
+        let mut p = std::process::Command::new(&command[0]);
+        p.args(&command[1..]).envs(env.iter().cloned());
+        p
+    } else if compose_defines(&compose, target.service.name()) {
+        let mut p = std::process::Command::new("docker");
+        p.arg("compose").arg("-f").arg(&compose).arg("exec");
+        for (k, v) in &env {
+            p.arg("-e").arg(format!("{}={}", k, v));
+        }
+        p.arg(target.service.name()).args(&command);
+        eprintln!("{} não está instalado; usando o do contêiner {}.", command[0], target.service.name());
+        p
+    } else {
+        eprintln!("O cliente {} não está instalado.", command[0]);
+        eprintln!("Instale-o ou suba o banco com dx dev-services run para usar o cliente do contêiner.");
+        return 2;
+    };
+    eprintln!("▶ {} {} ({})", command[0], masked(&url), target.origin);
+    match process.current_dir(&project_dir).status() {
+        Ok(status) => status.code().unwrap_or(1),
+        Err(e) => {
+            eprintln!("Erro ao executar {}: {}", command[0], e);
+            1
+        }
+    }
+}
@@ -1,14 +1,16 @@
 // SPDX-License-Identifier: MIT OR Apache-2.0
 // Copyright (c) 2025 The dx-cli Contributors
 
+use serde::{Deserialize, Serialize};
 use serde_json::Value;
 use std::collections::BTreeMap;
 use std::fs;
 use std::io;
 use std::path::{Path, PathBuf};
-use toml_edit::{value, Document};
+use toml_edit::{value, DocumentMut};
 
-#[derive(Clone, Copy, Debug, PartialEq, Eq)]
+#[derive(Clone, Copy, Debug, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
 enum Stack {
     Node,
     Rust,
@@ -18,6 +20,9 @@ enum Stack {
     Gradle,
     Php,
     Ruby,
+    DotNet,
+    Elixir,
+    Erlang,
     Unknown,
 }
 
@@ -42,17 +47,51 @@ impl Stack {
             Stack::Php
         } else if dir.join("Gemfile").exists() {
             Stack::Ruby
+        } else if crate::dotnet_build::is_project(dir) {
+            Stack::DotNet
+        } else if dir.join(crate::beam_build::MIX_FILE).exists() {
+            Stack::Elixir
+        } else if dir.join(crate::beam_build::REBAR_FILE).exists() {
+            Stack::Erlang
         } else {
             Stack::Unknown
         }
     }
+
+    fn key(self) -> &'static str {
+        match self {
+            Stack::Node => "node",
+            Stack::Rust => "rust",
+            Stack::Python => "python",
+            Stack::Go => "go",
+            Stack::Maven => "maven",
+            Stack::Gradle => "gradle",
+            Stack::Php => "php",
+            Stack::Ruby => "ruby",
+            Stack::DotNet => "dotnet",
+            Stack::Elixir => "elixir",
+            Stack::Erlang => "erlang",
+            Stack::Unknown => "unknown",
+        }
+    }
 }
 
 fn project_dir(dir: Option<PathBuf>) -> PathBuf {
     dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")))
 }
 
-#[derive(Debug, Clone)]
+/// Ask the registry about every dependency at once (in parallel, at the registry's pace), so
+/// the lookups one by one that follow are answered from the cache.
+fn prefetch(urls: Vec<String>) {
+    if urls.len() < 2 {
+        return;
+    }
+    let phase = crate::progress::Phase::start("dev-dependencies.latest", "Consultando as versões mais recentes nos registries");
+    let results = crate::registry::get_all(&urls, Some(&phase));
+    phase.finish(results.iter().all(|r| r.is_ok()));
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
 pub struct DependencyInfo {
     pub name: String,
     pub current_version: String,
@@ -67,18 +106,109 @@ impl DependencyInfo {
     }
 }
 
+/// A development dependency as declared in the manifest.
+#[derive(Serialize, Deserialize)]
+struct Declared {
+    name: String,
+    version: String,
+    /// Features turned on in it (Cargo)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    features: Vec<String>,
+}
+
+#[derive(Serialize, Deserialize)]
+struct DeclaredList {
+    stack: Stack,
+    dependencies: Vec<Declared>,
+}
+
+#[derive(Serialize)]
+struct ProjectList {
+    project: String,
+    #[serde(flatten)]
+    list: DeclaredList,
+}
+
+/// The development dependencies declared in the manifest of `project_dir` (from the detection
+/// cache while the manifests are unchanged). Java versions managed by parents and BOMs of the
+/// repository are detected again when it could not be reached.
+fn declared(project_dir: &Path) -> DeclaredList {
+    crate::detection_cache::cached_if("declared", project_dir, None, || {
+        let failures = crate::registry::failures();
+        let list = read_declared(project_dir);
+        (list, crate::registry::failures() == failures)
+    })
+}
+
+fn read_declared(project_dir: &Path) -> DeclaredList {
+    let stack = Stack::detect(project_dir);
+    if stack == Stack::Rust {
+        return DeclaredList { stack, dependencies: list_rust(project_dir) };
+    }
+    let declared = match stack {
+        Stack::Node => list_node(project_dir),
+        Stack::Python => list_python(project_dir),
+        Stack::Go => list_go(project_dir),
+        Stack::Maven | Stack::Gradle => list_java(project_dir),
+        Stack::Php => list_php(project_dir),
+        Stack::Ruby => list_ruby(project_dir),
+        Stack::DotNet => list_dotnet(project_dir),
+        Stack::Elixir | Stack::Erlang => list_hex(project_dir),
+        Stack::Rust | Stack::Unknown => Vec::new(),
+    };
+    let dependencies = declared.into_iter().map(|(name, version)| Declared { name, version, features: Vec::new() }).collect();
+    DeclaredList { stack, dependencies }
+}
+
+fn print_declared(list: &DeclaredList, indent: &str) {
+    if list.stack == Stack::Unknown {
+        println!("{}Stack não suportada ou não detectada.", indent);
+    } else if list.dependencies.is_empty() {
+        println!("{}Nenhuma dependência encontrada.", indent);
+    } else {
+        for d in &list.dependencies {
+            if d.features.is_empty() {
+                println!("{}- {} = {}", indent, d.name, d.version);
+            } else {
+                println!("{}- {} = {} (features: {})", indent, d.name, d.version, d.features.join(", "));
+            }
+        }
+    }
+}
+
+/// `dx dev-dependencies list`: the development dependencies of the manifest, as text or, under
+/// `--output json`, as `{stack, dependencies: [{name, version}]}`. A directory that is not a
+/// project itself but holds several (repositories, monorepo packages) lists each of them, read
+/// in parallel, and `--output json` gives `[{project, stack, dependencies}]`.
 pub fn list(dir: Option<PathBuf>) {
     let project_dir = project_dir(dir);
-    match Stack::detect(&project_dir) {
-        Stack::Node => list_node(&project_dir),
-        Stack::Rust => list_rust(&project_dir),
-        Stack::Python => list_python(&project_dir),
-        Stack::Go => list_go(&project_dir),
-        Stack::Maven => list_maven(&project_dir),
-        Stack::Gradle => list_gradle(&project_dir),
-        Stack::Php => list_php(&project_dir),
-        Stack::Ruby => list_ruby(&project_dir),
-        Stack::Unknown => println!("Stack não suportada ou não detectada."),
+    let projects = if Stack::detect(&project_dir) == Stack::Unknown { crate::scan::subprojects(&project_dir) } else { Vec::new() };
+    if projects.is_empty() {
+        let list = declared(&project_dir);
+        if crate::output::json() {
+            crate::output::print(&list);
+        } else {
+            print_declared(&list, "");
+        }
+        return;
+    }
+
+    let phase = crate::progress::Phase::start("dev-dependencies.list", "Lendo as dependências dos projetos");
+    let lists = crate::scan::each(&project_dir, &projects, &phase, declared);
+    phase.finish(true);
+    let lists: Vec<ProjectList> = projects
+        .iter()
+        .zip(lists)
+        .map(|(project, list)| ProjectList { project: crate::scan::display(&project_dir, project), list })
+        .collect();
+    if crate::output::json() {
+        crate::output::print(&lists);
+        return;
+    }
+    println!("Dependências de {} projetos em {}:", lists.len(), project_dir.display());
+    for ProjectList { project, list } in &lists {
+        println!("\n{} ({})", project, list.stack.key());
+        print_declared(list, "  ");
     }
 }
 
@@ -93,6 +223,8 @@ pub fn add(dir: Option<PathBuf>, name: String, version: Option<String>) {
         Stack::Maven => add_maven(&project_dir, name, version),
         Stack::Gradle => add_gradle(&project_dir, name, version),
         Stack::Ruby => add_ruby(&project_dir, name, version),
+        Stack::DotNet => add_dotnet(&project_dir, name, version),
+        stack @ (Stack::Elixir | Stack::Erlang) => add_hex(stack),
         Stack::Unknown => println!("Stack não suportada ou não detectada."),
     }
 }
@@ -108,6 +240,8 @@ pub fn update(dir: Option<PathBuf>, name: Option<String>) {
         Stack::Maven => update_maven(&project_dir, name),
         Stack::Gradle => update_gradle(&project_dir, name),
         Stack::Ruby => update_ruby(&project_dir, name),
+        Stack::DotNet => update_dotnet(&project_dir, name),
+        stack @ (Stack::Elixir | Stack::Erlang) => update_hex(stack),
         Stack::Unknown => println!("Stack não suportada ou não detectada."),
     }
 }
@@ -123,21 +257,36 @@ pub fn delete(dir: Option<PathBuf>, name: String) {
         Stack::Maven => delete_maven(&project_dir, name),
         Stack::Gradle => delete_gradle(&project_dir, name),
         Stack::Ruby => delete_ruby(&project_dir, name),
+        Stack::DotNet => delete_dotnet(&project_dir, name),
+        stack @ (Stack::Elixir | Stack::Erlang) => delete_hex(stack),
         Stack::Unknown => println!("Stack não suportada ou não detectada."),
     }
 }
 
+/// The development dependencies of `dir` with their latest versions. They come from the detection
+/// cache while the manifests are unchanged and the registry answers still fresh (`registry_cache_ttl`);
+/// a detection during which a registry failed is not kept.
 pub fn get_dependencies(dir: &Path) -> io::Result<Vec<DependencyInfo>> {
+    let ttl = crate::settings::get_u64("registry_cache_ttl").unwrap_or(0);
+    Ok(crate::detection_cache::cached_if("dependencies", dir, Some(ttl), || {
+        let failures = crate::registry::failures();
+        let deps = detect_dependencies(dir);
+        (deps, crate::registry::failures() == failures)
+    }))
+}
+
+fn detect_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     match Stack::detect(dir) {
-        Stack::Node => Ok(get_node_dependencies(dir)),
-        Stack::Rust => Ok(get_rust_dependencies(dir)),
-        Stack::Python => Ok(get_python_dependencies(dir)),
-        Stack::Go => Ok(get_go_dependencies(dir)),
-        Stack::Maven => Ok(get_maven_dependencies(dir)),
-        Stack::Gradle => Ok(get_gradle_dependencies(dir)),
-        Stack::Php => Ok(get_php_dependencies(dir)),
-        Stack::Ruby => Ok(get_ruby_dependencies(dir)),
-        Stack::Unknown => Ok(Vec::new()),
+        Stack::Node => get_node_dependencies(dir),
+        Stack::Rust => get_rust_dependencies(dir),
+        Stack::Python => get_python_dependencies(dir),
+        Stack::Go => get_go_dependencies(dir),
+        stack @ (Stack::Maven | Stack::Gradle) => get_java_dependencies(dir, stack),
+        Stack::Php => get_php_dependencies(dir),
+        Stack::Ruby => get_ruby_dependencies(dir),
+        Stack::DotNet => get_dotnet_dependencies(dir),
+        stack @ (Stack::Elixir | Stack::Erlang) => get_hex_dependencies(dir, stack),
+        Stack::Unknown => Vec::new(),
     }
 }
 
@@ -153,24 +302,17 @@ fn load_package_json(path: &Path) -> Value {
 
 fn save_package_json(path: &Path, v: &Value) {
     if let Ok(data) = serde_json::to_string_pretty(v) {
-        if let Err(e) = fs::write(path, data) {
+        if let Err(e) = crate::audit::write(path, data) {
             eprintln!("Erro ao salvar package.json: {e}");
         }
     }
 }
 
-fn list_node(dir: &Path) {
+fn list_node(dir: &Path) -> Vec<(String, String)> {
     let path = node_package_json(dir);
     let v = load_package_json(&path);
-    if let Some(obj) = v.get("devDependencies").and_then(|d| d.as_object()) {
-        for (k, v) in obj {
-            if let Some(ver) = v.as_str() {
-                println!("- {k} = {ver}");
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+    let Some(obj) = v.get("devDependencies").and_then(|d| d.as_object()) else { return Vec::new() };
+    obj.iter().filter_map(|(k, v)| v.as_str().map(|ver| (k.clone(), ver.to_string()))).collect()
 }
 
 fn add_node(dir: &Path, name: String, version: Option<String>) {
@@ -188,11 +330,12 @@ fn add_node(dir: &Path, name: String, version: Option<String>) {
     }
 }
 
+fn node_url(name: &str) -> String {
+    format!("https://registry.npmjs.org/{}/latest", name)
+}
+
 fn fetch_latest_node(name: &str) -> Option<String> {
-    let url = format!("https://registry.npmjs.org/{}/latest", name);
-    reqwest::blocking::get(url)
-        .ok()?
-        .json::<Value>()
+    serde_json::from_str::<Value>(&crate::registry::get(&node_url(name)).ok()?)
         .ok()?
         .get("version")
         .and_then(|v| v.as_str())
@@ -214,6 +357,7 @@ fn update_node(dir: &Path, name: Option<String>) {
                 println!("Dependência '{n}' atualizada.");
             }
         } else {
+            prefetch(map.keys().map(|k| node_url(k)).collect());
             for (k, val) in map.iter_mut() {
                 if let Some(latest) = fetch_latest_node(k) {
                     *val = Value::String(latest);
@@ -245,6 +389,7 @@ fn get_node_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     let v = load_package_json(&path);
     let mut deps = Vec::new();
     if let Some(obj) = v.get("devDependencies").and_then(|d| d.as_object()) {
+        prefetch(obj.keys().map(|k| node_url(k)).collect());
         for (k, v) in obj {
             if let Some(ver) = v.as_str() {
                 let latest = fetch_latest_node(k);
@@ -261,32 +406,47 @@ fn get_node_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     deps
 }
 
-// Rust helpers
+// Rust helpers (workspace members, locked versions and features read by cargo_build)
 fn cargo_toml(path: &Path) -> PathBuf {
     path.join("Cargo.toml")
 }
 
-fn load_cargo_toml(path: &Path) -> Document {
+fn load_cargo_toml(path: &Path) -> DocumentMut {
     let data = fs::read_to_string(path).unwrap_or_default();
-    data.parse::<Document>().unwrap_or_default()
+    data.parse::<DocumentMut>().unwrap_or_default()
 }
 
-fn save_cargo_toml(path: &Path, doc: &Document) {
-    if let Err(e) = fs::write(path, doc.to_string()) {
+fn save_cargo_toml(path: &Path, doc: &DocumentMut) {
+    if let Err(e) = crate::audit::write(path, doc.to_string()) {
         eprintln!("Erro ao salvar Cargo.toml: {e}");
     }
 }
 
-fn list_rust(dir: &Path) {
-    let path = cargo_toml(dir);
-    let doc = load_cargo_toml(&path);
-    if let Some(table) = doc.get("dev-dependencies").and_then(|t| t.as_table()) {
-        for (k, v) in table.iter() {
-            println!("- {} = {}", k, v.as_value().map(|v| v.to_string()).unwrap_or_default());
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+/// The development dependencies of the packages of `dir` (every member of a workspace root), once per
+/// name and version: the version Cargo.lock holds, else the requirement.
+fn rust_dev_dependencies(dir: &Path) -> Vec<crate::cargo_build::CargoDependency> {
+    let mut seen = std::collections::HashSet::new();
+    crate::cargo_build::dependencies(dir)
+        .into_iter()
+        .filter(|d| d.kind == "dev" && seen.insert((d.name.clone(), d.version.clone(), d.requirement.clone())))
+        .collect()
+}
+
+fn rust_version(d: &crate::cargo_build::CargoDependency) -> String {
+    d.version.clone().or_else(|| d.requirement.clone()).or_else(|| d.local.clone()).unwrap_or_else(|| "?".to_string())
+}
+
+fn list_rust(dir: &Path) -> Vec<Declared> {
+    rust_dev_dependencies(dir)
+        .into_iter()
+        .map(|d| {
+            let mut features = d.features.clone();
+            if d.no_default_features {
+                features.insert(0, "sem default".to_string());
+            }
+            Declared { version: rust_version(&d), name: d.name, features }
+        })
+        .collect()
 }
 
 fn add_rust(dir: &Path, name: String, version: Option<String>) {
@@ -298,16 +458,18 @@ fn add_rust(dir: &Path, name: String, version: Option<String>) {
         .or_insert(toml_edit::Item::Table(Default::default()))
         .as_table_mut()
         .unwrap();
-    tbl.insert(name.clone(), value(version.unwrap_or("*".into())));
+    tbl.insert(&name, value(version.unwrap_or("*".into())));
     save_cargo_toml(&path, &doc);
     println!("Dependência '{name}' adicionada.");
 }
 
+/// Crate registry (setting `crates_registry`) that serves the latest versions.
+fn crate_url(name: &str) -> String {
+    format!("{}/api/v1/crates/{}", crate::settings::get("crates_registry").trim_end_matches('/'), name)
+}
+
 fn fetch_latest_crate(name: &str) -> Option<String> {
-    let url = format!("https://crates.io/api/v1/crates/{}", name);
-    reqwest::blocking::get(url)
-        .ok()?
-        .json::<Value>()
+    serde_json::from_str::<Value>(&crate::registry::get(&crate_url(name)).ok()?)
         .ok()?
         .get("crate")
         .and_then(|c| c.get("max_stable_version"))
@@ -325,9 +487,10 @@ fn update_rust(dir: &Path, name: Option<String>) {
                 println!("Dependência '{n}' atualizada.");
             }
         } else {
+            prefetch(table.iter().map(|(k, _)| crate_url(k)).collect());
             for (k, item) in table.iter_mut() {
-                if let Some(latest) = fetch_latest_crate(k) {
-                    *item.value_mut() = value(latest);
+                if let Some(latest) = fetch_latest_crate(k.get()) {
+                    *item = value(latest);
                 }
             }
             println!("Todas as dependências atualizadas.");
@@ -346,24 +509,32 @@ fn delete_rust(dir: &Path, name: String) {
     save_cargo_toml(&path, &doc);
 }
 
+/// The update takes `cargo update --precise` while the requirement accepts the latest version, else
+/// a new requirement with `cargo add` (in the package that declares it).
 fn get_rust_dependencies(dir: &Path) -> Vec<DependencyInfo> {
-    let path = cargo_toml(dir);
-    let doc = load_cargo_toml(&path);
-    let mut deps = Vec::new();
-    if let Some(table) = doc.get("dev-dependencies").and_then(|t| t.as_table()) {
-        for (k, v) in table.iter() {
-            let ver = v.as_value().map(|v| v.to_string()).unwrap_or_default();
-            let latest = fetch_latest_crate(k);
-            deps.push(DependencyInfo {
-                name: k.to_string(),
-                current_version: ver.clone(),
-                latest_version: latest.clone(),
-                update_command: format!("cargo update -p {} --precise {}", k, latest.clone().unwrap_or_default()),
-                url: format!("https://crates.io/crates/{}", k),
-            });
-        }
-    }
-    deps
+    let parsed: Vec<_> = rust_dev_dependencies(dir).into_iter().filter(|d| d.local.is_none()).collect();
+    prefetch(parsed.iter().map(|d| crate_url(&d.name)).collect());
+    let single = parsed.iter().all(|d| d.source == "Cargo.toml");
+    parsed
+        .into_iter()
+        .map(|d| {
+            let latest = fetch_latest_crate(&d.name);
+            let update_command = match (&latest, &d.requirement) {
+                (Some(latest), Some(requirement)) if crate::cargo_build::matches(requirement, latest) => {
+                    format!("cargo update -p {} --precise {}", d.name, latest)
+                }
+                _ if single => format!("cargo add {}@{} --dev", d.name, latest.clone().unwrap_or_else(|| "latest".to_string())),
+                _ => format!("cargo add {}@{} --dev -p {}", d.name, latest.clone().unwrap_or_else(|| "latest".to_string()), d.package),
+            };
+            DependencyInfo {
+                current_version: rust_version(&d),
+                latest_version: latest,
+                update_command,
+                url: format!("https://crates.io/crates/{}", d.name),
+                name: d.name,
+            }
+        })
+        .collect()
 }
 
 // Python helpers
@@ -375,7 +546,7 @@ fn requirements_path(dir: &Path) -> PathBuf {
     }
 }
 
-fn parse_requirements(content: &str) -> BTreeMap<String, String> {
+pub(crate) fn parse_requirements(content: &str) -> BTreeMap<String, String> {
     let mut map = BTreeMap::new();
     for line in content.lines() {
         let line = line.trim();
@@ -400,25 +571,14 @@ fn write_requirements(path: &Path, map: &BTreeMap<String, String>) {
             out.push_str(&format!("{}=={}\n", k, v));
         }
     }
-    if let Err(e) = fs::write(path, out) {
+    if let Err(e) = crate::audit::write(path, out) {
         eprintln!("Erro ao salvar requirements: {e}");
     }
 }
 
-fn list_python(dir: &Path) {
+fn list_python(dir: &Path) -> Vec<(String, String)> {
     let path = requirements_path(dir);
-    if let Ok(data) = fs::read_to_string(&path) {
-        let map = parse_requirements(&data);
-        if map.is_empty() {
-            println!("Nenhuma dependência encontrada.");
-        } else {
-            for (k, v) in map {
-                println!("- {} = {}", k, v);
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+    fs::read_to_string(&path).map(|data| parse_requirements(&data).into_iter().collect()).unwrap_or_default()
 }
 
 fn add_python(dir: &Path, name: String, version: Option<String>) {
@@ -433,11 +593,12 @@ fn add_python(dir: &Path, name: String, version: Option<String>) {
     println!("Dependência '{name}' adicionada.");
 }
 
+fn pypi_url(name: &str) -> String {
+    format!("https://pypi.org/pypi/{}/json", name)
+}
+
 fn fetch_latest_pypi(name: &str) -> Option<String> {
-    let url = format!("https://pypi.org/pypi/{}/json", name);
-    reqwest::blocking::get(url)
-        .ok()?
-        .json::<Value>()
+    serde_json::from_str::<Value>(&crate::registry::get(&pypi_url(name)).ok()?)
         .ok()?
         .get("info")
         .and_then(|i| i.get("version"))
@@ -455,6 +616,7 @@ fn update_python(dir: &Path, name: Option<String>) {
                 println!("Dependência '{n}' atualizada.");
             }
         } else {
+            prefetch(map.keys().map(|k| pypi_url(k)).collect());
             for (k, v) in map.iter_mut() {
                 if let Some(latest) = fetch_latest_pypi(k) {
                     *v = latest;
@@ -481,6 +643,7 @@ fn get_python_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     let mut deps = Vec::new();
     if let Ok(data) = fs::read_to_string(&path) {
         let map = parse_requirements(&data);
+        prefetch(map.keys().map(|k| pypi_url(k)).collect());
         for (k, v) in map {
             let latest = fetch_latest_pypi(&k);
             deps.push(DependencyInfo {
@@ -500,7 +663,7 @@ fn go_mod_path(dir: &Path) -> PathBuf {
     dir.join("go.mod")
 }
 
-fn parse_go_mod(data: &str) -> BTreeMap<String, String> {
+pub(crate) fn parse_go_mod(data: &str) -> BTreeMap<String, String> {
     let mut map = BTreeMap::new();
     let mut in_block = false;
     for line in data.lines() {
@@ -530,27 +693,17 @@ fn parse_go_mod(data: &str) -> BTreeMap<String, String> {
     map
 }
 
-fn list_go(dir: &Path) {
+fn list_go(dir: &Path) -> Vec<(String, String)> {
     let path = go_mod_path(dir);
-    if let Ok(data) = fs::read_to_string(&path) {
-        let map = parse_go_mod(&data);
-        if map.is_empty() {
-            println!("Nenhuma dependência encontrada.");
-        } else {
-            for (k, v) in map {
-                println!("- {} = {}", k, v);
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+    fs::read_to_string(&path).map(|data| parse_go_mod(&data).into_iter().collect()).unwrap_or_default()
+}
+
+fn go_url(name: &str) -> String {
+    format!("https://proxy.golang.org/{}/@latest", name)
 }
 
 fn fetch_latest_go(name: &str) -> Option<String> {
-    let url = format!("https://proxy.golang.org/{}/@latest", name);
-    reqwest::blocking::get(url)
-        .ok()?
-        .json::<Value>()
+    serde_json::from_str::<Value>(&crate::registry::get(&go_url(name)).ok()?)
         .ok()?
         .get("Version")
         .and_then(|v| v.as_str())
@@ -574,6 +727,7 @@ fn get_go_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     let mut deps = Vec::new();
     if let Ok(data) = fs::read_to_string(&path) {
         let map = parse_go_mod(&data);
+        prefetch(map.keys().map(|k| go_url(k)).collect());
         for (k, v) in map {
             let latest = fetch_latest_go(&k);
             deps.push(DependencyInfo {
@@ -588,30 +742,19 @@ fn get_go_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     deps
 }
 
-// Maven helpers
-fn pom_xml_path(dir: &Path) -> PathBuf {
-    dir.join("pom.xml")
+// Maven and Gradle helpers (versions resolved by java_build: properties, parents, BOMs, catalogs)
+
+/// The test dependencies of the Java build in `dir`, across its modules, once per name and version.
+fn java_test_dependencies(dir: &Path) -> Vec<crate::java_build::JavaDependency> {
+    let mut seen = std::collections::HashSet::new();
+    crate::java_build::dependencies(dir)
+        .into_iter()
+        .filter(|d| d.is_test() && seen.insert((d.name(), d.version.clone())))
+        .collect()
 }
 
-fn parse_maven_deps(data: &str) -> Vec<(String, String, String)> {
-    let mut deps = Vec::new();
-    let mut rest = data;
-    while let Some(start) = rest.find("<dependency>") {
-        rest = &rest[start + "<dependency>".len()..];
-        if let Some(end) = rest.find("</dependency>") {
-            let block = &rest[..end];
-            rest = &rest[end + "</dependency>".len()..];
-            if block.contains("<scope>test</scope>") {
-                let group = extract_between(block, "<groupId>", "</groupId>").unwrap_or_default();
-                let artifact = extract_between(block, "<artifactId>", "</artifactId>").unwrap_or_default();
-                let version = extract_between(block, "<version>", "</version>").unwrap_or_default();
-                deps.push((group.to_string(), artifact.to_string(), version.to_string()));
-            }
-        } else {
-            break;
-        }
-    }
-    deps
+fn list_java(dir: &Path) -> Vec<(String, String)> {
+    java_test_dependencies(dir).into_iter().map(|d| (d.name(), d.version.unwrap_or_else(|| "?".to_string()))).collect()
 }
 
 fn extract_between<'a>(hay: &'a str, start: &str, end: &str) -> Option<&'a str> {
@@ -620,26 +763,12 @@ fn extract_between<'a>(hay: &'a str, start: &str, end: &str) -> Option<&'a str>
     Some(&hay[s..e])
 }
 
-fn list_maven(dir: &Path) {
-    let path = pom_xml_path(dir);
-    if let Ok(data) = fs::read_to_string(&path) {
-        let deps = parse_maven_deps(&data);
-        if deps.is_empty() {
-            println!("Nenhuma dependência encontrada.");
-        } else {
-            for (g, a, v) in deps {
-                println!("- {}:{} = {}", g, a, v);
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+fn maven_url(group: &str, artifact: &str) -> String {
+    format!("{}/{}/{}/maven-metadata.xml", crate::java_build::repository(), group.replace('.', "/"), artifact)
 }
 
 fn fetch_latest_maven(group: &str, artifact: &str) -> Option<String> {
-    let path = group.replace('.', "/");
-    let url = format!("https://repo1.maven.org/maven2/{}/{}/maven-metadata.xml", path, artifact);
-    let text = reqwest::blocking::get(url).ok()?.text().ok()?;
+    let text = crate::registry::get(&maven_url(group, artifact)).ok()?;
     extract_between(&text, "<latest>", "</latest>")
         .or_else(|| extract_between(&text, "<release>", "</release>"))
         .map(|s| s.to_string())
@@ -657,90 +786,6 @@ fn delete_maven(_dir: &Path, _name: String) {
     println!("Operação não suportada para Maven.");
 }
 
-fn get_maven_dependencies(dir: &Path) -> Vec<DependencyInfo> {
-    let path = pom_xml_path(dir);
-    let mut deps = Vec::new();
-    if let Ok(data) = fs::read_to_string(&path) {
-        for (g, a, v) in parse_maven_deps(&data) {
-            let latest = fetch_latest_maven(&g, &a);
-            let name = format!("{}:{}", g, a);
-            deps.push(DependencyInfo {
-                name: name.clone(),
-                current_version: v.clone(),
-                latest_version: latest.clone(),
-                update_command: format!("mvn dependency:get -Dartifact={}:{}:LATEST", g, a),
-                url: format!("https://search.maven.org/artifact/{}/{}", g, a),
-            });
-        }
-    }
-    deps
-}
-
-// Gradle helpers
-fn gradle_build_path(dir: &Path) -> PathBuf {
-    if dir.join("build.gradle.kts").exists() {
-        dir.join("build.gradle.kts")
-    } else {
-        dir.join("build.gradle")
-    }
-}
-
-fn parse_gradle_deps(data: &str) -> Vec<(String, String, String)> {
-    let mut deps = Vec::new();
-    let mut in_block = false;
-    for line in data.lines() {
-        let l = line.trim();
-        if l.starts_with("dependencies") {
-            in_block = true;
-            continue;
-        }
-        if in_block && l.starts_with('}') {
-            in_block = false;
-            continue;
-        }
-        if in_block {
-            let configs = [
-                "testImplementation",
-                "testCompile",
-                "testRuntimeOnly",
-                "testCompileOnly",
-            ];
-            for cfg in configs {
-                if l.starts_with(cfg) {
-                    if let Some(start) = l.find("'").or_else(|| l.find("\"")) {
-                        let quote = l.chars().nth(start).unwrap();
-                        if let Some(end) = l[start + 1..].find(quote) {
-                            let dep = &l[start + 1..start + 1 + end];
-                            let mut parts = dep.split(':');
-                            let g = parts.next().unwrap_or("").to_string();
-                            let a = parts.next().unwrap_or("").to_string();
-                            let v = parts.next().unwrap_or("").to_string();
-                            deps.push((g, a, v));
-                        }
-                    }
-                }
-            }
-        }
-    }
-    deps
-}
-
-fn list_gradle(dir: &Path) {
-    let path = gradle_build_path(dir);
-    if let Ok(data) = fs::read_to_string(&path) {
-        let deps = parse_gradle_deps(&data);
-        if deps.is_empty() {
-            println!("Nenhuma dependência encontrada.");
-        } else {
-            for (g, a, v) in deps {
-                println!("- {}:{} = {}", g, a, v);
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
-}
-
 fn add_gradle(_dir: &Path, _name: String, _version: Option<String>) {
     println!("Operação não suportada para Gradle.");
 }
@@ -753,23 +798,25 @@ fn delete_gradle(_dir: &Path, _name: String) {
     println!("Operação não suportada para Gradle.");
 }
 
-fn get_gradle_dependencies(dir: &Path) -> Vec<DependencyInfo> {
-    let path = gradle_build_path(dir);
-    let mut deps = Vec::new();
-    if let Ok(data) = fs::read_to_string(&path) {
-        for (g, a, v) in parse_gradle_deps(&data) {
-            let latest = fetch_latest_maven(&g, &a);
-            let name = format!("{}:{}", g, a);
-            deps.push(DependencyInfo {
-                name: name.clone(),
-                current_version: v.clone(),
-                latest_version: latest.clone(),
-                update_command: "./gradlew --refresh-dependencies".into(),
-                url: format!("https://search.maven.org/artifact/{}/{}", g, a),
-            });
-        }
-    }
-    deps
+fn get_java_dependencies(dir: &Path, stack: Stack) -> Vec<DependencyInfo> {
+    let parsed = java_test_dependencies(dir);
+    prefetch(parsed.iter().map(|d| maven_url(&d.group, &d.artifact)).collect());
+    parsed
+        .into_iter()
+        .map(|d| {
+            let update_command = match stack {
+                Stack::Gradle => "./gradlew --refresh-dependencies".to_string(),
+                _ => format!("mvn dependency:get -Dartifact={}:LATEST", d.name()),
+            };
+            DependencyInfo {
+                name: d.name(),
+                current_version: d.version.clone().unwrap_or_else(|| "?".to_string()),
+                latest_version: fetch_latest_maven(&d.group, &d.artifact),
+                update_command,
+                url: format!("https://search.maven.org/artifact/{}/{}", d.group, d.artifact),
+            }
+        })
+        .collect()
 }
 
 // PHP helpers
@@ -784,22 +831,15 @@ fn load_composer_json(path: &Path) -> Value {
 
 fn save_composer_json(path: &Path, v: &Value) {
     if let Ok(data) = serde_json::to_string_pretty(v) {
-        let _ = fs::write(path, data);
+        let _ = crate::audit::write(path, data);
     }
 }
 
-fn list_php(dir: &Path) {
+fn list_php(dir: &Path) -> Vec<(String, String)> {
     let path = composer_json_path(dir);
     let v = load_composer_json(&path);
-    if let Some(obj) = v.get("require-dev").and_then(|d| d.as_object()) {
-        for (k, v) in obj {
-            if let Some(ver) = v.as_str() {
-                println!("- {} = {}", k, ver);
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+    let Some(obj) = v.get("require-dev").and_then(|d| d.as_object()) else { return Vec::new() };
+    obj.iter().filter_map(|(k, v)| v.as_str().map(|ver| (k.clone(), ver.to_string()))).collect()
 }
 
 fn add_php(dir: &Path, name: String, version: Option<String>) {
@@ -817,9 +857,12 @@ fn add_php(dir: &Path, name: String, version: Option<String>) {
     }
 }
 
+fn packagist_url(name: &str) -> String {
+    format!("https://repo.packagist.org/p2/{}.json", name)
+}
+
 fn fetch_latest_packagist(name: &str) -> Option<String> {
-    let url = format!("https://repo.packagist.org/p2/{}.json", name);
-    let v = reqwest::blocking::get(url).ok()?.json::<Value>().ok()?;
+    let v: Value = serde_json::from_str(&crate::registry::get(&packagist_url(name)).ok()?).ok()?;
     v.get("packages")?.as_object()?.get(name)?.get(0)?.get("version")?.as_str().map(|s| s.trim_start_matches('v').to_string())
 }
 
@@ -833,6 +876,7 @@ fn update_php(dir: &Path, name: Option<String>) {
                 println!("Dependência '{n}' atualizada.");
             }
         } else {
+            prefetch(map.keys().map(|k| packagist_url(k)).collect());
             for (k, val) in map.iter_mut() {
                 if let Some(latest) = fetch_latest_packagist(k) {
                     *val = Value::String(latest);
@@ -863,6 +907,7 @@ fn get_php_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     let mut deps = Vec::new();
     let v = load_composer_json(&path);
     if let Some(map) = v.get("require-dev").and_then(|d| d.as_object()) {
+        prefetch(map.keys().map(|k| packagist_url(k)).collect());
         for (k, val) in map {
             if let Some(ver) = val.as_str() {
                 let latest = fetch_latest_packagist(k);
@@ -920,20 +965,9 @@ fn parse_gemfile(data: &str) -> BTreeMap<String, String> {
     map
 }
 
-fn list_ruby(dir: &Path) {
+fn list_ruby(dir: &Path) -> Vec<(String, String)> {
     let path = gemfile_path(dir);
-    if let Ok(data) = fs::read_to_string(&path) {
-        let map = parse_gemfile(&data);
-        if map.is_empty() {
-            println!("Nenhuma dependência encontrada.");
-        } else {
-            for (k, v) in map {
-                println!("- {} = {}", k, v);
-            }
-        }
-    } else {
-        println!("Nenhuma dependência encontrada.");
-    }
+    fs::read_to_string(&path).map(|data| parse_gemfile(&data).into_iter().collect()).unwrap_or_default()
 }
 
 fn add_ruby(_dir: &Path, _name: String, _version: Option<String>) {
@@ -948,11 +982,12 @@ fn delete_ruby(_dir: &Path, _name: String) {
     println!("Operação não suportada para Ruby.");
 }
 
+fn rubygems_url(name: &str) -> String {
+    format!("https://rubygems.org/api/v1/gems/{}.json", name)
+}
+
 fn fetch_latest_ruby(name: &str) -> Option<String> {
-    let url = format!("https://rubygems.org/api/v1/gems/{}.json", name);
-    reqwest::blocking::get(url)
-        .ok()?
-        .json::<Value>()
+    serde_json::from_str::<Value>(&crate::registry::get(&rubygems_url(name)).ok()?)
         .ok()?
         .get("version")
         .and_then(|v| v.as_str())
@@ -963,7 +998,9 @@ fn get_ruby_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     let path = gemfile_path(dir);
     let mut deps = Vec::new();
     if let Ok(data) = fs::read_to_string(&path) {
-        for (k, v) in parse_gemfile(&data) {
+        let gems = parse_gemfile(&data);
+        prefetch(gems.keys().map(|k| rubygems_url(k)).collect());
+        for (k, v) in gems {
             let latest = fetch_latest_ruby(&k);
             deps.push(DependencyInfo {
                 name: k.clone(),
@@ -976,3 +1013,128 @@ fn get_ruby_dependencies(dir: &Path) -> Vec<DependencyInfo> {
     }
     deps
 }
+
+// .NET helpers (versions resolved by dotnet_build: lock files, central package management)
+
+/// The development-only packages of the .NET build in `dir` (`PrivateAssets="all"` or referenced by a
+/// test project), across its projects, once per name and version.
+fn dotnet_dev_dependencies(dir: &Path) -> Vec<crate::dotnet_build::NuGetPackage> {
+    let mut seen = std::collections::HashSet::new();
+    crate::dotnet_build::dependencies(dir)
+        .into_iter()
+        .filter(|p| p.dev && seen.insert((p.name.clone(), p.version.clone())))
+        .collect()
+}
+
+fn list_dotnet(dir: &Path) -> Vec<(String, String)> {
+    dotnet_dev_dependencies(dir).into_iter().map(|p| (p.name, p.version.unwrap_or_else(|| "?".to_string()))).collect()
+}
+
+fn nuget_url(name: &str) -> String {
+    format!("{}/{}/index.json", crate::dotnet_build::feed(), name.to_lowercase())
+}
+
+/// Latest stable version in the feed (versions are listed in ascending order; prereleases have a `-`).
+fn fetch_latest_nuget(name: &str) -> Option<String> {
+    let index: Value = serde_json::from_str(&crate::registry::get(&nuget_url(name)).ok()?).ok()?;
+    index["versions"].as_array()?.iter().filter_map(|v| v.as_str()).filter(|v| !v.contains('-')).last().map(str::to_string)
+}
+
+fn add_dotnet(_dir: &Path, _name: String, _version: Option<String>) {
+    println!("Operação não suportada para .NET. Use: dotnet add <projeto> package <pacote>");
+}
+
+fn update_dotnet(_dir: &Path, _name: Option<String>) {
+    println!("Operação não suportada para .NET. Use: dotnet add <projeto> package <pacote> (instala a versão mais recente)");
+}
+
+fn delete_dotnet(_dir: &Path, _name: String) {
+    println!("Operação não suportada para .NET. Use: dotnet remove <projeto> package <pacote>");
+}
+
+fn get_dotnet_dependencies(dir: &Path) -> Vec<DependencyInfo> {
+    let parsed = dotnet_dev_dependencies(dir);
+    prefetch(parsed.iter().map(|p| nuget_url(&p.name)).collect());
+    parsed
+        .into_iter()
+        .map(|p| DependencyInfo {
+            latest_version: fetch_latest_nuget(&p.name),
+            current_version: p.version.clone().unwrap_or_else(|| "?".to_string()),
+            update_command: format!("dotnet add {} package {}", p.source, p.name),
+            url: format!("https://www.nuget.org/packages/{}", p.name),
+            name: p.name,
+        })
+        .collect()
+}
+
+// Elixir and Erlang helpers (packages and locked versions read by beam_build)
+
+/// The development-only Hex packages of mix.exs (`only: [:dev, :test]`) or rebar.config (dev and test
+/// profiles).
+fn hex_dev_dependencies(dir: &Path) -> Vec<crate::beam_build::HexPackage> {
+    crate::beam_build::dependencies(dir).into_iter().filter(|p| p.dev).collect()
+}
+
+fn list_hex(dir: &Path) -> Vec<(String, String)> {
+    hex_dev_dependencies(dir)
+        .into_iter()
+        .map(|p| {
+            let version = p.version.or(p.requirement).or(p.local).unwrap_or_else(|| "?".to_string());
+            (p.name, version)
+        })
+        .collect()
+}
+
+/// Hex repository (setting `hex_registry`) that serves the latest versions.
+fn hex_url(name: &str) -> String {
+    format!("{}/api/packages/{}", crate::settings::get("hex_registry").trim_end_matches('/'), name)
+}
+
+fn fetch_latest_hex(name: &str) -> Option<String> {
+    serde_json::from_str::<Value>(&crate::registry::get(&hex_url(name)).ok()?)
+        .ok()?
+        .get("latest_stable_version")
+        .and_then(|v| v.as_str())
+        .map(|s| s.to_string())
+}
+
+fn add_hex(stack: Stack) {
+    println!(
+        "Operação não suportada para Elixir/Erlang. Declare o pacote em {} e rode: {}",
+        if stack == Stack::Elixir { "deps/0 do mix.exs" } else { "deps do rebar.config" },
+        if stack == Stack::Elixir { "mix deps.get" } else { "rebar3 get-deps" }
+    );
+}
+
+fn update_hex(stack: Stack) {
+    println!(
+        "Operação não suportada para Elixir/Erlang. Use: {}",
+        if stack == Stack::Elixir { "mix deps.update <pacote>" } else { "rebar3 upgrade <pacote>" }
+    );
+}
+
+fn delete_hex(stack: Stack) {
+    println!(
+        "Operação não suportada para Elixir/Erlang. Remova o pacote de {} e rode: {}",
+        if stack == Stack::Elixir { "deps/0 do mix.exs" } else { "deps do rebar.config" },
+        if stack == Stack::Elixir { "mix deps.unlock --unused" } else { "rebar3 unlock <pacote>" }
+    );
+}
+
+fn get_hex_dependencies(dir: &Path, stack: Stack) -> Vec<DependencyInfo> {
+    let packages: Vec<_> = hex_dev_dependencies(dir).into_iter().filter(|p| p.local.is_none()).collect();
+    prefetch(packages.iter().map(|p| hex_url(&p.name)).collect());
+    packages
+        .into_iter()
+        .map(|p| DependencyInfo {
+            latest_version: fetch_latest_hex(&p.name),
+            current_version: p.version.clone().or(p.requirement.clone()).unwrap_or_else(|| "?".to_string()),
+            update_command: match stack {
+                Stack::Elixir => format!("mix deps.update {}", p.name),
+                _ => format!("rebar3 upgrade {}", p.name),
+            },
+            url: format!("https://hex.pm/packages/{}", p.name),
+            name: p.name,
+        })
+        .collect()
+}
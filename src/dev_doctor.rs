@@ -0,0 +1,258 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::net::TcpListener;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum DoctorFormat {
+    /// Uma linha por verificação, com a correção sugerida
+    Text,
+    /// Array JSON de verificações (para CI e IDEs)
+    Json,
+}
+
+/// Port the application listens on when the code does not say otherwise.
+const DEFAULT_APP_PORT: u16 = 8080;
+/// Variables whose default is taken as the application's port.
+const PORT_VARS: &[&str] = &["PORT", "HTTP_PORT", "SERVER_PORT", "APP_PORT"];
+
+/// Result of one check of the developer machine.
+#[derive(Debug, Clone)]
+pub struct Check {
+    /// "runtime", "docker", "port" or "env"
+    pub category: &'static str,
+    pub name: String,
+    pub ok: bool,
+    /// What was found (version, process holding a port, ...)
+    pub detail: String,
+    /// How to fix a failed check
+    pub fix: Option<String>,
+}
+
+impl Check {
+    fn ok(category: &'static str, name: impl Into<String>, detail: impl Into<String>) -> Check {
+        Check { category, name: name.into(), ok: true, detail: detail.into(), fix: None }
+    }
+
+    fn failed(category: &'static str, name: impl Into<String>, detail: impl Into<String>, fix: impl Into<String>) -> Check {
+        Check { category, name: name.into(), ok: false, detail: detail.into(), fix: Some(fix.into()) }
+    }
+}
+
+/// A runtime the project needs, with the command that reports its version.
+struct Runtime {
+    name: &'static str,
+    program: &'static str,
+    args: &'static [&'static str],
+    install: &'static str,
+}
+
+const GO: Runtime = Runtime { name: "Go", program: "go", args: &["version"], install: "instale o Go em https://go.dev/dl/ (ou: brew install go / sdk install go)" };
+const NODE: Runtime = Runtime { name: "Node.js", program: "node", args: &["--version"], install: "instale o Node.js em https://nodejs.org (ou com nvm: nvm install --lts)" };
+const JAVA: Runtime = Runtime { name: "Java", program: "java", args: &["-version"], install: "instale um JDK, ex.: sdk install java 21-tem (SDKMAN) ou https://adoptium.net" };
+const DOTNET: Runtime = Runtime { name: ".NET SDK", program: "dotnet", args: &["--version"], install: "instale o SDK em https://dotnet.microsoft.com/download (ou: ./dotnet-install.sh --jsonfile global.json)" };
+
+/// Runtimes the project's build files call for, with the minimum version they declare (if any).
+fn required_runtimes(project_dir: &Path) -> Vec<(Runtime, Option<String>)> {
+    let mut runtimes = Vec::new();
+    if let Ok(go_mod) = std::fs::read_to_string(project_dir.join("go.mod")) {
+        let wanted = go_mod.lines().find_map(|l| l.trim().strip_prefix("go ").map(|v| v.trim().to_string()));
+        runtimes.push((GO, wanted));
+    }
+    if project_dir.join("package.json").exists() {
+        let wanted = [".nvmrc", ".node-version"]
+            .iter()
+            .find_map(|f| std::fs::read_to_string(project_dir.join(f)).ok())
+            .map(|v| v.trim().trim_start_matches('v').to_string())
+            .filter(|v| v.starts_with(|c: char| c.is_ascii_digit()));
+        runtimes.push((NODE, wanted));
+    }
+    if ["pom.xml", "build.gradle", "build.gradle.kts"].iter().any(|f| project_dir.join(f).exists()) {
+        runtimes.push((JAVA, None));
+    }
+    if crate::dotnet_build::is_project(project_dir) {
+        runtimes.push((DOTNET, crate::dotnet_build::sdk_version(project_dir)));
+    }
+    runtimes
+}
+
+/// First version-looking token of a `--version` output ("go1.22.1", "v20.11.0", "\"21.0.2\"").
+fn parse_version(output: &str) -> Option<String> {
+    output.split_whitespace().find_map(|word| {
+        let v = word.trim_matches('"').trim_start_matches("go").trim_start_matches('v');
+        (v.starts_with(|c: char| c.is_ascii_digit()) && v.contains('.')).then(|| v.to_string())
+    })
+}
+
+fn check_runtime(runtime: &Runtime, wanted: Option<&str>) -> Check {
+    let output = match Command::new(runtime.program).args(runtime.args).output() {
+        Ok(out) if out.status.success() => out,
+        _ => return Check::failed("runtime", runtime.name, format!("'{}' não encontrado no PATH", runtime.program), runtime.install),
+    };
+    // java -version writes to stderr
+    let text = format!("{}{}", String::from_utf8_lossy(&output.stdout), String::from_utf8_lossy(&output.stderr));
+    let version = parse_version(&text).unwrap_or_else(|| "versão desconhecida".to_string());
+    match wanted {
+        Some(wanted) if crate::dependency_audit::version_key(&version) < crate::dependency_audit::version_key(wanted) => Check::failed(
+            "runtime",
+            runtime.name,
+            format!("{} instalado, o projeto pede {}", version, wanted),
+            format!("atualize para {} ou mais recente: {}", wanted, runtime.install),
+        ),
+        _ => Check::ok("runtime", runtime.name, version),
+    }
+}
+
+fn check_docker() -> Check {
+    let output = Command::new("docker").args(["info", "--format", "{{.ServerVersion}}"]).output();
+    match output {
+        Err(_) => Check::failed(
+            "docker",
+            "Docker",
+            "'docker' não encontrado no PATH",
+            "instale o Docker Desktop (https://docs.docker.com/get-docker/) ou o Docker Engine/Podman",
+        ),
+        Ok(out) if !out.status.success() => {
+            let fix = if cfg!(target_os = "linux") {
+                "inicie o daemon: sudo systemctl start docker (e adicione seu usuário ao grupo docker)"
+            } else {
+                "abra o Docker Desktop (ou: colima start) e aguarde o daemon subir"
+            };
+            Check::failed("docker", "Docker", "o daemon não responde (docker info falhou)", fix)
+        }
+        Ok(out) => Check::ok("docker", "Docker", format!("daemon {}", String::from_utf8_lossy(&out.stdout).trim())),
+    }
+}
+
+/// Ports the project needs free: the application's (from the code's PORT default, else 8080), the
+/// detected Dev Services' and the extra ones asked for, each with what uses it.
+fn required_ports(project_dir: &Path, extra: &[u16]) -> Vec<(u16, String)> {
+    let vars = crate::dev_env::scan(project_dir);
+    let app_port = vars
+        .iter()
+        .filter(|v| PORT_VARS.contains(&v.name.as_str()))
+        .find_map(|v| v.default.as_deref()?.trim_matches(|c| c == '"' || c == '\'' || c == ':').parse().ok())
+        .unwrap_or(DEFAULT_APP_PORT);
+    let mut ports = vec![(app_port, "aplicação".to_string())];
+    for (name, service) in crate::dev_services::detect_dependencies(project_dir).services {
+        ports.extend(service.ports.iter().map(|p| (*p, name.clone())));
+    }
+    ports.extend(extra.iter().map(|p| (*p, "--port".to_string())));
+    ports.sort();
+    ports.dedup_by_key(|(p, _)| *p);
+    ports
+}
+
+/// Container publishing `port`, when a Dev Service (or any container) is the one holding it.
+fn container_on(port: u16) -> Option<String> {
+    let out = Command::new("docker")
+        .args(["ps", "--filter", &format!("publish={}", port), "--format", "{{.Names}}"])
+        .output()
+        .ok()?;
+    let name = String::from_utf8_lossy(&out.stdout).lines().next()?.trim().to_string();
+    (out.status.success() && !name.is_empty()).then_some(name)
+}
+
+fn check_port(port: u16, user: &str) -> Check {
+    let name = format!("porta {} ({})", port, user);
+    if TcpListener::bind(("127.0.0.1", port)).is_ok() && TcpListener::bind(("0.0.0.0", port)).is_ok() {
+        return Check::ok("port", name, "livre");
+    }
+    if let Some(container) = container_on(port) {
+        return Check::ok("port", name, format!("em uso pelo container {}", container));
+    }
+    let fix = if cfg!(windows) {
+        format!("veja quem usa a porta com: netstat -ano | findstr :{} e encerre o processo", port)
+    } else {
+        format!("veja quem usa a porta com: lsof -i :{} e encerre o processo (ou mude a porta do serviço)", port)
+    };
+    Check::failed("port", name, "em uso por outro processo", fix)
+}
+
+/// `.env` is needed when the project ships a `.env.example` or the code reads variables without defaults.
+fn check_dotenv(project_dir: &Path) -> Option<Check> {
+    let required: Vec<String> = crate::dev_env::scan(project_dir).into_iter().filter(|v| v.required).map(|v| v.name).collect();
+    let example = project_dir.join(".env.example").exists();
+    let content = std::fs::read_to_string(project_dir.join(".env"));
+    let Ok(content) = content else {
+        if !example && required.is_empty() {
+            return None;
+        }
+        let fix = if example { "crie com: cp .env.example .env (ou: dx dev-env init --env)" } else { "crie com: dx dev-env init --env" };
+        let detail = if required.is_empty() { "ausente".to_string() } else { format!("ausente; o código exige {}", required.join(", ")) };
+        return Some(Check::failed("env", ".env", detail, fix));
+    };
+    let defined = crate::dev_env::dotenv_keys(&content);
+    let missing: Vec<&String> = required.iter().filter(|v| !defined.contains(v)).collect();
+    if missing.is_empty() {
+        return Some(Check::ok("env", ".env", format!("{} variável(is) definida(s)", defined.len())));
+    }
+    Some(Check::failed(
+        "env",
+        ".env",
+        format!("faltam {}", missing.iter().map(|v| v.as_str()).collect::<Vec<_>>().join(", ")),
+        "acrescente as variáveis com: dx dev-env init --env (valores existentes são mantidos)",
+    ))
+}
+
+/// Run every check for the project in `project_dir`.
+pub fn run_checks(project_dir: &Path, extra_ports: &[u16]) -> Vec<Check> {
+    let runtimes = required_runtimes(project_dir);
+    let ports = required_ports(project_dir, extra_ports);
+    let total = runtimes.len() + ports.len() + 2;
+    let phase = crate::progress::Phase::start("dev-doctor", "Verificando o ambiente de desenvolvimento");
+    let mut checks = Vec::new();
+    for (runtime, wanted) in &runtimes {
+        phase.step(checks.len() + 1, total, runtime.name);
+        checks.push(check_runtime(runtime, wanted.as_deref()));
+    }
+    phase.step(checks.len() + 1, total, "Docker");
+    checks.push(check_docker());
+    for (port, user) in &ports {
+        phase.step(checks.len() + 1, total, &format!("porta {}", port));
+        checks.push(check_port(*port, user));
+    }
+    phase.step(total, total, ".env");
+    checks.extend(check_dotenv(project_dir));
+    phase.finish(checks.iter().all(|c| c.ok));
+    checks
+}
+
+fn render_text(project_dir: &Path, checks: &[Check]) -> String {
+    let mut out = format!("dx dev-doctor — {}\n\n", project_dir.display());
+    for check in checks {
+        out.push_str(&format!("{} {}: {}\n", if check.ok { "✓" } else { "✗" }, check.name, check.detail));
+        if let Some(fix) = &check.fix {
+            out.push_str(&format!("    → {}\n", fix));
+        }
+    }
+    let failed = checks.iter().filter(|c| !c.ok).count();
+    if failed == 0 {
+        out.push_str(&format!("\nTudo pronto: {} verificação(ões) ok.\n", checks.len()));
+    } else {
+        out.push_str(&format!("\n{} de {} verificação(ões) falharam.\n", failed, checks.len()));
+    }
+    out
+}
+
+fn render_json(checks: &[Check]) -> String {
+    let items: Vec<serde_json::Value> = checks
+        .iter()
+        .map(|c| serde_json::json!({ "category": c.category, "name": c.name, "ok": c.ok, "detail": c.detail, "fix": c.fix }))
+        .collect();
+    serde_json::to_string_pretty(&items).unwrap_or_default()
+}
+
+/// `dx dev-doctor`: check that this machine can run the project (runtimes, Docker daemon, free
+/// ports, `.env`) and suggest a fix for each failed check. Returns 1 when any check fails.
+pub fn cmd_doctor(dir: Option<PathBuf>, ports: Vec<u16>, format: DoctorFormat) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let checks = run_checks(&project_dir, &ports);
+    match format {
+        DoctorFormat::Text => print!("{}", render_text(&project_dir, &checks)),
+        DoctorFormat::Json => println!("{}", render_json(&checks)),
+    }
+    if checks.iter().all(|c| c.ok) { 0 } else { 1 }
+}
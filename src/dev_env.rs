@@ -0,0 +1,1030 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+const START_MARKER: &str = "<!-- dx-cli:env:start -->";
+const END_MARKER: &str = "<!-- dx-cli:env:end -->";
+
+/// An environment variable read somewhere in the project's source.
+#[derive(Debug, Clone)]
+pub struct EnvVar {
+    pub name: String,
+    /// Default applied by the code when the variable is unset (as written in the source).
+    pub default: Option<String>,
+    /// True when no read site provides a default or treats the variable as optional.
+    pub required: bool,
+    /// Dev service the variable configures ("mongodb", "kafka", ...) or "aplicação".
+    pub service: String,
+    /// Read sites as "relative/path:line".
+    pub locations: Vec<String>,
+}
+
+/// Expressions that read an environment variable; the name follows as a string literal
+/// (or, for `process.env.`, as an identifier).
+const READ_PATTERNS: &[&str] = &[
+    // Go
+    "os.Getenv(",
+    "os.LookupEnv(",
+    "getEnv(",
+    "getEnvOrDefault(",
+    // Node.js
+    "process.env.",
+    "process.env[",
+    // Python
+    "os.getenv(",
+    "os.environ.get(",
+    "os.environ[",
+    // Rust
+    "env::var(",
+    "env::var_os(",
+    // Java / Kotlin
+    "System.getenv(",
+    // Ruby
+    "ENV.fetch(",
+    "ENV[",
+    // PHP
+    "$_ENV[",
+    "$_SERVER[",
+    // Elixir
+    "System.get_env(",
+    "System.fetch_env!(",
+    // Erlang
+    "os:getenv(",
+];
+
+/// Reads that never fail when the variable is unset, even without a literal default.
+const OPTIONAL_PATTERNS: &[&str] = &["os.LookupEnv(", "env::var_os("];
+
+const SOURCE_EXTENSIONS: &[&str] = &[
+    "go", "js", "jsx", "ts", "tsx", "mjs", "cjs", "py", "rs", "java", "kt", "rb", "php", "ex", "exs", "erl",
+    "properties", "yml", "yaml",
+];
+
+const SKIP_DIRS: &[&str] = &[
+    "node_modules", "target", "build", "dist", "vendor", ".git", ".github", ".idea", ".vscode", ".dx",
+    "venv", ".venv", "__pycache__", "_build", "deps",
+];
+
+/// Output of `dx dev-env scan`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum ScanFormat {
+    /// Tables of required and optional variables
+    Text,
+    /// JSON array with every variable
+    Json,
+}
+
+/// How a read site treats a missing variable.
+enum Fallback {
+    Default(String),
+    Optional,
+}
+
+/// Walk the project and collect every environment variable read by the source code.
+pub fn scan(project_dir: &Path) -> Vec<EnvVar> {
+    let mut files = Vec::new();
+    collect_source_files(project_dir, &mut files);
+    files.sort();
+
+    let services: Vec<String> = crate::dev_services::detect_dependencies(project_dir)
+        .services
+        .keys()
+        .cloned()
+        .collect();
+
+    let mut vars: BTreeMap<String, EnvVar> = BTreeMap::new();
+    let phase = crate::progress::Phase::start("dev-env.scan", "Procurando leituras de variáveis de ambiente");
+    let total = files.len();
+    for (i, file) in files.into_iter().enumerate() {
+        let rel = file.strip_prefix(project_dir).unwrap_or(&file).to_string_lossy().replace('\\', "/");
+        phase.step(i + 1, total, &rel);
+        let Ok(content) = fs::read_to_string(&file) else { continue };
+        let ext = file.extension().and_then(|e| e.to_str());
+        let config_file = matches!(ext, Some("properties" | "yml" | "yaml"));
+        let lines: Vec<&str> = content.lines().collect();
+        for (idx, line) in lines.iter().enumerate() {
+            let mut reads = reads_in_line(line, &lines[idx + 1..]);
+            if config_file {
+                reads.extend(spring_placeholders(line));
+            }
+            if ext == Some("go") {
+                reads.extend(go_struct_tag(line));
+            }
+            for (name, fallback) in reads {
+                let var = vars.entry(name.clone()).or_insert_with(|| EnvVar {
+                    service: service_for(&name, &services),
+                    name,
+                    default: None,
+                    required: true,
+                    locations: Vec::new(),
+                });
+                match fallback {
+                    Some(Fallback::Default(value)) => {
+                        var.required = false;
+                        var.default.get_or_insert(value);
+                    }
+                    Some(Fallback::Optional) => var.required = false,
+                    None => {}
+                }
+                let location = format!("{}:{}", rel, idx + 1);
+                if !var.locations.contains(&location) {
+                    var.locations.push(location);
+                }
+            }
+        }
+    }
+    phase.finish(true);
+    vars.into_values().collect()
+}
+
+pub(crate) fn collect_source_files(dir: &Path, out: &mut Vec<PathBuf>) {
+    let Ok(entries) = fs::read_dir(dir) else { return };
+    for entry in entries.flatten() {
+        let path = entry.path();
+        let name = path.file_name().and_then(|n| n.to_str()).unwrap_or("");
+        if path.is_dir() {
+            if !SKIP_DIRS.contains(&name) {
+                collect_source_files(&path, out);
+            }
+            continue;
+        }
+        // Tests usually set their own variables; they are not part of the app's contract
+        if name.ends_with("_test.go") || name.ends_with("_test.exs") || name.contains(".test.") || name.contains(".spec.") {
+            continue;
+        }
+        let ext = path.extension().and_then(|e| e.to_str()).unwrap_or("");
+        if SOURCE_EXTENSIONS.contains(&ext) {
+            out.push(path);
+        }
+    }
+}
+
+/// Find the variables read on `line`; `following` is the rest of the file, used to
+/// recognise Go's `if v == "" { v = "default" }` idiom.
+fn reads_in_line(line: &str, following: &[&str]) -> Vec<(String, Option<Fallback>)> {
+    let code = line.trim_start();
+    if code.starts_with("//") || code.starts_with('#') {
+        return Vec::new();
+    }
+    let mut found = Vec::new();
+    for pattern in READ_PATTERNS {
+        let mut search_from = 0;
+        while let Some(pos) = line[search_from..].find(pattern) {
+            let start = search_from + pos + pattern.len();
+            search_from = start;
+            // Skip matches inside longer identifiers (`$_ENV[` must not also count as `ENV[`)
+            if let Some(prev) = line[..start - pattern.len()].chars().last() {
+                if prev.is_alphanumeric() || prev == '_' {
+                    continue;
+                }
+            }
+            let Some((name, rest)) = read_name(&line[start..], *pattern == "process.env.") else { continue };
+            let mut fallback = fallback_after(rest, pattern.ends_with('('));
+            if fallback.is_none() && OPTIONAL_PATTERNS.contains(pattern) {
+                fallback = Some(Fallback::Optional);
+            }
+            if fallback.is_none() && *pattern == "os.Getenv(" {
+                fallback = go_fallback(&line[..start - pattern.len()], following);
+            }
+            found.push((name, fallback));
+        }
+    }
+    found
+}
+
+/// Parse the variable name at the start of `s` (a quoted literal, or a bare identifier
+/// for `process.env.NAME`) and return it with the remaining text.
+fn read_name(s: &str, bare: bool) -> Option<(String, &str)> {
+    let (name, rest) = if bare {
+        let end = s.find(|c: char| !(c.is_alphanumeric() || c == '_')).unwrap_or(s.len());
+        (&s[..end], &s[end..])
+    } else {
+        let quote = s.chars().next().filter(|c| matches!(c, '"' | '\'' | '`'))?;
+        let end = s[1..].find(quote)? + 1;
+        (&s[1..end], &s[end + 1..])
+    };
+    valid_name(name).then(|| (name.to_string(), rest))
+}
+
+fn valid_name(name: &str) -> bool {
+    !name.is_empty()
+        && !name.starts_with(|c: char| c.is_ascii_digit())
+        && name.chars().all(|c| c.is_ascii_alphanumeric() || c == '_')
+}
+
+/// Default given at the read site: a second call argument (`os.getenv("X", "d")`),
+/// a fallback operator (`|| 3000`, `?? 'x'`, `or 'x'`) or `.unwrap_or(...)`/`.orElse(...)`.
+fn fallback_after(rest: &str, call: bool) -> Option<Fallback> {
+    if call {
+        if let Some(arg) = rest.trim_start().strip_prefix(',') {
+            return Some(literal(arg));
+        }
+    }
+    let rest = rest.trim_start_matches([']', ')']).trim_start();
+    for op in ["||", "??", "or ", "?:"] {
+        if let Some(value) = rest.strip_prefix(op) {
+            // Elixir and Ruby: `System.get_env("X") || raise "..."` still requires it
+            if value.trim_start().starts_with("raise ") {
+                return None;
+            }
+            return Some(literal(value));
+        }
+    }
+    for call in [".unwrap_or(", ".unwrap_or_else(|_| ", ".orElse(", ".or("] {
+        if let Some(value) = rest.strip_prefix(call) {
+            return Some(literal(value));
+        }
+    }
+    // Ruby: ENV.fetch("X") { "default" }
+    if let Some(value) = rest.strip_prefix('{') {
+        return Some(literal(value));
+    }
+    None
+}
+
+/// Parse a literal default; anything else (identifiers, nil/None/undefined) only marks
+/// the variable as optional since the value cannot be known statically.
+fn literal(s: &str) -> Fallback {
+    let s = s.trim_start();
+    if let Some(quote) = s.chars().next().filter(|c| matches!(c, '"' | '\'' | '`')) {
+        if let Some(end) = s[1..].find(quote) {
+            return Fallback::Default(s[1..end + 1].to_string());
+        }
+    }
+    let token: String = s
+        .chars()
+        .take_while(|c| c.is_alphanumeric() || matches!(c, '.' | '-' | '_'))
+        .collect();
+    let numeric = token.parse::<f64>().is_ok();
+    if numeric || token == "true" || token == "false" {
+        Fallback::Default(token)
+    } else {
+        Fallback::Optional
+    }
+}
+
+/// Go idiom: `v := os.Getenv("X")` followed by `if v == "" { v = "default" }`,
+/// or `if v != "" { ... }` which makes the variable optional.
+fn go_fallback(before: &str, following: &[&str]) -> Option<Fallback> {
+    let var = before
+        .trim_end()
+        .trim_end_matches(":=")
+        .trim_end_matches('=')
+        .trim_end()
+        .rsplit(|c: char| !(c.is_alphanumeric() || c == '_'))
+        .next()
+        .filter(|v| !v.is_empty())?;
+    for (i, line) in following.iter().take(6).enumerate() {
+        let t = line.trim();
+        if t == format!("if {} == \"\" {{", var) {
+            let assign = following.get(i + 1)?.trim();
+            let value = assign.strip_prefix(&format!("{} = ", var))?;
+            return Some(literal(value));
+        }
+        if t.starts_with(&format!("if {} != \"\"", var)) {
+            return Some(Fallback::Optional);
+        }
+    }
+    None
+}
+
+/// Go config structs loaded from the environment by a library, the variable named in the field's
+/// tag: `env:"PORT" envDefault:"8080"` and `env:"KEY,required"` (caarlos0/env),
+/// `env:"PORT, default=8080"` (sethvargo/go-envconfig), `envconfig:"PORT" default:"8080"
+/// required:"true"` (kelseyhightower/envconfig) and `env:"PORT" env-default:"8080"` (cleanenv).
+/// A field with neither a default nor `required` is left at its zero value, so it is optional.
+fn go_struct_tag(line: &str) -> Option<(String, Option<Fallback>)> {
+    if line.trim_start().starts_with("//") {
+        return None;
+    }
+    let start = line.find('`')? + 1;
+    let end = start + line[start..].find('`')?;
+    let tags = struct_tags(&line[start..end]);
+    let tag = |key: &str| tags.iter().find(|(k, _)| *k == key).map(|(_, v)| *v);
+
+    let mut options = tag("env").or_else(|| tag("envconfig"))?.split(',').map(str::trim);
+    let name = options.next().filter(|n| valid_name(n))?.to_string();
+    let mut default = ["envDefault", "env-default", "default"].into_iter().find_map(tag).map(str::to_string);
+    let mut required = tag("required") == Some("true") || tag("env-required") == Some("true");
+    for option in options {
+        match option {
+            "required" | "notEmpty" => required = true,
+            _ => {
+                if let Some(value) = option.strip_prefix("default=") {
+                    default = Some(value.to_string());
+                }
+            }
+        }
+    }
+    let fallback = match default {
+        Some(value) => Some(Fallback::Default(value)),
+        None if required => None,
+        None => Some(Fallback::Optional),
+    };
+    Some((name, fallback))
+}
+
+/// The `key:"value"` pairs of a Go struct tag.
+fn struct_tags(tag: &str) -> Vec<(&str, &str)> {
+    let mut pairs = Vec::new();
+    let mut rest = tag.trim_start();
+    while let Some((key, after)) = rest.split_once(":\"") {
+        let Some(end) = after.find('"') else { break };
+        pairs.push((key, &after[..end]));
+        rest = after[end + 1..].trim_start();
+    }
+    pairs
+}
+
+/// Placeholders in properties/YAML: Spring's `${MONGODB_URI:mongodb://localhost}` and the shell
+/// syntax of compose files, `${VAR:-default}` (or `-`), `${VAR:?message}` (required) and
+/// `${VAR:+value}` (used only when set).
+fn spring_placeholders(line: &str) -> Vec<(String, Option<Fallback>)> {
+    let mut found = Vec::new();
+    let mut rest = line;
+    while let Some(pos) = rest.find("${") {
+        rest = &rest[pos + 2..];
+        let Some(end) = rest.find('}') else { break };
+        let inner = &rest[..end];
+        let split = inner.find(|c: char| !(c.is_ascii_alphanumeric() || c == '_' || c == '.')).unwrap_or(inner.len());
+        let (name, modifier) = inner.split_at(split);
+        let default = match modifier {
+            "" => None,
+            m if m.starts_with(":?") || m.starts_with('?') => None,
+            m if m.starts_with(":+") || m.starts_with('+') => Some(Fallback::Optional),
+            m if m.starts_with(":-") => Some(Fallback::Default(m[2..].to_string())),
+            m if m.starts_with('-') => Some(Fallback::Default(m[1..].to_string())),
+            m => Some(Fallback::Default(m.strip_prefix(':').unwrap_or(m).to_string())),
+        };
+        // Only upper-case names are environment variables; ${spring.x} are properties
+        if !name.is_empty() && name.chars().all(|c| c.is_ascii_uppercase() || c.is_ascii_digit() || c == '_') {
+            found.push((name.to_string(), default));
+        }
+        rest = &rest[end..];
+    }
+    found
+}
+
+/// Map a variable to the dev service it configures, preferring services detected in the project.
+fn service_for(name: &str, services: &[String]) -> String {
+    let n = name.to_uppercase();
+    let direct = [
+        ("MONGO", "mongodb"),
+        ("KAFKA", "kafka"),
+        ("REDIS", "redis"),
+        ("POSTGRES", "postgres"),
+        ("PG", "postgres"),
+        ("MYSQL", "mysql"),
+        ("MARIADB", "mysql"),
+        ("OTEL_", "otel-collector"),
+        ("FLINK", "jobmanager"),
+        ("RABBITMQ", "rabbitmq"),
+        ("AMQP", "rabbitmq"),
+    ];
+    for (key, service) in direct {
+        if n.starts_with(key) || n.contains(&format!("_{}", key)) {
+            return service.to_string();
+        }
+    }
+    // Generic database settings configure whichever database the project uses
+    if n.starts_with("DATABASE_") || n.starts_with("DB_") {
+        for db in ["postgres", "mysql", "mongodb"] {
+            if services.iter().any(|s| s == db) {
+                return db.to_string();
+            }
+        }
+        return "banco de dados".to_string();
+    }
+    "aplicação".to_string()
+}
+
+/// Markdown block (between markers) documenting the scanned variables.
+pub fn render_markdown(vars: &[EnvVar]) -> String {
+    let mut md = String::new();
+    md.push_str(START_MARKER);
+    md.push_str("\n## Variáveis de ambiente\n\n");
+    md.push_str("Gerado por `dx dev-env docs` a partir das leituras encontradas no código. Não edite entre os marcadores.\n\n");
+    if vars.is_empty() {
+        md.push_str("Nenhuma variável de ambiente encontrada no código.\n");
+    } else {
+        md.push_str("| Variável | Obrigatória | Padrão | Serviço | Lida em |\n");
+        md.push_str("|---|---|---|---|---|\n");
+        for v in vars {
+            let default = v
+                .default
+                .as_ref()
+                .map(|d| if d.is_empty() { "(vazio)".to_string() } else { format!("`{}`", d.replace('|', "\\|")) })
+                .unwrap_or_else(|| "-".to_string());
+            md.push_str(&format!(
+                "| `{}` | {} | {} | {} | {} |\n",
+                v.name,
+                if v.required { "sim" } else { "não" },
+                default,
+                v.service,
+                v.locations.join(", ")
+            ));
+        }
+    }
+    md.push_str(END_MARKER);
+    md
+}
+
+/// Write the block into `path`, replacing a previous block or appending it; creates the file if missing.
+pub fn upsert_env_docs(path: &Path, block: &str) -> std::io::Result<()> {
+    let content = if path.exists() {
+        let mut content = fs::read_to_string(path)?;
+        if let (Some(start_idx), Some(end_idx)) = (content.find(START_MARKER), content.find(END_MARKER)) {
+            content.replace_range(start_idx..end_idx + END_MARKER.len(), block);
+        } else {
+            if !content.ends_with('\n') {
+                content.push('\n');
+            }
+            content.push('\n');
+            content.push_str(block);
+            content.push('\n');
+        }
+        content
+    } else {
+        format!("{}\n", block)
+    };
+    crate::audit::write(path, content)
+}
+
+/// `dx dev-env docs`: document the variables in ENV.md (or README.md with `readme`).
+pub fn docs(dir: Option<PathBuf>, save_file: bool, readme: bool) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let vars = scan(&project_dir);
+    let block = render_markdown(&vars);
+
+    if !save_file {
+        println!("{}", block);
+        return;
+    }
+
+    let target = project_dir.join(if readme { "README.md" } else { "ENV.md" });
+    match upsert_env_docs(&target, &block) {
+        Ok(()) => {
+            let required = vars.iter().filter(|v| v.required).count();
+            println!(
+                "{} variáveis documentadas ({} obrigatórias) em {}",
+                vars.len(),
+                required,
+                target.display()
+            );
+        }
+        Err(e) => eprintln!("Erro ao escrever {}: {}", target.display(), e),
+    }
+}
+
+fn render_table(vars: &[&EnvVar]) -> String {
+    let default_of = |v: &EnvVar| match &v.default {
+        Some(d) if d.is_empty() => "(vazio)".to_string(),
+        Some(d) => d.clone(),
+        None => "-".to_string(),
+    };
+    let name_w = vars.iter().map(|v| v.name.len()).chain([8]).max().unwrap_or(8);
+    let default_w = vars.iter().map(|v| default_of(v).chars().count()).chain([6]).max().unwrap_or(6);
+    let service_w = vars.iter().map(|v| v.service.chars().count()).chain([7]).max().unwrap_or(7);
+    let mut out = format!(
+        "  {:<name_w$}  {:<default_w$}  {:<service_w$}  {}\n",
+        "VARIÁVEL", "PADRÃO", "SERVIÇO", "LIDA EM"
+    );
+    for v in vars {
+        out.push_str(&format!(
+            "  {:<name_w$}  {:<default_w$}  {:<service_w$}  {}\n",
+            v.name,
+            default_of(v),
+            v.service,
+            v.locations.join(", ")
+        ));
+    }
+    out
+}
+
+/// `dx dev-env scan`: list the variables the code reads, required ones first.
+pub fn cmd_scan(dir: Option<PathBuf>, format: ScanFormat) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let vars = scan(&project_dir);
+    if format == ScanFormat::Json {
+        let items: Vec<serde_json::Value> = vars
+            .iter()
+            .map(|v| {
+                serde_json::json!({
+                    "name": v.name,
+                    "required": v.required,
+                    "default": v.default,
+                    "service": v.service,
+                    "locations": v.locations,
+                })
+            })
+            .collect();
+        println!("{}", serde_json::to_string_pretty(&items).unwrap_or_default());
+        return;
+    }
+    if vars.is_empty() {
+        println!("Nenhuma variável de ambiente encontrada em {}.", project_dir.display());
+        return;
+    }
+
+    let (required, optional): (Vec<&EnvVar>, Vec<&EnvVar>) = vars.iter().partition(|v| v.required);
+    println!("Variáveis de ambiente lidas em {}:\n", project_dir.display());
+    println!("Obrigatórias ({}) — sem padrão no código:", required.len());
+    if required.is_empty() {
+        println!("  (nenhuma)");
+    } else {
+        print!("{}", render_table(&required));
+    }
+    println!("\nOpcionais ({}) — com padrão ou tratadas como ausentes:", optional.len());
+    if optional.is_empty() {
+        println!("  (nenhuma)");
+    } else {
+        print!("{}", render_table(&optional));
+    }
+}
+
+const EXAMPLE_FILE: &str = ".env.example";
+const DOTENV_FILE: &str = ".env";
+/// First line of a generated .env.example; files without it were written by hand.
+const EXAMPLE_HEADER: &str = "# Gerado por: dx dev-env init";
+
+/// .env.example with every scanned variable: required ones empty, optional ones with the code's default.
+pub fn render_example(vars: &[EnvVar]) -> String {
+    let mut out = format!(
+        "{} — variáveis lidas pelo código.\n# Copie para .env e preencha as obrigatórias. Não coloque segredos reais aqui.\n",
+        EXAMPLE_HEADER
+    );
+    let (required, optional): (Vec<&EnvVar>, Vec<&EnvVar>) = vars.iter().partition(|v| v.required);
+    for (title, group) in [("Obrigatórias", required), ("Opcionais (valor padrão do código)", optional)] {
+        if group.is_empty() {
+            continue;
+        }
+        out.push_str(&format!("\n# {}\n", title));
+        for v in group {
+            let service = if v.service == "aplicação" { String::new() } else { format!(" ({})", v.service) };
+            out.push_str(&format!("# {}{}\n", v.locations.join(", "), service));
+            out.push_str(&crate::env_export::dotenv_line(&v.name, v.default.as_deref().unwrap_or("")));
+        }
+    }
+    out
+}
+
+/// Variable names defined in a .env file (`KEY=...` or `export KEY=...`).
+pub(crate) fn dotenv_keys(content: &str) -> Vec<String> {
+    dotenv_entries(content).into_iter().map(|(key, _)| key).collect()
+}
+
+/// `KEY=value` pairs of a .env file in order, quotes removed and empty values kept.
+fn dotenv_entries(content: &str) -> Vec<(String, String)> {
+    content
+        .lines()
+        .filter_map(|l| {
+            let l = l.trim();
+            let l = l.strip_prefix("export ").unwrap_or(l);
+            let (key, value) = l.split_once('=')?;
+            let key = key.trim();
+            let value = value.trim().trim_matches(|c| c == '"' || c == '\'');
+            (!key.is_empty() && !key.starts_with('#')).then(|| (key.to_string(), value.to_string()))
+        })
+        .collect()
+}
+
+/// Add the variables missing from `.env`, never touching the values already there. Local
+/// Dev Services connections (as in `dx dev-env export`) are used before the code's defaults.
+/// Returns the variables added with their values.
+fn fill_dotenv(project_dir: &Path, vars: &[EnvVar]) -> std::io::Result<Vec<(String, String)>> {
+    let existing = dotenv_keys(&fs::read_to_string(project_dir.join(DOTENV_FILE)).unwrap_or_default());
+    let composed = crate::env_export::compose(project_dir);
+    let missing: Vec<(String, String)> = vars
+        .iter()
+        .filter(|v| !existing.contains(&v.name))
+        .map(|v| {
+            let value = composed.get(&v.name).map(|c| c.value.clone()).or_else(|| v.default.clone());
+            (v.name.clone(), value.unwrap_or_default())
+        })
+        .collect();
+    append_dotenv(project_dir, &missing, "init")?;
+    Ok(missing)
+}
+
+/// Append `entries` to `.env` (created when absent) under a comment naming the dx command.
+fn append_dotenv(project_dir: &Path, entries: &[(String, String)], command: &str) -> std::io::Result<()> {
+    if entries.is_empty() {
+        return Ok(());
+    }
+    let path = project_dir.join(DOTENV_FILE);
+    let mut content = fs::read_to_string(&path).unwrap_or_default();
+    if !content.is_empty() {
+        if !content.ends_with('\n') {
+            content.push('\n');
+        }
+        content.push('\n');
+    }
+    content.push_str(&format!("# Adicionadas por: dx dev-env {}\n", command));
+    for (name, value) in entries {
+        content.push_str(&crate::env_export::dotenv_line(name, value));
+    }
+    crate::audit::write(&path, content)
+}
+
+/// `dx dev-env init`: write .env.example from the scan and, with `dotenv`, fill in .env.
+/// A hand-written .env.example goes through the conflict resolution (`force` replaces it).
+pub fn cmd_init(dir: Option<PathBuf>, save_file: bool, dotenv: bool, force: bool) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let vars = scan(&project_dir);
+    let example = render_example(&vars);
+    if !save_file {
+        print!("{}", example);
+        return;
+    }
+    if vars.is_empty() {
+        println!("Nenhuma variável de ambiente encontrada em {}; nada a gerar.", project_dir.display());
+        return;
+    }
+
+    let mut files = vec![(project_dir.join(EXAMPLE_FILE), example)];
+    if !crate::conflicts::settle(&mut files, crate::conflicts::generated_by(EXAMPLE_HEADER), force) {
+        crate::exit(1);
+    }
+    if let Some((path, example)) = files.first() {
+        if let Err(e) = crate::audit::write(path, example) {
+            eprintln!("Erro ao escrever {}: {}", path.display(), e);
+            crate::exit(1);
+        }
+        let required = vars.iter().filter(|v| v.required).count();
+        println!("{} gerado: {} variáveis ({} obrigatórias).", path.display(), vars.len(), required);
+    }
+
+    if !dotenv {
+        println!("Para criar o .env a partir dele: dx dev-env init --env");
+        return;
+    }
+    let env_path = project_dir.join(DOTENV_FILE);
+    match fill_dotenv(&project_dir, &vars) {
+        Ok(added) if added.is_empty() => println!("{} já define todas as variáveis.", env_path.display()),
+        Ok(added) => {
+            let names: Vec<&str> = added.iter().map(|(name, _)| name.as_str()).collect();
+            println!("{}: {} variáveis adicionadas ({}).", env_path.display(), added.len(), names.join(", "));
+            let empty: Vec<&str> = added
+                .iter()
+                .filter(|(name, value)| value.is_empty() && vars.iter().any(|v| v.required && &v.name == name))
+                .map(|(name, _)| name.as_str())
+                .collect();
+            if !empty.is_empty() {
+                println!("Preencha as obrigatórias sem valor: {}", empty.join(", "));
+            }
+        }
+        Err(e) => {
+            eprintln!("Erro ao escrever {}: {}", env_path.display(), e);
+            crate::exit(1);
+        }
+    }
+    let ignored = fs::read_to_string(project_dir.join(".gitignore"))
+        .is_ok_and(|c| c.lines().any(|l| matches!(l.trim(), ".env" | "/.env" | ".env*" | "*.env")));
+    if !ignored {
+        println!("Aviso: .env não está no .gitignore; adicione-o para não versionar segredos.");
+    }
+}
+
+/// Output of `dx dev-env check`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum CheckFormat {
+    /// Problems grouped by kind
+    Text,
+    /// JSON object with the missing, empty, mismatched and unused variables
+    Json,
+}
+
+/// Value type the code expects for a variable.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum ValueKind {
+    Integer,
+    Number,
+    Boolean,
+    Duration,
+    Url,
+}
+
+impl ValueKind {
+    fn label(self) -> &'static str {
+        match self {
+            ValueKind::Integer => "inteiro",
+            ValueKind::Number => "número",
+            ValueKind::Boolean => "booleano (true/false)",
+            ValueKind::Duration => "duração (ex.: 500ms, 30s, 5m)",
+            ValueKind::Url => "URL (esquema://...)",
+        }
+    }
+
+    fn accepts(self, value: &str) -> bool {
+        match self {
+            ValueKind::Integer => value.parse::<i64>().is_ok(),
+            ValueKind::Number => value.parse::<f64>().is_ok(),
+            ValueKind::Boolean => {
+                matches!(value.to_ascii_lowercase().as_str(), "true" | "false" | "1" | "0" | "t" | "f" | "yes" | "no" | "on" | "off")
+            }
+            ValueKind::Duration => value == "0" || go_duration(value),
+            ValueKind::Url => value.contains("://"),
+        }
+    }
+}
+
+/// A Go `time.ParseDuration` value: one or more decimal numbers each followed by a unit.
+fn go_duration(value: &str) -> bool {
+    let mut rest = value.strip_prefix(['-', '+']).unwrap_or(value);
+    if rest.is_empty() {
+        return false;
+    }
+    while !rest.is_empty() {
+        let digits = rest.find(|c: char| !(c.is_ascii_digit() || c == '.')).unwrap_or(rest.len());
+        if digits == 0 {
+            return false;
+        }
+        rest = &rest[digits..];
+        let Some(unit) = ["ns", "us", "µs", "ms", "s", "m", "h"].into_iter().find(|u| rest.starts_with(u) && !(*u == "m" && rest.starts_with("ms"))) else {
+            return false;
+        };
+        rest = &rest[unit.len()..];
+    }
+    true
+}
+
+/// Conversions applied at a read site and the type they need.
+const CONVERSIONS: &[(&str, ValueKind)] = &[
+    ("strconv.Atoi(", ValueKind::Integer),
+    ("strconv.ParseInt(", ValueKind::Integer),
+    ("strconv.ParseUint(", ValueKind::Integer),
+    ("strconv.ParseFloat(", ValueKind::Number),
+    ("strconv.ParseBool(", ValueKind::Boolean),
+    ("time.ParseDuration(", ValueKind::Duration),
+    ("parseInt(", ValueKind::Integer),
+    ("parseFloat(", ValueKind::Number),
+    ("Number(", ValueKind::Number),
+    ("int(", ValueKind::Integer),
+    ("float(", ValueKind::Number),
+    ("Integer.parseInt(", ValueKind::Integer),
+    ("Integer.valueOf(", ValueKind::Integer),
+    ("Long.parseLong(", ValueKind::Integer),
+    ("Double.parseDouble(", ValueKind::Number),
+    ("Boolean.parseBoolean(", ValueKind::Boolean),
+    (".to_i", ValueKind::Integer),
+    (".to_f", ValueKind::Number),
+];
+
+/// Type the code expects for `var` and why: the Go struct field it loads into, a conversion at a
+/// read site (`strconv.Atoi`, `parseInt`, `.parse::<u16>()`), the literal default, or the name
+/// (`*_PORT`, `*_URL`).
+fn expected_kind(project_dir: &Path, var: &EnvVar) -> Option<(ValueKind, String)> {
+    let mut from_call = None;
+    for location in &var.locations {
+        let Some((file, line)) = location.rsplit_once(':') else { continue };
+        let Some(text) = line
+            .parse::<usize>()
+            .ok()
+            .and_then(|n| fs::read_to_string(project_dir.join(file)).ok()?.lines().nth(n - 1).map(str::to_string))
+        else {
+            continue;
+        };
+        if file.ends_with(".go") && go_struct_tag(&text).is_some_and(|(name, _)| name == var.name) {
+            let fields: Vec<&str> = text[..text.find('`').unwrap_or(0)].split_whitespace().collect();
+            if let [_, ty] = fields.as_slice() {
+                if let Some(kind) = go_kind(ty.trim_start_matches('*')) {
+                    return Some((kind, format!("campo {} em {}", ty, location)));
+                }
+            }
+        }
+        if from_call.is_none() {
+            from_call = conversion(&text).map(|(call, kind)| (kind, format!("{} em {}", call, location)));
+        }
+    }
+    if from_call.is_some() {
+        return from_call;
+    }
+    if let Some(default) = &var.default {
+        let kind = if default.parse::<i64>().is_ok() {
+            Some(ValueKind::Integer)
+        } else if default.parse::<f64>().is_ok() {
+            Some(ValueKind::Number)
+        } else if default == "true" || default == "false" {
+            Some(ValueKind::Boolean)
+        } else {
+            None
+        };
+        if let Some(kind) = kind {
+            return Some((kind, format!("padrão {} no código", default)));
+        }
+    }
+    let name = var.name.as_str();
+    if name == "PORT" || name.ends_with("_PORT") {
+        return Some((ValueKind::Integer, "nome termina em PORT".to_string()));
+    }
+    if name.ends_with("_URL") || name.ends_with("_URI") {
+        return Some((ValueKind::Url, format!("nome termina em {}", &name[name.len() - 3..])));
+    }
+    None
+}
+
+fn go_kind(ty: &str) -> Option<ValueKind> {
+    match ty {
+        "int" | "int8" | "int16" | "int32" | "int64" | "uint" | "uint8" | "uint16" | "uint32" | "uint64" => Some(ValueKind::Integer),
+        "float32" | "float64" => Some(ValueKind::Number),
+        "bool" => Some(ValueKind::Boolean),
+        "time.Duration" => Some(ValueKind::Duration),
+        _ => None,
+    }
+}
+
+/// The first conversion applied on a source line, as written, with the type it needs.
+fn conversion(line: &str) -> Option<(String, ValueKind)> {
+    let mut found: Option<(usize, String, ValueKind)> = None;
+    for (call, kind) in CONVERSIONS {
+        let mut search_from = 0;
+        while let Some(pos) = line[search_from..].find(call) {
+            let at = search_from + pos;
+            search_from = at + call.len();
+            // `print(` must not count as `int(`, nor `myNumber(` as `Number(`
+            if line[..at].chars().last().is_some_and(|c| c.is_alphanumeric() || c == '_') && !call.starts_with('.') {
+                continue;
+            }
+            if found.as_ref().map_or(true, |(first, _, _)| at < *first) {
+                found = Some((at, call.trim_end_matches('(').to_string(), *kind));
+            }
+            break;
+        }
+    }
+    if let Some(at) = line.find(".parse::<") {
+        let ty: String = line[at + 9..].chars().take_while(|c| c.is_alphanumeric()).collect();
+        let kind = match ty.chars().next() {
+            Some('u' | 'i') => Some(ValueKind::Integer),
+            Some('f') => Some(ValueKind::Number),
+            _ if ty == "bool" => Some(ValueKind::Boolean),
+            _ => None,
+        };
+        if let Some(kind) = kind {
+            if found.as_ref().map_or(true, |(first, _, _)| at < *first) {
+                found = Some((at, format!(".parse::<{}>()", ty), kind));
+            }
+        }
+    }
+    found.map(|(_, call, kind)| (call, kind))
+}
+
+/// Variables read by tools rather than the application (Docker Compose, dx itself).
+const TOOLING_PREFIXES: &[&str] = &["COMPOSE_", "DOCKER_", "DX_"];
+
+/// Result of comparing `.env` with `.env.example` and the variables the code reads.
+#[derive(Debug, Default)]
+struct EnvCheck {
+    /// Variable, where it is expected (`.env.example` and/or the code's read site) and the value `--fix` writes.
+    missing: Vec<(String, String, String)>,
+    /// Required by the code but empty in `.env`.
+    empty: Vec<(String, String)>,
+    /// Variable, value in `.env`, expected type and why.
+    mismatched: Vec<(String, String, ValueKind, String)>,
+    /// Variable and the files that define it although the code never reads it.
+    unused: Vec<(String, Vec<&'static str>)>,
+}
+
+impl EnvCheck {
+    fn failed(&self) -> bool {
+        !(self.missing.is_empty() && self.empty.is_empty() && self.mismatched.is_empty())
+    }
+}
+
+fn check(project_dir: &Path, vars: &[EnvVar]) -> EnvCheck {
+    let dotenv = dotenv_entries(&fs::read_to_string(project_dir.join(DOTENV_FILE)).unwrap_or_default());
+    let example = dotenv_entries(&fs::read_to_string(project_dir.join(EXAMPLE_FILE)).unwrap_or_default());
+    let value_in = |entries: &[(String, String)], name: &str| entries.iter().find(|(k, _)| k == name).map(|(_, v)| v.clone());
+    let composed = crate::env_export::compose(project_dir);
+    let mut report = EnvCheck::default();
+
+    let mut expected: Vec<&str> = example.iter().map(|(k, _)| k.as_str()).collect();
+    expected.extend(vars.iter().filter(|v| v.required).map(|v| v.name.as_str()));
+    expected.sort();
+    expected.dedup();
+    for name in expected {
+        if value_in(&dotenv, name).is_some() {
+            continue;
+        }
+        let var = vars.iter().find(|v| v.name == name);
+        let mut sources = Vec::new();
+        if value_in(&example, name).is_some() {
+            sources.push(EXAMPLE_FILE.to_string());
+        }
+        if let Some(var) = var.filter(|v| v.required) {
+            sources.push(format!("obrigatória em {}", var.locations.first().map(String::as_str).unwrap_or("?")));
+        }
+        let value = value_in(&example, name)
+            .filter(|v| !v.is_empty())
+            .or_else(|| composed.get(name).map(|c| c.value.clone()))
+            .or_else(|| var.and_then(|v| v.default.clone()))
+            .unwrap_or_default();
+        report.missing.push((name.to_string(), sources.join("; "), value));
+    }
+
+    for (name, value) in &dotenv {
+        let Some(var) = vars.iter().find(|v| &v.name == name) else {
+            if TOOLING_PREFIXES.iter().any(|p| name.starts_with(p)) {
+                continue;
+            }
+            let mut files = vec![DOTENV_FILE];
+            if value_in(&example, name).is_some() {
+                files.push(EXAMPLE_FILE);
+            }
+            report.unused.push((name.clone(), files));
+            continue;
+        };
+        if value.is_empty() {
+            if var.required {
+                report.empty.push((name.clone(), var.locations.first().cloned().unwrap_or_default()));
+            }
+            continue;
+        }
+        if let Some((kind, reason)) = expected_kind(project_dir, var) {
+            if !kind.accepts(value) {
+                report.mismatched.push((name.clone(), value.clone(), kind, reason));
+            }
+        }
+    }
+    for (name, _) in &example {
+        let read = vars.iter().any(|v| &v.name == name);
+        let listed = report.unused.iter().any(|(n, _)| n == name);
+        if !read && !listed && value_in(&dotenv, name).is_none() && !TOOLING_PREFIXES.iter().any(|p| name.starts_with(p)) {
+            report.unused.push((name.clone(), vec![EXAMPLE_FILE]));
+        }
+    }
+    report
+}
+
+/// `dx dev-env check`: compare `.env` with `.env.example` and the variables the code reads,
+/// reporting missing, empty, wrongly typed and unused ones. `fix` appends the missing keys
+/// (value from `.env.example`, the Dev Services connection or the code's default).
+/// Unused variables are only warnings; returns 1 when anything else is wrong.
+pub fn cmd_check(dir: Option<PathBuf>, format: CheckFormat, fix: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let vars = scan(&project_dir);
+    let mut report = check(&project_dir, &vars);
+
+    let mut added = Vec::new();
+    if fix && !report.missing.is_empty() {
+        let entries: Vec<(String, String)> = report.missing.iter().map(|(name, _, value)| (name.clone(), value.clone())).collect();
+        if let Err(e) = append_dotenv(&project_dir, &entries, "check --fix") {
+            eprintln!("Erro ao escrever {}: {}", project_dir.join(DOTENV_FILE).display(), e);
+            return 1;
+        }
+        added = entries.into_iter().map(|(name, _)| name).collect();
+        report = check(&project_dir, &vars);
+    }
+
+    if format == CheckFormat::Json {
+        let out = serde_json::json!({
+            "missing": report.missing.iter().map(|(name, source, _)| serde_json::json!({"name": name, "source": source})).collect::<Vec<_>>(),
+            "empty": report.empty.iter().map(|(name, location)| serde_json::json!({"name": name, "location": location})).collect::<Vec<_>>(),
+            "mismatched": report.mismatched.iter().map(|(name, value, kind, reason)| {
+                serde_json::json!({"name": name, "value": value, "expected": kind.label(), "reason": reason})
+            }).collect::<Vec<_>>(),
+            "unused": report.unused.iter().map(|(name, files)| serde_json::json!({"name": name, "files": files})).collect::<Vec<_>>(),
+            "added": added,
+        });
+        println!("{}", serde_json::to_string_pretty(&out).unwrap_or_default());
+        return if report.failed() { 1 } else { 0 };
+    }
+
+    if !added.is_empty() {
+        println!("{}: {} variável(is) adicionada(s) ({}).", project_dir.join(DOTENV_FILE).display(), added.len(), added.join(", "));
+    }
+    if !report.missing.is_empty() {
+        println!("Faltando no .env ({}):", report.missing.len());
+        for (name, source, _) in &report.missing {
+            println!("  ✗ {}  {}", name, source);
+        }
+    }
+    if !report.empty.is_empty() {
+        println!("Obrigatórias sem valor ({}):", report.empty.len());
+        for (name, location) in &report.empty {
+            println!("  ✗ {}  lida em {}", name, location);
+        }
+    }
+    if !report.mismatched.is_empty() {
+        println!("Tipo incorreto ({}):", report.mismatched.len());
+        for (name, value, kind, reason) in &report.mismatched {
+            println!("  ✗ {}={}  esperado {} ({})", name, value, kind.label(), reason);
+        }
+    }
+    if !report.unused.is_empty() {
+        println!("Não usadas pelo código ({}):", report.unused.len());
+        for (name, files) in &report.unused {
+            println!("  ! {}  em {}", name, files.join(" e "));
+        }
+    }
+    if report.failed() {
+        if !fix && !report.missing.is_empty() {
+            println!("Para acrescentar as que faltam: dx dev-env check --fix");
+        }
+        return 1;
+    }
+    println!("✓ .env de {} confere com o .env.example e com o código.", project_dir.display());
+    0
+}
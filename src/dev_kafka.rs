@@ -0,0 +1,1256 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::collections::BTreeMap;
+use std::io::{self, Read, Write};
+use std::net::{TcpStream, ToSocketAddrs};
+use std::path::{Path, PathBuf};
+use std::time::Duration;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum TopicsFormat {
+    /// Tópicos do broker e os usados pelo código
+    Text,
+    /// Objeto JSON com `broker`, `topics` e `detected`
+    Json,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum ConsumeFormat {
+    /// Cabeçalho por mensagem e valor JSON indentado
+    Text,
+    /// Uma linha JSON por mensagem (`topic`, `partition`, `offset`, `timestamp`, `key`, `headers`, `value`)
+    Jsonl,
+}
+
+/// Broker used when neither the environment nor the code names one.
+const DEFAULT_BROKERS: &str = "localhost:9092";
+const CLIENT_ID: &str = "dx-cli";
+const TIMEOUT: Duration = Duration::from_secs(5);
+/// Broker-side timeout for topic creation and deletion, in milliseconds.
+const ADMIN_TIMEOUT_MS: i32 = 10_000;
+
+// Kafka API keys and the (non-flexible) versions dx speaks; all are supported from Kafka 1.0 to 4.x
+// and by Redpanda, the Dev Services broker.
+const METADATA: (i16, i16) = (3, 4);
+const CREATE_TOPICS: (i16, i16) = (19, 2);
+const DELETE_TOPICS: (i16, i16) = (20, 1);
+const LIST_OFFSETS: (i16, i16) = (2, 1);
+const FETCH: (i16, i16) = (1, 4);
+const PRODUCE: (i16, i16) = (0, 3);
+
+/// How long a fetch waits on the broker for new messages, in milliseconds.
+const FETCH_WAIT_MS: i32 = 500;
+const FETCH_MAX_BYTES: i32 = 1 << 20;
+/// ListOffsets timestamps for the first and the next offset of a partition.
+const EARLIEST: i64 = -2;
+const LATEST: i64 = -1;
+/// Kafka error code 1: the offset is no longer (or not yet) in the log.
+const OFFSET_OUT_OF_RANGE: i16 = 1;
+
+/// Acknowledgement asked of the broker when producing: the leader has written the batch.
+const ACKS_LEADER: i16 = 1;
+
+/// Kafka error code 36: the topic already exists (not a failure for `create`).
+const TOPIC_ALREADY_EXISTS: i16 = 36;
+
+fn error_name(code: i16) -> &'static str {
+    match code {
+        1 => "offset fora do log da partição",
+        3 => "tópico inexistente",
+        6 => "o broker não é o líder da partição",
+        7 => "tempo esgotado no broker",
+        17 => "nome de tópico inválido",
+        29 => "sem permissão para o tópico",
+        36 => "o tópico já existe",
+        37 => "número de partições inválido",
+        38 => "fator de replicação inválido (maior que o número de brokers?)",
+        41 => "o broker não é o controller",
+        73 => "remoção de tópicos desabilitada no broker",
+        _ => "erro do broker",
+    }
+}
+
+/// Request body encoder (big-endian, Kafka's non-flexible encoding).
+#[derive(Default)]
+struct Encoder(Vec<u8>);
+
+impl Encoder {
+    fn i16(&mut self, v: i16) -> &mut Self {
+        self.0.extend_from_slice(&v.to_be_bytes());
+        self
+    }
+
+    fn i32(&mut self, v: i32) -> &mut Self {
+        self.0.extend_from_slice(&v.to_be_bytes());
+        self
+    }
+
+    fn i64(&mut self, v: i64) -> &mut Self {
+        self.0.extend_from_slice(&v.to_be_bytes());
+        self
+    }
+
+    fn bool(&mut self, v: bool) -> &mut Self {
+        self.0.push(v as u8);
+        self
+    }
+
+    fn string(&mut self, v: &str) -> &mut Self {
+        self.i16(v.len() as i16);
+        self.0.extend_from_slice(v.as_bytes());
+        self
+    }
+
+    /// Zigzag varint of the v2 record format.
+    fn varint(&mut self, v: i64) -> &mut Self {
+        let mut z = ((v << 1) ^ (v >> 63)) as u64;
+        while z >= 0x80 {
+            self.0.push((z as u8 & 0x7f) | 0x80);
+            z >>= 7;
+        }
+        self.0.push(z as u8);
+        self
+    }
+
+    /// Varint-prefixed bytes of a record (`None` is null).
+    fn varint_bytes(&mut self, v: Option<&[u8]>) -> &mut Self {
+        match v {
+            Some(bytes) => {
+                self.varint(bytes.len() as i64);
+                self.0.extend_from_slice(bytes);
+            }
+            None => {
+                self.varint(-1);
+            }
+        }
+        self
+    }
+}
+
+/// Response body decoder; every read fails with `InvalidData` past the end.
+struct Decoder<'a>(&'a [u8]);
+
+impl Decoder<'_> {
+    fn take(&mut self, n: usize) -> io::Result<&[u8]> {
+        if self.0.len() < n {
+            return Err(io::Error::new(io::ErrorKind::InvalidData, "resposta do broker truncada"));
+        }
+        let (head, rest) = self.0.split_at(n);
+        self.0 = rest;
+        Ok(head)
+    }
+
+    fn i16(&mut self) -> io::Result<i16> {
+        Ok(i16::from_be_bytes(self.take(2)?.try_into().unwrap()))
+    }
+
+    fn i32(&mut self) -> io::Result<i32> {
+        Ok(i32::from_be_bytes(self.take(4)?.try_into().unwrap()))
+    }
+
+    fn i64(&mut self) -> io::Result<i64> {
+        Ok(i64::from_be_bytes(self.take(8)?.try_into().unwrap()))
+    }
+
+    fn bool(&mut self) -> io::Result<bool> {
+        Ok(self.take(1)?[0] != 0)
+    }
+
+    /// Zigzag varint of the v2 record format.
+    fn varint(&mut self) -> io::Result<i64> {
+        let mut value: u64 = 0;
+        for shift in (0..64).step_by(7) {
+            let byte = self.take(1)?[0];
+            value |= ((byte & 0x7f) as u64) << shift;
+            if byte & 0x80 == 0 {
+                return Ok((value >> 1) as i64 ^ -((value & 1) as i64));
+            }
+        }
+        Err(io::Error::new(io::ErrorKind::InvalidData, "varint inválido"))
+    }
+
+    /// Varint-prefixed bytes of a record (length -1 is null).
+    fn varint_bytes(&mut self) -> io::Result<Option<Vec<u8>>> {
+        let len = self.varint()?;
+        if len < 0 {
+            return Ok(None);
+        }
+        Ok(Some(self.take(len as usize)?.to_vec()))
+    }
+
+    fn nullable_string(&mut self) -> io::Result<Option<String>> {
+        let len = self.i16()?;
+        if len < 0 {
+            return Ok(None);
+        }
+        Ok(Some(String::from_utf8_lossy(self.take(len as usize)?).into_owned()))
+    }
+
+    fn string(&mut self) -> io::Result<String> {
+        Ok(self.nullable_string()?.unwrap_or_default())
+    }
+
+    /// Array length (null arrays count as empty).
+    fn len(&mut self) -> io::Result<usize> {
+        Ok(self.i32()?.max(0) as usize)
+    }
+}
+
+/// One connection to a broker.
+struct Connection {
+    stream: TcpStream,
+    correlation_id: i32,
+}
+
+impl Connection {
+    fn open(address: &str) -> io::Result<Connection> {
+        let addr = address
+            .to_socket_addrs()?
+            .next()
+            .ok_or_else(|| io::Error::new(io::ErrorKind::NotFound, format!("endereço inválido: {}", address)))?;
+        let stream = TcpStream::connect_timeout(&addr, TIMEOUT)?;
+        stream.set_read_timeout(Some(TIMEOUT + Duration::from_millis(ADMIN_TIMEOUT_MS as u64)))?;
+        stream.set_write_timeout(Some(TIMEOUT))?;
+        Ok(Connection { stream, correlation_id: 0 })
+    }
+
+    /// Send one request (header v1) and return the body of its response (header v0).
+    fn call(&mut self, (api_key, api_version): (i16, i16), body: &Encoder) -> io::Result<Vec<u8>> {
+        self.correlation_id += 1;
+        let mut request = Encoder::default();
+        request.i16(api_key).i16(api_version).i32(self.correlation_id).string(CLIENT_ID);
+        request.0.extend_from_slice(&body.0);
+        self.stream.write_all(&(request.0.len() as i32).to_be_bytes())?;
+        self.stream.write_all(&request.0)?;
+
+        let mut size = [0u8; 4];
+        self.stream.read_exact(&mut size)?;
+        let mut response = vec![0u8; i32::from_be_bytes(size).max(0) as usize];
+        self.stream.read_exact(&mut response)?;
+        let mut decoder = Decoder(&response);
+        if decoder.i32()? != self.correlation_id {
+            return Err(io::Error::new(io::ErrorKind::InvalidData, "resposta fora de ordem do broker"));
+        }
+        Ok(decoder.0.to_vec())
+    }
+}
+
+/// A topic on the broker.
+#[derive(Debug, Clone)]
+pub struct Topic {
+    pub name: String,
+    pub partitions: usize,
+    pub replication_factor: usize,
+    pub internal: bool,
+    /// Leader broker of each partition, by partition index.
+    pub leaders: BTreeMap<i32, i32>,
+}
+
+/// What the broker reports about the cluster.
+struct Metadata {
+    brokers: BTreeMap<i32, String>,
+    controller: i32,
+    topics: Vec<Topic>,
+}
+
+fn metadata(conn: &mut Connection) -> io::Result<Metadata> {
+    let mut body = Encoder::default();
+    // All topics (null array), without creating any
+    body.i32(-1).bool(false);
+    let response = conn.call(METADATA, &body)?;
+    let mut d = Decoder(&response);
+    d.i32()?; // throttle_time_ms
+    let mut brokers = BTreeMap::new();
+    for _ in 0..d.len()? {
+        let (id, host, port) = (d.i32()?, d.string()?, d.i32()?);
+        d.nullable_string()?; // rack
+        brokers.insert(id, format!("{}:{}", host, port));
+    }
+    d.nullable_string()?; // cluster_id
+    let controller = d.i32()?;
+    let mut topics = Vec::new();
+    for _ in 0..d.len()? {
+        let (error, name, internal) = (d.i16()?, d.string()?, d.bool()?);
+        let partitions = d.len()?;
+        let mut replication_factor = 0;
+        let mut leaders = BTreeMap::new();
+        for _ in 0..partitions {
+            d.i16()?; // error_code
+            let (index, leader) = (d.i32()?, d.i32()?);
+            leaders.insert(index, leader);
+            let replicas = d.len()?;
+            for _ in 0..replicas {
+                d.i32()?;
+            }
+            for _ in 0..d.len()? {
+                d.i32()?; // isr
+            }
+            replication_factor = replication_factor.max(replicas);
+        }
+        if error == 0 {
+            topics.push(Topic { name, partitions, replication_factor, internal, leaders });
+        }
+    }
+    topics.sort_by(|a, b| a.name.cmp(&b.name));
+    Ok(Metadata { brokers, controller, topics })
+}
+
+/// Per-topic result of a create or delete: `None` on success, else the error.
+type TopicResults = Vec<(String, Option<(i16, Option<String>)>)>;
+
+fn create_topics(conn: &mut Connection, names: &[String], partitions: i32, replication_factor: i16) -> io::Result<TopicResults> {
+    let mut body = Encoder::default();
+    body.i32(names.len() as i32);
+    for name in names {
+        // No manual assignments nor configs
+        body.string(name).i32(partitions).i16(replication_factor).i32(0).i32(0);
+    }
+    body.i32(ADMIN_TIMEOUT_MS).bool(false);
+    let response = conn.call(CREATE_TOPICS, &body)?;
+    let mut d = Decoder(&response);
+    d.i32()?; // throttle_time_ms
+    let mut results = Vec::new();
+    for _ in 0..d.len()? {
+        let (name, error, message) = (d.string()?, d.i16()?, d.nullable_string()?);
+        results.push((name, (error != 0).then_some((error, message))));
+    }
+    Ok(results)
+}
+
+fn delete_topics(conn: &mut Connection, names: &[String]) -> io::Result<TopicResults> {
+    let mut body = Encoder::default();
+    body.i32(names.len() as i32);
+    for name in names {
+        body.string(name);
+    }
+    body.i32(ADMIN_TIMEOUT_MS);
+    let response = conn.call(DELETE_TOPICS, &body)?;
+    let mut d = Decoder(&response);
+    d.i32()?; // throttle_time_ms
+    let mut results = Vec::new();
+    for _ in 0..d.len()? {
+        let (name, error) = (d.string()?, d.i16()?);
+        results.push((name, (error != 0).then_some((error, None))));
+    }
+    Ok(results)
+}
+
+/// Offset of each partition of `topic` at `timestamp` (`EARLIEST` or `LATEST`).
+fn list_offsets(conn: &mut Connection, topic: &str, partitions: &[i32], timestamp: i64) -> io::Result<BTreeMap<i32, i64>> {
+    let mut body = Encoder::default();
+    body.i32(-1).i32(1).string(topic).i32(partitions.len() as i32);
+    for partition in partitions {
+        body.i32(*partition).i64(timestamp);
+    }
+    let response = conn.call(LIST_OFFSETS, &body)?;
+    let mut d = Decoder(&response);
+    let mut offsets = BTreeMap::new();
+    for _ in 0..d.len()? {
+        d.string()?;
+        for _ in 0..d.len()? {
+            let (partition, error) = (d.i32()?, d.i16()?);
+            d.i64()?; // timestamp
+            let offset = d.i64()?;
+            if error != 0 {
+                return Err(io::Error::other(format!("partição {}: {} (código {})", partition, error_name(error), error)));
+            }
+            offsets.insert(partition, offset);
+        }
+    }
+    Ok(offsets)
+}
+
+/// A message read from a partition.
+#[derive(Debug, Clone)]
+pub struct Record {
+    pub partition: i32,
+    pub offset: i64,
+    /// Milliseconds since the epoch.
+    pub timestamp: i64,
+    pub key: Option<Vec<u8>>,
+    pub value: Option<Vec<u8>>,
+    pub headers: Vec<(String, Option<Vec<u8>>)>,
+}
+
+/// What one fetch returned for a partition.
+struct PartitionFetch {
+    partition: i32,
+    error: i16,
+    records: Vec<Record>,
+    /// Offset to fetch next, past every batch read (including skipped ones).
+    next_offset: Option<i64>,
+    /// Batches left out because they are compressed.
+    compressed: usize,
+}
+
+/// Records of the v2 record batches in `data`, from `from` on. A batch cut at the end of the
+/// response (the broker stops at the size limit) is left for the next fetch.
+fn decode_batches(partition: i32, from: i64, data: &[u8], fetched: &mut PartitionFetch) -> io::Result<()> {
+    let mut d = Decoder(data);
+    while d.0.len() >= 12 {
+        let base_offset = d.i64()?;
+        let len = d.i32()?.max(0) as usize;
+        if d.0.len() < len {
+            break;
+        }
+        let mut batch = Decoder(d.take(len)?);
+        batch.i32()?; // partition_leader_epoch
+        if batch.take(1)?[0] != 2 {
+            // Message sets of the old formats (magic 0 and 1) carry one message per entry
+            fetched.next_offset = Some(base_offset + 1);
+            continue;
+        }
+        batch.i32()?; // crc
+        let attributes = batch.i16()?;
+        let last_offset_delta = batch.i32()?;
+        let first_timestamp = batch.i64()?;
+        batch.take(8 + 8 + 2 + 4)?; // max_timestamp, producer_id, producer_epoch, base_sequence
+        let count = batch.i32()?.max(0);
+        fetched.next_offset = Some(base_offset + last_offset_delta as i64 + 1);
+        // Transaction markers are not messages
+        if attributes & 0x20 != 0 {
+            continue;
+        }
+        if attributes & 0x07 != 0 {
+            fetched.compressed += 1;
+            continue;
+        }
+        for _ in 0..count {
+            let len = batch.varint()?.max(0) as usize;
+            let mut record = Decoder(batch.take(len)?);
+            record.take(1)?; // attributes
+            let timestamp = first_timestamp + record.varint()?;
+            let offset = base_offset + record.varint()?;
+            let key = record.varint_bytes()?;
+            let value = record.varint_bytes()?;
+            let mut headers = Vec::new();
+            for _ in 0..record.varint()?.max(0) {
+                let name = record.varint_bytes()?.unwrap_or_default();
+                headers.push((String::from_utf8_lossy(&name).into_owned(), record.varint_bytes()?));
+            }
+            if offset >= from {
+                fetched.records.push(Record { partition, offset, timestamp, key, value, headers });
+            }
+        }
+    }
+    Ok(())
+}
+
+/// Read the next messages of `topic` from the given (partition, offset) pairs.
+fn fetch(conn: &mut Connection, topic: &str, positions: &[(i32, i64)]) -> io::Result<Vec<PartitionFetch>> {
+    let mut body = Encoder::default();
+    body.i32(-1).i32(FETCH_WAIT_MS).i32(1).i32(FETCH_MAX_BYTES);
+    body.0.push(0); // isolation_level: read uncommitted
+    body.i32(1).string(topic).i32(positions.len() as i32);
+    for (partition, offset) in positions {
+        body.i32(*partition).i64(*offset).i32(FETCH_MAX_BYTES);
+    }
+    let response = conn.call(FETCH, &body)?;
+    let mut d = Decoder(&response);
+    d.i32()?; // throttle_time_ms
+    let mut results = Vec::new();
+    for _ in 0..d.len()? {
+        d.string()?;
+        for _ in 0..d.len()? {
+            let (partition, error) = (d.i32()?, d.i16()?);
+            d.i64()?; // high_watermark
+            d.i64()?; // last_stable_offset
+            for _ in 0..d.len()? {
+                d.i64()?; // aborted producer_id
+                d.i64()?; // aborted first_offset
+            }
+            let size = d.i32()?.max(0) as usize;
+            let data = d.take(size)?;
+            let mut fetched = PartitionFetch { partition, error, records: Vec::new(), next_offset: None, compressed: 0 };
+            let from = positions.iter().find(|(p, _)| *p == partition).map(|(_, o)| *o).unwrap_or(0);
+            decode_batches(partition, from, data, &mut fetched)?;
+            results.push(fetched);
+        }
+    }
+    Ok(results)
+}
+
+/// A message to produce.
+struct Outgoing {
+    key: Option<Vec<u8>>,
+    value: Vec<u8>,
+    headers: Vec<(String, Vec<u8>)>,
+}
+
+/// CRC-32C (Castagnoli), the checksum of v2 record batches.
+fn crc32c(data: &[u8]) -> u32 {
+    let mut crc = !0u32;
+    for byte in data {
+        crc ^= *byte as u32;
+        for _ in 0..8 {
+            crc = if crc & 1 != 0 { (crc >> 1) ^ 0x82f6_3b78 } else { crc >> 1 };
+        }
+    }
+    !crc
+}
+
+/// `messages` as one uncompressed v2 record batch, timestamped `now` (milliseconds).
+fn encode_batch(messages: &[&Outgoing], now: i64) -> Vec<u8> {
+    let mut body = Encoder::default();
+    body.i16(0).i32(messages.len() as i32 - 1).i64(now).i64(now);
+    body.i64(-1).i16(-1).i32(-1); // no idempotence: producer_id, producer_epoch, base_sequence
+    body.i32(messages.len() as i32);
+    for (delta, message) in messages.iter().enumerate() {
+        let mut record = Encoder::default();
+        record.0.push(0); // attributes
+        record.varint(0).varint(delta as i64);
+        record.varint_bytes(message.key.as_deref()).varint_bytes(Some(&message.value));
+        record.varint(message.headers.len() as i64);
+        for (name, value) in &message.headers {
+            record.varint_bytes(Some(name.as_bytes())).varint_bytes(Some(value));
+        }
+        body.varint(record.0.len() as i64);
+        body.0.extend_from_slice(&record.0);
+    }
+    let mut batch = Encoder::default();
+    batch.i64(0).i32(4 + 1 + 4 + body.0.len() as i32).i32(-1);
+    batch.0.push(2); // magic
+    batch.0.extend_from_slice(&crc32c(&body.0).to_be_bytes());
+    batch.0.extend_from_slice(&body.0);
+    batch.0
+}
+
+/// Write `messages` to one partition of `topic`; returns the offset of the first one.
+fn produce(conn: &mut Connection, topic: &str, partition: i32, messages: &[&Outgoing], now: i64) -> io::Result<i64> {
+    let batch = encode_batch(messages, now);
+    let mut body = Encoder::default();
+    body.i16(-1).i16(ACKS_LEADER).i32(TIMEOUT.as_millis() as i32); // no transactional_id
+    body.i32(1).string(topic).i32(1).i32(partition).i32(batch.len() as i32);
+    body.0.extend_from_slice(&batch);
+    let response = conn.call(PRODUCE, &body)?;
+    let mut d = Decoder(&response);
+    let mut base_offset = None;
+    for _ in 0..d.len()? {
+        d.string()?;
+        for _ in 0..d.len()? {
+            let (index, error, offset) = (d.i32()?, d.i16()?, d.i64()?);
+            d.i64()?; // log_append_time
+            if error != 0 {
+                return Err(io::Error::other(format!("partição {}: {} (código {})", index, error_name(error), error)));
+            }
+            base_offset = Some(offset);
+        }
+    }
+    base_offset.ok_or_else(|| io::Error::new(io::ErrorKind::InvalidData, "resposta do broker sem a partição"))
+}
+
+/// Broker list for the project and where it came from: `--brokers`, then `KAFKA_BROKERS` (or
+/// `KAFKA_BOOTSTRAP_SERVERS`) from the environment, `.env`, `dx dev-env export` and the code's default.
+pub(crate) fn resolve_brokers(project_dir: &Path, flag: Option<String>) -> (String, String) {
+    const VARS: &[&str] = &["KAFKA_BROKERS", "KAFKA_BOOTSTRAP_SERVERS"];
+    if let Some(brokers) = flag {
+        return (brokers, "--brokers".to_string());
+    }
+    for var in VARS {
+        if let Ok(v) = std::env::var(var).map(|v| v.trim().to_string()) {
+            if !v.is_empty() {
+                return (v, format!("{} do ambiente", var));
+            }
+        }
+    }
+    let dotenv = dotenv_values(project_dir);
+    for var in VARS {
+        if let Some(v) = dotenv.get(*var) {
+            return (v.clone(), format!("{} do .env", var));
+        }
+    }
+    let composed = crate::env_export::compose(project_dir);
+    for var in VARS {
+        if let Some(v) = composed.get(*var) {
+            return (v.value.clone(), format!("{} ({})", var, v.source));
+        }
+    }
+    let scanned = crate::dev_env::scan(project_dir);
+    for var in VARS {
+        if let Some(default) = scanned.iter().find(|v| v.name == *var).and_then(|v| v.default.clone()) {
+            return (default, format!("padrão de {} no código", var));
+        }
+    }
+    (DEFAULT_BROKERS.to_string(), "padrão".to_string())
+}
+
+/// `KEY=value` pairs of the project's `.env` (quotes removed).
+pub(crate) fn dotenv_values(project_dir: &Path) -> BTreeMap<String, String> {
+    let content = std::fs::read_to_string(project_dir.join(".env")).unwrap_or_default();
+    content
+        .lines()
+        .filter_map(|l| {
+            let l = l.trim();
+            let (key, value) = l.strip_prefix("export ").unwrap_or(l).split_once('=')?;
+            let value = value.trim().trim_matches(|c| c == '"' || c == '\'');
+            (!key.trim().starts_with('#') && !value.is_empty()).then(|| (key.trim().to_string(), value.to_string()))
+        })
+        .collect()
+}
+
+/// Connect to the first reachable broker of a comma-separated list.
+fn connect(brokers: &str) -> Result<(Connection, String), String> {
+    let mut errors = Vec::new();
+    for address in brokers.split(',').map(str::trim).filter(|a| !a.is_empty()) {
+        let address = address.strip_prefix("PLAINTEXT://").unwrap_or(address);
+        match Connection::open(address) {
+            Ok(conn) => return Ok((conn, address.to_string())),
+            Err(e) => errors.push(format!("{}: {}", address, e)),
+        }
+    }
+    Err(errors.join("; "))
+}
+
+/// Connection to the cluster controller, where topics are created and deleted. Falls back to the
+/// bootstrap broker when the controller's advertised address is not reachable from this machine
+/// (e.g. `kafka:9092` inside Docker); Redpanda and KRaft brokers forward the request.
+fn controller(conn: Connection, address: &str, meta: &Metadata) -> Connection {
+    match meta.brokers.get(&meta.controller) {
+        Some(controller) if controller != address => Connection::open(controller).unwrap_or(conn),
+        _ => conn,
+    }
+}
+
+/// A topic name the project uses and where it was found.
+#[derive(Debug, Clone)]
+pub struct DetectedTopic {
+    pub name: String,
+    /// Variable (`KAFKA_TOPIC_USERS`) or read site (`src/consumer.ts:12`)
+    pub source: String,
+}
+
+/// Source expressions followed by topic name literals: kafka-go's `Topic:`, kafkajs' `topic:`,
+/// Spring's `@KafkaListener(topics = ...)`, Java's `ProducerRecord` and kafka-python's consumer.
+const TOPIC_PATTERNS: &[&str] = &[
+    "Topic:",
+    "topic:",
+    "topics =",
+    "topics=",
+    "topic =",
+    "topic=",
+    "ProducerRecord<>(",
+    "ProducerRecord(",
+    "KafkaConsumer(",
+];
+
+fn is_topic_name(name: &str) -> bool {
+    !name.is_empty() && name.len() <= 249 && name.chars().all(|c| c.is_ascii_alphanumeric() || matches!(c, '.' | '_' | '-'))
+}
+
+/// Whether `name` is a dead-letter topic (`users.dlq`, `orders-DLT`...), which holds the messages
+/// that could not be handled rather than events of its own.
+pub(crate) fn is_dead_letter(name: &str) -> bool {
+    let lower = name.to_lowercase();
+    ["dlq", "dlt", "dead-letter", "deadletter"].iter().any(|suffix| {
+        lower.strip_suffix(suffix).is_some_and(|rest| rest.is_empty() || rest.ends_with(['.', '_', '-']))
+    })
+}
+
+/// String literals right after `rest` (a single one, or a `{...}`/`[...]` list of them).
+pub(crate) fn literals_after(rest: &str) -> Vec<String> {
+    let rest = rest.trim_start();
+    let rest = rest.strip_prefix(['{', '[']).unwrap_or(rest);
+    let mut names = Vec::new();
+    let mut rest = rest.trim_start();
+    while let Some(quote) = rest.chars().next().filter(|c| matches!(c, '"' | '\'' | '`')) {
+        let Some(end) = rest[1..].find(quote) else { break };
+        names.push(rest[1..1 + end].to_string());
+        rest = rest[end + 2..].trim_start();
+        match rest.strip_prefix(',') {
+            Some(r) => rest = r.trim_start(),
+            None => break,
+        }
+    }
+    names
+}
+
+/// Topics the project uses: values (or code defaults) of `*TOPIC*` variables, then literals in the
+/// source next to Kafka client calls.
+pub fn detect_topics(project_dir: &Path) -> Vec<DetectedTopic> {
+    let mut found: BTreeMap<String, String> = BTreeMap::new();
+    let dotenv = dotenv_values(project_dir);
+    for var in crate::dev_env::scan(project_dir).into_iter().filter(|v| v.name.contains("TOPIC")) {
+        let value = std::env::var(&var.name).ok().or_else(|| dotenv.get(&var.name).cloned()).or(var.default);
+        for name in value.iter().flat_map(|v| v.split(',')).map(str::trim).filter(|n| is_topic_name(n)) {
+            found.entry(name.to_string()).or_insert_with(|| var.name.clone());
+        }
+    }
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(project_dir, &mut files);
+    files.sort();
+    for file in files {
+        let Ok(content) = std::fs::read_to_string(&file) else { continue };
+        let rel = file.strip_prefix(project_dir).unwrap_or(&file).to_string_lossy().replace('\\', "/");
+        for (i, line) in content.lines().enumerate() {
+            for pattern in TOPIC_PATTERNS {
+                for (at, _) in line.match_indices(pattern) {
+                    for name in literals_after(&line[at + pattern.len()..]).into_iter().filter(|n| is_topic_name(n)) {
+                        found.entry(name).or_insert_with(|| format!("{}:{}", rel, i + 1));
+                    }
+                }
+            }
+        }
+    }
+    found.into_iter().map(|(name, source)| DetectedTopic { name, source }).collect()
+}
+
+fn project_dir_or_cwd(dir: Option<PathBuf>) -> PathBuf {
+    dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")))
+}
+
+/// Connect to the project's broker and read the cluster metadata, explaining failures.
+fn open_cluster(project_dir: &Path, brokers: Option<String>) -> Result<(Connection, String, Metadata), i32> {
+    let (brokers, origin) = resolve_brokers(project_dir, brokers);
+    let connected = connect(&brokers).and_then(|(mut conn, address)| match metadata(&mut conn) {
+        Ok(meta) => Ok((conn, address, meta)),
+        Err(e) => Err(format!("{}: {}", address, e)),
+    });
+    match connected {
+        Ok(cluster) => {
+            eprintln!("Broker: {} ({})", cluster.1, origin);
+            Ok(cluster)
+        }
+        Err(e) => {
+            eprintln!("Não foi possível falar com o broker Kafka {} ({}): {}", brokers, origin, e);
+            eprintln!("Suba o broker local com: dx dev-services run (ou informe outro com --brokers <host:porta>)");
+            Err(2)
+        }
+    }
+}
+
+/// `dx dev-kafka topics`: list the broker's topics next to the ones the project uses.
+pub fn cmd_list(dir: Option<PathBuf>, brokers: Option<String>, format: TopicsFormat) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let detected = detect_topics(&project_dir);
+    let (_, address, meta) = match open_cluster(&project_dir, brokers) {
+        Ok(cluster) => cluster,
+        Err(code) => return code,
+    };
+    let topics: Vec<&Topic> = meta.topics.iter().filter(|t| !t.internal).collect();
+    let exists = |name: &str| meta.topics.iter().any(|t| t.name == name);
+
+    if format == TopicsFormat::Json {
+        let doc = serde_json::json!({
+            "broker": address,
+            "topics": topics.iter().map(|t| serde_json::json!({
+                "name": t.name,
+                "partitions": t.partitions,
+                "replication_factor": t.replication_factor,
+            })).collect::<Vec<_>>(),
+            "detected": detected.iter().map(|t| serde_json::json!({
+                "name": t.name,
+                "source": t.source,
+                "exists": exists(&t.name),
+            })).collect::<Vec<_>>(),
+        });
+        println!("{}", serde_json::to_string_pretty(&doc).unwrap_or_default());
+        return 0;
+    }
+
+    let width = topics.iter().map(|t| t.name.len()).chain(detected.iter().map(|t| t.name.len())).max().unwrap_or(0);
+    println!("Tópicos no broker ({}):", topics.len());
+    if topics.is_empty() {
+        println!("  (nenhum)");
+    }
+    for t in &topics {
+        println!("  {:width$}  {} partição(ões), replicação {}", t.name, t.partitions, t.replication_factor, width = width);
+    }
+    if !detected.is_empty() {
+        println!("\nTópicos usados pelo projeto ({}):", detected.len());
+        for t in &detected {
+            let mark = if exists(&t.name) { "✓" } else { "✗" };
+            println!("  {} {:width$}  {}", mark, t.name, t.source, width = width);
+        }
+        if detected.iter().any(|t| !exists(&t.name)) {
+            println!("\nCrie os que faltam com: dx dev-kafka topics create");
+        }
+    }
+    0
+}
+
+/// Print per-topic results; returns how many failed.
+fn report(results: &TopicResults, done: &str) -> usize {
+    let mut failed = 0;
+    for (name, error) in results {
+        match error {
+            None => println!("  ✓ {}: {}", name, done),
+            Some((TOPIC_ALREADY_EXISTS, _)) => println!("  = {}: já existe", name),
+            Some((code, message)) => {
+                failed += 1;
+                let message = message.as_deref().filter(|m| !m.is_empty()).unwrap_or(error_name(*code));
+                println!("  ✗ {}: {} (código {})", name, message, code);
+            }
+        }
+    }
+    failed
+}
+
+/// `dx dev-kafka topics create [<tópico>...]`: create the given topics or, without names, the ones
+/// the project uses that the broker does not have yet.
+pub fn cmd_create(dir: Option<PathBuf>, brokers: Option<String>, names: Vec<String>, partitions: i32, replication_factor: i16) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    if let Some(bad) = names.iter().find(|n| !is_topic_name(n)) {
+        eprintln!("Nome de tópico inválido: '{}' (use letras, números, '.', '_' ou '-')", bad);
+        return 2;
+    }
+    let detected = if names.is_empty() { detect_topics(&project_dir) } else { Vec::new() };
+    let (conn, address, meta) = match open_cluster(&project_dir, brokers) {
+        Ok(cluster) => cluster,
+        Err(code) => return code,
+    };
+    let names: Vec<String> = if names.is_empty() {
+        if detected.is_empty() {
+            println!("Nenhum tópico detectado no projeto. Informe os nomes: dx dev-kafka topics create <tópico>...");
+            return 0;
+        }
+        let missing: Vec<String> =
+            detected.into_iter().filter(|t| !meta.topics.iter().any(|b| b.name == t.name)).map(|t| t.name).collect();
+        if missing.is_empty() {
+            println!("Todos os tópicos usados pelo projeto já existem no broker.");
+            return 0;
+        }
+        missing
+    } else {
+        names
+    };
+    let mut conn = controller(conn, &address, &meta);
+    println!("Criando {} tópico(s) ({} partição(ões), replicação {}):", names.len(), partitions, replication_factor);
+    match create_topics(&mut conn, &names, partitions, replication_factor) {
+        Ok(results) if report(&results, "criado") == 0 => 0,
+        Ok(_) => 1,
+        Err(e) => {
+            eprintln!("Erro ao criar os tópicos: {}", e);
+            1
+        }
+    }
+}
+
+/// `dx dev-kafka topics delete <tópico>...`: delete topics from the broker.
+pub fn cmd_delete(dir: Option<PathBuf>, brokers: Option<String>, names: Vec<String>) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let (conn, address, meta) = match open_cluster(&project_dir, brokers) {
+        Ok(cluster) => cluster,
+        Err(code) => return code,
+    };
+    let (present, absent): (Vec<String>, Vec<String>) = names.into_iter().partition(|n| meta.topics.iter().any(|t| &t.name == n));
+    for name in &absent {
+        println!("  - {}: não existe no broker", name);
+    }
+    if present.is_empty() {
+        return if absent.is_empty() { 0 } else { 1 };
+    }
+    let mut conn = controller(conn, &address, &meta);
+    match delete_topics(&mut conn, &present) {
+        Ok(results) if report(&results, "removido") == 0 && absent.is_empty() => 0,
+        Ok(_) => 1,
+        Err(e) => {
+            eprintln!("Erro ao remover os tópicos: {}", e);
+            1
+        }
+    }
+}
+
+/// Message filters of `dx dev-kafka consume`.
+struct Filter {
+    key: Option<String>,
+    /// `name=value` headers (exact value) or `name` alone (header present).
+    headers: Vec<(String, Option<String>)>,
+}
+
+impl Filter {
+    fn matches(&self, record: &Record) -> bool {
+        if let Some(key) = &self.key {
+            if record.key.as_deref() != Some(key.as_bytes()) {
+                return false;
+            }
+        }
+        self.headers.iter().all(|(name, value)| {
+            record.headers.iter().any(|(n, v)| n == name && value.as_ref().is_none_or(|value| v.as_deref() == Some(value.as_bytes())))
+        })
+    }
+}
+
+/// UTC ISO 8601 time of a Kafka timestamp (milliseconds since the epoch).
+pub(crate) fn format_timestamp(ms: i64) -> String {
+    let (secs, millis) = (ms.div_euclid(1000), ms.rem_euclid(1000));
+    let (days, rem) = (secs.div_euclid(86_400), secs.rem_euclid(86_400));
+    // Civil date from days since 1970-01-01 (Howard Hinnant's algorithm)
+    let z = days + 719_468;
+    let era = z.div_euclid(146_097);
+    let doe = z.rem_euclid(146_097);
+    let yoe = (doe - doe / 1460 + doe / 36_524 - doe / 146_096) / 365;
+    let doy = doe - (365 * yoe + yoe / 4 - yoe / 100);
+    let mp = (5 * doy + 2) / 153;
+    let day = doy - (153 * mp + 2) / 5 + 1;
+    let month = if mp < 10 { mp + 3 } else { mp - 9 };
+    let year = yoe + era * 400 + (month <= 2) as i64;
+    format!("{:04}-{:02}-{:02}T{:02}:{:02}:{:02}.{:03}Z", year, month, day, rem / 3600, rem % 3600 / 60, rem % 60, millis)
+}
+
+fn lossy(bytes: &Option<Vec<u8>>) -> Option<String> {
+    bytes.as_ref().map(|b| String::from_utf8_lossy(b).into_owned())
+}
+
+/// The value as JSON when it is JSON, else as a string.
+fn json_value(value: &Option<Vec<u8>>) -> serde_json::Value {
+    match value {
+        None => serde_json::Value::Null,
+        Some(bytes) => serde_json::from_slice(bytes).unwrap_or_else(|_| serde_json::Value::String(String::from_utf8_lossy(bytes).into_owned())),
+    }
+}
+
+fn print_record(topic: &str, record: &Record, format: ConsumeFormat) {
+    if format == ConsumeFormat::Jsonl {
+        let headers: serde_json::Map<String, serde_json::Value> =
+            record.headers.iter().map(|(name, value)| (name.clone(), lossy(value).into())).collect();
+        let line = serde_json::json!({
+            "topic": topic,
+            "partition": record.partition,
+            "offset": record.offset,
+            "timestamp": format_timestamp(record.timestamp),
+            "key": lossy(&record.key),
+            "headers": headers,
+            "value": json_value(&record.value),
+        });
+        println!("{}", line);
+        return;
+    }
+    let key = lossy(&record.key).map(|k| format!("  key={}", k)).unwrap_or_default();
+    println!("{}[{}]@{}  {}{}", topic, record.partition, record.offset, format_timestamp(record.timestamp), key);
+    for (name, value) in &record.headers {
+        println!("  {}: {}", name, lossy(value).unwrap_or_else(|| "(nulo)".to_string()));
+    }
+    match json_value(&record.value) {
+        serde_json::Value::Null => println!("(sem valor)"),
+        serde_json::Value::String(text) => println!("{}", text),
+        value => println!("{}", serde_json::to_string_pretty(&value).unwrap_or_default()),
+    }
+    println!();
+    let _ = io::stdout().flush();
+}
+
+/// Connection to a partition leader, falling back to the bootstrap broker when the advertised
+/// address is not reachable from this machine.
+fn leader_connection(meta: &Metadata, leader: i32, bootstrap: &str) -> io::Result<Connection> {
+    match meta.brokers.get(&leader) {
+        Some(address) => Connection::open(address).or_else(|_| Connection::open(bootstrap)),
+        None => Connection::open(bootstrap),
+    }
+}
+
+/// `dx dev-kafka consume <tópico>`: print the topic's messages as they arrive (or all of them with
+/// `from_beginning`), keeping those that match the key and header filters. Stops after
+/// `max_messages` matches, if given.
+pub fn cmd_consume(
+    dir: Option<PathBuf>,
+    brokers: Option<String>,
+    topic: String,
+    from_beginning: bool,
+    key: Option<String>,
+    headers: Vec<String>,
+    format: ConsumeFormat,
+    max_messages: Option<usize>,
+) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let headers: Vec<(String, Option<String>)> = headers
+        .iter()
+        .map(|h| match h.split_once('=') {
+            Some((name, value)) => (name.trim().to_string(), Some(value.to_string())),
+            None => (h.trim().to_string(), None),
+        })
+        .collect();
+    if headers.iter().any(|(name, _)| name.is_empty()) {
+        eprintln!("Filtro de header inválido: use --header <nome>=<valor> ou --header <nome>");
+        return 2;
+    }
+    let filter = Filter { key, headers };
+    let (_, address, meta) = match open_cluster(&project_dir, brokers) {
+        Ok(cluster) => cluster,
+        Err(code) => return code,
+    };
+    let Some(info) = meta.topics.iter().find(|t| t.name == topic) else {
+        let names: Vec<&str> = meta.topics.iter().filter(|t| !t.internal).map(|t| t.name.as_str()).collect();
+        eprintln!("O tópico '{}' não existe no broker (tópicos: {}).", topic, if names.is_empty() { "nenhum".to_string() } else { names.join(", ") });
+        return 1;
+    };
+
+    let mut conns: BTreeMap<i32, Connection> = BTreeMap::new();
+    let mut offsets: BTreeMap<i32, i64> = BTreeMap::new();
+    let by_leader = |offsets: &BTreeMap<i32, i64>| {
+        let mut groups: BTreeMap<i32, Vec<(i32, i64)>> = BTreeMap::new();
+        for (partition, leader) in &info.leaders {
+            groups.entry(*leader).or_default().push((*partition, offsets.get(partition).copied().unwrap_or(0)));
+        }
+        groups
+    };
+    for (leader, positions) in by_leader(&offsets) {
+        let partitions: Vec<i32> = positions.iter().map(|(p, _)| *p).collect();
+        let start = leader_connection(&meta, leader, &address)
+            .and_then(|mut conn| list_offsets(&mut conn, &topic, &partitions, if from_beginning { EARLIEST } else { LATEST }).map(|o| (conn, o)));
+        match start {
+            Ok((conn, start)) => {
+                conns.insert(leader, conn);
+                offsets.extend(start);
+            }
+            Err(e) => {
+                eprintln!("Erro ao ler os offsets de {}: {}", topic, e);
+                return 1;
+            }
+        }
+    }
+    eprintln!(
+        "Consumindo {} ({} partição(ões)) {}; Ctrl+C para parar.",
+        topic,
+        info.leaders.len(),
+        if from_beginning { "desde o início" } else { "a partir de agora" }
+    );
+
+    let mut printed = 0;
+    let mut warned = false;
+    loop {
+        if max_messages.is_some_and(|max| printed >= max) {
+            return 0;
+        }
+        for (leader, positions) in by_leader(&offsets) {
+            let Some(conn) = conns.get_mut(&leader) else { continue };
+            let results = match fetch(conn, &topic, &positions) {
+                Ok(results) => results,
+                Err(e) => {
+                    eprintln!("Erro ao ler {}: {}", topic, e);
+                    return 1;
+                }
+            };
+            for fetched in results {
+                match fetched.error {
+                    0 => {}
+                    // Retention removed what was next: continue from the oldest offset kept
+                    OFFSET_OUT_OF_RANGE => match list_offsets(conn, &topic, &[fetched.partition], EARLIEST) {
+                        Ok(earliest) => {
+                            offsets.extend(earliest);
+                            continue;
+                        }
+                        Err(e) => {
+                            eprintln!("Erro ao ler os offsets de {}: {}", topic, e);
+                            return 1;
+                        }
+                    },
+                    code => {
+                        eprintln!("Erro ao ler {}[{}]: {} (código {})", topic, fetched.partition, error_name(code), code);
+                        return 1;
+                    }
+                }
+                if fetched.compressed > 0 && !warned {
+                    warned = true;
+                    eprintln!("Aviso: mensagens comprimidas (gzip, snappy, lz4, zstd) são ignoradas; desative a compressão no produtor local.");
+                }
+                for record in fetched.records.iter().filter(|r| filter.matches(r)) {
+                    print_record(&topic, record, format);
+                    printed += 1;
+                    if max_messages.is_some_and(|max| printed >= max) {
+                        return 0;
+                    }
+                }
+                if let Some(next) = fetched.next_offset {
+                    offsets.insert(fetched.partition, next);
+                }
+            }
+        }
+    }
+}
+
+/// Kafka's default partitioner hash (murmur2), so keyed messages land where the Java, Go and
+/// JavaScript clients would put them.
+fn murmur2(data: &[u8]) -> u32 {
+    const M: u32 = 0x5bd1_e995;
+    let mut h: u32 = 0x9747_b28c ^ data.len() as u32;
+    let mut chunks = data.chunks_exact(4);
+    for chunk in chunks.by_ref() {
+        let mut k = u32::from_le_bytes(chunk.try_into().unwrap());
+        k = k.wrapping_mul(M);
+        k ^= k >> 24;
+        h = h.wrapping_mul(M) ^ k.wrapping_mul(M);
+    }
+    let tail = chunks.remainder();
+    if !tail.is_empty() {
+        for (i, byte) in tail.iter().enumerate() {
+            h ^= (*byte as u32) << (8 * i);
+        }
+        h = h.wrapping_mul(M);
+    }
+    h ^= h >> 13;
+    h = h.wrapping_mul(M);
+    h ^ (h >> 15)
+}
+
+/// 64 random bits, from the standard library's randomly keyed hasher.
+pub(crate) fn random_u64() -> u64 {
+    use std::hash::{BuildHasher, Hasher};
+    let mut hasher = std::collections::hash_map::RandomState::new().build_hasher();
+    hasher.write_u128(std::time::SystemTime::now().duration_since(std::time::UNIX_EPOCH).unwrap_or_default().as_nanos());
+    hasher.finish()
+}
+
+pub(crate) fn uuid_v4() -> String {
+    let (high, low) = (random_u64(), random_u64());
+    let high = (high & !0xf000) | 0x4000;
+    let low = (low & !(0b11 << 62)) | (0b10 << 62);
+    format!("{:08x}-{:04x}-{:04x}-{:04x}-{:012x}", high >> 32, (high >> 16) & 0xffff, high & 0xffff, low >> 48, low & 0xffff_ffff_ffff)
+}
+
+/// Fill the placeholders of a message template: `{{i}}` (1, 2, ...), `{{uuid}}`, `{{random}}`
+/// (a new value at each occurrence), `{{now}}`, `{{date}}` and `{{timestamp}}` (milliseconds).
+fn render_template(template: &str, index: usize, now: i64) -> String {
+    let mut out = String::with_capacity(template.len());
+    let mut rest = template;
+    while let Some(start) = rest.find("{{") {
+        out.push_str(&rest[..start]);
+        let Some(end) = rest[start..].find("}}") else { break };
+        let placeholder = &rest[start + 2..start + end];
+        match placeholder.trim() {
+            "i" => out.push_str(&index.to_string()),
+            "uuid" => out.push_str(&uuid_v4()),
+            "random" => out.push_str(&(random_u64() % 1_000_000).to_string()),
+            "now" => out.push_str(&format_timestamp(now)),
+            "date" => out.push_str(&format_timestamp(now)[..10]),
+            "timestamp" => out.push_str(&now.to_string()),
+            _ => out.push_str(&rest[start..start + end + 2]),
+        }
+        rest = &rest[start + end + 2..];
+    }
+    out.push_str(rest);
+    out
+}
+
+/// Messages of a file or of stdin: the elements of a JSON array, else one per non-empty line.
+fn split_messages(content: &str) -> Vec<String> {
+    if let Ok(serde_json::Value::Array(items)) = serde_json::from_str(content) {
+        return items.iter().map(|item| item.to_string()).collect();
+    }
+    content.lines().map(str::trim).filter(|l| !l.is_empty()).map(String::from).collect()
+}
+
+/// `dx dev-kafka produce <tópico>`: send `count` copies of each message — `value`, the lines (or
+/// JSON array) of `file` or stdin, or a sample of the cataloged `event` — after filling their
+/// placeholders. Keyed messages go to the partition Kafka's default partitioner picks.
+pub fn cmd_produce(
+    dir: Option<PathBuf>,
+    brokers: Option<String>,
+    topic: String,
+    value: Option<String>,
+    file: Option<PathBuf>,
+    event: Option<String>,
+    event_type: Option<String>,
+    key: Option<String>,
+    headers: Vec<String>,
+    count: usize,
+) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let mut header_templates = Vec::new();
+    for header in &headers {
+        match header.split_once('=') {
+            Some((name, value)) if !name.trim().is_empty() => header_templates.push((name.trim().to_string(), value.to_string())),
+            _ => {
+                eprintln!("Header inválido: '{}' (use --header <nome>=<valor>)", header);
+                return 2;
+            }
+        }
+    }
+    let mut key_field = None;
+    let templates = if let Some(value) = value {
+        vec![value]
+    } else if let Some(name) = event {
+        let sample = match crate::kafka_events::sample(&project_dir, &name, event_type.as_deref()) {
+            Ok(sample) => sample,
+            Err(e) => {
+                eprintln!("{}", e);
+                return 2;
+            }
+        };
+        key_field = sample.key_field;
+        if let Some((header, value)) = sample.type_header.filter(|(h, _)| !header_templates.iter().any(|(n, _)| n == h)) {
+            header_templates.push((header, value));
+        }
+        vec![sample.value]
+    } else {
+        let read = match &file {
+            Some(path) if path.as_os_str() != "-" => std::fs::read_to_string(path),
+            _ if file.is_none() && io::IsTerminal::is_terminal(&io::stdin()) => {
+                eprintln!("Informe as mensagens com --value, --file ou --event (ou pela entrada padrão).");
+                return 2;
+            }
+            _ => io::read_to_string(io::stdin()),
+        };
+        match read {
+            Ok(content) => split_messages(&content),
+            Err(e) => {
+                eprintln!("Erro ao ler as mensagens: {}", e);
+                return 2;
+            }
+        }
+    };
+    if templates.is_empty() {
+        eprintln!("Nenhuma mensagem para enviar.");
+        return 2;
+    }
+
+    let now = std::time::SystemTime::now().duration_since(std::time::UNIX_EPOCH).map_or(0, |d| d.as_millis() as i64);
+    let mut messages = Vec::new();
+    for template in std::iter::repeat_n(&templates, count).flatten() {
+        let index = messages.len() + 1;
+        let value = render_template(template, index, now);
+        let key = match (&key, &key_field) {
+            (Some(key), _) => Some(render_template(key, index, now)),
+            (None, Some(field)) => serde_json::from_str::<serde_json::Value>(&value).ok().and_then(|v| match v.get(field) {
+                Some(serde_json::Value::String(s)) => Some(s.clone()),
+                Some(serde_json::Value::Null) | None => None,
+                Some(other) => Some(other.to_string()),
+            }),
+            (None, None) => None,
+        };
+        let headers = header_templates.iter().map(|(name, value)| (name.clone(), render_template(value, index, now).into_bytes())).collect();
+        messages.push(Outgoing { key: key.map(String::into_bytes), value: value.into_bytes(), headers });
+    }
+
+    let (_, address, meta) = match open_cluster(&project_dir, brokers) {
+        Ok(cluster) => cluster,
+        Err(code) => return code,
+    };
+    let Some(info) = meta.topics.iter().find(|t| t.name == topic) else {
+        eprintln!("O tópico '{}' não existe no broker; crie-o com: dx dev-kafka topics create {}", topic, topic);
+        return 1;
+    };
+    let partitions = info.leaders.len().max(1) as u32;
+    let mut by_partition: BTreeMap<i32, Vec<&Outgoing>> = BTreeMap::new();
+    for (i, message) in messages.iter().enumerate() {
+        // Keyless messages are spread round-robin
+        let partition = match &message.key {
+            Some(key) => (murmur2(key) & 0x7fff_ffff) % partitions,
+            None => i as u32 % partitions,
+        };
+        by_partition.entry(partition as i32).or_default().push(message);
+    }
+
+    let mut conns: BTreeMap<i32, Connection> = BTreeMap::new();
+    for (partition, batch) in &by_partition {
+        let leader = info.leaders.get(partition).copied().unwrap_or(-1);
+        let sent = match conns.entry(leader) {
+            std::collections::btree_map::Entry::Occupied(entry) => Ok(entry.into_mut()),
+            std::collections::btree_map::Entry::Vacant(entry) => leader_connection(&meta, leader, &address).map(|conn| entry.insert(conn)),
+        }
+        .and_then(|conn| produce(conn, &topic, *partition, batch, now));
+        let base_offset = match sent {
+            Ok(offset) => offset,
+            Err(e) => {
+                eprintln!("Erro ao enviar para {}[{}]: {}", topic, partition, e);
+                return 1;
+            }
+        };
+        for (i, message) in batch.iter().enumerate() {
+            let key = lossy(&message.key).map(|k| format!("  key={}", k)).unwrap_or_default();
+            println!("{}[{}]@{}{}", topic, partition, base_offset + i as i64, key);
+        }
+    }
+    eprintln!("{} mensagem(ns) enviada(s) para {}.", messages.len(), topic);
+    0
+}
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+const START_MARKER: &str = "<!-- dx-cli:readme:start -->";
+const END_MARKER: &str = "<!-- dx-cli:readme:end -->";
+/// Comment right after the start marker, so readers know the block is rewritten.
+const NOTICE: &str = "<!-- Gerado por: dx dev-readme generate. Edições dentro deste bloco são substituídas. -->";
+
+/// Markdown table cell: pipes escaped, line breaks flattened.
+fn cell(value: &str) -> String {
+    value.replace('|', "\\|").replace(['\r', '\n'], " ")
+}
+
+/// `Go 1.22`, `Node.js (LTS)`, `Python`: the stack and the version its toolchain pins.
+pub(crate) fn toolchain_line(project_dir: &Path, stack: Stack) -> String {
+    match crate::devcontainer::toolchain_version(project_dir, stack).as_deref() {
+        None | Some("latest") => stack.to_string(),
+        Some("lts") => format!("{} (LTS)", stack),
+        Some(version) => format!("{} {}", stack, version),
+    }
+}
+
+/// The "Primeiros passos" section: prerequisites, how to run and test, the environment variables
+/// read in the code and the HTTP routes it registers.
+fn render_section(project_dir: &Path, stack: Stack) -> (String, usize, usize) {
+    let vars = crate::dev_env::scan(project_dir);
+    let infra = crate::dev_badges::detect_infra(project_dir);
+    let routes = crate::api_client::detect_routes(project_dir);
+    let port = crate::devcontainer::app_port(&vars).or(crate::dockerfile::default_port(stack));
+    let has_compose = crate::dev_services::find_project_compose_file(project_dir).is_some();
+
+    let mut out = format!("{START_MARKER}\n{NOTICE}\n## Primeiros passos\n\n### Pré-requisitos\n\n");
+    out.push_str(&format!("- {}\n", toolchain_line(project_dir, stack)));
+    if !infra.is_empty() {
+        out.push_str(&format!("- Docker, para os serviços locais: {}\n", infra.join(", ")));
+    } else if has_compose {
+        out.push_str("- Docker, para o compose do projeto\n");
+    }
+
+    out.push_str("\n### Como rodar\n\n```sh\n");
+    if !infra.is_empty() || has_compose {
+        for command in crate::task_runner::commands(project_dir, stack, "compose-up") {
+            out.push_str(&format!("{}\n", command));
+        }
+    }
+    for command in crate::task_runner::commands(project_dir, stack, "run") {
+        out.push_str(&format!("{}\n", command));
+    }
+    out.push_str("```\n\n");
+    if let Some(port) = port {
+        out.push_str(&format!("A aplicação escuta em http://localhost:{}.\n\n", port));
+    }
+    let tests = crate::task_runner::commands(project_dir, stack, "test");
+    if !tests.is_empty() {
+        out.push_str(&format!("Testes:\n\n```sh\n{}\n```\n", tests.join("\n")));
+    }
+
+    if !vars.is_empty() {
+        out.push_str("\n### Variáveis de ambiente\n\n| Variável | Padrão | Serviço |\n|---|---|---|\n");
+        for var in &vars {
+            let default = match &var.default {
+                _ if crate::k8s::is_secret(&var.name) => "segredo: defina no ambiente".to_string(),
+                Some(value) => format!("`{}`", cell(value)),
+                None if var.required => "obrigatória".to_string(),
+                None => "—".to_string(),
+            };
+            out.push_str(&format!("| `{}` | {} | {} |\n", var.name, default, cell(&var.service)));
+        }
+    }
+
+    if !routes.is_empty() {
+        out.push_str("\n### Rotas\n\n| Método | Rota | Definida em |\n|---|---|---|\n");
+        for (method, path, location) in &routes {
+            out.push_str(&format!("| {} | `{}` | `{}` |\n", method, cell(path), cell(location)));
+        }
+    }
+    out.push_str(END_MARKER);
+    out.push('\n');
+    (out, vars.len(), routes.len())
+}
+
+/// README content with `block` in place of the managed block, or appended when there is none.
+fn upsert(readme: Option<&str>, name: &str, block: &str) -> String {
+    let Some(readme) = readme else {
+        return format!("# {}\n\n{}", name, block);
+    };
+    if let (Some(start), Some(end)) = (readme.find(START_MARKER), readme.find(END_MARKER)) {
+        if start < end {
+            let end = end + END_MARKER.len();
+            let end = if readme[end..].starts_with('\n') { end + 1 } else { end };
+            return format!("{}{}{}", &readme[..start], block, &readme[end..]);
+        }
+    }
+    let mut content = readme.trim_end().to_string();
+    content.push_str("\n\n");
+    content.push_str(block);
+    content
+}
+
+/// `dx dev-readme generate`: write the "Primeiros passos" section of README.md from what dx
+/// detects (toolchain, services, run and test commands, environment variables, routes), between
+/// markers so a new run only replaces that block. With `check`, only reports whether it is stale.
+pub fn cmd_generate(dir: Option<PathBuf>, save_file: bool, check: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    if stack == Stack::Unknown {
+        eprintln!("Nenhuma stack detectada em {}; nada a documentar.", project_dir.display());
+        return 1;
+    }
+    let (block, vars, routes) = render_section(&project_dir, stack);
+    if !save_file && !check {
+        print!("{}", block);
+        return 0;
+    }
+
+    let path = project_dir.join("README.md");
+    let current = fs::read_to_string(&path).ok();
+    let content = upsert(current.as_deref(), &crate::k8s::resource_name(&project_dir), &block);
+    let up_to_date = current.as_deref() == Some(content.as_str());
+    if check {
+        if up_to_date {
+            println!("✓ Seção \"Primeiros passos\" de {} em dia com o projeto.", path.display());
+            return 0;
+        }
+        println!("✗ Seção \"Primeiros passos\" de {} desatualizada; rode `dx dev-readme generate` para atualizá-la.", path.display());
+        return 1;
+    }
+    if up_to_date {
+        println!("{} já está em dia.", path.display());
+        return 0;
+    }
+    if let Err(e) = crate::audit::write(&path, content) {
+        eprintln!("Erro ao salvar {}: {}", path.display(), e);
+        return 1;
+    }
+    println!("✓ Seção \"Primeiros passos\" atualizada em {} ({} variável(is), {} rota(s)).", path.display(), vars, routes);
+    0
+}
@@ -0,0 +1,291 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Output of `dx dev-routes list`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum RoutesFormat {
+    /// Table of method, route, handler and location
+    Text,
+    /// JSON array with every route
+    Json,
+}
+
+/// An HTTP route registered in the code.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub(crate) struct Route {
+    /// `GET`, `POST`... or `ANY` when the registration accepts every method (`http.HandleFunc`, `Any`).
+    pub method: String,
+    /// Full path, group prefixes included, with parameters as `{id}`.
+    pub path: String,
+    /// Handler as written in the registration (`userHandler.GetAllUsers`), if known.
+    pub handler: Option<String>,
+    /// `file:line` of the registration.
+    pub location: String,
+}
+
+/// Methods of the router APIs: gin and echo (`GET`), chi and fiber (`Get`).
+const GO_METHODS: &[(&str, &str)] = &[
+    ("GET", "GET"),
+    ("POST", "POST"),
+    ("PUT", "PUT"),
+    ("PATCH", "PATCH"),
+    ("DELETE", "DELETE"),
+    ("HEAD", "HEAD"),
+    ("OPTIONS", "OPTIONS"),
+    ("Any", "ANY"),
+    ("Get", "GET"),
+    ("Post", "POST"),
+    ("Put", "PUT"),
+    ("Patch", "PATCH"),
+    ("Delete", "DELETE"),
+    ("Head", "HEAD"),
+    ("Options", "OPTIONS"),
+    ("All", "ANY"),
+];
+
+const HTTP_METHODS: &[&str] = &["GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"];
+
+/// String literal at the start of `s` (after whitespace) and the text after it.
+fn literal(s: &str) -> Option<(&str, &str)> {
+    let s = s.trim_start();
+    let quote = s.chars().next().filter(|c| matches!(c, '"' | '`'))?;
+    let rest = &s[1..];
+    let end = rest.find(quote)?;
+    Some((&rest[..end], &rest[end + 1..]))
+}
+
+/// The last argument of a call whose arguments start at `args` (after the ones already read):
+/// `, mw, h.List)` gives `h.List`; an anonymous function gives "função anônima".
+fn handler(args: &str) -> Option<String> {
+    let mut depth = 0;
+    let mut current = String::new();
+    let mut last = None;
+    for c in args.chars() {
+        match c {
+            '(' | '{' | '[' => depth += 1,
+            ')' | '}' | ']' if depth == 0 => break,
+            ')' | '}' | ']' => depth -= 1,
+            ',' if depth == 0 => {
+                last = Some(std::mem::take(&mut current));
+                continue;
+            }
+            _ => {}
+        }
+        current.push(c);
+    }
+    let arg = if current.trim().is_empty() { last? } else { current };
+    let arg = arg.trim();
+    if arg.starts_with("func(") {
+        Some("função anônima".to_string())
+    } else if arg.is_empty() {
+        None
+    } else {
+        Some(arg.to_string())
+    }
+}
+
+/// Name of the parameter of the closure opened on `code`: `func(r chi.Router) {` gives `r`.
+fn closure_param(code: &str) -> Option<&str> {
+    let at = code.find("func(")?;
+    let name = code[at + 5..].split(|c: char| !(c.is_ascii_alphanumeric() || c == '_')).next()?;
+    (!name.is_empty()).then_some(name)
+}
+
+/// Change in brace depth on `code`, ignoring braces inside string literals.
+fn brace_delta(code: &str) -> i32 {
+    let mut delta = 0;
+    let mut quote = None;
+    let mut escaped = false;
+    for c in code.chars() {
+        match quote {
+            Some(q) => {
+                if escaped {
+                    escaped = false;
+                } else if c == '\\' && q == '"' {
+                    escaped = true;
+                } else if c == q {
+                    quote = None;
+                }
+            }
+            None => match c {
+                '"' | '`' | '\'' => quote = Some(c),
+                '{' => delta += 1,
+                '}' => delta -= 1,
+                _ => {}
+            },
+        }
+    }
+    delta
+}
+
+/// Routes registered in one Go file with gin, echo, chi, fiber or net/http:
+/// - `r.GET("/users", h)` and `r.Get(...)`, with the prefix of router groups (`api := r.Group("/api")`,
+///   including empty ones such as `users.Group("", mw)` and `users.GET("", h)`);
+/// - chi and fiber sub-routers: `r.Route("/users", func(r chi.Router) { r.Get("/", h) })`;
+/// - `r.Handle("GET", "/x", h)` (gin), `r.Method("GET", "/x", h)` (chi);
+/// - `http.HandleFunc("/x", h)` and `mux.Handle("GET /x/{id}", h)` (Go 1.22 patterns).
+pub(crate) fn parse_go(rel: &str, content: &str) -> Vec<Route> {
+    let mut routes = Vec::new();
+    // Router groups by variable: `api := r.Group("/api")`
+    let mut groups: BTreeMap<String, String> = BTreeMap::new();
+    // Closures of `Route`/`Group`: (variable, prefix, brace depth of the closure body)
+    let mut scopes: Vec<(String, String, i32)> = Vec::new();
+    let mut depth = 0;
+
+    for (i, line) in content.lines().enumerate() {
+        let code = line.trim();
+        if code.starts_with("//") {
+            continue;
+        }
+        let location = format!("{}:{}", rel, i + 1);
+        let prefix_of = |name: &str| -> Option<String> {
+            scopes.iter().rev().find(|(var, _, _)| var == name).map(|(_, prefix, _)| prefix.clone()).or_else(|| groups.get(name).cloned())
+        };
+
+        if let Some(at) = code.find(".Group(") {
+            let parent = prefix_of(crate::api_client::receiver(code, at));
+            let args = &code[at + 7..];
+            if let Some((prefix, _)) = literal(args) {
+                // `api := r.Group("/api")` (gin, echo, fiber)
+                let target = code[..at].split_once(":=").or_else(|| code[..at].split_once(" = ")).map(|(t, _)| t.trim());
+                if let Some(target) = target.filter(|t| !t.is_empty() && !t.contains(' ')) {
+                    groups.insert(target.to_string(), format!("{}{}", parent.unwrap_or_default(), prefix));
+                }
+            } else if let Some(param) = closure_param(args) {
+                // `r.Group(func(r chi.Router) { ... })` (chi): same prefix, new middleware stack
+                scopes.push((param.to_string(), parent.unwrap_or_default(), depth + 1));
+            }
+        } else if let Some(at) = code.find(".Route(") {
+            // `r.Route("/users", func(r chi.Router) { ... })` (chi, fiber)
+            let parent = prefix_of(crate::api_client::receiver(code, at)).unwrap_or_default();
+            if let (Some((prefix, _)), Some(param)) = (literal(&code[at + 7..]), closure_param(code)) {
+                scopes.push((param.to_string(), format!("{}{}", parent, prefix.trim_end_matches('/')), depth + 1));
+            }
+        } else if let Some((method, path, rest, receiver_end)) = registration(code) {
+            let receiver = crate::api_client::receiver(code, receiver_end);
+            let prefix = prefix_of(receiver);
+            // An empty path only makes sense on a group: `users.GET("", h)`
+            if path.starts_with('/') || (path.is_empty() && prefix.is_some()) {
+                // `r.Get("/", h)` inside `r.Route("/users", ...)` is `/users`
+                let full = match prefix {
+                    Some(prefix) if !prefix.is_empty() && path == "/" => prefix,
+                    prefix => format!("{}{}", prefix.unwrap_or_default(), path),
+                };
+                routes.push(Route {
+                    method,
+                    path: crate::api_client::normalize_route(&full),
+                    handler: handler(rest.trim_start().trim_start_matches(',')),
+                    location,
+                });
+            }
+        }
+
+        depth += brace_delta(code);
+        scopes.retain(|(_, _, entry)| depth >= *entry);
+    }
+    routes
+}
+
+/// Method, path, remaining arguments and receiver end of a route registration on `code`.
+fn registration(code: &str) -> Option<(String, String, &str, usize)> {
+    for (name, method) in GO_METHODS {
+        let needle = format!(".{}(", name);
+        let Some(at) = code.find(&needle) else { continue };
+        let (path, rest) = literal(&code[at + needle.len()..])?;
+        return Some((method.to_string(), path.to_string(), rest, at));
+    }
+    for needle in [".Handle(", ".HandleFunc(", ".Method(", ".MethodFunc("] {
+        let Some(at) = code.find(needle) else { continue };
+        let (first, rest) = literal(&code[at + needle.len()..])?;
+        // `r.Handle("GET", "/x", h)` (gin) and `r.Method("GET", "/x", h)` (chi)
+        if HTTP_METHODS.contains(&first) {
+            let (path, rest) = literal(rest.trim_start().strip_prefix(',')?)?;
+            return Some((first.to_string(), path.to_string(), rest, at));
+        }
+        // `mux.HandleFunc("GET /users/{id}", h)` (Go 1.22) or `http.HandleFunc("/users", h)`
+        return match first.split_once(' ') {
+            Some((method, path)) if HTTP_METHODS.contains(&method) => Some((method.to_string(), path.trim().to_string(), rest, at)),
+            Some(_) => None,
+            None => Some(("ANY".to_string(), first.to_string(), rest, at)),
+        };
+    }
+    None
+}
+
+/// Routes of every Go file under `root` (tests excluded), sorted by path and method.
+pub(crate) fn detect_go(root: &Path) -> Vec<Route> {
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(root, &mut files);
+    files.retain(|f| f.extension().is_some_and(|e| e == "go"));
+    files.sort();
+    let mut routes = Vec::new();
+    for file in files {
+        let Ok(content) = fs::read_to_string(&file) else { continue };
+        let rel = file.strip_prefix(root).unwrap_or(&file).display().to_string();
+        routes.extend(parse_go(&rel, &content));
+    }
+    sort_routes(&mut routes);
+    routes
+}
+
+fn sort_routes(routes: &mut Vec<Route>) {
+    routes.sort_by(|a, b| (&a.path, &a.method).cmp(&(&b.path, &b.method)));
+    routes.dedup_by(|a, b| a.method == b.method && a.path == b.path);
+}
+
+/// Routes of the project: the Go analyzer for Go code, the generic detection (without handler
+/// names) for Express, FastAPI and Flask.
+pub(crate) fn detect(root: &Path) -> Vec<Route> {
+    let mut routes = detect_go(root);
+    routes.extend(
+        crate::api_client::detect_routes(root)
+            .into_iter()
+            .filter(|(_, _, location)| !location.split(':').next().is_some_and(|file| file.ends_with(".go")))
+            .map(|(method, path, location)| Route { method, path, handler: None, location }),
+    );
+    sort_routes(&mut routes);
+    routes
+}
+
+fn render_table(routes: &[Route]) -> String {
+    let handler_of = |r: &Route| r.handler.clone().unwrap_or_else(|| "-".to_string());
+    let method_w = routes.iter().map(|r| r.method.len()).chain([6]).max().unwrap_or(6);
+    let path_w = routes.iter().map(|r| r.path.chars().count()).chain([4]).max().unwrap_or(4);
+    let handler_w = routes.iter().map(|r| handler_of(r).chars().count()).chain([7]).max().unwrap_or(7);
+    let mut out = format!("  {:<method_w$}  {:<path_w$}  {:<handler_w$}  {}\n", "MÉTODO", "ROTA", "HANDLER", "DEFINIDA EM");
+    for r in routes {
+        out.push_str(&format!("  {:<method_w$}  {:<path_w$}  {:<handler_w$}  {}\n", r.method, r.path, handler_of(r), r.location));
+    }
+    out
+}
+
+/// `dx dev-routes list`: the HTTP routes the code registers, with method, path and handler.
+pub fn cmd_list(dir: Option<PathBuf>, format: RoutesFormat) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let routes = detect(&project_dir);
+    if format == RoutesFormat::Json {
+        let items: Vec<serde_json::Value> = routes
+            .iter()
+            .map(|r| {
+                serde_json::json!({
+                    "method": r.method,
+                    "path": r.path,
+                    "handler": r.handler,
+                    "location": r.location,
+                })
+            })
+            .collect();
+        println!("{}", serde_json::to_string_pretty(&items).unwrap_or_default());
+        return;
+    }
+    if routes.is_empty() {
+        println!("Nenhuma rota HTTP encontrada em {}.", project_dir.display());
+        return;
+    }
+    println!("Rotas HTTP registradas em {} ({}):\n", project_dir.display(), routes.len());
+    print!("{}", render_table(&routes));
+}
@@ -1,20 +1,49 @@
 // SPDX-License-Identifier: MIT OR Apache-2.0
 // Copyright (c) 2025 The dx-cli Contributors
-use std::collections::HashMap;
+use std::collections::{BTreeSet, HashMap};
 use std::fs;
-use std::path::Path;
+use std::path::{Path, PathBuf};
 
+#[derive(Default)]
 pub struct DockerService {
     pub image: String,
+    /// Build context relative to the compose file, used instead of `image`.
+    pub build: Option<String>,
     pub env: HashMap<String, String>,
     pub ports: Vec<u16>,
     pub volumes: Vec<String>,
     pub command: Option<String>,
+    /// Compose profiles; a service with profiles only starts when one of them is active.
+    pub profiles: Vec<String>,
+    /// Services this one waits for, with the compose condition
+    /// ("service_started", "service_healthy" or "service_completed_successfully").
+    pub depends_on: Vec<(String, String)>,
+    pub healthcheck: Option<Healthcheck>,
+    /// `host:ip` entries added to the container's /etc/hosts (e.g. `host.docker.internal:host-gateway`)
+    pub extra_hosts: Vec<String>,
+}
+
+pub struct Healthcheck {
+    /// Command run inside the container (CMD-SHELL form)
+    pub test: String,
+    pub interval: String,
+    pub timeout: String,
+    pub retries: u32,
 }
 
+/// Compose file names looked up in the project root (same order Docker Compose uses).
+const PROJECT_COMPOSE_FILES: [&str; 4] = [
+    "compose.yaml",
+    "compose.yml",
+    "docker-compose.yml",
+    "docker-compose.yaml",
+];
+
 #[derive(Default)]
 pub struct DockerComposeConfig {
     pub version: String,
+    /// Compose project name (top-level `name:`), which namespaces containers, networks and volumes
+    pub name: Option<String>,
     pub services: HashMap<String, DockerService>,
 }
 
@@ -22,6 +51,7 @@ impl DockerComposeConfig {
     pub fn new() -> Self {
         DockerComposeConfig {
             version: "3.8".to_string(),
+            name: None,
             services: HashMap::new(),
         }
     }
@@ -31,22 +61,62 @@ impl DockerComposeConfig {
     }
 
     pub fn to_yaml(&self) -> String {
-        let mut yaml = format!("version: '{}'\nservices:\n", self.version);
+        let mut yaml = format!("version: '{}'\n", self.version);
+        if let Some(name) = &self.name {
+            yaml.push_str(&format!("name: {}\n", name));
+        }
+        yaml.push_str("services:\n");
 
         // Collect all defined volumes
         let mut volumes = Vec::new();
 
-        for (name, service) in &self.services {
+        // Sorted so regenerated files diff cleanly
+        let mut names: Vec<&String> = self.services.keys().collect();
+        names.sort();
+        for name in names {
+            let service = &self.services[name];
             yaml.push_str(&format!("  {}:\n", name));
-            yaml.push_str(&format!("    image: {}\n", service.image));
+            match &service.build {
+                Some(context) => yaml.push_str(&format!("    build: {}\n", context)),
+                None => yaml.push_str(&format!("    image: {}\n", service.image)),
+            }
 
             if let Some(cmd) = &service.command {
                 yaml.push_str(&format!("    command: {}\n", cmd));
             }
 
+            if !service.profiles.is_empty() {
+                yaml.push_str(&format!("    profiles: [{}]\n", service.profiles.join(", ")));
+            }
+
+            if !service.depends_on.is_empty() {
+                yaml.push_str("    depends_on:\n");
+                for (dep, condition) in &service.depends_on {
+                    yaml.push_str(&format!("      {}:\n", dep));
+                    yaml.push_str(&format!("        condition: {}\n", condition));
+                }
+            }
+
+            if let Some(hc) = &service.healthcheck {
+                yaml.push_str("    healthcheck:\n");
+                yaml.push_str(&format!("      test: [\"CMD-SHELL\", \"{}\"]\n", hc.test.replace('"', "\\\"")));
+                yaml.push_str(&format!("      interval: {}\n", hc.interval));
+                yaml.push_str(&format!("      timeout: {}\n", hc.timeout));
+                yaml.push_str(&format!("      retries: {}\n", hc.retries));
+            }
+
+            if !service.extra_hosts.is_empty() {
+                yaml.push_str("    extra_hosts:\n");
+                for host in &service.extra_hosts {
+                    yaml.push_str(&format!("      - '{}'\n", host));
+                }
+            }
+
             if !service.env.is_empty() {
                 yaml.push_str("    environment:\n");
-                for (key, value) in &service.env {
+                let mut env: Vec<(&String, &String)> = service.env.iter().collect();
+                env.sort();
+                for (key, value) in env {
                     let force_block = key == "FLINK_PROPERTIES";
                     if force_block || value.contains('\n') {
                         yaml.push_str(&format!("      {}: |\n", key));
@@ -73,8 +143,8 @@ impl DockerComposeConfig {
 
                     // Extract the volume name (before the colon)
                     if let Some(volume_name) = volume.split(':').next() {
-                        if !volume_name.contains('/') && !volume_name.contains('\\') {
-                            // Likely a named volume, not a bind mount
+                        if !volume_name.contains('/') && !volume_name.contains('\\') && !volume_name.starts_with('.') {
+                            // Likely a named volume, not a bind mount (`.` and `..` are relative paths)
                             volumes.push(volume_name);
                         }
                     }
@@ -85,7 +155,9 @@ impl DockerComposeConfig {
         // Add volumes section if there are any named volumes
         if !volumes.is_empty() {
             yaml.push_str("\nvolumes:\n");
-            for volume in volumes.iter().collect::<std::collections::HashSet<_>>() {
+            volumes.sort();
+            volumes.dedup();
+            for volume in &volumes {
                 yaml.push_str(&format!("  {}:\n", volume));
             }
         }
@@ -94,153 +166,321 @@ impl DockerComposeConfig {
     }
 }
 
-pub fn detect_dependencies(project_dir: &Path) -> DockerComposeConfig {
-    let mut config = DockerComposeConfig::new();
+fn healthcheck(test: &str) -> Option<Healthcheck> {
+    Some(Healthcheck {
+        test: test.to_string(),
+        interval: "5s".to_string(),
+        timeout: "5s".to_string(),
+        retries: 20,
+    })
+}
 
-    // Check for common dependencies in project files
-    if has_postgres_dependency(project_dir) {
-        let mut env = HashMap::new();
-        env.insert("POSTGRES_PASSWORD".to_string(), "example".to_string());
-        env.insert("POSTGRES_DB".to_string(), "app".to_string());
-
-        config.add_service(
-            "postgres",
-            DockerService {
-                image: "postgres:16-alpine".to_string(),
-                env,
-                ports: vec![5432],
-                volumes: vec!["postgres-data:/var/lib/postgresql/data".to_string()],
-                command: None,
-            },
-        );
+/// Compose file already maintained by the project (compose.yaml, docker-compose.yml, ...), if any.
+pub fn find_project_compose_file(project_dir: &Path) -> Option<PathBuf> {
+    PROJECT_COMPOSE_FILES
+        .iter()
+        .map(|name| project_dir.join(name))
+        .find(|p| p.is_file())
+}
+
+/// Profile names declared under `services.*.profiles` in a compose file.
+/// Accepts both the flow form (`profiles: [debug, tools]`) and the block list form.
+pub fn compose_profiles(content: &str) -> BTreeSet<String> {
+    let mut profiles = BTreeSet::new();
+    let mut list_indent: Option<usize> = None;
+    for line in content.lines() {
+        let trimmed = line.trim();
+        if trimmed.is_empty() || trimmed.starts_with('#') {
+            continue;
+        }
+        let indent = line.len() - line.trim_start().len();
+        if let Some(base) = list_indent {
+            if indent >= base && trimmed.starts_with("- ") {
+                profiles.insert(unquote(&trimmed[2..]));
+                continue;
+            }
+            list_indent = None;
+        }
+        if let Some(rest) = trimmed.strip_prefix("profiles:") {
+            let rest = rest.trim();
+            if rest.is_empty() {
+                list_indent = Some(indent);
+            } else {
+                rest.trim_start_matches('[')
+                    .trim_end_matches(']')
+                    .split(',')
+                    .map(unquote)
+                    .filter(|p| !p.is_empty())
+                    .for_each(|p| {
+                        profiles.insert(p);
+                    });
+            }
+        }
     }
+    profiles
+}
+
+/// Profiles passed to the last `dx dev-services run`, kept for `dx prompt`.
+const STATE_FILE: &str = ".dx/dev-services-state.json";
 
-    if has_mysql_dependency(project_dir) {
-        let mut env = HashMap::new();
-        env.insert("MARIADB_ROOT_PASSWORD".to_string(), "example".to_string());
-        env.insert("MARIADB_DATABASE".to_string(), "app".to_string());
-
-        // Use MariaDB for a fully open-source, lighter MySQL-compatible server
-        config.add_service(
-            "mysql",
-            DockerService {
-                image: "mariadb:11".to_string(),
-                env,
-                ports: vec![3306],
-                volumes: vec!["mariadb-data:/var/lib/mysql".to_string()],
-                command: None,
-            },
-        );
+pub fn save_active_profiles(project_dir: &Path, profiles: &[String]) -> std::io::Result<()> {
+    let path = project_dir.join(STATE_FILE);
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
     }
+    crate::lock::write_atomic(&path, serde_json::json!({ "profiles": profiles }).to_string())
+}
+
+pub fn active_profiles(project_dir: &Path) -> Vec<String> {
+    fs::read_to_string(project_dir.join(STATE_FILE))
+        .ok()
+        .and_then(|c| serde_json::from_str::<serde_json::Value>(&c).ok())
+        .and_then(|v| v.get("profiles").cloned())
+        .and_then(|p| serde_json::from_value(p).ok())
+        .unwrap_or_default()
+}
+
+fn unquote(s: &str) -> String {
+    s.trim().trim_matches(|c| c == '"' || c == '\'').to_string()
+}
 
-    if has_kafka_dependency(project_dir) {
-        // Use Redpanda: Kafka API-compatible, lightweight, no-cost for local dev
-        let env = HashMap::new();
-        let redpanda_cmd = "redpanda start --overprovisioned --smp 1 --memory 512M --reserve-memory 0M --node-id 0 --check=false --kafka-addr PLAINTEXT://0.0.0.0:9092,PLAINTEXT_HOST://0.0.0.0:29092 --advertise-kafka-addr PLAINTEXT://kafka:9092,PLAINTEXT_HOST://localhost:29092".to_string();
-
-        config.add_service(
-            "kafka",
-            DockerService {
-                image: "redpandadata/redpanda:latest".to_string(),
-                env,
-                ports: vec![9092, 29092],
-                volumes: vec!["redpanda-data:/var/lib/redpanda/data".to_string()],
-                command: Some(redpanda_cmd),
-            },
-        );
-
-        // Add Kafka UI for local inspection when Kafka is present
-        let mut ui_env = HashMap::new();
-        ui_env.insert("KAFKA_CLUSTERS_0_NAME".to_string(), "local".to_string());
-        ui_env.insert(
-            "KAFKA_CLUSTERS_0_BOOTSTRAPSERVERS".to_string(),
-            "kafka:9092".to_string(),
-        );
-        // Change default UI port to 9093 to avoid conflicts and match N:N mapping
-        ui_env.insert("SERVER_PORT".to_string(), "9093".to_string());
-        config.add_service(
-            "kafka-ui",
-            DockerService {
-                image: "provectuslabs/kafka-ui:latest".to_string(),
-                env: ui_env,
-                ports: vec![9093],
-                volumes: vec![],
-                command: None,
-            },
-        );
+/// Add a Dev Service with dx's defaults (image, ports, volumes, healthcheck) and its
+/// companions (kafka-ui for kafka, taskmanager for jobmanager). Returns false for unknown names.
+pub fn add_known_service(config: &mut DockerComposeConfig, name: &str) -> bool {
+    match name {
+        "postgres" => {
+            let mut env = HashMap::new();
+            env.insert("POSTGRES_PASSWORD".to_string(), "example".to_string());
+            env.insert("POSTGRES_DB".to_string(), "app".to_string());
+
+            config.add_service(
+                "postgres",
+                DockerService {
+                    image: "postgres:16-alpine".to_string(),
+                    env,
+                    ports: vec![5432],
+                    volumes: vec!["postgres-data:/var/lib/postgresql/data".to_string()],
+                    command: None,
+                    healthcheck: healthcheck("pg_isready -U postgres"),
+                    ..Default::default()
+                },
+            );
+        }
+        "mysql" => {
+            let mut env = HashMap::new();
+            env.insert("MARIADB_ROOT_PASSWORD".to_string(), "example".to_string());
+            env.insert("MARIADB_DATABASE".to_string(), "app".to_string());
+
+            // Use MariaDB for a fully open-source, lighter MySQL-compatible server
+            config.add_service(
+                "mysql",
+                DockerService {
+                    image: "mariadb:11".to_string(),
+                    env,
+                    ports: vec![3306],
+                    volumes: vec!["mariadb-data:/var/lib/mysql".to_string()],
+                    command: None,
+                    healthcheck: healthcheck("healthcheck.sh --connect --innodb_initialized"),
+                    ..Default::default()
+                },
+            );
+        }
+        "kafka" | "kafka-ui" => {
+            // Use Redpanda: Kafka API-compatible, lightweight, no-cost for local dev
+            let env = HashMap::new();
+            let redpanda_cmd = "redpanda start --overprovisioned --smp 1 --memory 512M --reserve-memory 0M --node-id 0 --check=false --kafka-addr PLAINTEXT://0.0.0.0:9092,PLAINTEXT_HOST://0.0.0.0:29092 --advertise-kafka-addr PLAINTEXT://kafka:9092,PLAINTEXT_HOST://localhost:29092".to_string();
+
+            config.add_service(
+                "kafka",
+                DockerService {
+                    image: "redpandadata/redpanda:latest".to_string(),
+                    env,
+                    ports: vec![9092, 29092],
+                    volumes: vec!["redpanda-data:/var/lib/redpanda/data".to_string()],
+                    command: Some(redpanda_cmd),
+                    healthcheck: healthcheck("rpk cluster health | grep -E 'Healthy:.+true' || exit 1"),
+                    ..Default::default()
+                },
+            );
+
+            // Add Kafka UI for local inspection when Kafka is present
+            let mut ui_env = HashMap::new();
+            ui_env.insert("KAFKA_CLUSTERS_0_NAME".to_string(), "local".to_string());
+            ui_env.insert(
+                "KAFKA_CLUSTERS_0_BOOTSTRAPSERVERS".to_string(),
+                "kafka:9092".to_string(),
+            );
+            // Change default UI port to 9093 to avoid conflicts and match N:N mapping
+            ui_env.insert("SERVER_PORT".to_string(), "9093".to_string());
+            config.add_service(
+                "kafka-ui",
+                DockerService {
+                    image: "provectuslabs/kafka-ui:latest".to_string(),
+                    env: ui_env,
+                    ports: vec![9093],
+                    volumes: vec![],
+                    command: None,
+                    // Only start the UI once the broker answers, otherwise it boots with an empty cluster list
+                    depends_on: vec![("kafka".to_string(), "service_healthy".to_string())],
+                    ..Default::default()
+                },
+            );
+        }
+        "redis" => {
+            config.add_service(
+                "redis",
+                DockerService {
+                    image: "redis:alpine".to_string(),
+                    env: HashMap::new(),
+                    ports: vec![6379],
+                    volumes: vec!["redis-data:/data".to_string()],
+                    command: None,
+                    healthcheck: healthcheck("redis-cli ping"),
+                    ..Default::default()
+                },
+            );
+        }
+        "mongodb" => {
+            let mut env = HashMap::new();
+            env.insert("MONGO_INITDB_ROOT_USERNAME".to_string(), "root".to_string());
+            env.insert(
+                "MONGO_INITDB_ROOT_PASSWORD".to_string(),
+                "example".to_string(),
+            );
+
+            config.add_service(
+                "mongodb",
+                DockerService {
+                    image: "mongo:7.0".to_string(),
+                    env,
+                    ports: vec![27017],
+                    volumes: vec!["mongodb-data:/data/db".to_string()],
+                    command: None,
+                    healthcheck: healthcheck("mongosh --quiet --eval 'db.adminCommand({ping: 1})'"),
+                    ..Default::default()
+                },
+            );
+        }
+        "rabbitmq" => {
+            // The management image also serves the web console on 15672 (guest/guest from localhost)
+            config.add_service(
+                "rabbitmq",
+                DockerService {
+                    image: "rabbitmq:3-management-alpine".to_string(),
+                    env: HashMap::new(),
+                    ports: vec![5672, 15672],
+                    volumes: vec!["rabbitmq-data:/var/lib/rabbitmq".to_string()],
+                    command: None,
+                    healthcheck: healthcheck("rabbitmq-diagnostics -q ping"),
+                    ..Default::default()
+                },
+            );
+        }
+        "jobmanager" | "taskmanager" => {
+            // Apache Flink dependencies typically require multiple services
+
+            // JobManager service
+            let mut jobmanager_env = HashMap::new();
+            jobmanager_env.insert(
+                "FLINK_PROPERTIES".to_string(),
+                "jobmanager.rpc.address: jobmanager".to_string(),
+            );
+
+            config.add_service(
+                "jobmanager",
+                DockerService {
+                    image: "apache/flink:latest".to_string(),
+                    env: jobmanager_env,
+                    ports: vec![8081], // UI port
+                    volumes: vec!["flink-data:/opt/flink/data".to_string()],
+                    command: None,
+                    ..Default::default()
+                },
+            );
+
+            // TaskManager service
+            let mut taskmanager_env = HashMap::new();
+            taskmanager_env.insert(
+                "FLINK_PROPERTIES".to_string(),
+                "jobmanager.rpc.address: jobmanager\ntaskmanager.numberOfTaskSlots: 1".to_string(),
+            );
+
+            config.add_service(
+                "taskmanager",
+                DockerService {
+                    image: "apache/flink:latest".to_string(),
+                    env: taskmanager_env,
+                    ports: vec![],
+                    volumes: vec!["flink-data:/opt/flink/data".to_string()],
+                    command: None,
+                    depends_on: vec![("jobmanager".to_string(), "service_started".to_string())],
+                    ..Default::default()
+                },
+            );
+        }
+        _ => return false,
+    }
+    true
+}
+
+pub fn detect_dependencies(project_dir: &Path) -> DockerComposeConfig {
+    let mut config = DockerComposeConfig::new();
+
+    // Fingerprints of the detection rules in use (dx rules)
+    let rules = crate::rules::active(project_dir);
+    for rule in &rules.pack.services {
+        let keywords: Vec<&str> = rule.keywords.iter().map(String::as_str).collect();
+        if config.services.contains_key(&rule.service) || !search_for_dependency(project_dir, &keywords) {
+            continue;
+        }
+        match &rule.image {
+            Some(image) => config.add_service(
+                &rule.service,
+                DockerService { image: image.clone(), ports: rule.ports.clone(), ..Default::default() },
+            ),
+            None => {
+                if !add_known_service(&mut config, &rule.service) {
+                    eprintln!("Aviso: serviço '{}' das regras de detecção sem image e desconhecido pelo dx; ignorado.", rule.service);
+                }
+            }
+        }
     }
 
-    if has_redis_dependency(project_dir) {
-        config.add_service(
-            "redis",
-            DockerService {
-                image: "redis:alpine".to_string(),
-                env: HashMap::new(),
-                ports: vec![6379],
-                volumes: vec!["redis-data:/data".to_string()],
-                command: None,
-            },
-        );
+    // Services of the Hex packages an Elixir or Erlang project declares
+    let packages = crate::beam_build::dependencies(project_dir);
+    for rule in &rules.pack.hex_packages {
+        let Some(service) = &rule.service else { continue };
+        if config.services.contains_key(service) || !packages.iter().any(|p| !p.dev && p.name == rule.package) {
+            continue;
+        }
+        if !add_known_service(&mut config, service) {
+            eprintln!("Aviso: serviço '{}' das regras de detecção desconhecido pelo dx; ignorado.", service);
+        }
     }
 
-    if has_mongodb_dependency(project_dir) {
-        let mut env = HashMap::new();
-        env.insert("MONGO_INITDB_ROOT_USERNAME".to_string(), "root".to_string());
-        env.insert(
-            "MONGO_INITDB_ROOT_PASSWORD".to_string(),
-            "example".to_string(),
-        );
-
-        config.add_service(
-            "mongodb",
-            DockerService {
-                image: "mongo:7.0".to_string(),
-                env,
-                ports: vec![27017],
-                volumes: vec!["mongodb-data:/data/db".to_string()],
-                command: None,
-            },
-        );
+    // Services found by detector plugins (company-specific infrastructure)
+    for service in crate::plugins::detect(project_dir) {
+        if config.services.contains_key(&service.name) {
+            continue;
+        }
+        match service.image {
+            Some(image) => config.add_service(
+                &service.name,
+                DockerService { image, ports: service.ports, env: service.env.into_iter().collect(), ..Default::default() },
+            ),
+            None => {
+                if !add_known_service(&mut config, &service.name) {
+                    eprintln!("Aviso: serviço '{}' de um plugin sem image e desconhecido pelo dx; ignorado.", service.name);
+                }
+            }
+        }
     }
 
-    if has_flink_dependency(project_dir) {
-        // Apache Flink dependencies typically require multiple services
-
-        // JobManager service
-        let mut jobmanager_env = HashMap::new();
-        jobmanager_env.insert(
-            "FLINK_PROPERTIES".to_string(),
-            "jobmanager.rpc.address: jobmanager".to_string(),
-        );
-
-        config.add_service(
-            "jobmanager",
-            DockerService {
-                image: "apache/flink:latest".to_string(),
-                env: jobmanager_env,
-                ports: vec![8081], // UI port
-                volumes: vec!["flink-data:/opt/flink/data".to_string()],
-                command: None,
-            },
-        );
-
-        // TaskManager service
-        let mut taskmanager_env = HashMap::new();
-        taskmanager_env.insert(
-            "FLINK_PROPERTIES".to_string(),
-            "jobmanager.rpc.address: jobmanager\ntaskmanager.numberOfTaskSlots: 1".to_string(),
-        );
-
-        config.add_service(
-            "taskmanager",
-            DockerService {
-                image: "apache/flink:latest".to_string(),
-                env: taskmanager_env,
-                ports: vec![],
-                volumes: vec!["flink-data:/opt/flink/data".to_string()],
-                command: None,
-            },
-        );
+    // Services the team profile wants in every project
+    for name in crate::team_profile::effective(project_dir).services {
+        if !config.services.contains_key(&name) && !add_known_service(&mut config, &name) {
+            eprintln!("Aviso: serviço '{}' do perfil da equipe desconhecido pelo dx; ignorado.", name);
+        }
     }
 
     // Add volumes section if there are services with volumes
@@ -253,73 +493,6 @@ pub fn detect_dependencies(project_dir: &Path) -> DockerComposeConfig {
     config
 }
 
-fn has_postgres_dependency(project_dir: &Path) -> bool {
-    // Search for common Postgres-related strings
-    search_for_dependency(
-        project_dir,
-        &[
-            "postgres",
-            "pg",
-            "postgresql",
-            "psycopg",
-            "POSTGRES_URL",
-            "DATABASE_URL",
-        ],
-    )
-}
-
-fn has_mysql_dependency(project_dir: &Path) -> bool {
-    // Search for common MySQL-related strings
-    search_for_dependency(
-        project_dir,
-        &[
-            "mysql",
-            "mariadb",
-            "innodb",
-            "MYSQL_",
-            "DB_CONNECTION=mysql",
-        ],
-    )
-}
-
-fn has_kafka_dependency(project_dir: &Path) -> bool {
-    // Search for Kafka-related strings
-    search_for_dependency(
-        project_dir,
-        &["kafka", "KAFKA_BROKERS", "kafka-go", "spring-kafka"],
-    )
-}
-
-fn has_redis_dependency(project_dir: &Path) -> bool {
-    // Search for Redis-related strings
-    search_for_dependency(
-        project_dir,
-        &["redis", "REDIS_URL", "REDIS_HOST", "redis-client", "predis"],
-    )
-}
-
-fn has_mongodb_dependency(project_dir: &Path) -> bool {
-    // Search for MongoDB-related strings
-    search_for_dependency(
-        project_dir,
-        &["mongodb", "mongo", "MONGO_URI", "mongoose", "mongo-driver"],
-    )
-}
-
-fn has_flink_dependency(project_dir: &Path) -> bool {
-    // Search for Apache Flink-related strings
-    search_for_dependency(
-        project_dir,
-        &[
-            "flink",
-            "org.apache.flink",
-            "flink-connector",
-            "StreamExecutionEnvironment",
-            "DataStream",
-        ],
-    )
-}
-
 fn search_for_dependency(project_dir: &Path, keywords: &[&str]) -> bool {
     // Check configuration files and package manager files first
     if check_config_files(project_dir, keywords) {
@@ -395,6 +568,13 @@ fn check_config_files(project_dir: &Path, keywords: &[&str]) -> bool {
         return true;
     }
 
+    // Elixir - mix.exs, Erlang - rebar.config
+    for manifest in [crate::beam_build::MIX_FILE, crate::beam_build::REBAR_FILE] {
+        if check_file_for_keywords(&project_dir.join(manifest), keywords) {
+            return true;
+        }
+    }
+
     // Check application-specific config files
     // Java Spring - application.properties, application.yml
     let spring_properties_path = project_dir.join("src/main/resources/application.properties");
@@ -451,6 +631,8 @@ fn recursive_scan_directories(project_dir: &Path, keywords: &[&str]) -> bool {
         ".rb",  // Ruby
         ".go",  // Go
         ".php", // PHP
+        ".cs", ".fs", ".csproj", ".fsproj", ".vbproj", ".props", // .NET sources, projects and Directory.*.props
+        ".ex", ".exs", ".erl", ".hrl", // Elixir, Erlang
         ".yml", ".yaml", // YAML config
         ".json", // JSON config
         ".xml",  // XML config
@@ -469,6 +651,10 @@ fn recursive_scan_directories(project_dir: &Path, keywords: &[&str]) -> bool {
         ".github",
         ".idea",
         ".vscode",
+        "bin", // .NET build output
+        "obj",
+        "_build", // mix and rebar3 build output and fetched dependencies
+        "deps",
     ];
 
     // Recursively walk the directory
@@ -535,5 +721,5 @@ pub fn create_docker_compose_file(
     output_path: &Path,
 ) -> std::io::Result<()> {
     let yaml = config.to_yaml();
-    fs::write(output_path, yaml)
+    crate::audit::write(output_path, yaml)
 }
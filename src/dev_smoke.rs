@@ -0,0 +1,287 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `dx dev-test smoke`: a zero-config sanity check of the running application — every route of
+//! `dx dev-routes list` that is safe to call (GET, HEAD, OPTIONS) gets one request, and the status
+//! codes and latencies are reported. The application is started as `dx up` would when nothing
+//! answers on its port.
+
+use std::collections::VecDeque;
+use std::io::{BufRead, BufReader, Read};
+use std::net::{TcpStream, ToSocketAddrs};
+use std::path::{Path, PathBuf};
+use std::process::{Child, Stdio};
+use std::sync::{Arc, Mutex};
+use std::thread;
+use std::time::{Duration, Instant};
+
+use serde::Serialize;
+
+use crate::dev_routes::Route;
+
+/// Methods that read without changing anything; the other routes are skipped.
+const SAFE_METHODS: &[&str] = &["GET", "HEAD", "OPTIONS"];
+/// Value put in the path parameters (`/users/{id}` -> `/users/1`).
+const PARAM_VALUE: &str = "1";
+/// Per-request timeout.
+const REQUEST_TIMEOUT: Duration = Duration::from_secs(10);
+/// Lines of the application's output shown when it does not come up.
+const LOG_TAIL: usize = 20;
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum SmokeFormat {
+    /// Tabela legível
+    Text,
+    /// JSON, para scripts e CI
+    Json,
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "snake_case")]
+enum Outcome {
+    /// 2xx or 3xx
+    Ok,
+    /// 4xx: the route answers, but not to a request without body, parameters or credentials
+    Warning,
+    /// 5xx or no answer
+    Failed,
+    /// Not a safe method
+    Skipped,
+}
+
+impl Outcome {
+    fn symbol(self) -> &'static str {
+        match self {
+            Outcome::Ok => "✓",
+            Outcome::Warning => "!",
+            Outcome::Failed => "✗",
+            Outcome::Skipped => "-",
+        }
+    }
+}
+
+#[derive(Debug, Serialize)]
+struct Check {
+    method: String,
+    path: String,
+    url: String,
+    outcome: Outcome,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    status: Option<u16>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    latency_ms: Option<u64>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+#[derive(Serialize)]
+struct Report<'a> {
+    base_url: &'a str,
+    /// Whether dx started the application for the run
+    started: bool,
+    checks: &'a [Check],
+}
+
+/// The concrete path requested for a route: parameters filled in.
+fn concrete_path(path: &str) -> String {
+    path.split('/')
+        .map(|segment| if segment.starts_with('{') && segment.ends_with('}') { PARAM_VALUE } else { segment })
+        .collect::<Vec<_>>()
+        .join("/")
+}
+
+/// Whether something accepts connections on the host and port of `base_url`.
+fn answers(base_url: &reqwest::Url) -> bool {
+    let (Some(host), Some(port)) = (base_url.host_str(), base_url.port_or_known_default()) else { return false };
+    let Ok(addrs) = (host, port).to_socket_addrs() else { return false };
+    addrs.into_iter().any(|addr| TcpStream::connect_timeout(&addr, Duration::from_millis(500)).is_ok())
+}
+
+/// Keep the last lines of a stream of the application, to explain why it did not come up.
+fn collect_tail(stream: impl Read + Send + 'static, tail: Arc<Mutex<VecDeque<String>>>) {
+    thread::spawn(move || {
+        for line in BufReader::new(stream).lines().map_while(Result::ok) {
+            let mut tail = tail.lock().unwrap_or_else(|e| e.into_inner());
+            if tail.len() == LOG_TAIL {
+                tail.pop_front();
+            }
+            tail.push_back(line);
+        }
+    });
+}
+
+/// Start the application with the Dev Services variables, as `dx up` does, and wait until its port
+/// accepts connections. The output is kept aside and shown only if it does not come up in time.
+fn start_app(project_dir: &Path, base_url: &reqwest::Url, timeout: Duration) -> Result<Child, String> {
+    let mut start = crate::up::start_command(project_dir, Vec::new())?;
+    eprintln!("▶ {} ({})", start.label, start.origin);
+    // Variables already set in the shell win over the composed ones
+    let env: Vec<(String, String)> = crate::env_export::compose(project_dir)
+        .into_iter()
+        .filter(|(k, _)| std::env::var_os(k).is_none())
+        .map(|(k, v)| (k, v.value))
+        .collect();
+    let mut app = start
+        .command
+        .envs(env)
+        .stdin(Stdio::null())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .map_err(|e| format!("Erro ao executar '{}': {}", start.label, e))?;
+    let tail = Arc::new(Mutex::new(VecDeque::new()));
+    if let Some(stdout) = app.stdout.take() {
+        collect_tail(stdout, tail.clone());
+    }
+    if let Some(stderr) = app.stderr.take() {
+        collect_tail(stderr, tail.clone());
+    }
+
+    let started = Instant::now();
+    let failure = loop {
+        if answers(base_url) {
+            return Ok(app);
+        }
+        if let Ok(Some(status)) = app.try_wait() {
+            break format!("A aplicação terminou ({}) antes de responder em {}.", status, base_url);
+        }
+        if started.elapsed() >= timeout {
+            break format!("A aplicação não respondeu em {} após {}s.", base_url, timeout.as_secs());
+        }
+        thread::sleep(Duration::from_millis(200));
+    };
+    crate::supervisor::stop(&mut app);
+    let tail = tail.lock().unwrap_or_else(|e| e.into_inner());
+    let mut message = failure;
+    if !tail.is_empty() {
+        message.push_str("\nÚltimas linhas da saída:\n");
+        for line in tail.iter() {
+            message.push_str(&format!("  {}\n", line));
+        }
+    }
+    Err(message.trim_end().to_string())
+}
+
+fn check(client: &reqwest::blocking::Client, base_url: &reqwest::Url, route: &Route) -> Check {
+    let method = if route.method == "ANY" { "GET".to_string() } else { route.method.clone() };
+    let url = base_url.join(concrete_path(&route.path).trim_start_matches('/')).map(String::from).unwrap_or_default();
+    let mut result = Check { method, path: route.path.clone(), url, outcome: Outcome::Skipped, status: None, latency_ms: None, error: None };
+    if !SAFE_METHODS.contains(&result.method.as_str()) {
+        return result;
+    }
+    let method = reqwest::Method::from_bytes(result.method.as_bytes()).unwrap_or(reqwest::Method::GET);
+    let started = Instant::now();
+    match client.request(method, &result.url).send() {
+        Ok(response) => {
+            let status = response.status();
+            result.status = Some(status.as_u16());
+            result.outcome = match status.as_u16() {
+                200..=399 => Outcome::Ok,
+                400..=499 => Outcome::Warning,
+                _ => Outcome::Failed,
+            };
+        }
+        Err(e) => {
+            result.outcome = Outcome::Failed;
+            result.error = Some(if e.is_timeout() { format!("sem resposta em {}s", REQUEST_TIMEOUT.as_secs()) } else { e.to_string() });
+        }
+    }
+    result.latency_ms = Some(started.elapsed().as_millis() as u64);
+    result
+}
+
+fn render_table(checks: &[Check]) -> String {
+    let status_of = |c: &Check| match (c.outcome, c.status, &c.error) {
+        (Outcome::Skipped, _, _) => "pulada (método não seguro)".to_string(),
+        (_, Some(status), _) => status.to_string(),
+        (_, None, Some(error)) => format!("erro: {}", error),
+        _ => "-".to_string(),
+    };
+    let method_w = checks.iter().map(|c| c.method.len()).chain([6]).max().unwrap_or(6);
+    let path_w = checks.iter().map(|c| c.path.chars().count()).chain([4]).max().unwrap_or(4);
+    let mut out = format!("    {:<method_w$}  {:<path_w$}  {:<6}  {}\n", "MÉTODO", "ROTA", "TEMPO", "STATUS");
+    for c in checks {
+        let latency = c.latency_ms.map_or("-".to_string(), |ms| format!("{} ms", ms));
+        out.push_str(&format!("  {} {:<method_w$}  {:<path_w$}  {:<6}  {}\n", c.outcome.symbol(), c.method, c.path, latency, status_of(c)));
+    }
+    out
+}
+
+pub fn cmd_smoke(dir: Option<PathBuf>, url: Option<String>, no_start: bool, timeout: u64, format: SmokeFormat) -> i32 {
+    let root = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let routes = crate::dev_routes::detect(&root);
+    if routes.is_empty() {
+        eprintln!("Nenhuma rota HTTP encontrada em {}.", root.display());
+        return 2;
+    }
+    let url = url.unwrap_or_else(|| {
+        let stack = crate::dev_config::Stack::detect(&root);
+        let port = crate::devcontainer::app_port(&crate::dev_env::scan(&root)).or(crate::dockerfile::default_port(stack)).unwrap_or(8080);
+        format!("http://localhost:{}", port)
+    });
+    let base_url = match reqwest::Url::parse(&url) {
+        Ok(base_url) if base_url.host_str().is_some() => base_url,
+        _ => {
+            eprintln!("URL inválida: {}", url);
+            return 2;
+        }
+    };
+    let base_url = if base_url.path().ends_with('/') { base_url } else { reqwest::Url::parse(&format!("{}/", base_url)).unwrap_or(base_url) };
+    let shown = base_url.as_str().trim_end_matches('/').to_string();
+
+    let mut app = None;
+    if !answers(&base_url) {
+        if no_start {
+            eprintln!("Nada respondendo em {}. Inicie a aplicação (ex.: dx up) ou rode sem --no-start.", shown);
+            return 2;
+        }
+        match start_app(&root, &base_url, Duration::from_secs(timeout)) {
+            Ok(child) => app = Some(child),
+            Err(e) => {
+                eprintln!("{}", e);
+                return 2;
+            }
+        }
+    }
+
+    let client = match reqwest::blocking::Client::builder().timeout(REQUEST_TIMEOUT).redirect(reqwest::redirect::Policy::none()).build() {
+        Ok(client) => client,
+        Err(e) => {
+            eprintln!("Erro ao criar o cliente HTTP: {}", e);
+            return 1;
+        }
+    };
+    let checks: Vec<Check> = routes.iter().map(|route| check(&client, &base_url, route)).collect();
+    let started = app.is_some();
+    if let Some(mut app) = app {
+        crate::supervisor::stop(&mut app);
+    }
+
+    let count = |outcome: Outcome| checks.iter().filter(|c| c.outcome == outcome).count();
+    let failed = count(Outcome::Failed);
+    match format {
+        SmokeFormat::Json => {
+            let report = Report { base_url: &shown, started, checks: &checks };
+            println!("{}", serde_json::to_string_pretty(&report).unwrap_or_default());
+        }
+        SmokeFormat::Text => {
+            let how = if started { "aplicação iniciada pelo dx" } else { "instância em execução" };
+            println!("Smoke test em {} ({}):\n", shown, how);
+            print!("{}", render_table(&checks));
+            println!();
+            let summary = format!(
+                "{} ok, {} com aviso (4xx), {} falha(s), {} pulada(s)",
+                count(Outcome::Ok),
+                count(Outcome::Warning),
+                failed,
+                count(Outcome::Skipped)
+            );
+            if failed == 0 {
+                println!("✓ {}.", summary);
+            } else {
+                println!("✗ {}: respostas 5xx ou sem resposta.", summary);
+            }
+        }
+    }
+    if failed == 0 { 0 } else { 1 }
+}
@@ -0,0 +1,347 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use crate::dev_services::{DockerComposeConfig, DockerService};
+use crate::env_export::Network;
+use serde::Serialize;
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+const DEVCONTAINER_DIR: &str = ".devcontainer";
+const DEVCONTAINER_FILE: &str = "devcontainer.json";
+const DOCKERFILE: &str = "Dockerfile";
+const COMPOSE_FILE: &str = "docker-compose.yml";
+/// Service of the generated compose file where the editor runs.
+const APP_SERVICE: &str = "app";
+const BASE_IMAGE: &str = "mcr.microsoft.com/devcontainers/base:bookworm";
+/// First line of the generated files (`//` in devcontainer.json, which accepts comments);
+/// files without it were written by hand.
+const HEADER: &str = "Gerado por: dx dev-config devcontainer";
+
+/// Client tools installed in the image for the services the project uses.
+const CLIENT_PACKAGES: &[(&str, &str)] = &[("postgres", "postgresql-client"), ("mysql", "mariadb-client"), ("redis", "redis-tools")];
+
+#[derive(Serialize)]
+#[serde(rename_all = "camelCase")]
+struct DevContainer {
+    name: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    build: Option<Build>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    docker_compose_file: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    service: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    run_services: Option<Vec<String>>,
+    workspace_folder: String,
+    #[serde(skip_serializing_if = "BTreeMap::is_empty")]
+    features: BTreeMap<String, serde_json::Value>,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    forward_ports: Vec<serde_json::Value>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    post_create_command: Option<String>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    customizations: Option<serde_json::Value>,
+}
+
+#[derive(Serialize)]
+struct Build {
+    dockerfile: String,
+}
+
+/// Toolchain of the stack: the devcontainer feature with its options, the command that
+/// fetches the dependencies and the editor extension.
+struct Toolchain {
+    feature: &'static str,
+    options: serde_json::Value,
+    /// Version shown in the summary ("1.22", "lts", ...)
+    version: String,
+    post_create: Option<String>,
+    extension: &'static str,
+}
+
+/// Go version from go.mod: the `toolchain` line (the exact release) when present, else the `go` line.
+pub(crate) fn go_version(project_dir: &Path) -> Option<String> {
+    let content = fs::read_to_string(project_dir.join("go.mod")).ok()?;
+    let directive = |name: &str| {
+        content.lines().find_map(|l| l.trim().strip_prefix(name).and_then(|v| v.strip_prefix(' ')).map(|v| v.trim().to_string()))
+    };
+    directive("toolchain").map(|v| v.trim_start_matches("go").to_string()).or_else(|| directive("go")).filter(|v| !v.is_empty())
+}
+
+/// First line of a version file (.nvmrc, .python-version), without a leading `v`.
+pub(crate) fn version_file(project_dir: &Path, name: &str) -> Option<String> {
+    let content = fs::read_to_string(project_dir.join(name)).ok()?;
+    let line = content.lines().map(str::trim).find(|l| !l.is_empty() && !l.starts_with('#'))?;
+    Some(line.trim_start_matches('v').to_string())
+}
+
+/// Node version: .nvmrc, else the first number of `engines.node` in package.json ("lts" otherwise).
+pub(crate) fn node_version(project_dir: &Path) -> String {
+    if let Some(version) = version_file(project_dir, ".nvmrc") {
+        return if version.starts_with("lts") { "lts".to_string() } else { version };
+    }
+    let engines = fs::read_to_string(project_dir.join("package.json"))
+        .ok()
+        .and_then(|c| serde_json::from_str::<serde_json::Value>(&c).ok())
+        .and_then(|p| p["engines"]["node"].as_str().map(str::to_string));
+    engines
+        .and_then(|range| {
+            let digits: String = range.chars().skip_while(|c| !c.is_ascii_digit()).take_while(char::is_ascii_digit).collect();
+            (!digits.is_empty()).then_some(digits)
+        })
+        .unwrap_or_else(|| "lts".to_string())
+}
+
+/// Rust channel from rust-toolchain.toml (or the legacy rust-toolchain file).
+fn rust_version(project_dir: &Path) -> Option<String> {
+    if let Ok(content) = fs::read_to_string(project_dir.join("rust-toolchain.toml")) {
+        let doc = content.parse::<toml_edit::DocumentMut>().ok()?;
+        return doc.get("toolchain")?.get("channel")?.as_str().map(str::to_string);
+    }
+    version_file(project_dir, "rust-toolchain")
+}
+
+/// Toolchain version of the stack as the devcontainer pins it ("1.22", "lts", "latest"...).
+pub(crate) fn toolchain_version(project_dir: &Path, stack: Stack) -> Option<String> {
+    toolchain(project_dir, stack).map(|t| t.version)
+}
+
+fn toolchain(project_dir: &Path, stack: Stack) -> Option<Toolchain> {
+    let exists = |name: &str| project_dir.join(name).exists();
+    let toolchain = match stack {
+        Stack::Go => {
+            let version = go_version(project_dir).unwrap_or_else(|| "latest".to_string());
+            Toolchain {
+                feature: "ghcr.io/devcontainers/features/go:1",
+                options: serde_json::json!({ "version": version }),
+                version,
+                post_create: Some("go mod download".to_string()),
+                extension: "golang.go",
+            }
+        }
+        Stack::Node => {
+            let version = node_version(project_dir);
+            let install = if exists("package-lock.json") { "npm ci" } else { "npm install" };
+            Toolchain {
+                feature: "ghcr.io/devcontainers/features/node:1",
+                options: serde_json::json!({ "version": version }),
+                version,
+                post_create: Some(install.to_string()),
+                extension: "dbaeumer.vscode-eslint",
+            }
+        }
+        Stack::Python => {
+            let version = version_file(project_dir, ".python-version").unwrap_or_else(|| "latest".to_string());
+            let install = if exists("requirements.txt") { "pip install -r requirements.txt" } else { "pip install -e ." };
+            Toolchain {
+                feature: "ghcr.io/devcontainers/features/python:1",
+                options: serde_json::json!({ "version": version }),
+                version,
+                post_create: Some(install.to_string()),
+                extension: "ms-python.python",
+            }
+        }
+        Stack::Rust => {
+            let version = rust_version(project_dir).unwrap_or_else(|| "latest".to_string());
+            Toolchain {
+                feature: "ghcr.io/devcontainers/features/rust:1",
+                options: serde_json::json!({ "version": version }),
+                version,
+                post_create: Some("cargo fetch".to_string()),
+                extension: "rust-lang.rust-analyzer",
+            }
+        }
+        Stack::JavaMaven | Stack::JavaGradle => {
+            let maven = stack == Stack::JavaMaven;
+            let post_create = match (maven, exists("mvnw"), exists("gradlew")) {
+                (true, true, _) => "./mvnw -q dependency:go-offline",
+                (true, false, _) => "mvn -q dependency:go-offline",
+                (false, _, true) => "./gradlew dependencies",
+                (false, _, false) => "gradle dependencies",
+            };
+            Toolchain {
+                feature: "ghcr.io/devcontainers/features/java:1",
+                options: serde_json::json!({ "version": "lts", "installMaven": maven.to_string(), "installGradle": (!maven).to_string() }),
+                version: "lts".to_string(),
+                post_create: Some(post_create.to_string()),
+                extension: "vscjava.vscode-java-pack",
+            }
+        }
+        Stack::DotNet => {
+            let version = crate::dotnet_build::sdk_version(project_dir)
+                .or_else(|| crate::dotnet_build::channel(project_dir))
+                .unwrap_or_else(|| "lts".to_string());
+            Toolchain {
+                feature: "ghcr.io/devcontainers/features/dotnet:2",
+                options: serde_json::json!({ "version": version }),
+                version,
+                post_create: Some("dotnet restore".to_string()),
+                extension: "ms-dotnettools.csdevkit",
+            }
+        }
+        Stack::Unknown => return None,
+    };
+    Some(toolchain)
+}
+
+/// Port the application listens on: the default of PORT (or a `*_PORT` of the application) in the code.
+pub(crate) fn app_port(vars: &[crate::dev_env::EnvVar]) -> Option<u16> {
+    vars.iter()
+        .filter(|v| v.service == "aplicação" && (v.name == "PORT" || v.name.ends_with("_PORT")))
+        .min_by_key(|v| v.name != "PORT")
+        .and_then(|v| v.default.as_deref()?.trim().parse().ok())
+}
+
+/// Variables for the application container: the code's connection defaults moved from localhost
+/// to the service names, then the Dev Services connections (as in `dx dev-env export`).
+pub(crate) fn app_env(config: &DockerComposeConfig, vars: &[crate::dev_env::EnvVar]) -> BTreeMap<String, String> {
+    let mut env = BTreeMap::new();
+    for var in vars.iter().filter(|v| config.services.contains_key(&v.service)) {
+        let Some(default) = var.default.as_deref().filter(|d| crate::dev_infra::parse_endpoint(d).is_some()) else {
+            continue;
+        };
+        let mut value = default.replace("localhost", &var.service).replace("127.0.0.1", &var.service);
+        if var.service == "kafka" {
+            value = value.replace(":29092", ":9092");
+        }
+        env.insert(var.name.clone(), value);
+    }
+    env.extend(crate::env_export::connection_env(config, Network::Compose));
+    env
+}
+
+/// Compose file of the devcontainer: the detected services, reached by name (no published ports,
+/// so they don't clash with `dx up` on the host), plus the application container.
+fn render_compose(config: DockerComposeConfig, env: BTreeMap<String, String>, workspace: &str) -> String {
+    let mut devcontainer = DockerComposeConfig::new();
+    let mut depends_on = Vec::new();
+    for (name, mut service) in config.services {
+        service.ports.clear();
+        let condition = if service.healthcheck.is_some() { "service_healthy" } else { "service_started" };
+        if service.profiles.is_empty() {
+            depends_on.push((name.clone(), condition.to_string()));
+        }
+        devcontainer.add_service(&name, service);
+    }
+    depends_on.sort();
+    devcontainer.add_service(
+        APP_SERVICE,
+        DockerService {
+            build: Some(".".to_string()),
+            env: env.into_iter().collect(),
+            volumes: vec![format!("..:{}:cached", workspace)],
+            command: Some("sleep infinity".to_string()),
+            depends_on,
+            ..Default::default()
+        },
+    );
+    format!("# {}\n{}", HEADER, devcontainer.to_yaml())
+}
+
+fn render_dockerfile(stack: Stack, services: &[String]) -> String {
+    let mut out = format!("# {}\n# Base do devcontainer; a toolchain ({}) vem das features do devcontainer.json.\nFROM {}\n", HEADER, stack, BASE_IMAGE);
+    let packages: Vec<&str> = CLIENT_PACKAGES.iter().filter(|(s, _)| services.iter().any(|n| n == s)).map(|(_, p)| *p).collect();
+    if !packages.is_empty() {
+        out.push_str(&format!(
+            "\n# Clientes dos Dev Services\nRUN apt-get update && export DEBIAN_FRONTEND=noninteractive \\\n    && apt-get install -y --no-install-recommends {} \\\n    && rm -rf /var/lib/apt/lists/*\n",
+            packages.join(" ")
+        ));
+    }
+    out
+}
+
+/// `dx dev-config devcontainer`: write .devcontainer/ (devcontainer.json and Dockerfile, plus a
+/// docker-compose.yml attaching the container to the detected services). Hand-written files go
+/// through the conflict resolution; `force` replaces them.
+pub fn cmd_devcontainer(dir: Option<PathBuf>, save_file: bool, force: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    let config = crate::dev_services::detect_dependencies(&project_dir);
+    let toolchain = toolchain(&project_dir, stack);
+    if toolchain.is_none() && config.services.is_empty() {
+        eprintln!("Nenhuma stack ou infraestrutura detectada em {}; nada a configurar.", project_dir.display());
+        return 1;
+    }
+
+    let folder = project_dir.canonicalize().unwrap_or_else(|_| project_dir.clone());
+    let name = folder.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_else(|| "app".to_string());
+    let workspace = format!("/workspaces/{}", name);
+    let vars = crate::dev_env::scan(&project_dir);
+    let mut services: Vec<String> = config.services.keys().cloned().collect();
+    services.sort();
+    let mut forward_ports: Vec<serde_json::Value> = app_port(&vars).into_iter().map(serde_json::Value::from).collect();
+    for service in &services {
+        if let Some(port) = config.services[service].ports.first() {
+            forward_ports.push(format!("{}:{}", service, port).into());
+        }
+    }
+
+    let mut devcontainer = DevContainer {
+        name: name.clone(),
+        build: None,
+        docker_compose_file: None,
+        service: None,
+        run_services: None,
+        workspace_folder: workspace.clone(),
+        features: BTreeMap::new(),
+        forward_ports,
+        post_create_command: None,
+        customizations: None,
+    };
+    if let Some(toolchain) = &toolchain {
+        devcontainer.features.insert(toolchain.feature.to_string(), toolchain.options.clone());
+        devcontainer.post_create_command = toolchain.post_create.clone();
+        devcontainer.customizations = Some(serde_json::json!({ "vscode": { "extensions": [toolchain.extension] } }));
+    }
+    let dir = project_dir.join(DEVCONTAINER_DIR);
+    let mut files = vec![(dir.join(DOCKERFILE), render_dockerfile(stack, &services))];
+    if services.is_empty() {
+        devcontainer.build = Some(Build { dockerfile: DOCKERFILE.to_string() });
+    } else {
+        let run_services = services.iter().filter(|s| config.services[*s].profiles.is_empty()).cloned().collect();
+        devcontainer.docker_compose_file = Some(COMPOSE_FILE.to_string());
+        devcontainer.service = Some(APP_SERVICE.to_string());
+        devcontainer.run_services = Some(run_services);
+        let env = app_env(&config, &vars);
+        files.push((dir.join(COMPOSE_FILE), render_compose(config, env, &workspace)));
+    }
+    let json = serde_json::to_string_pretty(&devcontainer).unwrap_or_default();
+    files.insert(0, (dir.join(DEVCONTAINER_FILE), format!("// {}\n{}\n", HEADER, json)));
+
+    if !save_file {
+        for (path, content) in &files {
+            println!("# {}\n{}", path.strip_prefix(&project_dir).unwrap_or(path).display(), content);
+        }
+        return 0;
+    }
+    if !crate::conflicts::settle(&mut files, crate::conflicts::generated_by(HEADER), force) {
+        return 1;
+    }
+    if let Err(e) = fs::create_dir_all(&dir) {
+        eprintln!("Erro ao criar {}: {}", dir.display(), e);
+        return 1;
+    }
+    for (path, content) in &files {
+        if let Err(e) = crate::audit::write(path, content) {
+            eprintln!("Erro ao salvar {}: {}", path.display(), e);
+            return 1;
+        }
+        println!("  {}", path.display());
+    }
+    // A compose file generated earlier, when the project had services it no longer uses
+    let stale = dir.join(COMPOSE_FILE);
+    if services.is_empty() && stale.exists() && crate::conflicts::generated_by(HEADER)(&stale) {
+        if let Err(e) = crate::audit::remove_file(&stale) {
+            eprintln!("Aviso: não foi possível remover {}: {}", stale.display(), e);
+        }
+    }
+
+    let toolchain = toolchain.map_or("sem toolchain".to_string(), |t| format!("{} {}", stack, t.version));
+    let attached = if services.is_empty() { "sem serviços".to_string() } else { format!("serviços: {}", services.join(", ")) };
+    println!("✓ Devcontainer gerado ({}; {}).", toolchain, attached);
+    println!("Abra o projeto no VS Code e use \"Reopen in Container\" (ou: devcontainer up --workspace-folder {}).", project_dir.display());
+    0
+}
@@ -0,0 +1,277 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+const DOCKERFILE: &str = "Dockerfile";
+const DOCKERIGNORE: &str = ".dockerignore";
+/// First line of the generated files; files without it were written by hand.
+const HEADER: &str = "# Gerado por: dx dev-config dockerfile";
+/// Routes that answer health checks, in order of preference; `/` is the last resort.
+const HEALTH_ROUTES: &[&str] = &["/healthz", "/health", "/livez", "/readyz", "/ping", "/status"];
+/// Options of the generated HEALTHCHECK.
+const HEALTHCHECK: &str = "HEALTHCHECK --interval=30s --timeout=3s --start-period=10s --retries=3";
+
+const DOCKERIGNORE_ENTRIES: &[&str] = &[".git", ".dx", ".devcontainer", ".env", "*.log", "node_modules", "coverage", "dist", "__pycache__", ".venv", ".pytest_cache"];
+
+/// GET route to probe for health: a health-like route, else `/`, else None.
+pub(crate) fn health_route(routes: &[(String, String, String)]) -> Option<String> {
+    let gets: Vec<&str> = routes.iter().filter(|(m, p, _)| m == "GET" && !p.contains('{')).map(|(_, p, _)| p.as_str()).collect();
+    HEALTH_ROUTES
+        .iter()
+        .find_map(|h| gets.iter().find(|p| p.ends_with(h)).copied())
+        .or_else(|| gets.iter().find(|p| p.contains("health")).copied())
+        .or_else(|| gets.iter().find(|p| **p == "/").copied())
+        .map(str::to_string)
+}
+
+/// GET route for a readiness probe apart from the health one (`/readyz`, `/ready`), if any.
+pub(crate) fn readiness_route(routes: &[(String, String, String)]) -> Option<String> {
+    routes
+        .iter()
+        .find(|(m, p, _)| m == "GET" && (p.ends_with("/readyz") || p.ends_with("/ready")))
+        .map(|(_, p, _)| p.clone())
+}
+
+/// Port the stack's usual frameworks listen on, for the stacks with a Dockerfile template.
+pub(crate) fn default_port(stack: Stack) -> Option<u16> {
+    match stack {
+        Stack::Go => Some(8080),
+        Stack::Node => Some(3000),
+        Stack::Python => Some(8000),
+        _ => None,
+    }
+}
+
+/// HEALTHCHECK probing `route`, with a tool the runtime image has (busybox wget, or Python itself).
+fn healthcheck(stack: Stack, port: u16, route: Option<&str>) -> String {
+    let Some(route) = route else {
+        return "# Nenhuma rota GET de saúde detectada (ex.: /healthz); adicione uma para ter HEALTHCHECK.\n".to_string();
+    };
+    let url = format!("http://127.0.0.1:{}{}", port, route);
+    match stack {
+        Stack::Python => format!(
+            "{} \\\n    CMD [\"python\", \"-c\", \"import urllib.request; urllib.request.urlopen('{}', timeout=3)\"]\n",
+            HEALTHCHECK, url
+        ),
+        _ => format!("{} \\\n    CMD wget -q -O /dev/null {} || exit 1\n", HEALTHCHECK, url),
+    }
+}
+
+/// Package to build in a Go module: the root when it has `main.go`, else the first `cmd/<name>`.
+pub(crate) fn go_main_package(project_dir: &Path) -> String {
+    if project_dir.join("main.go").exists() {
+        return ".".to_string();
+    }
+    let mut commands: Vec<String> = fs::read_dir(project_dir.join("cmd"))
+        .map(|entries| {
+            entries
+                .flatten()
+                .filter(|e| e.path().join("main.go").exists())
+                .map(|e| e.file_name().to_string_lossy().into_owned())
+                .collect()
+        })
+        .unwrap_or_default();
+    commands.sort();
+    commands.first().map_or(".".to_string(), |c| format!("./cmd/{}", c))
+}
+
+/// Go: the module is downloaded in its own layer, then built as a static binary that runs as a
+/// non-root user on Alpine (which keeps wget for the health check).
+fn go(project_dir: &Path, port: u16, health: &str) -> String {
+    let version = crate::devcontainer::go_version(project_dir).map(|v| v.split('.').take(2).collect::<Vec<_>>().join("."));
+    let image = version.map_or("golang:alpine".to_string(), |v| format!("golang:{}-alpine", v));
+    let sums = if project_dir.join("go.sum").exists() { "go.mod go.sum" } else { "go.mod" };
+    format!(
+        "{HEADER}\n\n\
+         FROM {image} AS build\n\
+         WORKDIR /src\n\
+         COPY {sums} ./\n\
+         RUN go mod download\n\
+         COPY . .\n\
+         RUN CGO_ENABLED=0 GOOS=linux go build -trimpath -ldflags=\"-s -w\" -o /out/app {main}\n\n\
+         FROM alpine:3.20\n\
+         RUN apk add --no-cache ca-certificates tzdata && addgroup -S app && adduser -S -G app app\n\
+         COPY --from=build /out/app /usr/local/bin/app\n\
+         USER app\n\
+         EXPOSE {port}\n\
+         {health}\
+         ENTRYPOINT [\"/usr/local/bin/app\"]\n",
+        main = go_main_package(project_dir)
+    )
+}
+
+/// Node: dependencies installed once, the build run with the devDependencies, which are then
+/// pruned before the files are copied to the runtime image (user `node` of the official image).
+fn node(project_dir: &Path, port: u16, health: &str) -> String {
+    let package: serde_json::Value = fs::read_to_string(project_dir.join("package.json"))
+        .ok()
+        .and_then(|c| serde_json::from_str(&c).ok())
+        .unwrap_or_default();
+    let version = crate::devcontainer::node_version(project_dir);
+    let image = format!("node:{}-alpine", version.split('.').next().unwrap_or("lts"));
+    let (manifests, install) = if project_dir.join("package-lock.json").exists() {
+        ("package.json package-lock.json", "npm ci")
+    } else {
+        ("package.json", "npm install")
+    };
+    let build = if package["scripts"]["build"].is_string() { "RUN npm run build\n" } else { "" };
+    let command = if package["scripts"]["start"].is_string() {
+        "[\"npm\", \"start\"]".to_string()
+    } else {
+        format!("[\"node\", \"{}\"]", package["main"].as_str().unwrap_or("index.js"))
+    };
+    format!(
+        "{HEADER}\n\n\
+         FROM {image} AS build\n\
+         WORKDIR /app\n\
+         COPY {manifests} ./\n\
+         RUN {install}\n\
+         COPY . .\n\
+         {build}\
+         RUN npm prune --omit=dev\n\n\
+         FROM {image}\n\
+         ENV NODE_ENV=production\n\
+         WORKDIR /app\n\
+         COPY --from=build --chown=node:node /app ./\n\
+         USER node\n\
+         EXPOSE {port}\n\
+         {health}\
+         CMD {command}\n"
+    )
+}
+
+/// Command that starts a Python service: uvicorn for FastAPI, runserver for Django, else the entry script.
+fn python_command(project_dir: &Path, port: u16) -> String {
+    let read = |name: &str| fs::read_to_string(project_dir.join(name)).unwrap_or_default();
+    if project_dir.join("manage.py").exists() {
+        return format!("[\"python\", \"manage.py\", \"runserver\", \"0.0.0.0:{}\"]", port);
+    }
+    let entry = ["app.py", "main.py", "server.py", "wsgi.py"].into_iter().find(|f| project_dir.join(f).exists()).unwrap_or("app.py");
+    if read(entry).contains("FastAPI(") {
+        let module = entry.trim_end_matches(".py");
+        return format!("[\"uvicorn\", \"{}:app\", \"--host\", \"0.0.0.0\", \"--port\", \"{}\"]", module, port);
+    }
+    format!("[\"python\", \"{}\"]", entry)
+}
+
+/// Python: the dependencies are built into wheels in a first stage (with compilers available) and
+/// installed from them in a slim image, without build tools, running as a non-root user.
+fn python(project_dir: &Path, port: u16, health: &str) -> String {
+    let version = crate::devcontainer::version_file(project_dir, ".python-version")
+        .map(|v| v.split('.').take(2).collect::<Vec<_>>().join("."))
+        .unwrap_or_else(|| "3.12".to_string());
+    let image = format!("python:{}-slim", version);
+    let wheels = if project_dir.join("requirements.txt").exists() {
+        "COPY requirements.txt ./\nRUN pip wheel --no-cache-dir --wheel-dir /wheels -r requirements.txt\n"
+    } else {
+        "COPY . .\nRUN pip wheel --no-cache-dir --wheel-dir /wheels .\n"
+    };
+    format!(
+        "{HEADER}\n\n\
+         FROM {image} AS build\n\
+         WORKDIR /src\n\
+         {wheels}\n\
+         FROM {image}\n\
+         ENV PYTHONDONTWRITEBYTECODE=1 PYTHONUNBUFFERED=1\n\
+         RUN useradd --create-home --uid 10001 app\n\
+         WORKDIR /app\n\
+         COPY --from=build /wheels /wheels\n\
+         RUN pip install --no-cache-dir --no-index /wheels/* && rm -rf /wheels\n\
+         COPY --chown=app:app . .\n\
+         USER app\n\
+         EXPOSE {port}\n\
+         {health}\
+         CMD {command}\n",
+        command = python_command(project_dir, port)
+    )
+}
+
+fn render_dockerignore() -> String {
+    let mut out = format!("{}\n", HEADER);
+    for entry in DOCKERIGNORE_ENTRIES {
+        out.push_str(entry);
+        out.push('\n');
+    }
+    out
+}
+
+/// A Dockerfile generated for a project: its content, with what went into it.
+pub(crate) struct Rendered {
+    pub stack: Stack,
+    pub port: u16,
+    pub route: Option<String>,
+    pub content: String,
+}
+
+/// The Dockerfile dx generates for `project_dir`; Err with the detected stack when it is not supported.
+pub(crate) fn render(project_dir: &Path) -> Result<Rendered, Stack> {
+    let stack = Stack::detect(project_dir);
+    let default_port = default_port(stack).ok_or(stack)?;
+    let port = crate::devcontainer::app_port(&crate::dev_env::scan(project_dir)).unwrap_or(default_port);
+    let route = health_route(&crate::api_client::detect_routes(project_dir));
+    let health = healthcheck(stack, port, route.as_deref());
+    let content = match stack {
+        Stack::Go => go(project_dir, port, &health),
+        Stack::Node => node(project_dir, port, &health),
+        _ => python(project_dir, port, &health),
+    };
+    Ok(Rendered { stack, port, route, content })
+}
+
+/// `dx dev-config dockerfile`: write a multi-stage Dockerfile (and .dockerignore) for the detected
+/// stack, with a non-root user and a HEALTHCHECK on the health route found in the code. A
+/// hand-written Dockerfile goes through the conflict resolution (`force` replaces it); a
+/// hand-written .dockerignore is kept.
+pub fn cmd_dockerfile(dir: Option<PathBuf>, save_file: bool, force: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Rendered { stack, port, route, content } = match render(&project_dir) {
+        Ok(rendered) => rendered,
+        Err(stack) => {
+            eprintln!("Stack {} ainda não suportada por dx dev-config dockerfile (Go, Node.js e Python).", stack);
+            return 1;
+        }
+    };
+    if !save_file {
+        print!("{}", content);
+        return 0;
+    }
+
+    let mut files = vec![(project_dir.join(DOCKERFILE), content)];
+    if !crate::conflicts::settle(&mut files, crate::conflicts::generated_by(HEADER), force) {
+        return 1;
+    }
+    for (path, content) in &files {
+        if let Err(e) = crate::audit::write(path, content) {
+            eprintln!("Erro ao salvar {}: {}", path.display(), e);
+            return 1;
+        }
+        println!("  {}", path.display());
+    }
+    let ignore = project_dir.join(DOCKERIGNORE);
+    if crate::conflicts::generated_by(HEADER)(&ignore) {
+        match crate::audit::write(&ignore, render_dockerignore()) {
+            Ok(()) => println!("  {}", ignore.display()),
+            Err(e) => eprintln!("Aviso: não foi possível salvar {}: {}", ignore.display(), e),
+        }
+    }
+    let health = route.map_or("sem healthcheck: nenhuma rota de saúde detectada".to_string(), |r| format!("healthcheck GET {}", r));
+    println!("✓ Dockerfile gerado ({}; porta {}; {}).", stack, port, health);
+    println!("Construa com: docker build -t {} {}", image_name(&project_dir), project_dir.display());
+    0
+}
+
+/// Image name suggested in the build hint: the project directory, lower-cased.
+fn image_name(project_dir: &Path) -> String {
+    let dir = project_dir.canonicalize().unwrap_or_else(|_| project_dir.to_path_buf());
+    let name: String = dir
+        .file_name()
+        .map(|n| n.to_string_lossy().to_lowercase())
+        .unwrap_or_default()
+        .chars()
+        .map(|c| if c.is_ascii_alphanumeric() || c == '.' || c == '-' || c == '_' { c } else { '-' })
+        .collect();
+    if name.is_empty() { "app".to_string() } else { name }
+}
@@ -0,0 +1,306 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! .NET builds: the NuGet packages referenced by the projects (.csproj, .fsproj, .vbproj) below a
+//! directory, each with the version it restores to — packages.lock.json, then the reference itself,
+//! then central package management (Directory.Packages.props) — and the SDK the build pins in
+//! global.json.
+
+use crate::xml::Element;
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// Extensions of MSBuild project files that reference NuGet packages.
+const PROJECT_EXTENSIONS: &[&str] = &["csproj", "fsproj", "vbproj"];
+/// Extensions of solution files, which mark the root of a .NET repository too.
+const SOLUTION_EXTENSIONS: &[&str] = &["sln", "slnx"];
+/// Central package management: the versions of the packages of every project below it.
+const CENTRAL_FILE: &str = "Directory.Packages.props";
+/// Properties and package references shared by every project below it.
+const BUILD_PROPS: &str = "Directory.Build.props";
+const LOCK_FILE: &str = "packages.lock.json";
+/// How deep below the root project files are looked for (`src/Api/Api.csproj` is 2).
+const MAX_DEPTH: usize = 4;
+/// Directories without projects of their own: build output, restored packages, tooling.
+const SKIP: &[&str] = &["bin", "obj", "node_modules", "packages", "artifacts", "TestResults"];
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct NuGetPackage {
+    pub name: String,
+    /// Version it restores to; None when nothing declares it
+    pub version: Option<String>,
+    /// Development only: `PrivateAssets="all"` (analyzers, build tools) or referenced by a test project
+    pub dev: bool,
+    /// Project file that references it, relative to the root
+    pub source: String,
+    /// Where the version comes from when it is not written in the reference: the lock file or
+    /// Directory.Packages.props, relative to the root
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub managed_by: Option<String>,
+}
+
+impl NuGetPackage {
+    /// A version a registry or advisory database can match: a plain version or an exact range `[1.2.3]`.
+    pub fn exact_version(&self) -> Option<&str> {
+        let version = self.version.as_deref()?;
+        let version = version.strip_prefix('[').and_then(|v| v.strip_suffix(']')).unwrap_or(version);
+        Some(version.trim()).filter(|v| !v.is_empty() && !v.contains(['$', '[', '(', ')', ']', ',', '*']))
+    }
+}
+
+fn has_extension(path: &Path, extensions: &[&str]) -> bool {
+    path.extension().and_then(|e| e.to_str()).is_some_and(|e| extensions.iter().any(|x| e.eq_ignore_ascii_case(x)))
+}
+
+/// Whether `dir` is the root of a .NET build: a project or solution file, or central package management.
+pub fn is_project(dir: &Path) -> bool {
+    if dir.join(CENTRAL_FILE).is_file() {
+        return true;
+    }
+    let Ok(entries) = fs::read_dir(dir) else { return false };
+    entries.flatten().any(|e| {
+        let path = e.path();
+        path.is_file() && (has_extension(&path, PROJECT_EXTENSIONS) || has_extension(&path, SOLUTION_EXTENSIONS))
+    })
+}
+
+/// Project files below `root`, sorted.
+fn project_files(root: &Path) -> Vec<PathBuf> {
+    fn walk(dir: &Path, depth: usize, found: &mut Vec<PathBuf>) {
+        let Ok(entries) = fs::read_dir(dir) else { return };
+        for entry in entries.flatten() {
+            let path = entry.path();
+            let name = entry.file_name().to_string_lossy().into_owned();
+            if path.is_dir() {
+                if depth < MAX_DEPTH && !name.starts_with('.') && !SKIP.iter().any(|s| s.eq_ignore_ascii_case(&name)) {
+                    walk(&path, depth + 1, found);
+                }
+            } else if has_extension(&path, PROJECT_EXTENSIONS) {
+                found.push(path);
+            }
+        }
+    }
+    let mut found = Vec::new();
+    walk(root, 0, &mut found);
+    found.sort();
+    found
+}
+
+/// The file `name` MSBuild imports for a project in `dir`: the nearest one going up, not above `root`.
+fn nearest(root: &Path, dir: &Path, name: &str) -> Option<PathBuf> {
+    dir.ancestors().take_while(|d| d.starts_with(root)).map(|d| d.join(name)).find(|p| p.is_file())
+}
+
+fn parse(path: &Path) -> Option<Element> {
+    fs::read_to_string(path).ok().and_then(|d| crate::xml::parse(&d))
+}
+
+fn relative(root: &Path, path: &Path) -> String {
+    path.strip_prefix(root).unwrap_or(path).to_string_lossy().replace('\\', "/")
+}
+
+/// Children of the `group` elements of a project (PropertyGroup, ItemGroup), in order.
+fn items<'a>(project: &'a Element, group: &'a str) -> impl Iterator<Item = &'a Element> {
+    project.all(group).flat_map(|g| g.children.iter())
+}
+
+/// Properties of the project files, later ones overriding earlier (Directory.Build.props first).
+fn properties(files: &[&Element]) -> BTreeMap<String, String> {
+    files.iter().flat_map(|f| items(f, "PropertyGroup")).map(|p| (p.name.clone(), p.text.trim().to_string())).collect()
+}
+
+/// Replace `$(Name)` with the property's value; unknown properties stay as written.
+fn interpolate(value: &str, properties: &BTreeMap<String, String>) -> String {
+    let mut out = String::new();
+    let mut rest = value;
+    while let Some(start) = rest.find("$(") {
+        let Some(end) = rest[start..].find(')') else { break };
+        let name = &rest[start + 2..start + end];
+        out.push_str(&rest[..start]);
+        match properties.get(name) {
+            Some(v) => out.push_str(v),
+            None => out.push_str(&rest[start..start + end + 1]),
+        }
+        rest = &rest[start + end + 1..];
+    }
+    out.push_str(rest);
+    out
+}
+
+/// An attribute of an item, or its child element of the same name (`<Version>1.0</Version>`).
+fn metadata(item: &Element, name: &str) -> Option<String> {
+    item.attribute(name).or_else(|| item.text_of(name))
+}
+
+/// Whether the project is a test project: `IsTestProject` or a reference to the test SDK.
+fn is_test_project(files: &[&Element], properties: &BTreeMap<String, String>) -> bool {
+    properties.get("IsTestProject").is_some_and(|v| v.eq_ignore_ascii_case("true"))
+        || files
+            .iter()
+            .flat_map(|f| items(f, "ItemGroup"))
+            .any(|i| i.name == "PackageReference" && i.attribute("Include").is_some_and(|n| n.eq_ignore_ascii_case("Microsoft.NET.Test.Sdk")))
+}
+
+/// Versions of Directory.Packages.props by lower-cased package name.
+fn central_versions(path: &Path) -> BTreeMap<String, String> {
+    let Some(props) = parse(path) else { return BTreeMap::new() };
+    let properties = properties(&[&props]);
+    items(&props, "ItemGroup")
+        .filter(|i| i.name == "PackageVersion")
+        .filter_map(|i| Some((i.attribute("Include")?.to_lowercase(), interpolate(&metadata(i, "Version")?, &properties))))
+        .collect()
+}
+
+#[derive(Deserialize)]
+struct LockFile {
+    #[serde(default)]
+    dependencies: BTreeMap<String, BTreeMap<String, Locked>>,
+}
+
+#[derive(Deserialize)]
+struct Locked {
+    #[serde(rename = "type", default)]
+    kind: String,
+    resolved: Option<String>,
+}
+
+/// Resolved versions of a packages.lock.json (every target framework) by package name; project
+/// references are not packages.
+fn lock_versions(path: &Path) -> BTreeMap<String, String> {
+    let Some(lock) = fs::read_to_string(path).ok().and_then(|d| serde_json::from_str::<LockFile>(&d).ok()) else {
+        return BTreeMap::new();
+    };
+    lock.dependencies
+        .into_values()
+        .flatten()
+        .filter(|(_, l)| l.kind != "Project")
+        .filter_map(|(name, l)| Some((name, l.resolved?)))
+        .collect()
+}
+
+/// The packages referenced by each project below `root` (and by the Directory.Build.props they
+/// import), with the version each restores to.
+pub fn dependencies(root: &Path) -> Vec<NuGetPackage> {
+    let mut packages = Vec::new();
+    for file in project_files(root) {
+        let dir = file.parent().unwrap_or(root);
+        let Some(project) = parse(&file) else { continue };
+        let build_props = nearest(root, dir, BUILD_PROPS).and_then(|p| parse(&p));
+        let files: Vec<&Element> = build_props.iter().chain([&project]).collect();
+        let properties = properties(&files);
+        let central = nearest(root, dir, CENTRAL_FILE);
+        let central_versions = central.as_deref().map(central_versions).unwrap_or_default();
+        let lock = dir.join(LOCK_FILE);
+        let locked: BTreeMap<String, String> = lock_versions(&lock).into_iter().map(|(n, v)| (n.to_lowercase(), v)).collect();
+
+        let references: Vec<&Element> =
+            files.iter().flat_map(|f| items(f, "ItemGroup")).filter(|i| i.name == "PackageReference" && i.attribute("Include").is_some()).collect();
+        let test_project = is_test_project(&files, &properties);
+        let source = relative(root, &file);
+        for reference in references {
+            let name = interpolate(&reference.attribute("Include").unwrap_or_default(), &properties);
+            let key = name.to_lowercase();
+            let written = metadata(reference, "VersionOverride").or_else(|| metadata(reference, "Version")).map(|v| interpolate(&v, &properties));
+            let (version, managed_by) = match (locked.get(&key), written, central_versions.get(&key)) {
+                (Some(resolved), Some(v), _) if *resolved == v => (Some(v), None),
+                (Some(resolved), _, _) => (Some(resolved.clone()), Some(relative(root, &lock))),
+                (None, Some(v), _) => (Some(v), None),
+                (None, None, Some(v)) => (Some(v.clone()), central.as_deref().map(|c| relative(root, c))),
+                (None, None, None) => (None, None),
+            };
+            let private = metadata(reference, "PrivateAssets").is_some_and(|p| p.to_lowercase().split(';').any(|a| a.trim() == "all"));
+            packages.push(NuGetPackage { name, version, dev: private || test_project, source: source.clone(), managed_by });
+        }
+    }
+    packages
+}
+
+/// Every package with a version pinned for the build below `root`, as (name, version, source): all
+/// the lock files hold (transitive ones included), then the exact versions of the references of
+/// projects without a lock file.
+pub fn pinned(root: &Path) -> Vec<(String, String, String)> {
+    let mut pinned = Vec::new();
+    let mut locked_projects = Vec::new();
+    for file in project_files(root) {
+        let lock = file.parent().unwrap_or(root).join(LOCK_FILE);
+        let versions = lock_versions(&lock);
+        if versions.is_empty() {
+            continue;
+        }
+        locked_projects.push(relative(root, &file));
+        let source = relative(root, &lock);
+        pinned.extend(versions.into_iter().map(|(name, version)| (name, version, source.clone())));
+    }
+    for package in dependencies(root).into_iter().filter(|p| !locked_projects.contains(&p.source)) {
+        if let Some(version) = package.exact_version() {
+            pinned.push((package.name.clone(), version.to_string(), package.managed_by.clone().unwrap_or_else(|| package.source.clone())));
+        }
+    }
+    pinned
+}
+
+/// Build files that change what `dependencies` finds: the project files and, below the root, the
+/// props and lock files next to them (the root ones are key files of the detection cache already).
+pub fn manifests(root: &Path) -> Vec<PathBuf> {
+    let mut files = Vec::new();
+    for file in project_files(root) {
+        let dir = file.parent().unwrap_or(root).to_path_buf();
+        if dir != root {
+            files.extend([BUILD_PROPS, CENTRAL_FILE, LOCK_FILE].iter().map(|name| dir.join(name)).filter(|f| f.exists()));
+        }
+        files.push(file);
+    }
+    files.sort();
+    files.dedup();
+    files
+}
+
+/// The project that runs the application (relative to the root): a web project, else an executable
+/// one, never a test project.
+pub fn app_project(root: &Path) -> Option<String> {
+    let mut candidates: Vec<(bool, PathBuf)> = project_files(root)
+        .into_iter()
+        .filter_map(|file| {
+            let project = parse(&file)?;
+            let properties = properties(&[&project]);
+            let test = is_test_project(&[&project], &properties);
+            let web = project.attribute("Sdk").is_some_and(|s| s.ends_with(".Web"));
+            let exe = properties.get("OutputType").is_some_and(|t| t.eq_ignore_ascii_case("Exe"));
+            (!test && (web || exe)).then_some((!web, file))
+        })
+        .collect();
+    candidates.sort();
+    candidates.first().map(|(_, file)| relative(root, file))
+}
+
+/// SDK version global.json pins (`sdk.version`).
+pub fn sdk_version(root: &Path) -> Option<String> {
+    let global: serde_json::Value = serde_json::from_str(&fs::read_to_string(root.join("global.json")).ok()?).ok()?;
+    global["sdk"]["version"].as_str().map(str::to_string).filter(|v| !v.is_empty())
+}
+
+/// .NET channel of the projects (`8.0` for `net8.0`): the highest target framework they build for.
+pub fn channel(root: &Path) -> Option<String> {
+    project_files(root)
+        .iter()
+        .filter_map(|f| parse(f))
+        .flat_map(|p| {
+            let properties = properties(&[&p]);
+            let frameworks = properties.get("TargetFramework").or_else(|| properties.get("TargetFrameworks")).cloned().unwrap_or_default();
+            frameworks.split(';').map(str::to_string).collect::<Vec<_>>()
+        })
+        .filter_map(|tfm| {
+            let version = tfm.trim().strip_prefix("net")?.split('-').next()?.to_string();
+            let (major, minor) = version.split_once('.')?;
+            Some((major.parse::<u32>().ok()?, minor.parse::<u32>().ok()?))
+        })
+        .max()
+        .map(|(major, minor)| format!("{}.{}", major, minor))
+}
+
+/// NuGet feed (v3 flat container) that serves the latest versions of `dx dev-dependencies`.
+pub fn feed() -> String {
+    crate::settings::get("nuget_feed").trim_end_matches('/').to_string()
+}
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::environments::{self, canonical, compose, compose_project_name, now, Environment, DAY};
+use std::path::PathBuf;
+
+/// Days without `dx up` after which `dx down --prune` removes an environment.
+pub const DEFAULT_PRUNE_DAYS: u64 = 30;
+
+/// Remove the environments not started for `older_than_days` or whose project directory is gone:
+/// containers, networks and volumes.
+fn prune(older_than_days: u64) -> i32 {
+    let environments = environments::load();
+    let cutoff = now().saturating_sub(older_than_days * DAY);
+    let stale: Vec<(&PathBuf, &Environment)> =
+        environments.iter().filter(|(dir, e)| !dir.exists() || e.last_up <= cutoff).collect();
+    if stale.is_empty() {
+        println!(
+            "Nenhum ambiente para remover: {} acompanhado(s) pelo dx, todos usados nos últimos {} dia(s).",
+            environments.len(),
+            older_than_days
+        );
+        return 0;
+    }
+    let mut removed = Vec::new();
+    for (dir, environment) in &stale {
+        let reason = if dir.exists() {
+            format!("último uso há {} dia(s)", now().saturating_sub(environment.last_up) / DAY)
+        } else {
+            "diretório não existe mais".to_string()
+        };
+        println!("Removendo o ambiente de {} ({})...", dir.display(), reason);
+        if compose(environment, &["down", "-v", "--remove-orphans"]) {
+            removed.push((*dir).clone());
+        } else {
+            eprintln!("  ✗ falha ao remover; o ambiente continua registrado para uma próxima tentativa.");
+        }
+    }
+    environments::forget(&removed);
+    println!("✓ {} de {} ambiente(s) removido(s), com containers, redes e volumes.", removed.len(), stale.len());
+    if removed.len() == stale.len() { 0 } else { 1 }
+}
+
+/// `dx down`: stop the project's containers, or remove them with their networks (`networks`) and
+/// also their volumes (`volumes`). `prune` instead cleans the old environments tracked by dx.
+pub fn cmd_down(dir: Option<PathBuf>, volumes: bool, networks: bool, prune_days: Option<u64>) -> i32 {
+    if let Some(days) = prune_days {
+        return prune(days);
+    }
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let _lock = crate::lock_dev_services(&project_dir);
+    let compose_path = crate::dev_services_compose_path(&project_dir);
+    let environment = if compose_path.exists() {
+        Environment { compose: compose_path.clone(), project: compose_project_name(&compose_path), last_up: 0 }
+    } else if let Some(tracked) = environments::load().remove(&canonical(&project_dir)) {
+        tracked
+    } else {
+        eprintln!(
+            "Nenhum ambiente do dx neste projeto: {} não encontrado. Suba-o com: dx up",
+            compose_path.display()
+        );
+        return 1;
+    };
+
+    let (args, done): (&[&str], &str) = if volumes {
+        (&["down", "-v", "--remove-orphans"], "Containers, redes e volumes removidos.")
+    } else if networks {
+        (&["down", "--remove-orphans"], "Containers e redes removidos; os volumes (dados) foram mantidos.")
+    } else {
+        (&["stop"], "Serviços parados; containers, redes e volumes mantidos.")
+    };
+    println!("Encerrando os Dev Services de {}...", project_dir.display());
+    if !compose(&environment, args) {
+        eprintln!("Erro: não foi possível encerrar os serviços. Verifique se o Docker está em execução.");
+        return 1;
+    }
+    if volumes {
+        environments::forget(&[canonical(&project_dir)]);
+    }
+    println!("✓ {} Para subir de novo: dx up", done);
+    0
+}
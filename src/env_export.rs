@@ -0,0 +1,235 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_services::{DockerComposeConfig, detect_dependencies};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+const ENVRC_FILE: &str = ".envrc";
+const START_MARKER: &str = "# dx-cli:envrc:start";
+const END_MARKER: &str = "# dx-cli:envrc:end";
+
+/// Output format of `dx dev-env export`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum ExportFormat {
+    /// `export KEY='valor'` (sh/bash/zsh, direnv)
+    Sh,
+    /// `KEY=valor` (.env)
+    Dotenv,
+    /// Objeto JSON
+    Json,
+}
+
+/// One variable of the composed environment and where it came from.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub struct EnvValue {
+    pub value: String,
+    pub source: String,
+}
+
+/// Where connection variables point: the ports published on localhost, or the services
+/// by name from another container of the same compose network (a devcontainer).
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Network {
+    Host,
+    Compose,
+}
+
+/// Connection variables for the Dev Services the project uses, pointing at the ports
+/// published on localhost by `dx dev-services run`.
+pub fn service_env(config: &DockerComposeConfig) -> BTreeMap<String, String> {
+    connection_env(config, Network::Host)
+}
+
+/// Connection variables for the Dev Services of `config`, as seen from `network`.
+pub fn connection_env(config: &DockerComposeConfig, network: Network) -> BTreeMap<String, String> {
+    let mut env = BTreeMap::new();
+    let host = |service: &'static str| if network == Network::Host { "localhost" } else { service };
+    let var = |service: &str, key: &str, default: &str| {
+        config
+            .services
+            .get(service)
+            .and_then(|s| s.env.get(key).cloned())
+            .unwrap_or_else(|| default.to_string())
+    };
+
+    if config.services.contains_key("postgres") {
+        let password = var("postgres", "POSTGRES_PASSWORD", "postgres");
+        let db = var("postgres", "POSTGRES_DB", "postgres");
+        env.insert("DATABASE_URL".into(), format!("postgres://postgres:{}@{}:5432/{}", password, host("postgres"), db));
+        env.insert("PGHOST".into(), host("postgres").into());
+        env.insert("PGPORT".into(), "5432".into());
+        env.insert("PGUSER".into(), "postgres".into());
+        env.insert("PGPASSWORD".into(), password);
+        env.insert("PGDATABASE".into(), db);
+    }
+    if config.services.contains_key("mysql") {
+        let password = var("mysql", "MARIADB_ROOT_PASSWORD", "root");
+        let db = var("mysql", "MARIADB_DATABASE", "app");
+        let url = format!("mysql://root:{}@{}:3306/{}", password, host("mysql"), db);
+        env.entry("DATABASE_URL".into()).or_insert_with(|| url.clone());
+        env.insert("MYSQL_URL".into(), url);
+    }
+    if config.services.contains_key("kafka") {
+        // Listeners advertised by the Redpanda container: one for the host, one for the network
+        let brokers = if network == Network::Host { "localhost:29092" } else { "kafka:9092" };
+        env.insert("KAFKA_BOOTSTRAP_SERVERS".into(), brokers.into());
+    }
+    if config.services.contains_key("redis") {
+        env.insert("REDIS_URL".into(), format!("redis://{}:6379", host("redis")));
+    }
+    if config.services.contains_key("rabbitmq") {
+        let user = var("rabbitmq", "RABBITMQ_DEFAULT_USER", "guest");
+        let password = var("rabbitmq", "RABBITMQ_DEFAULT_PASS", "guest");
+        env.insert("RABBITMQ_URL".into(), format!("amqp://{}:{}@{}:5672", user, password, host("rabbitmq")));
+    }
+    if config.services.contains_key("mongodb") {
+        let user = var("mongodb", "MONGO_INITDB_ROOT_USERNAME", "root");
+        let password = var("mongodb", "MONGO_INITDB_ROOT_PASSWORD", "example");
+        env.insert("MONGODB_URI".into(), format!("mongodb://{}:{}@{}:27017", user, password, host("mongodb")));
+    }
+    if config.services.contains_key("jobmanager") {
+        env.insert("FLINK_REST_URL".into(), format!("http://{}:8081", host("jobmanager")));
+    }
+    env
+}
+
+fn is_env_name(key: &str) -> bool {
+    let mut chars = key.chars();
+    matches!(chars.next(), Some(c) if c.is_ascii_alphabetic() || c == '_')
+        && chars.all(|c| c.is_ascii_alphanumeric() || c == '_')
+}
+
+/// The code's connection defaults that do not reach the Dev Services from the host, moved to where
+/// they do: Kafka to the listener Redpanda advertises for the host (`localhost:9092` becomes
+/// `localhost:29092`), as `devcontainer::app_env` does for the compose network.
+fn host_defaults(config: &DockerComposeConfig, vars: &[crate::dev_env::EnvVar]) -> BTreeMap<String, String> {
+    let mut env = BTreeMap::new();
+    for var in vars.iter().filter(|v| v.service == "kafka" && config.services.contains_key(&v.service)) {
+        let Some(default) = var.default.as_deref().filter(|d| crate::dev_infra::parse_endpoint(d).is_some()) else {
+            continue;
+        };
+        let value = default.replace("127.0.0.1", "localhost").replace("kafka:9092", "localhost:9092").replace("localhost:9092", "localhost:29092");
+        if value != default {
+            env.insert(var.name.clone(), value);
+        }
+    }
+    env
+}
+
+/// The environment dx composes for a project: Dev Services connection variables (with the code's
+/// own connection variables moved to the host listeners), overridden by the values saved with
+/// `dx dev-config` (keys that are valid variable names).
+pub fn compose(project_dir: &Path) -> BTreeMap<String, EnvValue> {
+    let config = detect_dependencies(project_dir);
+    let mut env: BTreeMap<String, EnvValue> = host_defaults(&config, &crate::dev_env::scan(project_dir))
+        .into_iter()
+        .chain(service_env(&config))
+        .map(|(k, v)| (k, EnvValue { value: v, source: "dev-services".into() }))
+        .collect();
+    for (k, v) in crate::dev_config::values(project_dir) {
+        if is_env_name(&k) {
+            env.insert(k, EnvValue { value: v, source: "dev-config".into() });
+        }
+    }
+    env
+}
+
+fn sh_quote(value: &str) -> String {
+    format!("'{}'", value.replace('\'', "'\\''"))
+}
+
+/// `KEY=valor` line of a .env file, quoting values with spaces or special characters.
+pub fn dotenv_line(key: &str, value: &str) -> String {
+    if value.chars().any(|c| c.is_whitespace() || "\"'#$".contains(c)) {
+        format!("{}=\"{}\"\n", key, value.replace('\\', "\\\\").replace('"', "\\\""))
+    } else {
+        format!("{}={}\n", key, value)
+    }
+}
+
+pub fn render(env: &BTreeMap<String, EnvValue>, format: ExportFormat) -> String {
+    match format {
+        ExportFormat::Sh => env
+            .iter()
+            .map(|(k, v)| format!("export {}={}\n", k, sh_quote(&v.value)))
+            .collect(),
+        ExportFormat::Dotenv => env.iter().map(|(k, v)| dotenv_line(k, &v.value)).collect(),
+        ExportFormat::Json => {
+            let map: serde_json::Map<String, serde_json::Value> =
+                env.iter().map(|(k, v)| (k.clone(), serde_json::Value::String(v.value.clone()))).collect();
+            serde_json::to_string_pretty(&map).unwrap_or_default() + "\n"
+        }
+    }
+}
+
+/// `dx dev-env export`: print the composed environment (used by the generated .envrc).
+pub fn cmd_export(dir: Option<PathBuf>, format: ExportFormat) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    print!("{}", render(&compose(&project_dir), format));
+}
+
+/// direnv block: defines `use_dx` inline so `use dx` works without extra setup, and
+/// reloads when the files that feed the environment change.
+fn envrc_block() -> String {
+    format!(
+        "{start}\n\
+         # Carrega o ambiente composto pelo dx (Dev Services + dev-config). Gerado por: dx dev-env envrc\n\
+         use_dx() {{\n\
+         \x20 watch_file dx.yaml .dx/config.json .dx/docker-compose.yml\n\
+         \x20 eval \"$(dx dev-env export --format sh)\"\n\
+         }}\n\
+         use dx\n\
+         {end}",
+        start = START_MARKER,
+        end = END_MARKER
+    )
+}
+
+/// Insert or replace the dx block in `.envrc`, keeping everything else the user wrote.
+fn upsert_envrc(path: &Path, block: &str) -> std::io::Result<()> {
+    let content = match fs::read_to_string(path) {
+        Ok(mut content) => {
+            if let (Some(start), Some(end)) = (content.find(START_MARKER), content.find(END_MARKER)) {
+                content.replace_range(start..end + END_MARKER.len(), block);
+            } else {
+                if !content.is_empty() && !content.ends_with('\n') {
+                    content.push('\n');
+                }
+                content.push_str(block);
+                content.push('\n');
+            }
+            content
+        }
+        Err(_) => format!("{}\n", block),
+    };
+    crate::audit::write(path, content)
+}
+
+/// `dx dev-env envrc`: generate (or update) the project's .envrc for direnv.
+pub fn cmd_envrc(dir: Option<PathBuf>, save_file: bool) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let block = envrc_block();
+    if !save_file {
+        println!("{}", block);
+        return;
+    }
+    let path = project_dir.join(ENVRC_FILE);
+    match upsert_envrc(&path, &block) {
+        Ok(()) => {
+            println!("{} atualizado.", path.display());
+            let env = compose(&project_dir);
+            if env.is_empty() {
+                println!("Nenhuma variável no momento (sem Dev Services detectados nem valores em dx dev-config).");
+            } else {
+                println!("Variáveis carregadas ao entrar no diretório:");
+                for (k, v) in &env {
+                    println!("  {:<24} ({})", k, v.source);
+                }
+            }
+            println!("Execute 'direnv allow' para ativar.");
+        }
+        Err(e) => eprintln!("Erro ao salvar {}: {}", path.display(), e),
+    }
+}
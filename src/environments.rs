@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Environments started by dx, one per project: the compose project that namespaces its
+//! containers, networks and volumes, tracked in the state directory so they can be listed and
+//! removed from any repository (`dx env list`, `dx env rm`, `dx down --prune`).
+
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::BTreeMap;
+use std::ffi::OsString;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+use std::time::{SystemTime, UNIX_EPOCH};
+
+/// Environments started by dx (`dx up`, `dx dev-services run`), in the state directory.
+const ENVIRONMENTS_FILE: &str = "environments.json";
+pub const DAY: u64 = 24 * 60 * 60;
+/// Label compose puts on everything it creates for a project.
+const PROJECT_LABEL: &str = "com.docker.compose.project";
+
+/// A project whose containers dx started: its compose file, the compose project name (to
+/// clean up even after the file is gone) and when it was last started (Unix seconds).
+#[derive(Serialize, Deserialize)]
+pub struct Environment {
+    pub compose: PathBuf,
+    pub project: String,
+    pub last_up: u64,
+}
+
+/// Tracked environments by project directory.
+pub type Environments = BTreeMap<PathBuf, Environment>;
+
+pub fn now() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).unwrap_or_default().as_secs()
+}
+
+pub fn environments_path() -> PathBuf {
+    crate::paths::state_dir().join(ENVIRONMENTS_FILE)
+}
+
+pub fn load() -> Environments {
+    fs::read_to_string(environments_path()).ok().and_then(|c| serde_json::from_str(&c).ok()).unwrap_or_default()
+}
+
+/// Read-modify-write of the tracked environments, under the file's lock.
+pub fn update(change: impl FnOnce(&mut Environments)) -> std::io::Result<()> {
+    let path = environments_path();
+    let _lock = crate::lock::for_file(&path)?;
+    let mut environments = load();
+    change(&mut environments);
+    crate::lock::write_atomic(&path, serde_json::to_string_pretty(&environments).unwrap_or_default())
+}
+
+/// Stop tracking the environments of `dirs`, reporting (not failing on) a write error.
+pub fn forget(dirs: &[PathBuf]) {
+    if let Err(e) = update(|environments| environments.retain(|dir, _| !dirs.contains(dir))) {
+        eprintln!("Aviso: falha ao atualizar {}: {}", environments_path().display(), e);
+    }
+}
+
+pub fn canonical(project_dir: &Path) -> PathBuf {
+    project_dir.canonicalize().unwrap_or_else(|_| project_dir.to_path_buf())
+}
+
+/// Compose project name of the environment dx generates for `project_dir`: `dx-<directory>-<hash>`,
+/// where the hash is of the absolute path, so two checkouts named alike never share containers,
+/// networks or volumes.
+pub fn project_name(project_dir: &Path) -> String {
+    let dir = canonical(project_dir);
+    let hash: String = Sha256::digest(dir.to_string_lossy().as_bytes()).iter().take(4).map(|b| format!("{:02x}", b)).collect();
+    let slug: String = crate::k8s::resource_name(&dir).chars().take(40).collect();
+    format!("dx-{}-{}", slug.trim_end_matches('-'), hash)
+}
+
+/// The name compose gives the project: COMPOSE_PROJECT_NAME, the file's top-level `name:`, else
+/// the compose file's directory, lower-cased and reduced to the characters compose accepts.
+pub fn compose_project_name(compose_path: &Path) -> String {
+    if let Some(name) = std::env::var("COMPOSE_PROJECT_NAME").ok().filter(|n| !n.is_empty()) {
+        return name;
+    }
+    let declared = fs::read_to_string(compose_path).ok().and_then(|c| {
+        c.lines().find_map(|l| l.strip_prefix("name:").map(|n| n.trim().trim_matches(['"', '\'']).to_string()))
+    });
+    let name = declared.filter(|n| !n.is_empty()).unwrap_or_else(|| {
+        let dir = canonical(compose_path.parent().unwrap_or(Path::new(".")));
+        dir.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_default()
+    });
+    name.to_lowercase()
+        .chars()
+        .filter(|c| c.is_ascii_alphanumeric() || *c == '_' || *c == '-')
+        .skip_while(|c| !c.is_ascii_alphanumeric())
+        .collect()
+}
+
+/// Remember that dx started the containers of `project_dir`, for `dx env` and `dx down --prune`.
+pub fn track(project_dir: &Path, compose_path: &Path) {
+    let environment = Environment { compose: canonical(compose_path), project: compose_project_name(compose_path), last_up: now() };
+    if let Err(e) = update(|environments| {
+        environments.insert(canonical(project_dir), environment);
+    }) {
+        eprintln!("Aviso: falha ao registrar o ambiente em {}: {}", environments_path().display(), e);
+    }
+}
+
+/// Run compose on an environment: through its file while it exists, else by project name.
+/// Tries Docker Compose V2, then the legacy docker-compose.
+pub fn compose(environment: &Environment, args: &[&str]) -> bool {
+    let target: Vec<OsString> = if environment.compose.exists() {
+        vec!["-f".into(), environment.compose.clone().into()]
+    } else {
+        vec!["-p".into(), environment.project.clone().into()]
+    };
+    let run = |program: &str, prefix: &[&str]| {
+        Command::new(program)
+            .args(prefix)
+            .args(&target)
+            .args(args)
+            .stdin(Stdio::inherit())
+            .stdout(Stdio::inherit())
+            .stderr(Stdio::inherit())
+            .status()
+            .is_ok_and(|s| s.success())
+    };
+    crate::audit::container(&environment.compose, &args.join(" "));
+    if run("docker", &["compose"]) {
+        return true;
+    }
+    eprintln!("Falha ao executar 'docker compose'. Tentando 'docker-compose' (CLI legada)...");
+    run("docker-compose", &[])
+}
+
+/// Lines docker prints for the objects of a compose project (`docker ps -a`, `docker volume ls`),
+/// or None when Docker does not answer.
+fn docker_lines(args: &[&str], project: &str) -> Option<Vec<String>> {
+    let output = Command::new("docker")
+        .args(args)
+        .args(["--filter", &format!("label={}={}", PROJECT_LABEL, project)])
+        .stderr(Stdio::null())
+        .output()
+        .ok()
+        .filter(|o| o.status.success())?;
+    Some(String::from_utf8_lossy(&output.stdout).lines().filter(|l| !l.trim().is_empty()).map(str::to_string).collect())
+}
+
+#[derive(Serialize)]
+struct EnvironmentJson {
+    name: String,
+    dir: PathBuf,
+    dir_exists: bool,
+    compose: PathBuf,
+    last_up: u64,
+    /// Counts from Docker; absent when it does not answer
+    #[serde(skip_serializing_if = "Option::is_none")]
+    containers: Option<usize>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    running: Option<usize>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    volumes: Option<usize>,
+}
+
+fn describe(dir: &Path, environment: &Environment) -> EnvironmentJson {
+    let states = docker_lines(&["ps", "-a", "--format", "{{.State}}"], &environment.project);
+    EnvironmentJson {
+        name: environment.project.clone(),
+        dir: dir.to_path_buf(),
+        dir_exists: dir.exists(),
+        compose: environment.compose.clone(),
+        last_up: environment.last_up,
+        containers: states.as_ref().map(|s| s.len()),
+        running: states.as_ref().map(|s| s.iter().filter(|s| s.as_str() == "running").count()),
+        volumes: docker_lines(&["volume", "ls", "-q"], &environment.project).map(|v| v.len()),
+    }
+}
+
+fn count(n: Option<usize>) -> String {
+    n.map(|n| n.to_string()).unwrap_or_else(|| "-".to_string())
+}
+
+/// `dx env list`: the environments dx started on this machine, with their containers and volumes.
+pub fn cmd_list() -> i32 {
+    let environments = load();
+    let rows: Vec<EnvironmentJson> = environments.iter().map(|(dir, e)| describe(dir, e)).collect();
+    if crate::output::json() {
+        crate::output::print(&rows);
+        return 0;
+    }
+    if rows.is_empty() {
+        println!("Nenhum ambiente registrado pelo dx. Eles são criados por: dx up (ou dx dev-services run)");
+        return 0;
+    }
+    println!("{:<32} {:<10} {:<8} {:<10} DIRETÓRIO", "AMBIENTE", "CONTAINERS", "VOLUMES", "ÚLTIMO UP");
+    for row in &rows {
+        let containers = match (row.running, row.containers) {
+            (Some(running), Some(total)) => format!("{}/{}", running, total),
+            _ => "-".to_string(),
+        };
+        let days = now().saturating_sub(row.last_up) / DAY;
+        let last_up = if days == 0 { "hoje".to_string() } else { format!("há {} dia(s)", days) };
+        let missing = if row.dir_exists { "" } else { " (não existe mais)" };
+        println!("{:<32} {:<10} {:<8} {:<10} {}{}", row.name, containers, count(row.volumes), last_up, row.dir.display(), missing);
+    }
+    if rows.iter().any(|r| r.containers.is_none()) {
+        eprintln!("Aviso: o Docker não respondeu; containers e volumes aparecem como '-'.");
+    }
+    0
+}
+
+/// `dx env rm`: remove an environment (by name or project directory) with its containers, networks
+/// and volumes, and stop tracking it.
+pub fn cmd_rm(name: String) -> i32 {
+    let environments = load();
+    let wanted = canonical(Path::new(&name));
+    let Some((dir, environment)) = environments.iter().find(|(dir, e)| e.project == name || **dir == wanted) else {
+        eprintln!("Erro: nenhum ambiente '{}'. Veja os registrados com: dx env list", name);
+        return 1;
+    };
+    println!("Removendo o ambiente {} ({})...", environment.project, dir.display());
+    if !compose(environment, &["down", "-v", "--remove-orphans"]) {
+        eprintln!("Erro: não foi possível remover o ambiente. Verifique se o Docker está em execução.");
+        return 1;
+    }
+    forget(&[dir.clone()]);
+    println!("✓ Ambiente {} removido, com containers, redes e volumes.", environment.project);
+    0
+}
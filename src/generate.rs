@@ -0,0 +1,766 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum Lang {
+    Go,
+    Node,
+    Python,
+}
+
+impl Lang {
+    fn label(self) -> &'static str {
+        match self {
+            Lang::Go => "Go",
+            Lang::Node => "Node.js",
+            Lang::Python => "Python",
+        }
+    }
+}
+
+/// Dev Services a generated service can connect to (`--with`).
+pub const SERVICES: &[&str] = &["kafka", "mongodb", "postgres", "mysql", "redis"];
+
+/// First port tried for a new service when the compose file does not use it yet.
+const FIRST_PORT: u16 = 8080;
+/// Directory new services go to when the monorepo has no other packages to follow.
+const DEFAULT_PARENT: &str = "services";
+
+/// Connection variable of a Dev Service and its value with `host` as the server address: the
+/// service name inside compose, `localhost` for the code's default. Credentials are the Dev
+/// Services defaults (`dx dev-services`).
+fn connection(service: &str, lang: Lang, host: &str) -> (&'static str, String) {
+    match service {
+        // Redpanda advertises kafka:9092 inside compose and localhost:29092 to the host
+        "kafka" if host == "localhost" => ("KAFKA_BROKERS", "localhost:29092".to_string()),
+        "kafka" => ("KAFKA_BROKERS", format!("{}:9092", host)),
+        "mongodb" => ("MONGODB_URI", format!("mongodb://root:example@{}:27017", host)),
+        "postgres" => ("DATABASE_URL", format!("postgres://postgres:example@{}:5432/app", host)),
+        "redis" => ("REDIS_URL", format!("redis://{}:6379", host)),
+        // go-sql-driver takes a DSN rather than a URL
+        "mysql" if lang == Lang::Go => ("MYSQL_DSN", format!("root:example@tcp({}:3306)/app", host)),
+        _ => ("MYSQL_URL", format!("mysql://root:example@{}:3306/app", host)),
+    }
+}
+
+/// Variables the generated code reads, with their defaults and the Dev Service they configure.
+fn env_vars(name: &str, lang: Lang, with: &[String], port: u16) -> Vec<(String, String, String)> {
+    let mut vars = vec![("PORT".to_string(), port.to_string(), "aplicação".to_string())];
+    for service in with {
+        let (var, value) = connection(service, lang, "localhost");
+        vars.push((var.to_string(), value, service.clone()));
+        match service.as_str() {
+            "kafka" => vars.push(("KAFKA_TOPIC".to_string(), name.to_string(), service.clone())),
+            "mongodb" => vars.push(("MONGODB_DATABASE".to_string(), name.replace('-', "_"), service.clone())),
+            _ => {}
+        }
+    }
+    vars
+}
+
+// Library versions pinned in the generated manifests.
+const GO_MODULES: &[(&str, &str, &str)] = &[
+    ("kafka", "github.com/segmentio/kafka-go", "v0.4.47"),
+    ("mongodb", "go.mongodb.org/mongo-driver", "v1.13.1"),
+    ("postgres", "github.com/jackc/pgx/v5", "v5.5.2"),
+    ("mysql", "github.com/go-sql-driver/mysql", "v1.7.1"),
+    ("redis", "github.com/redis/go-redis/v9", "v9.4.0"),
+];
+const NPM_PACKAGES: &[(&str, &str, &str)] = &[
+    ("kafka", "kafkajs", "^2.2.4"),
+    ("mongodb", "mongodb", "^6.3.0"),
+    ("postgres", "pg", "^8.11.3"),
+    ("mysql", "mysql2", "^3.7.0"),
+    ("redis", "redis", "^4.6.12"),
+];
+const PYPI_PACKAGES: &[(&str, &str)] = &[
+    ("kafka", "kafka-python==2.0.2"),
+    ("mongodb", "pymongo==4.6.1"),
+    ("postgres", "psycopg[binary]==3.1.17"),
+    ("mysql", "PyMySQL==1.1.0"),
+    ("redis", "redis==5.0.1"),
+];
+
+fn go_main(name: &str, with: &[String], port: u16) -> String {
+    let has = |s: &str| with.iter().any(|w| w == s);
+    let mut std_imports = vec!["\"context\"", "\"log\"", "\"net/http\"", "\"os\"", "\"os/signal\"", "\"syscall\"", "\"time\""];
+    if has("mysql") {
+        std_imports.push("\"database/sql\"");
+    }
+    if has("kafka") {
+        std_imports.push("\"strings\"");
+    }
+    std_imports.sort();
+    let mut modules = Vec::new();
+    for (service, module, _) in GO_MODULES.iter().filter(|(s, _, _)| has(s)) {
+        match *service {
+            "mysql" => modules.push(format!("_ \"{}\"", module)),
+            "postgres" => modules.push(format!("\"{}/pgxpool\"", module)),
+            "mongodb" => modules.extend([format!("\"{}/mongo\"", module), format!("\"{}/mongo/options\"", module)]),
+            _ => modules.push(format!("\"{}\"", module)),
+        }
+    }
+    // gofmt order: by import path
+    modules.sort_by(|a, b| a.trim_start_matches("_ ").cmp(b.trim_start_matches("_ ")));
+    let mut setup = String::new();
+    for service in with {
+        let (var, default) = connection(service, Lang::Go, "localhost");
+        let block = match service.as_str() {
+            "kafka" => format!(
+                "\tkafkaWriter := &kafka.Writer{{\n\t\tAddr:     kafka.TCP(strings.Split(getEnv(\"{var}\", \"{default}\"), \",\")...),\n\t\tTopic:    getEnv(\"KAFKA_TOPIC\", \"{name}\"),\n\t\tBalancer: &kafka.LeastBytes{{}},\n\t}}\n\tdefer kafkaWriter.Close()\n"
+            ),
+            "mongodb" => format!(
+                "\tmongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(getEnv(\"{var}\", \"{default}\")))\n\tif err != nil {{\n\t\tlog.Fatalf(\"MongoDB: %v\", err)\n\t}}\n\tdefer mongoClient.Disconnect(context.Background())\n\tdb := mongoClient.Database(getEnv(\"MONGODB_DATABASE\", \"{}\"))\n\t_ = db\n",
+                name.replace('-', "_")
+            ),
+            "postgres" => format!(
+                "\tpgPool, err := pgxpool.New(ctx, getEnv(\"{var}\", \"{default}\"))\n\tif err != nil {{\n\t\tlog.Fatalf(\"Postgres: %v\", err)\n\t}}\n\tdefer pgPool.Close()\n"
+            ),
+            "mysql" => format!(
+                "\tmysqlDB, err := sql.Open(\"mysql\", getEnv(\"{var}\", \"{default}\"))\n\tif err != nil {{\n\t\tlog.Fatalf(\"MySQL: %v\", err)\n\t}}\n\tdefer mysqlDB.Close()\n"
+            ),
+            _ => format!(
+                "\tredisOptions, err := redis.ParseURL(getEnv(\"{var}\", \"{default}\"))\n\tif err != nil {{\n\t\tlog.Fatalf(\"Redis: %v\", err)\n\t}}\n\tredisClient := redis.NewClient(redisOptions)\n\tdefer redisClient.Close()\n"
+            ),
+        };
+        setup.push_str(&block);
+        setup.push('\n');
+    }
+    let mut imports: String = std_imports.iter().map(|i| format!("\t{}\n", i)).collect();
+    if !modules.is_empty() {
+        imports.push('\n');
+        imports.extend(modules.iter().map(|m| format!("\t{}\n", m)));
+    }
+    format!(
+        r#"package main
+
+import (
+{imports})
+
+// getEnv returns the value of key, or fallback when it is unset.
+func getEnv(key, fallback string) string {{
+	if v := os.Getenv(key); v != "" {{
+		return v
+	}}
+	return fallback
+}}
+
+func main() {{
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+{setup}	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {{
+		w.Write([]byte("ok"))
+	}})
+
+	srv := &http.Server{{Addr: ":" + getEnv("PORT", "{port}"), Handler: mux}}
+	go func() {{
+		log.Printf("{name} ouvindo em %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {{
+			log.Fatal(err)
+		}}
+	}}()
+
+	<-ctx.Done()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	srv.Shutdown(shutdownCtx)
+}}
+"#
+    )
+}
+
+fn go_mod(module: &str, with: &[String]) -> String {
+    let mut out = format!("module {}\n\ngo 1.22\n", module);
+    let requires: Vec<String> = GO_MODULES
+        .iter()
+        .filter(|(s, _, _)| with.iter().any(|w| w == s))
+        .map(|(_, m, v)| format!("\t{} {}\n", m, v))
+        .collect();
+    if !requires.is_empty() {
+        out.push_str(&format!("\nrequire (\n{})\n", requires.concat()));
+    }
+    out
+}
+
+fn node_index(name: &str, with: &[String], port: u16) -> String {
+    let mut requires = vec!["const http = require('node:http');".to_string()];
+    let mut setup = String::new();
+    for service in with {
+        let (var, default) = connection(service, Lang::Node, "localhost");
+        let (require, block) = match service.as_str() {
+            "kafka" => (
+                "const { Kafka } = require('kafkajs');",
+                format!(
+                    "  const kafka = new Kafka({{ clientId: '{name}', brokers: (process.env.{var} || '{default}').split(',') }});\n  const producer = kafka.producer();\n  await producer.connect();\n  const topic = process.env.KAFKA_TOPIC || '{name}';\n"
+                ),
+            ),
+            "mongodb" => (
+                "const { MongoClient } = require('mongodb');",
+                format!(
+                    "  const mongo = new MongoClient(process.env.{var} || '{default}');\n  await mongo.connect();\n  const db = mongo.db(process.env.MONGODB_DATABASE || '{}');\n",
+                    name.replace('-', "_")
+                ),
+            ),
+            "postgres" => ("const { Pool } = require('pg');", format!("  const pg = new Pool({{ connectionString: process.env.{var} || '{default}' }});\n")),
+            "mysql" => ("const mysql = require('mysql2/promise');", format!("  const mysqlPool = mysql.createPool(process.env.{var} || '{default}');\n")),
+            _ => (
+                "const { createClient } = require('redis');",
+                format!("  const redis = createClient({{ url: process.env.{var} || '{default}' }});\n  await redis.connect();\n"),
+            ),
+        };
+        requires.push(require.to_string());
+        setup.push_str(&block);
+        setup.push('\n');
+    }
+    format!(
+        r#"{requires}
+
+const port = process.env.PORT || '{port}';
+
+async function main() {{
+{setup}  http
+    .createServer((req, res) => {{
+      if (req.url === '/healthz') {{
+        res.end('ok');
+        return;
+      }}
+      res.writeHead(404).end();
+    }})
+    .listen(port, () => console.log(`{name} ouvindo na porta ${{port}}`));
+}}
+
+main().catch((err) => {{
+  console.error(err);
+  process.exit(1);
+}});
+"#,
+        requires = requires.join("\n")
+    )
+}
+
+/// npm package name, scoped like the root package (`@acme/root` → `@acme/<name>`).
+fn npm_name(root: &Path, name: &str) -> String {
+    let root_name = fs::read_to_string(root.join("package.json"))
+        .ok()
+        .and_then(|c| serde_json::from_str::<serde_json::Value>(&c).ok())
+        .and_then(|v| v["name"].as_str().map(str::to_string));
+    match root_name.as_deref().and_then(|n| n.strip_prefix('@')).and_then(|n| n.split_once('/')) {
+        Some((scope, _)) => format!("@{}/{}", scope, name),
+        None => name.to_string(),
+    }
+}
+
+fn package_json(package: &str, with: &[String]) -> String {
+    let deps: serde_json::Map<String, serde_json::Value> = NPM_PACKAGES
+        .iter()
+        .filter(|(s, _, _)| with.iter().any(|w| w == s))
+        .map(|(_, p, v)| (p.to_string(), serde_json::Value::from(*v)))
+        .collect();
+    let doc = serde_json::json!({
+        "name": package,
+        "version": "0.1.0",
+        "private": true,
+        "main": "index.js",
+        "scripts": { "start": "node index.js", "test": "node --test" },
+        "dependencies": deps,
+    });
+    serde_json::to_string_pretty(&doc).unwrap_or_default() + "\n"
+}
+
+fn python_app(name: &str, with: &[String], port: u16) -> String {
+    let mut imports = vec!["import os".to_string()];
+    let mut setup = String::new();
+    for service in with {
+        let (var, default) = connection(service, Lang::Python, "localhost");
+        let (import, block) = match service.as_str() {
+            "kafka" => (
+                "from kafka import KafkaProducer",
+                format!(
+                    "producer = KafkaProducer(bootstrap_servers=os.getenv(\"{var}\", \"{default}\").split(\",\"))\nTOPIC = os.getenv(\"KAFKA_TOPIC\", \"{name}\")\n"
+                ),
+            ),
+            "mongodb" => (
+                "from pymongo import MongoClient",
+                format!(
+                    "mongo = MongoClient(os.getenv(\"{var}\", \"{default}\"))\ndb = mongo[os.getenv(\"MONGODB_DATABASE\", \"{}\")]\n",
+                    name.replace('-', "_")
+                ),
+            ),
+            "postgres" => ("import psycopg", format!("pg = psycopg.connect(os.getenv(\"{var}\", \"{default}\"))\n")),
+            "mysql" => (
+                "import pymysql\nfrom urllib.parse import urlparse",
+                format!(
+                    "_mysql = urlparse(os.getenv(\"{var}\", \"{default}\"))\nmysql = pymysql.connect(host=_mysql.hostname, port=_mysql.port or 3306, user=_mysql.username, password=_mysql.password, database=_mysql.path.lstrip(\"/\"))\n"
+                ),
+            ),
+            _ => ("import redis", format!("cache = redis.from_url(os.getenv(\"{var}\", \"{default}\"))\n")),
+        };
+        imports.push(import.to_string());
+        setup.push_str(&block);
+    }
+    imports.push("from http.server import BaseHTTPRequestHandler, HTTPServer".to_string());
+    if !setup.is_empty() {
+        setup = format!("\n{}", setup);
+    }
+    format!(
+        r#"{imports}
+
+PORT = int(os.getenv("PORT", "{port}"))
+{setup}
+
+class Handler(BaseHTTPRequestHandler):
+    def do_GET(self):
+        if self.path == "/healthz":
+            self.send_response(200)
+            self.end_headers()
+            self.wfile.write(b"ok")
+        else:
+            self.send_response(404)
+            self.end_headers()
+
+
+if __name__ == "__main__":
+    print(f"{name} ouvindo na porta {{PORT}}")
+    HTTPServer(("0.0.0.0", PORT), Handler).serve_forever()
+"#,
+        imports = imports.join("\n")
+    )
+}
+
+fn dockerfile(lang: Lang, port: u16) -> String {
+    match lang {
+        Lang::Go => format!(
+            "FROM golang:1.22-alpine AS build\nWORKDIR /src\nCOPY . .\nRUN go mod tidy && CGO_ENABLED=0 go build -o /out/app .\n\nFROM alpine:3.19\nCOPY --from=build /out/app /app\nEXPOSE {port}\nCMD [\"/app\"]\n"
+        ),
+        Lang::Node => format!(
+            "FROM node:20-alpine\nWORKDIR /app\nCOPY package*.json ./\nRUN npm install --omit=dev\nCOPY . .\nEXPOSE {port}\nCMD [\"node\", \"index.js\"]\n"
+        ),
+        Lang::Python => format!(
+            "FROM python:3.12-slim\nWORKDIR /app\nCOPY requirements.txt .\nRUN pip install --no-cache-dir -r requirements.txt\nCOPY . .\nEXPOSE {port}\nCMD [\"python\", \"app.py\"]\n"
+        ),
+    }
+}
+
+/// Build, test and run commands of the service (dx.yaml tasks and CI steps).
+fn commands(lang: Lang) -> [(&'static str, &'static str, &'static str); 3] {
+    match lang {
+        Lang::Go => [
+            ("build", "Compila o serviço", "go mod tidy && go build ./..."),
+            ("test", "Roda os testes", "go test ./..."),
+            ("start", "Sobe o serviço", "go run ."),
+        ],
+        Lang::Node => [
+            ("build", "Instala as dependências", "npm install"),
+            ("test", "Roda os testes", "npm test"),
+            ("start", "Sobe o serviço", "npm start"),
+        ],
+        Lang::Python => [
+            ("build", "Instala as dependências", "pip install -r requirements.txt"),
+            ("test", "Verifica a sintaxe", "python -m compileall -q ."),
+            ("start", "Sobe o serviço", "python app.py"),
+        ],
+    }
+}
+
+fn dx_yaml(lang: Lang) -> String {
+    let mut file = crate::tasks::DxFile::default();
+    for (name, description, run) in commands(lang) {
+        file.tasks.insert(
+            name.to_string(),
+            crate::tasks::Task {
+                description: Some(description.to_string()),
+                run: Some(crate::tasks::Run::One(run.to_string())),
+                ..Default::default()
+            },
+        );
+    }
+    serde_yaml::to_string(&file).unwrap_or_default()
+}
+
+fn readme(name: &str, lang: Lang, with: &[String], port: u16, rel: &str) -> String {
+    let services = if with.is_empty() { String::new() } else { format!(" Usa {}.", with.join(", ")) };
+    format!(
+        "# {name}\n\nServiço {} gerado com `dx generate service`.{services}\n\n## Desenvolvimento\n\n```bash\ndx dev-services run        # na raiz do monorepo: sobe as dependências\ncd {rel}\ndx dev-env init --env      # cria o .env a partir do .env.example\ndx run start               # http://localhost:{port}/healthz\n```\n",
+        lang.label()
+    )
+}
+
+fn gitignore(lang: Lang) -> &'static str {
+    match lang {
+        Lang::Go => ".env\n/bin/\n",
+        Lang::Node => ".env\nnode_modules/\n",
+        Lang::Python => ".env\n__pycache__/\n.venv/\n",
+    }
+}
+
+/// `*` matches any run of characters except `/`.
+fn wildcard(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern.first() {
+        None => text.is_empty(),
+        Some(b'*') => wildcard(&pattern[1..], text) || (!text.is_empty() && text[0] != b'/' && wildcard(pattern, &text[1..])),
+        Some(c) => text.first() == Some(c) && wildcard(&pattern[1..], &text[1..]),
+    }
+}
+
+/// Add `item` to the JSON array after `"key"` in `content`, keeping the file's formatting.
+fn json_array_push(content: &str, key: &str, item: &str) -> Option<String> {
+    let at = content.find(&format!("\"{}\"", key))?;
+    let open = at + content[at..].find('[')?;
+    let close = open + content[open..].find(']')?;
+    let inside = &content[open + 1..close];
+    let entry = format!("\"{}\"", item);
+    let insert = if inside.trim().is_empty() {
+        entry
+    } else if inside.contains('\n') {
+        let last = inside.trim_end();
+        let indent: String = last.lines().last().unwrap_or("").chars().take_while(|c| c.is_whitespace()).collect();
+        return Some(format!("{}{},\n{}{}{}", &content[..open + 1], last, indent, entry, &content[open + 1 + last.len()..]));
+    } else {
+        format!("{}, {}", inside.trim_end(), entry)
+    };
+    Some(format!("{}{}{}", &content[..open + 1], insert, &content[close..]))
+}
+
+/// One file written by the generator.
+struct Change {
+    path: PathBuf,
+    content: String,
+    /// What changed in an existing file (`None` for new files)
+    note: Option<String>,
+}
+
+/// Register the service in the workspace of its language: go.work, npm/pnpm workspaces or uv.
+fn register_workspace(root: &Path, rel: &str, lang: Lang, changes: &mut Vec<Change>, notes: &mut Vec<String>) {
+    match lang {
+        Lang::Go => {
+            let path = root.join("go.work");
+            let Ok(content) = fs::read_to_string(&path) else {
+                notes.push(format!("sem go.work na raiz; para um workspace Go: go work init && go work use ./{}", rel));
+                return;
+            };
+            let entry = format!("./{}", rel);
+            if content.lines().any(|l| l.trim().trim_start_matches("use").trim() == entry) {
+                return;
+            }
+            let updated = match content.find("use (") {
+                Some(start) => {
+                    let close = start + content[start..].find(')').unwrap_or(content.len() - start);
+                    format!("{}\t{}\n{}", &content[..close], entry, &content[close..])
+                }
+                None => format!("{}\nuse {}\n", content.trim_end(), entry),
+            };
+            changes.push(Change { path, content: updated, note: Some(format!("use {}", entry)) });
+        }
+        Lang::Node => {
+            let pnpm = root.join("pnpm-workspace.yaml");
+            if let Ok(content) = fs::read_to_string(&pnpm) {
+                let patterns: Vec<String> = content
+                    .lines()
+                    .filter_map(|l| l.trim().strip_prefix("- "))
+                    .map(|p| p.trim().trim_matches(|c| c == '"' || c == '\'').to_string())
+                    .collect();
+                if !patterns.iter().any(|p| wildcard(p.as_bytes(), rel.as_bytes())) {
+                    let updated = format!("{}\n  - '{}'\n", content.trim_end(), rel);
+                    changes.push(Change { path: pnpm, content: updated, note: Some(format!("pacote {}", rel)) });
+                }
+                return;
+            }
+            let path = root.join("package.json");
+            let content = fs::read_to_string(&path).unwrap_or_default();
+            let doc: serde_json::Value = serde_json::from_str(&content).unwrap_or_default();
+            let workspaces = doc["workspaces"].as_array().or_else(|| doc["workspaces"]["packages"].as_array());
+            let Some(workspaces) = workspaces else {
+                notes.push("sem workspaces no package.json da raiz; o pacote não foi registrado".to_string());
+                return;
+            };
+            if workspaces.iter().filter_map(|w| w.as_str()).any(|p| wildcard(p.as_bytes(), rel.as_bytes())) {
+                return;
+            }
+            if let Some(updated) = json_array_push(&content, "workspaces", rel).or_else(|| json_array_push(&content, "packages", rel)) {
+                changes.push(Change { path, content: updated, note: Some(format!("workspaces += {}", rel)) });
+            }
+        }
+        Lang::Python => {
+            let path = root.join("pyproject.toml");
+            let content = fs::read_to_string(&path).unwrap_or_default();
+            let Ok(mut doc) = content.parse::<toml_edit::DocumentMut>() else { return };
+            let Some(members) = doc
+                .get_mut("tool")
+                .and_then(|t| t.get_mut("uv"))
+                .and_then(|u| u.get_mut("workspace"))
+                .and_then(|w| w.get_mut("members"))
+                .and_then(|m| m.as_array_mut())
+            else {
+                notes.push("sem [tool.uv.workspace] no pyproject.toml da raiz; o pacote não foi registrado".to_string());
+                return;
+            };
+            if members.iter().filter_map(|m| m.as_str()).any(|p| wildcard(p.as_bytes(), rel.as_bytes())) {
+                return;
+            }
+            members.push(rel);
+            changes.push(Change { path, content: doc.to_string(), note: Some(format!("tool.uv.workspace.members += {}", rel)) });
+        }
+    }
+}
+
+/// Range of the top-level `services:` block of a compose file, as line indexes.
+fn services_block(lines: &[&str]) -> Option<(usize, usize)> {
+    let start = lines.iter().position(|l| l.trim_end() == "services:")?;
+    let end = lines[start + 1..]
+        .iter()
+        .position(|l| !l.is_empty() && !l.starts_with([' ', '\t', '#']))
+        .map_or(lines.len(), |i| start + 1 + i);
+    Some((start, end))
+}
+
+/// Names of the services of a compose file.
+fn compose_services(content: &str) -> Vec<String> {
+    let lines: Vec<&str> = content.lines().collect();
+    let Some((start, end)) = services_block(&lines) else { return Vec::new() };
+    lines[start + 1..end]
+        .iter()
+        .filter(|l| l.starts_with("  ") && !l.starts_with("   ") && l.trim_end().ends_with(':'))
+        .map(|l| l.trim().trim_end_matches(':').to_string())
+        .collect()
+}
+
+/// Host ports already published in a compose file (`- "8080:8080"`).
+fn compose_ports(content: &str) -> Vec<u16> {
+    content
+        .lines()
+        .filter_map(|l| l.trim().strip_prefix("- "))
+        .filter_map(|p| p.trim_matches(|c| c == '"' || c == '\'').split(':').next()?.parse().ok())
+        .collect()
+}
+
+fn compose_service(name: &str, rel: &str, lang: Lang, with: &[String], port: u16, existing: &[String]) -> String {
+    let mut block = format!("  {}:\n    build: ./{}\n    ports:\n      - '{}:{}'\n", name, rel, port, port);
+    block.push_str(&format!("    environment:\n      PORT: '{}'\n", port));
+    for service in with {
+        let (var, value) = connection(service, lang, service);
+        block.push_str(&format!("      {}: {}\n", var, value));
+    }
+    let deps: Vec<&String> = with.iter().filter(|s| existing.contains(s)).collect();
+    if !deps.is_empty() {
+        block.push_str("    depends_on:\n");
+        for dep in deps {
+            block.push_str(&format!("      - {}\n", dep));
+        }
+    }
+    block
+}
+
+/// Add the service to the monorepo's compose file, creating one with its Dev Services if needed.
+fn register_compose(root: &Path, name: &str, rel: &str, lang: Lang, with: &[String], port: u16, changes: &mut Vec<Change>, notes: &mut Vec<String>) {
+    let existing_file = crate::dev_services::find_project_compose_file(root);
+    let (path, content, created) = match &existing_file {
+        Some(path) => (path.clone(), fs::read_to_string(path).unwrap_or_default(), false),
+        None => {
+            let mut config = crate::dev_services::DockerComposeConfig::new();
+            for service in with {
+                crate::dev_services::add_known_service(&mut config, service);
+            }
+            (root.join("compose.yaml"), config.to_yaml(), true)
+        }
+    };
+    let services = compose_services(&content);
+    for missing in with.iter().filter(|s| !services.contains(s)) {
+        notes.push(format!("{} não está em {}: o serviço usará o do dx dev-services (sem depends_on)", missing, path.display()));
+    }
+    let block = compose_service(name, rel, lang, with, port, &services);
+    let lines: Vec<&str> = content.lines().collect();
+    let updated = match services_block(&lines) {
+        Some((_, end)) => {
+            // After the last non-blank line of the block
+            let at = (0..end).rev().find(|i| !lines[*i].trim().is_empty()).map_or(end, |i| i + 1);
+            let mut out: Vec<String> = lines[..at].iter().map(|l| l.to_string()).collect();
+            out.push(block.trim_end().to_string());
+            out.extend(lines[at..].iter().map(|l| l.to_string()));
+            out.join("\n") + "\n"
+        }
+        None => format!("{}\nservices:\n{}", content.trim_end(), block).trim_start().to_string(),
+    };
+    let note = if created { None } else { Some(format!("serviço {}", name)) };
+    changes.push(Change { path, content: updated, note });
+}
+
+fn github_workflow(name: &str, rel: &str, lang: Lang) -> String {
+    let setup = match lang {
+        Lang::Go => format!("      - uses: actions/setup-go@v5\n        with:\n          go-version-file: {}/go.mod\n", rel),
+        Lang::Node => "      - uses: actions/setup-node@v4\n        with:\n          node-version: 20\n".to_string(),
+        Lang::Python => "      - uses: actions/setup-python@v5\n        with:\n          python-version: '3.12'\n".to_string(),
+    };
+    let steps: String = commands(lang)[..2].iter().map(|(_, _, run)| format!("      - run: {}\n", run)).collect();
+    let paths = format!("    paths:\n      - '{}/**'\n      - '.github/workflows/{}.yml'\n", rel, name);
+    format!(
+        "name: {name}\n\non:\n  push:\n{paths}  pull_request:\n{paths}\njobs:\n  build:\n    runs-on: ubuntu-latest\n    defaults:\n      run:\n        working-directory: {rel}\n    steps:\n      - uses: actions/checkout@v4\n{setup}{steps}"
+    )
+}
+
+fn gitlab_job(name: &str, rel: &str, lang: Lang) -> String {
+    let image = match lang {
+        Lang::Go => "golang:1.22",
+        Lang::Node => "node:20",
+        Lang::Python => "python:3.12",
+    };
+    let steps: String = commands(lang)[..2].iter().map(|(_, _, run)| format!("    - {}\n", run)).collect();
+    format!("\n{name}:\n  image: {image}\n  rules:\n    - changes:\n        - {rel}/**/*\n  script:\n    - cd {rel}\n{steps}")
+}
+
+/// Add a CI job for the service: a job in .gitlab-ci.yml, or a GitHub Actions workflow limited to its path.
+fn register_ci(root: &Path, name: &str, rel: &str, lang: Lang, changes: &mut Vec<Change>, notes: &mut Vec<String>) {
+    let gitlab = root.join(".gitlab-ci.yml");
+    if let Ok(content) = fs::read_to_string(&gitlab) {
+        if content.lines().any(|l| l.trim_end() == format!("{}:", name)) {
+            notes.push(format!("{} já tem o job {}", gitlab.display(), name));
+            return;
+        }
+        let updated = format!("{}\n{}", content.trim_end(), gitlab_job(name, rel, lang));
+        changes.push(Change { path: gitlab, content: updated, note: Some(format!("job {}", name)) });
+        return;
+    }
+    let path = root.join(".github/workflows").join(format!("{}.yml", name));
+    if path.exists() {
+        notes.push(format!("{} já existe; o CI não foi alterado", path.display()));
+        return;
+    }
+    changes.push(Change { path, content: github_workflow(name, rel, lang), note: None });
+}
+
+/// Where the monorepo keeps its packages: the most common parent of the existing ones.
+fn default_parent(root: &Path) -> String {
+    let mut parents: BTreeMap<String, usize> = BTreeMap::new();
+    for package in crate::dev_badges::find_packages(root) {
+        if let Some(parent) = package.parent().and_then(|p| p.strip_prefix(root).ok()) {
+            if !parent.as_os_str().is_empty() {
+                *parents.entry(parent.to_string_lossy().replace('\\', "/")).or_default() += 1;
+            }
+        }
+    }
+    parents.into_iter().max_by_key(|(_, n)| *n).map_or(DEFAULT_PARENT.to_string(), |(p, _)| p)
+}
+
+/// Go module path next to the monorepo's other modules (`github.com/acme/api` → `github.com/acme/<name>`).
+fn go_module(root: &Path, name: &str) -> String {
+    let sibling = crate::dev_badges::find_packages(root).into_iter().chain(std::iter::once(root.to_path_buf())).find_map(|d| {
+        let content = fs::read_to_string(d.join("go.mod")).ok()?;
+        content.lines().find_map(|l| l.strip_prefix("module ").map(|m| m.trim().to_string()))
+    });
+    match sibling.as_deref().and_then(|m| m.rsplit_once('/')) {
+        Some((prefix, _)) => format!("{}/{}", prefix, name),
+        None => format!("example.com/{}", name),
+    }
+}
+
+fn is_service_name(name: &str) -> bool {
+    !name.is_empty()
+        && name.starts_with(|c: char| c.is_ascii_lowercase())
+        && name.chars().all(|c| c.is_ascii_lowercase() || c.is_ascii_digit() || c == '-')
+}
+
+/// `dx generate service <nome>`: scaffold a service in the monorepo at `dir` and register it in the
+/// workspace, the compose file and CI. With `dry_run`, only prints the files it would write.
+pub fn cmd_service(
+    name: String,
+    lang: Lang,
+    with: Vec<String>,
+    path: Option<String>,
+    port: Option<u16>,
+    dry_run: bool,
+    dir: Option<PathBuf>,
+) -> i32 {
+    let root = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    if !is_service_name(&name) {
+        eprintln!("Nome de serviço inválido: '{}' (use letras minúsculas, números e '-')", name);
+        return 2;
+    }
+    let mut with: Vec<String> = with.into_iter().map(|s| s.trim().to_lowercase()).filter(|s| !s.is_empty()).collect();
+    with.dedup();
+    if let Some(unknown) = with.iter().find(|s| !SERVICES.contains(&s.as_str())) {
+        eprintln!("Dev Service desconhecido em --with: '{}' (disponíveis: {})", unknown, SERVICES.join(", "));
+        return 2;
+    }
+    let rel = path.unwrap_or_else(|| format!("{}/{}", default_parent(&root), name)).trim_matches('/').to_string();
+    let target = root.join(&rel);
+    if fs::read_dir(&target).is_ok_and(|mut entries| entries.next().is_some()) {
+        eprintln!("{} já existe e não está vazio.", target.display());
+        return 2;
+    }
+    let compose = crate::dev_services::find_project_compose_file(&root).and_then(|p| fs::read_to_string(p).ok()).unwrap_or_default();
+    if compose_services(&compose).contains(&name) {
+        eprintln!("O compose da raiz já tem um serviço chamado {}.", name);
+        return 2;
+    }
+    let used = compose_ports(&compose);
+    let port = port.unwrap_or_else(|| (FIRST_PORT..).find(|p| !used.contains(p)).unwrap_or(FIRST_PORT));
+
+    let mut files: Vec<(&str, String)> = match lang {
+        Lang::Go => vec![("go.mod", go_mod(&go_module(&root, &name), &with)), ("main.go", go_main(&name, &with, port))],
+        Lang::Node => vec![("package.json", package_json(&npm_name(&root, &name), &with)), ("index.js", node_index(&name, &with, port))],
+        Lang::Python => {
+            let requirements: String =
+                PYPI_PACKAGES.iter().filter(|(s, _)| with.iter().any(|w| w == s)).map(|(_, p)| format!("{}\n", p)).collect();
+            vec![("requirements.txt", requirements), ("app.py", python_app(&name, &with, port))]
+        }
+    };
+    // Read sites for .env.example, as `dx dev-env init` would write them
+    let (code_file, code) = (files[1].0, files[1].1.clone());
+    let vars: Vec<crate::dev_env::EnvVar> = env_vars(&name, lang, &with, port)
+        .into_iter()
+        .map(|(var, default, service)| {
+            let line = code.lines().position(|l| l.contains(&format!("\"{}\"", var)) || l.contains(&format!("env.{}", var)));
+            let locations = line.map(|i| format!("{}:{}", code_file, i + 1)).into_iter().collect();
+            crate::dev_env::EnvVar { name: var, default: Some(default), required: false, service, locations }
+        })
+        .collect();
+    files.extend([
+        ("Dockerfile", dockerfile(lang, port)),
+        (".gitignore", gitignore(lang).to_string()),
+        (".env.example", crate::dev_env::render_example(&vars)),
+        (crate::tasks::DX_FILE, dx_yaml(lang)),
+        ("README.md", readme(&name, lang, &with, port, &rel)),
+    ]);
+    let mut changes: Vec<Change> =
+        files.into_iter().map(|(file, content)| Change { path: target.join(file), content, note: None }).collect();
+    let mut notes = Vec::new();
+    register_workspace(&root, &rel, lang, &mut changes, &mut notes);
+    register_compose(&root, &name, &rel, lang, &with, port, &mut changes, &mut notes);
+    register_ci(&root, &name, &rel, lang, &mut changes, &mut notes);
+
+    let services = if with.is_empty() { String::new() } else { format!(", com {}", with.join(", ")) };
+    println!("{} o serviço {} ({}{}) em {}, porta {}:", if dry_run { "Geraria" } else { "Gerando" }, name, lang.label(), services, rel, port);
+    for change in &changes {
+        let shown = change.path.strip_prefix(&root).unwrap_or(&change.path).display().to_string();
+        match &change.note {
+            None => println!("  + {}", shown),
+            Some(note) => println!("  ~ {} ({})", shown, note),
+        }
+        if dry_run {
+            continue;
+        }
+        let written = fs::create_dir_all(change.path.parent().unwrap_or(&root)).and_then(|_| crate::audit::write(&change.path, &change.content));
+        if let Err(e) = written {
+            eprintln!("Erro ao escrever {}: {}", change.path.display(), e);
+            return 1;
+        }
+    }
+    for note in &notes {
+        println!("  ! {}", note);
+    }
+    if dry_run {
+        return 0;
+    }
+    println!("\nPróximos passos:");
+    println!("  cd {} && dx run build", rel);
+    if !with.is_empty() {
+        println!("  dx dev-services run    # sobe {}", with.join(", "));
+    }
+    println!("  docker compose up --build {}", name);
+    0
+}
@@ -0,0 +1,274 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::k8s::{quote, Workload};
+use std::fs;
+use std::path::PathBuf;
+
+const CHARTS_DIR: &str = "charts";
+/// Text of the first line of the generated files (a `#` or `{{/* */}}` comment); files without it
+/// were written by hand.
+const HEADER: &str = "Gerado por: dx dev-config helm";
+
+const HELPERS: &str = r#"{{/* Gerado por: dx dev-config helm */}}
+{{- define "app.fullname" -}}
+{{- if contains .Chart.Name .Release.Name -}}
+{{- .Release.Name | trunc 63 | trimSuffix "-" -}}
+{{- else -}}
+{{- printf "%s-%s" .Release.Name .Chart.Name | trunc 63 | trimSuffix "-" -}}
+{{- end -}}
+{{- end -}}
+
+{{- define "app.selectorLabels" -}}
+app.kubernetes.io/name: {{ .Chart.Name }}
+app.kubernetes.io/instance: {{ .Release.Name }}
+{{- end -}}
+
+{{- define "app.labels" -}}
+{{ include "app.selectorLabels" . }}
+app.kubernetes.io/version: {{ .Chart.AppVersion | quote }}
+app.kubernetes.io/managed-by: {{ .Release.Service }}
+helm.sh/chart: {{ printf "%s-%s" .Chart.Name .Chart.Version }}
+{{- end -}}
+
+{{- define "app.probe" -}}
+{{- if .path }}
+httpGet:
+  path: {{ .path }}
+  port: http
+{{- else }}
+tcpSocket:
+  port: http
+{{- end }}
+periodSeconds: {{ .periodSeconds }}
+{{- end -}}
+"#;
+
+const DEPLOYMENT: &str = r#"# Gerado por: dx dev-config helm
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  replicas: {{ .Values.replicaCount }}
+  selector:
+    matchLabels:
+      {{- include "app.selectorLabels" . | nindent 6 }}
+  template:
+    metadata:
+      labels:
+        {{- include "app.selectorLabels" . | nindent 8 }}
+      annotations:
+        # Restart the pods when the variables change
+        checksum/config: {{ include (print $.Template.BasePath "/configmap.yaml") . | sha256sum }}
+    spec:
+      containers:
+        - name: {{ .Chart.Name }}
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag | default .Chart.AppVersion }}"
+          imagePullPolicy: {{ .Values.image.pullPolicy }}
+          ports:
+            - name: http
+              containerPort: {{ .Values.containerPort }}
+          envFrom:
+            - configMapRef:
+                name: {{ include "app.fullname" . }}
+            {{- with .Values.existingSecret }}
+            - secretRef:
+                name: {{ . }}
+                optional: true
+            {{- end }}
+          {{- if .Values.probes.enabled }}
+          readinessProbe:
+            {{- include "app.probe" (dict "path" (.Values.probes.readinessPath | default .Values.probes.path) "periodSeconds" .Values.probes.periodSeconds) | nindent 12 }}
+            initialDelaySeconds: {{ .Values.probes.readinessInitialDelaySeconds }}
+          livenessProbe:
+            {{- include "app.probe" (dict "path" .Values.probes.path "periodSeconds" .Values.probes.periodSeconds) | nindent 12 }}
+            initialDelaySeconds: {{ .Values.probes.livenessInitialDelaySeconds }}
+          {{- end }}
+          resources:
+            {{- toYaml .Values.resources | nindent 12 }}
+          securityContext:
+            {{- toYaml .Values.securityContext | nindent 12 }}
+"#;
+
+const SERVICE: &str = r#"# Gerado por: dx dev-config helm
+apiVersion: v1
+kind: Service
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+spec:
+  type: {{ .Values.service.type }}
+  selector:
+    {{- include "app.selectorLabels" . | nindent 4 }}
+  ports:
+    - name: http
+      port: {{ .Values.service.port }}
+      targetPort: http
+"#;
+
+const CONFIGMAP: &str = r#"# Gerado por: dx dev-config helm
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: {{ include "app.fullname" . }}
+  labels:
+    {{- include "app.labels" . | nindent 4 }}
+data:
+  {{- with .Values.portEnv }}
+  {{ . }}: {{ $.Values.containerPort | quote }}
+  {{- end }}
+  {{- range $key, $value := .Values.env }}
+  {{ $key }}: {{ $value | quote }}
+  {{- end }}
+"#;
+
+const HELMIGNORE: &str = "# Gerado por: dx dev-config helm\n.git/\n.DS_Store\n*.tgz\n*.swp\n*.bak\n*.tmp\n.vscode/\n.idea/\n";
+
+/// `repository:tag` split for values.yaml; the tag also becomes the chart's appVersion.
+fn split_image(image: &str) -> (&str, &str) {
+    match image.rsplit_once(':') {
+        Some((repository, tag)) if !tag.contains('/') => (repository, tag),
+        _ => (image, "latest"),
+    }
+}
+
+fn render_chart(workload: &Workload, app_version: &str) -> String {
+    format!(
+        "# {HEADER}\n\
+         apiVersion: v2\n\
+         name: {name}\n\
+         description: Chart da aplicação {name}\n\
+         type: application\n\
+         version: 0.1.0\n\
+         appVersion: {}\n",
+        quote(app_version),
+        name = workload.name
+    )
+}
+
+/// values.yaml: the image, port, probes and resources, and the variables of the env scan, each one
+/// a value that can be overridden per release (`--set env.MONGODB_URI=...`).
+fn render_values(workload: &Workload, repository: &str) -> String {
+    let port_env = workload.port_env();
+    let mut out = format!(
+        "# {HEADER}\n\
+         replicaCount: 1\n\n\
+         image:\n\
+         \x20 repository: {repository}\n\
+         \x20 # Padrão: appVersion do Chart.yaml\n\
+         \x20 tag: \"\"\n\
+         \x20 pullPolicy: IfNotPresent\n\n\
+         containerPort: {port}\n\
+         # Variável que recebe containerPort\n\
+         portEnv: {port_env}\n\n\
+         service:\n\
+         \x20 type: ClusterIP\n\
+         \x20 port: 80\n\n",
+        port = workload.port,
+        port_env = port_env.unwrap_or("\"\""),
+    );
+    out.push_str("# Variáveis lidas no código, com o padrão de lá; as conexões apontam para os serviços pelo nome\n");
+    if !workload.required.is_empty() {
+        out.push_str(&format!("# Obrigatórias (sem padrão no código), acrescente com os valores do ambiente: {}\n", workload.required.join(", ")));
+    }
+    let env: Vec<(&String, &String)> = workload.env.iter().filter(|(k, _)| Some(k.as_str()) != port_env).collect();
+    if env.is_empty() {
+        out.push_str("env: {}\n\n");
+    } else {
+        out.push_str("env:\n");
+        for (key, value) in env {
+            out.push_str(&format!("  {}: {}\n", key, quote(value)));
+        }
+        out.push('\n');
+    }
+    match workload.secrets.as_slice() {
+        [] => out.push_str("# Secret com variáveis sensíveis, lido além do ConfigMap\nexistingSecret: \"\"\n\n"),
+        secrets => out.push_str(&format!("# Secret com as variáveis sensíveis: {}\nexistingSecret: {}-secrets\n\n", secrets.join(", "), workload.name)),
+    }
+    out.push_str(&format!(
+        "probes:\n\
+         \x20 enabled: true\n\
+         \x20 # Rota GET de saúde detectada no código; vazio usa uma probe TCP na porta\n\
+         \x20 path: {}\n\
+         \x20 # Rota GET de prontidão (ex.: /readyz, que verifica as dependências); vazio usa path\n\
+         \x20 readinessPath: {}\n\
+         \x20 readinessInitialDelaySeconds: 5\n\
+         \x20 livenessInitialDelaySeconds: 15\n\
+         \x20 periodSeconds: 10\n\n\
+         resources:\n\
+         \x20 requests:\n\
+         \x20   cpu: 100m\n\
+         \x20   memory: 128Mi\n\
+         \x20 limits:\n\
+         \x20   memory: 256Mi\n\n\
+         securityContext:\n\
+         \x20 allowPrivilegeEscalation: false\n\
+         \x20 capabilities:\n\
+         \x20   drop: [\"ALL\"]\n",
+        workload.health.as_deref().unwrap_or("\"\""),
+        workload.ready.as_deref().unwrap_or("\"\"")
+    ));
+    out
+}
+
+/// `dx dev-config helm`: scaffold a Helm chart under charts/<name> with Deployment, Service and
+/// ConfigMap templates whose values (image, port, probes, resources and every variable of the env
+/// scan) come from what dx detects. Hand-written files are kept, merged or replaced as `conflicts`
+/// settles them (`force` replaces).
+pub fn cmd_helm(dir: Option<PathBuf>, save_file: bool, force: bool, image: Option<String>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(workload) = Workload::detect(&project_dir) else {
+        eprintln!("Nenhuma stack detectada em {}; nada a implantar.", project_dir.display());
+        return 1;
+    };
+    let image = image.unwrap_or_else(|| format!("{}:latest", workload.name));
+    let (repository, tag) = split_image(&image);
+
+    let chart = project_dir.join(CHARTS_DIR).join(&workload.name);
+    let templates = chart.join("templates");
+    let mut files = vec![
+        (chart.join("Chart.yaml"), render_chart(&workload, tag)),
+        (chart.join("values.yaml"), render_values(&workload, repository)),
+        (chart.join(".helmignore"), HELMIGNORE.to_string()),
+        (templates.join("_helpers.tpl"), HELPERS.to_string()),
+        (templates.join("deployment.yaml"), DEPLOYMENT.to_string()),
+        (templates.join("service.yaml"), SERVICE.to_string()),
+        (templates.join("configmap.yaml"), CONFIGMAP.to_string()),
+    ];
+
+    if !save_file {
+        for (path, content) in &files {
+            println!("# {}\n{}", path.strip_prefix(&project_dir).unwrap_or(path).display(), content);
+        }
+        return 0;
+    }
+    if !crate::conflicts::settle(&mut files, crate::conflicts::generated_by(HEADER), force) {
+        return 1;
+    }
+    if let Err(e) = fs::create_dir_all(&templates) {
+        eprintln!("Erro ao criar {}: {}", templates.display(), e);
+        return 1;
+    }
+    for (path, content) in &files {
+        if let Err(e) = crate::audit::write(path, content) {
+            eprintln!("Erro ao salvar {}: {}", path.display(), e);
+            return 1;
+        }
+        println!("  {}", path.display());
+    }
+
+    println!(
+        "✓ Chart Helm gerado ({}; porta {}; {}; {} variável(is) em values.yaml).",
+        workload.name,
+        workload.port,
+        workload.probes_summary(),
+        workload.env.len()
+    );
+    workload.print_notes("O values.yaml");
+    println!("Instale com: helm upgrade --install {} {}", workload.name, chart.display());
+    0
+}
@@ -0,0 +1,212 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::fs;
+use std::io::Write;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+use std::sync::OnceLock;
+use std::time::{Instant, SystemTime, UNIX_EPOCH};
+
+/// Per-project history, relative to the directory where dx was invoked.
+pub const HISTORY_FILE: &str = ".dx/history.jsonl";
+/// Older entries are dropped once the file grows past this many lines.
+const MAX_ENTRIES: usize = 500;
+/// Subcommands that are not worth repeating (or would repeat themselves).
+const SKIPPED: &[&str] = &["history", "rerun", "clean", "allow", "deny", "prompt", "audit", "undo"];
+/// Subcommands run behind the user's back: the direnv hook runs `dev-env export` on every `cd`.
+const SKIPPED_SUBCOMMANDS: &[(&str, &str)] = &[("dev-env", "export")];
+/// Set to `0` for dx processes started by dx itself, so nested steps are not recorded.
+pub const HISTORY_ENV: &str = "DX_HISTORY";
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct Entry {
+    /// Unix timestamp (seconds) of when the command started
+    pub started_at: u64,
+    pub duration_ms: u64,
+    pub exit_code: i32,
+    /// Arguments after `dx`
+    pub args: Vec<String>,
+}
+
+struct Pending {
+    started: Instant,
+    started_at: u64,
+    args: Vec<String>,
+    cwd: PathBuf,
+}
+
+static PENDING: OnceLock<Pending> = OnceLock::new();
+
+/// Whether an invocation with the subcommand names `command` is left out of the history.
+fn skipped(command: &[String]) -> bool {
+    let first = command.first().map(String::as_str).unwrap_or_default();
+    let second = command.get(1).map(String::as_str).unwrap_or_default();
+    SKIPPED.contains(&first) || SKIPPED_SUBCOMMANDS.contains(&(first, second))
+}
+
+/// Remember the current invocation (`args`, whose subcommand names are `command`) so `record` can
+/// append it when the command ends.
+pub fn init(args: &[String], command: &[String]) {
+    if command.is_empty() || skipped(command) || std::env::var(HISTORY_ENV).is_ok_and(|v| v == "0") {
+        return;
+    }
+    let Ok(cwd) = std::env::current_dir() else { return };
+    let _ = PENDING.set(Pending { started: Instant::now(), started_at: now_secs(), args: args.to_vec(), cwd });
+}
+
+/// Append the invocation started in `init` with its outcome. Failures are ignored:
+/// history must never break the command itself.
+pub fn record(exit_code: i32) {
+    let Some(p) = PENDING.get() else { return };
+    let entry = Entry {
+        started_at: p.started_at,
+        duration_ms: p.started.elapsed().as_millis() as u64,
+        exit_code,
+        args: p.args.clone(),
+    };
+    let _ = append(&p.cwd, &entry);
+}
+
+fn append(project_dir: &Path, entry: &Entry) -> std::io::Result<()> {
+    let path = project_dir.join(HISTORY_FILE);
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    let line = serde_json::to_string(entry).map_err(std::io::Error::other)?;
+    let _lock = crate::lock::for_file(&path)?;
+    let mut file = fs::OpenOptions::new().create(true).append(true).open(&path)?;
+    writeln!(file, "{}", line)?;
+    drop(file);
+
+    let entries = load(project_dir);
+    if entries.len() > MAX_ENTRIES {
+        let keep: Vec<String> = entries[entries.len() - MAX_ENTRIES..]
+            .iter()
+            .filter_map(|e| serde_json::to_string(e).ok())
+            .collect();
+        crate::lock::write_atomic(&path, keep.join("\n") + "\n")?;
+    }
+    Ok(())
+}
+
+/// Read the history of `project_dir`, oldest first. Unreadable lines are skipped.
+pub fn load(project_dir: &Path) -> Vec<Entry> {
+    let Ok(content) = fs::read_to_string(project_dir.join(HISTORY_FILE)) else { return Vec::new() };
+    content.lines().filter_map(|l| serde_json::from_str(l).ok()).collect()
+}
+
+fn now_secs() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).map(|d| d.as_secs()).unwrap_or(0)
+}
+
+pub(crate) fn ago(started_at: u64) -> String {
+    let secs = now_secs().saturating_sub(started_at);
+    match secs {
+        0..60 => format!("há {}s", secs),
+        60..3600 => format!("há {}min", secs / 60),
+        3600..86400 => format!("há {}h", secs / 3600),
+        _ => format!("há {}d", secs / 86400),
+    }
+}
+
+fn format_duration(ms: u64) -> String {
+    if ms >= 60_000 {
+        format!("{}min{:02}s", ms / 60_000, (ms / 1000) % 60)
+    } else {
+        format!("{:.1}s", ms as f64 / 1000.0)
+    }
+}
+
+/// Quote arguments that would not survive copy and paste into a shell.
+fn shell_line(args: &[String]) -> String {
+    let quoted: Vec<String> = args
+        .iter()
+        .map(|a| {
+            if !a.is_empty() && a.chars().all(|c| c.is_alphanumeric() || "-_./=:@,+".contains(c)) {
+                a.clone()
+            } else {
+                format!("'{}'", a.replace('\'', "'\\''"))
+            }
+        })
+        .collect();
+    format!("dx {}", quoted.join(" "))
+}
+
+/// `dx history`: list the latest `limit` commands run in the current directory.
+pub fn cmd_history(limit: usize, clear: bool) {
+    let project_dir = std::env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    if clear {
+        match fs::remove_file(project_dir.join(HISTORY_FILE)) {
+            Ok(()) => println!("Histórico removido."),
+            Err(e) if e.kind() == std::io::ErrorKind::NotFound => println!("Nenhum histórico para remover."),
+            Err(e) => eprintln!("Erro ao remover {}: {}", HISTORY_FILE, e),
+        }
+        return;
+    }
+    let entries = load(&project_dir);
+    if entries.is_empty() {
+        println!("Nenhum comando no histórico deste diretório ({}).", HISTORY_FILE);
+        return;
+    }
+    let start = entries.len().saturating_sub(limit);
+    for (i, e) in entries.iter().enumerate().skip(start) {
+        let status = if e.exit_code == 0 { "ok".to_string() } else { format!("código {}", e.exit_code) };
+        println!(
+            "{:>4}  {:<10} {:>9}  {:<10} {}",
+            i + 1,
+            ago(e.started_at),
+            format_duration(e.duration_ms),
+            status,
+            shell_line(&e.args)
+        );
+    }
+    println!("\nRepita com: dx rerun <número> (sem número: o último comando)");
+}
+
+/// `dx rerun [<número>|<trecho>]`: run a previous command again with the same arguments.
+/// Returns the exit code of the repeated command.
+pub fn cmd_rerun(selector: Option<String>, print_only: bool) -> i32 {
+    let project_dir = std::env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    let entries = load(&project_dir);
+    let found = match selector.as_deref() {
+        None => entries.last(),
+        Some(s) => match s.parse::<usize>() {
+            Ok(n) => n.checked_sub(1).and_then(|i| entries.get(i)),
+            Err(_) => entries.iter().rev().find(|e| e.args.join(" ").contains(s)),
+        },
+    };
+    let Some(entry) = found else {
+        match selector {
+            Some(s) => eprintln!("Nenhum comando do histórico corresponde a '{}'. Veja 'dx history'.", s),
+            None => eprintln!("Nenhum comando no histórico deste diretório ({}).", HISTORY_FILE),
+        }
+        return 2;
+    };
+
+    let line = shell_line(&entry.args);
+    if print_only {
+        println!("{}", line);
+        return 0;
+    }
+    // Replaying a rerun (or another skipped command) would replay an entry again, endlessly
+    if skipped(&crate::subcommand_path(&entry.args)) {
+        eprintln!("'{}' não pode ser repetido pelo dx rerun.", line);
+        return 2;
+    }
+    eprintln!("▶ {}", line);
+    let exe = std::env::current_exe().unwrap_or_else(|_| "dx".into());
+    let status = Command::new(exe)
+        .args(&entry.args)
+        .current_dir(&project_dir)
+        .env(crate::notifications::NOTIFY_AFTER_ENV, "0")
+        .status();
+    match status {
+        Ok(status) => status.code().unwrap_or(1),
+        Err(e) => {
+            eprintln!("Erro ao executar '{}': {}", line, e);
+            1
+        }
+    }
+}
@@ -0,0 +1,324 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::ErrorKind;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+/// Second line of the installed hooks (after the shebang); hooks without it were written by hand.
+const HEADER: &str = "# Gerado por: dx dev-config hooks";
+const ESLINT_CONFIGS: &[&str] = &["eslint.config.js", "eslint.config.mjs", "eslint.config.cjs", ".eslintrc", ".eslintrc.js", ".eslintrc.cjs", ".eslintrc.json", ".eslintrc.yml"];
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum Hook {
+    /// Formatação, lint e segredos dos arquivos no stage
+    PreCommit,
+    /// As mesmas verificações em todos os arquivos, e os testes
+    PrePush,
+}
+
+impl Hook {
+    const ALL: [Hook; 2] = [Hook::PreCommit, Hook::PrePush];
+
+    fn name(self) -> &'static str {
+        match self {
+            Hook::PreCommit => "pre-commit",
+            Hook::PrePush => "pre-push",
+        }
+    }
+}
+
+/// A formatting or lint check of the stack and the files it looks at.
+struct Check {
+    name: &'static str,
+    extensions: &'static [&'static str],
+    program: &'static str,
+    args: &'static [&'static str],
+    /// Receives the files as arguments (else it checks the whole project)
+    per_file: bool,
+    /// Reports problems by listing them on a successful exit, like `gofmt -l`
+    fails_on_output: bool,
+    /// Command that fixes what the check reports
+    fix: Option<&'static str>,
+}
+
+/// Checks of the stack: gofmt and go vet, eslint (when the project uses it), black and rustfmt.
+fn stack_checks(project_dir: &Path, stack: Stack) -> Vec<Check> {
+    match stack {
+        Stack::Go => vec![
+            Check { name: "gofmt", extensions: &["go"], program: "gofmt", args: &["-l"], per_file: true, fails_on_output: true, fix: Some("gofmt -w .") },
+            Check { name: "go vet", extensions: &["go"], program: "go", args: &["vet", "./..."], per_file: false, fails_on_output: false, fix: None },
+        ],
+        Stack::Node => {
+            let package = fs::read_to_string(project_dir.join("package.json")).unwrap_or_default();
+            if !package.contains("\"eslint\"") && !ESLINT_CONFIGS.iter().any(|f| project_dir.join(f).exists()) {
+                return Vec::new();
+            }
+            vec![Check {
+                name: "eslint",
+                extensions: &["js", "jsx", "mjs", "cjs", "ts", "tsx"],
+                program: "npx",
+                args: &["--no-install", "eslint"],
+                per_file: true,
+                fails_on_output: false,
+                fix: Some("npx eslint --fix ."),
+            }]
+        }
+        Stack::Python => vec![Check { name: "black", extensions: &["py"], program: "black", args: &["--check"], per_file: true, fails_on_output: false, fix: Some("black .") }],
+        Stack::Rust => vec![Check { name: "rustfmt", extensions: &["rs"], program: "cargo", args: &["fmt", "--check"], per_file: false, fails_on_output: false, fix: Some("cargo fmt") }],
+        Stack::DotNet => vec![Check {
+            name: "dotnet format",
+            extensions: &["cs", "fs", "vb"],
+            program: "dotnet",
+            args: &["format", "--verify-no-changes"],
+            per_file: false,
+            fails_on_output: false,
+            fix: Some("dotnet format"),
+        }],
+        _ => Vec::new(),
+    }
+}
+
+fn git(project_dir: &Path, args: &[&str]) -> Result<String, String> {
+    let out = Command::new("git")
+        .args(args)
+        .current_dir(project_dir)
+        .output()
+        .map_err(|e| format!("git indisponível: {}", e))?;
+    if !out.status.success() {
+        return Err(String::from_utf8_lossy(&out.stderr).trim().to_string());
+    }
+    Ok(String::from_utf8_lossy(&out.stdout).trim_end().to_string())
+}
+
+/// Files to check, relative to the project: the staged ones (added, copied, modified or renamed),
+/// or every tracked file.
+pub(crate) fn files(project_dir: &Path, all_files: bool) -> Result<Vec<String>, String> {
+    let listing = match all_files {
+        true => git(project_dir, &["ls-files"])?,
+        false => git(project_dir, &["diff", "--cached", "--name-only", "--diff-filter=ACMR", "--relative"])?,
+    };
+    Ok(listing.lines().filter(|l| !l.is_empty()).map(str::to_string).collect())
+}
+
+/// Whether this runs in CI, where a missing tool fails the check instead of skipping it.
+fn in_ci() -> bool {
+    std::env::var("CI").is_ok_and(|v| !v.is_empty() && v != "false" && v != "0")
+}
+
+enum Outcome {
+    Passed,
+    Failed(String),
+    Missing,
+}
+
+fn report(name: &str, outcome: &Outcome, fix: Option<&str>) {
+    match outcome {
+        Outcome::Passed => println!("  ✓ {}", name),
+        Outcome::Missing => println!("  ⚠ {}: não encontrado no PATH", name),
+        Outcome::Failed(output) => {
+            println!("  ✗ {}", name);
+            for line in output.lines().filter(|l| !l.trim().is_empty()) {
+                println!("      {}", line);
+            }
+            if let Some(fix) = fix {
+                println!("      Corrija com: {}", fix);
+            }
+        }
+    }
+}
+
+fn run_check(project_dir: &Path, check: &Check, files: &[&String]) -> Outcome {
+    let mut command = Command::new(check.program);
+    command.args(check.args).current_dir(project_dir);
+    if check.per_file {
+        command.args(files);
+    }
+    match command.output() {
+        Err(e) if e.kind() == ErrorKind::NotFound => Outcome::Missing,
+        Err(e) => Outcome::Failed(e.to_string()),
+        Ok(out) => {
+            let text = format!("{}{}", String::from_utf8_lossy(&out.stdout), String::from_utf8_lossy(&out.stderr));
+            if out.status.success() && !(check.fails_on_output && !text.trim().is_empty()) {
+                Outcome::Passed
+            } else {
+                Outcome::Failed(text)
+            }
+        }
+    }
+}
+
+/// Hard-coded credentials in `files` (their staged content with `staged`), outside the baseline
+/// (see `dx dev-secrets scan`); None when none of the files is one secrets are looked for in.
+fn secrets_check(project_dir: &Path, files: &[String], staged: bool) -> Option<Outcome> {
+    let scan = match crate::dev_secrets::scan_files(project_dir, files, staged) {
+        Ok(scan) if scan.files == 0 => return None,
+        Ok(scan) => scan,
+        Err(e) => return Some(Outcome::Failed(e)),
+    };
+    if scan.findings.is_empty() {
+        return Some(Outcome::Passed);
+    }
+    Some(Outcome::Failed(format!(
+        "{}Leia-os de variáveis de ambiente; falsos positivos: dx dev-secrets baseline",
+        crate::dev_secrets::render_findings(&scan.findings)
+    )))
+}
+
+/// A test command of the stack, run through the shell like a `dx run` task (127: not found).
+fn run_tests(project_dir: &Path, cmd: &str) -> Outcome {
+    let status = crate::tasks::shell_command(project_dir, cmd, &BTreeMap::new()).and_then(|mut c| c.status());
+    match status {
+        Ok(s) if s.success() => Outcome::Passed,
+        Ok(s) if s.code() == Some(127) => Outcome::Missing,
+        Ok(s) => Outcome::Failed(format!("saiu com {}", s)),
+        Err(e) => Outcome::Failed(e.to_string()),
+    }
+}
+
+/// `dx hooks run <hook>`: the checks of a git hook, also runnable by hand or in CI. pre-commit
+/// looks at the staged files (every tracked file with `all_files`), for formatting, lint and
+/// secrets; pre-push looks at every file and runs the tests. A tool that is not installed is skipped, except in CI (`CI` set).
+pub fn cmd_run(dir: Option<PathBuf>, hook: Hook, all_files: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    let all_files = all_files || hook == Hook::PrePush;
+    let files = match files(&project_dir, all_files) {
+        Ok(files) => files,
+        Err(e) => {
+            eprintln!("Não foi possível listar os arquivos do git em {}: {}", project_dir.display(), e);
+            return 1;
+        }
+    };
+
+    println!("dx hooks run {} ({}; {} arquivo(s))", hook.name(), stack, files.len());
+    let ci = in_ci();
+    let (mut ran, mut failed, mut missing) = (0, Vec::new(), Vec::new());
+    let mut tally = |name: &str, outcome: Outcome, fix: Option<&str>| {
+        report(name, &outcome, fix);
+        ran += 1;
+        match outcome {
+            Outcome::Passed => {}
+            Outcome::Failed(_) => failed.push(name.to_string()),
+            Outcome::Missing => missing.push(name.to_string()),
+        }
+    };
+    for check in stack_checks(&project_dir, stack) {
+        let matching: Vec<&String> = files
+            .iter()
+            .filter(|f| Path::new(f).extension().and_then(|e| e.to_str()).is_some_and(|e| check.extensions.contains(&e)))
+            .collect();
+        if !matching.is_empty() {
+            tally(check.name, run_check(&project_dir, &check, &matching), check.fix);
+        }
+    }
+    if let Some(outcome) = secrets_check(&project_dir, &files, !all_files) {
+        tally("segredos", outcome, None);
+    }
+    if hook == Hook::PrePush {
+        for cmd in crate::task_runner::commands(&project_dir, stack, "test") {
+            tally(&cmd, run_tests(&project_dir, &cmd), None);
+        }
+    }
+
+    if ran == 0 {
+        println!("Nada a verificar.");
+        return 0;
+    }
+    if !missing.is_empty() {
+        let what = if ci { "falharam no CI" } else { "foram puladas" };
+        println!("Ferramentas ausentes, verificações que {}: {}", what, missing.join(", "));
+        if ci {
+            failed.append(&mut missing);
+        }
+    }
+    if failed.is_empty() {
+        println!("✓ {}: {} verificação(ões) ok.", hook.name(), ran - missing.len());
+        0
+    } else {
+        println!("✗ {}: {} de {} verificação(ões) falharam: {}", hook.name(), failed.len(), ran, failed.join(", "));
+        if hook == Hook::PreCommit {
+            println!("Para pular só desta vez: git commit --no-verify");
+        }
+        1
+    }
+}
+
+/// Hook script: a shim calling `dx hooks run` from the repository root into the project (`prefix`),
+/// so the checks are maintained by dx and the same ones run by hand and in CI.
+fn render_hook(hook: Hook, prefix: &str) -> String {
+    let dir = match prefix.trim_end_matches('/') {
+        "" => String::new(),
+        dir => format!(" {}", dir),
+    };
+    format!(
+        "#!/bin/sh\n\
+         {HEADER}\n\
+         # As verificações ficam no dx; rode manualmente com: dx hooks run {name}{dir}\n\
+         if ! command -v dx >/dev/null 2>&1; then\n\
+         \x20 echo \"dx não encontrado no PATH; hook {name} ignorado.\" >&2\n\
+         \x20 exit 0\n\
+         fi\n\
+         exec dx hooks run {name}{dir}\n",
+        name = hook.name()
+    )
+}
+
+#[cfg(unix)]
+fn make_executable(path: &Path) -> std::io::Result<()> {
+    use std::os::unix::fs::PermissionsExt;
+    fs::set_permissions(path, fs::Permissions::from_mode(0o755))
+}
+
+#[cfg(not(unix))]
+fn make_executable(_path: &Path) -> std::io::Result<()> {
+    Ok(())
+}
+
+/// `dx dev-config hooks`: install pre-commit and pre-push hooks in the repository's hooks
+/// directory (honoring `core.hooksPath`) that call `dx hooks run`. Hand-written hooks go
+/// through the conflict resolution (`force` replaces them).
+pub fn cmd_install(dir: Option<PathBuf>, save_file: bool, force: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    let located = git(&project_dir, &["rev-parse", "--path-format=absolute", "--git-path", "hooks"]).and_then(|hooks| Ok((hooks, git(&project_dir, &["rev-parse", "--show-prefix"])?)));
+    let (hooks_dir, prefix) = match located {
+        Ok((hooks, prefix)) => (project_dir.join(hooks), prefix),
+        Err(e) => {
+            eprintln!("{} não está em um repositório git: {}", project_dir.display(), e);
+            return 1;
+        }
+    };
+    let mut scripts: Vec<(PathBuf, String)> = Hook::ALL.iter().map(|h| (hooks_dir.join(h.name()), render_hook(*h, &prefix))).collect();
+
+    if !save_file {
+        for (path, content) in &scripts {
+            println!("# {}\n{}", path.display(), content);
+        }
+        return 0;
+    }
+    if !crate::conflicts::settle(&mut scripts, crate::conflicts::generated_by(HEADER), force) {
+        return 1;
+    }
+    if let Err(e) = fs::create_dir_all(&hooks_dir) {
+        eprintln!("Erro ao criar {}: {}", hooks_dir.display(), e);
+        return 1;
+    }
+    for (path, content) in &scripts {
+        if let Err(e) = crate::audit::write(path, content).and_then(|_| make_executable(path)) {
+            eprintln!("Erro ao salvar {}: {}", path.display(), e);
+            return 1;
+        }
+        println!("  {}", path.display());
+    }
+
+    let mut checks: Vec<&str> = stack_checks(&project_dir, stack).iter().map(|c| c.name).collect();
+    checks.push("segredos");
+    let checks = checks.join(", ");
+    println!("✓ Hooks do git instalados ({}): pre-commit roda {}; pre-push roda também os testes.", stack, checks);
+    println!("Rode manualmente ou no CI com: dx hooks run pre-commit --all-files");
+    0
+}
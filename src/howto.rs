@@ -0,0 +1,208 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use crate::makefile::Makefile;
+use crate::tasks::DxFile;
+use serde::Serialize;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum HowtoFormat {
+    /// Passos numerados com os comandos
+    Text,
+    /// Script sh que executa os passos em ordem
+    Sh,
+    /// Objeto JSON
+    Json,
+}
+
+/// One onboarding step: what it is for and the shell commands, in order.
+#[derive(Debug, Clone, Serialize)]
+pub struct Step {
+    pub title: String,
+    pub commands: Vec<String>,
+    /// Where the commands come from or what they do ("tarefa build do dx.yaml", ...)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub note: Option<String>,
+    /// False for steps that only make sense by hand (stopping the services after the application)
+    pub script: bool,
+}
+
+#[derive(Debug, Clone, Serialize)]
+pub struct Howto {
+    pub project: String,
+    pub stack: String,
+    pub steps: Vec<Step>,
+    /// Where the application answers once started, with its health route when one is detected
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub url: Option<String>,
+}
+
+fn step(title: &str, commands: Vec<String>, note: Option<String>) -> Step {
+    Step { title: title.to_string(), commands, note, script: true }
+}
+
+/// Commands for one of `names`: a task of the dx.yaml, a target of the Makefile or, for the first
+/// name, the stack's own commands (as `dx dev-config tasks` writes them), with where they come from.
+fn commands(project_dir: &Path, stack: Stack, dx_file: &DxFile, makefile: Option<&Makefile>, names: &[&str]) -> Option<(Vec<String>, String)> {
+    if let Some(name) = names.iter().find(|n| dx_file.tasks.contains_key(**n)) {
+        return Some((vec![format!("dx run {}", name)], format!("tarefa {} do {}", name, crate::tasks::DX_FILE)));
+    }
+    if let Some(name) = names.iter().find(|n| makefile.is_some_and(|m| m.target(n).is_some())) {
+        return Some((vec![format!("make {}", name)], format!("alvo {} do Makefile", name)));
+    }
+    let native = crate::task_runner::commands(project_dir, stack, names[0]);
+    (!native.is_empty()).then(|| (native, format!("comandos da stack {}", stack)))
+}
+
+/// The steps to get the project running from a fresh clone: check the machine, prepare the .env,
+/// build, start the Dev Services, test, run, and stop the services again.
+pub fn synthesize(project_dir: &Path, stack: Stack) -> Howto {
+    let dx_file = crate::tasks::load(project_dir).ok().flatten().unwrap_or_default();
+    let makefile = crate::makefile::find(project_dir).and_then(|p| fs::read_to_string(p).ok()).map(|c| crate::makefile::parse(&c));
+    let vars = crate::dev_env::scan(project_dir);
+    let detected = crate::dev_services::detect_dependencies(project_dir);
+    let compose_path = crate::dev_services_compose_path(project_dir);
+    let has_services = compose_path.exists() || !detected.services.is_empty();
+
+    let mut steps = vec![step(
+        "Verificar a máquina (runtimes, Docker, portas livres)",
+        vec!["dx dev-doctor".to_string()],
+        Some(format!("o projeto usa {}", crate::dev_readme::toolchain_line(project_dir, stack))),
+    )];
+
+    if !vars.is_empty() {
+        let required = vars.iter().filter(|v| v.required).count();
+        // An existing .env is only checked, never overwritten
+        let mut cmds = Vec::new();
+        if !project_dir.join(".env").exists() {
+            cmds.push(match project_dir.join(".env.example").exists() {
+                true => "cp .env.example .env".to_string(),
+                false => "dx dev-env init --env".to_string(),
+            });
+        }
+        cmds.push("dx dev-env check".to_string());
+        steps.push(step(
+            "Preparar o .env",
+            cmds,
+            Some(format!("o código lê {} variável(is), {} obrigatória(s)", vars.len(), required)),
+        ));
+    }
+
+    if let Some((cmds, origin)) = commands(project_dir, stack, &dx_file, makefile.as_ref(), &["build"]) {
+        steps.push(step("Instalar as dependências e compilar", cmds, Some(origin)));
+    }
+
+    if has_services {
+        let mut cmds = Vec::new();
+        let note = if compose_path.exists() {
+            let names: Vec<String> = detected.services.into_keys().collect();
+            let relative = compose_path.strip_prefix(project_dir).unwrap_or(&compose_path);
+            match names.is_empty() {
+                true => format!("serviços de {}", relative.display()),
+                false => format!("serviços de {} ({})", relative.display(), sorted(names).join(", ")),
+            }
+        } else {
+            cmds.push("dx dev-services".to_string());
+            format!("o dx gera .dx/docker-compose.yml com: {}", sorted(detected.services.into_keys().collect()).join(", "))
+        };
+        cmds.push("dx dev-services run".to_string());
+        cmds.push("eval \"$(dx dev-env export)\"".to_string());
+        steps.push(step("Subir os serviços e exportar as variáveis de conexão", cmds, Some(note)));
+    }
+
+    if let Some((cmds, origin)) = commands(project_dir, stack, &dx_file, makefile.as_ref(), &["test"]) {
+        steps.push(step("Rodar os testes", cmds, Some(origin)));
+    }
+
+    // Same order `dx up` uses to find the start command
+    let start = if let Some(task) = ["dev", "start"].into_iter().find(|n| dx_file.tasks.contains_key(*n)) {
+        Some((vec![format!("dx run {}", task)], format!("tarefa {} do {}", task, crate::tasks::DX_FILE)))
+    } else if let Some(target) = ["dev", "start", "run"].into_iter().find(|n| makefile.as_ref().is_some_and(|m| m.target(n).is_some())) {
+        Some((vec![format!("make {}", target)], format!("alvo {} do Makefile", target)))
+    } else {
+        commands(project_dir, stack, &DxFile::default(), None, &["run"])
+    };
+    if has_services {
+        let note = match &start {
+            Some((cmds, origin)) => format!("sobe o que faltar, espera os serviços e inicia: {} ({})", cmds.join(" && "), origin),
+            None => "sobe o que faltar, espera os serviços e inicia a aplicação".to_string(),
+        };
+        steps.push(step("Rodar a aplicação", vec!["dx up".to_string()], Some(note)));
+        let mut stop = step("Parar os serviços", vec!["dx down".to_string()], Some("os dados continuam nos volumes".to_string()));
+        stop.script = false;
+        steps.push(stop);
+    } else if let Some((cmds, origin)) = start {
+        steps.push(step("Rodar a aplicação", cmds, Some(origin)));
+    }
+
+    let url = crate::devcontainer::app_port(&vars).or(crate::dockerfile::default_port(stack)).map(|port| {
+        let routes = crate::api_client::detect_routes(project_dir);
+        match crate::dockerfile::health_route(&routes) {
+            Some(route) if route != "/" => format!("http://localhost:{}{}", port, route),
+            _ => format!("http://localhost:{}", port),
+        }
+    });
+    Howto { project: crate::k8s::resource_name(project_dir), stack: stack.to_string(), steps, url }
+}
+
+fn sorted(mut names: Vec<String>) -> Vec<String> {
+    names.sort();
+    names
+}
+
+fn render_text(howto: &Howto) -> String {
+    let mut out = format!("Como rodar {} ({}):\n", howto.project, howto.stack);
+    for (i, s) in howto.steps.iter().enumerate() {
+        out.push_str(&format!("\n{}. {}\n", i + 1, s.title));
+        for command in &s.commands {
+            out.push_str(&format!("   $ {}\n", command));
+        }
+        if let Some(note) = &s.note {
+            out.push_str(&format!("   ({})\n", note));
+        }
+    }
+    if let Some(url) = &howto.url {
+        out.push_str(&format!("\nA aplicação responde em {}.\n", url));
+    }
+    out
+}
+
+fn render_sh(howto: &Howto) -> String {
+    let mut out = format!("#!/bin/sh\n# Gerado por: dx howto ({}, {})\nset -e\n", howto.project, howto.stack);
+    for (i, s) in howto.steps.iter().enumerate() {
+        out.push_str(&format!("\n# {}. {}\n", i + 1, s.title));
+        if let Some(note) = &s.note {
+            out.push_str(&format!("# {}\n", note));
+        }
+        for command in &s.commands {
+            let prefix = if s.script { "" } else { "# " };
+            out.push_str(&format!("{}{}\n", prefix, command));
+        }
+    }
+    if let Some(url) = &howto.url {
+        out.push_str(&format!("\n# A aplicação responde em {}\n", url));
+    }
+    out
+}
+
+/// `dx howto`: print the commands that build, run and test the project with the Dev Services, in
+/// order, from what dx detects (stack, dx.yaml tasks, Makefile targets, environment variables and
+/// services). Returns the exit code.
+pub fn cmd_howto(dir: Option<PathBuf>, format: HowtoFormat) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    if stack == Stack::Unknown {
+        eprintln!("Nenhuma stack detectada em {}; não há como montar os passos.", project_dir.display());
+        return 1;
+    }
+    let howto = synthesize(&project_dir, stack);
+    match format {
+        HowtoFormat::Text => print!("{}", render_text(&howto)),
+        HowtoFormat::Sh => print!("{}", render_sh(&howto)),
+        HowtoFormat::Json => crate::output::print(&howto),
+    }
+    0
+}
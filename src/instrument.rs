@@ -0,0 +1,830 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use crate::dev_env::EnvVar;
+use serde::Serialize;
+use std::fs;
+use std::io::{self, BufRead, IsTerminal, Write};
+use std::path::{Path, PathBuf};
+
+/// Route the suggested health endpoints answer on.
+const HEALTH_PATH: &str = "/healthz";
+/// Go modules that already load the configuration from the environment.
+const GO_CONFIG_MODULES: &[&str] = &["caarlos0/env", "kelseyhightower/envconfig", "sethvargo/go-envconfig", "spf13/viper", "knadh/koanf"];
+const NODE_CONFIG_PACKAGES: &[&str] = &["convict", "envalid", "env-var", "@nestjs/config", "config"];
+const PYTHON_CONFIG_PACKAGES: &[&str] = &["pydantic-settings", "python-decouple", "environs", "dynaconf"];
+const NODE_OTEL_PACKAGES: &[&str] = &["@opentelemetry/sdk-node", "@opentelemetry/auto-instrumentations-node", "@opentelemetry/exporter-trace-otlp-http"];
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, clap::ValueEnum)]
+#[serde(rename_all = "lowercase")]
+pub enum Kind {
+    /// Rota de saúde (/healthz)
+    Health,
+    /// OpenTelemetry (traces via OTLP)
+    Otel,
+    /// Carregamento das variáveis de ambiente num só lugar
+    Config,
+}
+
+impl Kind {
+    const ALL: [Kind; 3] = [Kind::Health, Kind::Otel, Kind::Config];
+
+    fn label(self) -> &'static str {
+        match self {
+            Kind::Health => "health",
+            Kind::Otel => "otel",
+            Kind::Config => "config",
+        }
+    }
+}
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum InstrumentFormat {
+    /// Patch unificado (aplicável com git apply), com os passos seguintes em comentários
+    Diff,
+    /// Objeto JSON
+    Json,
+}
+
+/// A file the suggestion creates (`old` is None) or changes.
+#[derive(Debug, Clone)]
+struct Change {
+    path: String,
+    old: Option<String>,
+    new: String,
+}
+
+impl Change {
+    fn diff(&self) -> String {
+        let diff = crate::audit::unified_diff(&self.path, self.old.as_deref().unwrap_or(""), &self.new);
+        match self.old {
+            // New files come from /dev/null, as git writes them
+            None => diff.replacen(&format!("--- a/{}", self.path), "--- /dev/null", 1),
+            Some(_) => diff,
+        }
+    }
+}
+
+#[derive(Debug, Clone)]
+struct Suggestion {
+    kind: Kind,
+    title: String,
+    changes: Vec<Change>,
+    /// Commands to run after applying (installing the new dependencies, ...)
+    next: Vec<String>,
+}
+
+/// A suggestion for `kind`, or why there is none.
+type Outcome = Result<Suggestion, String>;
+
+#[derive(Serialize)]
+struct FileJson {
+    path: String,
+    new_file: bool,
+    diff: String,
+}
+
+#[derive(Serialize)]
+struct SuggestionJson {
+    kind: Kind,
+    title: String,
+    files: Vec<FileJson>,
+    next: Vec<String>,
+}
+
+#[derive(Serialize)]
+struct SkippedJson {
+    kind: Kind,
+    reason: String,
+}
+
+#[derive(Serialize)]
+struct Report {
+    stack: String,
+    suggestions: Vec<SuggestionJson>,
+    skipped: Vec<SkippedJson>,
+}
+
+fn read(path: &Path) -> String {
+    fs::read_to_string(path).unwrap_or_default()
+}
+
+/// `/`-separated path of `path` relative to `root`.
+fn relative(root: &Path, path: &Path) -> String {
+    let rel = path.strip_prefix(root).unwrap_or(path);
+    rel.components().map(|c| c.as_os_str().to_string_lossy()).collect::<Vec<_>>().join("/")
+}
+
+/// `content` with `line` inserted after line number `index` (0-based).
+fn insert_after(content: &str, index: usize, line: &str) -> String {
+    let mut lines: Vec<&str> = content.lines().collect();
+    lines.insert(index + 1, line);
+    let mut out = lines.join("\n");
+    out.push('\n');
+    out
+}
+
+/// Default written in the code as a string literal of the generated file (JSON quoting is valid in
+/// Go, JavaScript and Python for these values).
+fn literal(value: &str) -> String {
+    let value = value.trim();
+    let unquoted = ['"', '\'', '`'].iter().find_map(|q| value.strip_prefix(*q).and_then(|v| v.strip_suffix(*q))).unwrap_or(value);
+    serde_json::to_string(unquoted).unwrap_or_else(|_| "\"\"".to_string())
+}
+
+/// Whether the project already serves a health route.
+fn has_health_route(project_dir: &Path) -> Option<String> {
+    let routes = crate::api_client::detect_routes(project_dir);
+    routes
+        .iter()
+        .find(|(method, path, _)| method == "GET" && ["/healthz", "/health", "/livez", "/readyz", "/ping"].iter().any(|h| path.ends_with(h)))
+        .map(|(method, path, location)| format!("{} {} em {}", method, path, location))
+}
+
+// --- Go ---
+
+#[derive(Clone, Copy, PartialEq, Eq)]
+enum GoRouter {
+    Gin,
+    Echo,
+    Fiber,
+    Chi,
+    ServeMux,
+}
+
+struct GoPackage {
+    dir: PathBuf,
+    /// Non-test files of the main package with their content
+    files: Vec<(PathBuf, String)>,
+}
+
+impl GoPackage {
+    fn load(project_dir: &Path) -> GoPackage {
+        let dir = project_dir.join(crate::dockerfile::go_main_package(project_dir).trim_start_matches("./"));
+        let mut files: Vec<(PathBuf, String)> = fs::read_dir(&dir)
+            .map(|entries| {
+                entries
+                    .flatten()
+                    .map(|e| e.path())
+                    .filter(|p| p.extension().is_some_and(|e| e == "go") && !p.to_string_lossy().ends_with("_test.go"))
+                    .map(|p| {
+                        let content = read(&p);
+                        (p, content)
+                    })
+                    .collect()
+            })
+            .unwrap_or_default();
+        files.sort();
+        GoPackage { dir, files }
+    }
+
+    fn declares(&self, needle: &str) -> bool {
+        self.files.iter().any(|(_, c)| c.contains(needle))
+    }
+
+    fn main_file(&self) -> Option<&(PathBuf, String)> {
+        self.files.iter().find(|(_, c)| c.contains("func main() {"))
+    }
+
+    /// File, line index, indentation and variable of the first router created in the package.
+    fn router(&self) -> Option<(&Path, &str, usize, String, String, GoRouter)> {
+        const CONSTRUCTORS: &[(&str, GoRouter)] = &[
+            ("gin.Default(", GoRouter::Gin),
+            ("gin.New(", GoRouter::Gin),
+            ("echo.New(", GoRouter::Echo),
+            ("fiber.New(", GoRouter::Fiber),
+            ("chi.NewRouter(", GoRouter::Chi),
+            ("chi.NewMux(", GoRouter::Chi),
+            ("http.NewServeMux(", GoRouter::ServeMux),
+        ];
+        for (path, content) in &self.files {
+            for (i, line) in content.lines().enumerate() {
+                let Some((var, rhs)) = line.split_once(":=") else { continue };
+                let var = var.trim();
+                let rhs = rhs.trim();
+                if !var.chars().all(|c| c.is_alphanumeric() || c == '_') {
+                    continue;
+                }
+                if let Some((_, router)) = CONSTRUCTORS.iter().find(|(c, _)| rhs.starts_with(c)) {
+                    let indent: String = line.chars().take_while(|c| c.is_whitespace()).collect();
+                    return Some((path, content, i, indent, var.to_string(), *router));
+                }
+            }
+        }
+        None
+    }
+}
+
+/// Import path of a required module starting with `prefix` (`github.com/labstack/echo/v4`).
+fn go_module(go_mod: &str, prefix: &str) -> Option<String> {
+    go_mod.split_whitespace().find(|t| t.starts_with(prefix)).map(str::to_string)
+}
+
+fn go_health(project_dir: &Path, pkg: &GoPackage, go_mod: &str) -> Outcome {
+    if let Some(route) = has_health_route(project_dir) {
+        return Err(format!("o projeto já tem uma rota de saúde ({})", route));
+    }
+    if pkg.declares("func healthz(") {
+        return Err("o pacote main já declara uma função healthz".to_string());
+    }
+    let handler_path = relative(project_dir, &pkg.dir.join("health.go"));
+    let comment = "// healthz reports that the process is up (liveness probe).";
+    let std_handler = format!(
+        "package main\n\nimport (\n\t\"encoding/json\"\n\t\"net/http\"\n)\n\n{}\nfunc healthz(w http.ResponseWriter, r *http.Request) {{\n\tw.Header().Set(\"Content-Type\", \"application/json\")\n\t_ = json.NewEncoder(w).Encode(map[string]string{{\"status\": \"ok\"}})\n}}\n",
+        comment
+    );
+    let Some((path, content, index, indent, var, router)) = pkg.router() else {
+        // No router of its own: the application serves http.DefaultServeMux
+        let handler = format!("{}\nfunc init() {{\n\thttp.HandleFunc(\"{}\", healthz)\n}}\n", std_handler, HEALTH_PATH);
+        return Ok(Suggestion {
+            kind: Kind::Health,
+            title: format!("rota {} no http.DefaultServeMux", HEALTH_PATH),
+            changes: vec![Change { path: handler_path, old: None, new: handler }],
+            next: Vec::new(),
+        });
+    };
+    let (handler, method, name) = match router {
+        GoRouter::Gin => (
+            format!(
+                "package main\n\nimport (\n\t\"net/http\"\n\n\t\"github.com/gin-gonic/gin\"\n)\n\n{}\nfunc healthz(c *gin.Context) {{\n\tc.JSON(http.StatusOK, gin.H{{\"status\": \"ok\"}})\n}}\n",
+                comment
+            ),
+            "GET",
+            "gin",
+        ),
+        GoRouter::Echo => (
+            format!(
+                "package main\n\nimport (\n\t\"net/http\"\n\n\t\"{}\"\n)\n\n{}\nfunc healthz(c echo.Context) error {{\n\treturn c.JSON(http.StatusOK, map[string]string{{\"status\": \"ok\"}})\n}}\n",
+                go_module(go_mod, "github.com/labstack/echo").unwrap_or_else(|| "github.com/labstack/echo/v4".to_string()),
+                comment
+            ),
+            "GET",
+            "echo",
+        ),
+        GoRouter::Fiber => (
+            format!(
+                "package main\n\nimport \"{}\"\n\n{}\nfunc healthz(c *fiber.Ctx) error {{\n\treturn c.JSON(fiber.Map{{\"status\": \"ok\"}})\n}}\n",
+                go_module(go_mod, "github.com/gofiber/fiber").unwrap_or_else(|| "github.com/gofiber/fiber/v2".to_string()),
+                comment
+            ),
+            "Get",
+            "fiber",
+        ),
+        GoRouter::Chi => (std_handler, "Get", "chi"),
+        GoRouter::ServeMux => (std_handler, "HandleFunc", "net/http"),
+    };
+    let registration = format!("{}{}.{}(\"{}\", healthz)", indent, var, method, HEALTH_PATH);
+    Ok(Suggestion {
+        kind: Kind::Health,
+        title: format!("rota {} ({})", HEALTH_PATH, name),
+        changes: vec![
+            Change { path: handler_path, old: None, new: handler },
+            Change { path: relative(project_dir, path), old: Some(content.to_string()), new: insert_after(content, index, &registration) },
+        ],
+        next: Vec::new(),
+    })
+}
+
+fn go_otel(project_dir: &Path, pkg: &GoPackage, go_mod: &str) -> Outcome {
+    if go_mod.contains("go.opentelemetry.io/otel") {
+        return Err("go.opentelemetry.io/otel já está no go.mod".to_string());
+    }
+    if pkg.declares("func setupOTel(") {
+        return Err("o pacote main já declara uma função setupOTel".to_string());
+    }
+    let Some((main_path, main)) = pkg.main_file() else {
+        return Err("nenhuma função main encontrada".to_string());
+    };
+    let index = main.lines().position(|l| l.starts_with("func main() {")).unwrap_or_default();
+    let setup = "package main
+
+import (
+\t\"context\"
+\t\"log\"
+\t\"time\"
+
+\t\"go.opentelemetry.io/otel\"
+\t\"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp\"
+\t\"go.opentelemetry.io/otel/propagation\"
+\tsdktrace \"go.opentelemetry.io/otel/sdk/trace\"
+)
+
+// setupOTel exports traces over OTLP/HTTP (OTEL_EXPORTER_OTLP_ENDPOINT, default
+// http://localhost:4318; service name from OTEL_SERVICE_NAME) and returns the function that
+// flushes the pending spans on exit.
+func setupOTel() func() {
+\texporter, err := otlptracehttp.New(context.Background())
+\tif err != nil {
+\t\tlog.Printf(\"OpenTelemetry disabled: %v\", err)
+\t\treturn func() {}
+\t}
+\tprovider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter))
+\totel.SetTracerProvider(provider)
+\totel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+\treturn func() {
+\t\tctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+\t\tdefer cancel()
+\t\tif err := provider.Shutdown(ctx); err != nil {
+\t\t\tlog.Printf(\"OpenTelemetry shutdown: %v\", err)
+\t\t}
+\t}
+}
+";
+    Ok(Suggestion {
+        kind: Kind::Otel,
+        title: "OpenTelemetry (traces via OTLP/HTTP)".to_string(),
+        changes: vec![
+            Change { path: relative(project_dir, &pkg.dir.join("otel.go")), old: None, new: setup.to_string() },
+            Change { path: relative(project_dir, main_path), old: Some(main.clone()), new: insert_after(main, index, "\tdefer setupOTel()()") },
+        ],
+        next: vec![
+            "go get go.opentelemetry.io/otel go.opentelemetry.io/otel/sdk go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp".to_string(),
+            "go mod tidy".to_string(),
+        ],
+    })
+}
+
+/// Go identifier for a variable name: `MONGODB_URI` → `MongodbURI`.
+fn go_field(name: &str) -> String {
+    const INITIALISMS: &[&str] = &["ID", "URL", "URI", "API", "HTTP", "HTTPS", "TLS", "TTL", "DB", "JWT", "SQL", "TCP", "UDP", "IP"];
+    name.split('_')
+        .filter(|p| !p.is_empty())
+        .map(|part| {
+            let upper = part.to_uppercase();
+            if INITIALISMS.contains(&upper.as_str()) {
+                return upper;
+            }
+            let lower = part.to_lowercase();
+            let mut chars = lower.chars();
+            chars.next().map(|c| c.to_uppercase().chain(chars).collect()).unwrap_or_default()
+        })
+        .collect()
+}
+
+fn go_config(project_dir: &Path, pkg: &GoPackage, go_mod: &str, vars: &[EnvVar]) -> Outcome {
+    if let Some(module) = GO_CONFIG_MODULES.iter().find(|m| go_mod.contains(*m)) {
+        return Err(format!("o projeto já carrega a configuração com {}", module));
+    }
+    if pkg.declares("env:\"") || pkg.declares("type Config ") {
+        return Err("o pacote main já tem uma struct de configuração".to_string());
+    }
+    if pkg.declares("func loadConfig(") || pkg.declares("func getenv(") {
+        return Err("o pacote main já declara loadConfig ou getenv".to_string());
+    }
+    if vars.is_empty() {
+        return Err("o código não lê variáveis de ambiente".to_string());
+    }
+    let fields: Vec<(String, &EnvVar)> = vars.iter().map(|v| (go_field(&v.name), v)).collect();
+    let width = fields.iter().map(|(f, _)| f.len()).max().unwrap_or_default();
+    let required: Vec<&(String, &EnvVar)> = fields.iter().filter(|(_, v)| v.required).collect();
+    let with_default = vars.iter().any(|v| v.default.is_some());
+
+    let mut imports = vec!["\"os\""];
+    if !required.is_empty() {
+        imports.insert(0, "\"fmt\"");
+        imports.push("\"strings\"");
+    }
+    let mut out = format!("package main\n\nimport (\n{}\n)\n\n", imports.iter().map(|i| format!("\t{}", i)).collect::<Vec<_>>().join("\n"));
+    out.push_str("// Config holds the environment variables the application reads.\ntype Config struct {\n");
+    for (field, _) in &fields {
+        out.push_str(&format!("\t{:<width$} string\n", field));
+    }
+    out.push_str("}\n\n// loadConfig reads the configuration from the environment, with the defaults of the code.\n");
+    out.push_str("func loadConfig() (Config, error) {\n\tcfg := Config{\n");
+    for (field, var) in &fields {
+        let value = match &var.default {
+            Some(default) => format!("getenv(\"{}\", {})", var.name, literal(default)),
+            None => format!("os.Getenv(\"{}\")", var.name),
+        };
+        out.push_str(&format!("\t\t{:<w$} {},\n", format!("{}:", field), value, w = width + 1));
+    }
+    out.push_str("\t}\n");
+    if !required.is_empty() {
+        out.push_str("\tvar missing []string\n");
+        for (field, var) in &required {
+            out.push_str(&format!("\tif cfg.{} == \"\" {{\n\t\tmissing = append(missing, \"{}\")\n\t}}\n", field, var.name));
+        }
+        out.push_str("\tif len(missing) > 0 {\n\t\treturn cfg, fmt.Errorf(\"missing required environment variables: %s\", strings.Join(missing, \", \"))\n\t}\n");
+    }
+    out.push_str("\treturn cfg, nil\n}\n");
+    if with_default {
+        out.push_str("\n// getenv returns the variable, or fallback when it is unset.\nfunc getenv(key, fallback string) string {\n\tif value, ok := os.LookupEnv(key); ok {\n\t\treturn value\n\t}\n\treturn fallback\n}\n");
+    }
+    Ok(Suggestion {
+        kind: Kind::Config,
+        title: format!("loadConfig com {} variável(is) de ambiente", vars.len()),
+        changes: vec![Change { path: relative(project_dir, &pkg.dir.join("config.go")), old: None, new: out }],
+        next: Vec::new(),
+    })
+}
+
+// --- Node.js ---
+
+struct NodeProject {
+    package: serde_json::Value,
+    /// Entry file and its content
+    main: Option<(PathBuf, String)>,
+    esm: bool,
+    /// `npm install`, `pnpm add` or `yarn add`
+    add: &'static str,
+}
+
+impl NodeProject {
+    fn load(project_dir: &Path) -> NodeProject {
+        let package: serde_json::Value = serde_json::from_str(&read(&project_dir.join("package.json"))).unwrap_or_default();
+        let declared = package["main"].as_str().map(str::to_string);
+        let main = declared
+            .into_iter()
+            .chain(["index.js", "app.js", "server.js", "src/index.js", "src/app.js", "src/server.js"].map(String::from))
+            .map(|f| project_dir.join(f))
+            .find(|p| p.is_file())
+            .map(|p| {
+                let content = read(&p);
+                (p, content)
+            });
+        let esm = package["type"] == "module"
+            || main.as_ref().is_some_and(|(p, c)| p.extension().is_some_and(|e| e == "mjs") || c.lines().any(|l| l.starts_with("import ")));
+        let add = if project_dir.join("pnpm-lock.yaml").exists() {
+            "pnpm add"
+        } else if project_dir.join("yarn.lock").exists() {
+            "yarn add"
+        } else {
+            "npm install"
+        };
+        NodeProject { package, main, esm, add }
+    }
+
+    fn depends_on(&self, name: &str) -> bool {
+        ["dependencies", "devDependencies"].iter().any(|k| self.package[*k][name].is_string())
+    }
+}
+
+fn node_health(project_dir: &Path, node: &NodeProject) -> Outcome {
+    if let Some(route) = has_health_route(project_dir) {
+        return Err(format!("o projeto já tem uma rota de saúde ({})", route));
+    }
+    let Some((path, content)) = &node.main else {
+        return Err("arquivo de entrada não encontrado (main do package.json, index.js, app.js, server.js)".to_string());
+    };
+    let found = content.lines().enumerate().find_map(|(i, line)| {
+        let (left, right) = line.split_once('=')?;
+        if !right.trim_start().starts_with("express()") {
+            return None;
+        }
+        let var = left.trim().rsplit(' ').next()?.to_string();
+        Some((i, var))
+    });
+    let Some((index, var)) = found else {
+        return Err(format!("nenhum app Express criado em {}", relative(project_dir, path)));
+    };
+    let route = format!("{}.get('{}', (req, res) => res.json({{ status: 'ok' }}));", var, HEALTH_PATH);
+    Ok(Suggestion {
+        kind: Kind::Health,
+        title: format!("rota {} (Express)", HEALTH_PATH),
+        changes: vec![Change { path: relative(project_dir, path), old: Some(content.clone()), new: insert_after(content, index, &route) }],
+        next: Vec::new(),
+    })
+}
+
+fn node_otel(project_dir: &Path, node: &NodeProject) -> Outcome {
+    if let Some(package) = NODE_OTEL_PACKAGES.iter().find(|p| node.depends_on(p)) {
+        return Err(format!("{} já está no package.json", package));
+    }
+    let Some((path, content)) = &node.main else {
+        return Err("arquivo de entrada não encontrado (main do package.json, index.js, app.js, server.js)".to_string());
+    };
+    let header = "// OpenTelemetry: traces over OTLP/HTTP (OTEL_EXPORTER_OTLP_ENDPOINT, default http://localhost:4318;\n// service name from OTEL_SERVICE_NAME). Loaded before the application so the instrumentations can patch it.\n";
+    let imports = if node.esm {
+        "import { NodeSDK } from '@opentelemetry/sdk-node';\nimport { getNodeAutoInstrumentations } from '@opentelemetry/auto-instrumentations-node';\nimport { OTLPTraceExporter } from '@opentelemetry/exporter-trace-otlp-http';\n"
+    } else {
+        "const { NodeSDK } = require('@opentelemetry/sdk-node');\nconst { getNodeAutoInstrumentations } = require('@opentelemetry/auto-instrumentations-node');\nconst { OTLPTraceExporter } = require('@opentelemetry/exporter-trace-otlp-http');\n"
+    };
+    let body = "\nconst sdk = new NodeSDK({\n  traceExporter: new OTLPTraceExporter(),\n  instrumentations: [getNodeAutoInstrumentations()],\n});\nsdk.start();\n\nprocess.on('SIGTERM', () => {\n  sdk.shutdown().finally(() => process.exit(0));\n});\n";
+    let file = path.parent().unwrap_or(project_dir).join("instrumentation.js");
+    let mut changes = vec![Change { path: relative(project_dir, &file), old: None, new: format!("{}{}{}", header, imports, body) }];
+    let mut next = vec![format!("{} {}", node.add, NODE_OTEL_PACKAGES.join(" "))];
+    if node.esm {
+        // ES modules are linked before any code runs: the SDK has to be preloaded
+        next.push(format!("node --import ./{} {}", relative(project_dir, &file), relative(project_dir, path)));
+    } else {
+        let index = content.lines().position(|l| l.contains("'use strict'") || l.contains("\"use strict\""));
+        let new = match index {
+            Some(i) => insert_after(content, i, "require('./instrumentation');"),
+            None => format!("require('./instrumentation');\n{}", content),
+        };
+        changes.push(Change { path: relative(project_dir, path), old: Some(content.clone()), new });
+    }
+    Ok(Suggestion { kind: Kind::Otel, title: "OpenTelemetry (NodeSDK com auto-instrumentações)".to_string(), changes, next })
+}
+
+/// camelCase key for a variable name: `MONGODB_URI` → `mongodbUri`.
+fn camel(name: &str) -> String {
+    let mut out = String::new();
+    for (i, part) in name.split('_').filter(|p| !p.is_empty()).enumerate() {
+        let lower = part.to_lowercase();
+        if i == 0 {
+            out.push_str(&lower);
+        } else {
+            let mut chars = lower.chars();
+            if let Some(c) = chars.next() {
+                out.extend(c.to_uppercase());
+                out.push_str(chars.as_str());
+            }
+        }
+    }
+    out
+}
+
+fn node_config(project_dir: &Path, node: &NodeProject, vars: &[EnvVar]) -> Outcome {
+    if let Some(package) = NODE_CONFIG_PACKAGES.iter().find(|p| node.depends_on(p)) {
+        return Err(format!("o projeto já carrega a configuração com {}", package));
+    }
+    let dir = node.main.as_ref().and_then(|(p, _)| p.parent()).unwrap_or(project_dir);
+    let file = dir.join("config.js");
+    if file.exists() {
+        return Err(format!("{} já existe", relative(project_dir, &file)));
+    }
+    if vars.is_empty() {
+        return Err("o código não lê variáveis de ambiente".to_string());
+    }
+    let mut out = "// Environment variables the application reads, with the defaults of the code.\n".to_string();
+    let required: Vec<String> = vars.iter().filter(|v| v.required).map(|v| format!("'{}'", v.name)).collect();
+    if !required.is_empty() {
+        out.push_str(&format!(
+            "const missing = [{}].filter((name) => !process.env[name]);\nif (missing.length > 0) {{\n  throw new Error(`missing required environment variables: ${{missing.join(', ')}}`);\n}}\n\n",
+            required.join(", ")
+        ));
+    }
+    out.push_str(if node.esm { "export default {\n" } else { "module.exports = {\n" });
+    for var in vars {
+        match &var.default {
+            Some(default) => {
+                // Single quotes, as in the rest of the generated JavaScript
+                let value = literal(default);
+                let value = match value.contains('\'') || value.contains('\\') {
+                    true => value,
+                    false => format!("'{}'", &value[1..value.len() - 1]),
+                };
+                out.push_str(&format!("  {}: process.env.{} ?? {},\n", camel(&var.name), var.name, value));
+            }
+            None => out.push_str(&format!("  {}: process.env.{},\n", camel(&var.name), var.name)),
+        }
+    }
+    out.push_str("};\n");
+    Ok(Suggestion {
+        kind: Kind::Config,
+        title: format!("config.js com {} variável(is) de ambiente", vars.len()),
+        changes: vec![Change { path: relative(project_dir, &file), old: None, new: out }],
+        next: Vec::new(),
+    })
+}
+
+// --- Python ---
+
+/// The file that creates the FastAPI or Flask app, its content and the app variable.
+fn python_app(project_dir: &Path) -> Option<(PathBuf, String, String)> {
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(project_dir, &mut files);
+    files.sort();
+    files.into_iter().filter(|p| p.extension().is_some_and(|e| e == "py")).find_map(|p| {
+        let content = read(&p);
+        let var = content.lines().find_map(|line| {
+            let (left, right) = line.split_once('=')?;
+            let right = right.trim_start();
+            (right.starts_with("FastAPI(") || right.starts_with("Flask(")).then(|| left.trim().to_string())
+        })?;
+        var.chars().all(|c| c.is_alphanumeric() || c == '_').then_some((p, content, var))
+    })
+}
+
+fn python_health(project_dir: &Path) -> Outcome {
+    if let Some(route) = has_health_route(project_dir) {
+        return Err(format!("o projeto já tem uma rota de saúde ({})", route));
+    }
+    let Some((path, content, var)) = python_app(project_dir) else {
+        return Err("nenhum app FastAPI ou Flask encontrado".to_string());
+    };
+    let route = format!("@{}.get(\"{}\")\ndef healthz():\n    return {{\"status\": \"ok\"}}\n", var, HEALTH_PATH);
+    // Before `if __name__ == "__main__":`, which starts the server when the file is run directly
+    let new = match content.find("\nif __name__") {
+        Some(at) => format!("{}\n\n\n{}\n{}", content[..at].trim_end(), route, &content[at..]),
+        None => format!("{}\n\n\n{}", content.trim_end(), route),
+    };
+    let framework = if content.contains("FastAPI(") { "FastAPI" } else { "Flask" };
+    Ok(Suggestion {
+        kind: Kind::Health,
+        title: format!("rota {} ({})", HEALTH_PATH, framework),
+        changes: vec![Change { path: relative(project_dir, &path), old: Some(content.clone()), new }],
+        next: Vec::new(),
+    })
+}
+
+fn python_otel(project_dir: &Path, stack: Stack) -> Outcome {
+    let path = project_dir.join("requirements.txt");
+    let Ok(content) = fs::read_to_string(&path) else {
+        return Err("sem requirements.txt para declarar as dependências".to_string());
+    };
+    if content.contains("opentelemetry") {
+        return Err("opentelemetry já está no requirements.txt".to_string());
+    }
+    let mut new = content.trim_end().to_string();
+    new.push_str("\nopentelemetry-distro\nopentelemetry-exporter-otlp\n");
+    let mut next = vec!["python -m pip install -r requirements.txt".to_string(), "opentelemetry-bootstrap -a install".to_string()];
+    if let Some(run) = crate::task_runner::commands(project_dir, stack, "run").first() {
+        next.push(format!("OTEL_SERVICE_NAME={} opentelemetry-instrument {}", crate::k8s::resource_name(project_dir), run));
+    }
+    Ok(Suggestion {
+        kind: Kind::Otel,
+        title: "OpenTelemetry (instrumentação automática com opentelemetry-instrument)".to_string(),
+        changes: vec![Change { path: "requirements.txt".to_string(), old: Some(content), new }],
+        next,
+    })
+}
+
+fn python_config(project_dir: &Path, vars: &[EnvVar]) -> Outcome {
+    let declared = read(&project_dir.join("requirements.txt")) + &read(&project_dir.join("pyproject.toml"));
+    if let Some(package) = PYTHON_CONFIG_PACKAGES.iter().find(|p| declared.contains(*p)) {
+        return Err(format!("o projeto já carrega a configuração com {}", package));
+    }
+    let dir = python_app(project_dir).and_then(|(p, _, _)| p.parent().map(Path::to_path_buf)).unwrap_or_else(|| project_dir.to_path_buf());
+    if let Some(existing) = ["config.py", "settings.py"].iter().map(|f| dir.join(f)).find(|p| p.exists()) {
+        return Err(format!("{} já existe", relative(project_dir, &existing)));
+    }
+    if vars.is_empty() {
+        return Err("o código não lê variáveis de ambiente".to_string());
+    }
+    let mut out = "\"\"\"Environment variables the application reads, with the defaults of the code.\"\"\"\n\nimport os\n\n".to_string();
+    let required: Vec<String> = vars.iter().filter(|v| v.required).map(|v| format!("\"{}\"", v.name)).collect();
+    if !required.is_empty() {
+        out.push_str(&format!(
+            "_missing = [name for name in ({},) if not os.environ.get(name)]\nif _missing:\n    raise RuntimeError(\"missing required environment variables: \" + \", \".join(_missing))\n\n",
+            required.join(", ")
+        ));
+    }
+    for var in vars {
+        match &var.default {
+            Some(default) => out.push_str(&format!("{} = os.environ.get(\"{}\", {})\n", var.name, var.name, literal(default))),
+            None => out.push_str(&format!("{} = os.environ.get(\"{}\", \"\")\n", var.name, var.name)),
+        }
+    }
+    Ok(Suggestion {
+        kind: Kind::Config,
+        title: format!("config.py com {} variável(is) de ambiente", vars.len()),
+        changes: vec![Change { path: relative(project_dir, &dir.join("config.py")), old: None, new: out }],
+        next: Vec::new(),
+    })
+}
+
+/// Suggestions for the detected stack, one outcome per requested kind.
+fn suggest(project_dir: &Path, stack: Stack, kinds: &[Kind]) -> Vec<(Kind, Outcome)> {
+    let vars = crate::dev_env::scan(project_dir);
+    match stack {
+        Stack::Go => {
+            let pkg = GoPackage::load(project_dir);
+            let go_mod = read(&project_dir.join("go.mod"));
+            kinds
+                .iter()
+                .map(|k| {
+                    let outcome = match k {
+                        Kind::Health => go_health(project_dir, &pkg, &go_mod),
+                        Kind::Otel => go_otel(project_dir, &pkg, &go_mod),
+                        Kind::Config => go_config(project_dir, &pkg, &go_mod, &vars),
+                    };
+                    (*k, outcome)
+                })
+                .collect()
+        }
+        Stack::Node => {
+            let node = NodeProject::load(project_dir);
+            kinds
+                .iter()
+                .map(|k| {
+                    let outcome = match k {
+                        Kind::Health => node_health(project_dir, &node),
+                        Kind::Otel => node_otel(project_dir, &node),
+                        Kind::Config => node_config(project_dir, &node, &vars),
+                    };
+                    (*k, outcome)
+                })
+                .collect()
+        }
+        Stack::Python => kinds
+            .iter()
+            .map(|k| {
+                let outcome = match k {
+                    Kind::Health => python_health(project_dir),
+                    Kind::Otel => python_otel(project_dir, stack),
+                    Kind::Config => python_config(project_dir, &vars),
+                };
+                (*k, outcome)
+            })
+            .collect(),
+        _ => Vec::new(),
+    }
+}
+
+fn confirm(files: usize) -> bool {
+    eprint!("Aplicar as alterações em {} arquivo(s)? [s/N] ", files);
+    let _ = io::stderr().flush();
+    let mut answer = String::new();
+    let _ = io::stdin().lock().read_line(&mut answer);
+    matches!(answer.trim().to_lowercase().as_str(), "s" | "sim" | "y" | "yes")
+}
+
+fn apply(project_dir: &Path, suggestions: &[Suggestion]) -> io::Result<()> {
+    for change in suggestions.iter().flat_map(|s| &s.changes) {
+        let path = project_dir.join(&change.path);
+        if let Some(parent) = path.parent() {
+            fs::create_dir_all(parent)?;
+        }
+        crate::audit::write(&path, &change.new)?;
+        eprintln!("  ✓ {} ({})", change.path, if change.old.is_some() { "alterado" } else { "criado" });
+    }
+    Ok(())
+}
+
+/// `dx instrument suggest`: propose the code that adds a health route, OpenTelemetry or a single
+/// configuration loader to the detected application, as a unified diff. With `apply`, writes the
+/// changes after a confirmation (skipped with `yes`). Returns the exit code.
+pub fn cmd_suggest(dir: Option<PathBuf>, only: Vec<Kind>, apply_changes: bool, yes: bool, format: InstrumentFormat) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    if !matches!(stack, Stack::Go | Stack::Node | Stack::Python) {
+        eprintln!("dx instrument suggest sabe instrumentar projetos Go, Node.js e Python; stack detectada em {}: {}.", project_dir.display(), stack);
+        return 1;
+    }
+    let kinds = if only.is_empty() { Kind::ALL.to_vec() } else { only };
+    let (mut suggestions, mut skipped) = (Vec::new(), Vec::new());
+    for (kind, outcome) in suggest(&project_dir, stack, &kinds) {
+        match outcome {
+            Ok(s) => suggestions.push(s),
+            Err(reason) => skipped.push((kind, reason)),
+        }
+    }
+
+    if format == InstrumentFormat::Json {
+        let report = Report {
+            stack: stack.to_string(),
+            suggestions: suggestions
+                .iter()
+                .map(|s| SuggestionJson {
+                    kind: s.kind,
+                    title: s.title.clone(),
+                    files: s.changes.iter().map(|c| FileJson { path: c.path.clone(), new_file: c.old.is_none(), diff: c.diff() }).collect(),
+                    next: s.next.clone(),
+                })
+                .collect(),
+            skipped: skipped.iter().map(|(kind, reason)| SkippedJson { kind: *kind, reason: reason.clone() }).collect(),
+        };
+        crate::output::print(&report);
+    } else {
+        // Comments before each file's headers are ignored by git apply, so the output stays a patch
+        for s in &suggestions {
+            println!("# {}: {}", s.kind.label(), s.title);
+            for command in &s.next {
+                println!("# depois de aplicar: {}", command);
+            }
+            for change in &s.changes {
+                print!("{}", change.diff());
+            }
+        }
+        for (kind, reason) in &skipped {
+            eprintln!("- {}: nada a sugerir ({})", kind.label(), reason);
+        }
+    }
+    if suggestions.is_empty() {
+        eprintln!("Nenhuma instrumentação a sugerir para {}.", project_dir.display());
+        return 0;
+    }
+    if !apply_changes {
+        if format != InstrumentFormat::Json {
+            eprintln!("Para aplicar: dx instrument suggest --apply (ou salve a saída e use git apply).");
+        }
+        return 0;
+    }
+
+    let files = suggestions.iter().map(|s| s.changes.len()).sum();
+    if !yes {
+        if !(io::stdin().is_terminal() && io::stderr().is_terminal()) {
+            eprintln!("Erro: confirmação necessária e não há terminal; use --yes para aplicar sem perguntar.");
+            return 2;
+        }
+        if !confirm(files) {
+            eprintln!("Nada foi alterado.");
+            return 1;
+        }
+    }
+    if let Err(e) = apply(&project_dir, &suggestions) {
+        eprintln!("Erro ao aplicar as alterações: {}", e);
+        return 1;
+    }
+    let next: Vec<&String> = suggestions.iter().flat_map(|s| &s.next).collect();
+    if !next.is_empty() {
+        eprintln!("Próximos passos:");
+        for command in next {
+            eprintln!("  {}", command);
+        }
+    }
+    0
+}
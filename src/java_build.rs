@@ -0,0 +1,702 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Java builds: the dependencies declared in pom.xml and build.gradle(.kts) across the modules of
+//! a multi-module build, each with the version it resolves to — properties, parent POMs,
+//! dependencyManagement and imported BOMs for Maven; properties, version catalogs, constraints and
+//! platforms (including the Spring Boot plugin's) for Gradle. Parents and BOMs outside the build
+//! come from the Maven repository (setting `maven_repository`).
+
+use crate::xml::Element;
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, HashMap, HashSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::rc::Rc;
+
+/// Parents and BOMs nested deeper than this are not followed (and cycles end here).
+const MAX_DEPTH: usize = 10;
+/// The BOM the Spring Boot Gradle plugin applies with io.spring.dependency-management.
+const SPRING_BOOT_BOM: (&str, &str) = ("org.springframework.boot", "spring-boot-dependencies");
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct JavaDependency {
+    pub group: String,
+    pub artifact: String,
+    /// Effective version; None when nothing declares or manages it (or its BOM could not be fetched)
+    pub version: Option<String>,
+    /// Maven scope (compile, test...) or Gradle configuration (implementation, testImplementation...)
+    pub scope: String,
+    /// Manifest that declares it, relative to the project root
+    pub source: String,
+    /// Where the version comes from when it is not written next to the dependency: a BOM or parent
+    /// (`group:artifact:version`), the dependencyManagement of a POM of the build, a version catalog
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub managed_by: Option<String>,
+}
+
+impl JavaDependency {
+    pub fn name(&self) -> String {
+        format!("{}:{}", self.group, self.artifact)
+    }
+
+    /// Test scope in Maven, a test configuration (testImplementation, integrationTestRuntimeOnly...) in Gradle.
+    pub fn is_test(&self) -> bool {
+        self.scope.starts_with("test") || self.scope.contains("Test")
+    }
+
+    /// A version a registry or advisory database can match: resolved and without ranges or dynamic parts.
+    pub fn exact_version(&self) -> Option<&str> {
+        self.version
+            .as_deref()
+            .filter(|v| !v.is_empty() && !v.contains(['$', '[', '(', ',', '+']) && !v.starts_with("latest."))
+    }
+}
+
+/// The dependencies of the Java build at `project_dir` (Maven when there is a pom.xml, else Gradle),
+/// module by module.
+pub fn dependencies(project_dir: &Path) -> Vec<JavaDependency> {
+    if project_dir.join("pom.xml").exists() {
+        maven_dependencies(project_dir)
+    } else {
+        gradle_dependencies(project_dir)
+    }
+}
+
+/// Build files below the project root that change what `dependencies` finds: module POMs and build
+/// scripts, and the Gradle version catalog.
+pub fn manifests(project_dir: &Path) -> Vec<PathBuf> {
+    let mut files = Vec::new();
+    if project_dir.join("pom.xml").exists() {
+        collect_modules(project_dir, &mut HashSet::new(), &mut |dir, _| files.push(dir.join("pom.xml")), 0);
+    } else {
+        files.push(project_dir.join("gradle/libs.versions.toml"));
+        files.extend(gradle_modules(project_dir).iter().filter_map(|m| gradle_build_file(&project_dir.join(m))));
+    }
+    // The root manifests are key files of the detection cache already
+    files.retain(|f| f.exists() && f.parent() != Some(project_dir));
+    files
+}
+
+/// Maven repository that serves parents and BOMs (and the latest versions of `dx dev-dependencies`).
+pub fn repository() -> String {
+    crate::settings::get("maven_repository").trim_end_matches('/').to_string()
+}
+
+fn pom_url(group: &str, artifact: &str, version: &str) -> String {
+    format!("{}/{}/{}/{}/{}-{}.pom", repository(), group.replace('.', "/"), artifact, version, artifact, version)
+}
+
+fn relative(project_dir: &Path, path: &Path) -> String {
+    path.strip_prefix(project_dir).unwrap_or(path).to_string_lossy().replace('\\', "/")
+}
+
+// --- Maven ------------------------------------------------------------------------------------
+
+#[derive(Debug, Clone)]
+struct Dep {
+    group: String,
+    artifact: String,
+    version: Option<String>,
+    scope: Option<String>,
+    kind: Option<String>,
+}
+
+impl Dep {
+    fn from(e: &Element) -> Dep {
+        Dep {
+            group: e.text_of("groupId").unwrap_or_default(),
+            artifact: e.text_of("artifactId").unwrap_or_default(),
+            version: e.text_of("version"),
+            scope: e.text_of("scope"),
+            kind: e.text_of("type"),
+        }
+    }
+}
+
+#[derive(Debug)]
+struct Parent {
+    group: String,
+    artifact: String,
+    version: String,
+    /// `<relativePath/>` (empty) means: only from the repository
+    relative_path: Option<String>,
+}
+
+#[derive(Debug)]
+struct Pom {
+    group: Option<String>,
+    artifact: String,
+    version: Option<String>,
+    parent: Option<Parent>,
+    properties: BTreeMap<String, String>,
+    managed: Vec<Dep>,
+    dependencies: Vec<Dep>,
+    modules: Vec<String>,
+}
+
+impl Pom {
+    fn parse(data: &str) -> Option<Pom> {
+        let project = crate::xml::parse(data).filter(|p| p.name == "project")?;
+        let parent = project.child("parent").map(|p| Parent {
+            group: p.text_of("groupId").unwrap_or_default(),
+            artifact: p.text_of("artifactId").unwrap_or_default(),
+            version: p.text_of("version").unwrap_or_default(),
+            relative_path: p.child("relativePath").map(|r| r.text.trim().to_string()),
+        });
+        let deps = |e: Option<&Element>| e.map(|d| d.all("dependency").map(Dep::from).collect()).unwrap_or_default();
+        Some(Pom {
+            group: project.text_of("groupId").or_else(|| parent.as_ref().map(|p| p.group.clone())),
+            artifact: project.text_of("artifactId").unwrap_or_default(),
+            version: project.text_of("version").or_else(|| parent.as_ref().map(|p| p.version.clone())),
+            properties: project
+                .child("properties")
+                .map(|p| p.children.iter().map(|c| (c.name.clone(), c.text.trim().to_string())).collect())
+                .unwrap_or_default(),
+            managed: deps(project.child("dependencyManagement").and_then(|m| m.child("dependencies"))),
+            dependencies: deps(project.child("dependencies")),
+            modules: project.child("modules").map(|m| m.all("module").map(|m| m.text.trim().to_string()).collect()).unwrap_or_default(),
+            parent,
+        })
+    }
+}
+
+/// A managed version and where it was declared.
+#[derive(Debug, Clone)]
+struct Managed {
+    version: String,
+    scope: Option<String>,
+    origin: String,
+}
+
+/// What a POM inherits and passes on: its interpolated properties and dependencyManagement.
+#[derive(Debug, Default)]
+struct Effective {
+    properties: BTreeMap<String, String>,
+    managed: BTreeMap<(String, String), Managed>,
+}
+
+/// Replace `${name}` with the properties, repeatedly (properties refer to others); unknown ones stay.
+fn interpolate(value: &str, properties: &BTreeMap<String, String>) -> String {
+    let mut value = value.to_string();
+    for _ in 0..MAX_DEPTH {
+        let mut changed = false;
+        let mut out = String::new();
+        let mut rest = value.as_str();
+        while let Some(start) = rest.find("${") {
+            let Some(end) = rest[start..].find('}') else { break };
+            let key = &rest[start + 2..start + end];
+            out.push_str(&rest[..start]);
+            match properties.get(key) {
+                Some(v) => {
+                    out.push_str(v);
+                    changed = true;
+                }
+                None => out.push_str(&rest[start..start + end + 1]),
+            }
+            rest = &rest[start + end + 1..];
+        }
+        out.push_str(rest);
+        value = out;
+        if !changed {
+            break;
+        }
+    }
+    value
+}
+
+/// Resolves POMs of the build (by coordinates, from disk) and from the repository, once each.
+struct MavenResolver {
+    root: PathBuf,
+    /// Modules of the build by `group:artifact`
+    reactor: HashMap<String, PathBuf>,
+    remote: HashMap<String, Option<Rc<Effective>>>,
+}
+
+impl MavenResolver {
+    fn local(&mut self, dir: &Path, depth: usize) -> Option<(Pom, Rc<Effective>)> {
+        let pom = Pom::parse(&fs::read_to_string(dir.join("pom.xml")).ok()?)?;
+        let origin = relative(&self.root, &dir.join("pom.xml"));
+        let effective = self.effective(&pom, Some(dir), &origin, depth);
+        Some((pom, effective))
+    }
+
+    fn fetch(&mut self, group: &str, artifact: &str, version: &str, depth: usize) -> Option<Rc<Effective>> {
+        let key = format!("{}:{}:{}", group, artifact, version);
+        if let Some(cached) = self.remote.get(&key) {
+            return cached.clone();
+        }
+        if let Some(dir) = self.reactor.get(&format!("{}:{}", group, artifact)).cloned() {
+            return self.local(&dir, depth).map(|(_, e)| e);
+        }
+        // Placeholder while resolving, so a cycle ends here
+        self.remote.insert(key.clone(), None);
+        let effective = crate::registry::get(&pom_url(group, artifact, version))
+            .ok()
+            .and_then(|data| Pom::parse(&data))
+            .map(|pom| self.effective(&pom, None, &key, depth));
+        self.remote.insert(key, effective.clone());
+        effective
+    }
+
+    /// Properties and dependencyManagement of `pom` (in `dir`, when it is on disk), over its parent's.
+    fn effective(&mut self, pom: &Pom, dir: Option<&Path>, origin: &str, depth: usize) -> Rc<Effective> {
+        if depth > MAX_DEPTH {
+            return Rc::default();
+        }
+        let parent = pom.parent.as_ref().and_then(|p| {
+            let local = dir.zip(match p.relative_path.as_deref() {
+                Some("") => None,
+                Some(path) => Some(path),
+                None => Some("../pom.xml"),
+            });
+            let from_disk = local.and_then(|(dir, path)| {
+                let path = dir.join(path);
+                let parent_dir = if path.is_dir() { path } else { path.parent()?.to_path_buf() };
+                let parsed = Pom::parse(&fs::read_to_string(parent_dir.join("pom.xml")).ok()?)?;
+                (parsed.artifact == p.artifact).then_some(parent_dir)
+            });
+            match from_disk {
+                Some(parent_dir) => self.local(&parent_dir, depth + 1).map(|(_, e)| e),
+                None => self.fetch(&p.group, &p.artifact, &p.version, depth + 1),
+            }
+        });
+
+        let mut properties = parent.as_ref().map(|p| p.properties.clone()).unwrap_or_default();
+        properties.extend(pom.properties.clone());
+        let mut project = vec![("artifactId", Some(pom.artifact.clone())), ("groupId", pom.group.clone()), ("version", pom.version.clone())];
+        if let Some(p) = &pom.parent {
+            project.push(("parent.groupId", Some(p.group.clone())));
+            project.push(("parent.version", Some(p.version.clone())));
+        }
+        for (key, value) in project {
+            if let Some(value) = value {
+                properties.insert(format!("project.{}", key), value.clone());
+                properties.insert(format!("pom.{}", key), value);
+            }
+        }
+        let snapshot = properties.clone();
+        for value in properties.values_mut() {
+            *value = interpolate(value, &snapshot);
+        }
+
+        let mut managed = parent.as_ref().map(|p| p.managed.clone()).unwrap_or_default();
+        let mut imported = Vec::new();
+        for dep in &pom.managed {
+            let group = interpolate(&dep.group, &properties);
+            let artifact = interpolate(&dep.artifact, &properties);
+            let Some(version) = dep.version.as_deref().map(|v| interpolate(v, &properties)) else { continue };
+            if dep.scope.as_deref() == Some("import") && dep.kind.as_deref() == Some("pom") {
+                imported.push((group, artifact, version));
+                continue;
+            }
+            let scope = dep.scope.clone();
+            managed.insert((group, artifact), Managed { version, scope, origin: origin.to_string() });
+        }
+        // Imported BOMs add what the POM and its parents do not manage; the first import wins
+        for (group, artifact, version) in imported {
+            let Some(bom) = self.fetch(&group, &artifact, &version, depth + 1) else { continue };
+            for (key, entry) in &bom.managed {
+                managed.entry(key.clone()).or_insert_with(|| entry.clone());
+            }
+        }
+        Rc::new(Effective { properties, managed })
+    }
+}
+
+/// Visit the module directories of the Maven build rooted at `dir` (itself first), with their POMs.
+fn collect_modules(dir: &Path, seen: &mut HashSet<PathBuf>, visit: &mut dyn FnMut(&Path, &Pom), depth: usize) {
+    let canonical = dir.canonicalize().unwrap_or_else(|_| dir.to_path_buf());
+    if depth > MAX_DEPTH || !seen.insert(canonical) {
+        return;
+    }
+    let Some(pom) = fs::read_to_string(dir.join("pom.xml")).ok().and_then(|d| Pom::parse(&d)) else { return };
+    visit(dir, &pom);
+    for module in &pom.modules {
+        let path = dir.join(module);
+        let module_dir = if path.is_dir() { path } else { path.parent().map(Path::to_path_buf).unwrap_or(path) };
+        collect_modules(&module_dir, seen, visit, depth + 1);
+    }
+}
+
+fn maven_dependencies(project_dir: &Path) -> Vec<JavaDependency> {
+    let mut modules = Vec::new();
+    collect_modules(project_dir, &mut HashSet::new(), &mut |dir, pom| modules.push((dir.to_path_buf(), pom.group.clone(), pom.artifact.clone())), 0);
+    let reactor: HashMap<String, PathBuf> =
+        modules.iter().map(|(dir, group, artifact)| (format!("{}:{}", group.clone().unwrap_or_default(), artifact), dir.clone())).collect();
+    let mut resolver = MavenResolver { root: project_dir.to_path_buf(), reactor, remote: HashMap::new() };
+
+    let mut deps = Vec::new();
+    for (dir, _, _) in &modules {
+        let Some((pom, effective)) = resolver.local(dir, 0) else { continue };
+        let source = relative(project_dir, &dir.join("pom.xml"));
+        for dep in &pom.dependencies {
+            let group = interpolate(&dep.group, &effective.properties);
+            let artifact = interpolate(&dep.artifact, &effective.properties);
+            // Modules of the same build are not dependencies to list
+            if resolver.reactor.contains_key(&format!("{}:{}", group, artifact)) {
+                continue;
+            }
+            let managed = effective.managed.get(&(group.clone(), artifact.clone()));
+            let (version, managed_by) = match &dep.version {
+                Some(v) => (Some(interpolate(v, &effective.properties)), None),
+                None => (managed.map(|m| m.version.clone()), managed.map(|m| m.origin.clone())),
+            };
+            let scope = dep.scope.clone().or_else(|| managed.and_then(|m| m.scope.clone())).unwrap_or_else(|| "compile".to_string());
+            deps.push(JavaDependency { group, artifact, version, scope, source: source.clone(), managed_by });
+        }
+    }
+    deps
+}
+
+// --- Gradle -----------------------------------------------------------------------------------
+
+fn gradle_build_file(dir: &Path) -> Option<PathBuf> {
+    ["build.gradle.kts", "build.gradle"].iter().map(|f| dir.join(f)).find(|p| p.exists())
+}
+
+/// Quoted strings of a line, in order ('single' and "double").
+fn quoted(line: &str) -> Vec<String> {
+    let mut out = Vec::new();
+    let mut rest = line;
+    while let Some(start) = rest.find(['\'', '"']) {
+        let quote = &rest[start..start + 1];
+        let Some(end) = rest[start + 1..].find(quote) else { break };
+        out.push(rest[start + 1..start + 1 + end].to_string());
+        rest = &rest[start + 1 + end + 1..];
+    }
+    out
+}
+
+/// Module directories of the Gradle build (relative, "" for the root), from settings.gradle(.kts).
+fn gradle_modules(project_dir: &Path) -> Vec<String> {
+    let mut modules = vec![String::new()];
+    let settings = ["settings.gradle.kts", "settings.gradle"].iter().find_map(|f| fs::read_to_string(project_dir.join(f)).ok()).unwrap_or_default();
+    for line in settings.lines().map(str::trim).filter(|l| l.starts_with("include")) {
+        for name in quoted(line) {
+            let path = name.trim_start_matches(':').replace(':', "/");
+            if !path.is_empty() && !modules.contains(&path) {
+                modules.push(path);
+            }
+        }
+    }
+    modules
+}
+
+/// Properties a Gradle script can refer to: gradle.properties, `ext` values and local `def`/`val` strings.
+fn gradle_properties(project_dir: &Path, scripts: &[&str]) -> BTreeMap<String, String> {
+    let mut properties = BTreeMap::new();
+    if let Ok(data) = fs::read_to_string(project_dir.join("gradle.properties")) {
+        for line in data.lines().map(str::trim).filter(|l| !l.starts_with('#')) {
+            if let Some((k, v)) = line.split_once('=') {
+                properties.insert(k.trim().to_string(), v.trim().to_string());
+            }
+        }
+    }
+    for script in scripts {
+        let mut in_ext = false;
+        for line in script.lines().map(str::trim) {
+            if line.starts_with("ext {") || line == "ext{" {
+                in_ext = true;
+                continue;
+            }
+            if in_ext && line.starts_with('}') {
+                in_ext = false;
+                continue;
+            }
+            let assignment = ["ext.", "def ", "val ", "var ", "extra[\"", "set("].iter().find_map(|p| line.strip_prefix(p));
+            let assignment = match (assignment, in_ext) {
+                (Some(a), _) => a,
+                (None, true) => line,
+                (None, false) => continue,
+            };
+            let key: String = assignment.chars().take_while(|c| c.is_alphanumeric() || *c == '_' || *c == '.' || *c == '-').collect();
+            if let Some(value) = quoted(&assignment[key.len()..]).first() {
+                if !key.is_empty() {
+                    properties.insert(key, value.clone());
+                }
+            }
+        }
+    }
+    properties
+}
+
+/// Replace `$name` and `${name}` (Groovy/Kotlin templates) with the properties.
+fn gradle_interpolate(value: &str, properties: &BTreeMap<String, String>) -> String {
+    let mut out = String::new();
+    let mut rest = value;
+    while let Some(start) = rest.find('$') {
+        out.push_str(&rest[..start]);
+        let after = &rest[start + 1..];
+        let (name, consumed) = match after.strip_prefix('{') {
+            Some(inner) => inner.find('}').map(|end| (&inner[..end], end + 2)).unwrap_or(("", 0)),
+            None => {
+                let len = after.find(|c: char| !(c.is_alphanumeric() || c == '_' || c == '.')).unwrap_or(after.len());
+                (&after[..len], len)
+            }
+        };
+        let key = name.trim_start_matches("rootProject.").trim_start_matches("project.");
+        match properties.get(key) {
+            Some(v) if consumed > 0 => out.push_str(v),
+            _ => out.push_str(&rest[start..start + 1 + consumed]),
+        }
+        rest = &after[consumed..];
+    }
+    out.push_str(rest);
+    out
+}
+
+/// gradle/libs.versions.toml: `libs.<alias>` and `libs.bundles.<alias>` accessors to coordinates.
+#[derive(Default)]
+struct Catalog {
+    libraries: BTreeMap<String, (String, String, Option<String>)>,
+    bundles: BTreeMap<String, Vec<String>>,
+}
+
+/// The accessor form of a catalog alias (`spring-boot_starter` → `spring.boot.starter`).
+fn accessor(alias: &str) -> String {
+    alias.replace(['-', '_'], ".")
+}
+
+impl Catalog {
+    fn load(project_dir: &Path) -> Catalog {
+        let mut catalog = Catalog::default();
+        let Ok(data) = fs::read_to_string(project_dir.join("gradle/libs.versions.toml")) else { return catalog };
+        let Ok(doc) = data.parse::<toml_edit::DocumentMut>() else { return catalog };
+        let versions: BTreeMap<String, String> = doc
+            .get("versions")
+            .and_then(|v| v.as_table_like())
+            .map(|t| {
+                t.iter()
+                    .filter_map(|(k, v)| {
+                        let version = v.as_str().map(str::to_string).or_else(|| {
+                            let t = v.as_table_like()?;
+                            ["strictly", "require", "prefer"].iter().find_map(|k| t.get(k)?.as_str().map(str::to_string))
+                        })?;
+                        Some((k.to_string(), version))
+                    })
+                    .collect()
+            })
+            .unwrap_or_default();
+        if let Some(libraries) = doc.get("libraries").and_then(|l| l.as_table_like()) {
+            for (alias, entry) in libraries.iter() {
+                let coordinates = if let Some(s) = entry.as_str() {
+                    let mut parts = s.split(':');
+                    let (g, a, v) = (parts.next(), parts.next(), parts.next());
+                    g.zip(a).map(|(g, a)| (g.to_string(), a.to_string(), v.map(str::to_string)))
+                } else if let Some(t) = entry.as_table_like() {
+                    let (g, a) = match t.get("module").and_then(|m| m.as_str()).and_then(|m| m.split_once(':')) {
+                        Some((g, a)) => (Some(g.to_string()), Some(a.to_string())),
+                        None => (t.get("group").and_then(|g| g.as_str()).map(str::to_string), t.get("name").and_then(|n| n.as_str()).map(str::to_string)),
+                    };
+                    let version = t.get("version").and_then(|v| {
+                        if let Some(v) = v.as_str() {
+                            return Some(v.to_string());
+                        }
+                        let t = v.as_table_like()?;
+                        if let Some(r) = t.get("ref").and_then(|r| r.as_str()) {
+                            return versions.get(r).cloned();
+                        }
+                        ["strictly", "require", "prefer"].iter().find_map(|k| t.get(k)?.as_str().map(str::to_string))
+                    });
+                    g.zip(a).map(|(g, a)| (g, a, version))
+                } else {
+                    None
+                };
+                if let Some(coordinates) = coordinates {
+                    catalog.libraries.insert(accessor(alias), coordinates);
+                }
+            }
+        }
+        if let Some(bundles) = doc.get("bundles").and_then(|b| b.as_table_like()) {
+            for (alias, entry) in bundles.iter() {
+                let members = entry.as_array().map(|a| a.iter().filter_map(|v| v.as_str().map(accessor)).collect()).unwrap_or_default();
+                catalog.bundles.insert(accessor(alias), members);
+            }
+        }
+        catalog
+    }
+}
+
+/// One declaration of a dependencies block.
+struct Declaration {
+    configuration: String,
+    group: String,
+    artifact: String,
+    version: Option<String>,
+    /// From the version catalog
+    catalog: bool,
+    /// platform(...) or enforcedPlatform(...): a BOM
+    platform: bool,
+    /// Inside `constraints { }`: a managed version, not a dependency
+    constraint: bool,
+}
+
+/// The declarations of the `dependencies { }` blocks of a build script.
+fn parse_gradle_script(script: &str, properties: &BTreeMap<String, String>, catalog: &Catalog) -> Vec<Declaration> {
+    let mut out = Vec::new();
+    // Brace depth at which the dependencies and constraints blocks opened
+    let mut deps_depth: Option<i32> = None;
+    let mut constraints_depth: Option<i32> = None;
+    let mut depth = 0;
+    for line in script.lines() {
+        let line = line.split("//").next().unwrap_or("").trim();
+        let opens = line.matches('{').count() as i32;
+        let closes = line.matches('}').count() as i32;
+        if deps_depth.is_none() && line.starts_with("dependencies") && opens > 0 {
+            deps_depth = Some(depth);
+        } else if deps_depth.is_some() && constraints_depth.is_none() && line.starts_with("constraints") && opens > 0 {
+            constraints_depth = Some(depth);
+        } else if deps_depth.is_some() {
+            if let Some(declaration) = parse_declaration(line, properties, catalog) {
+                out.extend(declaration.into_iter().map(|mut d| {
+                    d.constraint = constraints_depth.is_some();
+                    d
+                }));
+            }
+        }
+        depth += opens - closes;
+        if constraints_depth.is_some_and(|d| depth <= d) {
+            constraints_depth = None;
+        }
+        if deps_depth.is_some_and(|d| depth <= d) {
+            deps_depth = None;
+        }
+    }
+    out
+}
+
+fn parse_declaration(line: &str, properties: &BTreeMap<String, String>, catalog: &Catalog) -> Option<Vec<Declaration>> {
+    let configuration: String = line.chars().take_while(|c| c.is_alphanumeric() || *c == '_').collect();
+    if configuration.is_empty() || ["if", "else", "for", "exclude", "because", "force", "version", "classpath"].contains(&configuration.as_str()) {
+        return None;
+    }
+    let rest = line[configuration.len()..].trim_start();
+    let notation = rest.starts_with(['(', '\'', '"']) || ["libs.", "platform", "enforcedPlatform", "group"].iter().any(|p| rest.starts_with(p));
+    if !notation {
+        return None;
+    }
+    let platform = rest.contains("platform(") || rest.contains("Platform(") || rest.contains("platform ");
+    let declaration = |group: String, artifact: String, version: Option<String>, catalog: bool| Declaration {
+        configuration: configuration.clone(),
+        group,
+        artifact,
+        version: version.map(|v| gradle_interpolate(&v, properties)).filter(|v| !v.is_empty()),
+        catalog,
+        platform,
+        constraint: false,
+    };
+
+    // libs.some.library or libs.bundles.name
+    if let Some(start) = rest.find("libs.") {
+        let path: String = rest[start + 5..].chars().take_while(|c| c.is_alphanumeric() || *c == '.' || *c == '_').collect();
+        let path = path.trim_end_matches(".get").trim_end_matches('.').to_string();
+        if let Some(bundle) = path.strip_prefix("bundles.") {
+            let members = catalog.bundles.get(bundle)?;
+            return Some(
+                members
+                    .iter()
+                    .filter_map(|m| catalog.libraries.get(m))
+                    .map(|(g, a, v)| declaration(g.clone(), a.clone(), v.clone(), true))
+                    .collect(),
+            );
+        }
+        let (g, a, v) = catalog.libraries.get(&path)?;
+        return Some(vec![declaration(g.clone(), a.clone(), v.clone(), true)]);
+    }
+
+    let strings = quoted(rest);
+    // Map notation: group: 'g', name: 'a', version: 'v' (Groovy) or group = "g", name = "a" (Kotlin)
+    if rest.contains("name") && (rest.contains("group:") || rest.contains("group =")) {
+        let field = |key: &str| {
+            let at = rest.find(&format!("{}:", key)).or_else(|| rest.find(&format!("{} =", key)))?;
+            quoted(&rest[at..]).into_iter().next()
+        };
+        let group = field("group")?;
+        let artifact = field("name")?;
+        return Some(vec![declaration(group, artifact, field("version"), false)]);
+    }
+    let coordinates = strings.first()?;
+    let mut parts = coordinates.split(':');
+    let group = gradle_interpolate(parts.next()?, properties);
+    let artifact = gradle_interpolate(parts.next()?, properties);
+    if group.is_empty() || artifact.is_empty() || group.contains('/') {
+        return None;
+    }
+    let version = parts.next().map(|v| v.split('@').next().unwrap_or(v).trim_end_matches("!!").to_string());
+    Some(vec![declaration(group, artifact, version, false)])
+}
+
+/// Version of a plugin in a `plugins { }` block: `id 'x' version 'v'` or `id("x") version "v"`.
+fn plugin_version(script: &str, id: &str) -> Option<String> {
+    script.lines().map(str::trim).find_map(|line| {
+        let strings = quoted(line);
+        (line.starts_with("id") && strings.first().map(String::as_str) == Some(id) && line.contains("version")).then(|| strings.get(1).cloned()).flatten()
+    })
+}
+
+/// BOMs imported with the dependency-management plugin: `mavenBom 'g:a:v'` / `mavenBom("g:a:v")`.
+fn maven_boms(script: &str, properties: &BTreeMap<String, String>) -> Vec<(String, String, String)> {
+    script
+        .lines()
+        .map(str::trim)
+        .filter(|l| l.starts_with("mavenBom"))
+        .filter_map(|l| {
+            let coordinates = gradle_interpolate(quoted(l).first()?, properties);
+            let mut parts = coordinates.split(':');
+            Some((parts.next()?.to_string(), parts.next()?.to_string(), parts.next()?.to_string()))
+        })
+        .collect()
+}
+
+fn gradle_dependencies(project_dir: &Path) -> Vec<JavaDependency> {
+    let modules = gradle_modules(project_dir);
+    let root_script = gradle_build_file(project_dir).and_then(|p| fs::read_to_string(p).ok()).unwrap_or_default();
+    let catalog = Catalog::load(project_dir);
+    let mut resolver = MavenResolver { root: project_dir.to_path_buf(), reactor: HashMap::new(), remote: HashMap::new() };
+    // The Spring Boot plugin version is usually declared once, in the root script
+    let root_boot = plugin_version(&root_script, "org.springframework.boot");
+
+    let mut deps = Vec::new();
+    for module in &modules {
+        let Some(file) = gradle_build_file(&project_dir.join(module)) else { continue };
+        let Ok(script) = fs::read_to_string(&file) else { continue };
+        let scripts: Vec<&str> = if module.is_empty() { vec![&script] } else { vec![&root_script, &script] };
+        let properties = gradle_properties(project_dir, &scripts);
+        let source = relative(project_dir, &file);
+        let declarations = parse_gradle_script(&script, &properties, &catalog);
+
+        // Managed versions: constraints first, then platforms and BOMs, in declaration order
+        let mut managed: BTreeMap<(String, String), Managed> = BTreeMap::new();
+        for d in declarations.iter().filter(|d| d.constraint) {
+            if let Some(version) = &d.version {
+                managed.insert((d.group.clone(), d.artifact.clone()), Managed { version: version.clone(), scope: None, origin: source.clone() });
+            }
+        }
+        let mut boms: Vec<(String, String, String)> =
+            declarations.iter().filter(|d| d.platform && !d.constraint).filter_map(|d| Some((d.group.clone(), d.artifact.clone(), d.version.clone()?))).collect();
+        boms.extend(maven_boms(&script, &properties));
+        if script.contains("io.spring.dependency-management") || root_script.contains("io.spring.dependency-management") {
+            if let Some(version) = plugin_version(&script, "org.springframework.boot").or(root_boot.clone()) {
+                boms.push((SPRING_BOOT_BOM.0.to_string(), SPRING_BOOT_BOM.1.to_string(), version));
+            }
+        }
+        for (group, artifact, version) in boms {
+            let Some(bom) = resolver.fetch(&group, &artifact, &version, 0) else { continue };
+            for (key, entry) in &bom.managed {
+                managed.entry(key.clone()).or_insert_with(|| entry.clone());
+            }
+        }
+
+        for d in declarations.into_iter().filter(|d| !d.constraint && !d.platform) {
+            let entry = managed.get(&(d.group.clone(), d.artifact.clone()));
+            let (version, managed_by) = match (&d.version, d.catalog) {
+                (Some(v), true) => (Some(v.clone()), Some("gradle/libs.versions.toml".to_string())),
+                (Some(v), false) => (Some(v.clone()), None),
+                (None, _) => (entry.map(|m| m.version.clone()), entry.map(|m| m.origin.clone())),
+            };
+            deps.push(JavaDependency { group: d.group, artifact: d.artifact, version, scope: d.configuration, source: source.clone(), managed_by });
+        }
+    }
+    deps
+}
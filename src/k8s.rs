@@ -0,0 +1,320 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+const K8S_DIR: &str = "k8s";
+/// First line of the generated files; files without it were written by hand.
+const HEADER: &str = "# Gerado por: dx dev-config k8s";
+/// Overlays written with `--overlays`: (name, replicas).
+const OVERLAYS: &[(&str, u32)] = &[("dev", 1), ("staging", 2)];
+/// Parts of a variable name that mark it as a secret, kept out of the ConfigMap.
+const SECRET_MARKERS: &[&str] = &["SECRET", "PASSWORD", "TOKEN", "API_KEY", "PRIVATE_KEY", "CREDENTIAL"];
+
+/// Name of the Kubernetes objects: the project directory as a DNS-1123 label.
+pub(crate) fn resource_name(project_dir: &Path) -> String {
+    let dir = project_dir.canonicalize().unwrap_or_else(|_| project_dir.to_path_buf());
+    let raw = dir.file_name().map(|n| n.to_string_lossy().to_lowercase()).unwrap_or_default();
+    let name: String = raw.chars().map(|c| if c.is_ascii_alphanumeric() { c } else { '-' }).collect();
+    let name = name.split('-').filter(|p| !p.is_empty()).collect::<Vec<_>>().join("-");
+    let name: String = name.chars().take(63).collect();
+    match name.trim_end_matches('-') {
+        "" => "app".to_string(),
+        name => name.to_string(),
+    }
+}
+
+pub(crate) fn is_secret(name: &str) -> bool {
+    SECRET_MARKERS.iter().any(|m| name.contains(m))
+}
+
+/// YAML double-quoted scalar (a JSON string is one).
+pub(crate) fn quote(value: &str) -> String {
+    serde_json::to_string(value).unwrap_or_default()
+}
+
+/// Variables of the application in the cluster: the connections to the infrastructure by service
+/// name (as in the devcontainer), then every other variable read in the code that has a default.
+/// The required ones (no default) are returned apart. PORT is added when the code reads no port.
+fn cluster_env(vars: &[crate::dev_env::EnvVar], port: u16, config: &crate::dev_services::DockerComposeConfig) -> (BTreeMap<String, String>, Vec<String>) {
+    let mut env = crate::devcontainer::app_env(config, vars);
+    let mut required = Vec::new();
+    for var in vars {
+        match &var.default {
+            Some(default) => {
+                env.entry(var.name.clone()).or_insert_with(|| default.clone());
+            }
+            None if var.required && !env.contains_key(&var.name) => required.push(var.name.clone()),
+            None => {}
+        }
+    }
+    if crate::devcontainer::app_port(vars).is_none() {
+        env.insert("PORT".to_string(), port.to_string());
+    }
+    (env, required)
+}
+
+/// The application as deployed to a cluster, from what dx detects in the project.
+pub(crate) struct Workload {
+    pub name: String,
+    pub port: u16,
+    /// GET route for the probes; TCP probes without one
+    pub health: Option<String>,
+    /// GET route for the readiness probe when the application has its own (`/readyz`)
+    pub ready: Option<String>,
+    /// ConfigMap variables
+    pub env: BTreeMap<String, String>,
+    /// Variables without a default, to be filled in
+    pub required: Vec<String>,
+    /// Variables kept out of the ConfigMap, read from a Secret
+    pub secrets: Vec<String>,
+    /// Infrastructure services the application connects to (tools such as kafka-ui are left out)
+    pub services: Vec<String>,
+}
+
+impl Workload {
+    /// None when no stack is detected in `project_dir`.
+    pub(crate) fn detect(project_dir: &Path) -> Option<Workload> {
+        let stack = Stack::detect(project_dir);
+        if stack == Stack::Unknown {
+            return None;
+        }
+        let vars = crate::dev_env::scan(project_dir);
+        let port = crate::devcontainer::app_port(&vars).or(crate::dockerfile::default_port(stack)).unwrap_or(8080);
+        let config = crate::dev_services::detect_dependencies(project_dir);
+        let (mut env, mut required) = cluster_env(&vars, port, &config);
+        let mut secrets: Vec<String> = env.keys().chain(&required).filter(|k| is_secret(k)).cloned().collect();
+        secrets.sort();
+        env.retain(|k, _| !is_secret(k));
+        required.retain(|k| !is_secret(k));
+        let mut services: Vec<String> = config.services.keys().filter(|s| vars.iter().any(|v| &v.service == *s)).cloned().collect();
+        services.sort();
+        let routes = crate::api_client::detect_routes(project_dir);
+        let health = crate::dockerfile::health_route(&routes);
+        Some(Workload {
+            name: resource_name(project_dir),
+            port,
+            ready: crate::dockerfile::readiness_route(&routes).filter(|r| Some(r) != health.as_ref()),
+            health,
+            env,
+            required,
+            secrets,
+            services,
+        })
+    }
+
+    /// Variable carrying the application port in the ConfigMap (PORT, APP_PORT...).
+    pub(crate) fn port_env(&self) -> Option<&str> {
+        let port = self.port.to_string();
+        self.env.iter().find(|(k, v)| (*k == "PORT" || k.ends_with("_PORT")) && v.trim() == port).map(|(k, _)| k.as_str())
+    }
+
+    /// Probes for the summary line: `probes GET /healthz e /readyz`, or TCP ones.
+    pub(crate) fn probes_summary(&self) -> String {
+        match (&self.health, &self.ready) {
+            (Some(health), Some(ready)) => format!("probes GET {} e {}", health, ready),
+            (None, Some(ready)) => format!("probes TCP e GET {}", ready),
+            (Some(health), None) => format!("probes GET {}", health),
+            (None, None) => "probes TCP".to_string(),
+        }
+    }
+
+    /// Lines printed after the files: the services the configuration points to and the Secret to create.
+    pub(crate) fn print_notes(&self, what: &str) {
+        if !self.services.is_empty() {
+            println!("{} aponta para os serviços {} pelo nome; eles precisam existir no mesmo namespace.", what, self.services.join(", "));
+        }
+        if let Some(first) = self.secrets.first() {
+            println!(
+                "Crie o Secret {name}-secrets com: {} (ex.: kubectl create secret generic {name}-secrets --from-literal={}=...).",
+                self.secrets.join(", "),
+                first,
+                name = self.name
+            );
+        }
+    }
+}
+
+pub(crate) fn render_deployment(name: &str, image: &str, port: u16, health: Option<&str>, ready: Option<&str>, secrets: bool) -> String {
+    let probe = |kind: &str, path: Option<&str>, delay: u32| match path {
+        Some(path) => format!(
+            "          {}:\n            httpGet:\n              path: {}\n              port: http\n            initialDelaySeconds: {}\n            periodSeconds: 10\n",
+            kind, path, delay
+        ),
+        None => format!("          {}:\n            tcpSocket:\n              port: http\n            initialDelaySeconds: {}\n            periodSeconds: 10\n", kind, delay),
+    };
+    let secret_ref = if secrets {
+        format!("            - secretRef:\n                name: {}-secrets\n                optional: true\n", name)
+    } else {
+        String::new()
+    };
+    format!(
+        "{HEADER}\n\
+         apiVersion: apps/v1\n\
+         kind: Deployment\n\
+         metadata:\n\
+         \x20 name: {name}\n\
+         \x20 labels:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         spec:\n\
+         \x20 replicas: 1\n\
+         \x20 selector:\n\
+         \x20   matchLabels:\n\
+         \x20     app.kubernetes.io/name: {name}\n\
+         \x20 template:\n\
+         \x20   metadata:\n\
+         \x20     labels:\n\
+         \x20       app.kubernetes.io/name: {name}\n\
+         \x20   spec:\n\
+         \x20     containers:\n\
+         \x20       - name: {name}\n\
+         \x20         image: {image}\n\
+         \x20         ports:\n\
+         \x20           - name: http\n\
+         \x20             containerPort: {port}\n\
+         \x20         envFrom:\n\
+         \x20           - configMapRef:\n\
+         \x20               name: {name}-config\n\
+         {secret_ref}\
+         {readiness}\
+         {liveness}\
+         \x20         resources:\n\
+         \x20           requests:\n\
+         \x20             cpu: 100m\n\
+         \x20             memory: 128Mi\n\
+         \x20           limits:\n\
+         \x20             memory: 256Mi\n\
+         \x20         securityContext:\n\
+         \x20           allowPrivilegeEscalation: false\n\
+         \x20           capabilities:\n\
+         \x20             drop: [\"ALL\"]\n",
+        readiness = probe("readinessProbe", ready.or(health), 5),
+        liveness = probe("livenessProbe", health, 15),
+    )
+}
+
+pub(crate) fn render_service(name: &str) -> String {
+    format!(
+        "{HEADER}\n\
+         apiVersion: v1\n\
+         kind: Service\n\
+         metadata:\n\
+         \x20 name: {name}\n\
+         \x20 labels:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         spec:\n\
+         \x20 type: ClusterIP\n\
+         \x20 selector:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         \x20 ports:\n\
+         \x20   - name: http\n\
+         \x20     port: 80\n\
+         \x20     targetPort: http\n"
+    )
+}
+
+pub(crate) fn render_configmap(name: &str, env: &BTreeMap<String, String>, required: &[String], secrets: &[String]) -> String {
+    let mut out = format!("{HEADER}\n");
+    if !required.is_empty() {
+        out.push_str(&format!("# Obrigatórias (sem padrão no código), acrescente com os valores do ambiente: {}\n", required.join(", ")));
+    }
+    if !secrets.is_empty() {
+        out.push_str(&format!("# Fora do ConfigMap, no Secret {}-secrets: {}\n", name, secrets.join(", ")));
+    }
+    out.push_str(&format!("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: {name}-config\n  labels:\n    app.kubernetes.io/name: {name}\n"));
+    out.push_str(if env.is_empty() { "data: {}\n" } else { "data:\n" });
+    for (key, value) in env {
+        out.push_str(&format!("  {}: {}\n", key, quote(value)));
+    }
+    out
+}
+
+fn render_kustomization(resources: &[&str]) -> String {
+    let mut out = format!("{HEADER}\napiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n");
+    for resource in resources {
+        out.push_str(&format!("  - {}\n", resource));
+    }
+    out
+}
+
+fn render_overlay(name: &str, image: &str, environment: &str, replicas: u32) -> String {
+    let repository = image.rsplit_once(':').filter(|(_, tag)| !tag.contains('/')).map_or(image, |(repo, _)| repo);
+    format!(
+        "{HEADER}\n\
+         apiVersion: kustomize.config.k8s.io/v1beta1\n\
+         kind: Kustomization\n\
+         resources:\n\
+         \x20 - ../../base\n\
+         namespace: {name}-{environment}\n\
+         labels:\n\
+         \x20 - pairs:\n\
+         \x20     app.kubernetes.io/environment: {environment}\n\
+         replicas:\n\
+         \x20 - name: {name}\n\
+         \x20   count: {replicas}\n\
+         images:\n\
+         \x20 - name: {repository}\n\
+         \x20   newTag: {environment}\n"
+    )
+}
+
+/// `dx dev-config k8s`: write Deployment, Service and ConfigMap manifests (with a kustomization)
+/// under k8s/ for the application, from the detected port, environment variables and
+/// infrastructure. With `overlays`, the manifests go to k8s/base and dev/staging overlays are
+/// added. Hand-written files go through the conflict resolution; `force` replaces them.
+pub fn cmd_k8s(dir: Option<PathBuf>, save_file: bool, force: bool, overlays: bool, image: Option<String>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(workload) = Workload::detect(&project_dir) else {
+        eprintln!("Nenhuma stack detectada em {}; nada a implantar.", project_dir.display());
+        return 1;
+    };
+    let Workload { name, port, health, ready, env, required, secrets, .. } = &workload;
+    let image = image.unwrap_or_else(|| format!("{}:latest", name));
+
+    let root = project_dir.join(K8S_DIR);
+    let base = if overlays { root.join("base") } else { root.clone() };
+    let mut files = vec![
+        (base.join("deployment.yaml"), render_deployment(name, &image, *port, health.as_deref(), ready.as_deref(), !secrets.is_empty())),
+        (base.join("service.yaml"), render_service(name)),
+        (base.join("configmap.yaml"), render_configmap(name, env, required, secrets)),
+        (base.join("kustomization.yaml"), render_kustomization(&["deployment.yaml", "service.yaml", "configmap.yaml"])),
+    ];
+    if overlays {
+        for (environment, replicas) in OVERLAYS {
+            let dir = root.join("overlays").join(environment);
+            files.push((dir.join("kustomization.yaml"), render_overlay(name, &image, environment, *replicas)));
+        }
+    }
+
+    if !save_file {
+        for (path, content) in &files {
+            println!("# {}\n{}", path.strip_prefix(&project_dir).unwrap_or(path).display(), content);
+        }
+        return 0;
+    }
+    if !crate::conflicts::settle(&mut files, crate::conflicts::generated_by(HEADER), force) {
+        return 1;
+    }
+    for (path, content) in &files {
+        if let Some(parent) = path.parent() {
+            if let Err(e) = fs::create_dir_all(parent) {
+                eprintln!("Erro ao criar {}: {}", parent.display(), e);
+                return 1;
+            }
+        }
+        if let Err(e) = crate::audit::write(path, content) {
+            eprintln!("Erro ao salvar {}: {}", path.display(), e);
+            return 1;
+        }
+        println!("  {}", path.display());
+    }
+
+    println!("✓ Manifestos Kubernetes gerados ({}; porta {}; {}; {} variável(is) no ConfigMap).", name, port, workload.probes_summary(), env.len());
+    workload.print_notes("O ConfigMap");
+    let target = if overlays { root.join("overlays").join("dev") } else { root };
+    println!("Aplique com: kubectl apply -k {}", target.display());
+    0
+}
@@ -0,0 +1,1222 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `dx dev-kafka events`: the catalog of events a project publishes to Kafka, read from the
+//! payload types it serializes (Go structs with `json` tags, Java classes and records), the event
+//! type constants assigned to them and the topics and headers of the producers that send them.
+
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use serde_json::{json, Map, Value};
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum EventsFormat {
+    /// Documento Markdown com um resumo e uma seção por evento
+    Markdown,
+    /// Objeto JSON com `events` (tópicos, tipos, headers e o JSON Schema do payload)
+    Json,
+}
+
+/// JSON fields (in order of preference) that tell events sharing a payload apart.
+const TYPE_FIELDS: &[&str] = &["event_type", "eventType", "type", "event_name", "eventName", "kind"];
+
+/// Where a producer names the header it adds: kafka-go's `kafka.Header{Key: ...}` is handled
+/// apart; these are the Java clients' and Spring's.
+const JAVA_HEADER_PATTERNS: &[&str] = &[".headers().add(", "new RecordHeader(", ".setHeader("];
+
+/// Java generic types whose last argument is the payload sent to Kafka.
+const JAVA_PRODUCER_TYPES: &[&str] = &["KafkaTemplate<", "ProducerRecord<", "KafkaProducer<", "Producer<"];
+
+#[derive(Debug, Clone, PartialEq)]
+enum FieldType {
+    /// JSON string, with an optional JSON Schema `format`
+    String(Option<&'static str>),
+    Integer,
+    Number,
+    Boolean,
+    Array(Box<FieldType>),
+    Map(Box<FieldType>),
+    /// Another type of the project: a struct, or an enum of string constants
+    Named(String),
+    Any,
+}
+
+#[derive(Debug, Clone)]
+struct Field {
+    /// Name in the JSON payload
+    json: String,
+    /// Name in the code (`EventType`, `eventType`)
+    code: String,
+    ty: FieldType,
+    required: bool,
+    /// Validation rules of Go `binding:"..."` (gin) or `validate:"..."` tags: `required`, `min=3`, `email`
+    rules: Vec<String>,
+    /// Name in query strings and forms (Go `form:"..."` tag), when it differs from the JSON one
+    form: Option<String>,
+}
+
+#[derive(Debug, Clone)]
+struct Struct {
+    /// Definition site (`internal/models/user.go:27`)
+    source: String,
+    fields: Vec<Field>,
+    /// Go embedded structs, whose fields are promoted into this one
+    embeds: Vec<String>,
+}
+
+/// Everything read from the project's sources.
+#[derive(Default)]
+pub(crate) struct Code {
+    structs: BTreeMap<String, Struct>,
+    /// Constant (Go) or enum (Java) type name -> its string values
+    enums: BTreeMap<String, Vec<String>>,
+    /// Go string constants by name
+    consts: BTreeMap<String, String>,
+    /// `key=value` from `.properties` files, for Spring's `@Value("${...}")` topics
+    properties: BTreeMap<String, String>,
+    /// (relative path, content) of the Go and Java files
+    files: Vec<(String, String)>,
+    /// Whether any file talks to Kafka
+    uses_kafka: bool,
+}
+
+/// An event the project publishes: its payload type and what the producers tell about it.
+pub(crate) struct Event {
+    pub name: String,
+    /// Definition site of the payload type
+    pub source: String,
+    /// Files that send it
+    pub producers: BTreeSet<String>,
+    pub topics: BTreeSet<String>,
+    /// Payload field used as message key
+    pub key: Option<String>,
+    pub headers: BTreeSet<String>,
+    /// Payload field telling the event types apart, and its values
+    pub type_field: Option<String>,
+    pub types: Vec<String>,
+}
+
+fn is_ident(c: char) -> bool {
+    c.is_ascii_alphanumeric() || c == '_'
+}
+
+/// The identifier at the start of `s` (dots allowed with `dotted`, for `pkg.Name`).
+pub(crate) fn ident_at(s: &str, dotted: bool) -> &str {
+    let end = s.find(|c: char| !(is_ident(c) || (dotted && c == '.'))).unwrap_or(s.len());
+    &s[..end]
+}
+
+/// Last segment of a qualified name (`models.UserEvent` -> `UserEvent`).
+pub(crate) fn simple_name(name: &str) -> &str {
+    name.rsplit('.').next().unwrap_or(name)
+}
+
+/// Byte offsets where `word` occurs as a whole identifier in `text`.
+pub(crate) fn word_positions<'a>(text: &'a str, word: &'a str) -> impl Iterator<Item = usize> + 'a {
+    text.match_indices(word).map(|(at, _)| at).filter(move |&at| {
+        let before = text[..at].chars().next_back().is_none_or(|c| !is_ident(c));
+        let after = text[at + word.len()..].chars().next().is_none_or(|c| !is_ident(c));
+        before && after
+    })
+}
+
+/// Text between the `{` at `open` and its matching `}`.
+pub(crate) fn braced(text: &str, open: usize) -> &str {
+    let mut depth = 0;
+    for (i, c) in text[open..].char_indices() {
+        match c {
+            '{' => depth += 1,
+            '}' => {
+                depth -= 1;
+                if depth == 0 {
+                    return &text[open + 1..open + i];
+                }
+            }
+            _ => {}
+        }
+    }
+    &text[open + 1..]
+}
+
+/// The first string literal of `rest` (after optional blanks).
+fn literal(rest: &str) -> Option<String> {
+    crate::dev_kafka::literals_after(rest).into_iter().next()
+}
+
+fn line_of(text: &str, at: usize) -> usize {
+    text[..at].matches('\n').count() + 1
+}
+
+// ---------------------------------------------------------------------------------------------
+// Go
+// ---------------------------------------------------------------------------------------------
+
+fn go_type(ty: &str) -> FieldType {
+    let ty = ty.trim().trim_start_matches('*');
+    if ty == "[]byte" {
+        return FieldType::String(Some("byte"));
+    }
+    if let Some(item) = ty.strip_prefix("[]") {
+        return FieldType::Array(Box::new(go_type(item)));
+    }
+    if let Some(rest) = ty.strip_prefix("map[") {
+        let value = rest.split_once(']').map(|(_, v)| v).unwrap_or("");
+        return FieldType::Map(Box::new(go_type(value)));
+    }
+    match ty {
+        "string" | "primitive.ObjectID" | "uuid.UUID" => FieldType::String(None),
+        "time.Time" => FieldType::String(Some("date-time")),
+        "bool" => FieldType::Boolean,
+        "float32" | "float64" => FieldType::Number,
+        "int" | "int8" | "int16" | "int32" | "int64" | "uint" | "uint8" | "uint16" | "uint32" | "uint64" | "time.Duration" => {
+            FieldType::Integer
+        }
+        "" | "any" | "interface{}" | "json.RawMessage" => FieldType::Any,
+        _ if ty.starts_with("struct") || ty.starts_with("func") || ty.starts_with("chan") => FieldType::Any,
+        _ => FieldType::Named(simple_name(ty).to_string()),
+    }
+}
+
+/// Parse the fields of a Go struct body; returns (fields, embedded types).
+fn go_fields(body: &str) -> (Vec<Field>, Vec<String>) {
+    let (mut fields, mut embeds) = (Vec::new(), Vec::new());
+    let mut depth = 0;
+    for line in body.lines() {
+        let code = line.split("//").next().unwrap_or("").trim();
+        let nested = depth > 0;
+        depth += code.matches('{').count() as i32 - code.matches('}').count() as i32;
+        if nested || code.is_empty() {
+            continue;
+        }
+        let (decl, tag) = match code.split_once('`') {
+            Some((decl, rest)) => (decl.trim(), rest.trim_end_matches('`')),
+            None => (code, ""),
+        };
+        let tag_value = |key: &str| tag.split_once(&format!("{}:\"", key)).and_then(|(_, rest)| rest.split_once('"')).map(|(t, _)| t);
+        let json_tag = tag_value("json");
+        let rules: Vec<String> = tag_value("binding").or_else(|| tag_value("validate")).map(|r| r.split(',').map(str::to_string).collect()).unwrap_or_default();
+        let form = tag_value("form").map(|f| f.split(',').next().unwrap_or("").to_string()).filter(|f| !f.is_empty() && f != "-");
+        let (json_name, omitempty) = match json_tag {
+            Some(t) => {
+                let mut parts = t.split(',');
+                let name = parts.next().unwrap_or("");
+                (Some(name), parts.any(|o| o == "omitempty" || o == "omitzero"))
+            }
+            None => (None, false),
+        };
+        if json_name == Some("-") {
+            continue;
+        }
+        let mut tokens = decl.split_whitespace();
+        let Some(first) = tokens.next() else { continue };
+        let mut names = vec![first.trim_end_matches(',').to_string()];
+        let mut last = first;
+        let mut ty = String::new();
+        for token in tokens.by_ref() {
+            if last.ends_with(',') {
+                names.push(token.trim_end_matches(',').to_string());
+                last = token;
+            } else {
+                ty = token.to_string();
+                break;
+            }
+        }
+        ty.extend(tokens.map(|t| format!(" {}", t)));
+        if ty.is_empty() {
+            if json_name.is_none_or(str::is_empty) {
+                embeds.push(simple_name(first.trim_start_matches('*')).to_string());
+                continue;
+            }
+            ty = first.to_string();
+            names = vec![simple_name(first.trim_start_matches('*')).to_string()];
+        }
+        for name in names {
+            if !name.starts_with(|c: char| c.is_ascii_uppercase()) {
+                continue;
+            }
+            let json = json_name.filter(|n| !n.is_empty()).unwrap_or(&name).to_string();
+            // Validation tags say what a request must carry; otherwise, what encoding always writes
+            let required = match rules.is_empty() {
+                true => !omitempty && !ty.starts_with('*'),
+                false => rules.iter().any(|r| r == "required"),
+            };
+            fields.push(Field { json, code: name, ty: go_type(&ty), required, rules: rules.clone(), form: form.clone() });
+        }
+    }
+    (fields, embeds)
+}
+
+fn read_go(rel: &str, content: &str, code: &mut Code) {
+    let mut search = 0;
+    while let Some(found) = content[search..].find("type ") {
+        let at = search + found;
+        search = at + 5;
+        if content[..at].chars().next_back().is_some_and(is_ident) {
+            continue;
+        }
+        let rest = &content[at + 5..];
+        let name = ident_at(rest, false);
+        let after = rest[name.len()..].trim_start();
+        if name.is_empty() {
+            continue;
+        }
+        if let Some(body) = after.strip_prefix("struct") {
+            let Some(open) = body.find('{').filter(|&o| body[..o].trim().is_empty()) else { continue };
+            let open = content.len() - body.len() + open;
+            let (fields, embeds) = go_fields(braced(content, open));
+            let source = format!("{}:{}", rel, line_of(content, at));
+            code.structs.insert(name.to_string(), Struct { source, fields, embeds });
+        }
+    }
+    // Constants: `Name = "v"` and typed `Name Type = "v"`, alone or in `const ( ... )` blocks
+    let mut in_block = false;
+    for line in content.lines() {
+        let trimmed = line.split("//").next().unwrap_or("").trim();
+        if trimmed.starts_with("const (") {
+            in_block = true;
+            continue;
+        }
+        if in_block && trimmed == ")" {
+            in_block = false;
+            continue;
+        }
+        let decl = match trimmed.strip_prefix("const ") {
+            Some(decl) => decl,
+            None if in_block => trimmed,
+            None => continue,
+        };
+        let Some((left, value)) = decl.split_once('=') else { continue };
+        let Some(value) = literal(value) else { continue };
+        let mut left = left.split_whitespace();
+        let (Some(name), ty) = (left.next(), left.next()) else { continue };
+        code.consts.insert(name.to_string(), value.clone());
+        if let Some(ty) = ty {
+            code.enums.entry(ty.to_string()).or_default().push(value);
+        }
+    }
+}
+
+/// The type of the Go variable `var` in `content`: a parameter or `var` declaration
+/// (`event UserEvent`) or a composite literal assignment (`event := &UserEvent{`).
+pub(crate) fn go_var_type(content: &str, var: &str) -> Option<String> {
+    for at in word_positions(content, var) {
+        let rest = content[at + var.len()..].trim_start_matches([' ', '\t']);
+        let ty = if let Some(value) = rest.strip_prefix(":=") {
+            let value = value.trim_start().trim_start_matches('&');
+            let name = ident_at(value, true);
+            if !value[name.len()..].starts_with('{') {
+                continue;
+            }
+            name
+        } else {
+            ident_at(rest.trim_start_matches(['*', '&']), true)
+        };
+        let ty = simple_name(ty);
+        if ty.starts_with(|c: char| c.is_ascii_uppercase()) {
+            return Some(ty.to_string());
+        }
+    }
+    None
+}
+
+/// Payload types a Go file serializes (`json.Marshal(event)`), with the variable holding each.
+fn go_payloads(content: &str) -> Vec<(String, Option<String>)> {
+    let mut payloads = Vec::new();
+    for (at, pattern) in content.match_indices("json.Marshal(") {
+        let arg = content[at + pattern.len()..].trim_start().trim_start_matches('&');
+        let name = ident_at(arg, true);
+        if arg[name.len()..].starts_with('{') {
+            payloads.push((simple_name(name).to_string(), None));
+        } else if !name.contains('.') {
+            if let Some(ty) = go_var_type(content, name) {
+                payloads.push((ty, Some(name.to_string())));
+            }
+        }
+    }
+    payloads
+}
+
+/// Header names of kafka-go messages: `Headers: []kafka.Header{{Key: "event_type", ...}}`.
+fn go_headers(content: &str) -> Vec<String> {
+    let mut headers = Vec::new();
+    for (at, _) in content.match_indices("Headers:") {
+        let Some(open) = content[at..].find('{') else { continue };
+        let body = braced(content, at + open);
+        for (key, _) in body.match_indices("Key:") {
+            headers.extend(literal(&body[key + 4..]));
+        }
+    }
+    headers
+}
+
+/// The payload field used as message key: `Key: []byte(event.UserID)`.
+fn go_key(content: &str, var: &str) -> Option<String> {
+    content.match_indices("Key:").find_map(|(at, _)| {
+        let expr = content[at + 4..].lines().next().unwrap_or("");
+        let field = expr.find(&format!("{}.", var)).map(|i| ident_at(&expr[i + var.len() + 1..], false))?;
+        (!field.is_empty()).then(|| field.to_string())
+    })
+}
+
+// ---------------------------------------------------------------------------------------------
+// Java
+// ---------------------------------------------------------------------------------------------
+
+/// Split `s` on commas outside `<...>`.
+fn split_generic(s: &str) -> Vec<&str> {
+    let (mut parts, mut depth, mut start) = (Vec::new(), 0, 0);
+    for (i, c) in s.char_indices() {
+        match c {
+            '<' => depth += 1,
+            '>' => depth -= 1,
+            ',' if depth == 0 => {
+                parts.push(s[start..i].trim());
+                start = i + 1;
+            }
+            _ => {}
+        }
+    }
+    parts.push(s[start..].trim());
+    parts
+}
+
+fn java_type(ty: &str) -> FieldType {
+    let ty = ty.trim();
+    if let Some(item) = ty.strip_suffix("[]") {
+        return match item {
+            "byte" => FieldType::String(Some("byte")),
+            _ => FieldType::Array(Box::new(java_type(item))),
+        };
+    }
+    let (base, args) = match ty.split_once('<') {
+        Some((base, args)) => (base.trim(), split_generic(args.strip_suffix('>').unwrap_or(args))),
+        None => (ty, Vec::new()),
+    };
+    match simple_name(base) {
+        "String" | "UUID" | "char" | "Character" | "ObjectId" => FieldType::String(None),
+        "LocalDateTime" | "Instant" | "OffsetDateTime" | "ZonedDateTime" | "Date" | "Timestamp" => {
+            FieldType::String(Some("date-time"))
+        }
+        "LocalDate" => FieldType::String(Some("date")),
+        "boolean" | "Boolean" => FieldType::Boolean,
+        "double" | "Double" | "float" | "Float" | "BigDecimal" => FieldType::Number,
+        "int" | "Integer" | "long" | "Long" | "short" | "Short" | "byte" | "Byte" | "BigInteger" => FieldType::Integer,
+        "List" | "Set" | "Collection" | "ArrayList" | "LinkedList" | "HashSet" => {
+            FieldType::Array(Box::new(args.first().map(|a| java_type(a)).unwrap_or(FieldType::Any)))
+        }
+        "Map" | "HashMap" | "LinkedHashMap" | "TreeMap" => {
+            FieldType::Map(Box::new(args.get(1).map(|a| java_type(a)).unwrap_or(FieldType::Any)))
+        }
+        "Object" | "JsonNode" | "" => FieldType::Any,
+        name => FieldType::Named(name.to_string()),
+    }
+}
+
+/// A Java field or record component from its declaration (annotations included).
+fn java_field(decl: &str) -> Option<Field> {
+    let mut json = None;
+    let mut required = false;
+    let mut rest = decl.trim();
+    while let Some(annotated) = rest.strip_prefix('@') {
+        let name = ident_at(annotated, true);
+        let mut after = &annotated[name.len()..];
+        let mut args = "";
+        if after.starts_with('(') {
+            let end = after.find(')').unwrap_or(after.len() - 1);
+            args = &after[1..end];
+            after = &after[end + 1..];
+        }
+        match simple_name(name) {
+            "JsonIgnore" => return None,
+            "JsonProperty" | "SerializedName" => json = literal(args.trim_start_matches("value").trim_start_matches([' ', '='])),
+            "NotNull" | "NonNull" | "NotBlank" | "NotEmpty" => required = true,
+            _ => {}
+        }
+        rest = after.trim_start();
+    }
+    let words: Vec<&str> = rest.split_whitespace().collect();
+    let modifiers = ["private", "protected", "public", "final", "transient", "volatile"];
+    if words.contains(&"static") {
+        return None;
+    }
+    let words: Vec<&str> = words.into_iter().filter(|w| !modifiers.contains(w)).collect();
+    let (name, ty) = words.split_last()?;
+    let ty = ty.join(" ");
+    if ty.is_empty() || !name.chars().all(is_ident) {
+        return None;
+    }
+    required |= matches!(ty.as_str(), "int" | "long" | "short" | "byte" | "double" | "float" | "boolean" | "char");
+    Some(Field { json: json.unwrap_or_else(|| name.to_string()), code: name.to_string(), ty: java_type(&ty), required, rules: Vec::new(), form: None })
+}
+
+fn read_java(rel: &str, content: &str, code: &mut Code) {
+    // Records: the components are the fields
+    for at in word_positions(content, "record") {
+        let rest = content[at + 6..].trim_start();
+        let name = ident_at(rest, false);
+        let Some(params) = rest[name.len()..].trim_start().strip_prefix('(') else { continue };
+        if name.is_empty() || !name.starts_with(|c: char| c.is_ascii_uppercase()) {
+            continue;
+        }
+        let mut depth = 0;
+        let end = params
+            .char_indices()
+            .find(|&(_, c)| {
+                depth += match c {
+                    '(' => 1,
+                    ')' => -1,
+                    _ => 0,
+                };
+                depth < 0
+            })
+            .map_or(params.len(), |(i, _)| i);
+        let params = &params[..end];
+        let fields = split_generic(params).into_iter().filter_map(java_field).collect();
+        let source = format!("{}:{}", rel, line_of(content, at));
+        code.structs.insert(name.to_string(), Struct { source, fields, embeds: Vec::new() });
+    }
+    // Classes and enums: class-level declarations, tracked with a stack of open types
+    let mut stack: Vec<(String, i32, bool)> = Vec::new();
+    let mut depth = 0;
+    let mut pending = String::new();
+    for (i, line) in content.lines().enumerate() {
+        let text = line.split("//").next().unwrap_or("").trim();
+        let opens = text.matches('{').count() as i32;
+        let closes = text.matches('}').count() as i32;
+        let kind = ["class", "enum", "interface"].into_iter().find(|k| word_positions(text, k).next().is_some());
+        if let (Some(kind), true) = (kind, opens > 0) {
+            let at = word_positions(text, kind).next().unwrap_or(0);
+            let name = ident_at(text[at + kind.len()..].trim_start(), false).to_string();
+            if kind == "class" {
+                let source = format!("{}:{}", rel, i + 1);
+                code.structs.insert(name.clone(), Struct { source, fields: Vec::new(), embeds: Vec::new() });
+            }
+            // Enum constants come first and end at the first `;` (or the closing brace)
+            let mut constants = kind == "enum";
+            if constants {
+                let body = text.split_once('{').map(|(_, b)| b).unwrap_or("");
+                constants = java_enum_constants(code.enums.entry(name.clone()).or_default(), body);
+            }
+            stack.push((name, depth, constants));
+            depth += opens - closes;
+            pending.clear();
+            continue;
+        }
+        if let Some((name, _, constants)) = stack.last_mut().filter(|(_, d, _)| depth == *d + 1) {
+            if *constants {
+                *constants = java_enum_constants(code.enums.entry(name.clone()).or_default(), text);
+            } else if text.starts_with('@') && !text.ends_with(';') {
+                pending.push_str(text);
+                pending.push(' ');
+            } else if let Some(decl) = text.strip_suffix(';').filter(|d| !d.split('=').next().unwrap_or(d).contains('(')) {
+                let decl = decl.split('=').next().unwrap_or(decl);
+                if let Some(field) = java_field(&format!("{}{}", pending, decl)) {
+                    if let Some(s) = code.structs.get_mut(name.as_str()) {
+                        s.fields.push(field);
+                    }
+                }
+                pending.clear();
+            } else if !text.is_empty() {
+                pending.clear();
+            }
+        }
+        depth += opens - closes;
+        while stack.last().is_some_and(|(_, d, _)| depth <= *d) {
+            stack.pop();
+        }
+    }
+}
+
+/// Add the enum constants declared in `text` to `values`; false once the constant list has ended.
+fn java_enum_constants(values: &mut Vec<String>, text: &str) -> bool {
+    let list = text.split([';', '}']).next().unwrap_or(text);
+    let mut depth = 0;
+    for part in list.split(',') {
+        let part = part.trim();
+        let value = ident_at(part, false);
+        if depth == 0 && value.starts_with(|c: char| c.is_ascii_uppercase()) && !values.iter().any(|v| v == value) {
+            values.push(value.to_string());
+        }
+        depth += part.matches('(').count() as i32 - part.matches(')').count() as i32;
+    }
+    !text.contains([';', '}'])
+}
+
+/// Payload types a Java file sends: the value type of `KafkaTemplate<String, UserEvent>` & co.
+fn java_payloads(content: &str) -> Vec<String> {
+    let mut payloads = Vec::new();
+    for pattern in JAVA_PRODUCER_TYPES {
+        for at in word_positions(content, pattern.trim_end_matches('<')) {
+            let rest = &content[at..];
+            let Some(args) = rest.strip_prefix(pattern) else { continue };
+            let Some(end) = args.find('>') else { continue };
+            if let Some(value) = split_generic(&args[..end]).last().filter(|v| !v.is_empty()) {
+                payloads.push(simple_name(ident_at(value, true)).to_string());
+            }
+        }
+    }
+    payloads
+}
+
+/// The class of the Java variable `var`: a declaration or parameter (`UserEvent event`).
+fn java_var_type(content: &str, var: &str) -> Option<String> {
+    word_positions(content, var).find_map(|at| {
+        let before = content[..at].trim_end();
+        let ty = &before[before.rfind(|c: char| !is_ident(c)).map_or(0, |i| i + 1)..];
+        ty.starts_with(|c: char| c.is_ascii_uppercase()).then(|| ty.to_string())
+    })
+}
+
+/// Classes a Java file sends with `rabbitTemplate.convertAndSend(..., payload)`: the type of the
+/// last argument, a variable or a `new` expression.
+fn java_sent_types(content: &str) -> Vec<String> {
+    let mut types = Vec::new();
+    for (at, pattern) in content.match_indices(".convertAndSend(") {
+        let args = &content[at + pattern.len()..];
+        let Some(end) = args.find(");") else { continue };
+        let Some(last) = args[..end].rsplit(',').next().map(str::trim) else { continue };
+        let ty = match last.strip_prefix("new ") {
+            Some(created) => Some(ident_at(created.trim_start(), false).to_string()),
+            None => java_var_type(content, ident_at(last, false)),
+        };
+        types.extend(ty.filter(|t| !t.is_empty()));
+    }
+    types
+}
+
+/// Topics a Java file sends to: `send("topic", ...)`, or a `@Value("${prop}")` field resolved
+/// from the `.properties` files.
+fn java_topics(content: &str, properties: &BTreeMap<String, String>) -> Vec<String> {
+    let mut topics = Vec::new();
+    for (at, pattern) in content.match_indices(".send(") {
+        let arg = content[at + pattern.len()..].trim_start();
+        if let Some(topic) = literal(arg) {
+            topics.push(topic);
+            continue;
+        }
+        let var = ident_at(arg, false);
+        if var.is_empty() {
+            continue;
+        }
+        for decl in word_positions(content, var) {
+            let before = &content[..decl];
+            let Some(value_at) = before.rfind("@Value(") else { continue };
+            if before[value_at..].matches(';').count() > 0 {
+                continue;
+            }
+            let key = literal(&before[value_at + 7..]).unwrap_or_default();
+            let key = key.trim_start_matches("${").trim_end_matches('}');
+            let key = key.split(':').next().unwrap_or(key);
+            if let Some(topic) = properties.get(key) {
+                topics.push(topic.clone());
+                break;
+            }
+        }
+    }
+    topics
+}
+
+// ---------------------------------------------------------------------------------------------
+// Catalog
+// ---------------------------------------------------------------------------------------------
+
+fn read_code(root: &Path) -> Code {
+    let mut files = Vec::new();
+    crate::dev_env::collect_source_files(root, &mut files);
+    files.sort();
+    let mut code = Code::default();
+    for file in files {
+        let Ok(content) = fs::read_to_string(&file) else { continue };
+        let rel = file.strip_prefix(root).unwrap_or(&file).to_string_lossy().replace('\\', "/");
+        match file.extension().and_then(|e| e.to_str()).unwrap_or("") {
+            "go" => read_go(&rel, &content, &mut code),
+            "java" => read_java(&rel, &content, &mut code),
+            "properties" => {
+                for line in content.lines().map(str::trim).filter(|l| !l.starts_with('#')) {
+                    if let Some((key, value)) = line.split_once('=') {
+                        code.properties.insert(key.trim().to_string(), value.trim().to_string());
+                    }
+                }
+                continue;
+            }
+            _ => continue,
+        }
+        code.uses_kafka |= content.to_lowercase().contains("kafka");
+        code.files.push((rel, content));
+    }
+    code
+}
+
+/// All fields of a struct, with the ones of Go embedded structs promoted.
+fn all_fields(code: &Code, name: &str, seen: &mut BTreeSet<String>) -> Vec<Field> {
+    let Some(s) = code.structs.get(name) else { return Vec::new() };
+    if !seen.insert(name.to_string()) {
+        return Vec::new();
+    }
+    let mut fields: Vec<Field> = s.embeds.iter().flat_map(|e| all_fields(code, e, seen)).collect();
+    fields.retain(|f| !s.fields.iter().any(|own| own.json == f.json));
+    fields.extend(s.fields.iter().cloned());
+    fields
+}
+
+fn type_field(fields: &[Field]) -> Option<&Field> {
+    TYPE_FIELDS.iter().find_map(|name| fields.iter().find(|f| f.json == *name))
+}
+
+/// Values given to the type field of `event`: Go composite literals (`EventType: EventTypeUserCreated`)
+/// and assignments, Java builders and setters (`.eventType("USER_CREATED")`).
+fn event_types(code: &Code, event: &str, field: &Field) -> Vec<String> {
+    let mut types = Vec::new();
+    if let FieldType::Named(enum_name) = &field.ty {
+        types.extend(code.enums.get(enum_name).cloned().unwrap_or_default());
+    }
+    let resolve = |expr: &str| -> Option<String> {
+        let expr = expr.trim();
+        if let Some(value) = literal(expr) {
+            return Some(value);
+        }
+        let name = simple_name(ident_at(expr, true));
+        code.consts.get(name).cloned().or_else(|| {
+            (!name.is_empty() && name.chars().all(|c| c.is_ascii_uppercase() || c.is_ascii_digit() || c == '_')).then(|| name.to_string())
+        })
+    };
+    for (rel, content) in &code.files {
+        if rel.ends_with(".go") {
+            for at in word_positions(content, event) {
+                if let Some(open) = Some(at + event.len()).filter(|&o| content[o..].starts_with('{')) {
+                    let body = braced(content, open);
+                    for pos in word_positions(body, &field.code) {
+                        if let Some(expr) = body[pos + field.code.len()..].trim_start().strip_prefix(':') {
+                            types.extend(resolve(expr.split([',', '\n']).next().unwrap_or("")));
+                        }
+                    }
+                }
+            }
+            for (at, pattern) in content.match_indices(&format!(".{} = ", field.code)) {
+                types.extend(resolve(content[at + pattern.len()..].lines().next().unwrap_or("")));
+            }
+        } else {
+            let setter = format!("set{}{}(", field.code[..1].to_uppercase(), &field.code[1..]);
+            for pattern in [format!(".{}(", field.code), setter] {
+                for (at, _) in content.match_indices(&pattern) {
+                    types.extend(resolve(&content[at + pattern.len()..]));
+                }
+            }
+        }
+    }
+    let mut seen = BTreeSet::new();
+    types.retain(|t| seen.insert(t.clone()));
+    types
+}
+
+/// Lines (1-based) where a file configures a consumer: kafka-go's `ReaderConfig{...}`, Spring's
+/// `@KafkaListener` and the subscriptions of the other clients.
+fn consumer_lines(content: &str) -> BTreeSet<usize> {
+    let mut lines = BTreeSet::new();
+    for (at, pattern) in content.match_indices("ReaderConfig{") {
+        let open = at + pattern.len() - 1;
+        let end = open + braced(content, open).len() + 1;
+        lines.extend(line_of(content, at)..=line_of(content, end));
+    }
+    for (i, line) in content.lines().enumerate() {
+        if ["@KafkaListener", "KafkaConsumer(", "subscribe(", "SubscribeTopics("].iter().any(|p| line.contains(p)) {
+            lines.insert(i + 1);
+        }
+    }
+    lines
+}
+
+fn catalog(root: &Path, code: &Code) -> Vec<Event> {
+    let detected = crate::dev_kafka::detect_topics(root);
+    let mut events: BTreeMap<String, Event> = BTreeMap::new();
+    let new_event = |name: &str| Event {
+        name: name.to_string(),
+        source: code.structs[name].source.clone(),
+        producers: BTreeSet::new(),
+        topics: BTreeSet::new(),
+        key: None,
+        headers: BTreeSet::new(),
+        type_field: None,
+        types: Vec::new(),
+    };
+    for (rel, content) in code.files.iter().filter(|(_, c)| c.to_lowercase().contains("kafka")) {
+        let (payloads, headers, topics): (Vec<(String, Option<String>)>, Vec<String>, Vec<String>) = if rel.ends_with(".go") {
+            (go_payloads(content), go_headers(content), Vec::new())
+        } else {
+            let headers = JAVA_HEADER_PATTERNS
+                .iter()
+                .flat_map(|p| content.match_indices(p).map(|(at, _)| at + p.len()))
+                .filter_map(|at| literal(&content[at..]))
+                .collect();
+            (java_payloads(content).into_iter().map(|p| (p, None)).collect(), headers, java_topics(content, &code.properties))
+        };
+        // Topics named in the file, except the ones its consumers read
+        let consumers = consumer_lines(content);
+        let topics = topics.into_iter().chain(
+            detected
+                .iter()
+                .filter(|t| {
+                    let line = t.source.strip_prefix(rel.as_str()).and_then(|s| s.strip_prefix(':')).and_then(|l| l.parse::<usize>().ok());
+                    line.is_some_and(|l| !consumers.contains(&l))
+                })
+                .map(|t| t.name.clone()),
+        );
+        let topics: Vec<String> = topics.collect();
+        for (payload, var) in payloads.into_iter().filter(|(p, _)| code.structs.contains_key(p)) {
+            let event = events.entry(payload.clone()).or_insert_with(|| new_event(&payload));
+            event.producers.insert(rel.clone());
+            event.topics.extend(topics.iter().cloned());
+            event.headers.extend(headers.iter().cloned());
+            if let Some(field) = var.and_then(|v| go_key(content, &v)) {
+                event.key = all_fields(code, &payload, &mut BTreeSet::new()).into_iter().find(|f| f.code == field).map(|f| f.json);
+            }
+        }
+    }
+    // Payload types named like events, even when the producer is not recognized
+    if code.uses_kafka {
+        for name in code.structs.keys().filter(|n| n.ends_with("Event") && n.len() > 5) {
+            if !code.structs[name].fields.is_empty() || !code.structs[name].embeds.is_empty() {
+                events.entry(name.clone()).or_insert_with(|| new_event(name));
+            }
+        }
+    }
+    // A project with a single topic (dead-letter topics aside) sends everything there
+    let mut primary = detected.iter().filter(|t| !crate::dev_kafka::is_dead_letter(&t.name));
+    let single = match (primary.next(), primary.next()) {
+        (Some(only), None) => Some(only.name.clone()),
+        _ => None,
+    };
+    for event in events.values_mut() {
+        if event.topics.is_empty() {
+            event.topics.extend(single.clone());
+        }
+        let fields = all_fields(code, &event.name, &mut BTreeSet::new());
+        if let Some(field) = type_field(&fields) {
+            event.type_field = Some(field.json.clone());
+            event.types = event_types(code, &event.name, field);
+        }
+    }
+    events.into_values().collect()
+}
+
+impl Code {
+    /// The Go and Java types of the project under `root` (also used by `dx dev-routes openapi`).
+    pub(crate) fn load(root: &Path) -> Code {
+        read_code(root)
+    }
+
+    /// (relative path, content) of the Go files.
+    pub(crate) fn go_files(&self) -> impl Iterator<Item = (&str, &str)> {
+        self.files.iter().filter(|(rel, _)| rel.ends_with(".go")).map(|(rel, content)| (rel.as_str(), content.as_str()))
+    }
+
+    pub(crate) fn has_struct(&self, name: &str) -> bool {
+        self.structs.contains_key(name)
+    }
+
+    /// JSON Schema of the Go type `ty` (`*models.User`, `[]models.User`), with `$ref`s to `refs` +
+    /// name for the project's structs, which are added to `pending`.
+    pub(crate) fn type_schema(&self, ty: &str, refs: &str, pending: &mut BTreeSet<String>) -> Value {
+        schema_of(&go_type(ty), self, refs, pending)
+    }
+
+    /// JSON Schemas of the structs `names` and of every type they reference.
+    pub(crate) fn schemas(&self, names: &BTreeSet<String>, refs: &str) -> BTreeMap<String, Value> {
+        let mut pending = names.clone();
+        let mut schemas = BTreeMap::new();
+        while let Some(name) = pending.iter().find(|n| !schemas.contains_key(*n)).cloned() {
+            let schema = object_schema(self, &name, None, refs, &mut pending);
+            schemas.insert(name, Value::Object(schema));
+        }
+        schemas
+    }
+
+    /// Query parameters bound to the struct `name` (gin's `ShouldBindQuery`): (name, schema, required),
+    /// named after the `form` tag, or the field as gin does without one.
+    pub(crate) fn query_params(&self, name: &str, refs: &str) -> Vec<(String, Value, bool)> {
+        let mut pending = BTreeSet::new();
+        all_fields(self, name, &mut BTreeSet::new())
+            .into_iter()
+            .map(|field| {
+                let mut schema = schema_of(&field.ty, self, refs, &mut pending);
+                constrain(&mut schema, &field.rules);
+                (field.form.unwrap_or(field.code), schema, field.rules.iter().any(|r| r == "required"))
+            })
+            .collect()
+    }
+}
+
+/// The project's events and the code their payloads are read from (also used by
+/// `dx generate asyncapi`).
+pub(crate) struct Catalog {
+    code: Code,
+    pub events: Vec<Event>,
+}
+
+impl Catalog {
+    pub(crate) fn load(root: &Path) -> Catalog {
+        let code = read_code(root);
+        let events = catalog(root, &code);
+        Catalog { code, events }
+    }
+
+    /// Whether `name` is a payload type of the project.
+    pub(crate) fn has(&self, name: &str) -> bool {
+        self.code.structs.contains_key(name)
+    }
+
+    /// Payload types serialized by the files that mention one of `markers` (`amqp`, `rabbit`), as
+    /// (file, type) pairs.
+    pub(crate) fn payloads(&self, markers: &[&str]) -> Vec<(String, String)> {
+        let mut payloads = Vec::new();
+        for (rel, content) in &self.code.files {
+            let lower = content.to_lowercase();
+            if !markers.iter().any(|m| lower.contains(m)) {
+                continue;
+            }
+            let types: Vec<String> = match rel.ends_with(".go") {
+                true => go_payloads(content).into_iter().map(|(ty, _)| ty).collect(),
+                false => java_sent_types(content),
+            };
+            for ty in types.into_iter().filter(|t| self.code.structs.contains_key(t)) {
+                if !payloads.iter().any(|(r, t)| r == rel && *t == ty) {
+                    payloads.push((rel.clone(), ty));
+                }
+            }
+        }
+        payloads
+    }
+
+    /// JSON Schemas of the payload types `names` and of every type they reference, with `$ref`s
+    /// to `refs` + name. Event type fields are restricted to the types found in the code.
+    pub(crate) fn schemas(&self, names: &BTreeSet<String>, refs: &str) -> BTreeMap<String, Value> {
+        let mut pending = names.clone();
+        let mut schemas = BTreeMap::new();
+        while let Some(name) = pending.iter().find(|n| !schemas.contains_key(*n)).cloned() {
+            let event = self.events.iter().find(|e| e.name == name);
+            let types = event.and_then(|e| e.type_field.as_deref().map(|f| (f, e.types.as_slice())));
+            let schema = object_schema(&self.code, &name, types, refs, &mut pending);
+            schemas.insert(name, Value::Object(schema));
+        }
+        schemas
+    }
+}
+
+// ---------------------------------------------------------------------------------------------
+// Output
+// ---------------------------------------------------------------------------------------------
+
+fn schema_of(ty: &FieldType, code: &Code, refs: &str, defs: &mut BTreeSet<String>) -> Value {
+    match ty {
+        FieldType::String(Some(format)) if *format == "byte" => json!({"type": "string", "contentEncoding": "base64"}),
+        FieldType::String(Some(format)) => json!({"type": "string", "format": format}),
+        FieldType::String(None) => json!({"type": "string"}),
+        FieldType::Integer => json!({"type": "integer"}),
+        FieldType::Number => json!({"type": "number"}),
+        FieldType::Boolean => json!({"type": "boolean"}),
+        FieldType::Array(item) => json!({"type": "array", "items": schema_of(item, code, refs, defs)}),
+        FieldType::Map(value) => json!({"type": "object", "additionalProperties": schema_of(value, code, refs, defs)}),
+        FieldType::Named(name) if code.structs.contains_key(name) => {
+            defs.insert(name.clone());
+            json!({"$ref": format!("{}{}", refs, name)})
+        }
+        FieldType::Named(name) => match code.enums.get(name) {
+            Some(values) => json!({"type": "string", "enum": values}),
+            None => json!({}),
+        },
+        FieldType::Any => json!({}),
+    }
+}
+
+/// Add the JSON Schema keywords of go-playground validator rules (`min=3`, `email`, `oneof=a b`)
+/// to a field's schema. Lengths apply to strings and arrays, bounds to numbers.
+fn constrain(schema: &mut Value, rules: &[String]) {
+    let Some(object) = schema.as_object_mut() else { return };
+    let ty = object.get("type").and_then(Value::as_str).unwrap_or("").to_string();
+    let numeric = ty == "integer" || ty == "number";
+    for rule in rules {
+        let (name, arg) = rule.split_once('=').unwrap_or((rule.as_str(), ""));
+        let number = arg.parse::<i64>().ok().map(Value::from).or_else(|| arg.parse::<f64>().ok().map(Value::from));
+        let keyword = match (name, ty.as_str()) {
+            ("email", _) => Some(("format", json!("email"))),
+            ("url" | "uri", _) => Some(("format", json!("uri"))),
+            ("uuid" | "uuid4", _) => Some(("format", json!("uuid"))),
+            ("oneof", _) => Some(("enum", arg.split_whitespace().map(|v| if numeric { v.parse::<i64>().map(Value::from).unwrap_or(json!(v)) } else { json!(v) }).collect())),
+            ("min" | "gte", "string") => number.map(|n| ("minLength", n)),
+            ("max" | "lte", "string") => number.map(|n| ("maxLength", n)),
+            ("min" | "gte", "array") => number.map(|n| ("minItems", n)),
+            ("max" | "lte", "array") => number.map(|n| ("maxItems", n)),
+            ("min" | "gte", _) if numeric => number.map(|n| ("minimum", n)),
+            ("max" | "lte", _) if numeric => number.map(|n| ("maximum", n)),
+            ("gt", _) if numeric => number.map(|n| ("exclusiveMinimum", n)),
+            ("lt", _) if numeric => number.map(|n| ("exclusiveMaximum", n)),
+            _ => None,
+        };
+        if let Some((key, value)) = keyword {
+            object.insert(key.to_string(), value);
+        }
+    }
+}
+
+fn object_schema(code: &Code, name: &str, types: Option<(&str, &[String])>, refs: &str, defs: &mut BTreeSet<String>) -> Map<String, Value> {
+    let mut properties = Map::new();
+    let mut required = Vec::new();
+    for field in all_fields(code, name, &mut BTreeSet::new()) {
+        let mut schema = schema_of(&field.ty, code, refs, defs);
+        if let Some((_, values)) = types.filter(|(f, v)| *f == field.json && !v.is_empty()) {
+            schema = json!({"type": "string", "enum": values});
+        }
+        constrain(&mut schema, &field.rules);
+        if field.required {
+            required.push(Value::String(field.json.clone()));
+        }
+        properties.insert(field.json, schema);
+    }
+    let mut schema = Map::new();
+    schema.insert("type".into(), json!("object"));
+    schema.insert("properties".into(), Value::Object(properties));
+    if !required.is_empty() {
+        schema.insert("required".into(), Value::Array(required));
+    }
+    schema
+}
+
+/// JSON Schema (draft 2020-12) of an event's payload, with the referenced types under `$defs`.
+fn event_schema(code: &Code, event: &Event) -> Value {
+    let mut defs = BTreeSet::new();
+    let types = event.type_field.as_deref().map(|f| (f, event.types.as_slice()));
+    let body = object_schema(code, &event.name, types, "#/$defs/", &mut defs);
+    let mut schema = Map::new();
+    schema.insert("$schema".into(), json!("https://json-schema.org/draft/2020-12/schema"));
+    schema.insert("$id".into(), json!(format!("{}.schema.json", event.name)));
+    schema.insert("title".into(), json!(event.name));
+    if !event.topics.is_empty() {
+        let topics: Vec<&str> = event.topics.iter().map(String::as_str).collect();
+        schema.insert("description".into(), json!(format!("Evento publicado em {}", topics.join(", "))));
+    }
+    schema.extend(body);
+    let mut definitions = Map::new();
+    let mut done = BTreeSet::new();
+    while let Some(name) = defs.iter().find(|d| !done.contains(*d)).cloned() {
+        done.insert(name.clone());
+        definitions.insert(name.clone(), Value::Object(object_schema(code, &name, None, "#/$defs/", &mut defs)));
+    }
+    if !definitions.is_empty() {
+        schema.insert("$defs".into(), Value::Object(definitions));
+    }
+    Value::Object(schema)
+}
+
+fn type_label(ty: &FieldType, code: &Code) -> String {
+    match ty {
+        FieldType::String(Some(format)) => format!("string ({})", format),
+        FieldType::String(None) => "string".into(),
+        FieldType::Integer => "integer".into(),
+        FieldType::Number => "number".into(),
+        FieldType::Boolean => "boolean".into(),
+        FieldType::Array(item) => format!("array<{}>", type_label(item, code)),
+        FieldType::Map(value) => format!("map<string, {}>", type_label(value, code)),
+        FieldType::Named(name) if code.structs.contains_key(name) => format!("[{}](#{})", name, name.to_lowercase()),
+        FieldType::Named(name) if code.enums.contains_key(name) => format!("string ({})", name),
+        FieldType::Named(_) | FieldType::Any => "any".into(),
+    }
+}
+
+fn code_list(values: impl IntoIterator<Item = impl AsRef<str>>) -> String {
+    values.into_iter().map(|v| format!("`{}`", v.as_ref())).collect::<Vec<_>>().join(", ")
+}
+
+fn field_table(out: &mut String, code: &Code, fields: &[Field]) {
+    out.push_str("| Campo | Tipo | Obrigatório |\n|---|---|---|\n");
+    for field in fields {
+        let required = if field.required { "sim" } else { "não" };
+        out.push_str(&format!("| `{}` | {} | {} |\n", field.json, type_label(&field.ty, code), required));
+    }
+}
+
+fn render_markdown(code: &Code, events: &[Event]) -> String {
+    let mut out = String::from("# Catálogo de eventos\n\nGerado por `dx dev-kafka events` a partir do código do projeto.\n\n");
+    out.push_str("| Evento | Tópicos | Tipos |\n|---|---|---|\n");
+    for event in events {
+        let topics = if event.topics.is_empty() { "—".to_string() } else { code_list(&event.topics) };
+        let types = if event.types.is_empty() { "—".to_string() } else { code_list(&event.types) };
+        out.push_str(&format!("| [{}](#{}) | {} | {} |\n", event.name, event.name.to_lowercase(), topics, types));
+    }
+    let mut referenced = BTreeSet::new();
+    for event in events {
+        out.push_str(&format!("\n## {}\n\n", event.name));
+        out.push_str(&format!("- Definido em: `{}`\n", event.source));
+        if !event.producers.is_empty() {
+            out.push_str(&format!("- Publicado em: {}\n", code_list(&event.producers)));
+        }
+        match event.topics.is_empty() {
+            true => out.push_str("- Tópicos: não identificados\n"),
+            false => out.push_str(&format!("- Tópicos: {}\n", code_list(&event.topics))),
+        }
+        if let Some(key) = &event.key {
+            out.push_str(&format!("- Chave: `{}`\n", key));
+        }
+        if !event.headers.is_empty() {
+            out.push_str(&format!("- Headers: {}\n", code_list(&event.headers)));
+        }
+        if let (Some(field), false) = (&event.type_field, event.types.is_empty()) {
+            out.push_str(&format!("- Tipos (`{}`): {}\n", field, code_list(&event.types)));
+        }
+        out.push('\n');
+        let fields = all_fields(code, &event.name, &mut BTreeSet::new());
+        field_table(&mut out, code, &fields);
+        event_schema(code, event).get("$defs").and_then(Value::as_object).inspect(|defs| referenced.extend(defs.keys().cloned()));
+    }
+    for name in referenced.iter().filter(|n| !events.iter().any(|e| &e.name == *n)) {
+        out.push_str(&format!("\n## {}\n\n- Definido em: `{}`\n\n", name, code.structs[name].source));
+        field_table(&mut out, code, &all_fields(code, name, &mut BTreeSet::new()));
+    }
+    out
+}
+
+fn render_json(code: &Code, events: &[Event]) -> Value {
+    let events: Vec<Value> = events
+        .iter()
+        .map(|e| {
+            json!({
+                "name": e.name,
+                "source": e.source,
+                "producers": e.producers,
+                "topics": e.topics,
+                "key": e.key,
+                "headers": e.headers,
+                "type_field": e.type_field,
+                "types": e.types,
+                "schema": event_schema(code, e),
+            })
+        })
+        .collect();
+    json!({ "events": events })
+}
+
+/// `dx dev-kafka events`: print (or write to `out`) the catalog of the events the project publishes
+/// and, with `schemas`, write a JSON Schema per event into that directory.
+pub fn cmd_events(dir: Option<PathBuf>, format: EventsFormat, out: Option<PathBuf>, schemas: Option<PathBuf>) -> i32 {
+    let root = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Catalog { code, events } = Catalog::load(&root);
+    if events.is_empty() {
+        eprintln!("Nenhum evento Kafka encontrado em {}.", root.display());
+        eprintln!("dx procura os tipos serializados pelos produtores (json.Marshal, KafkaTemplate<...>) e structs/classes *Event.");
+        return 1;
+    }
+    let document = match format {
+        EventsFormat::Markdown => render_markdown(&code, &events),
+        EventsFormat::Json => serde_json::to_string_pretty(&render_json(&code, &events)).unwrap_or_default() + "\n",
+    };
+    let shown = |path: &Path| path.strip_prefix(&root).unwrap_or(path).display().to_string();
+    let resolve = |path: PathBuf| if path.is_absolute() { path } else { root.join(path) };
+    match out.map(resolve) {
+        Some(path) => {
+            let written = path.parent().map_or(Ok(()), fs::create_dir_all).and_then(|_| crate::audit::write(&path, &document));
+            if let Err(e) = written {
+                eprintln!("Erro ao escrever {}: {}", path.display(), e);
+                return 1;
+            }
+            println!("Catálogo com {} evento(s) em {}", events.len(), shown(&path));
+        }
+        None => print!("{}", document),
+    }
+    if let Some(schema_dir) = schemas.map(resolve) {
+        for event in &events {
+            let path = schema_dir.join(format!("{}.schema.json", event.name));
+            let content = serde_json::to_string_pretty(&event_schema(&code, event)).unwrap_or_default() + "\n";
+            if fs::read_to_string(&path).ok().as_deref() == Some(content.as_str()) {
+                eprintln!("  = {}", shown(&path));
+                continue;
+            }
+            if let Err(e) = fs::create_dir_all(&schema_dir).and_then(|_| crate::audit::write(&path, &content)) {
+                eprintln!("Erro ao escrever {}: {}", path.display(), e);
+                return 1;
+            }
+            eprintln!("  + {}", shown(&path));
+        }
+    }
+    0
+}
+
+/// A cataloged event, for `dx dev-kafka produce --event` to build test messages from.
+pub(crate) struct EventSample {
+    /// Payload template: JSON with `{{uuid}}`, `{{i}}`, `{{now}}` and `{{date}}` placeholders
+    pub value: String,
+    /// Payload field used as the message key
+    pub key_field: Option<String>,
+    /// Header carrying the event type, with the type's value
+    pub type_header: Option<(String, String)>,
+}
+
+/// Placeholder of numeric fields; a string in the JSON value, unquoted in the template.
+const NUMBER_PLACEHOLDER: &str = "{{i}}";
+
+fn sample_value(ty: &FieldType, name: &str, code: &Code, depth: usize) -> Value {
+    match ty {
+        FieldType::String(Some("date-time")) => json!("{{now}}"),
+        FieldType::String(Some("date")) => json!("{{date}}"),
+        FieldType::String(Some(_)) => json!(""),
+        FieldType::String(None) => {
+            let lower = name.to_lowercase();
+            if lower.ends_with("id") {
+                json!("{{uuid}}")
+            } else if lower.contains("email") {
+                json!("user{{i}}@example.com")
+            } else {
+                json!(format!("{}-{{{{i}}}}", name))
+            }
+        }
+        FieldType::Integer | FieldType::Number => json!(NUMBER_PLACEHOLDER),
+        FieldType::Boolean => json!(false),
+        FieldType::Array(item) => match sample_value(item, name, code, depth + 1) {
+            Value::Null => json!([]),
+            value => json!([value]),
+        },
+        FieldType::Map(_) => json!({}),
+        FieldType::Named(other) if code.structs.contains_key(other) && depth < 4 => {
+            let fields = all_fields(code, other, &mut BTreeSet::new());
+            Value::Object(fields.iter().map(|f| (f.json.clone(), sample_value(&f.ty, &f.json, code, depth + 1))).collect())
+        }
+        FieldType::Named(other) => code.enums.get(other).and_then(|values| values.first()).map_or(Value::Null, |v| json!(v)),
+        FieldType::Any => Value::Null,
+    }
+}
+
+/// A sample payload of the event `name` of the project at `root`, typed `event_type` (or the
+/// first type found in the code).
+pub(crate) fn sample(root: &Path, name: &str, event_type: Option<&str>) -> Result<EventSample, String> {
+    let Catalog { code, events } = Catalog::load(root);
+    let names: Vec<&str> = events.iter().map(|e| e.name.as_str()).collect();
+    let Some(event) = events.iter().find(|e| e.name == name) else {
+        return Err(match names.is_empty() {
+            true => format!("Nenhum evento Kafka encontrado em {}.", root.display()),
+            false => format!("Evento '{}' não encontrado no código (eventos: {}).", name, names.join(", ")),
+        });
+    };
+    let chosen = match (&event.type_field, event_type) {
+        (None, Some(_)) => return Err(format!("O evento {} não tem campo de tipo ({}).", name, TYPE_FIELDS.join(", "))),
+        (Some(_), Some(t)) if !event.types.is_empty() && !event.types.iter().any(|known| known == t) => {
+            return Err(format!("Tipo '{}' desconhecido para {} (tipos: {}).", t, name, event.types.join(", ")));
+        }
+        (Some(_), Some(t)) => Some(t.to_string()),
+        (Some(_), None) => event.types.first().cloned(),
+        (None, None) => None,
+    };
+    let mut payload = Map::new();
+    for field in all_fields(&code, name, &mut BTreeSet::new()) {
+        let value = match &chosen {
+            Some(t) if event.type_field.as_deref() == Some(field.json.as_str()) => json!(t),
+            _ => sample_value(&field.ty, &field.json, &code, 0),
+        };
+        payload.insert(field.json, value);
+    }
+    let value = Value::Object(payload).to_string().replace(&format!("\"{}\"", NUMBER_PLACEHOLDER), NUMBER_PLACEHOLDER);
+    let type_header = match (&event.type_field, chosen) {
+        (Some(field), Some(t)) if event.headers.contains(field) => Some((field.clone(), t)),
+        _ => None,
+    };
+    Ok(EventSample { value, key_field: event.key.clone(), type_header })
+}
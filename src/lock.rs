@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::fs::{self, File, TryLockError};
+use std::io;
+use std::path::{Path, PathBuf};
+use std::time::{Duration, Instant};
+
+const POLL: Duration = Duration::from_millis(100);
+
+/// Exclusive lock shared with other dx processes (an editor plugin and a terminal, say),
+/// released when dropped.
+#[derive(Debug)]
+pub struct Guard {
+    _file: Option<File>,
+}
+
+/// `<path>.lock`, the lock file guarding `path`.
+fn lock_path(path: &Path) -> PathBuf {
+    let mut name = path.file_name().map(|n| n.to_os_string()).unwrap_or_default();
+    name.push(".lock");
+    path.with_file_name(name)
+}
+
+/// Wait for the lock file `path`, telling the user once when another dx holds it. Gives up
+/// after the `lock_timeout` setting. Filesystems without locking are used unlocked.
+pub fn acquire(path: &Path, what: &str) -> io::Result<Guard> {
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    let file = fs::OpenOptions::new().create(true).truncate(false).write(true).open(path)?;
+    let timeout = Duration::from_secs(crate::settings::get_u64("lock_timeout").unwrap_or(120));
+    let started = Instant::now();
+    let mut announced = false;
+    loop {
+        match file.try_lock() {
+            Ok(()) => return Ok(Guard { _file: Some(file) }),
+            Err(TryLockError::Error(e)) if e.kind() == io::ErrorKind::Unsupported => return Ok(Guard { _file: None }),
+            Err(TryLockError::Error(e)) => return Err(e),
+            Err(TryLockError::WouldBlock) if started.elapsed() >= timeout => {
+                return Err(io::Error::new(
+                    io::ErrorKind::TimedOut,
+                    format!("{} em uso por outro dx há mais de {}s ({})", what, timeout.as_secs(), path.display()),
+                ));
+            }
+            Err(TryLockError::WouldBlock) => {
+                if !announced {
+                    eprintln!("Aguardando outro dx terminar ({})...", what);
+                    announced = true;
+                }
+                std::thread::sleep(POLL);
+            }
+        }
+    }
+}
+
+/// Lock `<path>.lock` for a read-modify-write of `path`.
+pub fn for_file(path: &Path) -> io::Result<Guard> {
+    let what = path.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_default();
+    acquire(&lock_path(path), &what)
+}
+
+/// `for_file` for commands: exits with an error when the lock cannot be taken.
+pub fn for_file_or_exit(path: &Path) -> Guard {
+    for_file(path).unwrap_or_else(|e| {
+        eprintln!("Erro: {}", e);
+        crate::exit(1);
+    })
+}
+
+/// Replace `path` in one step (temporary file + rename), so readers never see half a file.
+pub fn write_atomic(path: &Path, contents: impl AsRef<[u8]>) -> io::Result<()> {
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    let mut name = path.file_name().map(|n| n.to_os_string()).unwrap_or_default();
+    name.push(format!(".{}.tmp", std::process::id()));
+    let tmp = path.with_file_name(name);
+    fs::write(&tmp, contents)?;
+    fs::rename(&tmp, path).inspect_err(|_| {
+        let _ = fs::remove_file(&tmp);
+    })
+}
@@ -1,6 +1,6 @@
 // SPDX-License-Identifier: MIT OR Apache-2.0
 // Copyright (c) 2025 The dx-cli Contributors
-use clap::{Parser, Subcommand};
+use clap::{CommandFactory, Parser, Subcommand};
 
 #[derive(Parser)]
 #[command(
@@ -8,9 +8,25 @@ use clap::{Parser, Subcommand};
     version,
     about = "DX em qualquer stack",
     long_about = "Projeto: DX em qualquer stack\n\nObjetivo: criar um conjunto de padrões, toolkits e automações que reduzam atrito do primeiro commit ao deploy — em qualquer stack — com IA promovendo ciclos de feedback curtos e decisões melhores.\n\nPilares (com IA embutida):\n- Ambiente instantâneo (\"Dev Services\" universais)\n- Dev UI portátil (portal do dev)\n- Testes Contínuos & Inteligentes\n- Configuração sem dor\n- Docs vivas + Q&A no código\n- Governança leve, guardrails fortes\n- Telemetria e feedback loops curtos",
-    arg_required_else_help = true
+    arg_required_else_help = true,
+    allow_external_subcommands = true
 )]
 struct Cli {
+    /// Notifica (desktop + sino do terminal) quando o comando demorar mais que SEGUNDOS (padrão: configuração notify_after)
+    #[arg(long, global = true, value_name = "SEGUNDOS")]
+    notify_after: Option<u64>,
+    /// Formato do progresso: `json` emite eventos NDJSON no stderr (ou no descritor de DX_PROGRESS_FD) (padrão: configuração progress)
+    #[arg(long, global = true, value_enum, value_name = "MODO")]
+    progress: Option<progress::ProgressMode>,
+    /// Saída dos comandos: `json` para scripts e outras ferramentas (equivale a `--format json` nos comandos que o têm); `sarif` para GitHub code scanning nos comandos de auditoria (JSON nos demais) (padrão: configuração output)
+    #[arg(long, global = true, value_enum, value_name = "MODO")]
+    output: Option<output::OutputMode>,
+    /// Envia os relatórios (audit, drift, analyzer) também para este destino: s3://, gs://, http(s):// ou mongodb:// (repetível; padrão: configuração report_sinks)
+    #[arg(long = "sink", global = true, value_name = "URL")]
+    sinks: Vec<String>,
+    /// Não consulta os registries de pacotes: usa só as respostas guardadas (padrão: configuração offline)
+    #[arg(long, global = true)]
+    offline: bool,
     #[command(subcommand)]
     command: Commands,
 }
@@ -36,11 +52,22 @@ enum Commands {
         /// Não salva no README (apenas imprime as badges). Por padrão, salva. Apenas para a ação de aplicar.
         #[arg(long, default_value_t = false)]
         no_save: bool,
+        /// Monorepo: sincroniza também os READMEs dos pacotes (subdiretórios com go.mod, package.json, Cargo.toml...)
+        #[arg(long)]
+        recursive: bool,
         /// Diretório alvo (padrão: diretório atual). Para `clean`, também pode ser informado após o subcomando.
         dir: Option<std::path::PathBuf>,
     },
+    /// Gera seções do README.md a partir da análise do projeto (ex.: `dx dev-readme generate`)
+    DevReadme {
+        #[command(subcommand)]
+        action: DevReadmeAction,
+    },
     /// Executa testes unitários continuamente ao detectar mudanças nos arquivos
     DevTest {
+        /// Ação opcional (ex.: `smoke`). Se omitida, monitora os arquivos e roda os testes.
+        #[command(subcommand)]
+        action: Option<DevTestAction>,
         /// Diretório raiz do projeto a ser monitorado (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
     },
@@ -60,12 +87,260 @@ enum Commands {
         /// Diretório raiz do projeto (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
     },
+    /// Documenta as variáveis de ambiente lidas pelo código
+    DevEnv {
+        #[command(subcommand)]
+        action: DevEnvAction,
+    },
+    /// Procura credenciais no código e nos .env (tokens, chaves, senhas em strings de conexão)
+    DevSecrets {
+        #[command(subcommand)]
+        action: DevSecretsAction,
+    },
+    /// Lista as rotas HTTP registradas no código (gin, echo, chi, fiber, net/http, Express, FastAPI, Flask)
+    DevRoutes {
+        #[command(subcommand)]
+        action: DevRoutesAction,
+    },
+    /// Detecta a infraestrutura (bancos, filas, caches) usada pelo código do projeto
+    DevInfra {
+        #[command(subcommand)]
+        action: DevInfraAction,
+    },
+    /// Utilitários para o Kafka local (tópicos e mensagens do broker em KAFKA_BROKERS)
+    DevKafka {
+        #[command(subcommand)]
+        action: DevKafkaAction,
+    },
+    /// Utilitários para o banco de dados local (MONGODB_URI, DATABASE_URL, REDIS_URL)
+    DevDb {
+        #[command(subcommand)]
+        action: DevDbAction,
+    },
+    /// Verifica a máquina de desenvolvimento: runtimes, daemon do Docker, portas livres e .env
+    DevDoctor {
+        /// Porta adicional que precisa estar livre (repetível)
+        #[arg(long = "port", value_name = "PORTA")]
+        ports: Vec<u16>,
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_doctor::DoctorFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Sobe a infraestrutura (Dev Services), espera ficar saudável e inicia a aplicação com as variáveis de conexão
+    Up {
+        /// Ativa um profile do compose (repetível), como em `dx dev-services run`
+        #[arg(long = "profile", value_name = "NOME")]
+        profiles: Vec<String>,
+        /// Tempo máximo para os serviços ficarem prontos
+        #[arg(long, default_value_t = startup_profile::DEFAULT_TIMEOUT_SECS, value_name = "SEGUNDOS")]
+        timeout: u64,
+        /// Mostra só a saída da aplicação, sem os logs dos containers
+        #[arg(long)]
+        no_logs: bool,
+        /// Reinicia a aplicação (refazendo o build) quando arquivos Go, JavaScript/TypeScript ou Python mudam; os containers continuam no ar
+        #[arg(long)]
+        watch: bool,
+        /// Milissegundos sem novas alterações antes de reiniciar (padrão: configuração watch_debounce)
+        #[arg(long, value_name = "MS", requires = "watch")]
+        debounce: Option<u64>,
+        /// Ignora arquivos no --watch, ex.: '*_test.go' ou 'gen/' (repetível; padrão: configuração watch_ignore)
+        #[arg(long = "ignore", value_name = "PADRÃO", requires = "watch")]
+        ignore: Vec<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+        /// Comando que inicia a aplicação (depois de --); padrão: tarefa dev/start do dx.yaml ou o da stack
+        #[arg(last = true)]
+        command: Vec<String>,
+    },
+    /// Encerra os Dev Services do projeto (stop); --networks e --volumes também removem redes e volumes
+    Down {
+        /// Remove os containers e as redes (docker compose down), mantendo os volumes
+        #[arg(long)]
+        networks: bool,
+        /// Remove também os volumes: os dados dos bancos são apagados
+        #[arg(long)]
+        volumes: bool,
+        /// Em vez do projeto atual, remove (com volumes) os ambientes sem `dx up` há --older-than dias ou cujo diretório não existe mais
+        #[arg(long, conflicts_with_all = ["networks", "volumes", "dir"])]
+        prune: bool,
+        /// Dias sem uso para --prune considerar um ambiente antigo
+        #[arg(long, default_value_t = down::DEFAULT_PRUNE_DAYS, value_name = "DIAS", requires = "prune")]
+        older_than: u64,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Ambientes de preview efêmeros: a aplicação e seus serviços num cluster kind local ou num
+    /// namespace de um cluster remoto, com URL para compartilhar e remoção automática (TTL)
+    Preview {
+        #[command(subcommand)]
+        action: PreviewAction,
+    },
+    /// Ambientes isolados de cada projeto (containers, redes e volumes) criados pelo dx nesta máquina
+    Env {
+        #[command(subcommand)]
+        action: EnvAction,
+    },
+    /// Executa uma tarefa do dx.yaml (ou alvo do Makefile); sem argumentos, lista as tarefas
+    Run {
+        /// Nome da tarefa (opcional). Se omitido, lista as tarefas disponíveis.
+        task: Option<String>,
+        /// Mostra a ordem de execução e as dependências (depends_on) sem executar
+        #[arg(long)]
+        graph: bool,
+        /// Executa os comandos isolados: sem rede e com escrita apenas no projeto e em /tmp
+        #[arg(long)]
+        sandbox: bool,
+        /// Executa a tarefa de novo (interrompendo a anterior) quando arquivos Go, JavaScript/TypeScript ou Python mudam
+        #[arg(long, requires = "task", conflicts_with = "graph")]
+        watch: bool,
+        /// Milissegundos sem novas alterações antes de reiniciar (padrão: configuração watch_debounce)
+        #[arg(long, value_name = "MS", requires = "watch")]
+        debounce: Option<u64>,
+        /// Ignora arquivos no --watch, ex.: '*_test.go' ou 'gen/' (repetível; padrão: configuração watch_ignore)
+        #[arg(long = "ignore", value_name = "PADRÃO", requires = "watch")]
+        ignore: Vec<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Autoriza a execução dos scripts do dx.yaml deste projeto (pedido de novo se o arquivo mudar)
+    Allow {
+        /// Autoriza apenas dentro do sandbox (sem rede, escrita restrita ao projeto)
+        #[arg(long)]
+        sandbox: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Bloqueia a execução dos scripts do dx.yaml deste projeto
+    Deny {
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Migra automações existentes para o dx.yaml (ex.: `dx migrate makefile`)
+    Migrate {
+        #[command(subcommand)]
+        action: MigrateAction,
+    },
+    /// Verificações dos hooks do git instalados por `dx dev-config hooks` (ex.: `dx hooks run pre-commit`)
+    Hooks {
+        #[command(subcommand)]
+        action: HooksAction,
+    },
+    /// Lista os últimos comandos dx executados neste diretório (.dx/history.jsonl)
+    History {
+        /// Quantidade de comandos exibidos
+        #[arg(long, default_value_t = 20)]
+        limit: usize,
+        /// Apaga o histórico deste diretório
+        #[arg(long)]
+        clear: bool,
+    },
+    /// Executa novamente um comando do histórico (padrão: o último)
+    Rerun {
+        /// Número exibido por `dx history` ou trecho do comando (ex.: `run test`)
+        command: Option<String>,
+        /// Apenas mostra o comando, sem executar
+        #[arg(long)]
+        print: bool,
+    },
+    /// Lista as alterações feitas pelo dx (arquivos e containers), com diffs
+    Audit {
+        /// Quantidade de comandos exibidos
+        #[arg(long, default_value_t = 10)]
+        limit: usize,
+        /// Mostra o diff de cada arquivo alterado
+        #[arg(long)]
+        diff: bool,
+    },
+    /// Desfaz as alterações de arquivos de uma execução (padrão: a última que gerou arquivos neste diretório)
+    Undo {
+        /// Id da execução (ou o início dele), como mostrado por `dx audit`
+        run: Option<String>,
+        /// Apenas mostra o que seria desfeito
+        #[arg(long)]
+        dry_run: bool,
+        /// Desfaz mesmo arquivos alterados depois do comando
+        #[arg(long)]
+        force: bool,
+    },
+    /// Estado do projeto para o prompt do shell (starship, powerlevel10k): projeto, serviços no ar, profiles
+    Prompt {
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<prompt::PromptFormat>,
+        /// Reaproveita a contagem de serviços por até N segundos (consultar o Docker é lento para um prompt)
+        #[arg(long, default_value_t = prompt::DEFAULT_MAX_AGE_SECS, value_name = "SEGUNDOS")]
+        max_age: u64,
+        /// Diretório de partida (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Propõe código (como diff) para instrumentar a aplicação: rota de saúde, OpenTelemetry e carregamento da configuração
+    Instrument {
+        #[command(subcommand)]
+        action: InstrumentAction,
+    },
+    /// Imprime os comandos para compilar, testar e rodar o projeto com os Dev Services, montados a partir do que o dx detecta
+    Howto {
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<howto::HowtoFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Painel no terminal: serviços, estado dos containers, portas, saúde das dependências e logs; r reinicia, s abre um shell
+    Dashboard {
+        /// Imprime uma única tela (ou o estado em JSON com --output json) e sai
+        #[arg(long)]
+        once: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Compara o que o dx detecta em dois projetos: stack, dependências, variáveis de ambiente e Dev Services
+    Compare {
+        /// Primeiro projeto (ex.: o template de referência)
+        a: std::path::PathBuf,
+        /// Segundo projeto
+        b: std::path::PathBuf,
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<compare::CompareFormat>,
+    },
+    /// Compara o projeto com padrões da organização (ex.: `dx benchmark archetype`, um golden path do perfil da equipe)
+    Benchmark {
+        #[command(subcommand)]
+        action: BenchmarkAction,
+    },
+    /// Gera código novo no projeto (ex.: `dx generate service` em um monorepo, `dx generate client` de uma API)
+    Generate {
+        #[command(subcommand)]
+        action: GenerateAction,
+    },
+    /// Templates de projeto: cria a partir de um template, mostra o desvio e aplica as mudanças dele
+    Template {
+        #[command(subcommand)]
+        action: TemplateAction,
+    },
+    /// Plugins: executáveis dx-<nome> no PATH e manifestos do usuário ou do dx.yaml (comandos, detectores e geradores)
+    Plugins {
+        /// Ação opcional; se omitida, lista os plugins
+        #[command(subcommand)]
+        action: Option<PluginsAction>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Comandos e aliases definidos em `commands:`/`aliases:` do dx.yaml, e plugins
+    #[command(external_subcommand)]
+    Custom(Vec<String>),
     /// Portal/plug-in do desenvolvedor (Dev UI)
     Portal,
     /// Testes contínuos e inteligentes (geração/execução)
     Tests,
-    /// Configuração tipada com wizards em linguagem natural
-    Config,
+    /// Configurações do usuário (diretórios XDG); exporta/importa entre máquinas
+    Config {
+        #[command(subcommand)]
+        action: Option<ConfigAction>,
+    },
     /// Documentação viva e Q&A no código
     Docs,
     /// Governança leve com guardrails
@@ -75,157 +350,1323 @@ enum Commands {
         /// Diretório raiz a partir do qual limpar .dx (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
     },
-    /// Analisa o projeto e resume o que o dx-cli aplicaria (todas as capabilities)
-    #[command(alias = "test-stacks", hide = true)]
-    #[command(alias = "doctor", hide = true)]
-    Analyzer {
-        /// Não salva o relatório (por padrão, o relatório é salvo)
+    /// Perfil da equipe referenciado em .dx/config.yaml (registries aprovados, licenças, badges, serviços, configurações)
+    Team {
+        #[command(subcommand)]
+        action: TeamAction,
+    },
+    /// Cache de detecção entre execuções (dependências por projeto, chaveadas pelos manifestos e lockfiles)
+    Cache {
+        #[command(subcommand)]
+        action: CacheAction,
+    },
+    /// Regras de detecção versionadas (serviços, clientes Go, frameworks): lista, atualiza e fixa no dx.lock
+    Rules {
+        #[command(subcommand)]
+        action: RulesAction,
+    },
+    /// Analisa o projeto e resume o que o dx-cli aplicaria (todas as capabilities)
+    #[command(alias = "test-stacks", hide = true)]
+    #[command(alias = "doctor", hide = true)]
+    Analyzer {
+        /// Não salva o relatório (por padrão, o relatório é salvo)
+        #[arg(long)]
+        no_save: bool,
+        /// Caminho para salvar o relatório (padrão: analyzer-report.md)
+        #[arg(long, default_value = "analyzer-report.md")]
+        report_path: String,
+        /// Projetos analisados em paralelo quando o diretório tem vários (padrão: configuração scan_concurrency; 0 = um por CPU)
+        #[arg(long, value_name = "N")]
+        concurrency: Option<u64>,
+        /// Detecta todos os projetos de novo, sem usar nem gravar o cache de detecção
+        #[arg(long)]
+        no_cache: bool,
+        /// Diretório do projeto a ser analisado (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevReadmeAction {
+    /// Escreve no README.md a seção "Primeiros passos" (pré-requisitos, como rodar, variáveis de ambiente e rotas) entre marcadores
+    Generate {
+        /// Não salva (apenas imprime a seção)
+        #[arg(long)]
+        no_save: bool,
+        /// Apenas verifica se a seção do README.md está em dia com o projeto (sai com 1 se não estiver)
+        #[arg(long)]
+        check: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevBadgesAction {
+    /// Limpa os badges do README.md entre os marcadores padrão
+    Clean {
+        /// Monorepo: limpa também os READMEs dos pacotes
+        #[arg(long)]
+        recursive: bool,
+        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevServicesAction {
+    /// Executa o docker compose localizado em .dx/docker-compose.yml (sobe serviços em segundo plano)
+    Run {
+        /// Mede o tempo até cada serviço ficar pronto e exibe um perfil de inicialização com dicas de gargalos
+        #[arg(long)]
+        timings: bool,
+        /// Ativa um profile do compose (pode ser repetido: --profile debug --profile tools)
+        #[arg(long = "profile", value_name = "NOME")]
+        profiles: Vec<String>,
+        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Para (stop) os containers definidos em .dx/docker-compose.yml
+    Stop {
+        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Reinicia (restart) os containers definidos em .dx/docker-compose.yml
+    Restart {
+        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Remove (down) os containers definidos em .dx/docker-compose.yml (não remove volumes)
+    Remove {
+        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevConfigAction {
+    /// Lista todas as configurações
+    List,
+    /// Cria nova configuração
+    Add {
+        /// Chave da configuração
+        key: String,
+        /// Valor da configuração
+        value: String,
+    },
+    /// Atualiza configuração existente
+    Update {
+        /// Chave da configuração
+        key: String,
+        /// Novo valor da configuração
+        value: String,
+    },
+    /// Remove uma configuração
+    Delete {
+        /// Chave da configuração
+        key: String,
+    },
+    /// Gera .devcontainer/ (devcontainer.json, Dockerfile e compose) com a stack e a infraestrutura detectadas
+    Devcontainer {
+        /// Não salva (apenas imprime os arquivos gerados)
+        #[arg(long)]
+        no_save: bool,
+        /// Substitui arquivos de .devcontainer/ que não foram gerados pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera um Dockerfile multi-stage para a stack detectada (Go, Node.js ou Python), com usuário não-root e HEALTHCHECK
+    Dockerfile {
+        /// Não salva (apenas imprime o Dockerfile gerado)
+        #[arg(long)]
+        no_save: bool,
+        /// Substitui um Dockerfile que não foi gerado pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera manifestos Kubernetes (Deployment, Service e ConfigMap) em k8s/ a partir da porta, das variáveis e da infraestrutura detectadas
+    K8s {
+        /// Não salva (apenas imprime os manifestos gerados)
+        #[arg(long)]
+        no_save: bool,
+        /// Substitui arquivos de k8s/ que não foram gerados pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Gera k8s/base com overlays Kustomize para dev e staging
+        #[arg(long)]
+        overlays: bool,
+        /// Imagem do container (padrão: <projeto>:latest)
+        #[arg(long)]
+        image: Option<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera um chart Helm em charts/<projeto> com valores derivados da porta, das variáveis e da rota de saúde detectadas
+    Helm {
+        /// Não salva (apenas imprime os arquivos do chart)
+        #[arg(long)]
+        no_save: bool,
+        /// Substitui arquivos do chart que não foram gerados pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Imagem do container (padrão: <projeto>:latest); a tag vira o appVersion do chart
+        #[arg(long)]
+        image: Option<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Instala hooks pre-commit e pre-push do git que rodam as verificações da stack (gofmt/go vet, eslint, black) via `dx hooks run`
+    Hooks {
+        /// Não salva (apenas imprime os hooks)
+        #[arg(long)]
+        no_save: bool,
+        /// Substitui hooks que não foram instalados pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera um Makefile (ou Taskfile.yml/justfile) com build, test, lint, run e compose-up usando os comandos da stack detectada
+    Tasks {
+        /// Formato do arquivo gerado
+        #[arg(long, value_enum, default_value_t = task_runner::TasksFormat::Makefile)]
+        format: task_runner::TasksFormat,
+        /// Não salva (apenas imprime o arquivo gerado)
+        #[arg(long)]
+        no_save: bool,
+        /// Substitui um arquivo que não foi gerado pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum PluginsAction {
+    /// Lista os plugins encontrados, com protocolo, capacidades e origem
+    List,
+}
+
+#[derive(Subcommand)]
+enum BenchmarkAction {
+    /// Pontua o projeto contra um arquétipo (golden path): stack, arquivos, configurações, rotas, Dev Services e variáveis; lista as lacunas com as tarefas de correção
+    Archetype {
+        /// Arquétipo a usar (padrão: o único definido)
+        #[arg(long)]
+        name: Option<String>,
+        /// Arquivo YAML com `archetypes:` (padrão: os do perfil da equipe e de .dx/config.yaml)
+        #[arg(long)]
+        file: Option<std::path::PathBuf>,
+        /// Não salva o relatório em .dx/archetype-report.md
+        #[arg(long)]
+        no_save: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum TeamAction {
+    /// Mostra o perfil efetivo: o da equipe com as chaves sobrescritas em .dx/config.yaml
+    Show {
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Busca o perfil da equipe de novo, sem usar a cópia guardada
+    Update {
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Confere o projeto contra o perfil: dependências de registries aprovados e badges obrigatórios no README
+    Check {
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum CacheAction {
+    /// Apaga o cache de detecção e as respostas guardadas dos registries
+    Clear,
+    /// Consulta antes, em paralelo, as versões, licenças e vulnerabilidades de todas as dependências,
+    /// para que outdated, audit e licenses respondam na hora e funcionem offline
+    Prefetch {
+        /// Roda em segundo plano, com o log em prefetch.log no diretório de estado
+        #[arg(long)]
+        background: bool,
+        /// Diretório do projeto (ou com vários projetos; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum EnvAction {
+    /// Lista os ambientes, com containers, volumes e o último `dx up`
+    List,
+    /// Remove um ambiente com seus containers, redes e volumes
+    Rm {
+        /// Nome do ambiente (coluna AMBIENTE de `dx env list`) ou diretório do projeto
+        name: String,
+    },
+}
+
+#[derive(Subcommand)]
+enum PreviewAction {
+    /// Constrói a imagem, implanta a aplicação com seus serviços e imprime a URL do preview
+    Create {
+        /// Onde implantar (padrão: configuração preview_target, kind)
+        #[arg(long, value_enum)]
+        target: Option<preview::Target>,
+        /// Contexto do kubectl com --target cluster (padrão: o atual)
+        #[arg(long)]
+        context: Option<String>,
+        /// Namespace (padrão: preview-<aplicação>-<branch>)
+        #[arg(long)]
+        namespace: Option<String>,
+        /// Tempo até o preview ser removido, ex.: 90m, 24h, 7d (padrão: configuração preview_ttl)
+        #[arg(long)]
+        ttl: Option<String>,
+        /// Imagem já publicada, sem construir (padrão: <registry>/<aplicação>:preview-<hora>)
+        #[arg(long)]
+        image: Option<String>,
+        /// Apenas imprime os manifestos, sem construir nem implantar
+        #[arg(long)]
+        dry_run: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Lista os previews com URL e expiração
+    List,
+    /// Remove um preview (o namespace inteiro)
+    Delete {
+        /// Nome do preview (coluna PREVIEW de `dx preview list`) ou namespace
+        name: String,
+    },
+    /// Remove os previews expirados: os registrados nesta máquina e os encontrados no cluster
+    Prune {
+        /// Contexto do kubectl cujos namespaces de preview são verificados (padrão: o atual)
+        #[arg(long)]
+        context: Option<String>,
+    },
+}
+
+#[derive(Subcommand)]
+enum RulesAction {
+    /// Lista as regras embutidas e as instaladas, marcando as usadas no projeto
+    List {
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Baixa e instala as regras mais novas (ou as de --version), sem atualizar o dx
+    Update {
+        /// Versão das regras (padrão: a mais nova)
+        #[arg(long)]
+        version: Option<String>,
+        /// Instala o pacote deste arquivo ou URL em vez do da configuração rules_url
+        #[arg(long, value_name = "ARQUIVO|URL")]
+        from: Option<String>,
+        /// Diretório do projeto, para avisar se o dx.lock dele fixa outra versão (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Fixa no dx.lock a versão e o hash das regras em uso (ou as de --version)
+    Pin {
+        /// Versão instalada ou embutida a fixar (padrão: a usada hoje no projeto)
+        #[arg(long)]
+        version: Option<String>,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevDependenciesAction {
+    /// Lista todas as dependências de desenvolvimento (de cada projeto, num diretório com vários)
+    List {
+        /// Projetos lidos em paralelo quando o diretório tem vários (padrão: configuração scan_concurrency; 0 = um por CPU)
+        #[arg(long, value_name = "N")]
+        concurrency: Option<u64>,
+        /// Lê todos os projetos de novo, sem usar nem gravar o cache de detecção
+        #[arg(long)]
+        no_cache: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Adiciona uma nova dependência de desenvolvimento
+    Add {
+        /// Nome da dependência
+        name: String,
+        /// Versão (opcional)
+        version: Option<String>,
+    },
+    /// Atualiza uma dependência específica ou todas se omitido
+    Update {
+        /// Nome da dependência (opcional)
+        name: Option<String>,
+    },
+    /// Remove uma dependência de desenvolvimento
+    Delete {
+        /// Nome da dependência
+        name: String,
+    },
+    /// Verifica vulnerabilidades conhecidas (OSV) nas dependências com versão fixada; falha para uso em CI
+    Audit {
+        /// Formato da saída (padrão: text; json com --output json; sarif com --output sarif)
+        #[arg(long, value_enum)]
+        format: Option<dependency_audit::AuditFormat>,
+        /// Severidade mínima que faz o comando falhar
+        #[arg(long, value_enum, default_value_t = dependency_audit::FailOn::Any)]
+        fail_on: dependency_audit::FailOn,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Imprime a árvore de dependências em DOT (Graphviz), Mermaid ou JSON
+    Graph {
+        /// Formato da saída (padrão: dot; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dependency_graph::GraphFormat>,
+        /// Profundidade máxima a partir do projeto (1 = só as dependências diretas)
+        #[arg(long)]
+        depth: Option<usize>,
+        /// Mostra só os módulos cujo nome começa com o prefixo (e o caminho até eles); aceita vários
+        #[arg(long, value_delimiter = ',')]
+        filter: Vec<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Relata a licença de cada dependência (diretas e transitivas); falha com licenças proibidas
+    Licenses {
+        /// Formato da saída (padrão: text; json com --output json; sarif com --output sarif)
+        #[arg(long, value_enum)]
+        format: Option<dependency_licenses::LicenseFormat>,
+        /// Licenças aceitas (SPDX, ou prefixo com `*`); as demais passam a ser proibidas. Soma-se ao dx.yaml
+        #[arg(long, value_delimiter = ',')]
+        allow: Vec<String>,
+        /// Licenças proibidas (SPDX, ou prefixo com `*`). Soma-se ao dx.yaml
+        #[arg(long, value_delimiter = ',')]
+        deny: Vec<String>,
+        /// Também falha quando a licença de alguma dependência não é encontrada
+        #[arg(long)]
+        fail_on_unknown: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Imprime o SBOM (lista de materiais de software) com todas as dependências, hashes dos lockfiles e licenças
+    Sbom {
+        /// Formato do SBOM
+        #[arg(long, value_enum, default_value_t = dependency_sbom::SbomFormat::Cyclonedx)]
+        format: dependency_sbom::SbomFormat,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevEnvAction {
+    /// Lista as variáveis de ambiente lidas pelo código (obrigatórias e opcionais, com padrões)
+    Scan {
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_env::ScanFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera o .env.example com as variáveis lidas pelo código e seus padrões (e o .env com --env)
+    Init {
+        /// Também cria o .env (ou completa o existente, sem alterar valores já definidos)
+        #[arg(long)]
+        env: bool,
+        /// Substitui um .env.example que não foi gerado pelo dx
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Não salva (apenas imprime o .env.example gerado)
+        #[arg(long)]
+        no_save: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera/atualiza ENV.md com variável, padrão, obrigatoriedade e serviço configurado
+    Docs {
+        /// Escreve a seção no README.md em vez de ENV.md
+        #[arg(long)]
+        readme: bool,
+        /// Não salva (apenas imprime a documentação gerada)
+        #[arg(long)]
+        no_save: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Imprime o ambiente composto pelo dx (conexões dos Dev Services + dev-config)
+    Export {
+        /// Formato da saída (padrão: sh; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<env_export::ExportFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera/atualiza o .envrc do direnv para carregar o ambiente do dx (`use dx`)
+    Envrc {
+        /// Não salva (apenas imprime o bloco do .envrc)
+        #[arg(long)]
+        no_save: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Compara o .env com o .env.example e com o código (faltando, sem valor, tipo incorreto, não usadas)
+    Check {
+        /// Acrescenta ao .env as variáveis que faltam, sem alterar as existentes
+        #[arg(long)]
+        fix: bool,
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_env::CheckFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum ConfigAction {
+    /// Mostra os diretórios usados e as configurações atuais
+    Show,
+    /// Valor efetivo das configurações (padrão < perfil da equipe < usuário < dx.yaml < .dx/config.yaml < ambiente < opção)
+    Get {
+        /// Configuração (opcional; padrão: todas)
+        key: Option<String>,
+        /// Mostra o valor de cada camada e de onde veio o valor efetivo
+        #[arg(long)]
+        explain: bool,
+    },
+    /// Define uma configuração (notify_after, sandbox)
+    Set { key: String, value: String },
+    /// Remove uma configuração
+    Unset { key: String },
+    /// Exporta as configurações do usuário em JSON (stdout ou --file)
+    Export {
+        /// Arquivo de destino (padrão: saída padrão)
+        #[arg(long)]
+        file: Option<std::path::PathBuf>,
+    },
+    /// Importa configurações exportadas (mescla com as atuais; use - para ler da entrada padrão)
+    Import {
+        file: std::path::PathBuf,
+        /// Substitui as configurações atuais em vez de mesclar
+        #[arg(long)]
+        replace: bool,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevTestAction {
+    /// Chama cada rota segura (GET, HEAD, OPTIONS) da aplicação em execução e mostra status e latência
+    Smoke {
+        /// URL base da aplicação (padrão: http://localhost:<porta detectada>)
+        #[arg(long)]
+        url: Option<String>,
+        /// Não inicia a aplicação; falha se nada responder na URL
+        #[arg(long)]
+        no_start: bool,
+        /// Segundos de espera pela aplicação iniciada pelo dx
+        #[arg(long, default_value_t = 60)]
+        timeout: u64,
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_smoke::SmokeFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevSecretsAction {
+    /// Lista os segredos encontrados fora do baseline (.secrets-baseline.json); falha para uso em CI
+    Scan {
+        /// Formato da saída (padrão: text; json com --output json; sarif com --output sarif)
+        #[arg(long, value_enum)]
+        format: Option<dev_secrets::ScanFormat>,
+        /// Só os arquivos no stage do git (como no hook pre-commit)
+        #[arg(long)]
+        staged: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Aceita os segredos encontrados agora como falsos positivos, em .secrets-baseline.json
+    Baseline {
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevRoutesAction {
+    /// Lista método, rota, handler e local de cada rota registrada no código
+    List {
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_routes::RoutesFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera um esqueleto OpenAPI 3.1 com as rotas e os schemas dos structs lidos pelos handlers
+    Openapi {
+        /// Arquivo do documento, relativo ao projeto (YAML, ou JSON se terminar em .json; padrão: openapi.yaml)
+        #[arg(long)]
+        out: Option<std::path::PathBuf>,
+        /// Apenas verifica se o documento em --out está em dia com o código (sai com 1 se não estiver)
+        #[arg(long)]
+        check: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevInfraAction {
+    /// Analisa go.mod e imports do código Go (ou pacotes Hex do mix.exs/rebar.config) e lista os serviços locais necessários
+    Detect {
+        /// Formato da saída (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_infra::DetectFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera um docker-compose.yml com a infraestrutura detectada, ajustado aos padrões do código
+    Compose {
+        /// Não salva (apenas imprime o docker-compose.yml gerado)
+        #[arg(long)]
+        no_save: bool,
+        /// Sobrescreve o arquivo compose já existente no projeto
+        #[arg(long)]
+        force: bool,
+        /// Como tratar arquivos existentes que não foram gerados pelo dx (padrão: ask, trecho a trecho; sem terminal, fail)
+        #[arg(long, value_enum)]
+        strategy: Option<conflicts::Strategy>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevKafkaAction {
+    /// Lista, cria e remove tópicos; sem ação, lista os do broker e os usados pelo projeto
+    Topics {
+        #[command(subcommand)]
+        action: Option<TopicsAction>,
+        /// Brokers (host:porta, separados por vírgula; padrão: KAFKA_BROKERS do ambiente, do .env ou do código)
+        #[arg(long, global = true)]
+        brokers: Option<String>,
+        /// Formato da listagem (padrão: text; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_kafka::TopicsFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Acompanha as mensagens de um tópico, com o JSON indentado e os headers (Ctrl+C para parar)
+    Consume {
+        /// Tópico a consumir
+        topic: String,
+        /// Lê desde a primeira mensagem do tópico (padrão: só as novas)
+        #[arg(long)]
+        from_beginning: bool,
+        /// Mostra só as mensagens com esta chave
+        #[arg(long)]
+        key: Option<String>,
+        /// Mostra só as mensagens com este header (nome=valor, ou só o nome; repetível)
+        #[arg(long = "header", value_name = "NOME=VALOR")]
+        headers: Vec<String>,
+        /// Formato das mensagens (padrão: text; jsonl com --output json)
+        #[arg(long, value_enum)]
+        format: Option<dev_kafka::ConsumeFormat>,
+        /// Para depois de mostrar este número de mensagens
+        #[arg(long, short = 'n')]
+        max_messages: Option<usize>,
+        /// Brokers (host:porta, separados por vírgula; padrão: KAFKA_BROKERS do ambiente, do .env ou do código)
+        #[arg(long)]
+        brokers: Option<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Envia mensagens de teste a um tópico: inline, de um arquivo, da entrada padrão ou de um evento do catálogo
+    Produce {
+        /// Tópico de destino
+        topic: String,
+        /// Valor da mensagem (aceita os marcadores {{i}}, {{uuid}}, {{random}}, {{now}}, {{date}} e {{timestamp}})
+        #[arg(long, conflicts_with_all = ["file", "event"])]
+        value: Option<String>,
+        /// Arquivo com as mensagens: um array JSON ou uma por linha ("-" para a entrada padrão)
+        #[arg(long, value_name = "ARQUIVO", conflicts_with = "event")]
+        file: Option<std::path::PathBuf>,
+        /// Gera o valor a partir de um evento do catálogo (veja dx dev-kafka events), com IDs aleatórios
+        #[arg(long, value_name = "EVENTO")]
+        event: Option<String>,
+        /// Tipo do evento gerado com --event (ex.: USER_CREATED; padrão: o primeiro encontrado no código)
+        #[arg(long = "type", value_name = "TIPO", requires = "event")]
+        event_type: Option<String>,
+        /// Chave das mensagens (aceita marcadores; com --event, padrão: o campo usado como chave pelo produtor)
+        #[arg(long)]
+        key: Option<String>,
+        /// Header das mensagens (nome=valor, aceita marcadores; repetível)
+        #[arg(long = "header", value_name = "NOME=VALOR")]
+        headers: Vec<String>,
+        /// Quantas vezes enviar cada mensagem
+        #[arg(long, short = 'n', default_value_t = 1)]
+        count: usize,
+        /// Brokers (host:porta, separados por vírgula; padrão: KAFKA_BROKERS do ambiente, do .env ou do código)
+        #[arg(long)]
+        brokers: Option<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera o catálogo dos eventos publicados pelo projeto (tópico, tipos e campos) e, opcionalmente, JSON Schemas
+    Events {
+        /// Formato do catálogo (padrão: markdown; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<kafka_events::EventsFormat>,
+        /// Escreve o catálogo neste arquivo em vez de mostrá-lo (ex.: docs/events.md)
+        #[arg(long, value_name = "ARQUIVO")]
+        out: Option<std::path::PathBuf>,
+        /// Escreve um JSON Schema por evento neste diretório (<Evento>.schema.json)
+        #[arg(long, value_name = "DIR")]
+        schemas: Option<std::path::PathBuf>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+}
+
+#[derive(Subcommand)]
+enum DevDbAction {
+    /// Carrega os documentos de seeds/<coleção>.json (ou .ndjson) no banco MongoDB do projeto
+    Seed {
+        /// Remove as coleções antes de carregar, voltando ao estado dos arquivos
+        #[arg(long)]
+        drop: bool,
+        /// URI do MongoDB (padrão: MONGODB_URI do ambiente, do .env ou do código)
+        #[arg(long)]
+        uri: Option<String>,
+        /// Banco de dados (padrão: o da URI ou MONGODB_DATABASE do ambiente, do .env ou do código)
+        #[arg(long = "db", value_name = "BANCO")]
+        database: Option<String>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Abre o shell do banco do projeto (mongosh, psql, mysql ou redis-cli) com a conexão das variáveis de ambiente
+    Shell {
+        /// Banco a abrir quando o projeto usa mais de um (padrão: o único encontrado)
+        #[arg(long, value_enum)]
+        service: Option<dev_db::DbService>,
+        /// Só mostra o comando, sem executá-lo
         #[arg(long)]
-        no_save: bool,
-        /// Caminho para salvar o relatório (padrão: analyzer-report.md)
-        #[arg(long, default_value = "analyzer-report.md")]
-        report_path: String,
-        /// Diretório do projeto a ser analisado (opcional; padrão: diretório atual)
+        print: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
+        /// Argumentos repassados ao cliente (depois de --)
+        #[arg(last = true)]
+        args: Vec<String>,
     },
 }
 
 #[derive(Subcommand)]
-enum DevBadgesAction {
-    /// Limpa os badges do README.md entre os marcadores padrão
-    Clean {
-        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
-        dir: Option<std::path::PathBuf>,
+enum TopicsAction {
+    /// Lista os tópicos do broker e os usados pelo projeto (padrão)
+    List,
+    /// Cria tópicos; sem nomes, cria os usados pelo projeto que ainda não existem no broker
+    Create {
+        /// Nomes dos tópicos (opcional)
+        topics: Vec<String>,
+        /// Número de partições de cada tópico
+        #[arg(long, default_value_t = 1)]
+        partitions: i32,
+        /// Fator de replicação (1 no broker local)
+        #[arg(long, default_value_t = 1)]
+        replication_factor: i16,
+    },
+    /// Remove tópicos do broker
+    Delete {
+        /// Nomes dos tópicos
+        #[arg(required = true)]
+        topics: Vec<String>,
     },
 }
 
 #[derive(Subcommand)]
-enum DevServicesAction {
-    /// Executa o docker compose localizado em .dx/docker-compose.yml (sobe serviços em segundo plano)
+enum HooksAction {
+    /// Roda as verificações de um hook (pre-commit ou pre-push), como o git faria
     Run {
-        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
-        dir: Option<std::path::PathBuf>,
-    },
-    /// Para (stop) os containers definidos em .dx/docker-compose.yml
-    Stop {
-        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+        /// Hook cujas verificações rodar
+        #[arg(value_enum)]
+        hook: hooks::Hook,
+        /// Verifica todos os arquivos versionados, não só os do stage (para o CI)
+        #[arg(long)]
+        all_files: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
     },
-    /// Reinicia (restart) os containers definidos em .dx/docker-compose.yml
-    Restart {
-        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+}
+
+#[derive(Subcommand)]
+enum MigrateAction {
+    /// Converte alvos comuns do Makefile em tarefas do dx.yaml
+    Makefile {
+        /// Executa de verdade estes alvos com make e com dx e só os migra se forem equivalentes (mesmo
+        /// resultado); separados por vírgula ou repetidos. Requer que o projeto seja confiável (dx allow)
+        #[arg(long, value_name = "ALVO", value_delimiter = ',')]
+        verify: Vec<String>,
+        /// Não salva o dx.yaml (apenas imprime o resultado)
+        #[arg(long)]
+        no_save: bool,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
     },
-    /// Remove (down) os containers definidos em .dx/docker-compose.yml (não remove volumes)
-    Remove {
-        /// Diretório alvo (opcional). Se omitido, usa o diretório atual.
+}
+
+#[derive(Subcommand)]
+enum InstrumentAction {
+    /// Mostra as alterações sugeridas como patch unificado; --apply as escreve depois de confirmar
+    Suggest {
+        /// Só estas sugestões (repetível ou separadas por vírgula; padrão: todas)
+        #[arg(long, value_enum, value_delimiter = ',')]
+        only: Vec<instrument::Kind>,
+        /// Aplica as alterações no projeto (pede confirmação)
+        #[arg(long)]
+        apply: bool,
+        /// Não pede confirmação ao aplicar
+        #[arg(long, requires = "apply")]
+        yes: bool,
+        /// Formato da saída (padrão: diff; json com --output json)
+        #[arg(long, value_enum)]
+        format: Option<instrument::InstrumentFormat>,
+        /// Diretório raiz do projeto (opcional; padrão: diretório atual)
         dir: Option<std::path::PathBuf>,
     },
 }
 
 #[derive(Subcommand)]
-enum DevConfigAction {
-    /// Lista todas as configurações
-    List,
-    /// Cria nova configuração
-    Add {
-        /// Chave da configuração
-        key: String,
-        /// Valor da configuração
-        value: String,
+enum GenerateAction {
+    /// Cria um serviço no monorepo e o registra no workspace, no compose e no CI
+    Service {
+        /// Nome do serviço (letras minúsculas, números e '-')
+        name: String,
+        /// Linguagem do serviço
+        #[arg(long, value_enum)]
+        lang: generate::Lang,
+        /// Dev Services usados pelo serviço (kafka, mongodb, postgres, mysql, redis)
+        #[arg(long, value_delimiter = ',')]
+        with: Vec<String>,
+        /// Diretório do serviço, relativo à raiz (padrão: ao lado dos outros pacotes, ex.: services/<nome>)
+        #[arg(long)]
+        path: Option<String>,
+        /// Porta HTTP do serviço (padrão: a primeira a partir de 8080 livre no compose)
+        #[arg(long)]
+        port: Option<u16>,
+        /// Apenas mostra os arquivos que seriam criados e alterados
+        #[arg(long)]
+        dry_run: bool,
+        /// Raiz do monorepo (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
     },
-    /// Atualiza configuração existente
-    Update {
-        /// Chave da configuração
-        key: String,
-        /// Novo valor da configuração
-        value: String,
+    /// Gera um cliente tipado da API a partir da especificação OpenAPI, dos .proto ou das rotas do código
+    Client {
+        /// Linguagem do cliente
+        #[arg(long, value_enum)]
+        lang: api_client::ClientLang,
+        /// Especificação OpenAPI/Swagger (JSON ou YAML) ou arquivo .proto (padrão: procurada no projeto)
+        #[arg(long)]
+        spec: Option<std::path::PathBuf>,
+        /// Diretório do cliente gerado, relativo ao projeto (padrão: clients/<lang>)
+        #[arg(long)]
+        out: Option<std::path::PathBuf>,
+        /// Apenas verifica se o cliente em --out está em dia com a API (sai com 1 se não estiver)
+        #[arg(long)]
+        check: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
     },
-    /// Remove uma configuração
-    Delete {
-        /// Chave da configuração
-        key: String,
+    /// Gera o documento AsyncAPI dos tópicos Kafka e filas RabbitMQ do projeto, com os payloads dos eventos
+    Asyncapi {
+        /// Arquivo do documento, relativo ao projeto (YAML, ou JSON se terminar em .json; padrão: asyncapi.yaml)
+        #[arg(long)]
+        out: Option<std::path::PathBuf>,
+        /// Apenas verifica se o documento em --out está em dia com o código (sai com 1 se não estiver)
+        #[arg(long)]
+        check: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+    },
+    /// Gera arquivos com um plugin que oferece `generate` (protocolo stdio)
+    Plugin {
+        /// Nome do plugin
+        name: String,
+        /// Apenas mostra os arquivos que seriam criados e alterados
+        #[arg(long)]
+        dry_run: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
+        /// Argumentos repassados ao gerador (depois de --)
+        #[arg(last = true)]
+        args: Vec<String>,
     },
 }
 
 #[derive(Subcommand)]
-enum DevDependenciesAction {
-    /// Lista todas as dependências de desenvolvimento
-    List,
-    /// Adiciona uma nova dependência de desenvolvimento
-    Add {
-        /// Nome da dependência
-        name: String,
-        /// Versão (opcional)
-        version: Option<String>,
+enum TemplateAction {
+    /// Cria o projeto a partir de um template (diretório vazio) ou associa um projeto existente a ele
+    Init {
+        /// Repositório git do template (URL ou caminho local)
+        source: String,
+        /// Branch ou tag do template a seguir (padrão: a branch principal)
+        #[arg(long = "ref", value_name = "REF")]
+        reference: Option<String>,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
     },
-    /// Atualiza uma dependência específica ou todas se omitido
-    Update {
-        /// Nome da dependência (opcional)
-        name: Option<String>,
+    /// Mostra as mudanças do template desde a versão aplicada e as divergências do projeto
+    Diff {
+        /// Compara com esta branch ou tag em vez da registrada
+        #[arg(long = "ref", value_name = "REF")]
+        reference: Option<String>,
+        /// Mostra o diff de cada arquivo alterado no template
+        #[arg(long)]
+        patch: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
     },
-    /// Remove uma dependência de desenvolvimento
-    Delete {
-        /// Nome da dependência
-        name: String,
+    /// Aplica as mudanças do template, mesclando os arquivos alterados também no projeto
+    Update {
+        /// Atualiza para esta branch ou tag (e passa a segui-la)
+        #[arg(long = "ref", value_name = "REF")]
+        reference: Option<String>,
+        /// Não pergunta: mescla os conflitos deixando marcadores
+        #[arg(long)]
+        yes: bool,
+        /// Diretório do projeto (opcional; padrão: diretório atual)
+        dir: Option<std::path::PathBuf>,
     },
 }
 
-
 mod dev_badges;
+mod dev_readme;
 mod dev_config;
+mod devcontainer;
+mod dockerfile;
+mod k8s;
+mod helm;
+mod task_runner;
+mod hooks;
 mod dev_test;
 mod dev_dependencies;
+mod dotnet_build;
+mod cargo_build;
+mod beam_build;
+mod preview;
+mod java_build;
+mod xml;
+mod conflicts;
+mod benchmark;
+mod dev_env;
+mod env_export;
+mod dev_secrets;
+mod dev_routes;
+mod dev_smoke;
+mod output;
+mod sarif;
+mod openapi;
+mod dev_infra;
+mod dev_doctor;
+mod dev_kafka;
+mod kafka_events;
+mod dev_db;
+mod up;
+mod supervisor;
+mod down;
+mod environments;
+mod tasks;
+mod task_graph;
+mod makefile;
+mod custom_commands;
+mod plugins;
+mod notifications;
+mod history;
+mod progress;
+mod trust;
+mod prompt;
+mod dashboard;
+mod howto;
+mod instrument;
+mod sandbox;
+mod sinks;
+mod registry;
+mod scan;
+mod detection_cache;
+mod rules;
+mod team_profile;
+mod paths;
+mod user_config;
+mod settings;
+mod audit;
+mod lock;
+mod compare;
+mod template;
+mod generate;
+mod api_client;
+mod asyncapi;
+mod dependency_audit;
+mod dependency_graph;
+mod dependency_licenses;
+mod dependency_sbom;
+mod prefetch;
 
 fn main() {
     let cli = Cli::parse();
+    paths::migrate_legacy();
+    if let Some(secs) = cli.notify_after {
+        settings::set_flag("notify_after", secs.to_string());
+    }
+    if let Some(mode) = cli.progress {
+        settings::set_flag("progress", if mode == progress::ProgressMode::Json { "json" } else { "text" });
+    }
+    if let Some(mode) = cli.output {
+        settings::set_flag("output", mode.key());
+    }
+    if cli.offline {
+        settings::set_flag("offline", "true");
+    }
+    if !cli.sinks.is_empty() {
+        match sinks::validate(&cli.sinks.join(",")) {
+            Ok(value) => settings::set_flag("report_sinks", value),
+            Err(e) => {
+                eprintln!("Erro: --sink: {}", e);
+                std::process::exit(2);
+            }
+        }
+    }
+    let progress_mode = if settings::get("progress") == "json" { progress::ProgressMode::Json } else { progress::ProgressMode::Text };
+    progress::init(progress_mode);
+    let args: Vec<String> = std::env::args().skip(1).collect();
+    notifications::init(settings::get_u64("notify_after"), notifications::command_label(&args));
+    history::init(&args, &subcommand_path(&args));
+    audit::init(&args);
     match cli.command {
         Commands::DevServices { action, no_save, dir } => {
             match action {
-                Some(DevServicesAction::Run { dir: d2 }) => cmd_dev_services_run(d2.or(dir)),
+                Some(DevServicesAction::Run { dir: d2, timings, profiles }) => {
+                    cmd_dev_services_run(d2.or(dir), timings, &profiles);
+                }
                 Some(DevServicesAction::Stop { dir: d2 }) => cmd_dev_services_stop(d2.or(dir)),
                 Some(DevServicesAction::Restart { dir: d2 }) => cmd_dev_services_restart(d2.or(dir)),
                 Some(DevServicesAction::Remove { dir: d2 }) => cmd_dev_services_remove(d2.or(dir)),
                 None => cmd_dev_services(!no_save, dir),
             }
         }
-        Commands::DevBadges { action, no_save, dir } => {
+        Commands::DevBadges { action, no_save, recursive, dir } => {
             match action {
-                Some(DevBadgesAction::Clean { dir: d2 }) => cmd_dev_badges_clean(d2.or(dir)),
-                None => cmd_dev_badges(!no_save, dir),
+                Some(DevBadgesAction::Clean { recursive: r2, dir: d2 }) => cmd_dev_badges_clean(d2.or(dir), recursive || r2),
+                None => cmd_dev_badges(!no_save, dir, recursive),
             }
         }
-        Commands::DevTest { dir } => dev_test::watch_and_test(dir),
+        Commands::DevReadme { action } => match action {
+            DevReadmeAction::Generate { no_save, check, dir } => exit(dev_readme::cmd_generate(dir, !no_save, check)),
+        },
+        Commands::DevTest { action, dir } => match action {
+            None => dev_test::watch_and_test(dir),
+            Some(DevTestAction::Smoke { url, no_start, timeout, format, dir: d2 }) => {
+                exit(dev_smoke::cmd_smoke(d2.or(dir), url, no_start, timeout, output::format(format, dev_smoke::SmokeFormat::Json, dev_smoke::SmokeFormat::Text)))
+            }
+        },
         Commands::DevConfig { action, dir } => match action.unwrap_or(DevConfigAction::List) {
             DevConfigAction::List => dev_config::list(dir),
             DevConfigAction::Add { key, value } => dev_config::add(dir, key, value),
             DevConfigAction::Update { key, value } => dev_config::update(dir, key, value),
             DevConfigAction::Delete { key } => dev_config::delete(dir, key),
+            DevConfigAction::Devcontainer { no_save, force, strategy, dir: d2 } => {
+                set_strategy_flag(strategy);
+                exit(devcontainer::cmd_devcontainer(d2.or(dir), !no_save, force))
+            }
+            DevConfigAction::Dockerfile { no_save, force, strategy, dir: d2 } => {
+                set_strategy_flag(strategy);
+                exit(dockerfile::cmd_dockerfile(d2.or(dir), !no_save, force))
+            }
+            DevConfigAction::K8s { no_save, force, strategy, overlays, image, dir: d2 } => {
+                set_strategy_flag(strategy);
+                exit(k8s::cmd_k8s(d2.or(dir), !no_save, force, overlays, image))
+            }
+            DevConfigAction::Helm { no_save, force, strategy, image, dir: d2 } => {
+                set_strategy_flag(strategy);
+                exit(helm::cmd_helm(d2.or(dir), !no_save, force, image))
+            }
+            DevConfigAction::Tasks { format, no_save, force, strategy, dir: d2 } => {
+                set_strategy_flag(strategy);
+                exit(task_runner::cmd_tasks(d2.or(dir), !no_save, force, format))
+            }
+            DevConfigAction::Hooks { no_save, force, strategy, dir: d2 } => {
+                set_strategy_flag(strategy);
+                exit(hooks::cmd_install(d2.or(dir), !no_save, force))
+            }
         },
-        Commands::DevDependencies { action, dir } => match action.unwrap_or(DevDependenciesAction::List) {
-            DevDependenciesAction::List => dev_dependencies::list(dir),
+        Commands::DevDependencies { action, dir } => match action.unwrap_or(DevDependenciesAction::List { concurrency: None, no_cache: false, dir: None }) {
+            DevDependenciesAction::List { concurrency, no_cache, dir: d2 } => {
+                set_concurrency_flag(concurrency);
+                set_no_cache_flag(no_cache);
+                dev_dependencies::list(d2.or(dir))
+            }
             DevDependenciesAction::Add { name, version } => dev_dependencies::add(dir, name, version),
             DevDependenciesAction::Update { name } => dev_dependencies::update(dir, name),
             DevDependenciesAction::Delete { name } => dev_dependencies::delete(dir, name),
+            DevDependenciesAction::Audit { format, fail_on, dir: d2 } => {
+                use dependency_audit::AuditFormat;
+                let format = output::format_or_sarif(format, AuditFormat::Sarif, AuditFormat::Json, AuditFormat::Text);
+                exit(dependency_audit::cmd_audit(d2.or(dir), format, fail_on))
+            }
+            DevDependenciesAction::Graph { format, depth, filter, dir: d2 } => {
+                let format = output::format(format, dependency_graph::GraphFormat::Json, dependency_graph::GraphFormat::Dot);
+                exit(dependency_graph::cmd_graph(d2.or(dir), format, depth, filter))
+            }
+            DevDependenciesAction::Licenses { format, allow, deny, fail_on_unknown, dir: d2 } => {
+                use dependency_licenses::LicenseFormat;
+                let format = output::format_or_sarif(format, LicenseFormat::Sarif, LicenseFormat::Json, LicenseFormat::Text);
+                exit(dependency_licenses::cmd_licenses(d2.or(dir), format, allow, deny, fail_on_unknown))
+            }
+            DevDependenciesAction::Sbom { format, dir: d2 } => exit(dependency_sbom::cmd_sbom(d2.or(dir), format)),
         },
+        Commands::DevEnv { action } => match action {
+            DevEnvAction::Scan { format, dir } => {
+                dev_env::cmd_scan(dir, output::format(format, dev_env::ScanFormat::Json, dev_env::ScanFormat::Text))
+            }
+            DevEnvAction::Init { env, force, strategy, no_save, dir } => {
+                set_strategy_flag(strategy);
+                dev_env::cmd_init(dir, !no_save, env, force)
+            }
+            DevEnvAction::Docs { readme, no_save, dir } => dev_env::docs(dir, !no_save, readme),
+            DevEnvAction::Export { format, dir } => {
+                env_export::cmd_export(dir, output::format(format, env_export::ExportFormat::Json, env_export::ExportFormat::Sh))
+            }
+            DevEnvAction::Envrc { no_save, dir } => env_export::cmd_envrc(dir, !no_save),
+            DevEnvAction::Check { fix, format, dir } => {
+                exit(dev_env::cmd_check(dir, output::format(format, dev_env::CheckFormat::Json, dev_env::CheckFormat::Text), fix))
+            }
+        },
+        Commands::DevSecrets { action } => match action {
+            DevSecretsAction::Scan { format, staged, dir } => {
+                let format = output::format_or_sarif(format, dev_secrets::ScanFormat::Sarif, dev_secrets::ScanFormat::Json, dev_secrets::ScanFormat::Text);
+                exit(dev_secrets::cmd_scan(dir, format, staged))
+            }
+            DevSecretsAction::Baseline { dir } => exit(dev_secrets::cmd_baseline(dir)),
+        },
+        Commands::DevRoutes { action } => match action {
+            DevRoutesAction::List { format, dir } => {
+                dev_routes::cmd_list(dir, output::format(format, dev_routes::RoutesFormat::Json, dev_routes::RoutesFormat::Text))
+            }
+            DevRoutesAction::Openapi { out, check, dir } => exit(openapi::cmd_openapi(out, check, dir)),
+        },
+        Commands::DevInfra { action } => match action {
+            DevInfraAction::Detect { format, dir } => {
+                dev_infra::cmd_detect(dir, output::format(format, dev_infra::DetectFormat::Json, dev_infra::DetectFormat::Text))
+            }
+            DevInfraAction::Compose { no_save, force, strategy, dir } => {
+                set_strategy_flag(strategy);
+                dev_infra::cmd_compose(dir, !no_save, force)
+            }
+        },
+        Commands::DevKafka { action } => match action {
+            DevKafkaAction::Topics { action, brokers, format, dir } => exit(match action {
+                None | Some(TopicsAction::List) => {
+                    dev_kafka::cmd_list(dir, brokers, output::format(format, dev_kafka::TopicsFormat::Json, dev_kafka::TopicsFormat::Text))
+                }
+                Some(TopicsAction::Create { topics, partitions, replication_factor }) => {
+                    dev_kafka::cmd_create(dir, brokers, topics, partitions, replication_factor)
+                }
+                Some(TopicsAction::Delete { topics }) => dev_kafka::cmd_delete(dir, brokers, topics),
+            }),
+            DevKafkaAction::Consume { topic, from_beginning, key, headers, format, max_messages, brokers, dir } => {
+                let format = output::format(format, dev_kafka::ConsumeFormat::Jsonl, dev_kafka::ConsumeFormat::Text);
+                exit(dev_kafka::cmd_consume(dir, brokers, topic, from_beginning, key, headers, format, max_messages))
+            }
+            DevKafkaAction::Produce { topic, value, file, event, event_type, key, headers, count, brokers, dir } => {
+                exit(dev_kafka::cmd_produce(dir, brokers, topic, value, file, event, event_type, key, headers, count))
+            }
+            DevKafkaAction::Events { format, out, schemas, dir } => {
+                let format = output::format(format, kafka_events::EventsFormat::Json, kafka_events::EventsFormat::Markdown);
+                exit(kafka_events::cmd_events(dir, format, out, schemas))
+            }
+        },
+        Commands::DevDb { action } => match action {
+            DevDbAction::Seed { drop, uri, database, dir } => exit(dev_db::cmd_seed(dir, uri, database, drop)),
+            DevDbAction::Shell { service, print, dir, args } => exit(dev_db::cmd_shell(dir, service, print, args)),
+        },
+        Commands::DevDoctor { ports, format, dir } => {
+            exit(dev_doctor::cmd_doctor(dir, ports, output::format(format, dev_doctor::DoctorFormat::Json, dev_doctor::DoctorFormat::Text)))
+        }
+        Commands::Up { profiles, timeout, no_logs, watch, debounce, ignore, dir, command } => {
+            set_watch_flags(debounce, &ignore);
+            exit(up::cmd_up(dir, &profiles, timeout, no_logs, watch, command))
+        }
+        Commands::Down { networks, volumes, prune, older_than, dir } => exit(down::cmd_down(dir, volumes, networks, prune.then_some(older_than))),
+        Commands::Env { action } => exit(match action {
+            EnvAction::List => environments::cmd_list(),
+            EnvAction::Rm { name } => environments::cmd_rm(name),
+        }),
+        Commands::Preview { action } => exit(match action {
+            PreviewAction::Create { target, context, namespace, ttl, image, dry_run, dir } => {
+                if !set_preview_flags(target, ttl) {
+                    exit(2);
+                }
+                preview::cmd_create(dir, context, namespace, image, dry_run)
+            }
+            PreviewAction::List => preview::cmd_list(),
+            PreviewAction::Delete { name } => preview::cmd_delete(name),
+            PreviewAction::Prune { context } => preview::cmd_prune(context),
+        }),
+        Commands::Run { task, graph, sandbox, watch, debounce, ignore, dir } => {
+            set_watch_flags(debounce, &ignore);
+            tasks::cmd_run(task, graph, sandbox, watch, dir)
+        }
+        Commands::Allow { sandbox, dir } => trust::cmd_allow(dir, sandbox),
+        Commands::Deny { dir } => trust::cmd_deny(dir),
+        Commands::Migrate { action } => match action {
+            MigrateAction::Makefile { verify, no_save, dir } => makefile::cmd_migrate(dir, !no_save, &verify),
+        },
+        Commands::Hooks { action } => match action {
+            HooksAction::Run { hook, all_files, dir } => exit(hooks::cmd_run(dir, hook, all_files)),
+        },
+        Commands::Custom(args) => custom_commands::dispatch(args),
+        Commands::Compare { a, b, format } => {
+            exit(compare::cmd_compare(a, b, output::format(format, compare::CompareFormat::Json, compare::CompareFormat::Text)))
+        }
+        Commands::Benchmark { action } => match action {
+            BenchmarkAction::Archetype { name, file, no_save, dir } => exit(benchmark::cmd_archetype(dir, name, file, !no_save)),
+        },
+        Commands::Generate { action } => match action {
+            GenerateAction::Service { name, lang, with, path, port, dry_run, dir } => {
+                exit(generate::cmd_service(name, lang, with, path, port, dry_run, dir))
+            }
+            GenerateAction::Client { lang, spec, out, check, dir } => exit(api_client::cmd_client(lang, spec, out, check, dir)),
+            GenerateAction::Asyncapi { out, check, dir } => exit(asyncapi::cmd_asyncapi(out, check, dir)),
+            GenerateAction::Plugin { name, dry_run, dir, args } => exit(plugins::cmd_generate(name, args, dry_run, dir)),
+        },
+        Commands::Plugins { action, dir } => match action.unwrap_or(PluginsAction::List) {
+            PluginsAction::List => {
+                use clap::CommandFactory;
+                let builtin: Vec<String> = Cli::command().get_subcommands().map(|c| c.get_name().to_string()).collect();
+                plugins::cmd_list(dir, &builtin)
+            }
+        },
+        Commands::Template { action } => exit(match action {
+            TemplateAction::Init { source, reference, dir } => template::cmd_init(source, reference, dir),
+            TemplateAction::Diff { reference, patch, dir } => template::cmd_diff(reference, patch, dir),
+            TemplateAction::Update { reference, yes, dir } => template::cmd_update(reference, yes, dir),
+        }),
+        Commands::Prompt { format, max_age, dir } => {
+            prompt::cmd_prompt(output::format(format, prompt::PromptFormat::Json, prompt::PromptFormat::Text), max_age, dir)
+        }
+        Commands::Instrument { action } => match action {
+            InstrumentAction::Suggest { only, apply, yes, format, dir } => exit(instrument::cmd_suggest(
+                dir,
+                only,
+                apply,
+                yes,
+                output::format(format, instrument::InstrumentFormat::Json, instrument::InstrumentFormat::Diff),
+            )),
+        },
+        Commands::Howto { format, dir } => {
+            exit(howto::cmd_howto(dir, output::format(format, howto::HowtoFormat::Json, howto::HowtoFormat::Text)))
+        }
+        Commands::Dashboard { once, dir } => exit(dashboard::cmd_dashboard(dir, once)),
+        Commands::History { limit, clear } => history::cmd_history(limit, clear),
+        Commands::Rerun { command, print } => exit(history::cmd_rerun(command, print)),
+        Commands::Audit { limit, diff } => audit::cmd_audit(limit, diff),
+        Commands::Undo { run, dry_run, force } => exit(audit::cmd_undo(run, dry_run, force)),
         Commands::Portal => cmd_portal(),
         Commands::Tests => cmd_tests(),
-        Commands::Config => cmd_config(),
+        Commands::Config { action } => match action.unwrap_or(ConfigAction::Show) {
+            ConfigAction::Show => user_config::cmd_show(),
+            ConfigAction::Get { key, explain } => settings::cmd_get(key, explain),
+            ConfigAction::Set { key, value } => user_config::cmd_set(key, value),
+            ConfigAction::Unset { key } => user_config::cmd_unset(key),
+            ConfigAction::Export { file } => user_config::cmd_export(file),
+            ConfigAction::Import { file, replace } => user_config::cmd_import(file, replace),
+        },
         Commands::Docs => cmd_docs(),
         Commands::Governance => cmd_governance(),
         Commands::Clean { dir } => cmd_clean(dir),
+        Commands::Team { action } => match action {
+            TeamAction::Show { dir } => team_profile::cmd_show(dir),
+            TeamAction::Update { dir } => team_profile::cmd_update(dir),
+            TeamAction::Check { dir } => exit(team_profile::cmd_check(dir)),
+        },
+        Commands::Cache { action: CacheAction::Clear } => detection_cache::cmd_clear(),
+        Commands::Cache { action: CacheAction::Prefetch { background, dir } } => {
+            exit(prefetch::cmd_prefetch(dir.unwrap_or_else(|| std::path::PathBuf::from(".")), background))
+        }
+        Commands::Rules { action } => exit(match action {
+            RulesAction::List { dir } => rules::cmd_list(dir),
+            RulesAction::Update { version, from, dir } => rules::cmd_update(version, from, dir),
+            RulesAction::Pin { version, dir } => rules::cmd_pin(version, dir),
+        }),
         Commands::Analyzer {
             no_save,
             report_path,
+            concurrency,
+            no_cache,
             dir,
-        } => cmd_analyzer(!no_save, report_path, dir),
+        } => {
+            set_concurrency_flag(concurrency);
+            set_no_cache_flag(no_cache);
+            cmd_analyzer(!no_save, report_path, dir)
+        }
     }
+    finish(0);
+}
+
+/// Record the outcome of the command (history, notifications).
+fn finish(code: i32) {
+    history::record(code);
+    notifications::finish(code);
+}
+
+/// Subcommand names of a dx invocation as clap reads them (`["dev-env", "export"]`), skipping global
+/// options and their values; empty when `args` do not parse.
+pub(crate) fn subcommand_path(args: &[String]) -> Vec<String> {
+    let Ok(matches) = Cli::command().try_get_matches_from(std::iter::once("dx".to_string()).chain(args.iter().cloned())) else {
+        return Vec::new();
+    };
+    std::iter::successors(matches.subcommand(), |(_, m)| m.subcommand()).map(|(name, _)| name.to_string()).collect()
+}
+
+/// Exit with `code` after recording the outcome; use instead of `std::process::exit`.
+pub(crate) fn exit(code: i32) -> ! {
+    finish(code);
+    std::process::exit(code)
 }
 
 
 mod dev_services;
 mod telemetry;
 mod report;
+mod startup_profile;
 
 fn cmd_dev_services(save_file: bool, dir: Option<std::path::PathBuf>) {
     use std::env;
@@ -277,6 +1718,9 @@ fn cmd_dev_services(save_file: bool, dir: Option<std::path::PathBuf>) {
                     Ok(res) => {
                         println!("Arquivo docker-compose.yml criado com sucesso em:");
                         println!("{}", res.compose_path.display());
+                        if let Some(endpoint) = &res.app_metrics {
+                            println!("O Prometheus também coleta as métricas da aplicação em {} (job app).", endpoint);
+                        }
                         println!("\nPara iniciar os serviços (incluindo Telemetry), execute:");
                         println!("docker compose -f .dx/docker-compose.yml up -d");
                         println!("ou, se estiver usando a CLI legada:");
@@ -290,7 +1734,7 @@ fn cmd_dev_services(save_file: bool, dir: Option<std::path::PathBuf>) {
                         let report_path = project_dir.join(".dx").join("analyzer-report.md");
                         let report = crate::report::build_analyzer_report(project_dir, &res.config);
                         if let Some(parent) = report_path.parent() { let _ = std::fs::create_dir_all(parent); }
-                        match std::fs::write(&report_path, report) {
+                        match audit::write(&report_path, report) {
                             Ok(_) => println!("\nRelatório (analyzer) gerado: {}", report_path.display()),
                             Err(e) => eprintln!("\nErro ao gerar relatório: {}", e),
                         }
@@ -332,17 +1776,18 @@ fn cmd_dev_services(save_file: bool, dir: Option<std::path::PathBuf>) {
         );
         match fs::read_dir(&target_dir) {
             Ok(entries) => {
-                for entry in entries.flatten() {
-                    let path: PathBuf = entry.path();
-                    let Ok(ft) = entry.file_type() else { continue };
-                    if ft.is_symlink() {
-                        continue;
-                    }
-                    if ft.is_dir() {
-                        println!("\n== Projeto: {} ==", path.display());
-                        process_project_dir(save_file, &path);
-                    }
+                let projects: Vec<PathBuf> = entries
+                    .flatten()
+                    .filter(|e| e.file_type().map(|ft| ft.is_dir() && !ft.is_symlink()).unwrap_or(false))
+                    .map(|e| e.path())
+                    .collect();
+                let phase = progress::Phase::start("dev-services.detect", "Detectando dependências dos projetos");
+                for (i, path) in projects.iter().enumerate() {
+                    println!("\n== Projeto: {} ==", path.display());
+                    process_project_dir(save_file, path);
+                    phase.step(i + 1, projects.len(), &path.display().to_string());
                 }
+                phase.finish(true);
             }
             Err(e) => {
                 eprintln!(
@@ -356,17 +1801,88 @@ fn cmd_dev_services(save_file: bool, dir: Option<std::path::PathBuf>) {
     }
 
     // Default: process a single directory
+    let phase = progress::Phase::start("dev-services.detect", "Detectando dependências");
     process_project_dir(save_file, &target_dir);
+    phase.finish(true);
+}
+
+/// Compose file used by run/stop/restart/remove: .dx/docker-compose.yml when present,
+/// otherwise a compose file the project already maintains (compose.yaml, docker-compose.yml, ...).
+/// `--debounce` and `--ignore` of the watch mode, as the flag layer of their settings.
+fn set_watch_flags(debounce: Option<u64>, ignore: &[String]) {
+    if let Some(ms) = debounce {
+        settings::set_flag("watch_debounce", ms.to_string());
+    }
+    if !ignore.is_empty() {
+        settings::set_flag("watch_ignore", ignore.join(","));
+    }
+}
+
+/// `--concurrency` of the multi-project scans, as the flag layer of `scan_concurrency`.
+fn set_concurrency_flag(concurrency: Option<u64>) {
+    if let Some(n) = concurrency {
+        settings::set_flag("scan_concurrency", n.to_string());
+    }
+}
+
+/// `--strategy` of the generators, as the flag layer of `conflict_strategy`.
+fn set_strategy_flag(strategy: Option<conflicts::Strategy>) {
+    if let Some(strategy) = strategy {
+        settings::set_flag("conflict_strategy", strategy.key());
+    }
+}
+
+/// `--target` and `--ttl` of `dx preview create`, as the flag layer of the preview settings. False
+/// when the TTL is not a duration.
+fn set_preview_flags(target: Option<preview::Target>, ttl: Option<String>) -> bool {
+    if let Some(target) = target {
+        settings::set_flag("preview_target", target.key());
+    }
+    if let Some(ttl) = ttl {
+        if let Err(e) = settings::validate("preview_ttl", &ttl) {
+            eprintln!("Erro: --ttl: {}", e);
+            return false;
+        }
+        settings::set_flag("preview_ttl", ttl);
+    }
+    true
+}
+
+/// `--no-cache`, as the flag layer of `detection_cache`.
+fn set_no_cache_flag(no_cache: bool) {
+    if no_cache {
+        settings::set_flag("detection_cache", "false");
+    }
+}
+
+fn dev_services_compose_path(project_dir: &std::path::Path) -> std::path::PathBuf {
+    let dx_compose = project_dir.join(".dx").join("docker-compose.yml");
+    if dx_compose.exists() {
+        return dx_compose;
+    }
+    dev_services::find_project_compose_file(project_dir).unwrap_or(dx_compose)
+}
+
+/// Serialize container operations of a project across dx processes (no double `up`, no
+/// `down` in the middle of an `up`). Held until the returned guard is dropped.
+fn lock_dev_services(project_dir: &std::path::Path) -> lock::Guard {
+    lock::acquire(&project_dir.join(".dx").join("dev-services.lock"), "Dev Services deste projeto").unwrap_or_else(|e| {
+        eprintln!("Erro: {}", e);
+        exit(1);
+    })
 }
 
-fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
+/// `dx dev-services run` (also the first step of `dx up`): returns whether compose started the containers.
+fn cmd_dev_services_run(dir: Option<std::path::PathBuf>, timings: bool, profiles: &[String]) -> bool {
     use std::env;
     use std::path::Path;
     use std::process::{Command, Stdio};
+    use std::time::{Duration, Instant};
 
     let project_dir = dir
         .unwrap_or_else(|| env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf()));
-    let compose_path = project_dir.join(".dx").join("docker-compose.yml");
+    let _lock = lock_dev_services(&project_dir);
+    let compose_path = dev_services_compose_path(&project_dir);
 
     if !compose_path.exists() {
         eprintln!(
@@ -380,14 +1896,16 @@ fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
         // Recheca se foi criado
         if !compose_path.exists() {
             eprintln!("Falha ao gerar .dx/docker-compose.yml automaticamente. Verifique mensagens acima ou execute 'dx dev-services' manualmente.");
-            return;
+            return false;
         }
     }
 
     // Migração: corrigir caminhos legados para evitar erros de montagem
     // - ".dx/telemetry/" -> "telemetry/"
     // - "telemetry/" -> "./telemetry/" (força bind mount)
-    if let Ok(content) = std::fs::read_to_string(&compose_path) {
+    // Apenas no manifesto gerado pelo dx; compose files do projeto nunca são alterados.
+    let generated = compose_path.starts_with(project_dir.join(".dx"));
+    if let Some(content) = std::fs::read_to_string(&compose_path).ok().filter(|_| generated) {
         let mut fixed = content.clone();
         let mut changed = false;
         if fixed.contains(".dx/telemetry/") {
@@ -399,7 +1917,7 @@ fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
             changed = true;
         }
         if changed && fixed != content {
-            match std::fs::write(&compose_path, fixed) {
+            match audit::write(&compose_path, fixed) {
                 Ok(_) => println!("Ajustando caminhos de telemetry no compose (bind mounts ./telemetry)."),
                 Err(e) => eprintln!("Aviso: falha ao auto-corrigir caminhos de telemetry no compose: {}", e),
             }
@@ -408,12 +1926,37 @@ fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
 
     println!("Iniciando Dev Services usando: {}", compose_path.display());
 
+    // Profiles do compose: serviços com `profiles:` só sobem quando um deles está ativo
+    let declared = std::fs::read_to_string(&compose_path)
+        .map(|c| dev_services::compose_profiles(&c))
+        .unwrap_or_default();
+    for p in profiles.iter().filter(|p| !declared.contains(*p)) {
+        eprintln!("Aviso: o profile '{}' não está declarado em {}.", p, compose_path.display());
+    }
+    if profiles.is_empty() && !declared.is_empty() {
+        let names: Vec<&str> = declared.iter().map(|s| s.as_str()).collect();
+        println!(
+            "Serviços com profiles não serão iniciados. Profiles disponíveis: {} (use --profile <nome>).",
+            names.join(", ")
+        );
+    } else if !profiles.is_empty() {
+        println!("Profiles ativos: {}", profiles.join(", "));
+    }
+    if let Err(e) = dev_services::save_active_profiles(&project_dir, profiles) {
+        eprintln!("Aviso: falha ao registrar os profiles ativos: {}", e);
+    }
+    let profile_args: Vec<&str> = profiles
+        .iter()
+        .flat_map(|p| ["--profile", p.as_str()])
+        .collect();
+
     // Prefer Docker Compose V2 (docker compose). If it fails to spawn, fallback to legacy docker-compose.
     let try_docker_compose_v2 = || -> std::io::Result<std::process::ExitStatus> {
         Command::new("docker")
             .arg("compose")
             .arg("-f")
             .arg(&compose_path)
+            .args(&profile_args)
             .arg("up")
             .arg("-d")
             .stdin(Stdio::inherit())
@@ -426,6 +1969,7 @@ fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
         Command::new("docker-compose")
             .arg("-f")
             .arg(&compose_path)
+            .args(&profile_args)
             .arg("up")
             .arg("-d")
             .stdin(Stdio::inherit())
@@ -434,10 +1978,37 @@ fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
             .status()
     };
 
+    // Report how long each service took to become ready (only when --timings is given)
+    let report_timings = |compose: &[&str], started: Instant| {
+        if !timings {
+            return;
+        }
+        println!("\nAguardando os serviços ficarem prontos para medir a inicialização...");
+        let timeout = Duration::from_secs(startup_profile::DEFAULT_TIMEOUT_SECS);
+        let phase = progress::Phase::start("dev-services.ready", "Aguardando os serviços ficarem prontos");
+        let result = startup_profile::profile_startup(compose, &compose_path, started, timeout, &phase);
+        phase.finish(result.is_some());
+        match result {
+            Some(result) => {
+                startup_profile::print_report(&project_dir, &result);
+                if let Err(e) = startup_profile::save_profile(&project_dir, &result) {
+                    eprintln!("Aviso: falha ao salvar .dx/startup-profile.json: {}", e);
+                }
+            }
+            None => eprintln!("Não foi possível obter o estado dos serviços (requer Docker Compose V2 com suporte a 'ps --format json')."),
+        }
+    };
+
+    audit::container(&compose_path, &[profile_args.as_slice(), &["up", "-d"]].concat().join(" "));
+    let phase = progress::Phase::start("dev-services.up", "Subindo os containers (compose up)");
+    let started = Instant::now();
     match try_docker_compose_v2() {
         Ok(status) if status.success() => {
             println!("Serviços iniciados com Docker Compose (V2). Use 'docker compose ps' para ver o status.");
-            return;
+            environments::track(&project_dir, &compose_path);
+            phase.finish(true);
+            report_timings(&["docker", "compose"], started);
+            return true;
         }
         Ok(_status) => {
             eprintln!("Falha ao executar 'docker compose'. Tentando 'docker-compose' (CLI legada)...");
@@ -446,20 +2017,30 @@ fn cmd_dev_services_run(dir: Option<std::path::PathBuf>) {
             eprintln!("Não foi possível executar 'docker compose': {}. Tentando 'docker-compose' (CLI legada)...", e);
         }
     }
+    phase.message("docker compose falhou; tentando docker-compose (CLI legada)");
 
+    let started = Instant::now();
     match try_docker_compose_v1() {
         Ok(status) if status.success() => {
             println!("Serviços iniciados com docker-compose. Use 'docker-compose ps' para ver o status.");
+            environments::track(&project_dir, &compose_path);
+            phase.finish(true);
+            report_timings(&["docker-compose"], started);
+            true
         }
         Ok(_status) => {
             eprintln!("Falha ao executar 'docker-compose'. Verifique se o Docker Desktop está instalado e em execução.");
+            phase.finish(false);
+            false
         }
         Err(e) => {
+            phase.finish(false);
             eprintln!("Erro ao tentar executar 'docker-compose': {}", e);
             eprintln!("Dicas:");
             eprintln!(" - Instale o Docker Desktop para Windows");
             eprintln!(" - Reabra o terminal após a instalação para atualizar o PATH");
             eprintln!(" - Teste no terminal: 'docker --version' e 'docker compose version'");
+            false
         }
     }
 }
@@ -471,7 +2052,8 @@ fn cmd_dev_services_stop(dir: Option<std::path::PathBuf>) {
 
     let project_dir = dir
         .unwrap_or_else(|| env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf()));
-    let compose_path = project_dir.join(".dx").join("docker-compose.yml");
+    let _lock = lock_dev_services(&project_dir);
+    let compose_path = dev_services_compose_path(&project_dir);
 
     if !compose_path.exists() {
         eprintln!(
@@ -506,6 +2088,7 @@ fn cmd_dev_services_stop(dir: Option<std::path::PathBuf>) {
             .status()
     };
 
+    audit::container(&compose_path, "stop");
     match try_docker_compose_v2() {
         Ok(status) if status.success() => {
             println!("Serviços parados com Docker Compose (V2). Para iniciar novamente: 'dx dev-services run'.");
@@ -536,12 +2119,16 @@ fn cmd_dev_services_stop(dir: Option<std::path::PathBuf>) {
     }
 }
 
-fn cmd_dev_badges(save_file: bool, dir: Option<std::path::PathBuf>) {
+fn cmd_dev_badges(save_file: bool, dir: Option<std::path::PathBuf>, recursive: bool) {
     use std::env;
     use std::fs;
     use std::path::{Path, PathBuf};
 
     let target_dir = dir.unwrap_or_else(|| env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf()));
+    if recursive {
+        crate::dev_badges::process_monorepo(save_file, &target_dir);
+        return;
+    }
 
     // Helper
     fn process_project_dir(save_file: bool, project_dir: &Path) {
@@ -595,12 +2182,16 @@ fn cmd_dev_badges(save_file: bool, dir: Option<std::path::PathBuf>) {
     process_project_dir(save_file, &target_dir);
 }
 
-fn cmd_dev_badges_clean(dir: Option<std::path::PathBuf>) {
+fn cmd_dev_badges_clean(dir: Option<std::path::PathBuf>, recursive: bool) {
     use std::env;
     use std::fs;
     use std::path::{Path, PathBuf};
 
     let target_dir = dir.unwrap_or_else(|| env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf()));
+    if recursive {
+        crate::dev_badges::process_clean_monorepo(&target_dir);
+        return;
+    }
 
     fn process_project_dir(project_dir: &Path) {
         crate::dev_badges::process_clean_directory(project_dir);
@@ -664,12 +2255,6 @@ fn cmd_tests() {
     );
 }
 
-fn cmd_config() {
-    println!(
-        "Configuração sem dor (stub)\n- Schema central tipado, wizards em linguagem natural (ex.: 'quero habilitar TLS e rodar em staging').\n- IA valida, propõe padrões e explica impacto das propriedades."
-    );
-}
-
 fn cmd_docs() {
     println!(
         "Docs vivas + Q&A (stub)\n- Documentação como código, indexada e consultável via chat embutido.\n- IA referencia trechos, PRs e decisões de arquitetura; sugere golden paths."
@@ -691,13 +2276,24 @@ fn clean_dx_from(root: &std::path::Path) -> (usize, Vec<String>) {
     use std::path::{Path, PathBuf};
 
     fn walk_and_clean(dir: &Path, removed: &mut usize, errors: &mut Vec<String>) {
-        // First, attempt to remove ".dx" in this directory, if present
+        // First, attempt to remove ".dx" in this directory, if present; the team profile
+        // configuration (.dx/config.yaml) is written by hand, so it is put back
         let dx_here = dir.join(".dx");
         if dx_here.is_dir() {
-            match fs::remove_dir_all(&dx_here) {
+            let local_config = dir.join(crate::team_profile::LOCAL_FILE);
+            let kept = fs::read(&local_config).ok();
+            match crate::audit::remove_dir_all(&dx_here) {
                 Ok(_) => {
                     *removed += 1;
-                    println!("Removido: {}", dx_here.display());
+                    match kept.map(|content| fs::create_dir_all(&dx_here).and_then(|_| fs::write(&local_config, content))) {
+                        Some(Ok(())) => println!("Removido: {} (mantido {})", dx_here.display(), crate::team_profile::LOCAL_FILE),
+                        Some(Err(e)) => {
+                            let msg = format!("Falha ao restaurar {}: {}", local_config.display(), e);
+                            eprintln!("{}", msg);
+                            errors.push(msg);
+                        }
+                        None => println!("Removido: {}", dx_here.display()),
+                    }
                 }
                 Err(e) => {
                     let msg = format!("Falha ao remover {}: {}", dx_here.display(), e);
@@ -793,49 +2389,8 @@ fn cmd_analyzer(save_report: bool, report_path: String, dir: Option<std::path::P
         }
     }
 
-    // Helper: check if a directory looks like a project root by presence of marker files
-    fn is_project_root(dir: &Path) -> bool {
-        let markers = [
-            "Cargo.toml",
-            "package.json",
-            "requirements.txt",
-            "pyproject.toml",
-            "setup.py",
-            "pom.xml",
-            "build.gradle",
-            "Gemfile",
-            "go.mod",
-            "composer.json",
-        ];
-        markers.iter().any(|m| dir.join(m).is_file())
-    }
-
-    // Helper: list candidate subprojects under a directory following directory rules
-    fn list_subprojects(root: &Path) -> Vec<PathBuf> {
-        let skip = [
-            ".git", ".github", ".idea", ".vscode", ".dx", "node_modules", "target", "build", "dist", "vendor",
-        ];
-        let mut subs = Vec::new();
-        if let Ok(entries) = fs::read_dir(root) {
-            for entry in entries.flatten() {
-                let path = entry.path();
-                if !path.is_dir() { continue; }
-                let name = path.file_name().and_then(|n| n.to_str()).unwrap_or("");
-                if name.starts_with('.') { continue; }
-                if skip.iter().any(|s| s.eq_ignore_ascii_case(&name)) { continue; }
-                if is_project_root(&path) {
-                    subs.push(path);
-                }
-            }
-        }
-        subs
-    }
-
     // Ensure the analyzed directory's .gitignore contains an entry to ignore .dx; create if needed
     fn ensure_gitignore_has_dx(dir: &Path) {
-        use std::fs::OpenOptions;
-        use std::io::Write;
-
         let gi_path = dir.join(".gitignore");
         match fs::read_to_string(&gi_path) {
             Ok(content) => {
@@ -845,20 +2400,18 @@ fn cmd_analyzer(save_report: bool, report_path: String, dir: Option<std::path::P
                     if t == ".dx" || t == "/.dx" || t == ".dx/" { has = true; break; }
                 }
                 if !has {
-                    let mut file = match OpenOptions::new().create(true).append(true).open(&gi_path) {
-                        Ok(f) => f,
-                        Err(_) => return,
-                    };
+                    let mut updated = content.clone();
                     // Ensure previous content ends with newline to avoid gluing
-                    if !content.is_empty() && !content.ends_with(['\n', '\r']) {
-                        let _ = writeln!(file);
+                    if !updated.is_empty() && !updated.ends_with(['\n', '\r']) {
+                        updated.push('\n');
                     }
-                    let _ = writeln!(file, ".dx");
+                    updated.push_str(".dx\n");
+                    let _ = audit::write(&gi_path, updated);
                 }
             }
             Err(_) => {
                 // No .gitignore: create one with .dx
-                let _ = fs::write(&gi_path, ".dx\n");
+                let _ = audit::write(&gi_path, ".dx\n");
             }
         }
     }
@@ -875,21 +2428,85 @@ fn cmd_analyzer(save_report: bool, report_path: String, dir: Option<std::path::P
         return;
     }
 
+    // --output json: one entry per analyzed project (each subproject, or the project itself), with
+    // the detected services and where its report was saved
+    if output::json() {
+        #[derive(serde::Serialize)]
+        struct Service {
+            name: String,
+            image: String,
+            ports: Vec<u16>,
+        }
+        #[derive(serde::Serialize)]
+        struct Analyzed {
+            project: String,
+            services: Vec<Service>,
+            report: Option<String>,
+        }
+        let subprojects = scan::subprojects(&project_dir);
+        let projects = if subprojects.is_empty() { vec![project_dir.clone()] } else { subprojects };
+        let phase = progress::Phase::start("analyzer", "Analisando os projetos");
+        let detected = scan::each(&project_dir, &projects, &phase, |project| {
+            let ds_config = dev_services::detect_dependencies(project);
+            let report = build_report(project, &ds_config);
+            (ds_config, report)
+        });
+        phase.finish(true);
+        let mut analyzed = Vec::new();
+        for (project, (ds_config, report)) in projects.iter().zip(detected) {
+            ensure_gitignore_has_dx(project);
+            let mut services: Vec<Service> = ds_config
+                .services
+                .iter()
+                .map(|(name, svc)| Service { name: name.clone(), image: svc.image.clone(), ports: svc.ports.clone() })
+                .collect();
+            services.sort_by(|a, b| a.name.cmp(&b.name));
+            let mut saved = None;
+            if save_report {
+                let (mut out_path, used_default) = compute_output_path(project, &report_path);
+                if projects.len() > 1 && out_path.is_absolute() && !used_default {
+                    out_path = project.join(".dx").join("analyzer-report.md");
+                }
+                if let Some(parent) = out_path.parent() { let _ = fs::create_dir_all(parent); }
+                match audit::write(&out_path, report.clone()) {
+                    Ok(_) => saved = Some(out_path.display().to_string()),
+                    Err(e) => eprintln!("Erro ao salvar relatório em {}: {}", out_path.display(), e),
+                }
+            }
+            sinks::publish(&sinks::Report { kind: "analyzer", project_dir: project, content: sinks::Content::Markdown(report) });
+            analyzed.push(Analyzed { project: project.display().to_string(), services, report: saved });
+        }
+        output::print(&analyzed);
+        return;
+    }
+
     println!("dx analyzer\n");
     println!("Analisando o projeto em: {}\n", project_dir.display());
 
     // If the provided directory contains multiple recognizable subprojects, produce per-directory reports
-    let subprojects = list_subprojects(&project_dir);
+    let subprojects = scan::subprojects(&project_dir);
     let multi = !subprojects.is_empty();
 
     if multi {
-        println!("Detectamos múltiplos projetos dentro de {}. Gerando relatórios por diretório...", project_dir.display());
+        println!(
+            "Detectamos {} projetos dentro de {}. Gerando relatórios por diretório ({} em paralelo)...",
+            subprojects.len(),
+            project_dir.display(),
+            scan::concurrency().min(subprojects.len())
+        );
         let mut count_ok = 0usize;
-        for sub in &subprojects {
+        // Detection runs in the workers; the reports are written here, one project at a time
+        let phase = progress::Phase::start("analyzer", "Analisando subprojetos");
+        let detected = scan::each(&project_dir, &subprojects, &phase, |sub| {
+            let ds_config = dev_services::detect_dependencies(sub);
+            let report = build_report(sub, &ds_config);
+            (ds_config, report)
+        });
+        phase.finish(true);
+        for (sub, (ds_config, report)) in subprojects.iter().zip(detected) {
             // Ensure .gitignore ignores .dx in each subproject
             ensure_gitignore_has_dx(sub);
             println!("\n--- Projeto: {} ---", sub.display());
-            let ds_config = dev_services::detect_dependencies(sub);
 
             // Print a brief console summary per subproject
             if ds_config.services.is_empty() {
@@ -907,12 +2524,12 @@ fn cmd_analyzer(save_report: bool, report_path: String, dir: Option<std::path::P
                     out_path = sub.join(".dx").join("analyzer-report.md");
                 }
                 if let Some(parent) = out_path.parent() { let _ = fs::create_dir_all(parent); }
-                let report = build_report(sub, &ds_config);
-                match fs::write(&out_path, report) {
+                match audit::write(&out_path, report.clone()) {
                     Ok(_) => { println!("Relatório salvo em: {}", out_path.display()); count_ok += 1; }
                     Err(e) => eprintln!("Erro ao salvar relatório em {}: {}", out_path.display(), e),
                 }
             }
+            sinks::publish(&sinks::Report { kind: "analyzer", project_dir: sub, content: sinks::Content::Markdown(report) });
         }
         if !save_report {
             println!("\nPara salvar os relatórios, execute sem --no-save ou forneça --report-path (relativo). Cada relatório será salvo no .dx de cada projeto.");
@@ -925,7 +2542,9 @@ fn cmd_analyzer(save_report: bool, report_path: String, dir: Option<std::path::P
     // Single-project behavior (existing flow)
     // Ensure .gitignore ignores .dx in this project
     ensure_gitignore_has_dx(&project_dir);
+    let phase = progress::Phase::start("analyzer", "Analisando o projeto");
     let ds_config = dev_services::detect_dependencies(&project_dir);
+    phase.finish(true);
     println!("=== Dev Services ===");
     if ds_config.services.is_empty() {
         println!("Nenhuma dependência de serviços detectada.");
@@ -972,18 +2591,19 @@ fn cmd_analyzer(save_report: bool, report_path: String, dir: Option<std::path::P
     println!("\n=== Telemetria ===");
     println!("Observabilidade e feedback loops curtos por padrão.\nUse: dx dev-services");
 
+    let report = build_report(&project_dir, &ds_config);
     if save_report {
         let (final_path, _used_default) = compute_output_path(&project_dir, &report_path);
         // Ensure parent exists
         if let Some(parent) = final_path.parent() { let _ = fs::create_dir_all(parent); }
-        let report = build_report(&project_dir, &ds_config);
-        match fs::write(&final_path, report) {
+        match audit::write(&final_path, report.clone()) {
             Ok(_) => println!("\nRelatório salvo em: {}", final_path.display()),
             Err(e) => eprintln!("\nErro ao salvar relatório: {}", e),
         }
     } else {
         println!("\nPara salvar este relatório, execute sem --no-save ou use --report-path");
     }
+    sinks::publish(&sinks::Report { kind: "analyzer", project_dir: &project_dir, content: sinks::Content::Markdown(report) });
 }
 
 
@@ -994,7 +2614,8 @@ fn cmd_dev_services_restart(dir: Option<std::path::PathBuf>) {
 
     let project_dir = dir
         .unwrap_or_else(|| env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf()));
-    let compose_path = project_dir.join(".dx").join("docker-compose.yml");
+    let _lock = lock_dev_services(&project_dir);
+    let compose_path = dev_services_compose_path(&project_dir);
 
     if !compose_path.exists() {
         eprintln!(
@@ -1029,6 +2650,7 @@ fn cmd_dev_services_restart(dir: Option<std::path::PathBuf>) {
             .status()
     };
 
+    audit::container(&compose_path, "restart");
     match try_docker_compose_v2() {
         Ok(status) if status.success() => {
             println!("Serviços reiniciados com Docker Compose (V2). Use 'docker compose ps' para ver o status.");
@@ -1067,7 +2689,8 @@ fn cmd_dev_services_remove(dir: Option<std::path::PathBuf>) {
 
     let project_dir = dir
         .unwrap_or_else(|| env::current_dir().unwrap_or_else(|_| Path::new(".").to_path_buf()));
-    let compose_path = project_dir.join(".dx").join("docker-compose.yml");
+    let _lock = lock_dev_services(&project_dir);
+    let compose_path = dev_services_compose_path(&project_dir);
 
     if !compose_path.exists() {
         eprintln!(
@@ -1104,6 +2727,7 @@ fn cmd_dev_services_remove(dir: Option<std::path::PathBuf>) {
             .status()
     };
 
+    audit::container(&compose_path, "down -v");
     match try_docker_compose_v2() {
         Ok(status) if status.success() => {
             println!("Containers e volumes removidos com Docker Compose (V2). Para iniciar novamente: 'dx-cli dev-services run'.");
@@ -0,0 +1,464 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::task_graph;
+use crate::tasks::{self, DxFile, Run, Task};
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+
+const MAKEFILE_NAMES: [&str; 3] = ["GNUmakefile", "makefile", "Makefile"];
+
+/// make functions that have no direct shell equivalent
+const MAKE_FUNCTIONS: &[&str] = &[
+    "shell", "wildcard", "patsubst", "subst", "foreach", "call", "if", "filter", "filter-out", "sort",
+    "dir", "notdir", "basename", "addprefix", "addsuffix", "eval", "origin", "realpath", "abspath",
+];
+
+#[derive(Debug, Clone)]
+pub struct MakeTarget {
+    pub name: String,
+    pub prerequisites: Vec<String>,
+    pub recipe: Vec<String>,
+    /// From `target: ## description` or a `# comment` right above the rule
+    pub description: Option<String>,
+    /// Declared inside ifeq/ifdef blocks (depends on make evaluation)
+    pub conditional: bool,
+}
+
+#[derive(Debug, Default)]
+pub struct Makefile {
+    pub variables: BTreeMap<String, String>,
+    pub phony: BTreeSet<String>,
+    pub targets: Vec<MakeTarget>,
+}
+
+impl Makefile {
+    pub fn target(&self, name: &str) -> Option<&MakeTarget> {
+        self.targets.iter().find(|t| t.name == name)
+    }
+}
+
+pub fn find(project_dir: &Path) -> Option<PathBuf> {
+    MAKEFILE_NAMES.iter().map(|n| project_dir.join(n)).find(|p| p.is_file())
+}
+
+/// Parse the subset of GNU make used by typical project Makefiles: variables,
+/// explicit rules, recipes, .PHONY and `##` help comments.
+pub fn parse(content: &str) -> Makefile {
+    let mut mk = Makefile::default();
+    let mut current: Vec<usize> = Vec::new();
+    let mut last_comment: Option<String> = None;
+    let mut cond_depth = 0usize;
+    let mut in_define = false;
+
+    for line in join_continuations(content) {
+        if in_define {
+            if line.trim_start().starts_with("endef") {
+                in_define = false;
+            }
+            continue;
+        }
+        if let Some(recipe) = line.strip_prefix('\t') {
+            let cmd = recipe.trim();
+            if !cmd.is_empty() && !cmd.starts_with('#') {
+                for &i in &current {
+                    mk.targets[i].recipe.push(cmd.to_string());
+                }
+            }
+            continue;
+        }
+        let trimmed = line.trim();
+        if trimmed.is_empty() {
+            last_comment = None;
+            continue;
+        }
+        if let Some(comment) = trimmed.strip_prefix('#') {
+            last_comment = Some(comment.trim_start_matches('#').trim().to_string());
+            continue;
+        }
+        let keyword = trimmed.split_whitespace().next().unwrap_or("");
+        match keyword {
+            "ifeq" | "ifneq" | "ifdef" | "ifndef" => {
+                cond_depth += 1;
+                continue;
+            }
+            "endif" => {
+                cond_depth = cond_depth.saturating_sub(1);
+                continue;
+            }
+            "else" | "include" | "-include" | "sinclude" | "vpath" | "unexport" => continue,
+            "define" => {
+                in_define = true;
+                continue;
+            }
+            _ => {}
+        }
+        current.clear();
+
+        if let Some((name, value)) = parse_assignment(trimmed) {
+            if let Some(v) = value.strip_prefix("+=") {
+                let entry = mk.variables.entry(name).or_default();
+                if !entry.is_empty() {
+                    entry.push(' ');
+                }
+                entry.push_str(v);
+            } else if let Some(v) = value.strip_prefix("?=") {
+                mk.variables.entry(name).or_insert_with(|| v.to_string());
+            } else {
+                mk.variables.insert(name, value);
+            }
+            last_comment = None;
+            continue;
+        }
+
+        let Some((targets_part, rest)) = split_rule(trimmed) else { continue };
+        let (rest, help) = match rest.split_once("##") {
+            Some((r, h)) => (r, Some(h.trim().to_string())),
+            None => (rest, None),
+        };
+        let (prereqs, inline_recipe) = match rest.split_once(';') {
+            Some((p, r)) => (p, Some(r.trim().to_string())),
+            None => (rest, None),
+        };
+        let prerequisites: Vec<String> = prereqs
+            .split('|')
+            .next()
+            .unwrap_or("")
+            .split_whitespace()
+            .map(|s| s.to_string())
+            .collect();
+
+        for name in targets_part.split_whitespace() {
+            if name == ".PHONY" {
+                mk.phony.extend(prerequisites.iter().cloned());
+                continue;
+            }
+            // Special targets and pattern rules are not user-facing tasks
+            if name.starts_with('.') || name.contains('%') || name.contains('$') {
+                continue;
+            }
+            let idx = match mk.targets.iter().position(|t| t.name == name) {
+                Some(i) => i,
+                None => {
+                    mk.targets.push(MakeTarget {
+                        name: name.to_string(),
+                        prerequisites: Vec::new(),
+                        recipe: Vec::new(),
+                        description: None,
+                        conditional: false,
+                    });
+                    mk.targets.len() - 1
+                }
+            };
+            let t = &mut mk.targets[idx];
+            t.prerequisites.extend(prerequisites.iter().cloned());
+            t.conditional |= cond_depth > 0;
+            if t.description.is_none() {
+                t.description = help.clone().or_else(|| last_comment.clone()).filter(|d| !d.is_empty());
+            }
+            if let Some(r) = &inline_recipe {
+                if !r.is_empty() {
+                    t.recipe.push(r.clone());
+                }
+            }
+            current.push(idx);
+        }
+        last_comment = None;
+    }
+    mk
+}
+
+fn join_continuations(content: &str) -> Vec<String> {
+    let mut out: Vec<String> = Vec::new();
+    let mut pending: Option<String> = None;
+    for line in content.lines() {
+        let (text, continues) = match line.strip_suffix('\\') {
+            Some(t) => (t, true),
+            None => (line, false),
+        };
+        let joined = match pending.take() {
+            Some(mut p) => {
+                p.push(' ');
+                p.push_str(text.trim_start());
+                p
+            }
+            None => text.to_string(),
+        };
+        if continues {
+            pending = Some(joined);
+        } else {
+            out.push(joined);
+        }
+    }
+    out.extend(pending);
+    out
+}
+
+/// `NAME = value`, `NAME := value`, `NAME ?= value`, `NAME += value` (optionally `export`/`override`).
+/// Returns the name and the value; `?=`/`+=` keep their operator as a prefix.
+fn parse_assignment(line: &str) -> Option<(String, String)> {
+    let line = line.strip_prefix("export ").or_else(|| line.strip_prefix("override ")).unwrap_or(line);
+    let eq = line.find('=')?;
+    // A ':' before '=' that is not part of ':=' / '::=' means this is a rule (e.g. `a: b=c`)
+    if let Some(colon) = line.find(':') {
+        if colon < eq && !line[colon..eq].chars().all(|c| c == ':') {
+            return None;
+        }
+    }
+    let lhs = &line[..eq];
+    let (name, op) = if let Some(n) = lhs.strip_suffix('?') {
+        (n, "?=")
+    } else if let Some(n) = lhs.strip_suffix('+') {
+        (n, "+=")
+    } else {
+        (lhs.trim_end_matches(':'), "")
+    };
+    let name = name.trim();
+    if name.is_empty() || name.contains(char::is_whitespace) {
+        return None;
+    }
+    let value = line[eq + 1..].trim();
+    Some((name.to_string(), format!("{}{}", op, value)))
+}
+
+fn split_rule(line: &str) -> Option<(&str, &str)> {
+    let colon = line.find(':')?;
+    let rest = &line[colon + 1..];
+    // `::` double-colon rules behave like normal ones for our purposes
+    let rest = rest.strip_prefix(':').unwrap_or(rest);
+    if rest.starts_with('=') {
+        return None;
+    }
+    Some((&line[..colon], rest))
+}
+
+/// Convert a target into an equivalent dx task, or explain why it stays in the Makefile.
+pub fn to_task(mk: &Makefile, target: &MakeTarget) -> Result<Task, String> {
+    if target.conditional {
+        return Err("definido dentro de ifeq/ifdef".to_string());
+    }
+    let looks_like_file = target.name.contains('.') || target.name.contains('/');
+    if looks_like_file && !mk.phony.contains(&target.name) {
+        return Err("alvo de arquivo (depende de timestamps do make)".to_string());
+    }
+
+    // Prerequisites that are targets become dependencies; plain files are just inputs
+    let depends_on: Vec<String> =
+        target.prerequisites.iter().filter(|p| mk.target(p).is_some()).cloned().collect();
+    let mut commands = Vec::new();
+    for line in &target.recipe {
+        commands.push(convert_recipe_line(mk, &target.name, line)?);
+    }
+    if commands.is_empty() && depends_on.is_empty() {
+        return Err("sem receita".to_string());
+    }
+
+    Ok(Task {
+        description: Some(
+            target
+                .description
+                .clone()
+                .unwrap_or_else(|| format!("Migrado do Makefile (make {})", target.name)),
+        ),
+        depends_on,
+        run: match commands.len() {
+            0 => None,
+            1 => Some(Run::One(commands.remove(0))),
+            _ => Some(Run::Many(commands)),
+        },
+        env: BTreeMap::new(),
+    })
+}
+
+fn convert_recipe_line(mk: &Makefile, target: &str, line: &str) -> Result<String, String> {
+    // Recipe prefixes: @ (silent), - (ignore errors), + (always run)
+    let mut ignore_errors = false;
+    let mut cmd = line;
+    while let Some(c) = cmd.chars().next().filter(|c| matches!(c, '@' | '-' | '+')) {
+        ignore_errors |= c == '-';
+        cmd = cmd[1..].trim_start();
+    }
+    // `$(MAKE) other` becomes `dx run other` so the chain stays inside dx
+    for make_ref in ["$(MAKE) ", "${MAKE} ", "make "] {
+        if let Some(rest) = cmd.strip_prefix(make_ref) {
+            let rest = rest.trim();
+            if mk.target(rest).is_some() {
+                return Ok(format!("dx run {}", rest));
+            }
+        }
+    }
+    let mut expanded = expand(mk, target, cmd, 0)?;
+    if ignore_errors {
+        expanded = format!("{} || true", expanded);
+    }
+    Ok(expanded)
+}
+
+fn expand(mk: &Makefile, target: &str, text: &str, depth: usize) -> Result<String, String> {
+    if depth > 8 {
+        return Err("variáveis recursivas".to_string());
+    }
+    let mut out = String::new();
+    let mut chars = text.char_indices().peekable();
+    while let Some((i, c)) = chars.next() {
+        if c != '$' {
+            out.push(c);
+            continue;
+        }
+        match chars.peek().map(|&(_, n)| n) {
+            Some('$') => {
+                chars.next();
+                out.push('$');
+            }
+            Some('@') => {
+                chars.next();
+                out.push_str(target);
+            }
+            Some(open @ ('(' | '{')) => {
+                let close = if open == '(' { ')' } else { '}' };
+                let start = i + 2;
+                let end = text[start..].find(close).map(|e| start + e).ok_or("referência de variável sem fechamento")?;
+                let inner = &text[start..end];
+                if let Some(func) = inner.split_whitespace().next().filter(|_| inner.contains(' ')) {
+                    if MAKE_FUNCTIONS.contains(&func) {
+                        return Err(format!("usa a função do make $({} ...)", func));
+                    }
+                }
+                match inner {
+                    "MAKE" => out.push_str("make"),
+                    "CURDIR" => out.push('.'),
+                    _ => match mk.variables.get(inner) {
+                        Some(value) => out.push_str(&expand(mk, target, value, depth + 1)?),
+                        // make imports the environment, so an unknown variable is an env var
+                        None => out.push_str(&format!("${{{}}}", inner)),
+                    },
+                }
+                while chars.peek().map(|&(j, _)| j <= end).unwrap_or(false) {
+                    chars.next();
+                }
+            }
+            Some(auto) => return Err(format!("usa a variável automática ${}", auto)),
+            None => out.push('$'),
+        }
+    }
+    Ok(out)
+}
+
+struct Outcome {
+    code: i32,
+    stdout: String,
+}
+
+fn run_make(project_dir: &Path, target: &str) -> std::io::Result<Outcome> {
+    let mut cmd = Command::new("make");
+    cmd.arg("-s").arg("--no-print-directory").arg(target).current_dir(project_dir);
+    let out = crate::sandbox::wrap(project_dir, cmd).map_err(std::io::Error::other)?.stdin(Stdio::null()).output()?;
+    Ok(Outcome { code: out.status.code().unwrap_or(1), stdout: String::from_utf8_lossy(&out.stdout).into_owned() })
+}
+
+/// Run the converted task (and its dependencies) as `dx run` would after the migration.
+fn run_dx(project_dir: &Path, dx_file: &DxFile, mk: &Makefile, name: &str, task: &Task) -> std::io::Result<Outcome> {
+    let mut candidate = dx_file.clone();
+    candidate.tasks.insert(name.to_string(), task.clone());
+    let mut stdout = String::new();
+    let code = task_graph::execute(project_dir, &candidate, Some(mk), name, Some(&mut stdout))
+        .map_err(std::io::Error::other)?;
+    Ok(Outcome { code, stdout })
+}
+
+/// `dx migrate makefile`: convert Makefile targets into dx.yaml tasks.
+/// The targets named in `verify` really run, with make and with dx, and are only kept when both
+/// agree; that runs the project's recipes, so the project must be trusted first.
+pub fn cmd_migrate(dir: Option<PathBuf>, save_file: bool, verify: &[String]) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(path) = find(&project_dir) else {
+        eprintln!("Nenhum Makefile encontrado em {}", project_dir.display());
+        return;
+    };
+    let mk = match fs::read_to_string(&path) {
+        Ok(c) => parse(&c),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", path.display(), e);
+            return;
+        }
+    };
+    let mut dx_file = match tasks::load(&project_dir) {
+        Ok(f) => f.unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", tasks::DX_FILE, e);
+            return;
+        }
+    };
+
+    if let Some(unknown) = verify.iter().find(|v| !mk.targets.iter().any(|t| &t.name == *v)) {
+        eprintln!("Erro: o alvo '{}' de --verify não existe em {}", unknown, path.display());
+        crate::exit(2);
+    }
+    if !verify.is_empty() {
+        crate::trust::ensure_trusted(&project_dir);
+    }
+
+    println!("Migrando {} para {}:", path.display(), tasks::DX_FILE);
+    let mut migrated = 0;
+    for target in &mk.targets {
+        if dx_file.tasks.contains_key(&target.name) {
+            println!("  = {:<20} já existe em {} (mantido)", target.name, tasks::DX_FILE);
+            continue;
+        }
+        let task = match to_task(&mk, target) {
+            Ok(t) => t,
+            Err(reason) => {
+                println!("  - {:<20} mantido no Makefile: {}", target.name, reason);
+                continue;
+            }
+        };
+        if verify.contains(&target.name) {
+            let make_result = run_make(&project_dir, &target.name);
+            let dx_result = run_dx(&project_dir, &dx_file, &mk, &target.name, &task);
+            match (make_result, dx_result) {
+                // make reports recipe failures with its own exit code (2), so compare success only
+                (Ok(m), Ok(d)) if (m.code == 0) == (d.code == 0) => {
+                    let note = if m.stdout.trim() == d.stdout.trim() { "" } else { " (saídas diferentes)" };
+                    let outcome = if m.code == 0 { "sucesso" } else { "falha" };
+                    println!("  ✔ {:<20} verificado: make e dx terminaram com {}{}", target.name, outcome, note);
+                }
+                (Ok(m), Ok(d)) => {
+                    println!(
+                        "  ✖ {:<20} não equivalente: make terminou com {}, dx com {} (mantido no Makefile)",
+                        target.name, m.code, d.code
+                    );
+                    continue;
+                }
+                (Err(e), _) | (_, Err(e)) => {
+                    println!("  ✖ {:<20} não verificado: {} (mantido no Makefile)", target.name, e);
+                    continue;
+                }
+            }
+        } else {
+            println!("  + {:<20} {}", target.name, task.commands().join(" && "));
+        }
+        dx_file.tasks.insert(target.name.clone(), task);
+        migrated += 1;
+    }
+
+    if migrated == 0 {
+        println!("Nenhum alvo convertido.");
+        return;
+    }
+    if !save_file {
+        match serde_yaml::to_string(&dx_file) {
+            Ok(yaml) => println!("\n{}", yaml),
+            Err(e) => eprintln!("Erro ao gerar {}: {}", tasks::DX_FILE, e),
+        }
+        return;
+    }
+    match tasks::save(&project_dir, &dx_file) {
+        Ok(p) => {
+            println!("\n{} tarefa(s) migrada(s) para {}", migrated, p.display());
+            println!("O Makefile não foi alterado. Execute as tarefas com: dx run <tarefa>");
+        }
+        Err(e) => eprintln!("Erro ao salvar {}: {}", tasks::DX_FILE, e),
+    }
+}
@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::io::{IsTerminal, Write};
+use std::process::{Command, Stdio};
+use std::sync::OnceLock;
+use std::time::{Duration, Instant};
+
+/// Environment variable with the default threshold (seconds) for `--notify-after`.
+pub const NOTIFY_AFTER_ENV: &str = "DX_NOTIFY_AFTER";
+
+struct Tracker {
+    started: Instant,
+    threshold: Duration,
+    label: String,
+}
+
+static TRACKER: OnceLock<Tracker> = OnceLock::new();
+
+/// Start timing the current command. `threshold_secs` is the resolved `notify_after` setting
+/// (`--notify-after`, `DX_NOTIFY_AFTER`, dx.yaml or user config). Zero or no value disables notifications.
+pub fn init(threshold_secs: Option<u64>, label: String) {
+    let Some(secs) = threshold_secs.filter(|s| *s > 0) else { return };
+    let _ = TRACKER.set(Tracker { started: Instant::now(), threshold: Duration::from_secs(secs), label });
+}
+
+/// Notify if the command ran longer than the threshold. Safe to call when disabled.
+pub fn finish(code: i32) {
+    let Some(t) = TRACKER.get() else { return };
+    let elapsed = t.started.elapsed();
+    if elapsed < t.threshold {
+        return;
+    }
+    let title = if code == 0 { "dx: concluído" } else { "dx: falhou" };
+    let body = if code == 0 {
+        format!("{} terminou em {}", t.label, format_duration(elapsed))
+    } else {
+        format!("{} falhou (código {}) após {}", t.label, code, format_duration(elapsed))
+    };
+    ring_bell();
+    if !desktop_notify(title, &body) {
+        eprintln!("{}: {}", title, body);
+    }
+}
+
+/// Human label for the invoked command: `dx dev-services run`, without flags or their values.
+pub fn command_label(args: &[String]) -> String {
+    let mut words = vec!["dx".to_string()];
+    let mut skip_value = false;
+    for a in args {
+        if skip_value {
+            skip_value = false;
+            continue;
+        }
+        if a == "--notify-after" {
+            skip_value = true;
+            continue;
+        }
+        if a.starts_with('-') {
+            continue;
+        }
+        words.push(a.clone());
+        if words.len() == 4 {
+            break;
+        }
+    }
+    words.join(" ")
+}
+
+fn format_duration(d: Duration) -> String {
+    let secs = d.as_secs();
+    if secs >= 60 {
+        format!("{}min{:02}s", secs / 60, secs % 60)
+    } else {
+        format!("{:.1}s", d.as_secs_f64())
+    }
+}
+
+/// Terminal bell, only when a person is likely watching the terminal.
+fn ring_bell() {
+    let mut stderr = std::io::stderr();
+    if stderr.is_terminal() {
+        let _ = stderr.write_all(b"\x07");
+        let _ = stderr.flush();
+    }
+}
+
+/// Best-effort desktop notification; returns false when no notifier is available.
+fn desktop_notify(title: &str, body: &str) -> bool {
+    let mut cmd = if cfg!(target_os = "macos") {
+        let script = format!(
+            "display notification \"{}\" with title \"{}\"",
+            body.replace('"', "\\\""),
+            title.replace('"', "\\\"")
+        );
+        let mut c = Command::new("osascript");
+        c.arg("-e").arg(script);
+        c
+    } else if cfg!(windows) {
+        let script = format!(
+            "Add-Type -AssemblyName System.Windows.Forms; \
+             $n = New-Object System.Windows.Forms.NotifyIcon; \
+             $n.Icon = [System.Drawing.SystemIcons]::Information; $n.Visible = $true; \
+             $n.ShowBalloonTip(5000, '{}', '{}', 'Info'); Start-Sleep -Seconds 5; $n.Dispose()",
+            title.replace('\'', "''"),
+            body.replace('\'', "''")
+        );
+        let mut c = Command::new("powershell");
+        c.args(["-NoProfile", "-Command", &script]);
+        c
+    } else {
+        let mut c = Command::new("notify-send");
+        c.args(["--app-name=dx", title, body]);
+        c
+    };
+    cmd.stdin(Stdio::null()).stdout(Stdio::null()).stderr(Stdio::null());
+    matches!(cmd.status(), Ok(s) if s.success())
+}
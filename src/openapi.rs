@@ -0,0 +1,468 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `dx dev-routes openapi`: an OpenAPI 3.1 skeleton of the HTTP API — the routes of
+//! `dx dev-routes list`, with the request bodies, query parameters and responses of the Go
+//! handlers inferred from the structs they bind (`c.ShouldBindJSON(&input)`, with the validator
+//! rules of the `binding` tags) and the values they answer with (`c.JSON(http.StatusOK, user)`;
+//! RFC 7807 `Problem` structs under `application/problem+json`).
+
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+
+use serde::Serialize;
+use serde_json::{json, Map, Value};
+
+use crate::asyncapi::call_args;
+use crate::dev_routes::Route;
+use crate::kafka_events::{braced, go_var_type, ident_at, simple_name, word_positions, Code};
+
+const REFS: &str = "#/components/schemas/";
+
+/// `net/http` status constants, with their reason phrases.
+const STATUSES: &[(&str, u16, &str)] = &[
+    ("StatusOK", 200, "OK"),
+    ("StatusCreated", 201, "Created"),
+    ("StatusAccepted", 202, "Accepted"),
+    ("StatusNoContent", 204, "No Content"),
+    ("StatusMovedPermanently", 301, "Moved Permanently"),
+    ("StatusFound", 302, "Found"),
+    ("StatusNotModified", 304, "Not Modified"),
+    ("StatusBadRequest", 400, "Bad Request"),
+    ("StatusUnauthorized", 401, "Unauthorized"),
+    ("StatusForbidden", 403, "Forbidden"),
+    ("StatusNotFound", 404, "Not Found"),
+    ("StatusMethodNotAllowed", 405, "Method Not Allowed"),
+    ("StatusConflict", 409, "Conflict"),
+    ("StatusGone", 410, "Gone"),
+    ("StatusUnprocessableEntity", 422, "Unprocessable Entity"),
+    ("StatusTooManyRequests", 429, "Too Many Requests"),
+    ("StatusInternalServerError", 500, "Internal Server Error"),
+    ("StatusNotImplemented", 501, "Not Implemented"),
+    ("StatusBadGateway", 502, "Bad Gateway"),
+    ("StatusServiceUnavailable", 503, "Service Unavailable"),
+];
+
+/// Calls that decode the request body into a variable: gin, echo, fiber and `encoding/json`.
+const BODY_BINDERS: &[&str] = &["ShouldBindJSON(&", "BindJSON(&", "ShouldBind(&", ".Bind(&", "BodyParser(&", "Decode(&"];
+
+/// Calls that decode the query string into a struct: gin.
+const QUERY_BINDERS: &[&str] = &["ShouldBindQuery(&", "BindQuery(&"];
+
+/// Single query parameters: gin (`Query`, `DefaultQuery`), echo (`QueryParam`) and fiber (`Query`).
+const QUERY_GETTERS: &[&str] = &[".Query(", ".DefaultQuery(", ".QueryParam("];
+
+/// Calls that answer with a status and a JSON value: gin, echo.
+const JSON_WRITERS: &[&str] = &[".JSON(", ".IndentedJSON(", ".AbortWithStatusJSON("];
+
+/// Map literals answered as ad hoc objects (`gin.H{"error": ...}`).
+const MAP_LITERALS: &[&str] = &["gin.H{", "echo.Map{", "fiber.Map{", "map[string]interface{}{", "map[string]any{"];
+
+/// Structs answered as RFC 7807 problem details, under `application/problem+json`.
+const PROBLEM_TYPES: &[&str] = &["Problem", "ProblemDetail", "ProblemDetails"];
+
+#[derive(Serialize)]
+struct Document {
+    openapi: &'static str,
+    info: Info,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    servers: Vec<Server>,
+    paths: BTreeMap<String, BTreeMap<String, Operation>>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    components: Option<Components>,
+}
+
+#[derive(Serialize)]
+struct Info {
+    title: String,
+    version: String,
+    description: String,
+}
+
+#[derive(Serialize)]
+struct Server {
+    url: String,
+    description: &'static str,
+}
+
+#[derive(Serialize)]
+#[serde(rename_all = "camelCase")]
+struct Operation {
+    operation_id: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    summary: Option<String>,
+    description: String,
+    #[serde(skip_serializing_if = "Vec::is_empty")]
+    parameters: Vec<Value>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    request_body: Option<Value>,
+    responses: BTreeMap<String, Value>,
+}
+
+#[derive(Serialize)]
+struct Components {
+    schemas: BTreeMap<String, Value>,
+}
+
+/// What a handler reads and answers, as far as its body shows.
+#[derive(Default)]
+struct Handler {
+    /// First sentence of the doc comment
+    summary: Option<String>,
+    body: Option<Value>,
+    query: Vec<Value>,
+    responses: BTreeMap<String, Value>,
+}
+
+fn status(expr: &str) -> Option<(u16, &'static str)> {
+    let expr = expr.trim();
+    if let Ok(code) = expr.parse::<u16>() {
+        let reason = STATUSES.iter().find(|(_, c, _)| *c == code).map_or("Resposta", |(_, _, r)| *r);
+        return Some((code, reason));
+    }
+    let name = simple_name(expr);
+    STATUSES.iter().find(|(n, _, _)| *n == name).map(|(_, code, reason)| (*code, *reason))
+}
+
+/// Go files whose functions describe the app: not tests nor generated code (mocks).
+fn sources(code: &Code) -> impl Iterator<Item = &str> {
+    code.go_files()
+        .filter(|(rel, content)| !rel.ends_with("_test.go") && !content.starts_with("// Code generated"))
+        .map(|(_, content)| content)
+}
+
+/// Start of the line holding `at`.
+fn line_start(text: &str, at: usize) -> usize {
+    text[..at].rfind('\n').map_or(0, |i| i + 1)
+}
+
+/// Parameters that make a function an HTTP handler: gin, echo, fiber and `net/http`.
+const HANDLER_PARAMS: &[&str] = &["*gin.Context", "echo.Context", "*fiber.Ctx", "http.ResponseWriter"];
+
+/// Body and doc comment of the Go function or method `name`. Methods are matched by name alone, so
+/// when another type has a method of the same name (a gRPC server next to the REST handlers), the
+/// one taking an HTTP handler's parameters wins.
+fn func_decl<'a>(code: &'a Code, name: &str) -> Option<(&'a str, Option<String>)> {
+    let mut fallback = None;
+    for content in sources(code) {
+        for at in word_positions(content, name) {
+            let start = line_start(content, at);
+            if !content[start..at].starts_with("func ") || !content[at + name.len()..].starts_with('(') {
+                continue;
+            }
+            let end = content[at..].find('\n').map_or(content.len(), |i| at + i);
+            let signature = content[..end].trim_end();
+            if !signature.ends_with('{') {
+                continue;
+            }
+            let mut comment: Vec<&str> = content[..start].lines().rev().take_while(|l| l.starts_with("//")).collect();
+            comment.reverse();
+            let comment = comment.iter().map(|l| l.trim_start_matches('/').trim()).collect::<Vec<_>>().join(" ");
+            // The first sentence, as godoc shows it
+            let summary = comment.split_once(". ").map_or(comment.as_str(), |(first, _)| first).trim_end_matches('.');
+            let decl = (braced(content, signature.len() - 1), (!summary.is_empty()).then(|| summary.to_string()));
+            if HANDLER_PARAMS.iter().any(|p| signature[at..].contains(p)) {
+                return Some(decl);
+            }
+            fallback.get_or_insert(decl);
+        }
+    }
+    fallback
+}
+
+/// Result types of the Go function, method or interface method `name` (`*models.User`, `error`).
+fn results(code: &Code, name: &str) -> Vec<String> {
+    for content in sources(code) {
+        for at in word_positions(content, name) {
+            let prefix = content[line_start(content, at)..at].trim();
+            if !(prefix.is_empty() || prefix == "func" || prefix.starts_with("func ")) || !content[at + name.len()..].starts_with('(') {
+                continue;
+            }
+            let rest = &content[at + name.len() + 1..];
+            let mut depth = 0;
+            let Some(close) = rest.find(|c: char| {
+                depth += match c {
+                    '(' => 1,
+                    ')' => -1,
+                    _ => 0,
+                };
+                depth < 0
+            }) else {
+                continue;
+            };
+            let rest = &rest[close + 1..];
+            let signature = rest[..rest.find(['\n', '{']).unwrap_or(rest.len())].trim();
+            let list = signature.strip_prefix('(').and_then(|s| s.strip_suffix(')')).unwrap_or(signature);
+            // Named results keep only their type: `(user *models.User, err error)`
+            return list.split(',').filter_map(|r| r.split_whitespace().last()).map(str::to_string).collect();
+        }
+    }
+    Vec::new()
+}
+
+/// The Go type of `var`: declared in `body`, or the matching result of the call assigned to it
+/// (`user, err := h.userRepo.FindByID(...)`).
+fn var_type(code: &Code, body: &str, var: &str) -> Option<String> {
+    if let Some(ty) = go_var_type(body, var).filter(|t| code.has_struct(t)) {
+        return Some(ty);
+    }
+    for line in body.lines() {
+        let Some((lhs, rhs)) = line.split_once(":=").or_else(|| line.split_once(" = ")) else { continue };
+        let Some(index) = lhs.split(',').position(|v| v.trim() == var) else { continue };
+        let call = rhs.trim();
+        let Some(open) = call.find('(') else { continue };
+        let method = simple_name(&call[..open]);
+        if let Some(ty) = results(code, method).into_iter().nth(index) {
+            return Some(ty);
+        }
+    }
+    None
+}
+
+/// Schema of a value a handler answers with, or `None` when it answers without content (`nil`).
+fn value_schema(code: &Code, body: &str, expr: &str, pending: &mut BTreeSet<String>) -> Option<Value> {
+    let expr = expr.trim();
+    if expr == "nil" {
+        return None;
+    }
+    if let Some(prefix) = MAP_LITERALS.iter().find(|p| expr.starts_with(**p)) {
+        let mut properties = Map::new();
+        for entry in call_args(&expr[prefix.len()..]) {
+            let Some((key, value)) = entry.split_once(':') else { continue };
+            let key = key.trim().trim_matches(['"', '`']);
+            let value = value.trim();
+            let schema = if value.starts_with(['"', '`']) || value.ends_with(".Error()") || value.starts_with("fmt.Sprint") {
+                json!({"type": "string"})
+            } else if value.parse::<i64>().is_ok() {
+                json!({"type": "integer"})
+            } else if value == "true" || value == "false" {
+                json!({"type": "boolean"})
+            } else {
+                json!({})
+            };
+            properties.insert(key.to_string(), schema);
+        }
+        return Some(json!({"type": "object", "properties": properties}));
+    }
+    let value = expr.trim_start_matches(['&', '*']);
+    if let Some(open) = value.find('{').filter(|&i| i > 0 && !value[..i].contains(['(', ' '])) {
+        return Some(code.type_schema(&value[..open], REFS, pending));
+    }
+    // A call answered directly (`validationProblem(c, err)`): its first result type
+    let callee = ident_at(value, true);
+    if !callee.is_empty() && value[callee.len()..].starts_with('(') && value.ends_with(')') {
+        if let Some(ty) = results(code, simple_name(callee)).into_iter().next() {
+            return Some(code.type_schema(&ty, REFS, pending));
+        }
+    }
+    let name = ident_at(value, false);
+    if !name.is_empty() && name.len() == value.len() {
+        if let Some(ty) = var_type(code, body, name) {
+            return Some(code.type_schema(&ty, REFS, pending));
+        }
+    }
+    Some(json!({}))
+}
+
+/// Media type of a response with `schema`: problem details or plain JSON.
+fn media_type(schema: &Value) -> &'static str {
+    let problem = schema["$ref"].as_str().and_then(|r| r.strip_prefix(REFS)).is_some_and(|name| PROBLEM_TYPES.contains(&name));
+    if problem {
+        "application/problem+json"
+    } else {
+        "application/json"
+    }
+}
+
+/// The variable a binder call decodes into (`input` of `c.ShouldBindJSON(&input)`).
+fn bound_vars<'a>(body: &'a str, binders: &[&str]) -> Vec<&'a str> {
+    binders
+        .iter()
+        .flat_map(|b| body.match_indices(b).map(move |(at, _)| ident_at(&body[at + b.len()..], false)))
+        .filter(|v| !v.is_empty())
+        .collect()
+}
+
+fn query_param(name: &str, schema: Value, required: bool) -> Value {
+    json!({"name": name, "in": "query", "required": required, "schema": schema})
+}
+
+fn analyze(code: &Code, name: &str, pending: &mut BTreeSet<String>) -> Handler {
+    let Some((body, summary)) = func_decl(code, name) else {
+        return Handler::default();
+    };
+    let mut handler = Handler { summary, ..Handler::default() };
+
+    if let Some(ty) = bound_vars(body, BODY_BINDERS).into_iter().find_map(|v| go_var_type(body, v).filter(|t| code.has_struct(t))) {
+        pending.insert(ty.clone());
+        handler.body = Some(json!({
+            "required": true,
+            "content": {"application/json": {"schema": {"$ref": format!("{}{}", REFS, ty)}}}
+        }));
+    }
+
+    let mut names = BTreeSet::new();
+    for ty in bound_vars(body, QUERY_BINDERS).into_iter().filter_map(|v| go_var_type(body, v)) {
+        for (param, schema, required) in code.query_params(&ty, REFS) {
+            if names.insert(param.clone()) {
+                handler.query.push(query_param(&param, schema, required));
+            }
+        }
+    }
+    for getter in QUERY_GETTERS {
+        for (at, _) in body.match_indices(getter) {
+            let Some(param) = crate::dev_kafka::literals_after(&body[at + getter.len()..]).into_iter().next() else { continue };
+            if names.insert(param.clone()) {
+                handler.query.push(query_param(&param, json!({"type": "string"}), false));
+            }
+        }
+    }
+
+    for writer in JSON_WRITERS {
+        for (at, _) in body.match_indices(writer) {
+            let args = call_args(&body[at + writer.len()..]);
+            let (code_expr, value) = match args.as_slice() {
+                [value] => ("200", *value),
+                [code_expr, value, ..] => (*code_expr, *value),
+                [] => continue,
+            };
+            let Some((status, reason)) = status(code_expr) else { continue };
+            if handler.responses.contains_key(&status.to_string()) {
+                continue;
+            }
+            let response = match value_schema(code, body, value, pending) {
+                Some(schema) => json!({"description": reason, "content": {(media_type(&schema)): {"schema": schema}}}),
+                None => json!({"description": reason}),
+            };
+            handler.responses.insert(status.to_string(), response);
+        }
+    }
+    handler
+}
+
+/// The handler's Go function or method name, when the registration names one (`userHandler.GetAllUsers`).
+fn handler_name(route: &Route) -> Option<&str> {
+    let handler = route.handler.as_deref()?;
+    let name = simple_name(handler);
+    (!name.is_empty() && handler.chars().all(|c| c.is_ascii_alphanumeric() || c == '_' || c == '.')).then_some(name)
+}
+
+fn lower_first(name: &str) -> String {
+    let mut chars = name.chars();
+    chars.next().map(|c| c.to_ascii_lowercase().to_string() + chars.as_str()).unwrap_or_default()
+}
+
+fn document(root: &Path, routes: &[Route]) -> Document {
+    let code = Code::load(root);
+    let mut pending = BTreeSet::new();
+    let mut handlers: BTreeMap<&str, Handler> = BTreeMap::new();
+    let mut uses = BTreeMap::new();
+    for name in routes.iter().filter_map(handler_name) {
+        *uses.entry(name).or_insert(0) += 1;
+        if !handlers.contains_key(name) {
+            handlers.insert(name, analyze(&code, name, &mut pending));
+        }
+    }
+
+    let mut paths: BTreeMap<String, BTreeMap<String, Operation>> = BTreeMap::new();
+    for route in routes {
+        let method = if route.method == "ANY" { "get".to_string() } else { route.method.to_lowercase() };
+        let name = handler_name(route);
+        let operation_id = match name {
+            // A handler registered on several routes would repeat its operationId
+            Some(name) if uses[name] == 1 => lower_first(name),
+            _ => crate::api_client::operation_name(&method, &route.path),
+        };
+        let file = route.location.split(':').next().unwrap_or(&route.location);
+        let description = match route.handler.as_deref().filter(|h| *h != "função anônima") {
+            Some(handler) => format!("Handler `{}` ({}).", handler, file),
+            None => format!("Registrada em {}.", file),
+        };
+        let mut parameters: Vec<Value> = route
+            .path
+            .split('/')
+            .filter_map(|s| s.strip_prefix('{')?.strip_suffix('}'))
+            .map(|p| json!({"name": p, "in": "path", "required": true, "schema": {"type": "string"}}))
+            .collect();
+        let handler = name.and_then(|n| handlers.get(n));
+        let mut responses = BTreeMap::new();
+        if let Some(handler) = handler {
+            parameters.extend(handler.query.iter().cloned());
+            responses = handler.responses.clone();
+        }
+        if responses.is_empty() {
+            responses.insert("200".to_string(), json!({"description": "OK"}));
+        }
+        let operation = Operation {
+            operation_id,
+            summary: handler.and_then(|h| h.summary.clone()),
+            description,
+            parameters,
+            request_body: handler.and_then(|h| h.body.clone()),
+            responses,
+        };
+        paths.entry(route.path.clone()).or_default().insert(method, operation);
+    }
+
+    let schemas = code.schemas(&pending, REFS);
+    let stack = crate::dev_config::Stack::detect(root);
+    let port = crate::devcontainer::app_port(&crate::dev_env::scan(root)).or(crate::dockerfile::default_port(stack));
+    let title = root.canonicalize().ok().and_then(|p| p.file_name().map(|n| n.to_string_lossy().into_owned())).unwrap_or_else(|| "api".to_string());
+    Document {
+        openapi: "3.1.0",
+        info: Info {
+            title,
+            version: "1.0.0".to_string(),
+            description: "Gerado por dx dev-routes openapi a partir das rotas e dos tipos do código; complete descrições e exemplos.".to_string(),
+        },
+        servers: port.map(|port| Server { url: format!("http://localhost:{}", port), description: "Desenvolvimento local" }).into_iter().collect(),
+        paths,
+        components: (!schemas.is_empty()).then_some(Components { schemas }),
+    }
+}
+
+pub fn cmd_openapi(out: Option<PathBuf>, check: bool, dir: Option<PathBuf>) -> i32 {
+    let root = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let routes = crate::dev_routes::detect(&root);
+    if routes.is_empty() {
+        eprintln!("Nenhuma rota HTTP encontrada em {}.", root.display());
+        return 2;
+    }
+    let doc = document(&root, &routes);
+    let path = out.unwrap_or_else(|| PathBuf::from("openapi.yaml"));
+    let path = if path.is_absolute() { path } else { root.join(path) };
+    let shown = path.strip_prefix(&root).unwrap_or(&path).display().to_string();
+    let content = match path.extension().and_then(|e| e.to_str()) {
+        Some("json") => serde_json::to_string_pretty(&doc).map(|s| s + "\n").map_err(|e| e.to_string()),
+        _ => serde_yaml::to_string(&doc).map_err(|e| e.to_string()),
+    };
+    let content = match content {
+        Ok(content) => content,
+        Err(e) => {
+            eprintln!("Erro ao gerar o documento OpenAPI: {}", e);
+            return 1;
+        }
+    };
+    let current = fs::read_to_string(&path).ok();
+    if check {
+        if current.as_deref() == Some(content.as_str()) {
+            println!("✓ {} em dia com o código.", shown);
+            return 0;
+        }
+        println!("✗ {} desatualizado em relação ao código; rode `dx dev-routes openapi` para atualizá-lo.", shown);
+        return 1;
+    }
+    let operations: usize = doc.paths.values().map(BTreeMap::len).sum();
+    let schemas = doc.components.as_ref().map_or(0, |c| c.schemas.len());
+    println!("OpenAPI 3.1 com {} operação(ões) e {} schema(s) em {}", operations, schemas, shown);
+    if current.as_deref() == Some(content.as_str()) {
+        return 0;
+    }
+    let written = path.parent().map_or(Ok(()), fs::create_dir_all).and_then(|_| crate::audit::write(&path, &content));
+    if let Err(e) = written {
+        eprintln!("Erro ao escrever {}: {}", path.display(), e);
+        return 1;
+    }
+    0
+}
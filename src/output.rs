@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `--output json`: machine-readable output for every command that reports something. Commands with
+//! their own `--format` switch to JSON when it is not given; the others print their JSON here.
+//! `--output sarif` does the same, except for the lint-style commands, which write a SARIF log.
+
+use serde::Serialize;
+
+/// How commands write their results (`--output`).
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, clap::ValueEnum)]
+pub enum OutputMode {
+    /// Human-readable text
+    #[default]
+    Text,
+    /// JSON on stdout, for scripts and other tools
+    Json,
+    /// SARIF 2.1.0 from audit and lint-style commands (GitHub code scanning); JSON from the others
+    Sarif,
+}
+
+impl OutputMode {
+    pub fn key(self) -> &'static str {
+        match self {
+            OutputMode::Text => "text",
+            OutputMode::Json => "json",
+            OutputMode::Sarif => "sarif",
+        }
+    }
+}
+
+/// Whether the results go out as JSON (`--output json`, `DX_OUTPUT=json` or the configuration).
+/// Under `--output sarif`, commands without a SARIF format fall back to JSON.
+pub fn json() -> bool {
+    matches!(crate::settings::get("output").as_str(), "json" | "sarif")
+}
+
+/// Whether the lint-style commands write SARIF (`--output sarif`).
+pub fn sarif() -> bool {
+    crate::settings::get("output") == "sarif"
+}
+
+/// The format of a command with its own `--format`: the explicit one wins; otherwise `json`
+/// under `--output json` and `default` in text mode.
+pub fn format<F>(explicit: Option<F>, json: F, default: F) -> F {
+    explicit.unwrap_or(if self::json() { json } else { default })
+}
+
+/// The format of a command that can also write SARIF: like [`format`], with `sarif` under
+/// `--output sarif`.
+pub fn format_or_sarif<F>(explicit: Option<F>, sarif: F, json: F, default: F) -> F {
+    match explicit {
+        Some(f) => f,
+        None if self::sarif() => sarif,
+        None => format(None, json, default),
+    }
+}
+
+/// Print `value` as pretty JSON on stdout.
+pub fn print<T: Serialize>(value: &T) {
+    println!("{}", serde_json::to_string_pretty(value).unwrap_or_default());
+}
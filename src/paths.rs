@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+
+/// Overrides the user configuration directory (mainly for tests and CI).
+pub const CONFIG_DIR_ENV: &str = "DX_CONFIG_DIR";
+/// Overrides the user state directory (mainly for tests and CI).
+pub const STATE_DIR_ENV: &str = "DX_STATE_DIR";
+/// Overrides the user cache directory (mainly for tests and CI).
+pub const CACHE_DIR_ENV: &str = "DX_CACHE_DIR";
+
+pub(crate) fn home() -> PathBuf {
+    PathBuf::from(std::env::var_os("HOME").or_else(|| std::env::var_os("USERPROFILE")).unwrap_or_default())
+}
+
+/// `$<override>`, then `$<xdg>/dx`, then `%<windows>%\dx` on Windows, then `~/<fallback>/dx`.
+fn user_dir(override_env: &str, xdg_env: &str, windows_env: &str, fallback: &str) -> PathBuf {
+    if let Some(d) = std::env::var_os(override_env).filter(|d| !d.is_empty()) {
+        return PathBuf::from(d);
+    }
+    if let Some(d) = std::env::var_os(xdg_env).filter(|d| !d.is_empty()) {
+        return PathBuf::from(d).join("dx");
+    }
+    if cfg!(windows) {
+        if let Some(d) = std::env::var_os(windows_env) {
+            return PathBuf::from(d).join("dx");
+        }
+    }
+    home().join(fallback).join("dx")
+}
+
+/// User settings that travel between machines (`dx config export/import`):
+/// `$DX_CONFIG_DIR`, `$XDG_CONFIG_HOME/dx`, `%APPDATA%\dx` or `~/.config/dx`.
+pub fn config_dir() -> PathBuf {
+    user_dir(CONFIG_DIR_ENV, "XDG_CONFIG_HOME", "APPDATA", ".config")
+}
+
+/// Machine-specific state (trust decisions keyed by absolute path):
+/// `$DX_STATE_DIR`, `$XDG_STATE_HOME/dx`, `%LOCALAPPDATA%\dx` or `~/.local/state/dx`.
+pub fn state_dir() -> PathBuf {
+    user_dir(STATE_DIR_ENV, "XDG_STATE_HOME", "LOCALAPPDATA", ".local/state")
+}
+
+/// Data dx can rebuild at any time (detection results keyed by content hashes):
+/// `$DX_CACHE_DIR`, `$XDG_CACHE_HOME/dx`, `%LOCALAPPDATA%\dx` or `~/.cache/dx`.
+pub fn cache_dir() -> PathBuf {
+    user_dir(CACHE_DIR_ENV, "XDG_CACHE_HOME", "LOCALAPPDATA", ".cache")
+}
+
+/// Files written by earlier versions and where they live now.
+fn legacy_files() -> Vec<(PathBuf, PathBuf)> {
+    // The trust store started out next to the settings
+    vec![(config_dir().join("trust.json"), state_dir().join("trust.json"))]
+}
+
+fn move_file(from: &Path, to: &Path) -> io::Result<()> {
+    if let Some(parent) = to.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    // rename fails across filesystems (e.g. XDG dirs on another mount)
+    fs::rename(from, to).or_else(|_| fs::copy(from, to).and_then(|_| fs::remove_file(from)))
+}
+
+/// Move files from legacy locations to the XDG directories. Existing files at the new
+/// location win; the legacy copy is then left alone.
+pub fn migrate_legacy() {
+    for (from, to) in legacy_files() {
+        if from == to || !from.is_file() || to.exists() {
+            continue;
+        }
+        match move_file(&from, &to) {
+            Ok(()) => eprintln!("dx: {} movido para {}", from.display(), to.display()),
+            Err(e) => eprintln!("Aviso: não foi possível mover {} para {}: {}", from.display(), to.display(), e),
+        }
+    }
+}
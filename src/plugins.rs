@@ -0,0 +1,565 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Plugins: company-specific commands, detectors and generators without forking dx.
+//!
+//! - `dx-<name>` executables on PATH run as `dx <name> [args]`, like git subcommands.
+//! - Manifests declare richer plugins: `<config>/plugins/<name>.yaml` for the user, `plugins:` of
+//!   dx.yaml for the project (trusted with `dx allow`, like its scripts). The `stdio` protocol
+//!   speaks JSON-RPC 2.0 over stdin/stdout, one message per line, and lets a plugin detect
+//!   services (`detect`) and generate files (`generate`) besides running commands (`command`).
+
+use serde::{Deserialize, Serialize};
+use std::collections::{BTreeMap, HashMap};
+use std::fs;
+use std::io::{BufRead, BufReader, Write};
+use std::path::{Component, Path, PathBuf};
+use std::process::{Command, Stdio};
+use std::sync::{Mutex, OnceLock};
+
+/// Prefix of the plugin executables looked up on PATH.
+pub const PREFIX: &str = "dx-";
+/// Directory of the user plugin manifests, under the configuration directory.
+const PLUGINS_DIR: &str = "plugins";
+/// Version of the stdio protocol, sent to plugins in every request.
+const PROTOCOL_VERSION: u32 = 1;
+
+/// How dx talks to the plugin.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Protocol {
+    /// Run with the arguments and the terminal, like `dx-<name>` on PATH
+    #[default]
+    Exec,
+    /// JSON-RPC 2.0 over stdin/stdout
+    Stdio,
+    /// Declared by the manifest, not supported yet
+    Grpc,
+}
+
+impl Protocol {
+    fn key(self) -> &'static str {
+        match self {
+            Protocol::Exec => "exec",
+            Protocol::Stdio => "stdio",
+            Protocol::Grpc => "grpc",
+        }
+    }
+}
+
+/// What the plugin offers.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Capability {
+    /// `dx <name> [args]`
+    Command,
+    /// Services added to the Dev Services detection (analyzer, dev-services, dev-env...)
+    Detect,
+    /// Files written by `dx generate plugin <name>`
+    Generate,
+}
+
+impl Capability {
+    fn key(self) -> &'static str {
+        match self {
+            Capability::Command => "command",
+            Capability::Detect => "detect",
+            Capability::Generate => "generate",
+        }
+    }
+}
+
+/// A plugin manifest: a file in `<config>/plugins/` or an entry of `plugins:` in dx.yaml.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct Manifest {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub description: Option<String>,
+    /// Program to run: a path relative to the manifest (the plugins directory, or the project
+    /// for dx.yaml), or a name looked up on PATH
+    pub command: String,
+    /// Arguments placed before the ones of the user
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub args: Vec<String>,
+    #[serde(default)]
+    pub protocol: Protocol,
+    /// What the plugin offers (default: command)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub capabilities: Vec<Capability>,
+}
+
+/// Where a plugin was found, in lookup order.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Source {
+    /// `plugins:` of dx.yaml
+    Project,
+    /// `<config>/plugins/<name>.yaml`
+    User,
+    /// `dx-<name>` on PATH
+    Path,
+}
+
+#[derive(Debug, Clone)]
+pub struct Plugin {
+    pub name: String,
+    pub source: Source,
+    pub manifest: Manifest,
+    /// Resolved program
+    pub program: PathBuf,
+}
+
+impl Plugin {
+    fn capabilities(&self) -> Vec<Capability> {
+        if self.manifest.capabilities.is_empty() { vec![Capability::Command] } else { self.manifest.capabilities.clone() }
+    }
+
+    fn offers(&self, capability: Capability) -> bool {
+        self.capabilities().contains(&capability)
+    }
+
+    /// The process of the plugin, run in `project_dir` with the dx context in its environment.
+    fn command(&self, project_dir: &Path) -> Command {
+        let project_dir = absolute(project_dir);
+        let mut command = Command::new(&self.program);
+        command
+            .args(&self.manifest.args)
+            .current_dir(&project_dir)
+            .env("DX_PLUGIN_NAME", &self.name)
+            .env("DX_PROJECT_DIR", &project_dir)
+            .env("DX_VERSION", env!("CARGO_PKG_VERSION"))
+            .env("DX_OUTPUT", crate::settings::get("output"))
+            .env("DX_BIN", std::env::current_exe().unwrap_or_else(|_| "dx".into()));
+        command
+    }
+
+    /// Project plugins run only when the user trusts dx.yaml, and inside the sandbox if so decided.
+    fn prepare(&self, project_dir: &Path, command: Command) -> Result<Command, String> {
+        if self.source != Source::Project {
+            return Ok(command);
+        }
+        crate::trust::ensure_trusted(project_dir);
+        crate::sandbox::wrap(project_dir, command)
+    }
+}
+
+fn absolute(dir: &Path) -> PathBuf {
+    fs::canonicalize(dir).unwrap_or_else(|_| dir.to_path_buf())
+}
+
+fn valid_name(name: &str) -> bool {
+    !name.is_empty() && name.chars().all(|c| c.is_ascii_lowercase() || c.is_ascii_digit() || c == '-' || c == '_')
+}
+
+/// `command` of a manifest: relative to `base` when it is a path, otherwise looked up on PATH.
+fn resolve_program(base: &Path, command: &str) -> PathBuf {
+    if command.contains(['/', '\\']) {
+        return base.join(command);
+    }
+    crate::sandbox::find_in_path(command)
+        .or_else(|| crate::sandbox::find_in_path(&format!("{}{}", command, std::env::consts::EXE_SUFFIX)))
+        .unwrap_or_else(|| PathBuf::from(command))
+}
+
+/// Name and program of the project plugins whose program is a file of the project
+/// (`command: ./tools/lint.sh`), which the trust decision covers along with dx.yaml.
+pub fn project_programs(project_dir: &Path) -> Vec<(String, PathBuf)> {
+    project_plugins(project_dir)
+        .into_iter()
+        .filter(|p| p.program.starts_with(project_dir) && p.program.is_file())
+        .map(|p| (p.name, p.program))
+        .collect()
+}
+
+fn user_dir() -> PathBuf {
+    crate::paths::config_dir().join(PLUGINS_DIR)
+}
+
+/// Plugins declared in `plugins:` of the project's dx.yaml.
+fn project_plugins(project_dir: &Path) -> Vec<Plugin> {
+    let Ok(Some(dx_file)) = crate::tasks::load(project_dir) else { return Vec::new() };
+    dx_file
+        .plugins
+        .into_iter()
+        .filter(|(name, _)| valid_name(name))
+        .map(|(name, manifest)| {
+            let program = resolve_program(project_dir, &manifest.command);
+            Plugin { name, source: Source::Project, manifest, program }
+        })
+        .collect()
+}
+
+/// Manifests of the user, one per file (`<name>.yaml`).
+fn user_plugins() -> Vec<Plugin> {
+    let dir = user_dir();
+    let Ok(entries) = fs::read_dir(&dir) else { return Vec::new() };
+    let mut plugins = Vec::new();
+    for path in entries.flatten().map(|e| e.path()) {
+        if !matches!(path.extension().and_then(|e| e.to_str()), Some("yaml" | "yml")) {
+            continue;
+        }
+        let Some(name) = path.file_stem().and_then(|s| s.to_str()).filter(|n| valid_name(n)) else { continue };
+        let manifest = match fs::read_to_string(&path).map_err(|e| e.to_string()).and_then(|c| serde_yaml::from_str::<Manifest>(&c).map_err(|e| e.to_string())) {
+            Ok(m) => m,
+            Err(e) => {
+                eprintln!("Aviso: manifesto de plugin inválido em {}: {}", path.display(), e);
+                continue;
+            }
+        };
+        let program = resolve_program(&dir, &manifest.command);
+        plugins.push(Plugin { name: name.to_string(), source: Source::User, manifest, program });
+    }
+    plugins.sort_by(|a, b| a.name.cmp(&b.name));
+    plugins
+}
+
+#[cfg(unix)]
+fn is_executable(path: &Path) -> bool {
+    use std::os::unix::fs::PermissionsExt;
+    fs::metadata(path).is_ok_and(|m| m.is_file() && m.permissions().mode() & 0o111 != 0)
+}
+
+#[cfg(not(unix))]
+fn is_executable(path: &Path) -> bool {
+    path.is_file()
+}
+
+/// `dx-<name>` executables on PATH; the first directory wins, as in the shell.
+fn path_plugins() -> Vec<Plugin> {
+    let Some(paths) = std::env::var_os("PATH") else { return Vec::new() };
+    let current = std::env::current_exe().ok().and_then(|p| fs::canonicalize(p).ok());
+    let mut found: BTreeMap<String, PathBuf> = BTreeMap::new();
+    for dir in std::env::split_paths(&paths) {
+        let Ok(entries) = fs::read_dir(&dir) else { continue };
+        for path in entries.flatten().map(|e| e.path()) {
+            let Some(file) = path.file_name().and_then(|f| f.to_str()) else { continue };
+            let file = file.strip_suffix(std::env::consts::EXE_SUFFIX).unwrap_or(file);
+            let Some(name) = file.strip_prefix(PREFIX).filter(|n| valid_name(n)) else { continue };
+            // dx itself may be installed under a dx- name
+            if found.contains_key(name) || !is_executable(&path) || fs::canonicalize(&path).ok() == current {
+                continue;
+            }
+            found.insert(name.to_string(), path);
+        }
+    }
+    found.into_iter().map(|(name, program)| Plugin { name, source: Source::Path, manifest: Manifest::default(), program }).collect()
+}
+
+/// Every plugin visible from `project_dir`: the project's, then the user's, then PATH. A name
+/// found twice keeps the first.
+pub fn discover(project_dir: &Path) -> Vec<Plugin> {
+    let mut plugins: Vec<Plugin> = Vec::new();
+    for plugin in project_plugins(project_dir).into_iter().chain(user_plugins()).chain(path_plugins()) {
+        if !plugins.iter().any(|p| p.name == plugin.name) {
+            plugins.push(plugin);
+        }
+    }
+    plugins
+}
+
+/// The plugin called `name`, looked up in the same order as [`discover`].
+pub fn find(project_dir: &Path, name: &str) -> Option<Plugin> {
+    if !valid_name(name) {
+        return None;
+    }
+    if let Some(plugin) = project_plugins(project_dir).into_iter().chain(user_plugins()).find(|p| p.name == name) {
+        return Some(plugin);
+    }
+    let file = format!("{}{}", PREFIX, name);
+    let program = crate::sandbox::find_in_path(&file)
+        .or_else(|| crate::sandbox::find_in_path(&format!("{}{}", file, std::env::consts::EXE_SUFFIX)))
+        .filter(|p| is_executable(p))?;
+    Some(Plugin { name: name.to_string(), source: Source::Path, manifest: Manifest::default(), program })
+}
+
+/// Send one JSON-RPC request to a stdio plugin and wait for its response. `log` notifications
+/// (`{"method": "log", "params": {"message": ...}}`) go to stderr meanwhile; the plugin's own
+/// stderr is the terminal's.
+fn call(plugin: &Plugin, project_dir: &Path, method: &str, mut params: serde_json::Value) -> Result<serde_json::Value, String> {
+    match plugin.manifest.protocol {
+        Protocol::Stdio => {}
+        Protocol::Exec => return Err(format!("'{}' exige protocol: stdio no manifesto", method)),
+        Protocol::Grpc => return Err("o protocolo grpc ainda não é suportado por esta versão do dx; use stdio".to_string()),
+    }
+    params["project_dir"] = serde_json::Value::String(absolute(project_dir).display().to_string());
+    params["dx_version"] = serde_json::Value::String(env!("CARGO_PKG_VERSION").to_string());
+    params["protocol_version"] = serde_json::Value::from(PROTOCOL_VERSION);
+
+    let mut command = plugin.prepare(project_dir, plugin.command(project_dir))?;
+    command.stdin(Stdio::piped()).stdout(Stdio::piped()).stderr(Stdio::inherit());
+    let mut child = command.spawn().map_err(|e| format!("não foi possível executar {}: {}", plugin.program.display(), e))?;
+    let request = serde_json::json!({ "jsonrpc": "2.0", "id": 1, "method": method, "params": params });
+    if let Some(mut stdin) = child.stdin.take() {
+        // A plugin that does not read the request fails below, without a response
+        let _ = writeln!(stdin, "{}", request);
+    }
+
+    let mut response = None;
+    if let Some(stdout) = child.stdout.take() {
+        for line in BufReader::new(stdout).lines() {
+            let line = line.map_err(|e| e.to_string())?;
+            if line.trim().is_empty() {
+                continue;
+            }
+            let message: serde_json::Value = match serde_json::from_str(&line) {
+                Ok(m) => m,
+                Err(_) => {
+                    let _ = child.kill();
+                    let _ = child.wait();
+                    return Err(format!("saída fora do protocolo no stdout: {}", line.chars().take(120).collect::<String>()));
+                }
+            };
+            if message.get("id").is_none() {
+                if message["method"] == "log" {
+                    eprintln!("[{}] {}", plugin.name, message["params"]["message"].as_str().unwrap_or_default());
+                }
+                continue;
+            }
+            response = Some(match message.get("error") {
+                Some(error) => Err(format!("{} (código {})", error["message"].as_str().unwrap_or("erro sem mensagem"), error["code"])),
+                None => Ok(message.get("result").cloned().unwrap_or(serde_json::Value::Null)),
+            });
+            break;
+        }
+    }
+    let status = child.wait().map_err(|e| e.to_string())?;
+    response.unwrap_or_else(|| {
+        Err(match status.code() {
+            Some(code) => format!("o plugin terminou sem responder (código {})", code),
+            None => "o plugin terminou sem responder".to_string(),
+        })
+    })
+}
+
+/// `dx <name> [args]` for a plugin: exec plugins get the arguments and the terminal; stdio plugins
+/// receive a `command` request and answer `{exit_code, text, json}`. Returns the exit code.
+pub fn run(plugin: &Plugin, project_dir: &Path, args: &[String]) -> i32 {
+    if !plugin.offers(Capability::Command) {
+        eprintln!("O plugin '{}' não oferece comandos (capabilities: {}).", plugin.name, capability_list(plugin));
+        return 2;
+    }
+    if plugin.manifest.protocol != Protocol::Exec {
+        let params = serde_json::json!({ "args": args, "output": crate::settings::get("output") });
+        return match call(plugin, project_dir, "command", params) {
+            Ok(result) => {
+                match (result.get("json"), result["text"].as_str()) {
+                    (Some(json), _) if crate::output::json() => crate::output::print(json),
+                    (_, Some(text)) => print!("{}{}", text, if text.ends_with('\n') { "" } else { "\n" }),
+                    _ => {}
+                }
+                result["exit_code"].as_i64().map_or(0, |c| c as i32)
+            }
+            Err(e) => {
+                eprintln!("Erro no plugin '{}': {}", plugin.name, e);
+                2
+            }
+        };
+    }
+    let mut command = match plugin.prepare(project_dir, plugin.command(project_dir)) {
+        Ok(c) => c,
+        Err(e) => {
+            eprintln!("Erro: {}", e);
+            return 2;
+        }
+    };
+    match command.args(args).status() {
+        Ok(status) => status.code().unwrap_or(1),
+        Err(e) => {
+            eprintln!("Erro ao executar o plugin '{}' ({}): {}", plugin.name, plugin.program.display(), e);
+            1
+        }
+    }
+}
+
+/// A service reported by a detector plugin. Without `image`, `name` must be a service dx knows
+/// (postgres, kafka, redis...).
+#[derive(Debug, Clone, Deserialize)]
+pub struct DetectedService {
+    pub name: String,
+    #[serde(default)]
+    pub image: Option<String>,
+    #[serde(default)]
+    pub ports: Vec<u16>,
+    #[serde(default)]
+    pub env: BTreeMap<String, String>,
+}
+
+#[derive(Deserialize)]
+struct DetectResult {
+    #[serde(default)]
+    services: Vec<DetectedService>,
+}
+
+/// Services the detector plugins find in `project_dir`, asked once per project and process.
+/// Plugins of an untrusted dx.yaml are skipped (with a warning) rather than asked about, since
+/// detection runs inside many commands.
+pub fn detect(project_dir: &Path) -> Vec<DetectedService> {
+    static CACHE: OnceLock<Mutex<HashMap<PathBuf, Vec<DetectedService>>>> = OnceLock::new();
+    let key = absolute(project_dir);
+    if let Some(found) = CACHE.get_or_init(Default::default).lock().unwrap().get(&key) {
+        return found.clone();
+    }
+
+    let mut found = Vec::new();
+    let detectors = project_plugins(project_dir).into_iter().chain(user_plugins()).filter(|p| p.offers(Capability::Detect));
+    let mut skipped = Vec::new();
+    for plugin in detectors {
+        if plugin.source == Source::Project && crate::trust::allowed(project_dir).is_none() {
+            skipped.push(plugin.name);
+            continue;
+        }
+        match call(&plugin, project_dir, "detect", serde_json::json!({})).and_then(|r| serde_json::from_value::<DetectResult>(r).map_err(|e| e.to_string())) {
+            Ok(result) => found.extend(result.services),
+            Err(e) => eprintln!("Aviso: detector do plugin '{}' falhou: {}", plugin.name, e),
+        }
+    }
+    if !skipped.is_empty() {
+        eprintln!(
+            "Aviso: detectores do {} ignorados ({}): autorize o projeto com dx allow {}",
+            crate::tasks::DX_FILE,
+            skipped.join(", "),
+            project_dir.display()
+        );
+    }
+    CACHE.get_or_init(Default::default).lock().unwrap().insert(key, found.clone());
+    found
+}
+
+#[derive(Deserialize)]
+struct GeneratedFile {
+    path: String,
+    content: String,
+}
+
+#[derive(Deserialize)]
+struct GenerateResult {
+    #[serde(default)]
+    files: Vec<GeneratedFile>,
+    #[serde(default)]
+    message: Option<String>,
+}
+
+/// Paths a generator may write: relative and inside the project.
+fn inside_project(path: &str) -> bool {
+    let path = Path::new(path);
+    !path.as_os_str().is_empty() && path.components().all(|c| matches!(c, Component::Normal(_) | Component::CurDir))
+}
+
+/// `dx generate plugin <name>`: ask a generator plugin for files and write them in the project
+/// (through the audit log, so `dx undo` reverts them). Returns the exit code.
+pub fn cmd_generate(name: String, args: Vec<String>, dry_run: bool, dir: Option<PathBuf>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(plugin) = find(&project_dir, &name) else {
+        eprintln!("Plugin '{}' não encontrado. Veja os disponíveis com 'dx plugins'.", name);
+        return 2;
+    };
+    if !plugin.offers(Capability::Generate) {
+        eprintln!("O plugin '{}' não oferece geradores (capabilities: {}).", plugin.name, capability_list(&plugin));
+        return 2;
+    }
+    let params = serde_json::json!({ "args": args, "dry_run": dry_run });
+    let result = match call(&plugin, &project_dir, "generate", params).and_then(|r| serde_json::from_value::<GenerateResult>(r).map_err(|e| e.to_string())) {
+        Ok(r) => r,
+        Err(e) => {
+            eprintln!("Erro no plugin '{}': {}", plugin.name, e);
+            return 2;
+        }
+    };
+    if let Some(bad) = result.files.iter().find(|f| !inside_project(&f.path)) {
+        eprintln!("O plugin '{}' pediu para escrever fora do projeto ({}); nada foi gravado.", plugin.name, bad.path);
+        return 1;
+    }
+
+    println!("{} os arquivos do plugin {} em {}:", if dry_run { "Geraria" } else { "Gerando" }, plugin.name, project_dir.display());
+    for file in &result.files {
+        let path = project_dir.join(&file.path);
+        match fs::read_to_string(&path) {
+            Ok(current) if current == file.content => {
+                println!("  = {} (sem mudanças)", file.path);
+                continue;
+            }
+            Ok(_) => println!("  ~ {}", file.path),
+            Err(_) => println!("  + {}", file.path),
+        }
+        if dry_run {
+            continue;
+        }
+        let written = fs::create_dir_all(path.parent().unwrap_or(&project_dir)).and_then(|_| crate::audit::write(&path, &file.content));
+        if let Err(e) = written {
+            eprintln!("Erro ao escrever {}: {}", path.display(), e);
+            return 1;
+        }
+    }
+    if result.files.is_empty() {
+        println!("  (nenhum arquivo)");
+    }
+    if let Some(message) = result.message {
+        println!("\n{}", message);
+    }
+    0
+}
+
+fn capability_list(plugin: &Plugin) -> String {
+    plugin.capabilities().iter().map(|c| c.key()).collect::<Vec<_>>().join(", ")
+}
+
+#[derive(Serialize)]
+struct Listed {
+    name: String,
+    source: Source,
+    protocol: &'static str,
+    capabilities: Vec<&'static str>,
+    program: String,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    description: Option<String>,
+    /// A built-in command or a dx.yaml alias/command with the same name runs instead
+    shadowed: bool,
+}
+
+/// `dx plugins`: list the plugins visible from the project. `builtin` holds the names of dx's own
+/// commands, which win over plugins.
+pub fn cmd_list(dir: Option<PathBuf>, builtin: &[String]) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let dx_file = crate::tasks::load(&project_dir).ok().flatten().unwrap_or_default();
+    let listed: Vec<Listed> = discover(&project_dir)
+        .into_iter()
+        .map(|p| Listed {
+            shadowed: builtin.contains(&p.name) || dx_file.aliases.contains_key(&p.name) || dx_file.commands.contains_key(&p.name),
+            capabilities: p.capabilities().iter().map(|c| c.key()).collect(),
+            protocol: p.manifest.protocol.key(),
+            program: p.program.display().to_string(),
+            description: p.manifest.description.clone(),
+            source: p.source,
+            name: p.name,
+        })
+        .collect();
+    if crate::output::json() {
+        crate::output::print(&listed);
+        return;
+    }
+    if listed.is_empty() {
+        println!(
+            "Nenhum plugin encontrado (executáveis {}<nome> no PATH, manifestos em {} ou plugins: do {}).",
+            PREFIX,
+            user_dir().display(),
+            crate::tasks::DX_FILE
+        );
+        return;
+    }
+    println!("Plugins ({}):", listed.len());
+    let width = listed.iter().map(|p| p.name.len()).max().unwrap_or(0);
+    for p in &listed {
+        let source = match p.source {
+            Source::Project => crate::tasks::DX_FILE,
+            Source::User => "usuário",
+            Source::Path => "PATH",
+        };
+        let mut line = format!("  {:<width$}  {:<5}  {:<24}  {}: {}", p.name, p.protocol, p.capabilities.join(", "), source, p.program);
+        if let Some(description) = &p.description {
+            line.push_str(&format!(" — {}", description));
+        }
+        if p.shadowed {
+            line.push_str(" (oculto por um comando de mesmo nome)");
+        }
+        println!("{}", line);
+    }
+}
@@ -0,0 +1,120 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! `dx cache prefetch`: ask the registries in advance about every dependency of the projects of a
+//! directory (latest versions, parent POMs and BOMs, OSV advisories), in parallel, so that
+//! `dx dev-dependencies`, `audit` and the analyzer report then answer from the registry cache,
+//! and keep answering with `offline` once the network is gone. Licenses need no prefetch: they
+//! are read from the installed packages.
+
+use serde::Serialize;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+
+/// Log of `dx cache prefetch --background`, in the state directory.
+const LOG_FILE: &str = "prefetch.log";
+
+#[derive(Serialize)]
+struct Prefetched {
+    project: String,
+    /// Development dependencies, with their latest versions
+    dependencies: usize,
+    /// Pinned packages checked against OSV
+    packages: usize,
+    /// Advisories affecting them; absent when OSV could not be queried
+    #[serde(skip_serializing_if = "Option::is_none")]
+    advisories: Option<usize>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    error: Option<String>,
+}
+
+fn prefetch(root: &Path, project: &Path) -> Prefetched {
+    let dependencies = crate::dev_dependencies::get_dependencies(project).map(|d| d.len()).unwrap_or(0);
+    let packages = crate::dependency_audit::collect(project);
+    let (advisories, error) = match packages.is_empty() {
+        true => (Some(0), None),
+        false => match crate::dependency_audit::query(&packages) {
+            Ok(findings) => (Some(findings.len()), None),
+            Err(e) => (None, Some(e)),
+        },
+    };
+    Prefetched { project: crate::scan::display(root, project), dependencies, packages: packages.len(), advisories, error }
+}
+
+/// Start `dx cache prefetch <dir>` detached, its output going to the log; returns at once.
+fn spawn_background(dir: &Path) -> i32 {
+    let log_path = crate::paths::state_dir().join(LOG_FILE);
+    let log = fs::create_dir_all(crate::paths::state_dir()).and_then(|_| fs::File::create(&log_path));
+    let spawned = log.and_then(|log| {
+        let exe = std::env::current_exe().unwrap_or_else(|_| "dx".into());
+        Command::new(exe)
+            .args(["cache", "prefetch"])
+            .arg(dir)
+            .env(crate::notifications::NOTIFY_AFTER_ENV, "0")
+            .env(crate::history::HISTORY_ENV, "0")
+            .stdin(Stdio::null())
+            .stdout(log.try_clone()?)
+            .stderr(log)
+            .spawn()
+    });
+    match spawned {
+        Ok(child) => {
+            println!("Pré-carregamento iniciado em segundo plano (pid {}); log em {}", child.id(), log_path.display());
+            0
+        }
+        Err(e) => {
+            eprintln!("Erro ao iniciar o pré-carregamento em segundo plano: {}", e);
+            1
+        }
+    }
+}
+
+/// `dx cache prefetch [--background] [<dir>]`: warm the registry cache for the project in `dir`,
+/// or for each project under it.
+pub fn cmd_prefetch(dir: PathBuf, background: bool) -> i32 {
+    if crate::registry::offline() {
+        eprintln!("Erro: o modo offline está ativo (configuração offline); não há como consultar os registries.");
+        return 2;
+    }
+    let Some(ttl) = crate::registry::cache_ttl_label() else {
+        eprintln!("Erro: o cache dos registries está desativado (registry_cache_ttl = 0); não há onde guardar as respostas.");
+        return 2;
+    };
+    let root = dir.canonicalize().unwrap_or(dir);
+    if background {
+        return spawn_background(&root);
+    }
+    let projects = match crate::scan::is_project_root(&root) {
+        true => vec![root.clone()],
+        false => crate::scan::subprojects(&root),
+    };
+    if projects.is_empty() {
+        eprintln!("Nenhum projeto encontrado em {}.", root.display());
+        return 1;
+    }
+
+    let phase = crate::progress::Phase::start("cache.prefetch", "Consultando os registries para as dependências dos projetos");
+    let rows = crate::scan::each(&root, &projects, &phase, |project| prefetch(&root, project));
+    let complete = rows.iter().all(|r| r.error.is_none());
+    phase.finish(complete);
+    if crate::output::json() {
+        crate::output::print(&rows);
+        return if complete { 0 } else { 1 };
+    }
+    for row in &rows {
+        let advisories = match (&row.advisories, &row.error) {
+            (Some(n), _) => format!("{} alerta(s)", n),
+            (None, Some(e)) => format!("vulnerabilidades sem resposta: {}", e),
+            (None, None) => "-".to_string(),
+        };
+        let name = if row.project.is_empty() { "." } else { row.project.as_str() };
+        println!("  {}: {} dependência(s), {} pacote(s) auditado(s), {}", name, row.dependencies, row.packages, advisories);
+    }
+    println!(
+        "✓ Respostas guardadas em {}: valem por {} (registry_cache_ttl) e seguem disponíveis com --offline.",
+        crate::registry::cache_path().display(),
+        ttl
+    );
+    if complete { 0 } else { 1 }
+}
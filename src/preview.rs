@@ -0,0 +1,787 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Preview environments (`dx preview`): the application image built from the project and deployed,
+//! with the infrastructure services it uses, to a namespace of a local kind cluster or of a remote
+//! cluster. Each preview gets a URL to share and an expiry (the `dx.dev/expires-at` annotation of
+//! its namespace), after which it is removed automatically: a CronJob in the namespace deletes it,
+//! and `dx preview prune` deletes the expired ones it finds in the cluster, whichever machine created
+//! them. Previews are tracked in the state directory.
+
+use crate::dev_services::DockerService;
+use crate::environments::{canonical, now};
+use crate::k8s::{quote, Workload};
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::Write;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Stdio};
+
+/// Previews created by `dx preview create`, in the state directory.
+const PREVIEWS_FILE: &str = "previews.json";
+/// Node ports the kind cluster maps to the same host ports, one per preview.
+const NODE_PORTS: std::ops::Range<u16> = 30100..30110;
+const NAMESPACE_PREFIX: &str = "preview-";
+const MANAGED_BY: &str = "app.kubernetes.io/managed-by";
+const PREVIEW_LABEL: &str = "dx.dev/preview";
+const EXPIRES_ANNOTATION: &str = "dx.dev/expires-at";
+/// ServiceAccount and CronJob that delete the namespace of a preview once it expires.
+const JANITOR: &str = "dx-preview-janitor";
+const JANITOR_SCHEDULE: &str = "*/10 * * * *";
+/// Run by the janitor in the preview namespace: delete it once the expiry annotation has passed.
+const JANITOR_SCRIPT: &str = "expires=$(kubectl get namespace \"$NAMESPACE\" -o jsonpath='{.metadata.annotations.dx\\.dev/expires-at}') || exit 1; \
+    [ -n \"$expires\" ] || exit 0; \
+    deadline=$(date -u -d \"$expires\" +%s) || exit 1; \
+    [ \"$(date -u +%s)\" -lt \"$deadline\" ] || kubectl delete namespace \"$NAMESPACE\" --wait=false";
+/// How long `create` waits for each Deployment to become ready.
+const ROLLOUT_TIMEOUT: &str = "180s";
+const HOUR: u64 = 60 * 60;
+
+/// Where a preview runs.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize, Deserialize, clap::ValueEnum)]
+#[serde(rename_all = "lowercase")]
+pub enum Target {
+    /// Cluster kind local, criado quando não existe
+    Kind,
+    /// Namespace no cluster do contexto do kubectl (atual ou --context)
+    Cluster,
+}
+
+impl Target {
+    pub fn key(self) -> &'static str {
+        match self {
+            Target::Kind => "kind",
+            Target::Cluster => "cluster",
+        }
+    }
+}
+
+/// Validator of the `preview_target` setting.
+pub fn validate_target(v: &str) -> Result<String, String> {
+    match v.trim().to_lowercase().as_str() {
+        t @ ("kind" | "cluster") => Ok(t.to_string()),
+        _ => Err("espera kind ou cluster".to_string()),
+    }
+}
+
+fn configured_target() -> Target {
+    match crate::settings::get("preview_target").as_str() {
+        "cluster" => Target::Cluster,
+        _ => Target::Kind,
+    }
+}
+
+/// A deployed preview: where it runs, the image it runs and when it expires (Unix seconds).
+#[derive(Serialize, Deserialize, Clone)]
+pub struct Preview {
+    pub dir: PathBuf,
+    pub target: Target,
+    pub context: String,
+    pub namespace: String,
+    pub image: String,
+    /// Port of the application on the kind node (and on localhost)
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub node_port: Option<u16>,
+    /// None on a remote cluster without `preview_domain`
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub url: Option<String>,
+    pub created: u64,
+    pub expires: u64,
+}
+
+/// Tracked previews by name.
+pub type Previews = BTreeMap<String, Preview>;
+
+pub fn previews_path() -> PathBuf {
+    crate::paths::state_dir().join(PREVIEWS_FILE)
+}
+
+pub fn load() -> Previews {
+    fs::read_to_string(previews_path()).ok().and_then(|c| serde_json::from_str(&c).ok()).unwrap_or_default()
+}
+
+/// Read-modify-write of the tracked previews, under the file's lock.
+fn update(change: impl FnOnce(&mut Previews)) -> std::io::Result<()> {
+    let path = previews_path();
+    let _lock = crate::lock::for_file(&path)?;
+    let mut previews = load();
+    change(&mut previews);
+    crate::lock::write_atomic(&path, serde_json::to_string_pretty(&previews).unwrap_or_default())
+}
+
+/// Stop tracking the previews `names`, reporting (not failing on) a write error.
+fn forget(names: &[String]) {
+    if let Err(e) = update(|previews| previews.retain(|name, _| !names.contains(name))) {
+        eprintln!("Aviso: falha ao atualizar {}: {}", previews_path().display(), e);
+    }
+}
+
+/// `text` as a DNS-1123 label: lower-case letters, digits and single dashes, at most `max` characters.
+fn label(text: &str, max: usize) -> String {
+    let mut out = String::new();
+    for c in text.to_lowercase().chars() {
+        if c.is_ascii_alphanumeric() {
+            out.push(c);
+        } else if !out.is_empty() && !out.ends_with('-') {
+            out.push('-');
+        }
+    }
+    out.chars().take(max).collect::<String>().trim_end_matches('-').to_string()
+}
+
+/// Branch checked out in `project_dir`; None outside a Git repository or on a detached HEAD.
+fn branch(project_dir: &Path) -> Option<String> {
+    let output = Command::new("git")
+        .arg("-C")
+        .arg(project_dir)
+        .args(["rev-parse", "--abbrev-ref", "HEAD"])
+        .stderr(Stdio::null())
+        .output()
+        .ok()
+        .filter(|o| o.status.success())?;
+    let branch = String::from_utf8_lossy(&output.stdout).trim().to_string();
+    (!branch.is_empty() && branch != "HEAD").then_some(branch)
+}
+
+/// Name of the preview of `app` in `project_dir`: the application and its branch, short enough to
+/// fit in the `preview-` namespace.
+fn preview_name(project_dir: &Path, app: &str) -> String {
+    let full = branch(project_dir).map_or_else(|| app.to_string(), |b| format!("{}-{}", app, b));
+    label(&full, 63 - NAMESPACE_PREFIX.len())
+}
+
+fn render_namespace(namespace: &str, name: &str, expires: u64) -> String {
+    let expires = crate::dev_kafka::format_timestamp(expires as i64 * 1000).replace(".000Z", "Z");
+    format!(
+        "apiVersion: v1\n\
+         kind: Namespace\n\
+         metadata:\n\
+         \x20 name: {namespace}\n\
+         \x20 labels:\n\
+         \x20   {MANAGED_BY}: dx\n\
+         \x20   {PREVIEW_LABEL}: {name}\n\
+         \x20 annotations:\n\
+         \x20   {EXPIRES_ANNOTATION}: {}\n",
+        quote(&expires)
+    )
+}
+
+/// Expiry written by `render_namespace` (`2025-01-31T12:00:00Z`) in Unix seconds.
+fn parse_expiry(text: &str) -> Option<u64> {
+    let (date, time) = text.trim().strip_suffix('Z')?.split_once('T')?;
+    let number = |part: Option<&str>| part.and_then(|p| p.parse::<i64>().ok());
+    let mut date = date.splitn(3, '-');
+    let (year, month, day) = (number(date.next())?, number(date.next())?, number(date.next())?);
+    let mut time = time.split('.').next()?.splitn(3, ':');
+    let (hour, minute, second) = (number(time.next())?, number(time.next())?, number(time.next())?);
+    if !(1..=12).contains(&month) || !(1..=31).contains(&day) {
+        return None;
+    }
+    // Days since 1970-01-01 from the civil date (Howard Hinnant's algorithm)
+    let year = if month <= 2 { year - 1 } else { year };
+    let era = year.div_euclid(400);
+    let yoe = year - era * 400;
+    let doy = (153 * ((month + 9) % 12) + 2) / 5 + day - 1;
+    let days = era * 146_097 + yoe * 365 + yoe / 4 - yoe / 100 + doy - 719_468;
+    u64::try_from(days * 86_400 + hour * 3600 + minute * 60 + second).ok()
+}
+
+/// ServiceAccount and CronJob that delete the preview namespace once its expiry annotation has
+/// passed, so a preview goes away even when no dx runs against the cluster.
+fn render_janitor(image: &str) -> String {
+    format!(
+        "apiVersion: v1\n\
+         kind: ServiceAccount\n\
+         metadata:\n\
+         \x20 name: {JANITOR}\n\
+         \x20 labels:\n\
+         \x20   {MANAGED_BY}: dx\n\
+         ---\n\
+         apiVersion: batch/v1\n\
+         kind: CronJob\n\
+         metadata:\n\
+         \x20 name: {JANITOR}\n\
+         \x20 labels:\n\
+         \x20   {MANAGED_BY}: dx\n\
+         spec:\n\
+         \x20 schedule: {schedule}\n\
+         \x20 concurrencyPolicy: Forbid\n\
+         \x20 successfulJobsHistoryLimit: 1\n\
+         \x20 failedJobsHistoryLimit: 1\n\
+         \x20 jobTemplate:\n\
+         \x20   spec:\n\
+         \x20     backoffLimit: 1\n\
+         \x20     template:\n\
+         \x20       spec:\n\
+         \x20         serviceAccountName: {JANITOR}\n\
+         \x20         restartPolicy: Never\n\
+         \x20         containers:\n\
+         \x20           - name: janitor\n\
+         \x20             image: {image}\n\
+         \x20             env:\n\
+         \x20               - name: NAMESPACE\n\
+         \x20                 valueFrom:\n\
+         \x20                   fieldRef:\n\
+         \x20                     fieldPath: metadata.namespace\n\
+         \x20             command: [\"/bin/sh\", \"-c\", {script}]\n",
+        schedule = quote(JANITOR_SCHEDULE),
+        script = quote(JANITOR_SCRIPT)
+    )
+}
+
+/// ClusterRole and ClusterRoleBinding that let the janitor read and delete its own namespace (and
+/// nothing else). With the namespace `uid` they are owned by it, so Kubernetes removes them with the
+/// namespace however it is deleted.
+fn render_janitor_access(namespace: &str, uid: Option<&str>) -> String {
+    let owner = uid.map_or_else(String::new, |uid| {
+        format!("  ownerReferences:\n    - apiVersion: v1\n      kind: Namespace\n      name: {namespace}\n      uid: {uid}\n")
+    });
+    format!(
+        "apiVersion: rbac.authorization.k8s.io/v1\n\
+         kind: ClusterRole\n\
+         metadata:\n\
+         \x20 name: {JANITOR}-{namespace}\n\
+         \x20 labels:\n\
+         \x20   {MANAGED_BY}: dx\n\
+         {owner}\
+         rules:\n\
+         \x20 - apiGroups: [\"\"]\n\
+         \x20   resources: [namespaces]\n\
+         \x20   resourceNames: [{namespace}]\n\
+         \x20   verbs: [get, delete]\n\
+         ---\n\
+         apiVersion: rbac.authorization.k8s.io/v1\n\
+         kind: ClusterRoleBinding\n\
+         metadata:\n\
+         \x20 name: {JANITOR}-{namespace}\n\
+         \x20 labels:\n\
+         \x20   {MANAGED_BY}: dx\n\
+         {owner}\
+         roleRef:\n\
+         \x20 apiGroup: rbac.authorization.k8s.io\n\
+         \x20 kind: ClusterRole\n\
+         \x20 name: {JANITOR}-{namespace}\n\
+         subjects:\n\
+         \x20 - kind: ServiceAccount\n\
+         \x20   name: {JANITOR}\n\
+         \x20   namespace: {namespace}\n"
+    )
+}
+
+/// Deployment and Service of an infrastructure service from its Dev Services definition, without
+/// volumes: a preview keeps no data.
+fn render_backing_service(name: &str, app: &str, service: &DockerService) -> String {
+    let mut container = format!("        - name: {name}\n          image: {}\n", service.image);
+    if let Some(command) = &service.command {
+        container.push_str("          args:\n");
+        for arg in command.split_whitespace() {
+            container.push_str(&format!("            - {}\n", quote(arg)));
+        }
+    }
+    let env: BTreeMap<&String, &String> = service.env.iter().collect();
+    if !env.is_empty() {
+        container.push_str("          env:\n");
+        for (key, value) in env {
+            container.push_str(&format!("            - name: {}\n              value: {}\n", key, quote(value)));
+        }
+    }
+    if !service.ports.is_empty() {
+        container.push_str("          ports:\n");
+        for port in &service.ports {
+            container.push_str(&format!("            - containerPort: {}\n", port));
+        }
+    }
+    let mut out = format!(
+        "apiVersion: apps/v1\n\
+         kind: Deployment\n\
+         metadata:\n\
+         \x20 name: {name}\n\
+         \x20 labels:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         \x20   app.kubernetes.io/part-of: {app}\n\
+         spec:\n\
+         \x20 replicas: 1\n\
+         \x20 selector:\n\
+         \x20   matchLabels:\n\
+         \x20     app.kubernetes.io/name: {name}\n\
+         \x20 template:\n\
+         \x20   metadata:\n\
+         \x20     labels:\n\
+         \x20       app.kubernetes.io/name: {name}\n\
+         \x20   spec:\n\
+         \x20     enableServiceLinks: false\n\
+         \x20     containers:\n\
+         {container}"
+    );
+    if !service.ports.is_empty() {
+        out.push_str(&format!(
+            "---\n\
+             apiVersion: v1\n\
+             kind: Service\n\
+             metadata:\n\
+             \x20 name: {name}\n\
+             \x20 labels:\n\
+             \x20   app.kubernetes.io/name: {name}\n\
+             \x20   app.kubernetes.io/part-of: {app}\n\
+             spec:\n\
+             \x20 selector:\n\
+             \x20   app.kubernetes.io/name: {name}\n\
+             \x20 ports:\n"
+        ));
+        for port in &service.ports {
+            out.push_str(&format!("    - name: port-{port}\n      port: {port}\n      targetPort: {port}\n"));
+        }
+    }
+    out
+}
+
+/// Service that exposes the application on a node port of the kind cluster.
+fn render_node_port(name: &str, node_port: u16) -> String {
+    format!(
+        "apiVersion: v1\n\
+         kind: Service\n\
+         metadata:\n\
+         \x20 name: {name}-preview\n\
+         \x20 labels:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         spec:\n\
+         \x20 type: NodePort\n\
+         \x20 selector:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         \x20 ports:\n\
+         \x20   - name: http\n\
+         \x20     port: 80\n\
+         \x20     targetPort: http\n\
+         \x20     nodePort: {node_port}\n"
+    )
+}
+
+fn render_ingress(name: &str, host: &str) -> String {
+    format!(
+        "apiVersion: networking.k8s.io/v1\n\
+         kind: Ingress\n\
+         metadata:\n\
+         \x20 name: {name}\n\
+         \x20 labels:\n\
+         \x20   app.kubernetes.io/name: {name}\n\
+         spec:\n\
+         \x20 rules:\n\
+         \x20   - host: {host}\n\
+         \x20     http:\n\
+         \x20       paths:\n\
+         \x20         - path: /\n\
+         \x20           pathType: Prefix\n\
+         \x20           backend:\n\
+         \x20             service:\n\
+         \x20               name: {name}\n\
+         \x20               port:\n\
+         \x20                 name: http\n"
+    )
+}
+
+/// Cluster kind with the node ports of the previews mapped to the same ports on localhost.
+fn render_kind_config() -> String {
+    let mut out = "kind: Cluster\napiVersion: kind.x-k8s.io/v1alpha4\nnodes:\n  - role: control-plane\n    extraPortMappings:\n".to_string();
+    for port in NODE_PORTS {
+        out.push_str(&format!("      - containerPort: {port}\n        hostPort: {port}\n"));
+    }
+    out
+}
+
+/// The objects of a preview in apply order: the Namespace, then the infrastructure services, then
+/// the application with what exposes it (a node port on kind, an Ingress on `host`), then the
+/// janitor that deletes the namespace when the preview expires.
+fn render(project_dir: &Path, workload: &Workload, preview: &Preview, name: &str, host: Option<&str>) -> (String, String) {
+    let Workload { name: app, port, health, ready, env, required, secrets, services } = workload;
+    let config = crate::dev_services::detect_dependencies(project_dir);
+    let mut objects = Vec::new();
+    for service in services {
+        match config.services.get(service) {
+            Some(definition) if !definition.image.is_empty() => objects.push(render_backing_service(service, app, definition)),
+            _ => eprintln!("Aviso: o serviço {} não tem imagem nos Dev Services; não será implantado no preview.", service),
+        }
+    }
+    objects.extend([
+        crate::k8s::render_configmap(app, env, required, secrets),
+        crate::k8s::render_deployment(app, &preview.image, *port, health.as_deref(), ready.as_deref(), !secrets.is_empty()),
+        crate::k8s::render_service(app),
+    ]);
+    if let Some(node_port) = preview.node_port {
+        objects.push(render_node_port(app, node_port));
+    }
+    if let Some(host) = host {
+        objects.push(render_ingress(app, host));
+    }
+    objects.push(render_janitor(&crate::settings::get("preview_janitor_image")));
+    (render_namespace(&preview.namespace, name, preview.expires), objects.join("---\n"))
+}
+
+/// Run `program` with its output on stderr (stdout stays for `--output json`) and `input` on stdin.
+/// False, after saying why, when it cannot run or fails.
+fn run(program: &str, args: &[&str], input: Option<&str>) -> bool {
+    let mut child = match Command::new(program)
+        .args(args)
+        .stdin(if input.is_some() { Stdio::piped() } else { Stdio::null() })
+        .stdout(std::io::stderr())
+        .stderr(Stdio::inherit())
+        .spawn()
+    {
+        Ok(child) => child,
+        Err(e) => {
+            eprintln!("Erro: não foi possível executar {}: {}", program, e);
+            return false;
+        }
+    };
+    if let (Some(input), Some(mut stdin)) = (input, child.stdin.take()) {
+        let _ = stdin.write_all(input.as_bytes());
+    }
+    match child.wait() {
+        Ok(status) if status.success() => true,
+        Ok(status) => {
+            eprintln!("Erro: {} {} saiu com {}.", program, args.first().unwrap_or(&""), status);
+            false
+        }
+        Err(e) => {
+            eprintln!("Erro: {} falhou: {}", program, e);
+            false
+        }
+    }
+}
+
+/// Trimmed stdout of `program`, None when it cannot run or fails.
+fn output(program: &str, args: &[&str]) -> Option<String> {
+    let output = Command::new(program).args(args).stderr(Stdio::null()).output().ok().filter(|o| o.status.success())?;
+    Some(String::from_utf8_lossy(&output.stdout).trim().to_string())
+}
+
+fn kind_clusters() -> Vec<String> {
+    output("kind", &["get", "clusters"]).map(|o| o.lines().map(str::to_string).collect()).unwrap_or_default()
+}
+
+/// Build the application image, from the project's Dockerfile or, without one, from the one
+/// `dx dev-config dockerfile` would generate.
+fn build(project_dir: &Path, image: &str) -> bool {
+    let context = project_dir.to_string_lossy();
+    if project_dir.join("Dockerfile").exists() {
+        return run("docker", &["build", "-t", image, &context], None);
+    }
+    match crate::dockerfile::render(project_dir) {
+        Ok(rendered) => {
+            println!("Sem Dockerfile no projeto; usando o gerado por dx dev-config dockerfile ({}).", rendered.stack);
+            run("docker", &["build", "-t", image, "-f", "-", &context], Some(&rendered.content))
+        }
+        Err(stack) => {
+            eprintln!("Erro: sem Dockerfile e a stack {} ainda não tem um gerado pelo dx (Go, Node.js e Python). Crie um Dockerfile.", stack);
+            false
+        }
+    }
+}
+
+/// Delete the namespace of a preview. A preview whose kind cluster no longer exists is gone already.
+fn delete(preview: &Preview) -> bool {
+    if preview.target == Target::Kind {
+        let cluster = preview.context.strip_prefix("kind-").unwrap_or(&preview.context);
+        if !kind_clusters().iter().any(|c| c == cluster) {
+            return true;
+        }
+    }
+    run("kubectl", &["--context", &preview.context, "delete", "namespace", &preview.namespace, "--ignore-not-found", "--wait=false"], None)
+}
+
+/// Delete the expired previews; false when one of them could not be deleted.
+fn prune_expired(previews: &Previews) -> bool {
+    let expired: Vec<(&String, &Preview)> = previews.iter().filter(|(_, p)| p.expires <= now()).collect();
+    let mut removed = Vec::new();
+    for (name, preview) in &expired {
+        println!("Removendo o preview expirado {} (namespace {})...", name, preview.namespace);
+        if delete(preview) {
+            removed.push((*name).clone());
+        } else {
+            eprintln!("  ✗ falha ao remover; o preview continua registrado para uma próxima tentativa.");
+        }
+    }
+    forget(&removed);
+    removed.len() == expired.len()
+}
+
+fn remaining(expires: u64) -> String {
+    match expires.saturating_sub(now()) {
+        0 => "expirado".to_string(),
+        secs if secs < HOUR => format!("em {} min", secs.div_ceil(60)),
+        secs => format!("em {} h", secs.div_ceil(HOUR)),
+    }
+}
+
+#[derive(Serialize)]
+struct PreviewJson<'a> {
+    name: &'a str,
+    #[serde(flatten)]
+    preview: &'a Preview,
+    expired: bool,
+}
+
+fn row<'a>(name: &'a str, preview: &'a Preview) -> PreviewJson<'a> {
+    PreviewJson { name, preview, expired: preview.expires <= now() }
+}
+
+/// `dx preview create`: build the image of the application, deploy it with the infrastructure
+/// services it uses to a namespace of the kind cluster or of a remote cluster and print the URL.
+/// Creating it again (same project and branch) redeploys it and renews the expiry. With `dry_run`
+/// only the manifests are printed.
+pub fn cmd_create(dir: Option<PathBuf>, context: Option<String>, namespace: Option<String>, image: Option<String>, dry_run: bool) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(workload) = Workload::detect(&project_dir) else {
+        eprintln!("Nenhuma stack detectada em {}; nada a implantar.", project_dir.display());
+        return 1;
+    };
+    let name = preview_name(&project_dir, &workload.name);
+    let namespace = namespace.unwrap_or_else(|| format!("{}{}", NAMESPACE_PREFIX, name));
+    let target = configured_target();
+    let ttl = crate::settings::get_u64("preview_ttl").unwrap_or(24 * HOUR);
+    let previews = load();
+    if !dry_run && !prune_expired(&previews) {
+        eprintln!("Aviso: há previews expirados que não puderam ser removidos; tente de novo com: dx preview prune");
+    }
+
+    let registry = crate::settings::get("preview_registry");
+    let prebuilt = image.is_some();
+    let image = match image {
+        Some(image) => image,
+        None if target == Target::Cluster && registry.is_empty() => {
+            eprintln!("Erro: o cluster remoto precisa baixar a imagem de um registry. Configure-o com: dx config set preview_registry <registry> (ou use --image).");
+            return 1;
+        }
+        None => {
+            let repository = if registry.is_empty() { workload.name.clone() } else { format!("{}/{}", registry, workload.name) };
+            format!("{}:preview-{}", repository, now())
+        }
+    };
+    let node_port = match target {
+        Target::Cluster => None,
+        Target::Kind => {
+            let taken: Vec<u16> = previews.iter().filter(|(n, _)| **n != name).filter_map(|(_, p)| p.node_port).collect();
+            let port = previews.get(&name).and_then(|p| p.node_port).or_else(|| NODE_PORTS.clone().find(|p| !taken.contains(p)));
+            if port.is_none() {
+                eprintln!("Erro: as {} portas de preview do cluster kind estão em uso. Remova um preview com: dx preview delete <nome>", NODE_PORTS.len());
+                return 1;
+            }
+            port
+        }
+    };
+    let domain = crate::settings::get("preview_domain");
+    let host = (target == Target::Cluster && !domain.is_empty()).then(|| format!("{}.{}", name, domain));
+    let mut preview = Preview {
+        dir: canonical(&project_dir),
+        target,
+        context: String::new(),
+        namespace,
+        image,
+        node_port,
+        url: node_port.map(|p| format!("http://localhost:{}", p)).or(host.as_ref().map(|h| format!("http://{}", h))),
+        created: now(),
+        expires: now() + ttl,
+    };
+    let (namespace_manifest, manifests) = render(&project_dir, &workload, &preview, &name, host.as_deref());
+    if dry_run {
+        print!("{}---\n{}---\n{}", namespace_manifest, render_janitor_access(&preview.namespace, None), manifests);
+        return 0;
+    }
+
+    preview.context = match (target, context) {
+        (Target::Kind, _) => {
+            let cluster = crate::settings::get("preview_kind_cluster");
+            if !kind_clusters().contains(&cluster) {
+                println!("Criando o cluster kind {}...", cluster);
+                if !run("kind", &["create", "cluster", "--name", &cluster, "--config", "-"], Some(&render_kind_config())) {
+                    return 1;
+                }
+            }
+            format!("kind-{}", cluster)
+        }
+        (Target::Cluster, Some(context)) => context,
+        (Target::Cluster, None) => match output("kubectl", &["config", "current-context"]).filter(|c| !c.is_empty()) {
+            Some(context) => context,
+            None => {
+                eprintln!("Erro: nenhum contexto atual no kubectl. Escolha um com --context <contexto>.");
+                return 1;
+            }
+        },
+    };
+    let context = preview.context.clone();
+    let kubectl = |args: &[&str], input: Option<&str>| {
+        let args: Vec<&str> = ["--context", context.as_str()].iter().chain(args).copied().collect();
+        run("kubectl", &args, input)
+    };
+    // A namespace that exists and was not created by dx preview belongs to someone else
+    let managed_by = format!("jsonpath={{.metadata.labels.{}}}", MANAGED_BY.replace('.', "\\."));
+    if let Some(owner) = output("kubectl", &["--context", &context, "get", "namespace", &preview.namespace, "-o", &managed_by]) {
+        if owner != "dx" {
+            eprintln!("Erro: o namespace {} já existe em {} e não é um preview do dx. Escolha outro com --namespace.", preview.namespace, context);
+            return 1;
+        }
+    }
+
+    if !prebuilt {
+        println!("Construindo a imagem {}...", preview.image);
+        if !build(&project_dir, &preview.image) {
+            return 1;
+        }
+        let published = match target {
+            Target::Kind => run("kind", &["load", "docker-image", &preview.image, "--name", context.trim_start_matches("kind-")], None),
+            Target::Cluster => run("docker", &["push", &preview.image], None),
+        };
+        if !published {
+            return 1;
+        }
+    }
+
+    println!("Implantando {} no namespace {} ({})...", name, preview.namespace, context);
+    if !kubectl(&["apply", "-f", "-"], Some(&namespace_manifest)) {
+        return 1;
+    }
+    let Some(uid) = output("kubectl", &["--context", &context, "get", "namespace", &preview.namespace, "-o", "jsonpath={.metadata.uid}"]).filter(|u| !u.is_empty()) else {
+        eprintln!("Erro: não foi possível ler o namespace {} em {}.", preview.namespace, context);
+        return 1;
+    };
+    if !kubectl(&["apply", "-f", "-"], Some(&render_janitor_access(&preview.namespace, Some(&uid)))) || !kubectl(&["apply", "-n", &preview.namespace, "-f", "-"], Some(&manifests)) {
+        return 1;
+    }
+    if let Err(e) = update(|previews| {
+        previews.insert(name.clone(), preview.clone());
+    }) {
+        eprintln!("Aviso: falha ao registrar o preview em {}: {}", previews_path().display(), e);
+    }
+    let mut ready = true;
+    for deployment in workload.services.iter().chain([&workload.name]) {
+        let deployment = format!("deployment/{}", deployment);
+        ready &= kubectl(&["rollout", "status", "-n", &preview.namespace, &deployment, "--timeout", ROLLOUT_TIMEOUT], None);
+    }
+
+    if crate::output::json() {
+        crate::output::print(&row(&name, &preview));
+    }
+    if !ready {
+        eprintln!("O preview {} foi implantado mas não ficou pronto; veja com: kubectl --context {} -n {} get pods", name, context, preview.namespace);
+        return 1;
+    }
+    match &preview.url {
+        Some(url) => println!("✓ Preview {} no ar: {} (expira {}).", name, url, remaining(preview.expires)),
+        None => {
+            println!("✓ Preview {} no ar (expira {}). Defina preview_domain para uma URL pública; por enquanto:", name, remaining(preview.expires));
+            println!("  kubectl --context {} -n {} port-forward svc/{} 8080:80", context, preview.namespace, workload.name);
+        }
+    }
+    if let Some(first) = workload.secrets.first() {
+        println!(
+            "Crie o Secret {name}-secrets com: {} (ex.: kubectl --context {} -n {} create secret generic {name}-secrets --from-literal={}=...).",
+            workload.secrets.join(", "),
+            context,
+            preview.namespace,
+            first,
+            name = workload.name
+        );
+    }
+    0
+}
+
+/// `dx preview list`: the tracked previews with their URL and expiry.
+pub fn cmd_list() -> i32 {
+    let previews = load();
+    if crate::output::json() {
+        let rows: Vec<PreviewJson> = previews.iter().map(|(name, p)| row(name, p)).collect();
+        crate::output::print(&rows);
+        return 0;
+    }
+    if previews.is_empty() {
+        println!("Nenhum preview registrado. Crie um com: dx preview create");
+        return 0;
+    }
+    println!("{:<40} {:<8} {:<32} {:<12} DIRETÓRIO", "PREVIEW", "ALVO", "URL", "EXPIRA");
+    for (name, preview) in &previews {
+        let url = preview.url.as_deref().unwrap_or("-");
+        println!("{:<40} {:<8} {:<32} {:<12} {}", name, preview.target.key(), url, remaining(preview.expires), preview.dir.display());
+    }
+    0
+}
+
+/// `dx preview delete`: delete a preview (by name or namespace) and stop tracking it.
+pub fn cmd_delete(name: String) -> i32 {
+    let previews = load();
+    let Some((name, preview)) = previews.iter().find(|(n, p)| **n == name || p.namespace == name) else {
+        eprintln!("Erro: nenhum preview '{}'. Veja os registrados com: dx preview list", name);
+        return 1;
+    };
+    println!("Removendo o preview {} (namespace {} em {})...", name, preview.namespace, preview.context);
+    if !delete(preview) {
+        eprintln!("Erro: não foi possível remover o preview; ele continua registrado.");
+        return 1;
+    }
+    forget(&[name.clone()]);
+    println!("✓ Preview {} removido.", name);
+    if preview.target == Target::Kind && !previews.values().any(|p| p.target == Target::Kind && p.namespace != preview.namespace) {
+        println!("Nenhum preview restante no kind; remova o cluster com: kind delete cluster --name {}", preview.context.trim_start_matches("kind-"));
+    }
+    0
+}
+
+/// Delete the expired previews found in the cluster of `context` by their label and expiry
+/// annotation, whichever machine created them, and stop tracking them here. None when the
+/// namespaces cannot be listed; else how many were expired and whether all of them were deleted.
+fn prune_cluster(context: &str) -> Option<(usize, bool)> {
+    let selector = format!("{}=dx,{}", MANAGED_BY, PREVIEW_LABEL);
+    let Some(list) = output("kubectl", &["--context", context, "get", "namespaces", "-l", &selector, "-o", "json"]) else {
+        eprintln!("Erro: não foi possível listar os namespaces de preview em {}.", context);
+        return None;
+    };
+    let list: serde_json::Value = serde_json::from_str(&list).unwrap_or_default();
+    let expired: Vec<&str> = list["items"]
+        .as_array()
+        .into_iter()
+        .flatten()
+        .map(|item| &item["metadata"])
+        .filter(|meta| meta["deletionTimestamp"].is_null())
+        .filter(|meta| meta["annotations"][EXPIRES_ANNOTATION].as_str().and_then(parse_expiry).is_some_and(|expires| expires <= now()))
+        .filter_map(|meta| meta["name"].as_str())
+        .collect();
+    let mut removed = Vec::new();
+    for namespace in &expired {
+        println!("Removendo o namespace expirado {} ({})...", namespace, context);
+        if run("kubectl", &["--context", context, "delete", "namespace", namespace, "--ignore-not-found", "--wait=false"], None) {
+            removed.push(*namespace);
+        } else {
+            eprintln!("  ✗ falha ao remover.");
+        }
+    }
+    let tracked: Vec<String> = load().into_iter().filter(|(_, p)| p.context == context && removed.contains(&p.namespace.as_str())).map(|(name, _)| name).collect();
+    forget(&tracked);
+    Some((expired.len(), removed.len() == expired.len()))
+}
+
+/// `dx preview prune`: delete the expired previews tracked here and those found in the cluster of
+/// `context` (default: the current kubectl context).
+pub fn cmd_prune(context: Option<String>) -> i32 {
+    let previews = load();
+    let local = previews.values().filter(|p| p.expires <= now()).count();
+    let mut ok = local == 0 || prune_expired(&previews);
+    let mut found = local;
+    match context.or_else(|| output("kubectl", &["config", "current-context"]).filter(|c| !c.is_empty())) {
+        Some(context) => match prune_cluster(&context) {
+            Some((expired, all_removed)) => {
+                found += expired;
+                ok &= all_removed;
+            }
+            None => ok = false,
+        },
+        None => println!("Nenhum contexto atual no kubectl; só os previews registrados nesta máquina foram verificados (use --context)."),
+    }
+    if !ok {
+        return 1;
+    }
+    if found == 0 {
+        println!("Nenhum preview expirado ({} registrado(s)).", previews.len());
+    } else {
+        println!("✓ Previews expirados removidos.");
+    }
+    0
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde_json::{Value, json};
+use std::cell::Cell;
+use std::io::Write;
+use std::sync::{Mutex, OnceLock};
+use std::time::{Instant, SystemTime, UNIX_EPOCH};
+
+/// File descriptor (unix) that receives the NDJSON events, keeping stdout/stderr untouched.
+pub const PROGRESS_FD_ENV: &str = "DX_PROGRESS_FD";
+
+/// How progress is reported (`--progress`).
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, clap::ValueEnum)]
+pub enum ProgressMode {
+    /// Only the usual human-readable messages
+    #[default]
+    Text,
+    /// NDJSON events on stderr (or on the fd from DX_PROGRESS_FD)
+    Json,
+}
+
+static SINK: OnceLock<Mutex<Box<dyn Write + Send>>> = OnceLock::new();
+
+/// Enable events for this process. `DX_PROGRESS_FD` enables them even in text mode,
+/// so wrappers can read events without mixing them with the terminal output.
+pub fn init(mode: ProgressMode) {
+    let sink: Option<Box<dyn Write + Send>> = match std::env::var(PROGRESS_FD_ENV).ok().and_then(|v| v.trim().parse::<u32>().ok()) {
+        Some(fd) => match std::fs::OpenOptions::new().write(true).open(format!("/dev/fd/{}", fd)) {
+            Ok(f) => Some(Box::new(f)),
+            Err(e) => {
+                eprintln!("Aviso: não foi possível abrir o descritor {} ({}): {}", fd, PROGRESS_FD_ENV, e);
+                None
+            }
+        },
+        None => None,
+    };
+    let sink = sink.or_else(|| (mode == ProgressMode::Json).then(|| Box::new(std::io::stderr()) as Box<dyn Write + Send>));
+    if let Some(s) = sink {
+        let _ = SINK.set(Mutex::new(s));
+    }
+}
+
+pub fn enabled() -> bool {
+    SINK.get().is_some()
+}
+
+fn emit(mut event: Value) {
+    let Some(sink) = SINK.get() else { return };
+    let ts = SystemTime::now().duration_since(UNIX_EPOCH).map(|d| d.as_millis() as u64).unwrap_or(0);
+    event["ts"] = json!(ts);
+    if let Ok(mut w) = sink.lock() {
+        let _ = writeln!(w, "{}", event);
+        let _ = w.flush();
+    }
+}
+
+/// A named phase of a long operation (scan, provisioning, ...). Emits `phase_started` on creation,
+/// `progress` on each step and `phase_finished` on `finish`. All calls are no-ops when events are disabled.
+pub struct Phase {
+    name: String,
+    started: Instant,
+    last_percent: Cell<Option<u32>>,
+}
+
+impl Phase {
+    pub fn start(name: &str, message: &str) -> Phase {
+        emit(json!({ "event": "phase_started", "phase": name, "message": message }));
+        Phase { name: name.to_string(), started: Instant::now(), last_percent: Cell::new(None) }
+    }
+
+    /// Report `current` of `total` items done. Events are only emitted when the integer
+    /// percentage changes, so scanning thousands of files does not flood the stream.
+    pub fn step(&self, current: usize, total: usize, item: &str) {
+        if !enabled() {
+            return;
+        }
+        let percent = if total == 0 { 100 } else { (current.min(total) * 100 / total) as u32 };
+        if self.last_percent.get() == Some(percent) && current < total {
+            return;
+        }
+        self.last_percent.set(Some(percent));
+        emit(json!({
+            "event": "progress",
+            "phase": self.name,
+            "current": current,
+            "total": total,
+            "percent": percent,
+            "item": item,
+        }));
+    }
+
+    /// Free-form status for phases without a known total (e.g. waiting for a service).
+    pub fn message(&self, message: &str) {
+        emit(json!({ "event": "message", "phase": self.name, "message": message }));
+    }
+
+    pub fn finish(self, success: bool) {
+        emit(json!({
+            "event": "phase_finished",
+            "phase": self.name,
+            "success": success,
+            "duration_ms": self.started.elapsed().as_millis() as u64,
+        }));
+    }
+}
@@ -0,0 +1,144 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::time::{SystemTime, UNIX_EPOCH};
+
+/// Docker is slow for a prompt; the service count is reused for this long.
+pub const DEFAULT_MAX_AGE_SECS: u64 = 10;
+const CACHE_FILE: &str = ".dx/prompt-cache.json";
+/// Files that mark a project root when walking up from the current directory.
+const ROOT_MARKERS: &[&str] = &["dx.yaml", ".dx", "go.mod", "Cargo.toml", "package.json", "pom.xml", ".git"];
+
+/// Output of `dx prompt`.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum PromptFormat {
+    /// Short segment, e.g. `myapp ⬢ 3/4 (debug)`
+    Text,
+    /// JSON object with every field
+    Json,
+}
+
+#[derive(Debug, Default, Serialize)]
+pub struct PromptState {
+    pub project: String,
+    pub root: PathBuf,
+    /// Running services / services expected with the active profiles (None without compose file or Docker)
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub services_up: Option<usize>,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub services_total: Option<usize>,
+    pub profiles: Vec<String>,
+}
+
+#[derive(Debug, Serialize, Deserialize)]
+struct Cache {
+    checked_at: u64,
+    up: usize,
+    total: usize,
+    profiles: Vec<String>,
+}
+
+#[derive(Deserialize)]
+struct ComposeFile {
+    #[serde(default)]
+    services: BTreeMap<String, ComposeService>,
+}
+
+#[derive(Deserialize)]
+struct ComposeService {
+    #[serde(default)]
+    profiles: Vec<String>,
+}
+
+/// Nearest ancestor of `start` (inclusive) that looks like a project root.
+pub fn find_root(start: &Path) -> Option<PathBuf> {
+    start
+        .ancestors()
+        .find(|d| ROOT_MARKERS.iter().any(|m| d.join(m).exists()))
+        .map(Path::to_path_buf)
+}
+
+fn now_secs() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).map(|d| d.as_secs()).unwrap_or(0)
+}
+
+/// Services of the compose file that start with the given profiles.
+fn expected_services(compose_path: &Path, profiles: &[String]) -> Option<Vec<String>> {
+    let content = fs::read_to_string(compose_path).ok()?;
+    let compose: ComposeFile = serde_yaml::from_str(&content).ok()?;
+    Some(
+        compose
+            .services
+            .into_iter()
+            .filter(|(_, s)| s.profiles.is_empty() || s.profiles.iter().any(|p| profiles.contains(p)))
+            .map(|(name, _)| name)
+            .collect(),
+    )
+}
+
+/// Count running services with `docker compose ps` (cached for `max_age` seconds).
+fn service_counts(root: &Path, profiles: &[String], max_age: u64) -> Option<(usize, usize)> {
+    let cache_path = root.join(CACHE_FILE);
+    if let Some(cache) = fs::read_to_string(&cache_path)
+        .ok()
+        .and_then(|c| serde_json::from_str::<Cache>(&c).ok())
+        .filter(|c| now_secs().saturating_sub(c.checked_at) < max_age && c.profiles == profiles)
+    {
+        return Some((cache.up, cache.total));
+    }
+
+    let compose_path = crate::dev_services_compose_path(root);
+    let expected = expected_services(&compose_path, profiles)?;
+    let states = crate::startup_profile::query_states(&["docker", "compose"], &compose_path)?;
+    let up = expected
+        .iter()
+        .filter(|name| states.iter().any(|c| &c.service == *name && c.state == "running"))
+        .count();
+    let cache = Cache { checked_at: now_secs(), up, total: expected.len(), profiles: profiles.to_vec() };
+    if let Ok(json) = serde_json::to_string(&cache) {
+        let _ = crate::lock::write_atomic(&cache_path, json);
+    }
+    Some((up, expected.len()))
+}
+
+pub fn state(start: &Path, max_age: u64) -> Option<PromptState> {
+    let root = find_root(start)?;
+    let project = root.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_default();
+    let profiles = crate::dev_services::active_profiles(&root);
+    let counts = service_counts(&root, &profiles, max_age);
+    Some(PromptState {
+        project,
+        services_up: counts.map(|c| c.0),
+        services_total: counts.map(|c| c.1),
+        profiles,
+        root,
+    })
+}
+
+pub fn render_text(state: &PromptState) -> String {
+    let mut out = state.project.clone();
+    if let (Some(up), Some(total)) = (state.services_up, state.services_total) {
+        if total > 0 {
+            out.push_str(&format!(" ⬢ {}/{}", up, total));
+        }
+    }
+    if !state.profiles.is_empty() {
+        out.push_str(&format!(" ({})", state.profiles.join(",")));
+    }
+    out
+}
+
+/// `dx prompt`: one line for shell prompt segments (starship, powerlevel10k).
+/// Prints nothing outside a project so the segment disappears.
+pub fn cmd_prompt(format: PromptFormat, max_age: u64, dir: Option<PathBuf>) {
+    let start = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let Some(state) = state(&start, max_age) else { return };
+    match format {
+        PromptFormat::Text => println!("{}", render_text(&state)),
+        PromptFormat::Json => println!("{}", serde_json::to_string(&state).unwrap_or_default()),
+    }
+}
@@ -0,0 +1,364 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::HashMap;
+use std::fs;
+use std::hash::{BuildHasher, RandomState};
+use std::io::{self, Write};
+use std::path::PathBuf;
+use std::sync::atomic::{AtomicBool, AtomicUsize, Ordering};
+use std::sync::{mpsc, Mutex, OnceLock};
+use std::thread;
+use std::time::{Duration, Instant, SystemTime, UNIX_EPOCH};
+
+/// Registry responses kept in the state directory, so an interrupted scan resumes where it stopped
+/// and an expired answer still serves when the registry cannot be reached (or with `offline`).
+const CACHE_FILE: &str = "registry-cache.jsonl";
+/// Past this size the cache is compacted: superseded entries go, then the oldest until it halves.
+const MAX_CACHE_BYTES: u64 = 16 * 1024 * 1024;
+/// Requests in flight at once, across all registries.
+const WORKERS: usize = 8;
+/// Requests per second to a registry without a published limit (and no `registry_rate`).
+const DEFAULT_RATE: f64 = 10.0;
+/// Published limits: crates.io asks crawlers for at most one request per second.
+const REGISTRY_RATES: &[(&str, f64)] = &[("crates.io", 1.0)];
+/// Slowest pace a registry is brought down to after answering 429.
+const MAX_INTERVAL: Duration = Duration::from_secs(5);
+const MAX_ATTEMPTS: u32 = 5;
+const BASE_DELAY: Duration = Duration::from_millis(500);
+const MAX_DELAY: Duration = Duration::from_secs(30);
+const TIMEOUT: Duration = Duration::from_secs(30);
+
+#[derive(Serialize, Deserialize)]
+struct CacheEntry {
+    key: String,
+    /// Unix seconds of the response
+    at: u64,
+    body: String,
+}
+
+/// Pace of one registry host: when it may get the next request and the gap between requests.
+struct Pace {
+    next: Instant,
+    interval: Duration,
+    throttled: bool,
+}
+
+struct Registry {
+    http: Result<reqwest::blocking::Client, String>,
+    paces: Mutex<HashMap<String, Pace>>,
+    /// Responses from the cache file or this run, with their Unix seconds, by request key
+    cache: Mutex<HashMap<String, (u64, String)>>,
+    /// Requests that already failed in this run, not retried again
+    failures: Mutex<HashMap<String, String>>,
+    /// Lookups answered with an error in this run, remembered failures included
+    failed: AtomicUsize,
+    /// Whether an expired response was already used in this run (warned once)
+    stale_used: AtomicBool,
+    /// Unix seconds when the run started
+    started: u64,
+    ttl: u64,
+    /// `offline`: answer only from the cache file, never from the network
+    offline: bool,
+}
+
+fn now() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).unwrap_or_default().as_secs()
+}
+
+pub fn cache_path() -> PathBuf {
+    crate::paths::state_dir().join(CACHE_FILE)
+}
+
+/// Lookups that failed so far in this run, or were answered with an expired response, so a caller
+/// can tell whether its own all got current answers.
+pub fn failures() -> usize {
+    registry().failed.load(Ordering::Relaxed)
+}
+
+/// Remove the cache file (`dx cache clear`); returns whether there was one.
+pub fn clear_cache() -> io::Result<bool> {
+    let path = cache_path();
+    let _lock = crate::lock::for_file(&path)?;
+    match fs::remove_file(&path) {
+        Ok(()) => Ok(true),
+        Err(e) if e.kind() == io::ErrorKind::NotFound => Ok(false),
+        Err(e) => Err(e),
+    }
+}
+
+/// How long responses are reused (`registry_cache_ttl`), as text for messages; None when the cache is off.
+pub fn cache_ttl_label() -> Option<String> {
+    let ttl = registry().ttl;
+    Some(match ttl {
+        0 => return None,
+        _ if ttl % 3600 == 0 => format!("{}h", ttl / 3600),
+        _ if ttl % 60 == 0 => format!("{}min", ttl / 60),
+        _ => format!("{}s", ttl),
+    })
+}
+
+/// Whether offline mode is on (`offline`), for commands that mention it.
+pub fn offline() -> bool {
+    registry().offline
+}
+
+/// Entries of the cache file, the latest per key, compacting it when it grew past MAX_CACHE_BYTES.
+fn load_cache() -> HashMap<String, (u64, String)> {
+    let path = cache_path();
+    let Ok(content) = fs::read_to_string(&path) else { return HashMap::new() };
+    // Appended in order, so a later line supersedes an earlier one for the same key
+    let mut latest: HashMap<String, CacheEntry> = HashMap::new();
+    for entry in content.lines().filter_map(|l| serde_json::from_str::<CacheEntry>(l).ok()) {
+        latest.insert(entry.key.clone(), entry);
+    }
+    if content.len() as u64 > MAX_CACHE_BYTES {
+        let _lock = crate::lock::for_file(&path);
+        let mut entries: Vec<CacheEntry> = latest.into_values().collect();
+        entries.sort_by_key(|e| e.at);
+        let mut size: u64 = entries.iter().map(|e| e.body.len() as u64).sum();
+        while size > MAX_CACHE_BYTES / 2 && !entries.is_empty() {
+            size -= entries.remove(0).body.len() as u64;
+        }
+        let lines: String = entries.iter().filter_map(|e| serde_json::to_string(e).ok()).map(|l| l + "\n").collect();
+        if let Err(e) = crate::lock::write_atomic(&path, lines) {
+            eprintln!("Aviso: não foi possível compactar {}: {}", path.display(), e);
+        }
+        latest = entries.into_iter().map(|e| (e.key.clone(), e)).collect();
+    }
+    latest.into_iter().map(|(key, e)| (key, (e.at, e.body))).collect()
+}
+
+fn registry() -> &'static Registry {
+    static REGISTRY: OnceLock<Registry> = OnceLock::new();
+    REGISTRY.get_or_init(|| {
+        let ttl = crate::settings::get_u64("registry_cache_ttl").unwrap_or(0);
+        let offline = crate::settings::get_bool("offline");
+        Registry {
+            http: reqwest::blocking::Client::builder()
+                .timeout(TIMEOUT)
+                .user_agent(concat!("dx-cli/", env!("CARGO_PKG_VERSION")))
+                .build()
+                .map_err(|e| e.to_string()),
+            paces: Mutex::new(HashMap::new()),
+            cache: Mutex::new(if ttl > 0 || offline { load_cache() } else { HashMap::new() }),
+            failures: Mutex::new(HashMap::new()),
+            failed: AtomicUsize::new(0),
+            stale_used: AtomicBool::new(false),
+            started: now(),
+            ttl,
+            offline,
+        }
+    })
+}
+
+fn host_of(url: &str) -> String {
+    let rest = url.split_once("://").map_or(url, |(_, r)| r);
+    rest.split(['/', '?']).next().unwrap_or(rest).to_string()
+}
+
+/// Requests per second allowed for `host`: the `registry_rate` setting, else the registry's published limit.
+fn rate(host: &str) -> f64 {
+    match crate::settings::get_u64("registry_rate") {
+        Some(rate) if rate > 0 => rate as f64,
+        _ => REGISTRY_RATES
+            .iter()
+            .find(|(h, _)| host == *h || host.ends_with(&format!(".{}", h)))
+            .map_or(DEFAULT_RATE, |(_, r)| *r),
+    }
+}
+
+/// Exponential backoff with jitter (up to half the delay on top), so parallel workers spread out.
+fn backoff(attempt: u32) -> Duration {
+    let delay = BASE_DELAY.saturating_mul(1 << attempt.min(16)).min(MAX_DELAY);
+    let jitter = RandomState::new().hash_one(Instant::now()) % (delay.as_millis() as u64 / 2 + 1);
+    delay + Duration::from_millis(jitter)
+}
+
+/// `Retry-After` in seconds (the HTTP-date form is rare on registries and falls back to backoff).
+fn retry_after(response: &reqwest::blocking::Response) -> Option<Duration> {
+    let seconds: u64 = response.headers().get("retry-after")?.to_str().ok()?.trim().parse().ok()?;
+    Some(Duration::from_secs(seconds).min(MAX_DELAY))
+}
+
+impl Registry {
+    /// Wait for this host's next free slot.
+    fn wait_turn(&self, host: &str) {
+        let wait = {
+            let mut paces = self.paces.lock().unwrap_or_else(|e| e.into_inner());
+            let now = Instant::now();
+            let pace = paces.entry(host.to_string()).or_insert_with(|| Pace {
+                next: now,
+                interval: Duration::from_secs_f64(1.0 / rate(host)),
+                throttled: false,
+            });
+            let at = pace.next.max(now);
+            pace.next = at + pace.interval;
+            at - now
+        };
+        thread::sleep(wait);
+    }
+
+    /// The registry answered 429: halve its pace for the rest of the run.
+    fn slow_down(&self, host: &str) {
+        let mut paces = self.paces.lock().unwrap_or_else(|e| e.into_inner());
+        if let Some(pace) = paces.get_mut(host) {
+            pace.interval = (pace.interval * 2).min(MAX_INTERVAL);
+            if !pace.throttled {
+                pace.throttled = true;
+                eprintln!("Aviso: {} pediu para reduzir o ritmo (HTTP 429); as consultas seguem mais espaçadas.", host);
+            }
+        }
+    }
+
+    fn store(&self, key: &str, body: &str) {
+        let at = now();
+        self.cache.lock().unwrap_or_else(|e| e.into_inner()).insert(key.to_string(), (at, body.to_string()));
+        if self.ttl == 0 {
+            return;
+        }
+        let path = cache_path();
+        let result = (|| -> io::Result<()> {
+            if let Some(parent) = path.parent() {
+                fs::create_dir_all(parent)?;
+            }
+            let _lock = crate::lock::for_file(&path)?;
+            let entry = CacheEntry { key: key.to_string(), at, body: body.to_string() };
+            let mut file = fs::OpenOptions::new().create(true).append(true).open(&path)?;
+            writeln!(file, "{}", serde_json::to_string(&entry).map_err(io::Error::other)?)
+        })();
+        if let Err(e) = result {
+            eprintln!("Aviso: não foi possível guardar a resposta em {}: {}", path.display(), e);
+        }
+    }
+
+    /// Send a request (JSON `body` means POST) at the registry's pace, retrying 429, 5xx and
+    /// network errors with backoff. Successful responses are cached; failures are remembered for the
+    /// run. A response past `registry_cache_ttl` answers only offline or when the registry fails.
+    fn request(&self, url: &str, body: Option<&serde_json::Value>) -> Result<String, String> {
+        self.send(url, body).inspect_err(|_| {
+            self.failed.fetch_add(1, Ordering::Relaxed);
+        })
+    }
+
+    fn send(&self, url: &str, body: Option<&serde_json::Value>) -> Result<String, String> {
+        let key = match body {
+            Some(body) => {
+                let hash: String = Sha256::digest(body.to_string().as_bytes()).iter().map(|b| format!("{:02x}", b)).collect();
+                format!("POST {} {}", url, hash)
+            }
+            None => format!("GET {}", url),
+        };
+        let cached = self.cache.lock().unwrap_or_else(|e| e.into_inner()).get(&key).cloned();
+        if let Some((at, body)) = &cached {
+            // Answers from this run are reused even with the cache off
+            if *at >= self.started || *at >= now().saturating_sub(self.ttl) {
+                return Ok(body.clone());
+            }
+            if self.offline {
+                self.failed.fetch_add(1, Ordering::Relaxed);
+                return Ok(body.clone());
+            }
+        }
+        if self.offline {
+            return Err("sem resposta guardada (modo offline)".to_string());
+        }
+        let failed = self.failures.lock().unwrap_or_else(|e| e.into_inner()).get(&key).cloned();
+        let result = match failed {
+            Some(error) => Err(error),
+            None => self.fetch(&key, url, body),
+        };
+        match (result, cached) {
+            (Err(error), Some((_, body))) => {
+                self.failed.fetch_add(1, Ordering::Relaxed);
+                if !self.stale_used.swap(true, Ordering::Relaxed) {
+                    eprintln!("Aviso: {} não respondeu ({}); usando as respostas guardadas, mesmo expiradas.", host_of(url), error);
+                }
+                Ok(body)
+            }
+            (result, _) => result,
+        }
+    }
+
+    fn fetch(&self, key: &str, url: &str, body: Option<&serde_json::Value>) -> Result<String, String> {
+        let http = self.http.as_ref().map_err(|e| e.clone())?;
+        let host = host_of(url);
+        let mut error = String::new();
+        for attempt in 0..MAX_ATTEMPTS {
+            self.wait_turn(&host);
+            let request = match body {
+                Some(body) => http.post(url).json(body),
+                None => http.get(url),
+            };
+            let delay = match request.send() {
+                Ok(response) if response.status().is_success() => {
+                    let text = response.text().map_err(|e| e.to_string())?;
+                    self.store(key, &text);
+                    return Ok(text);
+                }
+                Ok(response) if response.status().as_u16() == 429 || response.status().as_u16() >= 500 => {
+                    error = format!("HTTP {}", response.status());
+                    if response.status().as_u16() == 429 {
+                        self.slow_down(&host);
+                    }
+                    retry_after(&response).unwrap_or_else(|| backoff(attempt))
+                }
+                Ok(response) => {
+                    error = format!("HTTP {}", response.status());
+                    break;
+                }
+                Err(e) => {
+                    error = e.to_string();
+                    backoff(attempt)
+                }
+            };
+            if attempt + 1 < MAX_ATTEMPTS {
+                thread::sleep(delay);
+            } else {
+                error = format!("{} (após {} tentativas)", error, MAX_ATTEMPTS);
+            }
+        }
+        self.failures.lock().unwrap_or_else(|e| e.into_inner()).insert(key.to_string(), error.clone());
+        Err(error)
+    }
+}
+
+/// GET `url` from a package registry: paced per registry, retried with backoff and cached.
+pub fn get(url: &str) -> Result<String, String> {
+    registry().request(url, None)
+}
+
+/// POST a JSON query (e.g. an OSV batch), with the same pacing, retries and cache as `get`.
+pub fn post_json(url: &str, body: &serde_json::Value) -> Result<String, String> {
+    registry().request(url, Some(body))
+}
+
+/// GET many URLs with a pool of workers, each registry at its own pace. Results keep the order
+/// of `urls`; `phase` receives a step per finished request.
+pub fn get_all(urls: &[String], phase: Option<&crate::progress::Phase>) -> Vec<Result<String, String>> {
+    let registry = registry();
+    let next = AtomicUsize::new(0);
+    let mut results: Vec<Option<Result<String, String>>> = vec![None; urls.len()];
+    thread::scope(|s| {
+        let (tx, rx) = mpsc::channel();
+        for _ in 0..WORKERS.min(urls.len()) {
+            let (tx, next) = (tx.clone(), &next);
+            s.spawn(move || loop {
+                let i = next.fetch_add(1, Ordering::Relaxed);
+                if i >= urls.len() || tx.send((i, registry.request(&urls[i], None))).is_err() {
+                    break;
+                }
+            });
+        }
+        drop(tx);
+        for (done, (i, result)) in rx.iter().enumerate() {
+            if let Some(phase) = phase {
+                phase.step(done + 1, urls.len(), &urls[i]);
+            }
+            results[i] = Some(result);
+        }
+    });
+    results.into_iter().map(|r| r.unwrap_or_else(|| Err("consulta não executada".to_string()))).collect()
+}
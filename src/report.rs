@@ -173,6 +173,9 @@ pub fn build_analyzer_report(project_dir: &Path, ds_config: &DockerComposeConfig
             "kafka" => {
                 badges.insert("[![Kafka](https://img.shields.io/badge/Kafka-Dev_Service-black?logo=apachekafka)](#)");
             }
+            "rabbitmq" => {
+                badges.insert("[![RabbitMQ](https://img.shields.io/badge/RabbitMQ-Dev_Service-FF6600?logo=rabbitmq)](#)");
+            }
             "kafka-ui" => { /* skip explicit UI badge */ }
             "jobmanager" | "taskmanager" => {
                 badges.insert("[![Apache Flink](https://img.shields.io/badge/Flink-Dev_Service-orange?logo=apacheflink)](#)");
@@ -277,6 +280,11 @@ fn service_info(name: &str, svc: &DockerService) -> String {
             "Bootstrap: localhost:29092".to_string()
         }
         "kafka-ui" => "UI: http://localhost:9093".to_string(),
+        "rabbitmq" => {
+            let user = env("RABBITMQ_DEFAULT_USER").unwrap_or_else(|| "guest".to_string());
+            let pass = env("RABBITMQ_DEFAULT_PASS").unwrap_or_else(|| "guest".to_string());
+            format!("url: amqp://{}:{}@localhost:5672, console: http://localhost:15672", user, pass)
+        }
         // Flink
         "jobmanager" => "Flink UI: http://localhost:8081".to_string(),
         "taskmanager" => "Seguido pelo JobManager (sem UI)".to_string(),
@@ -0,0 +1,469 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Detection rules (service fingerprints, Go client modules, Hex packages, framework signatures) as a versioned
+//! pack. The binary embeds one; `dx rules update` installs newer ones in the state directory, and
+//! `dx rules pin` records the pack a project is analyzed with in its dx.lock.
+
+use crate::dev_config::Stack;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::HashMap;
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+use std::sync::{Arc, Mutex, OnceLock};
+use std::time::Duration;
+
+/// Pack compiled into the binary.
+const BUILTIN: &str = include_str!("../rules/detection.json");
+/// Lock file at the project root.
+pub const LOCK_FILE: &str = "dx.lock";
+/// First line of the dx.lock written by dx.
+const LOCK_HEADER: &str = "# Gerado por: dx rules pin. Fixa as regras de detecção para análises reproduzíveis.";
+/// Subdirectory of the state directory with the installed packs, one `<version>.json` each.
+const DIR: &str = "rules";
+const TIMEOUT: Duration = Duration::from_secs(30);
+
+/// Keywords that reveal a Dev Service in the manifests, .env and sources.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct ServiceRule {
+    pub service: String,
+    pub keywords: Vec<String>,
+    /// Image for services dx does not know; known ones use their built-in definition
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub image: Option<String>,
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub ports: Vec<u16>,
+}
+
+/// A Go client module (prefix) and the infrastructure it talks to.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct GoClientRule {
+    pub module: String,
+    pub kind: String,
+    /// Dev Services name, when dx can start it locally
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub service: Option<String>,
+}
+
+/// A Hex package (Elixir, Erlang) and the infrastructure it talks to.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct HexPackageRule {
+    pub package: String,
+    /// Modules whose use in the code shows the client is called (`Postgrex`, `Ecto.Adapters.Postgres`, `brod`)
+    pub modules: Vec<String>,
+    pub kind: String,
+    /// Dev Services name, when dx can start it locally
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub service: Option<String>,
+}
+
+/// A web framework: the dependency that reveals it in the stack's manifest and its README badge.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct FrameworkRule {
+    /// go, node, python, java-maven, java-gradle or dotnet
+    pub stack: String,
+    pub dependency: String,
+    pub badge: String,
+}
+
+impl FrameworkRule {
+    pub fn applies_to(&self, stack: Stack) -> bool {
+        stack.key() == Some(self.stack.as_str())
+    }
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct RulePack {
+    /// Dotted numbers (`2025.10.0`); higher is newer
+    pub version: String,
+    /// Oldest dx version that understands the pack, if it needs one
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub min_dx: Option<String>,
+    pub services: Vec<ServiceRule>,
+    pub go_clients: Vec<GoClientRule>,
+    /// Absent from packs older than the Elixir and Erlang detection
+    #[serde(default)]
+    pub hex_packages: Vec<HexPackageRule>,
+    pub frameworks: Vec<FrameworkRule>,
+}
+
+/// Where the pack in use came from.
+#[derive(Debug, Clone, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Origin {
+    Builtin,
+    Installed,
+}
+
+/// The pack a project is analyzed with.
+#[derive(Debug, Clone)]
+pub struct Active {
+    pub pack: RulePack,
+    pub sha256: String,
+    pub origin: Origin,
+    /// Version pinned in the project's dx.lock, if any
+    pub pinned: Option<String>,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct RulesPin {
+    pub version: String,
+    pub sha256: String,
+}
+
+/// dx.lock: what a project pins for reproducible analysis.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct DxLock {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub rules: Option<RulesPin>,
+}
+
+fn sha256(content: &str) -> String {
+    Sha256::digest(content.as_bytes()).iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+/// Numeric components of a version, for ordering (`2025.10.0` → [2025, 10, 0]).
+fn version_key(version: &str) -> Vec<u64> {
+    version.trim_start_matches('v').split(['.', '-']).map(|p| p.parse().unwrap_or(0)).collect()
+}
+
+fn dir() -> PathBuf {
+    crate::paths::state_dir().join(DIR)
+}
+
+/// Parse a pack and check that this dx can use it.
+pub fn parse(content: &str) -> Result<RulePack, String> {
+    let pack: RulePack = serde_json::from_str(content).map_err(|e| format!("pacote de regras inválido: {}", e))?;
+    if pack.version.trim().is_empty() || version_key(&pack.version).iter().all(|n| *n == 0) {
+        return Err("pacote de regras sem versão".to_string());
+    }
+    if let Some(min) = &pack.min_dx {
+        let current = env!("CARGO_PKG_VERSION");
+        // Pre-release builds (0.0.0-ALPHA) accept any pack
+        if version_key(current).iter().any(|n| *n > 0) && version_key(current) < version_key(min) {
+            return Err(format!("as regras {} exigem o dx {} ou mais novo (este é o {})", pack.version, min, current));
+        }
+    }
+    Ok(pack)
+}
+
+fn builtin() -> (RulePack, String) {
+    let pack = parse(BUILTIN).expect("embedded rule pack");
+    (pack, sha256(BUILTIN))
+}
+
+/// Installed packs as (version, content), oldest first.
+fn installed() -> Vec<(String, String)> {
+    let mut packs: Vec<(String, String)> = fs::read_dir(dir())
+        .map(|entries| {
+            entries
+                .flatten()
+                .filter_map(|e| {
+                    let content = fs::read_to_string(e.path()).ok()?;
+                    let pack = parse(&content).ok()?;
+                    Some((pack.version, content))
+                })
+                .collect()
+        })
+        .unwrap_or_default();
+    packs.sort_by_key(|(v, _)| version_key(v));
+    packs
+}
+
+pub fn read_lock(project_dir: &Path) -> Result<Option<DxLock>, String> {
+    let path = project_dir.join(LOCK_FILE);
+    let Ok(content) = fs::read_to_string(&path) else { return Ok(None) };
+    serde_yaml::from_str(&content).map(Some).map_err(|e| format!("{}: {}", path.display(), e))
+}
+
+/// The pack of a version: the embedded one or an installed one, with its hash.
+fn find(version: &str) -> Option<(RulePack, String, Origin)> {
+    let (pack, hash) = builtin();
+    if version_key(&pack.version) == version_key(version) {
+        return Some((pack, hash, Origin::Builtin));
+    }
+    installed()
+        .into_iter()
+        .find(|(v, _)| version_key(v) == version_key(version))
+        .and_then(|(_, content)| parse(&content).ok().map(|p| (p, sha256(&content), Origin::Installed)))
+}
+
+/// The pack of the pin: an embedded or installed one with the pinned version and hash, else the
+/// pinned release downloaded, checked against the hash and installed.
+fn pinned_pack(pin: &RulesPin) -> Result<(RulePack, String, Origin), String> {
+    let (pack, hash) = builtin();
+    let installed = installed().into_iter().filter_map(|(_, content)| Some((parse(&content).ok()?, sha256(&content), Origin::Installed)));
+    let mut candidates = std::iter::once((pack, hash, Origin::Builtin)).chain(installed).filter(|(p, _, _)| version_key(&p.version) == version_key(&pin.version));
+    if let Some(found) = candidates.find(|(_, hash, _)| *hash == pin.sha256) {
+        return Ok(found);
+    }
+    if crate::settings::get_bool("offline") {
+        return Err(format!(
+            "o {} fixa as regras {} (sha256 {}), que não estão instaladas com esse hash, e o dx está offline; rode: dx rules update --version {}",
+            LOCK_FILE, pin.version, short(&pin.sha256), pin.version
+        ));
+    }
+    eprintln!("Baixando as regras {} fixadas no {}...", pin.version, LOCK_FILE);
+    let content = fetch(&release_url(Some(&pin.version)))?;
+    let hash = sha256(&content);
+    if hash != pin.sha256 {
+        return Err(format!(
+            "as regras {} publicadas têm sha256 {}, não o {} fixado no {}; confira o pin ou fixe outras com: dx rules pin --version <versão>",
+            pin.version, short(&hash), short(&pin.sha256), LOCK_FILE
+        ));
+    }
+    let pack = parse(&content)?;
+    install(&content, &pack).map_err(|e| format!("erro ao instalar as regras {}: {}", pin.version, e))?;
+    Ok((pack, hash, Origin::Installed))
+}
+
+fn short(hash: &str) -> &str {
+    &hash[..hash.len().min(12)]
+}
+
+/// Decide the pack of a project: the one pinned in dx.lock, else the newest of the embedded and
+/// the installed ones. A pin that cannot be honored is an error: analyzing with other rules would
+/// not be reproducible.
+fn resolve(project_dir: &Path) -> Result<Active, String> {
+    let pinned = read_lock(project_dir).map_err(|e| format!("{}; corrija ou apague o {}", e, LOCK_FILE))?.and_then(|l| l.rules);
+    if let Some(pin) = pinned {
+        let (pack, sha256, origin) = pinned_pack(&pin)?;
+        return Ok(Active { pack, sha256, origin, pinned: Some(pin.version) });
+    }
+    let (builtin_pack, builtin_hash) = builtin();
+    if let Some((version, content)) = installed().pop() {
+        if version_key(&version) > version_key(&builtin_pack.version) {
+            if let Ok(pack) = parse(&content) {
+                return Ok(Active { pack, sha256: sha256(&content), origin: Origin::Installed, pinned: None });
+            }
+        }
+    }
+    Ok(Active { pack: builtin_pack, sha256: builtin_hash, origin: Origin::Builtin, pinned: None })
+}
+
+/// The rules `project_dir` is analyzed with, resolved once per run.
+pub fn active(project_dir: &Path) -> Arc<Active> {
+    static RESOLVED: OnceLock<Mutex<HashMap<PathBuf, Arc<Active>>>> = OnceLock::new();
+    let key = fs::canonicalize(project_dir).unwrap_or_else(|_| project_dir.to_path_buf());
+    let cache = RESOLVED.get_or_init(Default::default);
+    if let Some(active) = cache.lock().ok().and_then(|c| c.get(&key).cloned()) {
+        return active;
+    }
+    let active = match resolve(project_dir) {
+        Ok(active) => Arc::new(active),
+        Err(e) => {
+            eprintln!("Erro: {}", e);
+            crate::exit(1);
+        }
+    };
+    if let Ok(mut c) = cache.lock() {
+        c.insert(key, active.clone());
+    }
+    active
+}
+
+/// Where `dx rules update` downloads a pack: the latest release, or the release of `version`.
+fn release_url(version: Option<&str>) -> String {
+    let base = crate::settings::get("rules_url");
+    let base = base.trim_end_matches('/');
+    match version {
+        Some(v) => format!("{}/download/v{}/detection.json", base, v.trim_start_matches('v')),
+        None => format!("{}/latest/download/detection.json", base),
+    }
+}
+
+fn fetch(source: &str) -> Result<String, String> {
+    if !source.starts_with("http://") && !source.starts_with("https://") {
+        return fs::read_to_string(source).map_err(|e| format!("{}: {}", source, e));
+    }
+    let client = reqwest::blocking::Client::builder()
+        .timeout(TIMEOUT)
+        .user_agent(concat!("dx-cli/", env!("CARGO_PKG_VERSION")))
+        .build()
+        .map_err(|e| e.to_string())?;
+    client
+        .get(source)
+        .send()
+        .and_then(|r| r.error_for_status())
+        .and_then(|r| r.text())
+        .map_err(|e| format!("{}: {}", source, e))
+}
+
+fn install(content: &str, pack: &RulePack) -> io::Result<PathBuf> {
+    let path = dir().join(format!("{}.json", pack.version));
+    fs::create_dir_all(dir())?;
+    crate::lock::write_atomic(&path, content)?;
+    Ok(path)
+}
+
+fn write_lock(project_dir: &Path, pin: RulesPin) -> io::Result<PathBuf> {
+    let path = project_dir.join(LOCK_FILE);
+    let lock = DxLock { rules: Some(pin) };
+    let yaml = serde_yaml::to_string(&lock).map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e.to_string()))?;
+    crate::audit::write(&path, format!("{}\n{}", LOCK_HEADER, yaml))?;
+    Ok(path)
+}
+
+fn summary(pack: &RulePack) -> String {
+    format!(
+        "{} serviço(s), {} cliente(s) Go, {} pacote(s) Hex, {} framework(s)",
+        pack.services.len(),
+        pack.go_clients.len(),
+        pack.hex_packages.len(),
+        pack.frameworks.len()
+    )
+}
+
+#[derive(Serialize)]
+struct PackJson {
+    version: String,
+    sha256: String,
+    origin: Origin,
+    active: bool,
+    services: usize,
+    go_clients: usize,
+    hex_packages: usize,
+    frameworks: usize,
+}
+
+#[derive(Serialize)]
+struct ListJson {
+    active: String,
+    pinned: Option<String>,
+    packs: Vec<PackJson>,
+}
+
+/// `dx rules list`: the embedded and installed packs, marking the one `dir` is analyzed with.
+pub fn cmd_list(dir: Option<PathBuf>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let active = active(&project_dir);
+    let (builtin_pack, builtin_hash) = builtin();
+    let mut packs = vec![(builtin_pack, builtin_hash, Origin::Builtin)];
+    for (version, content) in installed() {
+        if version_key(&version) == version_key(&packs[0].0.version) {
+            continue;
+        }
+        if let Ok(pack) = parse(&content) {
+            packs.push((pack, sha256(&content), Origin::Installed));
+        }
+    }
+    packs.sort_by_key(|(p, _, _)| version_key(&p.version));
+
+    if crate::output::json() {
+        crate::output::print(&ListJson {
+            active: active.pack.version.clone(),
+            pinned: active.pinned.clone(),
+            packs: packs
+                .iter()
+                .map(|(p, hash, origin)| PackJson {
+                    version: p.version.clone(),
+                    sha256: hash.clone(),
+                    origin: origin.clone(),
+                    active: *hash == active.sha256,
+                    services: p.services.len(),
+                    go_clients: p.go_clients.len(),
+                    hex_packages: p.hex_packages.len(),
+                    frameworks: p.frameworks.len(),
+                })
+                .collect(),
+        });
+        return 0;
+    }
+    let how = match &active.pinned {
+        Some(_) => format!("fixadas no {}", LOCK_FILE),
+        None => "a mais nova disponível".to_string(),
+    };
+    let origin = if active.origin == Origin::Builtin { "embutidas" } else { "instaladas" };
+    println!("Regras de detecção de {}: {} ({}, {})", project_dir.display(), active.pack.version, origin, how);
+    for (pack, hash, origin) in &packs {
+        let marker = if *hash == active.sha256 { '*' } else { ' ' };
+        let origin = match origin {
+            Origin::Builtin => "embutida",
+            Origin::Installed => "instalada",
+        };
+        println!("  {} {:<12} {:<10} sha256:{}  {}", marker, pack.version, origin, &hash[..12], summary(pack));
+    }
+    0
+}
+
+/// `dx rules update`: download the latest pack (or `version`, or the pack at `from`) and install it
+/// next to the others. Projects pinned in dx.lock keep their version until `dx rules pin`.
+pub fn cmd_update(version: Option<String>, from: Option<String>, dir: Option<PathBuf>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let source = from.unwrap_or_else(|| release_url(version.as_deref()));
+    let content = match fetch(&source) {
+        Ok(c) => c,
+        Err(e) => {
+            eprintln!("Erro ao baixar as regras: {}", e);
+            return 1;
+        }
+    };
+    let pack = match parse(&content) {
+        Ok(p) => p,
+        Err(e) => {
+            eprintln!("Erro em {}: {}", source, e);
+            return 1;
+        }
+    };
+    if let Some(wanted) = &version {
+        if version_key(wanted) != version_key(&pack.version) {
+            eprintln!("Erro: {} tem as regras {}, não {}.", source, pack.version, wanted);
+            return 1;
+        }
+    }
+    let (builtin_pack, _) = builtin();
+    if version_key(&pack.version) == version_key(&builtin_pack.version) {
+        println!("As regras {} já vêm embutidas no dx; nada a instalar.", pack.version);
+        return 0;
+    }
+    match install(&content, &pack) {
+        Ok(path) => println!("✓ Regras {} instaladas em {} ({}).", pack.version, path.display(), summary(&pack)),
+        Err(e) => {
+            eprintln!("Erro ao instalar as regras: {}", e);
+            return 1;
+        }
+    }
+    if let Ok(Some(DxLock { rules: Some(pin) })) = read_lock(&project_dir) {
+        if version_key(&pin.version) != version_key(&pack.version) {
+            println!("O {} deste projeto fixa as regras {}; para adotar as novas: dx rules pin --version {}", LOCK_FILE, pin.version, pack.version);
+        }
+    }
+    0
+}
+
+/// `dx rules pin`: write the version and hash of the pack in use (or of `version`) to dx.lock, so
+/// every machine analyzes the project with the same rules.
+pub fn cmd_pin(version: Option<String>, dir: Option<PathBuf>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let (version, hash) = match version {
+        Some(v) => match find(&v) {
+            Some((pack, hash, _)) => (pack.version, hash),
+            None => {
+                eprintln!("Erro: as regras {} não estão instaladas; rode antes: dx rules update --version {}", v, v);
+                return 1;
+            }
+        },
+        None => {
+            let active = active(&project_dir);
+            (active.pack.version.clone(), active.sha256.clone())
+        }
+    };
+    if let Ok(Some(DxLock { rules: Some(pin) })) = read_lock(&project_dir) {
+        if pin.version == version && pin.sha256 == hash {
+            println!("O {} já fixa as regras {}.", LOCK_FILE, version);
+            return 0;
+        }
+    }
+    match write_lock(&project_dir, RulesPin { version: version.clone(), sha256: hash }) {
+        Ok(path) => {
+            println!("✓ Regras {} fixadas em {}.", version, path.display());
+            0
+        }
+        Err(e) => {
+            eprintln!("Erro ao salvar o {}: {}", LOCK_FILE, e);
+            1
+        }
+    }
+}
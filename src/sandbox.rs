@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use std::path::{Path, PathBuf};
+use std::process::Command;
+use std::sync::atomic::{AtomicBool, Ordering};
+
+/// `1` runs project scripts in the sandbox (also passed on to nested dx processes).
+pub const SANDBOX_ENV: &str = "DX_SANDBOX";
+/// Set inside the sandbox so nested dx processes do not try to wrap again.
+const INSIDE_ENV: &str = "DX_SANDBOXED";
+
+static ENABLED: AtomicBool = AtomicBool::new(false);
+
+pub fn enable() {
+    ENABLED.store(true, Ordering::Relaxed);
+}
+
+/// Sandbox requested by `--sandbox`, the trust decision or the `sandbox` setting
+/// (user config, dx.yaml or DX_SANDBOX=1).
+pub fn active() -> bool {
+    ENABLED.load(Ordering::Relaxed) || crate::settings::get_bool("sandbox")
+}
+
+pub(crate) fn find_in_path(program: &str) -> Option<PathBuf> {
+    let paths = std::env::var_os("PATH")?;
+    std::env::split_paths(&paths).map(|d| d.join(program)).find(|p| p.is_file())
+}
+
+/// Program that implements the sandbox on this system.
+fn backend() -> Result<PathBuf, String> {
+    if cfg!(target_os = "linux") {
+        find_in_path("bwrap").ok_or_else(|| "o sandbox requer o bubblewrap (bwrap) instalado".to_string())
+    } else if cfg!(target_os = "macos") {
+        Ok(PathBuf::from("sandbox-exec"))
+    } else {
+        Err("sandbox não suportado neste sistema (disponível no Linux e no macOS)".to_string())
+    }
+}
+
+/// Fails when the sandbox is active but cannot be used here, before any script starts.
+pub fn check() -> Result<(), String> {
+    if !active() || std::env::var_os(INSIDE_ENV).is_some() {
+        return Ok(());
+    }
+    backend().map(|_| ())
+}
+
+/// Wrap `cmd` so it runs without network access and can only write inside `project_dir`
+/// and the temporary directory. Uses bubblewrap (`bwrap`) on Linux and `sandbox-exec` on macOS.
+/// Returns the command unchanged when the sandbox is not active, and an error when it is
+/// active but not available on this system (scripts never run unconfined by accident).
+pub fn wrap(project_dir: &Path, cmd: Command) -> Result<Command, String> {
+    if !active() || std::env::var_os(INSIDE_ENV).is_some() {
+        return Ok(cmd);
+    }
+    let project = std::fs::canonicalize(project_dir).unwrap_or_else(|_| project_dir.to_path_buf());
+    let cwd = cmd.get_current_dir().map(Path::to_path_buf).unwrap_or_else(|| project.clone());
+
+    let program = backend()?;
+    let mut wrapped = if cfg!(target_os = "linux") {
+        let mut c = Command::new(program);
+        c.args(["--ro-bind", "/", "/", "--dev", "/dev", "--proc", "/proc", "--tmpfs", "/tmp"])
+            .arg("--bind")
+            .arg(&project)
+            .arg(&project)
+            .args(["--unshare-net", "--unshare-pid", "--die-with-parent", "--chdir"])
+            .arg(&cwd)
+            .arg("--")
+            .arg(cmd.get_program())
+            .args(cmd.get_args());
+        c
+    } else if cfg!(target_os = "macos") {
+        let profile = format!(
+            "(version 1)(allow default)(deny network*)(allow network* (remote unix-socket))\
+             (deny file-write*)(allow file-write* (subpath \"{}\") (subpath \"/private/tmp\") \
+             (subpath \"/private/var/folders\") (literal \"/dev/null\") (literal \"/dev/tty\"))",
+            project.display().to_string().replace('"', "\\\"")
+        );
+        let mut c = Command::new(program);
+        c.arg("-p").arg(profile).arg(cmd.get_program()).args(cmd.get_args());
+        c.current_dir(&cwd);
+        c
+    } else {
+        unreachable!("backend() only succeeds on Linux and macOS")
+    };
+
+    for (key, value) in cmd.get_envs() {
+        match value {
+            Some(v) => wrapped.env(key, v),
+            None => wrapped.env_remove(key),
+        };
+    }
+    wrapped.env(INSIDE_ENV, "1").env(SANDBOX_ENV, "1");
+    Ok(wrapped)
+}
@@ -0,0 +1,164 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! SARIF 2.1.0 logs for the lint-style commands (`dev-dependencies audit` and `licenses`,
+//! `dev-secrets scan`), so CI can upload them to GitHub code scanning and show the findings as
+//! pull request annotations.
+
+use serde::Serialize;
+use std::fs;
+use std::path::Path;
+
+const SCHEMA: &str = "https://json.schemastore.org/sarif-2.1.0.json";
+
+/// A SARIF log with one run of dx.
+#[derive(Debug, Serialize)]
+pub struct Log {
+    #[serde(rename = "$schema")]
+    schema: &'static str,
+    version: &'static str,
+    runs: Vec<Run>,
+}
+
+#[derive(Debug, Serialize)]
+struct Run {
+    tool: Tool,
+    results: Vec<SarifResult>,
+}
+
+#[derive(Debug, Serialize)]
+struct Tool {
+    driver: Driver,
+}
+
+#[derive(Debug, Serialize)]
+#[serde(rename_all = "camelCase")]
+struct Driver {
+    name: &'static str,
+    semantic_version: &'static str,
+    rules: Vec<Rule>,
+}
+
+/// What a finding breaks: an advisory, a license policy, or a kind of secret.
+#[derive(Debug, Serialize)]
+#[serde(rename_all = "camelCase")]
+pub struct Rule {
+    pub id: String,
+    pub short_description: Message,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    pub help_uri: Option<String>,
+    pub properties: RuleProperties,
+}
+
+#[derive(Debug, Default, Serialize)]
+pub struct RuleProperties {
+    /// CVSS-like score (0.1 to 10.0) GitHub uses to rank security alerts
+    #[serde(rename = "security-severity", skip_serializing_if = "Option::is_none")]
+    pub security_severity: Option<String>,
+    pub tags: Vec<String>,
+}
+
+#[derive(Debug, Serialize)]
+pub struct Message {
+    pub text: String,
+}
+
+impl Message {
+    pub fn new(text: impl Into<String>) -> Message {
+        Message { text: text.into() }
+    }
+}
+
+/// How GitHub shows a result: `error` fails the check, `warning` and `note` only annotate.
+#[derive(Debug, Clone, Copy, PartialEq, Eq, Serialize)]
+#[serde(rename_all = "lowercase")]
+pub enum Level {
+    Error,
+    Warning,
+    Note,
+}
+
+#[derive(Debug, Serialize)]
+#[serde(rename = "result", rename_all = "camelCase")]
+pub struct SarifResult {
+    pub rule_id: String,
+    pub level: Level,
+    pub message: Message,
+    pub locations: Vec<Location>,
+    /// Keeps an alert the same across runs, so GitHub does not reopen it when lines move
+    pub partial_fingerprints: serde_json::Map<String, serde_json::Value>,
+}
+
+#[derive(Debug, Serialize)]
+#[serde(rename_all = "camelCase")]
+pub struct Location {
+    physical_location: PhysicalLocation,
+}
+
+#[derive(Debug, Serialize)]
+#[serde(rename_all = "camelCase")]
+struct PhysicalLocation {
+    artifact_location: ArtifactLocation,
+    #[serde(skip_serializing_if = "Option::is_none")]
+    region: Option<Region>,
+}
+
+#[derive(Debug, Serialize)]
+struct ArtifactLocation {
+    uri: String,
+}
+
+#[derive(Debug, Serialize)]
+#[serde(rename_all = "camelCase")]
+struct Region {
+    start_line: usize,
+}
+
+impl Log {
+    pub fn new(rules: Vec<Rule>, results: Vec<SarifResult>) -> Log {
+        Log {
+            schema: SCHEMA,
+            version: "2.1.0",
+            runs: vec![Run {
+                tool: Tool { driver: Driver { name: "dx", semantic_version: env!("CARGO_PKG_VERSION"), rules } },
+                results,
+            }],
+        }
+    }
+}
+
+/// The stable fingerprint of a result: the rule plus what it was found in.
+pub fn fingerprint(parts: &[&str]) -> serde_json::Map<String, serde_json::Value> {
+    let mut map = serde_json::Map::new();
+    map.insert("dx/v1".to_string(), serde_json::Value::String(parts.join("|")));
+    map
+}
+
+/// Where a dependency is declared: `source` (a manifest or lockfile of the project) and the first
+/// line that mentions `name`.
+pub fn location(project_dir: &Path, source: &str, name: &str) -> Location {
+    let name = name.to_lowercase();
+    let start_line = fs::read_to_string(project_dir.join(source))
+        .ok()
+        .and_then(|content| content.lines().position(|line| line.to_lowercase().contains(&name)))
+        .map(|i| i + 1);
+    line_location(project_dir, source, start_line)
+}
+
+/// `line` of `source`, a file of the project, relative to the current directory (the repository
+/// root in CI) when the project is below it.
+pub fn line_location(project_dir: &Path, source: &str, start_line: Option<usize>) -> Location {
+    let path = project_dir.join(source);
+    let relative = std::env::current_dir()
+        .ok()
+        .and_then(|cwd| path.canonicalize().ok().and_then(|p| p.strip_prefix(cwd.canonicalize().ok()?).ok().map(Path::to_path_buf)))
+        .unwrap_or_else(|| path.clone());
+    let uri = relative.to_string_lossy().replace('\\', "/");
+    let uri = uri.strip_prefix("./").unwrap_or(&uri).to_string();
+    Location {
+        physical_location: PhysicalLocation {
+            artifact_location: ArtifactLocation { uri },
+            region: start_line.map(|start_line| Region { start_line }),
+        },
+    }
+}
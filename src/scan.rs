@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Scanning many projects at once: the projects below a directory (a folder of repositories or
+//! the packages of a monorepo) are found by their manifests and analyzed by a pool of workers.
+
+use std::fs;
+use std::io::IsTerminal;
+use std::path::{Path, PathBuf};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::mpsc;
+use std::thread;
+use std::time::Instant;
+
+/// Files that make a directory a project root.
+const MARKERS: &[&str] = &[
+    "Cargo.toml",
+    "package.json",
+    "requirements.txt",
+    "pyproject.toml",
+    "setup.py",
+    "pom.xml",
+    "build.gradle",
+    "build.gradle.kts",
+    "Gemfile",
+    "go.mod",
+    "composer.json",
+    "mix.exs",
+    "rebar.config",
+];
+
+/// Directories that never hold projects of their own (dependencies, build output, tooling).
+const SKIP: &[&str] = &[".git", ".github", ".idea", ".vscode", ".dx", "node_modules", "target", "build", "dist", "vendor", "_build", "deps"];
+
+/// How deep below the root projects are looked for (`packages/web` is 2).
+const MAX_DEPTH: usize = 3;
+
+pub fn is_project_root(dir: &Path) -> bool {
+    MARKERS.iter().any(|m| dir.join(m).is_file()) || crate::dotnet_build::is_project(dir)
+}
+
+/// Projects below `root`, sorted: the first project root on each path, up to `MAX_DEPTH` levels
+/// down, so the packages of a project are not listed apart from it.
+pub fn subprojects(root: &Path) -> Vec<PathBuf> {
+    fn walk(dir: &Path, depth: usize, found: &mut Vec<PathBuf>) {
+        let Ok(entries) = fs::read_dir(dir) else { return };
+        for entry in entries.flatten() {
+            let path = entry.path();
+            let name = entry.file_name().to_string_lossy().into_owned();
+            if !path.is_dir() || name.starts_with('.') || SKIP.iter().any(|s| s.eq_ignore_ascii_case(&name)) {
+                continue;
+            }
+            if is_project_root(&path) {
+                found.push(path);
+            } else if depth < MAX_DEPTH {
+                walk(&path, depth + 1, found);
+            }
+        }
+    }
+    let mut found = Vec::new();
+    walk(root, 1, &mut found);
+    found.sort();
+    found
+}
+
+/// Workers for a scan: `--concurrency`, the `scan_concurrency` setting, or one per CPU when 0.
+pub fn concurrency() -> usize {
+    match crate::settings::get_u64("scan_concurrency") {
+        Some(n) if n > 0 => n as usize,
+        _ => thread::available_parallelism().map_or(4, |n| n.get()),
+    }
+}
+
+/// `path` relative to `root`, for messages about the projects of a scan.
+pub fn display(root: &Path, path: &Path) -> String {
+    path.strip_prefix(root).unwrap_or(path).display().to_string()
+}
+
+/// Run `work` on each project with `concurrency()` workers. Results keep the order of `projects`;
+/// each finished project is a step of `phase` and, on a terminal, a line on stderr.
+pub fn each<T: Send>(root: &Path, projects: &[PathBuf], phase: &crate::progress::Phase, work: impl Fn(&Path) -> T + Sync) -> Vec<T> {
+    let next = AtomicUsize::new(0);
+    let mut results: Vec<Option<T>> = projects.iter().map(|_| None).collect();
+    let show = std::io::stderr().is_terminal() && !crate::output::json();
+    thread::scope(|s| {
+        let (tx, rx) = mpsc::channel();
+        for _ in 0..concurrency().min(projects.len()) {
+            let (tx, next, work) = (tx.clone(), &next, &work);
+            s.spawn(move || loop {
+                let i = next.fetch_add(1, Ordering::Relaxed);
+                if i >= projects.len() {
+                    break;
+                }
+                let started = Instant::now();
+                let result = work(&projects[i]);
+                if tx.send((i, result, started.elapsed())).is_err() {
+                    break;
+                }
+            });
+        }
+        drop(tx);
+        for (done, (i, result, elapsed)) in rx.iter().enumerate() {
+            let name = display(root, &projects[i]);
+            if show {
+                eprintln!("  [{}/{}] {} ({:.1}s)", done + 1, projects.len(), name, elapsed.as_secs_f64());
+            }
+            phase.step(done + 1, projects.len(), &name);
+            results[i] = Some(result);
+        }
+    });
+    results.into_iter().map(|r| r.expect("scan worker panicked")).collect()
+}
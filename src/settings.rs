@@ -0,0 +1,557 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fmt;
+use std::path::PathBuf;
+use std::sync::{Mutex, OnceLock};
+
+/// A setting dx understands and where each layer can set it.
+pub struct Setting {
+    pub key: &'static str,
+    pub description: &'static str,
+    pub default: &'static str,
+    /// Environment variable layer
+    pub env: Option<&'static str>,
+    /// Command-line flag layer
+    pub flag: Option<&'static str>,
+    /// The project layer can only turn the setting on (dx.yaml cannot weaken a safety choice of the user)
+    pub project_enable_only: bool,
+    /// The project layer is ignored: the value decides where dx sends data and credentials, which a
+    /// cloned repository must not choose
+    pub not_from_project: bool,
+    validate: fn(&str) -> Result<String, String>,
+}
+
+fn seconds(v: &str) -> Result<String, String> {
+    v.trim().parse::<u64>().map(|n| n.to_string()).map_err(|_| "espera um número de segundos".to_string())
+}
+
+fn count(v: &str) -> Result<String, String> {
+    v.trim().parse::<u64>().map(|n| n.to_string()).map_err(|_| "espera um número inteiro".to_string())
+}
+
+/// A duration with an s, m, h or d suffix (seconds without one), normalized to seconds.
+fn duration(v: &str) -> Result<String, String> {
+    let v = v.trim().to_lowercase();
+    let (number, unit) = match v.char_indices().find(|(_, c)| !c.is_ascii_digit()) {
+        Some((i, _)) => v.split_at(i),
+        None => (v.as_str(), "s"),
+    };
+    let unit = match unit.trim() {
+        "s" => 1,
+        "m" => 60,
+        "h" => 60 * 60,
+        "d" => 24 * 60 * 60,
+        _ => 0,
+    };
+    match number.parse::<u64>() {
+        Ok(n) if unit > 0 && n > 0 => Ok((n * unit).to_string()),
+        _ => Err("espera uma duração como 90m, 24h ou 7d".to_string()),
+    }
+}
+
+fn word(v: &str) -> Result<String, String> {
+    let v = v.trim();
+    match v.chars().any(char::is_whitespace) {
+        true => Err("espera um valor sem espaços".to_string()),
+        false => Ok(v.trim_end_matches('/').to_string()),
+    }
+}
+
+fn boolean(v: &str) -> Result<String, String> {
+    match v.trim().to_lowercase().as_str() {
+        "true" | "1" | "yes" | "sim" => Ok("true".to_string()),
+        "false" | "0" | "no" | "não" | "nao" => Ok("false".to_string()),
+        _ => Err("espera true ou false".to_string()),
+    }
+}
+
+fn text_or_json(v: &str) -> Result<String, String> {
+    match v.trim().to_lowercase().as_str() {
+        m @ ("text" | "json") => Ok(m.to_string()),
+        _ => Err("espera text ou json".to_string()),
+    }
+}
+
+fn url(v: &str) -> Result<String, String> {
+    let v = v.trim();
+    match v.starts_with("https://") || v.starts_with("http://") {
+        true => Ok(v.trim_end_matches('/').to_string()),
+        false => Err("espera uma URL http(s)://".to_string()),
+    }
+}
+
+fn output_mode(v: &str) -> Result<String, String> {
+    match v.trim().to_lowercase().as_str() {
+        m @ ("text" | "json" | "sarif") => Ok(m.to_string()),
+        _ => Err("espera text, json ou sarif".to_string()),
+    }
+}
+
+/// Settings, lowest layer first: built-in default < team profile < user config < project dx.yaml <
+/// local .dx/config.yaml < environment < flag.
+pub const SETTINGS: &[Setting] = &[
+    Setting {
+        key: "notify_after",
+        description: "segundos; notifica comandos mais longos (0 desativa)",
+        default: "0",
+        env: Some(crate::notifications::NOTIFY_AFTER_ENV),
+        flag: Some("--notify-after"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: seconds,
+    },
+    Setting {
+        key: "progress",
+        description: "text ou json (eventos NDJSON de progresso)",
+        default: "text",
+        env: Some("DX_PROGRESS"),
+        flag: Some("--progress"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: text_or_json,
+    },
+    Setting {
+        key: "output",
+        description: "text, json ou sarif (saída dos comandos para scripts e code scanning)",
+        default: "text",
+        env: Some("DX_OUTPUT"),
+        flag: Some("--output"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: output_mode,
+    },
+    Setting {
+        key: "lock_timeout",
+        description: "segundos de espera por outro dx usando o mesmo estado",
+        default: "120",
+        env: Some("DX_LOCK_TIMEOUT"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: seconds,
+    },
+    Setting {
+        key: "sandbox",
+        description: "true: sempre executa os scripts do projeto no sandbox",
+        default: "false",
+        env: Some(crate::sandbox::SANDBOX_ENV),
+        flag: Some("--sandbox"),
+        project_enable_only: true,
+        not_from_project: false,
+        validate: boolean,
+    },
+    Setting {
+        key: "report_sinks",
+        description: "destinos extras dos relatórios (audit, drift, analyzer), separados por vírgula: s3://, gs://, http(s)://, mongodb://",
+        default: "",
+        env: Some("DX_REPORT_SINKS"),
+        flag: Some("--sink"),
+        project_enable_only: false,
+        not_from_project: true,
+        validate: crate::sinks::validate,
+    },
+    Setting {
+        key: "registry_rate",
+        description: "consultas por segundo a cada registry de pacotes (0 usa o limite de cada um, ex.: 1/s no crates.io)",
+        default: "0",
+        env: Some("DX_REGISTRY_RATE"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: count,
+    },
+    Setting {
+        key: "registry_cache_ttl",
+        description: "segundos em que as respostas dos registries são reaproveitadas, para retomar varreduras (0 desativa)",
+        default: "3600",
+        env: Some("DX_REGISTRY_CACHE_TTL"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: seconds,
+    },
+    Setting {
+        key: "offline",
+        description: "true: não consulta os registries; responde só com o que está guardado (ex.: após dx cache prefetch)",
+        default: "false",
+        env: Some("DX_OFFLINE"),
+        flag: Some("--offline"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: boolean,
+    },
+    Setting {
+        key: "detection_cache",
+        description: "true: reaproveita a detecção dos projetos cujos manifestos e lockfiles não mudaram (--no-cache desativa)",
+        default: "true",
+        env: Some("DX_DETECTION_CACHE"),
+        flag: Some("--no-cache"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: boolean,
+    },
+    Setting {
+        key: "maven_repository",
+        description: "repositório Maven de onde vêm os POMs pais, os BOMs e as versões mais recentes das dependências Java",
+        default: "https://repo1.maven.org/maven2",
+        env: Some("DX_MAVEN_REPOSITORY"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: url,
+    },
+    Setting {
+        key: "nuget_feed",
+        description: "feed NuGet (API v3, flat container) de onde vêm as versões mais recentes das dependências .NET",
+        default: "https://api.nuget.org/v3-flatcontainer",
+        env: Some("DX_NUGET_FEED"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: url,
+    },
+    Setting {
+        key: "crates_registry",
+        description: "registry de crates (API do crates.io) de onde vêm as versões mais recentes das dependências Rust",
+        default: "https://crates.io",
+        env: Some("DX_CRATES_REGISTRY"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: url,
+    },
+    Setting {
+        key: "hex_registry",
+        description: "repositório Hex (API do hex.pm) de onde vêm as versões mais recentes das dependências Elixir e Erlang",
+        default: "https://hex.pm",
+        env: Some("DX_HEX_REGISTRY"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: url,
+    },
+    Setting {
+        key: "rules_url",
+        description: "releases de onde `dx rules update` baixa as regras de detecção (<url>/latest/download/detection.json)",
+        default: "https://github.com/dx-anywhere/dx-rules/releases",
+        env: Some("DX_RULES_URL"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: url,
+    },
+    Setting {
+        key: "scan_concurrency",
+        description: "projetos analisados em paralelo quando o diretório tem vários (0 = um por CPU)",
+        default: "0",
+        env: Some("DX_SCAN_CONCURRENCY"),
+        flag: Some("--concurrency"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: count,
+    },
+    Setting {
+        key: "watch_debounce",
+        description: "milissegundos sem novas alterações antes de reiniciar a aplicação no --watch",
+        default: "300",
+        env: Some("DX_WATCH_DEBOUNCE"),
+        flag: Some("--debounce"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: count,
+    },
+    Setting {
+        key: "watch_ignore",
+        description: "padrões ignorados pelo --watch, separados por vírgula (ex.: *_test.go,gen/), além de .git, node_modules, vendor e pastas de build",
+        default: "",
+        env: Some("DX_WATCH_IGNORE"),
+        flag: Some("--ignore"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: crate::supervisor::validate_patterns,
+    },
+    Setting {
+        key: "conflict_strategy",
+        description: "quando um gerador encontra um arquivo que não foi gerado pelo dx: ask (trecho a trecho, no terminal), ours (mantém), theirs (substitui) ou fail",
+        default: "ask",
+        env: Some("DX_CONFLICT_STRATEGY"),
+        flag: Some("--strategy"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: crate::conflicts::validate,
+    },
+    Setting {
+        key: "preview_target",
+        description: "onde dx preview create implanta: kind (cluster local) ou cluster (namespace no contexto atual do kubectl)",
+        default: "kind",
+        env: Some("DX_PREVIEW_TARGET"),
+        flag: Some("--target"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: crate::preview::validate_target,
+    },
+    Setting {
+        key: "preview_ttl",
+        description: "tempo de vida dos ambientes de preview (ex.: 90m, 24h, 7d); os expirados são removidos por dx preview prune e a cada create",
+        default: "86400",
+        env: Some("DX_PREVIEW_TTL"),
+        flag: Some("--ttl"),
+        project_enable_only: false,
+        not_from_project: false,
+        validate: duration,
+    },
+    Setting {
+        key: "preview_registry",
+        description: "registry para onde dx preview create envia a imagem quando o alvo é um cluster remoto (ex.: ghcr.io/minha-org)",
+        default: "",
+        env: Some("DX_PREVIEW_REGISTRY"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: word,
+    },
+    Setting {
+        key: "preview_domain",
+        description: "domínio com DNS curinga apontando para o Ingress do cluster; cada preview fica em http://<nome>.<domínio> (vazio: só port-forward)",
+        default: "",
+        env: Some("DX_PREVIEW_DOMAIN"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: word,
+    },
+    Setting {
+        key: "preview_kind_cluster",
+        description: "nome do cluster kind local dos previews, criado por dx preview create quando não existe",
+        default: "dx-preview",
+        env: Some("DX_PREVIEW_KIND_CLUSTER"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: word,
+    },
+    Setting {
+        key: "preview_janitor_image",
+        description: "imagem com sh, date e kubectl do CronJob que remove o namespace do preview quando ele expira",
+        default: "bitnami/kubectl:latest",
+        env: Some("DX_PREVIEW_JANITOR_IMAGE"),
+        flag: None,
+        project_enable_only: false,
+        not_from_project: false,
+        validate: word,
+    },
+];
+
+/// Scalar value of a setting in dx.yaml (`notify_after: 30`, `sandbox: true`, `progress: json`).
+#[derive(Debug, Clone, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(untagged)]
+pub enum ScalarValue {
+    Bool(bool),
+    Int(u64),
+    Str(String),
+}
+
+impl fmt::Display for ScalarValue {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            ScalarValue::Bool(b) => write!(f, "{}", b),
+            ScalarValue::Int(n) => write!(f, "{}", n),
+            ScalarValue::Str(s) => write!(f, "{}", s),
+        }
+    }
+}
+
+/// Where a value came from.
+#[derive(Debug, Clone, PartialEq, Eq)]
+pub enum Source {
+    Default,
+    /// Team profile referenced by .dx/config.yaml (its URL or path)
+    Team(String),
+    User(PathBuf),
+    Project(PathBuf),
+    /// Local overrides of .dx/config.yaml
+    Local(PathBuf),
+    Env(&'static str),
+    Flag(&'static str),
+}
+
+impl fmt::Display for Source {
+    fn fmt(&self, f: &mut fmt::Formatter<'_>) -> fmt::Result {
+        match self {
+            Source::Default => write!(f, "padrão"),
+            Source::Team(s) => write!(f, "perfil da equipe ({})", s),
+            Source::User(p) => write!(f, "usuário ({})", p.display()),
+            Source::Project(p) => write!(f, "projeto ({})", p.display()),
+            Source::Local(p) => write!(f, "local ({})", p.display()),
+            Source::Env(name) => write!(f, "ambiente ({})", name),
+            Source::Flag(name) => write!(f, "opção ({})", name),
+        }
+    }
+}
+
+/// One layer's opinion on a setting; `error` explains why the layer was ignored.
+#[derive(Debug, Clone)]
+pub struct Layer {
+    pub source: Source,
+    pub value: Option<String>,
+    pub error: Option<String>,
+}
+
+#[derive(Debug, Clone)]
+pub struct Resolved {
+    pub key: &'static str,
+    pub value: String,
+    pub source: Source,
+    /// Every layer, lowest first, including the ones without a value
+    pub layers: Vec<Layer>,
+}
+
+fn flags() -> &'static Mutex<BTreeMap<&'static str, String>> {
+    static FLAGS: OnceLock<Mutex<BTreeMap<&'static str, String>>> = OnceLock::new();
+    FLAGS.get_or_init(Default::default)
+}
+
+/// Record a value given on the command line (top layer).
+pub fn set_flag(key: &'static str, value: impl Into<String>) {
+    if let Ok(mut f) = flags().lock() {
+        f.insert(key, value.into());
+    }
+}
+
+pub fn find(key: &str) -> Option<&'static Setting> {
+    SETTINGS.iter().find(|s| s.key == key)
+}
+
+/// Validate a value for `key`, returning its normalized form.
+pub fn validate(key: &str, value: &str) -> Result<String, String> {
+    match find(key) {
+        Some(s) => (s.validate)(value).map_err(|e| format!("{} {}", key, e)),
+        None => {
+            let known: Vec<&str> = SETTINGS.iter().map(|s| s.key).collect();
+            Err(format!("configuração desconhecida: {} (disponíveis: {})", key, known.join(", ")))
+        }
+    }
+}
+
+/// Settings of the dx.yaml in the current directory (read once per process).
+fn project_settings() -> &'static BTreeMap<String, String> {
+    static PROJECT: OnceLock<BTreeMap<String, String>> = OnceLock::new();
+    PROJECT.get_or_init(|| {
+        let dir = std::env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+        crate::tasks::load(&dir)
+            .ok()
+            .flatten()
+            .map(|f| f.settings.iter().map(|(k, v)| (k.clone(), v.to_string())).collect())
+            .unwrap_or_default()
+    })
+}
+
+/// Team profile and local override layers, from the .dx/config.yaml of the current directory.
+fn team_settings() -> &'static (Option<String>, BTreeMap<String, String>, BTreeMap<String, String>) {
+    static TEAM: OnceLock<(Option<String>, BTreeMap<String, String>, BTreeMap<String, String>)> = OnceLock::new();
+    TEAM.get_or_init(crate::team_profile::settings_layers)
+}
+
+fn user_settings() -> &'static BTreeMap<String, String> {
+    static USER: OnceLock<BTreeMap<String, String>> = OnceLock::new();
+    USER.get_or_init(crate::user_config::load)
+}
+
+/// Resolve `key` through every layer; the highest layer with a valid value wins.
+pub fn resolve(key: &str) -> Option<Resolved> {
+    let setting = find(key)?;
+    let check = |source: Source, raw: Option<String>| -> Layer {
+        match raw {
+            None => Layer { source, value: None, error: None },
+            Some(v) => match (setting.validate)(&v) {
+                Ok(value) => Layer { source, value: Some(value), error: None },
+                Err(e) => Layer { source, value: Some(v), error: Some(e) },
+            },
+        }
+    };
+
+    let (team_source, team, local) = team_settings();
+    let mut layers = vec![Layer { source: Source::Default, value: Some(setting.default.to_string()), error: None }];
+    if let Some(source) = team_source {
+        layers.push(check(Source::Team(source.clone()), team.get(key).cloned()));
+    }
+    layers.push(check(Source::User(crate::user_config::settings_path()), user_settings().get(key).cloned()));
+    let mut project = check(Source::Project(PathBuf::from(crate::tasks::DX_FILE)), project_settings().get(key).cloned());
+    if setting.project_enable_only && project.error.is_none() && project.value.as_deref() == Some("false") {
+        project.error = Some("projetos só podem ativar esta configuração".to_string());
+    }
+    if setting.not_from_project && project.value.is_some() {
+        project.error = Some("não pode ser definida pelo dx.yaml do projeto".to_string());
+    }
+    layers.push(project);
+    if !local.is_empty() {
+        layers.push(check(Source::Local(PathBuf::from(crate::team_profile::LOCAL_FILE)), local.get(key).cloned()));
+    }
+    if let Some(env) = setting.env {
+        layers.push(check(Source::Env(env), std::env::var(env).ok().filter(|v| !v.is_empty())));
+    }
+    if let Some(flag) = setting.flag {
+        let value = flags().lock().ok().and_then(|f| f.get(key).cloned());
+        layers.push(check(Source::Flag(flag), value));
+    }
+
+    let winner = layers.iter().rev().find(|l| l.value.is_some() && l.error.is_none())?;
+    Some(Resolved {
+        key: setting.key,
+        value: winner.value.clone().unwrap_or_default(),
+        source: winner.source.clone(),
+        layers: layers.clone(),
+    })
+}
+
+/// Effective value of a known setting.
+pub fn get(key: &str) -> String {
+    resolve(key).map(|r| r.value).unwrap_or_default()
+}
+
+pub fn get_bool(key: &str) -> bool {
+    get(key) == "true"
+}
+
+pub fn get_u64(key: &str) -> Option<u64> {
+    get(key).parse().ok()
+}
+
+/// `dx config get [<chave>] [--explain]`: effective values and, with `--explain`, every layer.
+/// A single key without `--explain` prints only the value, for scripts.
+pub fn cmd_get(key: Option<String>, explain: bool) {
+    if let Some(k) = &key {
+        if find(k).is_none() {
+            let known: Vec<&str> = SETTINGS.iter().map(|s| s.key).collect();
+            eprintln!("Erro: configuração desconhecida: {} (disponíveis: {})", k, known.join(", "));
+            crate::exit(2);
+        }
+    }
+    let keys: Vec<&str> = match &key {
+        Some(k) => vec![k.as_str()],
+        None => SETTINGS.iter().map(|s| s.key).collect(),
+    };
+    for name in keys {
+        let Some(r) = resolve(name) else { continue };
+        if !explain {
+            if key.is_some() {
+                println!("{}", r.value);
+            } else {
+                println!("{} = {} ({})", r.key, r.value, r.source);
+            }
+            continue;
+        }
+        println!("{} = {}", r.key, r.value);
+        for layer in &r.layers {
+            let marker = if layer.source == r.source { "→" } else { " " };
+            let value = layer.value.as_deref().unwrap_or("-");
+            match &layer.error {
+                Some(e) => println!("  {} {:<44} {} (ignorado: {})", marker, layer.source.to_string(), value, e),
+                None => println!("  {} {:<44} {}", marker, layer.source.to_string(), value),
+            }
+        }
+    }
+}
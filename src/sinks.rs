@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde_json::{json, Value};
+use sha2::{Digest, Sha256};
+use std::path::Path;
+use std::time::Duration;
+
+/// Schemes a sink can use: S3 and GCS buckets, HTTP endpoints and MongoDB.
+const SCHEMES: &[&str] = &["s3://", "gs://", "http://", "https://", "mongodb://"];
+/// Bearer token sent to HTTP sinks, when set.
+const TOKEN_ENV: &str = "DX_SINK_TOKEN";
+/// Database and collection of MongoDB sinks whose URI names no database.
+const MONGO_DATABASE: &str = "dx";
+const MONGO_COLLECTION: &str = "reports";
+const TIMEOUT: Duration = Duration::from_secs(30);
+
+/// Content of a report: JSON documents or Markdown text.
+pub enum Content {
+    Json(Value),
+    Markdown(String),
+}
+
+/// A report to deliver: its kind (`audit`, `drift`, `analyzer`, `archetype`), the project it describes and its content.
+pub struct Report<'a> {
+    pub kind: &'static str,
+    pub project_dir: &'a Path,
+    pub content: Content,
+}
+
+impl Report<'_> {
+    fn project(&self) -> String {
+        let dir = self.project_dir.canonicalize().unwrap_or_else(|_| self.project_dir.to_path_buf());
+        dir.file_name().map(|n| n.to_string_lossy().into_owned()).unwrap_or_else(|| "projeto".to_string())
+    }
+
+    fn extension(&self) -> &'static str {
+        match self.content {
+            Content::Json(_) => "json",
+            Content::Markdown(_) => "md",
+        }
+    }
+
+    fn content_type(&self) -> &'static str {
+        match self.content {
+            Content::Json(_) => "application/json",
+            Content::Markdown(_) => "text/markdown; charset=utf-8",
+        }
+    }
+
+    fn body(&self) -> Vec<u8> {
+        match &self.content {
+            Content::Json(doc) => serde_json::to_vec_pretty(doc).unwrap_or_default(),
+            Content::Markdown(text) => text.clone().into_bytes(),
+        }
+    }
+
+    /// The report with what identifies it, as HTTP and MongoDB sinks receive it.
+    fn envelope(&self, generated_at: Value) -> Value {
+        let mut doc = json!({ "kind": self.kind, "project": self.project(), "generated_at": generated_at });
+        match &self.content {
+            Content::Json(report) => doc["report"] = report.clone(),
+            Content::Markdown(text) => doc["markdown"] = json!(text),
+        }
+        doc
+    }
+}
+
+/// Validate the `report_sinks` setting: sink URLs separated by commas.
+pub fn validate(value: &str) -> Result<String, String> {
+    let sinks: Vec<&str> = value.split(',').map(str::trim).filter(|s| !s.is_empty()).collect();
+    match sinks.iter().find(|s| !SCHEMES.iter().any(|scheme| s.starts_with(scheme))) {
+        Some(bad) => Err(format!("destino não suportado: {} (use {})", bad, SCHEMES.join(", "))),
+        None => Ok(sinks.join(",")),
+    }
+}
+
+/// Send `report` to every configured sink (`--sink`, DX_REPORT_SINKS, the user config or the team
+/// profile; never the project's dx.yaml, which could route reports and DX_SINK_TOKEN anywhere).
+/// Delivery failures are warnings: the report was already shown or written.
+pub fn publish(report: &Report) {
+    let Some(resolved) = crate::settings::resolve("report_sinks") else { return };
+    for layer in resolved.layers.iter().filter(|l| matches!(l.source, crate::settings::Source::Project(_)) && l.error.is_some()) {
+        eprintln!(
+            "Aviso: report_sinks de {} ignorado: defina os destinos com --sink, DX_REPORT_SINKS ou dx config set.",
+            layer.source
+        );
+    }
+    let sinks = resolved.value;
+    let now = std::time::SystemTime::now().duration_since(std::time::UNIX_EPOCH).unwrap_or_default().as_millis() as i64;
+    for sink in sinks.split(',').map(str::trim).filter(|s| !s.is_empty()) {
+        match send(sink, report, now) {
+            Ok(location) => eprintln!("Relatório {} enviado para {}", report.kind, location),
+            Err(e) => eprintln!("Aviso: falha ao enviar o relatório {} para {}: {}", report.kind, crate::dev_db::masked(sink), e),
+        }
+    }
+}
+
+fn send(sink: &str, report: &Report, now: i64) -> Result<String, String> {
+    let iso = crate::dev_kafka::format_timestamp(now);
+    // 20261017T120000Z: the object names' timestamp and the date of AWS signatures
+    let stamp: String = iso.split('.').next().unwrap_or_default().chars().filter(|c| *c != '-' && *c != ':').collect::<String>() + "Z";
+    let object = |location: &str| {
+        let (bucket, prefix) = location.split_once('/').unwrap_or((location, ""));
+        let key: Vec<String> = [prefix.trim_matches('/').to_string(), report.project(), report.kind.to_string(), format!("{}.{}", stamp, report.extension())]
+            .into_iter()
+            .filter(|p| !p.is_empty())
+            .collect();
+        (bucket.to_string(), key.join("/"))
+    };
+    if let Some(location) = sink.strip_prefix("s3://") {
+        let (bucket, key) = object(location);
+        put_s3(&bucket, &key, report.body(), report.content_type(), &stamp)
+    } else if let Some(location) = sink.strip_prefix("gs://") {
+        let (bucket, key) = object(location);
+        put_gcs(&bucket, &key, report.body(), report.content_type())
+    } else if sink.starts_with("mongodb://") {
+        let doc = report.envelope(json!({ "$date": iso }));
+        crate::dev_db::insert_json(sink, MONGO_DATABASE, MONGO_COLLECTION, &doc).map(|ns| format!("MongoDB {}", ns))
+    } else {
+        let mut request = client()?.post(sink).json(&report.envelope(json!(iso)));
+        if let Ok(token) = std::env::var(TOKEN_ENV) {
+            request = request.header("Authorization", format!("Bearer {}", token));
+        }
+        request.send().and_then(|r| r.error_for_status()).map_err(|e| e.to_string())?;
+        Ok(sink.to_string())
+    }
+}
+
+fn client() -> Result<reqwest::blocking::Client, String> {
+    reqwest::blocking::Client::builder()
+        .timeout(TIMEOUT)
+        .user_agent(concat!("dx-cli/", env!("CARGO_PKG_VERSION")))
+        .build()
+        .map_err(|e| e.to_string())
+}
+
+/// Percent-encoding of everything but unreserved characters (and `/` when `keep_slash`).
+fn encode(s: &str, keep_slash: bool) -> String {
+    s.bytes()
+        .map(|b| match b {
+            b'A'..=b'Z' | b'a'..=b'z' | b'0'..=b'9' | b'-' | b'_' | b'.' | b'~' => (b as char).to_string(),
+            b'/' if keep_slash => "/".to_string(),
+            _ => format!("%{:02X}", b),
+        })
+        .collect()
+}
+
+fn hex(bytes: &[u8]) -> String {
+    bytes.iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+fn env(name: &str) -> Option<String> {
+    std::env::var(name).ok().filter(|v| !v.is_empty())
+}
+
+/// Credentials and scope of an AWS Signature Version 4.
+struct Signer<'a> {
+    access_key: &'a str,
+    secret_key: &'a str,
+    region: &'a str,
+    service: &'a str,
+}
+
+impl Signer<'_> {
+    /// `Authorization` header of a request without query string; `headers` are lower-case and
+    /// sorted, and must include `host` and `x-amz-date`.
+    fn authorization(&self, method: &str, path: &str, headers: &[(&str, String)], payload_hash: &str, amz_date: &str) -> String {
+        let canonical_headers: String = headers.iter().map(|(n, v)| format!("{}:{}\n", n, v.trim())).collect();
+        let signed_headers = headers.iter().map(|(n, _)| *n).collect::<Vec<_>>().join(";");
+        let canonical_request = format!("{}\n{}\n\n{}\n{}\n{}", method, path, canonical_headers, signed_headers, payload_hash);
+        let date = &amz_date[..8];
+        let scope = format!("{}/{}/{}/aws4_request", date, self.region, self.service);
+        let string_to_sign = format!("AWS4-HMAC-SHA256\n{}\n{}\n{}", amz_date, scope, hex(&Sha256::digest(canonical_request.as_bytes())));
+        let mut key = crate::dev_db::hmac(format!("AWS4{}", self.secret_key).as_bytes(), date.as_bytes());
+        for part in [self.region, self.service, "aws4_request"] {
+            key = crate::dev_db::hmac(&key, part.as_bytes());
+        }
+        let signature = hex(&crate::dev_db::hmac(&key, string_to_sign.as_bytes()));
+        format!("AWS4-HMAC-SHA256 Credential={}/{}, SignedHeaders={}, Signature={}", self.access_key, scope, signed_headers, signature)
+    }
+}
+
+/// Upload to S3 (or an S3-compatible store such as MinIO, through AWS_ENDPOINT_URL).
+fn put_s3(bucket: &str, key: &str, body: Vec<u8>, content_type: &str, amz_date: &str) -> Result<String, String> {
+    let (Some(access_key), Some(secret_key)) = (env("AWS_ACCESS_KEY_ID"), env("AWS_SECRET_ACCESS_KEY")) else {
+        return Err("defina AWS_ACCESS_KEY_ID e AWS_SECRET_ACCESS_KEY".to_string());
+    };
+    let region = env("AWS_REGION").or_else(|| env("AWS_DEFAULT_REGION")).unwrap_or_else(|| "us-east-1".to_string());
+    // Custom endpoints use path-style addressing; AWS itself, the bucket's virtual host
+    let (url, host, path) = match env("AWS_ENDPOINT_URL_S3").or_else(|| env("AWS_ENDPOINT_URL")) {
+        Some(endpoint) => {
+            let endpoint = endpoint.trim_end_matches('/');
+            let host = endpoint.split_once("://").map_or(endpoint, |(_, h)| h).to_string();
+            let path = format!("/{}/{}", encode(bucket, false), encode(key, true));
+            (format!("{}{}", endpoint, path), host, path)
+        }
+        None => {
+            let host = format!("{}.s3.{}.amazonaws.com", bucket, region);
+            let path = format!("/{}", encode(key, true));
+            (format!("https://{}{}", host, path), host, path)
+        }
+    };
+    let payload_hash = hex(&Sha256::digest(&body));
+    let mut headers = vec![
+        ("content-type", content_type.to_string()),
+        ("host", host),
+        ("x-amz-content-sha256", payload_hash.clone()),
+        ("x-amz-date", amz_date.to_string()),
+    ];
+    let token = env("AWS_SESSION_TOKEN");
+    if let Some(token) = &token {
+        headers.push(("x-amz-security-token", token.clone()));
+    }
+    let signer = Signer { access_key: &access_key, secret_key: &secret_key, region: &region, service: "s3" };
+    let authorization = signer.authorization("PUT", &path, &headers, &payload_hash, amz_date);
+    let mut request = client()?.put(&url).header("Authorization", authorization);
+    for (name, value) in headers.iter().filter(|(n, _)| *n != "host") {
+        request = request.header(*name, value);
+    }
+    request.body(body).send().and_then(|r| r.error_for_status()).map_err(|e| e.to_string())?;
+    Ok(format!("s3://{}/{}", bucket, key))
+}
+
+/// Upload to Google Cloud Storage with an OAuth token (GOOGLE_OAUTH_ACCESS_TOKEN or gcloud's);
+/// STORAGE_EMULATOR_HOST points it at a local emulator such as fake-gcs-server.
+fn put_gcs(bucket: &str, key: &str, body: Vec<u8>, content_type: &str) -> Result<String, String> {
+    let emulator = env("STORAGE_EMULATOR_HOST");
+    let base = match &emulator {
+        Some(host) if host.contains("://") => host.trim_end_matches('/').to_string(),
+        Some(host) => format!("http://{}", host.trim_end_matches('/')),
+        None => "https://storage.googleapis.com".to_string(),
+    };
+    let token = env("GOOGLE_OAUTH_ACCESS_TOKEN").or_else(|| {
+        let out = std::process::Command::new("gcloud").args(["auth", "print-access-token"]).output().ok()?;
+        out.status.success().then(|| String::from_utf8_lossy(&out.stdout).trim().to_string())
+    });
+    if token.is_none() && emulator.is_none() {
+        return Err("defina GOOGLE_OAUTH_ACCESS_TOKEN ou autentique o gcloud (gcloud auth login)".to_string());
+    }
+    let url = format!("{}/upload/storage/v1/b/{}/o?uploadType=media&name={}", base, encode(bucket, false), encode(key, false));
+    let mut request = client()?.post(&url).header("Content-Type", content_type);
+    if let Some(token) = token {
+        request = request.header("Authorization", format!("Bearer {}", token));
+    }
+    request.body(body).send().and_then(|r| r.error_for_status()).map_err(|e| e.to_string())?;
+    Ok(format!("gs://{}/{}", bucket, key))
+}
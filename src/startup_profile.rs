@@ -0,0 +1,286 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::progress::Phase;
+use serde::{Deserialize, Serialize};
+use serde_json::Value;
+use std::collections::BTreeMap;
+use std::fs;
+use std::path::Path;
+use std::process::Command;
+use std::thread;
+use std::time::{Duration, Instant};
+
+/// Services slower than this are flagged and get tuning hints.
+const SLOW_THRESHOLD_SECS: f64 = 15.0;
+const POLL_INTERVAL_MS: u64 = 500;
+pub const DEFAULT_TIMEOUT_SECS: u64 = 180;
+
+/// Time a single service took to become ready after `docker compose up -d`.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+pub struct ServiceTiming {
+    pub service: String,
+    pub image: String,
+    /// Seconds until ready; `None` if the service never became ready before the timeout.
+    pub seconds: Option<f64>,
+    /// Last observed state ("healthy", "running", "exited", "unhealthy", ...).
+    pub status: String,
+}
+
+#[derive(Debug, Default, Serialize, Deserialize)]
+struct SavedProfile {
+    services: Vec<ServiceTiming>,
+}
+
+pub(crate) struct ContainerState {
+    pub service: String,
+    pub image: String,
+    pub state: String,
+    pub health: String,
+    /// Exit code of an exited container (one-shot init containers exit 0 once done).
+    pub exit_code: i64,
+    /// Published ports as (host, container).
+    pub ports: Vec<(u16, u16)>,
+}
+
+impl ContainerState {
+    fn is_ready(&self) -> bool {
+        // Containers with a healthcheck must report healthy; the others only need to be running,
+        // or to have finished successfully
+        if self.state == "exited" {
+            self.exit_code == 0
+        } else if self.health.is_empty() {
+            self.state == "running"
+        } else {
+            self.health == "healthy"
+        }
+    }
+
+    fn status(&self) -> String {
+        if self.health.is_empty() { self.state.clone() } else { self.health.clone() }
+    }
+
+    fn has_failed(&self) -> bool {
+        self.state == "exited" || self.state == "dead" || self.health == "unhealthy"
+    }
+}
+
+/// Query `docker compose ps` and parse the per-container state.
+/// Compose v2 prints either a JSON array (older releases) or one JSON object per line.
+pub(crate) fn query_states(compose: &[&str], compose_path: &Path) -> Option<Vec<ContainerState>> {
+    let output = Command::new(compose[0])
+        .args(&compose[1..])
+        .arg("-f")
+        .arg(compose_path)
+        .args(["ps", "--all", "--format", "json"])
+        .output()
+        .ok()?;
+    if !output.status.success() {
+        return None;
+    }
+    let text = String::from_utf8_lossy(&output.stdout);
+    let trimmed = text.trim();
+    let values: Vec<Value> = if trimmed.starts_with('[') {
+        serde_json::from_str(trimmed).ok()?
+    } else {
+        trimmed
+            .lines()
+            .filter_map(|l| serde_json::from_str::<Value>(l.trim()).ok())
+            .collect()
+    };
+    let field = |v: &Value, k: &str| v.get(k).and_then(|s| s.as_str()).unwrap_or("").to_string();
+    Some(
+        values
+            .iter()
+            .map(|v| ContainerState {
+                service: field(v, "Service"),
+                image: field(v, "Image"),
+                state: field(v, "State").to_lowercase(),
+                health: field(v, "Health").to_lowercase(),
+                exit_code: v.get("ExitCode").and_then(|c| c.as_i64()).unwrap_or(0),
+                ports: publishers(v),
+            })
+            .filter(|c| !c.service.is_empty())
+            .collect(),
+    )
+}
+
+/// `Publishers` of a `docker compose ps` entry: the ports published on the host, each once
+/// (Docker lists a port for IPv4 and again for IPv6).
+fn publishers(v: &Value) -> Vec<(u16, u16)> {
+    let mut ports: Vec<(u16, u16)> = v
+        .get("Publishers")
+        .and_then(|p| p.as_array())
+        .into_iter()
+        .flatten()
+        .filter_map(|p| {
+            let port = |k: &str| p.get(k).and_then(|n| n.as_u64()).and_then(|n| u16::try_from(n).ok());
+            Some((port("PublishedPort").filter(|p| *p > 0)?, port("TargetPort")?))
+        })
+        .collect();
+    ports.sort();
+    ports.dedup();
+    ports
+}
+
+/// Poll the compose project until every service is ready (or failed/timed out),
+/// recording how long each one took since `started`.
+pub fn profile_startup(
+    compose: &[&str],
+    compose_path: &Path,
+    started: Instant,
+    timeout: Duration,
+    phase: &Phase,
+) -> Option<Vec<ServiceTiming>> {
+    let mut timings: BTreeMap<String, ServiceTiming> = BTreeMap::new();
+    loop {
+        let states = query_states(compose, compose_path)?;
+        for c in &states {
+            let entry = timings.entry(c.service.clone()).or_insert_with(|| ServiceTiming {
+                service: c.service.clone(),
+                image: c.image.clone(),
+                seconds: None,
+                status: c.status(),
+            });
+            if entry.seconds.is_none() {
+                entry.status = c.status();
+                if c.is_ready() {
+                    entry.seconds = Some(started.elapsed().as_secs_f64());
+                }
+            }
+        }
+        let ready = timings.values().filter(|t| t.seconds.is_some()).count();
+        phase.step(ready, timings.len(), "");
+        let pending = states
+            .iter()
+            .filter(|c| !c.has_failed())
+            .any(|c| timings.get(&c.service).map(|t| t.seconds.is_none()).unwrap_or(true));
+        if !pending || started.elapsed() >= timeout {
+            break;
+        }
+        thread::sleep(Duration::from_millis(POLL_INTERVAL_MS));
+    }
+    Some(timings.into_values().collect())
+}
+
+/// Tuning hints for a slow service, keyed by service name and image.
+fn hints_for(service: &str, image: &str) -> Vec<&'static str> {
+    let s = service.to_lowercase();
+    let img = image.to_lowercase();
+    let mut hints = Vec::new();
+    if img.contains("redpanda") {
+        hints.push("Redpanda: use `--mode dev-container` (single-node, sem fsync) e reduza `--memory` para ambientes locais.");
+    } else if s.contains("kafka") && !s.contains("ui") {
+        hints.push("Kafka: migre para KRaft single-node (KAFKA_PROCESS_ROLES=broker,controller) e remova o ZooKeeper.");
+    }
+    if s.contains("zookeeper") || img.contains("zookeeper") {
+        hints.push("ZooKeeper: com Kafka em modo KRaft este serviço deixa de ser necessário.");
+    }
+    if s.contains("mongo") || img.contains("mongo") {
+        hints.push("MongoDB: inicie o mongod com `--quiet --wiredTigerCacheSizeGB 0.25` para reduzir logs, I/O e memória.");
+    }
+    if s.contains("postgres") || img.contains("postgres") {
+        hints.push("PostgreSQL: para dados descartáveis use tmpfs em /var/lib/postgresql/data e `-c fsync=off` (apenas dev).");
+    }
+    if s.contains("mysql") || img.contains("mariadb") || img.contains("mysql") {
+        hints.push("MariaDB/MySQL: use `--innodb-buffer-pool-size=64M --skip-name-resolve` para acelerar o start.");
+    }
+    if s.contains("jobmanager") || s.contains("taskmanager") || img.contains("flink") {
+        hints.push("Flink: reduza `taskmanager.memory.process.size`/`jobmanager.memory.process.size` em FLINK_PROPERTIES.");
+    }
+    if s.contains("grafana") || img.contains("grafana/grafana") {
+        hints.push("Grafana: defina GF_ANALYTICS_REPORTING_ENABLED=false e evite GF_INSTALL_PLUGINS em ambiente local.");
+    }
+    if s.contains("kafka-ui") || img.contains("kafka-ui") {
+        hints.push("Kafka UI: é uma ferramenta opcional; remova-a do compose se não usar a interface.");
+    }
+    hints
+}
+
+fn previous_profile_path(project_dir: &Path) -> std::path::PathBuf {
+    project_dir.join(".dx").join("startup-profile.json")
+}
+
+fn load_previous(project_dir: &Path) -> BTreeMap<String, f64> {
+    let path = previous_profile_path(project_dir);
+    let saved: SavedProfile = fs::read_to_string(path)
+        .ok()
+        .and_then(|d| serde_json::from_str(&d).ok())
+        .unwrap_or_default();
+    saved
+        .services
+        .into_iter()
+        .filter_map(|t| t.seconds.map(|s| (t.service, s)))
+        .collect()
+}
+
+/// Persist the profile under .dx so the next run can show the difference.
+pub fn save_profile(project_dir: &Path, timings: &[ServiceTiming]) -> std::io::Result<()> {
+    let path = previous_profile_path(project_dir);
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    let saved = SavedProfile { services: timings.to_vec() };
+    crate::lock::write_atomic(&path, serde_json::to_string_pretty(&saved).unwrap_or_default())
+}
+
+/// Print the per-service breakdown (slowest first), the bottleneck and tuning hints.
+pub fn print_report(project_dir: &Path, timings: &[ServiceTiming]) {
+    if timings.is_empty() {
+        println!("Nenhum container encontrado para medir a inicialização.");
+        return;
+    }
+    let previous = load_previous(project_dir);
+    let mut sorted: Vec<&ServiceTiming> = timings.iter().collect();
+    sorted.sort_by(|a, b| {
+        let ka = a.seconds.unwrap_or(f64::MAX);
+        let kb = b.seconds.unwrap_or(f64::MAX);
+        kb.partial_cmp(&ka).unwrap_or(std::cmp::Ordering::Equal)
+    });
+    let max = sorted.iter().filter_map(|t| t.seconds).fold(0.0_f64, f64::max).max(0.001);
+    let width = sorted.iter().map(|t| t.service.len()).max().unwrap_or(0);
+
+    println!("\nPerfil de inicialização (tempo até cada serviço ficar pronto):");
+    for t in &sorted {
+        match t.seconds {
+            Some(secs) => {
+                let bar = "█".repeat(((secs / max) * 20.0).round().max(1.0) as usize);
+                let delta = previous
+                    .get(&t.service)
+                    .map(|p| format!(" (anterior: {:.1}s)", p))
+                    .unwrap_or_default();
+                println!("  {:<width$}  {:>6.1}s  {:<20}  {}{}", t.service, secs, bar, t.status, delta, width = width);
+            }
+            None => println!("  {:<width$}  {:>7}  {:<20}  {}", t.service, "-", "", t.status, width = width),
+        }
+    }
+
+    let not_ready: Vec<&&ServiceTiming> = sorted.iter().filter(|t| t.seconds.is_none()).collect();
+    if let Some(slowest) = sorted.iter().find(|t| t.seconds.is_some()) {
+        println!("\nGargalo: {} ({:.1}s)", slowest.service, slowest.seconds.unwrap_or_default());
+    }
+
+    let mut tips: Vec<String> = Vec::new();
+    for t in &sorted {
+        let slow = t.seconds.map(|s| s >= SLOW_THRESHOLD_SECS).unwrap_or(true);
+        if !slow {
+            continue;
+        }
+        let hints = hints_for(&t.service, &t.image);
+        if hints.is_empty() && t.seconds.is_some() {
+            tips.push(format!("{}: verifique se a imagem já está em cache (`docker compose pull`); o primeiro start inclui o download.", t.service));
+        }
+        for h in hints {
+            tips.push(format!("{}: {}", t.service, h));
+        }
+    }
+    for t in &not_ready {
+        tips.push(format!("{}: não ficou pronto ({}). Veja os logs com `docker compose -f .dx/docker-compose.yml logs {}`.", t.service, t.status, t.service));
+    }
+    if !tips.is_empty() {
+        println!("\nDicas:");
+        for tip in tips {
+            println!("  - {}", tip);
+        }
+    }
+}
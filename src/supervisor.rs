@@ -0,0 +1,204 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use notify::{recommended_watcher, EventKind, RecommendedWatcher, RecursiveMode, Watcher};
+use std::collections::BTreeSet;
+use std::path::Path;
+use std::process::{Child, Command, Stdio};
+use std::sync::mpsc::{channel, Receiver, RecvTimeoutError};
+use std::thread;
+use std::time::{Duration, Instant};
+
+/// Directories never watched, besides hidden ones (.git, .dx, .venv): dependencies and build output.
+const IGNORED_DIRS: &[&str] = &["node_modules", "vendor", "target", "dist", "build", "bin", "obj", "__pycache__", "venv"];
+/// Files whose change restarts the application: Go, JavaScript/TypeScript and Python sources and
+/// their manifests (go.mod, go.sum, package.json).
+const WATCHED_EXTENSIONS: &[&str] = &["go", "mod", "sum", "js", "jsx", "ts", "tsx", "mjs", "cjs", "json", "py"];
+/// How often the supervisor looks at the application while no file changes.
+const POLL: Duration = Duration::from_millis(200);
+/// Time the application gets to exit after SIGTERM before it is killed.
+const GRACE: Duration = Duration::from_secs(5);
+
+/// Validate the `watch_ignore` setting: patterns separated by commas.
+pub fn validate_patterns(value: &str) -> Result<String, String> {
+    Ok(value.split(',').map(str::trim).filter(|p| !p.is_empty()).collect::<Vec<_>>().join(","))
+}
+
+/// `*` matches any run of characters except `/`.
+fn wildcard(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern.first() {
+        None => text.is_empty(),
+        Some(b'*') => wildcard(&pattern[1..], text) || (!text.is_empty() && text[0] != b'/' && wildcard(pattern, &text[1..])),
+        Some(c) => text.first() == Some(c) && wildcard(&pattern[1..], &text[1..]),
+    }
+}
+
+/// Whether a change to `path` (relative to the project, with `/`) should restart the application.
+/// Patterns ending in `/` name directories, patterns with `/` match the whole path and the others
+/// any part of it (`*_test.go`, `tmp`).
+fn relevant(path: &str, patterns: &[String]) -> bool {
+    let parts: Vec<&str> = path.split('/').collect();
+    let (dirs, name) = parts.split_at(parts.len().saturating_sub(1));
+    let extension = name.first().and_then(|n| n.rsplit_once('.')).map(|(_, e)| e).unwrap_or_default();
+    WATCHED_EXTENSIONS.contains(&extension)
+        && !dirs.iter().any(|d| d.starts_with('.') || IGNORED_DIRS.contains(d))
+        && !patterns.iter().any(|p| match p.strip_suffix('/') {
+            Some(dir) => dirs.iter().any(|d| wildcard(dir.as_bytes(), d.as_bytes())),
+            None if p.contains('/') => wildcard(p.as_bytes(), path.as_bytes()),
+            None => parts.iter().any(|part| wildcard(p.as_bytes(), part.as_bytes())),
+        })
+}
+
+/// Changes to the project's source files, as paths relative to the project.
+pub struct Watch {
+    changes: Receiver<String>,
+    _watcher: RecommendedWatcher,
+}
+
+impl Watch {
+    /// Watch `project_dir`, ignoring the `watch_ignore` patterns on top of the usual build directories.
+    pub fn start(project_dir: &Path) -> Result<Watch, String> {
+        let root = project_dir.canonicalize().unwrap_or_else(|_| project_dir.to_path_buf());
+        let patterns: Vec<String> = crate::settings::get("watch_ignore").split(',').filter(|p| !p.is_empty()).map(str::to_string).collect();
+        let (tx, changes) = channel();
+        let base = root.clone();
+        let mut watcher = recommended_watcher(move |res: notify::Result<notify::Event>| {
+            let Ok(event) = res else { return };
+            if !matches!(event.kind, EventKind::Create(_) | EventKind::Modify(_) | EventKind::Remove(_)) {
+                return;
+            }
+            for path in event.paths {
+                let relative = path.strip_prefix(&base).unwrap_or(&path).to_string_lossy().replace('\\', "/");
+                if relevant(&relative, &patterns) {
+                    let _ = tx.send(relative);
+                }
+            }
+        })
+        .map_err(|e| e.to_string())?;
+        watcher.watch(&root, RecursiveMode::Recursive).map_err(|e| e.to_string())?;
+        Ok(Watch { changes, _watcher: watcher })
+    }
+
+    /// Wait for the next batch of changes: the first change, then every other one until the
+    /// `watch_debounce` setting passes without news. `idle` runs every POLL while nothing changes.
+    pub fn next_changes(&self, mut idle: impl FnMut()) -> Vec<String> {
+        let debounce = Duration::from_millis(crate::settings::get_u64("watch_debounce").unwrap_or(300));
+        let mut changed = BTreeSet::new();
+        loop {
+            match self.changes.recv_timeout(POLL) {
+                Ok(path) => {
+                    changed.insert(path);
+                    break;
+                }
+                Err(RecvTimeoutError::Timeout) => idle(),
+                Err(RecvTimeoutError::Disconnected) => return Vec::new(),
+            }
+        }
+        let mut quiet_since = Instant::now();
+        while let Some(left) = debounce.checked_sub(quiet_since.elapsed()) {
+            match self.changes.recv_timeout(left) {
+                Ok(path) => {
+                    changed.insert(path);
+                    quiet_since = Instant::now();
+                }
+                Err(_) => break,
+            }
+        }
+        changed.into_iter().collect()
+    }
+}
+
+/// `main.go` or `main.go (+2)`, for the restart message.
+pub fn describe(changes: &[String]) -> String {
+    match changes {
+        [] => String::new(),
+        [one] => one.clone(),
+        [first, rest @ ..] => format!("{} (+{})", first, rest.len()),
+    }
+}
+
+/// Processes started by `pid`, at any depth (`go run` builds and starts the real binary as a child).
+#[cfg(unix)]
+fn descendants(pid: u32) -> Vec<u32> {
+    let Ok(out) = Command::new("ps").args(["-A", "-o", "pid=,ppid="]).output() else { return Vec::new() };
+    let pairs: Vec<(u32, u32)> = String::from_utf8_lossy(&out.stdout)
+        .lines()
+        .filter_map(|l| {
+            let mut cols = l.split_whitespace().map(|c| c.parse::<u32>().ok());
+            Some((cols.next()??, cols.next()??))
+        })
+        .collect();
+    let mut found = vec![pid];
+    let mut i = 0;
+    while i < found.len() {
+        let parent = found[i];
+        found.extend(pairs.iter().filter(|(_, ppid)| *ppid == parent).map(|(p, _)| *p));
+        i += 1;
+    }
+    found.split_off(1)
+}
+
+/// Stop the application and everything it started: SIGTERM, then SIGKILL after GRACE.
+pub fn stop(child: &mut Child) {
+    if matches!(child.try_wait(), Ok(Some(_))) {
+        return;
+    }
+    #[cfg(unix)]
+    {
+        let pids: Vec<String> = std::iter::once(child.id()).chain(descendants(child.id())).map(|p| p.to_string()).collect();
+        let signal = |name: &str| {
+            let _ = Command::new("kill").arg(format!("-{}", name)).args(&pids).stderr(Stdio::null()).status();
+        };
+        signal("TERM");
+        let started = Instant::now();
+        while started.elapsed() < GRACE && matches!(child.try_wait(), Ok(None)) {
+            thread::sleep(Duration::from_millis(50));
+        }
+        signal("KILL");
+    }
+    #[cfg(windows)]
+    {
+        let _ = Command::new("taskkill").args(["/T", "/F", "/PID", &child.id().to_string()]).stdout(Stdio::null()).status();
+    }
+    let _ = child.kill();
+    let _ = child.wait();
+}
+
+/// Supervise the process `spawn` starts: on every batch of source changes, stop it and start it
+/// again (`spawn` runs any build first). Reports when it exits on its own and waits for the next
+/// change; only Ctrl-C ends the supervision.
+pub fn supervise(project_dir: &Path, mut spawn: impl FnMut() -> Option<Child>) -> i32 {
+    let watch = match Watch::start(project_dir) {
+        Ok(watch) => watch,
+        Err(e) => {
+            eprintln!("Erro: não foi possível observar {}: {}", project_dir.display(), e);
+            return 2;
+        }
+    };
+    eprintln!(
+        "Observando {} (Go, JavaScript/TypeScript e Python); a aplicação reinicia a cada alteração.",
+        project_dir.display()
+    );
+    let mut child = spawn();
+    loop {
+        let mut reported = child.is_none();
+        let changes = watch.next_changes(|| {
+            let Some(app) = child.as_mut() else { return };
+            if let (false, Ok(Some(status))) = (reported, app.try_wait()) {
+                reported = true;
+                eprintln!(
+                    "A aplicação terminou (código {}); aguardando alterações para reiniciar.",
+                    status.code().map_or("sinal".to_string(), |c| c.to_string())
+                );
+            }
+        });
+        if changes.is_empty() {
+            return 1;
+        }
+        eprintln!("↻ {} alterado; reiniciando a aplicação...", describe(&changes));
+        if let Some(app) = child.as_mut() {
+            stop(app);
+        }
+        child = spawn();
+    }
+}
@@ -0,0 +1,227 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::makefile::Makefile;
+use crate::tasks::{self, DxFile};
+use std::collections::{BTreeMap, BTreeSet};
+use std::path::Path;
+use std::process::{Command, Stdio};
+use std::time::Instant;
+
+/// Resolve everything `target` depends on (transitively) and group it into levels:
+/// every task in a level only depends on tasks of earlier levels, so a level can run in parallel.
+/// Dependencies may also name Makefile targets that were not migrated yet.
+pub fn plan(dx_file: &DxFile, makefile: Option<&Makefile>, targets: &[String]) -> Result<Vec<Vec<String>>, String> {
+    let mut deps: BTreeMap<String, Vec<String>> = BTreeMap::new();
+    let mut queue: Vec<(String, Option<String>)> = targets.iter().map(|t| (t.clone(), None)).collect();
+    while let Some((name, parent)) = queue.pop() {
+        if deps.contains_key(&name) {
+            continue;
+        }
+        let task_deps = match dx_file.tasks.get(&name) {
+            Some(t) => t.depends_on.clone(),
+            None if makefile.map(|m| m.target(&name).is_some()).unwrap_or(false) => Vec::new(),
+            None => {
+                return Err(match parent {
+                    Some(p) => format!("'{}' depende de '{}', que não existe", p, name),
+                    None => format!("tarefa não encontrada: {}", name),
+                });
+            }
+        };
+        queue.extend(task_deps.iter().map(|d| (d.clone(), Some(name.clone()))));
+        deps.insert(name, task_deps);
+    }
+
+    // Kahn's algorithm, one level at a time
+    let mut levels = Vec::new();
+    let mut done: BTreeSet<String> = BTreeSet::new();
+    while done.len() < deps.len() {
+        let level: Vec<String> = deps
+            .iter()
+            .filter(|(n, ds)| !done.contains(*n) && ds.iter().all(|d| done.contains(d)))
+            .map(|(n, _)| n.clone())
+            .collect();
+        if level.is_empty() {
+            let pending: Vec<&str> = deps.keys().filter(|n| !done.contains(*n)).map(|n| n.as_str()).collect();
+            return Err(format!("dependência circular entre: {}", pending.join(", ")));
+        }
+        done.extend(level.iter().cloned());
+        levels.push(level);
+    }
+    Ok(levels)
+}
+
+/// `dx run --graph`: print the levels and the dependency tree of `targets`.
+pub fn print_graph(dx_file: &DxFile, makefile: Option<&Makefile>, targets: &[String]) -> Result<(), String> {
+    let levels = plan(dx_file, makefile, targets)?;
+    println!("Ordem de execução (tarefas do mesmo nível rodam em paralelo):");
+    for (i, level) in levels.iter().enumerate() {
+        println!("  {}. {}", i + 1, level.join(", "));
+    }
+    println!();
+    for t in targets {
+        println!("{}", t);
+        print_tree(dx_file, makefile, t, "");
+    }
+    Ok(())
+}
+
+fn print_tree(dx_file: &DxFile, makefile: Option<&Makefile>, name: &str, prefix: &str) {
+    let deps = dx_file.tasks.get(name).map(|t| t.depends_on.clone()).unwrap_or_default();
+    for (i, d) in deps.iter().enumerate() {
+        let last = i + 1 == deps.len();
+        let origin = if !dx_file.tasks.contains_key(d) && makefile.map(|m| m.target(d).is_some()).unwrap_or(false) {
+            " (make)"
+        } else {
+            ""
+        };
+        println!("{}{} {}{}", prefix, if last { "└──" } else { "├──" }, d, origin);
+        print_tree(dx_file, makefile, d, &format!("{}{}", prefix, if last { "    " } else { "│   " }));
+    }
+}
+
+/// Run `target` after its dependencies. Levels with more than one task run in parallel,
+/// with each task's output buffered and printed when it finishes. With `capture`,
+/// everything runs sequentially and stdout is collected instead of printed.
+/// Returns the exit code of the first failing task (0 on success).
+pub fn execute(
+    project_dir: &Path,
+    dx_file: &DxFile,
+    makefile: Option<&Makefile>,
+    target: &str,
+    capture: Option<&mut String>,
+) -> Result<i32, String> {
+    let levels = plan(dx_file, makefile, &[target.to_string()])?;
+    let total: usize = levels.iter().map(|l| l.len()).sum();
+    let mut done = 0;
+    let phase = crate::progress::Phase::start("run", &format!("dx run {}", target));
+    let result = execute_levels(project_dir, dx_file, levels, capture, |name| {
+        done += 1;
+        phase.step(done, total, name);
+    });
+    phase.finish(matches!(result, Ok(0)));
+    result
+}
+
+fn execute_levels(
+    project_dir: &Path,
+    dx_file: &DxFile,
+    levels: Vec<Vec<String>>,
+    mut capture: Option<&mut String>,
+    mut finished: impl FnMut(&str),
+) -> Result<i32, String> {
+    for level in levels {
+        if let Some(buf) = capture.as_deref_mut() {
+            for name in &level {
+                let code = run_node(project_dir, dx_file, name, Some(buf)).map_err(|e| e.to_string())?;
+                finished(name);
+                if code != 0 {
+                    return Ok(code);
+                }
+            }
+            continue;
+        }
+        if level.len() == 1 {
+            let code = run_node(project_dir, dx_file, &level[0], None).map_err(|e| e.to_string())?;
+            finished(&level[0]);
+            if code != 0 {
+                return Ok(code);
+            }
+            continue;
+        }
+
+        eprintln!("▶ em paralelo: {}", level.join(", "));
+        let results: Vec<(String, std::io::Result<(i32, String, String)>, f64)> = std::thread::scope(|s| {
+            let handles: Vec<_> = level
+                .iter()
+                .map(|name| {
+                    s.spawn(move || {
+                        let started = Instant::now();
+                        let r = run_node_buffered(project_dir, dx_file, name);
+                        (name.clone(), r, started.elapsed().as_secs_f64())
+                    })
+                })
+                .collect();
+            handles.into_iter().map(|h| h.join().expect("task thread panicked")).collect()
+        });
+        let mut first_failure = 0;
+        for (name, result, secs) in results {
+            finished(&name);
+            match result {
+                Ok((code, stdout, stderr)) => {
+                    let status = if code == 0 { "ok".to_string() } else { format!("falhou, código {}", code) };
+                    eprintln!("── {} ({}, {:.1}s) ──", name, status, secs);
+                    print!("{}", stdout);
+                    eprint!("{}", stderr);
+                    if code != 0 && first_failure == 0 {
+                        first_failure = code;
+                    }
+                }
+                Err(e) => {
+                    eprintln!("── {} (erro: {}) ──", name, e);
+                    if first_failure == 0 {
+                        first_failure = 1;
+                    }
+                }
+            }
+        }
+        if first_failure != 0 {
+            return Ok(first_failure);
+        }
+    }
+    Ok(0)
+}
+
+fn run_node(project_dir: &Path, dx_file: &DxFile, name: &str, capture: Option<&mut String>) -> std::io::Result<i32> {
+    match dx_file.tasks.get(name) {
+        Some(task) => tasks::run_task(project_dir, task, capture),
+        None => {
+            let mut cmd = Command::new("make");
+            cmd.arg(name).current_dir(project_dir);
+            if capture.is_some() {
+                cmd.arg("-s").arg("--no-print-directory");
+            }
+            let mut cmd = crate::sandbox::wrap(project_dir, cmd).map_err(std::io::Error::other)?;
+            match capture {
+                Some(buf) => {
+                    let out = cmd.stdin(Stdio::null()).output()?;
+                    buf.push_str(&String::from_utf8_lossy(&out.stdout));
+                    Ok(out.status.code().unwrap_or(1))
+                }
+                None => {
+                    eprintln!("▶ make {}", name);
+                    Ok(cmd.status()?.code().unwrap_or(1))
+                }
+            }
+        }
+    }
+}
+
+/// Run one node with stdout/stderr buffered (used for parallel levels).
+fn run_node_buffered(project_dir: &Path, dx_file: &DxFile, name: &str) -> std::io::Result<(i32, String, String)> {
+    let commands: Vec<(Command, String)> = match dx_file.tasks.get(name) {
+        Some(task) => task
+            .commands()
+            .into_iter()
+            .map(|c| Ok((tasks::shell_command(project_dir, &c, &task.env)?, c)))
+            .collect::<std::io::Result<_>>()?,
+        None => {
+            let mut cmd = Command::new("make");
+            cmd.arg(name).current_dir(project_dir);
+            let cmd = crate::sandbox::wrap(project_dir, cmd).map_err(std::io::Error::other)?;
+            vec![(cmd, format!("make {}", name))]
+        }
+    };
+    let mut stdout = String::new();
+    let mut stderr = String::new();
+    for (mut cmd, text) in commands {
+        stderr.push_str(&format!("▶ {}\n", text));
+        let out = cmd.stdin(Stdio::null()).output()?;
+        stdout.push_str(&String::from_utf8_lossy(&out.stdout));
+        stderr.push_str(&String::from_utf8_lossy(&out.stderr));
+        if !out.status.success() {
+            return Ok((out.status.code().unwrap_or(1), stdout, stderr));
+        }
+    }
+    Ok((0, stdout, stderr))
+}
@@ -0,0 +1,322 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::dev_config::Stack;
+use std::fs;
+use std::path::{Path, PathBuf};
+
+/// First line of the generated files; files without it were written by hand.
+const HEADER: &str = "# Gerado por: dx dev-config tasks";
+const GOLANGCI_FILES: &[&str] = &[".golangci.yml", ".golangci.yaml", ".golangci.toml", ".golangci.json"];
+/// Files where Python linters are declared or configured.
+const PYTHON_CONFIG_FILES: &[&str] = &["pyproject.toml", "requirements.txt", "requirements-dev.txt", "setup.cfg", "ruff.toml", ".flake8"];
+
+#[derive(Debug, Clone, Copy, PartialEq, Eq, clap::ValueEnum)]
+pub enum TasksFormat {
+    /// Makefile (GNU make)
+    Makefile,
+    /// Taskfile.yml (go-task)
+    Taskfile,
+    /// justfile (just)
+    Justfile,
+}
+
+impl TasksFormat {
+    fn file_name(self) -> &'static str {
+        match self {
+            TasksFormat::Makefile => "Makefile",
+            TasksFormat::Taskfile => "Taskfile.yml",
+            TasksFormat::Justfile => "justfile",
+        }
+    }
+
+    fn tool(self) -> &'static str {
+        match self {
+            TasksFormat::Makefile => "make",
+            TasksFormat::Taskfile => "task",
+            TasksFormat::Justfile => "just",
+        }
+    }
+}
+
+/// A target of the generated file and the shell commands it runs, in order.
+struct Target {
+    name: &'static str,
+    description: String,
+    commands: Vec<String>,
+}
+
+fn target(name: &'static str, description: &str, commands: Vec<String>) -> Target {
+    Target { name, description: description.to_string(), commands }
+}
+
+/// Placeholder for a target the project has no tool for, telling what to configure.
+fn missing(what: &str) -> Vec<String> {
+    vec![format!("echo \"{}\"", what)]
+}
+
+fn read(project_dir: &Path, name: &str) -> String {
+    fs::read_to_string(project_dir.join(name)).unwrap_or_default()
+}
+
+/// The stack's wrapper script when the project ships one (`./mvnw`, `./gradlew`), else the tool.
+fn wrapper(project_dir: &Path, script: &str, tool: &str) -> String {
+    if project_dir.join(script).exists() { format!("./{}", script) } else { tool.to_string() }
+}
+
+fn go(project_dir: &Path) -> Vec<Target> {
+    let main = crate::dockerfile::go_main_package(project_dir);
+    let lint = match GOLANGCI_FILES.iter().any(|f| project_dir.join(f).exists()) {
+        true => "golangci-lint run".to_string(),
+        false => "go vet ./...".to_string(),
+    };
+    vec![
+        target("build", "Compila os binários em bin/", vec!["go build -o bin/ ./...".to_string()]),
+        target("test", "Roda os testes", vec!["go test ./...".to_string()]),
+        target("lint", "Analisa o código", vec![lint]),
+        target("run", "Roda a aplicação", vec![format!("go run {}", main)]),
+    ]
+}
+
+/// Node: the package manager of the lockfile, the build/lint/start scripts of package.json.
+fn node(project_dir: &Path) -> Vec<Target> {
+    let package: serde_json::Value = serde_json::from_str(&read(project_dir, "package.json")).unwrap_or_default();
+    let script = |name: &str| package["scripts"][name].is_string();
+    let (pm, install) = if project_dir.join("pnpm-lock.yaml").exists() {
+        ("pnpm", "pnpm install --frozen-lockfile")
+    } else if project_dir.join("yarn.lock").exists() {
+        ("yarn", "yarn install --frozen-lockfile")
+    } else if project_dir.join("package-lock.json").exists() {
+        ("npm", "npm ci")
+    } else {
+        ("npm", "npm install")
+    };
+    let mut build = vec![install.to_string()];
+    if script("build") {
+        build.push(format!("{} run build", pm));
+    }
+    let has_eslint = ["dependencies", "devDependencies"].iter().any(|k| package[*k]["eslint"].is_string());
+    let lint = if script("lint") {
+        vec![format!("{} run lint", pm)]
+    } else if has_eslint {
+        vec!["npx eslint .".to_string()]
+    } else {
+        missing("Nenhum linter configurado: adicione um script lint ao package.json.")
+    };
+    let run = if script("dev") {
+        format!("{} run dev", pm)
+    } else if script("start") {
+        format!("{} start", pm)
+    } else {
+        format!("node {}", package["main"].as_str().unwrap_or("index.js"))
+    };
+    vec![
+        target("build", "Instala as dependências e compila", build),
+        target("test", "Roda os testes", vec![format!("{} test", pm)]),
+        target("lint", "Analisa o código", lint),
+        target("run", "Roda a aplicação", vec![run]),
+    ]
+}
+
+/// Python: pip on the requirements (or the project itself), pytest, and the linter the project declares.
+fn python(project_dir: &Path, port: u16) -> Vec<Target> {
+    let mut build = Vec::new();
+    for file in ["requirements.txt", "requirements-dev.txt"].into_iter().filter(|f| project_dir.join(f).exists()) {
+        build.push(format!("python -m pip install -r {}", file));
+    }
+    if build.is_empty() {
+        build.push("python -m pip install -e .".to_string());
+    }
+    let config: String = PYTHON_CONFIG_FILES.iter().map(|f| read(project_dir, f)).collect();
+    let lint = if config.contains("ruff") || project_dir.join("ruff.toml").exists() {
+        vec!["python -m ruff check .".to_string()]
+    } else if config.contains("flake8") {
+        vec!["python -m flake8".to_string()]
+    } else {
+        missing("Nenhum linter configurado: adicione ruff ou flake8 às dependências.")
+    };
+    let entry = ["app.py", "main.py", "server.py", "wsgi.py"].into_iter().find(|f| project_dir.join(f).exists()).unwrap_or("app.py");
+    let run = if project_dir.join("manage.py").exists() {
+        format!("python manage.py runserver 0.0.0.0:{}", port)
+    } else if read(project_dir, entry).contains("FastAPI(") {
+        format!("python -m uvicorn {}:app --reload --port {}", entry.trim_end_matches(".py"), port)
+    } else {
+        format!("python {}", entry)
+    };
+    vec![
+        target("build", "Instala as dependências", build),
+        target("test", "Roda os testes", vec!["python -m pytest".to_string()]),
+        target("lint", "Analisa o código", lint),
+        target("run", "Roda a aplicação", vec![run]),
+    ]
+}
+
+fn rust() -> Vec<Target> {
+    vec![
+        target("build", "Compila o projeto", vec!["cargo build".to_string()]),
+        target("test", "Roda os testes", vec!["cargo test".to_string()]),
+        target("lint", "Verifica a formatação e analisa o código", vec!["cargo fmt --check".to_string(), "cargo clippy --all-targets -- -D warnings".to_string()]),
+        target("run", "Roda a aplicação", vec!["cargo run".to_string()]),
+    ]
+}
+
+/// Maven: the wrapper when present, the checkstyle/spotless plugin of the pom for lint and
+/// Spring Boot's run goal when the project uses it.
+fn maven(project_dir: &Path) -> Vec<Target> {
+    let mvn = wrapper(project_dir, "mvnw", "mvn");
+    let pom = read(project_dir, "pom.xml");
+    let lint = if pom.contains("spotless-maven-plugin") {
+        vec![format!("{} -B spotless:check", mvn)]
+    } else if pom.contains("maven-checkstyle-plugin") {
+        vec![format!("{} -B checkstyle:check", mvn)]
+    } else {
+        missing("Nenhum linter configurado: adicione o spotless-maven-plugin ou o maven-checkstyle-plugin ao pom.xml.")
+    };
+    let run = match pom.contains("spring-boot") {
+        true => format!("{} spring-boot:run", mvn),
+        false => "java -jar target/*.jar".to_string(),
+    };
+    vec![
+        target("build", "Empacota sem rodar os testes", vec![format!("{} -B package -DskipTests", mvn)]),
+        target("test", "Roda os testes", vec![format!("{} -B test", mvn)]),
+        target("lint", "Analisa o código", lint),
+        target("run", "Roda a aplicação", vec![run]),
+    ]
+}
+
+/// .NET: the SDK commands over the solution, running the application project (web or executable).
+fn dotnet(project_dir: &Path) -> Vec<Target> {
+    let run = match crate::dotnet_build::app_project(project_dir) {
+        Some(project) if !project.contains('/') => vec!["dotnet run".to_string()],
+        Some(project) => vec![format!("dotnet run --project {}", project)],
+        None => missing("Nenhum projeto executável (Microsoft.NET.Sdk.Web ou OutputType Exe) encontrado."),
+    };
+    vec![
+        target("build", "Compila o projeto", vec!["dotnet build".to_string()]),
+        target("test", "Roda os testes", vec!["dotnet test".to_string()]),
+        target("lint", "Verifica a formatação", vec!["dotnet format --verify-no-changes".to_string()]),
+        target("run", "Roda a aplicação", run),
+    ]
+}
+
+fn gradle(project_dir: &Path) -> Vec<Target> {
+    let gradle = wrapper(project_dir, "gradlew", "gradle");
+    let build_file = read(project_dir, "build.gradle") + &read(project_dir, "build.gradle.kts");
+    let run = match build_file.contains("org.springframework.boot") {
+        true => "bootRun",
+        false => "run",
+    };
+    vec![
+        target("build", "Compila sem rodar os testes", vec![format!("{} build -x test", gradle)]),
+        target("test", "Roda os testes", vec![format!("{} test", gradle)]),
+        target("lint", "Roda as verificações (check) sem os testes", vec![format!("{} check -x test", gradle)]),
+        target("run", "Roda a aplicação", vec![format!("{} {}", gradle, run)]),
+    ]
+}
+
+/// compose-up: the project's own compose file, else the services dx detects.
+fn compose_up(project_dir: &Path) -> Target {
+    match crate::dev_services::find_project_compose_file(project_dir) {
+        Some(_) => target("compose-up", "Sobe os serviços do compose do projeto", vec!["docker compose up -d".to_string()]),
+        None => target("compose-up", "Sobe os serviços detectados pelo dx", vec!["dx dev-services run".to_string()]),
+    }
+}
+
+/// Targets of the stack, in the order they are written; None for stacks without native commands.
+fn targets(project_dir: &Path, stack: Stack) -> Option<Vec<Target>> {
+    let mut targets = match stack {
+        Stack::Go => go(project_dir),
+        Stack::Node => node(project_dir),
+        Stack::Python => {
+            let port = crate::devcontainer::app_port(&crate::dev_env::scan(project_dir)).unwrap_or(8000);
+            python(project_dir, port)
+        }
+        Stack::Rust => rust(),
+        Stack::JavaMaven => maven(project_dir),
+        Stack::JavaGradle => gradle(project_dir),
+        Stack::DotNet => dotnet(project_dir),
+        Stack::Unknown => return None,
+    };
+    targets.push(compose_up(project_dir));
+    Some(targets)
+}
+
+/// Commands of one of the stack's targets (`test`, `lint`...), as written in the generated files.
+pub(crate) fn commands(project_dir: &Path, stack: Stack, name: &str) -> Vec<String> {
+    let targets = targets(project_dir, stack).unwrap_or_default();
+    targets.into_iter().find(|t| t.name == name).map(|t| t.commands).unwrap_or_default()
+}
+
+/// Makefile with `target: ## description` rules (the form `dx migrate makefile` reads) and a help
+/// target as the default goal. `$` is doubled so make passes it to the shell.
+fn render_makefile(stack: Stack, targets: &[Target]) -> String {
+    let names: Vec<&str> = targets.iter().map(|t| t.name).collect();
+    let mut out = format!("{}\n# Stack: {}\n.PHONY: help {}\n\n", HEADER, stack, names.join(" "));
+    out.push_str("help: ## Lista os alvos\n");
+    out.push_str("\t@grep -E '^[a-zA-Z_-]+:.*## ' $(MAKEFILE_LIST) | awk 'BEGIN {FS = \":.*## \"}; {printf \"  %-12s %s\\n\", $$1, $$2}'\n");
+    for t in targets {
+        out.push_str(&format!("\n{}: ## {}\n", t.name, t.description));
+        for command in &t.commands {
+            out.push_str(&format!("\t{}\n", command.replace('$', "$$")));
+        }
+    }
+    out
+}
+
+fn render_taskfile(stack: Stack, targets: &[Target]) -> String {
+    let mut out = format!("{}\n# Stack: {}\nversion: '3'\n\ntasks:\n  default:\n    desc: Lista as tarefas\n    cmds:\n      - task --list\n", HEADER, stack);
+    for t in targets {
+        out.push_str(&format!("\n  {}:\n    desc: {}\n    cmds:\n", t.name, crate::k8s::quote(&t.description)));
+        for command in &t.commands {
+            out.push_str(&format!("      - {}\n", crate::k8s::quote(command)));
+        }
+    }
+    out
+}
+
+fn render_justfile(stack: Stack, targets: &[Target]) -> String {
+    let mut out = format!("{}\n# Stack: {}\n\n# Lista as receitas\ndefault:\n    @just --list\n", HEADER, stack);
+    for t in targets {
+        out.push_str(&format!("\n# {}\n{}:\n", t.description, t.name));
+        for command in &t.commands {
+            out.push_str(&format!("    {}\n", command));
+        }
+    }
+    out
+}
+
+/// `dx dev-config tasks`: write a Makefile, Taskfile.yml or justfile with build, test, lint, run
+/// and compose-up wired to the native commands of the detected stack. A hand-written file is
+/// settled by `conflicts` (kept, merged or, with `force`, replaced).
+pub fn cmd_tasks(dir: Option<PathBuf>, save_file: bool, force: bool, format: TasksFormat) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let stack = Stack::detect(&project_dir);
+    let Some(targets) = targets(&project_dir, stack) else {
+        eprintln!("Nenhuma stack detectada em {}; não há comandos para as tarefas.", project_dir.display());
+        return 1;
+    };
+    let content = match format {
+        TasksFormat::Makefile => render_makefile(stack, &targets),
+        TasksFormat::Taskfile => render_taskfile(stack, &targets),
+        TasksFormat::Justfile => render_justfile(stack, &targets),
+    };
+    if !save_file {
+        print!("{}", content);
+        return 0;
+    }
+
+    let mut files = vec![(project_dir.join(format.file_name()), content)];
+    if !crate::conflicts::settle(&mut files, crate::conflicts::generated_by(HEADER), force) {
+        return 1;
+    }
+    let Some((path, content)) = files.first() else { return 0 };
+    if let Err(e) = crate::audit::write(path, content) {
+        eprintln!("Erro ao salvar {}: {}", path.display(), e);
+        return 1;
+    }
+    println!("  {}", path.display());
+    let names: Vec<&str> = targets.iter().map(|t| t.name).collect();
+    println!("✓ {} gerado ({}): {}.", format.file_name(), stack, names.join(", "));
+    println!("Rode com: {} build", format.tool());
+    0
+}
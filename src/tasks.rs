@@ -0,0 +1,289 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io;
+use std::path::{Path, PathBuf};
+use std::process::{Command, ExitStatus, Stdio};
+
+pub const DX_FILE: &str = "dx.yaml";
+
+/// Project automation file (dx.yaml) at the project root.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct DxFile {
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub tasks: BTreeMap<String, Task>,
+    /// `dx <alias>` expands to another dx invocation, e.g. `up: dev-services run`
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub aliases: BTreeMap<String, String>,
+    /// `dx <command>` runs a sequence of dx and shell steps
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub commands: BTreeMap<String, CustomCommand>,
+    /// Project layer of the dx settings (`notify_after`, `progress`, `sandbox`)
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub settings: BTreeMap<String, crate::settings::ScalarValue>,
+    /// Licenses accepted or forbidden in dependencies (`dx dev-dependencies licenses`)
+    #[serde(default, skip_serializing_if = "crate::dependency_licenses::LicensePolicy::is_empty")]
+    pub licenses: crate::dependency_licenses::LicensePolicy,
+    /// Project plugins (`dx plugins`): name → manifest
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub plugins: BTreeMap<String, crate::plugins::Manifest>,
+    /// Template the project was created from and the version applied (`dx template`)
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub template: Option<crate::template::TemplateRecord>,
+}
+
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct CustomCommand {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub description: Option<String>,
+    /// Environment injected into every step
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub env: BTreeMap<String, String>,
+    #[serde(default)]
+    pub steps: Vec<Step>,
+}
+
+/// One step of a custom command: either `dx: <args>` or `run: <shell command>`.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct Step {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub name: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub dx: Option<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub run: Option<String>,
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub env: BTreeMap<String, String>,
+    /// Extra attempts before applying `on_failure`
+    #[serde(default, skip_serializing_if = "is_zero")]
+    pub retries: u32,
+    #[serde(default)]
+    pub on_failure: OnFailure,
+}
+
+/// What to do when a step still fails after its retries.
+#[derive(Debug, Clone, Copy, Default, PartialEq, Eq, Serialize, Deserialize)]
+#[serde(rename_all = "lowercase")]
+pub enum OnFailure {
+    /// Stop the command and exit with the step's code
+    #[default]
+    Abort,
+    /// Report the failure and go on with the next step
+    Continue,
+}
+
+fn is_zero(n: &u32) -> bool {
+    *n == 0
+}
+
+/// `run:` accepts a single command or a list executed in order.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(untagged)]
+pub enum Run {
+    One(String),
+    Many(Vec<String>),
+}
+
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct Task {
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub description: Option<String>,
+    /// Tasks that must finish successfully before this one (independent ones run in parallel)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub depends_on: Vec<String>,
+    #[serde(default, skip_serializing_if = "Option::is_none")]
+    pub run: Option<Run>,
+    /// Extra environment variables for every command of the task
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub env: BTreeMap<String, String>,
+}
+
+impl Task {
+    pub fn commands(&self) -> Vec<String> {
+        match &self.run {
+            Some(Run::One(cmd)) => vec![cmd.clone()],
+            Some(Run::Many(cmds)) => cmds.clone(),
+            None => Vec::new(),
+        }
+    }
+}
+
+/// Load dx.yaml; `Ok(None)` when the project has none.
+pub fn load(project_dir: &Path) -> io::Result<Option<DxFile>> {
+    let path = project_dir.join(DX_FILE);
+    if !path.exists() {
+        return Ok(None);
+    }
+    let content = fs::read_to_string(&path)?;
+    serde_yaml::from_str(&content)
+        .map(Some)
+        .map_err(|e| io::Error::new(io::ErrorKind::InvalidData, format!("{}: {}", path.display(), e)))
+}
+
+pub fn save(project_dir: &Path, file: &DxFile) -> io::Result<PathBuf> {
+    let path = project_dir.join(DX_FILE);
+    let yaml = serde_yaml::to_string(file).map_err(|e| io::Error::new(io::ErrorKind::InvalidData, e.to_string()))?;
+    crate::audit::write(&path, yaml)?;
+    Ok(path)
+}
+
+/// Run one shell command in the project directory. The directory of the running dx binary
+/// is put first on PATH so nested `dx run ...` steps use the same version. Fails when the
+/// sandbox is active but unavailable.
+pub fn shell_command(project_dir: &Path, cmd: &str, env: &BTreeMap<String, String>) -> io::Result<Command> {
+    let mut command = if cfg!(windows) {
+        let mut c = Command::new("cmd");
+        c.arg("/C").arg(cmd);
+        c
+    } else {
+        let mut c = Command::new("sh");
+        c.arg("-c").arg(cmd);
+        c
+    };
+    // Nested dx invocations must not notify or record history again; the outer one covers the whole run
+    command
+        .current_dir(project_dir)
+        .envs(env)
+        .env(crate::notifications::NOTIFY_AFTER_ENV, "0")
+        .env(crate::history::HISTORY_ENV, "0")
+        .env(crate::audit::RUN_ENV, crate::audit::run_id());
+    if let Some(bin_dir) = std::env::current_exe().ok().and_then(|p| p.parent().map(|d| d.to_path_buf())) {
+        let mut paths = vec![bin_dir];
+        if let Some(current) = std::env::var_os("PATH") {
+            paths.extend(std::env::split_paths(&current));
+        }
+        if let Ok(joined) = std::env::join_paths(paths) {
+            command.env("PATH", joined);
+        }
+    }
+    crate::sandbox::wrap(project_dir, command).map_err(io::Error::other)
+}
+
+/// Execute the task's commands in order, stopping at the first failure.
+/// Returns the exit code (0 on success).
+pub fn run_task(project_dir: &Path, task: &Task, capture: Option<&mut String>) -> io::Result<i32> {
+    let mut output = capture;
+    for cmd in task.commands() {
+        let mut command = shell_command(project_dir, &cmd, &task.env)?;
+        let status: ExitStatus = match output.as_deref_mut() {
+            Some(buf) => {
+                let out = command.stdin(Stdio::null()).output()?;
+                buf.push_str(&String::from_utf8_lossy(&out.stdout));
+                out.status
+            }
+            None => {
+                // Echo on stderr so stdout carries only the task's own output
+                eprintln!("▶ {}", cmd);
+                command.status()?
+            }
+        };
+        if !status.success() {
+            return Ok(status.code().unwrap_or(1));
+        }
+    }
+    Ok(0)
+}
+
+/// `dx run [<tarefa>]`: list tasks (dx.yaml + Makefile targets) or run one of them after its
+/// dependencies. With `graph`, only print the execution plan; with `watch`, run it again whenever
+/// the sources change. Tasks only run in trusted projects.
+pub fn cmd_run(task: Option<String>, graph: bool, sandbox: bool, watch: bool, dir: Option<PathBuf>) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let dx_file = match load(&project_dir) {
+        Ok(f) => f.unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", DX_FILE, e);
+            crate::exit(2);
+        }
+    };
+    let makefile = crate::makefile::find(&project_dir).and_then(|p| fs::read_to_string(p).ok()).map(|c| crate::makefile::parse(&c));
+
+    if graph {
+        let targets: Vec<String> = match task {
+            Some(t) => vec![t],
+            None => dx_file.tasks.keys().cloned().collect(),
+        };
+        if let Err(e) = crate::task_graph::print_graph(&dx_file, makefile.as_ref(), &targets) {
+            eprintln!("Erro no grafo de tarefas: {}", e);
+            crate::exit(2);
+        }
+        return;
+    }
+
+    let Some(name) = task else {
+        list_tasks(&dx_file, makefile.as_ref());
+        return;
+    };
+
+    if sandbox {
+        crate::sandbox::enable();
+    }
+    crate::trust::ensure_trusted(&project_dir);
+    if watch {
+        crate::exit(watch_task(&project_dir, &name));
+    }
+    match crate::task_graph::execute(&project_dir, &dx_file, makefile.as_ref(), &name, None) {
+        Ok(0) => {}
+        Ok(code) => {
+            eprintln!("Tarefa '{}' falhou (código {}).", name, code);
+            crate::exit(code);
+        }
+        Err(e) => {
+            eprintln!("{}. Use 'dx run' para listar as tarefas disponíveis.", e);
+            crate::exit(2);
+        }
+    }
+}
+
+/// `dx run --watch`: the task runs in a nested dx, so an ongoing run (a server, say) can be stopped
+/// with everything it started and the task run again from its dependencies.
+fn watch_task(project_dir: &Path, name: &str) -> i32 {
+    let exe = std::env::current_exe().unwrap_or_else(|_| "dx".into());
+    crate::supervisor::supervise(project_dir, || {
+        let spawned = Command::new(&exe)
+            .args(["run", name])
+            .current_dir(project_dir)
+            .env(crate::notifications::NOTIFY_AFTER_ENV, "0")
+            .env(crate::history::HISTORY_ENV, "0")
+            .env(crate::audit::RUN_ENV, crate::audit::run_id())
+            .env(crate::sandbox::SANDBOX_ENV, if crate::sandbox::active() { "1" } else { "0" })
+            .spawn();
+        spawned.inspect_err(|e| eprintln!("Erro ao executar 'dx run {}': {}", name, e)).ok()
+    })
+}
+
+fn list_tasks(dx_file: &DxFile, makefile: Option<&crate::makefile::Makefile>) {
+    let no_commands = dx_file.commands.is_empty() && dx_file.aliases.is_empty();
+    if no_commands && dx_file.tasks.is_empty() && makefile.map(|m| m.targets.is_empty()).unwrap_or(true) {
+        println!("Nenhuma tarefa encontrada (dx.yaml ou Makefile).");
+        return;
+    }
+    if !dx_file.tasks.is_empty() {
+        println!("Tarefas ({}):", DX_FILE);
+        for (name, t) in &dx_file.tasks {
+            println!("  {:<20} {}", name, t.description.as_deref().unwrap_or(""));
+        }
+    }
+    if let Some(m) = makefile {
+        let targets: Vec<_> = m.targets.iter().filter(|t| !dx_file.tasks.contains_key(&t.name)).collect();
+        if !targets.is_empty() {
+            println!("Alvos do Makefile (executados com make):");
+            for t in targets {
+                println!("  {:<20} {}", t.name, t.description.as_deref().unwrap_or(""));
+            }
+        }
+    }
+    if !no_commands {
+        println!("Comandos ({}; execute com dx <comando>):", DX_FILE);
+        for (name, c) in &dx_file.commands {
+            println!("  {:<20} {}", name, c.description.as_deref().unwrap_or(""));
+        }
+        for (name, target) in &dx_file.aliases {
+            println!("  {:<20} alias de: dx {}", name, target);
+        }
+    }
+    println!("\nExecute com: dx run <tarefa>");
+}
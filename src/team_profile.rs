@@ -0,0 +1,470 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! Team profiles: org-wide defaults (approved registries, license policy, required badges,
+//! default Dev Services, settings) published once, at an HTTP URL or in a git repository, and
+//! referenced by the `.dx/config.yaml` of each project, whose own keys override the profile's.
+//! Remote profiles are kept in the cache directory, so dx still works offline.
+
+use crate::dependency_licenses::LicensePolicy;
+use crate::settings::ScalarValue;
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::Command;
+use std::time::{Duration, SystemTime, UNIX_EPOCH};
+
+/// Local configuration of the project, next to the other files dx keeps in `.dx/`.
+pub const LOCAL_FILE: &str = ".dx/config.yaml";
+
+/// How long a fetched profile is used before asking its source again (`dx team update` forces it).
+const REFRESH_SECS: u64 = 3600;
+
+const TIMEOUT: Duration = Duration::from_secs(15);
+
+/// File read from a git repository when the reference gives no `path`.
+const DEFAULT_GIT_PATH: &str = "dx-profile.yaml";
+
+/// What a team profile sets; `.dx/config.yaml` takes the same keys as overrides.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct Profile {
+    /// Registries the dependencies may come from: hosts (`registry.npmjs.org`) or URL prefixes
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub registries: Vec<String>,
+    /// Licenses accepted or forbidden in dependencies; dx.yaml and `--allow`/`--deny` add to it
+    #[serde(default, skip_serializing_if = "LicensePolicy::is_empty")]
+    pub licenses: LicensePolicy,
+    /// Markdown badges every README must show; `dx dev-badges` adds them to the detected ones
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub badges: Vec<String>,
+    /// Dev Services always in the generated compose file (`redis`, `kafka`, ...)
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub services: Vec<String>,
+    /// dx settings, below the user's (`dx config get --explain`)
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub settings: BTreeMap<String, ScalarValue>,
+    /// Golden paths projects are benchmarked against (`dx benchmark archetype`), by name
+    #[serde(default, skip_serializing_if = "BTreeMap::is_empty")]
+    pub archetypes: BTreeMap<String, crate::benchmark::Archetype>,
+}
+
+impl Profile {
+    /// `self` with the keys `local` sets replaced: lists and license lists as a whole, settings and
+    /// archetypes one by one.
+    fn merge(mut self, local: &Profile) -> Profile {
+        let replace = |base: &mut Vec<String>, local: &Vec<String>| {
+            if !local.is_empty() {
+                *base = local.clone();
+            }
+        };
+        replace(&mut self.registries, &local.registries);
+        replace(&mut self.licenses.allow, &local.licenses.allow);
+        replace(&mut self.licenses.deny, &local.licenses.deny);
+        replace(&mut self.badges, &local.badges);
+        replace(&mut self.services, &local.services);
+        self.settings.extend(local.settings.clone());
+        self.archetypes.extend(local.archetypes.clone());
+        self
+    }
+}
+
+/// `profile:` of `.dx/config.yaml`: a URL or path, or a git repository with the ref and file to read.
+#[derive(Debug, Clone, Serialize, Deserialize)]
+#[serde(untagged)]
+enum Reference {
+    Url(String),
+    Repo {
+        url: String,
+        #[serde(default, rename = "ref", skip_serializing_if = "Option::is_none")]
+        reference: Option<String>,
+        #[serde(default, skip_serializing_if = "Option::is_none")]
+        path: Option<String>,
+    },
+}
+
+impl Reference {
+    fn url(&self) -> &str {
+        match self {
+            Reference::Url(url) | Reference::Repo { url, .. } => url,
+        }
+    }
+
+    fn is_git(&self) -> bool {
+        let url = self.url();
+        matches!(self, Reference::Repo { .. }) || url.ends_with(".git") || url.starts_with("git@") || url.starts_with("ssh://")
+    }
+
+    fn is_remote(&self) -> bool {
+        self.is_git() || self.url().starts_with("http://") || self.url().starts_with("https://")
+    }
+
+    /// The reference as shown to the user: the URL, with `//path` and `?ref=` for a repository
+    fn label(&self) -> String {
+        match self {
+            Reference::Url(url) => url.clone(),
+            Reference::Repo { url, reference, path } => {
+                let mut label = url.clone();
+                if let Some(path) = path {
+                    label.push_str(&format!("//{}", path));
+                }
+                if let Some(reference) = reference {
+                    label.push_str(&format!("?ref={}", reference));
+                }
+                label
+            }
+        }
+    }
+}
+
+#[derive(Debug, Default, Deserialize)]
+struct LocalConfig {
+    #[serde(default)]
+    profile: Option<Reference>,
+    #[serde(flatten)]
+    overrides: Profile,
+}
+
+/// A fetched profile kept in the cache directory.
+#[derive(Serialize, Deserialize)]
+struct CacheEntry {
+    source: String,
+    /// Unix seconds of the fetch
+    at: u64,
+    content: String,
+}
+
+/// The team profile of a project and its local overrides.
+#[derive(Debug, Default)]
+pub struct TeamProfile {
+    /// `profile:` of `.dx/config.yaml`, when it references one
+    pub source: Option<String>,
+    /// Unix seconds of the fetch of a remote profile
+    pub fetched_at: Option<u64>,
+    pub remote: Profile,
+    pub local: Profile,
+}
+
+impl TeamProfile {
+    /// The profile with the local overrides applied.
+    pub fn effective(&self) -> Profile {
+        self.remote.clone().merge(&self.local)
+    }
+}
+
+fn now() -> u64 {
+    SystemTime::now().duration_since(UNIX_EPOCH).unwrap_or_default().as_secs()
+}
+
+fn cache_path(reference: &Reference) -> PathBuf {
+    let id: String = Sha256::digest(reference.label().as_bytes()).iter().take(8).map(|b| format!("{:02x}", b)).collect();
+    crate::paths::cache_dir().join("team-profiles").join(format!("{}.json", id))
+}
+
+fn fetch_http(url: &str) -> Result<String, String> {
+    let client = reqwest::blocking::Client::builder()
+        .timeout(TIMEOUT)
+        .user_agent(concat!("dx-cli/", env!("CARGO_PKG_VERSION")))
+        .build()
+        .map_err(|e| e.to_string())?;
+    client
+        .get(url)
+        .send()
+        .and_then(|r| r.error_for_status())
+        .and_then(|r| r.text())
+        .map_err(|e| e.to_string())
+}
+
+/// Shallow clone of the repository at `reference` (default branch without it) next to the cached
+/// profiles, reading one file; the clone is removed afterwards.
+fn fetch_git(url: &str, reference: Option<&str>, path: &str, clone_dir: &Path) -> Result<String, String> {
+    let _ = fs::remove_dir_all(clone_dir);
+    let content = clone_and_read(url, reference, path, clone_dir);
+    let _ = fs::remove_dir_all(clone_dir);
+    content
+}
+
+fn clone_and_read(url: &str, reference: Option<&str>, path: &str, dir: &Path) -> Result<String, String> {
+    let mut cmd = Command::new("git");
+    cmd.args(["clone", "--quiet", "--depth", "1"]);
+    if let Some(r) = reference {
+        cmd.args(["--branch", r]);
+    }
+    let out = cmd.arg(url).arg(dir).output().map_err(|e| format!("git indisponível: {}", e))?;
+    if !out.status.success() {
+        return Err(format!("git clone {}: {}", url, String::from_utf8_lossy(&out.stderr).trim()));
+    }
+    fs::read_to_string(dir.join(path)).map_err(|e| format!("{} no repositório: {}", path, e))
+}
+
+fn fetch(reference: &Reference) -> Result<String, String> {
+    let clone_dir = cache_path(reference).with_extension("clone");
+    match reference {
+        Reference::Repo { url, reference, path } => {
+            fetch_git(url, reference.as_deref(), path.as_deref().unwrap_or(DEFAULT_GIT_PATH), &clone_dir)
+        }
+        Reference::Url(url) if reference.is_git() => fetch_git(url, None, DEFAULT_GIT_PATH, &clone_dir),
+        Reference::Url(url) => fetch_http(url),
+    }
+}
+
+/// Content of a remote profile and when it was fetched: the cached copy while it is recent (unless
+/// `refresh`), else a new fetch; a stale copy when the source cannot be reached.
+fn remote_content(reference: &Reference, refresh: bool) -> Result<(String, u64), String> {
+    let path = cache_path(reference);
+    let cached = fs::read_to_string(&path)
+        .ok()
+        .and_then(|c| serde_json::from_str::<CacheEntry>(&c).ok())
+        .filter(|e| e.source == reference.label());
+    if let Some(entry) = &cached {
+        if !refresh && now().saturating_sub(entry.at) < REFRESH_SECS {
+            return Ok((entry.content.clone(), entry.at));
+        }
+    }
+    match fetch(reference) {
+        Ok(content) => {
+            let entry = CacheEntry { source: reference.label(), at: now(), content };
+            // Without a cached copy the next run only fetches again
+            if let Ok(json) = serde_json::to_string(&entry) {
+                let _ = crate::lock::write_atomic(&path, json);
+            }
+            Ok((entry.content, entry.at))
+        }
+        Err(e) => match cached {
+            Some(entry) => {
+                eprintln!(
+                    "Aviso: perfil da equipe {} inacessível ({}); usando a cópia obtida {}.",
+                    reference.label(),
+                    e,
+                    crate::history::ago(entry.at)
+                );
+                Ok((entry.content, entry.at))
+            }
+            None => Err(format!("perfil da equipe {} inacessível: {}", reference.label(), e)),
+        },
+    }
+}
+
+/// Load `.dx/config.yaml` of `project_dir` and the profile it references; `Ok(None)` when the
+/// project has no such file. `refresh` fetches a remote profile even when the cached one is recent.
+pub fn load(project_dir: &Path, refresh: bool) -> Result<Option<TeamProfile>, String> {
+    let path = project_dir.join(LOCAL_FILE);
+    let content = match fs::read_to_string(&path) {
+        Ok(c) => c,
+        Err(e) if e.kind() == std::io::ErrorKind::NotFound => return Ok(None),
+        Err(e) => return Err(format!("{}: {}", path.display(), e)),
+    };
+    let local: LocalConfig = if content.trim().is_empty() {
+        LocalConfig::default()
+    } else {
+        serde_yaml::from_str(&content).map_err(|e| format!("{}: {}", path.display(), e))?
+    };
+    let mut team = TeamProfile { local: local.overrides, ..Default::default() };
+    let Some(reference) = local.profile else { return Ok(Some(team)) };
+
+    let (content, fetched_at) = if reference.is_remote() {
+        let (content, at) = remote_content(&reference, refresh)?;
+        (content, Some(at))
+    } else {
+        let file = project_dir.join(reference.url());
+        (fs::read_to_string(&file).map_err(|e| format!("{}: {}", file.display(), e))?, None)
+    };
+    team.remote = serde_yaml::from_str(&content).map_err(|e| format!("perfil da equipe {}: {}", reference.label(), e))?;
+    team.source = Some(reference.label());
+    team.fetched_at = fetched_at;
+    Ok(Some(team))
+}
+
+/// The effective profile of `project_dir` for the commands that apply it; an unreadable profile
+/// is reported and left out.
+pub fn effective(project_dir: &Path) -> Profile {
+    match load(project_dir, false) {
+        Ok(team) => team.map(|t| t.effective()).unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Aviso: {}; seguindo sem o perfil.", e);
+            Profile::default()
+        }
+    }
+}
+
+/// Settings of the profile referenced from the current directory and of the local overrides
+/// (two layers of `crate::settings`), with where the profile came from.
+pub fn settings_layers() -> (Option<String>, BTreeMap<String, String>, BTreeMap<String, String>) {
+    let dir = std::env::current_dir().unwrap_or_else(|_| PathBuf::from("."));
+    let team = match load(&dir, false) {
+        Ok(team) => team.unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Aviso: {}; seguindo sem o perfil.", e);
+            TeamProfile::default()
+        }
+    };
+    let strings = |p: &Profile| p.settings.iter().map(|(k, v)| (k.clone(), v.to_string())).collect();
+    (team.source.clone(), strings(&team.remote), strings(&team.local))
+}
+
+/// Registries the project resolves dependencies from, with the file that says so: `resolved` of
+/// package-lock.json and yarn.lock, `source` of Cargo.lock, `registry=` of .npmrc and the index
+/// options of requirements files.
+fn used_registries(project_dir: &Path) -> BTreeMap<String, String> {
+    let mut found = BTreeMap::new();
+    let read = |name: &str| fs::read_to_string(project_dir.join(name)).ok();
+
+    if let Some(lock) = read("package-lock.json").and_then(|c| serde_json::from_str::<serde_json::Value>(&c).ok()) {
+        if let Some(packages) = lock.get("packages").and_then(|p| p.as_object()) {
+            for package in packages.values() {
+                if let Some(url) = package.get("resolved").and_then(|r| r.as_str()) {
+                    found.entry(origin(url)).or_insert_with(|| "package-lock.json".to_string());
+                }
+            }
+        }
+    }
+    for line in read("yarn.lock").unwrap_or_default().lines() {
+        if let Some(url) = line.trim().strip_prefix("resolved ") {
+            found.entry(origin(url.trim_matches('"'))).or_insert_with(|| "yarn.lock".to_string());
+        }
+    }
+    for line in read("Cargo.lock").unwrap_or_default().lines() {
+        let Some(source) = line.strip_prefix("source = \"") else { continue };
+        if let Some(url) = source.strip_prefix("registry+").or_else(|| source.strip_prefix("sparse+")) {
+            found.entry(url.trim_end_matches('"').trim_end_matches('/').to_string()).or_insert_with(|| "Cargo.lock".to_string());
+        }
+    }
+    for line in read(".npmrc").unwrap_or_default().lines() {
+        let Some((key, value)) = line.split_once('=') else { continue };
+        if key.trim() == "registry" || key.trim().ends_with(":registry") {
+            found.entry(value.trim().trim_end_matches('/').to_string()).or_insert_with(|| ".npmrc".to_string());
+        }
+    }
+    for name in ["requirements.txt", "requirements-dev.txt"] {
+        for line in read(name).unwrap_or_default().lines() {
+            let mut words = line.split_whitespace();
+            while let Some(word) = words.next() {
+                let url = match word.split_once('=') {
+                    Some(("--index-url" | "--extra-index-url", url)) => Some(url.to_string()),
+                    None if matches!(word, "-i" | "--index-url" | "--extra-index-url") => words.next().map(str::to_string),
+                    _ => None,
+                };
+                if let Some(url) = url {
+                    found.entry(url.trim_end_matches('/').to_string()).or_insert_with(|| name.to_string());
+                }
+            }
+        }
+    }
+    found
+}
+
+/// `scheme://host` of a package URL; packages of one registry share it.
+fn origin(url: &str) -> String {
+    match url.split_once("://") {
+        Some((scheme, rest)) => format!("{}://{}", scheme, rest.split('/').next().unwrap_or(rest)),
+        None => url.to_string(),
+    }
+}
+
+/// An approved entry matches a registry by host, or as a prefix of its URL.
+fn approved(registry: &str, approved: &[String]) -> bool {
+    let host = registry.split_once("://").map_or(registry, |(_, rest)| rest).split('/').next().unwrap_or("");
+    approved.iter().any(|a| {
+        let a = a.trim_end_matches('/');
+        a.eq_ignore_ascii_case(host) || registry.starts_with(a)
+    })
+}
+
+#[derive(Serialize)]
+struct Finding {
+    check: &'static str,
+    message: String,
+}
+
+fn project_dir_or_cwd(dir: Option<PathBuf>) -> PathBuf {
+    dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")))
+}
+
+fn load_or_exit(project_dir: &Path, refresh: bool) -> TeamProfile {
+    match load(project_dir, refresh) {
+        Ok(Some(team)) => team,
+        Ok(None) => {
+            eprintln!(
+                "{} não tem {}. Crie-o com `profile: <url do perfil da equipe>` e, se quiser, as chaves a sobrescrever.",
+                project_dir.display(),
+                LOCAL_FILE
+            );
+            crate::exit(2);
+        }
+        Err(e) => {
+            eprintln!("Erro: {}", e);
+            crate::exit(1);
+        }
+    }
+}
+
+#[derive(Serialize)]
+struct Shown<'a> {
+    source: Option<&'a str>,
+    fetched_at: Option<u64>,
+    profile: Profile,
+}
+
+/// `dx team show`: the effective profile, with where it came from.
+pub fn cmd_show(dir: Option<PathBuf>) {
+    let project_dir = project_dir_or_cwd(dir);
+    let team = load_or_exit(&project_dir, false);
+    let profile = team.effective();
+    if crate::output::json() {
+        crate::output::print(&Shown { source: team.source.as_deref(), fetched_at: team.fetched_at, profile });
+        return;
+    }
+    match (&team.source, team.fetched_at) {
+        (Some(source), Some(at)) => println!("Perfil da equipe: {} (obtido {})", source, crate::history::ago(at)),
+        (Some(source), None) => println!("Perfil da equipe: {}", source),
+        (None, _) => println!("Sem perfil da equipe; apenas {}.", LOCAL_FILE),
+    }
+    println!("Sobrescrito por: {}\n", project_dir.join(LOCAL_FILE).display());
+    print!("{}", serde_yaml::to_string(&profile).unwrap_or_default());
+}
+
+/// `dx team update`: fetch the remote profile again, ignoring the cached copy.
+pub fn cmd_update(dir: Option<PathBuf>) {
+    let project_dir = project_dir_or_cwd(dir);
+    let team = load_or_exit(&project_dir, true);
+    match team.source {
+        Some(source) => println!("Perfil da equipe atualizado: {}", source),
+        None => println!("{} não referencia um perfil (profile:); nada a atualizar.", LOCAL_FILE),
+    }
+}
+
+/// `dx team check`: check the project against the profile: dependencies only from approved
+/// registries, and the required badges in the README. Returns the exit code, 1 on any finding.
+pub fn cmd_check(dir: Option<PathBuf>) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let profile = load_or_exit(&project_dir, false).effective();
+    let mut findings = Vec::new();
+
+    if !profile.registries.is_empty() {
+        for (registry, file) in used_registries(&project_dir) {
+            if !approved(&registry, &profile.registries) {
+                findings.push(Finding { check: "registries", message: format!("{} usa um registry não aprovado: {}", file, registry) });
+            }
+        }
+    }
+    if !profile.badges.is_empty() {
+        let readme = fs::read_to_string(project_dir.join("README.md")).unwrap_or_default();
+        let missing: BTreeSet<&String> = profile.badges.iter().filter(|b| !readme.contains(b.as_str())).collect();
+        for badge in missing {
+            findings.push(Finding { check: "badges", message: format!("README.md sem o badge obrigatório {}", badge) });
+        }
+    }
+
+    if crate::output::json() {
+        crate::output::print(&findings);
+    } else if findings.is_empty() {
+        println!("✓ {} segue o perfil da equipe (registries e badges).", project_dir.display());
+    } else {
+        for f in &findings {
+            println!("✗ {}", f.message);
+        }
+        println!("\n{} problema(s). Badges: dx dev-badges; licenças: dx dev-dependencies licenses.", findings.len());
+    }
+    if findings.is_empty() { 0 } else { 1 }
+}
@@ -9,6 +9,23 @@ use std::path::{Path, PathBuf};
 pub struct TelemetryResult {
     pub compose_path: PathBuf,
     pub config: DockerComposeConfig,
+    /// Application metrics endpoint Prometheus scrapes (`host.docker.internal:8080/metrics`), if any
+    pub app_metrics: Option<String>,
+}
+
+/// Host name of the machine running the application, as seen from the Prometheus container.
+const APP_HOST: &str = "host.docker.internal";
+
+/// Port and path of a GET metrics route of the application (`/metrics`, `/actuator/prometheus`),
+/// which Prometheus scrapes on the host.
+fn app_metrics_endpoint(project_dir: &Path) -> Option<(u16, String)> {
+    let routes = crate::api_client::detect_routes(project_dir);
+    let path = routes
+        .iter()
+        .find(|(m, p, _)| m == "GET" && (p.ends_with("/metrics") || p.ends_with("/prometheus")))
+        .map(|(_, p, _)| p.clone())?;
+    let port = crate::k8s::Workload::detect(project_dir)?.port;
+    Some((port, path))
 }
 
 pub fn apply(project_dir: &Path) -> std::io::Result<TelemetryResult> {
@@ -31,49 +48,54 @@ pub fn apply(project_dir: &Path) -> std::io::Result<TelemetryResult> {
 
     // Write Grafana provisioning: datasources
     let datasources_yaml = grafana_datasources_yaml();
-    fs::write(grafana_prov_ds.join("datasources.yaml"), datasources_yaml)?;
+    crate::audit::write(grafana_prov_ds.join("datasources.yaml"), datasources_yaml)?;
 
     // Write Grafana provisioning: dashboards
     let dashboards_yaml = grafana_dashboards_yaml();
-    fs::write(grafana_prov_dash.join("dashboards.yaml"), dashboards_yaml)?;
+    crate::audit::write(grafana_prov_dash.join("dashboards.yaml"), dashboards_yaml)?;
 
-    // Write Prometheus config
-    let prometheus_yaml = prometheus_config_yaml();
-    fs::write(prometheus_dir.join("prometheus.yml"), prometheus_yaml)?;
+    // Write Prometheus config, scraping the application too when it exposes metrics
+    let app_metrics = app_metrics_endpoint(project_dir);
+    let prometheus_yaml = prometheus_config_yaml(app_metrics.as_ref());
+    crate::audit::write(prometheus_dir.join("prometheus.yml"), prometheus_yaml)?;
 
     // Write OTel Collector config
     let otel_cfg = telemetry_dir.join("otel-collector-config.yaml");
     let otel_yaml = otel_collector_config_yaml();
-    fs::write(&otel_cfg, otel_yaml)?;
+    crate::audit::write(&otel_cfg, otel_yaml)?;
 
     // Write Tempo config (storage backend + receivers)
     let tempo_cfg = tempo_dir.join("tempo.yaml");
     let tempo_yaml = tempo_config_yaml();
-    fs::write(&tempo_cfg, tempo_yaml)?;
+    crate::audit::write(&tempo_cfg, tempo_yaml)?;
 
     // Detect language/framework and add a simple dashboard
     let (lang, framework) = detect_language_and_framework(project_dir);
     let dash = simple_dashboard_json(&lang, framework.as_deref());
-    fs::write(grafana_dash_dir.join(format!("{}-overview.json", lang.to_lowercase())), dash)?;
+    crate::audit::write(grafana_dash_dir.join(format!("{}-overview.json", lang.to_lowercase())), dash)?;
 
     // Build a docker-compose for telemetry and merge into the main dev-services compose
     // Start from detected dev services (if any)
     let mut base = crate::dev_services::detect_dependencies(project_dir);
-    let telemetry_cfg = build_telemetry_compose();
+    let telemetry_cfg = build_telemetry_compose(app_metrics.is_some());
     for (name, svc) in telemetry_cfg.services.into_iter() {
         base.add_service(&name, svc);
     }
 
+    // One compose project per checkout, instead of "dx" (the directory of the file) for all of them
+    base.name = Some(crate::environments::project_name(project_dir));
     let compose_path = dx_dir.join("docker-compose.yml");
     create_docker_compose_file(&base, &compose_path)?;
 
     Ok(TelemetryResult {
         compose_path,
         config: base,
+        app_metrics: app_metrics.map(|(port, path)| format!("{}:{}{}", APP_HOST, port, path)),
     })
 }
 
-fn build_telemetry_compose() -> DockerComposeConfig {
+/// `scrape_app`: Prometheus reaches the application on the host (Linux needs the host-gateway entry).
+fn build_telemetry_compose(scrape_app: bool) -> DockerComposeConfig {
     let mut cfg = DockerComposeConfig::new();
 
     // Loki
@@ -85,6 +107,7 @@ fn build_telemetry_compose() -> DockerComposeConfig {
             ports: vec![3100],
             volumes: vec!["loki-data:/loki".to_string()],
             command: None,
+            ..Default::default()
         },
     );
 
@@ -100,6 +123,7 @@ fn build_telemetry_compose() -> DockerComposeConfig {
                 "tempo-data:/var/tempo".to_string(),
             ],
             command: Some("-config.file=/etc/tempo.yaml".to_string()),
+            ..Default::default()
         },
     );
 
@@ -115,6 +139,8 @@ fn build_telemetry_compose() -> DockerComposeConfig {
                 "prom-data:/prometheus".to_string(),
             ],
             command: None,
+            extra_hosts: if scrape_app { vec![format!("{}:host-gateway", APP_HOST)] } else { Vec::new() },
+            ..Default::default()
         },
     );
 
@@ -137,6 +163,11 @@ fn build_telemetry_compose() -> DockerComposeConfig {
                 "grafana-storage:/var/lib/grafana".to_string(),
             ],
             command: None,
+            depends_on: ["prometheus", "loki", "tempo"]
+                .iter()
+                .map(|d| (d.to_string(), "service_started".to_string()))
+                .collect(),
+            ..Default::default()
         },
     );
 
@@ -152,6 +183,11 @@ fn build_telemetry_compose() -> DockerComposeConfig {
                 rel_bind("telemetry/otel-collector-config.yaml")
             )],
             command: Some("--config=/etc/otel-collector-config.yaml".to_string()),
+            depends_on: ["prometheus", "loki", "tempo"]
+                .iter()
+                .map(|d| (d.to_string(), "service_started".to_string()))
+                .collect(),
+            ..Default::default()
         },
     );
 
@@ -204,15 +240,22 @@ providers:
     s.to_string()
 }
 
-fn prometheus_config_yaml() -> String {
-    let s = r#"global:
+fn prometheus_config_yaml(app: Option<&(u16, String)>) -> String {
+    let mut s = r#"global:
   scrape_interval: 30s
 scrape_configs:
   - job_name: 'otel-collector'
     static_configs:
       - targets: ['otel-collector:8889']
-"#;
-    s.to_string()
+"#
+    .to_string();
+    if let Some((port, path)) = app {
+        s.push_str(&format!(
+            "  - job_name: 'app'\n    scrape_interval: 15s\n    metrics_path: {}\n    static_configs:\n      - targets: ['{}:{}']\n",
+            path, APP_HOST, port
+        ));
+    }
+    s
 }
 
 fn tempo_config_yaml() -> String {
@@ -323,6 +366,7 @@ fn detect_language_and_framework(project_dir: &Path) -> (String, Option<String>)
     if p.join("Gemfile").exists() { return ("Ruby".into(), None); }
     if p.join("go.mod").exists() { return ("Go".into(), None); }
     if p.join("composer.json").exists() { return ("PHP".into(), None); }
+    if crate::dotnet_build::is_project(p) { return ("C#".into(), Some(".NET".to_string())); }
     ("General".into(), None)
 }
 
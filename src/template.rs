@@ -0,0 +1,514 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::{BTreeMap, BTreeSet};
+use std::fs;
+use std::io::{self, BufRead, IsTerminal, Write};
+use std::path::{Path, PathBuf};
+use std::process::Command;
+
+/// Files a template never manages after the project is created (dx.yaml holds the project's
+/// own tasks and the template record).
+const ALWAYS_EXCLUDED: &[&str] = &[crate::tasks::DX_FILE];
+
+/// `template:` of dx.yaml: the template the project came from and the version last applied.
+#[derive(Debug, Clone, Default, Serialize, Deserialize)]
+pub struct TemplateRecord {
+    /// Git URL or absolute path of the template repository
+    pub source: String,
+    /// Branch or tag followed by `dx template update` (default: the template's default branch)
+    #[serde(default, rename = "ref", skip_serializing_if = "Option::is_none")]
+    pub reference: Option<String>,
+    /// `git describe` of the applied commit (its tag, when there is one)
+    pub version: String,
+    pub commit: String,
+    /// Paths, `dir/` prefixes or `*` patterns the project manages on its own
+    #[serde(default, skip_serializing_if = "Vec::is_empty")]
+    pub exclude: Vec<String>,
+}
+
+/// Files of the template at one commit.
+struct Snapshot {
+    commit: String,
+    version: String,
+    files: BTreeMap<String, Vec<u8>>,
+}
+
+fn git(dir: Option<&Path>, args: &[&str]) -> Result<Vec<u8>, String> {
+    let mut cmd = Command::new("git");
+    if let Some(d) = dir {
+        cmd.arg("-C").arg(d);
+    }
+    let out = cmd.args(args).output().map_err(|e| format!("git indisponível: {}", e))?;
+    if !out.status.success() {
+        return Err(format!("git {}: {}", args.join(" "), String::from_utf8_lossy(&out.stderr).trim()));
+    }
+    Ok(out.stdout)
+}
+
+fn git_text(dir: Option<&Path>, args: &[&str]) -> Result<String, String> {
+    git(dir, args).map(|out| String::from_utf8_lossy(&out).trim().to_string())
+}
+
+fn is_remote(source: &str) -> bool {
+    source.contains("://") || source.starts_with("git@")
+}
+
+/// Local mirror of the template repository under the state directory, fetched again on each use.
+fn mirror(source: &str) -> Result<PathBuf, String> {
+    let key: String = Sha256::digest(source.as_bytes()).iter().take(8).map(|b| format!("{:02x}", b)).collect();
+    let dir = crate::paths::state_dir().join("templates").join(key);
+    if dir.join("HEAD").exists() {
+        git(Some(&dir), &["remote", "update", "--prune"])?;
+    } else {
+        fs::create_dir_all(dir.parent().unwrap_or(&dir)).map_err(|e| e.to_string())?;
+        git(None, &["clone", "--mirror", "--quiet", source, &dir.to_string_lossy()])?;
+    }
+    Ok(dir)
+}
+
+/// `*` matches any run of characters except `/`.
+fn wildcard(pattern: &[u8], text: &[u8]) -> bool {
+    match pattern.first() {
+        None => text.is_empty(),
+        Some(b'*') => wildcard(&pattern[1..], text) || (!text.is_empty() && text[0] != b'/' && wildcard(pattern, &text[1..])),
+        Some(c) => text.first() == Some(c) && wildcard(&pattern[1..], &text[1..]),
+    }
+}
+
+fn excluded(path: &str, patterns: &[String]) -> bool {
+    let name = path.rsplit('/').next().unwrap_or(path);
+    ALWAYS_EXCLUDED.contains(&path)
+        || patterns.iter().any(|p| match p.strip_suffix('/') {
+            Some(dir) => path.starts_with(&format!("{}/", dir)),
+            None if p.contains('/') => wildcard(p.as_bytes(), path.as_bytes()),
+            None => wildcard(p.as_bytes(), name.as_bytes()),
+        })
+}
+
+/// Regular files of the template at `rev` (symlinks and submodules are left out).
+fn snapshot(repo: &Path, rev: &str, exclude: &[String]) -> Result<Snapshot, String> {
+    let commit = git_text(Some(repo), &["rev-parse", "--verify", "--quiet", &format!("{}^{{commit}}", rev)])
+        .map_err(|_| format!("versão '{}' não encontrada no template", rev))?;
+    let version = git_text(Some(repo), &["describe", "--tags", "--always", &commit]).unwrap_or_else(|_| commit.clone());
+    let mut files = BTreeMap::new();
+    for entry in git(Some(repo), &["ls-tree", "-r", "-z", &commit])?.split(|b| *b == 0) {
+        let entry = String::from_utf8_lossy(entry);
+        let Some((meta, path)) = entry.split_once('\t') else { continue };
+        let mut meta = meta.split(' ');
+        let (Some(mode), Some("blob")) = (meta.next(), meta.next()) else { continue };
+        if mode == "120000" || excluded(path, exclude) {
+            continue;
+        }
+        let content = git(Some(repo), &["cat-file", "blob", &format!("{}:{}", commit, path)])?;
+        files.insert(path.to_string(), content);
+    }
+    Ok(Snapshot { commit, version, files })
+}
+
+/// How a template change meets the project's copy of the file.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Status {
+    /// The project still has the old template version: take the new one
+    Apply,
+    /// The project already matches the new version
+    Same,
+    /// The project deleted the file the template changed
+    DeletedLocally,
+    /// Both changed the file: needs a merge
+    Conflict,
+}
+
+fn status(base: Option<&[u8]>, latest: Option<&[u8]>, project: Option<&[u8]>) -> Status {
+    if project == latest {
+        Status::Same
+    } else if project == base {
+        Status::Apply
+    } else if project.is_none() {
+        Status::DeletedLocally
+    } else {
+        Status::Conflict
+    }
+}
+
+fn upstream_label(base: Option<&[u8]>, latest: Option<&[u8]>) -> &'static str {
+    match (base, latest) {
+        (None, _) => "novo no template",
+        (_, None) => "removido do template",
+        _ => "alterado no template",
+    }
+}
+
+/// Template changes between the applied and the latest version, as (path, base, latest).
+fn upstream_changes<'a>(base: &'a Snapshot, latest: &'a Snapshot) -> Vec<(&'a str, Option<&'a [u8]>, Option<&'a [u8]>)> {
+    let paths: BTreeSet<&String> = base.files.keys().chain(latest.files.keys()).collect();
+    paths
+        .into_iter()
+        .map(|p| (p.as_str(), base.files.get(p).map(Vec::as_slice), latest.files.get(p).map(Vec::as_slice)))
+        .filter(|(_, b, l)| b != l)
+        .collect()
+}
+
+/// Three-way merge of the project's file with the template change (`git merge-file`). Returns the
+/// merged content and whether conflict markers were left in it.
+fn merge3(project: &[u8], base: &[u8], latest: &[u8], from: &str, to: &str) -> (Vec<u8>, bool) {
+    let dir = std::env::temp_dir().join(format!("dx-template-merge-{}", std::process::id()));
+    let write = |name: &str, content: &[u8]| -> io::Result<PathBuf> {
+        fs::create_dir_all(&dir)?;
+        let path = dir.join(name);
+        fs::write(&path, content)?;
+        Ok(path)
+    };
+    let merged = (|| -> io::Result<(Vec<u8>, bool)> {
+        let (p, b, l) = (write("projeto", project)?, write("base", base)?, write("template", latest)?);
+        let out = Command::new("git")
+            .args(["merge-file", "-p", "-L", "projeto", "-L"])
+            .arg(format!("template {}", from))
+            .arg("-L")
+            .arg(format!("template {}", to))
+            .args([&p, &b, &l])
+            .output()?;
+        match out.status.code() {
+            Some(0) => Ok((out.stdout, false)),
+            Some(n) if n > 0 => Ok((out.stdout, true)),
+            _ => Err(io::Error::other(String::from_utf8_lossy(&out.stderr).into_owned())),
+        }
+    })();
+    let _ = fs::remove_dir_all(&dir);
+    merged.unwrap_or_else(|_| {
+        // Without git, the whole file becomes one conflict
+        let mut out = b"<<<<<<< projeto\n".to_vec();
+        out.extend_from_slice(project);
+        out.extend_from_slice(b"=======\n");
+        out.extend_from_slice(latest);
+        out.extend_from_slice(format!(">>>>>>> template {}\n", to).as_bytes());
+        (out, true)
+    })
+}
+
+fn project_dir_or_cwd(dir: Option<PathBuf>) -> PathBuf {
+    dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")))
+}
+
+/// dx.yaml of the project with its template record; exits when there is none.
+fn load_record(project_dir: &Path) -> (crate::tasks::DxFile, TemplateRecord) {
+    let dx_file = match crate::tasks::load(project_dir) {
+        Ok(f) => f.unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", crate::tasks::DX_FILE, e);
+            crate::exit(2);
+        }
+    };
+    let Some(record) = dx_file.template.clone() else {
+        eprintln!("O projeto em {} não está associado a um template.", project_dir.display());
+        eprintln!("Associe com: dx template init <repositório> {}", project_dir.display());
+        crate::exit(2);
+    };
+    (dx_file, record)
+}
+
+/// Applied and latest snapshots of the project's template.
+fn fetch(record: &TemplateRecord, reference: Option<&str>) -> Result<(Snapshot, Snapshot), String> {
+    let repo = mirror(&record.source)?;
+    let base = snapshot(&repo, &record.commit, &record.exclude)
+        .map_err(|e| format!("{} (a versão aplicada {} sumiu do template?)", e, record.version))?;
+    let latest = snapshot(&repo, reference.or(record.reference.as_deref()).unwrap_or("HEAD"), &record.exclude)?;
+    Ok((base, latest))
+}
+
+fn is_empty_dir(dir: &Path) -> bool {
+    match fs::read_dir(dir) {
+        Ok(entries) => entries.flatten().all(|e| matches!(e.file_name().to_str(), Some(".git" | ".dx"))),
+        Err(_) => true,
+    }
+}
+
+/// `dx template init <fonte>`: create the project from a template (empty directory) or link an
+/// existing project to the template version it was created from.
+pub fn cmd_init(source: String, reference: Option<String>, dir: Option<PathBuf>) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    // Local templates are recorded by absolute path, so later commands work from any directory
+    let source = if is_remote(&source) {
+        source
+    } else {
+        fs::canonicalize(&source).map(|p| p.to_string_lossy().into_owned()).unwrap_or(source)
+    };
+    if let Ok(Some(f)) = crate::tasks::load(&project_dir) {
+        if let Some(t) = f.template {
+            eprintln!("O projeto já usa o template {} ({}). Atualize com: dx template update", t.source, t.version);
+            return 2;
+        }
+    }
+    let (snap, repo) = match mirror(&source).and_then(|repo| Ok((snapshot(&repo, reference.as_deref().unwrap_or("HEAD"), &[])?, repo))) {
+        Ok(s) => s,
+        Err(e) => {
+            eprintln!("Erro ao obter o template {}: {}", source, e);
+            return 2;
+        }
+    };
+
+    let scaffold = is_empty_dir(&project_dir);
+    if scaffold {
+        let template_dx_file = git(Some(&repo), &["cat-file", "blob", &format!("{}:{}", snap.commit, crate::tasks::DX_FILE)]);
+        let files = snap.files.iter().map(|(p, c)| (p.as_str(), c.as_slice()));
+        let files = files.chain(template_dx_file.as_deref().ok().map(|c| (crate::tasks::DX_FILE, c)));
+        for (path, content) in files {
+            let target = project_dir.join(path);
+            let written = fs::create_dir_all(target.parent().unwrap_or(&project_dir)).and_then(|_| crate::audit::write(&target, content));
+            if let Err(e) = written {
+                eprintln!("Erro ao escrever {}: {}", target.display(), e);
+                return 1;
+            }
+        }
+    }
+
+    let mut dx_file = match crate::tasks::load(&project_dir) {
+        Ok(f) => f.unwrap_or_default(),
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", crate::tasks::DX_FILE, e);
+            return 2;
+        }
+    };
+    dx_file.template = Some(TemplateRecord {
+        source: source.clone(),
+        reference,
+        version: snap.version.clone(),
+        commit: snap.commit.clone(),
+        exclude: Vec::new(),
+    });
+    if let Err(e) = crate::tasks::save(&project_dir, &dx_file) {
+        eprintln!("Erro ao salvar {}: {}", crate::tasks::DX_FILE, e);
+        return 1;
+    }
+    if scaffold {
+        println!("Projeto criado em {} a partir de {} ({}): {} arquivo(s).", project_dir.display(), source, snap.version, snap.files.len());
+    } else {
+        println!("Projeto associado ao template {} na versão {}.", source, snap.version);
+        println!("Veja as diferenças com: dx template diff");
+    }
+    0
+}
+
+/// `dx template diff`: show the template changes since the applied version and the files the
+/// project changed on its own. Returns 1 when the project drifted from the latest template.
+pub fn cmd_diff(reference: Option<String>, patch: bool, dir: Option<PathBuf>) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let (_, record) = load_record(&project_dir);
+    let (base, latest) = match fetch(&record, reference.as_deref()) {
+        Ok(s) => s,
+        Err(e) => {
+            eprintln!("Erro ao obter o template {}: {}", record.source, e);
+            return 2;
+        }
+    };
+    let read = |path: &str| fs::read(project_dir.join(path)).ok();
+
+    println!("Template: {} (aplicado: {}, atual: {})", record.source, base.version, latest.version);
+    let changes = upstream_changes(&base, &latest);
+    let mut drift = false;
+    let mut report_changes = Vec::new();
+    if !changes.is_empty() {
+        println!("\nMudanças do template desde {} ({}):", base.version, changes.len());
+        for (path, b, l) in &changes {
+            let project = read(path);
+            let (mark, note) = match status(*b, *l, project.as_deref()) {
+                Status::Apply => ("↓", "aplicada automaticamente"),
+                Status::Same => ("=", "o projeto já está igual"),
+                Status::DeletedLocally => ("-", "removido no projeto; será mantido assim"),
+                Status::Conflict => ("!", "também alterado no projeto; exige mesclagem"),
+            };
+            drift |= mark != "=";
+            report_changes.push(serde_json::json!({ "path": path, "change": upstream_label(*b, *l), "status": note }));
+            println!("  {} {} — {} ({})", mark, path, upstream_label(*b, *l), note);
+            if patch {
+                match (text(*b), text(*l)) {
+                    (Some(old), Some(new)) => print!("{}", crate::audit::unified_diff(path, old, new)),
+                    _ => println!("    (conteúdo binário)"),
+                }
+            }
+        }
+    }
+
+    let local: Vec<(&String, &'static str)> = base
+        .files
+        .iter()
+        .filter(|(p, c)| latest.files.get(*p) == Some(*c))
+        .filter_map(|(p, c)| match read(p) {
+            None => Some((p, "removido no projeto")),
+            Some(current) if current != *c => Some((p, "alterado no projeto")),
+            Some(_) => None,
+        })
+        .collect();
+    if !local.is_empty() {
+        drift = true;
+        println!("\nDivergências do projeto em arquivos do template ({}):", local.len());
+        for (path, note) in &local {
+            println!("  ~ {} — {}", path, note);
+        }
+    }
+    let report = serde_json::json!({
+        "template": record.source,
+        "applied": base.version,
+        "latest": latest.version,
+        "drift": drift,
+        "template_changes": report_changes,
+        "local_changes": local.iter().map(|(path, note)| serde_json::json!({ "path": path, "status": note })).collect::<Vec<_>>(),
+    });
+    crate::sinks::publish(&crate::sinks::Report { kind: "drift", project_dir: &project_dir, content: crate::sinks::Content::Json(report) });
+
+    if !drift {
+        println!("\nO projeto está conforme o template.");
+        return 0;
+    }
+    if changes.iter().any(|(p, b, l)| status(*b, *l, read(p).as_deref()) != Status::Same) {
+        println!("\nAplique as mudanças do template com: dx template update");
+    }
+    1
+}
+
+/// File content as text (missing files are empty); `None` for binary content.
+fn text(content: Option<&[u8]>) -> Option<&str> {
+    content.map_or(Some(""), |c| std::str::from_utf8(c).ok())
+}
+
+/// What to do with a file both the project and the template changed.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+enum Resolution {
+    Merge,
+    Template,
+    Project,
+}
+
+fn ask(path: &str, project: &[u8], latest: Option<&[u8]>) -> Resolution {
+    loop {
+        eprint!("  ! {}: alterado no template e no projeto. [m]esclar, usar o do [t]emplate, manter o do [p]rojeto, ver [d]iff? (m) ", path);
+        let _ = io::stderr().flush();
+        let mut answer = String::new();
+        if io::stdin().lock().read_line(&mut answer).unwrap_or(0) == 0 {
+            return Resolution::Merge;
+        }
+        match answer.trim().to_lowercase().as_str() {
+            "" | "m" => return Resolution::Merge,
+            "t" => return Resolution::Template,
+            "p" => return Resolution::Project,
+            "d" => {
+                let (old, new) = (String::from_utf8_lossy(project), String::from_utf8_lossy(latest.unwrap_or_default()));
+                eprint!("{}", crate::audit::unified_diff(path, &old, &new));
+            }
+            _ => {}
+        }
+    }
+}
+
+/// `dx template update`: bring the template changes into the project. Files the project did not
+/// touch are updated; files both changed are merged (asking first on a terminal, unless `yes`).
+/// Returns 1 when conflict markers were left to resolve.
+pub fn cmd_update(reference: Option<String>, yes: bool, dir: Option<PathBuf>) -> i32 {
+    let project_dir = project_dir_or_cwd(dir);
+    let (mut dx_file, mut record) = load_record(&project_dir);
+    let (base, latest) = match fetch(&record, reference.as_deref()) {
+        Ok(s) => s,
+        Err(e) => {
+            eprintln!("Erro ao obter o template {}: {}", record.source, e);
+            return 2;
+        }
+    };
+    if latest.commit == base.commit {
+        println!("O projeto já está na versão {} do template.", base.version);
+        return 0;
+    }
+    let interactive = !yes && io::stdin().is_terminal() && io::stderr().is_terminal();
+    println!("Atualizando o template de {} para {}:", base.version, latest.version);
+
+    let (mut applied, mut merged, mut conflicts) = (0, 0, Vec::new());
+    for (path, b, l) in upstream_changes(&base, &latest) {
+        let target = project_dir.join(path);
+        let project = fs::read(&target).ok();
+        let put = |content: Option<&[u8]>| -> io::Result<()> {
+            match content {
+                Some(c) => {
+                    fs::create_dir_all(target.parent().unwrap_or(&project_dir))?;
+                    crate::audit::write(&target, c)
+                }
+                None => crate::audit::remove_file(&target),
+            }
+        };
+        let result = match status(b, l, project.as_deref()) {
+            Status::Same => continue,
+            Status::DeletedLocally => {
+                println!("  - {}: removido no projeto; mantido assim", path);
+                continue;
+            }
+            Status::Apply => put(l).map(|_| {
+                applied += 1;
+                println!("  ✓ {} ({})", path, upstream_label(b, l));
+            }),
+            Status::Conflict => {
+                let project = project.unwrap_or_default();
+                let resolution = if interactive { ask(path, &project, l) } else { Resolution::Merge };
+                match (resolution, l) {
+                    (Resolution::Project, _) => {
+                        println!("  = {}: mantido o do projeto", path);
+                        Ok(())
+                    }
+                    (Resolution::Template, _) | (Resolution::Merge, None) => put(l).map(|_| {
+                        applied += 1;
+                        println!("  ✓ {}: {}", path, if l.is_some() { "substituído pelo do template" } else { "removido (como no template)" });
+                    }),
+                    (Resolution::Merge, Some(latest_content))
+                        if text(Some(&project)).is_none() || text(b).is_none() || text(l).is_none() =>
+                    {
+                        // Binary files cannot carry markers: the new version goes next to the project's
+                        let side = project_dir.join(format!("{}.dx-template", path));
+                        crate::audit::write(&side, latest_content).map(|_| {
+                            println!("  ⚠ {}: binário alterado nos dois; a versão do template está em {}.dx-template", path, path);
+                            conflicts.push(path.to_string());
+                        })
+                    }
+                    (Resolution::Merge, Some(latest_content)) => {
+                        let (content, conflicted) =
+                            merge3(&project, b.unwrap_or_default(), latest_content, &base.version, &latest.version);
+                        put(Some(&content)).map(|_| {
+                            if conflicted {
+                                println!("  ⚠ {}: conflitos marcados com <<<<<<< para resolver", path);
+                                conflicts.push(path.to_string());
+                            } else {
+                                merged += 1;
+                                println!("  ✓ {}: mesclado", path);
+                            }
+                        })
+                    }
+                }
+            }
+        };
+        if let Err(e) = result {
+            eprintln!("Erro ao atualizar {}: {}", target.display(), e);
+            return 1;
+        }
+    }
+
+    record.commit = latest.commit.clone();
+    record.version = latest.version.clone();
+    if reference.is_some() {
+        record.reference = reference;
+    }
+    dx_file.template = Some(record);
+    if let Err(e) = crate::tasks::save(&project_dir, &dx_file) {
+        eprintln!("Erro ao salvar {}: {}", crate::tasks::DX_FILE, e);
+        return 1;
+    }
+    println!(
+        "\n{} arquivo(s) atualizado(s), {} mesclado(s), {} com conflito. Template registrado na versão {}.",
+        applied,
+        merged,
+        conflicts.len(),
+        latest.version
+    );
+    if !conflicts.is_empty() {
+        println!("Resolva os conflitos em: {} (ou desfaça tudo com: dx undo)", conflicts.join(", "));
+        return 1;
+    }
+    0
+}
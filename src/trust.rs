@@ -0,0 +1,234 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::{self, BufRead, IsTerminal, Write};
+use std::path::{Path, PathBuf};
+
+const TRUST_FILE: &str = "trust.json";
+
+/// Trust decisions of the user, keyed by canonical project directory.
+#[derive(Debug, Default, Serialize, Deserialize)]
+struct TrustStore {
+    #[serde(default)]
+    projects: BTreeMap<String, TrustEntry>,
+}
+
+#[derive(Debug, Clone, Serialize, Deserialize)]
+struct TrustEntry {
+    /// SHA-256 of dx.yaml, the Makefile and the project plugin programs when the decision was made;
+    /// any change asks again
+    hash: String,
+    #[serde(default)]
+    denied: bool,
+    /// Trusted only inside the sandbox
+    #[serde(default)]
+    sandbox: bool,
+}
+
+/// How project scripts may run after the trust check.
+#[derive(Debug, Clone, Copy, PartialEq, Eq)]
+pub enum Decision {
+    Allowed { sandbox: bool },
+    Denied,
+}
+
+fn store_path() -> PathBuf {
+    crate::paths::state_dir().join(TRUST_FILE)
+}
+
+fn load_store() -> TrustStore {
+    fs::read_to_string(store_path())
+        .ok()
+        .and_then(|c| serde_json::from_str(&c).ok())
+        .unwrap_or_default()
+}
+
+fn save_store(store: &TrustStore) -> io::Result<()> {
+    let path = store_path();
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    let json = serde_json::to_string_pretty(store).map_err(io::Error::other)?;
+    crate::lock::write_atomic(&path, json + "\n")
+}
+
+fn project_key(project_dir: &Path) -> String {
+    fs::canonicalize(project_dir)
+        .unwrap_or_else(|_| project_dir.to_path_buf())
+        .to_string_lossy()
+        .into_owned()
+}
+
+/// Files dx executes besides dx.yaml: the Makefile `dx run` falls back to and the programs of the
+/// project plugins.
+fn executed_files(project_dir: &Path) -> Vec<PathBuf> {
+    crate::makefile::find(project_dir)
+        .into_iter()
+        .chain(crate::plugins::project_programs(project_dir).into_iter().map(|(_, program)| program))
+        .collect()
+}
+
+/// Hash of the project-defined scripts: dx.yaml (a missing file hashes as empty), then the name and
+/// content of each of `executed_files`. A project with only dx.yaml keeps the hash it always had.
+fn project_hash(project_dir: &Path) -> String {
+    let mut hasher = Sha256::new();
+    hasher.update(fs::read(project_dir.join(crate::tasks::DX_FILE)).unwrap_or_default());
+    for path in executed_files(project_dir) {
+        let name = path.strip_prefix(project_dir).unwrap_or(&path);
+        hasher.update(b"\0");
+        hasher.update(name.to_string_lossy().as_bytes());
+        hasher.update(b"\0");
+        hasher.update(fs::read(&path).unwrap_or_default());
+    }
+    hasher.finalize().iter().map(|b| format!("{:02x}", b)).collect()
+}
+
+/// dx.yaml and the `executed_files`, relative to the project, for messages.
+fn script_files(project_dir: &Path) -> String {
+    let mut names = vec![crate::tasks::DX_FILE.to_string()];
+    for path in executed_files(project_dir) {
+        names.push(path.strip_prefix(project_dir).unwrap_or(&path).display().to_string());
+    }
+    names.join(", ")
+}
+
+/// Stored decision for the current content of the project scripts, if any.
+fn recorded(project_dir: &Path) -> Option<Decision> {
+    let store = load_store();
+    let entry = store.projects.get(&project_key(project_dir))?;
+    if entry.hash != project_hash(project_dir) {
+        return None;
+    }
+    Some(if entry.denied { Decision::Denied } else { Decision::Allowed { sandbox: entry.sandbox } })
+}
+
+fn record(project_dir: &Path, decision: Decision) -> io::Result<()> {
+    let _lock = crate::lock::for_file(&store_path())?;
+    let mut store = load_store();
+    let entry = TrustEntry {
+        hash: project_hash(project_dir),
+        denied: decision == Decision::Denied,
+        sandbox: matches!(decision, Decision::Allowed { sandbox: true }),
+    };
+    store.projects.insert(project_key(project_dir), entry);
+    save_store(&store)
+}
+
+/// Whether the scripts of `project_dir` may run without asking: `Some(sandbox)` when the user
+/// allowed the current dx.yaml, `None` otherwise. For callers that must neither prompt nor exit.
+pub fn allowed(project_dir: &Path) -> Option<bool> {
+    match recorded(project_dir)? {
+        Decision::Allowed { sandbox } => Some(sandbox),
+        Decision::Denied => None,
+    }
+}
+
+/// Check that the user trusts the scripts of `project_dir` before running any of them.
+/// Unknown or changed projects are shown and confirmed interactively (like `direnv allow`);
+/// without a terminal the check fails and `dx allow` must be run first.
+/// Exits the process when the project is not trusted; otherwise enables the sandbox
+/// when requested by the user or by the trust decision.
+pub fn ensure_trusted(project_dir: &Path) {
+    let decision = match recorded(project_dir) {
+        Some(d) => d,
+        None if io::stdin().is_terminal() && io::stderr().is_terminal() => prompt(project_dir),
+        None => {
+            eprintln!(
+                "O projeto em {} define scripts ({}) que ainda não foram autorizados (ou mudaram desde a autorização).",
+                project_dir.display(),
+                script_files(project_dir)
+            );
+            eprintln!("Revise os arquivos e autorize com: dx allow [--sandbox] {}", project_dir.display());
+            crate::exit(2);
+        }
+    };
+    match decision {
+        Decision::Allowed { sandbox } => {
+            if sandbox {
+                crate::sandbox::enable();
+            }
+            if let Err(e) = crate::sandbox::check() {
+                eprintln!("Erro: {}; os scripts não serão executados fora dele.", e);
+                crate::exit(2);
+            }
+        }
+        Decision::Denied => {
+            eprintln!(
+                "Scripts de {} bloqueados (dx deny). Use 'dx allow {}' para autorizar.",
+                project_dir.display(),
+                project_dir.display()
+            );
+            crate::exit(2);
+        }
+    }
+}
+
+/// First-run confirmation showing what the project would execute.
+fn prompt(project_dir: &Path) -> Decision {
+    eprintln!("O projeto em {} define scripts que o dx vai executar:", project_dir.display());
+    if let Ok(Some(dx_file)) = crate::tasks::load(project_dir) {
+        for (name, task) in &dx_file.tasks {
+            for cmd in task.commands() {
+                eprintln!("  tarefa {:<16} {}", name, cmd);
+            }
+        }
+        for (name, command) in &dx_file.commands {
+            for step in command.steps.iter().filter_map(|s| s.run.as_ref()) {
+                eprintln!("  comando {:<15} {}", name, step);
+            }
+        }
+    }
+    if let Some(path) = crate::makefile::find(project_dir) {
+        let mk = crate::makefile::parse(&fs::read_to_string(&path).unwrap_or_default());
+        for target in &mk.targets {
+            for line in &target.recipe {
+                eprintln!("  alvo make {:<13} {}", target.name, line);
+            }
+        }
+    }
+    for (name, program) in crate::plugins::project_programs(project_dir) {
+        eprintln!("  plugin {:<16} {}", name, program.strip_prefix(project_dir).unwrap_or(&program).display());
+    }
+    eprint!("Confiar neste projeto? [s]im, [n]ão, [i]solado (sandbox, sem rede): ");
+    let _ = io::stderr().flush();
+    let mut answer = String::new();
+    let _ = io::stdin().lock().read_line(&mut answer);
+    let decision = match answer.trim().to_lowercase().as_str() {
+        "s" | "sim" | "y" | "yes" => Decision::Allowed { sandbox: false },
+        "i" | "isolado" | "sandbox" => Decision::Allowed { sandbox: true },
+        _ => Decision::Denied,
+    };
+    if let Err(e) = record(project_dir, decision) {
+        eprintln!("Aviso: não foi possível salvar a decisão em {}: {}", store_path().display(), e);
+    }
+    decision
+}
+
+/// `dx allow [--sandbox] [<dir>]`: trust the current content of the project's scripts.
+pub fn cmd_allow(dir: Option<PathBuf>, sandbox: bool) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    match record(&project_dir, Decision::Allowed { sandbox }) {
+        Ok(()) if sandbox => println!("Scripts de {} autorizados (somente no sandbox).", project_dir.display()),
+        Ok(()) => println!("Scripts de {} autorizados.", project_dir.display()),
+        Err(e) => {
+            eprintln!("Erro ao salvar {}: {}", store_path().display(), e);
+            crate::exit(1);
+        }
+    }
+}
+
+/// `dx deny [<dir>]`: block the project's scripts without asking again.
+pub fn cmd_deny(dir: Option<PathBuf>) {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    match record(&project_dir, Decision::Denied) {
+        Ok(()) => println!("Scripts de {} bloqueados.", project_dir.display()),
+        Err(e) => {
+            eprintln!("Erro ao salvar {}: {}", store_path().display(), e);
+            crate::exit(1);
+        }
+    }
+}
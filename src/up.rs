@@ -0,0 +1,310 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use crate::makefile::Makefile;
+use crate::tasks::DxFile;
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::{BufRead, BufReader, Read, Write};
+use std::path::{Path, PathBuf};
+use std::process::{Child, Command, Stdio};
+use std::thread::{self, JoinHandle};
+use std::time::{Duration, Instant};
+
+/// Log prefix of the application's own output.
+const APP_PREFIX: &str = "app";
+/// dx.yaml tasks (and Makefile targets) that start the application, in order of preference.
+const START_TASKS: &[&str] = &["dev", "start"];
+const START_TARGETS: &[&str] = &["dev", "start", "run"];
+
+/// How the application is started: the command, how it is shown and where it came from.
+pub(crate) struct Start {
+    pub command: Command,
+    pub label: String,
+    pub origin: String,
+}
+
+/// Package manager of a Node.js project, from its lockfile.
+fn node_runner(project_dir: &Path) -> &'static str {
+    if project_dir.join("pnpm-lock.yaml").exists() {
+        "pnpm"
+    } else if project_dir.join("yarn.lock").exists() {
+        "yarn"
+    } else if project_dir.join("bun.lockb").exists() || project_dir.join("bun.lock").exists() {
+        "bun"
+    } else {
+        "npm"
+    }
+}
+
+/// Start command of the project's stack, when it has an obvious one (`go run .`, `npm run dev`, ...).
+fn stack_command(project_dir: &Path) -> Option<String> {
+    if let Ok(content) = fs::read_to_string(project_dir.join("package.json")) {
+        let scripts = serde_json::from_str::<serde_json::Value>(&content).ok()?.get("scripts").cloned().unwrap_or_default();
+        let runner = node_runner(project_dir);
+        return if scripts.get("dev").is_some() {
+            Some(format!("{} run dev", runner))
+        } else if scripts.get("start").is_some() {
+            Some(format!("{} start", runner))
+        } else {
+            None
+        };
+    }
+    if project_dir.join("go.mod").exists() {
+        if project_dir.join("main.go").exists() {
+            return Some("go run .".to_string());
+        }
+        // Single binary under cmd/<nome>/main.go
+        let mains: Vec<String> = fs::read_dir(project_dir.join("cmd"))
+            .into_iter()
+            .flatten()
+            .flatten()
+            .filter(|e| e.path().join("main.go").exists())
+            .map(|e| e.file_name().to_string_lossy().into_owned())
+            .collect();
+        return match mains.as_slice() {
+            [name] => Some(format!("go run ./cmd/{}", name)),
+            _ => None,
+        };
+    }
+    if project_dir.join("Cargo.toml").exists() {
+        return Some("cargo run".to_string());
+    }
+    if project_dir.join("manage.py").exists() {
+        return Some("python manage.py runserver".to_string());
+    }
+    if let Some(script) = ["main.py", "app.py"].iter().find(|f| project_dir.join(f).exists()) {
+        return Some(format!("python {}", script));
+    }
+    if let Ok(pom) = fs::read_to_string(project_dir.join("pom.xml")) {
+        let mvn = if project_dir.join("mvnw").exists() { "./mvnw" } else { "mvn" };
+        return pom.contains("spring-boot").then(|| format!("{} spring-boot:run", mvn));
+    }
+    let gradle_file = ["build.gradle", "build.gradle.kts"].iter().map(|f| project_dir.join(f)).find(|p| p.exists())?;
+    let gradle = if project_dir.join("gradlew").exists() { "./gradlew" } else { "gradle" };
+    let build = fs::read_to_string(gradle_file).unwrap_or_default();
+    Some(format!("{} {}", gradle, if build.contains("org.springframework.boot") { "bootRun" } else { "run" }))
+}
+
+/// Decide how to start the application: the command after `--`, a `dev`/`start` task of the
+/// dx.yaml, a `dev`/`start`/`run` target of the Makefile or the stack's usual command. Project
+/// scripts only run in trusted projects, after the tasks they depend on (also used by
+/// `dx dev-test smoke`).
+pub(crate) fn start_command(project_dir: &Path, args: Vec<String>) -> Result<Start, String> {
+    if let Some((program, rest)) = args.split_first() {
+        let mut command = Command::new(program);
+        command.args(rest).current_dir(project_dir);
+        return Ok(Start { command, label: args.join(" "), origin: "linha de comando".to_string() });
+    }
+    let dx_file: DxFile = crate::tasks::load(project_dir)
+        .map_err(|e| format!("Erro ao ler {}: {}", crate::tasks::DX_FILE, e))?
+        .unwrap_or_default();
+    if let Some((name, task)) = START_TASKS.iter().find_map(|n| dx_file.tasks.get(*n).map(|t| (*n, t))) {
+        crate::trust::ensure_trusted(project_dir);
+        let makefile = read_makefile(project_dir);
+        for dependency in &task.depends_on {
+            match crate::task_graph::execute(project_dir, &dx_file, makefile.as_ref(), dependency, None) {
+                Ok(0) => {}
+                Ok(code) => return Err(format!("Tarefa '{}' (dependência de '{}') falhou (código {}).", dependency, name, code)),
+                Err(e) => return Err(e),
+            }
+        }
+        let line = task.commands().join(" && ");
+        let command = crate::tasks::shell_command(project_dir, &line, &task.env).map_err(|e| e.to_string())?;
+        return Ok(Start { command, label: line, origin: format!("tarefa {} do {}", name, crate::tasks::DX_FILE) });
+    }
+    let makefile = read_makefile(project_dir);
+    if let Some(target) = makefile.as_ref().and_then(|m| START_TARGETS.iter().find(|t| m.targets.iter().any(|mt| mt.name == **t))) {
+        crate::trust::ensure_trusted(project_dir);
+        let line = format!("make {}", target);
+        let command = crate::tasks::shell_command(project_dir, &line, &BTreeMap::new()).map_err(|e| e.to_string())?;
+        return Ok(Start { command, label: line, origin: format!("alvo {} do Makefile", target) });
+    }
+    let line = stack_command(project_dir).ok_or_else(|| {
+        "Não foi possível descobrir como iniciar a aplicação. Defina uma tarefa 'dev' ou 'start' no dx.yaml \
+         ou passe o comando depois de --, ex.: dx up -- go run ."
+            .to_string()
+    })?;
+    let command = crate::tasks::shell_command(project_dir, &line, &BTreeMap::new()).map_err(|e| e.to_string())?;
+    Ok(Start { command, label: line, origin: "stack detectada".to_string() })
+}
+
+fn read_makefile(project_dir: &Path) -> Option<Makefile> {
+    crate::makefile::find(project_dir).and_then(|p| fs::read_to_string(p).ok()).map(|c| crate::makefile::parse(&c))
+}
+
+/// Copy `stream` to stdout line by line, each line behind the padded `prefix`.
+fn prefix_lines(stream: impl Read + Send + 'static, prefix: String) -> JoinHandle<()> {
+    thread::spawn(move || {
+        let mut reader = BufReader::new(stream);
+        let mut line = Vec::new();
+        while reader.read_until(b'\n', &mut line).map(|n| n > 0).unwrap_or(false) {
+            let text = String::from_utf8_lossy(&line);
+            let _ = writeln!(std::io::stdout().lock(), "{} | {}", prefix, text.trim_end_matches(['\n', '\r']));
+            line.clear();
+        }
+    })
+}
+
+/// Follow the new log lines of one compose service.
+fn follow_logs(compose_path: &Path, service: &str, prefix: String) -> Option<Child> {
+    let mut child = Command::new("docker")
+        .arg("compose")
+        .arg("-f")
+        .arg(compose_path)
+        .args(["logs", "--follow", "--no-color", "--no-log-prefix", "--tail", "0", service])
+        .stdin(Stdio::null())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn()
+        .ok()?;
+    prefix_lines(child.stdout.take()?, prefix.clone());
+    prefix_lines(child.stderr.take()?, prefix);
+    Some(child)
+}
+
+/// Start the application with `env`, its output behind `prefix`. Returns the process and the
+/// threads copying its output.
+fn spawn_app(start: &mut Start, env: &[(String, String)], prefix: &str) -> Option<(Child, Vec<JoinHandle<()>>)> {
+    eprintln!("▶ {} ({})", start.label, start.origin);
+    let spawned = start
+        .command
+        .envs(env.iter().map(|(k, v)| (k, v)))
+        .stdin(Stdio::inherit())
+        .stdout(Stdio::piped())
+        .stderr(Stdio::piped())
+        .spawn();
+    let mut app = match spawned {
+        Ok(app) => app,
+        Err(e) => {
+            eprintln!("Erro ao executar '{}': {}", start.label, e);
+            return None;
+        }
+    };
+    let output = [
+        app.stdout.take().map(|s| prefix_lines(s, prefix.to_string())),
+        app.stderr.take().map(|s| prefix_lines(s, prefix.to_string())),
+    ]
+    .into_iter()
+    .flatten()
+    .collect();
+    Some((app, output))
+}
+
+/// Start the Dev Services and wait until every container is ready. Returns the services that
+/// are up, or the exit code when compose fails or a service does not become ready in time.
+fn start_services(project_dir: &Path, profiles: &[String], timeout: Duration) -> Result<Vec<String>, i32> {
+    let started = Instant::now();
+    if !crate::cmd_dev_services_run(Some(project_dir.to_path_buf()), false, profiles) {
+        eprintln!("Erro: não foi possível subir os Dev Services; a aplicação não foi iniciada.");
+        return Err(1);
+    }
+    // Resolved after `run`, which generates .dx/docker-compose.yml when the project has none
+    let compose_path = crate::dev_services_compose_path(project_dir);
+    let phase = crate::progress::Phase::start("up.ready", "Aguardando os serviços ficarem prontos");
+    let timings = crate::startup_profile::profile_startup(&["docker", "compose"], &compose_path, started, timeout, &phase);
+    phase.finish(timings.as_ref().is_some_and(|t| t.iter().all(|s| s.seconds.is_some())));
+    let Some(timings) = timings else {
+        eprintln!("Erro: não foi possível obter o estado dos serviços (requer Docker Compose V2 com 'ps --format json').");
+        return Err(1);
+    };
+    let not_ready: Vec<_> = timings.iter().filter(|t| t.seconds.is_none()).collect();
+    if !not_ready.is_empty() {
+        for t in &not_ready {
+            eprintln!("  ✗ {} não ficou pronto ({})", t.service, t.status);
+        }
+        let names: Vec<&str> = not_ready.iter().map(|t| t.service.as_str()).collect();
+        eprintln!("Veja os logs com: docker compose -f {} logs {}", compose_path.display(), names.join(" "));
+        return Err(1);
+    }
+    let ready: Vec<String> = timings.iter().map(|t| format!("{} ({:.1}s)", t.service, t.seconds.unwrap_or_default())).collect();
+    println!("✓ {} serviço(s) pronto(s): {}", timings.len(), ready.join(", "));
+    Ok(timings.into_iter().map(|t| t.service).collect())
+}
+
+/// `dx up`: start the infrastructure the project uses (Dev Services), wait until it is healthy,
+/// then run the application with the connection variables of `dx dev-env export`, printing its
+/// output and the containers' logs behind per-service prefixes. With `watch`, the application is
+/// restarted on source changes while the containers stay up. Returns the application's exit code.
+pub fn cmd_up(dir: Option<PathBuf>, profiles: &[String], timeout: u64, no_logs: bool, watch: bool, command: Vec<String>) -> i32 {
+    let project_dir = dir.unwrap_or_else(|| std::env::current_dir().unwrap_or_else(|_| PathBuf::from(".")));
+    let restart_args = command.clone();
+    // Resolve the start command first: nothing is started when there is no way to run the application
+    let mut start = match start_command(&project_dir, command) {
+        Ok(start) => start,
+        Err(e) => {
+            eprintln!("{}", e);
+            return 2;
+        }
+    };
+
+    let has_infra = crate::dev_services_compose_path(&project_dir).exists()
+        || !crate::dev_services::detect_dependencies(&project_dir).services.is_empty();
+    let services = if has_infra {
+        match start_services(&project_dir, profiles, Duration::from_secs(timeout)) {
+            Ok(services) => services,
+            Err(code) => return code,
+        }
+    } else {
+        println!("Nenhum serviço de infraestrutura detectado; iniciando só a aplicação.");
+        Vec::new()
+    };
+    let compose_path = crate::dev_services_compose_path(&project_dir);
+
+    // Variables already set in the shell win over the composed ones
+    let env = crate::env_export::compose(&project_dir);
+    let (kept, exported): (Vec<_>, Vec<_>) = env.iter().partition(|(k, _)| std::env::var_os(k).is_some());
+    if !exported.is_empty() {
+        let names: Vec<&str> = exported.iter().map(|(k, _)| k.as_str()).collect();
+        println!("Variáveis exportadas para a aplicação: {}", names.join(", "));
+    }
+    if !kept.is_empty() {
+        let names: Vec<&str> = kept.iter().map(|(k, _)| k.as_str()).collect();
+        println!("Mantidas do ambiente atual: {}", names.join(", "));
+    }
+
+    let width = services.iter().map(|s| s.len()).chain([APP_PREFIX.len()]).max().unwrap_or_default();
+    let mut followers: Vec<Child> = Vec::new();
+    if !no_logs {
+        followers.extend(services.iter().filter_map(|s| follow_logs(&compose_path, s, format!("{:<width$}", s))));
+    }
+
+    if !services.is_empty() {
+        eprintln!("Ctrl-C encerra a aplicação; os serviços continuam no ar (pare-os com: dx down).");
+    }
+    let app_env: Vec<(String, String)> = exported.iter().map(|(k, v)| (k.to_string(), v.value.clone())).collect();
+    let prefix = format!("{:<width$}", APP_PREFIX);
+    let code = if watch {
+        // The first start is already resolved; restarts resolve it again, rebuilding dx.yaml dependencies
+        let mut first = Some(start);
+        crate::supervisor::supervise(&project_dir, || {
+            let mut start = match first.take().map_or_else(|| start_command(&project_dir, restart_args.clone()), Ok) {
+                Ok(start) => start,
+                Err(e) => {
+                    eprintln!("{}", e);
+                    return None;
+                }
+            };
+            spawn_app(&mut start, &app_env, &prefix).map(|(app, _)| app)
+        })
+    } else {
+        match spawn_app(&mut start, &app_env, &prefix) {
+            Some((mut app, output)) => {
+                let code = app.wait().ok().and_then(|s| s.code()).unwrap_or(1);
+                for handle in output {
+                    let _ = handle.join();
+                }
+                code
+            }
+            None => 2,
+        }
+    };
+    for mut follower in followers {
+        let _ = follower.kill();
+        let _ = follower.wait();
+    }
+    if code != 0 {
+        eprintln!("A aplicação terminou com código {}.", code);
+    }
+    code
+}
@@ -0,0 +1,152 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+use serde::{Deserialize, Serialize};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::{self, Read};
+use std::path::PathBuf;
+
+const SETTINGS_FILE: &str = "config.json";
+/// Version of the `dx config export` document.
+const EXPORT_VERSION: u32 = 1;
+
+/// Portable document written by `dx config export` and read by `dx config import`.
+#[derive(Debug, Serialize, Deserialize)]
+struct ExportDoc {
+    version: u32,
+    #[serde(default)]
+    settings: BTreeMap<String, String>,
+}
+
+pub fn settings_path() -> PathBuf {
+    crate::paths::config_dir().join(SETTINGS_FILE)
+}
+
+/// User layer of the settings (see `crate::settings`).
+pub fn load() -> BTreeMap<String, String> {
+    fs::read_to_string(settings_path())
+        .ok()
+        .and_then(|c| serde_json::from_str(&c).ok())
+        .unwrap_or_default()
+}
+
+fn save(settings: &BTreeMap<String, String>) -> io::Result<()> {
+    let path = settings_path();
+    if let Some(parent) = path.parent() {
+        fs::create_dir_all(parent)?;
+    }
+    let json = serde_json::to_string_pretty(settings).map_err(io::Error::other)?;
+    crate::lock::write_atomic(&path, json + "\n")
+}
+
+fn save_or_exit(settings: &BTreeMap<String, String>) {
+    if let Err(e) = save(settings) {
+        eprintln!("Erro ao salvar {}: {}", settings_path().display(), e);
+        crate::exit(1);
+    }
+}
+
+/// `dx config`: where dx keeps its files and the current user settings.
+pub fn cmd_show() {
+    println!("Configuração: {}", settings_path().display());
+    println!("Estado:       {}", crate::paths::state_dir().display());
+    println!("Cache:        {}", crate::paths::cache_dir().display());
+    let settings = load();
+    println!();
+    for setting in crate::settings::SETTINGS {
+        match settings.get(setting.key) {
+            Some(v) => println!("  {:<14} = {:<8} # {}", setting.key, v, setting.description),
+            None => println!("  {:<14}   {:<8} # {}", setting.key, "-", setting.description),
+        }
+    }
+    println!("\nValores efetivos (padrão < perfil da equipe < usuário < dx.yaml < .dx/config.yaml < ambiente < opção): dx config get --explain");
+}
+
+/// `dx config set <chave> <valor>`
+pub fn cmd_set(key: String, value: String) {
+    let value = match crate::settings::validate(&key, &value) {
+        Ok(v) => v,
+        Err(e) => {
+            eprintln!("Erro: {}", e);
+            crate::exit(2);
+        }
+    };
+    let _lock = crate::lock::for_file_or_exit(&settings_path());
+    let mut settings = load();
+    settings.insert(key.clone(), value.clone());
+    save_or_exit(&settings);
+    println!("{} = {}", key, value);
+}
+
+/// `dx config unset <chave>`
+pub fn cmd_unset(key: String) {
+    let _lock = crate::lock::for_file_or_exit(&settings_path());
+    let mut settings = load();
+    if settings.remove(&key).is_none() {
+        println!("{} não estava definido.", key);
+        return;
+    }
+    save_or_exit(&settings);
+    println!("{} removido.", key);
+}
+
+/// `dx config export [--file F]`: user settings as a portable JSON document.
+/// Machine-specific state (trust decisions) is not exported.
+pub fn cmd_export(file: Option<PathBuf>) {
+    let doc = ExportDoc { version: EXPORT_VERSION, settings: load() };
+    let json = serde_json::to_string_pretty(&doc).unwrap_or_default() + "\n";
+    match file {
+        None => print!("{}", json),
+        Some(path) => match fs::write(&path, json) {
+            Ok(()) => println!("Configurações exportadas para {}", path.display()),
+            Err(e) => {
+                eprintln!("Erro ao salvar {}: {}", path.display(), e);
+                crate::exit(1);
+            }
+        },
+    }
+}
+
+/// `dx config import <arquivo|-> [--replace]`: merge (or replace) settings from an export.
+/// Nothing is written when any known setting has an invalid value.
+pub fn cmd_import(file: PathBuf, replace: bool) {
+    let content = if file.as_os_str() == "-" {
+        let mut buf = String::new();
+        io::stdin().read_to_string(&mut buf).map(|_| buf)
+    } else {
+        fs::read_to_string(&file)
+    };
+    let doc: ExportDoc = match content.map_err(|e| e.to_string()).and_then(|c| serde_json::from_str(&c).map_err(|e| e.to_string())) {
+        Ok(doc) => doc,
+        Err(e) => {
+            eprintln!("Erro ao ler {}: {}", file.display(), e);
+            crate::exit(1);
+        }
+    };
+    if doc.version > EXPORT_VERSION {
+        eprintln!("Erro: exportação na versão {} (esta versão do dx lê até a {}).", doc.version, EXPORT_VERSION);
+        crate::exit(1);
+    }
+
+    let mut imported = BTreeMap::new();
+    for (key, value) in doc.settings {
+        if crate::settings::find(&key).is_none() {
+            eprintln!("Aviso: ignorando configuração desconhecida: {}", key);
+            continue;
+        }
+        match crate::settings::validate(&key, &value) {
+            Ok(v) => imported.insert(key, v),
+            Err(e) => {
+                eprintln!("Erro: {}; nada foi importado.", e);
+                crate::exit(1);
+            }
+        };
+    }
+    let _lock = crate::lock::for_file_or_exit(&settings_path());
+    let mut settings = if replace { BTreeMap::new() } else { load() };
+    let count = imported.len();
+    settings.extend(imported);
+    save_or_exit(&settings);
+    println!("{} configuração(ões) importada(s) para {}", count, settings_path().display());
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+
+//! A small XML reader for build manifests (pom.xml, .csproj, .nuspec): elements with their
+//! attributes, text and children. Namespace prefixes are dropped; no DTDs or entities beyond
+//! the predefined five.
+
+use std::collections::BTreeMap;
+
+#[derive(Debug, Default)]
+pub struct Element {
+    pub name: String,
+    pub attributes: BTreeMap<String, String>,
+    pub text: String,
+    pub children: Vec<Element>,
+}
+
+impl Element {
+    pub fn child(&self, name: &str) -> Option<&Element> {
+        self.children.iter().find(|c| c.name == name)
+    }
+
+    pub fn all<'a>(&'a self, name: &'a str) -> impl Iterator<Item = &'a Element> {
+        self.children.iter().filter(move |c| c.name == name)
+    }
+
+    /// Trimmed text of the child `name`, when present and not empty.
+    pub fn text_of(&self, name: &str) -> Option<String> {
+        self.child(name).map(|c| c.text.trim().to_string()).filter(|t| !t.is_empty())
+    }
+
+    /// Trimmed value of the attribute `name`, when present and not empty.
+    pub fn attribute(&self, name: &str) -> Option<String> {
+        self.attributes.get(name).map(|v| v.trim().to_string()).filter(|v| !v.is_empty())
+    }
+}
+
+fn unescape(text: &str) -> String {
+    text.replace("&lt;", "<").replace("&gt;", ">").replace("&quot;", "\"").replace("&apos;", "'").replace("&amp;", "&")
+}
+
+/// `key="value"` pairs of a start tag (after its name).
+fn attributes(tag: &str) -> BTreeMap<String, String> {
+    let mut found = BTreeMap::new();
+    let mut rest = tag;
+    while let Some(eq) = rest.find('=') {
+        let key = rest[..eq].split_whitespace().last().unwrap_or("");
+        let value = rest[eq + 1..].trim_start();
+        let Some(quote) = value.chars().next().filter(|c| *c == '"' || *c == '\'') else { break };
+        let Some(end) = value[1..].find(quote) else { break };
+        if !key.is_empty() {
+            found.insert(key.rsplit(':').next().unwrap_or(key).to_string(), unescape(&value[1..end + 1]));
+        }
+        rest = &value[end + 2..];
+    }
+    found
+}
+
+/// Parse an XML document into its root element; None when it is not well formed enough to close it.
+pub fn parse(data: &str) -> Option<Element> {
+    let mut stack: Vec<Element> = Vec::new();
+    let mut rest = data;
+    while let Some(start) = rest.find('<') {
+        if let Some(top) = stack.last_mut() {
+            top.text.push_str(&unescape(&rest[..start]));
+        }
+        rest = &rest[start..];
+        let skip = |rest: &str, end: &str| rest.find(end).map(|i| i + end.len());
+        if rest.starts_with("<!--") {
+            rest = &rest[skip(rest, "-->")?..];
+        } else if let Some(cdata) = rest.strip_prefix("<![CDATA[") {
+            let end = cdata.find("]]>")?;
+            if let Some(top) = stack.last_mut() {
+                top.text.push_str(&cdata[..end]);
+            }
+            rest = &cdata[end + 3..];
+        } else if rest.starts_with("<?") {
+            rest = &rest[skip(rest, "?>")?..];
+        } else if rest.starts_with("<!") {
+            rest = &rest[skip(rest, ">")?..];
+        } else if rest.starts_with("</") {
+            rest = &rest[skip(rest, ">")?..];
+            let element = stack.pop()?;
+            match stack.last_mut() {
+                Some(parent) => parent.children.push(element),
+                None => return Some(element),
+            }
+        } else {
+            let end = rest.find('>')?;
+            let tag = &rest[1..end];
+            rest = &rest[end + 1..];
+            let name_end = tag.find(|c: char| c.is_whitespace() || c == '/').unwrap_or(tag.len());
+            let name = &tag[..name_end];
+            let element = Element {
+                name: name.rsplit(':').next().unwrap_or(name).to_string(),
+                attributes: attributes(&tag[name_end..]),
+                ..Default::default()
+            };
+            if tag.ends_with('/') {
+                match stack.last_mut() {
+                    Some(parent) => parent.children.push(element),
+                    None => return Some(element),
+                }
+            } else {
+                stack.push(element);
+            }
+        }
+    }
+    None
+}
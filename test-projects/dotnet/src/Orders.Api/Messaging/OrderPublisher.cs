@@ -0,0 +1,69 @@
+using System.Text.Json;
+using Microsoft.Extensions.Options;
+using RabbitMQ.Client;
+
+namespace Orders.Api.Messaging;
+
+public interface IOrderPublisher
+{
+    void Publish<T>(string routingKey, T message);
+}
+
+/// <summary>
+/// Publishes order events to a RabbitMQ topic exchange. The connection is opened on the
+/// first message, so the API starts even while the broker is still coming up.
+/// </summary>
+public sealed class OrderPublisher : IOrderPublisher, IDisposable
+{
+    private readonly RabbitMqOptions _options;
+    private readonly ILogger<OrderPublisher> _logger;
+    private readonly object _lock = new();
+    private IConnection? _connection;
+    private IModel? _channel;
+
+    public OrderPublisher(IOptions<RabbitMqOptions> options, ILogger<OrderPublisher> logger)
+    {
+        _options = options.Value;
+        _logger = logger;
+    }
+
+    public void Publish<T>(string routingKey, T message)
+    {
+        var body = JsonSerializer.SerializeToUtf8Bytes(message);
+        lock (_lock)
+        {
+            try
+            {
+                var channel = Channel();
+                var properties = channel.CreateBasicProperties();
+                properties.ContentType = "application/json";
+                properties.Persistent = true;
+                channel.BasicPublish(_options.Exchange, routingKey, properties, body);
+            }
+            catch (Exception e)
+            {
+                _logger.LogError(e, "Failed to publish {RoutingKey} to RabbitMQ", routingKey);
+            }
+        }
+    }
+
+    private IModel Channel()
+    {
+        if (_channel is { IsOpen: true })
+        {
+            return _channel;
+        }
+        var factory = new ConnectionFactory { Uri = new Uri(_options.Url), DispatchConsumersAsync = true };
+        _connection = factory.CreateConnection("orders-api");
+        _channel = _connection.CreateModel();
+        _channel.ExchangeDeclare(_options.Exchange, ExchangeType.Topic, durable: true);
+        _logger.LogInformation("Connected to RabbitMQ at {Host}", factory.HostName);
+        return _channel;
+    }
+
+    public void Dispose()
+    {
+        _channel?.Dispose();
+        _connection?.Dispose();
+    }
+}
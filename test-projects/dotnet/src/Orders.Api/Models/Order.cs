@@ -0,0 +1,5 @@
+namespace Orders.Api.Models;
+
+public record Order(Guid Id, string Product, int Quantity, DateTimeOffset CreatedAt);
+
+public record NewOrder(string Product, int Quantity);
@@ -0,0 +1,49 @@
+using Orders.Api.Messaging;
+using Orders.Api.Models;
+using System.Collections.Concurrent;
+
+var builder = WebApplication.CreateBuilder(args);
+
+// RABBITMQ_URL overrides the broker of appsettings.json (RabbitMQ:Url)
+builder.Services.Configure<RabbitMqOptions>(builder.Configuration.GetSection("RabbitMQ"));
+builder.Services.PostConfigure<RabbitMqOptions>(options =>
+{
+    options.Url = Environment.GetEnvironmentVariable("RABBITMQ_URL") ?? options.Url;
+});
+builder.Services.AddSingleton<IOrderPublisher, OrderPublisher>();
+builder.Services.AddSingleton<ConcurrentDictionary<Guid, Order>>();
+builder.Services.AddEndpointsApiExplorer();
+builder.Services.AddSwaggerGen();
+
+var app = builder.Build();
+
+if (app.Environment.IsDevelopment())
+{
+    app.UseSwagger();
+    app.UseSwaggerUI();
+}
+
+app.MapGet("/health", () => Results.Ok("ok"));
+
+app.MapGet("/orders", (ConcurrentDictionary<Guid, Order> orders) =>
+    orders.Values.OrderByDescending(o => o.CreatedAt));
+
+app.MapGet("/orders/{id:guid}", (Guid id, ConcurrentDictionary<Guid, Order> orders) =>
+    orders.TryGetValue(id, out var order) ? Results.Ok(order) : Results.NotFound());
+
+app.MapPost("/orders", (NewOrder request, ConcurrentDictionary<Guid, Order> orders, IOrderPublisher publisher) =>
+{
+    if (request.Quantity <= 0)
+    {
+        return Results.BadRequest("quantity must be positive");
+    }
+    var order = new Order(Guid.NewGuid(), request.Product, request.Quantity, DateTimeOffset.UtcNow);
+    orders[order.Id] = order;
+    publisher.Publish("order.created", order);
+    return Results.Created($"/orders/{order.Id}", order);
+});
+
+app.Run();
+
+// Exposed to WebApplicationFactory in the tests
+public partial class Program { }
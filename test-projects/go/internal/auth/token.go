@@ -0,0 +1,53 @@
+package auth
+
+import (
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrInvalidToken is returned when a token is malformed, expired or not signed with the secret
+var ErrInvalidToken = errors.New("invalid or expired token")
+
+// TokenIssuer signs and verifies the HS256 access tokens of the API
+type TokenIssuer struct {
+	secret []byte
+	ttl    time.Duration
+}
+
+// NewTokenIssuer creates a TokenIssuer with the JWT_SECRET and the token lifetime
+func NewTokenIssuer(secret string, ttl time.Duration) *TokenIssuer {
+	return &TokenIssuer{
+		secret: []byte(secret),
+		ttl:    ttl,
+	}
+}
+
+// Issue returns a signed token for the user and its expiration
+func (t *TokenIssuer) Issue(userID string) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(t.ttl)
+	claims := jwt.RegisteredClaims{
+		Subject:   userID,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(t.secret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return token, expiresAt, nil
+}
+
+// Verify checks the signature and expiration of a token and returns the user ID it was issued for
+func (t *TokenIssuer) Verify(token string) (string, error) {
+	var claims jwt.RegisteredClaims
+	_, err := jwt.ParseWithClaims(token, &claims, func(*jwt.Token) (any, error) {
+		return t.secret, nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil || claims.Subject == "" {
+		return "", ErrInvalidToken
+	}
+	return claims.Subject, nil
+}
@@ -0,0 +1,155 @@
+// Package config loads the settings of the app from the environment into one typed struct: every
+// variable is declared once, in a struct tag, with its default, and checked before anything starts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
+
+// Config holds every setting of the app. A variable that is unset or empty takes its envDefault;
+// one marked required has no default and must be set.
+type Config struct {
+	// Port serves the REST API
+	Port string `env:"APP_PORT" envDefault:"8080"`
+	// GRPCPort serves the gRPC UserService
+	GRPCPort string `env:"GRPC_PORT" envDefault:"9090"`
+	// JWTSecret signs the login tokens; set a real one outside development
+	JWTSecret string `env:"JWT_SECRET" envDefault:"dev-secret-change-me"`
+
+	HTTP    HTTP
+	Mongo   Mongo
+	Kafka   Kafka
+	Tracing Tracing
+}
+
+// HTTP holds the settings of the HTTP middleware
+type HTTP struct {
+	// RateLimitRPS is the sustained rate of the /api routes per client IP; 0 disables the limit
+	RateLimitRPS float64 `env:"RATE_LIMIT_RPS" envDefault:"10"`
+	// RateLimitBurst is how many requests an IP may make at once
+	RateLimitBurst int `env:"RATE_LIMIT_BURST" envDefault:"20"`
+	// TrustedProxies may set X-Forwarded-For, which then gives the client IP: comma-separated IPs or
+	// CIDRs, none by default
+	TrustedProxies []string `env:"TRUSTED_PROXIES"`
+}
+
+// Mongo holds the connection to MongoDB
+type Mongo struct {
+	URI       string `env:"MONGODB_URI" envDefault:"mongodb://localhost:27017"`
+	Database  string `env:"MONGODB_DATABASE" envDefault:"go_sample_app"`
+	TimeoutMs int    `env:"MONGODB_TIMEOUT_MS" envDefault:"5000"`
+}
+
+// Timeout bounds the initial connection
+func (m Mongo) Timeout() time.Duration {
+	return time.Duration(m.TimeoutMs) * time.Millisecond
+}
+
+// Kafka holds the connection to the brokers and the topics of the user events
+type Kafka struct {
+	// Brokers are comma-separated host:port addresses
+	Brokers       []string `env:"KAFKA_BROKERS" envDefault:"localhost:9092"`
+	Topic         string   `env:"KAFKA_TOPIC_USERS" envDefault:"users"`
+	DLQTopic      string   `env:"KAFKA_DLQ_TOPIC" envDefault:"users.dlq"`
+	ClientID      string   `env:"KAFKA_CLIENT_ID" envDefault:"go-sample-app-client"`
+	ConsumerGroup string   `env:"KAFKA_CONSUMER_GROUP" envDefault:"go-sample-app-users"`
+	// MaxRetries is how many times the event producer retries a publish before the dead-letter topic
+	MaxRetries     int `env:"KAFKA_MAX_RETRIES" envDefault:"3"`
+	RetryBackoffMs int `env:"KAFKA_RETRY_BACKOFF_MS" envDefault:"200"`
+	// Required makes the app refuse to start, and /readyz fail, while Kafka is unreachable. By
+	// default Kafka is optional: the app runs degraded, with the user events queued in the outbox
+	Required bool `env:"KAFKA_REQUIRED" envDefault:"false"`
+}
+
+// RetryBackoff is the wait before the first retry, doubled on each of the next ones
+func (k Kafka) RetryBackoff() time.Duration {
+	return time.Duration(k.RetryBackoffMs) * time.Millisecond
+}
+
+// Tracing holds the OpenTelemetry exporter
+type Tracing struct {
+	// Endpoint is the OTLP/HTTP collector
+	Endpoint    string `env:"OTEL_EXPORTER_OTLP_ENDPOINT" envDefault:"http://localhost:4318"`
+	ServiceName string `env:"OTEL_SERVICE_NAME" envDefault:"go-sample-app"`
+}
+
+// Load reads the configuration from the environment (after godotenv has loaded .env) and
+// validates it; the error lists every invalid variable at once
+func Load() (Config, error) {
+	return load(nil)
+}
+
+// load reads the configuration from environ, or from the process environment when nil
+func load(environ map[string]string) (Config, error) {
+	cfg, err := env.ParseAsWithOptions[Config](env.Options{Environment: environ})
+	if err != nil {
+		return Config{}, err
+	}
+	cfg.HTTP.TrustedProxies = trimList(cfg.HTTP.TrustedProxies)
+	cfg.Kafka.Brokers = trimList(cfg.Kafka.Brokers)
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// validate checks the values env cannot: ranges, addresses and lists that must not be empty
+func (c Config) validate() error {
+	var errs []error
+	invalid := func(name string, value any, reason string) {
+		errs = append(errs, fmt.Errorf("invalid %s %q: %s", name, fmt.Sprint(value), reason))
+	}
+
+	for _, port := range []struct{ name, value string }{{"APP_PORT", c.Port}, {"GRPC_PORT", c.GRPCPort}} {
+		if n, err := strconv.Atoi(port.value); err != nil || n < 1 || n > 65535 {
+			invalid(port.name, port.value, "must be a port between 1 and 65535")
+		}
+	}
+	if c.Port == c.GRPCPort {
+		invalid("GRPC_PORT", c.GRPCPort, "must differ from APP_PORT")
+	}
+	if c.HTTP.RateLimitRPS < 0 {
+		invalid("RATE_LIMIT_RPS", c.HTTP.RateLimitRPS, "must not be negative")
+	}
+	if c.HTTP.RateLimitBurst < 1 {
+		invalid("RATE_LIMIT_BURST", c.HTTP.RateLimitBurst, "must be at least 1")
+	}
+	for _, proxy := range c.HTTP.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				invalid("TRUSTED_PROXIES", proxy, "must be an IP or a CIDR")
+			}
+		}
+	}
+	if c.Mongo.TimeoutMs < 1 {
+		invalid("MONGODB_TIMEOUT_MS", c.Mongo.TimeoutMs, "must be at least 1")
+	}
+	if len(c.Kafka.Brokers) == 0 {
+		invalid("KAFKA_BROKERS", "", "must list at least one broker")
+	}
+	if c.Kafka.MaxRetries < 0 {
+		invalid("KAFKA_MAX_RETRIES", c.Kafka.MaxRetries, "must not be negative")
+	}
+	if c.Kafka.RetryBackoffMs < 0 {
+		invalid("KAFKA_RETRY_BACKOFF_MS", c.Kafka.RetryBackoffMs, "must not be negative")
+	}
+	return errors.Join(errs...)
+}
+
+// trimList drops the blanks around and between the items of a comma-separated variable
+func trimList(items []string) []string {
+	var out []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
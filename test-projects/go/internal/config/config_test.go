@@ -0,0 +1,69 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "8080" || cfg.GRPCPort != "9090" || cfg.Mongo.Database != "go_sample_app" {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if !reflect.DeepEqual(cfg.Kafka.Brokers, []string{"localhost:9092"}) || cfg.HTTP.TrustedProxies != nil {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if cfg.Mongo.Timeout() != 5*time.Second || cfg.Kafka.RetryBackoff() != 200*time.Millisecond {
+		t.Fatalf("config = %+v", cfg)
+	}
+}
+
+func TestLoadReadsTheEnvironment(t *testing.T) {
+	cfg, err := load(map[string]string{
+		"APP_PORT":        "3000",
+		"RATE_LIMIT_RPS":  "2.5",
+		"TRUSTED_PROXIES": "10.0.0.1, 192.168.0.0/16,",
+		"KAFKA_BROKERS":   "kafka-1:9092,kafka-2:9092",
+		// Empty counts as unset, as with the os.Getenv checks this loader replaces
+		"KAFKA_TOPIC_USERS": "",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Port != "3000" || cfg.HTTP.RateLimitRPS != 2.5 || cfg.Kafka.Topic != "users" {
+		t.Fatalf("config = %+v", cfg)
+	}
+	if want := []string{"10.0.0.1", "192.168.0.0/16"}; !reflect.DeepEqual(cfg.HTTP.TrustedProxies, want) {
+		t.Fatalf("trusted proxies = %q, want %q", cfg.HTTP.TrustedProxies, want)
+	}
+	if want := []string{"kafka-1:9092", "kafka-2:9092"}; !reflect.DeepEqual(cfg.Kafka.Brokers, want) {
+		t.Fatalf("brokers = %q, want %q", cfg.Kafka.Brokers, want)
+	}
+}
+
+func TestLoadReportsEveryInvalidVariable(t *testing.T) {
+	_, err := load(map[string]string{
+		"APP_PORT":          "http",
+		"RATE_LIMIT_BURST":  "0",
+		"TRUSTED_PROXIES":   "proxy.local",
+		"KAFKA_MAX_RETRIES": "-1",
+	})
+	if err == nil {
+		t.Fatal("err = nil")
+	}
+	for _, name := range []string{"APP_PORT", "RATE_LIMIT_BURST", "TRUSTED_PROXIES", "KAFKA_MAX_RETRIES"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Errorf("err = %v, want it to name %s", err, name)
+		}
+	}
+
+	// Values of the wrong type fail in env, before the validation; its error names the struct field
+	if _, err := load(map[string]string{"MONGODB_TIMEOUT_MS": "5s"}); err == nil || !strings.Contains(err.Error(), "TimeoutMs") {
+		t.Fatalf("err = %v", err)
+	}
+}
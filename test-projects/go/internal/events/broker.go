@@ -0,0 +1,75 @@
+// Package events fans the user events read from Kafka out to the clients of the /api/events stream.
+package events
+
+import (
+	"sync"
+
+	"github.com/example/go-sample-app/internal/models"
+)
+
+// clientBuffer is how many events a client may fall behind before it misses some
+const clientBuffer = 64
+
+// Broker relays each published event to every subscriber. Publishing never blocks: a subscriber
+// whose buffer is full misses the event, so one slow client cannot stall the Kafka consumer.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan models.UserEvent]struct{}
+	closed      bool
+}
+
+// NewBroker creates a broker without subscribers
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan models.UserEvent]struct{})}
+}
+
+// Subscribe returns a channel of the events published from now on and a function that ends the
+// subscription. The channel is closed when the subscription ends or the broker closes.
+func (b *Broker) Subscribe() (<-chan models.UserEvent, func()) {
+	ch := make(chan models.UserEvent, clientBuffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish hands the event to every subscriber that has room for it
+func (b *Broker) Publish(event models.UserEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+		}
+	}
+}
+
+// Subscribers is the number of connected clients
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subscribers)
+}
+
+// Close ends every subscription, so the open streams return and the server can shut down
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for ch := range b.subscribers {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
@@ -0,0 +1,38 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/example/go-sample-app/internal/models"
+)
+
+func TestBrokerFansOutAndDropsForSlowClients(t *testing.T) {
+	broker := NewBroker()
+	fast, unsubscribeFast := broker.Subscribe()
+	slow, _ := broker.Subscribe()
+	defer unsubscribeFast()
+
+	for i := 0; i < clientBuffer+1; i++ {
+		broker.Publish(models.UserEvent{EventID: string(rune('a' + i%26))})
+		if i < clientBuffer {
+			<-fast
+		}
+	}
+	if got := (<-fast).EventID; got == "" {
+		t.Fatal("the client that keeps up gets every event")
+	}
+	if len(slow) != clientBuffer {
+		t.Fatalf("slow client holds %d events, want its buffer of %d", len(slow), clientBuffer)
+	}
+
+	broker.Close()
+	for range slow {
+	}
+	if _, ok := <-fast; ok {
+		t.Fatal("Close ends the subscriptions")
+	}
+	if broker.Subscribers() != 0 {
+		t.Fatalf("subscribers = %d after Close", broker.Subscribers())
+	}
+	unsubscribeFast()
+}
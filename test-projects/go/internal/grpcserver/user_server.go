@@ -0,0 +1,258 @@
+// Package grpcserver serves the users over gRPC (proto/user/v1/user.proto), next to the REST API and
+// on the same repository, so both APIs share the validation rules, the outbox events and the tokens.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/example/go-sample-app/internal/auth"
+	userv1 "github.com/example/go-sample-app/internal/gen/user/v1"
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+)
+
+//go:generate protoc -I ../../proto --go_out=../gen --go_opt=paths=source_relative --go-grpc_out=../gen --go-grpc_opt=paths=source_relative user/v1/user.proto
+
+// validate checks the requests with the binding rules of the models, as gin does for the REST API
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(models.FieldName)
+	return v
+}
+
+// NewServer creates the gRPC server with the UserService and server reflection, so tools such as
+// grpcurl and Postman list and call the methods without the .proto files
+func NewServer(userRepo repository.UserRepository, tokens *auth.TokenIssuer) *grpc.Server {
+	srv := grpc.NewServer()
+	userv1.RegisterUserServiceServer(srv, NewUserServer(userRepo, tokens))
+	reflection.Register(srv)
+	return srv
+}
+
+// UserServer implements userv1.UserServiceServer
+type UserServer struct {
+	userv1.UnimplementedUserServiceServer
+	userRepo repository.UserRepository
+	tokens   *auth.TokenIssuer
+}
+
+// NewUserServer creates a new UserServer
+func NewUserServer(userRepo repository.UserRepository, tokens *auth.TokenIssuer) *UserServer {
+	return &UserServer{
+		userRepo: userRepo,
+		tokens:   tokens,
+	}
+}
+
+// GetUser returns a user by ID, or NotFound
+func (s *UserServer) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	user, err := s.find(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return toProto(user), nil
+}
+
+// ListUsers returns a page of users, with the filters, defaults and limits of GET /api/users
+func (s *UserServer) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	query := models.UserQuery{
+		Limit:    int64(req.GetLimit()),
+		Offset:   int64(req.GetOffset()),
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Sort:     req.GetSort(),
+		Order:    req.GetOrder(),
+	}
+	if req.CreatedAfter != nil {
+		after := req.CreatedAfter.AsTime()
+		query.CreatedAfter = &after
+	}
+	if req.CreatedBefore != nil {
+		before := req.CreatedBefore.AsTime()
+		query.CreatedBefore = &before
+	}
+	if err := validate.Struct(query); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		return nil, invalidFields(models.FieldError{
+			Field:   "created_after",
+			Rule:    "ltfield",
+			Param:   "created_before",
+			Message: "must be before created_before",
+		})
+	}
+	query = query.WithDefaults()
+
+	users, total, err := s.userRepo.FindAll(ctx, query)
+	if err != nil {
+		log.Printf("Error fetching users: %v", err)
+		return nil, status.Error(codes.Internal, "Failed to fetch users")
+	}
+
+	resp := &userv1.ListUsersResponse{Users: make([]*userv1.User, 0, len(users)), Total: total}
+	for i := range users {
+		resp.Users = append(resp.Users, toProto(&users[i]))
+	}
+	return resp, nil
+}
+
+// CreateUser creates a new user (sign-up); no token is required, as in POST /api/auth/register
+func (s *UserServer) CreateUser(ctx context.Context, req *userv1.CreateUserRequest) (*userv1.User, error) {
+	input := &models.UserInput{Username: req.GetUsername(), Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := validate.Struct(input); err != nil {
+		return nil, invalidArgument(err)
+	}
+
+	user, err := s.userRepo.Create(ctx, input)
+	if err != nil {
+		return nil, writeError(err, "Failed to create user")
+	}
+	return toProto(user), nil
+}
+
+// UpdateUser updates the authenticated user; users may only update themselves
+func (s *UserServer) UpdateUser(ctx context.Context, req *userv1.UpdateUserRequest) (*userv1.User, error) {
+	actorID, err := s.authorize(ctx, req.GetId(), "Cannot modify another user")
+	if err != nil {
+		return nil, err
+	}
+	input := &models.UserInput{Username: req.GetUsername(), Email: req.GetEmail(), Password: req.GetPassword()}
+	if err := validate.Struct(input); err != nil {
+		return nil, invalidArgument(err)
+	}
+	if _, err := s.find(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.Update(ctx, req.GetId(), input, actorID)
+	if err != nil {
+		return nil, writeError(err, "Failed to update user")
+	}
+	return toProto(user), nil
+}
+
+// DeleteUser soft-deletes the authenticated user; users may only delete themselves
+func (s *UserServer) DeleteUser(ctx context.Context, req *userv1.DeleteUserRequest) (*emptypb.Empty, error) {
+	actorID, err := s.authorize(ctx, req.GetId(), "Cannot delete another user")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.find(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.Delete(ctx, req.GetId(), actorID); err != nil {
+		log.Printf("Error deleting user: %v", err)
+		return nil, status.Error(codes.Internal, "Failed to delete user")
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// find returns the user with the given ID, or InvalidArgument, NotFound or Internal
+func (s *UserServer) find(ctx context.Context, id string) (*models.User, error) {
+	if !primitive.IsValidObjectID(id) {
+		return nil, invalidFields(models.FieldError{Field: "id", Rule: "objectid", Message: "must be a 24-character hex ObjectID"})
+	}
+	user, err := s.userRepo.FindByID(ctx, id)
+	if err != nil {
+		log.Printf("Error fetching user: %v", err)
+		return nil, status.Error(codes.Internal, "Failed to fetch user")
+	}
+	if user == nil {
+		return nil, status.Error(codes.NotFound, "User not found")
+	}
+	return user, nil
+}
+
+// authorize returns the user ID of the bearer token in the "authorization" metadata: Unauthenticated
+// without a valid token, and PermissionDenied (with denied as message) when it is not the user with id
+func (s *UserServer) authorize(ctx context.Context, id, denied string) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		if t, found := strings.CutPrefix(values[0], "Bearer "); found {
+			token = t
+		}
+	}
+	if token == "" {
+		return "", status.Error(codes.Unauthenticated, "Missing bearer token")
+	}
+
+	userID, err := s.tokens.Verify(token)
+	if err != nil {
+		return "", status.Error(codes.Unauthenticated, err.Error())
+	}
+	if userID != id {
+		return "", status.Error(codes.PermissionDenied, denied)
+	}
+	return userID, nil
+}
+
+// writeError is the status of a failed Create or Update: AlreadyExists for a username or email in use,
+// otherwise Internal with message
+func writeError(err error, message string) error {
+	if errors.Is(err, repository.ErrUsernameTaken) || errors.Is(err, repository.ErrEmailTaken) {
+		return status.Error(codes.AlreadyExists, err.Error())
+	}
+	log.Printf("%s: %v", message, err)
+	return status.Error(codes.Internal, message)
+}
+
+// invalidArgument describes a validation error field by field, as the REST API's problem details do
+func invalidArgument(err error) error {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	fields := make([]models.FieldError, 0, len(invalid))
+	for _, fe := range invalid {
+		fields = append(fields, models.NewFieldError(fe))
+	}
+	return invalidFields(fields...)
+}
+
+// invalidFields is an InvalidArgument status with a google.rpc.BadRequest detail listing the fields
+func invalidFields(fields ...models.FieldError) error {
+	st := status.New(codes.InvalidArgument, "One or more fields are not valid")
+	badRequest := &errdetails.BadRequest{}
+	for _, f := range fields {
+		badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       f.Field,
+			Description: f.Message,
+		})
+	}
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		return detailed.Err()
+	}
+	return st.Err()
+}
+
+func toProto(user *models.User) *userv1.User {
+	return &userv1.User{
+		Id:        user.ID.Hex(),
+		Username:  user.Username,
+		Email:     user.Email,
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+		CreatedBy: user.CreatedBy,
+		UpdatedBy: user.UpdatedBy,
+	}
+}
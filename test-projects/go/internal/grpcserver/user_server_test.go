@@ -0,0 +1,231 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/example/go-sample-app/internal/auth"
+	userv1 "github.com/example/go-sample-app/internal/gen/user/v1"
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+	"github.com/example/go-sample-app/internal/repository/mocks"
+)
+
+// newClient serves NewServer in memory, backed by a mock repository, and connects a client to it
+func newClient(t *testing.T) (*grpc.ClientConn, *mocks.MockUserRepository, *auth.TokenIssuer) {
+	t.Helper()
+	repo := mocks.NewMockUserRepository(gomock.NewController(t))
+	tokens := auth.NewTokenIssuer("test-secret", time.Hour)
+
+	listener := bufconn.Listen(1 << 20)
+	srv := NewServer(repo, tokens)
+	go srv.Serve(listener)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, repo, tokens
+}
+
+// withToken authenticates the calls made with ctx as userID
+func withToken(t *testing.T, tokens *auth.TokenIssuer, userID string) context.Context {
+	t.Helper()
+	token, _, err := tokens.Issue(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+}
+
+// violations checks err is InvalidArgument and returns its invalid fields
+func violations(t *testing.T, err error) map[string]string {
+	t.Helper()
+	st := status.Convert(err)
+	if st.Code() != codes.InvalidArgument {
+		t.Fatalf("code = %s, want InvalidArgument (%v)", st.Code(), err)
+	}
+	fields := map[string]string{}
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, v := range badRequest.GetFieldViolations() {
+				fields[v.GetField()] = v.GetDescription()
+			}
+		}
+	}
+	return fields
+}
+
+func testUser(username string) models.User {
+	return models.User{ID: primitive.NewObjectID(), Username: username, Email: username + "@example.com", CreatedAt: time.Now()}
+}
+
+func TestGetUser(t *testing.T) {
+	conn, repo, _ := newClient(t)
+	client := userv1.NewUserServiceClient(conn)
+	user := testUser("alice")
+
+	repo.EXPECT().FindByID(gomock.Any(), user.ID.Hex()).Return(&user, nil)
+	got, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: user.ID.Hex()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetUsername() != "alice" || !got.GetCreatedAt().AsTime().Equal(user.CreatedAt.UTC()) {
+		t.Fatalf("user = %v", got)
+	}
+
+	missing := primitive.NewObjectID().Hex()
+	repo.EXPECT().FindByID(gomock.Any(), missing).Return(nil, nil)
+	if _, err := client.GetUser(context.Background(), &userv1.GetUserRequest{Id: missing}); status.Code(err) != codes.NotFound {
+		t.Fatalf("err = %v, want NotFound", err)
+	}
+
+	// An ID that is not an ObjectID never reaches the repository
+	_, err = client.GetUser(context.Background(), &userv1.GetUserRequest{Id: "nope"})
+	if fields := violations(t, err); fields["id"] == "" {
+		t.Fatalf("violations = %v", fields)
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	t.Run("applies the defaults of the REST API", func(t *testing.T) {
+		conn, repo, _ := newClient(t)
+		want := models.UserQuery{Username: "ali", Limit: 20, Sort: "created_at", Order: "desc"}
+		repo.EXPECT().FindAll(gomock.Any(), want).Return([]models.User{testUser("alice")}, int64(1), nil)
+
+		resp, err := userv1.NewUserServiceClient(conn).ListUsers(context.Background(), &userv1.ListUsersRequest{Username: "ali"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.GetTotal() != 1 || len(resp.GetUsers()) != 1 || resp.GetUsers()[0].GetUsername() != "alice" {
+			t.Fatalf("response = %v", resp)
+		}
+	})
+
+	t.Run("reports the invalid fields by their proto names", func(t *testing.T) {
+		conn, _, _ := newClient(t)
+		_, err := userv1.NewUserServiceClient(conn).ListUsers(context.Background(), &userv1.ListUsersRequest{Limit: 500, Sort: "password"})
+		fields := violations(t, err)
+		if fields["limit"] != "must be at most 100" || fields["sort"] == "" {
+			t.Fatalf("violations = %v", fields)
+		}
+	})
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Run("creates a valid user", func(t *testing.T) {
+		conn, repo, _ := newClient(t)
+		user := testUser("bob")
+		input := &models.UserInput{Username: "bob", Email: "bob@example.com", Password: "secret1"}
+		repo.EXPECT().Create(gomock.Any(), input).Return(&user, nil)
+
+		got, err := userv1.NewUserServiceClient(conn).CreateUser(context.Background(),
+			&userv1.CreateUserRequest{Username: "bob", Email: "bob@example.com", Password: "secret1"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.GetId() != user.ID.Hex() {
+			t.Fatalf("user = %v", got)
+		}
+	})
+
+	t.Run("validates the request", func(t *testing.T) {
+		conn, _, _ := newClient(t)
+		_, err := userv1.NewUserServiceClient(conn).CreateUser(context.Background(),
+			&userv1.CreateUserRequest{Username: "bo", Email: "not-an-email"})
+		fields := violations(t, err)
+		if len(fields) != 3 || fields["email"] != "must be a valid email address" {
+			t.Fatalf("violations = %v", fields)
+		}
+	})
+
+	t.Run("reports conflicts", func(t *testing.T) {
+		conn, repo, _ := newClient(t)
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, repository.ErrEmailTaken)
+
+		_, err := userv1.NewUserServiceClient(conn).CreateUser(context.Background(),
+			&userv1.CreateUserRequest{Username: "bob", Email: "bob@example.com", Password: "secret1"})
+		if status.Code(err) != codes.AlreadyExists {
+			t.Fatalf("err = %v, want AlreadyExists", err)
+		}
+	})
+}
+
+func TestUpdateAndDeleteUser(t *testing.T) {
+	conn, repo, tokens := newClient(t)
+	client := userv1.NewUserServiceClient(conn)
+	user := testUser("carol")
+	id := user.ID.Hex()
+	update := &userv1.UpdateUserRequest{Id: id, Username: "carol2", Email: "carol@example.com", Password: "secret1"}
+
+	if _, err := client.UpdateUser(context.Background(), update); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("err = %v, want Unauthenticated", err)
+	}
+	other := withToken(t, tokens, primitive.NewObjectID().Hex())
+	if _, err := client.DeleteUser(other, &userv1.DeleteUserRequest{Id: id}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("err = %v, want PermissionDenied", err)
+	}
+
+	self := withToken(t, tokens, id)
+	updated := user
+	updated.Username = "carol2"
+	repo.EXPECT().FindByID(gomock.Any(), id).Return(&user, nil).Times(2)
+	repo.EXPECT().Update(gomock.Any(), id, gomock.Any(), id).Return(&updated, nil)
+	repo.EXPECT().Delete(gomock.Any(), id, id).Return(nil)
+
+	got, err := client.UpdateUser(self, update)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.GetUsername() != "carol2" {
+		t.Fatalf("user = %v", got)
+	}
+	if _, err := client.DeleteUser(self, &userv1.DeleteUserRequest{Id: id}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReflection(t *testing.T) {
+	conn, _, _ := newClient(t)
+	stream, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &reflectionpb.ServerReflectionRequest{MessageRequest: &reflectionpb.ServerReflectionRequest_ListServices{}}
+	if err := stream.Send(req); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var services []string
+	for _, service := range resp.GetListServicesResponse().GetService() {
+		services = append(services, service.GetName())
+	}
+	found := false
+	for _, name := range services {
+		found = found || name == userv1.UserService_ServiceDesc.ServiceName
+	}
+	if !found {
+		t.Fatalf("services = %v, want %s", services, userv1.UserService_ServiceDesc.ServiceName)
+	}
+}
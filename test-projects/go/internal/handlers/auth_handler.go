@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/go-sample-app/internal/auth"
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+)
+
+// AuthHandler handles authentication requests
+type AuthHandler struct {
+	userRepo repository.UserRepository
+	tokens   *auth.TokenIssuer
+}
+
+// NewAuthHandler creates a new AuthHandler that signs tokens with the given issuer
+func NewAuthHandler(userRepo repository.UserRepository, tokens *auth.TokenIssuer) *AuthHandler {
+	return &AuthHandler{
+		userRepo: userRepo,
+		tokens:   tokens,
+	}
+}
+
+// Login checks the credentials and returns a signed JWT (HS256) for the user
+func (h *AuthHandler) Login(c *gin.Context) {
+	var input models.LoginInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, validationProblem(c, err))
+		return
+	}
+
+	user, err := h.userRepo.Authenticate(c.Request.Context(), input.Username, input.Password)
+	if errors.Is(err, repository.ErrInvalidCredentials) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		log.Printf("Error authenticating user: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		return
+	}
+
+	// Sign the access token
+	token, expiresAt, err := h.tokens.Issue(user.ID.Hex())
+	if err != nil {
+		log.Printf("Error signing token: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to authenticate"})
+		return
+	}
+
+	c.JSON(http.StatusOK, models.LoginResponse{
+		Token:     token,
+		TokenType: "Bearer",
+		ExpiresAt: expiresAt,
+		User:      *user,
+	})
+}
@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/mock/gomock"
+
+	"github.com/example/go-sample-app/internal/auth"
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+	"github.com/example/go-sample-app/internal/repository/mocks"
+)
+
+func TestLogin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := auth.NewTokenIssuer("test-secret", time.Hour)
+	user := testUser("alice")
+	body := `{"username":"alice","password":"secret123"}`
+
+	cases := []struct {
+		name   string
+		user   *models.User
+		err    error
+		status int
+	}{
+		{"valid credentials", &user, nil, http.StatusOK},
+		{"invalid credentials", nil, repository.ErrInvalidCredentials, http.StatusUnauthorized},
+		{"repository error", nil, errors.New("connection reset"), http.StatusInternalServerError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := mocks.NewMockUserRepository(gomock.NewController(t))
+			repo.EXPECT().Authenticate(gomock.Any(), "alice", "secret123").Return(tc.user, tc.err)
+			router := gin.New()
+			router.POST("/login", NewAuthHandler(repo, tokens).Login)
+
+			rec := serve(router, http.MethodPost, "/login", body)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.status, rec.Body.String())
+			}
+			if tc.status != http.StatusOK {
+				return
+			}
+
+			// The token identifies the user
+			var resp models.LoginResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			subject, err := tokens.Verify(resp.Token)
+			if err != nil || subject != user.ID.Hex() || resp.TokenType != "Bearer" {
+				t.Fatalf("token subject = %q (%v), response = %+v", subject, err, resp)
+			}
+		})
+	}
+
+	t.Run("requires username and password", func(t *testing.T) {
+		repo := mocks.NewMockUserRepository(gomock.NewController(t))
+		router := gin.New()
+		router.POST("/login", NewAuthHandler(repo, tokens).Login)
+		if rec := serve(router, http.MethodPost, "/login", `{"username":"alice"}`); rec.Code != http.StatusBadRequest {
+			t.Fatalf("status = %d, want 400", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/go-sample-app/internal/events"
+	"github.com/example/go-sample-app/internal/models"
+)
+
+// heartbeatInterval keeps an idle stream open through proxies that drop silent connections
+const heartbeatInterval = 15 * time.Second
+
+// eventTypes are the values the type filter accepts
+var eventTypes = []string{models.EventTypeUserCreated, models.EventTypeUserUpdated, models.EventTypeUserDeleted}
+
+// EventHandler streams the user events consumed from Kafka to HTTP clients
+type EventHandler struct {
+	broker    *events.Broker
+	heartbeat time.Duration
+}
+
+// NewEventHandler creates an EventHandler that relays the events of broker
+func NewEventHandler(broker *events.Broker) *EventHandler {
+	return &EventHandler{broker: broker, heartbeat: heartbeatInterval}
+}
+
+// Stream serves the user events as Server-Sent Events until the client leaves: each one with the
+// event ID as `id`, its type (USER_CREATED, ...) as `event` and the JSON as `data`. The type query
+// parameter keeps only the listed types (comma-separated). Only events consumed after the client
+// connects are sent; there is no replay by Last-Event-ID.
+func (h *EventHandler) Stream(c *gin.Context) {
+	wanted := map[string]bool{}
+	for _, t := range strings.Split(c.Query("type"), ",") {
+		if t = strings.TrimSpace(t); t == "" {
+			continue
+		}
+		if !slices.Contains(eventTypes, t) {
+			c.JSON(http.StatusBadRequest, invalidRequest(c, "One or more fields are not valid", models.FieldError{
+				Field:   "type",
+				Rule:    "oneof",
+				Param:   strings.Join(eventTypes, " "),
+				Message: fmt.Sprintf("must be one of %s", strings.Join(eventTypes, ", ")),
+			}))
+			return
+		}
+		wanted[t] = true
+	}
+
+	stream, unsubscribe := h.broker.Subscribe()
+	defer unsubscribe()
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	// Tells nginx not to buffer the stream
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	// A comment first, so the client sees the stream open before the first event
+	fmt.Fprint(c.Writer, ": connected\n\n")
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-c.Request.Context().Done():
+			return false
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			return true
+		case event, ok := <-stream:
+			if !ok {
+				// The server is shutting down
+				return false
+			}
+			if len(wanted) > 0 && !wanted[event.EventType] {
+				return true
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				return true
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.EventID, event.EventType, data)
+			return true
+		}
+	})
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/go-sample-app/internal/events"
+	"github.com/example/go-sample-app/internal/models"
+)
+
+func TestEventStream(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	broker := events.NewBroker()
+	router := gin.New()
+	router.GET("/events", NewEventHandler(broker).Stream)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/events?type=USER_DELETED", nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("status = %d, content type = %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := bufio.NewReader(resp.Body)
+	if line, _ := reader.ReadString('\n'); line != ": connected\n" {
+		t.Fatalf("first line = %q", line)
+	}
+
+	// Only the deleted event passes the filter
+	broker.Publish(models.UserEvent{EventID: "e1", EventType: models.EventTypeUserCreated, UserID: "u1"})
+	broker.Publish(models.UserEvent{EventID: "e2", EventType: models.EventTypeUserDeleted, UserID: "u1"})
+	var frame []string
+	for len(frame) < 3 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading the stream: %v (got %q)", err, frame)
+		}
+		if line = strings.TrimSuffix(line, "\n"); line != "" {
+			frame = append(frame, line)
+		}
+	}
+	if frame[0] != "id: e2" || frame[1] != "event: USER_DELETED" || !strings.Contains(frame[2], `"user_id":"u1"`) {
+		t.Fatalf("frame = %q", frame)
+	}
+
+	// Closing the broker ends the stream, as on shutdown
+	broker.Close()
+	if rest, err := io.ReadAll(reader); err != nil || strings.TrimSpace(string(rest)) != "" {
+		t.Fatalf("rest of the stream = %q, %v", rest, err)
+	}
+}
+
+func TestEventStreamRejectsUnknownTypes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/events", NewEventHandler(events.NewBroker()).Stream)
+
+	rec := serve(router, http.MethodGet, "/events?type=USER_CREATED,USER_LOGGED_IN", "")
+	problem := decodeProblem(t, rec)
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "type" {
+		t.Fatalf("problem = %+v", problem)
+	}
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// readinessTimeout bounds each dependency check, so a probe never hangs on a dead broker
+const readinessTimeout = 2 * time.Second
+
+// HealthHandler answers the liveness and readiness probes
+type HealthHandler struct {
+	mongoClient   *mongo.Client
+	kafkaBroker   string
+	kafkaDialer   *kafka.Dialer
+	kafkaRequired bool
+}
+
+// NewHealthHandler creates a HealthHandler that checks the given MongoDB client and Kafka broker.
+// Unless kafkaRequired, an unreachable Kafka leaves the instance ready, in degraded mode.
+func NewHealthHandler(mongoClient *mongo.Client, kafkaBroker string, kafkaRequired bool) *HealthHandler {
+	return &HealthHandler{
+		mongoClient:   mongoClient,
+		kafkaBroker:   kafkaBroker,
+		kafkaDialer:   &kafka.Dialer{Timeout: readinessTimeout},
+		kafkaRequired: kafkaRequired,
+	}
+}
+
+// Live reports that the process is up; it checks no dependency, so an outage of MongoDB or Kafka
+// does not get the pod restarted
+func (h *HealthHandler) Live(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Ready reports whether MongoDB and Kafka answer; 503 takes the instance out of the load balancer
+// until they are back. An optional Kafka that is down only makes the status "degraded": the API
+// still serves requests and the user events wait in the outbox.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	mongoResult := checkResult(h.pingMongo(c.Request.Context()))
+	kafkaResult := checkResult(h.PingKafka(c.Request.Context()))
+	checks := gin.H{"mongodb": mongoResult, "kafka": kafkaResult}
+	switch {
+	case mongoResult != "ok" || (kafkaResult != "ok" && h.kafkaRequired):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "checks": checks})
+	case kafkaResult != "ok":
+		c.JSON(http.StatusOK, gin.H{"status": "degraded", "checks": checks, "degraded": []string{"kafka"}})
+	default:
+		c.JSON(http.StatusOK, gin.H{"status": "ready", "checks": checks})
+	}
+}
+
+// pingMongo pings the primary
+func (h *HealthHandler) pingMongo(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	return h.mongoClient.Ping(ctx, readpref.Primary())
+}
+
+// PingKafka connects to the broker and asks for the cluster's brokers
+func (h *HealthHandler) PingKafka(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, readinessTimeout)
+	defer cancel()
+	conn, err := h.kafkaDialer.DialContext(ctx, "tcp", h.kafkaBroker)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+	_, err = conn.Brokers()
+	return err
+}
+
+// checkResult is "ok" or the error of a check
+func checkResult(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
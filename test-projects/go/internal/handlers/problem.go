@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"github.com/example/go-sample-app/internal/models"
+)
+
+func init() {
+	// Report fields by the names clients send (json, or form for query parameters), not the Go names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(models.FieldName)
+	}
+}
+
+// validationProblem describes why binding the request body or query failed, field by field when the
+// validator says which fields, and sets the problem+json content type of the response
+func validationProblem(c *gin.Context, err error) models.Problem {
+	var (
+		invalid   validator.ValidationErrors
+		typeErr   *json.UnmarshalTypeError
+		syntaxErr *json.SyntaxError
+		numErr    *strconv.NumError
+		timeErr   *time.ParseError
+		fields    []models.FieldError
+		detail    = "The request is not valid"
+	)
+	switch {
+	case errors.As(err, &invalid):
+		detail = "One or more fields are not valid"
+		for _, fe := range invalid {
+			fields = append(fields, models.NewFieldError(fe))
+		}
+	case errors.As(err, &typeErr):
+		detail = "One or more fields have the wrong type"
+		fields = append(fields, models.FieldError{
+			Field:   typeErr.Field,
+			Rule:    "type",
+			Param:   typeErr.Type.String(),
+			Message: fmt.Sprintf("must be a %s, not a %s", typeErr.Type, typeErr.Value),
+		})
+	case errors.Is(err, io.EOF):
+		detail = "The request body is empty"
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		detail = "The request body is not valid JSON"
+	case errors.As(err, &numErr):
+		detail = fmt.Sprintf("%q is not a valid number", numErr.Num)
+	case errors.As(err, &timeErr):
+		detail = fmt.Sprintf("%q is not a valid RFC 3339 time", timeErr.Value)
+	}
+	return invalidRequest(c, detail, fields...)
+}
+
+// invalidRequest is a 400 validation problem for the current request
+func invalidRequest(c *gin.Context, detail string, fields ...models.FieldError) models.Problem {
+	c.Header("Content-Type", models.ProblemContentType)
+	return models.Problem{
+		Type:     models.ValidationProblemType,
+		Title:    "Validation failed",
+		Status:   http.StatusBadRequest,
+		Detail:   detail,
+		Instance: c.Request.URL.Path,
+		Errors:   fields,
+	}
+}
@@ -1,40 +1,63 @@
 package handlers
 
 import (
-	"context"
 	"log"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 
+	"github.com/example/go-sample-app/internal/middleware"
 	"github.com/example/go-sample-app/internal/models"
 	"github.com/example/go-sample-app/internal/repository"
 )
 
-// UserHandler handles HTTP requests for user operations
+// UserHandler handles HTTP requests for user operations.
+// The repository writes the user events to the outbox, and worker.OutboxRelay publishes them.
 type UserHandler struct {
-	userRepo      *repository.UserRepository
-	eventProducer *models.EventProducer
+	userRepo repository.UserRepository
 }
 
 // NewUserHandler creates a new UserHandler
-func NewUserHandler(userRepo *repository.UserRepository, eventProducer *models.EventProducer) *UserHandler {
+func NewUserHandler(userRepo repository.UserRepository) *UserHandler {
 	return &UserHandler{
-		userRepo:      userRepo,
-		eventProducer: eventProducer,
+		userRepo: userRepo,
 	}
 }
 
-// GetAllUsers returns all users
+// GetAllUsers returns a page of users.
+// Query parameters: limit (1-100, default 20), offset, username (prefix), email,
+// created_after and created_before (RFC 3339), sort (username, email, created_at, updated_at)
+// and order (asc, desc; default created_at desc)
 func (h *UserHandler) GetAllUsers(c *gin.Context) {
-	users, err := h.userRepo.FindAll(c.Request.Context())
+	var query models.UserQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, validationProblem(c, err))
+		return
+	}
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		c.JSON(http.StatusBadRequest, invalidRequest(c, "One or more fields are not valid", models.FieldError{
+			Field:   "created_after",
+			Rule:    "ltfield",
+			Param:   "created_before",
+			Message: "must be before created_before",
+		}))
+		return
+	}
+	query = query.WithDefaults()
+
+	users, total, err := h.userRepo.FindAll(c.Request.Context(), query)
 	if err != nil {
 		log.Printf("Error fetching users: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch users"})
 		return
 	}
 
-	c.JSON(http.StatusOK, users)
+	c.JSON(http.StatusOK, models.UserPage{
+		Items:  users,
+		Total:  total,
+		Limit:  query.Limit,
+		Offset: query.Offset,
+	})
 }
 
 // GetUserByID returns a user by ID
@@ -60,11 +83,11 @@ func (h *UserHandler) GetUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
-// CreateUser creates a new user
+// CreateUser creates a new user (sign-up)
 func (h *UserHandler) CreateUser(c *gin.Context) {
 	var input models.UserInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, validationProblem(c, err))
 		return
 	}
 
@@ -75,62 +98,50 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 		return
 	}
 
-	// Publish event to Kafka
-	event := models.NewUserCreatedEvent(*user)
-	go func() {
-		// Use background context for async operations
-		ctx := context.Background()
-		if err := h.eventProducer.PublishUserEvent(ctx, event); err != nil {
-			log.Printf("Error publishing user created event: %v", err)
-		}
-	}()
-
 	c.JSON(http.StatusCreated, user)
 }
 
-// UpdateUser updates an existing user
+// UpdateUser updates an existing user; users may only update themselves
 func (h *UserHandler) UpdateUser(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
 		return
 	}
+	if !isSelf(c, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot modify another user"})
+		return
+	}
 
 	var input models.UserInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, validationProblem(c, err))
 		return
 	}
 
-	user, err := h.userRepo.Update(c.Request.Context(), id, &input)
+	user, err := h.userRepo.Update(c.Request.Context(), id, &input, c.GetString(middleware.UserIDKey))
 	if err != nil {
 		log.Printf("Error updating user: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	// Publish event to Kafka
-	event := models.NewUserUpdatedEvent(*user)
-	go func() {
-		// Use background context for async operations
-		ctx := context.Background()
-		if err := h.eventProducer.PublishUserEvent(ctx, event); err != nil {
-			log.Printf("Error publishing user updated event: %v", err)
-		}
-	}()
-
 	c.JSON(http.StatusOK, user)
 }
 
-// DeleteUser deletes a user
+// DeleteUser soft-deletes a user; users may only delete themselves
 func (h *UserHandler) DeleteUser(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "User ID is required"})
 		return
 	}
+	if !isSelf(c, id) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Cannot delete another user"})
+		return
+	}
 
-	// Get user before deletion for event publishing
+	// Check the user exists, to answer 404
 	user, err := h.userRepo.FindByID(c.Request.Context(), id)
 	if err != nil {
 		log.Printf("Error fetching user for deletion: %v", err)
@@ -144,21 +155,16 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	}
 
 	// Delete the user
-	if err := h.userRepo.Delete(c.Request.Context(), id); err != nil {
+	if err := h.userRepo.Delete(c.Request.Context(), id, c.GetString(middleware.UserIDKey)); err != nil {
 		log.Printf("Error deleting user: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user"})
 		return
 	}
 
-	// Publish event to Kafka
-	event := models.NewUserDeletedEvent(*user)
-	go func() {
-		// Use background context for async operations
-		ctx := context.Background()
-		if err := h.eventProducer.PublishUserEvent(ctx, event); err != nil {
-			log.Printf("Error publishing user deleted event: %v", err)
-		}
-	}()
-
 	c.JSON(http.StatusNoContent, nil)
-}
\ No newline at end of file
+}
+
+// isSelf reports whether the authenticated user (set by middleware.RequireAuth) is the user with the given ID
+func isSelf(c *gin.Context, id string) bool {
+	return c.GetString(middleware.UserIDKey) == id
+}
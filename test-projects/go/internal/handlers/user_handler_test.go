@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+
+	"github.com/example/go-sample-app/internal/middleware"
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+	"github.com/example/go-sample-app/internal/repository/mocks"
+)
+
+// newUserRouter registers the user routes on a router where requests are authenticated as userID
+// (as middleware.RequireAuth would do), backed by a mock repository
+func newUserRouter(t *testing.T, userID string) (*gin.Engine, *mocks.MockUserRepository) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	repo := mocks.NewMockUserRepository(gomock.NewController(t))
+	handler := NewUserHandler(repo)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, userID)
+	})
+	router.GET("/users", handler.GetAllUsers)
+	router.GET("/users/:id", handler.GetUserByID)
+	router.POST("/users", handler.CreateUser)
+	router.PUT("/users/:id", handler.UpdateUser)
+	router.DELETE("/users/:id", handler.DeleteUser)
+	return router, repo
+}
+
+func serve(router *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeProblem checks rec is a 400 problem+json response and decodes it
+func decodeProblem(t *testing.T, rec *httptest.ResponseRecorder) models.Problem {
+	t.Helper()
+	if rec.Code != http.StatusBadRequest || rec.Header().Get("Content-Type") != models.ProblemContentType {
+		t.Fatalf("status = %d, content type = %q, want 400 %s", rec.Code, rec.Header().Get("Content-Type"), models.ProblemContentType)
+	}
+	var problem models.Problem
+	if err := json.Unmarshal(rec.Body.Bytes(), &problem); err != nil {
+		t.Fatal(err)
+	}
+	if problem.Status != http.StatusBadRequest || problem.Type != models.ValidationProblemType {
+		t.Fatalf("problem = %+v", problem)
+	}
+	return problem
+}
+
+func testUser(username string) models.User {
+	return models.User{ID: primitive.NewObjectID(), Username: username, Email: username + "@example.com"}
+}
+
+func TestGetAllUsers(t *testing.T) {
+	t.Run("applies the default page and sort", func(t *testing.T) {
+		router, repo := newUserRouter(t, "")
+		want := models.UserQuery{Username: "ali", Limit: 20, Sort: "created_at", Order: "desc"}
+		repo.EXPECT().FindAll(gomock.Any(), want).Return([]models.User{testUser("alice")}, int64(1), nil)
+
+		rec := serve(router, http.MethodGet, "/users?username=ali", "")
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+		}
+		var page models.UserPage
+		if err := json.Unmarshal(rec.Body.Bytes(), &page); err != nil {
+			t.Fatal(err)
+		}
+		if page.Total != 1 || page.Limit != 20 || len(page.Items) != 1 || page.Items[0].Username != "alice" {
+			t.Fatalf("page = %+v", page)
+		}
+	})
+
+	t.Run("passes the filters, page and sort to the repository", func(t *testing.T) {
+		router, repo := newUserRouter(t, "")
+		after, before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+		want := models.UserQuery{
+			Limit: 5, Offset: 10, Email: "alice@example.com", CreatedAfter: &after, CreatedBefore: &before,
+			Sort: "username", Order: "asc",
+		}
+		repo.EXPECT().FindAll(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, query models.UserQuery) ([]models.User, int64, error) {
+			if query.Limit != want.Limit || query.Offset != want.Offset || query.Email != want.Email || query.Sort != want.Sort ||
+				query.Order != want.Order || !query.CreatedAfter.Equal(after) || !query.CreatedBefore.Equal(before) {
+				t.Errorf("query = %+v, want %+v", query, want)
+			}
+			return nil, 0, nil
+		})
+
+		path := "/users?limit=5&offset=10&email=alice@example.com&created_after=2025-01-01T00:00:00Z&created_before=2025-02-01T00:00:00Z&sort=username&order=asc"
+		if rec := serve(router, http.MethodGet, path, ""); rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want 200 (%s)", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("rejects an invalid query without reading the repository", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  models.FieldError
+		}{
+			{"limit=500", models.FieldError{Field: "limit", Rule: "max", Param: "100", Message: "must be at most 100"}},
+			{"limit=-1", models.FieldError{Field: "limit", Rule: "min", Param: "1"}},
+			{"offset=-1", models.FieldError{Field: "offset", Rule: "min", Param: "0"}},
+			{"sort=password", models.FieldError{Field: "sort", Rule: "oneof", Param: "username email created_at updated_at"}},
+			{"order=up", models.FieldError{Field: "order", Rule: "oneof", Param: "asc desc"}},
+			{"email=alice", models.FieldError{Field: "email", Rule: "email"}},
+			{
+				"created_after=2025-02-01T00:00:00Z&created_before=2025-01-01T00:00:00Z",
+				models.FieldError{Field: "created_after", Rule: "ltfield", Param: "created_before", Message: "must be before created_before"},
+			},
+			{
+				"created_after=2025-01-01T00:00:00Z&created_before=2025-01-01T00:00:00Z",
+				models.FieldError{Field: "created_after", Rule: "ltfield", Param: "created_before", Message: "must be before created_before"},
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				router, _ := newUserRouter(t, "")
+				problem := decodeProblem(t, serve(router, http.MethodGet, "/users?"+tt.query, ""))
+				if len(problem.Errors) != 1 {
+					t.Fatalf("errors = %+v, want one about %s", problem.Errors, tt.want.Field)
+				}
+				got := problem.Errors[0]
+				if tt.want.Message == "" {
+					got.Message = ""
+				}
+				if !reflect.DeepEqual(got, tt.want) {
+					t.Fatalf("error = %+v, want %+v", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("hides repository errors", func(t *testing.T) {
+		router, repo := newUserRouter(t, "")
+		repo.EXPECT().FindAll(gomock.Any(), gomock.Any()).Return(nil, int64(0), errors.New("connection reset"))
+
+		rec := serve(router, http.MethodGet, "/users", "")
+		if rec.Code != http.StatusInternalServerError || strings.Contains(rec.Body.String(), "connection reset") {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestGetUserByID(t *testing.T) {
+	router, repo := newUserRouter(t, "")
+	user := testUser("alice")
+	repo.EXPECT().FindByID(gomock.Any(), user.ID.Hex()).Return(&user, nil)
+	repo.EXPECT().FindByID(gomock.Any(), "missing").Return(nil, nil)
+
+	if rec := serve(router, http.MethodGet, "/users/"+user.ID.Hex(), ""); rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if rec := serve(router, http.MethodGet, "/users/missing", ""); rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want 404", rec.Code)
+	}
+}
+
+func TestCreateUser(t *testing.T) {
+	t.Run("creates a valid user", func(t *testing.T) {
+		router, repo := newUserRouter(t, "")
+		input := &models.UserInput{Username: "alice", Email: "alice@example.com", Password: "secret123"}
+		user := testUser("alice")
+		repo.EXPECT().Create(gomock.Any(), input).Return(&user, nil)
+
+		rec := serve(router, http.MethodPost, "/users", `{"username":"alice","email":"alice@example.com","password":"secret123"}`)
+		if rec.Code != http.StatusCreated {
+			t.Fatalf("status = %d, want 201 (%s)", rec.Code, rec.Body.String())
+		}
+		if strings.Contains(rec.Body.String(), "password") {
+			t.Fatalf("response exposes the password: %s", rec.Body.String())
+		}
+	})
+
+	t.Run("validates the body", func(t *testing.T) {
+		router, _ := newUserRouter(t, "")
+		rec := serve(router, http.MethodPost, "/users", `{"username":"al","email":"not-an-email","password":"123"}`)
+		problem := decodeProblem(t, rec)
+		if problem.Instance != "/users" || len(problem.Errors) != 3 {
+			t.Fatalf("problem = %+v", problem)
+		}
+		want := models.FieldError{Field: "username", Rule: "min", Param: "3", Message: "must be at least 3 characters"}
+		if problem.Errors[0] != want || problem.Errors[1].Field != "email" || problem.Errors[2].Field != "password" {
+			t.Fatalf("errors = %+v", problem.Errors)
+		}
+	})
+
+	t.Run("reports malformed and mistyped bodies", func(t *testing.T) {
+		router, _ := newUserRouter(t, "")
+		if problem := decodeProblem(t, serve(router, http.MethodPost, "/users", `{"username":`)); problem.Detail != "The request body is not valid JSON" {
+			t.Fatalf("problem = %+v", problem)
+		}
+		problem := decodeProblem(t, serve(router, http.MethodPost, "/users", `{"username":42}`))
+		if len(problem.Errors) != 1 || problem.Errors[0].Field != "username" || problem.Errors[0].Rule != "type" {
+			t.Fatalf("problem = %+v", problem)
+		}
+	})
+
+	t.Run("reports conflicts", func(t *testing.T) {
+		router, repo := newUserRouter(t, "")
+		repo.EXPECT().Create(gomock.Any(), gomock.Any()).Return(nil, repository.ErrUsernameTaken)
+
+		rec := serve(router, http.MethodPost, "/users", `{"username":"alice","email":"alice@example.com","password":"secret123"}`)
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "username already exists") {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+	})
+}
+
+func TestUpdateUser(t *testing.T) {
+	user := testUser("alice")
+	body := `{"username":"alice2","email":"alice@example.com","password":"secret123"}`
+
+	t.Run("updates the authenticated user", func(t *testing.T) {
+		router, repo := newUserRouter(t, user.ID.Hex())
+		updated := user
+		updated.Username = "alice2"
+		repo.EXPECT().Update(gomock.Any(), user.ID.Hex(), gomock.Any(), user.ID.Hex()).Return(&updated, nil)
+
+		rec := serve(router, http.MethodPut, "/users/"+user.ID.Hex(), body)
+		if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"username":"alice2"`) {
+			t.Fatalf("status = %d, body = %s", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("forbids updating another user", func(t *testing.T) {
+		router, _ := newUserRouter(t, "someone-else")
+		rec := serve(router, http.MethodPut, "/users/"+user.ID.Hex(), body)
+		if rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403", rec.Code)
+		}
+	})
+}
+
+func TestDeleteUser(t *testing.T) {
+	user := testUser("alice")
+
+	t.Run("deletes the authenticated user", func(t *testing.T) {
+		router, repo := newUserRouter(t, user.ID.Hex())
+		gomock.InOrder(
+			repo.EXPECT().FindByID(gomock.Any(), user.ID.Hex()).Return(&user, nil),
+			repo.EXPECT().Delete(gomock.Any(), user.ID.Hex(), user.ID.Hex()).Return(nil),
+		)
+
+		if rec := serve(router, http.MethodDelete, "/users/"+user.ID.Hex(), ""); rec.Code != http.StatusNoContent {
+			t.Fatalf("status = %d, want 204", rec.Code)
+		}
+	})
+
+	t.Run("answers 404 without deleting", func(t *testing.T) {
+		router, repo := newUserRouter(t, user.ID.Hex())
+		repo.EXPECT().FindByID(gomock.Any(), user.ID.Hex()).Return(nil, nil)
+
+		if rec := serve(router, http.MethodDelete, "/users/"+user.ID.Hex(), ""); rec.Code != http.StatusNotFound {
+			t.Fatalf("status = %d, want 404", rec.Code)
+		}
+	})
+
+	t.Run("forbids deleting another user", func(t *testing.T) {
+		router, _ := newUserRouter(t, "someone-else")
+		if rec := serve(router, http.MethodDelete, "/users/"+user.ID.Hex(), ""); rec.Code != http.StatusForbidden {
+			t.Fatalf("status = %d, want 403", rec.Code)
+		}
+	})
+}
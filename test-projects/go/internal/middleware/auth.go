@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/go-sample-app/internal/auth"
+)
+
+// UserIDKey is the context key holding the ID of the authenticated user
+const UserIDKey = "user_id"
+
+// RequireAuth rejects requests without a valid "Authorization: Bearer <token>" header
+// and stores the authenticated user ID in the context
+func RequireAuth(tokens *auth.TokenIssuer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		header := c.GetHeader("Authorization")
+		token, found := strings.CutPrefix(header, "Bearer ")
+		if !found || token == "" {
+			c.Header("WWW-Authenticate", `Bearer realm="api"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing bearer token"})
+			return
+		}
+
+		userID, err := tokens.Verify(token)
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer realm="api", error="invalid_token"`)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(UserIDKey, userID)
+		c.Next()
+	}
+}
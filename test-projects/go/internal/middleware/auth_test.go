@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/example/go-sample-app/internal/auth"
+)
+
+func TestRequireAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := auth.NewTokenIssuer("test-secret", time.Hour)
+	router := gin.New()
+	router.POST("/private", RequireAuth(tokens), func(c *gin.Context) {
+		c.String(http.StatusOK, c.GetString(UserIDKey))
+	})
+
+	valid, _, err := tokens.Issue("user-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, _, _ := auth.NewTokenIssuer("other-secret", time.Hour).Issue("user-1")
+	expired, _, _ := auth.NewTokenIssuer("test-secret", -time.Minute).Issue("user-1")
+
+	cases := []struct {
+		name   string
+		header string
+		status int
+	}{
+		{"valid token", "Bearer " + valid, http.StatusOK},
+		{"missing header", "", http.StatusUnauthorized},
+		{"wrong scheme", "Basic " + valid, http.StatusUnauthorized},
+		{"other secret", "Bearer " + forged, http.StatusUnauthorized},
+		{"expired", "Bearer " + expired, http.StatusUnauthorized},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/private", nil)
+			if tc.header != "" {
+				req.Header.Set("Authorization", tc.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d (%s)", rec.Code, tc.status, rec.Body.String())
+			}
+			if tc.status == http.StatusOK && rec.Body.String() != "user-1" {
+				t.Fatalf("user id = %q, want user-1", rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/time/rate"
+)
+
+// RateLimitConfig sets how many requests each client IP may make
+type RateLimitConfig struct {
+	// RequestsPerSecond is the sustained rate per IP; 0 disables the limit
+	RequestsPerSecond float64
+	// Burst is how many requests an IP may make at once, above the sustained rate
+	Burst int
+	// IdleTTL is how long the limiter of an IP without requests is kept
+	IdleTTL time.Duration
+}
+
+type client struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// RateLimit rejects with 429 and a Retry-After header the requests of an IP (gin's ClientIP, which
+// reads X-Forwarded-For only from the trusted proxies) above its token bucket
+func RateLimit(config RateLimitConfig) gin.HandlerFunc {
+	if config.RequestsPerSecond <= 0 {
+		return func(c *gin.Context) { c.Next() }
+	}
+	if config.Burst < 1 {
+		config.Burst = 1
+	}
+	if config.IdleTTL <= 0 {
+		config.IdleTTL = 10 * time.Minute
+	}
+
+	var (
+		mu        sync.Mutex
+		clients   = map[string]*client{}
+		lastSweep = time.Now()
+	)
+	return func(c *gin.Context) {
+		now := time.Now()
+		mu.Lock()
+		// Forget idle IPs, so the map does not grow with every address ever seen
+		if now.Sub(lastSweep) > config.IdleTTL {
+			for ip, cl := range clients {
+				if now.Sub(cl.lastSeen) > config.IdleTTL {
+					delete(clients, ip)
+				}
+			}
+			lastSweep = now
+		}
+		cl, ok := clients[c.ClientIP()]
+		if !ok {
+			cl = &client{limiter: rate.NewLimiter(rate.Limit(config.RequestsPerSecond), config.Burst)}
+			clients[c.ClientIP()] = cl
+		}
+		cl.lastSeen = now
+		reservation := cl.limiter.ReserveN(now, 1)
+		mu.Unlock()
+
+		c.Header("X-RateLimit-Limit", strconv.Itoa(config.Burst))
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			c.Header("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Too many requests"})
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/", RateLimit(RateLimitConfig{RequestsPerSecond: 0.5, Burst: 2}), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	get := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = ip + ":1234"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := get("10.0.0.1"); rec.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200 within the burst", i+1, rec.Code)
+		}
+	}
+	rec := get("10.0.0.1")
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "2" {
+		t.Fatalf("status = %d, Retry-After = %q, want 429 and 2", rec.Code, rec.Header().Get("Retry-After"))
+	}
+	if rec := get("10.0.0.2"); rec.Code != http.StatusOK {
+		t.Fatalf("other IP: status = %d, want 200", rec.Code)
+	}
+
+	t.Run("zero rate disables the limit", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/", RateLimit(RateLimitConfig{}), func(c *gin.Context) { c.Status(http.StatusOK) })
+		for i := 0; i < 50; i++ {
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", rec.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		if RequestIDFrom(c.Request.Context()) != c.GetString(RequestIDKey) {
+			t.Errorf("request context and gin context disagree")
+		}
+		c.String(http.StatusOK, c.GetString(RequestIDKey))
+	})
+
+	cases := []struct {
+		name   string
+		header string
+		keep   bool
+	}{
+		{"keeps the caller's ID", "gateway-42", true},
+		{"creates one when missing", "", false},
+		{"replaces IDs with control characters", "a\tb", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.header != "" {
+				req.Header.Set(RequestIDHeader, tc.header)
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			id := rec.Header().Get(RequestIDHeader)
+			if id != rec.Body.String() {
+				t.Fatalf("response header %q, handler saw %q", id, rec.Body.String())
+			}
+			if tc.keep && id != tc.header {
+				t.Fatalf("id = %q, want %q", id, tc.header)
+			}
+			if !tc.keep && len(id) != 32 {
+				t.Fatalf("id = %q, want a new 32-character ID", id)
+			}
+		})
+	}
+}
@@ -4,24 +4,64 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"strconv"
 	"time"
 
 	"github.com/segmentio/kafka-go"
+
+	"github.com/example/go-sample-app/internal/metrics"
+	"github.com/example/go-sample-app/internal/tracing"
 )
 
+// messageWriter is the part of *kafka.Writer used by EventProducer, so tests can stand in for the broker
+type messageWriter interface {
+	WriteMessages(ctx context.Context, msgs ...kafka.Message) error
+	Close() error
+}
+
+// ProducerConfig sets how EventProducer retries a failed write and where the events that still
+// fail go. The zero value writes once, without a dead-letter topic.
+type ProducerConfig struct {
+	// MaxRetries is how many times a failed write is retried
+	MaxRetries int
+	// Backoff is the wait before the first retry; it doubles on each retry, up to MaxBackoff
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// DLQ receives the events that could not be published after the retries; nil disables it
+	DLQ *kafka.Writer
+}
+
 // EventProducer handles publishing events to Kafka
 type EventProducer struct {
-	writer *kafka.Writer
+	writer     messageWriter
+	topic      string
+	dlq        messageWriter
+	dlqTopic   string
+	maxRetries int
+	backoff    time.Duration
+	maxBackoff time.Duration
 }
 
-// NewEventProducer creates a new event producer with the given Kafka writer
-func NewEventProducer(writer *kafka.Writer) *EventProducer {
-	return &EventProducer{
-		writer: writer,
+// NewEventProducer creates a new event producer with the given Kafka writer and retry settings
+func NewEventProducer(writer *kafka.Writer, config ProducerConfig) *EventProducer {
+	p := &EventProducer{
+		writer:     writer,
+		topic:      writer.Topic,
+		maxRetries: config.MaxRetries,
+		backoff:    config.Backoff,
+		maxBackoff: config.MaxBackoff,
 	}
+	if config.DLQ != nil {
+		p.dlq = config.DLQ
+		p.dlqTopic = config.DLQ.Topic
+	}
+	return p
 }
 
-// PublishUserEvent publishes a user event to Kafka
+// PublishUserEvent publishes a user event to Kafka, retrying failed writes with exponential
+// backoff. An event that still fails is written to the dead-letter topic, if any, and counts as
+// handled; the error is returned only when that write fails too (or there is no dead-letter
+// topic), so the caller can try again later.
 func (p *EventProducer) PublishUserEvent(ctx context.Context, event UserEvent) error {
 	// Convert event to JSON bytes
 	value, err := json.Marshal(event)
@@ -43,17 +83,68 @@ func (p *EventProducer) PublishUserEvent(ctx context.Context, event UserEvent) e
 		},
 	}
 
-	// Write message to Kafka
-	if err := p.writer.WriteMessages(ctx, msg); err != nil {
-		log.Printf("Error writing message to Kafka: %v", err)
-		return err
+	wait := p.backoff
+	for attempt := 0; ; attempt++ {
+		err = p.write(ctx, p.writer, p.topic, event.EventType, msg)
+		if err == nil {
+			log.Printf("Published %s event for user %s to Kafka", event.EventType, event.UserID)
+			return nil
+		}
+		log.Printf("Error writing message to Kafka (attempt %d of %d): %v", attempt+1, p.maxRetries+1, err)
+		if attempt == p.maxRetries || ctx.Err() != nil {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if p.maxBackoff > 0 && wait > p.maxBackoff {
+			wait = p.maxBackoff
+		}
 	}
 
-	log.Printf("Published %s event for user %s to Kafka", event.EventType, event.UserID)
+	// A canceled publish is not a persistent failure: leave the event to the caller
+	if p.dlq == nil || ctx.Err() != nil {
+		return err
+	}
+	dead := kafka.Message{
+		Key:   msg.Key,
+		Value: msg.Value,
+		Time:  time.Now(),
+		Headers: []kafka.Header{
+			{Key: "event_type", Value: []byte(event.EventType)},
+			{Key: "original_topic", Value: []byte(p.topic)},
+			{Key: "attempts", Value: []byte(strconv.Itoa(p.maxRetries + 1))},
+			{Key: "error", Value: []byte(err.Error())},
+		},
+	}
+	if dlqErr := p.write(ctx, p.dlq, p.dlqTopic, event.EventType, dead); dlqErr != nil {
+		log.Printf("Error writing message to the dead-letter topic %s: %v", p.dlqTopic, dlqErr)
+		return err
+	}
+	log.Printf("Sent %s event for user %s to the dead-letter topic %s after %d attempts: %v",
+		event.EventType, event.UserID, p.dlqTopic, p.maxRetries+1, err)
 	return nil
 }
 
-// Close closes the Kafka writer
+// write sends one message to topic, carrying the trace context in its headers
+func (p *EventProducer) write(ctx context.Context, writer messageWriter, topic, eventType string, msg kafka.Message) error {
+	ctx, span := tracing.StartPublish(ctx, topic, &msg)
+	err := writer.WriteMessages(ctx, msg)
+	tracing.End(span, err)
+	metrics.ObserveKafkaPublish(topic, eventType, err)
+	return err
+}
+
+// Close closes the Kafka writers
 func (p *EventProducer) Close() error {
-	return p.writer.Close()
-}
\ No newline at end of file
+	err := p.writer.Close()
+	if p.dlq != nil {
+		if dlqErr := p.dlq.Close(); err == nil {
+			err = dlqErr
+		}
+	}
+	return err
+}
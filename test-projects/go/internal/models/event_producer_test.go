@@ -0,0 +1,110 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// fakeWriter fails the first failures writes and records the messages written after that
+type fakeWriter struct {
+	failures int
+	calls    int
+	written  []kafka.Message
+}
+
+func (w *fakeWriter) WriteMessages(_ context.Context, msgs ...kafka.Message) error {
+	w.calls++
+	if w.calls <= w.failures {
+		return errors.New("leader not available")
+	}
+	w.written = append(w.written, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) Close() error { return nil }
+
+func newTestProducer(writer, dlq *fakeWriter, maxRetries int) *EventProducer {
+	p := &EventProducer{writer: writer, topic: "users", maxRetries: maxRetries, backoff: time.Millisecond, maxBackoff: 2 * time.Millisecond}
+	if dlq != nil {
+		p.dlq = dlq
+		p.dlqTopic = "users.dlq"
+	}
+	return p
+}
+
+func header(msg kafka.Message, key string) string {
+	for _, h := range msg.Headers {
+		if h.Key == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+func TestPublishUserEventRetriesTransientFailures(t *testing.T) {
+	writer, dlq := &fakeWriter{failures: 2}, &fakeWriter{}
+	p := newTestProducer(writer, dlq, 3)
+
+	if err := p.PublishUserEvent(context.Background(), UserEvent{EventType: EventTypeUserCreated, UserID: "u1"}); err != nil {
+		t.Fatalf("PublishUserEvent() error = %v", err)
+	}
+	if writer.calls != 3 || len(writer.written) != 1 {
+		t.Fatalf("calls = %d, written = %d, want 3 and 1", writer.calls, len(writer.written))
+	}
+	if len(dlq.written) != 0 {
+		t.Fatalf("dead-lettered %d messages, want none", len(dlq.written))
+	}
+}
+
+func TestPublishUserEventSendsPersistentFailuresToDLQ(t *testing.T) {
+	writer, dlq := &fakeWriter{failures: 100}, &fakeWriter{}
+	p := newTestProducer(writer, dlq, 2)
+
+	if err := p.PublishUserEvent(context.Background(), UserEvent{EventType: EventTypeUserDeleted, UserID: "u1"}); err != nil {
+		t.Fatalf("PublishUserEvent() error = %v", err)
+	}
+	if writer.calls != 3 {
+		t.Fatalf("calls = %d, want 3 (1 + 2 retries)", writer.calls)
+	}
+	if len(dlq.written) != 1 {
+		t.Fatalf("dead-lettered %d messages, want 1", len(dlq.written))
+	}
+	dead := dlq.written[0]
+	if string(dead.Key) != "u1" || header(dead, "event_type") != EventTypeUserDeleted {
+		t.Fatalf("dead-letter message = %+v", dead)
+	}
+	if header(dead, "original_topic") != "users" || header(dead, "attempts") != "3" || header(dead, "error") != "leader not available" {
+		t.Fatalf("dead-letter headers = %+v", dead.Headers)
+	}
+}
+
+func TestPublishUserEventFailsWhenDLQFails(t *testing.T) {
+	p := newTestProducer(&fakeWriter{failures: 100}, &fakeWriter{failures: 100}, 1)
+	if err := p.PublishUserEvent(context.Background(), UserEvent{EventType: EventTypeUserCreated, UserID: "u1"}); err == nil {
+		t.Fatal("PublishUserEvent() error = nil, want the write error")
+	}
+
+	// Without a dead-letter topic the error goes back to the caller (the outbox keeps the event)
+	p = newTestProducer(&fakeWriter{failures: 100}, nil, 0)
+	if err := p.PublishUserEvent(context.Background(), UserEvent{EventType: EventTypeUserCreated, UserID: "u1"}); err == nil {
+		t.Fatal("PublishUserEvent() error = nil, want the write error")
+	}
+}
+
+func TestPublishUserEventStopsWhenCanceled(t *testing.T) {
+	writer, dlq := &fakeWriter{failures: 100}, &fakeWriter{}
+	p := newTestProducer(writer, dlq, 5)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := p.PublishUserEvent(ctx, UserEvent{EventType: EventTypeUserCreated, UserID: "u1"}); err == nil {
+		t.Fatal("PublishUserEvent() error = nil, want an error")
+	}
+	if writer.calls != 1 || len(dlq.written) != 0 {
+		t.Fatalf("calls = %d, dead-lettered = %d, want 1 and 0", writer.calls, len(dlq.written))
+	}
+}
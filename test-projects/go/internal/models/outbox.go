@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OutboxMessage is a user event waiting in the outbox collection to be published to Kafka.
+// It is written in the same transaction as the user change, so an event exists if and only if
+// the change was committed.
+type OutboxMessage struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	Event       UserEvent          `bson:"event"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	PublishedAt *time.Time         `bson:"published_at,omitempty"`
+	Attempts    int                `bson:"attempts"`
+	LastError   string             `bson:"last_error,omitempty"`
+}
+
+// NewOutboxMessage wraps an event for the outbox
+func NewOutboxMessage(event UserEvent) OutboxMessage {
+	return OutboxMessage{
+		ID:        primitive.NewObjectID(),
+		Event:     event,
+		CreatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ProblemContentType is the media type of Problem responses (RFC 7807)
+const ProblemContentType = "application/problem+json"
+
+// ValidationProblemType identifies problems caused by an invalid request body or query
+const ValidationProblemType = "https://example.com/problems/validation-error"
+
+// Problem is an RFC 7807 "problem details" response
+type Problem struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	// Errors lists the invalid fields of a validation problem (an extension member)
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// FieldError is one invalid field of the request: its JSON (or query) name, the validation rule
+// it breaks (required, min, email, type...) and the rule's parameter, if any
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// FieldName is the name clients send a struct field by: json, or form for query parameters. It is the
+// tag name function of the validators, so errors name the fields as the REST and gRPC clients do.
+func FieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form"} {
+		name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// NewFieldError translates a validator error into the field, the broken rule and a readable message
+func NewFieldError(fe validator.FieldError) FieldError {
+	var message string
+	unit := ""
+	if fe.Kind() == reflect.String {
+		unit = " characters"
+	}
+	switch fe.Tag() {
+	case "required":
+		message = "is required"
+	case "min":
+		message = fmt.Sprintf("must be at least %s%s", fe.Param(), unit)
+	case "max":
+		message = fmt.Sprintf("must be at most %s%s", fe.Param(), unit)
+	case "email":
+		message = "must be a valid email address"
+	case "oneof":
+		message = "must be one of: " + strings.Join(strings.Fields(fe.Param()), ", ")
+	default:
+		message = fmt.Sprintf("does not satisfy %s", fe.Tag())
+	}
+	return FieldError{Field: fe.Field(), Rule: fe.Tag(), Param: fe.Param(), Message: message}
+}
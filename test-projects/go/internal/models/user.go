@@ -6,7 +6,8 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
-// User represents a user in the system
+// User represents a user in the system. Deleting a user only sets DeletedAt (soft delete): the
+// repository leaves such users out of every query.
 type User struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	Username  string             `json:"username" bson:"username"`
@@ -14,6 +15,11 @@ type User struct {
 	Password  string             `json:"-" bson:"password"` // Password not included in JSON responses
 	CreatedAt time.Time          `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updated_at"`
+	// Audit fields: the ID of the user who made each change (users sign themselves up)
+	CreatedBy string     `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	UpdatedBy string     `json:"updated_by,omitempty" bson:"updated_by,omitempty"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
+	DeletedBy string     `json:"deleted_by,omitempty" bson:"deleted_by,omitempty"`
 }
 
 // UserInput is the data structure for creating or updating users
@@ -23,14 +29,74 @@ type UserInput struct {
 	Password string `json:"password" binding:"required,min=6"`
 }
 
+// LoginInput is the body of POST /api/auth/login; Username also accepts the email
+type LoginInput struct {
+	Username string `json:"username" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+// LoginResponse carries the access token issued on a successful login
+type LoginResponse struct {
+	Token     string    `json:"token"`
+	TokenType string    `json:"token_type"`
+	ExpiresAt time.Time `json:"expires_at"`
+	User      User      `json:"user"`
+}
+
+// UserQuery holds the query parameters of GET /api/users: pagination, filters and sorting
+type UserQuery struct {
+	Limit         int64      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset        int64      `form:"offset" binding:"omitempty,min=0"`
+	Username      string     `form:"username" binding:"omitempty,max=30"`
+	Email         string     `form:"email" binding:"omitempty,email"`
+	CreatedAfter  *time.Time `form:"created_after" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore *time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Sort          string     `form:"sort" binding:"omitempty,oneof=username email created_at updated_at"`
+	Order         string     `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// Defaults when the query parameters are omitted
+const (
+	DefaultUserLimit = 20
+	DefaultUserSort  = "created_at"
+	DefaultUserOrder = "desc"
+)
+
+// WithDefaults fills the omitted pagination and sorting parameters
+func (q UserQuery) WithDefaults() UserQuery {
+	if q.Limit == 0 {
+		q.Limit = DefaultUserLimit
+	}
+	if q.Sort == "" {
+		q.Sort = DefaultUserSort
+	}
+	if q.Order == "" {
+		q.Order = DefaultUserOrder
+	}
+	return q
+}
+
+// UserPage is one page of users with the total matching the filters
+type UserPage struct {
+	Items  []User `json:"items"`
+	Total  int64  `json:"total"`
+	Limit  int64  `json:"limit"`
+	Offset int64  `json:"offset"`
+}
+
 // UserEvent represents an event related to a user that will be sent to Kafka
 type UserEvent struct {
-	EventID    string    `json:"event_id"`
-	EventType  string    `json:"event_type"`
-	UserID     string    `json:"user_id"`
-	Username   string    `json:"username"`
-	Email      string    `json:"email"`
-	OccurredAt time.Time `json:"occurred_at"`
+	EventID    string    `json:"event_id" bson:"event_id"`
+	EventType  string    `json:"event_type" bson:"event_type"`
+	UserID     string    `json:"user_id" bson:"user_id"`
+	Username   string    `json:"username" bson:"username"`
+	Email      string    `json:"email" bson:"email"`
+	OccurredAt time.Time `json:"occurred_at" bson:"occurred_at"`
+	// ActorID is the ID of the user who made the change
+	ActorID   string     `json:"actor_id,omitempty" bson:"actor_id,omitempty"`
+	CreatedAt time.Time  `json:"created_at" bson:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" bson:"updated_at"`
+	DeletedAt *time.Time `json:"deleted_at,omitempty" bson:"deleted_at,omitempty"`
 }
 
 // EventType constants
@@ -49,6 +115,9 @@ func NewUserCreatedEvent(user User) UserEvent {
 		Username:   user.Username,
 		Email:      user.Email,
 		OccurredAt: time.Now(),
+		ActorID:    user.CreatedBy,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
 	}
 }
 
@@ -61,10 +130,13 @@ func NewUserUpdatedEvent(user User) UserEvent {
 		Username:   user.Username,
 		Email:      user.Email,
 		OccurredAt: time.Now(),
+		ActorID:    user.UpdatedBy,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
 	}
 }
 
-// NewUserDeletedEvent creates a new UserEvent for user deletion
+// NewUserDeletedEvent creates a new UserEvent for the (soft) deletion of user
 func NewUserDeletedEvent(user User) UserEvent {
 	return UserEvent{
 		EventID:    primitive.NewObjectID().Hex(),
@@ -73,5 +145,9 @@ func NewUserDeletedEvent(user User) UserEvent {
 		Username:   user.Username,
 		Email:      user.Email,
 		OccurredAt: time.Now(),
+		ActorID:    user.DeletedBy,
+		CreatedAt:  user.CreatedAt,
+		UpdatedAt:  user.UpdatedAt,
+		DeletedAt:  user.DeletedAt,
 	}
 }
\ No newline at end of file
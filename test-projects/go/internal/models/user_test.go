@@ -0,0 +1,16 @@
+package models
+
+import "testing"
+
+func TestUserQueryWithDefaults(t *testing.T) {
+	got := UserQuery{Username: "ali"}.WithDefaults()
+	if want := (UserQuery{Username: "ali", Limit: DefaultUserLimit, Sort: "created_at", Order: "desc"}); got != want {
+		t.Fatalf("WithDefaults() = %+v, want %+v", got, want)
+	}
+
+	// Given parameters are kept
+	query := UserQuery{Limit: 5, Offset: 10, Sort: "username", Order: "asc"}
+	if got := query.WithDefaults(); got != query {
+		t.Fatalf("WithDefaults() = %+v, want %+v", got, query)
+	}
+}
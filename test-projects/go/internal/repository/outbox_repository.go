@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/example/go-sample-app/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// publishedRetention is how long published messages are kept (for troubleshooting) before the TTL index removes them
+const publishedRetention = 7 * 24 * time.Hour
+
+// OutboxRepository stores the user events waiting to be published
+type OutboxRepository struct {
+	collection *mongo.Collection
+}
+
+// NewOutboxRepository creates a new OutboxRepository
+func NewOutboxRepository(db *mongo.Database) *OutboxRepository {
+	return &OutboxRepository{
+		collection: db.Collection("outbox"),
+	}
+}
+
+// EnsureIndexes creates the index the relay polls and the TTL index that expires published messages
+func (r *OutboxRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "published_at", Value: 1}, {Key: "created_at", Value: 1}}},
+		{
+			Keys:    bson.D{{Key: "published_at", Value: 1}},
+			Options: options.Index().SetName("published_ttl").SetExpireAfterSeconds(int32(publishedRetention.Seconds())),
+		},
+	})
+	return err
+}
+
+// Add writes an event to the outbox; pass the session context to join the caller's transaction
+func (r *OutboxRepository) Add(ctx context.Context, event models.UserEvent) error {
+	_, err := r.collection.InsertOne(ctx, models.NewOutboxMessage(event))
+	return err
+}
+
+// Pending returns the oldest unpublished messages, in the order they were written
+func (r *OutboxRepository) Pending(ctx context.Context, limit int64) ([]models.OutboxMessage, error) {
+	messages := []models.OutboxMessage{}
+	opts := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}).
+		SetLimit(limit)
+
+	cursor, err := r.collection.Find(ctx, bson.M{"published_at": nil}, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// MarkPublished records that the message reached Kafka
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{"published_at": time.Now()},
+		"$inc": bson.M{"attempts": 1},
+	}
+	_, err := r.collection.UpdateByID(ctx, id, update)
+	return err
+}
+
+// MarkFailed records a failed publish attempt; the message stays pending
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id primitive.ObjectID, cause error) error {
+	update := bson.M{
+		"$set": bson.M{"last_error": cause.Error()},
+		"$inc": bson.M{"attempts": 1},
+	}
+	_, err := r.collection.UpdateByID(ctx, id, update)
+	return err
+}
@@ -0,0 +1,129 @@
+//go:build integration
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/example/go-sample-app/internal/models"
+)
+
+// startMongoDB starts a standalone MongoDB, which has no transactions, and returns a database on it
+func startMongoDB(ctx context.Context, t *testing.T) *mongo.Database {
+	t.Helper()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "mongo:7.0",
+			ExposedPorts: []string{"27017/tcp"},
+			WaitingFor:   wait.ForLog("Waiting for connections"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start MongoDB: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(context.Background()); err != nil {
+			t.Logf("Error terminating MongoDB: %v", err)
+		}
+	})
+	uri, err := container.PortEndpoint(ctx, "27017/tcp", "mongodb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	return client.Database("go_sample_app_test")
+}
+
+func TestUserChangesReachOutboxWithoutTransactions(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	db := startMongoDB(ctx, t)
+	outbox := NewOutboxRepository(db)
+	if err := outbox.EnsureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	users := NewMongoUserRepository(db, outbox)
+	if err := users.EnsureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The standalone server refuses the transaction, so each change falls back to separate writes
+	user, err := users.Create(ctx, &models.UserInput{Username: "alice", Email: "alice@example.com", Password: "secret1"})
+	if err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if _, err := users.Update(ctx, user.ID.Hex(), &models.UserInput{Username: "alice", Email: "alice@example.org", Password: "secret1"}, user.ID.Hex()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	// A rejected change writes no event
+	if _, err := users.Create(ctx, &models.UserInput{Username: "alice", Email: "other@example.com", Password: "secret1"}); !errors.Is(err, ErrUsernameTaken) {
+		t.Fatalf("Create() error = %v, want %v", err, ErrUsernameTaken)
+	}
+
+	messages, err := outbox.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Event.EventType != models.EventTypeUserCreated || messages[1].Event.EventType != models.EventTypeUserUpdated {
+		t.Fatalf("Pending() = %+v, want the created and updated events in order", messages)
+	}
+	for _, msg := range messages {
+		if msg.Event.UserID != user.ID.Hex() {
+			t.Fatalf("event %s is for user %s, want %s", msg.Event.EventType, msg.Event.UserID, user.ID.Hex())
+		}
+	}
+}
+
+func TestOutboxMarksPublishedAndFailedMessages(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	outbox := NewOutboxRepository(startMongoDB(ctx, t))
+	if err := outbox.EnsureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"u1", "u2", "u3"} {
+		if err := outbox.Add(ctx, models.UserEvent{EventType: models.EventTypeUserUpdated, UserID: id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	messages, err := outbox.Pending(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Event.UserID != "u1" || messages[1].Event.UserID != "u2" {
+		t.Fatalf("Pending(2) = %+v, want u1 and u2, oldest first", messages)
+	}
+
+	// A failed message stays pending, ahead of the newer ones, with the attempt recorded
+	if err := outbox.MarkPublished(ctx, messages[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := outbox.MarkFailed(ctx, messages[1].ID, errors.New("leader not available")); err != nil {
+		t.Fatal(err)
+	}
+	messages, err = outbox.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(messages) != 2 || messages[0].Event.UserID != "u2" || messages[1].Event.UserID != "u3" {
+		t.Fatalf("Pending() = %+v, want u2 and u3", messages)
+	}
+	if failed := messages[0]; failed.Attempts != 1 || failed.LastError != "leader not available" || failed.PublishedAt != nil {
+		t.Fatalf("failed message = %+v, want 1 attempt and its error", failed)
+	}
+}
@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"errors"
+	"log"
+	"regexp"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/example/go-sample-app/internal/models"
@@ -10,44 +14,183 @@ import (
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"golang.org/x/crypto/bcrypt"
 )
 
-// UserRepository handles database operations for users
-type UserRepository struct {
+// ErrInvalidCredentials is returned by Authenticate when the user or the password does not match
+var ErrInvalidCredentials = errors.New("invalid username or password")
+
+// ErrUsernameTaken and ErrEmailTaken are returned by Create and Update when another user already
+// has the username or the email
+var (
+	ErrUsernameTaken = errors.New("username already exists")
+	ErrEmailTaken    = errors.New("email already exists")
+)
+
+// notDeleted matches the users that were not (soft) deleted; a missing deleted_at matches too
+var notDeleted = bson.E{Key: "deleted_at", Value: nil}
+
+// Names of the unique indexes, which tell which field a duplicate key error is about
+const (
+	usernameIndex = "username_unique"
+	emailIndex    = "email_unique"
+)
+
+//go:generate mockgen -destination=mocks/mock_user_repository.go -package=mocks . UserRepository
+
+// UserRepository stores the users. Handlers depend on this interface, so their tests run against
+// the generated mocks.MockUserRepository instead of MongoDB. Deleted users are kept with their
+// deleted_at set, and no method returns them.
+type UserRepository interface {
+	// FindAll returns one page of the users matching the query, and how many match in total
+	FindAll(ctx context.Context, query models.UserQuery) ([]models.User, int64, error)
+	// FindByID returns the user, or nil when there is none with that ID
+	FindByID(ctx context.Context, id string) (*models.User, error)
+	Create(ctx context.Context, input *models.UserInput) (*models.User, error)
+	// Update changes the user on behalf of actorID, the authenticated user
+	Update(ctx context.Context, id string, input *models.UserInput, actorID string) (*models.User, error)
+	// Delete marks the user as deleted by actorID
+	Delete(ctx context.Context, id, actorID string) error
+	// Authenticate returns the user whose username or email and password match, or ErrInvalidCredentials
+	Authenticate(ctx context.Context, login, password string) (*models.User, error)
+}
+
+// MongoUserRepository is the UserRepository on MongoDB. Every change also writes its user event
+// to the outbox, in the same transaction.
+type MongoUserRepository struct {
 	collection *mongo.Collection
+	outbox     *OutboxRepository
+	client     *mongo.Client
+	// warnOnce logs once that the server has no transactions
+	warnOnce sync.Once
 }
 
-// NewUserRepository creates a new UserRepository
-func NewUserRepository(db *mongo.Database) *UserRepository {
-	return &UserRepository{
+var _ UserRepository = (*MongoUserRepository)(nil)
+
+// NewMongoUserRepository creates a new MongoUserRepository that records its events in outbox
+func NewMongoUserRepository(db *mongo.Database, outbox *OutboxRepository) *MongoUserRepository {
+	return &MongoUserRepository{
 		collection: db.Collection("users"),
+		outbox:     outbox,
+		client:     db.Client(),
+	}
+}
+
+// EnsureIndexes creates the unique indexes on username and email. They are what keeps two users
+// from sharing either one, even when both are registered at the same time, so the app does not
+// start without them. Deleted users keep their username and email reserved.
+func (r *MongoUserRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "username", Value: 1}}, Options: options.Index().SetName(usernameIndex).SetUnique(true)},
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetName(emailIndex).SetUnique(true)},
+	})
+	return err
+}
+
+// uniqueViolation turns a duplicate key error on one of the unique indexes into ErrUsernameTaken
+// or ErrEmailTaken; other errors are returned as they are
+func uniqueViolation(err error) error {
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	switch {
+	case strings.Contains(err.Error(), usernameIndex):
+		return ErrUsernameTaken
+	case strings.Contains(err.Error(), emailIndex):
+		return ErrEmailTaken
+	}
+	return err
+}
+
+// inTransaction runs fn in a MongoDB transaction, so a user change and its outbox event are
+// committed together. Standalone servers (like a plain local container) have no transactions:
+// fn then runs without one, and a crash between its writes may lose the event.
+func (r *MongoUserRepository) inTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	session, err := r.client.StartSession()
+	if err != nil {
+		return err
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sessCtx mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sessCtx)
+	})
+	if !transactionsUnsupported(err) {
+		return err
 	}
+	r.warnOnce.Do(func() {
+		log.Printf("MongoDB without transactions (not a replica set); writing users and outbox events separately")
+	})
+	return fn(ctx)
 }
 
-// FindAll retrieves all users from the database
-func (r *UserRepository) FindAll(ctx context.Context) ([]models.User, error) {
-	var users []models.User
+// transactionsUnsupported reports whether err is the server refusing transactions (IllegalOperation)
+func transactionsUnsupported(err error) bool {
+	var cmdErr mongo.CommandError
+	return errors.As(err, &cmdErr) && cmdErr.Code == 20
+}
 
-	// Define options to sort by creation date, newest first
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+// FindAll retrieves one page of the users matching the query filters, and how many match in total
+func (r *MongoUserRepository) FindAll(ctx context.Context, query models.UserQuery) ([]models.User, int64, error) {
+	users := []models.User{}
+	filter := userFilter(query)
+
+	// Count every match so clients can page through the results
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Sort by the requested field, with the ID as tie-breaker so pages are stable
+	direction := 1
+	if query.Order == "desc" {
+		direction = -1
+	}
+	opts := options.Find().
+		SetSort(bson.D{{Key: query.Sort, Value: direction}, {Key: "_id", Value: direction}}).
+		SetSkip(query.Offset).
+		SetLimit(query.Limit)
 
 	// Execute the query
-	cursor, err := r.collection.Find(ctx, bson.M{}, opts)
+	cursor, err := r.collection.Find(ctx, filter, opts)
 	if err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 	defer cursor.Close(ctx)
 
 	// Decode results
 	if err := cursor.All(ctx, &users); err != nil {
-		return nil, err
+		return nil, 0, err
 	}
 
-	return users, nil
+	return users, total, nil
+}
+
+// userFilter builds the MongoDB filter of a user query: username prefix (case-insensitive),
+// exact email and creation date range, among the users not deleted
+func userFilter(query models.UserQuery) bson.M {
+	filter := bson.M{notDeleted.Key: notDeleted.Value}
+	if query.Username != "" {
+		filter["username"] = primitive.Regex{Pattern: "^" + regexp.QuoteMeta(query.Username), Options: "i"}
+	}
+	if query.Email != "" {
+		filter["email"] = query.Email
+	}
+	created := bson.M{}
+	if query.CreatedAfter != nil {
+		created["$gte"] = *query.CreatedAfter
+	}
+	if query.CreatedBefore != nil {
+		created["$lt"] = *query.CreatedBefore
+	}
+	if len(created) > 0 {
+		filter["created_at"] = created
+	}
+	return filter
 }
 
 // FindByID retrieves a user by ID
-func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
+func (r *MongoUserRepository) FindByID(ctx context.Context, id string) (*models.User, error) {
 	var user models.User
 
 	// Convert string ID to ObjectID
@@ -56,8 +199,8 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.User,
 		return nil, err
 	}
 
-	// Find user by ID
-	err = r.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
+	// Find user by ID; a deleted user is not found
+	err = r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: objectID}, notDeleted}).Decode(&user)
 	if err != nil {
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, nil // User not found
@@ -69,42 +212,42 @@ func (r *UserRepository) FindByID(ctx context.Context, id string) (*models.User,
 }
 
 // Create inserts a new user into the database
-func (r *UserRepository) Create(ctx context.Context, input *models.UserInput) (*models.User, error) {
-	// Check if username or email already exists
-	if exists, err := r.existsByField(ctx, "username", input.Username); err != nil {
-		return nil, err
-	} else if exists {
-		return nil, errors.New("username already exists")
-	}
-
-	if exists, err := r.existsByField(ctx, "email", input.Email); err != nil {
+func (r *MongoUserRepository) Create(ctx context.Context, input *models.UserInput) (*models.User, error) {
+	hash, err := hashPassword(input.Password)
+	if err != nil {
 		return nil, err
-	} else if exists {
-		return nil, errors.New("email already exists")
 	}
 
-	// Create new user
+	// Create new user; sign-up is not authenticated, so the user is its own creator
 	now := time.Now()
+	id := primitive.NewObjectID()
 	user := models.User{
-		ID:        primitive.NewObjectID(),
+		ID:        id,
 		Username:  input.Username,
 		Email:     input.Email,
-		Password:  input.Password, // In a real app, would hash this password
+		Password:  hash,
 		CreatedAt: now,
 		UpdatedAt: now,
+		CreatedBy: id.Hex(),
+		UpdatedBy: id.Hex(),
 	}
 
-	// Insert into database
-	_, err := r.collection.InsertOne(ctx, user)
+	// Insert the user and its created event together; the unique indexes reject a taken username or email
+	err = r.inTransaction(ctx, func(ctx context.Context) error {
+		if _, err := r.collection.InsertOne(ctx, user); err != nil {
+			return err
+		}
+		return r.outbox.Add(ctx, models.NewUserCreatedEvent(user))
+	})
 	if err != nil {
-		return nil, err
+		return nil, uniqueViolation(err)
 	}
 
 	return &user, nil
 }
 
-// Update updates an existing user
-func (r *UserRepository) Update(ctx context.Context, id string, input *models.UserInput) (*models.User, error) {
+// Update updates an existing user on behalf of actorID
+func (r *MongoUserRepository) Update(ctx context.Context, id string, input *models.UserInput, actorID string) (*models.User, error) {
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -120,22 +263,9 @@ func (r *UserRepository) Update(ctx context.Context, id string, input *models.Us
 		return nil, errors.New("user not found")
 	}
 
-	// Check username uniqueness if changed
-	if input.Username != existingUser.Username {
-		if exists, err := r.existsByField(ctx, "username", input.Username); err != nil {
-			return nil, err
-		} else if exists {
-			return nil, errors.New("username already exists")
-		}
-	}
-
-	// Check email uniqueness if changed
-	if input.Email != existingUser.Email {
-		if exists, err := r.existsByField(ctx, "email", input.Email); err != nil {
-			return nil, err
-		} else if exists {
-			return nil, errors.New("email already exists")
-		}
+	hash, err := hashPassword(input.Password)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update the user
@@ -143,60 +273,99 @@ func (r *UserRepository) Update(ctx context.Context, id string, input *models.Us
 		"$set": bson.M{
 			"username":   input.Username,
 			"email":      input.Email,
-			"password":   input.Password, // In a real app, would hash this password
+			"password":   hash,
 			"updated_at": time.Now(),
+			"updated_by": actorID,
 		},
 	}
 
-	// Execute update
-	result := r.collection.FindOneAndUpdate(
-		ctx,
-		bson.M{"_id": objectID},
-		update,
-		options.FindOneAndUpdate().SetReturnDocument(options.After),
-	)
-
-	// Check for errors
-	if result.Err() != nil {
-		return nil, result.Err()
-	}
-
-	// Decode updated user
+	// Execute the update and record its event together; the unique indexes reject a taken username or email
 	var updatedUser models.User
-	if err := result.Decode(&updatedUser); err != nil {
-		return nil, err
+	err = r.inTransaction(ctx, func(ctx context.Context) error {
+		result := r.collection.FindOneAndUpdate(
+			ctx,
+			bson.D{{Key: "_id", Value: objectID}, notDeleted},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		)
+		if err := result.Decode(&updatedUser); err != nil {
+			return err
+		}
+		return r.outbox.Add(ctx, models.NewUserUpdatedEvent(updatedUser))
+	})
+	if err != nil {
+		return nil, uniqueViolation(err)
 	}
 
 	return &updatedUser, nil
 }
 
-// Delete removes a user from the database
-func (r *UserRepository) Delete(ctx context.Context, id string) error {
+// Delete soft-deletes a user: it stays in the database with deleted_at and deleted_by set
+func (r *MongoUserRepository) Delete(ctx context.Context, id, actorID string) error {
 	// Convert string ID to ObjectID
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
 		return err
 	}
 
-	// Execute delete
-	result, err := r.collection.DeleteOne(ctx, bson.M{"_id": objectID})
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"deleted_at": now,
+			"deleted_by": actorID,
+			"updated_at": now,
+			"updated_by": actorID,
+		},
+	}
+
+	// Mark the user as deleted and record its deleted event together
+	return r.inTransaction(ctx, func(ctx context.Context) error {
+		var deleted models.User
+		err := r.collection.FindOneAndUpdate(
+			ctx,
+			bson.D{{Key: "_id", Value: objectID}, notDeleted},
+			update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&deleted)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return errors.New("user not found")
+		}
+		if err != nil {
+			return err
+		}
+		return r.outbox.Add(ctx, models.NewUserDeletedEvent(deleted))
+	})
+}
+
+// Authenticate finds the user by username or email, among those not deleted, and checks the password against its bcrypt hash
+func (r *MongoUserRepository) Authenticate(ctx context.Context, login, password string) (*models.User, error) {
+	var user models.User
+	filter := bson.D{{Key: "$or", Value: bson.A{bson.M{"username": login}, bson.M{"email": login}}}, notDeleted}
+	err := r.collection.FindOne(ctx, filter).Decode(&user)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// Compare anyway so unknown users take as long as wrong passwords
+		_ = bcrypt.CompareHashAndPassword(dummyHash, []byte(password))
+		return nil, ErrInvalidCredentials
+	}
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Check if user was found and deleted
-	if result.DeletedCount == 0 {
-		return errors.New("user not found")
+	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
+		return nil, ErrInvalidCredentials
 	}
 
-	return nil
+	return &user, nil
 }
 
-// existsByField checks if a user exists with the given field value
-func (r *UserRepository) existsByField(ctx context.Context, field, value string) (bool, error) {
-	count, err := r.collection.CountDocuments(ctx, bson.M{field: value})
+// dummyHash is compared against when the user does not exist
+var dummyHash, _ = bcrypt.GenerateFromPassword([]byte("dummy-password"), bcrypt.DefaultCost)
+
+// hashPassword returns the bcrypt hash stored in place of the password
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
-		return false, err
+		return "", err
 	}
-	return count > 0, nil
-}
\ No newline at end of file
+	return string(hash), nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/example/go-sample-app/internal/models"
+)
+
+func duplicateKey(index string) error {
+	return mongo.WriteException{WriteErrors: []mongo.WriteError{{
+		Code:    11000,
+		Message: "E11000 duplicate key error collection: go_sample_app.users index: " + index + " dup key: { ... }",
+	}}}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	other := errors.New("connection reset")
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"username", duplicateKey(usernameIndex), ErrUsernameTaken},
+		{"email", duplicateKey(emailIndex), ErrEmailTaken},
+		{"other error", other, other},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := uniqueViolation(tt.err); !errors.Is(got, tt.want) {
+				t.Fatalf("uniqueViolation() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// A duplicate _id is not about the username or the email
+	got := uniqueViolation(duplicateKey("_id_"))
+	if errors.Is(got, ErrUsernameTaken) || errors.Is(got, ErrEmailTaken) || !mongo.IsDuplicateKeyError(got) {
+		t.Fatalf("uniqueViolation() = %v, want the duplicate key error unchanged", got)
+	}
+}
+
+func TestUserFilterLeavesOutDeletedUsers(t *testing.T) {
+	for _, query := range []models.UserQuery{{}, {Username: "ali", Email: "alice@example.com"}} {
+		filter := userFilter(query)
+		if value, ok := filter["deleted_at"]; !ok || value != nil {
+			t.Fatalf("userFilter(%+v) = %v, want deleted_at: nil", query, filter)
+		}
+	}
+}
+
+func TestUserFilter(t *testing.T) {
+	after, before := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name  string
+		query models.UserQuery
+		want  bson.M
+	}{
+		{"no filters", models.UserQuery{}, bson.M{"deleted_at": nil}},
+		{
+			"username prefix, quoted and case-insensitive",
+			models.UserQuery{Username: "a.b"},
+			bson.M{"deleted_at": nil, "username": primitive.Regex{Pattern: `^a\.b`, Options: "i"}},
+		},
+		{"exact email", models.UserQuery{Email: "alice@example.com"}, bson.M{"deleted_at": nil, "email": "alice@example.com"}},
+		{"created after", models.UserQuery{CreatedAfter: &after}, bson.M{"deleted_at": nil, "created_at": bson.M{"$gte": after}}},
+		{
+			"created range",
+			models.UserQuery{CreatedAfter: &after, CreatedBefore: &before},
+			bson.M{"deleted_at": nil, "created_at": bson.M{"$gte": after, "$lt": before}},
+		},
+		{
+			"pagination and sorting are not filters",
+			models.UserQuery{Limit: 5, Offset: 10, Sort: "username", Order: "asc"},
+			bson.M{"deleted_at": nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := userFilter(tt.query); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("userFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// MongoMonitor returns a command monitor that records every MongoDB command as a client span,
+// child of the span in the command's context (e.g. the HTTP request that issued it)
+func MongoMonitor() *event.CommandMonitor {
+	// Spans in flight, by the request ID the driver gives to the command
+	var spans sync.Map
+
+	finish := func(requestID int64, err string) {
+		value, ok := spans.LoadAndDelete(requestID)
+		if !ok {
+			return
+		}
+		span := value.(trace.Span)
+		if err != "" {
+			span.SetStatus(codes.Error, err)
+		}
+		span.End()
+	}
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			attrs := []attribute.KeyValue{
+				semconv.DBSystemMongoDB,
+				semconv.DBName(evt.DatabaseName),
+				semconv.DBOperation(evt.CommandName),
+			}
+			// The collection is the value of the command's first element (e.g. {find: "users"})
+			name := evt.CommandName
+			if first, err := evt.Command.IndexErr(0); err == nil {
+				if collection, ok := first.Value().StringValueOK(); ok {
+					attrs = append(attrs, semconv.DBMongoDBCollection(collection))
+					name += " " + collection
+				}
+			}
+			_, span := tracer().Start(ctx, name,
+				trace.WithSpanKind(trace.SpanKindClient),
+				trace.WithAttributes(attrs...),
+			)
+			spans.Store(evt.RequestID, span)
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finish(evt.RequestID, "")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finish(evt.RequestID, evt.Failure)
+		},
+	}
+}
@@ -0,0 +1,65 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/example/go-sample-app/internal/config"
+)
+
+// instrumentationName identifies the spans created by this app (Mongo commands, Kafka messages)
+const instrumentationName = "github.com/example/go-sample-app"
+
+// serviceName is the service.name of every span, also used by the Gin middleware
+var serviceName string
+
+// Setup installs the global tracer provider, exporting spans in batches over OTLP/HTTP to the
+// collector at cfg.Endpoint (OTEL_EXPORTER_OTLP_ENDPOINT), and the W3C trace context propagator. The returned
+// function flushes the pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg config.Tracing) (func(context.Context) error, error) {
+	serviceName = cfg.ServiceName
+
+	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(cfg.Endpoint))
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+	return provider.Shutdown, nil
+}
+
+// Middleware starts a server span per request, named after the route template, and continues
+// the trace of callers that send a traceparent header
+func Middleware() gin.HandlerFunc {
+	return otelgin.Middleware(serviceName)
+}
+
+// tracer returns the tracer of the global provider, so spans started before Setup are no-ops
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
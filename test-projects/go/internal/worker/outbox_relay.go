@@ -0,0 +1,152 @@
+package worker
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+//go:generate mockgen -destination=mocks/mock_outbox_relay.go -package=mocks . Outbox,Publisher
+
+// Outbox is the queue of user events the relay drains (repository.OutboxRepository)
+type Outbox interface {
+	Pending(ctx context.Context, limit int64) ([]models.OutboxMessage, error)
+	MarkPublished(ctx context.Context, id primitive.ObjectID) error
+	MarkFailed(ctx context.Context, id primitive.ObjectID, cause error) error
+}
+
+// Publisher sends the relayed events to Kafka (models.EventProducer)
+type Publisher interface {
+	PublishUserEvent(ctx context.Context, event models.UserEvent) error
+}
+
+var (
+	_ Outbox    = (*repository.OutboxRepository)(nil)
+	_ Publisher = (*models.EventProducer)(nil)
+)
+
+// maxOutageWait bounds the wait between two attempts while Kafka is unavailable
+const maxOutageWait = 30 * time.Second
+
+// OutboxRelay publishes the pending outbox messages to Kafka. Delivery is at-least-once: a crash
+// between publishing and marking a message publishes it again, so consumers dedupe by event_id.
+// While Kafka is unavailable the relay runs degraded: the messages stay queued in the outbox and
+// are retried with a growing wait, and only the start and the end of the outage are logged as such.
+type OutboxRelay struct {
+	outbox   Outbox
+	producer Publisher
+	interval time.Duration
+	batch    int64
+	outage   outage
+}
+
+// NewOutboxRelay creates a relay that polls the outbox every interval for up to batch messages
+func NewOutboxRelay(outbox Outbox, producer Publisher, interval time.Duration, batch int64) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:   outbox,
+		producer: producer,
+		interval: interval,
+		batch:    batch,
+		outage:   outage{minWait: interval, maxWait: maxOutageWait},
+	}
+}
+
+// Run relays messages until ctx is canceled
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if !r.outage.due(time.Now()) {
+				continue
+			}
+			publishErr, err := r.relayBatch(ctx)
+			switch {
+			case ctx.Err() != nil:
+			case err != nil:
+				log.Printf("Error relaying outbox: %v", err)
+			case publishErr != nil:
+				if r.outage.fail(time.Now()) {
+					log.Printf("Warning: Kafka is unavailable, running in degraded mode: user events stay queued in the outbox: %v", publishErr)
+				} else {
+					log.Printf("Kafka is still unavailable, retrying in %s: %v", r.outage.wait, publishErr)
+				}
+			default:
+				if downFor, ended := r.outage.end(time.Now()); ended {
+					log.Printf("Kafka is available again after %s; relaying the queued user events", downFor.Round(time.Second))
+				}
+			}
+		}
+	}
+}
+
+// relayBatch publishes one batch in order. It stops at the first failure so that a user's events
+// are never published out of order; the failed message is retried on the next attempt. A failed
+// publish is returned apart from the errors of the outbox itself.
+func (r *OutboxRelay) relayBatch(ctx context.Context) (publishErr, err error) {
+	messages, err := r.outbox.Pending(ctx, r.batch)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, msg := range messages {
+		if err := r.producer.PublishUserEvent(ctx, msg.Event); err != nil {
+			// Record the attempt even if ctx was canceled mid-publish
+			if markErr := r.outbox.MarkFailed(context.WithoutCancel(ctx), msg.ID, err); markErr != nil {
+				log.Printf("Error recording failed outbox message %s: %v", msg.ID.Hex(), markErr)
+			}
+			return err, nil
+		}
+		if err := r.outbox.MarkPublished(context.WithoutCancel(ctx), msg.ID); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// outage tracks a Kafka outage seen by the relay: while it lasts, the relay tries again after a
+// wait that starts at minWait and doubles on each failure, up to maxWait, instead of on every tick
+type outage struct {
+	// since is when the outage started; zero while Kafka is available
+	since   time.Time
+	next    time.Time
+	wait    time.Duration
+	minWait time.Duration
+	maxWait time.Duration
+}
+
+// due reports whether the relay should try to publish at now
+func (o *outage) due(now time.Time) bool {
+	return o.since.IsZero() || !now.Before(o.next)
+}
+
+// fail records a failed attempt at now and reports whether it started the outage
+func (o *outage) fail(now time.Time) bool {
+	started := o.since.IsZero()
+	if started {
+		o.since = now
+		o.wait = o.minWait
+	} else {
+		o.wait = min(o.wait*2, max(o.maxWait, o.minWait))
+	}
+	o.next = now.Add(o.wait)
+	return started
+}
+
+// end records a successful attempt at now and returns how long the outage lasted, if there was one
+func (o *outage) end(now time.Time) (time.Duration, bool) {
+	if o.since.IsZero() {
+		return 0, false
+	}
+	downFor := now.Sub(o.since)
+	*o = outage{minWait: o.minWait, maxWait: o.maxWait}
+	return downFor, true
+}
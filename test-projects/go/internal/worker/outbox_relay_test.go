@@ -0,0 +1,140 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.uber.org/mock/gomock"
+
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/worker/mocks"
+)
+
+func TestOutageBacksOffUntilKafkaIsBack(t *testing.T) {
+	o := outage{minWait: time.Second, maxWait: 3 * time.Second}
+	start := time.Unix(1000, 0)
+	if !o.due(start) {
+		t.Fatal("without an outage every tick is an attempt")
+	}
+
+	if !o.fail(start) {
+		t.Fatal("the first failure starts the outage")
+	}
+	if o.due(start.Add(500 * time.Millisecond)) {
+		t.Fatal("no attempt before the wait is over")
+	}
+	if o.fail(start.Add(time.Second)) || o.wait != 2*time.Second {
+		t.Fatalf("the next failure doubles the wait, got %s", o.wait)
+	}
+	o.fail(start.Add(3 * time.Second))
+	if o.wait != 3*time.Second {
+		t.Fatalf("the wait is capped at maxWait, got %s", o.wait)
+	}
+	if !o.due(start.Add(6 * time.Second)) {
+		t.Fatal("an attempt is due once the wait is over")
+	}
+
+	downFor, ended := o.end(start.Add(6 * time.Second))
+	if !ended || downFor != 6*time.Second {
+		t.Fatalf("end = %s, %v; want 6s, true", downFor, ended)
+	}
+	if _, ended := o.end(start.Add(7 * time.Second)); ended || !o.due(start.Add(7*time.Second)) {
+		t.Fatal("after the outage the relay is back to every tick")
+	}
+}
+
+func newTestRelay(t *testing.T) (*OutboxRelay, *mocks.MockOutbox, *mocks.MockPublisher) {
+	ctrl := gomock.NewController(t)
+	outbox, producer := mocks.NewMockOutbox(ctrl), mocks.NewMockPublisher(ctrl)
+	return NewOutboxRelay(outbox, producer, time.Second, 10), outbox, producer
+}
+
+func pending(userIDs ...string) []models.OutboxMessage {
+	messages := make([]models.OutboxMessage, len(userIDs))
+	for i, id := range userIDs {
+		messages[i] = models.NewOutboxMessage(models.UserEvent{EventType: models.EventTypeUserUpdated, UserID: id})
+	}
+	return messages
+}
+
+func TestRelayBatchPublishesInOrder(t *testing.T) {
+	relay, outbox, producer := newTestRelay(t)
+	messages := pending("u1", "u1", "u2")
+
+	calls := []any{outbox.EXPECT().Pending(gomock.Any(), int64(10)).Return(messages, nil)}
+	for _, msg := range messages {
+		calls = append(calls,
+			producer.EXPECT().PublishUserEvent(gomock.Any(), msg.Event).Return(nil),
+			outbox.EXPECT().MarkPublished(gomock.Any(), msg.ID).Return(nil),
+		)
+	}
+	gomock.InOrder(calls...)
+
+	if publishErr, err := relay.relayBatch(context.Background()); publishErr != nil || err != nil {
+		t.Fatalf("relayBatch() = %v, %v; want nil, nil", publishErr, err)
+	}
+}
+
+func TestRelayBatchStopsAtFirstFailure(t *testing.T) {
+	relay, outbox, producer := newTestRelay(t)
+	messages := pending("u1", "u1", "u1")
+	unavailable := errors.New("leader not available")
+
+	// The third message is neither published nor marked: it would overtake the second one
+	gomock.InOrder(
+		outbox.EXPECT().Pending(gomock.Any(), int64(10)).Return(messages, nil),
+		producer.EXPECT().PublishUserEvent(gomock.Any(), messages[0].Event).Return(nil),
+		outbox.EXPECT().MarkPublished(gomock.Any(), messages[0].ID).Return(nil),
+		producer.EXPECT().PublishUserEvent(gomock.Any(), messages[1].Event).Return(unavailable),
+		outbox.EXPECT().MarkFailed(gomock.Any(), messages[1].ID, unavailable).Return(nil),
+	)
+
+	publishErr, err := relay.relayBatch(context.Background())
+	if !errors.Is(publishErr, unavailable) || err != nil {
+		t.Fatalf("relayBatch() = %v, %v; want %v, nil", publishErr, err, unavailable)
+	}
+}
+
+func TestRelayBatchRecordsFailureAfterCancel(t *testing.T) {
+	relay, outbox, producer := newTestRelay(t)
+	messages := pending("u1")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	outbox.EXPECT().Pending(gomock.Any(), int64(10)).Return(messages, nil)
+	producer.EXPECT().PublishUserEvent(gomock.Any(), messages[0].Event).DoAndReturn(func(context.Context, models.UserEvent) error {
+		cancel()
+		return context.Canceled
+	})
+	outbox.EXPECT().MarkFailed(gomock.Any(), messages[0].ID, context.Canceled).DoAndReturn(func(ctx context.Context, _ primitive.ObjectID, _ error) error {
+		return ctx.Err()
+	})
+
+	if publishErr, err := relay.relayBatch(ctx); !errors.Is(publishErr, context.Canceled) || err != nil {
+		t.Fatalf("relayBatch() = %v, %v; want context canceled, nil", publishErr, err)
+	}
+}
+
+func TestRelayBatchReturnsOutboxErrors(t *testing.T) {
+	down := errors.New("connection reset")
+
+	relay, outbox, _ := newTestRelay(t)
+	outbox.EXPECT().Pending(gomock.Any(), int64(10)).Return(nil, down)
+	if publishErr, err := relay.relayBatch(context.Background()); publishErr != nil || !errors.Is(err, down) {
+		t.Fatalf("relayBatch() = %v, %v; want nil, %v", publishErr, err, down)
+	}
+
+	// A message that was published but could not be marked stops the batch as well
+	relay, outbox, producer := newTestRelay(t)
+	messages := pending("u1", "u2")
+	gomock.InOrder(
+		outbox.EXPECT().Pending(gomock.Any(), int64(10)).Return(messages, nil),
+		producer.EXPECT().PublishUserEvent(gomock.Any(), messages[0].Event).Return(nil),
+		outbox.EXPECT().MarkPublished(gomock.Any(), messages[0].ID).Return(down),
+	)
+	if publishErr, err := relay.relayBatch(context.Background()); publishErr != nil || !errors.Is(err, down) {
+		t.Fatalf("relayBatch() = %v, %v; want nil, %v", publishErr, err, down)
+	}
+}
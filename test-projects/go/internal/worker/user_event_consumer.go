@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/tracing"
+)
+
+// HandlerFunc processes one user event; an error stops the consumer without committing the event
+type HandlerFunc func(ctx context.Context, event models.UserEvent) error
+
+// UserEventConsumer reads user events from Kafka as part of a consumer group
+type UserEventConsumer struct {
+	reader *kafka.Reader
+	handle HandlerFunc
+}
+
+// NewUserEventConsumer creates a consumer that passes each event of the reader to handle
+func NewUserEventConsumer(reader *kafka.Reader, handle HandlerFunc) *UserEventConsumer {
+	return &UserEventConsumer{
+		reader: reader,
+		handle: handle,
+	}
+}
+
+// Run consumes events until ctx is canceled. Offsets are committed only after an event was
+// handled, so an event interrupted by a shutdown or a crash is delivered again on restart.
+func (c *UserEventConsumer) Run(ctx context.Context) error {
+	for {
+		msg, err := c.reader.FetchMessage(ctx)
+		if errors.Is(err, context.Canceled) || errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("fetching user event: %w", err)
+		}
+
+		// Process the event in a span that continues the trace of the request that produced it
+		spanCtx, span := tracing.StartConsume(ctx, c.reader.Config().GroupID, &msg)
+		var event models.UserEvent
+		if err := json.Unmarshal(msg.Value, &event); err != nil {
+			// A malformed event would never succeed, so skip it instead of blocking the partition
+			log.Printf("Skipping malformed user event at %s/%d offset %d: %v", msg.Topic, msg.Partition, msg.Offset, err)
+			tracing.End(span, err)
+		} else if err := c.handle(spanCtx, event); err != nil {
+			tracing.End(span, err)
+			return fmt.Errorf("handling user event %s: %w", event.EventID, err)
+		} else {
+			tracing.End(span, nil)
+		}
+
+		// Commit even when ctx was canceled meanwhile: the event is done
+		if err := c.reader.CommitMessages(context.WithoutCancel(ctx), msg); err != nil {
+			return fmt.Errorf("committing offset %d: %w", msg.Offset, err)
+		}
+	}
+}
+
+// Close leaves the consumer group and closes the Kafka reader
+func (c *UserEventConsumer) Close() error {
+	return c.reader.Close()
+}
+
+// LogUserEvent is the default handler: it logs the events published by the API
+func LogUserEvent(_ context.Context, event models.UserEvent) error {
+	log.Printf("Consumed %s event for user %s (%s)", event.EventType, event.UserID, event.EventID)
+	return nil
+}
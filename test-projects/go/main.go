@@ -2,8 +2,8 @@ package main
 
 import (
 	"context"
-	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -17,9 +17,17 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
 
+	"github.com/example/go-sample-app/internal/auth"
+	"github.com/example/go-sample-app/internal/config"
+	"github.com/example/go-sample-app/internal/events"
+	"github.com/example/go-sample-app/internal/grpcserver"
 	"github.com/example/go-sample-app/internal/handlers"
+	"github.com/example/go-sample-app/internal/metrics"
+	"github.com/example/go-sample-app/internal/middleware"
 	"github.com/example/go-sample-app/internal/models"
 	"github.com/example/go-sample-app/internal/repository"
+	"github.com/example/go-sample-app/internal/tracing"
+	"github.com/example/go-sample-app/internal/worker"
 )
 
 func main() {
@@ -28,12 +36,31 @@ func main() {
 		log.Printf("Warning: Error loading .env file: %v", err)
 	}
 
+	// Read and validate every setting before connecting to anything
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// Setup context with cancellation for graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Export traces to the OpenTelemetry collector; spans are flushed on shutdown
+	shutdownTracing, err := tracing.Setup(ctx, cfg.Tracing)
+	if err != nil {
+		log.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer func() {
+		flushCtx, flushCancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer flushCancel()
+		if err := shutdownTracing(flushCtx); err != nil {
+			log.Printf("Error flushing traces: %v", err)
+		}
+	}()
+
 	// Connect to MongoDB
-	mongoClient, err := connectToMongoDB(ctx)
+	mongoClient, err := connectToMongoDB(ctx, cfg.Mongo)
 	if err != nil {
 		log.Fatalf("Failed to connect to MongoDB: %v", err)
 	}
@@ -41,68 +68,99 @@ func main() {
 	log.Println("Connected to MongoDB")
 
 	// Get MongoDB database
-	dbName := os.Getenv("MONGODB_DATABASE")
-	if dbName == "" {
-		dbName = "go_sample_app"
+	db := mongoClient.Database(cfg.Mongo.Database)
+
+	// Initialize repositories; user changes record their events in the outbox
+	outboxRepo := repository.NewOutboxRepository(db)
+	if err := outboxRepo.EnsureIndexes(ctx); err != nil {
+		log.Printf("Warning: Error creating outbox indexes: %v", err)
+	}
+	userRepo := repository.NewMongoUserRepository(db, outboxRepo)
+	if err := userRepo.EnsureIndexes(ctx); err != nil {
+		log.Fatalf("Failed to create user indexes: %v", err)
 	}
-	db := mongoClient.Database(dbName)
 
-	// Initialize repositories
-	userRepo := repository.NewUserRepository(db)
+	// The readiness probe checks the first broker
+	healthHandler := handlers.NewHealthHandler(mongoClient, cfg.Kafka.Brokers[0], cfg.Kafka.Required)
 
-	// Connect to Kafka
-	kafkaWriter := connectToKafka()
+	// Connect to Kafka. It is optional unless KAFKA_REQUIRED: when it is down the app starts in
+	// degraded mode, with the user events queued in the outbox until the relay can publish them
+	kafkaWriter := connectToKafka(cfg.Kafka)
 	defer kafkaWriter.Close()
-	log.Println("Connected to Kafka")
-
-	// Initialize Kafka event producer
-	eventProducer := models.NewEventProducer(kafkaWriter)
+	dlqWriter := connectToKafkaDLQ(cfg.Kafka)
+	defer dlqWriter.Close()
+	if err := healthHandler.PingKafka(ctx); err != nil {
+		if cfg.Kafka.Required {
+			log.Fatalf("Failed to connect to Kafka: %v", err)
+		}
+		log.Printf("Warning: Kafka is unavailable, starting in degraded mode: user events stay queued in the outbox: %v", err)
+	} else {
+		log.Println("Connected to Kafka")
+	}
 
-	// Setup Gin router
-	router := gin.Default()
-	router.Use(gin.Recovery())
+	// Initialize Kafka event producer; events that keep failing go to the dead-letter topic
+	eventProducer := models.NewEventProducer(kafkaWriter, kafkaProducerConfig(cfg.Kafka, dlqWriter))
 
-	// Initialize handlers
-	userHandler := handlers.NewUserHandler(userRepo, eventProducer)
+	// Start the relay that publishes the outbox to Kafka; it stops when ctx is canceled
+	relay := worker.NewOutboxRelay(outboxRepo, eventProducer, time.Second, 100)
+	relayDone := make(chan struct{})
+	go func() {
+		defer close(relayDone)
+		relay.Run(ctx)
+	}()
 
-	// Define routes
-	router.GET("/", func(c *gin.Context) {
-		c.JSON(http.StatusOK, gin.H{
-			"message": "Go Sample App with MongoDB and Kafka",
-		})
+	// Start the consumer of the users topic, which logs each event and relays it to the clients of
+	// /api/events; it stops when ctx is canceled
+	broker := events.NewBroker()
+	consumer := worker.NewUserEventConsumer(connectToKafkaReader(cfg.Kafka), func(ctx context.Context, event models.UserEvent) error {
+		if err := worker.LogUserEvent(ctx, event); err != nil {
+			return err
+		}
+		broker.Publish(event)
+		return nil
 	})
-
-	// User routes
-	api := router.Group("/api")
-	{
-		users := api.Group("/users")
-		{
-			users.GET("", userHandler.GetAllUsers)
-			users.GET("/:id", userHandler.GetUserByID)
-			users.POST("", userHandler.CreateUser)
-			users.PUT("/:id", userHandler.UpdateUser)
-			users.DELETE("/:id", userHandler.DeleteUser)
+	consumerDone := make(chan struct{})
+	go func() {
+		defer close(consumerDone)
+		if err := consumer.Run(ctx); err != nil {
+			log.Printf("User event consumer stopped: %v", err)
 		}
-	}
+	}()
+
+	// Secret used to sign the login tokens
+	tokens := auth.NewTokenIssuer(cfg.JWTSecret, time.Hour)
+
+	router := newRouter(userRepo, tokens, healthHandler, broker, cfg.HTTP)
 
 	// Start the server
-	port := os.Getenv("APP_PORT")
-	if port == "" {
-		port = "8080"
-	}
 	srv := &http.Server{
-		Addr:    ":" + port,
+		Addr:    ":" + cfg.Port,
 		Handler: router,
 	}
+	// The event streams never finish on their own; end them so Shutdown does not wait for them
+	srv.RegisterOnShutdown(broker.Close)
 
 	// Start server in a goroutine
 	go func() {
-		log.Printf("Server starting on port %s...", port)
+		log.Printf("Server starting on port %s...", cfg.Port)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Failed to start server: %v", err)
 		}
 	}()
 
+	// Serve the gRPC UserService next to the REST API, on the same repository and tokens
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("Failed to listen on gRPC port %s: %v", cfg.GRPCPort, err)
+	}
+	grpcSrv := grpcserver.NewServer(userRepo, tokens)
+	go func() {
+		log.Printf("gRPC server starting on port %s...", cfg.GRPCPort)
+		if err := grpcSrv.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
 	// Setup graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -117,25 +175,94 @@ func main() {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
 
+	// Let the gRPC calls in flight finish, within the same 5 seconds
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcSrv.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-shutdownCtx.Done():
+		grpcSrv.Stop()
+	}
+
+	// Stop the relay and the consumer after their current event, then leave the consumer group
+	cancel()
+	<-relayDone
+	<-consumerDone
+	if err := consumer.Close(); err != nil {
+		log.Printf("Error closing Kafka reader: %v", err)
+	}
+
 	log.Println("Server exited properly")
 }
 
-// connectToMongoDB establishes a connection to MongoDB
-func connectToMongoDB(ctx context.Context) (*mongo.Client, error) {
-	uri := os.Getenv("MONGODB_URI")
-	if uri == "" {
-		uri = "mongodb://localhost:27017"
+// newRouter registers the middleware and routes of the app (the integration tests serve the same router)
+func newRouter(userRepo repository.UserRepository, tokens *auth.TokenIssuer, healthHandler *handlers.HealthHandler, broker *events.Broker, httpConfig config.HTTP) *gin.Engine {
+	router := gin.Default()
+	if err := router.SetTrustedProxies(httpConfig.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
 	}
+	router.Use(gin.Recovery())
+	router.Use(metrics.Middleware())
+	router.Use(tracing.Middleware())
+	// After tracing, so the request ID lands on the request's span
+	router.Use(middleware.RequestID())
+
+	// Initialize handlers
+	userHandler := handlers.NewUserHandler(userRepo)
+	authHandler := handlers.NewAuthHandler(userRepo, tokens)
+	eventHandler := handlers.NewEventHandler(broker)
+
+	// Define routes
+	router.GET("/", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"message": "Go Sample App with MongoDB and Kafka",
+		})
+	})
+
+	// Probes: liveness checks only the process, readiness also MongoDB and Kafka
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
 
-	clientOptions := options.Client().ApplyURI(uri)
+	// Prometheus metrics: request count and latency by route, Kafka publishes by result
+	router.GET("/metrics", metrics.Handler())
 
-	// Set timeout
-	timeoutStr := os.Getenv("MONGODB_TIMEOUT_MS")
-	var timeout int64 = 5000 // default 5 seconds
-	if timeoutStr != "" {
-		fmt.Sscanf(timeoutStr, "%d", &timeout)
+	// User routes, rate limited per client IP (the probes and metrics are not)
+	api := router.Group("/api", middleware.RateLimit(middleware.RateLimitConfig{
+		RequestsPerSecond: httpConfig.RateLimitRPS,
+		Burst:             httpConfig.RateLimitBurst,
+	}))
+	{
+		api.POST("/auth/register", userHandler.CreateUser)
+		api.POST("/auth/login", authHandler.Login)
+
+		// Server-Sent Events with the user events consumed from Kafka
+		api.GET("/events", eventHandler.Stream)
+
+		users := api.Group("/users")
+		{
+			users.GET("", userHandler.GetAllUsers)
+			users.GET("/:id", userHandler.GetUserByID)
+
+			// Mutations require a bearer token from /api/auth/login
+			protected := users.Group("", middleware.RequireAuth(tokens))
+			protected.POST("", userHandler.CreateUser)
+			protected.PUT("/:id", userHandler.UpdateUser)
+			protected.DELETE("/:id", userHandler.DeleteUser)
+		}
 	}
-	connectCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Millisecond)
+
+	return router
+}
+
+// connectToMongoDB establishes a connection to MongoDB
+func connectToMongoDB(ctx context.Context, cfg config.Mongo) (*mongo.Client, error) {
+	// Every command becomes a span of the request that issued it
+	clientOptions := options.Client().ApplyURI(cfg.URI).SetMonitor(tracing.MongoMonitor())
+
+	connectCtx, cancel := context.WithTimeout(ctx, cfg.Timeout())
 	defer cancel()
 
 	// Connect to MongoDB
@@ -155,26 +282,50 @@ func connectToMongoDB(ctx context.Context) (*mongo.Client, error) {
 }
 
 // connectToKafka establishes a connection to Kafka
-func connectToKafka() *kafka.Writer {
-	brokers := os.Getenv("KAFKA_BROKERS")
-	if brokers == "" {
-		brokers = "localhost:9092"
-	}
+func connectToKafka(cfg config.Kafka) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  cfg.Brokers,
+		Topic:    cfg.Topic,
+		Balancer: &kafka.LeastBytes{},
+		Dialer:   &kafka.Dialer{ClientID: cfg.ClientID, Timeout: 10 * time.Second},
+		// The event producer retries, with its own backoff and dead-letter topic
+		MaxAttempts: 1,
+	})
+}
 
-	topic := os.Getenv("KAFKA_TOPIC_USERS")
-	if topic == "" {
-		topic = "users"
-	}
+// connectToKafkaDLQ creates the writer of the dead-letter topic, where the events that could not
+// be published to the users topic are kept with the error
+func connectToKafkaDLQ(cfg config.Kafka) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers:     cfg.Brokers,
+		Topic:       cfg.DLQTopic,
+		Balancer:    &kafka.LeastBytes{},
+		Dialer:      &kafka.Dialer{ClientID: cfg.ClientID, Timeout: 10 * time.Second},
+		MaxAttempts: 1,
+	})
+}
 
-	clientID := os.Getenv("KAFKA_CLIENT_ID")
-	if clientID == "" {
-		clientID = "go-sample-app-client"
+// kafkaProducerConfig holds the retry settings of the event producer
+func kafkaProducerConfig(cfg config.Kafka, dlq *kafka.Writer) models.ProducerConfig {
+	return models.ProducerConfig{
+		MaxRetries: cfg.MaxRetries,
+		Backoff:    cfg.RetryBackoff(),
+		MaxBackoff: 5 * time.Second,
+		DLQ:        dlq,
 	}
+}
 
-	return kafka.NewWriter(kafka.WriterConfig{
-		Brokers:  []string{brokers},
-		Topic:    topic,
-		Balancer: &kafka.LeastBytes{},
-		ClientID: clientID,
+// connectToKafkaReader creates the reader of the users topic, in a consumer group whose offsets
+// are committed by the consumer itself
+func connectToKafkaReader(cfg config.Kafka) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     cfg.Brokers,
+		GroupID:     cfg.ConsumerGroup,
+		Topic:       cfg.Topic,
+		StartOffset: kafka.FirstOffset,
+		MaxBytes:    10e6,
+		Dialer:      &kafka.Dialer{ClientID: cfg.ClientID, Timeout: 10 * time.Second},
+		// Commit synchronously, only what the consumer reports as handled
+		CommitInterval: 0,
 	})
 }
\ No newline at end of file
@@ -0,0 +1,314 @@
+//go:build integration
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/segmentio/kafka-go"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/redpanda"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/example/go-sample-app/internal/auth"
+	"github.com/example/go-sample-app/internal/config"
+	"github.com/example/go-sample-app/internal/events"
+	"github.com/example/go-sample-app/internal/handlers"
+	"github.com/example/go-sample-app/internal/models"
+	"github.com/example/go-sample-app/internal/repository"
+	"github.com/example/go-sample-app/internal/worker"
+)
+
+// usersTopic is created by the test, so the relay never races the topic auto-creation
+const usersTopic = "users"
+
+// startContainer starts a container that is terminated when the test ends
+func startContainer(ctx context.Context, t *testing.T, req testcontainers.ContainerRequest) testcontainers.Container {
+	t.Helper()
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: req,
+		Started:          true,
+	})
+	if err != nil {
+		t.Fatalf("Failed to start %s: %v", req.Image, err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(context.Background()); err != nil {
+			t.Logf("Error terminating %s: %v", req.Image, err)
+		}
+	})
+	return container
+}
+
+// startMongoDB starts a standalone MongoDB and returns its URI; without a replica set the
+// repository writes the user and its outbox event without a transaction
+func startMongoDB(ctx context.Context, t *testing.T) string {
+	container := startContainer(ctx, t, testcontainers.ContainerRequest{
+		Image:        "mongo:7.0",
+		ExposedPorts: []string{"27017/tcp"},
+		WaitingFor:   wait.ForLog("Waiting for connections"),
+	})
+	uri, err := container.PortEndpoint(ctx, "27017/tcp", "mongodb")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return uri
+}
+
+// startKafka starts a single Redpanda broker, as in the dev services, and creates the users topic
+func startKafka(ctx context.Context, t *testing.T) string {
+	container, err := redpanda.Run(ctx, "redpandadata/redpanda:v23.3.10")
+	if err != nil {
+		t.Fatalf("Failed to start Redpanda: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := container.Terminate(context.Background()); err != nil {
+			t.Logf("Error terminating Redpanda: %v", err)
+		}
+	})
+	broker, err := container.KafkaSeedBroker(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The single broker is the controller, so it accepts the topic once its partitions can be led
+	eventually(t, 30*time.Second, func() error {
+		conn, err := kafka.DialContext(ctx, "tcp", broker)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		return conn.CreateTopics(kafka.TopicConfig{Topic: usersTopic, NumPartitions: 1, ReplicationFactor: 1})
+	})
+	return broker
+}
+
+// eventually retries check until it succeeds or the timeout expires
+func eventually(t *testing.T, timeout time.Duration, check func() error) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for {
+		err := check()
+		if err == nil {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Gave up after %s: %v", timeout, err)
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// call sends a request to the app, checks the status and decodes the JSON response into out, if not nil
+func call(t *testing.T, server *httptest.Server, method, path, token, body string, status int, out any) {
+	t.Helper()
+	req, err := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != status {
+		t.Fatalf("%s %s: status = %d, want %d (%s)", method, path, resp.StatusCode, status, data)
+	}
+	if out != nil {
+		if err := json.Unmarshal(data, out); err != nil {
+			t.Fatalf("%s %s: %v (%s)", method, path, err, data)
+		}
+	}
+}
+
+// TestUserLifecycle runs the app against real MongoDB and Kafka containers: a user is registered
+// (a second one with the same username or email is rejected), logs in, is read, updated and
+// soft-deleted over HTTP, and the outbox relay publishes one event per change, which the users topic consumer
+// receives in order.
+// streamEvents opens /api/events and sends each event of the stream on the returned channel
+func streamEvents(ctx context.Context, t *testing.T, server *httptest.Server) <-chan models.UserEvent {
+	t.Helper()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /api/events: status %d", resp.StatusCode)
+	}
+	out := make(chan models.UserEvent, 10)
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+			var event models.UserEvent
+			if ok && json.Unmarshal([]byte(data), &event) == nil {
+				out <- event
+			}
+		}
+	}()
+	return out
+}
+
+func TestUserLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	mongoURI := startMongoDB(ctx, t)
+	broker := startKafka(ctx, t)
+
+	mongoClient, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer mongoClient.Disconnect(context.Background())
+	db := mongoClient.Database("go_sample_app_test")
+	outboxRepo := repository.NewOutboxRepository(db)
+	if err := outboxRepo.EnsureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	userRepo := repository.NewMongoUserRepository(db, outboxRepo)
+	if err := userRepo.EnsureIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// The relay and the consumer run as in main, stopping with the test
+	workersCtx, stopWorkers := context.WithCancel(ctx)
+	defer stopWorkers()
+	writer := kafka.NewWriter(kafka.WriterConfig{
+		Brokers:  []string{broker},
+		Topic:    usersTopic,
+		Balancer: &kafka.LeastBytes{},
+	})
+	defer writer.Close()
+	go worker.NewOutboxRelay(outboxRepo, models.NewEventProducer(writer, models.ProducerConfig{}), 100*time.Millisecond, 100).Run(workersCtx)
+
+	// The consumer relays the events to the clients of /api/events, as in main
+	eventBroker := events.NewBroker()
+	consumer := worker.NewUserEventConsumer(kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     []string{broker},
+		GroupID:     "go-sample-app-integration",
+		Topic:       usersTopic,
+		StartOffset: kafka.FirstOffset,
+	}), func(_ context.Context, event models.UserEvent) error {
+		eventBroker.Publish(event)
+		return nil
+	})
+	defer consumer.Close()
+	go consumer.Run(workersCtx)
+
+	tokens := auth.NewTokenIssuer("integration-secret", time.Hour)
+	server := httptest.NewServer(newRouter(userRepo, tokens, handlers.NewHealthHandler(mongoClient, broker, true), eventBroker, config.HTTP{}))
+	defer server.Close()
+
+	call(t, server, http.MethodGet, "/readyz", "", "", http.StatusOK, nil)
+	// Opened before the first change, so the stream gets every event
+	stream := streamEvents(workersCtx, t, server)
+
+	var user models.User
+	call(t, server, http.MethodPost, "/api/auth/register", "",
+		`{"username":"alice","email":"alice@example.com","password":"secret123"}`, http.StatusCreated, &user)
+	id := user.ID.Hex()
+	if user.CreatedBy != id || user.DeletedAt != nil {
+		t.Fatalf("registered = %+v, want created by itself and not deleted", user)
+	}
+
+	// Binding failures answer RFC 7807 problems with the invalid fields
+	var problem models.Problem
+	call(t, server, http.MethodPost, "/api/auth/register", "",
+		`{"username":"bob","email":"bob","password":"secret123"}`, http.StatusBadRequest, &problem)
+	if len(problem.Errors) != 1 || problem.Errors[0].Field != "email" || problem.Errors[0].Rule != "email" {
+		t.Fatalf("problem = %+v, want an invalid email", problem)
+	}
+
+	// The unique indexes reject a second user with the same username or email
+	call(t, server, http.MethodPost, "/api/auth/register", "",
+		`{"username":"alice","email":"other@example.com","password":"secret123"}`, http.StatusBadRequest, nil)
+	call(t, server, http.MethodPost, "/api/auth/register", "",
+		`{"username":"bob","email":"alice@example.com","password":"secret123"}`, http.StatusBadRequest, nil)
+
+	var login models.LoginResponse
+	call(t, server, http.MethodPost, "/api/auth/login", "", `{"username":"alice","password":"secret123"}`, http.StatusOK, &login)
+
+	var page models.UserPage
+	call(t, server, http.MethodGet, "/api/users?username=ali", "", "", http.StatusOK, &page)
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0].ID != user.ID {
+		t.Fatalf("page = %+v, want only %s", page, id)
+	}
+
+	var updated models.User
+	call(t, server, http.MethodPut, "/api/users/"+id, login.Token,
+		`{"username":"alice2","email":"alice@example.com","password":"secret123"}`, http.StatusOK, &updated)
+	if updated.Username != "alice2" || updated.UpdatedBy != id {
+		t.Fatalf("updated = %+v", updated)
+	}
+	call(t, server, http.MethodGet, "/api/users/"+id, "", "", http.StatusOK, &updated)
+	if updated.Username != "alice2" {
+		t.Fatalf("read back = %+v", updated)
+	}
+
+	call(t, server, http.MethodDelete, "/api/users/"+id, login.Token, "", http.StatusNoContent, nil)
+	call(t, server, http.MethodGet, "/api/users/"+id, "", "", http.StatusNotFound, nil)
+
+	// The deleted user stays in the collection, out of every query
+	call(t, server, http.MethodGet, "/api/users?username=ali", "", "", http.StatusOK, &page)
+	if page.Total != 0 {
+		t.Fatalf("page = %+v, want no users", page)
+	}
+	call(t, server, http.MethodPost, "/api/auth/login", "", `{"username":"alice2","password":"secret123"}`, http.StatusUnauthorized, nil)
+	var stored models.User
+	if err := db.Collection("users").FindOne(ctx, bson.M{"_id": user.ID}).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.DeletedAt == nil || stored.DeletedBy != id {
+		t.Fatalf("stored = %+v, want deleted by %s", stored, id)
+	}
+
+	// One event per change, in order, delivered through the outbox, Kafka, the consumer group and
+	// the event stream
+	for _, want := range []string{models.EventTypeUserCreated, models.EventTypeUserUpdated, models.EventTypeUserDeleted} {
+		select {
+		case event := <-stream:
+			if event.EventType != want || event.UserID != id || event.ActorID != id {
+				t.Fatalf("event = %+v, want %s for user %s", event, want, id)
+			}
+		case <-time.After(60 * time.Second):
+			t.Fatalf("No %s event received", want)
+		}
+	}
+
+	pending, err := outboxRepo.Pending(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 0 {
+		t.Fatalf("%d outbox messages still pending", len(pending))
+	}
+}
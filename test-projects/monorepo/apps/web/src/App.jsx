@@ -0,0 +1,24 @@
+import { useEffect, useState } from 'react';
+
+export function App() {
+  const [orders, setOrders] = useState([]);
+
+  useEffect(() => {
+    fetch('/api/orders')
+      .then((res) => res.json())
+      .then((data) => setOrders(data ?? []));
+  }, []);
+
+  return (
+    <main>
+      <h1>Pedidos</h1>
+      <ul>
+        {orders.map((order) => (
+          <li key={order.id}>
+            {order.product} × {order.quantity}
+          </li>
+        ))}
+      </ul>
+    </main>
+  );
+}
@@ -0,0 +1,12 @@
+import { defineConfig } from 'vite';
+import react from '@vitejs/plugin-react';
+
+export default defineConfig({
+  plugins: [react()],
+  server: {
+    port: Number(process.env.WEB_PORT || 5173),
+    proxy: {
+      '/api': process.env.API_URL || 'http://localhost:8080',
+    },
+  },
+});
@@ -0,0 +1,8 @@
+module github.com/example/monorepo/services/api
+
+go 1.22
+
+require (
+	github.com/segmentio/kafka-go v0.4.47
+	go.mongodb.org/mongo-driver v1.15.0
+)
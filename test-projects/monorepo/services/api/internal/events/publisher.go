@@ -0,0 +1,47 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Publisher sends order events to Kafka
+type Publisher struct {
+	writer *kafka.Writer
+}
+
+// NewPublisher creates a Publisher for the brokers in KAFKA_BROKERS
+func NewPublisher() *Publisher {
+	brokers := os.Getenv("KAFKA_BROKERS")
+	if brokers == "" {
+		brokers = "localhost:9092"
+	}
+	return &Publisher{
+		writer: &kafka.Writer{
+			Addr:     kafka.TCP(strings.Split(brokers, ",")...),
+			Topic:    "orders",
+			Balancer: &kafka.LeastBytes{},
+		},
+	}
+}
+
+// Publish sends an event with its type in the event_type header
+func (p *Publisher) Publish(ctx context.Context, eventType string, payload any) error {
+	value, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{
+		Value:   value,
+		Headers: []kafka.Header{{Key: "event_type", Value: []byte(eventType)}},
+	})
+}
+
+// Close flushes and closes the writer
+func (p *Publisher) Close() error {
+	return p.writer.Close()
+}
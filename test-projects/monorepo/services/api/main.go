@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/example/monorepo/services/api/internal/events"
+)
+
+type Order struct {
+	ID        string    `json:"id" bson:"_id"`
+	Product   string    `json:"product" bson:"product"`
+	Quantity  int       `json:"quantity" bson:"quantity"`
+	CreatedAt time.Time `json:"created_at" bson:"created_at"`
+}
+
+func main() {
+	ctx := context.Background()
+
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		mongoURI = "mongodb://localhost:27017"
+	}
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatalf("Failed to connect to MongoDB: %v", err)
+	}
+	defer client.Disconnect(ctx)
+	orders := client.Database("orders").Collection("orders")
+
+	publisher := events.NewPublisher()
+	defer publisher.Close()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /api/orders", func(w http.ResponseWriter, r *http.Request) {
+		cursor, err := orders.Find(r.Context(), bson.M{})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		var result []Order
+		if err := cursor.All(r.Context(), &result); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		json.NewEncoder(w).Encode(result)
+	})
+	mux.HandleFunc("POST /api/orders", func(w http.ResponseWriter, r *http.Request) {
+		var order Order
+		if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		order.ID = time.Now().Format("20060102150405.000000")
+		order.CreatedAt = time.Now()
+		if _, err := orders.InsertOne(r.Context(), order); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if err := publisher.Publish(r.Context(), "order.created", order); err != nil {
+			log.Printf("Failed to publish event: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(order)
+	})
+
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	log.Printf("API listening on :%s", port)
+	log.Fatal(http.ListenAndServe(":"+port, mux))
+}
@@ -0,0 +1,40 @@
+import json
+import os
+
+import redis
+from confluent_kafka import Consumer
+
+KAFKA_BROKERS = os.getenv("KAFKA_BROKERS", "localhost:9092")
+REDIS_URL = os.getenv("REDIS_URL", "redis://localhost:6379")
+
+
+def handle(cache, event_type, order):
+    """Count orders per product; other event types are ignored."""
+    if event_type != "order.created":
+        return False
+    cache.hincrby("orders:by_product", order["product"], order.get("quantity", 1))
+    return True
+
+
+def run():
+    cache = redis.Redis.from_url(REDIS_URL)
+    consumer = Consumer({
+        "bootstrap.servers": KAFKA_BROKERS,
+        "group.id": os.getenv("CONSUMER_GROUP", "events-worker"),
+        "auto.offset.reset": "earliest",
+    })
+    consumer.subscribe(["orders"])
+    try:
+        while True:
+            msg = consumer.poll(1.0)
+            if msg is None or msg.error():
+                continue
+            headers = dict(msg.headers() or [])
+            event_type = headers.get("event_type", b"").decode()
+            handle(cache, event_type, json.loads(msg.value()))
+    finally:
+        consumer.close()
+
+
+if __name__ == "__main__":
+    run()
@@ -0,0 +1,21 @@
+from events_worker.main import handle
+
+
+class FakeCache:
+    def __init__(self):
+        self.counts = {}
+
+    def hincrby(self, key, field, amount):
+        self.counts[field] = self.counts.get(field, 0) + amount
+
+
+def test_counts_created_orders():
+    cache = FakeCache()
+    assert handle(cache, "order.created", {"product": "book", "quantity": 2})
+    assert cache.counts == {"book": 2}
+
+
+def test_ignores_other_events():
+    cache = FakeCache()
+    assert not handle(cache, "order.cancelled", {"product": "book"})
+    assert cache.counts == {}
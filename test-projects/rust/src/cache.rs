@@ -0,0 +1,26 @@
+use crate::models::User;
+use redis::aio::ConnectionManager;
+use redis::AsyncCommands;
+use uuid::Uuid;
+
+/// How long a user stays cached, in seconds.
+const TTL_SECONDS: u64 = 60;
+
+fn key(id: Uuid) -> String {
+    format!("user:{}", id)
+}
+
+pub async fn get_user(cache: &mut ConnectionManager, id: Uuid) -> Option<User> {
+    let cached: Option<String> = cache.get(key(id)).await.ok()?;
+    serde_json::from_str(&cached?).ok()
+}
+
+pub async fn put_user(cache: &mut ConnectionManager, user: &User) {
+    if let Ok(json) = serde_json::to_string(user) {
+        let _: Result<(), _> = cache.set_ex(key(user.id), json, TTL_SECONDS).await;
+    }
+}
+
+pub async fn evict_user(cache: &mut ConnectionManager, id: Uuid) {
+    let _: Result<(), _> = cache.del(key(id)).await;
+}
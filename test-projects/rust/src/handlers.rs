@@ -0,0 +1,47 @@
+use crate::models::{NewUser, User};
+use crate::{cache, repository, AppState};
+use axum::extract::{Path, State};
+use axum::http::StatusCode;
+use axum::Json;
+use uuid::Uuid;
+
+type ApiResult<T> = Result<T, (StatusCode, String)>;
+
+fn internal(e: sqlx::Error) -> (StatusCode, String) {
+    tracing::error!("database error: {}", e);
+    (StatusCode::INTERNAL_SERVER_ERROR, "internal error".to_string())
+}
+
+pub async fn health() -> &'static str {
+    "ok"
+}
+
+pub async fn list_users(State(state): State<AppState>) -> ApiResult<Json<Vec<User>>> {
+    repository::find_all(&state.db).await.map(Json).map_err(internal)
+}
+
+/// Cache-aside: Redis first, then Postgres.
+pub async fn get_user(State(mut state): State<AppState>, Path(id): Path<Uuid>) -> ApiResult<Json<User>> {
+    if let Some(user) = cache::get_user(&mut state.cache, id).await {
+        return Ok(Json(user));
+    }
+    let user = repository::find_by_id(&state.db, id)
+        .await
+        .map_err(internal)?
+        .ok_or((StatusCode::NOT_FOUND, "user not found".to_string()))?;
+    cache::put_user(&mut state.cache, &user).await;
+    Ok(Json(user))
+}
+
+pub async fn create_user(State(state): State<AppState>, Json(body): Json<NewUser>) -> ApiResult<(StatusCode, Json<User>)> {
+    let user = repository::create(&state.db, body).await.map_err(internal)?;
+    Ok((StatusCode::CREATED, Json(user)))
+}
+
+pub async fn delete_user(State(mut state): State<AppState>, Path(id): Path<Uuid>) -> ApiResult<StatusCode> {
+    if !repository::delete(&state.db, id).await.map_err(internal)? {
+        return Err((StatusCode::NOT_FOUND, "user not found".to_string()));
+    }
+    cache::evict_user(&mut state.cache, id).await;
+    Ok(StatusCode::NO_CONTENT)
+}
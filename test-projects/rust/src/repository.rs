@@ -0,0 +1,30 @@
+use crate::models::{NewUser, User};
+use sqlx::PgPool;
+use uuid::Uuid;
+
+pub async fn find_all(db: &PgPool) -> Result<Vec<User>, sqlx::Error> {
+    sqlx::query_as::<_, User>("SELECT id, name, email, created_at FROM users ORDER BY created_at DESC")
+        .fetch_all(db)
+        .await
+}
+
+pub async fn find_by_id(db: &PgPool, id: Uuid) -> Result<Option<User>, sqlx::Error> {
+    sqlx::query_as::<_, User>("SELECT id, name, email, created_at FROM users WHERE id = $1")
+        .bind(id)
+        .fetch_optional(db)
+        .await
+}
+
+pub async fn create(db: &PgPool, user: NewUser) -> Result<User, sqlx::Error> {
+    sqlx::query_as::<_, User>("INSERT INTO users (id, name, email) VALUES ($1, $2, $3) RETURNING id, name, email, created_at")
+        .bind(Uuid::new_v4())
+        .bind(user.name)
+        .bind(user.email)
+        .fetch_one(db)
+        .await
+}
+
+pub async fn delete(db: &PgPool, id: Uuid) -> Result<bool, sqlx::Error> {
+    let result = sqlx::query("DELETE FROM users WHERE id = $1").bind(id).execute(db).await?;
+    Ok(result.rows_affected() > 0)
+}
@@ -0,0 +1,19 @@
+use axum::body::Body;
+use axum::http::{Request, StatusCode};
+use axum::routing::get;
+use axum::Router;
+use http_body_util::BodyExt;
+use tower::ServiceExt;
+
+async fn health() -> &'static str {
+    "ok"
+}
+
+#[tokio::test]
+async fn health_returns_ok() {
+    let app = Router::new().route("/health", get(health));
+    let response = app.oneshot(Request::builder().uri("/health").body(Body::empty()).unwrap()).await.unwrap();
+    assert_eq!(response.status(), StatusCode::OK);
+    let body = response.into_body().collect().await.unwrap().to_bytes();
+    assert_eq!(&body[..], b"ok");
+}
@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// dx running in `dir` with a private audit log (DX_STATE_DIR)
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .env_remove("DX_AUDIT")
+        .env_remove("DX_AUDIT_RUN")
+        .output()
+        .expect("run dx")
+}
+
+// Test that generated files are logged with diffs and `dx undo` restores them, latest run first
+#[test]
+fn audit_records_writes_and_undo_restores() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let root = dir.path();
+    fs::write(root.join("main.go"), "package main\n\nimport \"os\"\n\nfunc main() { _ = os.Getenv(\"API_TOKEN\") }\n").unwrap();
+    fs::write(root.join("README.md"), "# Demo\n").unwrap();
+
+    assert!(dx(root, &["dev-env", "docs", "--readme"]).status.success());
+    assert!(dx(root, &["dev-env", "docs"]).status.success());
+    assert!(root.join("ENV.md").exists());
+
+    let audit = dx(root, &["audit", "--diff"]);
+    let stdout = String::from_utf8_lossy(&audit.stdout);
+    assert!(stdout.contains("dx dev-env docs --readme"), "{}", stdout);
+    assert!(stdout.contains("criado"), "{}", stdout);
+    assert!(stdout.contains("+| `API_TOKEN` | sim |"), "{}", stdout);
+
+    // Latest run: ENV.md was created, so undo removes it
+    assert!(dx(root, &["undo"]).status.success());
+    assert!(!root.join("ENV.md").exists());
+    assert!(fs::read_to_string(root.join("README.md")).unwrap().contains("API_TOKEN"));
+
+    // Files edited after the command are kept unless --force
+    fs::write(root.join("README.md"), fs::read_to_string(root.join("README.md")).unwrap() + "editado\n").unwrap();
+    let kept = dx(root, &["undo"]);
+    assert!(!kept.status.success());
+    assert!(String::from_utf8_lossy(&kept.stdout).contains("--force"));
+    assert!(dx(root, &["undo", "--force"]).status.success());
+    assert_eq!(fs::read_to_string(root.join("README.md")).unwrap(), "# Demo\n");
+
+    let nothing = dx(root, &["undo"]);
+    assert!(String::from_utf8_lossy(&nothing.stdout).contains("Nada para desfazer"));
+}
+
+// Test that `dx undo <run>` reverts an older run from its backups, leaving later runs alone
+#[test]
+fn undo_specific_run_from_backup() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let root = dir.path();
+    fs::write(root.join("main.go"), "package main\n\nimport \"os\"\n\nfunc main() { _ = os.Getenv(\"API_TOKEN\") }\n").unwrap();
+    fs::write(root.join("README.md"), "# Demo\n").unwrap();
+
+    assert!(dx(root, &["dev-env", "docs", "--readme"]).status.success());
+    assert!(dx(root, &["dev-config", "add", "foo", "bar"]).status.success());
+
+    let log = fs::read_to_string(root.join(".dx-state/audit.jsonl")).unwrap();
+    let first: serde_json::Value = serde_json::from_str(log.lines().next().unwrap()).unwrap();
+    let run = first["run"].as_str().unwrap().to_string();
+    let backup = first["backup"].as_str().expect("backup path");
+    assert_eq!(fs::read_to_string(backup).unwrap(), "# Demo\n");
+    assert!(first.get("before").is_none());
+
+    let output = dx(root, &["undo", &run]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert_eq!(fs::read_to_string(root.join("README.md")).unwrap(), "# Demo\n");
+    assert!(root.join(".dx/config.json").exists());
+
+    let again = dx(root, &["undo", &run]);
+    assert!(!again.status.success());
+    assert!(String::from_utf8_lossy(&again.stderr).contains("já foi desfeita"));
+}
@@ -0,0 +1,106 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.join(".state"))
+        .env("DX_CACHE_DIR", dir.join(".cache"))
+        .output()
+        .expect("failed to run dx benchmark")
+}
+
+fn go_service(dir: &Path) {
+    fs::write(dir.join("go.mod"), "module example.com/orders\n\ngo 1.22\n\nrequire github.com/gin-gonic/gin v1.9.1\n").unwrap();
+    fs::write(
+        dir.join("main.go"),
+        "package main\n\nfunc main() {\n\tport := os.Getenv(\"PORT\")\n\tr := gin.Default()\n\tr.GET(\"/healthz\", health)\n\t_ = r.Run(\":\" + port)\n}\n",
+    )
+    .unwrap();
+    fs::write(dir.join(".golangci.yml"), "linters:\n  enable: [errcheck]\n").unwrap();
+    fs::create_dir_all(dir.join(".github/workflows")).unwrap();
+    fs::write(dir.join(".github/workflows/ci.yml"), "on: push\n").unwrap();
+}
+
+// Test that the archetype of .dx/config.yaml scores the project by weight, lists each gap with its
+// remediation task and fails below min_score
+#[test]
+fn benchmark_archetype_gap_report() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    go_service(dir);
+    fs::create_dir_all(dir.join(".dx")).unwrap();
+    fs::write(
+        dir.join(".dx/config.yaml"),
+        r#"archetypes:
+  go-service:
+    description: Serviço HTTP em Go
+    stack: go
+    min_score: 75
+    files:
+      - .github/workflows/*.yml
+      - path: Dockerfile
+        weight: 2
+    configs:
+      - path: .golangci.yml
+        contains: [errcheck, gosec]
+    routes: [GET /healthz, /metrics]
+    env:
+      - PORT
+      - name: LOG_LEVEL
+        fix: use o pacote interno de logging
+"#,
+    )
+    .unwrap();
+
+    let output = dx(dir, &["benchmark", "archetype"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    // 4 of 9: stack, workflow, /healthz and PORT met; Dockerfile (2), gosec, /metrics and LOG_LEVEL missing
+    assert!(stdout.contains("✗ Pontuação: 44/100 (mínimo: 75)."), "{}", stdout);
+    assert!(stdout.contains("Arquivos (1/3)"), "{}", stdout);
+    assert!(stdout.contains("  ✓ .github/workflows/*.yml"), "{}", stdout);
+    assert!(stdout.contains("  - Crie Dockerfile (dx dev-config dockerfile)"), "{}", stdout);
+    assert!(stdout.contains("  - Adicione gosec a .golangci.yml"), "{}", stdout);
+    assert!(stdout.contains("  - Exponha /metrics na aplicação"), "{}", stdout);
+    assert!(stdout.contains("  - Leia a variável LOG_LEVEL na configuração da aplicação (use o pacote interno de logging)"), "{}", stdout);
+    let report = fs::read_to_string(dir.join(".dx/archetype-report.md")).unwrap();
+    assert!(report.starts_with("# Arquétipo go-service: 44/100\n\nServiço HTTP em Go\n"), "{}", report);
+    assert!(report.contains("| Arquivos | `Dockerfile` | 2 | ✗ ausente |"), "{}", report);
+    assert!(report.contains("- [ ] Crie Dockerfile (`dx dev-config dockerfile`)"), "{}", report);
+
+    // After the fixes, the project passes
+    fs::write(dir.join("Dockerfile"), "FROM golang:1.22\n").unwrap();
+    fs::write(dir.join(".golangci.yml"), "linters:\n  enable: [errcheck, gosec]\n").unwrap();
+    let output = dx(dir, &["--output", "json", "benchmark", "archetype", "--name", "go-service", "--no-save"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stdout));
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(doc["score"], 77, "{}", doc);
+    assert_eq!(doc["passed"], true, "{}", doc);
+    assert_eq!(doc["tasks"].as_array().unwrap().len(), 2, "{}", doc);
+}
+
+// Test that --file reads the archetypes from a file and a missing name lists the available ones
+#[test]
+fn benchmark_archetype_from_file() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    go_service(dir);
+    let file = tmp.path().join("golden-paths.yaml");
+    fs::write(&file, "archetypes:\n  go-service:\n    stack: go\n  node-service:\n    stack: node\n").unwrap();
+    let file = file.to_str().unwrap();
+
+    let output = dx(dir, &["benchmark", "archetype", "--file", file]);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("escolha um com --name: go-service, node-service"));
+
+    let output = dx(dir, &["benchmark", "archetype", "--file", file, "--name", "node-service", "--no-save"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("  ✗ node (detectada: Go)"), "{}", stdout);
+    assert!(!dir.join(".dx/archetype-report.md").exists());
+}
@@ -0,0 +1,67 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn go_service(dir: &Path, gin: &str, port_default: &str) {
+    fs::write(
+        dir.join("go.mod"),
+        format!("module example.com/svc\n\ngo 1.21\n\nrequire (\n\tgithub.com/gin-gonic/gin {}\n\tgo.mongodb.org/mongo-driver v1.12.1\n)\n", gin),
+    )
+    .unwrap();
+    fs::write(
+        dir.join("main.go"),
+        format!(
+            "package main\n\nimport \"os\"\n\nfunc main() {{\n\tport := os.Getenv(\"PORT\")\n\tif port == \"\" {{\n\t\tport = \"{}\"\n\t}}\n\t_ = os.Getenv(\"MONGODB_URI\")\n}}\n",
+            port_default
+        ),
+    )
+    .unwrap();
+}
+
+fn compare(a: &Path, b: &Path) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .arg("compare")
+        .args([a, b])
+        .args(["--format", "json"])
+        .output()
+        .expect("failed to run dx compare")
+}
+
+// Test that compare reports dependency and environment drift, and exits 0 for equivalent projects
+#[test]
+fn compare_two_projects() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (a, b) = (tmp.path().join("template"), tmp.path().join("service"));
+    fs::create_dir_all(&a).unwrap();
+    fs::create_dir_all(&b).unwrap();
+    go_service(&a, "v1.10.0", "8080");
+    go_service(&b, "v1.9.1", "8080");
+
+    let output = compare(&a, &b);
+    assert_eq!(output.status.code(), Some(1), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(doc["equal"], false);
+    assert_eq!(doc["stack"]["a"], "Go");
+    assert_eq!(doc["dependencies"]["changed"]["Go github.com/gin-gonic/gin"]["a"], "1.10.0");
+    assert_eq!(doc["dependencies"]["changed"]["Go github.com/gin-gonic/gin"]["b"], "1.9.1");
+    assert_eq!(doc["dependencies"]["same"], 1);
+    assert_eq!(doc["env"]["same"], 2, "{}", doc);
+
+    fs::write(b.join("docker-compose.yml"), "services:\n  redis:\n    image: redis:7\n").unwrap();
+    go_service(&b, "v1.10.0", "3000");
+    let output = Command::new(env!("CARGO_BIN_EXE_dx")).arg("compare").args([&a, &b]).output().unwrap();
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("~ PORT: padrão: 8080 → padrão: 3000"), "{}", stdout);
+    assert!(stdout.contains("Dependências: 0 só em A, 0 só em B, 0 diferente(s), 2 igual(is)"), "{}", stdout);
+    assert!(stdout.contains("+ redis (redis:alpine)"), "{}", stdout);
+
+    go_service(&b, "v1.10.0", "8080");
+    fs::remove_file(b.join("docker-compose.yml")).unwrap();
+    let output = compare(&a, &b);
+    assert_eq!(output.status.code(), Some(0), "{}", String::from_utf8_lossy(&output.stdout));
+
+    let output = compare(&a, &tmp.path().join("missing"));
+    assert_eq!(output.status.code(), Some(2));
+}
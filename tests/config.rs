@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// dx with settings and state under `home`, as XDG directories
+fn dx(home: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(home)
+        .env_remove("DX_CONFIG_DIR")
+        .env_remove("DX_STATE_DIR")
+        .env_remove("DX_NOTIFY_AFTER")
+        .env_remove("DX_PROGRESS")
+        .env_remove("DX_SANDBOX")
+        .env("HOME", home)
+        .env("XDG_CONFIG_HOME", home.join("config"))
+        .env("XDG_STATE_HOME", home.join("state"))
+        .output()
+        .expect("run dx")
+}
+
+// Test that settings exported on one machine are imported on another
+#[test]
+fn config_export_import_roundtrip() {
+    let a = tempfile::tempdir().expect("tempdir");
+    let b = tempfile::tempdir().expect("tempdir");
+
+    assert!(dx(a.path(), &["config", "set", "notify_after", "30"]).status.success());
+    assert!(a.path().join("config/dx/config.json").is_file());
+    let bad = dx(a.path(), &["config", "set", "notify_after", "logo"]);
+    assert!(!bad.status.success());
+
+    let export = a.path().join("dx-config.json");
+    assert!(dx(a.path(), &["config", "export", "--file", export.to_str().unwrap()]).status.success());
+    let exported = fs::read_to_string(&export).expect("read export");
+    assert!(exported.contains("\"notify_after\": \"30\""), "{}", exported);
+
+    let import = dx(b.path(), &["config", "import", export.to_str().unwrap()]);
+    assert!(import.status.success(), "{}", String::from_utf8_lossy(&import.stderr));
+    let show = dx(b.path(), &["config"]);
+    let stdout = String::from_utf8_lossy(&show.stdout);
+    assert!(stdout.contains("notify_after   = 30"), "{}", stdout);
+}
+
+// Test that the trust store moves from the config directory to the state directory
+#[test]
+fn config_migrates_legacy_trust_store() {
+    let home = tempfile::tempdir().expect("tempdir");
+    let legacy = home.path().join("config/dx/trust.json");
+    fs::create_dir_all(legacy.parent().unwrap()).unwrap();
+    fs::write(&legacy, "{\"projects\":{}}\n").unwrap();
+
+    let output = dx(home.path(), &["config"]);
+    assert!(output.status.success());
+    assert!(!legacy.exists());
+    assert!(home.path().join("state/dx/trust.json").is_file());
+    assert!(String::from_utf8_lossy(&output.stderr).contains("movido para"));
+}
+
+// Test the precedence default < user < dx.yaml < environment < flag and the --explain output
+#[test]
+fn config_get_explains_layers() {
+    let home = tempfile::tempdir().expect("tempdir");
+    fs::write(home.path().join("dx.yaml"), "settings:\n  notify_after: 60\n  sandbox: false\n").unwrap();
+    assert!(dx(home.path(), &["config", "set", "notify_after", "30"]).status.success());
+    assert!(dx(home.path(), &["config", "set", "sandbox", "true"]).status.success());
+
+    let value = dx(home.path(), &["config", "get", "notify_after"]);
+    assert_eq!(String::from_utf8_lossy(&value.stdout).trim(), "60");
+
+    let flag = dx(home.path(), &["--notify-after", "5", "config", "get", "notify_after", "--explain"]);
+    let stdout = String::from_utf8_lossy(&flag.stdout);
+    assert!(stdout.starts_with("notify_after = 5\n"), "{}", stdout);
+    assert!(stdout.contains("→ opção (--notify-after)"), "{}", stdout);
+
+    // A project cannot turn off the sandbox chosen by the user
+    let sandbox = dx(home.path(), &["config", "get", "sandbox", "--explain"]);
+    let stdout = String::from_utf8_lossy(&sandbox.stdout);
+    assert!(stdout.starts_with("sandbox = true\n"), "{}", stdout);
+    assert!(stdout.contains("projeto (dx.yaml)"), "{}", stdout);
+    assert!(stdout.contains("ignorado"), "{}", stdout);
+}
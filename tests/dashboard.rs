@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::process::Command;
+
+// Test that `dx dashboard --once` lists the compose services with their port mappings, as text and JSON
+#[test]
+fn dashboard_once_lists_services_and_ports() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let project = dir.path().join("loja");
+    fs::create_dir_all(project.join(".dx")).expect("create .dx");
+    fs::write(
+        project.join(".dx/docker-compose.yml"),
+        r#"services:
+  mongodb:
+    image: mongo:7
+    ports:
+      - "${MONGO_PORT:-27017}:27017"
+  kafka:
+    image: bitnami/kafka:3.7
+    ports:
+      - target: 9092
+        published: 19092
+"#,
+    )
+    .expect("write compose");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let text = Command::new(exe).args(["dashboard", "--once"]).arg(&project).output().expect("dx dashboard --once");
+    assert!(text.status.success(), "stderr: {}", String::from_utf8_lossy(&text.stderr));
+    let stdout = String::from_utf8_lossy(&text.stdout);
+    assert!(stdout.contains("Serviços (2)"), "stdout: {}", stdout);
+    assert!(stdout.contains("mongodb") && stdout.contains("27017→27017"), "stdout: {}", stdout);
+    assert!(stdout.contains("kafka") && stdout.contains("19092→9092"), "stdout: {}", stdout);
+    assert!(stdout.contains("Logs: kafka"), "services are sorted, the first one is selected: {}", stdout);
+
+    let json = Command::new(exe)
+        .args(["--output", "json", "dashboard", "--once"])
+        .arg(&project)
+        .output()
+        .expect("dx dashboard --once --output json");
+    let value: serde_json::Value = serde_json::from_slice(&json.stdout).expect("invalid JSON");
+    assert_eq!(value["project"], "loja");
+    assert_eq!(value["services"][0]["name"], "kafka");
+    assert_eq!(value["services"][0]["ports"][0], serde_json::json!([19092, 9092]));
+    assert_eq!(value["services"][1]["image"], "mongo:7");
+}
@@ -0,0 +1,298 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::collections::HashMap;
+use std::fs;
+use std::io::{BufRead, BufReader, Read, Write};
+use std::net::TcpListener;
+use std::path::Path;
+use std::process::{Command, Output};
+use std::sync::atomic::{AtomicBool, Ordering};
+use std::sync::{Arc, Mutex};
+
+/// Minimal OSV API: four known advisories, answered for any number of requests.
+fn osv_mock() -> String {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut request_line = String::new();
+            reader.read_line(&mut request_line).unwrap();
+            let mut length = 0;
+            loop {
+                let mut header = String::new();
+                reader.read_line(&mut header).unwrap();
+                if header.trim().is_empty() {
+                    break;
+                }
+                if let Some(v) = header.to_lowercase().strip_prefix("content-length:") {
+                    length = v.trim().parse().unwrap();
+                }
+            }
+            let mut body = vec![0; length];
+            reader.read_exact(&mut body).unwrap();
+            let path = request_line.split_whitespace().nth(1).unwrap_or("").to_string();
+            let response = if path == "/v1/querybatch" {
+                let request: serde_json::Value = serde_json::from_slice(&body).unwrap();
+                let results: Vec<serde_json::Value> = request["queries"]
+                    .as_array()
+                    .unwrap()
+                    .iter()
+                    .map(|q| match (q["package"]["name"].as_str().unwrap(), q["version"].as_str().unwrap()) {
+                        ("golang.org/x/net", "0.7.0") => serde_json::json!({ "vulns": [{ "id": "GO-2023-1988" }] }),
+                        ("lodash", "4.17.20") => serde_json::json!({ "vulns": [{ "id": "GHSA-35jh-r3h4-6jhm" }] }),
+                        ("requests", "2.19.0") => serde_json::json!({ "vulns": [{ "id": "PYSEC-2018-28" }] }),
+                        ("smallvec", "1.6.0") => serde_json::json!({ "vulns": [{ "id": "RUSTSEC-2021-0003" }] }),
+                        _ => serde_json::json!({}),
+                    })
+                    .collect();
+                serde_json::json!({ "results": results })
+            } else {
+                match path.trim_start_matches("/v1/vulns/") {
+                    "GO-2023-1988" => serde_json::json!({
+                        "id": "GO-2023-1988",
+                        "summary": "Improper rendering of text nodes in golang.org/x/net/html",
+                        "aliases": ["CVE-2023-3978"],
+                        "affected": [{ "package": { "name": "golang.org/x/net", "ecosystem": "Go" },
+                                       "ranges": [{ "type": "SEMVER", "events": [{ "introduced": "0" }, { "fixed": "0.13.0" }] }] }]
+                    }),
+                    "GHSA-35jh-r3h4-6jhm" => serde_json::json!({
+                        "id": "GHSA-35jh-r3h4-6jhm",
+                        "summary": "Command Injection in lodash",
+                        "database_specific": { "severity": "HIGH" },
+                        "affected": [{ "package": { "name": "lodash", "ecosystem": "npm" },
+                                       "ranges": [{ "type": "SEMVER", "events": [{ "introduced": "0" }, { "fixed": "4.17.21" }] }] }]
+                    }),
+                    "RUSTSEC-2021-0003" => serde_json::json!({
+                        "id": "RUSTSEC-2021-0003",
+                        "summary": "Buffer overflow in SmallVec::insert_many",
+                        "affected": [{ "package": { "name": "smallvec", "ecosystem": "crates.io" },
+                                       "ranges": [{ "type": "SEMVER", "events": [{ "introduced": "0.6.3" }, { "fixed": "1.6.1" }] }] }]
+                    }),
+                    _ => serde_json::json!({
+                        "id": "PYSEC-2018-28",
+                        "details": "Requests sends credentials on redirects to HTTP.\nMore details.",
+                        "severity": [{ "type": "CVSS_V3", "score": "CVSS:3.1/AV:N/AC:L/PR:N/UI:N/S:U/C:H/I:H/A:H" }],
+                        "affected": [{ "package": { "name": "requests", "ecosystem": "PyPI" },
+                                       "ranges": [{ "type": "ECOSYSTEM", "events": [{ "introduced": "0" }, { "fixed": "2.20.0" }] }] }]
+                    }),
+                }
+            };
+            let body = response.to_string();
+            let mut stream = stream;
+            let _ = write!(
+                stream,
+                "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}",
+                body.len(),
+                body
+            );
+        }
+    });
+    format!("http://{}", addr)
+}
+
+/// OSV API that rate-limits: the first batch query gets 429, and GO-2023-0002 answers 503 while
+/// `down` is set. `hits` counts the requests per path.
+fn flaky_osv_mock(down: Arc<AtomicBool>, hits: Arc<Mutex<HashMap<String, usize>>>) -> String {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut request_line = String::new();
+            reader.read_line(&mut request_line).unwrap();
+            let mut length = 0;
+            loop {
+                let mut header = String::new();
+                reader.read_line(&mut header).unwrap();
+                if header.trim().is_empty() {
+                    break;
+                }
+                if let Some(v) = header.to_lowercase().strip_prefix("content-length:") {
+                    length = v.trim().parse().unwrap();
+                }
+            }
+            let mut body = vec![0; length];
+            reader.read_exact(&mut body).unwrap();
+            let path = request_line.split_whitespace().nth(1).unwrap_or("").to_string();
+            let count = {
+                let mut hits = hits.lock().unwrap();
+                let count = hits.entry(path.clone()).or_insert(0);
+                *count += 1;
+                *count
+            };
+            let id = path.trim_start_matches("/v1/vulns/");
+            let (status, body) = match path.as_str() {
+                "/v1/querybatch" if count == 1 => ("429 Too Many Requests", String::new()),
+                "/v1/querybatch" => ("200 OK", r#"{"results":[{"vulns":[{"id":"GO-2023-0001"},{"id":"GO-2023-0002"}]}]}"#.to_string()),
+                "/v1/vulns/GO-2023-0002" if down.load(Ordering::SeqCst) => ("503 Service Unavailable", String::new()),
+                _ => ("200 OK", format!(r#"{{"id":"{}","summary":"Advisory {}"}}"#, id, id)),
+            };
+            let mut stream = stream;
+            let _ = write!(
+                stream,
+                "HTTP/1.1 {}\r\nRetry-After: 0\r\nContent-Type: application/json\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}",
+                status,
+                body.len(),
+                body
+            );
+        }
+    });
+    format!("http://{}", addr)
+}
+
+fn audit(dir: &Path, osv: &str, extra: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-dependencies", "audit"])
+        .args(extra)
+        .arg(dir)
+        .env("DX_OSV_URL", osv)
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .output()
+        .expect("run dx")
+}
+
+// Test that vulnerable Go, npm and PyPI versions are reported with severity and fix, failing the command
+#[test]
+fn dev_dependencies_audit_reports_and_fails() {
+    let osv = osv_mock();
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(
+        dir.path().join("go.mod"),
+        "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgolang.org/x/net v0.7.0\n\tgithub.com/google/uuid v1.3.0 // indirect\n)\n",
+    )
+    .unwrap();
+    fs::write(
+        dir.path().join("package-lock.json"),
+        r#"{"lockfileVersion":3,"packages":{"":{"name":"app"},"node_modules/lodash":{"version":"4.17.20"},"node_modules/left-pad":{"version":"1.3.0"}}}"#,
+    )
+    .unwrap();
+    fs::write(dir.path().join("requirements.txt"), "requests==2.19.0 ; python_version >= \"3.8\"\nflask\n").unwrap();
+
+    let output = audit(dir.path(), &osv, &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("3 vulnerabilidade(s) em 3 de 5 pacotes"), "{}", stdout);
+    assert!(stdout.contains("golang.org/x/net 0.7.0 (Go, go.mod)"), "{}", stdout);
+    assert!(stdout.contains("corrigida em 4.17.21"), "{}", stdout);
+
+    let json = audit(dir.path(), &osv, &["--format", "json", "--fail-on", "critical"]);
+    assert_eq!(json.status.code(), Some(1));
+    let doc: serde_json::Value = serde_json::from_slice(&json.stdout).expect("json");
+    let vulns = doc["vulnerabilities"].as_array().unwrap();
+    // Most severe first; the CVSS vector scores 9.8
+    assert_eq!(vulns[0]["id"], "PYSEC-2018-28");
+    assert_eq!(vulns[0]["severity"], "critical");
+    assert_eq!(vulns[0]["summary"], "Requests sends credentials on redirects to HTTP.");
+    assert_eq!(vulns[1]["severity"], "high");
+    assert_eq!(vulns[2]["severity"], "unknown");
+    assert_eq!(vulns[2]["fixed"][0], "0.13.0");
+
+    // Only the Go module left: its advisory has no severity, so --fail-on high passes
+    fs::remove_file(dir.path().join("package-lock.json")).unwrap();
+    fs::remove_file(dir.path().join("requirements.txt")).unwrap();
+    assert_eq!(audit(dir.path(), &osv, &["--fail-on", "high"]).status.code(), Some(0));
+    assert_eq!(audit(dir.path(), &osv, &[]).status.code(), Some(1));
+}
+
+// Test that 429 and 5xx answers are retried, and that a failed run resumes from the cached responses
+#[test]
+fn dev_dependencies_audit_retries_and_resumes() {
+    let down = Arc::new(AtomicBool::new(true));
+    let hits = Arc::new(Mutex::new(HashMap::new()));
+    let osv = flaky_osv_mock(down.clone(), hits.clone());
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("go.mod"), "module example.com/app\n\ngo 1.21\n\nrequire golang.org/x/net v0.7.0\n").unwrap();
+    let count = |path: &str| hits.lock().unwrap().get(path).copied().unwrap_or(0);
+
+    let output = audit(dir.path(), &osv, &[]);
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert_eq!(output.status.code(), Some(2), "{}", stderr);
+    assert!(stderr.contains("pediu para reduzir o ritmo (HTTP 429)"), "{}", stderr);
+    assert!(stderr.contains("/v1/vulns/GO-2023-0002: HTTP 503") && stderr.contains("(após 5 tentativas)"), "{}", stderr);
+    assert!(stderr.contains("ficam guardadas por 1h; execute de novo"), "{}", stderr);
+    assert_eq!((count("/v1/querybatch"), count("/v1/vulns/GO-2023-0001"), count("/v1/vulns/GO-2023-0002")), (2, 1, 5));
+
+    // Back up: only the advisory that failed is asked again
+    down.store(false, Ordering::SeqCst);
+    let output = audit(dir.path(), &osv, &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("2 vulnerabilidade(s) em 1 de 1 pacotes"), "{}", stdout);
+    assert_eq!((count("/v1/querybatch"), count("/v1/vulns/GO-2023-0001"), count("/v1/vulns/GO-2023-0002")), (2, 1, 6));
+}
+
+// Test that a member of a Cargo workspace is audited with the root Cargo.lock, direct dependencies
+// reported at the manifest that declares them
+#[test]
+fn dev_dependencies_audit_cargo_workspace() {
+    let osv = osv_mock();
+    let dir = tempfile::tempdir().expect("tempdir");
+    let member = dir.path().join("crates").join("core");
+    fs::create_dir_all(&member).unwrap();
+    fs::write(dir.path().join("Cargo.toml"), "[workspace]\nmembers = [\"crates/*\"]\n").unwrap();
+    fs::write(member.join("Cargo.toml"), "[package]\nname = \"core\"\nversion = \"0.1.0\"\n\n[dependencies]\nsmallvec = \"1.6\"\n").unwrap();
+    let registry = "source = \"registry+https://github.com/rust-lang/crates.io-index\"";
+    fs::write(
+        dir.path().join("Cargo.lock"),
+        format!("version = 3\n\n[[package]]\nname = \"core\"\nversion = \"0.1.0\"\n\n[[package]]\nname = \"itoa\"\nversion = \"1.0.0\"\n{registry}\n\n[[package]]\nname = \"smallvec\"\nversion = \"1.6.0\"\n{registry}\n"),
+    )
+    .unwrap();
+
+    let output = audit(&member, &osv, &["--format", "json"]);
+    assert_eq!(output.status.code(), Some(1), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).expect("json");
+    assert_eq!(doc["packages"], 2, "{}", doc);
+    assert_eq!(doc["vulnerabilities"][0]["id"], "RUSTSEC-2021-0003", "{}", doc);
+    assert_eq!(doc["vulnerabilities"][0]["source"], "Cargo.toml", "{}", doc);
+
+    let output = audit(dir.path(), &osv, &["--format", "json"]);
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).expect("json");
+    assert_eq!(doc["vulnerabilities"][0]["source"], "crates/core/Cargo.toml", "{}", doc);
+}
+
+// Test the SARIF log of --output sarif: one rule per advisory and results pointing at the manifest line
+#[test]
+fn dev_dependencies_audit_sarif() {
+    let osv = osv_mock();
+    let repo = tempfile::tempdir().expect("tempdir");
+    let dir = repo.path().join("services/api");
+    fs::create_dir_all(&dir).unwrap();
+    fs::write(dir.join("go.mod"), "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgolang.org/x/net v0.7.0\n)\n").unwrap();
+    fs::write(dir.join("requirements.txt"), "flask==3.0.0\nRequests==2.19.0\n").unwrap();
+
+    // Run from the repository root, as CI does, so the URIs are relative to it
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["--output", "sarif", "dev-dependencies", "audit", "--fail-on", "critical", "services/api"])
+        .current_dir(repo.path())
+        .env("DX_OSV_URL", &osv)
+        .env("DX_STATE_DIR", repo.path().join(".dx-state"))
+        .output()
+        .expect("run dx");
+    assert_eq!(output.status.code(), Some(1), "{}", String::from_utf8_lossy(&output.stderr));
+    let log: serde_json::Value = serde_json::from_slice(&output.stdout).expect("sarif");
+    assert_eq!(log["version"], "2.1.0");
+    let run = &log["runs"][0];
+    assert_eq!(run["tool"]["driver"]["name"], "dx");
+    let rules = run["tool"]["driver"]["rules"].as_array().unwrap();
+    assert_eq!(rules.len(), 2);
+    let rule = rules.iter().find(|r| r["id"] == "PYSEC-2018-28").unwrap();
+    assert_eq!(rule["properties"]["security-severity"], "9.0");
+    assert_eq!(rule["helpUri"], "https://osv.dev/vulnerability/PYSEC-2018-28");
+    assert!(rules.iter().find(|r| r["id"] == "GO-2023-1988").unwrap()["properties"].get("security-severity").is_none());
+
+    let results = run["results"].as_array().unwrap();
+    let result = |id: &str| results.iter().find(|r| r["ruleId"] == id).unwrap_or_else(|| panic!("{} missing: {:?}", id, results));
+    let requests = result("PYSEC-2018-28");
+    assert_eq!(requests["level"], "error");
+    let location = &requests["locations"][0]["physicalLocation"];
+    assert_eq!(location["artifactLocation"]["uri"], "services/api/requirements.txt");
+    assert_eq!(location["region"]["startLine"], 2);
+    assert!(requests["message"]["text"].as_str().unwrap().contains("corrigida em 2.20.0"), "{}", requests);
+    // Below --fail-on: reported, but only as a warning
+    let net = result("GO-2023-1988");
+    assert_eq!(net["level"], "warning");
+    assert_eq!(net["locations"][0]["physicalLocation"]["artifactLocation"]["uri"], "services/api/go.mod");
+    assert_eq!(net["locations"][0]["physicalLocation"]["region"]["startLine"], 6);
+    assert!(net["partialFingerprints"].as_object().is_some_and(|f| !f.is_empty()));
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// Go module with one direct dependency whose own go.mod is in the module cache, plus an npm lockfile.
+fn project(dir: &Path) {
+    fs::write(
+        dir.join("go.mod"),
+        "module example.com/app\n\ngo 1.21\n\nrequire github.com/acme/Web v1.0.0\n\nrequire (\n\tgithub.com/acme/log v0.2.0 // indirect\n\tgolang.org/x/text v0.14.0 // indirect\n)\n",
+    )
+    .unwrap();
+    let download = dir.join("modcache/cache/download");
+    fs::create_dir_all(download.join("github.com/acme/!web/@v")).unwrap();
+    fs::write(
+        download.join("github.com/acme/!web/@v/v1.0.0.mod"),
+        "module github.com/acme/Web\n\nrequire (\n\tgithub.com/acme/log v0.1.0\n\tgolang.org/x/text v0.3.0\n)\n",
+    )
+    .unwrap();
+    fs::create_dir_all(download.join("github.com/acme/log/@v")).unwrap();
+    fs::write(download.join("github.com/acme/log/@v/v0.2.0.mod"), "module github.com/acme/log\n\nrequire golang.org/x/text v0.14.0\n").unwrap();
+
+    fs::write(
+        dir.join("package-lock.json"),
+        r#"{"lockfileVersion": 3, "packages": {
+            "": {"name": "web", "dependencies": {"react": "^18"}, "devDependencies": {"vite": "^5"}},
+            "node_modules/react": {"version": "18.2.0", "dependencies": {"loose-envify": "^1"}},
+            "node_modules/loose-envify": {"version": "1.4.0"},
+            "node_modules/vite": {"version": "5.0.0", "dependencies": {"loose-envify": "^2"}},
+            "node_modules/vite/node_modules/loose-envify": {"version": "2.0.0"}
+        }}"#,
+    )
+    .unwrap();
+}
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-dependencies", "graph"])
+        .args(args)
+        .arg(dir)
+        .env("GOMODCACHE", dir.join("modcache"))
+        .output()
+        .expect("failed to run dx dev-dependencies graph")
+}
+
+fn edges(output: &Output) -> Vec<(String, String)> {
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    doc["edges"]
+        .as_array()
+        .unwrap()
+        .iter()
+        .map(|e| (e["from"].as_str().unwrap().to_string(), e["to"].as_str().unwrap().to_string()))
+        .collect()
+}
+
+// Test that the graph follows go.mod files in the module cache and npm's nested node_modules
+#[test]
+fn graph_go_and_npm() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+
+    let all = edges(&dx(tmp.path(), &["--format", "json"]));
+    let has = |from: &str, to: &str| all.iter().any(|(f, t)| f == from && t == to);
+    assert!(has("example.com/app", "github.com/acme/Web@v1.0.0"), "{:?}", all);
+    // Versions are the ones the main go.mod selects
+    assert!(has("github.com/acme/Web@v1.0.0", "github.com/acme/log@v0.2.0"), "{:?}", all);
+    assert!(has("github.com/acme/log@v0.2.0", "golang.org/x/text@v0.14.0"), "{:?}", all);
+    assert!(!has("example.com/app", "golang.org/x/text@v0.14.0"), "{:?}", all);
+    assert!(has("web", "vite@5.0.0"), "{:?}", all);
+    assert!(has("react@18.2.0", "loose-envify@1.4.0"), "{:?}", all);
+    assert!(has("vite@5.0.0", "loose-envify@2.0.0"), "{:?}", all);
+
+    let direct = edges(&dx(tmp.path(), &["--format", "json", "--depth", "1"]));
+    assert_eq!(direct.len(), 3, "{:?}", direct);
+
+    let filtered = edges(&dx(tmp.path(), &["--format", "json", "--filter", "golang.org/x/"]));
+    assert_eq!(
+        filtered,
+        vec![
+            ("example.com/app".to_string(), "github.com/acme/Web@v1.0.0".to_string()),
+            ("github.com/acme/Web@v1.0.0".to_string(), "github.com/acme/log@v0.2.0".to_string()),
+            ("github.com/acme/Web@v1.0.0".to_string(), "golang.org/x/text@v0.14.0".to_string()),
+            ("github.com/acme/log@v0.2.0".to_string(), "golang.org/x/text@v0.14.0".to_string()),
+        ]
+    );
+}
+
+// Test the DOT and Mermaid renderings and the error for a filter matching nothing
+#[test]
+fn graph_dot_and_mermaid() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+
+    let output = dx(tmp.path(), &["--depth", "1"]);
+    let dot = String::from_utf8_lossy(&output.stdout);
+    assert!(dot.starts_with("digraph dependencies {"), "{}", dot);
+    assert!(dot.contains("\"example.com/app\" -> \"github.com/acme/Web@v1.0.0\";"), "{}", dot);
+
+    let output = dx(tmp.path(), &["--format", "mermaid", "--filter", "react"]);
+    let mermaid = String::from_utf8_lossy(&output.stdout);
+    assert!(mermaid.starts_with("graph LR\n"), "{}", mermaid);
+    assert!(mermaid.contains("[\"react 18.2.0\"]"), "{}", mermaid);
+    assert!(mermaid.contains("[[\"web\"]]"), "{}", mermaid);
+    assert!(!mermaid.contains("vite"), "{}", mermaid);
+
+    let output = dx(tmp.path(), &["--filter", "nothing.example"]);
+    assert_eq!(output.status.code(), Some(1));
+}
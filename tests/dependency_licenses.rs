@@ -0,0 +1,123 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+const MIT: &str = "MIT License\n\nPermission is hereby granted, free of charge, to any person obtaining a copy\n";
+const GPL3: &str = "GNU GENERAL PUBLIC LICENSE\nVersion 3, 29 June 2007\n";
+
+/// A Go, npm and Python project whose licenses are all on disk (module cache, lockfile, virtualenv).
+fn project(dir: &Path) {
+    fs::write(
+        dir.join("go.mod"),
+        "module example.com/app\n\ngo 1.21\n\nrequire (\n\tgithub.com/acme/Tools v1.2.0\n\tgithub.com/acme/util v0.3.1 // indirect\n)\n",
+    )
+    .unwrap();
+    let cache = dir.join("modcache");
+    fs::create_dir_all(cache.join("github.com/acme/!tools@v1.2.0")).unwrap();
+    fs::write(cache.join("github.com/acme/!tools@v1.2.0/LICENSE"), MIT).unwrap();
+    fs::create_dir_all(cache.join("github.com/acme/util@v0.3.1")).unwrap();
+    fs::write(cache.join("github.com/acme/util@v0.3.1/COPYING"), GPL3).unwrap();
+
+    fs::write(
+        dir.join("package-lock.json"),
+        r#"{"lockfileVersion": 3, "packages": {
+            "": {"name": "app"},
+            "node_modules/left-pad": {"version": "1.3.0", "license": "WTFPL"},
+            "node_modules/react": {"version": "18.2.0", "license": "MIT"}
+        }}"#,
+    )
+    .unwrap();
+
+    let dist = dir.join(".venv/lib/python3.12/site-packages/Requests-2.31.0.dist-info");
+    fs::create_dir_all(&dist).unwrap();
+    fs::write(
+        dist.join("METADATA"),
+        "Metadata-Version: 2.1\nName: requests\nVersion: 2.31.0\nClassifier: License :: OSI Approved :: Apache Software License\n\nBody\n",
+    )
+    .unwrap();
+    let dist = dir.join(".venv/lib/python3.12/site-packages/mystery-0.1.dist-info");
+    fs::create_dir_all(&dist).unwrap();
+    fs::write(dist.join("METADATA"), "Metadata-Version: 2.1\nName: mystery\nVersion: 0.1\n").unwrap();
+}
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("GOMODCACHE", dir.join("modcache"))
+        .output()
+        .expect("failed to run dx dev-dependencies licenses")
+}
+
+// Test that licenses are resolved per ecosystem and a denied one fails the command
+#[test]
+fn licenses_report_and_deny_list() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses", "--format", "json"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    let license = |name: &str| {
+        let deps = doc["dependencies"].as_array().unwrap();
+        deps.iter().find(|d| d["name"] == name).map(|d| d["license"].clone()).unwrap_or_else(|| panic!("{} missing", name))
+    };
+    assert_eq!(license("github.com/acme/Tools"), "MIT");
+    assert_eq!(license("github.com/acme/util"), "GPL-3.0");
+    assert_eq!(license("left-pad"), "WTFPL");
+    assert_eq!(license("requests"), "Apache-2.0");
+    assert_eq!(license("mystery"), serde_json::Value::Null);
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses", "--deny", "GPL-*"]);
+    assert_eq!(output.status.code(), Some(1));
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("Licenças proibidas (1)"), "{}", stdout);
+    assert!(stdout.contains("github.com/acme/util 0.3.1 (Go) — GPL-3.0 (modcache/"), "{}", stdout);
+    assert!(stdout.contains("Sem licença identificada (1)"), "{}", stdout);
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses", "--fail-on-unknown"]);
+    assert_eq!(output.status.code(), Some(1));
+}
+
+// Test that the allow list of dx.yaml forbids every other license
+#[test]
+fn licenses_allow_list_from_dx_yaml() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+    fs::write(tmp.path().join("dx.yaml"), "licenses:\n  allow: [MIT, Apache-2.0, GPL-3.0]\n").unwrap();
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses"]);
+    assert_eq!(output.status.code(), Some(1));
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("✗ left-pad 1.3.0 (npm) — WTFPL (package-lock.json)"), "{}", stdout);
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses", "--allow", "WTFPL"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stdout));
+}
+
+// Test the SARIF log: forbidden licenses are errors, unknown ones notes unless --fail-on-unknown
+#[test]
+fn licenses_sarif() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses", "--format", "sarif", "--deny", "GPL-*"]);
+    assert_eq!(output.status.code(), Some(1));
+    let log: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid SARIF");
+    let results = log["runs"][0]["results"].as_array().unwrap();
+    assert_eq!(results.len(), 2, "{:?}", results);
+    let util = results.iter().find(|r| r["ruleId"] == "dx/license-forbidden").unwrap();
+    assert_eq!(util["level"], "error");
+    assert!(util["message"]["text"].as_str().unwrap().contains("github.com/acme/util 0.3.1 (Go)"), "{}", util);
+    assert!(util["locations"][0]["physicalLocation"]["artifactLocation"]["uri"].as_str().unwrap().ends_with("go.mod"));
+    assert_eq!(util["locations"][0]["physicalLocation"]["region"]["startLine"], 7);
+    let mystery = results.iter().find(|r| r["ruleId"] == "dx/license-unknown").unwrap();
+    assert_eq!(mystery["level"], "note");
+
+    let output = dx(tmp.path(), &["dev-dependencies", "licenses", "--format", "sarif", "--fail-on-unknown"]);
+    assert_eq!(output.status.code(), Some(1));
+    let log: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid SARIF");
+    assert_eq!(log["runs"][0]["results"][0]["level"], "error");
+}
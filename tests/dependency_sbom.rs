@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// SHA-256 of the bytes 0..32, as go.sum (base64) and as the SBOM (hex) write it.
+const H1: &str = "AAECAwQFBgcICQoLDA0ODxAREhMUFRYXGBkaGxwdHh8=";
+const H1_HEX: &str = "000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f";
+
+/// A Go, npm and Python project with hashes in go.sum, package-lock.json and requirements.txt.
+fn project(dir: &Path) {
+    fs::write(dir.join("go.mod"), "module example.com/app\n\ngo 1.21\n\nrequire github.com/acme/tools v1.2.0\n").unwrap();
+    fs::write(
+        dir.join("go.sum"),
+        format!("github.com/acme/tools v1.2.0 h1:{}\ngithub.com/acme/tools v1.2.0/go.mod h1:{}\n", H1, H1),
+    )
+    .unwrap();
+    fs::write(
+        dir.join("package-lock.json"),
+        r#"{"lockfileVersion": 3, "packages": {
+            "": {"name": "app", "dependencies": {"@scope/ui": "^1.0.0"}},
+            "node_modules/@scope/ui": {"version": "1.0.0", "license": "MIT", "integrity": "sha1-AAECAwQFBgcICQoLDA0ODxAREhM=", "dependencies": {"left-pad": "1"}},
+            "node_modules/left-pad": {"version": "1.3.0", "license": "WTFPL"}
+        }}"#,
+    )
+    .unwrap();
+    fs::write(dir.join("requirements.txt"), format!("requests==2.31.0 \\\n    --hash=sha256:{}\n", H1_HEX)).unwrap();
+}
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("GOMODCACHE", dir.join("modcache"))
+        .env("SOURCE_DATE_EPOCH", "1700000000")
+        .output()
+        .expect("failed to run dx dev-dependencies sbom")
+}
+
+// Test that the CycloneDX SBOM lists every ecosystem with purls, lockfile hashes, licenses and the dependency graph
+#[test]
+fn sbom_cyclonedx_has_hashes_and_dependencies() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+
+    let output = dx(tmp.path(), &["dev-dependencies", "sbom"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("SBOM CycloneDX 1.5: 4 componente(s), 3 com hash, 2 com licença."));
+    let bom: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    assert_eq!(bom["bomFormat"], "CycloneDX");
+    assert_eq!(bom["metadata"]["timestamp"], "2023-11-14T22:13:20Z");
+    assert_eq!(bom["metadata"]["component"]["name"], "example.com/app");
+
+    let components = bom["components"].as_array().unwrap();
+    let find = |purl: &str| components.iter().find(|c| c["purl"] == purl).unwrap_or_else(|| panic!("{} missing: {:#}", purl, bom));
+    let go = find("pkg:golang/github.com/acme/tools@v1.2.0");
+    assert_eq!(go["hashes"], serde_json::json!([{"alg": "SHA-256", "content": H1_HEX}]));
+    let ui = find("pkg:npm/%40scope/ui@1.0.0");
+    assert_eq!(ui["hashes"][0]["content"], "000102030405060708090a0b0c0d0e0f10111213");
+    assert_eq!(ui["licenses"][0]["license"]["id"], "MIT");
+    assert_eq!(find("pkg:pypi/requests@2.31.0")["hashes"][0]["alg"], "SHA-256");
+    assert!(find("pkg:npm/left-pad@1.3.0").get("hashes").is_none());
+
+    let dependencies = bom["dependencies"].as_array().unwrap();
+    let depends_on = |r: &str| dependencies.iter().find(|d| d["ref"] == r).map(|d| d["dependsOn"].clone()).unwrap();
+    assert_eq!(depends_on("pkg:npm/%40scope/ui@1.0.0"), serde_json::json!(["pkg:npm/left-pad@1.3.0"]));
+    let root = depends_on(bom["metadata"]["component"]["bom-ref"].as_str().unwrap());
+    assert!(root.as_array().unwrap().contains(&serde_json::json!("pkg:golang/github.com/acme/tools@v1.2.0")), "{}", root);
+}
+
+// Test that the SPDX document has checksums, purls and DEPENDS_ON relationships from the project
+#[test]
+fn sbom_spdx_document() {
+    let tmp = tempfile::tempdir().unwrap();
+    project(tmp.path());
+
+    let output = dx(tmp.path(), &["dev-dependencies", "sbom", "--format", "spdx"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    assert_eq!(doc["spdxVersion"], "SPDX-2.3");
+    assert_eq!(doc["creationInfo"]["created"], "2023-11-14T22:13:20Z");
+
+    let packages = doc["packages"].as_array().unwrap();
+    assert_eq!(packages.len(), 5, "project plus 4 dependencies");
+    let requests = packages.iter().find(|p| p["name"] == "requests").unwrap();
+    assert_eq!(requests["checksums"], serde_json::json!([{"algorithm": "SHA256", "checksumValue": H1_HEX}]));
+    assert_eq!(requests["externalRefs"][0]["referenceLocator"], "pkg:pypi/requests@2.31.0");
+    let ui = packages.iter().find(|p| p["name"] == "@scope/ui").unwrap();
+    assert_eq!(ui["licenseDeclared"], "MIT");
+
+    let relationships = doc["relationships"].as_array().unwrap();
+    let depends = |from: &serde_json::Value, to: &serde_json::Value| {
+        relationships.iter().any(|r| r["relationshipType"] == "DEPENDS_ON" && r["spdxElementId"] == *from && r["relatedSpdxElement"] == *to)
+    };
+    let left_pad = packages.iter().find(|p| p["name"] == "left-pad").unwrap();
+    assert!(depends(&ui["SPDXID"], &left_pad["SPDXID"]), "{:#}", doc["relationships"]);
+    assert!(depends(&serde_json::json!("SPDXRef-Package-project"), &requests["SPDXID"]), "{:#}", doc["relationships"]);
+}
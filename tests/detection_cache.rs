@@ -0,0 +1,72 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("DX_CONFIG_DIR", dir.join(".dx-config"))
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .env("DX_CACHE_DIR", dir.join(".dx-cache"))
+        .env_remove("DX_DETECTION_CACHE")
+        .env_remove("DX_OUTPUT")
+        .output()
+        .expect("failed to run dx")
+}
+
+fn list(dir: &Path, extra: &[&str]) -> serde_json::Value {
+    let mut args = vec!["--output", "json", "dev-dependencies", "list"];
+    args.extend_from_slice(extra);
+    args.push("app");
+    let output = dx(dir, &args);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    serde_json::from_slice(&output.stdout).unwrap()
+}
+
+fn entries(dir: &Path) -> Vec<PathBuf> {
+    fs::read_dir(dir.join(".dx-cache/detection")).map(|d| d.flatten().map(|e| e.path()).collect()).unwrap_or_default()
+}
+
+// Test that a repeat run reads the detection from the cache until the manifest changes
+#[test]
+fn dev_dependencies_list_reuses_detection_until_manifest_changes() {
+    let tmp = tempfile::tempdir().unwrap();
+    let manifest = tmp.path().join("app/package.json");
+    fs::create_dir_all(manifest.parent().unwrap()).unwrap();
+    fs::write(&manifest, r#"{"devDependencies": {"vitest": "^1.6.0"}}"#).unwrap();
+
+    assert_eq!(list(tmp.path(), &[])["dependencies"][0]["name"], "vitest");
+    let cached = entries(tmp.path());
+    assert_eq!(cached.len(), 1, "{:?}", cached);
+
+    // An entry edited behind dx's back shows that the second run does not parse the manifest
+    let entry = fs::read_to_string(&cached[0]).unwrap().replace("vitest", "from-cache");
+    fs::write(&cached[0], entry).unwrap();
+    assert_eq!(list(tmp.path(), &[])["dependencies"][0]["name"], "from-cache");
+    assert_eq!(list(tmp.path(), &["--no-cache"])["dependencies"][0]["name"], "vitest");
+
+    fs::write(&manifest, r#"{"devDependencies": {"jest": "29.7.0"}}"#).unwrap();
+    assert_eq!(list(tmp.path(), &[])["dependencies"][0]["name"], "jest");
+}
+
+// Test that --no-cache writes nothing and dx cache clear empties the cache
+#[test]
+fn cache_clear_removes_entries() {
+    let tmp = tempfile::tempdir().unwrap();
+    fs::create_dir_all(tmp.path().join("app")).unwrap();
+    fs::write(tmp.path().join("app/go.mod"), "module example.com/app\n\ngo 1.21\n").unwrap();
+
+    list(tmp.path(), &["--no-cache"]);
+    assert!(entries(tmp.path()).is_empty());
+    list(tmp.path(), &[]);
+    assert_eq!(entries(tmp.path()).len(), 1);
+
+    let output = dx(tmp.path(), &["cache", "clear"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Cache de detecção: 1 entrada(s) removida(s)"), "{}", stdout);
+    assert!(entries(tmp.path()).is_empty());
+}
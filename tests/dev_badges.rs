@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .output()
+        .expect("failed to run dx dev-badges")
+}
+
+// Test that --recursive gives each package README its own stack and coverage badges
+#[test]
+fn dev_badges_recursive_syncs_package_readmes() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::write(root.join("README.md"), "# Monorepo\n").unwrap();
+
+    let api = root.join("services/api");
+    fs::create_dir_all(&api).unwrap();
+    fs::write(api.join("go.mod"), "module example.com/api\n\ngo 1.21\n").unwrap();
+    fs::write(api.join("README.md"), "# API\n\nServiço de pedidos.\n").unwrap();
+    fs::write(api.join("coverage.out"), "mode: set\napi/main.go:3.13,5.2 3 1\napi/main.go:7.13,9.2 1 0\n").unwrap();
+
+    let web = root.join("apps/web");
+    fs::create_dir_all(web.join("coverage")).unwrap();
+    fs::write(web.join("package.json"), r#"{"name": "web"}"#).unwrap();
+    fs::write(web.join("README.md"), "# Web\n").unwrap();
+    fs::write(web.join("coverage/lcov.info"), "SF:src/a.js\nLF:10\nLH:5\nend_of_record\n").unwrap();
+
+    let tool = root.join("tools/gen");
+    fs::create_dir_all(&tool).unwrap();
+    fs::write(tool.join("Cargo.toml"), "[package]\nname = \"gen\"\n").unwrap();
+
+    let output = dx(root, &["dev-badges", "--recursive"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("tools/gen: sem README.md, ignorado"), "{}", stdout);
+
+    let api_readme = fs::read_to_string(api.join("README.md")).unwrap();
+    assert!(api_readme.contains("Stack-Go"), "{}", api_readme);
+    assert!(api_readme.contains("Coverage-75%25-yellow"), "{}", api_readme);
+    assert!(api_readme.contains("Serviço de pedidos."), "{}", api_readme);
+    let web_readme = fs::read_to_string(web.join("README.md")).unwrap();
+    assert!(web_readme.contains("Stack-Node.js"), "{}", web_readme);
+    assert!(web_readme.contains("Coverage-50%25-red"), "{}", web_readme);
+    assert!(fs::read_to_string(root.join("README.md")).unwrap().contains("dx-cli:badges:start"));
+    assert!(!tool.join("README.md").exists());
+
+    // A second run finds everything in sync; new coverage updates only that package
+    let output = dx(root, &["dev-badges", "--recursive"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("0 README(s) atualizado(s), 3 em dia"), "{}", stdout);
+    fs::write(web.join("coverage/lcov.info"), "SF:src/a.js\nLF:10\nLH:9\nend_of_record\n").unwrap();
+    let output = dx(root, &["dev-badges", "--recursive"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("1 README(s) atualizado(s), 2 em dia"), "{}", stdout);
+    assert!(fs::read_to_string(web.join("README.md")).unwrap().contains("Coverage-90%25-brightgreen"));
+
+    let output = dx(root, &["dev-badges", "clean", "--recursive"]);
+    assert!(output.status.success());
+    assert!(!fs::read_to_string(api.join("README.md")).unwrap().contains("dx-cli:badges"));
+    assert!(!fs::read_to_string(web.join("README.md")).unwrap().contains("dx-cli:badges"));
+}
+
+// Test that the monorepo fixture reports each package with its own stack and services, and the root with all of them
+#[test]
+fn dev_badges_recursive_monorepo_fixture() {
+    let state = tempfile::tempdir().unwrap();
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-badges", "--recursive", "--no-save", "test-projects/monorepo"])
+        .env("DX_STATE_DIR", state.path())
+        .output()
+        .expect("failed to run dx dev-badges");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("e de 3 pacote(s)"), "{}", stdout);
+    let line = |package: &str| stdout.lines().find(|l| l.starts_with(&format!("  ~ {}:", package))).unwrap_or_default().to_string();
+    let (root, api, web, worker) = (line("."), line("services/api"), line("apps/web"), line("workers/events"));
+    assert!(api.contains("Stack-Go") && api.contains("Kafka") && api.contains("MongoDB") && !api.contains("Redis"), "{}", stdout);
+    assert!(web.contains("Stack-Node.js") && !web.contains("Dev_Service"), "{}", stdout);
+    assert!(worker.contains("Stack-Python") && worker.contains("Kafka") && worker.contains("Redis") && !worker.contains("MongoDB"), "{}", stdout);
+    assert!(root.contains("Kafka") && root.contains("MongoDB") && root.contains("Redis"), "{}", stdout);
+}
+
+// Test that infrastructure badges follow the dev-infra detection and that frameworks and Docker files get badges
+#[test]
+fn dev_badges_infra_frameworks_and_docker() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::write(
+        root.join("go.mod"),
+        "module example.com/svc\n\ngo 1.22\n\nrequire (\n\tgithub.com/gin-gonic/gin v1.9.1\n\tgithub.com/segmentio/kafka-go v0.4.47\n\tgithub.com/rabbitmq/amqp091-go v1.9.0\n\tgo.mongodb.org/mongo-driver v1.15.0\n)\n",
+    )
+    .unwrap();
+    fs::write(
+        root.join("main.go"),
+        "package main\n\nimport (\n\t\"github.com/gin-gonic/gin\"\n\tamqp \"github.com/rabbitmq/amqp091-go\"\n\t\"github.com/segmentio/kafka-go\"\n)\n\nfunc main() {}\n",
+    )
+    .unwrap();
+    fs::write(root.join("Dockerfile"), "FROM golang:1.22\n").unwrap();
+    fs::write(root.join("compose.yaml"), "services: {}\n").unwrap();
+
+    let output = dx(root, &["dev-badges", "--no-save"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("Framework-Gin"), "{}", stdout);
+    assert!(stdout.contains("Kafka-Dev_Service"), "{}", stdout);
+    assert!(stdout.contains("RabbitMQ-Dev_Service"), "{}", stdout);
+    assert!(stdout.contains("Docker-Dockerfile_%2B_Compose"), "{}", stdout);
+    // Declared in go.mod but never imported
+    assert!(!stdout.contains("MongoDB"), "{}", stdout);
+
+    let web = root.join("web");
+    fs::create_dir_all(&web).unwrap();
+    fs::write(web.join("package.json"), r#"{"name": "web", "dependencies": {"express": "^4.18.2"}, "devDependencies": {"fastify": "^4.0.0"}}"#).unwrap();
+    let output = dx(&web, &["dev-badges", "--no-save"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("Framework-Express") && !stdout.contains("Fastify") && !stdout.contains("Docker"), "{}", stdout);
+}
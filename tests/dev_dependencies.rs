@@ -1,4 +1,8 @@
+use std::collections::HashMap;
 use std::fs;
+use std::io::{BufRead, BufReader, Write};
+use std::net::TcpListener;
+use std::path::Path;
 use std::process::Command;
 use tempfile;
 
@@ -73,6 +77,20 @@ fn dev_dependencies_list_go() {
     assert!(stdout.contains("github.com/gin-gonic/gin"));
 }
 
+#[test]
+fn dev_dependencies_list_rust() {
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let output = Command::new(exe)
+        .args(["dev-dependencies", "list"])
+        .current_dir("test-projects/rust")
+        .output()
+        .expect("run list");
+    assert!(output.status.success());
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("tower"));
+    assert!(stdout.contains("http-body-util"));
+}
+
 #[test]
 fn dev_dependencies_list_php() {
     let exe = env!("CARGO_BIN_EXE_dx");
@@ -137,3 +155,420 @@ fn dev_dependencies_list_flink() {
     let stdout = String::from_utf8_lossy(&output.stdout);
     assert!(stdout.contains("flink-test-utils"));
 }
+
+/// Maven repository serving `files` by path (404 for anything else).
+fn maven_repository_mock(files: HashMap<&'static str, &'static str>) -> String {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut request_line = String::new();
+            reader.read_line(&mut request_line).unwrap();
+            loop {
+                let mut header = String::new();
+                reader.read_line(&mut header).unwrap();
+                if header.trim().is_empty() {
+                    break;
+                }
+            }
+            let path = request_line.split_whitespace().nth(1).unwrap_or("");
+            let (status, body) = match files.get(path) {
+                Some(body) => ("200 OK", *body),
+                None => ("404 Not Found", ""),
+            };
+            let mut stream = stream;
+            let _ = write!(stream, "HTTP/1.1 {}\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}", status, body.len(), body);
+        }
+    });
+    format!("http://{}", addr)
+}
+
+/// `dx --output json dev-dependencies list` of `dir`, against the mock repository, as name → version.
+fn java_list(dir: &Path, repository: &str) -> HashMap<String, String> {
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["--output", "json", "dev-dependencies", "list"])
+        .arg(dir)
+        .env("DX_MAVEN_REPOSITORY", repository)
+        .env("DX_CACHE_DIR", dir.join(".cache"))
+        .env("DX_STATE_DIR", dir.join(".state"))
+        .output()
+        .expect("run list");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    list["dependencies"]
+        .as_array()
+        .unwrap()
+        .iter()
+        .map(|d| (d["name"].as_str().unwrap().to_string(), d["version"].as_str().unwrap().to_string()))
+        .collect()
+}
+
+const TESTCONTAINERS_BOM: &str = r#"<project>
+  <groupId>org.testcontainers</groupId><artifactId>testcontainers-bom</artifactId><version>1.19.0</version>
+  <properties><tc.version>1.19.0</tc.version></properties>
+  <dependencyManagement><dependencies>
+    <dependency><groupId>org.testcontainers</groupId><artifactId>postgresql</artifactId><version>${tc.version}</version></dependency>
+    <dependency><groupId>org.assertj</groupId><artifactId>assertj-core</artifactId><version>3.0.0</version></dependency>
+  </dependencies></dependencyManagement>
+</project>"#;
+const TESTCONTAINERS_BOM_PATH: &str = "/org/testcontainers/testcontainers-bom/1.19.0/testcontainers-bom-1.19.0.pom";
+
+// Test that a multi-module Maven build lists the test dependencies of every module with the versions
+// its properties, parent dependencyManagement and imported BOM (from the repository) resolve to
+#[test]
+fn dev_dependencies_list_maven_multi_module_effective_versions() {
+    let repository = maven_repository_mock(HashMap::from([(TESTCONTAINERS_BOM_PATH, TESTCONTAINERS_BOM)]));
+    let dir = tempfile::tempdir().unwrap();
+    let root = dir.path();
+    fs::write(
+        root.join("pom.xml"),
+        r#"<?xml version="1.0"?>
+<project>
+  <groupId>com.acme</groupId><artifactId>shop</artifactId><version>2.0.0</version><packaging>pom</packaging>
+  <modules><module>core</module><module>api</module></modules>
+  <properties><junit.version>5.10.1</junit.version></properties>
+  <dependencyManagement><dependencies>
+    <!-- own entries win over the BOM -->
+    <dependency><groupId>org.assertj</groupId><artifactId>assertj-core</artifactId><version>3.24.2</version></dependency>
+    <dependency><groupId>org.junit.jupiter</groupId><artifactId>junit-jupiter</artifactId><version>${junit.version}</version></dependency>
+    <dependency>
+      <groupId>org.testcontainers</groupId><artifactId>testcontainers-bom</artifactId><version>1.19.0</version>
+      <type>pom</type><scope>import</scope>
+    </dependency>
+  </dependencies></dependencyManagement>
+</project>"#,
+    )
+    .unwrap();
+    for (module, deps) in [
+        (
+            "core",
+            r#"<dependency><groupId>org.junit.jupiter</groupId><artifactId>junit-jupiter</artifactId><scope>test</scope></dependency>
+    <dependency><groupId>org.testcontainers</groupId><artifactId>postgresql</artifactId><scope>test</scope></dependency>
+    <dependency><groupId>com.acme</groupId><artifactId>api</artifactId><version>${project.version}</version><scope>test</scope></dependency>"#,
+        ),
+        (
+            "api",
+            r#"<dependency><groupId>org.assertj</groupId><artifactId>assertj-core</artifactId><scope>test</scope></dependency>
+    <dependency><groupId>org.mockito</groupId><artifactId>mockito-core</artifactId><version>${mockito.version}</version><scope>test</scope></dependency>
+    <dependency><groupId>org.slf4j</groupId><artifactId>slf4j-api</artifactId><version>2.0.9</version></dependency>"#,
+        ),
+    ] {
+        fs::create_dir_all(root.join(module)).unwrap();
+        let pom = format!(
+            r#"<project>
+  <parent><groupId>com.acme</groupId><artifactId>shop</artifactId><version>2.0.0</version></parent>
+  <artifactId>{}</artifactId>
+  <properties><mockito.version>5.7.0</mockito.version></properties>
+  <dependencies>
+    {}
+  </dependencies>
+</project>"#,
+            module, deps
+        );
+        fs::write(root.join(module).join("pom.xml"), pom).unwrap();
+    }
+
+    let deps = java_list(root, &repository);
+    assert_eq!(deps["org.junit.jupiter:junit-jupiter"], "5.10.1");
+    assert_eq!(deps["org.testcontainers:postgresql"], "1.19.0");
+    assert_eq!(deps["org.assertj:assertj-core"], "3.24.2");
+    assert_eq!(deps["org.mockito:mockito-core"], "5.7.0");
+    assert!(!deps.contains_key("com.acme:api"), "modules of the build are not dependencies: {:?}", deps);
+    assert!(!deps.contains_key("org.slf4j:slf4j-api"), "only test dependencies: {:?}", deps);
+}
+
+// Test that a multi-project Gradle build resolves versions from gradle.properties, the version
+// catalog and a platform BOM
+#[test]
+fn dev_dependencies_list_gradle_multi_project_effective_versions() {
+    let repository = maven_repository_mock(HashMap::from([(TESTCONTAINERS_BOM_PATH, TESTCONTAINERS_BOM)]));
+    let dir = tempfile::tempdir().unwrap();
+    let root = dir.path();
+    fs::write(root.join("settings.gradle.kts"), "rootProject.name = \"shop\"\ninclude(\"app\", \":lib\")\n").unwrap();
+    fs::write(root.join("build.gradle.kts"), "plugins {\n    java\n}\n").unwrap();
+    fs::write(root.join("gradle.properties"), "junitVersion=5.10.1\n").unwrap();
+    fs::create_dir_all(root.join("gradle")).unwrap();
+    fs::write(
+        root.join("gradle/libs.versions.toml"),
+        "[versions]\nassertj = \"3.24.2\"\n\n[libraries]\nassertj-core = { module = \"org.assertj:assertj-core\", version.ref = \"assertj\" }\n",
+    )
+    .unwrap();
+    fs::create_dir_all(root.join("app")).unwrap();
+    fs::write(
+        root.join("app/build.gradle.kts"),
+        r#"dependencies {
+    implementation(project(":lib"))
+    testImplementation("org.junit.jupiter:junit-jupiter:${junitVersion}")
+    testImplementation(libs.assertj.core)
+    testImplementation(platform("org.testcontainers:testcontainers-bom:1.19.0"))
+    testImplementation("org.testcontainers:postgresql") {
+        because("integration tests")
+    }
+}
+"#,
+    )
+    .unwrap();
+    fs::create_dir_all(root.join("lib")).unwrap();
+    fs::write(root.join("lib/build.gradle"), "dependencies {\n    testImplementation 'org.mockito:mockito-core:5.7.0'\n}\n").unwrap();
+
+    let deps = java_list(root, &repository);
+    assert_eq!(deps["org.junit.jupiter:junit-jupiter"], "5.10.1");
+    assert_eq!(deps["org.assertj:assertj-core"], "3.24.2");
+    assert_eq!(deps["org.testcontainers:postgresql"], "1.19.0");
+    assert_eq!(deps["org.mockito:mockito-core"], "5.7.0");
+    assert_eq!(deps.len(), 4, "{:?}", deps);
+}
+
+#[test]
+fn dev_dependencies_list_dotnet() {
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let output = Command::new(exe)
+        .args(["dev-dependencies", "list"])
+        .current_dir("test-projects/dotnet")
+        .env("DX_NUGET_FEED", "http://127.0.0.1:9")
+        .output()
+        .expect("run list");
+    assert!(output.status.success());
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("xunit"), "{}", stdout);
+    assert!(!stdout.contains("RabbitMQ.Client"), "{}", stdout);
+}
+
+// Test that a .NET solution lists the packages of its test projects and the PrivateAssets ones, with
+// the versions packages.lock.json resolved, falling back to Directory.Packages.props and VersionOverride
+#[test]
+fn dev_dependencies_list_dotnet_effective_versions() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    fs::write(
+        dir.join("Directory.Packages.props"),
+        r#"<Project>
+  <PropertyGroup><ManagePackageVersionsCentrally>true</ManagePackageVersionsCentrally></PropertyGroup>
+  <ItemGroup>
+    <PackageVersion Include="Serilog" Version="3.1.1" />
+    <PackageVersion Include="StyleCop.Analyzers" Version="1.1.118" />
+    <PackageVersion Include="xunit" Version="2.6.0" />
+    <PackageVersion Include="Moq" Version="4.20.0" />
+  </ItemGroup>
+</Project>"#,
+    )
+    .unwrap();
+    fs::create_dir_all(dir.join("src").join("App")).unwrap();
+    fs::write(
+        dir.join("src").join("App").join("App.csproj"),
+        r#"<Project Sdk="Microsoft.NET.Sdk">
+  <ItemGroup>
+    <PackageReference Include="Serilog" />
+    <PackageReference Include="StyleCop.Analyzers" PrivateAssets="all" />
+  </ItemGroup>
+</Project>"#,
+    )
+    .unwrap();
+    fs::create_dir_all(dir.join("tests").join("App.Tests")).unwrap();
+    fs::write(
+        dir.join("tests").join("App.Tests").join("App.Tests.csproj"),
+        r#"<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup><IsTestProject>true</IsTestProject></PropertyGroup>
+  <ItemGroup>
+    <PackageReference Include="xunit" />
+    <PackageReference Include="Moq" VersionOverride="4.20.70" />
+  </ItemGroup>
+</Project>"#,
+    )
+    .unwrap();
+    fs::write(
+        dir.join("tests").join("App.Tests").join("packages.lock.json"),
+        r#"{"version": 1, "dependencies": {"net8.0": {
+  "xunit": {"type": "Direct", "requested": "[2.6.0, )", "resolved": "2.6.6"},
+  "App": {"type": "Project"}
+}}}"#,
+    )
+    .unwrap();
+    let feed = maven_repository_mock(HashMap::from([("/xunit/index.json", r#"{"versions":["2.6.6","2.9.0-pre.1"]}"#)]));
+
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["--output", "json", "dev-dependencies", "list"])
+        .arg(dir)
+        .env("DX_NUGET_FEED", &feed)
+        .env("DX_CACHE_DIR", dir.join(".cache"))
+        .env("DX_STATE_DIR", dir.join(".state"))
+        .output()
+        .expect("run list");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    let versions: HashMap<String, String> = list["dependencies"]
+        .as_array()
+        .unwrap()
+        .iter()
+        .map(|d| (d["name"].as_str().unwrap().to_string(), d["version"].as_str().unwrap().to_string()))
+        .collect();
+    assert_eq!(versions.get("xunit").map(String::as_str), Some("2.6.6"), "{}", list);
+    assert_eq!(versions.get("Moq").map(String::as_str), Some("4.20.70"), "{}", list);
+    assert_eq!(versions.get("StyleCop.Analyzers").map(String::as_str), Some("1.1.118"), "{}", list);
+    assert!(!versions.contains_key("Serilog"), "{}", list);
+}
+
+/// A Cargo workspace: two members from `crates/*` (one excluded), a dependency inherited from
+/// `[workspace.dependencies]` and a Cargo.lock at the root with two versions of proptest.
+fn cargo_workspace(dir: &Path) {
+    fs::write(
+        dir.join("Cargo.toml"),
+        r#"[workspace]
+members = ["crates/*"]
+exclude = ["crates/legacy"]
+
+[workspace.dependencies]
+tokio = { version = "1", default-features = false, features = ["macros"] }
+"#,
+    )
+    .unwrap();
+    for member in ["api", "helpers", "legacy"] {
+        fs::create_dir_all(dir.join("crates").join(member)).unwrap();
+    }
+    fs::write(
+        dir.join("crates/api/Cargo.toml"),
+        r#"[package]
+name = "api"
+version = "0.1.0"
+
+[dependencies]
+serde = "1"
+
+[dev-dependencies]
+tokio = { workspace = true, features = ["rt"] }
+insta = "=1.34.0"
+helpers = { path = "../helpers" }
+"#,
+    )
+    .unwrap();
+    fs::write(dir.join("crates/helpers/Cargo.toml"), "[package]\nname = \"helpers\"\nversion = \"0.1.0\"\n\n[dev-dependencies]\nproptest = \"1.2\"\n").unwrap();
+    fs::write(dir.join("crates/legacy/Cargo.toml"), "[package]\nname = \"legacy\"\nversion = \"0.1.0\"\n\n[dev-dependencies]\nmockall = \"0.11\"\n").unwrap();
+    let registry = "source = \"registry+https://github.com/rust-lang/crates.io-index\"";
+    let packages = [("api", "0.1.0", ""), ("helpers", "0.1.0", ""), ("insta", "1.34.0", registry), ("proptest", "0.9.6", registry), ("proptest", "1.2.0", registry), ("serde", "1.0.197", registry), ("tokio", "1.36.0", registry)];
+    let lock: String = packages.iter().map(|(name, version, source)| format!("[[package]]\nname = \"{}\"\nversion = \"{}\"\n{}\n\n", name, version, source)).collect();
+    fs::write(dir.join("Cargo.lock"), format!("version = 3\n\n{}", lock)).unwrap();
+}
+
+// Test that a Cargo workspace lists the dev-dependencies of its members with the locked versions and
+// features, and that the analyzer of a member reads the root Cargo.lock and suggests `cargo update` or
+// `cargo add` depending on the requirement
+#[test]
+fn dev_dependencies_rust_workspace() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    cargo_workspace(dir);
+    let registry = maven_repository_mock(HashMap::from([
+        ("/api/v1/crates/tokio", r#"{"crate":{"max_stable_version":"1.37.0"}}"#),
+        ("/api/v1/crates/insta", r#"{"crate":{"max_stable_version":"1.39.0"}}"#),
+        ("/api/v1/crates/proptest", r#"{"crate":{"max_stable_version":"1.4.0"}}"#),
+    ]));
+    let dx = |args: &[&str], project: &Path| {
+        Command::new(env!("CARGO_BIN_EXE_dx"))
+            .args(args)
+            .arg(project)
+            .env("DX_CRATES_REGISTRY", &registry)
+            .env("DX_CACHE_DIR", dir.join(".cache"))
+            .env("DX_STATE_DIR", dir.join(".state"))
+            .output()
+            .expect("run dx")
+    };
+
+    let output = dx(&["--output", "json", "dev-dependencies", "list"], dir);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(list["stack"], "rust", "{}", list);
+    let deps: HashMap<String, &serde_json::Value> =
+        list["dependencies"].as_array().unwrap().iter().map(|d| (d["name"].as_str().unwrap().to_string(), d)).collect();
+    assert_eq!(deps["tokio"]["version"], "1.36.0", "{}", list);
+    assert_eq!(deps["tokio"]["features"], serde_json::json!(["sem default", "macros", "rt"]), "{}", list);
+    assert_eq!(deps["insta"]["version"], "1.34.0", "{}", list);
+    assert_eq!(deps["proptest"]["version"], "1.2.0", "{}", list);
+    assert_eq!(deps["helpers"]["version"], "path", "{}", list);
+    assert!(!deps.contains_key("mockall") && !deps.contains_key("serde"), "{}", list);
+
+    let api = dir.join("crates").join("api");
+    let output = dx(&["analyzer", "--no-cache"], &api);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let report = fs::read_to_string(api.join(".dx").join("analyzer-report.md")).unwrap();
+    assert!(report.contains("| 1.36.0 | 1.37.0 | `cargo update -p tokio --precise 1.37.0` |"), "{}", report);
+    assert!(report.contains("| 1.34.0 | 1.39.0 | `cargo add insta@1.39.0 --dev` |"), "{}", report);
+}
+
+// Test that an Elixir project lists the packages restricted to dev/test with the mix.lock versions and
+// the analyzer checks them against the Hex repository, and that an Erlang project lists the deps of
+// the test profile of rebar.config
+#[test]
+fn dev_dependencies_elixir_and_erlang() {
+    let tmp = tempfile::tempdir().unwrap();
+    let elixir = tmp.path().join("shop");
+    fs::create_dir_all(&elixir).unwrap();
+    fs::write(
+        elixir.join("mix.exs"),
+        "defmodule Shop.MixProject do\n  use Mix.Project\n\n  def project, do: [app: :shop, deps: deps()]\n\n  \
+         # mix help deps\n  defp deps do\n    [\n      {:amqp, \"~> 3.3\"},\n      {:jason, \"~> 1.4\"},\n      \
+         {:credo, \"~> 1.7\", only: [:dev, :test], runtime: false},\n      {:ex_machina, \"2.7.0\", only: :test},\n      \
+         {:shared, path: \"../shared\", only: :test}\n    ]\n  end\nend\n",
+    )
+    .unwrap();
+    fs::write(
+        elixir.join("mix.lock"),
+        "%{\n  \"amqp\": {:hex, :amqp, \"3.3.0\", \"056d\", [:mix], [], \"hexpm\", \"8d3a\"},\n  \
+         \"credo\": {:hex, :credo, \"1.7.5\", \"6432\", [:mix], [{:jason, \"~> 1.0\", [hex: :jason, repo: \"hexpm\", optional: false]}], \"hexpm\", \"f799\"},\n  \
+         \"jason\": {:hex, :jason, \"1.4.1\", \"af15\", [:mix], [], \"hexpm\", \"fbb0\"},\n}\n",
+    )
+    .unwrap();
+    let erlang = tmp.path().join("orders");
+    fs::create_dir_all(&erlang).unwrap();
+    fs::write(
+        erlang.join("rebar.config"),
+        "%% deps\n{deps, [{epgsql, \"4.7.1\"}, brod]}.\n{profiles, [{test, [{deps, [{meck, \"0.9.2\"}, proper]}]}]}.\n",
+    )
+    .unwrap();
+    let registry = maven_repository_mock(HashMap::from([
+        ("/api/packages/credo", r#"{"name":"credo","latest_stable_version":"1.7.7"}"#),
+        ("/api/packages/ex_machina", r#"{"name":"ex_machina","latest_stable_version":"2.8.0"}"#),
+    ]));
+    let dx = |args: &[&str], project: &Path| {
+        Command::new(env!("CARGO_BIN_EXE_dx"))
+            .args(args)
+            .arg(project)
+            .env("DX_HEX_REGISTRY", &registry)
+            .env("DX_CACHE_DIR", tmp.path().join(".cache"))
+            .env("DX_STATE_DIR", tmp.path().join(".state"))
+            .output()
+            .expect("run dx")
+    };
+
+    let output = dx(&["--output", "json", "dev-dependencies", "list"], &elixir);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(list["stack"], "elixir", "{}", list);
+    assert_eq!(
+        list["dependencies"],
+        serde_json::json!([
+            {"name": "credo", "version": "1.7.5"},
+            {"name": "ex_machina", "version": "2.7.0"},
+            {"name": "shared", "version": "path"}
+        ]),
+        "{}",
+        list
+    );
+
+    let output = dx(&["analyzer", "--no-cache"], &elixir);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let report = fs::read_to_string(elixir.join(".dx").join("analyzer-report.md")).unwrap();
+    assert!(report.contains("| 1.7.5 | 1.7.7 | `mix deps.update credo` |"), "{}", report);
+    assert!(report.contains("| 2.7.0 | 2.8.0 | `mix deps.update ex_machina` |"), "{}", report);
+
+    let output = dx(&["--output", "json", "dev-dependencies", "list"], &erlang);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(list["stack"], "erlang", "{}", list);
+    assert_eq!(
+        list["dependencies"],
+        serde_json::json!([{"name": "meck", "version": "0.9.2"}, {"name": "proper", "version": "?"}]),
+        "{}",
+        list
+    );
+}
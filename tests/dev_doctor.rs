@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::net::TcpListener;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// Executable script in `bin` standing in for a real tool.
+fn fake_tool(bin: &Path, name: &str, script: &str) {
+    let path = bin.join(name);
+    fs::write(&path, format!("#!/bin/sh\n{}\n", script)).unwrap();
+    fs::set_permissions(&path, fs::Permissions::from_mode(0o755)).unwrap();
+}
+
+fn doctor(dir: &Path, bin: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .arg("dev-doctor")
+        .args(args)
+        .arg(dir)
+        .env("PATH", bin)
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .output()
+        .expect("failed to run dx dev-doctor")
+}
+
+// Test that each failed check (old runtime, missing runtime, Docker down, busy port, no .env) gets a fix
+#[test]
+fn dev_doctor_reports_failures_with_fixes() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (project, bin) = (tmp.path().join("app"), tmp.path().join("bin"));
+    fs::create_dir_all(&project).unwrap();
+    fs::create_dir_all(&bin).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/app\n\ngo 1.22\n").unwrap();
+    fs::write(project.join("package.json"), r#"{"name": "web"}"#).unwrap();
+    fs::write(project.join(".env.example"), "API_KEY=\n").unwrap();
+    fake_tool(&bin, "go", "echo 'go version go1.21.5 linux/amd64'");
+    fake_tool(&bin, "docker", "echo 'Cannot connect to the Docker daemon' >&2; exit 1");
+
+    // The application's port comes from the code's PORT default
+    let busy = TcpListener::bind("127.0.0.1:0").unwrap();
+    let port = busy.local_addr().unwrap().port().to_string();
+    fs::write(project.join("main.go"), format!("package main\n\nvar port = getEnv(\"PORT\", \"{}\")\n", port)).unwrap();
+    let output = doctor(&project, &bin, &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("✗ Go: 1.21.5 instalado, o projeto pede 1.22"), "{}", stdout);
+    assert!(stdout.contains("✗ Node.js: 'node' não encontrado no PATH"), "{}", stdout);
+    assert!(stdout.contains("https://nodejs.org"), "{}", stdout);
+    assert!(stdout.contains("✗ Docker: o daemon não responde"), "{}", stdout);
+    assert!(stdout.contains(&format!("✗ porta {} (aplicação): em uso", port)), "{}", stdout);
+    assert!(stdout.contains("✗ .env: ausente"), "{}", stdout);
+    assert!(stdout.contains("cp .env.example .env"), "{}", stdout);
+    drop(busy);
+
+    // Fixed machine: everything passes and the JSON lists each check
+    fake_tool(&bin, "go", "echo 'go version go1.22.3 linux/amd64'");
+    fake_tool(&bin, "node", "echo v20.11.0");
+    fake_tool(&bin, "docker", "echo 26.1.0");
+    fs::write(project.join(".env"), "API_KEY=secret\n").unwrap();
+    let extra = TcpListener::bind("127.0.0.1:0").unwrap().local_addr().unwrap().port().to_string();
+    let output = doctor(&project, &bin, &["--format", "json", "--port", &extra]);
+    assert_eq!(output.status.code(), Some(0), "{}", String::from_utf8_lossy(&output.stdout));
+    let checks: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    let names: Vec<&str> = checks.as_array().unwrap().iter().map(|c| c["name"].as_str().unwrap()).collect();
+    assert!(names.contains(&"Go") && names.contains(&"Node.js") && names.contains(&"Docker") && names.contains(&".env"), "{:?}", names);
+    assert!(names.contains(&format!("porta {} (aplicação)", port).as_str()), "{:?}", names);
+    assert!(names.contains(&format!("porta {} (--port)", extra).as_str()), "{:?}", names);
+}
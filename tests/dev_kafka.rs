@@ -0,0 +1,653 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::collections::BTreeSet;
+use std::fs;
+use std::io::{Read, Write};
+use std::net::{TcpListener, TcpStream};
+use std::path::Path;
+use std::process::{Command, Output};
+use std::sync::{Arc, Mutex};
+
+/// A message in partition 0 of every topic: key, value and headers.
+type Message = (Option<&'static str>, &'static str, Vec<(&'static str, &'static str)>);
+
+/// A message received by Produce: topic, key, value and headers.
+type Produced = (String, Option<String>, String, Vec<(String, String)>);
+
+/// Minimal Kafka broker answering Metadata v4, CreateTopics v2, DeleteTopics v1, ListOffsets v1,
+/// Fetch v4 (serving `messages`) and Produce v3 (keeping the messages in `produced`).
+fn fake_broker(topics: Arc<Mutex<BTreeSet<String>>>, messages: Vec<Message>, produced: Arc<Mutex<Vec<Produced>>>) -> u16 {
+    let listener = TcpListener::bind("127.0.0.1:0").unwrap();
+    let port = listener.local_addr().unwrap().port();
+    let messages = Arc::new(messages);
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let (topics, messages, produced) = (topics.clone(), messages.clone(), produced.clone());
+            std::thread::spawn(move || serve(stream, port, &topics, &messages, &produced));
+        }
+    });
+    port
+}
+
+fn string(out: &mut Vec<u8>, s: &str) {
+    out.extend_from_slice(&(s.len() as i16).to_be_bytes());
+    out.extend_from_slice(s.as_bytes());
+}
+
+fn varint(out: &mut Vec<u8>, v: i64) {
+    let mut z = ((v << 1) ^ (v >> 63)) as u64;
+    while z >= 0x80 {
+        out.push((z as u8 & 0x7f) | 0x80);
+        z >>= 7;
+    }
+    out.push(z as u8);
+}
+
+fn varint_bytes(out: &mut Vec<u8>, bytes: Option<&str>) {
+    match bytes {
+        Some(b) => {
+            varint(out, b.len() as i64);
+            out.extend_from_slice(b.as_bytes());
+        }
+        None => varint(out, -1),
+    }
+}
+
+fn read_varint(data: &[u8], pos: &mut usize) -> i64 {
+    let mut z: u64 = 0;
+    for shift in (0..64).step_by(7) {
+        let byte = data[*pos];
+        *pos += 1;
+        z |= ((byte & 0x7f) as u64) << shift;
+        if byte & 0x80 == 0 {
+            break;
+        }
+    }
+    (z >> 1) as i64 ^ -((z & 1) as i64)
+}
+
+fn read_varint_bytes(data: &[u8], pos: &mut usize) -> Option<String> {
+    let len = read_varint(data, pos);
+    if len < 0 {
+        return None;
+    }
+    *pos += len as usize;
+    Some(String::from_utf8(data[*pos - len as usize..*pos].to_vec()).unwrap())
+}
+
+fn crc32c(data: &[u8]) -> u32 {
+    let mut crc = !0u32;
+    for byte in data {
+        crc ^= *byte as u32;
+        for _ in 0..8 {
+            crc = if crc & 1 != 0 { (crc >> 1) ^ 0x82f6_3b78 } else { crc >> 1 };
+        }
+    }
+    !crc
+}
+
+/// Messages of one produced v2 record batch, after checking its CRC.
+fn decode_batch(batch: &[u8]) -> Vec<(Option<String>, String, Vec<(String, String)>)> {
+    assert_eq!(batch[16], 2, "magic");
+    let crc = u32::from_be_bytes(batch[17..21].try_into().unwrap());
+    assert_eq!(crc, crc32c(&batch[21..]), "crc");
+    let count = i32::from_be_bytes(batch[57..61].try_into().unwrap());
+    let mut pos = 61;
+    let mut messages = Vec::new();
+    for _ in 0..count {
+        read_varint(batch, &mut pos); // length
+        pos += 1; // attributes
+        read_varint(batch, &mut pos); // timestamp_delta
+        read_varint(batch, &mut pos); // offset_delta
+        let key = read_varint_bytes(batch, &mut pos);
+        let value = read_varint_bytes(batch, &mut pos).unwrap();
+        let headers = (0..read_varint(batch, &mut pos))
+            .map(|_| (read_varint_bytes(batch, &mut pos).unwrap(), read_varint_bytes(batch, &mut pos).unwrap()))
+            .collect();
+        messages.push((key, value, headers));
+    }
+    messages
+}
+
+/// All messages as one v2 record batch starting at offset 0.
+fn record_batch(messages: &[Message]) -> Vec<u8> {
+    let mut batch = Vec::new();
+    batch.extend_from_slice(&0i32.to_be_bytes()); // partition_leader_epoch
+    batch.push(2); // magic
+    batch.extend_from_slice(&0i32.to_be_bytes()); // crc
+    batch.extend_from_slice(&0i16.to_be_bytes()); // attributes
+    batch.extend_from_slice(&(messages.len() as i32 - 1).to_be_bytes());
+    batch.extend_from_slice(&1_700_000_000_000i64.to_be_bytes());
+    batch.extend_from_slice(&1_700_000_000_000i64.to_be_bytes());
+    batch.extend_from_slice(&[0xff; 8 + 2 + 4]); // producer_id, producer_epoch, base_sequence
+    batch.extend_from_slice(&(messages.len() as i32).to_be_bytes());
+    for (i, (key, value, headers)) in messages.iter().enumerate() {
+        let mut record = vec![0];
+        varint(&mut record, i as i64 * 10); // timestamp_delta
+        varint(&mut record, i as i64);
+        varint_bytes(&mut record, *key);
+        varint_bytes(&mut record, Some(value));
+        varint(&mut record, headers.len() as i64);
+        for (name, value) in headers {
+            varint_bytes(&mut record, Some(name));
+            varint_bytes(&mut record, Some(value));
+        }
+        varint(&mut batch, record.len() as i64);
+        batch.extend_from_slice(&record);
+    }
+    let mut out = 0i64.to_be_bytes().to_vec();
+    out.extend_from_slice(&(batch.len() as i32).to_be_bytes());
+    out.extend_from_slice(&batch);
+    out
+}
+
+fn serve(mut stream: TcpStream, port: u16, topics: &Mutex<BTreeSet<String>>, messages: &[Message], produced: &Mutex<Vec<Produced>>) {
+    let mut size = [0u8; 4];
+    while stream.read_exact(&mut size).is_ok() {
+        let mut request = vec![0u8; i32::from_be_bytes(size) as usize];
+        stream.read_exact(&mut request).unwrap();
+        let mut pos = 0;
+        let mut take = |n: usize| {
+            pos += n;
+            request[pos - n..pos].to_vec()
+        };
+        let i16_at = |b: Vec<u8>| i16::from_be_bytes([b[0], b[1]]);
+        let i32_at = |b: Vec<u8>| i32::from_be_bytes([b[0], b[1], b[2], b[3]]);
+        let i64_at = |b: Vec<u8>| i64::from_be_bytes(b.try_into().unwrap());
+        let api_key = i16_at(take(2));
+        take(2);
+        let correlation_id = take(4);
+        let client_len = i16_at(take(2)) as usize;
+        take(client_len);
+
+        let mut body = correlation_id;
+        if api_key != 2 && api_key != 0 {
+            body.extend_from_slice(&0i32.to_be_bytes()); // throttle_time_ms
+        }
+        let mut topics = topics.lock().unwrap();
+        match api_key {
+            0 => {
+                take(2 + 2 + 4 + 4); // null transactional_id, acks, timeout, one topic
+                let len = i16_at(take(2)) as usize;
+                let name = take(len);
+                take(4); // one partition
+                let partition = take(4);
+                let size = i32_at(take(4)) as usize;
+                let topic = String::from_utf8(name.clone()).unwrap();
+                let mut produced = produced.lock().unwrap();
+                let base_offset = produced.iter().filter(|p| p.0 == topic).count() as i64;
+                for (key, value, headers) in decode_batch(&take(size)) {
+                    produced.push((topic.clone(), key, value, headers));
+                }
+                body.extend_from_slice(&1i32.to_be_bytes());
+                body.extend_from_slice(&(len as i16).to_be_bytes());
+                body.extend_from_slice(&name);
+                body.extend_from_slice(&1i32.to_be_bytes());
+                body.extend_from_slice(&partition);
+                body.extend_from_slice(&0i16.to_be_bytes());
+                body.extend_from_slice(&base_offset.to_be_bytes());
+                body.extend_from_slice(&(-1i64).to_be_bytes()); // log_append_time
+                body.extend_from_slice(&0i32.to_be_bytes()); // throttle_time_ms
+            }
+            2 => {
+                take(4 + 4); // replica_id, one topic
+                let len = i16_at(take(2)) as usize;
+                let name = take(len);
+                take(4 + 4); // one partition: 0
+                let offset = if i64_at(take(8)) == -2 { 0 } else { messages.len() as i64 };
+                body.extend_from_slice(&1i32.to_be_bytes());
+                body.extend_from_slice(&(len as i16).to_be_bytes());
+                body.extend_from_slice(&name);
+                body.extend_from_slice(&[0, 0, 0, 1, 0, 0, 0, 0, 0, 0]); // one partition, index 0, no error
+                body.extend_from_slice(&(-1i64).to_be_bytes());
+                body.extend_from_slice(&offset.to_be_bytes());
+            }
+            1 => {
+                take(4 + 4 + 4 + 4 + 1 + 4); // replica_id, max_wait, min_bytes, max_bytes, isolation, one topic
+                let len = i16_at(take(2)) as usize;
+                let name = take(len);
+                take(4 + 4); // one partition: 0
+                let offset = i64_at(take(8));
+                // Whole batch from offset 0, like a broker returning the batch holding the offset
+                let records = if offset < messages.len() as i64 { record_batch(messages) } else { Vec::new() };
+                if records.is_empty() {
+                    std::thread::sleep(std::time::Duration::from_millis(20));
+                }
+                body.extend_from_slice(&1i32.to_be_bytes());
+                body.extend_from_slice(&(len as i16).to_be_bytes());
+                body.extend_from_slice(&name);
+                body.extend_from_slice(&[0, 0, 0, 1, 0, 0, 0, 0, 0, 0]); // one partition, index 0, no error
+                body.extend_from_slice(&(messages.len() as i64).to_be_bytes()); // high_watermark
+                body.extend_from_slice(&(messages.len() as i64).to_be_bytes()); // last_stable_offset
+                body.extend_from_slice(&(-1i32).to_be_bytes()); // aborted_transactions
+                body.extend_from_slice(&(records.len() as i32).to_be_bytes());
+                body.extend_from_slice(&records);
+            }
+            3 => {
+                body.extend_from_slice(&1i32.to_be_bytes());
+                body.extend_from_slice(&0i32.to_be_bytes());
+                string(&mut body, "127.0.0.1");
+                body.extend_from_slice(&(port as i32).to_be_bytes());
+                body.extend_from_slice(&(-1i16).to_be_bytes()); // rack
+                body.extend_from_slice(&(-1i16).to_be_bytes()); // cluster_id
+                body.extend_from_slice(&0i32.to_be_bytes()); // controller_id
+                body.extend_from_slice(&(topics.len() as i32).to_be_bytes());
+                for name in topics.iter() {
+                    body.extend_from_slice(&0i16.to_be_bytes());
+                    string(&mut body, name);
+                    body.push(0);
+                    body.extend_from_slice(&1i32.to_be_bytes());
+                    body.extend_from_slice(&[0, 0, 0, 0, 0, 0, 0, 0, 0, 0]); // error, index, leader
+                    body.extend_from_slice(&[0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0]); // replicas, isr
+                }
+            }
+            19 | 20 => {
+                let count = i32_at(take(4));
+                body.extend_from_slice(&count.to_be_bytes());
+                for _ in 0..count {
+                    let len = i16_at(take(2)) as usize;
+                    let name = String::from_utf8(take(len)).unwrap();
+                    let error: i16 = if api_key == 19 {
+                        take(4 + 2 + 4 + 4); // partitions, replication, no assignments, no configs
+                        if topics.insert(name.clone()) { 0 } else { 36 }
+                    } else if topics.remove(&name) {
+                        0
+                    } else {
+                        3
+                    };
+                    string(&mut body, &name);
+                    body.extend_from_slice(&error.to_be_bytes());
+                    if api_key == 19 {
+                        body.extend_from_slice(&(-1i16).to_be_bytes()); // error_message
+                    }
+                }
+            }
+            _ => return,
+        }
+        stream.write_all(&(body.len() as i32).to_be_bytes()).unwrap();
+        stream.write_all(&body).unwrap();
+    }
+}
+
+fn dx(dir: &Path, port: u16, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-kafka", "topics"])
+        .args(args)
+        .current_dir(dir)
+        .env("KAFKA_BROKERS", format!("127.0.0.1:{}", port))
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .output()
+        .expect("failed to run dx dev-kafka topics")
+}
+
+// Test listing, creating (detected and named) and deleting topics against a broker
+#[test]
+fn dev_kafka_topics() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path();
+    fs::write(
+        project.join("main.go"),
+        "package main\n\nimport \"os\"\n\nfunc topic() string {\n\ttopic := os.Getenv(\"KAFKA_TOPIC_USERS\")\n\tif topic == \"\" {\n\t\ttopic = \"users\"\n\t}\n\treturn topic\n}\n",
+    )
+    .unwrap();
+    fs::write(project.join("producer.js"), "await producer.send({ topic: 'orders', messages })\n").unwrap();
+    let topics = Arc::new(Mutex::new(BTreeSet::from(["audit".to_string()])));
+    let port = fake_broker(topics.clone(), Vec::new(), Arc::default());
+
+    let output = dx(project, port, &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Tópicos no broker (1):"), "{}", stdout);
+    assert!(stdout.contains("✗ users   KAFKA_TOPIC_USERS"), "{}", stdout);
+    assert!(stdout.contains("✗ orders  producer.js:1"), "{}", stdout);
+
+    let output = dx(project, port, &["create"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", stdout);
+    assert!(stdout.contains("✓ orders: criado") && stdout.contains("✓ users: criado"), "{}", stdout);
+    assert_eq!(topics.lock().unwrap().len(), 3);
+
+    let output = dx(project, port, &["create", "users"]);
+    assert!(String::from_utf8_lossy(&output.stdout).contains("= users: já existe"));
+    assert_eq!(dx(project, port, &["create", "bad topic"]).status.code(), Some(2));
+
+    let output = dx(project, port, &["delete", "audit", "missing"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("- missing: não existe no broker"));
+    assert_eq!(*topics.lock().unwrap(), BTreeSet::from(["orders".to_string(), "users".to_string()]));
+
+    let output = dx(project, port, &["--format", "json"]);
+    let doc: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    assert_eq!(doc["topics"].as_array().unwrap().len(), 2);
+    assert!(doc["detected"].as_array().unwrap().iter().all(|t| t["exists"] == true), "{}", doc);
+
+    // Unreachable broker
+    let closed = TcpListener::bind("127.0.0.1:0").unwrap().local_addr().unwrap().port();
+    assert_eq!(dx(project, closed, &[]).status.code(), Some(2));
+}
+
+// Test tailing a topic: pretty JSON with headers, key and header filters, JSON lines
+#[test]
+fn dev_kafka_consume() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path();
+    let messages: Vec<Message> = vec![
+        (Some("u1"), r#"{"user_id":"u1","event_type":"user.created"}"#, vec![("event_type", "user.created")]),
+        (Some("u1"), "not json", vec![("event_type", "user.updated")]),
+        (Some("u2"), r#"{"user_id":"u2","event_type":"user.deleted"}"#, vec![("event_type", "user.deleted"), ("source", "api")]),
+    ];
+    let port = fake_broker(Arc::new(Mutex::new(BTreeSet::from(["users".to_string()]))), messages, Arc::default());
+    let consume = |args: &[&str]| {
+        Command::new(env!("CARGO_BIN_EXE_dx"))
+            .args(["dev-kafka", "consume"])
+            .args(args)
+            .current_dir(project)
+            .env("KAFKA_BROKERS", format!("127.0.0.1:{}", port))
+            .env("DX_STATE_DIR", project.join(".dx-state"))
+            .output()
+            .expect("failed to run dx dev-kafka consume")
+    };
+
+    let output = consume(&["users", "--from-beginning", "-n", "3"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.starts_with("users[0]@0  2023-11-14T22:13:20.000Z  key=u1\n  event_type: user.created\n{\n"), "{}", stdout);
+    assert!(stdout.contains("\n  \"user_id\": \"u1\""), "{}", stdout);
+    assert!(stdout.contains("users[0]@1  2023-11-14T22:13:20.010Z  key=u1\n  event_type: user.updated\nnot json\n"), "{}", stdout);
+    assert!(stdout.contains("users[0]@2"), "{}", stdout);
+
+    let output = consume(&["users", "--from-beginning", "--header", "event_type=user.deleted", "--format", "jsonl", "-n", "1"]);
+    let lines: Vec<serde_json::Value> = String::from_utf8_lossy(&output.stdout).lines().map(|l| serde_json::from_str(l).unwrap()).collect();
+    assert_eq!(lines.len(), 1);
+    assert_eq!((&lines[0]["topic"], &lines[0]["offset"]), (&serde_json::json!("users"), &serde_json::json!(2)));
+    assert_eq!(lines[0]["key"], "u2");
+    assert_eq!(lines[0]["headers"]["source"], "api");
+    assert_eq!(lines[0]["value"]["user_id"], "u2");
+
+    let output = consume(&["users", "--from-beginning", "--key", "u1", "--header", "event_type", "--format", "jsonl", "-n", "2"]);
+    let offsets: Vec<i64> =
+        String::from_utf8_lossy(&output.stdout).lines().map(|l| serde_json::from_str::<serde_json::Value>(l).unwrap()["offset"].as_i64().unwrap()).collect();
+    assert_eq!(offsets, [0, 1]);
+
+    let output = consume(&["orders"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("O tópico 'orders' não existe no broker (tópicos: users)"));
+}
+
+fn events(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-kafka", "events"])
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .output()
+        .expect("failed to run dx dev-kafka events")
+}
+
+// Test that the events catalog and JSON Schemas come from the Go structs a kafka-go producer marshals
+#[test]
+fn dev_kafka_events_go() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    fs::write(
+        dir.join("events.go"),
+        r#"package orders
+
+import "time"
+
+type Status string
+
+const (
+	StatusPaid    Status = "paid"
+	StatusShipped Status = "shipped"
+)
+
+const (
+	OrderPlaced   = "ORDER_PLACED"
+	OrderCanceled = "ORDER_CANCELED"
+)
+
+type Meta struct {
+	TraceID string `json:"trace_id"`
+}
+
+type Item struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type OrderEvent struct {
+	Meta
+	Type     string     `json:"type"`
+	OrderID  string     `json:"order_id"`
+	Status   Status     `json:"status"`
+	Items    []Item     `json:"items"`
+	Total    float64    `json:"total"`
+	Note     *string    `json:"note,omitempty"`
+	Internal string     `json:"-"`
+	At       time.Time  `json:"at"`
+}
+
+func placed(id string) OrderEvent {
+	return OrderEvent{Type: OrderPlaced, OrderID: id}
+}
+
+func canceled(id string) *OrderEvent {
+	e := &OrderEvent{OrderID: id}
+	e.Type = OrderCanceled
+	return e
+}
+"#,
+    )
+    .unwrap();
+    fs::write(
+        dir.join("producer.go"),
+        r#"package orders
+
+import (
+	"encoding/json"
+
+	"github.com/segmentio/kafka-go"
+)
+
+func publish(w *kafka.Writer, event *OrderEvent) error {
+	value, _ := json.Marshal(event)
+	return w.WriteMessages(ctx, kafka.Message{
+		Topic: "orders",
+		Key:   []byte(event.OrderID),
+		Value: value,
+		Headers: []kafka.Header{{Key: "source", Value: []byte("api")}},
+	})
+}
+"#,
+    )
+    .unwrap();
+
+    let output = events(dir, &["--out", "docs/events.md", "--schemas", "schemas"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Catálogo com 1 evento(s) em docs/events.md"), "{}", stdout);
+    let doc = fs::read_to_string(dir.join("docs/events.md")).unwrap();
+    assert!(doc.contains("| [OrderEvent](#orderevent) | `orders` | `ORDER_PLACED`, `ORDER_CANCELED` |"), "{}", doc);
+    assert!(doc.contains("- Definido em: `events.go:26`\n- Publicado em: `producer.go`\n"), "{}", doc);
+    assert!(doc.contains("- Chave: `order_id`\n- Headers: `source`\n- Tipos (`type`): `ORDER_PLACED`, `ORDER_CANCELED`\n"), "{}", doc);
+    // Embedded fields are promoted; `-` fields are left out; pointers and omitempty are optional
+    assert!(doc.contains("| `trace_id` | string | sim |\n| `type` | string | sim |"), "{}", doc);
+    assert!(doc.contains("| `items` | array<[Item](#item)> | sim |"), "{}", doc);
+    assert!(doc.contains("| `note` | string | não |"), "{}", doc);
+    assert!(!doc.contains("Internal"), "{}", doc);
+    assert!(doc.contains("\n## Item\n"), "{}", doc);
+
+    let schema: serde_json::Value = serde_json::from_str(&fs::read_to_string(dir.join("schemas/OrderEvent.schema.json")).unwrap()).unwrap();
+    assert_eq!(schema["$schema"], "https://json-schema.org/draft/2020-12/schema");
+    assert_eq!(schema["properties"]["type"]["enum"], serde_json::json!(["ORDER_PLACED", "ORDER_CANCELED"]));
+    assert_eq!(schema["properties"]["status"]["enum"], serde_json::json!(["paid", "shipped"]));
+    assert_eq!(schema["properties"]["at"]["format"], "date-time");
+    assert_eq!(schema["properties"]["items"]["items"]["$ref"], "#/$defs/Item");
+    assert_eq!(schema["$defs"]["Item"]["properties"]["quantity"]["type"], "integer");
+    assert!(!schema["required"].as_array().unwrap().contains(&serde_json::json!("note")));
+}
+
+// Test that Java classes and records sent through KafkaTemplate are cataloged, with Spring topic properties
+#[test]
+fn dev_kafka_events_java() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    fs::write(dir.join("application.properties"), "app.topic.payments=payments\n").unwrap();
+    fs::write(
+        dir.join("PaymentEvent.java"),
+        r#"package shop;
+
+import com.fasterxml.jackson.annotation.JsonProperty;
+
+public record PaymentEvent(@JsonProperty("payment_id") String paymentId, Kind kind, long amountCents, java.time.Instant at) {
+    public enum Kind {
+        AUTHORIZED,
+        REFUNDED;
+
+        public String label() { return name(); }
+    }
+}
+"#,
+    )
+    .unwrap();
+    fs::write(
+        dir.join("Payments.java"),
+        r#"package shop;
+
+import org.springframework.kafka.core.KafkaTemplate;
+
+public class Payments {
+    private final KafkaTemplate<String, PaymentEvent> kafkaTemplate;
+
+    @Value("${app.topic.payments}")
+    private String topic;
+
+    void publish(PaymentEvent event) {
+        kafkaTemplate.send(topic, event);
+    }
+}
+"#,
+    )
+    .unwrap();
+
+    let output = events(dir, &["--format", "json"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let json: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    let event = &json["events"][0];
+    assert_eq!(event["name"], "PaymentEvent");
+    assert_eq!(event["topics"], serde_json::json!(["payments"]));
+    assert_eq!(event["type_field"], "kind");
+    assert_eq!(event["types"], serde_json::json!(["AUTHORIZED", "REFUNDED"]));
+    let properties = &event["schema"]["properties"];
+    assert_eq!(properties["payment_id"]["type"], "string");
+    assert_eq!(properties["kind"]["enum"], serde_json::json!(["AUTHORIZED", "REFUNDED"]));
+    assert_eq!(properties["amountCents"]["type"], "integer");
+    assert_eq!(event["schema"]["required"], serde_json::json!(["amountCents"]));
+
+    // Nothing to catalog
+    fs::remove_file(dir.join("PaymentEvent.java")).unwrap();
+    assert_eq!(events(dir, &[]).status.code(), Some(1));
+}
+
+// Test producing inline, stdin and templated catalog events, checked on the broker side
+#[test]
+fn dev_kafka_produce() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path();
+    fs::write(
+        project.join("events.go"),
+        r#"package users
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+)
+
+const (
+	UserCreated = "USER_CREATED"
+	UserDeleted = "USER_DELETED"
+)
+
+type UserEvent struct {
+	EventID    string    `json:"event_id"`
+	EventType  string    `json:"event_type"`
+	UserID     string    `json:"user_id"`
+	Age        int       `json:"age"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
+
+func created(id string) UserEvent { return UserEvent{EventType: UserCreated, UserID: id} }
+func deleted(id string) UserEvent { return UserEvent{EventType: UserDeleted, UserID: id} }
+
+func publish(w *kafka.Writer, event UserEvent) error {
+	value, _ := json.Marshal(event)
+	return w.WriteMessages(ctx, kafka.Message{
+		Key:     []byte(event.UserID),
+		Value:   value,
+		Headers: []kafka.Header{{Key: "event_type", Value: []byte(event.EventType)}},
+	})
+}
+"#,
+    )
+    .unwrap();
+    let produced: Arc<Mutex<Vec<Produced>>> = Arc::default();
+    let port = fake_broker(Arc::new(Mutex::new(BTreeSet::from(["users".to_string()]))), Vec::new(), produced.clone());
+    let produce = |args: &[&str], stdin: &str| {
+        let mut child = Command::new(env!("CARGO_BIN_EXE_dx"))
+            .args(["dev-kafka", "produce"])
+            .args(args)
+            .current_dir(project)
+            .env("KAFKA_BROKERS", format!("127.0.0.1:{}", port))
+            .env("DX_STATE_DIR", project.join(".dx-state"))
+            .stdin(std::process::Stdio::piped())
+            .stdout(std::process::Stdio::piped())
+            .stderr(std::process::Stdio::piped())
+            .spawn()
+            .expect("failed to run dx dev-kafka produce");
+        child.stdin.take().unwrap().write_all(stdin.as_bytes()).unwrap();
+        child.wait_with_output().unwrap()
+    };
+
+    let output = produce(&["users", "--value", r#"{"n":{{i}}}"#, "--key", "k{{i}}", "--header", "source=dx", "-n", "2"], "");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert_eq!(String::from_utf8_lossy(&output.stdout), "users[0]@0  key=k1\nusers[0]@1  key=k2\n");
+    {
+        let produced = produced.lock().unwrap();
+        assert_eq!(produced[0], ("users".into(), Some("k1".into()), r#"{"n":1}"#.into(), vec![("source".into(), "dx".into())]));
+        assert_eq!(produced[1].2, r#"{"n":2}"#);
+    }
+
+    // A JSON array on stdin is one message per element
+    let output = produce(&["users", "--file", "-"], r#"[{"a": 1}, "text"]"#);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    {
+        let produced = produced.lock().unwrap();
+        assert_eq!((produced[2].1.as_deref(), produced[2].2.as_str()), (None, r#"{"a":1}"#));
+        assert_eq!(produced[3].2, r#""text""#);
+    }
+
+    // Catalog events: random IDs, the chosen type, the producer's key and type header
+    let output = produce(&["users", "--event", "UserEvent", "--type", "USER_DELETED", "-n", "3"], "");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let produced = produced.lock().unwrap().clone();
+    let events = &produced[4..];
+    assert_eq!(events.len(), 3);
+    let values: Vec<serde_json::Value> = events.iter().map(|p| serde_json::from_str(&p.2).unwrap()).collect();
+    assert_eq!(values[0]["event_type"], "USER_DELETED");
+    assert_eq!(values[2]["age"], 3);
+    assert_eq!(values[0]["user_id"].as_str().unwrap().len(), 36);
+    assert_ne!(values[0]["event_id"], values[1]["event_id"]);
+    assert!(values[0]["occurred_at"].as_str().unwrap().ends_with('Z'));
+    assert_eq!(events[0].1.as_deref(), values[0]["user_id"].as_str());
+    assert_eq!(events[0].3, vec![("event_type".to_string(), "USER_DELETED".to_string())]);
+
+    let output = produce(&["users", "--event", "UserEvent", "--type", "USER_BANNED"], "");
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Tipo 'USER_BANNED' desconhecido para UserEvent (tipos: USER_CREATED, USER_DELETED)"));
+    let output = produce(&["orders", "--value", "x"], "");
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("dx dev-kafka topics create orders"));
+}
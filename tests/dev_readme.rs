@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx dev-readme")
+}
+
+const MAIN_GO: &str = "package main\n\nimport \"os\"\n\nfunc main() {\n\tport := os.Getenv(\"PORT\")\n\tif port == \"\" {\n\t\tport = \"9000\"\n\t}\n\tregion := os.Getenv(\"REGION\")\n\ttoken := os.Getenv(\"PAYMENTS_API_TOKEN\")\n\tr := gin.Default()\n\tr.GET(\"/orders\", listOrders)\n\tr.POST(\"/orders\", createOrder)\n\t_ = r.Run(\":\" + port)\n}\n";
+
+// Test that the section lists the toolchain, commands, variables and routes, and only the managed block is rewritten
+#[test]
+fn dev_readme_generate_updates_managed_block() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("orders");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/orders\n\ngo 1.22\n").unwrap();
+    fs::write(project.join("main.go"), MAIN_GO).unwrap();
+    fs::write(project.join("README.md"), "# Orders\n\nServiço de pedidos.\n").unwrap();
+
+    let output = dx(&project, &["dev-readme", "generate"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ Seção \"Primeiros passos\" atualizada em"), "{}", stdout);
+    assert!(stdout.contains("(3 variável(is), 2 rota(s))"), "{}", stdout);
+
+    let readme = fs::read_to_string(project.join("README.md")).unwrap();
+    assert!(readme.starts_with("# Orders\n\nServiço de pedidos.\n\n<!-- dx-cli:readme:start -->\n"), "{}", readme);
+    assert!(readme.contains("- Go 1.22\n"), "{}", readme);
+    assert!(readme.contains("```sh\ngo run .\n```\n"), "{}", readme);
+    assert!(readme.contains("A aplicação escuta em http://localhost:9000."), "{}", readme);
+    assert!(readme.contains("```sh\ngo test ./...\n```\n"), "{}", readme);
+    assert!(readme.contains("| `PORT` | `9000` | aplicação |\n"), "{}", readme);
+    assert!(readme.contains("| `REGION` | obrigatória | aplicação |\n"), "{}", readme);
+    assert!(readme.contains("| `PAYMENTS_API_TOKEN` | segredo: defina no ambiente | aplicação |\n"), "{}", readme);
+    assert!(readme.contains("| POST | `/orders` | `main.go:14` |\n"), "{}", readme);
+    assert!(readme.ends_with("<!-- dx-cli:readme:end -->\n"), "{}", readme);
+
+    // Text added after the block survives; a second run changes nothing
+    fs::write(project.join("README.md"), format!("{}\n## Licença\n\nMIT\n", readme)).unwrap();
+    let output = dx(&project, &["dev-readme", "generate", "--check"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stdout));
+    let output = dx(&project, &["dev-readme", "generate"]);
+    assert!(String::from_utf8_lossy(&output.stdout).contains("já está em dia"));
+
+    // A new route makes the block stale; regenerating replaces it in place
+    fs::write(project.join("main.go"), MAIN_GO.replace("\t_ = r.Run", "\tr.GET(\"/healthz\", health)\n\t_ = r.Run")).unwrap();
+    let output = dx(&project, &["dev-readme", "generate", "--check"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("desatualizada; rode `dx dev-readme generate`"));
+    let output = dx(&project, &["dev-readme", "generate"]);
+    assert!(output.status.success());
+    let readme = fs::read_to_string(project.join("README.md")).unwrap();
+    assert!(readme.contains("| GET | `/healthz` |"), "{}", readme);
+    assert_eq!(readme.matches("<!-- dx-cli:readme:start -->").count(), 1, "{}", readme);
+    assert!(readme.ends_with("<!-- dx-cli:readme:end -->\n\n## Licença\n\nMIT\n"), "{}", readme);
+}
+
+// Test that --no-save only prints the section and a project without README gets one
+#[test]
+fn dev_readme_generate_no_save_and_new_readme() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("web");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("package.json"), r#"{"name": "web", "scripts": {"dev": "vite"}, "engines": {"node": ">=20"}}"#).unwrap();
+
+    let output = dx(&project, &["dev-readme", "generate", "--no-save"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("- Node.js 20\n") && stdout.contains("npm run dev\n"), "{}", stdout);
+    assert!(!project.join("README.md").exists());
+
+    let output = dx(&project, &["dev-readme", "generate"]);
+    assert!(output.status.success());
+    let readme = fs::read_to_string(project.join("README.md")).unwrap();
+    assert!(readme.starts_with("# web\n\n<!-- dx-cli:readme:start -->\n"), "{}", readme);
+}
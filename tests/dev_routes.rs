@@ -0,0 +1,139 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx dev-routes")
+}
+
+// Test that the Go sample's routes are listed with their handlers, group prefixes included
+#[test]
+fn dev_routes_list_go_sample() {
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-routes", "list", "test-projects/go"])
+        .output()
+        .expect("failed to run dx dev-routes list");
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Rotas HTTP registradas em test-projects/go (12):"), "{}", stdout);
+    // `users.GET("", ...)` and `users.Group("", mw)` keep the group's path
+    assert!(stdout.contains("GET     /api/users          userHandler.GetAllUsers"), "{}", stdout);
+    assert!(stdout.contains("POST    /api/users          userHandler.CreateUser"), "{}", stdout);
+    assert!(stdout.contains("PUT     /api/users/{id}     userHandler.UpdateUser"), "{}", stdout);
+    assert!(stdout.contains("GET     /                   função anônima           main.go:"), "{}", stdout);
+    assert!(stdout.contains("GET     /metrics            metrics.Handler()"), "{}", stdout);
+    assert!(stdout.contains("GET     /api/events         eventHandler.Stream"), "{}", stdout);
+}
+
+// Test chi sub-routers, echo groups and net/http patterns, in text and JSON
+#[test]
+fn dev_routes_list_chi_echo_and_net_http() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("svc");
+    fs::create_dir_all(project.join("internal/api")).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/svc\n\ngo 1.22\n").unwrap();
+    fs::write(
+        project.join("main.go"),
+        "package main\n\nfunc main() {\n\tmux := http.NewServeMux()\n\tmux.HandleFunc(\"GET /orders/{id}\", getOrder)\n\thttp.HandleFunc(\"/healthz\", health)\n\n\te := echo.New()\n\tadmin := e.Group(\"/admin\", middleware.BasicAuth(check))\n\tadmin.DELETE(\"/cache\", clearCache)\n}\n",
+    )
+    .unwrap();
+    fs::write(
+        project.join("internal/api/router.go"),
+        "package api\n\nfunc Router(h *Handler) chi.Router {\n\tr := chi.NewRouter()\n\tr.Route(\"/v1/users\", func(r chi.Router) {\n\t\tr.Get(\"/\", h.List)\n\t\tr.Group(func(r chi.Router) {\n\t\t\tr.Use(auth)\n\t\t\tr.Post(\"/\", h.Create)\n\t\t})\n\t\tr.Get(\"/{id}\", h.Get)\n\t})\n\tr.Method(\"PATCH\", \"/v1/settings\", http.HandlerFunc(h.Settings))\n\tr.Get(\"/ping\", func(w http.ResponseWriter, r *http.Request) {\n\t\tw.Write([]byte(\"pong\"))\n\t})\n\treturn r\n}\n",
+    )
+    .unwrap();
+
+    let output = dx(&project, &["dev-routes", "list"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("(8):"), "{}", stdout);
+    assert!(stdout.contains("DELETE  /admin/cache"), "{}", stdout);
+    assert!(stdout.contains("ANY     /healthz"), "{}", stdout);
+    assert!(stdout.contains("GET     /orders/{id}"), "{}", stdout);
+    assert!(stdout.contains("GET     /v1/users       h.List"), "{}", stdout);
+    assert!(stdout.contains("POST    /v1/users       h.Create"), "{}", stdout);
+    assert!(stdout.contains("GET     /v1/users/{id}  h.Get"), "{}", stdout);
+    assert!(stdout.contains("GET     /ping           função anônima"), "{}", stdout);
+    // Routes after the sub-router closes are back at the root
+    assert!(stdout.contains("PATCH   /v1/settings    http.HandlerFunc(h.Settings)  internal/api/router.go:13"), "{}", stdout);
+
+    let output = dx(&project, &["dev-routes", "list", "--format", "json"]);
+    let value: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    let routes = value.as_array().unwrap();
+    assert_eq!(routes.len(), 8);
+    let create = routes.iter().find(|r| r["method"] == "POST").unwrap();
+    assert_eq!(create["path"], "/v1/users");
+    assert_eq!(create["handler"], "h.Create");
+    assert_eq!(create["location"], "internal/api/router.go:9");
+}
+
+// Test that the OpenAPI skeleton of the Go sample infers bodies, query parameters and responses
+// from the handlers, and that --check compares it with the code
+#[test]
+fn dev_routes_openapi_go_sample() {
+    let tmp = tempfile::tempdir().unwrap();
+    let spec = tmp.path().join("openapi.json");
+    let spec_arg = spec.to_str().unwrap();
+    let run = |args: &[&str]| {
+        Command::new(env!("CARGO_BIN_EXE_dx"))
+            .args(["dev-routes", "openapi"])
+            .args(args)
+            .arg("test-projects/go")
+            .env("DX_STATE_DIR", tmp.path().join("state"))
+            .output()
+            .expect("failed to run dx dev-routes openapi")
+    };
+
+    let output = run(&["--out", spec_arg]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("OpenAPI 3.1 com 12 operação(ões) e 7 schema(s)"), "{}", stdout);
+
+    let doc: serde_json::Value = serde_json::from_str(&fs::read_to_string(&spec).unwrap()).expect("invalid JSON");
+    assert_eq!(doc["openapi"], "3.1.0");
+    assert_eq!(doc["servers"][0]["url"], "http://localhost:8080");
+    let create = &doc["paths"]["/api/users"]["post"];
+    assert_eq!(create["requestBody"]["content"]["application/json"]["schema"]["$ref"], "#/components/schemas/UserInput");
+    // `user, err := h.userRepo.Create(...)` answers with the repository's result type
+    assert_eq!(create["responses"]["201"]["content"]["application/json"]["schema"]["$ref"], "#/components/schemas/User");
+    // `c.JSON(http.StatusBadRequest, validationProblem(c, err))` answers with the function's result, a problem
+    assert_eq!(create["responses"]["400"]["content"]["application/problem+json"]["schema"]["$ref"], "#/components/schemas/Problem");
+    // CreateUser is also registered on /api/auth/register, so neither route takes its name
+    assert_eq!(create["operationId"], "postApiUsers");
+
+    let list = &doc["paths"]["/api/users"]["get"];
+    assert_eq!(list["operationId"], "getAllUsers");
+    assert_eq!(list["summary"], "GetAllUsers returns a page of users");
+    let limit = list["parameters"].as_array().unwrap().iter().find(|p| p["name"] == "limit").unwrap();
+    assert_eq!(limit["in"], "query");
+    assert_eq!(limit["schema"], serde_json::json!({"type": "integer", "minimum": 1, "maximum": 100}));
+    assert_eq!(list["responses"]["200"]["content"]["application/json"]["schema"]["$ref"], "#/components/schemas/UserPage");
+    assert_eq!(list["responses"]["400"]["content"]["application/problem+json"]["schema"]["$ref"], "#/components/schemas/Problem");
+    assert_eq!(list["responses"]["500"]["content"]["application/json"]["schema"]["properties"]["error"]["type"], "string");
+
+    let delete = &doc["paths"]["/api/users/{id}"]["delete"];
+    assert_eq!(delete["parameters"][0], serde_json::json!({"name": "id", "in": "path", "required": true, "schema": {"type": "string"}}));
+    assert!(delete["responses"]["204"].get("content").is_none(), "{}", delete);
+
+    let input = &doc["components"]["schemas"]["UserInput"];
+    assert_eq!(input["properties"]["username"], serde_json::json!({"type": "string", "minLength": 3, "maxLength": 30}));
+    assert_eq!(input["properties"]["email"]["format"], "email");
+    assert_eq!(input["required"], serde_json::json!(["username", "email", "password"]));
+    assert!(doc["components"]["schemas"]["User"]["properties"].get("password").is_none());
+    let problem = &doc["components"]["schemas"]["Problem"];
+    assert_eq!(problem["properties"]["errors"]["items"]["$ref"], "#/components/schemas/FieldError");
+
+    let output = run(&["--out", spec_arg, "--check"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stdout));
+    fs::write(&spec, "{}\n").unwrap();
+    let output = run(&["--out", spec_arg, "--check"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("desatualizado"));
+}
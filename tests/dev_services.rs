@@ -203,6 +203,21 @@ postgres = "0.19"
     let _ = fs::remove_dir_all(&test_dir);
 }
 
+// Test that the Rust sample (sqlx + redis) is detected as Postgres and Redis
+#[test]
+fn dev_services_detects_rust_sample() {
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let output = Command::new(exe)
+        .args(["dev-services", "--no-save", "test-projects/rust"])
+        .output()
+        .expect("failed to run dx-cli dev-services --no-save");
+    assert!(output.status.success());
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("  postgres:"), "{}", stdout);
+    assert!(stdout.contains("  redis:"), "{}", stdout);
+    assert!(!stdout.contains("  mongodb:"), "{}", stdout);
+}
+
 // Test that the stop subcommand references the correct CLI name when compose file is missing
 #[test]
 fn dev_services_stop_shows_dx_cli_name() {
@@ -235,3 +250,174 @@ fn dev_services_stop_shows_dx_cli_name() {
     // Clean up
     let _ = fs::remove_dir_all(&temp_dir);
 }
+
+// Test that generated services declare healthchecks and wait on them through depends_on conditions
+#[test]
+fn dev_services_compose_has_healthchecks_and_depends_on() {
+    let test_dir = env::temp_dir().join("dx-cli-test-depends-on");
+    let _ = fs::remove_dir_all(&test_dir);
+    fs::create_dir_all(&test_dir).expect("Failed to create test directory");
+    fs::write(test_dir.join(".env"), "KAFKA_BROKERS=localhost:9092\n").expect("Failed to write .env");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let output = Command::new(exe)
+        .arg("dev-services")
+        .arg(test_dir.to_string_lossy().to_string())
+        .output()
+        .expect("failed to run dx-cli dev-services");
+    assert!(output.status.success());
+
+    let content = fs::read_to_string(test_dir.join(".dx").join("docker-compose.yml"))
+        .expect("Failed to read docker-compose.yml");
+    assert!(content.contains("healthcheck:"), "missing healthcheck:\n{}", content);
+    assert!(
+        content.contains("depends_on:\n      kafka:\n        condition: service_healthy"),
+        "kafka-ui should wait for a healthy kafka:\n{}",
+        content
+    );
+
+    let _ = fs::remove_dir_all(&test_dir);
+}
+
+// Test that run exposes the compose profile option
+#[test]
+fn dev_services_run_help_mentions_profile() {
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let output = Command::new(exe)
+        .args(["dev-services", "run", "--help"])
+        .output()
+        .expect("failed to run dx-cli dev-services run --help");
+
+    assert!(output.status.success());
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("--profile"), "Help output doesn't mention --profile");
+}
+
+// Test that the telemetry bundle scrapes the application when it exposes GET /metrics, and only then
+#[test]
+fn dev_services_prometheus_scrapes_app_metrics() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("api");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/api\n\ngo 1.22\n").unwrap();
+    fs::write(project.join(".env"), "KAFKA_BROKERS=localhost:9092\n").unwrap();
+    fs::write(
+        project.join("main.go"),
+        "package main\n\nfunc main() {\n\tport := os.Getenv(\"PORT\")\n\tif port == \"\" {\n\t\tport = \"9000\"\n\t}\n\tr := gin.Default()\n\tr.GET(\"/orders\", listOrders)\n\t_ = r.Run(\":\" + port)\n}\n",
+    )
+    .unwrap();
+
+    let run = || {
+        Command::new(env!("CARGO_BIN_EXE_dx"))
+            .arg("dev-services")
+            .arg(&project)
+            .env("DX_STATE_DIR", tmp.path().join("state"))
+            .output()
+            .expect("failed to run dx-cli dev-services")
+    };
+    let output = run();
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let prometheus = fs::read_to_string(project.join(".dx/telemetry/prometheus/prometheus.yml")).unwrap();
+    assert!(!prometheus.contains("job_name: 'app'"), "{}", prometheus);
+    assert!(!fs::read_to_string(project.join(".dx/docker-compose.yml")).unwrap().contains("extra_hosts:"));
+
+    let main = fs::read_to_string(project.join("main.go")).unwrap();
+    fs::write(project.join("main.go"), main.replace("\tr.GET(\"/orders\"", "\tr.GET(\"/metrics\", metrics)\n\tr.GET(\"/orders\"")).unwrap();
+    let output = run();
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("métricas da aplicação em host.docker.internal:9000/metrics (job app)"), "{}", stdout);
+    let prometheus = fs::read_to_string(project.join(".dx/telemetry/prometheus/prometheus.yml")).unwrap();
+    assert!(prometheus.contains("  - job_name: 'app'\n    scrape_interval: 15s\n    metrics_path: /metrics\n"), "{}", prometheus);
+    assert!(prometheus.contains("targets: ['host.docker.internal:9000']"), "{}", prometheus);
+    let compose = fs::read_to_string(project.join(".dx/docker-compose.yml")).unwrap();
+    assert!(compose.contains("extra_hosts:\n      - 'host.docker.internal:host-gateway'\n"), "{}", compose);
+}
+
+// Test that the services of a .NET project are inferred from its NuGet packages
+#[test]
+fn dev_services_detects_nuget_packages() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("Orders");
+    fs::create_dir_all(project.join("obj")).unwrap();
+    fs::write(
+        project.join("Orders.csproj"),
+        r#"<Project Sdk="Microsoft.NET.Sdk.Web">
+  <ItemGroup>
+    <PackageReference Include="MongoDB.Driver" Version="2.25.0" />
+    <PackageReference Include="Confluent.Kafka" Version="2.4.0" />
+  </ItemGroup>
+</Project>"#,
+    )
+    .unwrap();
+    fs::write(project.join("obj").join("project.assets.json"), r#"{"libraries": {"StackExchange.Redis/2.7.0": {}}}"#).unwrap();
+
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-services", "--no-save"])
+        .arg(&project)
+        .env("DX_STATE_DIR", tmp.path().join("state"))
+        .output()
+        .expect("failed to run dx-cli dev-services");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("  mongodb:"), "{}", stdout);
+    assert!(stdout.contains("  kafka:"), "{}", stdout);
+    assert!(!stdout.contains("  redis:"), "{}", stdout);
+}
+
+// Test that run --timings prints the per-service breakdown, the bottleneck, the previous run and the
+// tuning hints of a service that never became ready
+#[cfg(unix)]
+#[test]
+fn dev_services_run_timings_report() {
+    use std::os::unix::fs::PermissionsExt;
+
+    let tmp = tempfile::tempdir().unwrap();
+    let (project, bin) = (tmp.path().join("shop"), tmp.path().join("bin"));
+    fs::create_dir_all(project.join(".dx")).unwrap();
+    fs::create_dir_all(&bin).unwrap();
+    fs::write(
+        project.join(".dx/docker-compose.yml"),
+        "services:\n  mongodb:\n    image: mongo:7\n  kafka:\n    image: confluentinc/cp-kafka:7.6.0\n",
+    )
+    .unwrap();
+    fs::write(
+        project.join(".dx/startup-profile.json"),
+        r#"{"services": [{"service": "mongodb", "image": "mongo:7", "seconds": 21.5, "status": "healthy"}]}"#,
+    )
+    .unwrap();
+    fs::write(
+        bin.join("docker"),
+        r#"#!/bin/sh
+case "$*" in
+  *" ps "*) echo '{"Service":"mongodb","Image":"mongo:7","State":"running","Health":"healthy"}'
+            echo '{"Service":"kafka","Image":"confluentinc/cp-kafka:7.6.0","State":"running","Health":"unhealthy"}' ;;
+esac
+"#,
+    )
+    .unwrap();
+    fs::set_permissions(bin.join("docker"), fs::Permissions::from_mode(0o755)).unwrap();
+
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-services", "run", "--timings"])
+        .arg(&project)
+        .env("PATH", format!("{}:/usr/bin:/bin", bin.display()))
+        .env("DX_STATE_DIR", tmp.path().join("state"))
+        .env("DX_CONFIG_DIR", tmp.path().join("config"))
+        .output()
+        .expect("failed to run dx dev-services run --timings");
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Perfil de inicialização"), "{}", stdout);
+    let row = |service: &str| stdout.lines().find(|l| l.trim_start().starts_with(service)).unwrap_or_default().to_string();
+    let mongodb = row("mongodb");
+    assert!(mongodb.contains("s  █") && mongodb.contains("healthy (anterior: 21.5s)"), "{}", stdout);
+    let kafka = row("kafka");
+    assert!(kafka.contains(" - ") && kafka.trim_end().ends_with("unhealthy"), "{}", stdout);
+    assert!(stdout.contains("\nGargalo: mongodb ("), "{}", stdout);
+    assert!(stdout.contains("  - kafka: Kafka: migre para KRaft single-node"), "{}", stdout);
+    assert!(stdout.contains("  - kafka: não ficou pronto (unhealthy)."), "{}", stdout);
+    assert!(!stdout.contains("MongoDB:"), "a fast service gets no hints:\n{}", stdout);
+
+    let saved = fs::read_to_string(project.join(".dx/startup-profile.json")).unwrap();
+    assert!(saved.contains("\"unhealthy\""), "{}", saved);
+}
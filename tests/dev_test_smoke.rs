@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::io::{BufRead, BufReader, Write};
+use std::net::TcpListener;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// Minimal application: 200 on /ok, 500 on /boom, 404 otherwise, for any number of requests.
+fn app_mock() -> String {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    std::thread::spawn(move || {
+        for mut stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut request_line = String::new();
+            reader.read_line(&mut request_line).unwrap();
+            loop {
+                let mut header = String::new();
+                reader.read_line(&mut header).unwrap();
+                if header.trim().is_empty() {
+                    break;
+                }
+            }
+            let status = match request_line.split_whitespace().nth(1).unwrap_or("") {
+                "/ok" => "200 OK",
+                "/boom" => "500 Internal Server Error",
+                _ => "404 Not Found",
+            };
+            let _ = write!(stream, "HTTP/1.1 {}\r\nContent-Length: 0\r\nConnection: close\r\n\r\n", status);
+        }
+    });
+    format!("http://{}", addr)
+}
+
+fn project(root: &Path) -> std::path::PathBuf {
+    let project = root.join("svc");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/svc\n\ngo 1.22\n").unwrap();
+    fs::write(
+        project.join("main.go"),
+        "package main\n\nfunc main() {\n\tr := gin.Default()\n\tr.GET(\"/ok\", ok)\n\tr.GET(\"/boom\", boom)\n\tr.GET(\"/users/:id\", getUser)\n\tr.POST(\"/users\", createUser)\n\tr.Run()\n}\n",
+    )
+    .unwrap();
+    project
+}
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-test", "smoke"])
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx dev-test smoke")
+}
+
+// Test that safe routes are called against a running instance, unsafe ones skipped, and 5xx fails the run
+#[test]
+fn dev_test_smoke_running_instance() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = project(tmp.path());
+    let url = app_mock();
+
+    let output = dx(&project, &["--url", &url, "--format", "json"]);
+    assert_eq!(output.status.code(), Some(1), "{}", String::from_utf8_lossy(&output.stderr));
+    let report: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    assert_eq!(report["base_url"], url.as_str());
+    assert_eq!(report["started"], false);
+    let checks = report["checks"].as_array().unwrap();
+    let find = |method: &str, path: &str| {
+        checks
+            .iter()
+            .find(|c| c["method"] == method && c["path"] == path)
+            .unwrap_or_else(|| panic!("{} {} missing: {}", method, path, report))
+    };
+    assert_eq!(find("GET", "/ok")["outcome"], "ok");
+    assert_eq!(find("GET", "/ok")["status"], 200);
+    assert!(find("GET", "/ok")["latency_ms"].is_u64());
+    assert_eq!(find("GET", "/boom")["outcome"], "failed");
+    assert_eq!(find("GET", "/boom")["status"], 500);
+    // Path parameters are filled in, and a 4xx is only a warning
+    assert_eq!(find("GET", "/users/{id}")["url"], format!("{}/users/1", url));
+    assert_eq!(find("GET", "/users/{id}")["outcome"], "warning");
+    assert_eq!(find("POST", "/users")["outcome"], "skipped");
+    assert!(find("POST", "/users").get("status").is_none());
+
+    let output = dx(&project, &["--url", &url]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(stdout.contains(&format!("Smoke test em {} (instância em execução):", url)), "{}", stdout);
+    assert!(stdout.contains("pulada (método não seguro)"), "{}", stdout);
+    assert!(stdout.contains("✗ 1 ok, 1 com aviso (4xx), 1 falha(s), 1 pulada(s)"), "{}", stdout);
+}
+
+// Test that --no-start refuses to start the application when nothing answers
+#[test]
+fn dev_test_smoke_no_start_without_instance() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = project(tmp.path());
+    let closed = TcpListener::bind("127.0.0.1:0").unwrap().local_addr().unwrap().port();
+
+    let output = dx(&project, &["--url", &format!("http://127.0.0.1:{}", closed), "--no-start"]);
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(stderr.contains(&format!("Nada respondendo em http://127.0.0.1:{}", closed)), "{}", stderr);
+}
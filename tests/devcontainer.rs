@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx dev-config devcontainer")
+}
+
+// Test that the devcontainer pins the Go toolchain of go.mod and attaches to the detected services
+#[test]
+fn devcontainer_go_with_services() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("shop");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/shop\n\ngo 1.22\n\ntoolchain go1.22.5\n\nrequire github.com/lib/pq v1.10.9\n").unwrap();
+    fs::write(
+        project.join("main.go"),
+        "package main\n\nimport (\n\t\"os\"\n\t_ \"github.com/lib/pq\"\n)\n\nfunc main() {\n\tport := os.Getenv(\"PORT\")\n\tif port == \"\" {\n\t\tport = \"9090\"\n\t}\n\tdb := os.Getenv(\"POSTGRES_URL\")\n\tif db == \"\" {\n\t\tdb = \"postgres://localhost:5432/shop\"\n\t}\n\t_, _ = port, db\n}\n",
+    )
+    .unwrap();
+
+    let output = dx(&project, &["dev-config", "devcontainer"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ Devcontainer gerado (Go 1.22.5; serviços: postgres)."), "{}", stdout);
+
+    let json = fs::read_to_string(project.join(".devcontainer/devcontainer.json")).unwrap();
+    assert!(json.starts_with("// Gerado por: dx dev-config devcontainer\n"), "{}", json);
+    let config: serde_json::Value = serde_json::from_str(json.split_once('\n').unwrap().1).unwrap();
+    assert_eq!(config["features"]["ghcr.io/devcontainers/features/go:1"]["version"], "1.22.5");
+    assert_eq!(config["dockerComposeFile"], "docker-compose.yml");
+    assert_eq!(config["service"], "app");
+    assert_eq!(config["runServices"], serde_json::json!(["postgres"]));
+    assert_eq!(config["forwardPorts"], serde_json::json!([9090, "postgres:5432"]));
+    assert_eq!(config["workspaceFolder"], "/workspaces/shop");
+
+    let compose = fs::read_to_string(project.join(".devcontainer/docker-compose.yml")).unwrap();
+    assert!(compose.contains("  app:\n    build: .\n"), "{}", compose);
+    assert!(compose.contains("POSTGRES_URL: postgres://postgres:5432/shop"), "{}", compose);
+    assert!(compose.contains("PGHOST: postgres"), "{}", compose);
+    assert!(compose.contains("- ..:/workspaces/shop:cached"), "{}", compose);
+    assert!(!compose.contains("'5432:5432'"), "no published ports: {}", compose);
+    assert!(!compose.contains("\n  ..:"), "bind mount is not a named volume: {}", compose);
+    let dockerfile = fs::read_to_string(project.join(".devcontainer/Dockerfile")).unwrap();
+    assert!(dockerfile.contains("postgresql-client"), "{}", dockerfile);
+}
+
+// Test that without services the devcontainer builds the Dockerfile, and hand-written files need --force
+#[test]
+fn devcontainer_without_services_keeps_hand_written_files() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("web");
+    fs::create_dir_all(project.join(".devcontainer")).unwrap();
+    fs::write(project.join("package.json"), r#"{"name": "web", "engines": {"node": ">=20.10"}}"#).unwrap();
+    fs::write(project.join("package-lock.json"), "{}").unwrap();
+    fs::write(project.join(".devcontainer/devcontainer.json"), "{ \"name\": \"mine\" }\n").unwrap();
+
+    let output = dx(&project, &["dev-config", "devcontainer"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("não foi gerado pelo dx"));
+    assert_eq!(fs::read_to_string(project.join(".devcontainer/devcontainer.json")).unwrap(), "{ \"name\": \"mine\" }\n");
+
+    let output = dx(&project, &["dev-config", "devcontainer", "--force"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let json = fs::read_to_string(project.join(".devcontainer/devcontainer.json")).unwrap();
+    let config: serde_json::Value = serde_json::from_str(json.split_once('\n').unwrap().1).unwrap();
+    assert_eq!(config["build"]["dockerfile"], "Dockerfile");
+    assert_eq!(config["features"]["ghcr.io/devcontainers/features/node:1"]["version"], "20");
+    assert_eq!(config["postCreateCommand"], "npm ci");
+    assert!(config.get("dockerComposeFile").is_none());
+    assert!(!project.join(".devcontainer/docker-compose.yml").exists());
+}
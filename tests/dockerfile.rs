@@ -0,0 +1,114 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx dev-config dockerfile")
+}
+
+// Test that a Go service gets a static build on the go.mod version, a non-root user and a health check on /healthz
+#[test]
+fn dockerfile_go_static_binary_with_healthcheck() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("shop");
+    fs::create_dir_all(project.join("cmd/server")).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/shop\n\ngo 1.22\n\ntoolchain go1.22.5\n").unwrap();
+    fs::write(project.join("go.sum"), "").unwrap();
+    fs::write(
+        project.join("cmd/server/main.go"),
+        "package main\n\nimport (\n\t\"os\"\n\n\t\"github.com/gin-gonic/gin\"\n)\n\nfunc main() {\n\tport := os.Getenv(\"PORT\")\n\tif port == \"\" {\n\t\tport = \"9090\"\n\t}\n\tr := gin.Default()\n\tr.GET(\"/orders\", listOrders)\n\tr.GET(\"/healthz\", health)\n\t_ = r.Run(\":\" + port)\n}\n",
+    )
+    .unwrap();
+
+    let output = dx(&project, &["dev-config", "dockerfile"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ Dockerfile gerado (Go; porta 9090; healthcheck GET /healthz)."), "{}", stdout);
+
+    let dockerfile = fs::read_to_string(project.join("Dockerfile")).unwrap();
+    assert!(dockerfile.starts_with("# Gerado por: dx dev-config dockerfile\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("FROM golang:1.22-alpine AS build\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("COPY go.mod go.sum ./\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("CGO_ENABLED=0 GOOS=linux go build -trimpath -ldflags=\"-s -w\" -o /out/app ./cmd/server\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("USER app\nEXPOSE 9090\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("CMD wget -q -O /dev/null http://127.0.0.1:9090/healthz || exit 1"), "{}", dockerfile);
+    let ignore = fs::read_to_string(project.join(".dockerignore")).unwrap();
+    assert!(ignore.lines().any(|l| l == ".git") && ignore.lines().any(|l| l == ".env"), "{}", ignore);
+}
+
+// Test that a Node app builds with its devDependencies and prunes them, and runs as the node user
+#[test]
+fn dockerfile_node_prunes_dev_dependencies() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("web");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(
+        project.join("package.json"),
+        r#"{"name": "web", "engines": {"node": ">=20.10"}, "scripts": {"build": "tsc", "start": "node dist/index.js"}, "devDependencies": {"typescript": "^5.4.0"}}"#,
+    )
+    .unwrap();
+    fs::write(project.join("package-lock.json"), "{}").unwrap();
+    fs::write(project.join("index.js"), "const app = require('express')();\napp.get('/', (req, res) => res.send('ok'));\napp.listen(process.env.PORT || 3000);\n").unwrap();
+
+    let output = dx(&project, &["dev-config", "dockerfile", "--no-save"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(!project.join("Dockerfile").exists());
+    assert!(stdout.contains("FROM node:20-alpine AS build\n"), "{}", stdout);
+    assert!(stdout.contains("COPY package.json package-lock.json ./\nRUN npm ci\nCOPY . .\nRUN npm run build\nRUN npm prune --omit=dev\n"), "{}", stdout);
+    assert!(stdout.contains("ENV NODE_ENV=production\n"), "{}", stdout);
+    assert!(stdout.contains("COPY --from=build --chown=node:node /app ./\nUSER node\nEXPOSE 3000\n"), "{}", stdout);
+    assert!(stdout.contains("http://127.0.0.1:3000/ || exit 1"), "{}", stdout);
+    assert!(stdout.ends_with("CMD [\"npm\", \"start\"]\n"), "{}", stdout);
+}
+
+// Test that Python installs from wheels as a non-root user, and that a hand-written Dockerfile needs --force
+#[test]
+fn dockerfile_python_wheels_keeps_hand_written_file() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("api");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("requirements.txt"), "fastapi\nuvicorn\n").unwrap();
+    fs::write(project.join(".python-version"), "3.11.9\n").unwrap();
+    fs::write(project.join("main.py"), "from fastapi import FastAPI\n\napp = FastAPI()\n\n@app.get(\"/health\")\ndef health():\n    return {}\n").unwrap();
+    fs::write(project.join("Dockerfile"), "FROM python:3\n").unwrap();
+
+    let output = dx(&project, &["dev-config", "dockerfile"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("não foi gerado pelo dx"));
+    assert_eq!(fs::read_to_string(project.join("Dockerfile")).unwrap(), "FROM python:3\n");
+
+    let output = dx(&project, &["dev-config", "dockerfile", "--force"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("(Python; porta 8000; healthcheck GET /health)"), "{}", stdout);
+    let dockerfile = fs::read_to_string(project.join("Dockerfile")).unwrap();
+    assert!(dockerfile.contains("FROM python:3.11-slim AS build\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("RUN pip wheel --no-cache-dir --wheel-dir /wheels -r requirements.txt\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("RUN pip install --no-cache-dir --no-index /wheels/*"), "{}", dockerfile);
+    assert!(dockerfile.contains("RUN useradd --create-home --uid 10001 app\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("USER app\n"), "{}", dockerfile);
+    assert!(dockerfile.contains("urlopen('http://127.0.0.1:8000/health', timeout=3)"), "{}", dockerfile);
+    assert!(dockerfile.contains("CMD [\"uvicorn\", \"main:app\", \"--host\", \"0.0.0.0\", \"--port\", \"8000\"]"), "{}", dockerfile);
+}
+
+// Test that stacks without a production template are reported
+#[test]
+fn dockerfile_unsupported_stack() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("lib");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("pom.xml"), "<project/>").unwrap();
+
+    let output = dx(&project, &["dev-config", "dockerfile"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("ainda não suportada"));
+    assert!(!project.join("Dockerfile").exists());
+}
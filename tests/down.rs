@@ -0,0 +1,100 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// dx with a fake docker (that logs its arguments to `docker.log`) first on PATH and a private state.
+fn dx(root: &Path, args: &[&str]) -> Output {
+    let bin = root.join("bin");
+    fs::create_dir_all(&bin).unwrap();
+    let docker = bin.join("docker");
+    fs::write(&docker, format!("#!/bin/sh\necho \"$*\" >> {}\n", root.join("docker.log").display())).unwrap();
+    fs::set_permissions(&docker, fs::Permissions::from_mode(0o755)).unwrap();
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(root)
+        .env("PATH", format!("{}:/usr/bin:/bin", bin.display()))
+        .env("DX_STATE_DIR", root.join("state"))
+        .env_remove("COMPOSE_PROJECT_NAME")
+        .output()
+        .expect("failed to run dx")
+}
+
+/// Lines docker received since the last call, clearing the log.
+fn docker_calls(root: &Path) -> Vec<String> {
+    let log = root.join("docker.log");
+    let calls = fs::read_to_string(&log).unwrap_or_default().lines().map(str::to_string).collect();
+    let _ = fs::remove_file(&log);
+    calls
+}
+
+fn project(root: &Path, name: &str) -> String {
+    let dir = root.join(name);
+    fs::create_dir_all(&dir).unwrap();
+    fs::write(dir.join("docker-compose.yml"), "services:\n  cache:\n    image: redis:7\n").unwrap();
+    dir.to_string_lossy().into_owned()
+}
+
+// Test that `dx down` stops the containers, and removes networks and volumes only when asked
+#[test]
+fn down_stops_or_removes() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    let shop = project(root, "shop");
+    assert!(dx(root, &["dev-services", "run", &shop]).status.success());
+    docker_calls(root);
+
+    let output = dx(root, &["down", &shop]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("Serviços parados; containers, redes e volumes mantidos."));
+    assert_eq!(docker_calls(root), [format!("compose -f {}/docker-compose.yml stop", fs::canonicalize(&shop).unwrap().display())]);
+
+    assert!(dx(root, &["down", "--networks", &shop]).status.success());
+    assert!(docker_calls(root)[0].ends_with("docker-compose.yml down --remove-orphans"));
+
+    let environments = root.join("state").join("environments.json");
+    assert!(fs::read_to_string(&environments).unwrap().contains("shop"));
+    assert!(dx(root, &["down", "--volumes", &shop]).status.success());
+    assert!(docker_calls(root)[0].ends_with("docker-compose.yml down -v --remove-orphans"));
+    assert!(!fs::read_to_string(&environments).unwrap().contains("shop"), "removed environments are no longer tracked");
+
+    // No compose file and nothing tracked
+    let empty = root.join("empty");
+    fs::create_dir_all(&empty).unwrap();
+    let output = dx(root, &["down", empty.to_str().unwrap()]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Nenhum ambiente do dx neste projeto"));
+    assert!(docker_calls(root).is_empty());
+}
+
+// Test that `dx down --prune` removes the environments of deleted projects, then the ones unused for --older-than days
+#[test]
+fn down_prunes_old_environments() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    let (shop, legacy) = (project(root, "shop"), project(root, "Legacy.App"));
+    fs::write(Path::new(&legacy).join("docker-compose.yml"), "services:\n  db:\n    image: postgres:16\n").unwrap();
+    assert!(dx(root, &["dev-services", "run", &shop]).status.success());
+    assert!(dx(root, &["dev-services", "run", &legacy]).status.success());
+    fs::remove_dir_all(&legacy).unwrap();
+    docker_calls(root);
+
+    // The deleted project is cleaned by its compose project name
+    let output = dx(root, &["down", "--prune"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Legacy.App (diretório não existe mais)"), "{}", stdout);
+    assert!(stdout.contains("✓ 1 de 1 ambiente(s) removido(s)"), "{}", stdout);
+    assert_eq!(docker_calls(root), ["compose -p legacyapp down -v --remove-orphans"]);
+
+    let output = dx(root, &["down", "--prune"]);
+    assert!(String::from_utf8_lossy(&output.stdout).contains("Nenhum ambiente para remover: 1 acompanhado(s) pelo dx"));
+    assert!(docker_calls(root).is_empty());
+
+    assert!(dx(root, &["down", "--prune", "--older-than", "0"]).status.success());
+    assert!(docker_calls(root)[0].ends_with("shop/docker-compose.yml down -v --remove-orphans"));
+    assert_eq!(dx(root, &["down", "--prune", "--volumes"]).status.code(), Some(2));
+}
@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// dx with a fake docker (logging its arguments to `docker.log`, with one running container and
+/// one volume per project) first on PATH and a private state.
+fn dx(root: &Path, args: &[&str]) -> Output {
+    let bin = root.join("bin");
+    fs::create_dir_all(&bin).unwrap();
+    let docker = bin.join("docker");
+    let script = format!(
+        "#!/bin/sh\necho \"$*\" >> {}\ncase \"$1\" in ps) echo running ;; volume) echo data ;; esac\n",
+        root.join("docker.log").display()
+    );
+    fs::write(&docker, script).unwrap();
+    fs::set_permissions(&docker, fs::Permissions::from_mode(0o755)).unwrap();
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(root)
+        .env("PATH", format!("{}:/usr/bin:/bin", bin.display()))
+        .env("DX_STATE_DIR", root.join("state"))
+        .env("DX_CACHE_DIR", root.join("cache"))
+        .env_remove("COMPOSE_PROJECT_NAME")
+        .output()
+        .expect("failed to run dx")
+}
+
+// Test that two checkouts with the same directory name get separate compose projects, listed by
+// `dx env list` and removed by name with `dx env rm`
+#[test]
+fn env_isolates_list_and_remove() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    let mut checkouts = Vec::new();
+    for parent in ["work", "fork"] {
+        let dir = root.join(parent).join("api");
+        fs::create_dir_all(&dir).unwrap();
+        fs::write(dir.join(".env"), "REDIS_URL=redis://localhost:6379\n").unwrap();
+        let dir = dir.to_string_lossy().into_owned();
+        assert!(dx(root, &["dev-services", &dir]).status.success());
+        assert!(dx(root, &["dev-services", "run", &dir]).status.success());
+        checkouts.push(dir);
+    }
+    let names: Vec<String> = checkouts
+        .iter()
+        .map(|dir| {
+            let compose = fs::read_to_string(Path::new(dir).join(".dx/docker-compose.yml")).unwrap();
+            compose.lines().find_map(|l| l.strip_prefix("name: ")).expect("compose project name").to_string()
+        })
+        .collect();
+    assert!(names.iter().all(|n| n.starts_with("dx-api-")), "{:?}", names);
+    assert_ne!(names[0], names[1]);
+
+    let output = dx(root, &["--output", "json", "env", "list"]);
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(list.as_array().unwrap().len(), 2, "{}", list);
+    assert_eq!(list[0]["running"], 1);
+    assert_eq!(list[0]["volumes"], 1);
+    let _ = fs::remove_file(root.join("docker.log"));
+
+    let output = dx(root, &["env", "rm", &names[1]]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let calls = fs::read_to_string(root.join("docker.log")).unwrap();
+    assert!(calls.contains("fork/api/.dx/docker-compose.yml down -v --remove-orphans"), "{}", calls);
+    let output = dx(root, &["--output", "json", "env", "list"]);
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(list.as_array().unwrap().len(), 1);
+    assert_eq!(list[0]["name"], names[0].as_str());
+
+    assert_eq!(dx(root, &["env", "rm", "nope"]).status.code(), Some(1));
+}
@@ -0,0 +1,206 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(root: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["generate", "asyncapi"])
+        .args(args)
+        .arg(root)
+        .env("DX_STATE_DIR", root.join(".dx-state"))
+        .env_remove("KAFKA_BROKERS")
+        .env_remove("KAFKA_BOOTSTRAP_SERVERS")
+        .output()
+        .expect("failed to run dx generate asyncapi")
+}
+
+// Test that Kafka events and consumers and RabbitMQ exchanges and queues become AsyncAPI channels
+#[test]
+fn generate_asyncapi_kafka_and_rabbitmq() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::write(
+        root.join("events.go"),
+        r#"package shop
+
+import (
+	"encoding/json"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/segmentio/kafka-go"
+)
+
+type Item struct {
+	SKU string `json:"sku"`
+}
+
+type OrderEvent struct {
+	Type    string    `json:"type"`
+	OrderID string    `json:"order_id"`
+	Items   []Item    `json:"items"`
+	At      time.Time `json:"at"`
+}
+
+type ShipmentRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+func publishOrder(w *kafka.Writer, event OrderEvent) error {
+	value, _ := json.Marshal(event)
+	return w.WriteMessages(ctx, kafka.Message{Topic: "orders", Key: []byte(event.OrderID), Value: value})
+}
+
+func requestShipment(ch *amqp.Channel, req ShipmentRequest) error {
+	ch.ExchangeDeclare("logistics", "topic", true, false, false, false, nil)
+	body, _ := json.Marshal(req)
+	return ch.PublishWithContext(ctx, "logistics", "shipment.requested", false, false, amqp.Publishing{Body: body})
+}
+
+func consume(ch *amqp.Channel) {
+	msgs, _ := ch.Consume("billing", "", true, false, false, false, nil)
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{"localhost:9092"},
+		Topic:   "payments",
+	})
+	_, _ = msgs, r
+}
+
+func placed() OrderEvent { return OrderEvent{Type: "ORDER_PLACED"} }
+"#,
+    )
+    .unwrap();
+
+    let output = dx(root, &["--out", "docs/asyncapi.json"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("AsyncAPI 3.0 com 4 canal(is), 4 operação(ões) e 2 mensagem(ns) em docs/asyncapi.json"), "{}", stdout);
+    let doc: serde_json::Value = serde_json::from_str(&fs::read_to_string(root.join("docs/asyncapi.json")).unwrap()).unwrap();
+    assert_eq!(doc["asyncapi"], "3.0.0");
+    assert_eq!(doc["servers"]["kafka"]["protocol"], "kafka");
+    assert_eq!(doc["servers"]["rabbitmq"]["host"], "localhost:5672");
+
+    // Kafka: the published event and the consumed topic
+    assert_eq!(doc["channels"]["orders"]["messages"]["OrderEvent"]["$ref"], "#/components/messages/OrderEvent");
+    assert_eq!(doc["operations"]["sendOrders"]["messages"][0]["$ref"], "#/channels/orders/messages/OrderEvent");
+    assert_eq!(doc["operations"]["receivePayments"]["channel"]["$ref"], "#/channels/payments");
+    assert_eq!(doc["components"]["messages"]["OrderEvent"]["bindings"]["kafka"]["key"]["type"], "string");
+    let order = &doc["components"]["schemas"]["OrderEvent"];
+    assert_eq!(order["properties"]["type"]["enum"], serde_json::json!(["ORDER_PLACED"]));
+    assert_eq!(order["properties"]["items"]["items"]["$ref"], "#/components/schemas/Item");
+    assert_eq!(doc["components"]["schemas"]["Item"]["properties"]["sku"]["type"], "string");
+
+    // RabbitMQ: a routing key of a topic exchange and a consumed queue
+    let shipment = &doc["channels"]["logistics.shipment.requested"];
+    assert_eq!(shipment["address"], "shipment.requested");
+    assert_eq!(shipment["bindings"]["amqp"]["exchange"], serde_json::json!({"name": "logistics", "type": "topic"}));
+    assert_eq!(shipment["messages"]["ShipmentRequest"]["$ref"], "#/components/messages/ShipmentRequest");
+    assert_eq!(doc["operations"]["sendLogisticsShipmentRequested"]["action"], "send");
+    assert_eq!(doc["channels"]["billing"]["bindings"]["amqp"]["queue"]["name"], "billing");
+    assert_eq!(doc["operations"]["receiveBilling"]["action"], "receive");
+
+    // YAML by default, kept in sync with --check
+    assert!(dx(root, &[]).status.success());
+    assert!(fs::read_to_string(root.join("asyncapi.yaml")).unwrap().starts_with("asyncapi: 3.0.0\n"));
+    assert_eq!(dx(root, &["--check"]).status.code(), Some(0));
+    fs::write(root.join("more.go"), "package shop\n\nfunc canceled() OrderEvent { return OrderEvent{Type: \"ORDER_CANCELED\"} }\n").unwrap();
+    let output = dx(root, &["--check"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("asyncapi.yaml desatualizado"));
+}
+
+// Test that Spring listeners bring their payload class and that a project without messaging fails
+#[test]
+fn generate_asyncapi_spring_listeners() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::write(
+        root.join("Listeners.java"),
+        r#"package shop;
+
+public class Listeners {
+    @RabbitListener(queues = "invoices")
+    public void invoice(InvoiceCreated event) {}
+
+    @KafkaListener(topics = "refunds", groupId = "billing")
+    public void refund(@Payload RefundEvent event) {}
+}
+
+class InvoiceCreated {
+    private String invoiceId;
+    private java.math.BigDecimal total;
+}
+
+class RefundEvent {
+    private String refundId;
+}
+"#,
+    )
+    .unwrap();
+
+    let output = dx(root, &["--out", "asyncapi.json"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_str(&fs::read_to_string(root.join("asyncapi.json")).unwrap()).unwrap();
+    assert_eq!(doc["operations"]["receiveInvoices"]["messages"][0]["$ref"], "#/channels/invoices/messages/InvoiceCreated");
+    assert_eq!(doc["components"]["schemas"]["InvoiceCreated"]["properties"]["total"]["type"], "number");
+    assert_eq!(doc["operations"]["receiveRefunds"]["messages"][0]["$ref"], "#/channels/refunds/messages/RefundEvent");
+
+    fs::remove_file(root.join("Listeners.java")).unwrap();
+    assert_eq!(dx(root, &[]).status.code(), Some(2));
+}
+
+// Test that a kafka-go reader configured from a variable reads the project's only topic, its dead-letter
+// topic aside, as in the Go sample
+#[test]
+fn generate_asyncapi_kafka_go_reader_on_configured_topic() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::write(
+        root.join("main.go"),
+        r#"package main
+
+import (
+	"os"
+
+	"github.com/segmentio/kafka-go"
+)
+
+type UserEvent struct {
+	UserID string `json:"user_id"`
+}
+
+func main() {
+	topic := os.Getenv("KAFKA_TOPIC_USERS")
+	if topic == "" {
+		topic = "users"
+	}
+	dlqTopic := os.Getenv("KAFKA_DLQ_TOPIC")
+	if dlqTopic == "" {
+		dlqTopic = "users.dlq"
+	}
+	dlq := kafka.NewWriter(kafka.WriterConfig{Brokers: []string{"localhost:9092"}, Topic: dlqTopic})
+	_ = dlq
+	w := kafka.NewWriter(kafka.WriterConfig{Brokers: []string{"localhost:9092"}, Topic: topic})
+	_ = w.WriteMessages(ctx, kafka.Message{Value: mustJSON(UserEvent{UserID: "1"})})
+	r := kafka.NewReader(kafka.ReaderConfig{
+		Brokers: []string{"localhost:9092"},
+		GroupID: "audit",
+		Topic:   topic,
+	})
+	_ = r
+}
+"#,
+    )
+    .unwrap();
+
+    let output = dx(root, &["--out", "asyncapi.json"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let doc: serde_json::Value = serde_json::from_str(&fs::read_to_string(root.join("asyncapi.json")).unwrap()).unwrap();
+    assert_eq!(doc["operations"]["sendUsers"]["action"], "send");
+    assert_eq!(doc["operations"]["receiveUsers"]["action"], "receive");
+    assert_eq!(doc["operations"]["receiveUsers"]["channel"]["$ref"], "#/channels/users");
+    assert_eq!(doc["channels"]["users.dlq"]["address"], "users.dlq");
+    assert!(doc["operations"].get("sendUsersDlq").is_none(), "{}", doc["operations"]);
+}
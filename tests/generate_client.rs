@@ -0,0 +1,193 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(root: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["generate", "client"])
+        .args(args)
+        .arg(root)
+        .env("DX_STATE_DIR", root.join(".dx-state"))
+        .output()
+        .expect("failed to run dx generate client")
+}
+
+const OPENAPI: &str = r##"openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+servers:
+  - url: /api/v1
+paths:
+  /users:
+    get:
+      operationId: listUsers
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+      responses:
+        "200":
+          description: ok
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: "#/components/schemas/User"
+    post:
+      operationId: createUser
+      requestBody:
+        content:
+          application/json:
+            schema:
+              type: object
+              required:
+                - name
+              properties:
+                name:
+                  type: string
+      responses:
+        "201":
+          description: created
+          content:
+            application/json:
+              schema:
+                $ref: "#/components/schemas/User"
+  /users/{id}:
+    delete:
+      responses:
+        "204":
+          description: deleted
+components:
+  schemas:
+    User:
+      type: object
+      required:
+        - id
+      properties:
+        id:
+          type: string
+        status:
+          type: string
+          enum:
+            - active
+            - blocked
+"##;
+
+// Test that TypeScript and Go clients are generated from an OpenAPI spec and checked for drift
+#[test]
+fn generate_client_from_openapi() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::create_dir_all(root.join("api")).unwrap();
+    fs::write(root.join("api/openapi.yaml"), OPENAPI).unwrap();
+
+    let output = dx(root, &["--lang", "ts", "--out", "web/src/api"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Cliente TypeScript para api/openapi.yaml (3 operações, 3 tipos)"), "{}", stdout);
+    let ts = fs::read_to_string(root.join("web/src/api/client.ts")).unwrap();
+    assert!(ts.contains("export interface User {\n  id: string;\n  status?: UserStatus;\n}\n"), "{}", ts);
+    assert!(ts.contains("export type UserStatus = 'active' | 'blocked';"), "{}", ts);
+    assert!(ts.contains("  listUsers(query: { limit?: number } = {}): Promise<User[]> {\n"), "{}", ts);
+    assert!(ts.contains("  createUser(body: CreateUserRequest): Promise<User> {\n"), "{}", ts);
+    assert!(ts.contains("this.request<void>('DELETE', `/api/v1/users/${encodeURIComponent(String(id))}`)"), "{}", ts);
+
+    let output = dx(root, &["--lang", "go", "--out", "pkg/usersclient"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let go = fs::read_to_string(root.join("pkg/usersclient/client.go")).unwrap();
+    assert!(go.starts_with("// Code generated by dx generate client from api/openapi.yaml. DO NOT EDIT.\n\npackage usersclient\n"), "{}", go);
+    assert!(go.contains("type User struct {\n\tID     string     `json:\"id\"`\n\tStatus UserStatus `json:\"status,omitempty\"`\n}\n"), "{}", go);
+    assert!(go.contains("func (c *Client) ListUsers(ctx context.Context, params *ListUsersParams) ([]User, error) {"), "{}", go);
+    assert!(go.contains("func (c *Client) DeleteUsersByID(ctx context.Context, id string) error {"), "{}", go);
+
+    // The client follows the spec: --check fails once the routes change
+    assert_eq!(dx(root, &["--lang", "go", "--out", "pkg/usersclient", "--check"]).status.code(), Some(0));
+    fs::write(root.join("api/openapi.yaml"), OPENAPI.replace("operationId: listUsers", "operationId: searchUsers")).unwrap();
+    let output = dx(root, &["--lang", "go", "--out", "pkg/usersclient", "--check"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("~ pkg/usersclient/client.go"));
+
+    // Two specs are ambiguous unless --spec picks one
+    fs::write(root.join("api/admin.json"), r#"{"openapi": "3.0.0", "paths": {}}"#).unwrap();
+    assert_eq!(dx(root, &["--lang", "ts"]).status.code(), Some(2));
+    assert!(dx(root, &["--lang", "ts", "--spec", "api/openapi.yaml"]).status.success());
+}
+
+// Test that proto services and, without any spec, routes found in the code become clients
+#[test]
+fn generate_client_from_proto_and_routes() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    fs::create_dir_all(root.join("proto")).unwrap();
+    fs::write(
+        root.join("proto/orders.proto"),
+        r#"syntax = "proto3";
+package shop.v1;
+
+service OrderService {
+  rpc GetOrder(GetOrderRequest) returns (Order) {
+    option (google.api.http) = { get: "/v1/orders/{order_id}" };
+  }
+  rpc ListOrders(ListOrdersRequest) returns (ListOrdersResponse);
+  rpc Watch(GetOrderRequest) returns (stream Order);
+}
+
+message Order {
+  string order_id = 1;
+  int64 total_cents = 2;
+  Status status = 3;
+  enum Status {
+    STATUS_UNSPECIFIED = 0;
+    STATUS_PAID = 1;
+  }
+}
+
+message GetOrderRequest {
+  string order_id = 1;
+  bool include_items = 2;
+}
+
+message ListOrdersRequest {
+  int32 page_size = 1 [json_name = "limit"];
+}
+
+message ListOrdersResponse {
+  repeated Order orders = 1;
+}
+"#,
+    )
+    .unwrap();
+
+    let output = dx(root, &["--lang", "python"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let py = fs::read_to_string(root.join("clients/python/client.py")).unwrap();
+    assert!(py.contains("class Order(TypedDict, total=False):\n    orderId: Optional[str]\n    totalCents: Optional[str]\n    status: Optional[OrderStatus]\n"), "{}", py);
+    assert!(py.contains("OrderStatus = Literal[\"STATUS_UNSPECIFIED\", \"STATUS_PAID\"]"), "{}", py);
+    assert!(py.contains("    limit: Optional[int]\n"), "{}", py);
+    assert!(py.contains("    def get_order(self, order_id: str, include_items: Optional[bool] = None) -> Order:\n"), "{}", py);
+    // Rpcs without an HTTP binding use Connect's unary JSON convention; streams are left out
+    assert!(py.contains("return self._request(\"POST\", \"/shop.v1.OrderService/ListOrders\", None, body)"), "{}", py);
+    assert!(!py.contains("def watch"), "{}", py);
+    assert!(fs::read_to_string(root.join("clients/python/__init__.py")).unwrap().contains("from .client import *"));
+
+    // No spec at all: gin routes, with their groups, are read from the code
+    fs::remove_dir_all(root.join("proto")).unwrap();
+    fs::write(
+        root.join("main.go"),
+        "package main\n\nfunc routes(r *gin.Engine) {\n\tapi := r.Group(\"/api\")\n\tapi.GET(\"/users/:id\", getUser)\n\tapi.POST(\"/users\", createUser)\n}\n",
+    )
+    .unwrap();
+    let output = dx(root, &["--lang", "ts"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let ts = fs::read_to_string(root.join("clients/ts/client.ts")).unwrap();
+    assert!(ts.contains("/** GET /api/users/{id} — main.go:5 */\n  getApiUsersById(id: string): Promise<unknown> {"), "{}", ts);
+    assert!(ts.contains("  postApiUsers(body: unknown): Promise<unknown> {"), "{}", ts);
+
+    fs::remove_file(root.join("main.go")).unwrap();
+    assert_eq!(dx(root, &["--lang", "go"]).status.code(), Some(2));
+}
@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::process::Command;
+
+// Test that commands are recorded per directory and can be repeated with `dx rerun`
+#[cfg(unix)]
+#[test]
+fn history_records_and_reruns_commands() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("dx.yaml"), "tasks:\n  hello:\n    run: echo hello\n  broken:\n    run: exit 3\n")
+        .expect("write dx.yaml");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let state = dir.path().join(".dx-state");
+    let run = |args: &[&str]| {
+        Command::new(exe).args(args).current_dir(dir.path()).env("DX_STATE_DIR", &state).output().expect("run dx")
+    };
+    assert!(run(&["allow"]).status.success());
+
+    assert!(run(&["run", "hello"]).status.success());
+    assert_eq!(run(&["run", "broken"]).status.code(), Some(3));
+
+    let history = run(&["history"]);
+    assert!(history.status.success());
+    let listing = String::from_utf8_lossy(&history.stdout);
+    assert!(listing.contains("dx run hello"), "{}", listing);
+    assert!(listing.contains("código 3"), "failures should show the exit code:\n{}", listing);
+
+    let printed = run(&["rerun", "--print", "hello"]);
+    assert_eq!(String::from_utf8_lossy(&printed.stdout).trim(), "dx run hello");
+
+    // Without a selector the last command (the failing one) is repeated, keeping its exit code
+    assert_eq!(run(&["rerun"]).status.code(), Some(3));
+
+    let rerun = run(&["rerun", "1"]);
+    assert!(rerun.status.success());
+    assert_eq!(String::from_utf8_lossy(&rerun.stdout).trim(), "hello");
+
+    // Neither is the export the direnv hook runs on every `cd`
+    assert!(run(&["dev-env", "export", "--format", "sh"]).status.success());
+
+    // history/rerun themselves are not recorded, the repeated commands are
+    let lines = fs::read_to_string(dir.path().join(".dx/history.jsonl")).expect("history file");
+    assert_eq!(lines.lines().count(), 4, "{}", lines);
+
+    // Also with a global option and its value before the subcommand: only the repeated command is added
+    assert!(run(&["--output", "json", "rerun", "1"]).status.success());
+    assert!(run(&["--progress", "text", "dev-env", "export", "--format", "sh"]).status.success());
+    let lines = fs::read_to_string(dir.path().join(".dx/history.jsonl")).expect("history file");
+    assert_eq!(lines.lines().count(), 5, "{}", lines);
+    assert!(!lines.contains("\"rerun\"") && !lines.contains("\"export\""), "{}", lines);
+
+    // A rerun recorded by an earlier version is never replayed, which would loop forever
+    let entry = r#"{"started_at":1,"duration_ms":1,"exit_code":0,"args":["--output","json","rerun"]}"#;
+    fs::write(dir.path().join(".dx/history.jsonl"), format!("{}{}\n", lines, entry)).unwrap();
+    let replay = run(&["rerun"]);
+    assert_eq!(replay.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&replay.stderr).contains("não pode ser repetido"), "{}", String::from_utf8_lossy(&replay.stderr));
+}
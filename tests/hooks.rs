@@ -0,0 +1,163 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::{Path, PathBuf};
+use std::process::{Command, Output};
+
+/// Executable script in `bin` standing in for a real tool.
+fn fake_tool(bin: &Path, name: &str, script: &str) {
+    let path = bin.join(name);
+    fs::write(&path, format!("#!/bin/sh\n{}\n", script)).unwrap();
+    fs::set_permissions(&path, fs::Permissions::from_mode(0o755)).unwrap();
+}
+
+/// The real program `name` found on the test's PATH.
+fn real_tool(name: &str) -> PathBuf {
+    let path = std::env::var_os("PATH").unwrap();
+    std::env::split_paths(&path).map(|d| d.join(name)).find(|p| p.is_file()).unwrap_or_else(|| panic!("{} not found", name))
+}
+
+/// Git repository at `repo` with a `bin` holding only git, sh and the fake tools of each test.
+fn setup(tmp: &Path) -> (PathBuf, PathBuf) {
+    let (repo, bin) = (tmp.join("repo"), tmp.join("bin"));
+    fs::create_dir_all(&repo).unwrap();
+    fs::create_dir_all(&bin).unwrap();
+    fake_tool(&bin, "git", &format!("exec {} \"$@\"", real_tool("git").display()));
+    std::os::unix::fs::symlink(real_tool("sh"), bin.join("sh")).unwrap();
+    git(&repo, &["init", "--quiet"]);
+    (repo, bin)
+}
+
+fn git(dir: &Path, args: &[&str]) {
+    let status = Command::new("git").arg("-C").arg(dir).args(args).status().expect("failed to run git");
+    assert!(status.success(), "git {:?}", args);
+}
+
+fn dx(dir: &Path, bin: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .arg(dir)
+        .env("PATH", bin)
+        .env_remove("CI")
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx")
+}
+
+// Test that the hooks installed for a project in a subdirectory call dx from the repository root, and hand-written ones need --force
+#[test]
+fn hooks_install_shims() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (repo, bin) = setup(tmp.path());
+    let project = repo.join("services/api");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/api\n\ngo 1.22\n").unwrap();
+    let hooks = repo.join(".git/hooks");
+    fs::write(hooks.join("pre-push"), "#!/bin/sh\nmake test\n").unwrap();
+
+    let output = dx(&project, &bin, &["dev-config", "hooks"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("pre-push já existe e não foi gerado pelo dx"));
+    assert!(!hooks.join("pre-commit").exists());
+
+    let output = dx(&project, &bin, &["dev-config", "hooks", "--force"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ Hooks do git instalados (Go): pre-commit roda gofmt, go vet, segredos; pre-push roda também os testes."), "{}", stdout);
+    assert!(stdout.contains("dx hooks run pre-commit --all-files"), "{}", stdout);
+    for name in ["pre-commit", "pre-push"] {
+        let script = fs::read_to_string(hooks.join(name)).unwrap();
+        assert!(script.starts_with("#!/bin/sh\n# Gerado por: dx dev-config hooks\n"), "{}", script);
+        assert!(script.ends_with(&format!("exec dx hooks run {} services/api\n", name)), "{}", script);
+        assert_eq!(fs::metadata(hooks.join(name)).unwrap().permissions().mode() & 0o111, 0o111);
+    }
+
+    // Outside a repository there is nowhere to install
+    let outside = tmp.path().join("outside");
+    fs::create_dir_all(&outside).unwrap();
+    let output = dx(&outside, &bin, &["dev-config", "hooks"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("não está em um repositório git"));
+}
+
+// Test that pre-commit checks the staged Go files with gofmt and go vet and every staged file for secrets, and pre-push also runs the tests
+#[test]
+fn hooks_run_go_checks() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (repo, bin) = setup(tmp.path());
+    let log = tmp.path().join("go.log");
+    fake_tool(&bin, "gofmt", "shift; for f in \"$@\"; do while read -r l; do case \"$l\" in *'main(){'*) echo \"$f\";; esac; done < \"$f\"; done");
+    fake_tool(&bin, "go", &format!("echo \"go $*\" >> {}", log.display()));
+    fs::write(repo.join("go.mod"), "module example.com/app\n\ngo 1.22\n").unwrap();
+    fs::write(repo.join("main.go"), "package main\nfunc main(){}\n").unwrap();
+    fs::write(repo.join("README.md"), "# app\n").unwrap();
+    git(&repo, &["add", "-A"]);
+
+    let output = dx(&repo, &bin, &["hooks", "run", "pre-commit"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("dx hooks run pre-commit (Go; 3 arquivo(s))"), "{}", stdout);
+    assert!(stdout.contains("  ✗ gofmt\n      main.go\n      Corrija com: gofmt -w .\n"), "{}", stdout);
+    assert!(stdout.contains("  ✓ go vet\n"), "{}", stdout);
+    assert!(stdout.contains("  ✓ segredos\n"), "{}", stdout);
+    assert!(stdout.contains("✗ pre-commit: 1 de 3 verificação(ões) falharam: gofmt"), "{}", stdout);
+
+    fs::write(repo.join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+    git(&repo, &["add", "-A"]);
+    let output = dx(&repo, &bin, &["hooks", "run", "pre-commit"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", stdout);
+    assert!(stdout.contains("✓ pre-commit: 3 verificação(ões) ok."), "{}", stdout);
+
+    let output = dx(&repo, &bin, &["hooks", "run", "pre-push"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", stdout);
+    assert!(stdout.contains("  ✓ go test ./...\n"), "{}", stdout);
+    assert!(stdout.contains("✓ pre-push: 4 verificação(ões) ok."), "{}", stdout);
+    assert_eq!(fs::read_to_string(&log).unwrap(), "go vet ./...\ngo vet ./...\ngo vet ./...\ngo test ./...\n");
+
+    // A staged credential fails the commit (the key is put together here so this file is not a finding)
+    fs::write(repo.join("deploy.yaml"), format!("aws_access_key_id: AKIA{}\n", "Q3EXAMPLE7KD2N4F")).unwrap();
+    git(&repo, &["add", "-A"]);
+    let output = dx(&repo, &bin, &["hooks", "run", "pre-commit"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("  ✗ segredos\n      deploy.yaml:1  aws-access-key-id  AKIA************"), "{}", stdout);
+    assert!(stdout.contains("✗ pre-commit: 1 de 3 verificação(ões) falharam: segredos"), "{}", stdout);
+}
+
+// Test that a missing formatter is skipped on a workstation but fails in CI, where --all-files checks every tracked file
+#[test]
+fn hooks_run_missing_tool_fails_in_ci() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (repo, bin) = setup(tmp.path());
+    fs::write(repo.join("requirements.txt"), "flask\n").unwrap();
+    fs::write(repo.join("app.py"), "print('ok')\n").unwrap();
+
+    // Nothing staged yet
+    let output = dx(&repo, &bin, &["hooks", "run", "pre-commit"]);
+    assert!(output.status.success());
+    assert!(String::from_utf8_lossy(&output.stdout).contains("Nada a verificar."));
+
+    git(&repo, &["add", "-A"]);
+    let output = dx(&repo, &bin, &["hooks", "run", "pre-commit"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", stdout);
+    assert!(stdout.contains("  ⚠ black: não encontrado no PATH\n"), "{}", stdout);
+    assert!(stdout.contains("verificações que foram puladas: black"), "{}", stdout);
+
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["hooks", "run", "pre-commit", "--all-files"])
+        .arg(&repo)
+        .env("PATH", &bin)
+        .env("CI", "true")
+        .env("DX_STATE_DIR", tmp.path().join("state"))
+        .output()
+        .expect("failed to run dx");
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("verificações que falharam no CI: black"), "{}", stdout);
+    assert!(stdout.contains("✗ pre-commit: 1 de 2 verificação(ões) falharam: black"), "{}", stdout);
+}
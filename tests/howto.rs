@@ -0,0 +1,39 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::process::Command;
+
+// Test that `dx howto` orders the onboarding steps and prefers the project's own dx.yaml tasks
+#[test]
+fn howto_lists_steps_from_detection() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let project = dir.path().join("loja");
+    fs::create_dir_all(&project).expect("create project");
+    fs::write(project.join("go.mod"), "module example.com/loja\n\ngo 1.22\n\nrequire go.mongodb.org/mongo-driver v1.15.0\n").expect("write go.mod");
+    fs::write(
+        project.join("main.go"),
+        "package main\n\nimport \"os\"\n\nfunc main() {\n\turi := os.Getenv(\"MONGODB_URI\")\n\t_ = uri\n}\n",
+    )
+    .expect("write main.go");
+    fs::write(project.join(".env.example"), "MONGODB_URI=\n").expect("write .env.example");
+    fs::write(project.join("dx.yaml"), "tasks:\n  test:\n    run: go test -race ./...\n").expect("write dx.yaml");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let text = Command::new(exe).arg("howto").arg(&project).output().expect("dx howto");
+    assert!(text.status.success(), "stderr: {}", String::from_utf8_lossy(&text.stderr));
+    let stdout = String::from_utf8_lossy(&text.stdout);
+    let order = ["$ dx dev-doctor", "$ cp .env.example .env", "$ go build -o bin/ ./...", "$ dx dev-services\n", "$ dx dev-services run", "$ dx run test", "$ dx up", "$ dx down"];
+    let positions: Vec<usize> = order.iter().map(|c| stdout.find(c).unwrap_or_else(|| panic!("{:?} missing: {}", c, stdout))).collect();
+    assert!(positions.windows(2).all(|w| w[0] < w[1]), "steps out of order: {}", stdout);
+    assert!(stdout.contains("tarefa test do dx.yaml"), "stdout: {}", stdout);
+
+    let sh = Command::new(exe).args(["howto", "--format", "sh"]).arg(&project).output().expect("dx howto --format sh");
+    let script = String::from_utf8_lossy(&sh.stdout);
+    assert!(script.starts_with("#!/bin/sh\n"), "script: {}", script);
+    assert!(script.contains("\n# dx down\n") && script.contains("\ndx up\n"), "stop step stays commented: {}", script);
+
+    let json = Command::new(exe).args(["--output", "json", "howto"]).arg(&project).output().expect("dx --output json howto");
+    let value: serde_json::Value = serde_json::from_slice(&json.stdout).expect("invalid JSON");
+    assert_eq!(value["stack"], "Go");
+    assert_eq!(value["steps"][0]["commands"][0], "dx dev-doctor");
+}
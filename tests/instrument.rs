@@ -0,0 +1,60 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::process::Command;
+
+const MAIN_GO: &str = r#"package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+)
+
+func main() {
+	mux := http.NewServeMux()
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = "8080"
+	}
+	log.Fatal(http.ListenAndServe(":"+port, mux))
+}
+"#;
+
+// Test that `dx instrument suggest` proposes a health route on the app's own mux and a config
+// loader, skips what the project already has, and only writes the files with --apply
+#[test]
+fn instrument_suggest_diffs_and_applies() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let project = dir.path();
+    fs::write(project.join("go.mod"), "module example.com/loja\n\ngo 1.22\n\nrequire go.opentelemetry.io/otel v1.28.0\n").expect("write go.mod");
+    fs::write(project.join("main.go"), MAIN_GO).expect("write main.go");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let out = Command::new(exe).args(["instrument", "suggest"]).arg(project).output().expect("dx instrument suggest");
+    assert!(out.status.success(), "stderr: {}", String::from_utf8_lossy(&out.stderr));
+    let patch = String::from_utf8_lossy(&out.stdout);
+    assert!(patch.contains("--- /dev/null\n+++ b/health.go\n"), "patch: {}", patch);
+    assert!(patch.contains("+\tmux.HandleFunc(\"/healthz\", healthz)\n"), "registered on the app's mux: {}", patch);
+    assert!(patch.contains("+\t\tPort: getenv(\"PORT\", \"8080\"),\n"), "patch: {}", patch);
+    assert!(!patch.contains("otel.go"), "OpenTelemetry is already in go.mod: {}", patch);
+    assert!(String::from_utf8_lossy(&out.stderr).contains("otel: nada a sugerir"));
+    assert!(!project.join("health.go").exists(), "suggest alone writes nothing");
+
+    let json = Command::new(exe).args(["--output", "json", "instrument", "suggest", "--only", "health"]).arg(project).output().expect("json");
+    let value: serde_json::Value = serde_json::from_slice(&json.stdout).expect("invalid JSON");
+    assert_eq!(value["suggestions"].as_array().map(Vec::len), Some(1));
+    assert_eq!(value["suggestions"][0]["files"][0]["path"], "health.go");
+    assert_eq!(value["suggestions"][0]["files"][0]["new_file"], true);
+
+    let applied = Command::new(exe)
+        .args(["instrument", "suggest", "--only", "health", "--apply", "--yes"])
+        .arg(project)
+        .env("DX_STATE_DIR", dir.path().join("state"))
+        .output()
+        .expect("dx instrument suggest --apply");
+    assert!(applied.status.success(), "stderr: {}", String::from_utf8_lossy(&applied.stderr));
+    assert!(fs::read_to_string(project.join("health.go")).expect("health.go").contains("func healthz(w http.ResponseWriter"));
+    assert!(fs::read_to_string(project.join("main.go")).expect("main.go").contains("\tmux.HandleFunc(\"/healthz\", healthz)\n"));
+    assert!(!project.join("config.go").exists(), "--only limits what is applied");
+}
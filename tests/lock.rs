@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Child, Command, Stdio};
+
+fn spawn_dx(dir: &Path, args: &[&str]) -> Child {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .env("DX_CONFIG_DIR", dir.join(".dx-config"))
+        .stdout(Stdio::null())
+        .stderr(Stdio::piped())
+        .spawn()
+        .expect("run dx")
+}
+
+// Test that concurrent dx processes updating the same state file don't lose each other's changes
+#[test]
+fn concurrent_updates_keep_every_change() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let keys: Vec<String> = (0..8).map(|i| format!("chave{}", i)).collect();
+    let children: Vec<Child> = keys.iter().map(|k| spawn_dx(dir.path(), &["dev-config", "add", k, "valor"])).collect();
+    for child in children {
+        assert!(child.wait_with_output().expect("wait dx").status.success());
+    }
+
+    let config: serde_json::Value =
+        serde_json::from_str(&fs::read_to_string(dir.path().join(".dx/config.json")).unwrap()).unwrap();
+    for k in &keys {
+        assert_eq!(config[k], "valor", "{} perdida: {}", k, config);
+    }
+    let audit = fs::read_to_string(dir.path().join(".dx-state/audit.jsonl")).unwrap();
+    assert_eq!(audit.lines().count(), keys.len());
+}
+
+// Test that container operations wait for another dx on the same project and give up after lock_timeout
+#[test]
+fn dev_services_wait_for_the_project_lock() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let lock_path = dir.path().join(".dx/dev-services.lock");
+    fs::create_dir_all(lock_path.parent().unwrap()).unwrap();
+    let held = fs::File::create(&lock_path).unwrap();
+    held.lock().unwrap();
+
+    let child = spawn_dx(dir.path(), &["dev-services", "stop"]);
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-services", "stop"])
+        .current_dir(dir.path())
+        .env("DX_STATE_DIR", dir.path().join(".dx-state"))
+        .env("DX_LOCK_TIMEOUT", "1")
+        .output()
+        .expect("run dx");
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert!(!output.status.success());
+    assert!(stderr.contains("Aguardando outro dx terminar"), "{}", stderr);
+    assert!(stderr.contains("em uso por outro dx"), "{}", stderr);
+
+    // Once released, the waiting process goes on
+    drop(held);
+    let waited = child.wait_with_output().expect("wait dx");
+    assert!(String::from_utf8_lossy(&waited.stderr).contains("Arquivo não encontrado"));
+}
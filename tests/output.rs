@@ -0,0 +1,83 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(state: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .env("DX_STATE_DIR", state.join(".dx-state"))
+        .env_remove("DX_OUTPUT")
+        .output()
+        .expect("failed to run dx")
+}
+
+fn json(output: &Output) -> serde_json::Value {
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    serde_json::from_slice(&output.stdout).unwrap_or_else(|e| panic!("{}: {}", e, String::from_utf8_lossy(&output.stdout)))
+}
+
+// Test that --output json switches commands with --format to JSON, unless --format is given
+#[test]
+fn output_json_selects_the_json_format() {
+    let tmp = tempfile::tempdir().unwrap();
+    let vars = json(&dx(tmp.path(), &["--output", "json", "dev-env", "scan", "test-projects/go"]));
+    assert!(vars.as_array().unwrap().iter().any(|v| v["name"] == "MONGODB_URI"), "{}", vars);
+
+    // The flag is global: it may come after the subcommand
+    let routes = json(&dx(tmp.path(), &["dev-routes", "list", "--output", "json", "test-projects/go"]));
+    assert!(routes.as_array().unwrap().iter().any(|r| r["path"] == "/api/users"), "{}", routes);
+
+    let output = dx(tmp.path(), &["--output", "json", "dev-routes", "list", "--format", "text", "test-projects/go"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("Rotas HTTP registradas em test-projects/go"), "{}", stdout);
+}
+
+// Test the JSON of dev-dependencies list, selected by DX_OUTPUT
+#[test]
+fn output_json_dev_dependencies_list() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("web");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("package.json"), r#"{"name": "web", "devDependencies": {"jest": "^29.7.0", "eslint": "^8.57.0"}}"#).unwrap();
+
+    let list_in = |dir: &Path, output: Option<&str>| {
+        let mut command = Command::new(env!("CARGO_BIN_EXE_dx"));
+        command.args(["dev-dependencies", "list"]).current_dir(dir).env("DX_STATE_DIR", tmp.path().join(".dx-state"));
+        match output {
+            Some(mode) => command.env("DX_OUTPUT", mode),
+            None => command.env_remove("DX_OUTPUT"),
+        };
+        command.output().expect("failed to run dx dev-dependencies list")
+    };
+    let list = json(&list_in(&project, Some("json")));
+    assert_eq!(list["stack"], "node");
+    assert_eq!(list["dependencies"], serde_json::json!([{ "name": "jest", "version": "^29.7.0" }, { "name": "eslint", "version": "^8.57.0" }]));
+
+    let output = list_in(&project, None);
+    assert!(String::from_utf8_lossy(&output.stdout).contains("- jest = ^29.7.0"));
+
+    let empty = tmp.path().join("empty");
+    fs::create_dir_all(&empty).unwrap();
+    let list = json(&list_in(&empty, Some("json")));
+    assert_eq!(list, serde_json::json!({ "stack": "unknown", "dependencies": [] }));
+}
+
+// Test that the analyzer (doctor) reports its projects as JSON, without the text sections
+#[test]
+fn output_json_analyzer() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("api");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join(".env"), "DATABASE_URL=postgres://localhost:5432/app\n").unwrap();
+    fs::write(project.join("requirements.txt"), "psycopg2==2.9.9\n").unwrap();
+
+    let analyzed = json(&dx(tmp.path(), &["--output", "json", "doctor", "--no-save", project.to_str().unwrap()]));
+    let analyzed = analyzed.as_array().unwrap();
+    assert_eq!(analyzed.len(), 1);
+    assert_eq!(analyzed[0]["project"], project.to_str().unwrap());
+    assert!(analyzed[0]["services"].as_array().unwrap().iter().any(|s| s["name"] == "postgres"), "{:?}", analyzed);
+    assert!(analyzed[0]["report"].is_null());
+    assert!(!project.join(".dx/analyzer-report.md").exists());
+}
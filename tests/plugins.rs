@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// A stdio plugin: answers `detect`, `generate` and `command` requests, logging one message first.
+/// `generate evil` asks to write outside the project.
+const STDIO_PLUGIN: &str = r#"#!/bin/sh
+read request
+printf '%s\n' '{"jsonrpc":"2.0","method":"log","params":{"message":"recebido"}}'
+case "$request" in
+  *'"method":"detect"'*)
+    printf '%s\n' '{"jsonrpc":"2.0","id":1,"result":{"services":[{"name":"acme-queue","image":"acme/queue:1.4","ports":[7070],"env":{"QUEUE_MODE":"dev"}},{"name":"redis"}]}}' ;;
+  *'"method":"generate"'*'"evil"'*)
+    printf '%s\n' '{"jsonrpc":"2.0","id":1,"result":{"files":[{"path":"../escape.txt","content":"x"}]}}' ;;
+  *'"method":"generate"'*)
+    printf '%s\n' '{"jsonrpc":"2.0","id":1,"result":{"files":[{"path":"acme/service.yaml","content":"name: billing\n"}],"message":"Registre o serviço no catálogo."}}' ;;
+  *'"method":"command"'*)
+    printf '%s\n' '{"jsonrpc":"2.0","id":1,"result":{"exit_code":4,"text":"acme pronto","json":{"ready":true}}}' ;;
+  *)
+    printf '%s\n' '{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"método desconhecido"}}' ;;
+esac
+"#;
+
+fn write_script(path: &Path, content: &str) {
+    fs::create_dir_all(path.parent().unwrap()).unwrap();
+    fs::write(path, content).unwrap();
+    fs::set_permissions(path, fs::Permissions::from_mode(0o755)).unwrap();
+}
+
+/// dx running in `dir` with private settings and trust store, and `dir/bin` first on PATH
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    let path = format!("{}:{}", dir.join("bin").display(), std::env::var("PATH").unwrap_or_default());
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("PATH", path)
+        .env("DX_CONFIG_DIR", dir.join(".dx-config"))
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .env_remove("DX_OUTPUT")
+        .output()
+        .expect("failed to run dx")
+}
+
+// Test that dx-<name> executables on PATH run as dx <name>, with the arguments, the dx context and their exit code
+#[test]
+fn path_plugin_runs_as_subcommand() {
+    let tmp = tempfile::tempdir().unwrap();
+    write_script(&tmp.path().join("bin/dx-hello"), "#!/bin/sh\necho \"hello $* from $DX_PLUGIN_NAME in $(basename \"$DX_PROJECT_DIR\")\"\nexit 3\n");
+    write_script(&tmp.path().join("bin/dx-Bad"), "#!/bin/sh\n");
+
+    let output = dx(tmp.path(), &["hello", "a", "--b"]);
+    assert_eq!(output.status.code(), Some(3), "{}", String::from_utf8_lossy(&output.stderr));
+    let expected = format!("hello a --b from hello in {}", tmp.path().file_name().unwrap().to_string_lossy());
+    assert_eq!(String::from_utf8_lossy(&output.stdout).trim(), expected);
+
+    let output = dx(tmp.path(), &["plugins"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("Plugins (1):"), "{}", stdout);
+    assert!(stdout.contains("hello") && stdout.contains("exec") && stdout.contains("PATH:"), "{}", stdout);
+
+    let output = dx(tmp.path(), &["nope"]);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("dx plugins"));
+}
+
+// Test a user stdio plugin: command, detector merged into the analyzer and generator writing inside the project only
+#[test]
+fn stdio_plugin_commands_detectors_and_generators() {
+    let tmp = tempfile::tempdir().unwrap();
+    let plugins = tmp.path().join(".dx-config/plugins");
+    write_script(&plugins.join("bin/acme"), STDIO_PLUGIN);
+    fs::write(
+        plugins.join("acme.yaml"),
+        "description: Infra da Acme\ncommand: bin/acme\nprotocol: stdio\ncapabilities: [command, detect, generate]\n",
+    )
+    .unwrap();
+    let project = tmp.path().join("api");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/api\n\ngo 1.21\n").unwrap();
+
+    let output = dx(tmp.path(), &["acme", "status"]);
+    assert_eq!(output.status.code(), Some(4));
+    assert_eq!(String::from_utf8_lossy(&output.stdout), "acme pronto\n");
+    assert!(String::from_utf8_lossy(&output.stderr).contains("[acme] recebido"));
+    let output = dx(tmp.path(), &["--output", "json", "acme", "status"]);
+    assert_eq!(serde_json::from_slice::<serde_json::Value>(&output.stdout).unwrap(), serde_json::json!({ "ready": true }));
+
+    let output = dx(tmp.path(), &["--output", "json", "plugins"]);
+    let listed: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    assert_eq!(listed[0]["name"], "acme");
+    assert_eq!(listed[0]["source"], "user");
+    assert_eq!(listed[0]["protocol"], "stdio");
+    assert_eq!(listed[0]["capabilities"], serde_json::json!(["command", "detect", "generate"]));
+
+    let output = dx(tmp.path(), &["--output", "json", "doctor", "--no-save", "api"]);
+    let analyzed: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    let services = analyzed[0]["services"].as_array().unwrap();
+    let queue = services.iter().find(|s| s["name"] == "acme-queue").unwrap_or_else(|| panic!("{:?}", services));
+    assert_eq!(queue["image"], "acme/queue:1.4");
+    assert_eq!(queue["ports"], serde_json::json!([7070]));
+    assert!(services.iter().any(|s| s["name"] == "redis" && s["image"].as_str().unwrap().starts_with("redis")), "{:?}", services);
+
+    let output = dx(tmp.path(), &["generate", "plugin", "acme", "--dry-run", "api", "--", "billing"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(String::from_utf8_lossy(&output.stdout).contains("+ acme/service.yaml"));
+    assert!(!project.join("acme/service.yaml").exists());
+    let output = dx(tmp.path(), &["generate", "plugin", "acme", "api", "--", "billing"]);
+    assert!(output.status.success());
+    assert!(String::from_utf8_lossy(&output.stdout).contains("Registre o serviço no catálogo."));
+    assert_eq!(fs::read_to_string(project.join("acme/service.yaml")).unwrap(), "name: billing\n");
+    let output = dx(tmp.path(), &["generate", "plugin", "acme", "api"]);
+    assert!(String::from_utf8_lossy(&output.stdout).contains("= acme/service.yaml (sem mudanças)"));
+
+    let output = dx(tmp.path(), &["generate", "plugin", "acme", "api", "--", "evil"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("fora do projeto"));
+    assert!(!tmp.path().join("escape.txt").exists());
+}
+
+// Test that plugins of dx.yaml run only once the project is trusted, and that grpc is reported as unsupported
+#[test]
+fn project_plugins_require_trust() {
+    let tmp = tempfile::tempdir().unwrap();
+    write_script(&tmp.path().join("tools/lint"), "#!/bin/sh\necho \"lint $*\"\n");
+    fs::write(
+        tmp.path().join("dx.yaml"),
+        "plugins:\n  lint:\n    command: tools/lint\n    args: [--strict]\n  remote:\n    command: tools/lint\n    protocol: grpc\n",
+    )
+    .unwrap();
+
+    let blocked = dx(tmp.path(), &["lint", "src"]);
+    assert_eq!(blocked.status.code(), Some(2));
+    assert!(blocked.stdout.is_empty());
+    assert!(String::from_utf8_lossy(&blocked.stderr).contains("dx allow"));
+
+    assert!(dx(tmp.path(), &["allow"]).status.success());
+    let output = dx(tmp.path(), &["lint", "src"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert_eq!(String::from_utf8_lossy(&output.stdout).trim(), "lint --strict src");
+
+    let output = dx(tmp.path(), &["remote"]);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("grpc ainda não é suportado"));
+}
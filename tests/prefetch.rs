@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::io::{BufRead, BufReader, Read, Write};
+use std::net::TcpListener;
+use std::path::Path;
+use std::process::{Command, Output};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::Arc;
+
+/// Maven repository and OSV API in one server, counting the requests it answers.
+fn registries_mock(requests: Arc<AtomicUsize>) -> String {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut request_line = String::new();
+            reader.read_line(&mut request_line).unwrap();
+            let mut length = 0;
+            loop {
+                let mut header = String::new();
+                reader.read_line(&mut header).unwrap();
+                if header.trim().is_empty() {
+                    break;
+                }
+                if let Some(v) = header.to_lowercase().strip_prefix("content-length:") {
+                    length = v.trim().parse().unwrap();
+                }
+            }
+            let mut body = vec![0; length];
+            reader.read_exact(&mut body).unwrap();
+            requests.fetch_add(1, Ordering::SeqCst);
+            let path = request_line.split_whitespace().nth(1).unwrap_or("");
+            let (status, body) = match path {
+                "/maven2/junit/junit/maven-metadata.xml" => ("200 OK", "<metadata><versioning><latest>4.13.2</latest></versioning></metadata>"),
+                "/v1/querybatch" => ("200 OK", r#"{"results":[{"vulns":[{"id":"GHSA-269g-pwp5-87pp"}]}]}"#),
+                "/v1/vulns/GHSA-269g-pwp5-87pp" => ("200 OK", r#"{"id":"GHSA-269g-pwp5-87pp","summary":"TemporaryFolder on unix-like systems does not limit access to created files"}"#),
+                _ => ("404 Not Found", ""),
+            };
+            let mut stream = stream;
+            let _ = write!(stream, "HTTP/1.1 {}\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}", status, body.len(), body);
+        }
+    });
+    format!("http://{}", addr)
+}
+
+fn dx(dir: &Path, mock: &str, args: &[&str], env: &[(&str, &str)]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .envs(env.iter().copied())
+        .arg(dir)
+        .env("DX_MAVEN_REPOSITORY", format!("{}/maven2", mock))
+        .env("DX_OSV_URL", mock)
+        .env("DX_CACHE_DIR", dir.join(".cache"))
+        .env("DX_STATE_DIR", dir.join(".state"))
+        .output()
+        .expect("run dx")
+}
+
+// Test that a prefetch lets audit and the latest versions answer offline, without a request
+#[test]
+fn prefetch_then_offline() {
+    let tmp = tempfile::tempdir().unwrap();
+    let dir = tmp.path();
+    fs::write(
+        dir.join("pom.xml"),
+        r#"<project>
+  <groupId>com.example</groupId>
+  <artifactId>app</artifactId>
+  <version>1.0.0</version>
+  <dependencies>
+    <dependency><groupId>junit</groupId><artifactId>junit</artifactId><version>4.12</version><scope>test</scope></dependency>
+  </dependencies>
+</project>"#,
+    )
+    .unwrap();
+    let requests = Arc::new(AtomicUsize::new(0));
+    let mock = registries_mock(requests.clone());
+
+    let output = dx(dir, &mock, &["--output", "json", "cache", "prefetch"], &[]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let rows: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(rows[0]["dependencies"], 1, "{}", rows);
+    assert_eq!(rows[0]["packages"], 1, "{}", rows);
+    assert_eq!(rows[0]["advisories"], 1, "{}", rows);
+    let warmed = requests.load(Ordering::SeqCst);
+    assert!(warmed >= 3, "{} requests", warmed);
+
+    // Responses past registry_cache_ttl still answer offline
+    let offline = [("DX_OFFLINE", "true"), ("DX_REGISTRY_CACHE_TTL", "0")];
+    let output = dx(dir, &mock, &["dev-dependencies", "audit", "--fail-on", "critical"], &offline);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("GHSA-269g-pwp5-87pp"), "{}", stdout);
+
+    let output = dx(dir, &mock, &["analyzer", "--no-cache"], &offline);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let report = fs::read_to_string(dir.join(".dx").join("analyzer-report.md")).unwrap();
+    assert!(report.contains("| 4.12 | 4.13.2 |"), "{}", report);
+    assert_eq!(requests.load(Ordering::SeqCst), warmed, "offline runs must not reach the registries");
+}
@@ -0,0 +1,176 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// dx with fake docker, kind and kubectl first on PATH (logging their arguments to `calls.log` and
+/// what kubectl applies to `applied.yaml`; kubectl lists the namespaces in `namespaces.json`), a
+/// private state and `env` set.
+fn dx(root: &Path, args: &[&str], env: &[(&str, &str)]) -> Output {
+    let bin = root.join("bin");
+    fs::create_dir_all(&bin).unwrap();
+    let log = root.join("calls.log");
+    let clusters = root.join("clusters");
+    let scripts = [
+        ("docker", format!("#!/bin/sh\necho \"docker $*\" >> {}\ncat > /dev/null\n", log.display())),
+        (
+            "kind",
+            format!(
+                "#!/bin/sh\necho \"kind $*\" >> {log}\ncase \"$1 $2\" in\n  'get clusters') cat {clusters} 2>/dev/null ;;\n  'create cluster') cat > /dev/null; echo \"$4\" >> {clusters} ;;\nesac\n",
+                log = log.display(),
+                clusters = clusters.display()
+            ),
+        ),
+        (
+            "kubectl",
+            format!(
+                "#!/bin/sh\necho \"kubectl $*\" >> {log}\ncase \"$*\" in\n  *metadata.uid*) echo 6f1c2d3e-0000-4000-8000-000000000001 ;;\n  *'get namespaces'*) cat {namespaces} 2>/dev/null || echo '{{\"items\": []}}' ;;\n  *'get namespace'*) exit 1 ;;\n  *apply*) cat >> {applied}; echo --- >> {applied} ;;\nesac\n",
+                log = log.display(),
+                namespaces = root.join("namespaces.json").display(),
+                applied = root.join("applied.yaml").display()
+            ),
+        ),
+    ];
+    for (name, script) in scripts {
+        fs::write(bin.join(name), script).unwrap();
+        fs::set_permissions(bin.join(name), fs::Permissions::from_mode(0o755)).unwrap();
+    }
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(root)
+        .env("PATH", format!("{}:/usr/bin:/bin", bin.display()))
+        .env("DX_STATE_DIR", root.join("state"))
+        .env("DX_CACHE_DIR", root.join("cache"))
+        .envs(env.iter().copied())
+        .output()
+        .expect("failed to run dx")
+}
+
+/// Node service on port 3000 using Postgres, without a Dockerfile.
+fn node_project(project: &Path) {
+    fs::create_dir_all(project).unwrap();
+    fs::write(project.join("package.json"), r#"{"name": "orders", "dependencies": {"express": "^4.19.0", "pg": "^8.11.0"}}"#).unwrap();
+    fs::write(
+        project.join("index.js"),
+        "const app = require('express')();\n\
+         const db = process.env.DATABASE_URL || 'postgres://localhost:5432/orders';\n\
+         app.get('/healthz', (req, res) => res.send('ok'));\n\
+         app.listen(process.env.PORT || 3000);\n",
+    )
+    .unwrap();
+}
+
+// Test that --dry-run prints the namespace, the Postgres service and the application exposed on a
+// kind node port, and that a remote cluster needs a registry and gets an Ingress on preview_domain
+#[test]
+fn preview_dry_run_manifests() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("orders-api");
+    node_project(&project);
+    let dir = project.to_string_lossy().into_owned();
+
+    let output = dx(tmp.path(), &["preview", "create", "--dry-run", &dir], &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.starts_with("apiVersion: v1\nkind: Namespace\nmetadata:\n  name: preview-orders-api\n"), "{}", stdout);
+    assert!(stdout.contains("    app.kubernetes.io/managed-by: dx\n    dx.dev/preview: orders-api\n"), "{}", stdout);
+    assert!(stdout.contains("    dx.dev/expires-at: \""), "{}", stdout);
+    assert!(stdout.contains("        - name: postgres\n          image: postgres:16-alpine\n"), "{}", stdout);
+    assert!(stdout.contains("    - name: port-5432\n      port: 5432\n"), "{}", stdout);
+    assert!(stdout.contains("image: orders-api:preview-"), "{}", stdout);
+    assert!(stdout.contains("  type: NodePort\n") && stdout.contains("nodePort: 30100\n"), "{}", stdout);
+    assert!(!stdout.contains("kind: Ingress"), "{}", stdout);
+    assert!(stdout.contains("kind: CronJob\n") && stdout.contains("          serviceAccountName: dx-preview-janitor\n"), "{}", stdout);
+    assert!(stdout.contains("kind: ClusterRoleBinding\nmetadata:\n  name: dx-preview-janitor-preview-orders-api\n"), "{}", stdout);
+    assert!(stdout.contains("    resourceNames: [preview-orders-api]\n    verbs: [get, delete]\n"), "{}", stdout);
+    assert!(!tmp.path().join("calls.log").exists(), "dry run should not run docker, kind or kubectl");
+
+    let output = dx(tmp.path(), &["preview", "create", "--target", "cluster", "--dry-run", &dir], &[]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("preview_registry"));
+
+    let env = [("DX_PREVIEW_REGISTRY", "ghcr.io/acme"), ("DX_PREVIEW_DOMAIN", "preview.example.com")];
+    let output = dx(tmp.path(), &["preview", "create", "--target", "cluster", "--dry-run", &dir], &env);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("image: ghcr.io/acme/orders-api:preview-"), "{}", stdout);
+    assert!(stdout.contains("kind: Ingress\n") && stdout.contains("  - host: orders-api.preview.example.com\n"), "{}", stdout);
+    assert!(!stdout.contains("NodePort"), "{}", stdout);
+
+    assert_eq!(dx(tmp.path(), &["preview", "create", "--ttl", "soon", "--dry-run", &dir], &[]).status.code(), Some(2));
+}
+
+// Test that create builds the image, creates the kind cluster, applies the manifests and tracks the
+// preview, and that prune deletes the namespaces of the expired previews only
+#[test]
+fn preview_create_list_and_prune() {
+    let tmp = tempfile::tempdir().unwrap();
+    let root = tmp.path();
+    let project = root.join("orders-api");
+    node_project(&project);
+    let dir = project.to_string_lossy().into_owned();
+
+    let output = dx(root, &["preview", "create", "--ttl", "2h", &dir], &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ Preview orders-api no ar: http://localhost:30100 (expira em 2 h)."), "{}", stdout);
+    let calls = fs::read_to_string(root.join("calls.log")).unwrap();
+    assert!(calls.contains("kind create cluster --name dx-preview --config -"), "{}", calls);
+    assert!(calls.contains("docker build -t orders-api:preview-") && calls.contains(" -f - "), "{}", calls);
+    assert!(calls.contains("kind load docker-image orders-api:preview-"), "{}", calls);
+    assert!(calls.contains("kubectl --context kind-dx-preview apply -n preview-orders-api -f -"), "{}", calls);
+    assert!(calls.contains("rollout status -n preview-orders-api deployment/postgres"), "{}", calls);
+    let applied = fs::read_to_string(root.join("applied.yaml")).unwrap();
+    assert!(applied.contains("kind: Namespace") && applied.contains("kind: Deployment"), "{}", applied);
+    // The janitor's access to the namespace is owned by it, so it goes away with the namespace
+    assert!(applied.contains("kind: ClusterRole\n") && applied.contains("      kind: Namespace\n      name: preview-orders-api\n      uid: 6f1c2d3e-"), "{}", applied);
+    assert!(applied.contains("kind: CronJob\n") && applied.contains("image: bitnami/kubectl:latest\n"), "{}", applied);
+
+    // A second preview, already expired
+    let state = root.join("state/previews.json");
+    let mut previews: serde_json::Value = serde_json::from_str(&fs::read_to_string(&state).unwrap()).unwrap();
+    let mut old = previews["orders-api"].clone();
+    old["namespace"] = "preview-old".into();
+    old["node_port"] = 30101.into();
+    old["expires"] = 1.into();
+    previews["old"] = old;
+    fs::write(&state, previews.to_string()).unwrap();
+
+    let output = dx(root, &["--output", "json", "preview", "list"], &[]);
+    let list: serde_json::Value = serde_json::from_slice(&output.stdout).expect("invalid JSON");
+    assert_eq!(list.as_array().unwrap().len(), 2, "{}", list);
+    assert_eq!(list[1]["name"], "orders-api");
+    assert_eq!(list[1]["url"], "http://localhost:30100");
+    assert_eq!(list[1]["expired"], false);
+    assert_eq!(list[0]["expired"], true);
+
+    let output = dx(root, &["preview", "prune"], &[]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let calls = fs::read_to_string(root.join("calls.log")).unwrap();
+    assert!(calls.contains("kubectl --context kind-dx-preview delete namespace preview-old"), "{}", calls);
+    assert!(!calls.contains("delete namespace preview-orders-api"), "{}", calls);
+
+    // Previews created elsewhere are found in the cluster by label and expiry annotation
+    let namespace = |name: &str, expires: &str, deleting: bool| {
+        let mut meta = serde_json::json!({"name": name, "labels": {"app.kubernetes.io/managed-by": "dx", "dx.dev/preview": name}, "annotations": {"dx.dev/expires-at": expires}});
+        if deleting {
+            meta["deletionTimestamp"] = "2025-01-01T00:00:00Z".into();
+        }
+        serde_json::json!({"metadata": meta})
+    };
+    let items = [namespace("preview-ci-old", "2025-01-31T12:00:00Z", false), namespace("preview-ci-new", "2999-01-01T00:00:00Z", false), namespace("preview-ci-gone", "2025-01-01T00:00:00Z", true)];
+    fs::write(root.join("namespaces.json"), serde_json::json!({"items": items}).to_string()).unwrap();
+    let output = dx(root, &["preview", "prune", "--context", "ci"], &[]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let calls = fs::read_to_string(root.join("calls.log")).unwrap();
+    assert!(calls.contains("kubectl --context ci get namespaces -l app.kubernetes.io/managed-by=dx,dx.dev/preview -o json"), "{}", calls);
+    assert!(calls.contains("kubectl --context ci delete namespace preview-ci-old"), "{}", calls);
+    assert!(!calls.contains("delete namespace preview-ci-new") && !calls.contains("delete namespace preview-ci-gone"), "{}", calls);
+
+    assert!(dx(root, &["preview", "delete", "preview-orders-api"], &[]).status.success());
+    let output = dx(root, &["--output", "json", "preview", "list"], &[]);
+    assert_eq!(String::from_utf8_lossy(&output.stdout).trim(), "[]");
+}
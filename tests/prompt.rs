@@ -0,0 +1,30 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::process::Command;
+
+// Test that `dx prompt` finds the project root from a subdirectory and shows the active profiles
+#[test]
+fn prompt_reports_project_and_profiles() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let project = dir.path().join("loja");
+    fs::create_dir_all(project.join(".dx")).expect("create .dx");
+    fs::create_dir_all(project.join("src/api")).expect("create subdir");
+    fs::write(project.join("dx.yaml"), "tasks: {}\n").expect("write dx.yaml");
+    fs::write(project.join(".dx/dev-services-state.json"), r#"{"profiles":["debug"]}"#).expect("write state");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let text = Command::new(exe).arg("prompt").arg(project.join("src/api")).output().expect("dx prompt");
+    assert!(text.status.success());
+    assert_eq!(String::from_utf8_lossy(&text.stdout).trim(), "loja (debug)");
+
+    let json = Command::new(exe)
+        .args(["prompt", "--format", "json"])
+        .arg(project.join("src/api"))
+        .output()
+        .expect("dx prompt --format json");
+    let value: serde_json::Value = serde_json::from_slice(&json.stdout).expect("invalid JSON");
+    assert_eq!(value["project"], "loja");
+    assert_eq!(value["profiles"][0], "debug");
+    assert!(value.get("services_up").is_none(), "no compose file, no service count: {}", value);
+}
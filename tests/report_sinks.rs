@@ -0,0 +1,162 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use sha2::{Digest, Sha256};
+use std::collections::BTreeMap;
+use std::fs;
+use std::io::{BufRead, BufReader, Read, Write};
+use std::net::TcpListener;
+use std::path::Path;
+use std::process::{Command, Output};
+use std::sync::{Arc, Mutex};
+
+/// A request received by the collector: method, path, lower-case headers and body.
+type Received = (String, String, BTreeMap<String, String>, Vec<u8>);
+
+/// HTTP server that accepts every request (HTTP endpoint, S3 and GCS uploads alike) and keeps it.
+fn collector(received: Arc<Mutex<Vec<Received>>>) -> String {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut request_line = String::new();
+            reader.read_line(&mut request_line).unwrap();
+            let mut headers = BTreeMap::new();
+            loop {
+                let mut header = String::new();
+                reader.read_line(&mut header).unwrap();
+                if header.trim().is_empty() {
+                    break;
+                }
+                if let Some((name, value)) = header.split_once(':') {
+                    headers.insert(name.trim().to_lowercase(), value.trim().to_string());
+                }
+            }
+            let mut body = vec![0; headers.get("content-length").map_or(0, |l| l.parse().unwrap())];
+            reader.read_exact(&mut body).unwrap();
+            let mut parts = request_line.split_whitespace();
+            let (method, path) = (parts.next().unwrap().to_string(), parts.next().unwrap().to_string());
+            received.lock().unwrap().push((method, path, headers, body));
+            let mut stream = stream;
+            let _ = write!(stream, "HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\n{{}}");
+        }
+    });
+    format!("127.0.0.1:{}", addr.port())
+}
+
+fn analyzer(dir: &Path, args: &[&str], env: &[(&str, &str)]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .args(["analyzer", "--no-save"])
+        .arg(dir)
+        .envs(env.iter().copied())
+        .env_remove("DX_REPORT_SINKS")
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .output()
+        .expect("failed to run dx analyzer")
+}
+
+// Test that the analyzer report reaches an HTTP endpoint, an S3-compatible bucket and a GCS emulator
+#[test]
+fn report_sinks_http_s3_gcs() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("shop");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/shop\n\ngo 1.22\n").unwrap();
+    let received = Arc::new(Mutex::new(Vec::new()));
+    let host = collector(received.clone());
+
+    // HTTP: the report in a JSON envelope, with the bearer token
+    let sink = format!("http://{}/reports", host);
+    let output = analyzer(&project, &["--sink", &sink], &[("DX_SINK_TOKEN", "t0k3n")]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(String::from_utf8_lossy(&output.stderr).contains(&format!("Relatório analyzer enviado para {}", sink)));
+    {
+        let received = received.lock().unwrap();
+        let (method, path, headers, body) = &received[0];
+        assert_eq!((method.as_str(), path.as_str()), ("POST", "/reports"));
+        assert_eq!(headers["authorization"], "Bearer t0k3n");
+        let doc: serde_json::Value = serde_json::from_slice(body).unwrap();
+        assert_eq!(doc["kind"], "analyzer");
+        assert_eq!(doc["project"], "shop");
+        assert!(doc["markdown"].as_str().unwrap().starts_with("# dx-cli _analyzer_"), "{}", doc);
+    }
+
+    // S3 (path-style on a custom endpoint), signed with AWS Signature Version 4
+    let endpoint = format!("http://{}", host);
+    let env = [
+        ("AWS_ENDPOINT_URL", endpoint.as_str()),
+        ("AWS_ACCESS_KEY_ID", "AKIDEXAMPLE"),
+        ("AWS_SECRET_ACCESS_KEY", "secret"),
+        ("AWS_REGION", "sa-east-1"),
+    ];
+    let output = analyzer(&project, &["--sink", "s3://dx-reports/nightly"], &env);
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert!(stderr.contains("Relatório analyzer enviado para s3://dx-reports/nightly/shop/analyzer/"), "{}", stderr);
+    {
+        let received = received.lock().unwrap();
+        let (method, path, headers, body) = &received[1];
+        assert_eq!(method, "PUT");
+        assert!(path.starts_with("/dx-reports/nightly/shop/analyzer/") && path.ends_with("Z.md"), "{}", path);
+        let auth = &headers["authorization"];
+        assert!(auth.starts_with("AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/"), "{}", auth);
+        assert!(auth.contains("/sa-east-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="), "{}", auth);
+        let hash: String = Sha256::digest(body).iter().map(|b| format!("{:02x}", b)).collect();
+        assert_eq!(headers["x-amz-content-sha256"], hash);
+    }
+
+    // GCS through the emulator; sinks also come from DX_REPORT_SINKS
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["analyzer", "--no-save"])
+        .arg(&project)
+        .env("DX_REPORT_SINKS", "gs://dx-reports")
+        .env("STORAGE_EMULATOR_HOST", &host)
+        .env("DX_STATE_DIR", project.join(".dx-state"))
+        .output()
+        .unwrap();
+    assert!(output.status.success());
+    {
+        let received = received.lock().unwrap();
+        let (method, path, headers, _) = &received[2];
+        assert_eq!(method, "POST");
+        assert!(path.starts_with("/upload/storage/v1/b/dx-reports/o?uploadType=media&name=shop%2Fanalyzer%2F"), "{}", path);
+        assert!(headers["content-type"].starts_with("text/markdown"));
+    }
+
+    // A sink that fails is a warning; an unknown scheme is an error
+    let closed = TcpListener::bind("127.0.0.1:0").unwrap().local_addr().unwrap().port();
+    let output = analyzer(&project, &["--sink", &format!("http://127.0.0.1:{}/", closed)], &[]);
+    assert!(output.status.success());
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Aviso: falha ao enviar o relatório analyzer"));
+    let output = analyzer(&project, &["--sink", "ftp://reports"], &[]);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("destino não suportado: ftp://reports"));
+}
+
+// Test that a sink named by the project's dx.yaml is ignored, so a cloned repository cannot collect
+// the reports or the sink token
+#[test]
+fn report_sinks_ignore_project_dx_yaml() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("shop");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/shop\n\ngo 1.22\n").unwrap();
+    let received = Arc::new(Mutex::new(Vec::new()));
+    let host = collector(received.clone());
+    fs::write(project.join("dx.yaml"), format!("settings:\n  report_sinks: http://{}/stolen\n", host)).unwrap();
+
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["analyzer", "--no-save", "."])
+        .current_dir(&project)
+        .env("DX_SINK_TOKEN", "t0k3n")
+        .env_remove("DX_REPORT_SINKS")
+        .env("DX_STATE_DIR", project.join(".dx-state"))
+        .env("DX_CONFIG_DIR", project.join(".dx-config"))
+        .output()
+        .expect("failed to run dx analyzer");
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert!(output.status.success(), "{}", stderr);
+    assert!(stderr.contains("Aviso: report_sinks de projeto (dx.yaml) ignorado"), "{}", stderr);
+    assert!(!stderr.contains("enviado para"), "{}", stderr);
+    assert!(received.lock().unwrap().is_empty(), "the project sink received a request");
+}
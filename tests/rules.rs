@@ -0,0 +1,95 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::process::Command;
+
+// Test that `dx rules update --from` installs a newer pack, that it becomes the active one and that
+// `dx rules pin` records it in the project's dx.lock
+#[test]
+fn rules_update_and_pin() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let state = dir.path().join("state");
+    let project = dir.path().join("app");
+    fs::create_dir_all(&project).expect("create project");
+    let mut pack: serde_json::Value = serde_json::from_str(include_str!("../rules/detection.json")).expect("embedded pack");
+    pack["version"] = "2099.1.0".into();
+    pack["services"]
+        .as_array_mut()
+        .unwrap()
+        .push(serde_json::json!({"service": "nats", "keywords": ["nats.go"], "image": "nats:2", "ports": [4222]}));
+    let pack_path = dir.path().join("detection.json");
+    fs::write(&pack_path, pack.to_string()).expect("write pack");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let dx = |args: &[&str]| {
+        Command::new(exe)
+            .args(args)
+            .arg(&project)
+            .env("DX_STATE_DIR", &state)
+            .env("DX_CACHE_DIR", dir.path().join("cache"))
+            .env("DX_CONFIG_DIR", dir.path().join("config"))
+            .output()
+            .expect("run dx")
+    };
+
+    let update = dx(&["rules", "update", "--from", pack_path.to_str().unwrap()]);
+    assert!(update.status.success(), "{}", String::from_utf8_lossy(&update.stderr));
+    assert!(state.join("rules/2099.1.0.json").exists());
+
+    let list = dx(&["--output", "json", "rules", "list"]);
+    let value: serde_json::Value = serde_json::from_slice(&list.stdout).expect("invalid JSON");
+    assert_eq!(value["active"], "2099.1.0");
+    assert_eq!(value["packs"].as_array().unwrap().len(), 2);
+
+    let pin = dx(&["rules", "pin"]);
+    assert!(pin.status.success(), "{}", String::from_utf8_lossy(&pin.stderr));
+    let lock = fs::read_to_string(project.join("dx.lock")).expect("dx.lock");
+    assert!(lock.contains("2099.1.0"), "{}", lock);
+
+    let list = dx(&["--output", "json", "rules", "list"]);
+    let value: serde_json::Value = serde_json::from_slice(&list.stdout).expect("invalid JSON");
+    assert_eq!(value["pinned"], "2099.1.0");
+}
+
+// Test that a dx.lock pin that cannot be honored (pack not installed, or installed with another
+// sha256) stops the analysis instead of falling back to the embedded rules
+#[test]
+fn rules_pin_not_honored_fails() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    let state = dir.path().join("state");
+    let project = dir.path().join("app");
+    fs::create_dir_all(&project).expect("create project");
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let dx = |args: &[&str]| {
+        Command::new(exe)
+            .args(args)
+            .arg(&project)
+            .env("DX_STATE_DIR", &state)
+            .env("DX_CACHE_DIR", dir.path().join("cache"))
+            .env("DX_CONFIG_DIR", dir.path().join("config"))
+            .env("DX_OFFLINE", "true")
+            .output()
+            .expect("run dx")
+    };
+
+    fs::write(project.join("go.mod"), "module example.com/app\n\ngo 1.22\n").unwrap();
+    fs::write(project.join("dx.lock"), format!("rules:\n  version: 2099.3.0\n  sha256: {}\n", "0".repeat(64))).unwrap();
+    let list = dx(&["rules", "list"]);
+    assert_eq!(list.status.code(), Some(1));
+    let stderr = String::from_utf8_lossy(&list.stderr);
+    assert!(stderr.contains("fixa as regras 2099.3.0") && stderr.contains("dx rules update --version 2099.3.0"), "{}", stderr);
+    assert_eq!(dx(&["dev-infra", "detect"]).status.code(), Some(1));
+
+    let mut pack: serde_json::Value = serde_json::from_str(include_str!("../rules/detection.json")).expect("embedded pack");
+    pack["version"] = "2099.3.0".into();
+    fs::create_dir_all(state.join("rules")).unwrap();
+    fs::write(state.join("rules/2099.3.0.json"), pack.to_string()).unwrap();
+    assert_eq!(dx(&["rules", "list"]).status.code(), Some(1), "installed pack with another sha256");
+
+    let pin = dx(&["rules", "pin", "--version", "2099.3.0"]);
+    assert!(pin.status.success(), "{}", String::from_utf8_lossy(&pin.stderr));
+    let list = dx(&["--output", "json", "rules", "list"]);
+    assert!(list.status.success(), "{}", String::from_utf8_lossy(&list.stderr));
+    let value: serde_json::Value = serde_json::from_slice(&list.stdout).expect("invalid JSON");
+    assert_eq!(value["active"], "2099.3.0");
+}
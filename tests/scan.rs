@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("DX_CONFIG_DIR", dir.join(".dx-config"))
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .env_remove("DX_OUTPUT")
+        .env_remove("DX_PROGRESS")
+        .output()
+        .expect("failed to run dx")
+}
+
+/// A folder of repositories: two at the top, one package two levels down, and a project inside
+/// node_modules that must be left out.
+fn workspace(root: &Path) {
+    for (dir, file, content) in [
+        ("api", "go.mod", "module example.com/api\n\ngo 1.21\n"),
+        ("web", "package.json", r#"{"name": "web", "devDependencies": {"vitest": "^1.6.0"}}"#),
+        ("packages/ui", "package.json", r#"{"name": "ui", "devDependencies": {"typescript": "5.4.5"}}"#),
+        ("web/node_modules/left-pad", "package.json", r#"{"name": "left-pad"}"#),
+    ] {
+        fs::create_dir_all(root.join(dir)).unwrap();
+        fs::write(root.join(dir).join(file), content).unwrap();
+    }
+}
+
+// Test that dev-dependencies list reads every project of a folder of repositories, in path order
+#[test]
+fn dev_dependencies_list_scans_each_project() {
+    let tmp = tempfile::tempdir().unwrap();
+    workspace(tmp.path());
+
+    let output = dx(tmp.path(), &["--output", "json", "dev-dependencies", "list", "--concurrency", "2", "."]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let lists: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    let projects: Vec<&str> = lists.as_array().unwrap().iter().map(|l| l["project"].as_str().unwrap()).collect();
+    assert_eq!(projects, ["api", "packages/ui", "web"]);
+    assert_eq!(lists[1]["stack"], "node");
+    assert_eq!(lists[1]["dependencies"], serde_json::json!([{"name": "typescript", "version": "5.4.5"}]));
+
+    let output = dx(tmp.path(), &["dev-dependencies", "list"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("Dependências de 3 projetos"), "{}", stdout);
+    assert!(stdout.contains("web (node)\n  - vitest = ^1.6.0"), "{}", stdout);
+}
+
+// Test that the analyzer scans the projects in parallel, reporting a progress step per project
+#[test]
+fn analyzer_scans_projects_in_parallel_with_progress() {
+    let tmp = tempfile::tempdir().unwrap();
+    workspace(tmp.path());
+    // Without dependencies, so the reports do not ask the registries about them
+    for dir in ["web", "packages/ui"] {
+        fs::write(tmp.path().join(dir).join("package.json"), r#"{"name": "app"}"#).unwrap();
+    }
+
+    let output = dx(tmp.path(), &["--output", "json", "--progress", "json", "analyzer", "--no-save", "--concurrency", "3", "."]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let analyzed: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    let projects: Vec<String> = analyzed.as_array().unwrap().iter().map(|a| a["project"].as_str().unwrap().to_string()).collect();
+    assert_eq!(projects.len(), 3, "{:?}", projects);
+    assert!(projects[1].ends_with("ui"), "{:?}", projects);
+
+    let steps: Vec<serde_json::Value> = String::from_utf8_lossy(&output.stderr)
+        .lines()
+        .filter_map(|l| serde_json::from_str::<serde_json::Value>(l).ok())
+        .filter(|e| e["event"] == "progress" && e["phase"] == "analyzer")
+        .collect();
+    assert_eq!(steps.len(), 3, "{:?}", steps);
+    assert_eq!(steps[2]["current"], 3);
+    assert_eq!(steps[2]["total"], 3);
+}
@@ -0,0 +1,137 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-config", "tasks"])
+        .args(args)
+        .arg(dir)
+        .env("DX_STATE_DIR", dir.parent().unwrap().join("state"))
+        .output()
+        .expect("failed to run dx dev-config tasks")
+}
+
+// Test that a Go module gets a Makefile with the native commands and a project compose file is used for compose-up
+#[test]
+fn tasks_go_makefile() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("shop");
+    fs::create_dir_all(project.join("cmd/server")).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/shop\n\ngo 1.22\n").unwrap();
+    fs::write(project.join("cmd/server/main.go"), "package main\n\nfunc main() {}\n").unwrap();
+    fs::write(project.join(".golangci.yml"), "linters:\n  enable: [errcheck]\n").unwrap();
+    fs::write(project.join("compose.yaml"), "services:\n  db:\n    image: postgres:16\n").unwrap();
+
+    let output = dx(&project, &[]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ Makefile gerado (Go): build, test, lint, run, compose-up."), "{}", stdout);
+    assert!(stdout.contains("Rode com: make build"), "{}", stdout);
+
+    let makefile = fs::read_to_string(project.join("Makefile")).unwrap();
+    assert!(makefile.starts_with("# Gerado por: dx dev-config tasks\n"), "{}", makefile);
+    assert!(makefile.contains(".PHONY: help build test lint run compose-up\n"), "{}", makefile);
+    assert!(makefile.contains("build: ## Compila os binários em bin/\n\tgo build -o bin/ ./...\n"), "{}", makefile);
+    assert!(makefile.contains("test: ## Roda os testes\n\tgo test ./...\n"), "{}", makefile);
+    assert!(makefile.contains("lint: ## Analisa o código\n\tgolangci-lint run\n"), "{}", makefile);
+    assert!(makefile.contains("run: ## Roda a aplicação\n\tgo run ./cmd/server\n"), "{}", makefile);
+    assert!(makefile.contains("compose-up: ## Sobe os serviços do compose do projeto\n\tdocker compose up -d\n"), "{}", makefile);
+    assert!(makefile.contains("{printf \"  %-12s %s\\n\", $$1, $$2}"), "{}", makefile);
+}
+
+// Test that Node scripts and the lockfile's package manager reach a Taskfile, and a hand-written one needs --force
+#[test]
+fn tasks_node_taskfile_keeps_hand_written_file() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("web");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("package.json"), r#"{"name": "web", "scripts": {"build": "tsc", "lint": "eslint src", "dev": "tsx watch src/index.ts", "test": "vitest run"}}"#).unwrap();
+    fs::write(project.join("pnpm-lock.yaml"), "lockfileVersion: '9.0'\n").unwrap();
+    fs::write(project.join("Taskfile.yml"), "version: '3'\n").unwrap();
+
+    let output = dx(&project, &["--format", "taskfile"]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Taskfile.yml já existe e não foi gerado pelo dx"));
+    assert_eq!(fs::read_to_string(project.join("Taskfile.yml")).unwrap(), "version: '3'\n");
+
+    let output = dx(&project, &["--format", "taskfile", "--force"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Rode com: task build"), "{}", stdout);
+    let taskfile = fs::read_to_string(project.join("Taskfile.yml")).unwrap();
+    assert!(taskfile.starts_with("# Gerado por: dx dev-config tasks\n# Stack: Node.js\nversion: '3'\n"), "{}", taskfile);
+    assert!(taskfile.contains("  build:\n    desc: \"Instala as dependências e compila\"\n    cmds:\n      - \"pnpm install --frozen-lockfile\"\n      - \"pnpm run build\"\n"), "{}", taskfile);
+    assert!(taskfile.contains("      - \"pnpm test\"\n"), "{}", taskfile);
+    assert!(taskfile.contains("      - \"pnpm run lint\"\n"), "{}", taskfile);
+    assert!(taskfile.contains("      - \"pnpm run dev\"\n"), "{}", taskfile);
+    assert!(taskfile.contains("  compose-up:\n") && taskfile.contains("      - \"dx dev-services run\"\n"), "{}", taskfile);
+}
+
+// Test that a FastAPI app gets a justfile with pytest, the declared linter and uvicorn on the detected port
+#[test]
+fn tasks_python_justfile() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("api");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("requirements.txt"), "fastapi\nuvicorn\n").unwrap();
+    fs::write(project.join("requirements-dev.txt"), "pytest\nruff\n").unwrap();
+    fs::write(project.join("main.py"), "import os\nfrom fastapi import FastAPI\n\napp = FastAPI()\nport = int(os.getenv(\"PORT\", \"8081\"))\n").unwrap();
+
+    let output = dx(&project, &["--format", "justfile", "--no-save"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(!project.join("justfile").exists());
+    assert!(stdout.contains("default:\n    @just --list\n"), "{}", stdout);
+    assert!(stdout.contains("build:\n    python -m pip install -r requirements.txt\n    python -m pip install -r requirements-dev.txt\n"), "{}", stdout);
+    assert!(stdout.contains("# Roda os testes\ntest:\n    python -m pytest\n"), "{}", stdout);
+    assert!(stdout.contains("lint:\n    python -m ruff check .\n"), "{}", stdout);
+    assert!(stdout.contains("run:\n    python -m uvicorn main:app --reload --port 8081\n"), "{}", stdout);
+}
+
+// Test that a project without a detected stack is reported
+#[test]
+fn tasks_unknown_stack() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("docs");
+    fs::create_dir_all(&project).unwrap();
+
+    let output = dx(&project, &[]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Nenhuma stack detectada"));
+    assert!(!project.join("Makefile").exists());
+}
+
+// Test that without a terminal --strategy settles a hand-written Makefile: fail (the default off a
+// terminal) stops, ours keeps it and theirs (also from DX_CONFLICT_STRATEGY) replaces it
+#[test]
+fn tasks_conflict_strategy() {
+    let tmp = tempfile::tempdir().unwrap();
+    let project = tmp.path().join("svc");
+    fs::create_dir_all(&project).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/svc\n\ngo 1.22\n").unwrap();
+    fs::write(project.join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+    let hand_written = "build:\n\tgo build -tags prod ./...\n";
+    fs::write(project.join("Makefile"), hand_written).unwrap();
+
+    let output = dx(&project, &[]);
+    assert_eq!(output.status.code(), Some(1));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("--strategy ours para manter"), "{}", String::from_utf8_lossy(&output.stderr));
+
+    let output = dx(&project, &["--strategy", "ours"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Makefile já existe e não foi gerado pelo dx; mantido."));
+    assert_eq!(fs::read_to_string(project.join("Makefile")).unwrap(), hand_written);
+
+    let output = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["dev-config", "tasks"])
+        .arg(&project)
+        .env("DX_STATE_DIR", tmp.path().join("state"))
+        .env("DX_CONFLICT_STRATEGY", "theirs")
+        .output()
+        .expect("failed to run dx dev-config tasks");
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(fs::read_to_string(project.join("Makefile")).unwrap().starts_with("# Gerado por: dx dev-config tasks\n"));
+}
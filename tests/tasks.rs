@@ -0,0 +1,369 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::Command;
+
+/// dx running in `dir` with private settings and trust store (DX_CONFIG_DIR, DX_STATE_DIR)
+fn dx(dir: &Path) -> Command {
+    let mut cmd = Command::new(env!("CARGO_BIN_EXE_dx"));
+    cmd.current_dir(dir)
+        .env("DX_CONFIG_DIR", dir.join(".dx-config"))
+        .env("DX_STATE_DIR", dir.join(".dx-state"));
+    cmd
+}
+
+/// Trust the scripts of dx.yaml in `dir`, as `dx allow` does after a review
+fn allow(dir: &Path) {
+    assert!(dx(dir).arg("allow").status().expect("dx allow").success());
+}
+
+const MAKEFILE: &str = "APP := demo\n\n.PHONY: build test gen\n\n## Compila o projeto\nbuild:\n\t@echo building $(APP)\n\ntest: build ## Roda os testes\n\techo testing\n\ngen:\n\techo $(shell date)\n";
+
+// Test that `dx migrate makefile` converts common targets and keeps the others in the Makefile
+#[cfg(unix)]
+#[test]
+fn migrate_makefile_writes_dx_yaml() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("Makefile"), MAKEFILE).expect("write Makefile");
+
+    let exe = env!("CARGO_BIN_EXE_dx");
+    let output = Command::new(exe)
+        .args(["migrate", "makefile"])
+        .arg(dir.path())
+        .output()
+        .expect("failed to run dx migrate makefile");
+    assert!(output.status.success());
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(stdout.contains("mantido no Makefile"), "gen should stay in the Makefile:\n{}", stdout);
+
+    let yaml = fs::read_to_string(dir.path().join("dx.yaml")).expect("dx.yaml not written");
+    assert!(yaml.contains("echo building demo"), "variables should be expanded:\n{}", yaml);
+    assert!(yaml.contains("depends_on"), "prerequisites should become depends_on:\n{}", yaml);
+    assert!(!yaml.contains("gen:"), "gen uses $(shell) and must not be migrated:\n{}", yaml);
+}
+
+// Test that `dx migrate makefile --verify` runs only the named targets, and only in a trusted project
+#[cfg(unix)]
+#[test]
+fn migrate_makefile_verifies_named_targets() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("Makefile"), "build:\n\techo building >> built.log\n\ndeploy:\n\ttouch deployed\n").unwrap();
+    let migrate = |args: &[&str]| dx(dir.path()).args(["migrate", "makefile"]).args(args).output().expect("dx migrate makefile");
+
+    let output = migrate(&["--verify", "build"]);
+    assert_eq!(output.status.code(), Some(2), "{}", String::from_utf8_lossy(&output.stdout));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("dx allow"));
+    assert!(!dir.path().join("built.log").exists(), "an untrusted Makefile must not run");
+    assert_eq!(migrate(&["--verify", "release"]).status.code(), Some(2));
+
+    allow(dir.path());
+    let output = migrate(&["--verify", "build"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✔ build"), "{}", stdout);
+    assert!(stdout.contains("+ deploy"), "{}", stdout);
+    assert_eq!(fs::read_to_string(dir.path().join("built.log")).unwrap(), "building\nbuilding\n");
+    assert!(!dir.path().join("deployed").exists(), "targets not named in --verify must not run");
+}
+
+// Test that `dx run` lists and executes dx.yaml tasks with their env
+#[cfg(unix)]
+#[test]
+fn run_executes_dx_yaml_task() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(
+        dir.path().join("dx.yaml"),
+        "tasks:\n  hello:\n    description: Diz olá\n    env:\n      GREETING: ola\n    run:\n      - echo $GREETING mundo\n  broken:\n    run: exit 3\n",
+    )
+    .expect("write dx.yaml");
+
+    allow(dir.path());
+    let list = dx(dir.path()).arg("run").output().expect("dx run");
+    assert!(list.status.success());
+    assert!(String::from_utf8_lossy(&list.stdout).contains("Diz olá"));
+
+    let run = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert!(run.status.success());
+    assert_eq!(String::from_utf8_lossy(&run.stdout).trim(), "ola mundo");
+
+    let failed = dx(dir.path()).args(["run", "broken"]).output().expect("dx run broken");
+    assert_eq!(failed.status.code(), Some(3));
+}
+
+// Test that depends_on runs dependencies first, shows the plan with --graph and rejects cycles
+#[cfg(unix)]
+#[test]
+fn run_resolves_task_dependencies() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(
+        dir.path().join("dx.yaml"),
+        r#"tasks:
+  gen:
+    run: echo gen
+  lint:
+    depends_on: [gen]
+    run: echo lint
+  test:
+    depends_on: [gen]
+    run: echo test
+  ci:
+    depends_on: [lint, test]
+    run: echo ci
+  a:
+    depends_on: [b]
+  b:
+    depends_on: [a]
+"#,
+    )
+    .expect("write dx.yaml");
+
+    allow(dir.path());
+    let run = dx(dir.path()).args(["run", "ci"]).output().expect("dx run ci");
+    assert!(run.status.success());
+    let stdout = String::from_utf8_lossy(&run.stdout);
+    let lines: Vec<&str> = stdout.lines().collect();
+    assert_eq!(lines.first(), Some(&"gen"), "gen should run first:\n{}", stdout);
+    assert_eq!(lines.last(), Some(&"ci"), "ci should run last:\n{}", stdout);
+    assert!(lines.contains(&"lint") && lines.contains(&"test"));
+    assert!(String::from_utf8_lossy(&run.stderr).contains("em paralelo: lint, test"));
+
+    let graph = dx(dir.path()).args(["run", "--graph", "ci"]).output().expect("dx run --graph");
+    assert!(graph.status.success());
+    let plan = String::from_utf8_lossy(&graph.stdout);
+    assert!(plan.contains("1. gen") && plan.contains("2. lint, test") && plan.contains("3. ci"), "{}", plan);
+
+    let cycle = dx(dir.path()).args(["run", "a"]).output().expect("dx run a");
+    assert_eq!(cycle.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&cycle.stderr).contains("circular"));
+}
+
+// Test that custom commands from dx.yaml run dx and shell steps with env and failure policies
+#[cfg(unix)]
+#[test]
+fn custom_command_runs_steps_with_policies() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(
+        dir.path().join("dx.yaml"),
+        r#"aliases:
+  hi: run hello
+tasks:
+  hello:
+    run: echo "hello $WHO"
+commands:
+  release:
+    env:
+      STAGE: staging
+    steps:
+      - dx: run hello
+        env:
+          WHO: team
+      - run: exit 4
+        on_failure: continue
+      - run: echo "stage=$STAGE"
+  broken:
+    steps:
+      - run: exit 5
+      - run: echo unreachable
+"#,
+    )
+    .expect("write dx.yaml");
+
+    allow(dir.path());
+    let release = dx(dir.path()).arg("release").output().expect("dx release");
+    assert!(release.status.success());
+    let stdout = String::from_utf8_lossy(&release.stdout);
+    assert!(stdout.contains("hello team"), "dx step should get the step env:\n{}", stdout);
+    assert!(stdout.contains("stage=staging"), "command env should reach every step:\n{}", stdout);
+
+    let alias = dx(dir.path()).arg("hi").output().expect("dx hi");
+    assert!(alias.status.success());
+    assert!(String::from_utf8_lossy(&alias.stdout).contains("hello"));
+
+    let broken = dx(dir.path()).arg("broken").output().expect("dx broken");
+    assert_eq!(broken.status.code(), Some(5));
+    assert!(!String::from_utf8_lossy(&broken.stdout).contains("unreachable"));
+}
+
+// Test that aliases and commands expanding back to themselves stop with the cycle instead of respawning dx
+#[cfg(unix)]
+#[test]
+fn custom_command_cycle_exits_with_chain() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(
+        dir.path().join("dx.yaml"),
+        "aliases:\n  a: b\n  b: a\ncommands:\n  deploy:\n    steps:\n      - dx: ship\n  ship:\n    steps:\n      - dx: deploy\n",
+    )
+    .expect("write dx.yaml");
+
+    let output = dx(dir.path()).arg("a").output().expect("dx a");
+    assert_eq!(output.status.code(), Some(2));
+    let stderr = String::from_utf8_lossy(&output.stderr);
+    assert!(stderr.contains("Ciclo de aliases/comandos em dx.yaml: a → b → a"), "{}", stderr);
+
+    allow(dir.path());
+    let output = dx(dir.path()).arg("deploy").output().expect("dx deploy");
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("deploy → ship → deploy"));
+}
+
+// Test that a task slower than --notify-after triggers a desktop notification and keeps the exit code
+#[cfg(target_os = "linux")]
+#[test]
+fn notify_after_reports_slow_task() {
+    use std::os::unix::fs::PermissionsExt;
+
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("dx.yaml"), "tasks:\n  slow:\n    run: sleep 1; exit 3\n").expect("write dx.yaml");
+    // Fake notify-send that records its arguments
+    let bin = dir.path().join("bin");
+    fs::create_dir(&bin).expect("bin dir");
+    let log = dir.path().join("notified.txt");
+    let script = bin.join("notify-send");
+    fs::write(&script, format!("#!/bin/sh\necho \"$@\" > {}\n", log.display())).expect("write notify-send");
+    fs::set_permissions(&script, fs::Permissions::from_mode(0o755)).expect("chmod");
+    let path = format!("{}:{}", bin.display(), std::env::var("PATH").unwrap_or_default());
+
+    allow(dir.path());
+    let output = dx(dir.path())
+        .args(["--notify-after", "1", "run", "slow"])
+        .env("PATH", path)
+        .output()
+        .expect("dx run slow");
+    assert_eq!(output.status.code(), Some(3));
+    let notified = fs::read_to_string(&log).expect("notify-send was not called");
+    assert!(notified.contains("dx: falhou") && notified.contains("dx run slow"), "{}", notified);
+}
+
+// Test that `--progress json` emits NDJSON phase and progress events on stderr
+#[cfg(unix)]
+#[test]
+fn progress_json_emits_events() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("dx.yaml"), "tasks:\n  a:\n    run: echo a\n  b:\n    depends_on: [a]\n    run: echo b\n")
+        .expect("write dx.yaml");
+
+    allow(dir.path());
+    let output = dx(dir.path())
+        .args(["--progress", "json", "run", "b"])
+        .output()
+        .expect("dx run b");
+    assert!(output.status.success());
+    let events: Vec<serde_json::Value> = String::from_utf8_lossy(&output.stderr)
+        .lines()
+        .filter(|l| l.starts_with('{'))
+        .map(|l| serde_json::from_str(l).expect("invalid NDJSON line"))
+        .collect();
+    let kinds: Vec<&str> = events.iter().filter_map(|e| e["event"].as_str()).collect();
+    assert_eq!(kinds, ["phase_started", "progress", "progress", "phase_finished"], "{:?}", events);
+    assert_eq!(events[2]["percent"], 100);
+    assert_eq!(events[3]["success"], true);
+    assert_eq!(String::from_utf8_lossy(&output.stdout), "a\nb\n", "stdout must stay clean");
+}
+
+// Test that scripts of an untrusted dx.yaml do not run until `dx allow`, and run again only after a re-allow when changed
+#[cfg(unix)]
+#[test]
+fn untrusted_project_scripts_require_allow() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("dx.yaml"), "tasks:\n  hello:\n    run: echo hello\n").expect("write dx.yaml");
+
+    let blocked = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert_eq!(blocked.status.code(), Some(2));
+    assert!(blocked.stdout.is_empty(), "the task must not run before dx allow");
+    assert!(String::from_utf8_lossy(&blocked.stderr).contains("dx allow"));
+
+    allow(dir.path());
+    let run = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert_eq!(String::from_utf8_lossy(&run.stdout).trim(), "hello");
+
+    // An unavailable sandbox never falls back to running the script unconfined
+    #[cfg(target_os = "linux")]
+    {
+        let no_bwrap = dx(dir.path()).args(["run", "--sandbox", "hello"]).env("PATH", "/nonexistent").output().expect("dx run");
+        assert_eq!(no_bwrap.status.code(), Some(2));
+        assert!(no_bwrap.stdout.is_empty());
+        assert!(String::from_utf8_lossy(&no_bwrap.stderr).contains("bwrap"));
+    }
+
+    // Changing dx.yaml invalidates the authorization
+    fs::write(dir.path().join("dx.yaml"), "tasks:\n  hello:\n    run: echo changed\n").expect("write dx.yaml");
+    assert_eq!(dx(dir.path()).args(["run", "hello"]).status().expect("dx run").code(), Some(2));
+
+    assert!(dx(dir.path()).arg("deny").status().expect("dx deny").success());
+    let denied = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert_eq!(denied.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&denied.stderr).contains("bloqueados"));
+}
+
+// Test that the authorization covers the Makefile `dx run` falls back to: a changed recipe asks again
+#[cfg(unix)]
+#[test]
+fn changed_makefile_requires_allow_again() {
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("Makefile"), "hello:\n\t@echo hello\n").unwrap();
+    allow(dir.path());
+    let run = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert_eq!(String::from_utf8_lossy(&run.stdout).trim(), "hello");
+
+    fs::write(dir.path().join("Makefile"), "hello:\n\t@echo changed\n").unwrap();
+    let blocked = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert_eq!(blocked.status.code(), Some(2));
+    assert!(blocked.stdout.is_empty(), "the changed recipe must not run before dx allow");
+    assert!(String::from_utf8_lossy(&blocked.stderr).contains("(dx.yaml, Makefile)"), "{}", String::from_utf8_lossy(&blocked.stderr));
+
+    allow(dir.path());
+    let run = dx(dir.path()).args(["run", "hello"]).output().expect("dx run hello");
+    assert_eq!(String::from_utf8_lossy(&run.stdout).trim(), "changed");
+}
+
+/// Child process that is killed and reaped when dropped, also when an assertion fails first
+#[cfg(unix)]
+struct KillOnDrop(std::process::Child);
+
+#[cfg(unix)]
+impl Drop for KillOnDrop {
+    fn drop(&mut self) {
+        let _ = self.0.kill();
+        let _ = self.0.wait();
+    }
+}
+
+// Test that `dx run --watch` runs the task again, from its dependencies, when a source file changes
+#[cfg(unix)]
+#[test]
+fn run_watch_reruns_task() {
+    use std::io::{BufRead, BufReader};
+    let dir = tempfile::tempdir().expect("tempdir");
+    fs::write(dir.path().join("dx.yaml"), "tasks:\n  build:\n    run: echo building\n  serve:\n    depends_on: [build]\n    run: echo serving\n").unwrap();
+    fs::write(dir.path().join("app.py"), "print('v1')\n").unwrap();
+    allow(dir.path());
+    let mut child = KillOnDrop(
+        dx(dir.path())
+            .args(["run", "--watch", "--debounce", "50", "serve"])
+            .stdout(std::process::Stdio::piped())
+            .stderr(std::process::Stdio::piped())
+            .spawn()
+            .expect("dx run --watch"),
+    );
+    let (tx, lines) = std::sync::mpsc::channel();
+    let (stdout, stderr) = (child.0.stdout.take().unwrap(), child.0.stderr.take().unwrap());
+    let out = tx.clone();
+    std::thread::spawn(move || BufReader::new(stdout).lines().map_while(Result::ok).for_each(|l| drop(out.send(l))));
+    std::thread::spawn(move || BufReader::new(stderr).lines().map_while(Result::ok).for_each(|l| drop(tx.send(l))));
+    // Lines of dx run --watch, without the echo of each command
+    let next = || loop {
+        let line = lines.recv_timeout(std::time::Duration::from_secs(10)).expect("dx run --watch went quiet");
+        if !line.starts_with("▶ ") {
+            return line;
+        }
+    };
+
+    let first: Vec<String> = (0..4).map(|_| next()).collect();
+    assert!(first[0].starts_with("Observando "), "{:?}", first);
+    assert_eq!(first[1..3], ["building", "serving"]);
+    assert_eq!(first[3], "A aplicação terminou (código 0); aguardando alterações para reiniciar.");
+    fs::write(dir.path().join("app.py"), "print('v2')\n").unwrap();
+    assert_eq!(next(), "↻ app.py alterado; reiniciando a aplicação...");
+    assert_eq!((next(), next()), ("building".to_string(), "serving".to_string()));
+}
@@ -0,0 +1,127 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::io::{BufRead, BufReader, Write};
+use std::net::TcpListener;
+use std::path::Path;
+use std::process::{Command, Output};
+use std::sync::atomic::{AtomicUsize, Ordering};
+use std::sync::Arc;
+
+const PROFILE: &str = "\
+registries:
+  - registry.npmjs.org
+badges:
+  - \"[![Acme](https://img.shields.io/badge/Acme-approved-blue)](#)\"
+services:
+  - redis
+settings:
+  notify_after: 30
+  registry_rate: 5
+";
+
+/// HTTP server answering every request with the profile; returns its URL and the request count.
+fn profile_server() -> (String, Arc<AtomicUsize>) {
+    let listener = TcpListener::bind("127.0.0.1:0").expect("bind");
+    let addr = listener.local_addr().unwrap();
+    let requests = Arc::new(AtomicUsize::new(0));
+    let count = requests.clone();
+    std::thread::spawn(move || {
+        for stream in listener.incoming().flatten() {
+            let mut reader = BufReader::new(stream.try_clone().unwrap());
+            let mut line = String::new();
+            while reader.read_line(&mut line).is_ok_and(|n| n > 0) && line != "\r\n" {
+                line.clear();
+            }
+            count.fetch_add(1, Ordering::SeqCst);
+            let mut stream = stream;
+            let _ = write!(stream, "HTTP/1.1 200 OK\r\nContent-Length: {}\r\nConnection: close\r\n\r\n{}", PROFILE.len(), PROFILE);
+        }
+    });
+    (format!("http://{}/dx-profile.yaml", addr), requests)
+}
+
+fn dx(dir: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("DX_CONFIG_DIR", dir.join(".dx-config"))
+        .env("DX_STATE_DIR", dir.join(".dx-state"))
+        .env("DX_CACHE_DIR", dir.join(".dx-cache"))
+        .env_remove("DX_NOTIFY_AFTER")
+        .env_remove("DX_REGISTRY_RATE")
+        .env_remove("DX_OUTPUT")
+        .output()
+        .expect("failed to run dx")
+}
+
+fn stdout(output: &Output) -> String {
+    String::from_utf8_lossy(&output.stdout).to_string()
+}
+
+// Test that a profile served over HTTP sets settings below the user's, is overridden by
+// .dx/config.yaml, is fetched once and checks the project's registries and badges
+#[test]
+fn team_profile_from_http() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (url, requests) = profile_server();
+    fs::create_dir_all(tmp.path().join(".dx")).unwrap();
+    fs::write(tmp.path().join(".dx/config.yaml"), format!("profile: {}\nsettings:\n  registry_rate: 2\n", url)).unwrap();
+
+    let output = dx(tmp.path(), &["config", "get", "notify_after", "--explain"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout(&output).starts_with("notify_after = 30\n"), "{}", stdout(&output));
+    assert!(stdout(&output).contains(&format!("→ perfil da equipe ({})", url)), "{}", stdout(&output));
+    let output = dx(tmp.path(), &["config", "get", "registry_rate", "--explain"]);
+    assert!(stdout(&output).contains("→ local (.dx/config.yaml)"), "{}", stdout(&output));
+    assert_eq!(requests.load(Ordering::SeqCst), 1, "the second run reads the cached profile");
+
+    fs::write(
+        tmp.path().join("package-lock.json"),
+        r#"{"packages": {"": {}, "node_modules/left-pad": {"resolved": "https://npm.evil.example/left-pad/-/left-pad-1.3.0.tgz"}}}"#,
+    )
+    .unwrap();
+    fs::write(tmp.path().join("README.md"), "# App\n").unwrap();
+    let output = dx(tmp.path(), &["team", "check"]);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout(&output));
+    assert!(stdout(&output).contains("package-lock.json usa um registry não aprovado: https://npm.evil.example"), "{}", stdout(&output));
+    assert!(stdout(&output).contains("README.md sem o badge obrigatório [![Acme]"), "{}", stdout(&output));
+
+    // dev-badges adds the required badge, which satisfies the check along with an approved registry
+    assert!(dx(tmp.path(), &["dev-badges"]).status.success());
+    fs::write(tmp.path().join(".dx/config.yaml"), format!("profile: {}\nregistries: [npm.evil.example]\n", url)).unwrap();
+    let output = dx(tmp.path(), &["team", "check"]);
+    assert!(output.status.success(), "{}", stdout(&output));
+    assert_eq!(requests.load(Ordering::SeqCst), 1);
+}
+
+// Test that a profile kept in a git repository adds its Dev Services and survives dx clean
+#[test]
+fn team_profile_from_git() {
+    let tmp = tempfile::tempdir().unwrap();
+    let repo = tmp.path().join("profiles");
+    fs::create_dir_all(repo.join("teams")).unwrap();
+    fs::write(repo.join("teams/backend.yaml"), PROFILE).unwrap();
+    for args in [&["init", "--quiet"][..], &["add", "-A"], &["-c", "user.name=dx", "-c", "user.email=dx@example.com", "commit", "--quiet", "-m", "v1"]] {
+        assert!(Command::new("git").arg("-C").arg(&repo).args(args).status().unwrap().success());
+    }
+
+    let project = tmp.path().join("app");
+    fs::create_dir_all(project.join(".dx")).unwrap();
+    fs::write(project.join("go.mod"), "module example.com/app\n\ngo 1.21\n").unwrap();
+    let config = format!("profile:\n  url: {}\n  path: teams/backend.yaml\nservices: [redis, postgres]\n", repo.display());
+    fs::write(project.join(".dx/config.yaml"), &config).unwrap();
+
+    let output = dx(&project, &["--output", "json", "team", "show"]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    let shown: serde_json::Value = serde_json::from_slice(&output.stdout).unwrap();
+    assert_eq!(shown["source"], format!("{}//teams/backend.yaml", repo.display()));
+    assert_eq!(shown["profile"]["services"], serde_json::json!(["redis", "postgres"]));
+    assert_eq!(shown["profile"]["registries"], serde_json::json!(["registry.npmjs.org"]));
+
+    let output = dx(&project, &["dev-services", "--no-save"]);
+    assert!(stdout(&output).contains("redis:") && stdout(&output).contains("postgres:"), "{}", stdout(&output));
+
+    assert!(dx(&project, &["clean"]).status.success());
+    assert_eq!(fs::read_to_string(project.join(".dx/config.yaml")).unwrap(), config);
+}
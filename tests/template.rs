@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+use std::fs;
+use std::path::Path;
+use std::process::{Command, Output};
+
+fn git(dir: &Path, args: &[&str]) {
+    let status = Command::new("git")
+        .arg("-C")
+        .arg(dir)
+        .args(args)
+        .env("GIT_AUTHOR_NAME", "dx")
+        .env("GIT_AUTHOR_EMAIL", "dx@example.com")
+        .env("GIT_COMMITTER_NAME", "dx")
+        .env("GIT_COMMITTER_EMAIL", "dx@example.com")
+        .status()
+        .expect("failed to run git");
+    assert!(status.success(), "git {:?}", args);
+}
+
+fn commit(dir: &Path, tag: &str) {
+    git(dir, &["add", "-A"]);
+    git(dir, &["commit", "--quiet", "-m", tag]);
+    git(dir, &["tag", tag]);
+}
+
+fn dx(state: &Path, args: &[&str]) -> Output {
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .arg("template")
+        .args(args)
+        .env("DX_STATE_DIR", state)
+        .output()
+        .expect("failed to run dx template")
+}
+
+// Test scaffolding from a template, drift detection and the guided update to a new version
+#[test]
+fn template_init_diff_update() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (template, project, state) = (tmp.path().join("template"), tmp.path().join("app"), tmp.path().join("state"));
+    fs::create_dir_all(template.join("ci")).unwrap();
+    git(&template, &["init", "--quiet"]);
+    fs::write(template.join("dx.yaml"), "tasks:\n  build:\n    run: make\n").unwrap();
+    fs::write(template.join("Makefile"), "build:\n\tgo build ./...\n").unwrap();
+    fs::write(template.join("ci/pipeline.yml"), "steps:\n  - lint\n  - test\n  - build\n").unwrap();
+    fs::write(template.join("Dockerfile"), "FROM golang:1.21\nWORKDIR /app\nCOPY . .\nRUN make\n").unwrap();
+    commit(&template, "v1.0.0");
+
+    let output = dx(&state, &["init", &template.to_string_lossy(), &project.to_string_lossy()]);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(project.join("ci/pipeline.yml").exists());
+    let dx_yaml = fs::read_to_string(project.join("dx.yaml")).unwrap();
+    assert!(dx_yaml.contains("run: make") && dx_yaml.contains("version: v1.0.0"), "{}", dx_yaml);
+
+    let output = dx(&state, &["diff", &project.to_string_lossy()]);
+    assert_eq!(output.status.code(), Some(0), "{}", String::from_utf8_lossy(&output.stdout));
+
+    // The project edits two files; the template changes three and adds one
+    fs::write(project.join("ci/pipeline.yml"), "steps:\n  - lint\n  - test\n  - build\n  - deploy\n").unwrap();
+    fs::write(project.join("Dockerfile"), "FROM golang:1.20\nWORKDIR /app\nCOPY . .\nRUN make\n").unwrap();
+    fs::write(template.join("Makefile"), "build:\n\tgo build -trimpath ./...\n").unwrap();
+    fs::write(template.join("ci/pipeline.yml"), "steps:\n  - vet\n  - test\n  - build\n").unwrap();
+    fs::write(template.join("Dockerfile"), "FROM golang:1.22\nWORKDIR /app\nCOPY . .\nRUN make\n").unwrap();
+    fs::write(template.join(".editorconfig"), "root = true\n").unwrap();
+    commit(&template, "v1.1.0");
+
+    let output = dx(&state, &["diff", &project.to_string_lossy()]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("↓ Makefile — alterado no template"), "{}", stdout);
+    assert!(stdout.contains("↓ .editorconfig — novo no template"), "{}", stdout);
+    assert!(stdout.contains("! Dockerfile"), "{}", stdout);
+
+    let output = dx(&state, &["update", "--yes", &project.to_string_lossy()]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(1), "{}", stdout);
+    assert!(stdout.contains("2 arquivo(s) atualizado(s), 1 mesclado(s), 1 com conflito"), "{}", stdout);
+    assert!(fs::read_to_string(project.join("Makefile")).unwrap().contains("-trimpath"));
+    assert_eq!(fs::read_to_string(project.join(".editorconfig")).unwrap(), "root = true\n");
+    // Non-overlapping edits merge cleanly; the same line edited on both sides gets markers
+    assert_eq!(fs::read_to_string(project.join("ci/pipeline.yml")).unwrap(), "steps:\n  - vet\n  - test\n  - build\n  - deploy\n");
+    let dockerfile = fs::read_to_string(project.join("Dockerfile")).unwrap();
+    assert!(dockerfile.contains("<<<<<<< projeto\nFROM golang:1.20\n=======\nFROM golang:1.22\n>>>>>>>"), "{}", dockerfile);
+    let dx_yaml = fs::read_to_string(project.join("dx.yaml")).unwrap();
+    assert!(dx_yaml.contains("version: v1.1.0") && dx_yaml.contains("run: make"), "{}", dx_yaml);
+
+    let output = dx(&state, &["update", &project.to_string_lossy()]);
+    assert!(String::from_utf8_lossy(&output.stdout).contains("já está na versão v1.1.0"));
+}
+
+// Test that diff and update refuse projects without a template record
+#[test]
+fn template_diff_without_record() {
+    let tmp = tempfile::tempdir().unwrap();
+    let output = dx(&tmp.path().join("state"), &["diff", &tmp.path().to_string_lossy()]);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("não está associado a um template"));
+}
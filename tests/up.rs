@@ -0,0 +1,167 @@
+// SPDX-License-Identifier: MIT OR Apache-2.0
+// Copyright (c) 2025 The dx-cli Contributors
+#![cfg(unix)]
+use std::fs;
+use std::os::unix::fs::PermissionsExt;
+use std::path::Path;
+use std::process::{Command, Output};
+
+/// Executable script in `bin` standing in for a real tool.
+fn fake_tool(bin: &Path, name: &str, script: &str) {
+    let path = bin.join(name);
+    fs::write(&path, format!("#!/bin/sh\n{}\n", script)).unwrap();
+    fs::set_permissions(&path, fs::Permissions::from_mode(0o755)).unwrap();
+}
+
+/// dx with the fake tools of `bin` first on PATH and private settings and trust store.
+fn dx(dir: &Path, bin: &Path, args: &[&str]) -> Output {
+    let state = bin.parent().unwrap().join("state");
+    Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(args)
+        .current_dir(dir)
+        .env("PATH", format!("{}:/usr/bin:/bin", bin.display()))
+        .env("DX_CONFIG_DIR", state.join("config"))
+        .env("DX_STATE_DIR", &state)
+        .env_remove("DATABASE_URL")
+        .output()
+        .expect("failed to run dx")
+}
+
+// Test that `dx up` starts the containers, waits for them and runs the app with their variables and logs
+#[test]
+fn up_starts_services_then_app() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (project, bin) = (tmp.path().join("shop"), tmp.path().join("bin"));
+    fs::create_dir_all(&project).unwrap();
+    fs::create_dir_all(&bin).unwrap();
+    fs::write(project.join("docker-compose.yml"), "services:\n  postgres:\n    image: postgres:16\n  migrate:\n    image: migrate/migrate\n").unwrap();
+    let calls = tmp.path().join("docker.log");
+    fake_tool(
+        &bin,
+        "docker",
+        &format!(
+            r#"echo "$*" >> {}
+case "$*" in
+  *" up -d"*) ;;
+  *" ps "*) echo '{{"Service":"postgres","Image":"postgres:16","State":"running","Health":"healthy"}}'
+            echo '{{"Service":"migrate","Image":"migrate/migrate","State":"exited","ExitCode":0}}' ;;
+  *" logs "*) for arg; do service=$arg; done; echo "$service is ready"; exec sleep 5 ;;
+esac"#,
+            calls.display()
+        ),
+    );
+
+    let output = dx(&project, &bin, &["up", "--", "sh", "-c", "sleep 1; echo \"db=$DATABASE_URL\"; exit 3"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert_eq!(output.status.code(), Some(3), "{}\n{}", stdout, String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("✓ 2 serviço(s) pronto(s)"), "{}", stdout);
+    assert!(stdout.contains("Variáveis exportadas para a aplicação: DATABASE_URL, PGDATABASE"), "{}", stdout);
+    assert!(stdout.contains("postgres | postgres is ready"), "{}", stdout);
+    assert!(stdout.contains("migrate  | migrate is ready"), "{}", stdout);
+    assert!(stdout.contains("app      | db=postgres://postgres:"), "{}", stdout);
+    let calls = fs::read_to_string(&calls).unwrap();
+    assert!(calls.lines().next().unwrap().ends_with("docker-compose.yml up -d"), "{}", calls);
+}
+
+// Test that without infrastructure the app starts from the dx.yaml dev task or the stack's command
+#[test]
+fn up_picks_start_command() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (project, bin) = (tmp.path().join("hello"), tmp.path().join("bin"));
+    fs::create_dir_all(&project).unwrap();
+    fs::create_dir_all(&bin).unwrap();
+    fake_tool(&bin, "docker", "echo unexpected docker call >&2; exit 1");
+
+    // Nothing to run
+    let output = dx(&project, &bin, &["up"]);
+    assert_eq!(output.status.code(), Some(2));
+    assert!(String::from_utf8_lossy(&output.stderr).contains("Não foi possível descobrir como iniciar a aplicação"));
+
+    // Go: go run .
+    fs::write(project.join("go.mod"), "module example.com/hello\n\ngo 1.22\n").unwrap();
+    fs::write(project.join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+    fake_tool(&bin, "go", "echo \"go $*\"");
+    let output = dx(&project, &bin, &["up", "--no-logs"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("Nenhum serviço de infraestrutura detectado"), "{}", stdout);
+    assert!(stdout.contains("app | go run ."), "{}", stdout);
+
+    // dx.yaml dev task, after the tasks it depends on, once the project is trusted
+    fs::write(project.join("dx.yaml"), "tasks:\n  gen:\n    run: echo generating\n  dev:\n    depends_on: [gen]\n    run: echo \"dev on $PORT\"\n    env:\n      PORT: \"8080\"\n").unwrap();
+    assert_eq!(dx(&project, &bin, &["up"]).status.code(), Some(2), "untrusted dx.yaml must not run");
+    assert!(dx(&project, &bin, &["allow"]).status.success());
+    let output = dx(&project, &bin, &["up"]);
+    let stdout = String::from_utf8_lossy(&output.stdout);
+    assert!(output.status.success(), "{}", String::from_utf8_lossy(&output.stderr));
+    assert!(stdout.contains("generating\n") && stdout.contains("app | dev on 8080"), "{}", stdout);
+    assert!(String::from_utf8_lossy(&output.stderr).contains("(tarefa dev do dx.yaml)"));
+}
+
+/// Lines of stdout and stderr of a running dx, as they arrive.
+fn stream(child: &mut std::process::Child) -> std::sync::mpsc::Receiver<String> {
+    use std::io::{BufRead, BufReader};
+    let (tx, rx) = std::sync::mpsc::channel();
+    let stdout = child.stdout.take().unwrap();
+    let stderr = child.stderr.take().unwrap();
+    let out = tx.clone();
+    std::thread::spawn(move || BufReader::new(stdout).lines().map_while(Result::ok).for_each(|l| drop(out.send(l))));
+    std::thread::spawn(move || BufReader::new(stderr).lines().map_while(Result::ok).for_each(|l| drop(tx.send(l))));
+    rx
+}
+
+/// Wait up to 10s for a line containing `needle`, returning the lines seen until then.
+fn wait_for(lines: &std::sync::mpsc::Receiver<String>, needle: &str) -> Vec<String> {
+    let mut seen = Vec::new();
+    let deadline = std::time::Instant::now() + std::time::Duration::from_secs(10);
+    while let Some(left) = deadline.checked_duration_since(std::time::Instant::now()) {
+        match lines.recv_timeout(left) {
+            Ok(line) if line.contains(needle) => return seen,
+            Ok(line) => seen.push(line),
+            Err(_) => break,
+        }
+    }
+    panic!("'{}' not seen in:\n{}", needle, seen.join("\n"));
+}
+
+// Test that `dx up --watch` restarts the app on source changes, stopping the previous process, and skips ignored files
+#[test]
+fn up_watch_restarts_app() {
+    let tmp = tempfile::tempdir().unwrap();
+    let (project, bin) = (tmp.path().join("hello"), tmp.path().join("bin"));
+    fs::create_dir_all(&project).unwrap();
+    fs::create_dir_all(&bin).unwrap();
+    fs::write(project.join("main.go"), "package main\n").unwrap();
+    let state = tmp.path().join("state");
+    let mut dx = Command::new(env!("CARGO_BIN_EXE_dx"))
+        .args(["up", "--watch", "--debounce", "100", "--ignore", "*_test.go", "--", "sh", "-c", "echo $$ >> ../pids; echo started; exec sleep 30"])
+        .current_dir(&project)
+        .env("PATH", format!("{}:/usr/bin:/bin", bin.display()))
+        .env("DX_CONFIG_DIR", state.join("config"))
+        .env("DX_STATE_DIR", &state)
+        .stdout(std::process::Stdio::piped())
+        .stderr(std::process::Stdio::piped())
+        .spawn()
+        .unwrap();
+    let lines = stream(&mut dx);
+    wait_for(&lines, "app | started");
+
+    // Ignored: tests (--ignore), docs and build output
+    fs::write(project.join("main_test.go"), "package main\n").unwrap();
+    fs::write(project.join("README.md"), "# hello\n").unwrap();
+    fs::create_dir_all(project.join("node_modules")).unwrap();
+    fs::write(project.join("node_modules").join("index.js"), "\n").unwrap();
+    std::thread::sleep(std::time::Duration::from_millis(800));
+    fs::write(project.join("main.go"), "package main\n\nfunc main() {}\n").unwrap();
+    let seen = wait_for(&lines, "↻ main.go alterado; reiniciando a aplicação...");
+    assert!(!seen.iter().any(|l| l.contains("↻")), "{}", seen.join("\n"));
+    wait_for(&lines, "app | started");
+
+    let pids = fs::read_to_string(tmp.path().join("pids")).unwrap();
+    let pids: Vec<&str> = pids.lines().collect();
+    assert_eq!(pids.len(), 2, "{:?}", pids);
+    let alive = |pid: &str| Command::new("kill").args(["-0", pid]).status().unwrap().success();
+    assert!(!alive(pids[0]), "the previous app process must be stopped");
+    let _ = dx.kill();
+    let _ = Command::new("kill").arg(pids[1]).status();
+}